@@ -17,14 +17,17 @@ import (
 	"github.com/gmsas95/myrai-cli/internal/app"
 	"github.com/gmsas95/myrai-cli/internal/cli"
 	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/incident"
 	"github.com/gmsas95/myrai-cli/internal/jobs"
 	"github.com/gmsas95/myrai-cli/internal/llm"
 	"github.com/gmsas95/myrai-cli/internal/onboarding"
 	"github.com/gmsas95/myrai-cli/internal/persona"
+	"github.com/gmsas95/myrai-cli/internal/remote"
 	"github.com/gmsas95/myrai-cli/internal/skills"
 	"github.com/gmsas95/myrai-cli/internal/store"
 	"github.com/gmsas95/myrai-cli/internal/tui"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 var (
@@ -33,6 +36,9 @@ var (
 	cliMode    = flag.Bool("cli", false, "Run in CLI mode (one-shot or interactive)")
 	tuiMode    = flag.Bool("tui", false, "Run in beautiful TUI mode")
 	message    = flag.String("m", "", "Message to send (CLI mode)")
+	output     = flag.String("output", "", "Write the answer to -m to this file")
+	files      fileFlags
+	project    = flag.String("project", "", "Project to work in, instead of the current one")
 	serverMode = flag.Bool("server", false, "Run in server mode")
 	onboard    = flag.Bool("onboard", false, "Run onboarding wizard")
 	version    = "dev"
@@ -47,11 +53,27 @@ type AppContext struct {
 }
 
 func main() {
+	skills.Version = version
+
+	args, err := cli.ParseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	os.Args = append(os.Args[:1], args...)
+
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
 		case "onboard":
+			if len(os.Args) > 2 {
+				cli.HandleOnboardCommand(os.Args[2:])
+				return
+			}
 			runOnboarding()
 			return
+		case "profile", "profiles":
+			cli.HandleProfileCommand(os.Args[2:])
+			return
 		case "project":
 			cli.HandleProjectCommand(os.Args[2:])
 			return
@@ -78,6 +100,14 @@ func main() {
 				cli.PrintGatewayHelp()
 				return
 			}
+			if len(os.Args) > 2 && os.Args[2] == "profile" {
+				cli.HandleGatewayProfileCommand(os.Args[3:])
+				return
+			}
+			if !cli.GatewayNeedsApp(os.Args[2:]) {
+				cli.HandleGatewayServiceCommand(os.Args[2:])
+				return
+			}
 			appCtx := initAppWithGracefulShutdown()
 			cli.HandleGatewayCommand(os.Args[2:], appCtx.App)
 			return
@@ -105,17 +135,95 @@ func main() {
 		case "job":
 			handleJobCommand(os.Args[2:])
 			return
+		case "alias":
+			cli.HandleAliasCommand(os.Args[2:])
+			return
+		case "plan":
+			cli.HandlePlanCommand(os.Args[2:])
+			return
+		case "prompt":
+			cli.HandlePromptCommand(os.Args[2:])
+			return
+		case "artifacts", "artifact":
+			cli.HandleArtifactsCommand(os.Args[2:])
+			return
+		case "household":
+			cli.HandleHouseholdCommand(os.Args[2:])
+			return
+		case "locale":
+			cli.HandleLocaleCommand(os.Args[2:])
+			return
+		case "calendar":
+			cli.HandleCalendarCommand(os.Args[2:])
+			return
+		case "kb":
+			cli.HandleKBCommand(os.Args[2:])
+			return
+		case "vector":
+			cli.HandleVectorCommand(os.Args[2:])
+			return
+		case "db":
+			cli.HandleDBCommand(os.Args[2:])
+			return
+		case "backup":
+			cli.HandleBackupCommand(os.Args[2:])
+			return
+		case "git":
+			cli.HandleGitCommand(os.Args[2:])
+			return
+		case "do":
+			cli.HandleDoCommand(os.Args[2:])
+			return
+		case "conversations", "conversation", "convs":
+			if len(os.Args) > 2 && os.Args[2] == "resume" && cli.RemoteClient() == nil {
+				conv := cli.ConversationToResume(os.Args[3:])
+				appCtx := initAppWithGracefulShutdown()
+				appCtx.App.ResumeCLI(conv)
+				shutdown(appCtx)
+				return
+			}
+			cli.HandleConversationsCommand(os.Args[2:])
+			return
+		case "privacy":
+			cli.HandlePrivacyCommand(os.Args[2:])
+			return
+		case "secret", "secrets":
+			cli.HandleSecretCommand(os.Args[2:])
+			return
+		case "upgrade":
+			cli.HandleUpgradeCommand(os.Args[2:])
+			return
+		case "tui":
+			// Same as --tui; the remaining flags are parsed below
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+			*tuiMode = true
 		case "help", "--help", "-h":
 			cli.PrintExtendedHelp()
 			return
 		case "version", "--version", "-v":
 			fmt.Printf("Myrai version %s\n", version)
 			return
+		default:
+			if msg, ok := cli.ResolveAlias(os.Args[1], os.Args[2:]); ok {
+				appCtx := initAppWithGracefulShutdown()
+				appCtx.App.RunCLI(msg)
+				shutdown(appCtx)
+				return
+			}
 		}
 	}
 
+	flag.Var(&files, "f", "File to attach to -m (repeatable)")
+	flag.Var(&files, "file", "File to attach to -m (repeatable)")
+	flag.StringVar(output, "o", "", "Write the answer to -m to this file")
 	flag.Parse()
 
+	// With a gateway elsewhere there's no local agent to build
+	if client := cli.RemoteClient(); client != nil {
+		runRemote(client)
+		return
+	}
+
 	if onboarding.CheckFirstRun() && !*onboard && term.IsTerminal(int(os.Stdin.Fd())) {
 		fmt.Println("🤖 Welcome to Myrai!")
 		fmt.Println()
@@ -143,20 +251,35 @@ func main() {
 
 	if *tuiMode {
 		// Run beautiful TUI mode
+		appCtx.App.UseChannelPersona("cli")
 		agentInstance, err := appCtx.App.CreateAgent()
 		if err != nil {
 			appCtx.Logger.Fatal("Failed to create agent", zap.Error(err))
 		}
 
-		if err := tui.Run(agentInstance); err != nil {
+		if err := tui.Run(agentInstance, appCtx.Store); err != nil {
 			appCtx.Logger.Fatal("TUI error", zap.Error(err))
 		}
 		shutdown(appCtx)
 		return
 	}
 
-	if *cliMode || *message != "" {
-		appCtx.App.RunCLI(*message)
+	if *message != "" || len(files) > 0 {
+		req := app.OneShotRequest{Message: *message, Files: files, Output: *output}
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			input, err := app.ReadPipedInput(os.Stdin)
+			if err != nil {
+				appCtx.Logger.Fatal("Failed to read stdin", zap.Error(err))
+			}
+			req.Input = input
+		}
+		appCtx.App.RunOneShot(req)
+		shutdown(appCtx)
+		return
+	}
+
+	if *cliMode {
+		appCtx.App.RunCLI("")
 		shutdown(appCtx)
 		return
 	}
@@ -165,7 +288,37 @@ func main() {
 	runServerWithGracefulShutdown(appCtx)
 }
 
+// runRemote chats with the gateway client points at, one-shot with -m or
+// interactively otherwise
+func runRemote(client *remote.Client) {
+	if *serverMode || *tuiMode || *onboard {
+		fmt.Println("❌ --server, --tui and --onboard can't be used with --remote")
+		os.Exit(1)
+	}
+	if *message != "" || len(files) > 0 {
+		req := app.OneShotRequest{Message: *message, Files: files, Output: *output}
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			input, err := app.ReadPipedInput(os.Stdin)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Failed to read stdin: %v\n", err)
+				os.Exit(1)
+			}
+			req.Input = input
+		}
+		cli.RunRemoteOneShot(client, req)
+		return
+	}
+	cli.RunRemoteInteractive(client, "")
+}
+
 func runOnboarding() {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Println("❌ The setup wizard needs a terminal.")
+		fmt.Println("Use 'myrai onboard --non-interactive --provider <name> --model <model> --api-key-env <VAR>'")
+		fmt.Println("or 'myrai onboard --from answers.yaml' instead; see 'myrai onboard --help'.")
+		os.Exit(1)
+	}
+
 	logger, _ := zap.NewDevelopment()
 	defer logger.Sync()
 
@@ -177,10 +330,13 @@ func runOnboarding() {
 }
 
 func initAppWithGracefulShutdown() *AppContext {
-	logger, err := zap.NewDevelopment()
+	// The level is atomic so incident mode can raise it at runtime
+	logConfig := zap.NewDevelopmentConfig()
+	logger, err := logConfig.Build()
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
+	incidentMode := incident.New(logConfig.Level, logger)
 
 	logger.Info("Starting Myrai",
 		zap.String("version", version),
@@ -192,6 +348,12 @@ func initAppWithGracefulShutdown() *AppContext {
 		logger.Fatal("Failed to load config", zap.Error(err))
 	}
 
+	if level, err := zapcore.ParseLevel(cfg.Server.LogLevel); err != nil {
+		logger.Warn("Invalid log level, keeping debug", zap.String("log_level", cfg.Server.LogLevel))
+	} else {
+		incidentMode.SetBaseLevel(level)
+	}
+
 	st, err := store.New(cfg)
 	if err != nil {
 		logger.Fatal("Failed to initialize store", zap.Error(err))
@@ -203,6 +365,14 @@ func initAppWithGracefulShutdown() *AppContext {
 		logger.Warn("Failed to initialize persona manager", zap.Error(err))
 		pm = nil
 	}
+	if *project != "" {
+		if pm == nil {
+			logger.Fatal("Can't use a project without the persona manager", zap.String("project", *project))
+		}
+		if err := pm.UseProject(*project); err != nil {
+			logger.Fatal("Failed to use project", zap.String("project", *project), zap.Error(err))
+		}
+	}
 
 	// Create LLM client for vision skill
 	var llmClient *llm.Client
@@ -218,6 +388,7 @@ func initAppWithGracefulShutdown() *AppContext {
 
 	application := app.New(cfg, st, logger, pm, version)
 	application.SetSkillsRegistry(skillsRegistry)
+	application.Incident = incidentMode
 
 	// Initialize job registry
 	var jobRegistry *jobs.Registry
@@ -405,8 +576,20 @@ func handleJobCommand(args []string) {
 	}
 }
 
+// fileFlags collects every -f given
+type fileFlags []string
+
+func (f *fileFlags) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileFlags) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
 func getMode() string {
-	if *cliMode || *message != "" {
+	if *cliMode || *message != "" || len(files) > 0 {
 		return "cli"
 	}
 	return "server"
@@ -5,8 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/artifacts"
+	"github.com/gmsas95/myrai-cli/internal/cache"
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/incident"
+	"github.com/gmsas95/myrai-cli/internal/journal"
 	"github.com/gmsas95/myrai-cli/internal/llm"
 	"github.com/gmsas95/myrai-cli/internal/persona"
 	"github.com/gmsas95/myrai-cli/internal/skills"
@@ -26,6 +34,17 @@ type Agent struct {
 	contextManager  *ContextManager
 	agentLoop       *AgentLoop
 	onToolExecuting func(toolName string) // Callback for tool execution feedback
+	pinTokenBudget  int                   // Max tokens of pinned context per conversation
+	incident        *incident.Mode        // Dumps tool calls while active
+	journal         *journal.Journal      // Records file writes and commands
+	artifacts       *artifacts.Store      // Keeps long tool outputs and attached files
+	replies         *ReplyPipeline        // Post-processes replies before delivery
+	titles          config.TitlesConfig   // Names conversations, see titleConversation
+	router          *Router               // Sends simple turns to a cheaper model
+	networkSkills   map[string]bool       // Switched off while offline
+	toolPrompting   atomic.Bool           // The provider rejected native tools
+	subAgents       *Orchestrator         // Runs spawn_subagent calls, see EnableSubAgents
+	subAgentsMu     sync.Mutex
 }
 
 // New creates a new Agent
@@ -58,11 +77,43 @@ func (a *Agent) ExecuteAutonomous(ctx context.Context, task AutonomousTask) (*Ta
 	return a.agentLoop.ExecuteAutonomous(ctx, task)
 }
 
+// SetLoopOptions configures the limits used by Chat and ExecuteAutonomous
+func (a *Agent) SetLoopOptions(opts LoopOptions) {
+	if a.agentLoop == nil {
+		a.agentLoop = NewAgentLoop(a, a.logger)
+	}
+	a.agentLoop.Configure(opts)
+}
+
+// LoopOptions returns the configured agent loop limits
+func (a *Agent) LoopOptions() LoopOptions {
+	if a.agentLoop == nil {
+		return DefaultLoopOptions()
+	}
+	return a.agentLoop.Options()
+}
+
 // SetSkillsRegistry sets the skills registry
 func (a *Agent) SetSkillsRegistry(registry *skills.Registry) {
 	a.skillsRegistry = registry
 }
 
+// SetIncidentMode sets the switch that enables tool-call dumps
+func (a *Agent) SetIncidentMode(mode *incident.Mode) {
+	a.incident = mode
+}
+
+// SetReplyPipeline sets the chain replies run through before delivery
+func (a *Agent) SetReplyPipeline(p *ReplyPipeline) {
+	a.replies = p
+}
+
+// SetJournal sets the activity journal that file writes, commands and plan
+// steps are recorded in
+func (a *Agent) SetJournal(j *journal.Journal) {
+	a.journal = j
+}
+
 // GetSkillsRegistry returns the skills registry
 func (a *Agent) GetSkillsRegistry() *skills.Registry {
 	return a.skillsRegistry
@@ -76,6 +127,11 @@ type ChatRequest struct {
 	Stream          bool
 	OnStream        func(string)
 	OnToolExecuting func(toolName string) // Callback when a tool starts executing
+	Loop            *LoopOptions          // Overrides the configured loop limits for this request
+	NoCache         bool                  // Skills skip cached lookups and fetch fresh results
+	ResponseFormat  *ResponseFormat       // Asks for the answer as JSON matching a schema
+	Images          []llm.ImagePart       // Sent with the message to vision-capable models
+	Channel         string                // cli, api, telegram or discord; picks the reply chain
 }
 
 // ChatResponse represents a chat response
@@ -85,11 +141,41 @@ type ChatResponse struct {
 	ToolCalls      []llm.ToolCall
 	TokensUsed     int
 	ResponseTime   time.Duration
+	Loop           *LoopTelemetry
+	Attachments    []string             // Files tools produced for the user, e.g. charts
+	Artifacts      []artifacts.Artifact // Stored copies of the attachments
+	Sources        []Source             // Retrieved context the answer cited
+	Parts          []string             // Content split into the messages to deliver it as
+	Model          string               // The model that answered
+	Offline        bool                 // A local model answered as the network is down
+	Route          string               // fast or smart when the turn was routed
+	// Structured is the answer as validated JSON when a ResponseFormat was
+	// requested
+	Structured json.RawMessage
+}
+
+// Chatter runs a prompt. *Agent implements it, as does the gateway client
+// of 'myrai --remote'; triggers and batches take a Chatter.
+type Chatter interface {
+	Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error)
 }
 
 // Chat handles a single chat turn with possible tool execution
 func (a *Agent) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
 	start := time.Now()
+	if req.NoCache {
+		ctx = cache.WithBypass(ctx)
+	}
+	ctx, attachments := skills.WithAttachments(ctx)
+	ctx, forget := skills.WithForgetRequest(ctx)
+	if req.ResponseFormat != nil && req.ResponseFormat.schema == nil {
+		if err := req.ResponseFormat.Compile(); err != nil {
+			return nil, err
+		}
+	}
+	if len(req.Images) > 0 && !a.SupportsVision() {
+		return nil, fmt.Errorf("model %s does not accept images", a.llmClient.GetModel())
+	}
 
 	// Get or create conversation
 	conv, err := a.getOrCreateConversation(req.ConversationID)
@@ -108,14 +194,20 @@ func (a *Agent) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error
 		return nil, fmt.Errorf("failed to save user message: %w", err)
 	}
 
+	// Memories, tools and the model follow the current project
+	ctx, profile := a.projectScope(ctx)
+	ctx = a.offlineScope(ctx)
+	offline := a.Offline()
+
 	// Build system prompt
-	systemPrompt := req.SystemPrompt
-	if systemPrompt == "" {
-		systemPrompt = a.buildSystemPrompt()
+	if req.SystemPrompt == "" && a.personaManager != nil {
+		a.personaManager.LearnGlossary(req.Message, persona.GlossaryConversation)
 	}
+	systemPrompt := persona.JoinLayers(a.systemLayers(ctx, req.SystemPrompt))
 
 	// Build message history using context manager if available
 	var messages []llm.Message
+	var sources []Source
 	if a.contextManager != nil {
 		convCtx, err := a.contextManager.BuildContext(ctx, conv.ID, systemPrompt, req.Message)
 		if err != nil {
@@ -123,6 +215,7 @@ func (a *Agent) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error
 			messages, _ = a.buildContext(ctx, conv.ID, systemPrompt)
 		} else {
 			messages = convCtx.Messages
+			sources = convCtx.Sources
 		}
 	} else {
 		messages, err = a.buildContext(ctx, conv.ID, systemPrompt)
@@ -131,6 +224,16 @@ func (a *Agent) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error
 		}
 	}
 
+	// Images go with the message being answered; history keeps only text
+	if len(req.Images) > 0 {
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Role == "user" {
+				messages[i].Images = req.Images
+				break
+			}
+		}
+	}
+
 	// Build tool definitions from both tools and skills registries
 	var toolDefs []map[string]interface{}
 	if a.tools != nil {
@@ -139,6 +242,7 @@ func (a *Agent) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error
 	if a.skillsRegistry != nil {
 		toolDefs = append(toolDefs, a.skillsRegistry.GetToolDefinitions()...)
 	}
+	toolDefs = a.filterToolDefs(ctx, toolDefs)
 
 	// Call LLM
 	tools := a.convertTools(toolDefs)
@@ -148,18 +252,47 @@ func (a *Agent) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error
 		Tools:             tools,
 		MaxTokens:         4096,
 		Stream:            req.Stream,
-		ParallelToolCalls: len(tools) > 0, // Disable parallel tool calls for better reliability
+		ParallelToolCalls: len(tools) > 0, // Read-only calls run concurrently, see runToolCalls
+	}
+	// A project's own model wins over routing. Offline, the local model
+	// answers and the routing state is left for when the network is back.
+	var route string
+	if profile != nil && profile.Model != "" {
+		llmReq.Model = profile.Model
+	} else if !offline {
+		if r, model := a.routeTurn(conv, req); r != "" {
+			route = r
+			if model != "" {
+				llmReq.Model = model
+			}
+		}
+	}
+	if req.ResponseFormat != nil {
+		if len(messages) > 0 && messages[0].Role == "system" {
+			messages[0].Content += "\n\n" + req.ResponseFormat.instruction()
+		}
+		llmReq.ResponseFormat = req.ResponseFormat.llmFormat(llmReq.Model)
 	}
 
 	// Set up tool execution callback for UI feedback
 	a.onToolExecuting = req.OnToolExecuting
 	defer func() { a.onToolExecuting = nil }() // Clear after request
 
+	loopOpts := a.LoopOptions()
+	if req.Loop != nil {
+		loopOpts = *req.Loop
+	}
+
 	var response *ChatResponse
-	if req.Stream && req.OnStream != nil {
-		response, err = a.chatStream(ctx, llmReq, conv.ID, req.OnStream)
+	// Structured answers are validated whole, so they aren't streamed
+	streamed := req.Stream && req.OnStream != nil && req.ResponseFormat == nil
+	if streamed {
+		if offline {
+			req.OnStream(a.offlineNotice() + "\n\n")
+		}
+		response, err = a.chatStream(ctx, llmReq, conv.ID, loopOpts, req.OnStream)
 	} else {
-		response, err = a.chatNonStream(ctx, llmReq, conv.ID)
+		response, err = a.chatNonStream(ctx, llmReq, conv.ID, req.Message, loopOpts, req.ResponseFormat)
 	}
 
 	if err != nil {
@@ -167,51 +300,219 @@ func (a *Agent) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error
 	}
 
 	response.ResponseTime = time.Since(start)
+	response.Model = llmReq.Model
+	response.Route = route
+	a.afterRoutedTurn(conv, route, response)
+	if a.Offline() {
+		// The network may have gone down during the turn
+		response.Model, response.Route, response.Offline = a.llmClient.GetModel(), "", true
+		if !streamed && req.ResponseFormat == nil {
+			response.Content = a.offlineNotice() + "\n\n" + response.Content
+		}
+	}
+	response.Attachments = attachments.Paths()
+	response.Artifacts = a.storeAttachments(ctx, response.ConversationID, response.Attachments)
+
+	// Answers drawing on retrieved context list what they cited
+	if cited := citedSources(response.Content, sources); len(cited) > 0 && req.ResponseFormat == nil {
+		response.Sources = cited
+		response.Content += "\n\n" + FormatSourcesSection(cited)
+	}
+
+	// History keeps the raw reply; channels deliver the processed parts
+	if req.ResponseFormat == nil {
+		response.Content, response.Parts = a.replies.Apply(req.Channel, response.Content)
+	} else {
+		response.Parts = []string{response.Content}
+	}
 
 	// Update conversation stats
 	conv.TokensUsed += int64(response.TokensUsed)
 	conv.MessageCount += 2 // user + assistant
 	conv.UpdatedAt = time.Now()
-	if err := a.store.UpdateConversation(conv); err != nil {
+	if err := a.store.UpdateConversationStats(conv); err != nil {
 		a.logger.Warn("Failed to update conversation stats", zap.Error(err))
 	}
 
+	// Deleted only now, as the turn itself has been saved into it
+	if forget.Requested() {
+		if err := a.store.ForgetConversation(conv.ID); err != nil {
+			return nil, fmt.Errorf("failed to forget conversation: %w", err)
+		}
+		response.ConversationID = ""
+	} else if a.titles.Enabled {
+		go a.titleConversation(context.WithoutCancel(ctx), *conv, req.Message, response.Content)
+	}
+
 	return response, nil
 }
 
-func (a *Agent) chatNonStream(ctx context.Context, req llm.ChatRequest, convID string) (*ChatResponse, error) {
-	resp, err := a.llmClient.ChatCompletion(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("LLM error: %w", err)
+// SupportsVision reports whether the active model accepts images
+func (a *Agent) SupportsVision() bool {
+	return a.llmClient != nil && a.llmClient.SupportsVision()
+}
+
+// chatNonStream runs the tool loop: the model may call tools repeatedly until
+// it answers or a loop limit is reached. The last permitted iteration is sent
+// without tools so the model has to answer. With a format, answers that
+// don't match it are sent back for correction.
+func (a *Agent) chatNonStream(ctx context.Context, req llm.ChatRequest, convID, userMessage string, opts LoopOptions, format *ResponseFormat) (*ChatResponse, error) {
+	start := time.Now()
+	telemetry := &LoopTelemetry{}
+
+	loopCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		loopCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
 	}
 
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from LLM")
+	messages := req.Messages
+	var executed []llm.ToolCall
+	var content string
+	toolsAllowed := true
+	var structured json.RawMessage
+	var structuredErr error
+	structuredRetries := 0
+
+	for {
+		telemetry.Iterations++
+		iterReq := req
+		iterReq.Messages = messages
+		lastIteration := opts.MaxIterations > 0 && telemetry.Iterations >= opts.MaxIterations
+		if !toolsAllowed || lastIteration {
+			iterReq.Tools = nil
+			iterReq.ParallelToolCalls = false
+		}
+
+		resp, err := a.complete(loopCtx, iterReq)
+		if err != nil && iterReq.ResponseFormat != nil && unsupportedResponseFormat(err) {
+			// Fall back to asking for JSON in the prompt and validating it
+			a.logger.Info("Provider rejected response_format, validating JSON instead", zap.Error(err))
+			req.ResponseFormat = nil
+			telemetry.Iterations--
+			continue
+		}
+		if err != nil {
+			if loopCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+				telemetry.StopReason = StopTimeout
+				break
+			}
+			return nil, fmt.Errorf("LLM error: %w", err)
+		}
+		if len(resp.Choices) == 0 {
+			return nil, fmt.Errorf("no response from LLM")
+		}
+
+		telemetry.TokensUsed += resp.Usage.TotalTokens
+		msg := resp.Choices[0].Message
+		content = msg.Content
+
+		if len(msg.ToolCalls) == 0 || iterReq.Tools == nil {
+			if format != nil {
+				structured, structuredErr = format.Parse(content)
+				if structuredErr != nil && structuredRetries < maxStructuredRetries &&
+					opts.limitReached(telemetry.Iterations, telemetry.TokensUsed) == "" {
+					structuredRetries++
+					a.logger.Info("Answer doesn't match the response format, retrying", zap.Error(structuredErr))
+					messages = append(messages,
+						llm.Message{Role: "assistant", Content: content},
+						llm.Message{Role: "user", Content: fmt.Sprintf("Your answer doesn't match the required format: %v. Answer again with only the JSON.", structuredErr)},
+					)
+					continue
+				}
+			}
+			if opts.RequireFinalAnswer {
+				telemetry.ValidationAttempts++
+				ok, reason, tokens := a.validateFinalAnswer(loopCtx, userMessage, content)
+				telemetry.TokensUsed += tokens
+				telemetry.Validated = ok
+				if !ok {
+					if toolsAllowed && opts.limitReached(telemetry.Iterations, telemetry.TokensUsed) == "" {
+						a.logger.Info("Final answer rejected, continuing", zap.String("reason", reason))
+						messages = append(messages,
+							llm.Message{Role: "assistant", Content: content},
+							llm.Message{Role: "user", Content: fmt.Sprintf("Your answer was judged incomplete: %s. Keep working on my original request and give a complete final answer.", reason)},
+						)
+						continue
+					}
+					if telemetry.StopReason == "" {
+						telemetry.StopReason = StopValidationFailed
+					}
+				}
+			}
+			if telemetry.StopReason == "" {
+				telemetry.StopReason = StopCompleted
+				if lastIteration && len(executed) > 0 {
+					telemetry.StopReason = StopMaxIterations
+				}
+			}
+			break
+		}
+
+		if reason := opts.limitReached(0, telemetry.TokensUsed); reason != "" {
+			telemetry.StopReason = reason
+			break
+		}
+
+		followUp, calls, failures := a.executeToolCalls(loopCtx, convID, msg, opts)
+		messages = append(messages, followUp...)
+		executed = append(executed, calls...)
+		telemetry.ToolCalls += len(calls)
+		telemetry.ToolErrors += failures
+
+		if failures > 0 && opts.StopOnToolError {
+			toolsAllowed = false
+			telemetry.StopReason = StopToolError
+		}
+		if loopCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			telemetry.StopReason = StopTimeout
+			break
+		}
 	}
 
-	choice := resp.Choices[0]
-	msg := choice.Message
+	switch telemetry.StopReason {
+	case StopTimeout, StopTokenBudget, StopCostBudget:
+		notice := fmt.Sprintf("(Stopped before finishing: %s after %d iterations.)", strings.ReplaceAll(telemetry.StopReason, "_", " "), telemetry.Iterations)
+		content = strings.TrimSpace(content + "\n\n" + notice)
+	}
 
-	// Handle tool calls
-	if len(msg.ToolCalls) > 0 {
-		return a.handleToolCalls(ctx, req, convID, msg)
+	telemetry.Cost = opts.cost(telemetry.TokensUsed)
+	telemetry.Duration = time.Since(start)
+	a.logger.Debug("Agent loop finished",
+		zap.Int("iterations", telemetry.Iterations),
+		zap.Int("tool_calls", telemetry.ToolCalls),
+		zap.Int("tokens", telemetry.TokensUsed),
+		zap.String("stop_reason", telemetry.StopReason),
+	)
+
+	if format != nil && structured != nil {
+		content = string(structured)
 	}
 
 	// Save assistant message
 	assistantMsg := &store.Message{
 		ConversationID: convID,
 		Role:           "assistant",
-		Content:        msg.Content,
-		Tokens:         resp.Usage.CompletionTokens,
+		Content:        content,
+		Tokens:         llm.CountTokens(content),
 	}
 	if err := a.store.CreateMessage(assistantMsg); err != nil {
 		a.logger.Warn("Failed to save assistant message", zap.Error(err))
 	}
 
+	if format != nil && structured == nil {
+		if structuredErr == nil {
+			structuredErr = fmt.Errorf("stopped before answering: %s", strings.ReplaceAll(telemetry.StopReason, "_", " "))
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStructuredOutput, structuredErr)
+	}
+
 	// Extract memories from this conversation turn (async)
 	if a.contextManager != nil {
+		project := projectFrom(ctx)
 		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			ctx, cancel := context.WithTimeout(WithProject(context.Background(), project), 30*time.Second)
 			defer cancel()
 
 			// Get the user message from the conversation
@@ -221,51 +522,80 @@ func (a *Agent) chatNonStream(ctx context.Context, req llm.ChatRequest, convID s
 				userContent = userMsgs[0].Content
 			}
 
-			if err := a.contextManager.ExtractAndStoreMemories(ctx, convID, userContent, msg.Content); err != nil {
+			if err := a.contextManager.ExtractAndStoreMemories(ctx, convID, userContent, content); err != nil {
 				a.logger.Debug("Failed to extract memories", zap.Error(err))
 			}
 		}()
 	}
 
 	return &ChatResponse{
-		Content:        msg.Content,
+		Content:        content,
 		ConversationID: convID,
-		TokensUsed:     resp.Usage.TotalTokens,
+		ToolCalls:      executed,
+		TokensUsed:     telemetry.TokensUsed,
+		Loop:           telemetry,
+		Structured:     structured,
 	}, nil
 }
 
-func (a *Agent) chatStream(ctx context.Context, req llm.ChatRequest, convID string, onChunk func(string)) (*ChatResponse, error) {
-	handler := llm.NewStreamHandler(onChunk, nil)
+// chatStream streams the answer through onChunk. Tool calls the model makes
+// are run between streamed turns, up to the loop's iteration limit.
+func (a *Agent) chatStream(ctx context.Context, req llm.ChatRequest, convID string, opts LoopOptions, onChunk func(string)) (*ChatResponse, error) {
+	if a.promptedTools() {
+		// Streamed answers don't run tools, so prompted models aren't offered any
+		req.Tools = nil
+		req = promptTools(req)
+	}
 
-	err := a.llmClient.ChatCompletionStream(ctx, req, func(chunk llm.StreamResponse) error {
-		done, toolCalls, err := handler.HandleChunk(chunk)
-		if err != nil {
-			return err
+	telemetry := &LoopTelemetry{}
+	var executed []llm.ToolCall
+	var content string
+
+	for {
+		telemetry.Iterations++
+		iterReq := req
+		if opts.MaxIterations > 0 && telemetry.Iterations >= opts.MaxIterations {
+			iterReq.Tools = nil
+			iterReq.ParallelToolCalls = false
 		}
-		if done {
-			// Handle completion in the callback
-			if len(toolCalls) > 0 {
-				// Tool calls detected - will be handled after stream ends
-				return nil
+
+		handler := llm.NewStreamHandler(onChunk, nil)
+		var toolCalls []llm.ToolCall
+		err := a.llmClient.ChatCompletionStream(ctx, iterReq, func(chunk llm.StreamResponse) error {
+			done, calls, err := handler.HandleChunk(chunk)
+			if err != nil {
+				return err
+			}
+			if done && len(calls) > 0 {
+				toolCalls = calls
 			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("streaming error: %w", err)
 		}
-		return nil
-	})
 
-	if err != nil {
-		return nil, fmt.Errorf("streaming error: %w", err)
-	}
-
-	content := handler.GetContent()
+		content = handler.GetContent()
+		telemetry.TokensUsed += llm.CountTokens(content)
+		if len(toolCalls) == 0 || iterReq.Tools == nil {
+			break
+		}
 
-	// Check for tool calls from handler
-	// Note: We need to access the accumulator's tool calls
-	// For now, let's check if there were any tool calls in the request
-	// and handle them properly
+		followUp, calls, failures := a.executeToolCalls(ctx, convID, llm.Message{
+			Role:      "assistant",
+			Content:   content,
+			ToolCalls: toolCalls,
+		}, opts)
+		req.Messages = append(req.Messages, followUp...)
+		executed = append(executed, calls...)
+		telemetry.ToolCalls += len(calls)
+		telemetry.ToolErrors += failures
+	}
 
-	// Handle tool calls if any (simplified - in real implementation,
-	// we'd track if tool calls were detected)
-	// For now, return the content
+	telemetry.StopReason = StopCompleted
+	if opts.MaxIterations > 0 && telemetry.Iterations >= opts.MaxIterations && len(executed) > 0 {
+		telemetry.StopReason = StopMaxIterations
+	}
 
 	// Save assistant message
 	assistantMsg := &store.Message{
@@ -279,12 +609,18 @@ func (a *Agent) chatStream(ctx context.Context, req llm.ChatRequest, convID stri
 	}
 
 	return &ChatResponse{
-		Content:    content,
-		TokensUsed: llm.CountTokens(content),
+		Content:        content,
+		ConversationID: convID,
+		ToolCalls:      executed,
+		TokensUsed:     telemetry.TokensUsed,
+		Loop:           telemetry,
 	}, nil
 }
 
-func (a *Agent) handleToolCalls(ctx context.Context, req llm.ChatRequest, convID string, msg llm.Message) (*ChatResponse, error) {
+// executeToolCalls runs the tool calls of an assistant message, saving both to
+// the conversation. It returns the messages to append to the LLM context, the
+// tool calls with their final IDs and the number of failed calls.
+func (a *Agent) executeToolCalls(ctx context.Context, convID string, msg llm.Message, opts LoopOptions) ([]llm.Message, []llm.ToolCall, int) {
 	toolCalls := msg.ToolCalls
 
 	// Pre-generate consistent tool call IDs to avoid mismatches
@@ -321,33 +657,23 @@ func (a *Agent) handleToolCalls(ctx context.Context, req llm.ChatRequest, convID
 		a.logger.Warn("Failed to save assistant message with tool calls", zap.Error(err))
 	}
 
-	// Execute tools
+	// Let tools associate what they create with this conversation
+	ctx = context.WithValue(ctx, "conversation_id", convID)
+	// Images tools show, like screenshots, are only collected for models
+	// that can see them
+	var images *skills.Images
+	if a.SupportsVision() {
+		ctx, images = skills.WithImages(ctx)
+	}
+
+	// Execute tools; independent calls run at the same time
+	outcomes := a.runToolCalls(ctx, toolCalls, opts)
 	toolResults := make([]map[string]interface{}, 0, len(toolCalls))
+	failures := 0
 
 	for i, tc := range toolCalls {
-		a.logger.Info("Executing tool",
-			zap.String("tool", tc.Function.Name),
-			zap.String("args", tc.Function.Arguments),
-		)
-
-		// Notify UI that tool is executing
-		if a.onToolExecuting != nil {
-			a.onToolExecuting(tc.Function.Name)
-		}
-
 		toolCallID := toolCallIDs[i]
-
-		// Try skills registry first
-		var result interface{}
-		var err error
-
-		if a.skillsRegistry != nil {
-			result, err = a.skillsRegistry.ExecuteTool(ctx, tc.Function.Name, []byte(tc.Function.Arguments))
-		} else if a.tools != nil {
-			result, err = a.tools.ExecuteJSON(ctx, tc.Function.Name, tc.Function.Arguments)
-		} else {
-			err = fmt.Errorf("no tool registry available")
-		}
+		result, err := outcomes[i].result, outcomes[i].err
 
 		resultObj := map[string]interface{}{
 			"tool_call_id": toolCallID,
@@ -356,6 +682,7 @@ func (a *Agent) handleToolCalls(ctx context.Context, req llm.ChatRequest, convID
 		}
 
 		if err != nil {
+			failures++
 			resultObj["content"] = fmt.Sprintf("Error: %v", err)
 			a.logger.Warn("Tool execution failed",
 				zap.String("tool", tc.Function.Name),
@@ -363,7 +690,15 @@ func (a *Agent) handleToolCalls(ctx context.Context, req llm.ChatRequest, convID
 			)
 		} else {
 			resultStr := fmt.Sprintf("%v", result)
-			resultObj["content"] = resultStr
+			resultObj["content"] = a.storeToolOutput(ctx, convID, tc.Function.Name, resultStr)
+		}
+
+		if a.incident.Active() {
+			a.dumpToolCall(convID, tc, result, err, outcomes[i].elapsed)
+		}
+		a.journalToolCall(ctx, tc, err)
+		if err == nil {
+			a.learnFromDocument(tc.Function.Name, result)
 		}
 
 		toolResults = append(toolResults, resultObj)
@@ -385,17 +720,14 @@ func (a *Agent) handleToolCalls(ctx context.Context, req llm.ChatRequest, convID
 		}
 	}
 
-	// Build follow-up request with tool results
+	// Build follow-up messages with tool results
 	// Note: Content must be omitted (not empty string) when ToolCalls are present
 	// Include reasoning_content if present (required by some LLM APIs with thinking enabled)
-
-	// updatedToolCalls already created above
-
-	followUpMessages := append(req.Messages, llm.Message{
+	followUpMessages := []llm.Message{{
 		Role:             "assistant",
 		ToolCalls:        updatedToolCalls,
 		ReasoningContent: msg.ReasoningContent, // Preserve reasoning content
-	})
+	}}
 
 	for _, tr := range toolResults {
 		followUpMessages = append(followUpMessages, llm.Message{
@@ -405,50 +737,230 @@ func (a *Agent) handleToolCalls(ctx context.Context, req llm.ChatRequest, convID
 		})
 	}
 
-	followUpReq := llm.ChatRequest{
-		Model:       req.Model,
-		Messages:    followUpMessages,
-		Tools:       req.Tools,
-		MaxTokens:   req.MaxTokens,
-		Temperature: req.Temperature,
+	// Tool messages only take text, so images follow them from the user.
+	// Like images the user sends, they aren't saved with the history.
+	if images != nil {
+		if msg := a.toolImagesMessage(images.Paths()); msg != nil {
+			followUpMessages = append(followUpMessages, *msg)
+		}
 	}
 
-	// Get final response
-	resp, err := a.llmClient.ChatCompletion(ctx, followUpReq)
-	if err != nil {
-		return nil, fmt.Errorf("LLM error after tool calls: %w", err)
+	return followUpMessages, updatedToolCalls, failures
+}
+
+// toolOutcome is what running one tool call produced
+type toolOutcome struct {
+	result  interface{}
+	err     error
+	elapsed time.Duration
+}
+
+// parallelSafe reports whether a call may run alongside others. Only tools
+// marked read-only may; anything else could change what the others see, or
+// race with them, so it runs on its own.
+func (a *Agent) parallelSafe(name string) bool {
+	return a.skillsRegistry != nil && a.skillsRegistry.ReadOnly(name)
+}
+
+// runToolCalls runs the calls of one assistant message. Consecutive calls
+// to read-only tools run concurrently, at most opts.MaxParallelTools at a
+// time; others run one by one, in the order the model asked for them. The
+// outcomes are in the order of calls.
+func (a *Agent) runToolCalls(ctx context.Context, calls []llm.ToolCall, opts LoopOptions) []toolOutcome {
+	outcomes := make([]toolOutcome, len(calls))
+	limit := opts.MaxParallelTools
+	if limit <= 0 {
+		limit = len(calls)
 	}
 
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from LLM after tool calls")
+	for start := 0; start < len(calls); {
+		end := start + 1
+		if a.parallelSafe(calls[start].Function.Name) {
+			for end < len(calls) && a.parallelSafe(calls[end].Function.Name) {
+				end++
+			}
+		}
+
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, limit)
+		for i := start; i < end; i++ {
+			slots <- struct{}{}
+			tc := calls[i]
+			a.logger.Info("Executing tool",
+				zap.String("tool", tc.Function.Name),
+				zap.String("args", tc.Function.Arguments),
+			)
+			// Notify UI that tool is executing
+			if a.onToolExecuting != nil {
+				a.onToolExecuting(tc.Function.Name)
+			}
+
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer func() { <-slots }()
+				outcomes[i] = a.runToolCall(ctx, calls[i], opts.ToolTimeout)
+			}(i)
+		}
+		wg.Wait()
+		start = end
 	}
+	return outcomes
+}
 
-	finalContent := resp.Choices[0].Message.Content
+// runToolCall runs one tool call with a timeout, when that's set. A
+// read-only tool that ignores the timeout is left to finish in the
+// background; any other tool is waited for, as it may still be changing
+// things, and the next call must see what it did.
+func (a *Agent) runToolCall(ctx context.Context, tc llm.ToolCall, timeout time.Duration) toolOutcome {
+	started := time.Now()
+	callCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	timedOut := func() error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%s timed out after %s", tc.Function.Name, timeout)
+	}
 
-	// Save final assistant message
-	finalAssistantMsg := &store.Message{
-		ConversationID: convID,
-		Role:           "assistant",
-		Content:        finalContent,
-		Tokens:         resp.Usage.CompletionTokens,
+	var out toolOutcome
+	if !a.parallelSafe(tc.Function.Name) {
+		out.result, out.err = a.callTool(callCtx, tc)
+		if out.err != nil && callCtx.Err() != nil {
+			out.err = timedOut()
+		}
+		out.elapsed = time.Since(started)
+		return out
 	}
-	if err := a.store.CreateMessage(finalAssistantMsg); err != nil {
-		a.logger.Warn("Failed to save assistant message", zap.Error(err))
+
+	done := make(chan toolOutcome, 1)
+	go func() {
+		var out toolOutcome
+		out.result, out.err = a.callTool(callCtx, tc)
+		done <- out
+	}()
+
+	select {
+	case out = <-done:
+	case <-callCtx.Done():
+		out.err = timedOut()
+		a.logger.Warn("Tool call didn't stop when it timed out; leaving it to finish in the background",
+			zap.String("tool", tc.Function.Name),
+			zap.Error(out.err),
+		)
 	}
+	out.elapsed = time.Since(started)
+	return out
+}
 
-	return &ChatResponse{
-		Content:        finalContent,
-		ConversationID: convID,
-		ToolCalls:      toolCalls,
-		TokensUsed:     resp.Usage.TotalTokens,
-	}, nil
+// callTool executes a tool call with the skills registry, or the tools
+// registry when there is none
+func (a *Agent) callTool(ctx context.Context, tc llm.ToolCall) (interface{}, error) {
+	if a.skillsRegistry != nil {
+		return a.skillsRegistry.ExecuteTool(withCallingAgent(ctx, a), tc.Function.Name, []byte(tc.Function.Arguments))
+	}
+	if a.tools != nil {
+		if err := skills.CheckTool(ctx, "", tc.Function.Name); err != nil {
+			return nil, err
+		}
+		return a.tools.ExecuteJSON(ctx, tc.Function.Name, tc.Function.Arguments)
+	}
+	return nil, fmt.Errorf("no tool registry available")
+}
+
+// toolImagesMessage loads the images tools showed into a message for the
+// model, skipping any that can't be read
+func (a *Agent) toolImagesMessage(paths []string) *llm.Message {
+	var parts []llm.ImagePart
+	for _, path := range paths {
+		img, err := llm.ImageFromFile(path)
+		if err != nil {
+			a.logger.Warn("Failed to load tool image", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		parts = append(parts, img)
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return &llm.Message{
+		Role:    "user",
+		Content: fmt.Sprintf("[%d image(s) from the tool results above]", len(parts)),
+		Images:  parts,
+	}
+}
+
+// journalToolCall records tool calls that change the machine: file writes
+// and shell commands
+func (a *Agent) journalToolCall(ctx context.Context, tc llm.ToolCall, err error) {
+	if a.journal == nil {
+		return
+	}
+
+	var args map[string]interface{}
+	json.Unmarshal([]byte(tc.Function.Arguments), &args)
+	userID := household.UserID(ctx)
+
+	switch tc.Function.Name {
+	case "write_file":
+		path, _ := args["path"].(string)
+		verb := "Wrote"
+		if appendMode, _ := args["append"].(bool); appendMode {
+			verb = "Appended to"
+		}
+		a.journal.Record(userID, journal.KindFile, fmt.Sprintf("%s %s", verb, path), "", err)
+	case "execute_command", "exec_command":
+		command, _ := args["command"].(string)
+		a.journal.Record(userID, journal.KindCommand,
+			fmt.Sprintf("Ran `%s`", journal.Summarize(command, 80)), command, err)
+	}
+}
+
+// learnFromDocument adds the terms a processed PDF or image defines to the
+// current project's glossary
+func (a *Agent) learnFromDocument(tool string, result interface{}) {
+	if a.personaManager == nil || (tool != "process_pdf" && tool != "process_image") {
+		return
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	var doc struct {
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(data, &doc) == nil && doc.Text != "" {
+		a.personaManager.LearnGlossary(doc.Text, persona.GlossaryDocument)
+	}
+}
+
+// dumpToolCall logs a tool call in full while incident mode is on
+func (a *Agent) dumpToolCall(convID string, tc llm.ToolCall, result interface{}, err error, elapsed time.Duration) {
+	fields := []zap.Field{
+		zap.String("conversation_id", convID),
+		zap.String("tool", tc.Function.Name),
+		zap.String("args", incident.Truncate(tc.Function.Arguments)),
+		zap.Duration("duration", elapsed),
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	} else if data, jsonErr := json.Marshal(result); jsonErr == nil {
+		fields = append(fields, zap.String("result", incident.Truncate(string(data))))
+	} else {
+		fields = append(fields, zap.String("result", incident.Truncate(fmt.Sprintf("%v", result))))
+	}
+	a.logger.Info("Tool call dump", fields...)
 }
 
 func (a *Agent) getOrCreateConversation(id string) (*store.Conversation, error) {
 	if id == "" {
 		// Create new conversation
 		conv := &store.Conversation{
-			Title: "New Conversation",
+			Title: DefaultTitle,
 		}
 		if err := a.store.CreateConversation(conv); err != nil {
 			return nil, err
@@ -460,7 +972,7 @@ func (a *Agent) getOrCreateConversation(id string) (*store.Conversation, error)
 	if err != nil {
 		// Create new if not found
 		conv = &store.Conversation{
-			Title: "New Conversation",
+			Title: DefaultTitle,
 		}
 		if err := a.store.CreateConversation(conv); err != nil {
 			return nil, err
@@ -475,7 +987,7 @@ func (a *Agent) buildContext(ctx context.Context, convID string, systemPrompt st
 
 	// Build system prompt with persona context (cached internally by personaManager)
 	if systemPrompt == "" {
-		systemPrompt = a.buildSystemPrompt()
+		systemPrompt = a.buildSystemPrompt(ctx)
 	}
 
 	// Preallocate message slice with capacity for efficiency
@@ -484,6 +996,9 @@ func (a *Agent) buildContext(ctx context.Context, convID string, systemPrompt st
 		Role:    "system",
 		Content: systemPrompt,
 	})
+	if pinMsg, ok := pinnedContextMessage(a.store, convID); ok {
+		messages = append(messages, pinMsg)
+	}
 
 	// Get recent messages (last 20)
 	storeMsgs, err := a.store.GetMessages(convID, 20, 0)
@@ -513,12 +1028,10 @@ func (a *Agent) buildContext(ctx context.Context, convID string, systemPrompt st
 	return messages, nil
 }
 
-func (a *Agent) buildSystemPrompt() string {
-	// Add persona context if available, otherwise use default
-	if a.personaManager != nil {
-		return a.personaManager.GetSystemPrompt()
-	}
-	return a.defaultSystemPrompt()
+// buildSystemPrompt answers as the persona ctx names, if any, e.g. the one
+// the channel is bound to
+func (a *Agent) buildSystemPrompt(ctx context.Context) string {
+	return persona.JoinLayers(a.systemLayers(ctx, ""))
 }
 
 func (a *Agent) defaultSystemPrompt() string {
@@ -579,20 +1092,10 @@ func getString(m map[string]interface{}, key string) string {
 
 // GenerateTitle generates a title for a conversation
 func (a *Agent) GenerateTitle(ctx context.Context, firstMessage string) (string, error) {
-	prompt := fmt.Sprintf("Generate a short, concise title (3-5 words) for a conversation that starts with this message: \"%s\". Respond with ONLY the title, no quotes.", firstMessage)
-
-	title, err := a.llmClient.SimpleChat(ctx, "You are a helpful assistant.", prompt)
+	title, err := a.generateTitle(ctx, firstMessage, "")
 	if err != nil {
-		return "New Conversation", nil
+		return DefaultTitle, nil
 	}
-
-	title = strings.TrimSpace(title)
-	title = strings.Trim(title, `"'`)
-
-	if len(title) > 50 {
-		title = title[:50] + "..."
-	}
-
 	return title, nil
 }
 
@@ -604,7 +1107,7 @@ func (a *Agent) ExecuteTool(ctx context.Context, toolName string, args map[strin
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal tool arguments: %w", err)
 		}
-		return a.skillsRegistry.ExecuteTool(ctx, toolName, argsJSON)
+		return a.skillsRegistry.ExecuteTool(withCallingAgent(ctx, a), toolName, argsJSON)
 	}
 	if a.tools != nil {
 		// Convert args to JSON string for the tools registry
@@ -0,0 +1,162 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gmsas95/myrai-cli/internal/artifacts"
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"go.uber.org/zap"
+)
+
+// GetArtifactTool reads and sends stored artifacts
+const GetArtifactTool = "get_artifact"
+
+// Tool results longer than maxInlineToolResult bytes are saved as artifacts.
+// The model sees the first toolResultPreview bytes and reads on with
+// get_artifact.
+const (
+	maxInlineToolResult = 16000
+	toolResultPreview   = 4000
+)
+
+// artifactReadLimit is how much get_artifact returns at a time
+const artifactReadLimit = 8000
+
+// SetArtifacts keeps long tool outputs and the files tools attach in st
+func (a *Agent) SetArtifacts(st *artifacts.Store) {
+	a.artifacts = st
+}
+
+// storeToolOutput saves a tool result too long for the context as an
+// artifact, returning what the model is given instead. Results are passed
+// through when there's no store or saving fails.
+func (a *Agent) storeToolOutput(ctx context.Context, convID, tool, content string) string {
+	if a.artifacts == nil || len(content) <= maxInlineToolResult {
+		return content
+	}
+	art := &artifacts.Artifact{
+		UserID:         household.UserID(ctx),
+		ConversationID: convID,
+		Kind:           artifacts.KindOutput,
+		Name:           tool + ".txt",
+		MimeType:       "text/plain; charset=utf-8",
+		Source:         tool,
+	}
+	if err := a.artifacts.Save(art, []byte(content)); err != nil {
+		a.logger.Warn("Failed to store tool output", zap.String("tool", tool), zap.Error(err))
+		return content
+	}
+	// Cut the preview on a character boundary
+	cut := toolResultPreview
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	preview := content[:cut]
+	return fmt.Sprintf("%s\n\n[Output truncated: showing %d of %d bytes. The full output is artifact %s; "+
+		"read on with get_artifact from offset %d.]", preview, len(preview), len(content), art.ID, len(preview))
+}
+
+// storeAttachments keeps copies of the files tools attached during a turn
+func (a *Agent) storeAttachments(ctx context.Context, convID string, paths []string) []artifacts.Artifact {
+	if a.artifacts == nil {
+		return nil
+	}
+	var stored []artifacts.Artifact
+	for _, path := range paths {
+		if art, ok := a.artifacts.Lookup(path); ok {
+			stored = append(stored, *art)
+			continue
+		}
+		art := &artifacts.Artifact{
+			UserID:         household.UserID(ctx),
+			ConversationID: convID,
+			Kind:           artifacts.KindFile,
+		}
+		if err := a.artifacts.SaveFile(art, path); err != nil {
+			a.logger.Warn("Failed to store attachment", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		stored = append(stored, *art)
+	}
+	return stored
+}
+
+// EnableArtifacts registers get_artifact, which reads long tool outputs
+// saved as artifacts, lists them and sends stored files to the user.
+// Calling it again is a no-op.
+func (a *Agent) EnableArtifacts() error {
+	if a.skillsRegistry == nil {
+		return fmt.Errorf("skills registry not set")
+	}
+	if a.artifacts == nil {
+		return fmt.Errorf("artifact store not set")
+	}
+	if _, exists := a.skillsRegistry.GetTool(GetArtifactTool); exists {
+		return nil
+	}
+	st := a.artifacts
+
+	skill := skills.NewBaseSkill("artifacts", "Stored tool outputs and generated files", "1.0.0")
+	skill.AddTool(skills.Tool{
+		Name: GetArtifactTool,
+		Description: "Read a stored artifact: the full text of a truncated tool output, or a file generated earlier such as a chart or export. " +
+			"Text is returned a piece at a time from offset. Set send to deliver the file to the user. " +
+			"Without an ID, lists the artifacts of this conversation.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"artifact_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Artifact ID",
+				},
+				"offset": map[string]interface{}{
+					"type":        "integer",
+					"description": "Byte offset to read text from (default 0)",
+				},
+				"send": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Send the file to the user as an attachment",
+				},
+			},
+		},
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			id, _ := args["artifact_id"].(string)
+			if id == "" {
+				convID, _ := ctx.Value("conversation_id").(string)
+				return st.List(artifacts.Filter{UserID: household.UserID(ctx), ConversationID: convID, Limit: 20})
+			}
+			art, err := st.Get(id)
+			if err != nil || art.UserID != household.UserID(ctx) {
+				return nil, fmt.Errorf("artifact not found: %s", id)
+			}
+
+			if send, _ := args["send"].(bool); send {
+				if !skills.Attach(ctx, art.StoragePath) {
+					return nil, fmt.Errorf("this channel can't receive files; artifact %s can be downloaded with 'myrai artifacts export %s'", art.ID, art.ID)
+				}
+				return fmt.Sprintf("Sending %s (%s) to the user.", art.Name, art.ID), nil
+			}
+
+			offset, _ := args["offset"].(float64)
+			text, more, err := st.Read(art, int64(offset), artifactReadLimit)
+			if err != nil {
+				return nil, err
+			}
+			result := map[string]interface{}{
+				"id":      art.ID,
+				"name":    art.Name,
+				"size":    art.SizeBytes,
+				"offset":  int64(offset),
+				"content": text,
+			}
+			if more {
+				result["next_offset"] = int64(offset) + int64(len(text))
+			}
+			return result, nil
+		},
+	})
+	return a.skillsRegistry.Register(skill)
+}
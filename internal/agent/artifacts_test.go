@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/artifacts"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+)
+
+func newArtifactTestAgent(t *testing.T) (*Agent, *artifacts.Store) {
+	a, st := newPlanTestAgent(t)
+	arts, err := artifacts.NewStore(st.DB(), filepath.Join(t.TempDir(), "artifacts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.SetArtifacts(arts)
+	a.SetSkillsRegistry(skills.NewRegistry(nil))
+	if err := a.EnableArtifacts(); err != nil {
+		t.Fatalf("EnableArtifacts failed: %v", err)
+	}
+	return a, arts
+}
+
+func TestAgent_StoreToolOutput(t *testing.T) {
+	a, arts := newArtifactTestAgent(t)
+	ctx := context.WithValue(context.Background(), "conversation_id", "conv_1")
+
+	if got := a.storeToolOutput(ctx, "conv_1", "lookup", "short"); got != "short" {
+		t.Errorf("Expected a short result to pass through, got %q", got)
+	}
+
+	long := strings.Repeat("é", maxInlineToolResult)
+	got := a.storeToolOutput(ctx, "conv_1", "web_fetch", long)
+	list, _ := arts.List(artifacts.Filter{ConversationID: "conv_1"})
+	if len(list) != 1 || list[0].Kind != artifacts.KindOutput || list[0].Source != "web_fetch" {
+		t.Fatalf("Expected one stored output, got %+v", list)
+	}
+	if len(got) > toolResultPreview+300 || !strings.Contains(got, list[0].ID) {
+		t.Errorf("Expected a preview naming %s, got %d bytes", list[0].ID, len(got))
+	}
+	preview := strings.SplitN(got, "\n\n[", 2)[0]
+	if !strings.HasPrefix(long, preview) || strings.ContainsRune(preview, '�') {
+		t.Error("Expected the preview to be the start of the output, cut between characters")
+	}
+
+	get, _ := a.skillsRegistry.GetTool(GetArtifactTool)
+	out, err := get.Handler(ctx, map[string]interface{}{"artifact_id": list[0].ID, "offset": float64(len(preview))})
+	if err != nil {
+		t.Fatalf("get_artifact failed: %v", err)
+	}
+	page := out.(map[string]interface{})
+	if content := page["content"].(string); !strings.HasPrefix(long[len(preview):], content) || content == "" {
+		t.Errorf("Expected the output to continue after the preview, got %d bytes", len(content))
+	}
+	if page["next_offset"] == nil {
+		t.Error("Expected a next offset for the rest of the output")
+	}
+
+	listed, err := get.Handler(ctx, map[string]interface{}{})
+	if err != nil || len(listed.([]artifacts.Artifact)) != 1 {
+		t.Errorf("Expected the conversation's artifact to be listed, got %v, %v", listed, err)
+	}
+}
+
+func TestAgent_StoreAttachments(t *testing.T) {
+	a, arts := newArtifactTestAgent(t)
+
+	path := filepath.Join(t.TempDir(), "report.csv")
+	if err := os.WriteFile(path, []byte("a,b\n1,2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stored := a.storeAttachments(context.Background(), "conv_2", []string{path})
+	if len(stored) != 1 || stored[0].Name != "report.csv" || stored[0].ConversationID != "conv_2" {
+		t.Fatalf("Unexpected stored attachments: %+v", stored)
+	}
+
+	// Sending a stored artifact attaches its copy, which isn't stored again
+	ctx, attachments := skills.WithAttachments(context.Background())
+	get, _ := a.skillsRegistry.GetTool(GetArtifactTool)
+	if _, err := get.Handler(ctx, map[string]interface{}{"artifact_id": stored[0].ID, "send": true}); err != nil {
+		t.Fatalf("get_artifact send failed: %v", err)
+	}
+	again := a.storeAttachments(ctx, "conv_2", attachments.Paths())
+	if len(again) != 1 || again[0].ID != stored[0].ID {
+		t.Errorf("Expected the sent artifact itself, got %+v", again)
+	}
+	if list, _ := arts.List(artifacts.Filter{}); len(list) != 1 {
+		t.Errorf("Expected one artifact, got %d", len(list))
+	}
+
+	if _, err := get.Handler(context.Background(), map[string]interface{}{"artifact_id": stored[0].ID, "send": true}); err == nil {
+		t.Error("Expected sending without a channel to fail")
+	}
+	if _, err := get.Handler(context.Background(), map[string]interface{}{"artifact_id": "art_missing"}); err == nil {
+		t.Error("Expected an unknown artifact to be an error")
+	}
+}
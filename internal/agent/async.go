@@ -0,0 +1,317 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/strutil"
+	"go.uber.org/zap"
+)
+
+// Tools that queue and check background jobs
+const (
+	AskAsyncTool    = "ask_async"
+	GetAsyncJobTool = "get_async_job"
+)
+
+// asyncPollInterval is how often idle workers look for jobs, in case one
+// was queued by another process
+const asyncPollInterval = 30 * time.Second
+
+// AsyncConfig bounds the workers that run queued jobs
+type AsyncConfig struct {
+	Workers     int           // Jobs running at the same time
+	Timeout     time.Duration // Wall-clock limit per job
+	MaxAttempts int           // Runs before a job a restart keeps interrupting is given up
+}
+
+// DefaultAsyncConfig returns the limits used when nothing is configured
+func DefaultAsyncConfig() AsyncConfig {
+	return AsyncConfig{
+		Workers:     2,
+		Timeout:     30 * time.Minute,
+		MaxAttempts: 3,
+	}
+}
+
+// AsyncQueue runs jobs queued with ask_async in the background, so long
+// tool chains neither hold up the channel that asked for them nor are lost
+// on restart
+type AsyncQueue struct {
+	agent     *Agent
+	config    AsyncConfig
+	logger    *zap.Logger
+	household *household.Manager
+	onDone    func(ctx context.Context, job *store.AsyncJob)
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewAsyncQueue creates a queue whose jobs the agent runs. Zero limits in
+// cfg take the defaults.
+func NewAsyncQueue(a *Agent, cfg AsyncConfig) *AsyncQueue {
+	defaults := DefaultAsyncConfig()
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaults.Workers
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaults.MaxAttempts
+	}
+	q := &AsyncQueue{
+		agent:  a,
+		config: cfg,
+		logger: a.logger,
+		wake:   make(chan struct{}, 1),
+	}
+	if mgr, err := household.NewManager(a.store.DB()); err == nil {
+		q.household = mgr
+	}
+	return q
+}
+
+// SetOnDone calls fn when a job finishes or fails, to tell the user
+func (q *AsyncQueue) SetOnDone(fn func(ctx context.Context, job *store.AsyncJob)) {
+	q.onDone = fn
+}
+
+// Enqueue saves a job for the user in ctx and wakes a worker
+func (q *AsyncQueue) Enqueue(ctx context.Context, title, payload, conversationID string) (*store.AsyncJob, error) {
+	payload = strings.TrimSpace(payload)
+	if payload == "" {
+		return nil, fmt.Errorf("the task is empty")
+	}
+	if title = strings.TrimSpace(title); title == "" {
+		title = payload
+	}
+	job := &store.AsyncJob{
+		UserID:         household.UserID(ctx),
+		ConversationID: conversationID,
+		Title:          strutil.Truncate(title, 60),
+		Payload:        payload,
+	}
+	if err := q.agent.store.EnqueueAsyncJob(job); err != nil {
+		return nil, err
+	}
+	q.notify()
+	return job, nil
+}
+
+// Start requeues jobs a previous run left unfinished and starts the workers
+func (q *AsyncQueue) Start() {
+	if n, err := q.agent.store.RequeueAsyncJobs(); err != nil {
+		q.logger.Warn("Failed to requeue interrupted jobs", zap.Error(err))
+	} else if n > 0 {
+		q.logger.Info("Requeued interrupted jobs", zap.Int64("count", n))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+	for i := 0; i < q.config.Workers; i++ {
+		q.wg.Add(1)
+		go q.worker(ctx)
+	}
+	q.notify()
+}
+
+// Stop stops the workers. Jobs they were running are queued again for the
+// next start.
+func (q *AsyncQueue) Stop() {
+	if q.cancel == nil {
+		return
+	}
+	q.cancel()
+	q.wg.Wait()
+}
+
+func (q *AsyncQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *AsyncQueue) worker(ctx context.Context) {
+	defer q.wg.Done()
+	ticker := time.NewTicker(asyncPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+
+		for ctx.Err() == nil {
+			job, err := q.agent.store.ClaimAsyncJob()
+			if err != nil {
+				q.logger.Error("Failed to claim job", zap.Error(err))
+				break
+			}
+			if job == nil {
+				break
+			}
+			// Let another worker look for the next job
+			q.notify()
+			q.run(ctx, job)
+		}
+	}
+}
+
+// run answers one job in a conversation of its own and records the outcome
+func (q *AsyncQueue) run(ctx context.Context, job *store.AsyncJob) {
+	if job.Attempts > q.config.MaxAttempts {
+		q.finish(ctx, job, "", fmt.Errorf("interrupted %d times", job.Attempts-1))
+		return
+	}
+
+	conv := &store.Conversation{Title: "Job: " + job.Title}
+	if err := q.agent.store.CreateConversation(conv); err != nil {
+		q.finish(ctx, job, "", err)
+		return
+	}
+	job.RunID = conv.ID
+
+	runCtx, cancel := context.WithTimeout(q.jobContext(ctx, job), q.config.Timeout)
+	defer cancel()
+	loop := q.agent.LoopOptions()
+	loop.Timeout = q.config.Timeout
+
+	q.logger.Info("Job started", zap.String("id", job.ID), zap.Int("attempt", job.Attempts))
+	resp, err := q.agent.Chat(runCtx, ChatRequest{ConversationID: conv.ID, Message: job.Payload, Loop: &loop})
+
+	if ctx.Err() != nil {
+		// Shutting down: Start queues it again
+		return
+	}
+	if resp != nil {
+		job.TokensUsed += resp.TokensUsed
+	}
+	if err != nil {
+		q.finish(ctx, job, "", err)
+		return
+	}
+	q.finish(ctx, job, resp.Content, nil)
+}
+
+// jobContext carries the household profile of the user who asked for the
+// job, and keeps the job from queueing further jobs
+func (q *AsyncQueue) jobContext(ctx context.Context, job *store.AsyncJob) context.Context {
+	if q.household != nil && job.UserID != "" && job.UserID != household.SharedUserID {
+		if profile, err := q.household.Get(job.UserID); err == nil {
+			ctx = household.WithProfile(ctx, profile)
+		}
+	}
+	return skills.WithToolFilter(ctx, func(skill, tool string) error {
+		if tool == AskAsyncTool {
+			return fmt.Errorf("this is already a background job; do the work here")
+		}
+		return nil
+	})
+}
+
+func (q *AsyncQueue) finish(ctx context.Context, job *store.AsyncJob, result string, err error) {
+	now := time.Now()
+	job.CompletedAt = &now
+	job.Result = result
+	job.Status = store.AsyncJobDone
+	if err != nil {
+		job.Status, job.Error = store.AsyncJobFailed, err.Error()
+	}
+	if err := q.agent.store.UpdateAsyncJob(job); err != nil {
+		q.logger.Error("Failed to record job", zap.String("id", job.ID), zap.Error(err))
+	}
+	q.logger.Info("Job finished", zap.String("id", job.ID), zap.String("status", job.Status))
+
+	if q.onDone != nil {
+		q.onDone(context.WithoutCancel(ctx), job)
+	}
+}
+
+// EnableAsyncJobs registers ask_async, which queues long work for q, and
+// get_async_job, which reports on it. Calling it again is a no-op.
+func (a *Agent) EnableAsyncJobs(q *AsyncQueue) error {
+	if a.skillsRegistry == nil {
+		return fmt.Errorf("skills registry not set")
+	}
+	if _, exists := a.skillsRegistry.GetTool(AskAsyncTool); exists {
+		return nil
+	}
+
+	skill := skills.NewBaseSkill("async", "Run long tasks in the background and report back", "1.0.0")
+	skill.AddTool(skills.Tool{
+		Name: AskAsyncTool,
+		Description: "Queue a long, self-contained task, such as research across many pages or a long chain of tool calls, " +
+			"to run in the background. The user is sent the result when it's done, so answer now saying it was queued. " +
+			"The task runs without this conversation, so include everything it needs.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"task": map[string]interface{}{
+					"type":        "string",
+					"description": "Complete instructions for the background job",
+				},
+				"title": map[string]interface{}{
+					"type":        "string",
+					"description": "Short name for the job, shown to the user",
+				},
+			},
+			"required": []string{"task"},
+		},
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			task, _ := args["task"].(string)
+			title, _ := args["title"].(string)
+			convID, _ := ctx.Value("conversation_id").(string)
+			job, err := q.Enqueue(ctx, title, task, convID)
+			if err != nil {
+				return nil, err
+			}
+			return fmt.Sprintf("Queued job %s (%q). The user will be sent the result when it finishes.", job.ID, job.Title), nil
+		},
+	})
+	skill.AddTool(skills.Tool{
+		Name:        GetAsyncJobTool,
+		Description: "Check on a background job queued with ask_async, or list the user's recent jobs when no ID is given",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"job_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Job ID",
+				},
+			},
+		},
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			jobID, _ := args["job_id"].(string)
+			if jobID == "" {
+				return a.store.ListAsyncJobs(household.UserID(ctx), 10)
+			}
+			job, err := a.store.GetAsyncJob(jobID)
+			if err != nil || job.UserID != household.UserID(ctx) {
+				return nil, fmt.Errorf("job not found: %s", jobID)
+			}
+			return job, nil
+		},
+	})
+	return a.skillsRegistry.Register(skill)
+}
+
+// FormatAsyncJobResult renders a finished job as a message to the user
+func FormatAsyncJobResult(job *store.AsyncJob) (title, body string) {
+	if job.Status == store.AsyncJobFailed {
+		return "Job failed: " + job.Title, job.Error
+	}
+	return "Job done: " + job.Title, job.Result
+}
@@ -0,0 +1,140 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+func waitForAsyncJob(t *testing.T, st *store.Store, id string) *store.AsyncJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := st.GetAsyncJob(id)
+		if err != nil {
+			t.Fatalf("GetAsyncJob failed: %v", err)
+		}
+		if job.Status == store.AsyncJobDone || job.Status == store.AsyncJobFailed {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Job still %s", job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAsyncQueue_RunsJobs(t *testing.T) {
+	a, st := newPlanTestAgent(t)
+	q := NewAsyncQueue(a, AsyncConfig{Workers: 2})
+
+	done := make(chan *store.AsyncJob, 1)
+	q.SetOnDone(func(ctx context.Context, job *store.AsyncJob) { done <- job })
+	q.Start()
+	defer q.Stop()
+
+	job, err := q.Enqueue(context.Background(), "", "Compare three laptops", "conv_1")
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if job.Status != store.AsyncJobPending || job.Title != "Compare three laptops" {
+		t.Errorf("Unexpected queued job: %+v", job)
+	}
+
+	select {
+	case finished := <-done:
+		if finished.ID != job.ID || finished.Status != store.AsyncJobDone {
+			t.Errorf("Expected job %s done, got %+v", job.ID, finished)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Job never finished")
+	}
+
+	saved := waitForAsyncJob(t, st, job.ID)
+	if saved.Result != "did Compare three laptops" || saved.Attempts != 1 || saved.TokensUsed != 10 {
+		t.Errorf("Unexpected result: %+v", saved)
+	}
+	if saved.RunID == "" || saved.RunID == "conv_1" {
+		t.Errorf("Expected the job to run in a conversation of its own, got %q", saved.RunID)
+	}
+	if title, body := FormatAsyncJobResult(saved); !strings.Contains(title, "Compare three laptops") || body != saved.Result {
+		t.Errorf("Unexpected notification: %q %q", title, body)
+	}
+}
+
+func TestAsyncQueue_RequeuesInterruptedJobs(t *testing.T) {
+	a, st := newPlanTestAgent(t)
+
+	interrupted := &store.AsyncJob{Title: "Research", Payload: "Research tides"}
+	if err := st.EnqueueAsyncJob(interrupted); err != nil {
+		t.Fatal(err)
+	}
+	given := &store.AsyncJob{Title: "Stuck", Payload: "Never finishes"}
+	if err := st.EnqueueAsyncJob(given); err != nil {
+		t.Fatal(err)
+	}
+	// Both were left running by a process that stopped; the second has
+	// already used up its attempts
+	interrupted.Status, interrupted.Attempts = store.AsyncJobRunning, 1
+	given.Status, given.Attempts = store.AsyncJobRunning, 3
+	st.UpdateAsyncJob(interrupted)
+	st.UpdateAsyncJob(given)
+
+	q := NewAsyncQueue(a, AsyncConfig{Workers: 1, MaxAttempts: 3})
+	q.Start()
+	defer q.Stop()
+
+	if job := waitForAsyncJob(t, st, interrupted.ID); job.Status != store.AsyncJobDone || job.Attempts != 2 {
+		t.Errorf("Expected the interrupted job to finish on its second attempt, got %+v", job)
+	}
+	if job := waitForAsyncJob(t, st, given.ID); job.Status != store.AsyncJobFailed {
+		t.Errorf("Expected the job to be given up, got %+v", job)
+	}
+}
+
+func TestEnableAsyncJobs(t *testing.T) {
+	a, st := newPlanTestAgent(t)
+	registry := skills.NewRegistry(nil)
+	a.SetSkillsRegistry(registry)
+	q := NewAsyncQueue(a, AsyncConfig{})
+
+	if err := a.EnableAsyncJobs(q); err != nil {
+		t.Fatalf("EnableAsyncJobs failed: %v", err)
+	}
+	if err := a.EnableAsyncJobs(q); err != nil {
+		t.Fatalf("Expected enabling twice to be a no-op, got %v", err)
+	}
+
+	ask, ok := registry.GetTool(AskAsyncTool)
+	if !ok {
+		t.Fatal("Expected ask_async to be registered")
+	}
+	ctx := context.WithValue(context.Background(), "conversation_id", "conv_9")
+	if _, err := ask.Handler(ctx, map[string]interface{}{"task": "  "}); err == nil {
+		t.Error("Expected an empty task to be refused")
+	}
+	out, err := ask.Handler(ctx, map[string]interface{}{"task": "Summarise the news", "title": "News"})
+	if err != nil {
+		t.Fatalf("ask_async failed: %v", err)
+	}
+	jobs, _ := st.ListAsyncJobs("", 0)
+	if len(jobs) != 1 || jobs[0].ConversationID != "conv_9" || jobs[0].Title != "News" {
+		t.Fatalf("Expected one queued job from conv_9, got %+v", jobs)
+	}
+	if !strings.Contains(out.(string), jobs[0].ID) {
+		t.Errorf("Expected the job ID in %q", out)
+	}
+
+	get, _ := registry.GetTool(GetAsyncJobTool)
+	job, err := get.Handler(context.Background(), map[string]interface{}{"job_id": jobs[0].ID})
+	if err != nil || job.(*store.AsyncJob).Status != store.AsyncJobPending {
+		t.Errorf("get_async_job = %v, %v", job, err)
+	}
+	if _, err := get.Handler(context.Background(), map[string]interface{}{"job_id": "ajob_missing"}); err == nil {
+		t.Error("Expected an unknown job to be an error")
+	}
+}
@@ -0,0 +1,128 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// memoryRelevance is how relevant a memory must be to be given as context
+const memoryRelevance = 0.7
+
+// citationPattern matches the [n] markers the model cites context with
+var citationPattern = regexp.MustCompile(`\[(\d{1,2})\]`)
+
+// Source is an item of retrieved context the model was given and can cite
+type Source struct {
+	Marker    int       `json:"marker"`
+	Kind      string    `json:"kind"`            // memory, document or web
+	Title     string    `json:"title,omitempty"` // file name, page title or memory type
+	URL       string    `json:"url,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitempty"` // when a memory was remembered
+	Content   string    `json:"-"`
+	Relevance float64   `json:"relevance"`
+}
+
+// DocumentSearcher finds passages in the user's documents for a query, to be
+// given to the model alongside memories
+type DocumentSearcher interface {
+	SearchDocuments(ctx context.Context, query string, limit int) ([]Source, error)
+}
+
+// memorySources turns memories relevant enough to include into sources
+func memorySources(memories []MemoryInfo) []Source {
+	var sources []Source
+	for _, m := range memories {
+		if m.Relevance > memoryRelevance {
+			sources = append(sources, Source{
+				Kind:      "memory",
+				Title:     m.Type,
+				Timestamp: m.CreatedAt,
+				Content:   m.Content,
+				Relevance: m.Relevance,
+			})
+		}
+	}
+	return sources
+}
+
+// numberSources gives each source its marker
+func numberSources(sources []Source) []Source {
+	for i := range sources {
+		sources[i].Marker = i + 1
+	}
+	return sources
+}
+
+// formatSources lists sources for the model, one per line, each with the
+// marker it cites the source with
+func formatSources(sources []Source) string {
+	var lines []string
+	for _, s := range sources {
+		label := s.Title
+		switch s.Kind {
+		case "document":
+			label = "document: " + s.Title
+		case "web":
+			label = "web page: " + s.Title
+		}
+		content := strings.Join(strings.Fields(s.Content), " ")
+		lines = append(lines, fmt.Sprintf("[%d] [%s] %s", s.Marker, label, content))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// citedSources returns the sources content cites, in marker order
+func citedSources(content string, sources []Source) []Source {
+	if len(sources) == 0 {
+		return nil
+	}
+	byMarker := make(map[int]Source, len(sources))
+	for _, s := range sources {
+		byMarker[s.Marker] = s
+	}
+
+	seen := make(map[int]bool)
+	var cited []Source
+	for _, m := range citationPattern.FindAllStringSubmatch(content, -1) {
+		marker, _ := strconv.Atoi(m[1])
+		if s, ok := byMarker[marker]; ok && !seen[marker] {
+			seen[marker] = true
+			cited = append(cited, s)
+		}
+	}
+	sort.Slice(cited, func(a, b int) bool { return cited[a].Marker < cited[b].Marker })
+	return cited
+}
+
+// FormatSourcesSection renders cited sources as the "Sources" section shown
+// under an answer
+func FormatSourcesSection(sources []Source) string {
+	var b strings.Builder
+	b.WriteString("Sources:")
+	for _, s := range sources {
+		fmt.Fprintf(&b, "\n[%d] ", s.Marker)
+		switch s.Kind {
+		case "web":
+			b.WriteString(s.Title)
+			if s.URL != "" {
+				b.WriteString(" - " + s.URL)
+			}
+		case "memory":
+			b.WriteString("Memory")
+			if s.Title != "" {
+				b.WriteString(" (" + s.Title + ")")
+			}
+			if !s.Timestamp.IsZero() {
+				b.WriteString(", " + s.Timestamp.Format("2006-01-02 15:04"))
+			}
+		default:
+			b.WriteString(s.Title)
+		}
+	}
+	return b.String()
+}
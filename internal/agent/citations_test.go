@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"go.uber.org/zap"
+)
+
+type fakeDocuments []Source
+
+func (f fakeDocuments) SearchDocuments(ctx context.Context, query string, limit int) ([]Source, error) {
+	return f, nil
+}
+
+func TestContextManager_DocumentSources(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+
+	conv := &store.Conversation{Title: "Insurance"}
+	if err := st.CreateConversation(conv); err != nil {
+		t.Fatalf("Failed to create conversation: %v", err)
+	}
+
+	cm := NewContextManager(st, nil, nil, zap.NewNop())
+	cm.SetDocumentSearcher(fakeDocuments{
+		{Kind: "document", Title: "policy.pdf", Content: "The excess is\n£250.", Relevance: 0.8},
+		{Kind: "web", Title: "Claims guide", URL: "https://example.com/claims", Content: "Claim within 30 days.", Relevance: 0.6},
+	})
+
+	result, err := cm.BuildContext(context.Background(), conv.ID, "You are helpful.", "what is my excess?")
+	if err != nil {
+		t.Fatalf("BuildContext failed: %v", err)
+	}
+	if len(result.Sources) != 2 || result.Sources[1].Marker != 2 {
+		t.Fatalf("Expected two numbered sources, got %+v", result.Sources)
+	}
+
+	var injected string
+	for _, msg := range result.Messages {
+		if strings.HasPrefix(msg.Content, "Relevant context") {
+			injected = msg.Content
+		}
+	}
+	for _, want := range []string{"cite its marker", "[1] [document: policy.pdf] The excess is £250.", "[2] [web page: Claims guide] Claim within 30 days."} {
+		if !strings.Contains(injected, want) {
+			t.Errorf("Expected context to contain %q, got:\n%s", want, injected)
+		}
+	}
+}
+
+func TestCitedSources(t *testing.T) {
+	remembered := time.Date(2025, 3, 1, 14, 2, 0, 0, time.UTC)
+	sources := numberSources([]Source{
+		{Kind: "memory", Title: "preference", Timestamp: remembered},
+		{Kind: "document", Title: "policy.pdf"},
+		{Kind: "web", Title: "Claims guide", URL: "https://example.com/claims"},
+	})
+
+	cited := citedSources("Your excess is £250 [2]. Claim within 30 days [3][2]; not [7].", sources)
+	if len(cited) != 2 || cited[0].Marker != 2 || cited[1].Marker != 3 {
+		t.Fatalf("Expected sources 2 and 3, got %+v", cited)
+	}
+	if got := citedSources("No citations here.", sources); got != nil {
+		t.Errorf("Expected no sources, got %+v", got)
+	}
+
+	section := FormatSourcesSection(numberSources(sources))
+	want := "Sources:\n[1] Memory (preference), 2025-03-01 14:02\n[2] policy.pdf\n[3] Claims guide - https://example.com/claims"
+	if section != want {
+		t.Errorf("Unexpected sources section:\n%s", section)
+	}
+}
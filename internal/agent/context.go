@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/config"
 	"github.com/gmsas95/myrai-cli/internal/llm"
 	"github.com/gmsas95/myrai-cli/internal/neural"
 	"github.com/gmsas95/myrai-cli/internal/store"
@@ -27,11 +28,15 @@ type ContextManager struct {
 	// Neural cluster integration
 	neuralRetriever *neural.Retriever
 
+	// Passages from the user's documents, given alongside memories
+	documents DocumentSearcher
+
 	// Configuration
-	maxTokens         int // Max tokens for context window
-	maxMessages       int // Max full messages to keep
-	summaryThreshold  int // Messages before summarization kicks in
-	relevanceMessages int // Number of recent messages to always keep
+	tokenizer         *llm.Tokenizer // Counts tokens the way the model does
+	maxTokens         int            // Max prompt tokens, leaving room for the response
+	maxMessages       int            // Max full messages to keep
+	summaryThreshold  int            // Messages before summarization kicks in
+	relevanceMessages int            // Number of recent messages to always keep
 }
 
 // NewContextManager creates a new context manager
@@ -41,6 +46,7 @@ func NewContextManager(store *store.Store, vectorSearcher *vector.Searcher, llmC
 		vectorSearcher:    vectorSearcher,
 		llmClient:         llmClient,
 		logger:            logger,
+		tokenizer:         llm.TokenizerFor(""),
 		maxTokens:         6000, // Leave room for response
 		maxMessages:       50,
 		summaryThreshold:  20,
@@ -54,11 +60,26 @@ func (cm *ContextManager) SetNeuralRetriever(retriever *neural.Retriever) {
 	cm.logger.Info("Neural cluster retriever integrated into context manager")
 }
 
+// SetProvider sizes context for the provider's model: tokens are counted
+// with its tokenizer, and the prompt may fill its context window less room
+// for the response
+func (cm *ContextManager) SetProvider(provider config.Provider) {
+	cm.tokenizer = llm.TokenizerFor(provider.Model)
+	cm.maxTokens = llm.PromptBudget(provider)
+}
+
+// SetDocumentSearcher gives the model passages from the user's documents
+// that match each message, to answer from and cite
+func (cm *ContextManager) SetDocumentSearcher(documents DocumentSearcher) {
+	cm.documents = documents
+}
+
 // ConversationContext represents the built context for a conversation
 type ConversationContext struct {
 	Messages         []llm.Message
 	Summary          string
 	RelevantMemories []MemoryInfo
+	Sources          []Source // retrieved context the answer can cite
 	TotalTokens      int
 }
 
@@ -67,6 +88,7 @@ type MemoryInfo struct {
 	Content   string
 	Type      string
 	Relevance float64
+	CreatedAt time.Time
 }
 
 // BuildContext builds optimized context for a conversation
@@ -81,7 +103,13 @@ func (cm *ContextManager) BuildContext(ctx context.Context, convID string, syste
 		Content: systemPrompt,
 	}
 	result.Messages = append(result.Messages, sysMsg)
-	result.TotalTokens += llm.CountTokens(systemPrompt)
+	result.TotalTokens += cm.tokenizer.CountMessage(sysMsg)
+
+	// Pinned items are always included, independent of summarization
+	if pinMsg, ok := pinnedContextMessage(cm.store, convID); ok {
+		result.Messages = append(result.Messages, pinMsg)
+		result.TotalTokens += cm.tokenizer.CountMessage(pinMsg)
+	}
 
 	// Get message count to decide strategy
 	msgCount, err := cm.store.GetMessageCount(convID)
@@ -90,21 +118,16 @@ func (cm *ContextManager) BuildContext(ctx context.Context, convID string, syste
 		msgCount = 0
 	}
 
-	// Retrieve relevant memories based on current query
-	if currentQuery != "" && cm.vectorSearcher != nil && cm.vectorSearcher.IsEnabled() {
-		memories, err := cm.retrieveRelevantMemories(ctx, currentQuery)
-		if err == nil && len(memories) > 0 {
-			result.RelevantMemories = memories
-			// Inject memories into system prompt or as context message
-			memoryContext := cm.formatMemoriesForContext(memories)
-			if memoryContext != "" {
-				memoryMsg := llm.Message{
-					Role:    "system",
-					Content: "Relevant context from memory:\n" + memoryContext,
-				}
-				result.Messages = append(result.Messages, memoryMsg)
-				result.TotalTokens += llm.CountTokens(memoryMsg.Content)
-			}
+	// Retrieve relevant memories and document passages based on current
+	// query, numbered so the answer can cite them
+	if currentQuery != "" {
+		memories, sources := cm.relevantSources(ctx, currentQuery)
+		result.RelevantMemories = memories
+		if len(sources) > 0 {
+			result.Sources = sources
+			contextMsg := sourcesMessage(sources)
+			result.Messages = append(result.Messages, contextMsg)
+			result.TotalTokens += cm.tokenizer.CountMessage(contextMsg)
 		}
 	}
 
@@ -118,35 +141,46 @@ func (cm *ContextManager) BuildContext(ctx context.Context, convID string, syste
 	return cm.buildFullContext(ctx, convID, result)
 }
 
+// relevantSources finds the memories and document passages relevant to
+// query, numbered so the answer can cite them
+func (cm *ContextManager) relevantSources(ctx context.Context, query string) ([]MemoryInfo, []Source) {
+	var memories []MemoryInfo
+	var sources []Source
+	if cm.vectorSearcher != nil && cm.vectorSearcher.IsEnabled() {
+		found, err := cm.retrieveRelevantMemories(ctx, query)
+		if err == nil && len(found) > 0 {
+			memories = found
+			sources = append(sources, memorySources(found)...)
+		}
+	}
+	if cm.documents != nil {
+		passages, err := cm.documents.SearchDocuments(ctx, query, 3)
+		if err != nil {
+			cm.logger.Warn("Failed to search documents", zap.Error(err))
+		}
+		sources = append(sources, passages...)
+	}
+	return memories, numberSources(sources)
+}
+
+// sourcesMessage builds the system message listing the sources
+func sourcesMessage(sources []Source) llm.Message {
+	return llm.Message{
+		Role: "system",
+		Content: "Relevant context from memory and the user's documents. When your answer uses an item, " +
+			"cite its marker, e.g. [1], right after the statement it supports:\n" + formatSources(sources),
+	}
+}
+
 // buildFullContext builds context with all recent messages
 func (cm *ContextManager) buildFullContext(ctx context.Context, convID string, result *ConversationContext) (*ConversationContext, error) {
-	storeMsgs, err := cm.store.GetMessages(convID, cm.maxMessages, 0)
+	history, err := cm.loadHistory(convID, cm.maxMessages)
 	if err != nil {
 		cm.logger.Warn("Failed to get messages", zap.Error(err))
 		return result, nil
 	}
 
-	for _, msg := range storeMsgs {
-		lmMsg := llm.Message{
-			Role:             msg.Role,
-			Content:          msg.Content,
-			ToolCallID:       msg.ToolCallID,
-			ReasoningContent: msg.ReasoningContent, // Preserve reasoning content for thinking models
-		}
-
-		// Handle tool calls
-		if len(msg.ToolCalls) > 0 {
-			var tcs []llm.ToolCall
-			if err := json.Unmarshal(msg.ToolCalls, &tcs); err == nil {
-				lmMsg.ToolCalls = tcs
-			}
-		}
-
-		result.Messages = append(result.Messages, lmMsg)
-		result.TotalTokens += llm.CountTokens(msg.Content)
-	}
-
-	return result, nil
+	return cm.appendHistory(ctx, convID, result, history), nil
 }
 
 // buildSummarizedContext builds context with summary + recent messages
@@ -163,23 +197,39 @@ func (cm *ContextManager) buildSummarizedContext(ctx context.Context, convID str
 
 	// Add summary as a system message
 	if summary != "" {
-		summaryMsg := llm.Message{
-			Role:    "system",
-			Content: fmt.Sprintf("Previous conversation summary:\n%s", summary),
-		}
+		summaryMsg := summaryMessage(summary)
 		result.Messages = append(result.Messages, summaryMsg)
-		result.TotalTokens += llm.CountTokens(summaryMsg.Content)
+		result.TotalTokens += cm.tokenizer.CountMessage(summaryMsg)
 	}
 
 	// Get recent messages (always keep last N)
-	recentMsgs, err := cm.store.GetMessages(convID, cm.relevanceMessages, 0)
+	recentMsgs, err := cm.loadHistory(convID, cm.relevanceMessages)
 	if err != nil {
 		cm.logger.Warn("Failed to get recent messages", zap.Error(err))
 		return result, nil
 	}
 
-	// Add recent messages in order
-	for _, msg := range recentMsgs {
+	return cm.appendHistory(ctx, convID, result, recentMsgs), nil
+}
+
+// loadHistory returns the conversation's last limit messages, oldest first
+func (cm *ContextManager) loadHistory(convID string, limit int) ([]llm.Message, error) {
+	total, err := cm.store.GetMessageCount(convID)
+	if err != nil {
+		return nil, err
+	}
+	offset := max(int(total)-limit, 0)
+	storeMsgs, err := cm.store.GetMessages(convID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return toLLMMessages(storeMsgs), nil
+}
+
+// toLLMMessages converts stored messages for sending to the model
+func toLLMMessages(storeMsgs []store.Message) []llm.Message {
+	msgs := make([]llm.Message, 0, len(storeMsgs))
+	for _, msg := range storeMsgs {
 		lmMsg := llm.Message{
 			Role:             msg.Role,
 			Content:          msg.Content,
@@ -187,6 +237,7 @@ func (cm *ContextManager) buildSummarizedContext(ctx context.Context, convID str
 			ReasoningContent: msg.ReasoningContent, // Preserve reasoning content for thinking models
 		}
 
+		// Handle tool calls
 		if len(msg.ToolCalls) > 0 {
 			var tcs []llm.ToolCall
 			if err := json.Unmarshal(msg.ToolCalls, &tcs); err == nil {
@@ -194,19 +245,92 @@ func (cm *ContextManager) buildSummarizedContext(ctx context.Context, convID str
 			}
 		}
 
-		result.Messages = append(result.Messages, lmMsg)
-		result.TotalTokens += llm.CountTokens(msg.Content)
+		msgs = append(msgs, lmMsg)
+	}
+	return msgs
+}
 
-		// Check token limit
-		if result.TotalTokens > cm.maxTokens {
+// appendHistory adds as much of the history as fits in the token budget,
+// newest first. What doesn't fit is summarized, unless the context already
+// has a summary.
+func (cm *ContextManager) appendHistory(ctx context.Context, convID string, result *ConversationContext, history []llm.Message) *ConversationContext {
+	start := cm.fitHistory(history, cm.maxTokens-result.TotalTokens)
+	if start > 0 && result.Summary == "" && cm.llmClient != nil {
+		summary, err := cm.summarizeMessages(ctx, convID, history[:start])
+		if err != nil {
+			cm.logger.Warn("Failed to summarize trimmed messages", zap.Error(err))
+		} else if summary != "" {
+			result.Summary = summary
+			summaryMsg := summaryMessage(summary)
+			result.Messages = append(result.Messages, summaryMsg)
+			result.TotalTokens += cm.tokenizer.CountMessage(summaryMsg)
+			start = max(start, cm.fitHistory(history, cm.maxTokens-result.TotalTokens))
+		}
+	}
+	if start > 0 {
+		cm.logger.Debug("Trimmed conversation history to fit the context window",
+			zap.String("conversation_id", convID),
+			zap.Int("dropped", start),
+			zap.Int("kept", len(history)-start),
+			zap.Int("max_tokens", cm.maxTokens),
+		)
+	}
+
+	for _, msg := range history[start:] {
+		result.Messages = append(result.Messages, msg)
+		result.TotalTokens += cm.tokenizer.CountMessage(msg)
+	}
+	return result
+}
+
+// fitHistory returns the index of the oldest message to keep so the rest of
+// the history fits in available tokens. The newest message, normally the
+// one being answered, is always kept, and the kept history never opens with
+// tool results cut off from the call that requested them.
+func (cm *ContextManager) fitHistory(history []llm.Message, available int) int {
+	if len(history) == 0 {
+		return 0
+	}
+	start := len(history) - 1
+	used := cm.tokenizer.CountMessage(history[start])
+	for start > 0 {
+		used += cm.tokenizer.CountMessage(history[start-1])
+		if used > available {
 			break
 		}
+		start--
+	}
+	for start < len(history)-1 && history[start].Role == "tool" {
+		start++
+	}
+	return start
+}
+
+// summaryMessage carries a summary of earlier conversation
+func summaryMessage(summary string) llm.Message {
+	return llm.Message{
+		Role:    "system",
+		Content: fmt.Sprintf("Previous conversation summary:\n%s", summary),
 	}
+}
+
+type projectKey struct{}
 
-	return result, nil
+// WithProject scopes the memories recalled and remembered under ctx to a
+// project, by its ID
+func WithProject(ctx context.Context, project string) context.Context {
+	return context.WithValue(ctx, projectKey{}, project)
 }
 
-// retrieveRelevantMemories searches for memories relevant to the query
+// projectFrom returns the project memories under ctx belong to, or "" for
+// none
+func projectFrom(ctx context.Context) string {
+	project, _ := ctx.Value(projectKey{}).(string)
+	return project
+}
+
+// retrieveRelevantMemories searches for memories relevant to the query,
+// leaving out those of projects other than the one in ctx
 // Uses neural clusters when available for semantic context compression
 func (cm *ContextManager) retrieveRelevantMemories(ctx context.Context, query string) ([]MemoryInfo, error) {
 	// Prefer neural cluster retrieval when available
@@ -219,7 +343,7 @@ func (cm *ContextManager) retrieveRelevantMemories(ctx context.Context, query st
 		return nil, nil // No searcher available
 	}
 
-	results, err := cm.vectorSearcher.Search(query, 5)
+	results, err := cm.vectorSearcher.SearchProject(query, projectFrom(ctx), 5)
 	if err != nil {
 		return nil, err
 	}
@@ -230,6 +354,7 @@ func (cm *ContextManager) retrieveRelevantMemories(ctx context.Context, query st
 			Content:   r.Content,
 			Type:      r.Type,
 			Relevance: r.Similarity,
+			CreatedAt: r.CreatedAt,
 		})
 	}
 
@@ -296,18 +421,7 @@ func (cm *ContextManager) retrieveFromNeuralClusters(ctx context.Context, query
 
 // formatMemoriesForContext formats memories for inclusion in context
 func (cm *ContextManager) formatMemoriesForContext(memories []MemoryInfo) string {
-	if len(memories) == 0 {
-		return ""
-	}
-
-	var parts []string
-	for _, m := range memories {
-		if m.Relevance > 0.7 { // Only high relevance
-			parts = append(parts, fmt.Sprintf("- [%s] %s", m.Type, m.Content))
-		}
-	}
-
-	return strings.Join(parts, "\n")
+	return formatSources(numberSources(memorySources(memories)))
 }
 
 // getOrCreateSummary gets existing summary or creates one
@@ -331,22 +445,36 @@ func (cm *ContextManager) getExistingSummary(convID string) (string, error) {
 
 // generateSummary creates a summary of the conversation
 func (cm *ContextManager) generateSummary(ctx context.Context, convID string) (string, error) {
-	// Get older messages (skip recent ones that we keep in full)
-	offset := cm.relevanceMessages
+	// Summarize the messages before the recent ones that we keep in full
+	total, err := cm.store.GetMessageCount(convID)
+	if err != nil {
+		return "", err
+	}
+	end := int(total) - cm.relevanceMessages
+	if end <= 0 {
+		return "", nil
+	}
 	limit := 30 // Messages to summarize
+	offset := max(end-limit, 0)
 
-	storeMsgs, err := cm.store.GetMessages(convID, limit, offset)
+	storeMsgs, err := cm.store.GetMessages(convID, end-offset, offset)
 	if err != nil {
 		return "", err
 	}
 
-	if len(storeMsgs) == 0 {
+	return cm.summarizeMessages(ctx, convID, toLLMMessages(storeMsgs))
+}
+
+// summarizeMessages asks the model to summarize messages and stores the
+// summary
+func (cm *ContextManager) summarizeMessages(ctx context.Context, convID string, msgs []llm.Message) (string, error) {
+	if len(msgs) == 0 || cm.llmClient == nil {
 		return "", nil
 	}
 
 	// Build conversation text for summarization
 	var convoParts []string
-	for _, msg := range storeMsgs {
+	for _, msg := range msgs {
 		if msg.Role == "user" || msg.Role == "assistant" {
 			convoParts = append(convoParts, fmt.Sprintf("%s: %s", msg.Role, msg.Content))
 		}
@@ -411,7 +539,8 @@ func (cm *ContextManager) storeSummary(convID string, summary string) error {
 	return nil
 }
 
-// ExtractAndStoreMemories extracts memories from a conversation turn
+// ExtractAndStoreMemories extracts memories from a conversation turn. They
+// belong to the project in ctx, if any.
 func (cm *ContextManager) ExtractAndStoreMemories(ctx context.Context, convID string, userMsg, assistantMsg string) error {
 	// Skip if no content
 	if userMsg == "" || assistantMsg == "" {
@@ -469,6 +598,7 @@ Extractions:`, userMsg, assistantMsg)
 			Type:    memType,
 			Content: line,
 			Source:  convID,
+			Project: projectFrom(ctx),
 		}
 
 		if err := cm.store.CreateMemory(mem); err != nil {
@@ -548,7 +678,7 @@ func (cm *ContextManager) PrioritizeMessages(messages []llm.Message, query strin
 	totalTokens := 0
 
 	for _, s := range scored {
-		tokens := llm.CountTokens(s.Message.Content)
+		tokens := cm.tokenizer.CountMessage(s.Message)
 		if totalTokens+tokens > cm.maxTokens && len(result) >= cm.relevanceMessages {
 			break
 		}
@@ -634,7 +764,7 @@ func (cm *ContextManager) GetContextStats(convID string) (map[string]interface{}
 	recentMsgs, _ := cm.store.GetMessages(convID, 20, 0)
 	totalTokens := 0
 	for _, msg := range recentMsgs {
-		totalTokens += llm.CountTokens(msg.Content)
+		totalTokens += cm.tokenizer.Count(msg.Content)
 	}
 
 	return map[string]interface{}{
@@ -3,9 +3,14 @@ package agent
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/config"
 	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
 	"go.uber.org/zap"
 )
 
@@ -404,3 +409,64 @@ func TestContextManager_formatMemoriesForContext_RelevanceThreshold(t *testing.T
 		})
 	}
 }
+
+func TestContextManager_TrimsHistoryToBudget(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+
+	conv := &store.Conversation{Title: "Trip"}
+	if err := st.CreateConversation(conv); err != nil {
+		t.Fatalf("Failed to create conversation: %v", err)
+	}
+	long := strings.Repeat("We talked about the itinerary at length. ", 40)
+	started := time.Now().Add(-time.Hour)
+	for i, msg := range []store.Message{
+		{Role: "user", Content: long},
+		{Role: "assistant", Content: long},
+		{Role: "assistant", ToolCalls: []byte(`[{"id":"call_1","type":"function","function":{"name":"weather","arguments":"{\"notes\":\"` + long + `\"}"}}]`)},
+		{Role: "tool", ToolCallID: "call_1", Content: "Sunny, 24C"},
+		{Role: "assistant", Content: "It will be sunny."},
+		{Role: "user", Content: "Great, what should I pack?"},
+	} {
+		msg.ConversationID = conv.ID
+		msg.CreatedAt = started.Add(time.Duration(i) * time.Minute)
+		if err := st.CreateMessage(&msg); err != nil {
+			t.Fatalf("Failed to create message: %v", err)
+		}
+	}
+
+	cm := NewContextManager(st, nil, nil, zap.NewNop())
+	cm.SetProvider(config.Provider{Model: "gpt-4o"})
+	result, err := cm.BuildContext(context.Background(), conv.ID, "You are helpful.", "")
+	if err != nil {
+		t.Fatalf("BuildContext failed: %v", err)
+	}
+	if len(result.Messages) != 7 {
+		t.Errorf("Expected the whole conversation to fit a large window, got %d messages", len(result.Messages))
+	}
+
+	cm.maxTokens = 200
+	result, err = cm.BuildContext(context.Background(), conv.ID, "You are helpful.", "")
+	if err != nil {
+		t.Fatalf("BuildContext failed: %v", err)
+	}
+	if result.TotalTokens > cm.maxTokens {
+		t.Errorf("Expected at most %d tokens, got %d", cm.maxTokens, result.TotalTokens)
+	}
+	last := result.Messages[len(result.Messages)-1]
+	if last.Content != "Great, what should I pack?" {
+		t.Errorf("Expected the newest message to be kept, got %q", last.Content)
+	}
+	if result.Messages[1].Role == "tool" {
+		t.Error("Expected a tool result not to be kept without its call")
+	}
+	if len(result.Messages) != 3 {
+		t.Errorf("Expected the system prompt and the two short messages, got %d messages", len(result.Messages))
+	}
+
+	cm.maxTokens = 1
+	result, _ = cm.BuildContext(context.Background(), conv.ID, "You are helpful.", "")
+	if len(result.Messages) != 2 {
+		t.Errorf("Expected the message being answered to be kept regardless, got %d messages", len(result.Messages))
+	}
+}
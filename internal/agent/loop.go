@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/config"
 	"github.com/gmsas95/myrai-cli/internal/llm"
 	"go.uber.org/zap"
 )
@@ -23,17 +24,153 @@ type AgentLoop struct {
 	reflectionDepth  int           // How many past actions to consider
 	timeout          time.Duration // Maximum time for autonomous operation
 	requireConfirm   bool          // Whether to require user confirmation for destructive actions
+	maxTokens        int           // Token budget per run (0 = unlimited)
+	maxCost          float64       // Cost budget per run (0 = unlimited)
+	pricePer1K       float64       // Price per 1,000 tokens for cost tracking
+	stopOnToolError  bool          // Stop calling tools after the first tool failure
+	requireFinal     bool          // Validate the final answer before accepting it
+	maxParallelTools int           // Tool calls from one reply run at the same time (0 = unlimited)
+	toolTimeout      time.Duration // Limit per tool call (0 = none)
+}
+
+// Loop stop reasons reported in LoopTelemetry and TaskResult
+const (
+	StopCompleted        = "completed"
+	StopMaxIterations    = "max_iterations"
+	StopTimeout          = "timeout"
+	StopTokenBudget      = "token_budget"
+	StopCostBudget       = "cost_budget"
+	StopToolError        = "tool_error"
+	StopValidationFailed = "validation_failed"
+	StopError            = "error"
+)
+
+// LoopOptions bounds how long the agent may keep working on a request.
+// Zero budgets mean unlimited.
+type LoopOptions struct {
+	MaxIterations      int           // LLM round-trips per request
+	Timeout            time.Duration // Wall-clock limit per request
+	MaxTokens          int           // Token budget per request
+	MaxCost            float64       // Cost budget per request, requires PricePer1K
+	PricePer1K         float64       // Price per 1,000 tokens
+	StopOnToolError    bool          // Answer instead of continuing after a failed tool
+	RequireFinalAnswer bool          // Validate the final answer before returning it
+	MaxParallelTools   int           // Tool calls from one reply run at the same time
+	ToolTimeout        time.Duration // Limit per tool call
+}
+
+// DefaultLoopOptions returns the limits used when nothing is configured
+func DefaultLoopOptions() LoopOptions {
+	return LoopOptions{
+		MaxIterations:    10,
+		Timeout:          5 * time.Minute,
+		MaxParallelTools: 4,
+		ToolTimeout:      2 * time.Minute,
+	}
+}
+
+// LoopOptionsFromConfig converts the agent.loop section of config.yaml
+func LoopOptionsFromConfig(cfg config.LoopConfig) LoopOptions {
+	opts := DefaultLoopOptions()
+	if cfg.MaxIterations > 0 {
+		opts.MaxIterations = cfg.MaxIterations
+	}
+	if cfg.TimeoutSeconds > 0 {
+		opts.Timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	if cfg.MaxParallelTools > 0 {
+		opts.MaxParallelTools = cfg.MaxParallelTools
+	}
+	if cfg.ToolTimeoutSeconds > 0 {
+		opts.ToolTimeout = time.Duration(cfg.ToolTimeoutSeconds) * time.Second
+	}
+	opts.MaxTokens = cfg.MaxTokens
+	opts.MaxCost = cfg.MaxCost
+	opts.PricePer1K = cfg.PricePer1K
+	opts.StopOnToolError = cfg.StopOnToolError
+	opts.RequireFinalAnswer = cfg.RequireFinalAnswer
+	return opts
+}
+
+// LoopOverrides holds per-request changes to the configured loop options.
+// Unset fields keep the configured value.
+type LoopOverrides struct {
+	MaxIterations      *int     `json:"max_iterations,omitempty"`
+	TimeoutSeconds     *int     `json:"timeout_seconds,omitempty"`
+	MaxTokens          *int     `json:"max_tokens,omitempty"`
+	MaxCost            *float64 `json:"max_cost,omitempty"`
+	StopOnToolError    *bool    `json:"stop_on_tool_error,omitempty"`
+	RequireFinalAnswer *bool    `json:"require_final_answer,omitempty"`
+}
+
+// Apply returns base with the overrides applied
+func (o *LoopOverrides) Apply(base LoopOptions) LoopOptions {
+	if o == nil {
+		return base
+	}
+	if o.MaxIterations != nil && *o.MaxIterations > 0 {
+		base.MaxIterations = *o.MaxIterations
+	}
+	if o.TimeoutSeconds != nil && *o.TimeoutSeconds > 0 {
+		base.Timeout = time.Duration(*o.TimeoutSeconds) * time.Second
+	}
+	if o.MaxTokens != nil {
+		base.MaxTokens = *o.MaxTokens
+	}
+	if o.MaxCost != nil {
+		base.MaxCost = *o.MaxCost
+	}
+	if o.StopOnToolError != nil {
+		base.StopOnToolError = *o.StopOnToolError
+	}
+	if o.RequireFinalAnswer != nil {
+		base.RequireFinalAnswer = *o.RequireFinalAnswer
+	}
+	return base
+}
+
+// cost returns the price of the given number of tokens
+func (o LoopOptions) cost(tokens int) float64 {
+	return float64(tokens) / 1000 * o.PricePer1K
+}
+
+// limitReached returns the stop reason for the first exhausted limit, or ""
+func (o LoopOptions) limitReached(iterations, tokens int) string {
+	switch {
+	case o.MaxIterations > 0 && iterations >= o.MaxIterations:
+		return StopMaxIterations
+	case o.MaxTokens > 0 && tokens >= o.MaxTokens:
+		return StopTokenBudget
+	case o.MaxCost > 0 && o.PricePer1K > 0 && o.cost(tokens) >= o.MaxCost:
+		return StopCostBudget
+	}
+	return ""
+}
+
+// LoopTelemetry describes how a request's agent loop ran
+type LoopTelemetry struct {
+	Iterations         int           `json:"iterations"`
+	ToolCalls          int           `json:"tool_calls"`
+	ToolErrors         int           `json:"tool_errors"`
+	TokensUsed         int           `json:"tokens_used"`
+	Cost               float64       `json:"cost,omitempty"`
+	Duration           time.Duration `json:"duration"`
+	StopReason         string        `json:"stop_reason"`
+	ValidationAttempts int           `json:"validation_attempts,omitempty"`
+	Validated          bool          `json:"validated,omitempty"`
 }
 
 // NewAgentLoop creates a new agent loop
 func NewAgentLoop(agent *Agent, logger *zap.Logger) *AgentLoop {
 	return &AgentLoop{
-		agent:           agent,
-		logger:          logger,
-		maxIterations:   10,
-		reflectionDepth: 3,
-		timeout:         5 * time.Minute,
-		requireConfirm:  true,
+		agent:            agent,
+		logger:           logger,
+		maxIterations:    10,
+		reflectionDepth:  3,
+		timeout:          5 * time.Minute,
+		requireConfirm:   true,
+		maxParallelTools: 4,
+		toolTimeout:      2 * time.Minute,
 	}
 }
 
@@ -53,6 +190,8 @@ type TaskResult struct {
 	FinalAnswer string                 `json:"final_answer"`
 	Errors      []string               `json:"errors,omitempty"`
 	Duration    time.Duration          `json:"duration"`
+	TokensUsed  int                    `json:"tokens_used"`
+	StopReason  string                 `json:"stop_reason"`
 }
 
 // Action represents a single action taken by the agent
@@ -89,6 +228,8 @@ func (al *AgentLoop) ExecuteAutonomous(ctx context.Context, task AutonomousTask)
 		select {
 		case <-ctx.Done():
 			result.Errors = append(result.Errors, "Task timed out")
+			result.Iterations = iteration
+			result.StopReason = StopTimeout
 			result.Duration = time.Since(start)
 			return result, nil
 		default:
@@ -100,9 +241,11 @@ func (al *AgentLoop) ExecuteAutonomous(ctx context.Context, task AutonomousTask)
 			zap.String("goal", task.Goal))
 
 		// Get the next action from the LLM
-		action, err := al.getNextAction(ctx, conversationID, systemPrompt, task, result.Actions)
+		action, tokens, err := al.getNextAction(ctx, conversationID, systemPrompt, task, result.Actions)
+		result.TokensUsed += tokens
 		if err != nil {
 			result.Errors = append(result.Errors, fmt.Sprintf("Iteration %d error: %v", iteration, err))
+			result.StopReason = StopError
 			break
 		}
 
@@ -137,14 +280,39 @@ func (al *AgentLoop) ExecuteAutonomous(ctx context.Context, task AutonomousTask)
 			} else {
 				action.ToolResult = toolResult
 			}
+			result.Actions[len(result.Actions)-1].ToolResult = action.ToolResult
+
+			if err != nil && al.stopOnToolError {
+				result.Iterations = iteration
+				result.StopReason = StopToolError
+				result.FinalAnswer = fmt.Sprintf("Stopped after tool %s failed: %v", action.ToolCall.Function.Name, err)
+				result.Duration = time.Since(start)
+				return result, nil
+			}
 
 		case "reflect":
 			// Reflection is just logged, continue
 			al.logger.Debug("Agent reflecting", zap.String("reflection", action.Content))
 
 		case "respond":
+			if al.requireFinal {
+				ok, reason, tokens := al.agent.validateFinalAnswer(ctx, task.Goal, action.Content)
+				result.TokensUsed += tokens
+				if !ok && iteration < al.maxIterations {
+					result.Errors = append(result.Errors, fmt.Sprintf("Iteration %d: final answer rejected: %s", iteration, reason))
+					result.Actions[len(result.Actions)-1].Content += " [rejected: " + reason + "]"
+					continue
+				}
+				if !ok {
+					result.StopReason = StopValidationFailed
+				}
+			}
+
 			// Task is complete
-			result.Success = true
+			result.Success = result.StopReason == ""
+			if result.StopReason == "" {
+				result.StopReason = StopCompleted
+			}
 			result.FinalAnswer = action.Content
 			result.Iterations = iteration
 			result.Duration = time.Since(start)
@@ -153,13 +321,21 @@ func (al *AgentLoop) ExecuteAutonomous(ctx context.Context, task AutonomousTask)
 		default:
 			result.Errors = append(result.Errors, fmt.Sprintf("Unknown action type: %s", action.Type))
 		}
+
+		if reason := al.Options().limitReached(0, result.TokensUsed); reason != "" {
+			result.StopReason = reason
+			break
+		}
 	}
 
-	// Max iterations reached
+	// Max iterations or budget reached
 	result.Iterations = iteration
 	result.Duration = time.Since(start)
-	if result.FinalAnswer == "" {
-		result.FinalAnswer = "Task did not complete within maximum iterations. Last action: " + result.Actions[len(result.Actions)-1].Content
+	if result.StopReason == "" {
+		result.StopReason = StopMaxIterations
+	}
+	if result.FinalAnswer == "" && len(result.Actions) > 0 {
+		result.FinalAnswer = fmt.Sprintf("Task did not complete (%s). Last action: %s", result.StopReason, result.Actions[len(result.Actions)-1].Content)
 	}
 
 	return result, nil
@@ -216,7 +392,8 @@ Respond with JSON in this format:
 }
 
 // getNextAction determines the next action based on current state
-func (al *AgentLoop) getNextAction(ctx context.Context, convID string, systemPrompt string, task AutonomousTask, previousActions []Action) (*Action, error) {
+// along with an estimate of the tokens spent on the request
+func (al *AgentLoop) getNextAction(ctx context.Context, convID string, systemPrompt string, task AutonomousTask, previousActions []Action) (*Action, int, error) {
 	// Build action history for context
 	history := al.formatActionHistory(previousActions)
 
@@ -232,16 +409,17 @@ What is your next action? Respond with JSON.`,
 	// Call LLM
 	resp, err := al.agent.llmClient.SimpleChat(ctx, systemPrompt, prompt)
 	if err != nil {
-		return nil, fmt.Errorf("LLM error: %w", err)
+		return nil, 0, fmt.Errorf("LLM error: %w", err)
 	}
+	tokens := llm.CountTokens(systemPrompt) + llm.CountTokens(prompt) + llm.CountTokens(resp)
 
 	// Parse the response
 	action, err := al.parseActionResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse action: %w", err)
+		return nil, tokens, fmt.Errorf("failed to parse action: %w", err)
 	}
 
-	return action, nil
+	return action, tokens, nil
 }
 
 // formatActionHistory formats previous actions for the prompt
@@ -318,7 +496,7 @@ func (al *AgentLoop) parseActionResponse(response string) (*Action, error) {
 func (al *AgentLoop) executeTool(ctx context.Context, toolCall *llm.ToolCall) (interface{}, error) {
 	// Try skills registry first
 	if al.agent.skillsRegistry != nil {
-		result, err := al.agent.skillsRegistry.ExecuteTool(ctx, toolCall.Function.Name, []byte(toolCall.Function.Arguments))
+		result, err := al.agent.skillsRegistry.ExecuteTool(withCallingAgent(ctx, al.agent), toolCall.Function.Name, []byte(toolCall.Function.Arguments))
 		if err == nil {
 			return result, nil
 		}
@@ -335,7 +513,7 @@ func (al *AgentLoop) executeTool(ctx context.Context, toolCall *llm.ToolCall) (i
 // isDestructive checks if a tool call is potentially destructive
 func (al *AgentLoop) isDestructive(toolCall *llm.ToolCall) bool {
 	destructiveTools := []string{
-		"write_file", "delete_file", "rm", "remove", "drop", "delete",
+		"write_file", "delete_file", "apply_patch", "rm", "remove", "drop", "delete",
 	}
 
 	for _, dt := range destructiveTools {
@@ -367,3 +545,73 @@ func (al *AgentLoop) SetTimeout(timeout time.Duration) {
 func (al *AgentLoop) EnableConfirmation(enable bool) {
 	al.requireConfirm = enable
 }
+
+// Configure applies loop options to the agent loop
+func (al *AgentLoop) Configure(opts LoopOptions) {
+	if opts.MaxIterations > 0 {
+		al.maxIterations = opts.MaxIterations
+	}
+	if opts.Timeout > 0 {
+		al.timeout = opts.Timeout
+	}
+	al.maxTokens = opts.MaxTokens
+	al.maxCost = opts.MaxCost
+	al.pricePer1K = opts.PricePer1K
+	al.stopOnToolError = opts.StopOnToolError
+	al.requireFinal = opts.RequireFinalAnswer
+	al.maxParallelTools = opts.MaxParallelTools
+	al.toolTimeout = opts.ToolTimeout
+}
+
+// Options returns the agent loop's current limits
+func (al *AgentLoop) Options() LoopOptions {
+	return LoopOptions{
+		MaxIterations:      al.maxIterations,
+		Timeout:            al.timeout,
+		MaxTokens:          al.maxTokens,
+		MaxCost:            al.maxCost,
+		PricePer1K:         al.pricePer1K,
+		StopOnToolError:    al.stopOnToolError,
+		RequireFinalAnswer: al.requireFinal,
+		MaxParallelTools:   al.maxParallelTools,
+		ToolTimeout:        al.toolTimeout,
+	}
+}
+
+// validateFinalAnswer asks the model whether answer fully addresses request.
+// It returns the verdict, the model's reason and the tokens spent. Validation
+// errors never block an answer.
+func (a *Agent) validateFinalAnswer(ctx context.Context, request, answer string) (bool, string, int) {
+	if strings.TrimSpace(answer) == "" {
+		return false, "the answer is empty", 0
+	}
+
+	resp, err := a.llmClient.ChatCompletion(ctx, llm.ChatRequest{
+		Model: a.llmClient.GetModel(),
+		Messages: []llm.Message{
+			{Role: "system", Content: `You review an assistant's final answer before it is sent to the user.
+Reply with JSON only: {"complete": true|false, "reason": "short explanation"}.
+An answer is complete when it directly and fully addresses the request. Do not judge style.`},
+			{Role: "user", Content: fmt.Sprintf("Request:\n%s\n\nAnswer:\n%s", request, answer)},
+		},
+		MaxTokens: 200,
+	})
+	if err != nil || len(resp.Choices) == 0 {
+		a.logger.Warn("Final answer validation failed, accepting answer", zap.Error(err))
+		return true, "", 0
+	}
+
+	content := resp.Choices[0].Message.Content
+	if i, j := strings.Index(content, "{"), strings.LastIndex(content, "}"); i >= 0 && j > i {
+		content = content[i : j+1]
+	}
+
+	var verdict struct {
+		Complete bool   `json:"complete"`
+		Reason   string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(content), &verdict); err != nil {
+		return true, "", resp.Usage.TotalTokens
+	}
+	return verdict.Complete, verdict.Reason, resp.Usage.TotalTokens
+}
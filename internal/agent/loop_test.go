@@ -2,10 +2,23 @@
 package agent
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/config"
 	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
 	"go.uber.org/zap"
 )
 
@@ -381,6 +394,441 @@ func containsSubHelper(s, substr string) bool {
 	return false
 }
 
+func TestLoopOptionsFromConfig(t *testing.T) {
+	opts := LoopOptionsFromConfig(config.LoopConfig{})
+	if opts.MaxIterations != 10 || opts.Timeout != 5*time.Minute || opts.MaxParallelTools != 4 || opts.ToolTimeout != 2*time.Minute {
+		t.Errorf("Expected defaults for empty config, got %+v", opts)
+	}
+
+	opts = LoopOptionsFromConfig(config.LoopConfig{
+		MaxIterations:      3,
+		TimeoutSeconds:     30,
+		MaxTokens:          1000,
+		StopOnToolError:    true,
+		MaxParallelTools:   1,
+		ToolTimeoutSeconds: 10,
+	})
+	if opts.MaxIterations != 3 || opts.Timeout != 30*time.Second || opts.MaxTokens != 1000 || !opts.StopOnToolError ||
+		opts.MaxParallelTools != 1 || opts.ToolTimeout != 10*time.Second {
+		t.Errorf("Config not applied: %+v", opts)
+	}
+}
+
+func TestLoopOverrides_Apply(t *testing.T) {
+	base := LoopOptions{MaxIterations: 10, MaxTokens: 5000, RequireFinalAnswer: true}
+
+	var nilOverrides *LoopOverrides
+	if got := nilOverrides.Apply(base); got != base {
+		t.Errorf("Nil overrides changed options: %+v", got)
+	}
+
+	iterations, timeout, final := 2, 15, false
+	got := (&LoopOverrides{MaxIterations: &iterations, TimeoutSeconds: &timeout, RequireFinalAnswer: &final}).Apply(base)
+	if got.MaxIterations != 2 || got.Timeout != 15*time.Second || got.RequireFinalAnswer {
+		t.Errorf("Overrides not applied: %+v", got)
+	}
+	if got.MaxTokens != 5000 {
+		t.Errorf("Unset override changed MaxTokens: %d", got.MaxTokens)
+	}
+}
+
+func TestLoopOptions_LimitReached(t *testing.T) {
+	opts := LoopOptions{MaxIterations: 3, MaxTokens: 1000, MaxCost: 0.05, PricePer1K: 0.1}
+
+	tests := []struct {
+		iterations int
+		tokens     int
+		want       string
+	}{
+		{1, 100, ""},
+		{3, 100, StopMaxIterations},
+		{1, 1000, StopTokenBudget},
+		{1, 600, StopCostBudget},
+	}
+	for _, tt := range tests {
+		if got := opts.limitReached(tt.iterations, tt.tokens); got != tt.want {
+			t.Errorf("limitReached(%d, %d) = %q, want %q", tt.iterations, tt.tokens, got, tt.want)
+		}
+	}
+}
+
+func TestAgentLoop_Configure(t *testing.T) {
+	logger := zap.NewNop()
+	loop := NewAgentLoop(&Agent{logger: logger}, logger)
+
+	opts := LoopOptions{MaxIterations: 4, Timeout: time.Minute, MaxTokens: 200, StopOnToolError: true, RequireFinalAnswer: true}
+	loop.Configure(opts)
+
+	if got := loop.Options(); got != opts {
+		t.Errorf("Options() = %+v, want %+v", got, opts)
+	}
+}
+
+// newLoopTestAgent creates an agent whose fake LLM keeps calling the lookup
+// tool while tools are offered and answers once they are withheld
+func newLoopTestAgent(t *testing.T, toolErr error) *Agent {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		msg := llm.Message{Role: "assistant"}
+		if len(req.Tools) > 0 {
+			msg.ToolCalls = []llm.ToolCall{{ID: fmt.Sprintf("call_%d", len(req.Messages)), Type: "function"}}
+			msg.ToolCalls[0].Function.Name = "lookup"
+			msg.ToolCalls[0].Function.Arguments = "{}"
+		} else {
+			msg.Content = "final answer"
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": msg}},
+			"usage":   map[string]int{"total_tokens": 100},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	st := testutil.NewTestStore(t)
+	t.Cleanup(func() { st.Close() })
+
+	a := New(llm.NewClient(config.Provider{BaseURL: server.URL, Model: "test"}), nil, st, zap.NewNop(), nil)
+
+	registry := skills.NewRegistry(nil)
+	skill := skills.NewBaseSkill("test", "Test tools", "1.0.0")
+	skill.AddTool(skills.Tool{
+		Name:        "lookup",
+		Description: "Look something up",
+		Parameters:  map[string]interface{}{"type": "object"},
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return "result", toolErr
+		},
+	})
+	registry.Register(skill)
+	a.SetSkillsRegistry(registry)
+
+	return a
+}
+
+func TestAgent_ChatLoopLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       LoopOptions
+		toolErr    error
+		stopReason string
+		iterations int
+		toolCalls  int
+	}{
+		{"max iterations", LoopOptions{MaxIterations: 3}, nil, StopMaxIterations, 3, 2},
+		{"token budget", LoopOptions{MaxIterations: 10, MaxTokens: 250}, nil, StopTokenBudget, 3, 2},
+		{"cost budget", LoopOptions{MaxIterations: 10, MaxCost: 0.01, PricePer1K: 0.05}, nil, StopCostBudget, 2, 1},
+		{"stop on tool error", LoopOptions{MaxIterations: 10, StopOnToolError: true}, fmt.Errorf("boom"), StopToolError, 2, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newLoopTestAgent(t, tt.toolErr)
+
+			resp, err := a.Chat(context.Background(), ChatRequest{Message: "look it up", Loop: &tt.opts})
+			if err != nil {
+				t.Fatalf("Chat failed: %v", err)
+			}
+			if resp.Loop == nil {
+				t.Fatal("Expected loop telemetry")
+			}
+			if resp.Loop.StopReason != tt.stopReason {
+				t.Errorf("Expected stop reason %s, got %s", tt.stopReason, resp.Loop.StopReason)
+			}
+			if resp.Loop.Iterations != tt.iterations {
+				t.Errorf("Expected %d iterations, got %d", tt.iterations, resp.Loop.Iterations)
+			}
+			if resp.Loop.ToolCalls != tt.toolCalls {
+				t.Errorf("Expected %d tool calls, got %d", tt.toolCalls, resp.Loop.ToolCalls)
+			}
+			if resp.TokensUsed != resp.Loop.TokensUsed {
+				t.Errorf("TokensUsed %d does not match telemetry %d", resp.TokensUsed, resp.Loop.TokensUsed)
+			}
+		})
+	}
+}
+
+func TestAgent_ToolImages(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 16))
+	path := filepath.Join(t.TempDir(), "shot.png")
+	if err := os.WriteFile(path, png, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, vision := range []bool{true, false} {
+		t.Run(fmt.Sprintf("vision=%t", vision), func(t *testing.T) {
+			var sawImage, shown bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				var req llm.ChatRequest
+				json.Unmarshal(body, &req)
+
+				msg := llm.Message{Role: "assistant", Content: "done"}
+				if len(req.Messages) <= 2 {
+					msg.Content = ""
+					msg.ToolCalls = []llm.ToolCall{{ID: "call_1", Type: "function"}}
+					msg.ToolCalls[0].Function.Name = "screenshot"
+					msg.ToolCalls[0].Function.Arguments = "{}"
+				} else if strings.Contains(string(body), "data:image/png;base64,") {
+					sawImage = true
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"choices": []map[string]interface{}{{"message": msg}},
+				})
+			}))
+			defer server.Close()
+
+			st := testutil.NewTestStore(t)
+			defer st.Close()
+			a := New(llm.NewClient(config.Provider{BaseURL: server.URL, Model: "test", Vision: vision}), nil, st, zap.NewNop(), nil)
+
+			registry := skills.NewRegistry(nil)
+			skill := skills.NewBaseSkill("test", "Test tools", "1.0.0")
+			skill.AddTool(skills.Tool{
+				Name:       "screenshot",
+				Parameters: map[string]interface{}{"type": "object"},
+				Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+					shown = skills.ShowImage(ctx, path)
+					return "captured", nil
+				},
+			})
+			registry.Register(skill)
+			a.SetSkillsRegistry(registry)
+
+			if _, err := a.Chat(context.Background(), ChatRequest{Message: "take a look"}); err != nil {
+				t.Fatalf("Chat failed: %v", err)
+			}
+			if shown != vision {
+				t.Errorf("ShowImage reported %t, want %t", shown, vision)
+			}
+			if sawImage != vision {
+				t.Errorf("image sent to the model: %t, want %t", sawImage, vision)
+			}
+		})
+	}
+}
+
+func TestAgent_ChatStreamRunsTools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		if req.Messages[len(req.Messages)-1].Role != "tool" {
+			fmt.Fprint(w, `data: {"choices":[{"delta":{"content":"Checking. "}}]}`+"\n\n")
+			fmt.Fprint(w, `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{}"}}]}}]}`+"\n\n")
+			fmt.Fprint(w, `data: {"choices":[{"delta":{},"finish_reason":"tool_calls"}]}`+"\n\n")
+		} else {
+			fmt.Fprint(w, `data: {"choices":[{"delta":{"content":"It's 42."}}]}`+"\n\n")
+			fmt.Fprint(w, `data: {"choices":[{"delta":{},"finish_reason":"stop"}]}`+"\n\n")
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+	a := New(llm.NewClient(config.Provider{BaseURL: server.URL, Model: "test"}), nil, st, zap.NewNop(), nil)
+
+	registry := skills.NewRegistry(nil)
+	skill := skills.NewBaseSkill("test", "Test tools", "1.0.0")
+	skill.AddTool(skills.Tool{
+		Name:       "lookup",
+		Parameters: map[string]interface{}{"type": "object"},
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return "42", nil
+		},
+	})
+	registry.Register(skill)
+	a.SetSkillsRegistry(registry)
+
+	var streamed strings.Builder
+	var executing []string
+	resp, err := a.Chat(context.Background(), ChatRequest{
+		Message:         "look it up",
+		Stream:          true,
+		OnStream:        func(chunk string) { streamed.WriteString(chunk) },
+		OnToolExecuting: func(name string) { executing = append(executing, name) },
+	})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if resp.Content != "It's 42." {
+		t.Errorf("Expected the answer after the tool ran, got %q", resp.Content)
+	}
+	if streamed.String() != "Checking. It's 42." {
+		t.Errorf("Unexpected streamed text %q", streamed.String())
+	}
+	if len(executing) != 1 || executing[0] != "lookup" {
+		t.Errorf("Expected lookup to run, got %v", executing)
+	}
+	if resp.Loop.ToolCalls != 1 || resp.ConversationID == "" {
+		t.Errorf("Unexpected response: %d tool calls, conversation %q", resp.Loop.ToolCalls, resp.ConversationID)
+	}
+}
+
+func TestAgent_ParallelToolCalls(t *testing.T) {
+	calls := []string{"slow_a", "slow_b", "slow_c", "write_file", "slow_write", "stuck"}
+	readOnly := map[string]bool{"slow_a": true, "slow_b": true, "slow_c": true, "stuck": true}
+	var toolResults []llm.Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		msg := llm.Message{Role: "assistant", Content: "done"}
+		if last := req.Messages[len(req.Messages)-1]; last.Role == "tool" {
+			for _, m := range req.Messages {
+				if m.Role == "tool" {
+					toolResults = append(toolResults, m)
+				}
+			}
+		} else {
+			msg.Content = ""
+			for i, name := range calls {
+				tc := llm.ToolCall{ID: fmt.Sprintf("call_%d", i), Type: "function"}
+				tc.Function.Name = name
+				tc.Function.Arguments = "{}"
+				msg.ToolCalls = append(msg.ToolCalls, tc)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": msg}},
+		})
+	}))
+	defer server.Close()
+
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+	a := New(llm.NewClient(config.Provider{BaseURL: server.URL, Model: "test"}), nil, st, zap.NewNop(), nil)
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	writing, writeOverlapped := false, false
+	track := func(name string, d time.Duration) {
+		mu.Lock()
+		if running > 0 && (writing || !readOnly[name]) {
+			writeOverlapped = true
+		}
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		writing = !readOnly[name]
+		mu.Unlock()
+		time.Sleep(d)
+		mu.Lock()
+		running--
+		writing = false
+		mu.Unlock()
+	}
+
+	registry := skills.NewRegistry(nil)
+	skill := skills.NewBaseSkill("test", "Test tools", "1.0.0")
+	for _, name := range calls {
+		name := name
+		skill.AddTool(skills.Tool{
+			Name:       name,
+			Parameters: map[string]interface{}{"type": "object"},
+			ReadOnly:   readOnly[name],
+			Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				switch name {
+				case "stuck":
+					time.Sleep(time.Second)
+					return "too late", nil
+				case "slow_write":
+					// Outlasts the timeout, but is waited for
+					track(name, 300*time.Millisecond)
+				default:
+					track(name, 50*time.Millisecond)
+				}
+				return "result of " + name, nil
+			},
+		})
+	}
+	registry.Register(skill)
+	a.SetSkillsRegistry(registry)
+
+	opts := LoopOptions{MaxIterations: 5, MaxParallelTools: 2, ToolTimeout: 200 * time.Millisecond}
+	resp, err := a.Chat(context.Background(), ChatRequest{Message: "do it all", Loop: &opts})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	if maxRunning != 2 {
+		t.Errorf("Expected 2 tools at a time, got %d", maxRunning)
+	}
+	if writeOverlapped {
+		t.Error("Expected tools not marked read-only to run on their own")
+	}
+	if len(toolResults) != len(calls) {
+		t.Fatalf("Expected %d tool results, got %d", len(calls), len(toolResults))
+	}
+	for i, m := range toolResults[:5] {
+		if m.ToolCallID != fmt.Sprintf("call_%d", i) || m.Content != "result of "+calls[i] {
+			t.Errorf("Result %d out of order: %s %q", i, m.ToolCallID, m.Content)
+		}
+	}
+	if !strings.Contains(toolResults[5].Content, "timed out") {
+		t.Errorf("Expected the stuck tool to time out, got %q", toolResults[5].Content)
+	}
+	if resp.Loop.ToolCalls != len(calls) || resp.Loop.ToolErrors != 1 {
+		t.Errorf("Expected %d calls with 1 error, got %+v", len(calls), resp.Loop)
+	}
+}
+
+func TestAgent_ToolsRunOneByOneUnlessReadOnly(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+	a := New(llm.NewClient(config.Provider{BaseURL: "http://127.0.0.1:0", Model: "test"}), nil, st, zap.NewNop(), nil)
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	registry := skills.NewRegistry(nil)
+	skill := skills.NewBaseSkill("test", "Test tools", "1.0.0")
+	for _, name := range []string{"untagged_a", "untagged_b"} {
+		skill.AddTool(skills.Tool{
+			Name:       name,
+			Parameters: map[string]interface{}{"type": "object"},
+			Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				mu.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+				time.Sleep(20 * time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return "ok", nil
+			},
+		})
+	}
+	registry.Register(skill)
+	a.SetSkillsRegistry(registry)
+
+	var calls []llm.ToolCall
+	for i, name := range []string{"untagged_a", "untagged_b", "untagged_a"} {
+		tc := llm.ToolCall{ID: fmt.Sprintf("call_%d", i), Type: "function"}
+		tc.Function.Name = name
+		tc.Function.Arguments = "{}"
+		calls = append(calls, tc)
+	}
+	outcomes := a.runToolCalls(context.Background(), calls, LoopOptions{MaxParallelTools: 4})
+
+	if maxRunning != 1 {
+		t.Errorf("Expected tools not marked read-only to run one at a time, got %d at once", maxRunning)
+	}
+	for i, out := range outcomes {
+		if out.err != nil || out.result != "ok" {
+			t.Errorf("Unexpected outcome %d: %v %v", i, out.result, out.err)
+		}
+	}
+}
+
 // Benchmark tests
 func BenchmarkParseActionResponse(b *testing.B) {
 	logger, _ := zap.NewDevelopment()
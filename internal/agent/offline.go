@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/skills"
+)
+
+// SetNetworkSkills names the skills that need the internet. They are
+// switched off while the network is down.
+func (a *Agent) SetNetworkSkills(names []string) {
+	a.networkSkills = make(map[string]bool, len(names))
+	for _, name := range names {
+		a.networkSkills[name] = true
+	}
+}
+
+// Offline reports whether the network is down, so a local model answers.
+// It is never true without an offline fallback, see
+// llm.Client.SetOfflineFallback.
+func (a *Agent) Offline() bool {
+	return a.llmClient != nil && a.llmClient.Offline()
+}
+
+// offlineScope keeps the tools of network skills from running while
+// offline, telling the model why
+func (a *Agent) offlineScope(ctx context.Context) context.Context {
+	if len(a.networkSkills) == 0 || !a.Offline() {
+		return ctx
+	}
+	return skills.WithToolFilter(ctx, func(skill, tool string) error {
+		if a.networkSkills[skill] {
+			return fmt.Errorf("%s needs the internet, which can't be reached right now", skill)
+		}
+		return nil
+	})
+}
+
+// unavailableSkills lists the loaded network skills, by name
+func (a *Agent) unavailableSkills() []string {
+	if a.skillsRegistry == nil {
+		return nil
+	}
+	var names []string
+	for _, skill := range a.skillsRegistry.ListSkills() {
+		if a.networkSkills[skill.Name()] && skill.IsEnabled() {
+			names = append(names, skill.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// offlinePrompt tells the model it is standing in while the network is down
+func (a *Agent) offlinePrompt() string {
+	prompt := "## Offline\n\nThe network is unreachable, so you are a local model standing in until it returns. " +
+		"Work with what is on this machine"
+	if names := a.unavailableSkills(); len(names) > 0 {
+		prompt += "; these skills are switched off: " + strings.Join(names, ", ")
+	}
+	return prompt + ". If a request needs the internet, say it will have to wait until the connection is back."
+}
+
+// offlineNotice tells the user a reply comes from the local model
+func (a *Agent) offlineNotice() string {
+	notice := fmt.Sprintf("📴 Offline: answering with %s on this machine.", a.llmClient.GetModel())
+	if names := a.unavailableSkills(); len(names) > 0 {
+		notice += " Until the connection is back, " + strings.Join(names, ", ") + " can't be used."
+	}
+	return notice
+}
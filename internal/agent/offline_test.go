@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"go.uber.org/zap"
+)
+
+// switchConnectivity is online until switched off
+type switchConnectivity struct{ offline bool }
+
+func (s *switchConnectivity) Online() bool        { return !s.offline }
+func (s *switchConnectivity) ReportFailure(error) {}
+
+// offlineTestServer answers as name, recording the tools offered and the
+// system prompt of each request
+func offlineTestServer(t *testing.T, name string, tools *[]string, prompt *string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		*tools = (*tools)[:0]
+		for _, tool := range req.Tools {
+			*tools = append(*tools, tool.Function.Name)
+		}
+		*prompt = req.Messages[0].Content
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": llm.Message{Role: "assistant", Content: "from " + name}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAgent_Offline(t *testing.T) {
+	var tools []string
+	var prompt string
+	remote := offlineTestServer(t, "remote", &tools, &prompt)
+	local := offlineTestServer(t, "local", &tools, &prompt)
+
+	st := testutil.NewTestStore(t)
+	t.Cleanup(func() { st.Close() })
+
+	conn := &switchConnectivity{}
+	client := llm.NewClient(config.Provider{BaseURL: remote.URL, Model: "gpt-4o"})
+	client.SetOfflineFallback(llm.NewClient(config.Provider{BaseURL: local.URL, Model: "llama3.2"}), conn)
+	a := New(client, nil, st, zap.NewNop(), nil)
+
+	registry := skills.NewRegistry(nil)
+	for _, name := range []string{"search", "notes"} {
+		skill := skills.NewBaseSkill(name, name, "1.0.0")
+		skill.AddTool(skills.Tool{
+			Name:       name + "_tool",
+			Parameters: map[string]interface{}{"type": "object"},
+			Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				return "ok", nil
+			},
+		})
+		registry.Register(skill)
+	}
+	a.SetSkillsRegistry(registry)
+	a.SetNetworkSkills([]string{"search", "weather"})
+
+	chat := func() *ChatResponse {
+		t.Helper()
+		resp, err := a.Chat(context.Background(), ChatRequest{Message: "What's on my notes?"})
+		if err != nil {
+			t.Fatalf("Chat failed: %v", err)
+		}
+		return resp
+	}
+
+	if resp := chat(); resp.Offline || resp.Content != "from remote" || len(tools) != 2 {
+		t.Errorf("Expected the remote model with every tool while online, got %q with %v", resp.Content, tools)
+	}
+
+	conn.offline = true
+	resp := chat()
+	if !resp.Offline || resp.Model != "llama3.2" {
+		t.Errorf("Expected the local model to answer, got %s", resp.Model)
+	}
+	if !strings.HasPrefix(resp.Content, "📴 Offline: answering with llama3.2") || !strings.Contains(resp.Content, "search can't be used") ||
+		!strings.HasSuffix(resp.Content, "from local") {
+		t.Errorf("Expected the offline notice before the answer, got %q", resp.Content)
+	}
+	if len(tools) != 1 || tools[0] != "notes_tool" {
+		t.Errorf("Expected only local tools offered, got %v", tools)
+	}
+	if !strings.Contains(prompt, "## Offline") {
+		t.Errorf("Expected the model told it is offline, got %q", prompt)
+	}
+	var call llm.ToolCall
+	call.Function.Name, call.Function.Arguments = "search_tool", "{}"
+	if _, err := a.callTool(a.offlineScope(context.Background()), call); err == nil {
+		t.Error("Expected network tools refused while offline")
+	}
+
+	conn.offline = false
+	if resp := chat(); resp.Offline || resp.Content != "from remote" {
+		t.Errorf("Expected the remote model once the network is back, got %q", resp.Content)
+	}
+}
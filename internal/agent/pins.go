@@ -0,0 +1,193 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/strutil"
+)
+
+// DefaultPinBudget is the maximum number of tokens all pins in a single
+// conversation may consume
+const DefaultPinBudget = 2000
+
+// Pin kinds
+const (
+	PinKindFact       = "fact"
+	PinKindFile       = "file"
+	PinKindToolOutput = "tool_output"
+)
+
+// Pin adds a context item to a conversation. Pins are always injected into the
+// model context and are rejected once the conversation's pin budget is spent.
+func (a *Agent) Pin(convID, kind, label, content string) (*store.Pin, error) {
+	if convID == "" {
+		return nil, fmt.Errorf("no active conversation to pin to")
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, fmt.Errorf("nothing to pin")
+	}
+
+	pins, err := a.store.ListPins(convID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pins: %w", err)
+	}
+
+	used := 0
+	for _, p := range pins {
+		used += p.Tokens
+	}
+
+	tokens := llm.CountTokens(content)
+	if used+tokens > a.PinBudget() {
+		return nil, fmt.Errorf("pin budget exceeded (%d/%d tokens used, item needs %d); remove a pin first", used, a.PinBudget(), tokens)
+	}
+
+	pin := &store.Pin{
+		ConversationID: convID,
+		Kind:           kind,
+		Label:          label,
+		Content:        content,
+		Tokens:         tokens,
+	}
+	if err := a.store.CreatePin(pin); err != nil {
+		return nil, fmt.Errorf("failed to save pin: %w", err)
+	}
+	return pin, nil
+}
+
+// PinFromArgs resolves a /pin command argument string into a pin. Supported
+// forms are "file <id|name>", "tool" (the latest tool output) and free text.
+func (a *Agent) PinFromArgs(convID, args string) (*store.Pin, error) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("usage: /pin <text> | /pin file <id|name> | /pin tool")
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "file":
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("usage: /pin file <id|name>")
+		}
+		file, err := a.findFile(strings.Join(fields[1:], " "))
+		if err != nil {
+			return nil, err
+		}
+		content := file.ProcessedText
+		if content == "" {
+			return nil, fmt.Errorf("file %s has no extracted text to pin", file.Filename)
+		}
+		return a.Pin(convID, PinKindFile, file.Filename, content)
+
+	case "tool":
+		msg, err := a.store.GetLatestMessage(convID, "tool")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load conversation: %w", err)
+		}
+		if msg == nil {
+			return nil, fmt.Errorf("no tool output in this conversation yet")
+		}
+		return a.Pin(convID, PinKindToolOutput, "tool output", msg.Content)
+	}
+
+	return a.Pin(convID, PinKindFact, "", args)
+}
+
+// Pins returns the pins of a conversation
+func (a *Agent) Pins(convID string) ([]store.Pin, error) {
+	return a.store.ListPins(convID)
+}
+
+// Unpin removes a pin by ID, or by its 1-based position in the pin list
+func (a *Agent) Unpin(convID, ref string) error {
+	if ref == "all" {
+		return a.store.ClearPins(convID)
+	}
+
+	pins, err := a.store.ListPins(convID)
+	if err != nil {
+		return err
+	}
+	var n int
+	if _, err := fmt.Sscanf(ref, "%d", &n); err == nil && n >= 1 && n <= len(pins) {
+		ref = pins[n-1].ID
+	}
+	return a.store.DeletePin(convID, ref)
+}
+
+// FormatPins renders a conversation's pins for display in a channel
+func FormatPins(pins []store.Pin, budget int) string {
+	if len(pins) == 0 {
+		return "📌 No pinned items. Use /pin <text> to pin something."
+	}
+
+	var sb strings.Builder
+	used := 0
+	sb.WriteString("📌 Pinned items\n\n")
+	for i, p := range pins {
+		preview := strutil.Truncate(p.Content, 80)
+		label := p.Kind
+		if p.Label != "" {
+			label = p.Kind + ": " + p.Label
+		}
+		sb.WriteString(fmt.Sprintf("%d. [%s] %s (%d tokens)\n", i+1, label, preview, p.Tokens))
+		used += p.Tokens
+	}
+	sb.WriteString(fmt.Sprintf("\nBudget: %d/%d tokens. Use /unpin <number|all> to remove.", used, budget))
+	return sb.String()
+}
+
+// SetPinBudget overrides the per-conversation pin token budget, set by
+// agent.pin_budget; 0 keeps DefaultPinBudget
+func (a *Agent) SetPinBudget(tokens int) {
+	a.pinTokenBudget = tokens
+}
+
+// PinBudget returns the pin token budget per conversation
+func (a *Agent) PinBudget() int {
+	if a.pinTokenBudget > 0 {
+		return a.pinTokenBudget
+	}
+	return DefaultPinBudget
+}
+
+func (a *Agent) findFile(ref string) (*store.File, error) {
+	if file, err := a.store.GetFile(ref); err == nil {
+		return file, nil
+	}
+	files, err := a.store.ListAllFiles(100, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	for i := range files {
+		if strings.EqualFold(files[i].Filename, ref) {
+			return &files[i], nil
+		}
+	}
+	return nil, fmt.Errorf("file not found: %s", ref)
+}
+
+// pinnedContextMessage builds the system message carrying a conversation's pins
+func pinnedContextMessage(st *store.Store, convID string) (llm.Message, bool) {
+	if st == nil || convID == "" {
+		return llm.Message{}, false
+	}
+	pins, err := st.ListPins(convID)
+	if err != nil || len(pins) == 0 {
+		return llm.Message{}, false
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Pinned context (always relevant to this conversation):\n")
+	for _, p := range pins {
+		if p.Label != "" {
+			sb.WriteString(fmt.Sprintf("- [%s: %s] %s\n", p.Kind, p.Label, p.Content))
+		} else {
+			sb.WriteString(fmt.Sprintf("- [%s] %s\n", p.Kind, p.Content))
+		}
+	}
+	return llm.Message{Role: "system", Content: sb.String()}, true
+}
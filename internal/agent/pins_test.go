@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"go.uber.org/zap"
+)
+
+func TestAgent_PinLifecycle(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+
+	conv := &store.Conversation{Title: "Pins"}
+	if err := st.CreateConversation(conv); err != nil {
+		t.Fatalf("Failed to create conversation: %v", err)
+	}
+
+	a := New(nil, nil, st, zap.NewNop(), nil)
+	a.SetPinBudget(20)
+
+	if _, err := a.PinFromArgs(conv.ID, "The staging DB is on port 5433"); err != nil {
+		t.Fatalf("Failed to pin fact: %v", err)
+	}
+
+	if _, err := a.PinFromArgs(conv.ID, "tool"); err == nil {
+		t.Error("Expected error pinning tool output with no tool messages")
+	}
+
+	if _, err := a.Pin(conv.ID, PinKindFact, "", strings.Repeat("x", 200)); err == nil {
+		t.Error("Expected pin budget to be enforced")
+	}
+
+	msg, ok := pinnedContextMessage(st, conv.ID)
+	if !ok {
+		t.Fatal("Expected pinned context message")
+	}
+	if !strings.Contains(msg.Content, "port 5433") {
+		t.Errorf("Pinned context missing fact: %q", msg.Content)
+	}
+
+	pins, err := a.Pins(conv.ID)
+	if err != nil || len(pins) != 1 {
+		t.Fatalf("Expected 1 pin, got %d (err=%v)", len(pins), err)
+	}
+
+	if err := a.Unpin(conv.ID, "1"); err != nil {
+		t.Fatalf("Failed to unpin: %v", err)
+	}
+	if _, ok := pinnedContextMessage(st, conv.ID); ok {
+		t.Error("Expected no pinned context after unpin")
+	}
+}
+
+func TestAgent_PinLatestToolOutput(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+
+	conv := &store.Conversation{Title: "Pins"}
+	if err := st.CreateConversation(conv); err != nil {
+		t.Fatalf("Failed to create conversation: %v", err)
+	}
+
+	// More messages than one page, so the newest tool output is not among
+	// the oldest
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 250; i++ {
+		msg := &store.Message{ConversationID: conv.ID, Role: "tool", Content: fmt.Sprintf("output %d", i), CreatedAt: start.Add(time.Duration(i) * time.Second)}
+		if i%2 == 1 {
+			msg.Role = "assistant"
+		}
+		if err := st.CreateMessage(msg); err != nil {
+			t.Fatalf("Failed to create message: %v", err)
+		}
+	}
+
+	a := New(nil, nil, st, zap.NewNop(), nil)
+	pin, err := a.PinFromArgs(conv.ID, "tool")
+	if err != nil {
+		t.Fatalf("Failed to pin tool output: %v", err)
+	}
+	if pin.Content != "output 248" {
+		t.Errorf("Expected the newest tool output, got %q", pin.Content)
+	}
+}
+
+func TestFormatPins_TruncatesByRune(t *testing.T) {
+	out := FormatPins([]store.Pin{{Kind: PinKindFact, Content: strings.Repeat("日本", 50), Tokens: 10}}, 100)
+	if !utf8.ValidString(out) {
+		t.Errorf("Expected valid UTF-8, got %q", out)
+	}
+	if !strings.Contains(out, strings.Repeat("日本", 38)+"日...") {
+		t.Errorf("Expected the preview cut at 77 characters, got %q", out)
+	}
+}
@@ -0,0 +1,275 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/journal"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/strutil"
+	"go.uber.org/zap"
+)
+
+// RunTaskPlanTool is the name of the tool that starts executing a saved plan
+const RunTaskPlanTool = "run_task_plan"
+
+// maxStepResultChars bounds how many characters of each earlier step's
+// result are repeated in the prompt for the next step
+const maxStepResultChars = 400
+
+// runningPlans guards against executing the same plan twice at once
+var runningPlans sync.Map
+
+// RunPlan executes the remaining steps of a saved plan one at a time, each as
+// a chat turn in convID. An empty convID continues the conversation the plan
+// last ran in. The plan's status is re-read before every step, so pausing it
+// from another process stops the run after the current step. Cancelling ctx
+// pauses the plan and leaves the interrupted step to be retried on resume.
+// onStep, if set, is called after each step finishes.
+func (a *Agent) RunPlan(ctx context.Context, planID, convID string, onStep func(store.PlanStep)) (*store.Plan, error) {
+	if _, busy := runningPlans.LoadOrStore(planID, true); busy {
+		return nil, fmt.Errorf("plan %s is already running", planID)
+	}
+	defer runningPlans.Delete(planID)
+
+	plan, err := a.store.GetPlan(planID)
+	if err != nil {
+		return nil, fmt.Errorf("plan not found: %s", planID)
+	}
+	if plan.Status == store.PlanStatusCompleted {
+		return plan, nil
+	}
+
+	if convID != "" {
+		plan.ConversationID = convID
+	}
+	plan.Status = store.PlanStatusActive
+	if err := a.store.UpdatePlan(plan); err != nil {
+		return nil, err
+	}
+
+	for {
+		plan, err = a.store.GetPlan(planID)
+		if err != nil {
+			return nil, err
+		}
+		if plan.Status != store.PlanStatusActive {
+			// Paused or abandoned while the previous step ran
+			return plan, nil
+		}
+
+		step := plan.NextStep()
+		if step == nil {
+			plan.Status = store.PlanStatusCompleted
+			return plan, a.store.UpdatePlan(plan)
+		}
+
+		now := time.Now()
+		step.Status = store.StepStatusRunning
+		step.StartedAt = &now
+		if err := a.store.UpdatePlanStep(step); err != nil {
+			return nil, err
+		}
+
+		resp, chatErr := a.Chat(ctx, ChatRequest{
+			ConversationID: plan.ConversationID,
+			Message:        stepPrompt(plan, step),
+		})
+
+		if chatErr != nil && ctx.Err() != nil {
+			step.Status = store.StepStatusPending
+			step.StartedAt = nil
+			a.store.UpdatePlanStep(step)
+			plan.Status = store.PlanStatusPaused
+			a.store.UpdatePlan(plan)
+			return plan, ctx.Err()
+		}
+
+		// The model may have recorded the outcome itself with update_plan_step,
+		// and the plan may have been paused meanwhile
+		if current, err := a.store.GetPlan(planID); err == nil {
+			plan.Status = current.Status
+			for i := range current.Steps {
+				if current.Steps[i].ID == step.ID {
+					step = &current.Steps[i]
+				}
+			}
+		}
+
+		finished := time.Now()
+		if chatErr != nil {
+			step.Status = store.StepStatusFailed
+			step.Result = chatErr.Error()
+		} else {
+			if resp.ConversationID != "" {
+				plan.ConversationID = resp.ConversationID
+			}
+			if step.Status == store.StepStatusRunning || step.Status == store.StepStatusPending {
+				step.Status = store.StepStatusCompleted
+			}
+			if step.Result == "" {
+				step.Result = strings.TrimSpace(resp.Content)
+			}
+		}
+		if step.CompletedAt == nil {
+			step.CompletedAt = &finished
+		}
+		if err := a.store.UpdatePlanStep(step); err != nil {
+			return nil, err
+		}
+		if step.Status == store.StepStatusFailed {
+			plan.Status = store.PlanStatusFailed
+		}
+		detail, stepErr := step.Result, error(nil)
+		if step.Status == store.StepStatusFailed {
+			detail, stepErr = "", errors.New(step.Result)
+		}
+		a.journal.Record(household.UserID(ctx), journal.KindPlan,
+			fmt.Sprintf("Ran step %d of plan %q: %s", step.Position, journal.Summarize(plan.Goal, 60), journal.Summarize(step.Description, 80)),
+			detail, stepErr)
+		if err := a.store.UpdatePlan(plan); err != nil {
+			return nil, err
+		}
+
+		if onStep != nil {
+			onStep(*step)
+		}
+
+		if step.Status == store.StepStatusFailed {
+			return a.store.GetPlan(planID)
+		}
+	}
+}
+
+// stepPrompt builds the chat message that asks the agent to carry out a
+// single step, with the results of the steps before it
+func stepPrompt(plan *store.Plan, step *store.PlanStep) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("You are executing step %d of %d of the plan %q (plan_id %s).\n",
+		step.Position, len(plan.Steps), plan.Goal, plan.ID))
+
+	var done []string
+	for _, s := range plan.Steps {
+		if s.Position >= step.Position {
+			break
+		}
+		result := strutil.Truncate(s.Result, maxStepResultChars)
+		line := fmt.Sprintf("%d. [%s] %s", s.Position, s.Status, s.Description)
+		if result != "" {
+			line += " - " + result
+		}
+		if len(s.Artifacts) > 0 {
+			line += " (artifacts: " + strings.Join(s.Artifacts, ", ") + ")"
+		}
+		done = append(done, line)
+	}
+	if len(done) > 0 {
+		sb.WriteString("\nEarlier steps:\n")
+		sb.WriteString(strings.Join(done, "\n"))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("\nCurrent step: %s\n\n", step.Description))
+	sb.WriteString("Do only this step. Record files, links or IDs you produce with update_plan_step (artifacts), " +
+		"and mark the step failed there if it cannot be done. Finish with a short summary of the outcome.")
+	return sb.String()
+}
+
+// FormatPlan renders a plan and its steps for display
+func FormatPlan(plan *store.Plan) string {
+	icons := map[string]string{
+		store.StepStatusPending:   "⏳",
+		store.StepStatusRunning:   "▶️",
+		store.StepStatusCompleted: "✅",
+		store.StepStatusFailed:    "❌",
+		store.StepStatusSkipped:   "⏭️",
+	}
+
+	done, total := plan.Progress()
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📋 %s\n", plan.Goal))
+	sb.WriteString(fmt.Sprintf("   %s | %s | %d/%d steps\n", plan.ID, plan.Status, done, total))
+	if plan.ConversationID != "" {
+		sb.WriteString(fmt.Sprintf("   Conversation: %s\n", plan.ConversationID))
+	}
+	sb.WriteString("\n")
+	for _, s := range plan.Steps {
+		sb.WriteString(fmt.Sprintf("%s %d. %s\n", icons[s.Status], s.Position, s.Description))
+		if s.Result != "" {
+			result := strutil.Truncate(s.Result, 200)
+			sb.WriteString(fmt.Sprintf("      %s\n", strings.ReplaceAll(result, "\n", "\n      ")))
+		}
+		for _, artifact := range s.Artifacts {
+			sb.WriteString(fmt.Sprintf("      📎 %s\n", artifact))
+		}
+	}
+	return sb.String()
+}
+
+// EnablePlans registers the run_task_plan tool, which lets the model start a
+// saved plan from chat. Plans started this way run in the background in
+// their own conversation. Calling it again is a no-op.
+func (a *Agent) EnablePlans() error {
+	if a.skillsRegistry == nil {
+		return fmt.Errorf("skills registry not set")
+	}
+	if _, exists := a.skillsRegistry.GetTool(RunTaskPlanTool); exists {
+		return nil
+	}
+
+	skill := skills.NewBaseSkill("plans", "Execute saved task plans step by step", "1.0.0")
+	skill.AddTool(skills.Tool{
+		Name: RunTaskPlanTool,
+		Description: "Start or resume executing a plan saved with create_task_plan. Steps run one at a time in the background " +
+			"in a dedicated conversation; check progress with get_task_plan and pause with set_task_plan_status.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"plan_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Plan ID",
+				},
+			},
+			"required": []string{"plan_id"},
+		},
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			planID, _ := args["plan_id"].(string)
+			plan, err := a.store.GetPlan(planID)
+			if err != nil {
+				return nil, fmt.Errorf("plan not found: %s", planID)
+			}
+			if plan.Status == store.PlanStatusCompleted {
+				return nil, fmt.Errorf("plan %s is already completed", planID)
+			}
+			if _, busy := runningPlans.Load(planID); busy {
+				return nil, fmt.Errorf("plan %s is already running", planID)
+			}
+
+			// Steps must not write into the conversation that is waiting
+			// for this tool's result
+			conv := &store.Conversation{Title: "Plan: " + strutil.Truncate(plan.Goal, 60)}
+			if err := a.store.CreateConversation(conv); err != nil {
+				return nil, err
+			}
+
+			// Keep context values such as the household profile, but let the
+			// run outlive the current request
+			runCtx := context.WithoutCancel(ctx)
+			go func() {
+				if _, err := a.RunPlan(runCtx, planID, conv.ID, nil); err != nil {
+					a.logger.Warn("Plan run failed", zap.String("plan_id", planID), zap.Error(err))
+				}
+			}()
+
+			done, total := plan.Progress()
+			return fmt.Sprintf("Started plan %s at step %d of %d in conversation %s.", planID, done+1, total, conv.ID), nil
+		},
+	})
+	return a.skillsRegistry.Register(skill)
+}
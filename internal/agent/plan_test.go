@@ -0,0 +1,162 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"go.uber.org/zap"
+)
+
+// newPlanTestAgent creates an agent whose fake LLM echoes the current step
+// of each plan prompt
+func newPlanTestAgent(t *testing.T) (*Agent, *store.Store) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		prompt := req.Messages[len(req.Messages)-1].Content
+		step := prompt
+		if idx := strings.Index(prompt, "Current step: "); idx >= 0 {
+			step = strings.SplitN(prompt[idx+len("Current step: "):], "\n", 2)[0]
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": llm.Message{Role: "assistant", Content: "did " + step}}},
+			"usage":   map[string]int{"total_tokens": 10},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	st := testutil.NewTestStore(t)
+	t.Cleanup(func() { st.Close() })
+
+	return New(llm.NewClient(config.Provider{BaseURL: server.URL, Model: "test"}), nil, st, zap.NewNop(), nil), st
+}
+
+func TestAgent_RunPlan(t *testing.T) {
+	a, st := newPlanTestAgent(t)
+
+	plan := &store.Plan{
+		Goal:  "Plan a birthday party",
+		Steps: []store.PlanStep{{Description: "Pick a date"}, {Description: "Invite guests"}},
+	}
+	if err := st.CreatePlan(plan); err != nil {
+		t.Fatal(err)
+	}
+
+	var seen []int
+	result, err := a.RunPlan(context.Background(), plan.ID, "", func(step store.PlanStep) {
+		seen = append(seen, step.Position)
+	})
+	if err != nil {
+		t.Fatalf("RunPlan failed: %v", err)
+	}
+
+	if result.Status != store.PlanStatusCompleted {
+		t.Errorf("Expected completed plan, got %s", result.Status)
+	}
+	if len(seen) != 2 || seen[0] != 1 || seen[1] != 2 {
+		t.Errorf("Expected steps to run in order, got %v", seen)
+	}
+	for _, step := range result.Steps {
+		if step.Status != store.StepStatusCompleted || step.Result != "did "+step.Description {
+			t.Errorf("Unexpected step state: %+v", step)
+		}
+	}
+	if result.ConversationID == "" {
+		t.Error("Expected the plan to record its conversation")
+	}
+
+	// Earlier results are carried into later steps
+	messages, _ := st.GetMessages(result.ConversationID, 10, 0)
+	last := ""
+	for _, m := range messages {
+		if m.Role == "user" {
+			last = m.Content
+		}
+	}
+	if !strings.Contains(last, "1. [completed] Pick a date - did Pick a date") {
+		t.Errorf("Expected step 2 prompt to include step 1's result, got %q", last)
+	}
+}
+
+func TestAgent_RunPlan_PauseAndResume(t *testing.T) {
+	a, st := newPlanTestAgent(t)
+
+	plan := &store.Plan{
+		Goal:  "Clean the garage",
+		Steps: []store.PlanStep{{Description: "Sort boxes"}, {Description: "Sweep"}, {Description: "Recycle"}},
+	}
+	if err := st.CreatePlan(plan); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pause from "elsewhere" after the first step
+	paused, err := a.RunPlan(context.Background(), plan.ID, "", func(step store.PlanStep) {
+		p, _ := st.GetPlan(plan.ID)
+		p.Status = store.PlanStatusPaused
+		st.UpdatePlan(p)
+	})
+	if err != nil {
+		t.Fatalf("RunPlan failed: %v", err)
+	}
+	if paused.Status != store.PlanStatusPaused {
+		t.Fatalf("Expected paused plan, got %s", paused.Status)
+	}
+	if done, _ := paused.Progress(); done != 1 {
+		t.Fatalf("Expected one finished step before pausing, got %d", done)
+	}
+
+	// Resume in a different conversation
+	conv := &store.Conversation{Title: "Later"}
+	if err := st.CreateConversation(conv); err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := a.RunPlan(context.Background(), plan.ID, conv.ID, nil)
+	if err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if resumed.Status != store.PlanStatusCompleted || resumed.ConversationID != conv.ID {
+		t.Errorf("Expected plan completed in %s, got %s in %s", conv.ID, resumed.Status, resumed.ConversationID)
+	}
+
+	// Cancelling a run pauses the plan and keeps the step pending
+	plan2 := &store.Plan{Goal: "Interrupted", Steps: []store.PlanStep{{Description: "Only step"}}}
+	if err := st.CreatePlan(plan2); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	interrupted, err := a.RunPlan(ctx, plan2.ID, "", nil)
+	if err == nil {
+		t.Fatal("Expected cancelled run to return an error")
+	}
+	if interrupted.Status != store.PlanStatusPaused || interrupted.Steps[0].Status != store.StepStatusPending {
+		t.Errorf("Expected paused plan with pending step, got %s / %s", interrupted.Status, interrupted.Steps[0].Status)
+	}
+}
+
+func TestStepPrompt_CutsEarlierResultsByCharacter(t *testing.T) {
+	result := strings.Repeat("日本", maxStepResultChars)
+	plan := &store.Plan{Goal: "Translate", Steps: []store.PlanStep{
+		{Position: 1, Status: store.StepStatusCompleted, Description: "Draft", Result: result},
+		{Position: 2, Status: store.StepStatusPending, Description: "Review"},
+	}}
+	prompt := stepPrompt(plan, &plan.Steps[1])
+	if !utf8.ValidString(prompt) {
+		t.Error("Expected the prompt to be valid UTF-8")
+	}
+	if !strings.Contains(prompt, " - "+string([]rune(result)[:maxStepResultChars-3])+"...\n") {
+		t.Error("Expected the earlier result cut to maxStepResultChars characters")
+	}
+}
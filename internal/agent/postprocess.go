@@ -0,0 +1,185 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/security"
+)
+
+// Reply post-processing steps
+const (
+	StepRedactSecrets = "redact_secrets" // replace API keys, tokens and passwords
+	StepProfanity     = "profanity"      // mask swear words
+	StepReplace       = "replace"        // apply the configured regex replacements
+	StepSplit         = "split"          // break long replies into messages
+)
+
+// channelMaxLength is the message size of channels that limit it, used by
+// the split step when no max_length is configured
+var channelMaxLength = map[string]int{
+	"telegram": 4096,
+	"discord":  2000,
+}
+
+// replyStep transforms a reply's parts. Steps before a split see one part.
+type replyStep func(parts []string) []string
+
+// ReplyPipeline post-processes replies before delivery: an ordered chain of
+// steps, with its own chain for channels the config overrides. A nil
+// *ReplyPipeline leaves replies as they are.
+type ReplyPipeline struct {
+	chains map[string][]replyStep // by channel; "" is the default chain
+}
+
+// NewReplyPipeline builds the chains cfg describes. filter tunes the
+// profanity step's word lists.
+func NewReplyPipeline(cfg config.PostProcessConfig, filter security.ContentFilterOptions) (*ReplyPipeline, error) {
+	p := &ReplyPipeline{chains: make(map[string][]replyStep)}
+	chain, err := buildReplyChain(cfg, "", filter)
+	if err != nil {
+		return nil, err
+	}
+	p.chains[""] = chain
+
+	channels := make(map[string]bool)
+	for channel := range channelMaxLength {
+		channels[channel] = true
+	}
+	for channel := range cfg.Channels {
+		channels[channel] = true
+	}
+	for channel := range channels {
+		merged := cfg
+		if override, ok := cfg.Channels[channel]; ok {
+			if len(override.Steps) > 0 {
+				merged.Steps = override.Steps
+			}
+			if override.MaxLength > 0 {
+				merged.MaxLength = override.MaxLength
+			}
+			merged.Replacements = append(append([]config.ReplacementConfig(nil), cfg.Replacements...), override.Replacements...)
+		}
+		chain, err := buildReplyChain(merged, channel, filter)
+		if err != nil {
+			return nil, fmt.Errorf("postprocess channel %s: %w", channel, err)
+		}
+		p.chains[channel] = chain
+	}
+	return p, nil
+}
+
+func buildReplyChain(cfg config.PostProcessConfig, channel string, filter security.ContentFilterOptions) ([]replyStep, error) {
+	var chain []replyStep
+	for _, name := range cfg.Steps {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case StepRedactSecrets:
+			scanner := security.NewSecretScanner()
+			chain = append(chain, eachPart(scanner.Redact))
+		case StepProfanity:
+			f, err := security.NewContentFilter(security.FilterFamily, filter)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, eachPart(func(text string) string {
+				return f.MaskProfanity(text).Text
+			}))
+		case StepReplace:
+			for _, r := range cfg.Replacements {
+				re, err := regexp.Compile(r.Pattern)
+				if err != nil {
+					return nil, fmt.Errorf("invalid replacement pattern %q: %w", r.Pattern, err)
+				}
+				replace := r.Replace
+				chain = append(chain, eachPart(func(text string) string {
+					return re.ReplaceAllString(text, replace)
+				}))
+			}
+		case StepSplit:
+			limit := cfg.MaxLength
+			if limit <= 0 {
+				limit = channelMaxLength[channel]
+			}
+			if limit <= 0 {
+				continue
+			}
+			chain = append(chain, func(parts []string) []string {
+				var out []string
+				for _, part := range parts {
+					out = append(out, splitReply(part, limit)...)
+				}
+				return out
+			})
+		default:
+			return nil, fmt.Errorf("unknown postprocess step %q: use %s, %s, %s or %s",
+				name, StepRedactSecrets, StepProfanity, StepReplace, StepSplit)
+		}
+	}
+	return chain, nil
+}
+
+func eachPart(fn func(string) string) replyStep {
+	return func(parts []string) []string {
+		for i, part := range parts {
+			parts[i] = fn(part)
+		}
+		return parts
+	}
+}
+
+// Apply runs text through channel's chain, returning the processed reply
+// and the messages to deliver it as
+func (p *ReplyPipeline) Apply(channel, text string) (string, []string) {
+	if text == "" {
+		return text, nil
+	}
+	if p == nil {
+		return text, []string{text}
+	}
+	chain, ok := p.chains[channel]
+	if !ok {
+		chain = p.chains[""]
+	}
+	parts := []string{text}
+	for _, step := range chain {
+		parts = step(parts)
+	}
+	return strings.Join(parts, "\n"), parts
+}
+
+// splitReply breaks text into messages of at most limit characters,
+// preferring paragraph, then line, then word boundaries
+func splitReply(text string, limit int) []string {
+	var parts []string
+	for utf8.RuneCountInString(text) > limit {
+		// Byte offset of the first character past the limit
+		end := 0
+		for i := 0; i < limit; i++ {
+			_, size := utf8.DecodeRuneInString(text[end:])
+			end += size
+		}
+		head := text[:end]
+		cut := -1
+		for _, sep := range []string{"\n\n", "\n", " "} {
+			// A break in the first half would leave a message mostly empty
+			if i := strings.LastIndex(head, sep); i > len(head)/2 {
+				cut = i
+				break
+			}
+		}
+		if cut < 0 {
+			cut = end
+		}
+		if part := strings.TrimRight(text[:cut], " \n"); part != "" {
+			parts = append(parts, part)
+		}
+		text = strings.TrimLeft(text[cut:], " \n")
+	}
+	if text != "" {
+		parts = append(parts, text)
+	}
+	return parts
+}
@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gmsas95/myrai-cli/internal/persona"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+)
+
+// projectScope puts the current project into ctx: its ID, so memories are
+// recalled and remembered in it, and its tool profile, so only the tools the
+// profile offers run. It returns the profile, nil if the project has none.
+func (a *Agent) projectScope(ctx context.Context) (context.Context, *persona.ToolProfile) {
+	if a.personaManager == nil {
+		return ctx, nil
+	}
+	project := a.personaManager.GetCurrentProject()
+	if project == nil {
+		return ctx, nil
+	}
+	if _, scoped := ctx.Value(projectKey{}).(string); !scoped {
+		ctx = WithProject(ctx, project.ID())
+	}
+
+	profile, err := a.personaManager.ToolProfile(project.Name)
+	if err != nil || profile == nil {
+		return ctx, nil
+	}
+	name := project.Name
+	ctx = skills.WithToolFilter(ctx, func(skill, tool string) error {
+		if !profile.Allows(skill, tool) {
+			return fmt.Errorf("%s is disabled in project %s", tool, name)
+		}
+		return nil
+	})
+	return ctx, profile
+}
+
+// filterToolDefs drops the definitions of tools the filter in ctx refuses
+func (a *Agent) filterToolDefs(ctx context.Context, defs []map[string]interface{}) []map[string]interface{} {
+	kept := defs[:0]
+	for _, def := range defs {
+		fn, _ := def["function"].(map[string]interface{})
+		name := getString(fn, "name")
+		var skill string
+		if a.skillsRegistry != nil {
+			skill = a.skillsRegistry.ToolSkill(name)
+		}
+		if skills.CheckTool(ctx, skill, name) == nil {
+			kept = append(kept, def)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/persona"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"go.uber.org/zap"
+)
+
+func TestProjectScopeFiltersTools(t *testing.T) {
+	pm, err := persona.NewPersonaManager(t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to create persona manager: %v", err)
+	}
+	if _, err := pm.CreateProject("Novel", "writing", "desc"); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if err := pm.SwitchProject("Novel"); err != nil {
+		t.Fatalf("Failed to switch project: %v", err)
+	}
+	profile := &persona.ToolProfile{Deny: []string{"exec_command"}, Model: "writer-model"}
+	if err := pm.SetToolProfile("Novel", profile); err != nil {
+		t.Fatalf("Failed to set profile: %v", err)
+	}
+
+	a := &Agent{logger: zap.NewNop(), personaManager: pm}
+	ctx, got := a.projectScope(context.Background())
+	if got == nil || got.Model != "writer-model" {
+		t.Fatalf("Expected the project's profile, got %+v", got)
+	}
+	if projectFrom(ctx) == "" {
+		t.Error("Expected the project in ctx")
+	}
+
+	defs := []map[string]interface{}{
+		{"type": "function", "function": map[string]interface{}{"name": "exec_command"}},
+		{"type": "function", "function": map[string]interface{}{"name": "create_note"}},
+	}
+	kept := a.filterToolDefs(ctx, defs)
+	if len(kept) != 1 || kept[0]["function"].(map[string]interface{})["name"] != "create_note" {
+		t.Errorf("Expected only create_note, got %+v", kept)
+	}
+	if err := skills.CheckTool(ctx, "system", "exec_command"); err == nil {
+		t.Error("Expected exec_command to be refused")
+	}
+}
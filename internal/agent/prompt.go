@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/persona"
+)
+
+// The system prompt is assembled from layers: the persona's (time, identity,
+// user, project, tools, agents), then the agent's own below. Pins and
+// memories follow as separate system messages, so they don't disturb the
+// cached prefix of the prompt.
+const (
+	LayerDefault   = "default"   // the built-in prompt, without a persona manager
+	LayerCustom    = "custom"    // a system prompt given with the request
+	LayerSkills    = "skills"    // instructions of skills defined with a prompt
+	LayerHousehold = "household" // the household member being talked with
+	LayerOffline   = "offline"   // the network is down and a local model answers
+	LayerPins      = "pins"      // items pinned to the conversation
+	LayerMemory    = "memory"    // memories and document passages relevant to the message
+)
+
+// PromptLayer is one layer of an inspected prompt
+type PromptLayer struct {
+	Name    string
+	Content string
+	Tokens  int
+	Message int // index of the system message the layer is part of
+}
+
+// PromptInspection is the system prompt of a conversation as it would be
+// sent with the next message
+type PromptInspection struct {
+	Layers   []PromptLayer
+	Messages []llm.Message // the system messages, exactly as sent
+	Tokens   int           // of Messages, including per-message overhead
+}
+
+// systemLayers returns the layers of the system prompt for ctx. A custom
+// prompt replaces the persona and skill layers.
+func (a *Agent) systemLayers(ctx context.Context, custom string) []persona.PromptLayer {
+	var layers []persona.PromptLayer
+	switch {
+	case custom != "":
+		layers = append(layers, persona.PromptLayer{Name: LayerCustom, Content: custom})
+	case a.personaManager != nil:
+		layers = a.personaManager.SystemPromptLayers(persona.PersonaFrom(ctx))
+	default:
+		layers = append(layers, persona.PromptLayer{Name: LayerDefault, Content: a.defaultSystemPrompt()})
+	}
+
+	// Skills defined with a prompt say when and how to use their tools
+	if custom == "" && a.skillsRegistry != nil {
+		if prompts := a.skillsRegistry.Prompts(ctx); len(prompts) > 0 {
+			layers = append(layers, persona.PromptLayer{
+				Name:    LayerSkills,
+				Content: "## Skill Instructions\n\n" + strings.Join(prompts, "\n\n"),
+			})
+		}
+	}
+	if prompt := household.PersonaPrompt(ctx); prompt != "" {
+		layers = append(layers, persona.PromptLayer{Name: LayerHousehold, Content: prompt})
+	}
+	if a.Offline() {
+		layers = append(layers, persona.PromptLayer{Name: LayerOffline, Content: a.offlinePrompt()})
+	}
+	return layers
+}
+
+// InspectPrompt assembles the system prompt the way Chat does and reports
+// each layer with its token count. convID adds the conversation's pins and
+// query the memories relevant to it; either may be empty.
+func (a *Agent) InspectPrompt(ctx context.Context, convID, query string) (*PromptInspection, error) {
+	ctx, _ = a.projectScope(ctx)
+	ctx = a.offlineScope(ctx)
+	tokenizer := llm.TokenizerFor(a.llmClient.GetModel())
+	if a.contextManager != nil {
+		tokenizer = a.contextManager.tokenizer
+	}
+
+	result := &PromptInspection{}
+	layers := a.systemLayers(ctx, "")
+	for _, layer := range layers {
+		if layer.Content == "" {
+			continue
+		}
+		result.Layers = append(result.Layers, PromptLayer{
+			Name:    layer.Name,
+			Content: layer.Content,
+			Tokens:  tokenizer.Count(layer.Content),
+		})
+	}
+	result.Messages = append(result.Messages, llm.Message{Role: "system", Content: persona.JoinLayers(layers)})
+
+	addMessage := func(name string, msg llm.Message) {
+		result.Layers = append(result.Layers, PromptLayer{
+			Name:    name,
+			Content: msg.Content,
+			Tokens:  tokenizer.Count(msg.Content),
+			Message: len(result.Messages),
+		})
+		result.Messages = append(result.Messages, msg)
+	}
+	if convID != "" {
+		if _, err := a.store.GetConversation(convID); err != nil {
+			return nil, fmt.Errorf("conversation not found: %s", convID)
+		}
+		if msg, ok := pinnedContextMessage(a.store, convID); ok {
+			addMessage(LayerPins, msg)
+		}
+	}
+	if query != "" && a.contextManager != nil {
+		if _, sources := a.contextManager.relevantSources(ctx, query); len(sources) > 0 {
+			addMessage(LayerMemory, sourcesMessage(sources))
+		}
+	}
+
+	result.Tokens = tokenizer.CountMessages(result.Messages)
+	return result, nil
+}
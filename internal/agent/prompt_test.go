@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/persona"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"go.uber.org/zap"
+)
+
+func TestAgent_InspectPrompt(t *testing.T) {
+	a, st := newPlanTestAgent(t)
+
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "TOOLS.md"), []byte("Prefer ripgrep over grep."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pm, err := persona.NewPersonaManager(workspace, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to create persona manager: %v", err)
+	}
+	a.personaManager = pm
+
+	conv := &store.Conversation{Title: "Inspect"}
+	if err := st.CreateConversation(conv); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.PinFromArgs(conv.ID, "The staging DB is on port 5433"); err != nil {
+		t.Fatal(err)
+	}
+
+	inspection, err := a.InspectPrompt(context.Background(), conv.ID, "")
+	if err != nil {
+		t.Fatalf("InspectPrompt failed: %v", err)
+	}
+
+	var names []string
+	for _, layer := range inspection.Layers {
+		names = append(names, layer.Name)
+		if layer.Tokens == 0 {
+			t.Errorf("Expected tokens counted for the %s layer", layer.Name)
+		}
+	}
+	if got := strings.Join(names, ","); got != "time,identity,user,tools,pins" {
+		t.Errorf("Expected the layers in order, got %s", got)
+	}
+	if len(inspection.Messages) != 2 || inspection.Layers[4].Message != 1 {
+		t.Fatalf("Expected the pins in a second system message, got %d messages", len(inspection.Messages))
+	}
+	if !strings.Contains(inspection.Messages[0].Content, "## Available Tools\nPrefer ripgrep") {
+		t.Errorf("Expected TOOLS.md in the system prompt, got %q", inspection.Messages[0].Content)
+	}
+	if inspection.Tokens <= inspection.Layers[0].Tokens {
+		t.Errorf("Expected the total to cover every layer, got %d", inspection.Tokens)
+	}
+
+	// Chat sends the same system prompt, apart from the clock
+	if want := a.buildSystemPrompt(context.Background()); !strings.HasSuffix(inspection.Messages[0].Content, want[strings.Index(want, "## Your Identity"):]) {
+		t.Error("Expected the inspected prompt to match the one Chat builds")
+	}
+
+	if _, err := a.InspectPrompt(context.Background(), "conv_missing", ""); err == nil {
+		t.Error("Expected an unknown conversation to be an error")
+	}
+}
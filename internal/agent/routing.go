@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"go.uber.org/zap"
+)
+
+// Routes a turn can take
+const (
+	RouteAuto  = "auto"  // the router decides each turn
+	RouteFast  = "fast"  // the fast, cheap model
+	RouteSmart = "smart" // the smart model
+)
+
+// taskPattern finds messages asking for work rather than a quick answer:
+// making or changing things, multi-step jobs and tool use
+var taskPattern = regexp.MustCompile(`(?i)\b(write|rewrite|create|build|implement|refactor|debug|fix|analy[sz]e|compare|plan|draft|summari[sz]e|translate|generate|convert|deploy|install|run|execute|search|browse|download|upload|edit|update|delete|remind|schedule|send|email|step by step|https?://)`)
+
+// Router picks the fast model for simple turns, such as a short factual
+// question, and the smart one for the rest
+type Router struct {
+	cfg config.RoutingConfig
+}
+
+// NewRouter returns the router for cfg, or nil when routing is off
+func NewRouter(cfg config.RoutingConfig) *Router {
+	if !cfg.Enabled || cfg.FastModel == "" {
+		return nil
+	}
+	return &Router{cfg: cfg}
+}
+
+// Classify returns RouteFast for a message the fast model can answer and
+// RouteSmart for one that looks like a task. Long messages, code, several
+// lines, images and links all go to the smart model.
+func (r *Router) Classify(message string, images int) string {
+	message = strings.TrimSpace(message)
+	switch {
+	case images > 0,
+		r.cfg.MaxFastChars > 0 && utf8.RuneCountInString(message) > r.cfg.MaxFastChars,
+		strings.Contains(message, "```"),
+		strings.Count(message, "\n") >= 2,
+		taskPattern.MatchString(message):
+		return RouteSmart
+	}
+	return RouteFast
+}
+
+// model returns the model for a route; "" is the provider's own
+func (r *Router) model(route string) string {
+	if route == RouteFast {
+		return r.cfg.FastModel
+	}
+	return r.cfg.SmartModel
+}
+
+// ParseRoute checks a route given to /model; "" means auto
+func ParseRoute(route string) (string, error) {
+	switch route = strings.ToLower(strings.TrimSpace(route)); route {
+	case "", RouteAuto:
+		return RouteAuto, nil
+	case RouteFast, RouteSmart:
+		return route, nil
+	}
+	return "", fmt.Errorf("unknown model route %q; use fast, smart or auto", route)
+}
+
+// SetRouter routes each turn to the fast or smart model; nil sends every
+// turn to the provider's model
+func (a *Agent) SetRouter(r *Router) {
+	a.router = r
+}
+
+// SetConversationRoute pins a conversation to the fast or smart model, or
+// with auto lets the router choose each turn again
+func (a *Agent) SetConversationRoute(convID, route string) error {
+	route, err := ParseRoute(route)
+	if err != nil {
+		return err
+	}
+	if route == RouteAuto {
+		route = ""
+	}
+	if err := a.store.SetConversationRoute(convID, route); err != nil {
+		return fmt.Errorf("conversation not found: %s", convID)
+	}
+	return nil
+}
+
+// DescribeRoute says which model a conversation's turns go to, for /model
+func (a *Agent) DescribeRoute(convID string) string {
+	if a.router == nil {
+		return fmt.Sprintf("Every turn goes to %s; set agent.routing in the config to route simple turns to a cheaper model.", a.llmClient.GetModel())
+	}
+	smart := a.router.model(RouteSmart)
+	if smart == "" {
+		smart = a.llmClient.GetModel()
+	}
+	route := ""
+	if conv, err := a.store.GetConversation(convID); err == nil {
+		route = conv.Route
+	}
+	switch route {
+	case RouteFast:
+		return fmt.Sprintf("This conversation uses the fast model, %s.", a.router.cfg.FastModel)
+	case RouteSmart:
+		return fmt.Sprintf("This conversation uses the smart model, %s.", smart)
+	}
+	return fmt.Sprintf("Each turn is routed: simple ones to %s, the rest to %s.", a.router.cfg.FastModel, smart)
+}
+
+// routeTurn picks the route and model for a turn of conv. A conversation
+// that needed the smart model stays on it for StickyTurns turns, so a task
+// isn't handed to the fast model half-way. It returns "" without a router.
+func (a *Agent) routeTurn(conv *store.Conversation, req ChatRequest) (string, string) {
+	if a.router == nil {
+		return "", ""
+	}
+	if conv.Route == RouteFast || conv.Route == RouteSmart {
+		return conv.Route, a.router.model(conv.Route)
+	}
+
+	route := a.router.Classify(req.Message, len(req.Images))
+	turns := conv.SmartTurns
+	switch {
+	case route == RouteSmart:
+		turns = a.router.cfg.StickyTurns
+	case turns > 0:
+		route = RouteSmart
+		turns--
+	}
+	a.setSmartTurns(conv, turns)
+
+	a.logger.Debug("Routed turn", zap.String("conversation", conv.ID), zap.String("route", route))
+	return route, a.router.model(route)
+}
+
+// afterRoutedTurn keeps the conversation on the smart model for the next
+// turns when the fast one found it needed tools
+func (a *Agent) afterRoutedTurn(conv *store.Conversation, route string, resp *ChatResponse) {
+	if a.router == nil || route != RouteFast || conv.Route != "" || len(resp.ToolCalls) == 0 {
+		return
+	}
+	a.setSmartTurns(conv, a.router.cfg.StickyTurns)
+}
+
+func (a *Agent) setSmartTurns(conv *store.Conversation, turns int) {
+	if turns == conv.SmartTurns {
+		return
+	}
+	conv.SmartTurns = turns
+	if err := a.store.SetSmartTurns(conv.ID, turns); err != nil {
+		a.logger.Warn("Failed to save model routing", zap.Error(err))
+	}
+}
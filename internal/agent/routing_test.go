@@ -0,0 +1,139 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"go.uber.org/zap"
+)
+
+var testRouting = config.RoutingConfig{Enabled: true, FastModel: "small", MaxFastChars: 80, StickyTurns: 2}
+
+func TestRouter_Classify(t *testing.T) {
+	r := NewRouter(testRouting)
+	tests := []struct {
+		message string
+		images  int
+		want    string
+	}{
+		{"What's the capital of Peru?", 0, RouteFast},
+		{"thanks!", 0, RouteFast},
+		{"Who wrote Dune?", 0, RouteFast},
+		{"What's in this picture?", 1, RouteSmart},
+		{strings.Repeat("a long question ", 10), 0, RouteSmart},
+		{"Why does this fail?\n```go\nx := nil\n```", 0, RouteSmart},
+		{"Refactor the config loader", 0, RouteSmart},
+		{"Write a haiku", 0, RouteSmart},
+		{"What's on https://example.com?", 0, RouteSmart},
+		{"one\ntwo\nthree", 0, RouteSmart},
+	}
+	for _, tt := range tests {
+		if got := r.Classify(tt.message, tt.images); got != tt.want {
+			t.Errorf("Classify(%q) = %s, want %s", tt.message, got, tt.want)
+		}
+	}
+
+	if NewRouter(config.RoutingConfig{FastModel: "small"}) != nil {
+		t.Error("Expected no router when routing is disabled")
+	}
+}
+
+func TestParseRoute(t *testing.T) {
+	for in, want := range map[string]string{"": RouteAuto, "Auto": RouteAuto, " fast ": RouteFast, "smart": RouteSmart} {
+		if got, err := ParseRoute(in); err != nil || got != want {
+			t.Errorf("ParseRoute(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseRoute("cheap"); err == nil {
+		t.Error("Expected an unknown route to be rejected")
+	}
+}
+
+// newRoutingTestAgent records the model each chat request asks for
+func newRoutingTestAgent(t *testing.T) (*Agent, *store.Store, *[]string) {
+	t.Helper()
+
+	var models []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		models = append(models, req.Model)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": llm.Message{Role: "assistant", Content: "ok"}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	st := testutil.NewTestStore(t)
+	t.Cleanup(func() { st.Close() })
+
+	a := New(llm.NewClient(config.Provider{BaseURL: server.URL, Model: "big"}), nil, st, zap.NewNop(), nil)
+	a.SetRouter(NewRouter(testRouting))
+	return a, st, &models
+}
+
+func TestAgent_ChatRouting(t *testing.T) {
+	a, st, models := newRoutingTestAgent(t)
+	ctx := context.Background()
+
+	chat := func(convID, message string) *ChatResponse {
+		t.Helper()
+		resp, err := a.Chat(ctx, ChatRequest{ConversationID: convID, Message: message})
+		if err != nil {
+			t.Fatalf("Chat failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := chat("", "What time is it in Tokyo?")
+	if resp.Route != RouteFast || resp.Model != "small" {
+		t.Errorf("Expected a simple question on the fast model, got %s on %s", resp.Route, resp.Model)
+	}
+	convID := resp.ConversationID
+
+	// A task keeps the conversation on the smart model for StickyTurns turns
+	chat(convID, "Draft a reply to my landlord")
+	chat(convID, "ok")
+	chat(convID, "shorter")
+	chat(convID, "thanks")
+	want := []string{"small", "big", "big", "big", "small"}
+	if strings.Join(*models, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected models %v, got %v", want, *models)
+	}
+
+	// /model pins the conversation until it is set back to auto
+	if err := a.SetConversationRoute(convID, "smart"); err != nil {
+		t.Fatal(err)
+	}
+	if resp := chat(convID, "hi"); resp.Route != RouteSmart || resp.Model != "big" {
+		t.Errorf("Expected the pinned smart model, got %s on %s", resp.Route, resp.Model)
+	}
+	if !strings.Contains(a.DescribeRoute(convID), "smart model, big") {
+		t.Errorf("Expected the pin described, got %q", a.DescribeRoute(convID))
+	}
+	if err := a.SetConversationRoute(convID, "auto"); err != nil {
+		t.Fatal(err)
+	}
+	conv, _ := st.GetConversation(convID)
+	if conv.Route != "" || conv.SmartTurns != 0 {
+		t.Errorf("Expected auto routing again, got %q with %d smart turns", conv.Route, conv.SmartTurns)
+	}
+	if resp := chat(convID, "hi"); resp.Route != RouteFast {
+		t.Errorf("Expected the fast model after auto, got %s", resp.Route)
+	}
+
+	if err := a.SetConversationRoute("conv_missing", "fast"); err == nil {
+		t.Error("Expected an unknown conversation to fail")
+	}
+	if err := a.SetConversationRoute(convID, "cheap"); err == nil {
+		t.Error("Expected an unknown route to fail")
+	}
+}
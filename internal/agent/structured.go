@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// maxStructuredRetries is how many times the model is asked to fix an answer
+// that doesn't match the requested format
+const maxStructuredRetries = 2
+
+// ErrInvalidStructuredOutput is returned when the model's answer still
+// doesn't match the requested format after retries
+var ErrInvalidStructuredOutput = errors.New("answer does not match the response format")
+
+// ResponseFormat asks for the answer as JSON matching a schema. Providers
+// with a native JSON mode are asked to use it; the answer is validated
+// either way and the model asked to correct it if needed.
+type ResponseFormat struct {
+	Name   string          `json:"name"`   // names the schema for the provider
+	Schema json.RawMessage `json:"schema"` // JSON schema; empty accepts any JSON object
+
+	schema *jsonschema.Schema
+	object bool // the schema only accepts objects
+}
+
+// Compile checks the schema, so bad schemas are reported before the model is
+// asked anything
+func (f *ResponseFormat) Compile() error {
+	if f.Name == "" {
+		f.Name = "response"
+	}
+	if len(bytes.TrimSpace(f.Schema)) == 0 {
+		f.Schema = json.RawMessage(`{"type":"object"}`)
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(f.Schema))
+	if err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	if m, ok := doc.(map[string]any); ok {
+		f.object = m["type"] == "object"
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("response.json", doc); err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	schema, err := compiler.Compile("response.json")
+	if err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	f.schema = schema
+	return nil
+}
+
+// instruction tells the model how to answer, for providers that can't be
+// constrained natively and as a reminder for those that can
+func (f *ResponseFormat) instruction() string {
+	return "Answer with only a JSON value matching this JSON schema, without code fences or other text:\n" + string(f.Schema)
+}
+
+// llmFormat returns the provider-native form of the format for a model, or
+// nil if the model has no JSON mode
+func (f *ResponseFormat) llmFormat(model string) *llm.ResponseFormat {
+	switch llm.LookupModel(model).JSONMode {
+	case llm.JSONSchemaMode:
+		return &llm.ResponseFormat{
+			Type:       llm.JSONSchemaMode,
+			JSONSchema: &llm.JSONSchema{Name: f.Name, Schema: f.Schema},
+		}
+	case llm.JSONObjectMode:
+		// JSON object mode can't return arrays or scalars
+		if f.object {
+			return &llm.ResponseFormat{Type: llm.JSONObjectMode}
+		}
+	}
+	return nil
+}
+
+// codeFence matches an answer wrapped in a Markdown code block
+var codeFence = regexp.MustCompile("(?s)^```(?:json)?\\s*(.*?)\\s*```$")
+
+// Parse extracts the JSON from an answer and validates it, returning it
+// compacted
+func (f *ResponseFormat) Parse(content string) (json.RawMessage, error) {
+	text := strings.TrimSpace(content)
+	if m := codeFence.FindStringSubmatch(text); m != nil {
+		text = m[1]
+	}
+
+	value, err := jsonschema.UnmarshalJSON(strings.NewReader(text))
+	if err != nil {
+		return nil, fmt.Errorf("not valid JSON: %w", err)
+	}
+	if f.schema != nil {
+		if err := f.schema.Validate(value); err != nil {
+			return nil, err
+		}
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(text)); err != nil {
+		return nil, fmt.Errorf("not valid JSON: %w", err)
+	}
+	return compact.Bytes(), nil
+}
+
+// unsupportedResponseFormat reports whether the provider rejected the
+// request because of its response_format
+func unsupportedResponseFormat(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "status 400") && strings.Contains(msg, "response_format")
+}
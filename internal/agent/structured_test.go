@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"go.uber.org/zap"
+)
+
+const citySchema = `{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`
+
+func TestResponseFormat_Parse(t *testing.T) {
+	format := &ResponseFormat{Schema: json.RawMessage(citySchema)}
+	if err := format.Compile(); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	parsed, err := format.Parse("```json\n{ \"city\": \"Lisbon\" }\n```")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if string(parsed) != `{"city":"Lisbon"}` {
+		t.Errorf("Expected compact JSON, got %s", parsed)
+	}
+
+	for _, answer := range []string{`{"city": 5}`, `{}`, `The city is Lisbon`} {
+		if _, err := format.Parse(answer); err == nil {
+			t.Errorf("Expected %q to be rejected", answer)
+		}
+	}
+
+	bad := &ResponseFormat{Schema: json.RawMessage(`{"type": 5}`)}
+	if err := bad.Compile(); err == nil {
+		t.Error("Expected an invalid schema to be rejected")
+	}
+}
+
+func TestResponseFormat_LLMFormat(t *testing.T) {
+	format := &ResponseFormat{Name: "city", Schema: json.RawMessage(citySchema)}
+	if err := format.Compile(); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	if f := format.llmFormat("gpt-4o-mini"); f == nil || f.Type != llm.JSONSchemaMode || f.JSONSchema.Name != "city" {
+		t.Errorf("Expected a json_schema format for gpt-4o, got %+v", f)
+	}
+	if f := format.llmFormat("deepseek-chat"); f == nil || f.Type != llm.JSONObjectMode {
+		t.Errorf("Expected a json_object format for deepseek, got %+v", f)
+	}
+	if f := format.llmFormat("claude-sonnet-4"); f != nil {
+		t.Errorf("Expected no native format for claude, got %+v", f)
+	}
+
+	list := &ResponseFormat{Schema: json.RawMessage(`{"type":"array"}`)}
+	if err := list.Compile(); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if f := list.llmFormat("deepseek-chat"); f != nil {
+		t.Errorf("Expected no json_object format for an array schema, got %+v", f)
+	}
+}
+
+func TestAgent_ChatStructured(t *testing.T) {
+	var formats []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.ResponseFormat != nil {
+			formats = append(formats, req.ResponseFormat.Type)
+			http.Error(w, `{"error":{"message":"response_format is not supported"}}`, http.StatusBadRequest)
+			return
+		}
+		formats = append(formats, "")
+
+		if !strings.Contains(req.Messages[0].Content, "matching this JSON schema") {
+			t.Errorf("Expected the schema in the system prompt, got %q", req.Messages[0].Content)
+		}
+		content := `{"city": 5}`
+		if last := req.Messages[len(req.Messages)-1]; strings.Contains(last.Content, "required format") {
+			content = "```json\n{\"city\": \"Lisbon\"}\n```"
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": llm.Message{Role: "assistant", Content: content}}},
+			"usage":   map[string]int{"total_tokens": 10},
+		})
+	}))
+	defer server.Close()
+
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+	a := New(llm.NewClient(config.Provider{BaseURL: server.URL, Model: "gpt-4o"}), nil, st, zap.NewNop(), nil)
+
+	resp, err := a.Chat(context.Background(), ChatRequest{
+		Message:        "Where is the Tower of Belém?",
+		ResponseFormat: &ResponseFormat{Schema: json.RawMessage(citySchema)},
+	})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if string(resp.Structured) != `{"city":"Lisbon"}` || resp.Content != `{"city":"Lisbon"}` {
+		t.Errorf("Expected the corrected answer, got %s / %q", resp.Structured, resp.Content)
+	}
+	if strings.Join(formats, ",") != "json_schema,," {
+		t.Errorf("Expected the native format to be tried once, then a validated retry, got %q", formats)
+	}
+
+	// Answers that never match are an error
+	_, err = a.Chat(context.Background(), ChatRequest{
+		Message:        "Where is it?",
+		ResponseFormat: &ResponseFormat{Schema: json.RawMessage(`{"type":"object","required":["country"]}`)},
+	})
+	if !errors.Is(err, ErrInvalidStructuredOutput) {
+		t.Errorf("Expected ErrInvalidStructuredOutput, got %v", err)
+	}
+}
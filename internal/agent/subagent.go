@@ -0,0 +1,422 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"go.uber.org/zap"
+)
+
+// SpawnSubAgentTool is the name of the tool exposed to the model
+const SpawnSubAgentTool = "spawn_subagent"
+
+// SubAgentConfig bounds sub-agent orchestration
+type SubAgentConfig struct {
+	MaxDepth       int // How deeply sub-agents may spawn further sub-agents
+	MaxConcurrency int // Sub-agents running at the same time
+	MaxPerCall     int // Sub-agents a single spawn_subagent call may request
+	MaxTurns       int // LLM round-trips per sub-agent
+	TokenBudget    int // Default token budget per sub-agent
+	MaxTokenBudget int // Upper bound for a requested token budget
+	Timeout        time.Duration
+}
+
+// DefaultSubAgentConfig returns conservative orchestration limits
+func DefaultSubAgentConfig() SubAgentConfig {
+	return SubAgentConfig{
+		MaxDepth:       2,
+		MaxConcurrency: 4,
+		MaxPerCall:     5,
+		MaxTurns:       8,
+		TokenBudget:    20000,
+		MaxTokenBudget: 100000,
+		Timeout:        3 * time.Minute,
+	}
+}
+
+// SubAgentSpec describes a specialised sub-agent
+type SubAgentSpec struct {
+	Role         string   `json:"role"`
+	Task         string   `json:"task"`
+	SystemPrompt string   `json:"system_prompt,omitempty"`
+	Tools        []string `json:"tools,omitempty"`
+	TokenBudget  int      `json:"token_budget,omitempty"`
+}
+
+// SubAgentResult is the outcome of a single sub-agent run
+type SubAgentResult struct {
+	Role       string        `json:"role"`
+	Output     string        `json:"output"`
+	Error      string        `json:"error,omitempty"`
+	TokensUsed int           `json:"tokens_used"`
+	ToolCalls  int           `json:"tool_calls"`
+	Truncated  bool          `json:"truncated,omitempty"` // Stopped by token budget or turn limit
+	Duration   time.Duration `json:"duration"`
+}
+
+// Orchestrator runs bounded sub-agents on behalf of an agent
+type Orchestrator struct {
+	agent  *Agent
+	config SubAgentConfig
+	// slots holds a semaphore per depth, so sub-agents waiting on the ones
+	// they spawned never hold the slots those need
+	slots  []chan struct{}
+	logger *zap.Logger
+}
+
+type subAgentDepthKey struct{}
+
+type callingAgentKey struct{}
+
+// withCallingAgent records the agent running a tool in ctx. The
+// spawn_subagent tool is registered once per registry, which agents may
+// share, and works for whichever agent calls it.
+func withCallingAgent(ctx context.Context, a *Agent) context.Context {
+	return context.WithValue(ctx, callingAgentKey{}, a)
+}
+
+// NewOrchestrator creates an orchestrator for the agent
+func NewOrchestrator(agent *Agent, cfg SubAgentConfig) *Orchestrator {
+	defaults := DefaultSubAgentConfig()
+	if cfg.MaxDepth <= 0 {
+		cfg.MaxDepth = defaults.MaxDepth
+	}
+	if cfg.MaxConcurrency <= 0 {
+		cfg.MaxConcurrency = defaults.MaxConcurrency
+	}
+	if cfg.MaxPerCall <= 0 {
+		cfg.MaxPerCall = defaults.MaxPerCall
+	}
+	if cfg.MaxTurns <= 0 {
+		cfg.MaxTurns = defaults.MaxTurns
+	}
+	if cfg.TokenBudget <= 0 {
+		cfg.TokenBudget = defaults.TokenBudget
+	}
+	if cfg.MaxTokenBudget <= 0 {
+		cfg.MaxTokenBudget = defaults.MaxTokenBudget
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+
+	slots := make([]chan struct{}, cfg.MaxDepth)
+	for i := range slots {
+		slots[i] = make(chan struct{}, cfg.MaxConcurrency)
+	}
+	return &Orchestrator{
+		agent:  agent,
+		config: cfg,
+		slots:  slots,
+		logger: agent.logger,
+	}
+}
+
+// depthFrom returns the sub-agent nesting depth recorded in ctx
+func depthFrom(ctx context.Context) int {
+	if d, ok := ctx.Value(subAgentDepthKey{}).(int); ok {
+		return d
+	}
+	return 0
+}
+
+// RunParallel runs the sub-agents concurrently and returns their results in
+// the order given. Individual failures are reported in the results. At most
+// MaxConcurrency sub-agents run at each depth.
+func (o *Orchestrator) RunParallel(ctx context.Context, specs []SubAgentSpec) ([]SubAgentResult, error) {
+	depth := depthFrom(ctx)
+	if depth >= o.config.MaxDepth {
+		return nil, fmt.Errorf("sub-agent depth limit reached (%d)", o.config.MaxDepth)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no sub-agents requested")
+	}
+	if len(specs) > o.config.MaxPerCall {
+		return nil, fmt.Errorf("too many sub-agents requested (%d, max %d)", len(specs), o.config.MaxPerCall)
+	}
+
+	slots := o.slots[depth]
+	ctx = context.WithValue(ctx, subAgentDepthKey{}, depth+1)
+	ctx, cancel := context.WithTimeout(ctx, o.config.Timeout)
+	defer cancel()
+
+	results := make([]SubAgentResult, len(specs))
+	var wg sync.WaitGroup
+	for i, spec := range specs {
+		wg.Add(1)
+		go func(i int, spec SubAgentSpec) {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				results[i] = SubAgentResult{Role: spec.Role, Error: ctx.Err().Error()}
+				return
+			}
+
+			results[i] = o.Run(ctx, spec)
+		}(i, spec)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// Run executes a single sub-agent with its own prompt, tool subset and
+// token budget. Sub-agents do not write to the conversation store.
+func (o *Orchestrator) Run(ctx context.Context, spec SubAgentSpec) SubAgentResult {
+	start := time.Now()
+	result := SubAgentResult{Role: spec.Role}
+	defer func() { result.Duration = time.Since(start) }()
+
+	if strings.TrimSpace(spec.Task) == "" {
+		result.Error = "sub-agent task is empty"
+		return result
+	}
+
+	budget := spec.TokenBudget
+	if budget <= 0 {
+		budget = o.config.TokenBudget
+	}
+	if budget > o.config.MaxTokenBudget {
+		budget = o.config.MaxTokenBudget
+	}
+
+	allowed := make(map[string]bool, len(spec.Tools))
+	for _, name := range spec.Tools {
+		allowed[name] = true
+	}
+	// Nested spawning is only offered while below the depth limit
+	if depthFrom(ctx) >= o.config.MaxDepth {
+		delete(allowed, SpawnSubAgentTool)
+	}
+
+	messages := []llm.Message{
+		{Role: "system", Content: o.subAgentPrompt(spec)},
+		{Role: "user", Content: spec.Task},
+	}
+	tools := o.toolSubset(allowed)
+
+	o.logger.Info("Sub-agent started",
+		zap.String("role", spec.Role),
+		zap.Int("depth", depthFrom(ctx)),
+		zap.Int("tools", len(tools)),
+		zap.Int("token_budget", budget),
+	)
+
+	for turn := 0; turn < o.config.MaxTurns; turn++ {
+		resp, err := o.agent.complete(ctx, llm.ChatRequest{
+			Model:     o.agent.llmClient.GetModel(),
+			Messages:  messages,
+			Tools:     tools,
+			MaxTokens: 4096,
+		})
+		if err != nil {
+			result.Error = fmt.Sprintf("LLM error: %v", err)
+			return result
+		}
+		if len(resp.Choices) == 0 {
+			result.Error = "no response from LLM"
+			return result
+		}
+
+		result.TokensUsed += resp.Usage.TotalTokens
+		msg := resp.Choices[0].Message
+
+		if len(msg.ToolCalls) == 0 {
+			result.Output = msg.Content
+			return result
+		}
+
+		if result.TokensUsed >= budget {
+			result.Output = msg.Content
+			result.Truncated = true
+			result.Error = fmt.Sprintf("token budget exhausted (%d/%d)", result.TokensUsed, budget)
+			return result
+		}
+
+		messages = append(messages, llm.Message{
+			Role:             "assistant",
+			ToolCalls:        msg.ToolCalls,
+			ReasoningContent: msg.ReasoningContent,
+		})
+		for _, tc := range msg.ToolCalls {
+			result.ToolCalls++
+			messages = append(messages, llm.Message{
+				Role:       "tool",
+				Content:    o.executeTool(ctx, allowed, tc),
+				ToolCallID: tc.ID,
+			})
+		}
+	}
+
+	result.Truncated = true
+	result.Error = fmt.Sprintf("turn limit reached (%d)", o.config.MaxTurns)
+	return result
+}
+
+func (o *Orchestrator) subAgentPrompt(spec SubAgentSpec) string {
+	var sb strings.Builder
+	if spec.SystemPrompt != "" {
+		sb.WriteString(spec.SystemPrompt)
+	} else {
+		role := spec.Role
+		if role == "" {
+			role = "assistant"
+		}
+		sb.WriteString(fmt.Sprintf("You are a specialised %s sub-agent working for a coordinating assistant.", role))
+	}
+	sb.WriteString("\n\nFocus only on the task you are given. Your final message is returned verbatim to the coordinator, so make it a complete, self-contained result without pleasantries.")
+	return sb.String()
+}
+
+// toolSubset returns the definitions of the allowed tools
+func (o *Orchestrator) toolSubset(allowed map[string]bool) []llm.Tool {
+	if len(allowed) == 0 || o.agent.skillsRegistry == nil {
+		return nil
+	}
+	var defs []map[string]interface{}
+	for _, def := range o.agent.skillsRegistry.GetToolDefinitions() {
+		if fn, ok := def["function"].(map[string]interface{}); ok && allowed[getString(fn, "name")] {
+			defs = append(defs, def)
+		}
+	}
+	return o.agent.convertTools(defs)
+}
+
+func (o *Orchestrator) executeTool(ctx context.Context, allowed map[string]bool, tc llm.ToolCall) string {
+	name := tc.Function.Name
+	if !allowed[name] {
+		return fmt.Sprintf("Error: tool %s is not available to this sub-agent", name)
+	}
+	if o.agent.skillsRegistry == nil {
+		return "Error: no tool registry available"
+	}
+
+	args := tc.Function.Arguments
+	if args == "" {
+		args = "{}"
+	}
+	res, err := o.agent.skillsRegistry.ExecuteTool(withCallingAgent(ctx, o.agent), name, []byte(args))
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return fmt.Sprintf("%v", res)
+}
+
+// MergeResults combines sub-agent results into a single report for the
+// coordinating agent
+func MergeResults(results []SubAgentResult) string {
+	var sb strings.Builder
+	for i, r := range results {
+		role := r.Role
+		if role == "" {
+			role = fmt.Sprintf("sub-agent %d", i+1)
+		}
+		sb.WriteString(fmt.Sprintf("## %s\n", role))
+		if r.Output != "" {
+			sb.WriteString(r.Output)
+			sb.WriteString("\n")
+		}
+		if r.Error != "" {
+			sb.WriteString(fmt.Sprintf("(error: %s)\n", r.Error))
+		}
+		sb.WriteString(fmt.Sprintf("[tokens: %d, tool calls: %d, time: %s]\n\n", r.TokensUsed, r.ToolCalls, r.Duration.Round(time.Millisecond)))
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// EnableSubAgents registers the spawn_subagent tool in the agent's skills
+// registry so the model can delegate work to parallel sub-agents. Agents
+// sharing the registry share the tool, and each call runs sub-agents of the
+// agent that made it.
+func (a *Agent) EnableSubAgents(cfg SubAgentConfig) error {
+	if a.skillsRegistry == nil {
+		return fmt.Errorf("skills registry not set")
+	}
+	o := a.setSubAgents(cfg)
+	if _, exists := a.skillsRegistry.GetTool(SpawnSubAgentTool); exists {
+		return nil
+	}
+	return a.skillsRegistry.Register(newSubAgentSkill(o))
+}
+
+// setSubAgents gives the agent an orchestrator, keeping the one it has
+func (a *Agent) setSubAgents(cfg SubAgentConfig) *Orchestrator {
+	a.subAgentsMu.Lock()
+	defer a.subAgentsMu.Unlock()
+	if a.subAgents == nil {
+		a.subAgents = NewOrchestrator(a, cfg)
+	}
+	return a.subAgents
+}
+
+// orchestratorFor returns the orchestrator of the agent calling the tool.
+// An agent sharing the registry without having enabled sub-agents gets one
+// with the limits of fallback; fallback runs calls from unknown callers.
+func orchestratorFor(ctx context.Context, fallback *Orchestrator) *Orchestrator {
+	caller, ok := ctx.Value(callingAgentKey{}).(*Agent)
+	if !ok || caller == nil || caller == fallback.agent {
+		return fallback
+	}
+	return caller.setSubAgents(fallback.config)
+}
+
+func newSubAgentSkill(o *Orchestrator) skills.Skill {
+	skill := skills.NewBaseSkill("subagents", "Delegate work to parallel specialised sub-agents", "1.0.0")
+	skill.AddTool(skills.Tool{
+		Name: SpawnSubAgentTool,
+		Description: fmt.Sprintf("Spawn up to %d specialised sub-agents (e.g. a researcher and a coder) that work in parallel, "+
+			"each with its own instructions, tool subset and token budget. Returns their merged results. "+
+			"Use for independent sub-tasks that benefit from focused work.", o.config.MaxPerCall),
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"agents": map[string]interface{}{
+					"type":        "array",
+					"description": "Sub-agents to run concurrently",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"role":          map[string]interface{}{"type": "string", "description": "Short role name, e.g. researcher"},
+							"task":          map[string]interface{}{"type": "string", "description": "Self-contained task description"},
+							"system_prompt": map[string]interface{}{"type": "string", "description": "Optional instructions for the sub-agent"},
+							"tools": map[string]interface{}{
+								"type":        "array",
+								"items":       map[string]interface{}{"type": "string"},
+								"description": "Names of tools the sub-agent may use (default: none)",
+							},
+							"token_budget": map[string]interface{}{"type": "integer", "description": "Maximum tokens the sub-agent may spend"},
+						},
+						"required": []string{"role", "task"},
+					},
+				},
+			},
+			"required": []string{"agents"},
+		},
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			raw, err := json.Marshal(args["agents"])
+			if err != nil {
+				return nil, fmt.Errorf("invalid agents: %w", err)
+			}
+			var specs []SubAgentSpec
+			if err := json.Unmarshal(raw, &specs); err != nil {
+				return nil, fmt.Errorf("invalid agents: %w", err)
+			}
+
+			results, err := orchestratorFor(ctx, o).RunParallel(ctx, specs)
+			if err != nil {
+				return nil, err
+			}
+			return MergeResults(results), nil
+		},
+	})
+	return skill
+}
@@ -0,0 +1,235 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"go.uber.org/zap"
+)
+
+// newSubAgentTestAgent creates an agent backed by a fake LLM endpoint. The
+// endpoint calls toolName on the first turn (if set) and then answers with
+// the task it was given.
+func newSubAgentTestAgent(t *testing.T, toolName string, usage int) *Agent {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+
+		msg := llm.Message{Role: "assistant"}
+		last := req.Messages[len(req.Messages)-1]
+		if toolName != "" && last.Role == "user" {
+			msg.ToolCalls = []llm.ToolCall{{ID: "call_1", Type: "function"}}
+			msg.ToolCalls[0].Function.Name = toolName
+			msg.ToolCalls[0].Function.Arguments = "{}"
+		} else {
+			msg.Content = "done: " + req.Messages[1].Content + " / " + last.Content
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": msg}},
+			"usage":   map[string]int{"total_tokens": usage},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	client := llm.NewClient(config.Provider{BaseURL: server.URL, Model: "test"})
+	a := New(client, nil, nil, zap.NewNop(), nil)
+
+	registry := skills.NewRegistry(nil)
+	skill := skills.NewBaseSkill("test", "Test tools", "1.0.0")
+	skill.AddTool(skills.Tool{
+		Name:        "lookup",
+		Description: "Look something up",
+		Parameters:  map[string]interface{}{"type": "object"},
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return "lookup result", nil
+		},
+	})
+	registry.Register(skill)
+	a.SetSkillsRegistry(registry)
+
+	return a
+}
+
+func TestOrchestrator_RunParallel(t *testing.T) {
+	a := newSubAgentTestAgent(t, "", 10)
+	o := NewOrchestrator(a, SubAgentConfig{MaxConcurrency: 2})
+
+	results, err := o.RunParallel(context.Background(), []SubAgentSpec{
+		{Role: "researcher", Task: "find sources"},
+		{Role: "coder", Task: "write code"},
+		{Role: "reviewer", Task: "review code"},
+	})
+	if err != nil {
+		t.Fatalf("RunParallel failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	for i, want := range []string{"find sources", "write code", "review code"} {
+		if !strings.Contains(results[i].Output, want) {
+			t.Errorf("Result %d: expected output for %q, got %q", i, want, results[i].Output)
+		}
+		if results[i].TokensUsed != 10 {
+			t.Errorf("Result %d: expected 10 tokens, got %d", i, results[i].TokensUsed)
+		}
+	}
+
+	merged := MergeResults(results)
+	if !strings.Contains(merged, "## researcher") || !strings.Contains(merged, "## coder") {
+		t.Errorf("Merged output missing sections: %s", merged)
+	}
+}
+
+func TestOrchestrator_Limits(t *testing.T) {
+	a := newSubAgentTestAgent(t, "", 10)
+	o := NewOrchestrator(a, SubAgentConfig{MaxDepth: 1, MaxPerCall: 2})
+
+	if _, err := o.RunParallel(context.Background(), nil); err == nil {
+		t.Error("Expected error for empty spec list")
+	}
+
+	specs := []SubAgentSpec{{Task: "a"}, {Task: "b"}, {Task: "c"}}
+	if _, err := o.RunParallel(context.Background(), specs); err == nil {
+		t.Error("Expected error when exceeding per-call limit")
+	}
+
+	nested := context.WithValue(context.Background(), subAgentDepthKey{}, 1)
+	if _, err := o.RunParallel(nested, specs[:1]); err == nil {
+		t.Error("Expected error when depth limit is reached")
+	}
+}
+
+func TestOrchestrator_ToolSubset(t *testing.T) {
+	a := newSubAgentTestAgent(t, "lookup", 10)
+	o := NewOrchestrator(a, SubAgentConfig{})
+
+	allowed := o.Run(context.Background(), SubAgentSpec{Role: "researcher", Task: "look", Tools: []string{"lookup"}})
+	if allowed.Error != "" {
+		t.Fatalf("Unexpected error: %s", allowed.Error)
+	}
+	if allowed.ToolCalls != 1 || !strings.Contains(allowed.Output, "lookup result") {
+		t.Errorf("Expected tool result in output, got %q (%d calls)", allowed.Output, allowed.ToolCalls)
+	}
+
+	denied := o.Run(context.Background(), SubAgentSpec{Role: "coder", Task: "look"})
+	if !strings.Contains(denied.Output, "not available") {
+		t.Errorf("Expected tool outside subset to be refused, got %q", denied.Output)
+	}
+}
+
+func TestOrchestrator_TokenBudget(t *testing.T) {
+	a := newSubAgentTestAgent(t, "lookup", 500)
+	o := NewOrchestrator(a, SubAgentConfig{})
+
+	result := o.Run(context.Background(), SubAgentSpec{Task: "look", Tools: []string{"lookup"}, TokenBudget: 100})
+	if !result.Truncated {
+		t.Error("Expected sub-agent to stop when its token budget is spent")
+	}
+	if result.ToolCalls != 0 {
+		t.Errorf("Expected no tool calls after budget exhaustion, got %d", result.ToolCalls)
+	}
+}
+
+func TestAgent_EnableSubAgents(t *testing.T) {
+	a := newSubAgentTestAgent(t, "", 10)
+
+	if err := a.EnableSubAgents(DefaultSubAgentConfig()); err != nil {
+		t.Fatalf("EnableSubAgents failed: %v", err)
+	}
+	// Enabling twice is a no-op
+	if err := a.EnableSubAgents(DefaultSubAgentConfig()); err != nil {
+		t.Fatalf("Second EnableSubAgents failed: %v", err)
+	}
+
+	args, _ := json.Marshal(map[string]interface{}{
+		"agents": []map[string]interface{}{{"role": "researcher", "task": "summarise"}},
+	})
+	out, err := a.skillsRegistry.ExecuteTool(context.Background(), SpawnSubAgentTool, args)
+	if err != nil {
+		t.Fatalf("spawn_subagent failed: %v", err)
+	}
+	if !strings.Contains(out.(string), "summarise") {
+		t.Errorf("Unexpected tool output: %v", out)
+	}
+}
+
+func TestOrchestrator_NestedSpawn(t *testing.T) {
+	// The outer sub-agent spawns an inner one and waits for it, with one
+	// slot per depth
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		msg := llm.Message{Role: "assistant"}
+		last := req.Messages[len(req.Messages)-1]
+		if req.Messages[1].Content == "delegate" && last.Role == "user" {
+			tc := llm.ToolCall{ID: "call_1", Type: "function"}
+			tc.Function.Name = SpawnSubAgentTool
+			tc.Function.Arguments = `{"agents":[{"role":"helper","task":"inner work"}]}`
+			msg.ToolCalls = []llm.ToolCall{tc}
+		} else {
+			msg.Content = "done: " + last.Content
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": msg}},
+		})
+	}))
+	defer server.Close()
+
+	a := New(llm.NewClient(config.Provider{BaseURL: server.URL, Model: "test"}), nil, nil, zap.NewNop(), nil)
+	a.SetSkillsRegistry(skills.NewRegistry(nil))
+	cfg := SubAgentConfig{MaxDepth: 2, MaxConcurrency: 1, Timeout: 5 * time.Second}
+	if err := a.EnableSubAgents(cfg); err != nil {
+		t.Fatalf("EnableSubAgents failed: %v", err)
+	}
+
+	results, err := a.subAgents.RunParallel(context.Background(), []SubAgentSpec{
+		{Role: "lead", Task: "delegate", Tools: []string{SpawnSubAgentTool}},
+	})
+	if err != nil {
+		t.Fatalf("RunParallel failed: %v", err)
+	}
+	if results[0].Error != "" {
+		t.Fatalf("Expected the nested run to finish, got %s", results[0].Error)
+	}
+	if !strings.Contains(results[0].Output, "inner work") {
+		t.Errorf("Expected the inner result to reach the outer sub-agent, got %q", results[0].Output)
+	}
+}
+
+func TestAgent_EnableSubAgentsSharedRegistry(t *testing.T) {
+	first := newSubAgentTestAgent(t, "", 10)
+	second := newSubAgentTestAgent(t, "", 77)
+	second.SetSkillsRegistry(first.skillsRegistry)
+
+	for _, a := range []*Agent{first, second} {
+		if err := a.EnableSubAgents(DefaultSubAgentConfig()); err != nil {
+			t.Fatalf("EnableSubAgents failed: %v", err)
+		}
+	}
+
+	out, err := second.ExecuteTool(context.Background(), SpawnSubAgentTool, map[string]interface{}{
+		"agents": []map[string]interface{}{{"role": "researcher", "task": "summarise"}},
+	})
+	if err != nil {
+		t.Fatalf("spawn_subagent failed: %v", err)
+	}
+	// Only the second agent's model reports 77 tokens
+	if !strings.Contains(out.(string), "tokens: 77") {
+		t.Errorf("Expected the sub-agent to run on the calling agent, got %v", out)
+	}
+}
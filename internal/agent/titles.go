@@ -0,0 +1,184 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"go.uber.org/zap"
+)
+
+// DefaultTitle is what a conversation is called until it is titled
+const DefaultTitle = "New Conversation"
+
+const (
+	maxTitleRunes   = 60
+	titleExcerpt    = 500 // characters of each message shown to the title model
+	retitleMessages = 6   // recent messages judged for a topic shift
+	keepTitle       = "KEEP"
+)
+
+// SetTitles names conversations after their first exchange and, every
+// cfg.RetitleAfter messages, renames those that have changed topic
+func (a *Agent) SetTitles(cfg config.TitlesConfig) {
+	a.titles = cfg
+}
+
+// RenameConversation gives a conversation the user's title. It is never
+// replaced automatically.
+func (a *Agent) RenameConversation(convID, title string) error {
+	title = strings.Join(strings.Fields(title), " ")
+	if title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if err := a.store.RenameConversation(convID, title); err != nil {
+		return fmt.Errorf("conversation not found: %s", convID)
+	}
+	return nil
+}
+
+// titleConversation titles conv once its first exchange is saved, and
+// checks titled conversations for a new topic every RetitleAfter messages.
+// Titles the user set are left alone.
+func (a *Agent) titleConversation(ctx context.Context, conv store.Conversation, userMessage, reply string) {
+	if !a.titles.Enabled || conv.TitleManual {
+		return
+	}
+
+	var title string
+	var err error
+	switch {
+	case conv.Title == "" || conv.Title == DefaultTitle:
+		title, err = a.generateTitle(ctx, userMessage, reply)
+	case a.titles.RetitleAfter > 0 && conv.MessageCount-conv.TitledAt >= a.titles.RetitleAfter:
+		title, err = a.checkTopicShift(ctx, conv)
+	default:
+		return
+	}
+	if err != nil {
+		a.logger.Debug("Failed to title conversation", zap.String("conversation", conv.ID), zap.Error(err))
+		return
+	}
+	if title == "" {
+		title = conv.Title
+	}
+	if err := a.store.SetAutoTitle(conv.ID, title, conv.MessageCount); err != nil {
+		a.logger.Warn("Failed to save conversation title", zap.Error(err))
+	}
+}
+
+// generateTitle names a conversation from its first exchange
+func (a *Agent) generateTitle(ctx context.Context, userMessage, reply string) (string, error) {
+	prompt := fmt.Sprintf("Write a concise title, at most six words, for a conversation that starts like this.\n\nUser: %s", excerpt(userMessage))
+	if reply != "" {
+		prompt += "\n\nAssistant: " + excerpt(reply)
+	}
+	prompt += "\n\nReply with only the title."
+
+	answer, err := a.titleModel(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	title := cleanTitle(answer)
+	if title == "" {
+		return "", fmt.Errorf("empty title")
+	}
+	return title, nil
+}
+
+// checkTopicShift asks whether the recent messages of conv still fit its
+// title, returning a new title if they don't and "" if they do
+func (a *Agent) checkTopicShift(ctx context.Context, conv store.Conversation) (string, error) {
+	msgs, err := a.store.GetMessages(conv.ID, -1, 0)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, m := range msgs {
+		if (m.Role == "user" || m.Role == "assistant") && strings.TrimSpace(m.Content) != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", roleLabel(m.Role), excerpt(m.Content)))
+		}
+	}
+	if len(lines) > retitleMessages {
+		lines = lines[len(lines)-retitleMessages:]
+	}
+
+	prompt := fmt.Sprintf("A conversation is titled %q. Its latest messages are:\n\n%s\n\n"+
+		"If the conversation has moved on to a clearly different topic, reply with a new concise title, at most six words. "+
+		"Otherwise reply %s.", conv.Title, strings.Join(lines, "\n\n"), keepTitle)
+	answer, err := a.titleModel(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	title := cleanTitle(answer)
+	if strings.EqualFold(strings.Trim(title, "."), keepTitle) {
+		return "", nil
+	}
+	return title, nil
+}
+
+// titleModel sends prompt to the model configured for titles
+func (a *Agent) titleModel(ctx context.Context, prompt string) (string, error) {
+	model := a.titles.Model
+	if model == "" {
+		model = a.llmClient.GetModel()
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := a.llmClient.ChatCompletion(ctx, llm.ChatRequest{
+		Model: model,
+		Messages: []llm.Message{
+			{Role: "system", Content: "You name conversations. Titles are short, specific and in the language of the conversation."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens: 30,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from model")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// cleanTitle keeps the first line of a model's answer without quotes,
+// Markdown, a "Title:" label or a closing period, shortened between words
+func cleanTitle(answer string) string {
+	const marks = "\"'`*_“” "
+	title, _, _ := strings.Cut(strings.TrimSpace(answer), "\n")
+	title = strings.Trim(title, marks)
+	if label, rest, ok := strings.Cut(title, ":"); ok && strings.EqualFold(strings.Trim(label, marks), "title") {
+		title = strings.Trim(rest, marks)
+	}
+	title = strings.TrimSuffix(title, ".")
+	title = strings.Join(strings.Fields(title), " ")
+	if runes := []rune(title); len(runes) > maxTitleRunes {
+		title = string(runes[:maxTitleRunes-3])
+		if i := strings.LastIndex(title, " "); i > 0 {
+			title = title[:i]
+		}
+		title += "..."
+	}
+	return title
+}
+
+func excerpt(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > titleExcerpt {
+		text = string(runes[:titleExcerpt]) + "..."
+	}
+	return text
+}
+
+func roleLabel(role string) string {
+	if role == "user" {
+		return "User"
+	}
+	return "Assistant"
+}
@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"go.uber.org/zap"
+)
+
+// newTitleTestAgent answers every prompt with answer and records the models
+// asked
+func newTitleTestAgent(t *testing.T, answer *string) (*Agent, *store.Store, *[]string) {
+	t.Helper()
+
+	var models []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		models = append(models, req.Model)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": llm.Message{Role: "assistant", Content: *answer}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	st := testutil.NewTestStore(t)
+	t.Cleanup(func() { st.Close() })
+
+	a := New(llm.NewClient(config.Provider{BaseURL: server.URL, Model: "big"}), nil, st, zap.NewNop(), nil)
+	a.SetTitles(config.TitlesConfig{Enabled: true, Model: "small", RetitleAfter: 4})
+	return a, st, &models
+}
+
+func TestAgent_TitleConversation(t *testing.T) {
+	answer := `Title: "Booking a Lisbon trip."`
+	a, st, models := newTitleTestAgent(t, &answer)
+	ctx := context.Background()
+
+	conv := &store.Conversation{Title: DefaultTitle, MessageCount: 2}
+	if err := st.CreateConversation(conv); err != nil {
+		t.Fatal(err)
+	}
+
+	a.titleConversation(ctx, *conv, "Find me flights to Lisbon in May", "Here are three options")
+	got, _ := st.GetConversation(conv.ID)
+	if got.Title != "Booking a Lisbon trip" || got.TitledAt != 2 {
+		t.Errorf("Expected a cleaned title from the first exchange, got %q at %d", got.Title, got.TitledAt)
+	}
+	if len(*models) != 1 || (*models)[0] != "small" {
+		t.Errorf("Expected the titles model to be asked, got %v", *models)
+	}
+
+	// Not yet RetitleAfter messages later
+	got.MessageCount = 4
+	a.titleConversation(ctx, *got, "", "")
+	if len(*models) != 1 {
+		t.Errorf("Expected no topic check before retitle_after messages, got %d calls", len(*models))
+	}
+
+	for _, m := range []struct{ role, content string }{
+		{"user", "Find me flights to Lisbon in May"},
+		{"assistant", "Here are three options"},
+		{"user", "Unrelated, how do I file my taxes?"},
+		{"assistant", "Start with last year's return"},
+	} {
+		st.CreateMessage(&store.Message{ConversationID: conv.ID, Role: m.role, Content: m.content})
+	}
+
+	// No new topic: the title stays, and the next check counts from here
+	answer = "KEEP"
+	got.MessageCount = 6
+	a.titleConversation(ctx, *got, "", "")
+	got, _ = st.GetConversation(conv.ID)
+	if got.Title != "Booking a Lisbon trip" || got.TitledAt != 6 {
+		t.Errorf("Expected the title kept and checked at 6, got %q at %d", got.Title, got.TitledAt)
+	}
+
+	answer = "Filing taxes"
+	got.MessageCount = 10
+	a.titleConversation(ctx, *got, "", "")
+	got, _ = st.GetConversation(conv.ID)
+	if got.Title != "Filing taxes" {
+		t.Errorf("Expected a new title after the topic shift, got %q", got.Title)
+	}
+
+	// The user's title is never replaced
+	if err := a.RenameConversation(conv.ID, "  My   taxes "); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = st.GetConversation(conv.ID)
+	answer = "Something else"
+	got.MessageCount = 40
+	a.titleConversation(ctx, *got, "", "")
+	if err := st.SetAutoTitle(conv.ID, "Something else", 40); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = st.GetConversation(conv.ID)
+	if got.Title != "My taxes" || !got.TitleManual {
+		t.Errorf("Expected the manual title kept, got %q", got.Title)
+	}
+
+	if err := a.RenameConversation("conv_missing", "x"); err == nil {
+		t.Error("Expected renaming an unknown conversation to fail")
+	}
+	if err := a.RenameConversation(conv.ID, " "); err == nil {
+		t.Error("Expected an empty title to be rejected")
+	}
+}
+
+func TestCleanTitle(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   string
+	}{
+		{"Weekend hiking plans", "Weekend hiking plans"},
+		{"\"Debugging the Go build.\"\n\nThis title captures...", "Debugging the Go build"},
+		{"**Title:** Sourdough starter", "Sourdough starter"},
+		{strings.Repeat("word ", 20), strings.TrimSpace(strings.Repeat("word ", 11)) + "..."},
+	}
+	for _, tt := range tests {
+		if got := cleanTitle(tt.answer); got != tt.want {
+			t.Errorf("cleanTitle(%q) = %q, want %q", tt.answer, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"go.uber.org/zap"
+)
+
+// Models that can't take tools natively are told about them in the system
+// prompt and asked to call them in tool_call blocks. Their replies are parsed
+// back into tool calls, so the rest of the loop doesn't know the difference.
+
+// toolPromptInstruction tells a prompted model how to call tools
+const toolPromptInstruction = `To call a tool, first think step by step about what you need, then reply with one block per call and nothing after them:
+<tool_call>
+{"name": "tool_name", "arguments": {"argument": "value"}}
+</tool_call>
+
+Results come back in <tool_result> blocks. Use them to continue, calling more tools if you need to. When you can answer, reply normally without any <tool_call> block. Only call the tools listed above, and always give the arguments as a JSON object.`
+
+// complete sends a request to the model. Tools are described in the prompt
+// for models that can't take them natively, including ones whose provider
+// turns out to reject them.
+func (a *Agent) complete(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	if !a.promptedTools() {
+		resp, err := a.llmClient.ChatCompletion(ctx, req)
+		if err == nil || len(req.Tools) == 0 || !toolsUnsupported(err) {
+			return resp, err
+		}
+		a.logger.Info("Model doesn't support tools, describing them in the prompt instead", zap.Error(err))
+		a.toolPrompting.Store(true)
+	}
+
+	resp, err := a.llmClient.ChatCompletion(ctx, promptTools(req))
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Tools) > 0 {
+		for i := range resp.Choices {
+			parseToolCalls(&resp.Choices[i].Message, req.Tools)
+		}
+	}
+	return resp, nil
+}
+
+// promptedTools reports whether tools are described in the prompt rather
+// than passed to the API
+func (a *Agent) promptedTools() bool {
+	return a.toolPrompting.Load() || a.llmClient.ToolCalling() == llm.ToolCallingPrompt
+}
+
+// toolsUnsupported reports whether the provider rejected a request because
+// the model can't take tools, e.g. Ollama's "does not support tools"
+func toolsUnsupported(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "status 400") && strings.Contains(msg, "support") &&
+		(strings.Contains(msg, "tool") || strings.Contains(msg, "function"))
+}
+
+// promptTools rewrites a request for a model without native tools: the tools
+// are listed in the system prompt, and earlier calls and results become text
+func promptTools(req llm.ChatRequest) llm.ChatRequest {
+	tools := req.Tools
+	req.Tools = nil
+	req.ToolChoice = nil
+	req.ParallelToolCalls = false
+
+	names := make(map[string]string) // tool call ID to tool name
+	messages := make([]llm.Message, 0, len(req.Messages)+1)
+	for _, msg := range req.Messages {
+		switch {
+		case msg.Role == "assistant" && len(msg.ToolCalls) > 0:
+			var sb strings.Builder
+			if msg.Content != "" {
+				sb.WriteString(msg.Content + "\n")
+			}
+			for _, tc := range msg.ToolCalls {
+				names[tc.ID] = tc.Function.Name
+				call, _ := json.Marshal(map[string]interface{}{
+					"name":      tc.Function.Name,
+					"arguments": json.RawMessage(toolArguments(tc.Function.Arguments)),
+				})
+				fmt.Fprintf(&sb, "<tool_call>\n%s\n</tool_call>\n", call)
+			}
+			msg.Content = strings.TrimSpace(sb.String())
+			msg.ToolCalls = nil
+		case msg.Role == "tool":
+			name := msg.Name
+			if name == "" {
+				name = names[msg.ToolCallID]
+			}
+			msg = llm.Message{
+				Role:    "user",
+				Content: fmt.Sprintf("<tool_result name=%q>\n%s\n</tool_result>", name, msg.Content),
+			}
+		}
+		messages = append(messages, msg)
+	}
+
+	if len(tools) > 0 {
+		prompt := describeTools(tools)
+		if len(messages) > 0 && messages[0].Role == "system" {
+			messages[0].Content += "\n\n" + prompt
+		} else {
+			messages = append([]llm.Message{{Role: "system", Content: prompt}}, messages...)
+		}
+	}
+	req.Messages = messages
+	return req
+}
+
+// describeTools lists the tools and how to call them
+func describeTools(tools []llm.Tool) string {
+	var sb strings.Builder
+	sb.WriteString("You can use these tools. Each is given with the JSON schema of its arguments:\n")
+	for _, tool := range tools {
+		params, _ := json.Marshal(tool.Function.Parameters)
+		fmt.Fprintf(&sb, "\n- %s: %s\n  arguments: %s\n", tool.Function.Name, tool.Function.Description, params)
+	}
+	sb.WriteString("\n" + toolPromptInstruction)
+	return sb.String()
+}
+
+// toolArguments returns a call's arguments as JSON, using an empty object
+// for missing or broken ones
+func toolArguments(arguments string) string {
+	if args := strings.TrimSpace(arguments); args != "" && json.Valid([]byte(args)) {
+		return args
+	}
+	return "{}"
+}
+
+var (
+	// toolCallBlock matches a tool_call block; models often leave the last
+	// one unclosed when they stop
+	toolCallBlock = regexp.MustCompile(`(?s)<tool_call>\s*(.*?)\s*(?:</tool_call>|$)`)
+	// jsonFence matches a JSON code block, which some models use instead
+	jsonFence = regexp.MustCompile("(?s)```(?:json)?\\s*(\\{.*?\\})\\s*```")
+	// reactAction matches the ReAct format: Action: name / Action Input: {...}
+	reactAction = regexp.MustCompile(`(?is)\bAction:\s*([\w.-]+)\s*\n\s*Action Input:\s*(\{.*\}|.*)$`)
+	// trailingComma matches commas JSON doesn't allow
+	trailingComma = regexp.MustCompile(`,\s*([}\]])`)
+)
+
+// parseToolCalls turns tool calls written in a prompted model's reply into
+// the message's tool calls, leaving the rest of the reply as its content.
+// tool_call blocks are taken as calls whatever they name, so the model hears
+// about mistakes; looser formats only count when they name a known tool.
+func parseToolCalls(msg *llm.Message, tools []llm.Tool) {
+	known := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		known[tool.Function.Name] = true
+	}
+
+	content := msg.Content
+	var calls []llm.ToolCall
+	add := func(name string, args json.RawMessage) {
+		tc := llm.ToolCall{ID: fmt.Sprintf("call_%d_%d", time.Now().UnixNano(), len(calls)), Type: "function"}
+		tc.Function.Name = name
+		tc.Function.Arguments = string(args)
+		calls = append(calls, tc)
+	}
+
+	if blocks := toolCallBlock.FindAllStringSubmatchIndex(content, -1); blocks != nil {
+		for _, b := range blocks {
+			if name, args, ok := parseToolCall(content[b[2]:b[3]]); ok {
+				add(name, args)
+			}
+		}
+		content = toolCallBlock.ReplaceAllString(content, "")
+	} else if m := reactAction.FindStringSubmatchIndex(content); m != nil && known[content[m[2]:m[3]]] {
+		args, err := repairJSON(content[m[4]:m[5]])
+		if err != nil {
+			args = json.RawMessage("{}")
+		}
+		add(content[m[2]:m[3]], args)
+		content = content[:m[0]]
+	} else {
+		candidates := jsonFence.FindAllStringSubmatch(content, -1)
+		if trimmed := strings.TrimSpace(content); strings.HasPrefix(trimmed, "{") {
+			candidates = append(candidates, []string{trimmed, trimmed})
+		}
+		for _, c := range candidates {
+			if name, args, ok := parseToolCall(c[1]); ok && known[name] {
+				add(name, args)
+				content = strings.Replace(content, c[0], "", 1)
+				break
+			}
+		}
+	}
+
+	if len(calls) > 0 {
+		msg.ToolCalls = calls
+		msg.Content = strings.TrimSpace(content)
+	}
+}
+
+// parseToolCall reads a call written as JSON, accepting the key names
+// models commonly use
+func parseToolCall(text string) (string, json.RawMessage, bool) {
+	raw, err := repairJSON(text)
+	if err != nil {
+		return "", nil, false
+	}
+	var call map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &call); err != nil {
+		return "", nil, false
+	}
+
+	var name string
+	for _, key := range []string{"name", "tool", "function", "action"} {
+		if json.Unmarshal(call[key], &name) == nil && name != "" {
+			break
+		}
+	}
+	if name == "" {
+		return "", nil, false
+	}
+
+	args := json.RawMessage("{}")
+	for _, key := range []string{"arguments", "parameters", "args", "input", "action_input"} {
+		value := call[key]
+		if len(value) == 0 {
+			continue
+		}
+		// Some models give the arguments as a string of JSON
+		var encoded string
+		if json.Unmarshal(value, &encoded) == nil {
+			if fixed, err := repairJSON(encoded); err == nil {
+				value = fixed
+			}
+		}
+		if strings.HasPrefix(string(value), "{") {
+			args = value
+		}
+		break
+	}
+	return name, args, true
+}
+
+// repairJSON extracts a JSON object from text, fixing the mistakes small
+// models make: surrounding text, trailing commas, single quotes and missing
+// closing braces
+func repairJSON(text string) (json.RawMessage, error) {
+	start := strings.Index(text, "{")
+	if start < 0 {
+		return nil, fmt.Errorf("no JSON object")
+	}
+	text = text[start:]
+	if end := strings.LastIndex(text, "}"); end >= 0 {
+		text = text[:end+1]
+	}
+
+	for _, fix := range []func(string) string{
+		func(s string) string { return s },
+		func(s string) string { return trailingComma.ReplaceAllString(s, "$1") },
+		func(s string) string {
+			if strings.Contains(s, `"`) {
+				return s
+			}
+			return strings.ReplaceAll(s, "'", `"`)
+		},
+	} {
+		text = fix(text)
+		if args, err := llm.ParseToolArguments(text); err == nil {
+			return json.Marshal(args)
+		}
+	}
+	return nil, fmt.Errorf("invalid JSON object")
+}
@@ -0,0 +1,154 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"go.uber.org/zap"
+)
+
+func TestParseToolCalls(t *testing.T) {
+	tools := []llm.Tool{{Type: "function", Function: llm.ToolFunction{Name: "weather"}}}
+
+	tests := []struct {
+		name    string
+		reply   string
+		calls   []string // name and arguments of each call
+		content string
+	}{
+		{"tagged", "I'll check.\n<tool_call>\n{\"name\": \"weather\", \"arguments\": {\"city\": \"Oslo\"}}\n</tool_call>",
+			[]string{`weather {"city":"Oslo"}`}, "I'll check."},
+		{"several, last unclosed", "<tool_call>{\"name\": \"weather\", \"arguments\": {\"city\": \"Oslo\"}}</tool_call>\n<tool_call>{\"name\": \"weather\", \"arguments\": {\"city\": \"Rome\",}",
+			[]string{`weather {"city":"Oslo"}`, `weather {"city":"Rome"}`}, ""},
+		{"unknown tool in a block", "<tool_call>{\"name\": \"stocks\"}</tool_call>", []string{`stocks {}`}, ""},
+		{"single quotes and string arguments", "<tool_call>{'tool': 'weather', 'parameters': '{}'}</tool_call>", []string{`weather {}`}, ""},
+		{"fenced", "Let me look.\n```json\n{\"name\": \"weather\", \"arguments\": {\"city\": \"Oslo\"}}\n```",
+			[]string{`weather {"city":"Oslo"}`}, "Let me look."},
+		{"bare JSON", `{"name": "weather", "arguments": {"city": "Oslo"}}`, []string{`weather {"city":"Oslo"}`}, ""},
+		{"ReAct", "Thought: I need the forecast.\nAction: weather\nAction Input: {\"city\": \"Oslo\"}",
+			[]string{`weather {"city":"Oslo"}`}, "Thought: I need the forecast."},
+		{"answer", "It's sunny in Oslo.", nil, "It's sunny in Oslo."},
+		{"JSON answer naming no tool", `{"name": "Oslo", "country": "Norway"}`, nil, `{"name": "Oslo", "country": "Norway"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := llm.Message{Role: "assistant", Content: tt.reply}
+			parseToolCalls(&msg, tools)
+
+			var calls []string
+			for _, tc := range msg.ToolCalls {
+				if tc.ID == "" {
+					t.Error("Expected the call to have an ID")
+				}
+				calls = append(calls, tc.Function.Name+" "+tc.Function.Arguments)
+			}
+			if strings.Join(calls, "|") != strings.Join(tt.calls, "|") {
+				t.Errorf("Expected calls %q, got %q", tt.calls, calls)
+			}
+			if msg.Content != tt.content {
+				t.Errorf("Expected content %q, got %q", tt.content, msg.Content)
+			}
+		})
+	}
+}
+
+func TestPromptTools(t *testing.T) {
+	call := llm.ToolCall{ID: "call_1", Type: "function"}
+	call.Function.Name = "weather"
+	call.Function.Arguments = `{"city":"Oslo"}`
+
+	req := promptTools(llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: "system", Content: "You are helpful."},
+			{Role: "user", Content: "Weather in Oslo?"},
+			{Role: "assistant", ToolCalls: []llm.ToolCall{call}},
+			{Role: "tool", ToolCallID: "call_1", Content: "Sunny"},
+		},
+		Tools:             []llm.Tool{{Type: "function", Function: llm.ToolFunction{Name: "weather", Description: "Get the forecast"}}},
+		ParallelToolCalls: true,
+	})
+
+	if req.Tools != nil || req.ParallelToolCalls {
+		t.Error("Expected the tools to be left out of the request")
+	}
+	if !strings.Contains(req.Messages[0].Content, "- weather: Get the forecast") || !strings.Contains(req.Messages[0].Content, "<tool_call>") {
+		t.Errorf("Expected the tools in the system prompt, got %q", req.Messages[0].Content)
+	}
+	if got := req.Messages[2]; len(got.ToolCalls) != 0 || got.Content != "<tool_call>\n{\"arguments\":{\"city\":\"Oslo\"},\"name\":\"weather\"}\n</tool_call>" {
+		t.Errorf("Expected the call written out, got %+v", got)
+	}
+	if got := req.Messages[3]; got.Role != "user" || got.Content != "<tool_result name=\"weather\">\nSunny\n</tool_result>" {
+		t.Errorf("Expected the result from the user, got %+v", got)
+	}
+}
+
+func TestAgent_PromptedToolFallback(t *testing.T) {
+	var requests []llm.ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		if len(req.Tools) > 0 {
+			http.Error(w, `{"error":"registry.ollama.ai/library/gemma2:9b does not support tools"}`, http.StatusBadRequest)
+			return
+		}
+
+		content := "<tool_call>\n{\"name\": \"lookup\", \"arguments\": {}}\n</tool_call>"
+		if last := req.Messages[len(req.Messages)-1]; strings.HasPrefix(last.Content, "<tool_result") {
+			content = "The answer is " + strings.Split(last.Content, "\n")[1]
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": llm.Message{Role: "assistant", Content: content}}},
+		})
+	}))
+	defer server.Close()
+
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+	a := New(llm.NewClient(config.Provider{BaseURL: server.URL, Model: "my-model"}), nil, st, zap.NewNop(), nil)
+	registry := skills.NewRegistry(nil)
+	skill := skills.NewBaseSkill("test", "Test tools", "1.0.0")
+	skill.AddTool(skills.Tool{
+		Name:        "lookup",
+		Description: "Look something up",
+		Parameters:  map[string]interface{}{"type": "object"},
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return "42", nil
+		},
+	})
+	registry.Register(skill)
+	a.SetSkillsRegistry(registry)
+
+	resp, err := a.Chat(context.Background(), ChatRequest{Message: "look it up"})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if resp.Content != "The answer is 42" || len(resp.ToolCalls) != 1 {
+		t.Errorf("Expected the prompted tool call to run, got %q with %d calls", resp.Content, len(resp.ToolCalls))
+	}
+	if len(requests) != 3 {
+		t.Fatalf("Expected a native attempt and two prompted requests, got %d", len(requests))
+	}
+
+	// The provider isn't offered native tools again
+	requests = nil
+	if _, err := a.Chat(context.Background(), ChatRequest{Message: "again"}); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if len(requests[0].Tools) != 0 {
+		t.Error("Expected later requests to describe tools in the prompt")
+	}
+
+	if !llm.SupportsTools("qwen2.5:7b") || llm.SupportsTools("gemma2:9b") || llm.SupportsTools("llama3") || !llm.SupportsTools("llama3.1:8b") {
+		t.Error("Unexpected native tool support for known models")
+	}
+}
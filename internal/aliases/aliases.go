@@ -0,0 +1,275 @@
+// Package aliases provides user-defined shortcuts that expand into full prompts.
+package aliases
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/strutil"
+	"gorm.io/gorm"
+)
+
+// DefaultUser owns aliases created from the CLI. Aliases owned by the default
+// user are visible in every channel unless a user defines their own alias
+// with the same name.
+const DefaultUser = "default"
+
+// PrefixAlias is the ID prefix for aliases
+const PrefixAlias = "alias"
+
+var (
+	namePattern        = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+	placeholderPattern = regexp.MustCompile(`\$(@|[1-9])|\{\{\s*args\s*\}\}`)
+)
+
+// Alias is a named prompt template
+type Alias struct {
+	ID        string    `gorm:"primaryKey" json:"id"`
+	UserID    string    `gorm:"uniqueIndex:idx_alias_user_name;not null" json:"user_id"`
+	Name      string    `gorm:"uniqueIndex:idx_alias_user_name;not null" json:"name"`
+	Template  string    `gorm:"type:text;not null" json:"template"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Manager handles alias persistence and expansion
+type Manager struct {
+	db *gorm.DB
+}
+
+func init() {
+	store.RegisterMigrations("aliases", store.Migration{
+		Version: 1,
+		Name:    "create aliases table",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Alias{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Alias{})
+		},
+	})
+}
+
+// NewManager creates a new alias manager
+func NewManager(db *gorm.DB) (*Manager, error) {
+	if err := store.Migrate(db, "aliases"); err != nil {
+		return nil, fmt.Errorf("failed to migrate alias schema: %w", err)
+	}
+	return &Manager{db: db}, nil
+}
+
+// ValidName reports whether name can be used as an alias
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Add creates or replaces an alias for a user
+func (m *Manager) Add(userID, name, template string) (*Alias, error) {
+	if userID == "" {
+		userID = DefaultUser
+	}
+	name = strings.ToLower(strings.TrimPrefix(name, "/"))
+	if !ValidName(name) {
+		return nil, fmt.Errorf("invalid alias name %q: use lowercase letters, digits, '-' or '_' (max 32 chars)", name)
+	}
+	template = strings.TrimSpace(template)
+	if template == "" {
+		return nil, fmt.Errorf("alias %q needs a non-empty expansion", name)
+	}
+
+	var alias Alias
+	err := m.db.Where("user_id = ? AND name = ?", userID, name).First(&alias).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		alias = Alias{
+			ID:       idgen.Generate(PrefixAlias),
+			UserID:   userID,
+			Name:     name,
+			Template: template,
+		}
+		if err := m.db.Create(&alias).Error; err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		alias.Template = template
+		if err := m.db.Save(&alias).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &alias, nil
+}
+
+// Remove deletes a user's alias
+func (m *Manager) Remove(userID, name string) error {
+	if userID == "" {
+		userID = DefaultUser
+	}
+	result := m.db.Where("user_id = ? AND name = ?", userID, strings.ToLower(name)).Delete(&Alias{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("alias not found: %s", name)
+	}
+	return nil
+}
+
+// List returns the aliases visible to a user: their own plus any default
+// aliases they have not overridden.
+func (m *Manager) List(userID string) ([]Alias, error) {
+	if userID == "" {
+		userID = DefaultUser
+	}
+	var all []Alias
+	if err := m.db.Where("user_id IN ?", []string{userID, DefaultUser}).Order("name ASC").Find(&all).Error; err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]int)
+	var result []Alias
+	for _, a := range all {
+		if idx, ok := seen[a.Name]; ok {
+			if a.UserID == userID {
+				result[idx] = a
+			}
+			continue
+		}
+		seen[a.Name] = len(result)
+		result = append(result, a)
+	}
+	return result, nil
+}
+
+// Get looks up an alias for a user, falling back to the default user.
+// It returns nil when no alias exists.
+func (m *Manager) Get(userID, name string) (*Alias, error) {
+	name = strings.ToLower(name)
+	if !ValidName(name) {
+		return nil, nil
+	}
+	owners := []string{DefaultUser}
+	if userID != "" && userID != DefaultUser {
+		owners = []string{userID, DefaultUser}
+	}
+	for _, owner := range owners {
+		var alias Alias
+		err := m.db.Where("user_id = ? AND name = ?", owner, name).First(&alias).Error
+		if err == gorm.ErrRecordNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &alias, nil
+	}
+	return nil, nil
+}
+
+// Resolve looks up an alias and expands it with args. The second return value
+// is false when no alias with that name exists.
+func (m *Manager) Resolve(userID, name string, args []string) (string, bool, error) {
+	alias, err := m.Get(userID, name)
+	if err != nil || alias == nil {
+		return "", false, err
+	}
+	return Expand(alias.Template, args), true, nil
+}
+
+// Expand substitutes arguments into a template. $1..$9 refer to individual
+// arguments and $@ or {{args}} to all of them. Templates without
+// placeholders get the arguments appended.
+func Expand(template string, args []string) string {
+	if !placeholderPattern.MatchString(template) {
+		if len(args) == 0 {
+			return template
+		}
+		return template + " " + strings.Join(args, " ")
+	}
+
+	expanded := placeholderPattern.ReplaceAllStringFunc(template, func(match string) string {
+		if match == "$@" || strings.HasPrefix(match, "{{") {
+			return strings.Join(args, " ")
+		}
+		n, _ := strconv.Atoi(match[1:])
+		if n <= len(args) {
+			return args[n-1]
+		}
+		return ""
+	})
+	return strings.TrimSpace(expanded)
+}
+
+// Command runs an "/alias" chat command for a user and returns the reply.
+// Supported forms are "add <name> <prompt>", "remove <name>" and "list".
+func (m *Manager) Command(userID, args string) string {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		fields = []string{"list"}
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "add", "set":
+		if len(fields) < 3 {
+			return "Usage: /alias add <name> <prompt>\n\nUse $1..$9 or $@ in the prompt to insert arguments."
+		}
+		alias, err := m.Add(userID, fields[1], afterFields(args, 2))
+		if err != nil {
+			return fmt.Sprintf("❌ %v", err)
+		}
+		return fmt.Sprintf("✅ Alias /%s saved", alias.Name)
+
+	case "remove", "rm", "delete":
+		if len(fields) < 2 {
+			return "Usage: /alias remove <name>"
+		}
+		if err := m.Remove(userID, fields[1]); err != nil {
+			return fmt.Sprintf("❌ %v", err)
+		}
+		return fmt.Sprintf("✅ Alias /%s removed", strings.ToLower(fields[1]))
+
+	case "list", "ls":
+		list, err := m.List(userID)
+		if err != nil {
+			return "❌ Failed to load aliases."
+		}
+		return FormatList(list)
+	}
+
+	return "Usage: /alias add <name> <prompt> | /alias remove <name> | /alias list"
+}
+
+// FormatList renders aliases for display in a channel
+func FormatList(list []Alias) string {
+	if len(list) == 0 {
+		return "📭 No aliases defined. Use /alias add <name> <prompt> to create one."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("⚡ Aliases\n\n")
+	for _, a := range list {
+		sb.WriteString(fmt.Sprintf("/%s - %s\n", a.Name, strutil.Truncate(a.Template, 80)))
+	}
+	return sb.String()
+}
+
+// afterFields returns s with its first n whitespace-separated fields removed,
+// preserving the spacing of the remainder.
+func afterFields(s string, n int) string {
+	s = strings.TrimSpace(s)
+	for i := 0; i < n; i++ {
+		idx := strings.IndexFunc(s, unicode.IsSpace)
+		if idx < 0 {
+			return ""
+		}
+		s = strings.TrimSpace(s[idx:])
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+package aliases
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTestManager(t *testing.T) *Manager {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	m, err := NewManager(db)
+	require.NoError(t, err)
+	return m
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		template string
+		args     []string
+		want     string
+	}{
+		{"Summarize my day", nil, "Summarize my day"},
+		{"Summarize my day", []string{"briefly"}, "Summarize my day briefly"},
+		{"Translate $2 into $1", []string{"French", "hello"}, "Translate hello into French"},
+		{"Search for $@ on GitHub", []string{"go", "fiber"}, "Search for go fiber on GitHub"},
+		{"Explain {{args}}", []string{"closures"}, "Explain closures"},
+		{"Weather in $1", nil, "Weather in"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Expand(tt.template, tt.args), tt.template)
+	}
+}
+
+func TestManager_AddResolveRemove(t *testing.T) {
+	m := setupTestManager(t)
+
+	_, err := m.Add("", "standup", "Summarize my completed tasks from yesterday and today's calendar")
+	require.NoError(t, err)
+
+	_, err = m.Add("", "Bad Name", "x")
+	assert.Error(t, err)
+
+	// Default aliases are visible to every user
+	text, ok, err := m.Resolve("telegram:42", "standup", nil)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, text, "completed tasks")
+
+	// A user's own alias overrides the default one
+	_, err = m.Add("telegram:42", "standup", "Just my tasks for $1")
+	require.NoError(t, err)
+	text, _, err = m.Resolve("telegram:42", "standup", []string{"today"})
+	require.NoError(t, err)
+	assert.Equal(t, "Just my tasks for today", text)
+
+	list, err := m.List("telegram:42")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "telegram:42", list[0].UserID)
+
+	// Re-adding replaces the template
+	_, err = m.Add("", "standup", "Updated")
+	require.NoError(t, err)
+	text, _, _ = m.Resolve("", "standup", nil)
+	assert.Equal(t, "Updated", text)
+
+	require.NoError(t, m.Remove("telegram:42", "standup"))
+	assert.Error(t, m.Remove("telegram:42", "standup"))
+
+	_, ok, err = m.Resolve("", "missing", nil)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestManager_Command(t *testing.T) {
+	m := setupTestManager(t)
+
+	reply := m.Command("discord:7", "add a Summarize   $@ for me")
+	assert.Contains(t, reply, "/a saved")
+
+	text, ok, err := m.Resolve("discord:7", "a", []string{"today"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "Summarize   today for me", text)
+
+	assert.Contains(t, m.Command("discord:7", "list"), "/a - Summarize")
+	assert.Contains(t, m.Command("discord:7", "remove a"), "removed")
+	assert.Contains(t, m.Command("discord:7", ""), "No aliases")
+}
+
+func TestFormatList_TruncatesByRune(t *testing.T) {
+	out := FormatList([]Alias{{Name: "jp", Template: strings.Repeat("日本", 50)}})
+	assert.True(t, utf8.ValidString(out))
+	assert.Contains(t, out, "/jp - "+strings.Repeat("日本", 38)+"日...")
+}
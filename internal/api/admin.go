@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/gmsas95/myrai-cli/internal/channels"
+	"github.com/gmsas95/myrai-cli/internal/cron"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SetChannels lets the admin endpoints report on the chat channels; health
+// is asked each time, as channels start in the background
+func (s *Server) SetChannels(health func() []channels.Health) {
+	s.channelHealth = health
+}
+
+// SetCronRunner lets the admin endpoints show and control scheduled jobs and
+// background tasks
+func (s *Server) SetCronRunner(runner *cron.Runner) {
+	s.cronRunner = runner
+}
+
+func (s *Server) handleAdminChannels(c *fiber.Ctx) error {
+	health := []channels.Health{}
+	if s.channelHealth != nil {
+		health = append(health, s.channelHealth()...)
+	}
+	return c.JSON(fiber.Map{"channels": health})
+}
+
+// handleAdminCron lists the scheduled jobs with their next runs, and the
+// background tasks. Jobs are listed without the runner too, as they are
+// kept in the database.
+func (s *Server) handleAdminCron(c *fiber.Ctx) error {
+	jobs, err := s.store.ListJobs()
+	if err != nil {
+		s.logger.Error("Failed to list jobs", zap.Error(err))
+		return c.Status(500).JSON(fiber.Map{"error": "failed to list jobs"})
+	}
+	tasks := []cron.TaskInfo{}
+	running := false
+	if s.cronRunner != nil {
+		tasks = s.cronRunner.Tasks()
+		running = s.cronRunner.IsRunning()
+	}
+	return c.JSON(fiber.Map{"running": running, "jobs": jobs, "tasks": tasks})
+}
+
+func (s *Server) handleAdminRunJob(c *fiber.Ctx) error {
+	if s.cronRunner == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "cron runner not enabled"})
+	}
+	job, err := s.cronRunner.RunJob(c.Params("id"))
+	if err != nil {
+		return s.jobError(c, err)
+	}
+	return c.Status(202).JSON(job)
+}
+
+func (s *Server) handleAdminUpdateJob(c *fiber.Ctx) error {
+	if s.cronRunner == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "cron runner not enabled"})
+	}
+	var req struct {
+		Active *bool `json:"active"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.Active == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "active is required"})
+	}
+	job, err := s.cronRunner.SetJobActive(c.Params("id"), *req.Active)
+	if err != nil {
+		return s.jobError(c, err)
+	}
+	return c.JSON(job)
+}
+
+func (s *Server) jobError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "job not found"})
+	}
+	return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+}
+
+func (s *Server) handleAdminRunTask(c *fiber.Ctx) error {
+	if s.cronRunner == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "cron runner not enabled"})
+	}
+	if err := s.cronRunner.RunTask(c.Params("name")); err != nil {
+		return taskError(c, err)
+	}
+	return c.Status(202).JSON(fiber.Map{"name": c.Params("name"), "running": true})
+}
+
+func (s *Server) handleAdminUpdateTask(c *fiber.Ctx) error {
+	if s.cronRunner == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "cron runner not enabled"})
+	}
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.Enabled == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "enabled is required"})
+	}
+	if err := s.cronRunner.SetTaskEnabled(c.Params("name"), *req.Enabled); err != nil {
+		return taskError(c, err)
+	}
+	return c.JSON(fiber.Map{"name": c.Params("name"), "enabled": *req.Enabled})
+}
+
+func taskError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, cron.ErrTaskNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+}
+
+// handleAdminSkills lists the registered skills with their tools, and the
+// tool calls that failed recently
+func (s *Server) handleAdminSkills(c *fiber.Ctx) error {
+	if s.skillsRegistry == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "skills not available"})
+	}
+
+	toolErrors := s.skillsRegistry.ToolErrors()
+	errorCounts := make(map[string]int)
+	for _, e := range toolErrors {
+		errorCounts[e.Skill]++
+	}
+
+	skills := s.skillsRegistry.ListSkills()
+	sort.Slice(skills, func(i, j int) bool { return skills[i].Name() < skills[j].Name() })
+	result := make([]fiber.Map, 0, len(skills))
+	for _, skill := range skills {
+		tools := make([]string, 0, len(skill.Tools()))
+		for _, tool := range skill.Tools() {
+			tools = append(tools, tool.Name)
+		}
+		result = append(result, fiber.Map{
+			"name":          skill.Name(),
+			"version":       skill.Version(),
+			"description":   skill.Description(),
+			"enabled":       skill.IsEnabled(),
+			"tools":         tools,
+			"recent_errors": errorCounts[skill.Name()],
+		})
+	}
+	return c.JSON(fiber.Map{"skills": result, "tool_errors": toolErrors})
+}
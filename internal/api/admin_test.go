@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"go.uber.org/zap"
+)
+
+const testAdminPassword = "correct horse"
+
+func newTestServer(t *testing.T, adminPassword string) *Server {
+	t.Helper()
+	st := testutil.NewTestStore(t)
+	t.Cleanup(func() { st.Close() })
+
+	dir := t.TempDir()
+	cfg := &config.Config{
+		LLM: config.LLMConfig{
+			DefaultProvider: "test",
+			Providers:       map[string]config.Provider{"test": {BaseURL: "http://127.0.0.1:0", Model: "test"}},
+		},
+		Storage: config.StorageConfig{DataDir: dir},
+		Security: config.SecurityConfig{
+			JWTSecret:     "test-secret",
+			AdminPassword: adminPassword,
+			GatewayToken:  "gateway-token",
+		},
+	}
+	return New(cfg, st, zap.NewNop())
+}
+
+// login returns a token from /api/auth/login for password
+func login(t *testing.T, s *Server, password string) string {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"password": password})
+	req := httptest.NewRequest("POST", "/api/auth/login", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to log in: %v", err)
+	}
+	var out struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil || out.Token == "" {
+		t.Fatalf("Expected a token from login, got %v", err)
+	}
+	return out.Token
+}
+
+func request(t *testing.T, s *Server, method, path, auth string) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	resp, err := s.app.Test(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	return resp.StatusCode
+}
+
+var adminEndpoints = []struct{ method, path string }{
+	{"GET", "/api/admin/incident"},
+	{"POST", "/api/admin/incident"},
+	{"DELETE", "/api/admin/incident"},
+	{"GET", "/api/admin/cache"},
+	{"DELETE", "/api/admin/cache"},
+	{"GET", "/api/admin/channels"},
+	{"GET", "/api/admin/cron"},
+	{"POST", "/api/admin/cron/jobs/job_1/run"},
+	{"PUT", "/api/admin/cron/jobs/job_1"},
+	{"POST", "/api/admin/cron/tasks/backup/run"},
+	{"PUT", "/api/admin/cron/tasks/backup"},
+	{"GET", "/api/admin/skills"},
+}
+
+func TestAdminEndpoints_RefuseNonAdmins(t *testing.T) {
+	s := newTestServer(t, testAdminPassword)
+	userToken := login(t, s, "wrong password")
+
+	for _, ep := range adminEndpoints {
+		for name, auth := range map[string]string{
+			"no auth":       "",
+			"login token":   "Bearer " + userToken,
+			"gateway token": "Bearer gateway-token",
+			"wrong basic":   "Basic " + base64.StdEncoding.EncodeToString([]byte("admin:nope")),
+		} {
+			if code := request(t, s, ep.method, ep.path, auth); code != http.StatusUnauthorized {
+				t.Errorf("%s %s with %s: expected 401, got %d", ep.method, ep.path, name, code)
+			}
+		}
+	}
+}
+
+func TestAdminEndpoints_AllowAdmins(t *testing.T) {
+	s := newTestServer(t, testAdminPassword)
+	adminToken := login(t, s, testAdminPassword)
+
+	for _, ep := range adminEndpoints {
+		for name, auth := range map[string]string{
+			"admin token": "Bearer " + adminToken,
+			"basic auth":  "Basic " + base64.StdEncoding.EncodeToString([]byte("admin:"+testAdminPassword)),
+		} {
+			code := request(t, s, ep.method, ep.path, auth)
+			if code == http.StatusUnauthorized || code == http.StatusForbidden {
+				t.Errorf("%s %s with %s: expected to be let in, got %d", ep.method, ep.path, name, code)
+			}
+		}
+	}
+}
+
+func TestAdminEndpoints_DisabledWithoutPassword(t *testing.T) {
+	s := newTestServer(t, "")
+	for _, ep := range adminEndpoints {
+		if code := request(t, s, ep.method, ep.path, "Bearer gateway-token"); code != http.StatusForbidden {
+			t.Errorf("%s %s: expected 403 without an admin password, got %d", ep.method, ep.path, code)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gmsas95/myrai-cli/internal/artifacts"
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// handleListArtifacts lists the caller's stored artifacts, newest first,
+// optionally for one conversation or kind
+func (s *Server) handleListArtifacts(c *fiber.Ctx) error {
+	if s.artifacts == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "artifacts are unavailable"})
+	}
+	list, err := s.artifacts.List(artifacts.Filter{
+		UserID:         household.UserID(s.chatContext(c.Context())),
+		ConversationID: c.Query("conversation_id"),
+		Kind:           c.Query("kind"),
+		Limit:          c.QueryInt("limit", 50),
+	})
+	if err != nil {
+		s.logger.Error("Failed to list artifacts", zap.Error(err))
+		return c.Status(500).JSON(fiber.Map{"error": "failed to list artifacts"})
+	}
+	return c.JSON(list)
+}
+
+func (s *Server) handleGetArtifact(c *fiber.Ctx) error {
+	art, status, err := s.findArtifact(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(art)
+}
+
+// handleDownloadArtifact sends the artifact's file under its name
+func (s *Server) handleDownloadArtifact(c *fiber.Ctx) error {
+	art, status, err := s.findArtifact(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Download(art.StoragePath, art.Name)
+}
+
+func (s *Server) handleDeleteArtifact(c *fiber.Ctx) error {
+	if _, status, err := s.findArtifact(c); err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := s.artifacts.Delete(c.Params("id")); err != nil {
+		s.logger.Error("Failed to delete artifact", zap.Error(err))
+		return c.Status(500).JSON(fiber.Map{"error": "failed to delete artifact"})
+	}
+	return c.SendStatus(204)
+}
+
+// findArtifact looks up the caller's artifact named by the id parameter,
+// returning the status to answer with when it can't. Other users'
+// artifacts are not found.
+func (s *Server) findArtifact(c *fiber.Ctx) (*artifacts.Artifact, int, error) {
+	if s.artifacts == nil {
+		return nil, 503, errors.New("artifacts are unavailable")
+	}
+	art, err := s.artifacts.Get(c.Params("id"))
+	if err == nil && art.UserID != household.UserID(s.chatContext(c.Context())) {
+		err = artifacts.ErrNotFound
+	}
+	if errors.Is(err, artifacts.ErrNotFound) {
+		return nil, 404, err
+	}
+	if err != nil {
+		s.logger.Error("Failed to get artifact", zap.Error(err))
+		return nil, 500, errors.New("failed to get artifact")
+	}
+	return art, 200, nil
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/artifacts"
+	"github.com/gmsas95/myrai-cli/internal/household"
+)
+
+func TestArtifacts_ScopedToTheCaller(t *testing.T) {
+	s := newTestServer(t, "")
+	auth := "Bearer gateway-token"
+
+	own := &artifacts.Artifact{UserID: household.SharedUserID, Kind: artifacts.KindOutput, Name: "mine.txt"}
+	other := &artifacts.Artifact{UserID: "profile_1", Kind: artifacts.KindOutput, Name: "theirs.txt"}
+	for _, a := range []*artifacts.Artifact{own, other} {
+		if err := s.artifacts.Save(a, []byte("data")); err != nil {
+			t.Fatalf("Failed to save artifact: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/artifacts", nil)
+	req.Header.Set("Authorization", auth)
+	resp, err := s.app.Test(req)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var list []artifacts.Artifact
+	json.NewDecoder(resp.Body).Decode(&list)
+	if len(list) != 1 || list[0].ID != own.ID {
+		t.Errorf("Expected only the caller's artifact, got %+v", list)
+	}
+
+	for _, ep := range []struct{ method, path string }{
+		{"GET", "/api/artifacts/" + other.ID},
+		{"GET", "/api/artifacts/" + other.ID + "/download"},
+		{"DELETE", "/api/artifacts/" + other.ID},
+	} {
+		if code := request(t, s, ep.method, ep.path, auth); code != http.StatusNotFound {
+			t.Errorf("%s %s: expected 404 for another user's artifact, got %d", ep.method, ep.path, code)
+		}
+	}
+	if _, err := s.artifacts.Get(other.ID); err != nil {
+		t.Errorf("Expected the other user's artifact to be kept: %v", err)
+	}
+
+	if code := request(t, s, "GET", "/api/artifacts/"+own.ID+"/download", auth); code != http.StatusOK {
+		t.Errorf("Expected the caller's artifact to download, got %d", code)
+	}
+}
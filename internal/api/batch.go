@@ -0,0 +1,143 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/batch"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// resultContentTypes are the download types of the batch output formats
+var resultContentTypes = map[string]string{
+	batch.FormatJSON:  "application/json",
+	batch.FormatJSONL: "application/x-ndjson",
+	batch.FormatCSV:   "text/csv; charset=utf-8",
+	batch.FormatText:  "text/plain; charset=utf-8",
+}
+
+// handleSubmitBatch queues a batch job. The input is the request body, JSON
+// lines or, sent as text/plain, one message per line; or a file uploaded to
+// /api/files/upload, given with the file_id query parameter.
+func (s *Server) handleSubmitBatch(c *fiber.Ctx) error {
+	if s.batchJobs == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "batch jobs are unavailable"})
+	}
+
+	var name string
+	var input []byte
+	if fileID := c.Query("file_id"); fileID != "" {
+		var file store.File
+		if err := s.store.DB().First(&file, "id = ?", fileID).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "file not found"})
+		}
+		data, err := os.ReadFile(file.StoragePath)
+		if err != nil {
+			s.logger.Error("Failed to read batch input", zap.String("file_id", fileID), zap.Error(err))
+			return c.Status(500).JSON(fiber.Map{"error": "failed to read file"})
+		}
+		name, input = file.Filename, data
+	} else {
+		name = "batch.jsonl"
+		if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMETextPlain) {
+			name = "batch.txt"
+		}
+		name = c.Query("name", name)
+		input = append([]byte(nil), c.Body()...)
+	}
+	if len(bytes.TrimSpace(input)) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "the batch is empty"})
+	}
+
+	job, err := s.batchJobs.Submit(name, input)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(202).JSON(job)
+}
+
+func (s *Server) handleListBatches(c *fiber.Ctx) error {
+	if s.batchJobs == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "batch jobs are unavailable"})
+	}
+	jobs, err := s.batchJobs.List(c.QueryInt("limit", 20))
+	if err != nil {
+		s.logger.Error("Failed to list batch jobs", zap.Error(err))
+		return c.Status(500).JSON(fiber.Map{"error": "failed to list batch jobs"})
+	}
+	return c.JSON(jobs)
+}
+
+func (s *Server) handleGetBatch(c *fiber.Ctx) error {
+	job, status, err := s.findBatchJob(c.Params("id"))
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(job)
+}
+
+// handleCancelBatch stops a queued or running job. A running job reports
+// cancelled once the items in flight return.
+func (s *Server) handleCancelBatch(c *fiber.Ctx) error {
+	if s.batchJobs == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "batch jobs are unavailable"})
+	}
+	job, err := s.batchJobs.Cancel(c.Params("id"))
+	switch {
+	case errors.Is(err, batch.ErrJobNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, batch.ErrJobFinished):
+		return c.Status(409).JSON(fiber.Map{"error": "batch job already " + job.Status})
+	case err != nil:
+		s.logger.Error("Failed to cancel batch job", zap.Error(err))
+		return c.Status(500).JSON(fiber.Map{"error": "failed to cancel batch job"})
+	}
+	return c.JSON(job)
+}
+
+// handleBatchResults downloads the results so far, as JSON lines or in the
+// format query parameter's json, csv or text
+func (s *Server) handleBatchResults(c *fiber.Ctx) error {
+	job, status, err := s.findBatchJob(c.Params("id"))
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	format := c.Query("format", batch.FormatJSONL)
+	if !batch.ValidOutputFormat(format) {
+		return c.Status(400).JSON(fiber.Map{"error": "format must be json, jsonl, csv or text"})
+	}
+
+	result, err := s.batchJobs.Results(job)
+	if err != nil {
+		s.logger.Error("Failed to read batch results", zap.String("id", job.ID), zap.Error(err))
+		return c.Status(500).JSON(fiber.Map{"error": "failed to read results"})
+	}
+	var buf bytes.Buffer
+	if err := batch.WriteResults(&buf, result, format, nil); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "failed to write results"})
+	}
+	c.Set(fiber.HeaderContentType, resultContentTypes[format])
+	c.Set("X-Batch-Status", job.Status)
+	return c.Send(buf.Bytes())
+}
+
+// findBatchJob looks up a job, returning the status to answer with when it
+// can't
+func (s *Server) findBatchJob(id string) (*batch.Job, int, error) {
+	if s.batchJobs == nil {
+		return nil, 503, errors.New("batch jobs are unavailable")
+	}
+	job, err := s.batchJobs.Get(id)
+	if errors.Is(err, batch.ErrJobNotFound) {
+		return nil, 404, err
+	}
+	if err != nil {
+		s.logger.Error("Failed to get batch job", zap.Error(err))
+		return nil, 500, errors.New("failed to get batch job")
+	}
+	return job, 200, nil
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (s *Server) handleCacheStats(c *fiber.Ctx) error {
+	if s.skillsRegistry == nil || s.skillsRegistry.Cache() == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "skill cache not enabled"})
+	}
+	stats := s.skillsRegistry.Cache().Stats()
+	namespaces := make(fiber.Map, len(stats))
+	for name, st := range stats {
+		namespaces[name] = fiber.Map{
+			"hits":      st.Hits,
+			"misses":    st.Misses,
+			"bypassed":  st.Bypassed,
+			"evictions": st.Evictions,
+			"entries":   st.Entries,
+			"hit_rate":  st.HitRate(),
+		}
+	}
+	return c.JSON(fiber.Map{"namespaces": namespaces})
+}
+
+// handleClearCache drops cached results, for one skill with ?namespace=
+func (s *Server) handleClearCache(c *fiber.Ctx) error {
+	if s.skillsRegistry == nil || s.skillsRegistry.Cache() == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "skill cache not enabled"})
+	}
+	removed := s.skillsRegistry.Cache().Purge(c.Query("namespace"))
+	return c.JSON(fiber.Map{"removed": removed})
+}
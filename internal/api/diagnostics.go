@@ -0,0 +1,134 @@
+package api
+
+import (
+	"bytes"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/expvar"
+	"github.com/gofiber/fiber/v2/middleware/pprof"
+)
+
+// maxGoroutineGroups caps the goroutine groups in a runtime snapshot
+const maxGoroutineGroups = 20
+
+// setupDiagnostics serves pprof under /debug/pprof, expvar at /debug/vars
+// and a runtime snapshot at /debug/runtime, all behind admin auth
+func (s *Server) setupDiagnostics() {
+	s.app.Use("/debug", s.rateLimitMiddleware(120, time.Minute), s.adminMiddleware(), pprof.New(), expvar.New())
+	s.app.Get("/debug/runtime", s.handleRuntimeSnapshot)
+}
+
+// handleRuntimeSnapshot reports memory, GC and goroutine counts. Goroutines
+// are grouped by the function that started them, which is usually enough to
+// spot a leak. ?gc=1 runs a collection first so the heap figures are live
+// data only.
+func (s *Server) handleRuntimeSnapshot(c *fiber.Ctx) error {
+	if c.QueryBool("gc") {
+		runtime.GC()
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	var lastGC string
+	if mem.LastGC > 0 {
+		lastGC = time.Unix(0, int64(mem.LastGC)).Format(time.RFC3339)
+	}
+
+	return c.JSON(fiber.Map{
+		"started_at": s.started.Format(time.RFC3339),
+		"uptime":     time.Since(s.started).Round(time.Second).String(),
+		"go_version": runtime.Version(),
+		"gomaxprocs": runtime.GOMAXPROCS(0),
+		"goroutines": runtime.NumGoroutine(),
+		"memory": fiber.Map{
+			"heap_alloc":    mem.HeapAlloc,
+			"heap_inuse":    mem.HeapInuse,
+			"heap_idle":     mem.HeapIdle,
+			"heap_released": mem.HeapReleased,
+			"heap_objects":  mem.HeapObjects,
+			"stack_inuse":   mem.StackInuse,
+			"sys":           mem.Sys,
+			"total_alloc":   mem.TotalAlloc,
+			"mallocs":       mem.Mallocs,
+			"frees":         mem.Frees,
+		},
+		"gc": fiber.Map{
+			"num_gc":          mem.NumGC,
+			"last_gc":         lastGC,
+			"next_gc":         mem.NextGC,
+			"pause_total":     time.Duration(mem.PauseTotalNs).String(),
+			"gc_cpu_fraction": mem.GCCPUFraction,
+		},
+		"goroutine_groups": goroutineGroups(maxGoroutineGroups),
+	})
+}
+
+type goroutineGroup struct {
+	CreatedBy string `json:"created_by"`
+	State     string `json:"state"`
+	Count     int    `json:"count"`
+}
+
+// goroutineGroups counts goroutines by creator and state, largest first
+func goroutineGroups(limit int) []goroutineGroup {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		if len(buf) >= 64<<20 {
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	counts := make(map[goroutineGroup]int)
+	for _, block := range bytes.Split(buf, []byte("\n\n")) {
+		lines := strings.Split(strings.TrimSpace(string(block)), "\n")
+		if len(lines) == 0 || !strings.HasPrefix(lines[0], "goroutine ") {
+			continue
+		}
+		key := goroutineGroup{CreatedBy: "main", State: goroutineState(lines[0])}
+		for _, line := range lines {
+			if rest, ok := strings.CutPrefix(line, "created by "); ok {
+				// "created by pkg.fn in goroutine 1"
+				key.CreatedBy, _, _ = strings.Cut(rest, " in goroutine")
+			}
+		}
+		counts[key]++
+	}
+
+	groups := make([]goroutineGroup, 0, len(counts))
+	for g, n := range counts {
+		g.Count = n
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].CreatedBy < groups[j].CreatedBy
+	})
+	if len(groups) > limit {
+		groups = groups[:limit]
+	}
+	return groups
+}
+
+// goroutineState extracts "chan receive" from "goroutine 7 [chan receive, 3 minutes]:"
+func goroutineState(header string) string {
+	start := strings.Index(header, "[")
+	end := strings.LastIndex(header, "]")
+	if start < 0 || end <= start {
+		return ""
+	}
+	state, _, _ := strings.Cut(header[start+1:end], ",")
+	return state
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/skills/kb"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// knowledgeBase returns the document store of the kb skill, nil without it
+func (s *Server) knowledgeBase() *kb.Store {
+	if s.skillsRegistry == nil {
+		return nil
+	}
+	skill, ok := s.skillsRegistry.GetSkill("kb")
+	if !ok {
+		return nil
+	}
+	kbSkill, ok := skill.(*kb.KnowledgeBaseSkill)
+	if !ok {
+		return nil
+	}
+	return kbSkill.Store()
+}
+
+// handleListDocuments lists the documents in the knowledge base
+func (s *Server) handleListDocuments(c *fiber.Ctx) error {
+	store := s.knowledgeBase()
+	if store == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "the knowledge base is not available"})
+	}
+	docs, err := store.List(household.UserID(s.chatContext(c.Context())))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "failed to list documents"})
+	}
+	return c.JSON(docs)
+}
+
+// handleUploadDocument adds an uploaded file to the knowledge base, where
+// chats can search and cite it, as files sent on Telegram are
+func (s *Server) handleUploadDocument(c *fiber.Ctx) error {
+	store := s.knowledgeBase()
+	if store == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "the knowledge base is not available"})
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "no file provided"})
+	}
+
+	dir, err := os.MkdirTemp("", "myrai-upload-")
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "failed to save file"})
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, filepath.Base(file.Filename))
+	if err := c.SaveFile(file, path); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "failed to save file"})
+	}
+
+	doc, err := store.Add(kb.AddOptions{
+		Path:     path,
+		Filename: file.Filename,
+		MimeType: file.Header.Get("Content-Type"),
+		UserID:   household.UserID(s.chatContext(c.Context())),
+		Source:   "api",
+	})
+	if err != nil {
+		s.logger.Warn("Failed to add document to knowledge base", zap.String("filename", file.Filename), zap.Error(err))
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(doc)
+}
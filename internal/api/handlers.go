@@ -3,12 +3,16 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/household"
 	"github.com/gmsas95/myrai-cli/internal/metrics"
+	"github.com/gmsas95/myrai-cli/internal/recurrence"
 	"github.com/gmsas95/myrai-cli/internal/security"
 	"github.com/gmsas95/myrai-cli/internal/store"
 	"github.com/gofiber/fiber/v2"
@@ -39,6 +43,7 @@ func (s *Server) handlePublicStatus(c *fiber.Ctx) error {
 			"provider":  s.config.LLM.DefaultProvider,
 			"model":     s.getDefaultModel(),
 			"connected": true,
+			"offline":   s.agent != nil && s.agent.Offline(),
 		},
 		"channels": fiber.Map{
 			"telegram": s.config.Channels.Telegram.Enabled,
@@ -73,11 +78,16 @@ func (s *Server) handleLogin(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"sub": "default",
 		"iat": time.Now().Unix(),
 		"exp": time.Now().Add(7 * 24 * time.Hour).Unix(),
-	})
+	}
+	// The admin password also unlocks the diagnostics endpoints
+	if s.config.Security.AdminPassword != "" && s.checkAdminPassword(req.Password) {
+		claims["admin"] = true
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	tokenString, err := token.SignedString([]byte(s.config.Security.JWTSecret))
 	if err != nil {
@@ -91,7 +101,7 @@ func (s *Server) handleListConversations(c *fiber.Ctx) error {
 	limit := c.QueryInt("limit", 20)
 	offset := c.QueryInt("offset", 0)
 
-	convs, err := s.store.ListConversations(limit, offset)
+	convs, err := s.store.ListActiveConversations(limit, offset)
 	if err != nil {
 		s.logger.Error("Failed to list conversations", zap.Error(err))
 		return c.Status(500).JSON(fiber.Map{"error": "failed to list conversations"})
@@ -115,7 +125,9 @@ func (s *Server) handleCreateConversation(c *fiber.Ctx) error {
 		Model: req.Model,
 	}
 	if conv.Title == "" {
-		conv.Title = "New Conversation"
+		conv.Title = agent.DefaultTitle
+	} else {
+		conv.TitleManual = true
 	}
 
 	if err := s.store.CreateConversation(conv); err != nil {
@@ -126,12 +138,52 @@ func (s *Server) handleCreateConversation(c *fiber.Ctx) error {
 	return c.Status(201).JSON(conv)
 }
 
+// handleGetConversation finds a conversation by its ID or the start of it
 func (s *Server) handleGetConversation(c *fiber.Ctx) error {
 	id := c.Params("id")
-	conv, err := s.store.GetConversation(id)
+	conv, err := s.store.FindConversation(id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(conv)
+}
+
+func (s *Server) handleUpdateConversation(c *fiber.Ctx) error {
+	var req struct {
+		Title string  `json:"title"`
+		Route *string `json:"route"`
+	}
+	if err := c.BodyParser(&req); err != nil || (strings.TrimSpace(req.Title) == "" && req.Route == nil) {
+		return c.Status(400).JSON(fiber.Map{"error": "title or route is required"})
+	}
+	route := ""
+	if req.Route != nil {
+		var err error
+		if route, err = agent.ParseRoute(*req.Route); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if route == agent.RouteAuto {
+			route = ""
+		}
+	}
+
+	conv, err := s.store.GetConversation(c.Params("id"))
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "conversation not found"})
 	}
+	if title := strings.TrimSpace(req.Title); title != "" {
+		// A title given here is the user's; it is never replaced automatically
+		conv.Title, conv.TitleManual = title, true
+		if err := s.store.RenameConversation(conv.ID, conv.Title); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "failed to update conversation"})
+		}
+	}
+	if req.Route != nil {
+		conv.Route, conv.SmartTurns = route, 0
+		if err := s.store.SetConversationRoute(conv.ID, conv.Route); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "failed to update conversation"})
+		}
+	}
 	return c.JSON(conv)
 }
 
@@ -156,11 +208,80 @@ func (s *Server) handleGetMessages(c *fiber.Ctx) error {
 	return c.JSON(messages)
 }
 
+func (s *Server) handleListPins(c *fiber.Ctx) error {
+	convID := c.Params("id")
+	pins, err := s.agent.Pins(convID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "failed to get pins"})
+	}
+
+	used := 0
+	for _, p := range pins {
+		used += p.Tokens
+	}
+
+	return c.JSON(fiber.Map{
+		"pins":        pins,
+		"tokens_used": used,
+		"budget":      s.agent.PinBudget(),
+	})
+}
+
+func (s *Server) handleCreatePin(c *fiber.Ctx) error {
+	var req struct {
+		Kind    string `json:"kind"`
+		Label   string `json:"label"`
+		Content string `json:"content"`
+		FileID  string `json:"file_id"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	convID := c.Params("id")
+	if _, err := s.store.GetConversation(convID); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "conversation not found"})
+	}
+
+	var (
+		pin *store.Pin
+		err error
+	)
+	switch {
+	case req.FileID != "":
+		pin, err = s.agent.PinFromArgs(convID, "file "+req.FileID)
+	case req.Kind == agent.PinKindToolOutput && req.Content == "":
+		pin, err = s.agent.PinFromArgs(convID, "tool")
+	default:
+		kind := req.Kind
+		if kind == "" {
+			kind = agent.PinKindFact
+		}
+		pin, err = s.agent.Pin(convID, kind, req.Label, req.Content)
+	}
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(pin)
+}
+
+func (s *Server) handleDeletePin(c *fiber.Ctx) error {
+	if err := s.agent.Unpin(c.Params("id"), c.Params("pinId")); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "pin not found"})
+	}
+	return c.SendStatus(204)
+}
+
 func (s *Server) handleChat(c *fiber.Ctx) error {
 	var req struct {
-		ConversationID string `json:"conversation_id"`
-		Message        string `json:"message"`
-		SystemPrompt   string `json:"system_prompt"`
+		ConversationID string                `json:"conversation_id"`
+		Message        string                `json:"message"`
+		SystemPrompt   string                `json:"system_prompt"`
+		Loop           *agent.LoopOverrides  `json:"loop"`
+		NoCache        bool                  `json:"no_cache"`
+		ResponseFormat *agent.ResponseFormat `json:"response_format"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -170,6 +291,11 @@ func (s *Server) handleChat(c *fiber.Ctx) error {
 	if req.Message == "" {
 		return c.Status(400).JSON(fiber.Map{"error": "message is required"})
 	}
+	if req.ResponseFormat != nil {
+		if err := req.ResponseFormat.Compile(); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
 
 	// SECURITY: Validate and sanitize input before sending to LLM
 	validation := security.ValidateUserInput(req.Message)
@@ -191,24 +317,47 @@ func (s *Server) handleChat(c *fiber.Ctx) error {
 	// Sanitize input (removes/redacts secrets if detected)
 	sanitizedMessage := security.SanitizeInput(req.Message)
 
-	resp, err := s.agent.Chat(c.Context(), agent.ChatRequest{
+	loopOpts := req.Loop.Apply(s.agent.LoopOptions())
+	resp, err := s.agent.Chat(s.chatContext(c.Context()), agent.ChatRequest{
 		ConversationID: req.ConversationID,
 		Message:        sanitizedMessage,
 		SystemPrompt:   req.SystemPrompt,
+		Channel:        "api",
 		Stream:         false,
+		Loop:           &loopOpts,
+		NoCache:        req.NoCache,
+		ResponseFormat: req.ResponseFormat,
 	})
 
+	if errors.Is(err, agent.ErrInvalidStructuredOutput) {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
 		s.logger.Error("Chat failed", zap.Error(err))
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.JSON(fiber.Map{
-		"content":       resp.Content,
-		"tool_calls":    resp.ToolCalls,
-		"tokens_used":   resp.TokensUsed,
-		"response_time": resp.ResponseTime.Milliseconds(),
-	})
+	result := fiber.Map{
+		"content":         resp.Content,
+		"conversation_id": resp.ConversationID,
+		"tool_calls":      resp.ToolCalls,
+		"tokens_used":     resp.TokensUsed,
+		"model":           resp.Model,
+		"response_time":   resp.ResponseTime.Milliseconds(),
+		"loop":            resp.Loop,
+		"sources":         resp.Sources,
+		"artifacts":       resp.Artifacts,
+	}
+	if resp.Route != "" {
+		result["route"] = resp.Route
+	}
+	if len(resp.Parts) > 1 {
+		result["parts"] = resp.Parts
+	}
+	if resp.Structured != nil {
+		result["structured"] = resp.Structured
+	}
+	return c.JSON(result)
 }
 
 func (s *Server) handleChatStream(c *fiber.Ctx) error {
@@ -216,6 +365,7 @@ func (s *Server) handleChatStream(c *fiber.Ctx) error {
 		ConversationID string `json:"conversation_id"`
 		Message        string `json:"message"`
 		SystemPrompt   string `json:"system_prompt"`
+		NoCache        bool   `json:"no_cache"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -252,21 +402,37 @@ func (s *Server) handleChatStream(c *fiber.Ctx) error {
 
 	var fullContent strings.Builder
 
-	_, err := s.agent.Chat(c.Context(), agent.ChatRequest{
+	resp, err := s.agent.Chat(s.chatContext(c.Context()), agent.ChatRequest{
 		ConversationID: req.ConversationID,
 		Message:        sanitizedMessage,
 		SystemPrompt:   req.SystemPrompt,
+		Channel:        "api",
 		Stream:         true,
+		NoCache:        req.NoCache,
 		OnStream: func(chunk string) {
 			fullContent.WriteString(chunk)
 			data, _ := json.Marshal(fiber.Map{"chunk": chunk})
 			fmt.Fprintf(c, "data: %s\n\n", data)
 		},
+		// Tool activity, for clients showing what the assistant is doing
+		OnToolExecuting: func(toolName string) {
+			data, _ := json.Marshal(fiber.Map{"tool": toolName})
+			fmt.Fprintf(c, "data: %s\n\n", data)
+		},
 	})
 
 	if err != nil {
 		data, _ := json.Marshal(fiber.Map{"error": err.Error()})
 		fmt.Fprintf(c, "data: %s\n\n", data)
+	} else {
+		// The conversation to continue in, for clients that started one
+		data, _ := json.Marshal(fiber.Map{
+			"conversation_id": resp.ConversationID,
+			"tokens_used":     resp.TokensUsed,
+			"model":           resp.Model,
+			"offline":         resp.Offline,
+		})
+		fmt.Fprintf(c, "data: %s\n\n", data)
 	}
 
 	fmt.Fprint(c, "data: [DONE]\n\n")
@@ -338,6 +504,20 @@ func (s *Server) handleFileUpload(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "failed to save file record"})
 	}
 
+	if s.skillsRegistry != nil {
+		s.skillsRegistry.Events().Publish(c.Context(), events.Event{
+			Type:   events.FileUploaded,
+			UserID: household.SharedUserID,
+			Source: "api",
+			Data: map[string]interface{}{
+				"file_id":   f.ID,
+				"filename":  f.Filename,
+				"mime_type": f.MimeType,
+				"size":      f.SizeBytes,
+			},
+		})
+	}
+
 	return c.Status(201).JSON(f)
 }
 
@@ -416,9 +596,10 @@ func (s *Server) handleWebSocket(c *websocket.Conn) {
 			// Sanitize input (removes/redacts secrets if detected)
 			sanitizedMessage := security.SanitizeInput(req.Message)
 
-			_, err := s.agent.Chat(context.Background(), agent.ChatRequest{
+			_, err := s.agent.Chat(s.chatContext(context.Background()), agent.ChatRequest{
 				ConversationID: req.ConversationID,
 				Message:        sanitizedMessage,
+				Channel:        "api",
 				Stream:         true,
 				OnStream: func(chunk string) {
 					c.WriteJSON(fiber.Map{"type": "chunk", "content": chunk})
@@ -480,6 +661,12 @@ func (s *Server) handleCreateJob(c *fiber.Ctx) error {
 		if d, err := time.ParseDuration(req.Schedule); err == nil {
 			next := now.Add(d)
 			job.NextRunAt = &next
+		} else if rule, err := recurrence.ParseAny(req.Schedule); err == nil {
+			next, ok := rule.Next(now, now)
+			if !ok {
+				return c.Status(400).JSON(fiber.Map{"error": "schedule has no more runs"})
+			}
+			job.NextRunAt = &next
 		} else {
 			next := now.Add(time.Minute)
 			job.NextRunAt = &next
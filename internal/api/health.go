@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/doctor"
+	"github.com/gofiber/fiber/v2"
+)
+
+// readinessTTL is how long /readyz reuses a provider or channel check, so a
+// probe every few seconds costs a token every few minutes at most
+const readinessTTL = 5 * time.Minute
+
+// setupReadiness registers the checks /readyz runs: the database on every
+// probe, and the LLM provider and enabled channels from cache
+func (s *Server) setupReadiness() {
+	s.readiness = doctor.NewReadiness()
+	s.readiness.Add(true, 0, func(ctx context.Context) doctor.Result {
+		return doctor.CheckDatabase(ctx, s.store.DB())
+	})
+
+	if provider, err := s.config.DefaultProvider(); err == nil {
+		name := s.config.LLM.DefaultProvider
+		s.readiness.Add(true, readinessTTL, func(ctx context.Context) doctor.Result {
+			return doctor.CheckProvider(ctx, name, provider)
+		})
+	}
+
+	channels := s.config.Channels
+	if channels.Telegram.Enabled && channels.Telegram.BotToken != "" {
+		s.readiness.Add(false, readinessTTL, func(ctx context.Context) doctor.Result {
+			return doctor.CheckTelegram(ctx, channels.Telegram.BotToken)
+		})
+	}
+	if channels.Discord.Enabled && channels.Discord.Token != "" {
+		s.readiness.Add(false, readinessTTL, func(ctx context.Context) doctor.Result {
+			return doctor.CheckDiscord(ctx, channels.Discord.Token)
+		})
+	}
+}
+
+// handleHealthz reports the process is up and serving requests
+func (s *Server) handleHealthz(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// handleReadyz reports whether the server can answer chats: 200 when the
+// database and LLM provider work, 503 otherwise. A failing channel only
+// marks the server degraded. Check details are left out, as the endpoint
+// needs no auth; 'myrai doctor' shows them.
+func (s *Server) handleReadyz(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 15*time.Second)
+	defer cancel()
+	ready, results := s.readiness.Check(ctx)
+
+	status := "ready"
+	checks := make(fiber.Map, len(results))
+	for _, r := range results {
+		checks[r.Name] = r.Status
+		if r.Status == doctor.Fail && status == "ready" {
+			status = "degraded"
+		}
+	}
+	if !ready {
+		status = "not_ready"
+		c.Status(fiber.StatusServiceUnavailable)
+	}
+	return c.JSON(fiber.Map{"status": status, "checks": checks})
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (s *Server) handleIncidentStatus(c *fiber.Ctx) error {
+	if s.incident == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "incident mode not available"})
+	}
+	return c.JSON(s.incident.Status())
+}
+
+func (s *Server) handleEnableIncident(c *fiber.Ctx) error {
+	if s.incident == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "incident mode not available"})
+	}
+
+	var req struct {
+		Minutes int    `json:"minutes"`
+		Reason  string `json:"reason"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		}
+	}
+	if req.Minutes < 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "minutes must be positive"})
+	}
+
+	if _, err := s.incident.Enable(time.Duration(req.Minutes)*time.Minute, req.Reason); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(s.incident.Status())
+}
+
+func (s *Server) handleDisableIncident(c *fiber.Ctx) error {
+	if s.incident == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "incident mode not available"})
+	}
+	s.incident.Disable()
+	return c.JSON(s.incident.Status())
+}
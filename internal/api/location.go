@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/location"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// checkInRequest accepts both OwnTracks location messages and a plain JSON
+// body that an iOS Shortcut or Tasker profile can send
+type checkInRequest struct {
+	// OwnTracks
+	Type string   `json:"_type"`
+	Lat  *float64 `json:"lat"`
+	Lon  *float64 `json:"lon"`
+	Acc  float64  `json:"acc"`
+	Tst  int64    `json:"tst"`
+
+	// Plain
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	Accuracy  float64  `json:"accuracy"`
+	Timestamp string   `json:"timestamp"` // RFC 3339
+	Source    string   `json:"source"`
+}
+
+// handleLocationCheckIn records where a user is, see checkInUser for which
+func (s *Server) handleLocationCheckIn(c *fiber.Ctx) error {
+	if s.location == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "location check-ins are disabled"})
+	}
+
+	var req checkInRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	// OwnTracks also posts waypoints, transitions and status messages;
+	// accept and ignore them, and answer with the empty command list it
+	// expects
+	owntracks := req.Type != ""
+	if owntracks && req.Type != "location" {
+		return c.JSON([]interface{}{})
+	}
+
+	fix, err := req.fix()
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID, err := checkInUser(s.chatContext(c.Context()), c.Query("user", c.Get("X-Limit-U")))
+	if err != nil {
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	result, err := s.location.CheckIn(c.Context(), userID, fix)
+	if errors.Is(err, location.ErrNotSharing) {
+		return c.Status(403).JSON(fiber.Map{"error": "location sharing is off for this user"})
+	}
+	if err != nil {
+		s.logger.Warn("Location check-in failed", zap.String("user_id", userID), zap.Error(err))
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if owntracks {
+		return c.JSON([]interface{}{})
+	}
+	return c.JSON(fiber.Map{
+		"recorded": true,
+		"place":    result.Fix.Place,
+		"arrived":  result.Arrived,
+		"left":     result.Left,
+		"reminded": result.Reminded,
+	})
+}
+
+// checkInUser returns the user a check-in is for. A request made as a
+// household profile is for that profile, and may only name it, so callers
+// can't move other members or fire their reminders. Without per-user auth
+// the user named by the user query parameter or OwnTracks' X-Limit-U
+// header is taken, defaulting to the shared user.
+func checkInUser(ctx context.Context, requested string) (string, error) {
+	requested = strings.TrimSpace(requested)
+	if profile := household.FromContext(ctx); profile != nil {
+		if requested != "" && requested != profile.UserID && !strings.EqualFold(requested, profile.Name) {
+			return "", errors.New("you can only check in as yourself")
+		}
+		return profile.UserID, nil
+	}
+	if requested == "" {
+		return household.SharedUserID, nil
+	}
+	return requested, nil
+}
+
+func (r *checkInRequest) fix() (location.Fix, error) {
+	lat, lon := r.Latitude, r.Longitude
+	if lat == nil {
+		lat = r.Lat
+	}
+	if lon == nil {
+		lon = r.Lon
+	}
+	if lat == nil || lon == nil {
+		return location.Fix{}, errors.New("latitude and longitude are required")
+	}
+
+	fix := location.Fix{
+		Latitude:  *lat,
+		Longitude: *lon,
+		Accuracy:  r.Accuracy,
+		Source:    r.Source,
+	}
+	if fix.Accuracy == 0 {
+		fix.Accuracy = r.Acc
+	}
+	switch {
+	case r.Tst > 0:
+		fix.RecordedAt = time.Unix(r.Tst, 0)
+	case r.Timestamp != "":
+		t, err := time.Parse(time.RFC3339, r.Timestamp)
+		if err != nil {
+			return location.Fix{}, errors.New("timestamp must be RFC 3339, e.g. 2025-03-04T18:30:00Z")
+		}
+		fix.RecordedAt = t
+	}
+	if fix.Source == "" {
+		fix.Source = "api"
+		if r.Type != "" {
+			fix.Source = "owntracks"
+		}
+	}
+	return fix, fix.Validate()
+}
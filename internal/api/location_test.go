@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/household"
+)
+
+func TestCheckInUser(t *testing.T) {
+	// Without per-user auth the request names the user
+	if got, err := checkInUser(context.Background(), ""); err != nil || got != household.SharedUserID {
+		t.Errorf("Expected the shared user, got %q (%v)", got, err)
+	}
+	if got, err := checkInUser(context.Background(), " profile_2 "); err != nil || got != "profile_2" {
+		t.Errorf("Expected the named user, got %q (%v)", got, err)
+	}
+
+	ctx := household.WithProfile(context.Background(), &household.Profile{Name: "alex", UserID: "profile_1"})
+	for _, requested := range []string{"", "profile_1", "Alex"} {
+		if got, err := checkInUser(ctx, requested); err != nil || got != "profile_1" {
+			t.Errorf("Requested %q: expected the caller's profile, got %q (%v)", requested, got, err)
+		}
+	}
+	if _, err := checkInUser(ctx, "profile_2"); err == nil {
+		t.Error("Expected a profile to be refused checking in as another member")
+	}
+}
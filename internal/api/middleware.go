@@ -1,12 +1,16 @@
 package api
 
 import (
+	"crypto/subtle"
+	"encoding/base64"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/idgen"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
 )
 
 func (s *Server) authMiddleware() fiber.Handler {
@@ -17,6 +21,11 @@ func (s *Server) authMiddleware() fiber.Handler {
 		}
 
 		tokenString := strings.TrimPrefix(auth, "Bearer ")
+		// The gateway token lets the CLI in remote mode, and other clients
+		// that can't log in, use the API
+		if s.checkGatewayToken(tokenString) {
+			return c.Next()
+		}
 		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 			return []byte(s.config.Security.JWTSecret), nil
 		})
@@ -29,6 +38,58 @@ func (s *Server) authMiddleware() fiber.Handler {
 	}
 }
 
+// adminMiddleware lets through requests that prove they know the admin
+// password, either with HTTP basic auth (any user name, which also works for
+// go tool pprof URLs) or with a token from /api/auth/login marked admin.
+// Without an admin password configured every request is refused.
+func (s *Server) adminMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if s.config.Security.AdminPassword == "" {
+			return c.Status(403).JSON(fiber.Map{"error": "admin endpoints are disabled: set security.admin_password"})
+		}
+		if s.isAdmin(c) {
+			return c.Next()
+		}
+		c.Set("WWW-Authenticate", `Basic realm="myrai admin"`)
+		return c.Status(401).JSON(fiber.Map{"error": "admin authentication required"})
+	}
+}
+
+func (s *Server) isAdmin(c *fiber.Ctx) bool {
+	auth := c.Get("Authorization")
+	if encoded, ok := strings.CutPrefix(auth, "Basic "); ok {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return false
+		}
+		_, password, _ := strings.Cut(string(decoded), ":")
+		return s.checkAdminPassword(password)
+	}
+
+	tokenString, ok := strings.CutPrefix(auth, "Bearer ")
+	if !ok {
+		return false
+	}
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return []byte(s.config.Security.JWTSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return false
+	}
+	claims, _ := token.Claims.(jwt.MapClaims)
+	admin, _ := claims["admin"].(bool)
+	return admin
+}
+
+func (s *Server) checkAdminPassword(password string) bool {
+	return subtle.ConstantTimeCompare([]byte(password), []byte(s.config.Security.AdminPassword)) == 1
+}
+
+func (s *Server) checkGatewayToken(token string) bool {
+	expected := s.config.Security.GatewayToken
+	return expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
 func (s *Server) securityHeadersMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		c.Set("X-Content-Type-Options", "nosniff")
@@ -145,3 +206,31 @@ func (s *Server) requestSizeLimitMiddleware(maxSize int) fiber.Handler {
 		return c.Next()
 	}
 }
+
+// traceMiddleware logs every request in detail while incident mode is on.
+// Each traced request gets an X-Trace-ID header to match client reports to
+// the log.
+func (s *Server) traceMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !s.incident.Active() {
+			return c.Next()
+		}
+
+		traceID := idgen.Generate("trc")
+		c.Set("X-Trace-ID", traceID)
+		start := time.Now()
+		err := c.Next()
+
+		s.logger.Info("Request trace",
+			zap.String("trace_id", traceID),
+			zap.String("method", c.Method()),
+			zap.String("path", c.Path()),
+			zap.String("query", string(c.Request().URI().QueryString())),
+			zap.Int("status", c.Response().StatusCode()),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("ip", c.IP()),
+			zap.Int("bytes", len(c.Response().Body())),
+			zap.Error(err))
+		return err
+	}
+}
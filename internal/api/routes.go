@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,6 +21,7 @@ import (
 
 func (s *Server) setupRoutes() {
 	s.app.Use(recover.New())
+	s.app.Use(s.traceMiddleware())
 	s.app.Use(logger.New(logger.Config{
 		Format: "[${time}] ${status} - ${latency} ${method} ${path}\n",
 	}))
@@ -31,7 +33,11 @@ func (s *Server) setupRoutes() {
 	s.app.Use(s.securityHeadersMiddleware())
 	s.app.Use(s.requestSizeLimitMiddleware(10 * 1024 * 1024))
 
+	s.setupDiagnostics()
+
 	s.app.Get("/api/health", s.handleHealth)
+	s.app.Get("/healthz", s.handleHealthz)
+	s.app.Get("/readyz", s.handleReadyz)
 	s.app.Get("/metrics", s.handleMetrics)
 	s.app.Get("/api/metrics", s.handleMetricsJSON)
 	s.app.Get("/oauth/callback", s.handleOAuthCallback)
@@ -43,13 +49,45 @@ func (s *Server) setupRoutes() {
 	// Public endpoint for dashboard status (no auth required)
 	api.Get("/public/status", s.handlePublicStatus)
 
+	// Read-only widget endpoints authenticated with widget tokens
+	widget := s.widgetAuthMiddleware()
+	api.Get("/widget/today", s.rateLimitMiddleware(60, time.Minute), widget, s.handleWidgetToday)
+	api.Get("/widget/tasks", s.rateLimitMiddleware(60, time.Minute), widget, s.handleWidgetTasks)
+
+	// Signed with the webhook secret instead of a login
+	api.Post("/webhooks/github", s.rateLimitMiddleware(120, time.Minute), s.handleGitHubWebhook)
+
+	// Admin endpoints need the admin password, so they are registered
+	// before the login check every other endpoint goes through
+	admin := api.Group("/admin", s.rateLimitMiddleware(120, time.Minute), s.adminMiddleware())
+	admin.Get("/incident", s.handleIncidentStatus)
+	admin.Post("/incident", s.handleEnableIncident)
+	admin.Delete("/incident", s.handleDisableIncident)
+	admin.Get("/cache", s.handleCacheStats)
+	admin.Delete("/cache", s.handleClearCache)
+	admin.Get("/channels", s.handleAdminChannels)
+	admin.Get("/cron", s.handleAdminCron)
+	admin.Post("/cron/jobs/:id/run", s.handleAdminRunJob)
+	admin.Put("/cron/jobs/:id", s.handleAdminUpdateJob)
+	admin.Post("/cron/tasks/:name/run", s.handleAdminRunTask)
+	admin.Put("/cron/tasks/:name", s.handleAdminUpdateTask)
+	admin.Get("/skills", s.handleAdminSkills)
+
 	protected := api.Use(s.authMiddleware())
 
 	protected.Get("/conversations", s.handleListConversations)
 	protected.Post("/conversations", s.handleCreateConversation)
 	protected.Get("/conversations/:id", s.handleGetConversation)
+	protected.Put("/conversations/:id", s.handleUpdateConversation)
 	protected.Delete("/conversations/:id", s.handleDeleteConversation)
 	protected.Get("/conversations/:id/messages", s.handleGetMessages)
+	protected.Get("/conversations/:id/pins", s.handleListPins)
+	protected.Post("/conversations/:id/pins", s.handleCreatePin)
+	protected.Delete("/conversations/:id/pins/:pinId", s.handleDeletePin)
+
+	protected.Get("/widget/tokens", s.handleListWidgetTokens)
+	protected.Post("/widget/tokens", s.handleCreateWidgetToken)
+	protected.Delete("/widget/tokens/:id", s.handleDeleteWidgetToken)
 
 	protected.Post("/chat", s.rateLimitMiddleware(60, time.Minute), s.handleChat)
 	protected.Post("/chat/stream", s.rateLimitMiddleware(60, time.Minute), s.handleChatStream)
@@ -59,8 +97,21 @@ func (s *Server) setupRoutes() {
 	protected.Delete("/memories/:id", s.handleDeleteMemory)
 
 	protected.Post("/files/upload", s.handleFileUpload)
+	protected.Get("/documents", s.handleListDocuments)
+	protected.Post("/documents", s.handleUploadDocument)
 	protected.Get("/files/:id", s.handleGetFile)
 
+	protected.Get("/artifacts", s.handleListArtifacts)
+	protected.Get("/artifacts/:id", s.handleGetArtifact)
+	protected.Get("/artifacts/:id/download", s.handleDownloadArtifact)
+	protected.Delete("/artifacts/:id", s.handleDeleteArtifact)
+
+	protected.Post("/batch", s.handleSubmitBatch)
+	protected.Get("/batch", s.handleListBatches)
+	protected.Get("/batch/:id", s.handleGetBatch)
+	protected.Delete("/batch/:id", s.handleCancelBatch)
+	protected.Get("/batch/:id/results", s.handleBatchResults)
+
 	protected.Get("/tools", s.handleListTools)
 	protected.Post("/tools/execute", s.handleExecuteTool)
 
@@ -68,14 +119,20 @@ func (s *Server) setupRoutes() {
 	protected.Post("/jobs", s.handleCreateJob)
 	protected.Delete("/jobs/:id", s.handleDeleteJob)
 
+
+	protected.Post("/location", s.rateLimitMiddleware(120, time.Minute), s.handleLocationCheckIn)
+
 	protected.Post("/search", s.handleVectorSearch)
 	protected.Post("/memories/:id/index", s.handleIndexMemory)
 
 	s.app.Get("/ws", websocket.New(s.handleWebSocket))
 
 	// Register dashboard API routes
-	dashboardHandler := dashboard.NewHandler(s.config, s.skillsRegistry, s.logger)
-	dashboardHandler.RegisterRoutes(s.app)
+	s.dashboard = dashboard.NewHandler(s.config, s.skillsRegistry, s.logger)
+	s.dashboard.RegisterRoutes(s.app)
+
+	// The built-in chat page is always there, whatever serves /
+	s.setupWebUI()
 
 	// Try to serve embedded dashboard first
 	if err := s.setupDashboard(); err != nil {
@@ -98,26 +155,30 @@ func (s *Server) setupRoutes() {
 			})
 		} else {
 			s.app.Get("/", func(c *fiber.Ctx) error {
-				return c.SendString(`<!DOCTYPE html>
-<html>
-<head><title>Myrai</title></head>
-<body style="font-family: sans-serif; max-width: 800px; margin: 50px auto; padding: 20px;">
-<h1>🤖 Myrai</h1>
-<p>Web UI files not found. Please ensure the web/dist directory exists.</p>
-<p>You can still use the API at <code>/api</code> or the CLI.</p>
-</body>
-</html>`)
+				return c.Redirect(WebUIPath + "/")
 			})
 		}
 	}
 }
 
 func (s *Server) Start() error {
+	if s.batchJobs != nil {
+		s.batchJobs.Start(s.batchCtx)
+	}
 	addr := fmt.Sprintf("%s:%d", s.config.Server.Address, s.config.Server.Port)
 	return s.app.Listen(addr)
 }
 
+// Serve handles requests from another listener, such as the tailnet, next
+// to the configured address
+func (s *Server) Serve(ln net.Listener) error {
+	return s.app.Listener(ln)
+}
+
 func (s *Server) Shutdown() error {
+	if s.stopBatchJobs != nil {
+		s.stopBatchJobs()
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	return s.app.ShutdownWithContext(ctx)
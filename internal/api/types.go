@@ -1,13 +1,28 @@
 package api
 
 import (
+	"context"
+	"path/filepath"
 	"time"
 
 	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/artifacts"
+	"github.com/gmsas95/myrai-cli/internal/batch"
+	"github.com/gmsas95/myrai-cli/internal/channels"
 	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/connectivity"
+	"github.com/gmsas95/myrai-cli/internal/cron"
+	"github.com/gmsas95/myrai-cli/internal/dashboard"
+	"github.com/gmsas95/myrai-cli/internal/doctor"
+	"github.com/gmsas95/myrai-cli/internal/incident"
+	"github.com/gmsas95/myrai-cli/internal/journal"
 	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/location"
 	"github.com/gmsas95/myrai-cli/internal/persona"
+	"github.com/gmsas95/myrai-cli/internal/security"
 	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/skills/calendar"
+	"github.com/gmsas95/myrai-cli/internal/skills/tasks"
 	"github.com/gmsas95/myrai-cli/internal/store"
 	"github.com/gmsas95/myrai-cli/internal/vector"
 	"github.com/gmsas95/myrai-cli/pkg/tools"
@@ -23,9 +38,22 @@ type Server struct {
 	llmClient      *llm.Client
 	tools          *tools.Registry
 	skillsRegistry *skills.Registry
+	dashboard      *dashboard.Handler
 	logger         *zap.Logger
 	personaManager *persona.PersonaManager
 	contextManager *agent.ContextManager
+	taskStore      *tasks.Store
+	calendarStore  *calendar.Store
+	incident       *incident.Mode
+	cronRunner     *cron.Runner
+	channelHealth  func() []channels.Health
+	location       *location.Tracker
+	readiness      *doctor.Readiness
+	artifacts      *artifacts.Store
+	batchJobs      *batch.Jobs
+	stopBatchJobs  context.CancelFunc
+	batchCtx       context.Context
+	started        time.Time
 }
 
 func New(cfg *config.Config, store *store.Store, logger *zap.Logger) *Server {
@@ -44,6 +72,7 @@ func New(cfg *config.Config, store *store.Store, logger *zap.Logger) *Server {
 	}
 
 	agentInstance := agent.New(llmClient, toolRegistry, store, logger, personaManager)
+	agentInstance.SetLoopOptions(agent.LoopOptionsFromConfig(cfg.Agent.Loop))
 
 	var contextManager *agent.ContextManager
 	if cfg.Vector.Enabled {
@@ -52,11 +81,13 @@ func New(cfg *config.Config, store *store.Store, logger *zap.Logger) *Server {
 			logger.Warn("Failed to create vector searcher", zap.Error(err))
 		} else {
 			contextManager = agent.NewContextManager(store, vectorSearcher, llmClient, logger)
+			contextManager.SetProvider(provider)
 			agentInstance.SetContextManager(contextManager)
 			logger.Info("Context manager initialized with vector search")
 		}
 	} else {
 		contextManager = agent.NewContextManager(store, nil, llmClient, logger)
+		contextManager.SetProvider(provider)
 		agentInstance.SetContextManager(contextManager)
 		logger.Info("Context manager initialized (without vector search)")
 	}
@@ -76,12 +107,58 @@ func New(cfg *config.Config, store *store.Store, logger *zap.Logger) *Server {
 		tools:          toolRegistry,
 		logger:         logger,
 		personaManager: personaManager,
+		started:        time.Now(),
 	}
 
 	if s.skillsRegistry != nil {
 		s.agent.SetSkillsRegistry(s.skillsRegistry)
 	}
 
+	// Task and calendar data for the widget endpoints
+	if taskStore, err := tasks.NewStore(store.DB()); err != nil {
+		logger.Warn("Widget task store unavailable", zap.Error(err))
+	} else {
+		s.taskStore = taskStore
+	}
+	if calendarStore, err := calendar.NewStore(store.DB()); err != nil {
+		logger.Warn("Widget calendar store unavailable", zap.Error(err))
+	} else {
+		s.calendarStore = calendarStore
+	}
+
+	// Long tool outputs and generated files, listed at /api/artifacts
+	if st, err := artifacts.NewStore(store.DB(), artifacts.DefaultDir(cfg.Storage.DataDir)); err != nil {
+		logger.Warn("Artifacts unavailable", zap.Error(err))
+	} else {
+		s.artifacts = st
+		agentInstance.SetArtifacts(st)
+	}
+
+	agentInstance.SetTitles(cfg.Agent.Titles)
+	agentInstance.SetRouter(agent.NewRouter(cfg.Agent.Routing))
+	agentInstance.SetPinBudget(cfg.Agent.PinBudget)
+
+	// Replies run through the configured post-processing chain
+	filter := cfg.Security.ContentFilter
+	if pipeline, err := agent.NewReplyPipeline(cfg.Agent.PostProcess, security.ContentFilterOptions{
+		WordList: filter.WordList,
+		Block:    filter.Block,
+		Allow:    filter.Allow,
+	}); err != nil {
+		logger.Warn("Reply post-processing unavailable", zap.Error(err))
+	} else {
+		agentInstance.SetReplyPipeline(pipeline)
+	}
+
+	// Batch jobs submitted to /api/batch, run once the server starts
+	if jobs, err := batch.NewJobs(store.DB(), agentInstance, filepath.Join(cfg.Storage.DataDir, "batch"), logger); err != nil {
+		logger.Warn("Batch jobs unavailable", zap.Error(err))
+	} else {
+		s.batchJobs = jobs
+		s.batchCtx, s.stopBatchJobs = context.WithCancel(s.chatContext(context.Background()))
+	}
+
+	s.setupReadiness()
 	s.setupRoutes()
 	return s
 }
@@ -91,4 +168,46 @@ func (s *Server) SetSkillsRegistry(registry *skills.Registry) {
 	if s.agent != nil {
 		s.agent.SetSkillsRegistry(registry)
 	}
+	if s.dashboard != nil {
+		s.dashboard.SetSkillsRegistry(registry)
+	}
+}
+
+// SetOffline has the API's agent answer with the local provider while
+// monitor reports the network down, as llm.offline configures
+func (s *Server) SetOffline(monitor *connectivity.Monitor) {
+	cfg := s.config.LLM.Offline
+	local, ok := s.config.LLM.Providers[cfg.Provider]
+	if monitor == nil || !ok {
+		return
+	}
+	s.llmClient.SetOfflineFallback(llm.NewClient(local), monitor)
+	if s.agent != nil {
+		s.agent.SetNetworkSkills(cfg.NetworkSkills)
+	}
+}
+
+// SetIncidentMode enables the admin incident endpoints and request tracing
+func (s *Server) SetIncidentMode(mode *incident.Mode) {
+	s.incident = mode
+	if s.agent != nil {
+		s.agent.SetIncidentMode(mode)
+	}
+}
+
+// SetJournal records the agent's file writes and commands in j
+func (s *Server) SetJournal(j *journal.Journal) {
+	if s.agent != nil {
+		s.agent.SetJournal(j)
+	}
+}
+
+// SetLocation enables location check-ins at POST /api/location
+func (s *Server) SetLocation(tracker *location.Tracker) {
+	s.location = tracker
+}
+
+// chatContext returns ctx carrying the persona the config binds to the API
+func (s *Server) chatContext(ctx context.Context) context.Context {
+	return persona.WithPersona(ctx, s.config.Agent.Personas["api"])
 }
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/skills/github"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// handleGitHubWebhook checks a GitHub webhook delivery's signature and
+// publishes it on the event bus, where triggers and workflows pick it up
+func (s *Server) handleGitHubWebhook(c *fiber.Ctx) error {
+	secret := s.config.Skills.GitHub.WebhookSecret
+	if secret == "" || s.skillsRegistry == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "GitHub webhooks are not enabled"})
+	}
+
+	body := c.Body()
+	if !github.VerifySignature(secret, body, c.Get("X-Hub-Signature-256")) {
+		return c.Status(401).JSON(fiber.Map{"error": "invalid signature"})
+	}
+
+	event, err := github.ParseWebhook(c.Get("X-GitHub-Event"), body)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if event == nil {
+		return c.SendStatus(204)
+	}
+
+	event.UserID = household.SharedUserID
+	s.logger.Info("GitHub webhook received",
+		zap.String("event", event.Type),
+		zap.String("delivery", c.Get("X-GitHub-Delivery")))
+	s.skillsRegistry.Events().Publish(c.Context(), *event)
+	return c.Status(202).JSON(fiber.Map{"event": event.Type})
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
+)
+
+// webUI is the built-in chat page, served at /ui/ so the assistant can be
+// used from a browser without building the dashboard. The page itself is
+// public; everything it loads goes through the API and needs the gateway
+// token.
+//
+//go:embed webui
+var webUI embed.FS
+
+// WebUIPath is where the built-in chat page is served
+const WebUIPath = "/ui"
+
+func (s *Server) setupWebUI() {
+	root, _ := fs.Sub(webUI, "webui")
+	// Relative asset links need the trailing slash; without strict routing
+	// this route matches /ui/ as well
+	s.app.Get(WebUIPath, func(c *fiber.Ctx) error {
+		if c.Path() == WebUIPath {
+			return c.Redirect(WebUIPath+"/", fiber.StatusMovedPermanently)
+		}
+		return c.Next()
+	})
+	s.app.Use(WebUIPath, filesystem.New(filesystem.Config{
+		Root:   http.FS(root),
+		Index:  "index.html",
+		MaxAge: 3600,
+	}))
+}
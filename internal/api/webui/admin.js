@@ -0,0 +1,206 @@
+// Myrai admin page: channel health, scheduled jobs and background tasks,
+// and skills with recent tool errors, from /api/admin. The admin endpoints
+// need the admin password, so the page logs in with it for a token carrying
+// the admin claim, kept apart from the chat page's gateway token.
+"use strict";
+
+const tokenKey = "myrai.adminToken";
+const $ = (id) => document.getElementById(id);
+
+class Unauthorized extends Error {}
+
+async function api(path, options = {}) {
+  const headers = new Headers(options.headers || {});
+  headers.set("Authorization", "Bearer " + (localStorage.getItem(tokenKey) || ""));
+  const resp = await fetch("/api/admin" + path, { ...options, headers });
+  const body = await resp.json().catch(() => ({}));
+  if (resp.status === 401) {
+    showLogin();
+    throw new Unauthorized(body.error || "admin authentication required");
+  }
+  if (!resp.ok) throw new Error(body.error || resp.statusText);
+  return body;
+}
+
+// Login
+
+function showLogin(error) {
+  $("login").hidden = false;
+  $("login-error").hidden = !error;
+  $("login-error").textContent = error || "";
+  $("password").focus();
+}
+
+// login trades the admin password for a token. Any password gets a token;
+// only the admin password gets one the admin endpoints accept.
+async function login(event) {
+  event.preventDefault();
+  const resp = await fetch("/api/auth/login", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ password: $("password").value }),
+  });
+  const body = await resp.json().catch(() => ({}));
+  if (!resp.ok || !body.token) {
+    showLogin(body.error || "Could not sign in.");
+    return;
+  }
+  localStorage.setItem(tokenKey, body.token);
+  try {
+    await api("/channels");
+  } catch (err) {
+    localStorage.removeItem(tokenKey);
+    showLogin(err instanceof Unauthorized ? "That password was not accepted." : err.message);
+    return;
+  }
+  $("login").hidden = true;
+  $("password").value = "";
+  load();
+}
+
+function logout() {
+  localStorage.removeItem(tokenKey);
+  showLogin();
+}
+
+function send(path, method, body) {
+  return api(path, {
+    method,
+    headers: { "Content-Type": "application/json" },
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+}
+
+function time(value) {
+  return value ? new Date(value).toLocaleString() : "—";
+}
+
+function row(cells) {
+  const tr = document.createElement("tr");
+  for (const cell of cells) {
+    const td = document.createElement("td");
+    if (cell instanceof Node) td.appendChild(cell);
+    else td.textContent = cell;
+    tr.appendChild(td);
+  }
+  return tr;
+}
+
+function fill(id, rows, empty) {
+  const body = $(id);
+  body.replaceChildren(...rows);
+  if (!rows.length) {
+    const tr = row([empty]);
+    tr.firstChild.colSpan = body.parentElement.querySelectorAll("th").length;
+    tr.className = "muted";
+    body.appendChild(tr);
+  }
+}
+
+function status(ok, yes, no) {
+  const span = document.createElement("span");
+  span.className = ok ? "ok" : "bad";
+  span.textContent = ok ? yes : no;
+  return span;
+}
+
+function actions(...buttons) {
+  const span = document.createElement("span");
+  span.className = "actions";
+  for (const [label, fn] of buttons) {
+    const button = document.createElement("button");
+    button.type = "button";
+    button.textContent = label;
+    button.addEventListener("click", async () => {
+      button.disabled = true;
+      try {
+        await fn();
+        await load();
+      } catch (err) {
+        if (!(err instanceof Unauthorized)) showError(err);
+        button.disabled = false;
+      }
+    });
+    span.appendChild(button);
+  }
+  return span;
+}
+
+function showError(err) {
+  $("admin-error").hidden = !err;
+  $("admin-error").textContent = err ? err.message : "";
+}
+
+async function loadChannels() {
+  const { channels } = await api("/channels");
+  fill("channels", channels.map((ch) => row([
+    ch.name,
+    status(ch.connected, "connected", "disconnected"),
+    time(ch.since),
+    time(ch.last_message),
+    ch.last_error ? ch.last_error + " (" + time(ch.last_error_at) + ")" : "",
+  ])), "No chat channels are enabled.");
+}
+
+async function loadCron() {
+  const cron = await api("/cron");
+  $("cron-state").textContent = cron.running ? "" : "(the cron runner is not running)";
+  const id = encodeURIComponent;
+  fill("jobs", cron.jobs.map((job) => row([
+    job.name,
+    job.cron_expression,
+    job.is_active ? time(job.next_run_at) : "disabled",
+    time(job.last_run_at),
+    String(job.run_count),
+    actions(
+      ["Run now", () => send("/cron/jobs/" + id(job.id) + "/run", "POST")],
+      [job.is_active ? "Disable" : "Enable", () => send("/cron/jobs/" + id(job.id), "PUT", { active: !job.is_active })],
+    ),
+  ])), "No scheduled jobs.");
+  fill("tasks", cron.tasks.map((task) => row([
+    task.name,
+    task.interval,
+    task.running ? "running" : task.enabled ? time(task.next_run_at) : "disabled",
+    time(task.last_run_at),
+    task.last_error || "",
+    actions(
+      ["Run now", () => send("/cron/tasks/" + id(task.name) + "/run", "POST")],
+      [task.enabled ? "Disable" : "Enable", () => send("/cron/tasks/" + id(task.name), "PUT", { enabled: !task.enabled })],
+    ),
+  ])), "No background tasks.");
+}
+
+async function loadSkills() {
+  const { skills, tool_errors: errors } = await api("/skills");
+  fill("skills", skills.map((skill) => {
+    const tr = row([
+      skill.name,
+      skill.version,
+      status(skill.enabled, "enabled", "disabled"),
+      String(skill.tools.length),
+      skill.recent_errors ? String(skill.recent_errors) : "",
+    ]);
+    tr.title = skill.description + (skill.tools.length ? "\n\nTools: " + skill.tools.join(", ") : "");
+    return tr;
+  }), "No skills are registered.");
+  fill("tool-errors", errors.map((e) => row([time(e.at), e.skill, e.tool, e.error])), "No tool errors.");
+}
+
+async function load() {
+  if (!$("login").hidden) return;
+  const results = await Promise.allSettled([loadChannels(), loadCron(), loadSkills()]);
+  const failed = results.find((r) => r.status === "rejected" && !(r.reason instanceof Unauthorized));
+  showError(failed ? failed.reason : null);
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+  $("login-form").addEventListener("submit", login);
+  $("logout").addEventListener("click", logout);
+  $("refresh").addEventListener("click", load);
+  if (localStorage.getItem(tokenKey)) {
+    load();
+  } else {
+    showLogin();
+  }
+  setInterval(load, 30000);
+});
@@ -0,0 +1,248 @@
+// Myrai web chat. Talks to the gateway API with the gateway token, kept in
+// localStorage, as a Bearer token.
+"use strict";
+
+const tokenKey = "myrai.token";
+const $ = (id) => document.getElementById(id);
+
+const state = {
+  conversationID: "",
+  busy: false,
+};
+
+class Unauthorized extends Error {}
+
+async function api(path, options = {}) {
+  const headers = new Headers(options.headers || {});
+  headers.set("Authorization", "Bearer " + (localStorage.getItem(tokenKey) || ""));
+  const resp = await fetch("/api" + path, { ...options, headers });
+  if (resp.status === 401) {
+    showLogin();
+    throw new Unauthorized("unauthorized");
+  }
+  if (!resp.ok) {
+    let message = resp.statusText;
+    try {
+      message = (await resp.json()).error || message;
+    } catch (_) {}
+    throw new Error(message);
+  }
+  return resp;
+}
+
+async function apiJSON(path, options) {
+  return (await api(path, options)).json();
+}
+
+// Login
+
+function showLogin(error) {
+  $("login").hidden = false;
+  $("login-error").hidden = !error;
+  $("login-error").textContent = error || "";
+  $("token").focus();
+}
+
+async function login(event) {
+  event.preventDefault();
+  localStorage.setItem(tokenKey, $("token").value.trim());
+  try {
+    await api("/conversations?limit=1");
+  } catch (err) {
+    showLogin(err instanceof Unauthorized ? "That token was not accepted." : err.message);
+    return;
+  }
+  $("login").hidden = true;
+  $("token").value = "";
+  load();
+}
+
+function logout() {
+  localStorage.removeItem(tokenKey);
+  showLogin();
+}
+
+// Conversations
+
+async function loadConversations() {
+  const list = $("conversations");
+  const convs = await apiJSON("/conversations?limit=50");
+  list.replaceChildren();
+  for (const conv of convs) {
+    const item = document.createElement("li");
+    item.textContent = conv.title || "Untitled";
+    item.title = conv.title || "";
+    item.classList.toggle("active", conv.id === state.conversationID);
+    item.addEventListener("click", () => openConversation(conv.id, conv.title));
+    list.appendChild(item);
+  }
+}
+
+async function openConversation(id, title) {
+  if (state.busy) return;
+  state.conversationID = id;
+  $("chat-title").textContent = title || "Untitled";
+  $("messages").replaceChildren();
+  const messages = await apiJSON("/conversations/" + encodeURIComponent(id) + "/messages?limit=-1");
+  for (const msg of messages) {
+    if ((msg.role === "user" || msg.role === "assistant") && msg.content) {
+      addMessage(msg.role, msg.content);
+    }
+  }
+  loadConversations();
+}
+
+function newChat() {
+  if (state.busy) return;
+  state.conversationID = "";
+  $("chat-title").textContent = "New chat";
+  $("messages").replaceChildren();
+  loadConversations();
+  $("input").focus();
+}
+
+// Messages
+
+function addMessage(kind, text) {
+  const el = document.createElement("div");
+  el.className = "message " + kind;
+  el.textContent = text;
+  $("messages").appendChild(el);
+  el.scrollIntoView({ block: "end" });
+  return el;
+}
+
+function logActivity(text) {
+  const list = $("activity");
+  list.querySelector(".muted")?.remove();
+  const item = document.createElement("li");
+  item.textContent = new Date().toLocaleTimeString() + "  " + text;
+  list.prepend(item);
+  while (list.children.length > 50) list.lastChild.remove();
+}
+
+function setBusy(busy) {
+  state.busy = busy;
+  $("send").disabled = busy;
+  $("new-chat").disabled = busy;
+}
+
+// send streams the reply from /api/chat/stream, which sends server-sent
+// events: {"chunk"} for text, {"tool"} when a tool runs, {"error"}, and a
+// final {"conversation_id"} before [DONE].
+async function send(event) {
+  event.preventDefault();
+  const text = $("input").value.trim();
+  if (!text || state.busy) return;
+  $("input").value = "";
+  addMessage("user", text);
+  const reply = addMessage("assistant", "…");
+  let content = "";
+  setBusy(true);
+
+  try {
+    const resp = await api("/chat/stream", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ conversation_id: state.conversationID, message: text }),
+    });
+    const reader = resp.body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = "";
+    for (;;) {
+      const { done, value } = await reader.read();
+      if (done) break;
+      buffer += decoder.decode(value, { stream: true });
+      const events = buffer.split("\n\n");
+      buffer = events.pop();
+      for (const raw of events) {
+        if (!raw.startsWith("data: ")) continue;
+        const data = raw.slice(6);
+        if (data === "[DONE]") continue;
+        const msg = JSON.parse(data);
+        if (msg.chunk !== undefined) {
+          content += msg.chunk;
+          reply.textContent = content;
+          reply.scrollIntoView({ block: "end" });
+        } else if (msg.tool) {
+          logActivity("Running " + msg.tool);
+        } else if (msg.error) {
+          addMessage("error", msg.error);
+        } else if (msg.conversation_id) {
+          state.conversationID = msg.conversation_id;
+          logActivity("Answered by " + (msg.model || "the assistant") +
+            (msg.offline ? " (offline)" : "") + ", " + msg.tokens_used + " tokens");
+        }
+      }
+    }
+  } catch (err) {
+    if (!(err instanceof Unauthorized)) addMessage("error", err.message);
+  } finally {
+    if (!content) reply.remove();
+    setBusy(false);
+  }
+  loadConversations().catch(() => {});
+}
+
+// Files go into the knowledge base, where chats can search them
+
+async function upload() {
+  const input = $("file");
+  const file = input.files[0];
+  input.value = "";
+  if (!file) return;
+  const note = addMessage("note", "Adding " + file.name + " to the knowledge base…");
+  const form = new FormData();
+  form.append("file", file);
+  try {
+    const doc = await apiJSON("/documents", { method: "POST", body: form });
+    note.textContent = "Added " + doc.filename + " to the knowledge base (" + doc.chunks + " chunks). Ask about it in any chat.";
+    logActivity("Indexed " + doc.filename);
+  } catch (err) {
+    note.className = "message error";
+    note.textContent = "Could not add " + file.name + ": " + err.message;
+  }
+}
+
+// Skills
+
+async function loadSkills() {
+  const list = $("skills");
+  const skills = await apiJSON("/skills");
+  skills.sort((a, b) => a.name.localeCompare(b.name));
+  list.replaceChildren();
+  for (const skill of skills) {
+    const item = document.createElement("li");
+    item.title = skill.description || "";
+    item.classList.toggle("disabled", !skill.enabled);
+    const tools = document.createElement("span");
+    tools.className = "tools";
+    tools.textContent = skill.tools + (skill.tools === 1 ? " tool" : " tools");
+    item.append(skill.name, tools);
+    list.appendChild(item);
+  }
+}
+
+async function load() {
+  try {
+    await Promise.all([loadConversations(), loadSkills()]);
+  } catch (err) {
+    if (!(err instanceof Unauthorized)) addMessage("error", err.message);
+  }
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+  $("login-form").addEventListener("submit", login);
+  $("logout").addEventListener("click", logout);
+  $("new-chat").addEventListener("click", newChat);
+  $("composer").addEventListener("submit", send);
+  $("file").addEventListener("change", upload);
+  $("input").addEventListener("keydown", (event) => {
+    if (event.key === "Enter" && !event.shiftKey) send(event);
+  });
+  if (localStorage.getItem(tokenKey)) {
+    load();
+  } else {
+    showLogin();
+  }
+});
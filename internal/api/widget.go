@@ -0,0 +1,258 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/skills/calendar"
+	"github.com/gmsas95/myrai-cli/internal/skills/tasks"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// widgetUserKey holds the user whose data a widget request shows
+const widgetUserKey = "widget_user_id"
+
+// widgetMaxAge is how long widget clients and proxies may cache responses
+const widgetMaxAge = 5 * time.Minute
+
+// widgetTask is the compact task representation returned to widgets
+type widgetTask struct {
+	Title    string `json:"title"`
+	Due      string `json:"due,omitempty"`
+	Priority string `json:"priority,omitempty"`
+	Overdue  bool   `json:"overdue,omitempty"`
+}
+
+// widgetEvent is the compact event representation returned to widgets
+type widgetEvent struct {
+	Title    string `json:"title"`
+	Start    string `json:"start"`
+	End      string `json:"end,omitempty"`
+	AllDay   bool   `json:"all_day,omitempty"`
+	Location string `json:"location,omitempty"`
+}
+
+// widgetAuthMiddleware accepts widget tokens, passed either as a bearer token
+// or as a ?token= query parameter for widget apps that cannot set headers.
+// Widget tokens only grant access to the read-only widget endpoints.
+func (s *Server) widgetAuthMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := c.Query("token")
+		if token == "" {
+			token = strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+		}
+		if token == "" {
+			return c.Status(401).JSON(fiber.Map{"error": "missing widget token"})
+		}
+
+		wt, err := s.store.ValidateWidgetToken(token)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(401).JSON(fiber.Map{"error": "invalid widget token"})
+		}
+		if err != nil {
+			s.logger.Error("Failed to check widget token", zap.Error(err))
+			return c.Status(500).JSON(fiber.Map{"error": "failed to check widget token"})
+		}
+
+		// Tokens from before owners were recorded show the shared data
+		userID := wt.UserID
+		if userID == "" {
+			userID = household.SharedUserID
+		}
+		c.Locals(widgetUserKey, userID)
+		return c.Next()
+	}
+}
+
+// widgetUserID returns the owner of the request's widget token
+func widgetUserID(c *fiber.Ctx) string {
+	userID, _ := c.Locals(widgetUserKey).(string)
+	return userID
+}
+
+func (s *Server) handleWidgetToday(c *fiber.Ctx) error {
+	now := time.Now()
+
+	due, overdue, err := s.widgetTasks(widgetUserID(c), now, 5)
+	if err != nil {
+		s.logger.Error("Failed to load widget tasks", zap.Error(err))
+		return c.Status(500).JSON(fiber.Map{"error": "failed to load tasks"})
+	}
+
+	events := []widgetEvent{}
+	var next *widgetEvent
+	if s.calendarStore != nil {
+		dayEvents, err := s.calendarStore.GetEventsForDay(widgetUserID(c), now)
+		if err != nil {
+			s.logger.Error("Failed to load widget events", zap.Error(err))
+			return c.Status(500).JSON(fiber.Map{"error": "failed to load events"})
+		}
+		for _, e := range dayEvents {
+			we := toWidgetEvent(e)
+			events = append(events, we)
+			if next == nil && !e.AllDay && e.StartTime.After(now) {
+				next = &we
+			}
+		}
+	}
+
+	return s.sendWidget(c, fiber.Map{
+		"date":          now.Format("2006-01-02"),
+		"weekday":       now.Weekday().String(),
+		"tasks_due":     len(due),
+		"tasks_overdue": overdue,
+		"tasks":         due,
+		"events":        events,
+		"next_event":    next,
+	})
+}
+
+func (s *Server) handleWidgetTasks(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 5)
+	if limit < 1 || limit > 20 {
+		limit = 5
+	}
+
+	due, overdue, err := s.widgetTasks(widgetUserID(c), time.Time{}, limit)
+	if err != nil {
+		s.logger.Error("Failed to load widget tasks", zap.Error(err))
+		return c.Status(500).JSON(fiber.Map{"error": "failed to load tasks"})
+	}
+
+	return s.sendWidget(c, fiber.Map{
+		"count":   len(due),
+		"overdue": overdue,
+		"tasks":   due,
+	})
+}
+
+// widgetTasks returns up to limit open tasks of userID, overdue first. When
+// day is set only tasks due by the end of that day are included.
+func (s *Server) widgetTasks(userID string, day time.Time, limit int) ([]widgetTask, int, error) {
+	result := []widgetTask{}
+	if s.taskStore == nil {
+		return result, 0, nil
+	}
+
+	opts := tasks.ListOptions{
+		Status:  []tasks.TaskStatus{tasks.TaskStatusPending, tasks.TaskStatusInProgress},
+		OrderBy: "due_date IS NULL, due_date ASC, created_at DESC",
+		Limit:   limit,
+	}
+	if !day.IsZero() {
+		endOfDay := time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 59, 0, day.Location())
+		opts.DueBefore = &endOfDay
+	}
+
+	list, err := s.taskStore.ListTasks(userID, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	for _, t := range list.Tasks {
+		wt := widgetTask{Title: t.Title, Priority: string(t.Priority)}
+		if t.DueDate != nil {
+			wt.Due = t.DueDate.Format(time.RFC3339)
+			wt.Overdue = t.DueDate.Before(now)
+		}
+		result = append(result, wt)
+	}
+	return result, list.Overdue, nil
+}
+
+func toWidgetEvent(e calendar.CalendarEvent) widgetEvent {
+	we := widgetEvent{
+		Title:    e.Title,
+		AllDay:   e.AllDay,
+		Location: e.Location,
+	}
+	if e.AllDay {
+		we.Start = e.StartTime.Format("2006-01-02")
+	} else {
+		we.Start = e.StartTime.Format("15:04")
+		we.End = e.EndTime.Format("15:04")
+	}
+	return we
+}
+
+// sendWidget writes a widget payload with caching headers. Clients that send
+// the previous ETag get a 304 when nothing changed.
+func (s *Server) sendWidget(c *fiber.Ctx, payload fiber.Map) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "failed to encode response"})
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	c.Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(widgetMaxAge.Seconds())))
+	c.Set("ETag", etag)
+	if c.Get("If-None-Match") == etag {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set("Content-Type", fiber.MIMEApplicationJSON)
+	return c.Send(body)
+}
+
+// widgetTokenOwners returns the token owners whose tokens the caller
+// manages: the caller, and for the shared user also the tokens from before
+// owners were recorded
+func (s *Server) widgetTokenOwners(c *fiber.Ctx) []string {
+	userID := household.UserID(s.chatContext(c.Context()))
+	if userID == household.SharedUserID {
+		return []string{userID, ""}
+	}
+	return []string{userID}
+}
+
+func (s *Server) handleListWidgetTokens(c *fiber.Ctx) error {
+	tokens, err := s.store.ListWidgetTokens(s.widgetTokenOwners(c))
+	if err != nil {
+		s.logger.Error("Failed to list widget tokens", zap.Error(err))
+		return c.Status(500).JSON(fiber.Map{"error": "failed to list widget tokens"})
+	}
+	return c.JSON(tokens)
+}
+
+func (s *Server) handleCreateWidgetToken(c *fiber.Ctx) error {
+	var req struct {
+		Name string `json:"name"`
+	}
+	c.BodyParser(&req)
+	if req.Name == "" {
+		req.Name = "widget"
+	}
+
+	// The widget shows the data of whoever creates the token
+	wt, token, err := s.store.CreateWidgetToken(req.Name, household.UserID(s.chatContext(c.Context())))
+	if err != nil {
+		s.logger.Error("Failed to create widget token", zap.Error(err))
+		return c.Status(500).JSON(fiber.Map{"error": "failed to create widget token"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"id":         wt.ID,
+		"name":       wt.Name,
+		"user_id":    wt.UserID,
+		"token":      token,
+		"created_at": wt.CreatedAt,
+	})
+}
+
+func (s *Server) handleDeleteWidgetToken(c *fiber.Ctx) error {
+	if err := s.store.RevokeWidgetToken(c.Params("id"), s.widgetTokenOwners(c)); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "widget token not found"})
+	}
+	return c.SendStatus(204)
+}
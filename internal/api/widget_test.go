@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/skills/tasks"
+)
+
+func widgetTaskTitles(t *testing.T, s *Server, token string) []string {
+	t.Helper()
+	resp, err := s.app.Test(httptest.NewRequest("GET", "/api/widget/tasks?token="+token, nil))
+	if err != nil {
+		t.Fatalf("Widget request failed: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	var out struct {
+		Tasks []widgetTask `json:"tasks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("Failed to decode widget tasks: %v", err)
+	}
+	var titles []string
+	for _, task := range out.Tasks {
+		titles = append(titles, task.Title)
+	}
+	return titles
+}
+
+func TestWidgetTasks_ShowTheTokenOwnersTasks(t *testing.T) {
+	s := newTestServer(t, "")
+	for _, task := range []*tasks.Task{
+		{UserID: household.SharedUserID, Title: "Pay rent", Status: tasks.TaskStatusPending},
+		{UserID: "profile_1", Title: "Water plants", Status: tasks.TaskStatusPending},
+	} {
+		if err := s.taskStore.CreateTask(task); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	_, token, err := s.store.CreateWidgetToken("phone", "profile_1")
+	if err != nil {
+		t.Fatalf("Failed to create widget token: %v", err)
+	}
+	if titles := widgetTaskTitles(t, s, token); len(titles) != 1 || titles[0] != "Water plants" {
+		t.Errorf("Expected only the owner's task, got %v", titles)
+	}
+
+	// Tokens without an owner show the shared tasks
+	_, legacy, err := s.store.CreateWidgetToken("old phone", "")
+	if err != nil {
+		t.Fatalf("Failed to create widget token: %v", err)
+	}
+	if titles := widgetTaskTitles(t, s, legacy); len(titles) != 1 || titles[0] != "Pay rent" {
+		t.Errorf("Expected the shared task, got %v", titles)
+	}
+}
+
+func TestWidgetTokens_ScopedToTheCaller(t *testing.T) {
+	s := newTestServer(t, "")
+	auth := "Bearer gateway-token"
+
+	own, _, err := s.store.CreateWidgetToken("phone", household.SharedUserID)
+	if err != nil {
+		t.Fatalf("Failed to create widget token: %v", err)
+	}
+	legacy, _, err := s.store.CreateWidgetToken("old phone", "")
+	if err != nil {
+		t.Fatalf("Failed to create widget token: %v", err)
+	}
+	other, _, err := s.store.CreateWidgetToken("their phone", "profile_1")
+	if err != nil {
+		t.Fatalf("Failed to create widget token: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/widget/tokens", nil)
+	req.Header.Set("Authorization", auth)
+	resp, err := s.app.Test(req)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var list []struct {
+		ID string `json:"id"`
+	}
+	json.NewDecoder(resp.Body).Decode(&list)
+	if len(list) != 2 {
+		t.Fatalf("Expected the caller's 2 tokens, got %+v", list)
+	}
+	for _, wt := range list {
+		if wt.ID != own.ID && wt.ID != legacy.ID {
+			t.Errorf("Expected only the caller's tokens, got %s", wt.ID)
+		}
+	}
+
+	if code := request(t, s, "DELETE", "/api/widget/tokens/"+other.ID, auth); code != http.StatusNotFound {
+		t.Errorf("Expected 404 revoking another user's token, got %d", code)
+	}
+	if tokens, _ := s.store.ListWidgetTokens([]string{"profile_1"}); len(tokens) != 1 {
+		t.Error("Expected the other user's token to be kept")
+	}
+	if code := request(t, s, "DELETE", "/api/widget/tokens/"+own.ID, auth); code != http.StatusNoContent {
+		t.Errorf("Expected the caller's token to be revoked, got %d", code)
+	}
+}
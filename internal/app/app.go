@@ -7,19 +7,45 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/aliases"
 	"github.com/gmsas95/myrai-cli/internal/api"
+	"github.com/gmsas95/myrai-cli/internal/artifacts"
+	"github.com/gmsas95/myrai-cli/internal/channels"
 	"github.com/gmsas95/myrai-cli/internal/channels/discord"
 	"github.com/gmsas95/myrai-cli/internal/channels/telegram"
 	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/connectivity"
 	"github.com/gmsas95/myrai-cli/internal/cron"
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/incident"
+	"github.com/gmsas95/myrai-cli/internal/journal"
 	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/gmsas95/myrai-cli/internal/location"
 	"github.com/gmsas95/myrai-cli/internal/mcp"
+	"github.com/gmsas95/myrai-cli/internal/mqtt"
+	"github.com/gmsas95/myrai-cli/internal/notify"
 	"github.com/gmsas95/myrai-cli/internal/persona"
+	"github.com/gmsas95/myrai-cli/internal/remote"
+	"github.com/gmsas95/myrai-cli/internal/security"
 	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/skills/agentic"
+	"github.com/gmsas95/myrai-cli/internal/skills/calendar"
+	"github.com/gmsas95/myrai-cli/internal/skills/contacts"
+	"github.com/gmsas95/myrai-cli/internal/skills/devices"
+	"github.com/gmsas95/myrai-cli/internal/skills/email"
+	"github.com/gmsas95/myrai-cli/internal/skills/github"
+	"github.com/gmsas95/myrai-cli/internal/skills/health"
+	"github.com/gmsas95/myrai-cli/internal/skills/homeassistant"
+	"github.com/gmsas95/myrai-cli/internal/skills/kb"
+	"github.com/gmsas95/myrai-cli/internal/skills/notes"
+	"github.com/gmsas95/myrai-cli/internal/skills/shopping"
+	"github.com/gmsas95/myrai-cli/internal/skills/voice"
 	"github.com/gmsas95/myrai-cli/internal/store"
 	"github.com/gmsas95/myrai-cli/internal/vector"
 	"github.com/gmsas95/myrai-cli/pkg/tools"
@@ -34,8 +60,15 @@ type App struct {
 	TelegramBot    *telegram.Bot
 	DiscordBot     *discord.Bot
 	CronRunner     *cron.Runner
+	Notifier       *notify.Dispatcher
+	Incident       *incident.Mode
+	Journal        *journal.Journal
+	Artifacts      *artifacts.Store
+	Location       *location.Tracker
 	PersonaManager *persona.PersonaManager
 	Version        string
+
+	reloadMu sync.Mutex // serializes config reloads
 }
 
 func New(cfg *config.Config, st *store.Store, logger *zap.Logger, pm *persona.PersonaManager, version string) *App {
@@ -50,6 +83,43 @@ func New(cfg *config.Config, st *store.Store, logger *zap.Logger, pm *persona.Pe
 
 func (app *App) SetSkillsRegistry(registry *skills.Registry) {
 	app.SkillsRegistry = registry
+	if registry == nil {
+		return
+	}
+	registry.SetDisabled(app.Config.Skills.Disabled)
+
+	// Scope skill data to the household profile making the request
+	scope := household.ContextHook(app.Config.Household.Shared)
+	locales, err := locale.NewManager(app.Store.DB())
+	if err != nil {
+		app.Logger.Warn("Failed to load locale preferences", zap.Error(err))
+		registry.SetContextHook(scope)
+		return
+	}
+	tracker := app.locationTracker()
+	registry.SetContextHook(func(ctx context.Context, skill string) context.Context {
+		// The locale and location follow the person, even in skills whose
+		// data is shared
+		person := personID(ctx)
+		ctx = locale.WithLocale(ctx, locales.Get(person))
+		if tracker != nil {
+			if fix, err := tracker.Current(person); err == nil {
+				ctx = location.WithFix(ctx, fix)
+			}
+		}
+		return scope(ctx, skill)
+	})
+}
+
+// personID returns the user ID holding the requester's own preferences
+func personID(ctx context.Context) string {
+	if profile := household.FromContext(ctx); profile != nil {
+		return profile.UserID
+	}
+	if userID, ok := ctx.Value("user_id").(string); ok && userID != "" {
+		return userID
+	}
+	return household.SharedUserID
 }
 
 func (app *App) RunServer() {
@@ -61,8 +131,25 @@ func (app *App) RunServer() {
 
 	agentInstance := agent.New(llmClient, nil, app.Store, app.Logger, app.PersonaManager)
 	agentInstance.SetSkillsRegistry(app.SkillsRegistry)
+	agentInstance.SetIncidentMode(app.Incident)
+	agentInstance.SetJournal(app.activityJournal())
+	app.enableArtifacts(agentInstance)
+	app.enableReplyPipeline(agentInstance)
+	agentInstance.SetTitles(app.Config.Agent.Titles)
+	agentInstance.SetRouter(agent.NewRouter(app.Config.Agent.Routing))
+	agentInstance.SetPinBudget(app.Config.Agent.PinBudget)
+	offline := app.enableOffline(llmClient, provider, agentInstance)
+	app.enableSubAgents(agentInstance)
+	// Plans run in the background, so only the long-running server offers
+	// run_task_plan
+	if app.SkillsRegistry != nil {
+		if err := agentInstance.EnablePlans(); err != nil {
+			app.Logger.Warn("Failed to enable task plans", zap.Error(err))
+		}
+	}
 
 	agentLoop := agent.NewAgentLoop(agentInstance, app.Logger)
+	agentLoop.Configure(agent.LoopOptionsFromConfig(app.Config.Agent.Loop))
 	agentInstance.SetAgentLoop(agentLoop)
 
 	var contextManager *agent.ContextManager
@@ -72,28 +159,128 @@ func (app *App) RunServer() {
 			app.Logger.Warn("Failed to create vector searcher", zap.Error(err))
 		} else {
 			contextManager = agent.NewContextManager(app.Store, vectorSearcher, llmClient, app.Logger)
+			contextManager.SetProvider(provider)
 			agentInstance.SetContextManager(contextManager)
 			app.Logger.Info("Context manager initialized with vector search")
 		}
 	} else {
 		contextManager = agent.NewContextManager(app.Store, nil, llmClient, app.Logger)
+		contextManager.SetProvider(provider)
 		agentInstance.SetContextManager(contextManager)
 		app.Logger.Info("Context manager initialized (without vector search)")
 	}
 
+	// Answers can draw on, and cite, passages from the user's documents
+	if contextManager != nil && app.SkillsRegistry != nil {
+		if skill, ok := app.SkillsRegistry.GetSkill("kb"); ok {
+			contextManager.SetDocumentSearcher(kbDocuments{
+				store: skill.(*kb.KnowledgeBaseSkill).Store(),
+				scope: household.ContextHook(app.Config.Household.Shared),
+			})
+		}
+	}
+
+	// Every message sent without being asked goes through the notifier, so
+	// quiet hours, routing and digests apply no matter who sends it
+	notifier, err := notify.NewDispatcher(app.Store.DB(), app.Logger)
+	if err != nil {
+		app.Logger.Warn("Failed to initialize notifications", zap.Error(err))
+	} else {
+		app.Notifier = notifier
+		notifier.SetJournal(app.Journal)
+		notifier.Start()
+	}
+	app.startEveningSummary()
+	asyncJobs := app.startAsyncJobs(agentInstance)
+
+	if tracker := app.locationTracker(); tracker != nil {
+		if app.Notifier != nil {
+			tracker.SetNotifier(app.Notifier)
+		}
+		tracker.Start()
+	}
+
+	// Approval codes for outgoing email, changes to shared shopping lists
+	// and code patches reach people through the notifier
+	if app.Notifier != nil && app.SkillsRegistry != nil {
+		if skill, ok := app.SkillsRegistry.GetSkill("agentic"); ok {
+			skill.(*agentic.AgenticSkill).SetNotifier(app.Notifier)
+		}
+		if skill, ok := app.SkillsRegistry.GetSkill("email"); ok {
+			skill.(*email.EmailSkill).SetNotifier(app.Notifier)
+		}
+		if skill, ok := app.SkillsRegistry.GetSkill("shopping"); ok {
+			skill.(*shopping.ShoppingSkill).SetNotifier(app.Notifier)
+		}
+	}
+
+	var contactReminders *contacts.Reminders
+	if app.Notifier != nil {
+		contactReminders, err = contacts.NewReminders(app.Store.DB(), app.Notifier, app.Logger)
+		if err != nil {
+			app.Logger.Warn("Failed to start contact reminders", zap.Error(err))
+		} else {
+			contactReminders.Start()
+		}
+	}
+
+	var medicationReminders *health.Reminders
+	if app.Notifier != nil {
+		medicationReminders, err = health.NewReminders(app.Store.DB(), app.Notifier, app.Logger)
+		if err != nil {
+			app.Logger.Warn("Failed to start medication reminders", zap.Error(err))
+		} else {
+			if app.SkillsRegistry != nil {
+				medicationReminders.SetEventBus(app.SkillsRegistry.Events())
+			}
+			medicationReminders.Start()
+		}
+	}
+
+	notesWatcher := app.startNotesWatcher()
+
+	var mqttBridge *mqtt.Bridge
+	if app.Config.MQTT.Enabled && app.SkillsRegistry != nil {
+		mqttBridge = app.startMQTT(agentInstance)
+	}
+
+	haWatcher := app.startHomeAssistantWatcher()
+	stopGitHubTriggers := app.startGitHubTriggers(agentInstance)
+
 	if app.Config.Channels.Telegram.Enabled {
 		telegramCfg := telegram.Config{
 			Token:     app.Config.Channels.Telegram.BotToken,
 			Enabled:   true,
 			AllowList: app.Config.Channels.Telegram.AllowList,
+			GroupMode: app.Config.Channels.Telegram.GroupMode,
 		}
 
 		go func() {
+			filter, err := app.contentFilter(app.Config.Channels.Telegram.ContentFilter)
+			if err != nil {
+				app.Logger.Error("Failed to create Telegram content filter", zap.Error(err))
+				return
+			}
 			bot, err := telegram.NewBot(telegramCfg, agentInstance, app.Store, app.Logger)
 			if err != nil {
 				app.Logger.Error("Failed to create Telegram bot", zap.Error(err))
 				return
 			}
+			bot.SetContentFilter(filter, app.Journal)
+			bot.SetInboundLimits(app.Config.Channels.Telegram.Inbound)
+			bot.SetPersona(app.Config.Agent.Personas["telegram"])
+			if app.Notifier != nil {
+				bot.SetNotifier(app.Notifier)
+			}
+			bot.SetIncidentMode(app.Incident)
+			if app.SkillsRegistry != nil {
+				if skill, ok := app.SkillsRegistry.GetSkill("kb"); ok {
+					bot.SetKnowledgeBase(skill.(*kb.KnowledgeBaseSkill).Store(), household.ContextHook(app.Config.Household.Shared))
+				}
+			}
+			if replier := app.voiceReplier(); replier != nil {
+				bot.SetVoiceReplies(replier)
+			}
 			if err := bot.Start(); err != nil {
 				app.Logger.Error("Failed to start Telegram bot", zap.Error(err))
 				return
@@ -105,17 +292,33 @@ func (app *App) RunServer() {
 
 	if app.Config.Channels.Discord.Enabled && app.Config.Channels.Discord.Token != "" {
 		discordCfg := discord.Config{
-			Token:   app.Config.Channels.Discord.Token,
-			Enabled: true,
-			AllowDM: true,
+			Token:     app.Config.Channels.Discord.Token,
+			Enabled:   true,
+			AllowDM:   true,
+			GroupMode: app.Config.Channels.Discord.GroupMode,
 		}
 
 		go func() {
+			filter, err := app.contentFilter(app.Config.Channels.Discord.ContentFilter)
+			if err != nil {
+				app.Logger.Error("Failed to create Discord content filter", zap.Error(err))
+				return
+			}
 			db, err := discord.NewBot(discordCfg, agentInstance, app.Store, app.Logger)
 			if err != nil {
 				app.Logger.Error("Failed to create Discord bot", zap.Error(err))
 				return
 			}
+			db.SetContentFilter(filter, app.Journal)
+			db.SetInboundLimits(app.Config.Channels.Discord.Inbound)
+			db.SetPersona(app.Config.Agent.Personas["discord"])
+			if app.Notifier != nil {
+				db.SetNotifier(app.Notifier)
+			}
+			db.SetIncidentMode(app.Incident)
+			if replier := app.voiceReplier(); replier != nil {
+				db.SetVoiceReplies(replier)
+			}
 			if err := db.Start(); err != nil {
 				app.Logger.Error("Failed to start Discord bot", zap.Error(err))
 				return
@@ -143,6 +346,13 @@ func (app *App) RunServer() {
 			MaxConcurrent: app.Config.Cron.MaxConcurrent,
 		}
 		app.CronRunner = cron.NewRunner(cronConfig, agentInstance, app.Store, app.Logger)
+		if app.Notifier != nil {
+			app.CronRunner.SetNotifier(app.Notifier)
+		}
+		app.CronRunner.SetJournal(app.Journal)
+		app.scheduleCalendarSync(app.CronRunner)
+		app.scheduleBackups(app.CronRunner)
+		app.scheduleRetention(app.CronRunner)
 		if err := app.CronRunner.Start(); err != nil {
 			app.Logger.Error("Failed to start cron runner", zap.Error(err))
 		} else {
@@ -152,6 +362,16 @@ func (app *App) RunServer() {
 
 	server := api.New(app.Config, app.Store, app.Logger)
 	server.SetSkillsRegistry(app.SkillsRegistry)
+	server.SetIncidentMode(app.Incident)
+	server.SetJournal(app.Journal)
+	server.SetOffline(offline)
+	server.SetChannels(app.channelHealth)
+	if app.CronRunner != nil {
+		server.SetCronRunner(app.CronRunner)
+	}
+	if app.Location != nil {
+		server.SetLocation(app.Location)
+	}
 
 	go func() {
 		if err := server.Start(); err != nil {
@@ -165,6 +385,8 @@ func (app *App) RunServer() {
 		zap.String("url", fmt.Sprintf("http://localhost:%d", app.Config.Server.Port)),
 	)
 
+	tailnet := app.startTailscale(server)
+
 	skillsList := app.SkillsRegistry.ListSkills()
 	app.Logger.Info("Loaded skills", zap.Int("count", len(skillsList)))
 	for _, skill := range skillsList {
@@ -177,14 +399,20 @@ func (app *App) RunServer() {
 
 	if app.PersonaManager != nil {
 		identity := app.PersonaManager.GetIdentity()
-		app.Logger.Info("Persona loaded", zap.String("name", identity.Name))
+		app.Logger.Info("Persona loaded",
+			zap.String("name", identity.Name),
+			zap.String("persona", app.PersonaManager.CurrentPersona()),
+		)
 	}
 
+	configWatcher := app.startConfigWatcher()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	app.Logger.Info("Shutting down...")
+	configWatcher.Stop()
 
 	if app.TelegramBot != nil {
 		app.TelegramBot.Stop()
@@ -198,9 +426,71 @@ func (app *App) RunServer() {
 		app.CronRunner.Stop()
 	}
 
+	if haWatcher != nil {
+		haWatcher.Stop()
+	}
+	if mqttBridge != nil {
+		mqttBridge.Stop()
+	}
+	stopGitHubTriggers()
+	if app.Location != nil {
+		app.Location.Stop()
+	}
+	if contactReminders != nil {
+		contactReminders.Stop()
+	}
+	if medicationReminders != nil {
+		medicationReminders.Stop()
+	}
+	if notesWatcher != nil {
+		notesWatcher.Stop()
+	}
+
+	if asyncJobs != nil {
+		asyncJobs.Stop()
+	}
+	offline.Stop()
+
+	app.Journal.Stop()
+
+	if app.Notifier != nil {
+		app.Notifier.Stop()
+	}
+
 	if err := server.Shutdown(); err != nil {
 		app.Logger.Error("Server shutdown error", zap.Error(err))
 	}
+	if tailnet != nil {
+		tailnet.Close()
+	}
+}
+
+// startTailscale serves the API and web UI on the tailnet when an auth key
+// is configured. Joining happens in the background; the server keeps
+// running locally if it fails.
+func (app *App) startTailscale(server *api.Server) *remote.Tailscale {
+	cfg := app.Config.Server.Tailscale
+	if cfg.AuthKey == "" {
+		return nil
+	}
+	tailnet, err := remote.NewTailscale(cfg, app.Config.Storage.DataDir, app.Logger)
+	if err != nil {
+		app.Logger.Error("Remote access disabled", zap.Error(err))
+		return nil
+	}
+
+	go func() {
+		ln, err := tailnet.Listen(context.Background())
+		if err != nil {
+			app.Logger.Error("Remote access disabled", zap.Error(err))
+			return
+		}
+		app.Logger.Info("Remote access over Tailscale", zap.String("url", tailnet.URL()))
+		if err := server.Serve(ln); err != nil {
+			app.Logger.Error("Tailscale listener stopped", zap.Error(err))
+		}
+	}()
+	return tailnet
 }
 
 func (app *App) CreateAgent() (*agent.Agent, error) {
@@ -212,44 +502,518 @@ func (app *App) CreateAgent() (*agent.Agent, error) {
 
 	agentInstance := agent.New(llmClient, nil, app.Store, app.Logger, app.PersonaManager)
 	agentInstance.SetSkillsRegistry(app.SkillsRegistry)
+	agentInstance.SetLoopOptions(agent.LoopOptionsFromConfig(app.Config.Agent.Loop))
+	agentInstance.SetJournal(app.activityJournal())
+	app.enableArtifacts(agentInstance)
+	app.enableReplyPipeline(agentInstance)
+	agentInstance.SetTitles(app.Config.Agent.Titles)
+	agentInstance.SetRouter(agent.NewRouter(app.Config.Agent.Routing))
+	agentInstance.SetPinBudget(app.Config.Agent.PinBudget)
+	app.enableOffline(llmClient, provider, agentInstance)
+	app.enableSubAgents(agentInstance)
 
 	return agentInstance, nil
 }
 
-func (app *App) RunCLI(message string) {
-	agentInstance, err := app.CreateAgent()
+// activityJournal opens the activity journal on first use. It returns nil,
+// which records nothing, if the journal cannot be opened.
+func (app *App) activityJournal() *journal.Journal {
+	if app.Journal == nil {
+		j, err := journal.New(app.Store.DB(), app.Logger)
+		if err != nil {
+			app.Logger.Warn("Failed to open activity journal", zap.Error(err))
+			return nil
+		}
+		app.Journal = j
+	}
+	return app.Journal
+}
+
+// enableArtifacts keeps the agent's long tool outputs and generated files
+// in the artifact store and lets it read them back with get_artifact
+func (app *App) enableArtifacts(agentInstance *agent.Agent) {
+	if app.Artifacts == nil {
+		st, err := artifacts.NewStore(app.Store.DB(), artifacts.DefaultDir(app.Config.Storage.DataDir))
+		if err != nil {
+			app.Logger.Warn("Failed to open artifact store", zap.Error(err))
+			return
+		}
+		app.Artifacts = st
+	}
+	agentInstance.SetArtifacts(app.Artifacts)
+	if app.SkillsRegistry == nil {
+		return
+	}
+	if err := agentInstance.EnableArtifacts(); err != nil {
+		app.Logger.Warn("Failed to enable artifacts", zap.Error(err))
+	}
+}
+
+// enableReplyPipeline runs the agent's replies through the configured
+// post-processing chain
+func (app *App) enableReplyPipeline(agentInstance *agent.Agent) {
+	pipeline, err := agent.NewReplyPipeline(app.Config.Agent.PostProcess, app.contentFilterOptions())
 	if err != nil {
-		app.Logger.Fatal("Failed to create agent", zap.Error(err))
+		app.Logger.Warn("Failed to build reply post-processing", zap.Error(err))
+		return
 	}
+	agentInstance.SetReplyPipeline(pipeline)
+}
 
-	if message != "" {
-		OneShot(agentInstance, message)
+// enableOffline sends turns to the local provider while the network is
+// down and switches off the skills that need it. It returns the monitor
+// probing the network, nil when offline mode is off.
+func (app *App) enableOffline(llmClient *llm.Client, provider config.Provider, agentInstance *agent.Agent) *connectivity.Monitor {
+	cfg := app.Config.LLM.Offline
+	if !cfg.Enabled {
+		return nil
+	}
+	local, ok := app.Config.LLM.Providers[cfg.Provider]
+	if !ok {
+		app.Logger.Warn("Offline mode needs a local provider", zap.String("provider", cfg.Provider))
+		return nil
+	}
+	url := cfg.CheckURL
+	if url == "" {
+		url = provider.BaseURL
+	}
+	monitor := connectivity.NewMonitor(url, time.Duration(cfg.CheckInterval)*time.Second, app.Logger)
+	llmClient.SetOfflineFallback(llm.NewClient(local), monitor)
+	agentInstance.SetNetworkSkills(cfg.NetworkSkills)
+	monitor.Start()
+	return monitor
+}
+
+// UseChannelPersona makes the persona the config binds to channel current
+// in this process, for processes serving only that channel such as the CLI
+func (app *App) UseChannelPersona(channel string) {
+	name := app.Config.Agent.Personas[channel]
+	if name == "" || app.PersonaManager == nil {
 		return
 	}
+	if err := app.PersonaManager.UsePersona(name); err != nil {
+		app.Logger.Warn("Failed to use channel persona",
+			zap.String("channel", channel),
+			zap.String("persona", name),
+			zap.Error(err),
+		)
+	}
+}
 
-	Interactive(agentInstance)
+// contentFilter builds a channel's output filter for profile. A channel
+// whose filter can't be built must not start unfiltered.
+func (app *App) contentFilter(profile string) (*security.ContentFilter, error) {
+	return security.NewContentFilter(profile, app.contentFilterOptions())
 }
 
-func OneShot(agentInstance *agent.Agent, msg string) {
-	fmt.Println("🤖 Myrai is thinking...")
-	fmt.Println()
+func (app *App) contentFilterOptions() security.ContentFilterOptions {
+	cfg := app.Config.Security.ContentFilter
+	return security.ContentFilterOptions{
+		WordList: cfg.WordList,
+		Block:    cfg.Block,
+		Allow:    cfg.Allow,
+	}
+}
 
-	ctx := context.Background()
-	resp, err := agentInstance.Chat(ctx, agent.ChatRequest{
-		Message: msg,
-		Stream:  false,
+// voiceReplier speaks chat replies with the voice skill when voice replies
+// are enabled; nil otherwise
+func (app *App) voiceReplier() *voice.Replier {
+	if !app.Config.Skills.Voice.Replies || app.SkillsRegistry == nil || app.Store == nil {
+		return nil
+	}
+	skill, ok := app.SkillsRegistry.GetSkill("voice")
+	if !ok {
+		return nil
+	}
+	voiceSkill, ok := skill.(*voice.VoiceSkill)
+	if !ok {
+		return nil
+	}
+	return voice.NewReplier(voiceSkill, app.Store, app.Config.Skills.Voice.MaxReplyChars)
+}
+
+// channelHealth reports on the enabled chat channels. A channel that
+// failed to start is listed as not connected; the log has why.
+func (app *App) channelHealth() []channels.Health {
+	var health []channels.Health
+	if app.Config.Channels.Telegram.Enabled {
+		if bot := app.TelegramBot; bot != nil {
+			health = append(health, bot.Health())
+		} else {
+			health = append(health, channels.Health{Name: "telegram", LastError: "not started"})
+		}
+	}
+	if app.Config.Channels.Discord.Enabled {
+		if bot := app.DiscordBot; bot != nil {
+			health = append(health, bot.Health())
+		} else {
+			health = append(health, channels.Health{Name: "discord", LastError: "not started"})
+		}
+	}
+	return health
+}
+
+// scheduleCalendarSync syncs connected Google calendars on the configured
+// interval
+func (app *App) scheduleCalendarSync(runner *cron.Runner) {
+	minutes := app.Config.Skills.Calendar.SyncIntervalMinutes
+	if minutes <= 0 || app.SkillsRegistry == nil {
+		return
+	}
+	skill, ok := app.SkillsRegistry.GetSkill("calendar")
+	if !ok {
+		return
+	}
+	calendarSkill, ok := skill.(*calendar.CalendarSkill)
+	if !ok {
+		return
+	}
+	runner.AddTask("calendar-sync", time.Duration(minutes)*time.Minute, calendarSkill.SyncEngine().SyncAll)
+}
+
+// scheduleBackups writes a backup archive on the configured interval and
+// deletes the ones past the retention count. A recent enough archive, e.g.
+// from before a restart, counts as the first run.
+func (app *App) scheduleBackups(runner *cron.Runner) {
+	cfg := &app.Config.Storage
+	hours := cfg.Backup.IntervalHours
+	if hours <= 0 {
+		return
+	}
+	interval := time.Duration(hours) * time.Hour
+	runner.AddTask("backup", interval, func(ctx context.Context) error {
+		dir := store.ArchiveDir(cfg)
+		if archives, err := store.ListArchives(dir); err == nil && len(archives) > 0 {
+			if info, err := os.Stat(archives[0]); err == nil && time.Since(info.ModTime()) < interval {
+				return nil
+			}
+		}
+
+		backup, err := store.CreateArchive(cfg, notes.Dir(app.Config.Skills.Notes.VaultDir), store.ArchivePath(cfg, time.Now()))
+		if err != nil {
+			return err
+		}
+		app.Logger.Info("Backup saved", zap.String("path", backup.Path))
+
+		removed, err := store.PruneArchives(dir, cfg.Backup.Keep)
+		for _, path := range removed {
+			app.Logger.Debug("Old backup deleted", zap.String("path", path))
+		}
+		return err
+	})
+}
+
+// retentionInterval is how often old conversations are deleted and redacted
+const retentionInterval = 6 * time.Hour
+
+// scheduleRetention deletes and redacts conversations past the configured
+// ages
+func (app *App) scheduleRetention(runner *cron.Runner) {
+	cfg := &app.Config.Privacy
+	if cfg.RetentionDays <= 0 && cfg.RedactAfterDays <= 0 {
+		return
+	}
+	runner.AddTask("retention", retentionInterval, func(ctx context.Context) error {
+		now := time.Now()
+		if cfg.RetentionDays > 0 {
+			n, err := app.Store.PurgeConversations(now.AddDate(0, 0, -cfg.RetentionDays))
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				app.Logger.Info("Deleted old conversations", zap.Int64("count", n), zap.Int("retention_days", cfg.RetentionDays))
+			}
+		}
+		if cfg.RedactAfterDays > 0 {
+			n, err := app.Store.RedactMessages(now.AddDate(0, 0, -cfg.RedactAfterDays))
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				app.Logger.Info("Redacted old messages", zap.Int64("count", n), zap.Int("redact_after_days", cfg.RedactAfterDays))
+			}
+		}
+		return nil
+	})
+}
+
+// startHomeAssistantWatcher publishes state changes of the watched Home
+// Assistant entities on the event bus. It returns nil when nothing is
+// watched or the skill isn't registered.
+func (app *App) startHomeAssistantWatcher() *homeassistant.Watcher {
+	cfg := app.Config.Skills.HomeAssistant
+	if !cfg.Enabled || len(cfg.Watch) == 0 || app.SkillsRegistry == nil {
+		return nil
+	}
+	skill, ok := app.SkillsRegistry.GetSkill("homeassistant")
+	if !ok {
+		return nil
+	}
+	haSkill, ok := skill.(*homeassistant.HomeAssistantSkill)
+	if !ok {
+		return nil
+	}
+	watcher, err := homeassistant.NewWatcher(haSkill.Client(), cfg.Watch, app.SkillsRegistry.Events(), app.Logger)
+	if err != nil {
+		app.Logger.Warn("Home Assistant state changes disabled", zap.Error(err))
+		return nil
+	}
+	watcher.Start()
+	return watcher
+}
+
+// startGitHubTriggers runs the configured prompts when GitHub webhook events
+// arrive. It returns a function that stops them.
+func (app *App) startGitHubTriggers(agentInstance *agent.Agent) func() {
+	cfg := app.Config.Skills.GitHub
+	if cfg.WebhookSecret == "" || len(cfg.Triggers) == 0 || app.SkillsRegistry == nil {
+		return func() {}
+	}
+	triggers := github.NewTriggers(cfg.Triggers, agentInstance, app.Logger)
+	if app.Notifier != nil {
+		triggers.SetNotifier(app.Notifier)
+	}
+	triggers.SetJournal(app.Journal)
+	return triggers.Subscribe(app.SkillsRegistry.Events())
+}
+
+// startNotesWatcher re-indexes notes as they change in the vault, e.g. when
+// edited in Obsidian. It returns nil when watching is off or fails to start.
+func (app *App) startNotesWatcher() *notes.Watcher {
+	if !app.Config.Skills.Notes.Watch || app.SkillsRegistry == nil {
+		return nil
+	}
+	skill, ok := app.SkillsRegistry.GetSkill("notes")
+	if !ok {
+		return nil
+	}
+	notesSkill, ok := skill.(*notes.NotesSkill)
+	if !ok {
+		return nil
+	}
+	watcher, err := notes.NewWatcher(notesSkill.Index(), app.Logger)
+	if err != nil {
+		app.Logger.Warn("Notes watcher disabled", zap.Error(err))
+		return nil
+	}
+	if err := watcher.Start(); err != nil {
+		app.Logger.Warn("Notes watcher disabled", zap.Error(err))
+		watcher.Stop()
+		return nil
+	}
+	return watcher
+}
+
+// documentRelevance is how similar a passage must be to a message to be
+// given to the model unasked
+const documentRelevance = 0.5
+
+// kbDocuments gives the context manager passages from the knowledge base,
+// from the documents the kb skill would see for the same request
+type kbDocuments struct {
+	store *kb.Store
+	scope skills.ContextHook
+}
+
+// SearchDocuments returns passages similar in meaning to query. Without
+// vector search it returns none, as word matches are too loose to add to
+// every message; query_documents still finds them.
+func (d kbDocuments) SearchDocuments(ctx context.Context, query string, limit int) ([]agent.Source, error) {
+	if !d.store.Semantic() {
+		return nil, nil
+	}
+	userID, _ := d.scope(ctx, "kb").Value("user_id").(string)
+	if userID == "" {
+		userID = household.SharedUserID
+	}
+	matches, err := d.store.Search(userID, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []agent.Source
+	for _, m := range matches {
+		if m.Score < documentRelevance {
+			continue
+		}
+		source := agent.Source{Kind: "document", Title: m.Filename, Content: m.Content, Relevance: m.Score}
+		if m.SourceURL != "" {
+			source.Kind = "web"
+			source.Title = m.Title
+			source.URL = m.SourceURL
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// startMQTT connects to the MQTT broker, offers the devices skill and runs
+// the configured trigger prompts. It returns nil if the bridge cannot be
+// created.
+func (app *App) startMQTT(agentInstance *agent.Agent) *mqtt.Bridge {
+	bus := app.SkillsRegistry.Events()
+	bridge, err := mqtt.NewBridge(app.Config.MQTT, bus, app.Logger)
+	if err != nil {
+		app.Logger.Warn("MQTT disabled", zap.Error(err))
+		return nil
+	}
+	app.SkillsRegistry.Register(devices.NewDevicesSkill(bridge))
+
+	triggers := mqtt.NewTriggers(app.Config.MQTT.Subscriptions, agentInstance, app.Logger)
+	if app.Notifier != nil {
+		triggers.SetNotifier(app.Notifier)
+	}
+	triggers.SetJournal(app.Journal)
+	triggers.Subscribe(bus)
+
+	if err := bridge.Start(); err != nil {
+		app.Logger.Warn("Failed to start MQTT", zap.Error(err))
+	}
+	return bridge
+}
+
+// locationTracker opens the location tracker on first use. It returns nil
+// when location check-ins are disabled or the tracker cannot be opened.
+func (app *App) locationTracker() *location.Tracker {
+	if app.Location == nil && app.Config.Location.Enabled {
+		tracker, err := location.NewTracker(app.Store.DB(), locationOptions(app.Config), app.Logger)
+		if err != nil {
+			app.Logger.Warn("Failed to open location tracker", zap.Error(err))
+			return nil
+		}
+		app.Location = tracker
+	}
+	return app.Location
+}
+
+func locationOptions(cfg *config.Config) location.Options {
+	return location.Options{
+		RetentionDays: cfg.Location.RetentionDays,
+		MaxPoints:     cfg.Location.MaxPoints,
+	}
+}
+
+// startEveningSummary sends each user what the assistant did that day at
+// the configured time, as the activity section of their evening briefing
+func (app *App) startEveningSummary() {
+	at := strings.TrimSpace(app.Config.Journal.EveningSummary)
+	if app.Journal == nil || app.Notifier == nil || at == "" || strings.EqualFold(at, "off") {
+		return
+	}
+	locales, err := locale.NewManager(app.Store.DB())
+	if err != nil {
+		app.Logger.Warn("Failed to load locale preferences", zap.Error(err))
+		return
+	}
+
+	err = app.Journal.StartDaily(at, func(ctx context.Context, day time.Time) {
+		users, err := app.Journal.UsersActiveOn(day)
+		if err != nil {
+			app.Logger.Warn("Failed to read activity journal", zap.Error(err))
+			return
+		}
+		for _, userID := range users {
+			section, err := app.Journal.DailySection([]string{userID}, day, locales.Get(userID))
+			if err != nil || section == "" {
+				continue
+			}
+			if _, err := app.Notifier.Send(ctx, notify.Notification{
+				UserID:   userID,
+				Category: "briefing",
+				Title:    "Evening briefing",
+				Body:     section,
+			}); err != nil {
+				app.Logger.Warn("Failed to send evening briefing",
+					zap.String("user_id", userID),
+					zap.Error(err))
+			}
+		}
 	})
+	if err != nil {
+		app.Logger.Warn("Evening summary disabled", zap.String("journal.evening_summary", at), zap.Error(err))
+	}
+}
 
+// enableSubAgents exposes the spawn_subagent tool to the agent
+func (app *App) enableSubAgents(agentInstance *agent.Agent) {
+	if app.SkillsRegistry == nil {
+		return
+	}
+	if err := agentInstance.EnableSubAgents(agent.DefaultSubAgentConfig()); err != nil {
+		app.Logger.Warn("Failed to enable sub-agents", zap.Error(err))
+	}
+}
+
+// startAsyncJobs runs the jobs the agent queues with ask_async, sending
+// each result to the user who asked for it
+func (app *App) startAsyncJobs(agentInstance *agent.Agent) *agent.AsyncQueue {
+	if app.SkillsRegistry == nil {
+		return nil
+	}
+	cfg := app.Config.Agent.Async
+	queue := agent.NewAsyncQueue(agentInstance, agent.AsyncConfig{
+		Workers:     cfg.Workers,
+		Timeout:     time.Duration(cfg.TimeoutMinutes) * time.Minute,
+		MaxAttempts: cfg.MaxAttempts,
+	})
+	if app.Notifier != nil {
+		queue.SetOnDone(func(ctx context.Context, job *store.AsyncJob) {
+			title, body := agent.FormatAsyncJobResult(job)
+			if _, err := app.Notifier.Send(ctx, notify.Notification{
+				UserID:   job.UserID,
+				Category: "jobs",
+				Title:    title,
+				Body:     body,
+			}); err != nil {
+				app.Logger.Warn("Failed to send job result", zap.String("job_id", job.ID), zap.Error(err))
+			}
+		})
+	}
+	if err := agentInstance.EnableAsyncJobs(queue); err != nil {
+		app.Logger.Warn("Failed to enable background jobs", zap.Error(err))
+		return nil
+	}
+	queue.Start()
+	return queue
+}
+
+// RunCLI answers message, or chats interactively when it's empty
+func (app *App) RunCLI(message string) {
+	app.runCLI(message, nil)
+}
+
+// ResumeCLI starts an interactive session that continues conv
+func (app *App) ResumeCLI(conv *store.Conversation) {
+	app.runCLI("", conv)
+}
+
+func (app *App) runCLI(message string, resume *store.Conversation) {
+	app.UseChannelPersona("cli")
+	agentInstance, err := app.CreateAgent()
+	if err != nil {
+		app.Logger.Fatal("Failed to create agent", zap.Error(err))
+	}
+
+	if message != "" {
+		OneShot(agentInstance, OneShotRequest{Message: message})
+		return
+	}
+
+	aliasMgr, err := aliases.NewManager(app.Store.DB())
 	if err != nil {
-		fmt.Printf("❌ Error: %v\n", err)
-		os.Exit(1)
+		app.Logger.Warn("Aliases unavailable", zap.Error(err))
 	}
 
-	fmt.Println(resp.Content)
-	fmt.Printf("\n⏱️  Response time: %v | Tokens: %d\n", resp.ResponseTime, resp.TokensUsed)
+	session := &cliSession{store: app.Store, agent: agentInstance}
+	if resume != nil {
+		session.resume(resume)
+	}
+	Interactive(agentInstance, aliasMgr, session)
 }
 
-func Interactive(agentInstance *agent.Agent) {
+// Interactive chats on the terminal, keeping to the session's conversation
+// until /new or /resume changes it
+func Interactive(agentInstance *agent.Agent, aliasMgr *aliases.Manager, session *cliSession) {
 	fmt.Println("🤖 Myrai - Interactive Mode")
 	fmt.Println("Type 'exit' or 'quit' to exit, 'help' for commands")
 	fmt.Println("Use slash commands like /skills to see available skills")
@@ -279,15 +1043,29 @@ func Interactive(agentInstance *agent.Agent) {
 			PrintInteractiveHelp()
 			continue
 		case "new", "n":
-			fmt.Println("🆕 New conversation started")
+			session.command("/new")
 			continue
 		case "clear", "cls":
 			fmt.Print("\033[H\033[2J")
 			continue
 		}
 
+		// Expand user-defined aliases before built-in slash commands
+		if strings.HasPrefix(input, "/") && aliasMgr != nil {
+			parts := strings.Fields(input[1:])
+			if len(parts) > 0 {
+				if expanded, ok, err := aliasMgr.Resolve(aliases.DefaultUser, parts[0], parts[1:]); err == nil && ok {
+					fmt.Printf("↪ %s\n", expanded)
+					input = expanded
+				}
+			}
+		}
+
 		// Handle slash commands
 		if strings.HasPrefix(input, "/") {
+			if session.command(input) {
+				continue
+			}
 			handled := handleSlashCommand(agentInstance, input)
 			if handled {
 				continue
@@ -301,8 +1079,10 @@ func Interactive(agentInstance *agent.Agent) {
 		start := time.Now()
 
 		resp, err := agentInstance.Chat(ctx, agent.ChatRequest{
-			Message: input,
-			Stream:  true,
+			ConversationID: session.conversationID,
+			Message:        input,
+			Channel:        "cli",
+			Stream:         true,
 			OnStream: func(chunk string) {
 				fmt.Print(chunk)
 				fullResponse.WriteString(chunk)
@@ -313,6 +1093,7 @@ func Interactive(agentInstance *agent.Agent) {
 			fmt.Printf("\n❌ Error: %v\n", err)
 			continue
 		}
+		session.conversationID = resp.ConversationID
 
 		fmt.Println()
 		fmt.Printf("\n⏱️  Response time: %v | Tokens: %d\n", time.Since(start), resp.TokensUsed)
@@ -396,7 +1177,13 @@ func PrintSlashCommandsHelp() {
 	fmt.Println()
 	fmt.Println("Slash Commands:")
 	fmt.Println("  /skills     - List all available skills and their tools")
+	fmt.Println("  /history    - List recent conversations")
+	fmt.Println("  /resume <n> - Continue a conversation from /history")
+	fmt.Println("  /title <t>  - Rename this conversation")
+	fmt.Println("  /model <m>  - Use the fast or smart model, or auto to route each turn")
+	fmt.Println("  /new        - Start a new conversation")
 	fmt.Println("  /help       - Show this help")
+	fmt.Println("  /<alias>    - Run a user-defined alias (see 'myrai alias list')")
 	fmt.Println()
 	PrintInteractiveHelp()
 }
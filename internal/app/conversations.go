@@ -0,0 +1,197 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+// ConversationListLimit is how many recent conversations are numbered for
+// /resume and 'myrai conversations'
+const ConversationListLimit = 20
+
+// RecentConversations lists the conversations that haven't been deleted,
+// most recent first, numbered from 1 in that order
+func RecentConversations(st *store.Store) ([]store.Conversation, error) {
+	return st.ListActiveConversations(ConversationListLimit, 0)
+}
+
+// ResolveConversation finds a conversation by its number in
+// RecentConversations or by its ID, or enough of the start of it
+func ResolveConversation(st *store.Store, ref string) (*store.Conversation, error) {
+	if n, err := strconv.Atoi(ref); err == nil {
+		convs, err := RecentConversations(st)
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 || n > len(convs) {
+			return nil, fmt.Errorf("no conversation %d; there are %d recent ones", n, len(convs))
+		}
+		return &convs[n-1], nil
+	}
+	return st.FindConversation(ref)
+}
+
+// ConversationTitle is a conversation's title, or the start of its first
+// message when it hasn't been given one and st is given
+func ConversationTitle(st *store.Store, conv *store.Conversation) string {
+	title := conv.Title
+	if (title == "" || title == agent.DefaultTitle) && st != nil {
+		if msgs, err := st.GetMessages(conv.ID, 1, 0); err == nil && len(msgs) > 0 {
+			title = msgs[0].Content
+		}
+	}
+	title = strings.Join(strings.Fields(title), " ")
+	if runes := []rune(title); len(runes) > 60 {
+		title = string(runes[:57]) + "..."
+	}
+	if title == "" {
+		return "(untitled)"
+	}
+	return title
+}
+
+// PrintConversationList prints numbered conversations, marking current
+func PrintConversationList(st *store.Store, convs []store.Conversation, current string) {
+	if len(convs) == 0 {
+		fmt.Println("📭 No conversations yet")
+		return
+	}
+	for i := range convs {
+		conv := &convs[i]
+		marker := " "
+		if conv.ID == current {
+			marker = "▶"
+		}
+		fmt.Printf("%s %2d. %s\n", marker, i+1, ConversationTitle(st, conv))
+		fmt.Printf("       %s · %d messages · %s\n", conv.UpdatedAt.Format("Jan 2, 3:04 PM"), conv.MessageCount, conv.ID)
+	}
+}
+
+// PrintConversationMessages prints the last limit messages of a
+// conversation, leaving out tool calls and their results
+func PrintConversationMessages(st *store.Store, conversationID string, limit int) error {
+	msgs, err := st.GetMessages(conversationID, -1, 0)
+	if err != nil {
+		return err
+	}
+	PrintMessages(msgs, limit)
+	return nil
+}
+
+// PrintMessages prints the last limit chat messages of msgs
+func PrintMessages(msgs []store.Message, limit int) {
+	var shown []store.Message
+	for _, msg := range msgs {
+		if (msg.Role == "user" || msg.Role == "assistant") && strings.TrimSpace(msg.Content) != "" {
+			shown = append(shown, msg)
+		}
+	}
+	if limit > 0 && len(shown) > limit {
+		fmt.Printf("   ... %d earlier messages\n\n", len(shown)-limit)
+		shown = shown[len(shown)-limit:]
+	}
+	for _, msg := range shown {
+		if msg.Role == "user" {
+			fmt.Printf("👤 You: %s\n\n", msg.Content)
+		} else {
+			fmt.Printf("🤖 Myrai: %s\n\n", msg.Content)
+		}
+	}
+}
+
+// cliSession is the conversation an interactive CLI session is in
+type cliSession struct {
+	store          *store.Store
+	agent          *agent.Agent // for /model; nil in tests
+	conversationID string
+}
+
+// command runs the conversation commands of the interactive CLI, reporting
+// whether input was one
+func (s *cliSession) command(input string) bool {
+	parts := strings.Fields(input)
+	if len(parts) == 0 {
+		return false
+	}
+	args := strings.TrimSpace(strings.TrimPrefix(input, parts[0]))
+
+	switch strings.ToLower(parts[0]) {
+	case "/new":
+		s.conversationID = ""
+		fmt.Println("🆕 New conversation started")
+
+	case "/history", "/conversations":
+		convs, err := RecentConversations(s.store)
+		if err != nil {
+			fmt.Printf("❌ Failed to list conversations: %v\n", err)
+			return true
+		}
+		fmt.Println()
+		PrintConversationList(s.store, convs, s.conversationID)
+		fmt.Println()
+		fmt.Println("Use /resume <number> to continue one.")
+
+	case "/resume":
+		if args == "" {
+			fmt.Println("Usage: /resume <number|id>  (see /history)")
+			return true
+		}
+		conv, err := ResolveConversation(s.store, args)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return true
+		}
+		s.resume(conv)
+
+	case "/title":
+		if s.conversationID == "" {
+			fmt.Println("❌ Nothing to title yet; send a message first")
+			return true
+		}
+		if args == "" {
+			fmt.Println("Usage: /title <new title>")
+			return true
+		}
+		if err := s.store.RenameConversation(s.conversationID, args); err != nil {
+			fmt.Printf("❌ Failed to rename conversation: %v\n", err)
+			return true
+		}
+		fmt.Printf("✏️  Conversation renamed to %q\n", args)
+
+	case "/model":
+		if s.agent == nil {
+			return false
+		}
+		if args == "" {
+			fmt.Println(s.agent.DescribeRoute(s.conversationID))
+			fmt.Println("Usage: /model fast|smart|auto")
+			return true
+		}
+		if s.conversationID == "" {
+			fmt.Println("❌ No conversation yet; send a message first")
+			return true
+		}
+		if err := s.agent.SetConversationRoute(s.conversationID, args); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return true
+		}
+		fmt.Printf("🔀 %s\n", s.agent.DescribeRoute(s.conversationID))
+
+	default:
+		return false
+	}
+	return true
+}
+
+// resume continues conv, showing where it left off
+func (s *cliSession) resume(conv *store.Conversation) {
+	s.conversationID = conv.ID
+	fmt.Printf("✅ Resumed: %s (%d messages)\n\n", ConversationTitle(s.store, conv), conv.MessageCount)
+	if err := PrintConversationMessages(s.store, conv.ID, 4); err != nil {
+		fmt.Printf("⚠️  Couldn't show earlier messages: %v\n", err)
+	}
+}
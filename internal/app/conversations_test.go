@@ -0,0 +1,93 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+)
+
+func TestResolveConversation(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+
+	for _, id := range []string{"conv_first", "conv_second"} {
+		if err := st.CreateConversation(&store.Conversation{ID: id}); err != nil {
+			t.Fatalf("Failed to create conversation: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	conv, err := ResolveConversation(st, "1")
+	if err != nil || conv.ID != "conv_second" {
+		t.Errorf("expected 1 to be the most recent conversation, got %v, %v", conv, err)
+	}
+	conv, err = ResolveConversation(st, "conv_f")
+	if err != nil || conv.ID != "conv_first" {
+		t.Errorf("expected conv_first by prefix, got %v, %v", conv, err)
+	}
+	if _, err := ResolveConversation(st, "3"); err == nil {
+		t.Error("expected an error for a number past the list")
+	}
+}
+
+func TestConversationTitle(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+
+	conv := &store.Conversation{ID: "conv_1", Title: "New Conversation"}
+	if err := st.CreateConversation(conv); err != nil {
+		t.Fatalf("Failed to create conversation: %v", err)
+	}
+	if got := ConversationTitle(st, conv); got != "New Conversation" {
+		t.Errorf("expected the default title without messages, got %q", got)
+	}
+
+	msg := &store.Message{ID: "msg_1", ConversationID: conv.ID, Role: "user", Content: "Plan a trip\nto Lisbon"}
+	if err := st.CreateMessage(msg); err != nil {
+		t.Fatalf("Failed to create message: %v", err)
+	}
+	if got := ConversationTitle(st, conv); got != "Plan a trip to Lisbon" {
+		t.Errorf("expected the first message as title, got %q", got)
+	}
+}
+
+func TestCLISessionCommand(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+
+	conv := &store.Conversation{ID: "conv_1", Title: "Trip"}
+	if err := st.CreateConversation(conv); err != nil {
+		t.Fatalf("Failed to create conversation: %v", err)
+	}
+
+	session := &cliSession{store: st}
+	if session.command("hello there") {
+		t.Error("plain messages aren't commands")
+	}
+	if !session.command("/title Nope") || session.conversationID != "" {
+		t.Error("/title before a conversation exists should do nothing")
+	}
+
+	if !session.command("/resume 1") {
+		t.Fatal("/resume should be handled")
+	}
+	if session.conversationID != "conv_1" {
+		t.Errorf("expected to resume conv_1, got %q", session.conversationID)
+	}
+
+	session.command("/title Lisbon in May")
+	got, err := st.GetConversation("conv_1")
+	if err != nil {
+		t.Fatalf("Failed to get conversation: %v", err)
+	}
+	if got.Title != "Lisbon in May" {
+		t.Errorf("expected the new title, got %q", got.Title)
+	}
+
+	session.command("/new")
+	if session.conversationID != "" {
+		t.Error("/new should leave the conversation")
+	}
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/skills/documents"
+	"go.uber.org/zap"
+)
+
+// MaxInlineBytes is how much piped input, or of an attached text file, is
+// put into the message itself
+const MaxInlineBytes = 100 * 1024
+
+// OneShotRequest is a single message sent with 'myrai -m'
+type OneShotRequest struct {
+	Message string   // what was asked
+	Input   string   // piped in on stdin
+	Files   []string // attached with -f
+	Output  string   // file to write the answer to instead of stdout
+}
+
+// RunOneShot answers req with a new CLI agent
+func (app *App) RunOneShot(req OneShotRequest) {
+	app.UseChannelPersona("cli")
+	agentInstance, err := app.CreateAgent()
+	if err != nil {
+		app.Logger.Fatal("Failed to create agent", zap.Error(err))
+	}
+	OneShot(agentInstance, req)
+}
+
+// OneShot answers req. Progress goes to stderr so the answer can be piped
+// on or written to req.Output as it is.
+func OneShot(agentInstance *agent.Agent, req OneShotRequest) {
+	prompt, images, err := BuildOneShotPrompt(req, agentInstance.SupportsVision())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintln(os.Stderr, "🤖 Myrai is thinking...")
+	fmt.Fprintln(os.Stderr)
+
+	resp, err := agentInstance.Chat(context.Background(), agent.ChatRequest{
+		Message: prompt,
+		Images:  images,
+		Channel: "cli",
+		Stream:  false,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if req.Output != "" {
+		if err := os.WriteFile(req.Output, []byte(resp.Content), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to write %s: %v\n", req.Output, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "📝 Answer written to %s\n", req.Output)
+	} else {
+		fmt.Println(resp.Content)
+	}
+	for _, path := range resp.Attachments {
+		fmt.Fprintf(os.Stderr, "📎 %s\n", path)
+	}
+	fmt.Fprintf(os.Stderr, "\n⏱️  Response time: %v | Tokens: %d\n", resp.ResponseTime, resp.TokensUsed)
+}
+
+// BuildOneShotPrompt puts the message, piped input and attached files into
+// one prompt. Images go to vision-capable models as they are; documents are
+// left for the documents skill to read, and other files are inlined as text.
+func BuildOneShotPrompt(req OneShotRequest, vision bool) (string, []llm.ImagePart, error) {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(req.Message))
+
+	if input := strings.TrimSpace(req.Input); input != "" {
+		if b.Len() == 0 {
+			b.WriteString(truncateInline(input))
+		} else {
+			b.WriteString("\n\nInput:\n```\n" + truncateInline(input) + "\n```")
+		}
+	}
+
+	var images []llm.ImagePart
+	for _, file := range req.Files {
+		path, err := filepath.Abs(file)
+		if err != nil {
+			return "", nil, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", nil, fmt.Errorf("can't attach %s: %w", file, err)
+		}
+		if info.IsDir() {
+			return "", nil, fmt.Errorf("can't attach %s: it's a directory", file)
+		}
+
+		tool := documents.ToolFor(path)
+		if tool == "process_image" && vision {
+			img, err := llm.ImageFromFile(path)
+			if err != nil {
+				return "", nil, fmt.Errorf("can't attach %s: %w", file, err)
+			}
+			images = append(images, img)
+			fmt.Fprintf(&b, "\n\nAttached image: %s", path)
+			continue
+		}
+		if tool != "" {
+			fmt.Fprintf(&b, "\n\nAttached file: %s\nRead it with %s.", path, tool)
+			continue
+		}
+
+		text, err := readTextFile(path)
+		if err != nil {
+			return "", nil, fmt.Errorf("can't attach %s: %w", file, err)
+		}
+		fmt.Fprintf(&b, "\n\nAttached file %s:\n```\n%s\n```", path, text)
+	}
+
+	if b.Len() == 0 {
+		return "", nil, fmt.Errorf("nothing to send; give a message with -m, pipe input or attach files with -f")
+	}
+	prompt := b.String()
+	if strings.TrimSpace(req.Message) == "" && strings.TrimSpace(req.Input) == "" {
+		prompt = "Please look at the attached files." + prompt
+	}
+	return prompt, images, nil
+}
+
+// ReadPipedInput reads what was piped in, up to MaxInlineBytes and a bit
+// more so truncateInline can tell it was cut
+func ReadPipedInput(r io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxInlineBytes+1))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// readTextFile reads an attached file that isn't a document, refusing
+// binary files the model couldn't read anyway
+func readTextFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, MaxInlineBytes+1))
+	if err != nil {
+		return "", err
+	}
+	sample := data
+	if len(sample) > MaxInlineBytes {
+		sample = sample[:MaxInlineBytes]
+	}
+	// The cut may split a character
+	valid := utf8.Valid(sample)
+	for i := 1; !valid && len(data) > MaxInlineBytes && i < utf8.UTFMax; i++ {
+		valid = utf8.Valid(sample[:len(sample)-i])
+	}
+	if bytes.IndexByte(sample, 0) >= 0 || !valid {
+		return "", fmt.Errorf("not a text file or a document Myrai can read")
+	}
+	return truncateInline(string(data)), nil
+}
+
+func truncateInline(text string) string {
+	if len(text) <= MaxInlineBytes {
+		return text
+	}
+	text = strings.ToValidUTF8(text[:MaxInlineBytes], "")
+	return text + "\n... (truncated)"
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildOneShotPrompt(t *testing.T) {
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.txt")
+	report := filepath.Join(dir, "report.pdf")
+	binary := filepath.Join(dir, "data.bin")
+	os.WriteFile(notes, []byte("buy milk"), 0644)
+	os.WriteFile(report, []byte("%PDF-1.4"), 0644)
+	os.WriteFile(binary, []byte{0x7f, 0x00, 0x01}, 0644)
+
+	prompt, images, err := BuildOneShotPrompt(OneShotRequest{
+		Message: "explain this",
+		Input:   "panic: nil map\n",
+		Files:   []string{notes, report},
+	}, false)
+	if err != nil {
+		t.Fatalf("BuildOneShotPrompt failed: %v", err)
+	}
+	if len(images) != 0 {
+		t.Errorf("expected no images, got %d", len(images))
+	}
+	for _, want := range []string{
+		"explain this",
+		"Input:\n```\npanic: nil map\n```",
+		"Attached file " + notes + ":\n```\nbuy milk\n```",
+		"Attached file: " + report + "\nRead it with process_pdf.",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+
+	prompt, _, err = BuildOneShotPrompt(OneShotRequest{Input: "what is 2+2?"}, false)
+	if err != nil || prompt != "what is 2+2?" {
+		t.Errorf("piped input alone should be the message, got %q, %v", prompt, err)
+	}
+
+	if _, _, err := BuildOneShotPrompt(OneShotRequest{Files: []string{binary}}, false); err == nil {
+		t.Error("expected binary files to be refused")
+	}
+	if _, _, err := BuildOneShotPrompt(OneShotRequest{Files: []string{filepath.Join(dir, "missing.txt")}}, false); err == nil {
+		t.Error("expected missing files to be refused")
+	}
+	if _, _, err := BuildOneShotPrompt(OneShotRequest{Input: "  \n"}, false); err == nil {
+		t.Error("expected an error with nothing to send")
+	}
+}
+
+func TestTruncateInline(t *testing.T) {
+	long := strings.Repeat("é", MaxInlineBytes)
+	got := truncateInline(long)
+	if !strings.HasSuffix(got, "... (truncated)") {
+		t.Error("expected long input to be marked as truncated")
+	}
+	if !strings.HasPrefix(got, "éé") || strings.ContainsRune(got, '�') {
+		t.Error("expected truncation to keep whole characters")
+	}
+}
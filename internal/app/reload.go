@@ -0,0 +1,346 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/cron"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/skills/system"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// The server re-reads its config file when it changes or on SIGHUP. Settings
+// that can change while running are applied at once; changes to anything
+// else are reported as needing a restart.
+
+// reloadDebounce is how long the config file must be quiet before it is
+// re-read, as editors save in several steps
+const reloadDebounce = 500 * time.Millisecond
+
+// reloadStatusFile records the last reload in the data directory, for
+// `myrai status`
+const reloadStatusFile = "config-reload.json"
+
+// ReloadStatus is the outcome of a config reload
+type ReloadStatus struct {
+	Time          time.Time `json:"time"`
+	Reloaded      []string  `json:"reloaded,omitempty"`       // settings applied
+	RestartNeeded []string  `json:"restart_needed,omitempty"` // config sections that changed but need a restart
+	Error         string    `json:"error,omitempty"`          // why the config couldn't be read; nothing was applied
+}
+
+// ReadReloadStatus returns the last reload recorded in dataDir, or nil if
+// the config hasn't been reloaded
+func ReadReloadStatus(dataDir string) (*ReloadStatus, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, reloadStatusFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var status ReloadStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("invalid reload status: %w", err)
+	}
+	return &status, nil
+}
+
+// ReloadConfig re-reads the config file and applies the settings that can
+// change while running: the log level, the Telegram allow list, inbound
+// message limits, allowed commands and their sandbox, the file access policy, provider rate limits,
+// disabled skills, cron and backup schedules, and conversation retention
+func (app *App) ReloadConfig() ReloadStatus {
+	app.reloadMu.Lock()
+	defer app.reloadMu.Unlock()
+
+	status := ReloadStatus{Time: time.Now()}
+	next, err := config.Load(app.Config.File, app.Config.Storage.DataDir)
+	if err != nil {
+		status.Error = err.Error()
+		app.Logger.Error("Failed to reload config, keeping the current one", zap.String("file", app.Config.File), zap.Error(err))
+		app.writeReloadStatus(status)
+		return status
+	}
+
+	// Secrets made up for each run would always look changed
+	for _, setting := range next.Generated {
+		switch setting {
+		case "security.jwt_secret":
+			next.Security.JWTSecret = app.Config.Security.JWTSecret
+		case "security.gateway_token":
+			next.Security.GatewayToken = app.Config.Security.GatewayToken
+		}
+	}
+
+	pending := *next
+	copyReloadable(&pending, app.Config)
+	status.RestartNeeded = changedSections(app.Config, &pending)
+
+	prev := *app.Config
+	copyReloadable(app.Config, next)
+	status.Reloaded = app.applyReloadable(&prev)
+
+	app.Logger.Info("Config reloaded",
+		zap.String("file", app.Config.File),
+		zap.Strings("reloaded", status.Reloaded),
+		zap.Strings("restart_needed", status.RestartNeeded),
+	)
+	app.writeReloadStatus(status)
+	return status
+}
+
+// copyReloadable copies the settings that can change while running from src
+// into dst, leaving dst's other settings alone
+func copyReloadable(dst, src *config.Config) {
+	dst.Server.LogLevel = src.Server.LogLevel
+	dst.Channels.Telegram.AllowList = src.Channels.Telegram.AllowList
+	dst.Channels.Telegram.Inbound = src.Channels.Telegram.Inbound
+	dst.Channels.Discord.Inbound = src.Channels.Discord.Inbound
+	dst.Tools.AllowedCmds = src.Tools.AllowedCmds
+	dst.Tools.Exec = src.Tools.Exec
+	dst.Tools.Filesystem = src.Tools.Filesystem
+	dst.Skills.Disabled = src.Skills.Disabled
+	dst.Skills.Calendar.SyncIntervalMinutes = src.Skills.Calendar.SyncIntervalMinutes
+	dst.Cron.IntervalMinutes = src.Cron.IntervalMinutes
+	dst.Cron.MaxConcurrent = src.Cron.MaxConcurrent
+	dst.Storage.Backup = src.Storage.Backup
+	dst.Privacy = src.Privacy
+
+	// The map is replaced rather than changed, as copies of dst share it
+	providers := make(map[string]config.Provider, len(dst.LLM.Providers))
+	for name, provider := range dst.LLM.Providers {
+		if p, ok := src.LLM.Providers[name]; ok {
+			provider.RateLimit = p.RateLimit
+		}
+		providers[name] = provider
+	}
+	dst.LLM.Providers = providers
+}
+
+// changedSections returns the top-level config sections that differ
+func changedSections(a, b *config.Config) []string {
+	va, vb := reflect.ValueOf(*a), reflect.ValueOf(*b)
+	var changed []string
+	for i := 0; i < va.NumField(); i++ {
+		name := va.Type().Field(i).Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// applyReloadable applies the reloadable settings in app.Config that differ
+// from prev, returning the ones that changed
+func (app *App) applyReloadable(prev *config.Config) []string {
+	cfg := app.Config
+	var reloaded []string
+
+	if cfg.Server.LogLevel != prev.Server.LogLevel {
+		if level, err := zapcore.ParseLevel(cfg.Server.LogLevel); err != nil {
+			app.Logger.Warn("Invalid log level, keeping the current one", zap.String("log_level", cfg.Server.LogLevel))
+		} else {
+			if app.Incident != nil {
+				app.Incident.SetBaseLevel(level)
+			}
+			reloaded = append(reloaded, "server.log_level")
+		}
+	}
+
+	if !reflect.DeepEqual(cfg.Channels.Telegram.AllowList, prev.Channels.Telegram.AllowList) {
+		if app.TelegramBot != nil {
+			app.TelegramBot.SetAllowList(cfg.Channels.Telegram.AllowList)
+		}
+		reloaded = append(reloaded, "channels.telegram.allow_list")
+	}
+
+	if !reflect.DeepEqual(cfg.Channels.Telegram.Inbound, prev.Channels.Telegram.Inbound) {
+		if app.TelegramBot != nil {
+			app.TelegramBot.SetInboundLimits(cfg.Channels.Telegram.Inbound)
+		}
+		reloaded = append(reloaded, "channels.telegram.inbound")
+	}
+
+	if !reflect.DeepEqual(cfg.Channels.Discord.Inbound, prev.Channels.Discord.Inbound) {
+		if app.DiscordBot != nil {
+			app.DiscordBot.SetInboundLimits(cfg.Channels.Discord.Inbound)
+		}
+		reloaded = append(reloaded, "channels.discord.inbound")
+	}
+
+	if !reflect.DeepEqual(cfg.Tools.AllowedCmds, prev.Tools.AllowedCmds) {
+		if app.SkillsRegistry != nil {
+			if skill, ok := app.SkillsRegistry.GetSkill("system"); ok {
+				skill.(*system.SystemSkill).SetAllowedCommands(cfg.Tools.AllowedCmds)
+			}
+		}
+		reloaded = append(reloaded, "tools.allowed_commands")
+	}
+
+	if !reflect.DeepEqual(cfg.Tools.Exec, prev.Tools.Exec) {
+		if app.SkillsRegistry != nil {
+			if skill, ok := app.SkillsRegistry.GetSkill("system"); ok {
+				skill.(*system.SystemSkill).SetSandbox(commandSandbox(cfg.Tools.Exec, app.Logger))
+			}
+		}
+		reloaded = append(reloaded, "tools.exec")
+	}
+
+	if !reflect.DeepEqual(cfg.Tools.Filesystem, prev.Tools.Filesystem) {
+		if app.SkillsRegistry != nil {
+			applyFilePolicy(app.SkillsRegistry, fileAccessPolicy(cfg, app.Logger))
+		}
+		reloaded = append(reloaded, "tools.filesystem")
+	}
+
+	names := make([]string, 0, len(cfg.LLM.Providers))
+	for name := range cfg.LLM.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if llm.ApplyRateLimits(cfg.LLM.Providers[name]) {
+			reloaded = append(reloaded, "llm.providers."+name+".rate_limit")
+		}
+	}
+
+	if app.SkillsRegistry != nil {
+		if changed := app.SkillsRegistry.SetDisabled(cfg.Skills.Disabled); len(changed) > 0 {
+			reloaded = append(reloaded, "skills.disabled ("+strings.Join(changed, ", ")+")")
+		}
+	}
+
+	if app.CronRunner != nil {
+		if cfg.Cron.IntervalMinutes != prev.Cron.IntervalMinutes || cfg.Cron.MaxConcurrent != prev.Cron.MaxConcurrent {
+			app.CronRunner.Reconfigure(cron.Config{
+				CheckInterval: cfg.Cron.IntervalMinutes,
+				MaxConcurrent: cfg.Cron.MaxConcurrent,
+			})
+			reloaded = append(reloaded, "cron")
+		}
+		if cfg.Skills.Calendar.SyncIntervalMinutes != prev.Skills.Calendar.SyncIntervalMinutes {
+			app.CronRunner.RemoveTask("calendar-sync")
+			app.scheduleCalendarSync(app.CronRunner)
+			reloaded = append(reloaded, "skills.calendar.sync_interval_minutes")
+		}
+		if cfg.Storage.Backup != prev.Storage.Backup {
+			app.CronRunner.RemoveTask("backup")
+			app.scheduleBackups(app.CronRunner)
+			reloaded = append(reloaded, "storage.backup")
+		}
+		if cfg.Privacy != prev.Privacy {
+			app.CronRunner.RemoveTask("retention")
+			app.scheduleRetention(app.CronRunner)
+			reloaded = append(reloaded, "privacy")
+		}
+	}
+
+	return reloaded
+}
+
+// writeReloadStatus records status for `myrai status`
+func (app *App) writeReloadStatus(status ReloadStatus) {
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(app.Config.Storage.DataDir, reloadStatusFile), data, 0644)
+	}
+	if err != nil {
+		app.Logger.Warn("Failed to record config reload", zap.Error(err))
+	}
+}
+
+// configWatcher reloads the config when its file changes or on SIGHUP
+type configWatcher struct {
+	fs      *fsnotify.Watcher
+	signals chan os.Signal
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// startConfigWatcher reloads the config whenever it changes. Without a
+// config file, or if the file can't be watched, it still reloads on SIGHUP.
+func (app *App) startConfigWatcher() *configWatcher {
+	w := &configWatcher{signals: make(chan os.Signal, 1), done: make(chan struct{})}
+	signal.Notify(w.signals, syscall.SIGHUP)
+
+	// The directory is watched, as editors often replace the file on save
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if file := app.Config.File; file != "" {
+		fs, err := fsnotify.NewWatcher()
+		if err == nil {
+			err = fs.Add(filepath.Dir(file))
+			if err != nil {
+				fs.Close()
+			}
+		}
+		if err != nil {
+			app.Logger.Warn("Not watching the config file, send SIGHUP to reload it", zap.String("file", file), zap.Error(err))
+		} else {
+			w.fs = fs
+			events, errs = fs.Events, fs.Errors
+		}
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		timer := time.NewTimer(reloadDebounce)
+		timer.Stop()
+		defer timer.Stop()
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-w.signals:
+				app.Logger.Info("Received SIGHUP, reloading config")
+				app.ReloadConfig()
+			case event, ok := <-events:
+				if !ok {
+					events = nil
+					continue
+				}
+				if filepath.Base(event.Name) == filepath.Base(app.Config.File) &&
+					event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+					timer.Reset(reloadDebounce)
+				}
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				app.Logger.Warn("Config watcher error", zap.Error(err))
+			case <-timer.C:
+				app.ReloadConfig()
+			}
+		}
+	}()
+	return w
+}
+
+// Stop stops watching the config
+func (w *configWatcher) Stop() {
+	signal.Stop(w.signals)
+	close(w.done)
+	w.wg.Wait()
+	if w.fs != nil {
+		w.fs.Close()
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"go.uber.org/zap"
+)
+
+func TestReloadConfig(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "myrai.yaml")
+	write := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	base := `
+llm:
+  default_provider: test
+  providers:
+    test:
+      api_key: key
+      base_url: https://reload.example
+server:
+  port: 8080
+`
+	write(base)
+
+	cfg, err := config.Load(file, dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.File != file {
+		t.Errorf("Expected the config file %q, got %q", file, cfg.File)
+	}
+
+	registry := skills.NewRegistry(nil)
+	skill := skills.NewBaseSkill("test", "Test tools", "1.0.0")
+	skill.AddTool(skills.Tool{Name: "lookup", Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return nil, nil
+	}})
+	registry.Register(skill)
+
+	app := New(cfg, nil, zap.NewNop(), nil, "test")
+	app.SkillsRegistry = registry
+
+	status := app.ReloadConfig()
+	if status.Error != "" || len(status.Reloaded) != 0 || len(status.RestartNeeded) != 0 {
+		t.Fatalf("Expected nothing to reload from an unchanged file, got %+v", status)
+	}
+
+	write(strings.Replace(base, "port: 8080", "port: 9090\n  log_level: warn", 1) + `
+skills:
+  disabled: [test]
+`)
+	status = app.ReloadConfig()
+	want := "server.log_level|skills.disabled (test)"
+	if got := strings.Join(status.Reloaded, "|"); got != want {
+		t.Errorf("Expected %q reloaded, got %q", want, got)
+	}
+	if len(status.RestartNeeded) != 1 || status.RestartNeeded[0] != "server" {
+		t.Errorf("Expected the port change to need a restart, got %v", status.RestartNeeded)
+	}
+	if _, ok := registry.GetTool("lookup"); ok || skill.IsEnabled() {
+		t.Error("Expected the disabled skill's tools to be withdrawn")
+	}
+	if app.Config.Server.LogLevel != "warn" || app.Config.Server.Port != 8080 {
+		t.Errorf("Expected only reloadable settings applied, got %+v", app.Config.Server)
+	}
+
+	recorded, err := ReadReloadStatus(dir)
+	if err != nil || recorded == nil {
+		t.Fatalf("Expected the reload recorded, got %v", err)
+	}
+	if strings.Join(recorded.Reloaded, "|") != want {
+		t.Errorf("Expected the recorded status to match, got %+v", recorded)
+	}
+
+	// A broken file leaves the running config alone
+	write("llm: [")
+	if status := app.ReloadConfig(); status.Error == "" {
+		t.Error("Expected an error for a broken config")
+	}
+	if _, ok := registry.GetTool("lookup"); ok {
+		t.Error("Expected the previous settings to stay in effect")
+	}
+}
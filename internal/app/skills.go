@@ -1,48 +1,141 @@
 package app
 
 import (
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/cache"
+	"github.com/gmsas95/myrai-cli/internal/codeindex"
 	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/household"
 	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/recurrence"
+	"github.com/gmsas95/myrai-cli/internal/sandbox"
+	"github.com/gmsas95/myrai-cli/internal/security"
 	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/skills/activity"
 	"github.com/gmsas95/myrai-cli/internal/skills/agentic"
 	"github.com/gmsas95/myrai-cli/internal/skills/browser"
+	"github.com/gmsas95/myrai-cli/internal/skills/calendar"
+	"github.com/gmsas95/myrai-cli/internal/skills/contacts"
 	"github.com/gmsas95/myrai-cli/internal/skills/daun"
+	"github.com/gmsas95/myrai-cli/internal/skills/declarative"
 	"github.com/gmsas95/myrai-cli/internal/skills/documents"
+	"github.com/gmsas95/myrai-cli/internal/skills/email"
+	"github.com/gmsas95/myrai-cli/internal/skills/expenses"
+	"github.com/gmsas95/myrai-cli/internal/skills/forge"
 	"github.com/gmsas95/myrai-cli/internal/skills/github"
 	"github.com/gmsas95/myrai-cli/internal/skills/health"
+	"github.com/gmsas95/myrai-cli/internal/skills/homeassistant"
+	"github.com/gmsas95/myrai-cli/internal/skills/imagegen"
 	"github.com/gmsas95/myrai-cli/internal/skills/intelligence"
+	"github.com/gmsas95/myrai-cli/internal/skills/kb"
 	"github.com/gmsas95/myrai-cli/internal/skills/notes"
+	"github.com/gmsas95/myrai-cli/internal/skills/places"
+	"github.com/gmsas95/myrai-cli/internal/skills/preferences"
+	"github.com/gmsas95/myrai-cli/internal/skills/privacy"
 	"github.com/gmsas95/myrai-cli/internal/skills/search"
 	"github.com/gmsas95/myrai-cli/internal/skills/shopping"
 	"github.com/gmsas95/myrai-cli/internal/skills/system"
+	"github.com/gmsas95/myrai-cli/internal/skills/tasks"
 	"github.com/gmsas95/myrai-cli/internal/skills/threads"
 	"github.com/gmsas95/myrai-cli/internal/skills/vision"
 	"github.com/gmsas95/myrai-cli/internal/skills/voice"
 	"github.com/gmsas95/myrai-cli/internal/skills/weather"
 	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/vector"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 func RegisterSkills(cfg *config.Config, st *store.Store, registry *skills.Registry, logger *zap.Logger, llmClient *llm.Client) {
+	// Tasks, events, medications and jobs repeating "except holidays" skip these
+	if holidays, err := recurrence.ParseDates(cfg.Skills.Holidays); err != nil {
+		logger.Warn("Ignoring holidays config", zap.Error(err))
+	} else {
+		recurrence.DefaultHolidays = holidays
+	}
+
+	// Skills announce what happened on a shared bus so others can react
+	bus := events.NewBus(logger)
+	registry.SetEventBus(bus)
+
+	// Identical external lookups within a few minutes share one API call
+	skillCache := newSkillCache(cfg, st, logger)
+	registry.SetCache(skillCache)
+
+	execPolicy := commandSandbox(cfg.Tools.Exec, logger)
 	systemSkill := system.NewSystemSkill(cfg.Tools.AllowedCmds)
+	systemSkill.SetSandbox(execPolicy)
 	registry.Register(systemSkill)
 
 	githubSkill := github.NewGitHubSkill(cfg.Skills.GitHub.Token)
+	githubSkill.SetCache(skillCache)
 	registry.Register(githubSkill)
 
-	notesSkill := notes.NewNotesSkill("")
+	// Self-hosted GitLab and Gitea servers share one set of tools
+	if len(cfg.Skills.Forges) > 0 {
+		if forgeSkill := forge.NewForgeSkill(cfg.Skills.Forges, logger); forgeSkill != nil {
+			registry.Register(forgeSkill)
+		}
+	}
+
+	// With vector search on, notes and documents are embedded and searched
+	// by meaning too
+	var searcher *vector.Searcher
+	if cfg.Vector.Enabled {
+		if s, err := vector.NewSearcher(&cfg.Vector, st, logger); err != nil {
+			logger.Warn("Semantic search of notes and documents disabled", zap.Error(err))
+		} else {
+			searcher = s
+		}
+	}
+
+	notesSkill := notes.NewNotesSkill(cfg.Skills.Notes.VaultDir)
+	if searcher != nil {
+		if err := notesSkill.EnableSemanticSearch(st.DB(), searcher); err != nil {
+			logger.Warn("Semantic note search disabled", zap.Error(err))
+		} else {
+			searcher.RegisterIndex("notes", notesSkill.Index().Reindex)
+		}
+	}
 	registry.Register(notesSkill)
 
 	weatherSkill := weather.NewWeatherSkill()
+	weatherSkill.SetCache(skillCache)
 	registry.Register(weatherSkill)
 
+	// Each browser profile keeps its cookies, so logins last across restarts
 	browserSkill := browser.NewBrowserSkill(browser.Config{
-		Enabled:  cfg.Skills.Browser.Enabled,
-		Headless: cfg.Skills.Browser.Headless,
+		Enabled:        cfg.Skills.Browser.Enabled,
+		Headless:       cfg.Skills.Browser.Headless,
+		ExecutablePath: cfg.Skills.Browser.ExecutablePath,
+		ProfilesDir:    filepath.Join(cfg.Storage.DataDir, "browser", "profiles"),
+		ScreenshotDir:  filepath.Join(cfg.Storage.DataDir, "browser", "screenshots"),
+		IdleTimeout:    time.Duration(cfg.Skills.Browser.IdleMinutes) * time.Minute,
 	})
 	registry.Register(browserSkill)
 
 	agenticSkill := agentic.NewAgenticSkill(cfg.Storage.DataDir)
+	agenticSkill.SetStore(st)
+	agenticSkill.SetSandbox(execPolicy)
+	agenticSkill.SetCodingConfig(cfg.Tools.Coding)
+	// Projects the agent looks at are indexed for repo maps and, with vector
+	// search, embedded to find code by what it does
+	var codeEmbedder codeindex.Embedder
+	if searcher != nil {
+		codeEmbedder = searcher
+	}
+	codeIndex := codeindex.NewManager(st.DB(), codeEmbedder, codeindex.Options{
+		Watch:    cfg.Tools.Coding.Index.Watch,
+		MaxFiles: cfg.Tools.Coding.Index.MaxFiles,
+	}, logger)
+	if searcher != nil {
+		searcher.RegisterIndex("code", codeIndex.Reindex)
+	}
+	agenticSkill.SetCodeIndex(codeIndex)
 	registry.Register(agenticSkill)
 
 	voiceConfig := voice.DefaultConfig()
@@ -53,42 +146,161 @@ func RegisterSkills(cfg *config.Config, st *store.Store, registry *skills.Regist
 	if googleProvider, ok := cfg.LLM.Providers["google"]; ok {
 		docsConfig.APIKey = googleProvider.APIKey
 	}
+	if len(cfg.Skills.Documents.OCRLanguages) > 0 {
+		docsConfig.OCRLanguages = cfg.Skills.Documents.OCRLanguages
+	}
+	docsConfig.OutputDir = filepath.Join(cfg.Storage.DataDir, "ocr")
 	docsSkill := documents.NewDocumentSkill(docsConfig)
 	registry.Register(docsSkill)
 
+	// Uploaded documents are kept and indexed so they can be asked about later
+	if kbStore, err := kb.NewStore(st.DB(), kb.DefaultDir(cfg.Storage.DataDir), logger); err != nil {
+		logger.Error("Failed to create knowledge base", zap.Error(err))
+	} else {
+		if searcher != nil {
+			kbStore.SetEmbedder(searcher)
+			kbStore.SetVectorStore(searcher.Vectors())
+			searcher.RegisterIndex(kb.Collection, kbStore.Reindex)
+		}
+		// Pages that need scripts to show their content are loaded in the browser
+		if cfg.Skills.Browser.Enabled {
+			kbStore.SetRenderer(browserSkill)
+		}
+		kbSkill := kb.NewKnowledgeBaseSkill(kbStore)
+		registry.Register(kbSkill)
+		// Scans read with OCR are kept with the other documents
+		docsSkill.SetArchiver(kbSkill)
+	}
+
+	// Warn when an index was built by an embedding model other than the
+	// configured one
+	if searcher != nil {
+		searcher.CheckIndexes()
+	}
+
 	shoppingSkill, err := shopping.NewShoppingSkill(st.DB(), logger)
 	if err != nil {
 		logger.Error("Failed to create shopping skill", zap.Error(err))
 	} else {
+		shoppingSkill.SetEventBus(bus)
+		// Lists can be shared with other household members
+		if members, err := household.NewManager(st.DB()); err != nil {
+			logger.Warn("Shopping list sharing disabled", zap.Error(err))
+		} else {
+			shoppingSkill.SetHousehold(members, slices.Contains(cfg.Household.Shared, "shopping"))
+		}
 		registry.Register(shoppingSkill)
 	}
 
+	expensesSkill, err := expenses.NewExpensesSkill(st.DB(), logger)
+	if err != nil {
+		logger.Error("Failed to create expenses skill", zap.Error(err))
+	} else {
+		registry.Register(expensesSkill)
+	}
+
+	if cfg.Skills.Calendar.Enabled {
+		calendarSkill, err := calendar.NewCalendarSkill(st.DB(), calendar.CalendarSkillConfig{
+			Enabled:         true,
+			GoogleClientID:  cfg.Skills.Calendar.GoogleClientID,
+			GoogleSecret:    cfg.Skills.Calendar.GoogleClientSecret,
+			DefaultTimezone: cfg.Skills.Calendar.DefaultTimezone,
+			EnableSync:      cfg.Skills.Calendar.SyncIntervalMinutes > 0,
+		}, logger)
+		if err != nil {
+			logger.Error("Failed to create calendar skill", zap.Error(err))
+		} else {
+			registry.Register(calendarSkill)
+		}
+	}
+
+	contactsSkill, err := contacts.NewContactsSkill(st.DB(), logger)
+	if err != nil {
+		logger.Error("Failed to create contacts skill", zap.Error(err))
+	} else {
+		// Birthdays go in the calendar the user's calendar skill sees
+		if calendarStore, err := calendar.NewStore(st.DB()); err != nil {
+			logger.Warn("Birthday events disabled", zap.Error(err))
+		} else {
+			contactsSkill.SetCalendar(calendarStore, household.ContextHook(cfg.Household.Shared))
+		}
+		registry.Register(contactsSkill)
+	}
+
+	activitySkill, err := activity.NewActivitySkill(st.DB())
+	if err != nil {
+		logger.Error("Failed to create activity skill", zap.Error(err))
+	} else {
+		registry.Register(activitySkill)
+	}
+
+	registry.Register(privacy.NewPrivacySkill())
+
+	// Location is opt-in twice: here, and per user with location_sharing
+	if cfg.Location.Enabled {
+		placesSkill, err := places.NewPlacesSkill(st.DB(), locationOptions(cfg))
+		if err != nil {
+			logger.Error("Failed to create places skill", zap.Error(err))
+		} else {
+			registry.Register(placesSkill)
+		}
+	}
+
+	if cfg.Skills.Email.Enabled {
+		emailSkill, err := email.NewEmailSkill(st.DB(), cfg.Skills.Email, logger)
+		if err != nil {
+			logger.Error("Failed to create email skill", zap.Error(err))
+		} else {
+			registry.Register(emailSkill)
+		}
+	}
+
+	if cfg.Skills.HomeAssistant.Enabled {
+		haSkill, err := homeassistant.NewHomeAssistantSkill(cfg.Skills.HomeAssistant)
+		if err != nil {
+			logger.Error("Failed to create Home Assistant skill", zap.Error(err))
+		} else {
+			registry.Register(haSkill)
+		}
+	}
+
+	preferencesSkill, err := preferences.NewPreferencesSkill(st.DB())
+	if err != nil {
+		logger.Error("Failed to create preferences skill", zap.Error(err))
+	} else {
+		registry.Register(preferencesSkill)
+	}
+
 	healthSkill, err := health.NewHealthSkill(st.DB(), logger)
 	if err != nil {
 		logger.Error("Failed to create health skill", zap.Error(err))
 	} else {
+		healthSkill.SetEventBus(bus)
 		registry.Register(healthSkill)
 	}
 
-	intelSkill, err := intelligence.NewIntelligenceSkill(st.DB(), logger, healthSkill, shoppingSkill)
+	intelSkill, err := intelligence.NewIntelligenceSkill(st.DB(), logger, dashboardSources(st, logger)...)
 	if err != nil {
 		logger.Error("Failed to create intelligence skill", zap.Error(err))
 	} else {
+		intelSkill.Subscribe(bus)
 		registry.Register(intelSkill)
 	}
 
 	searchSkill := search.NewSearchSkill(search.Config{
-		Enabled:     cfg.Skills.Search.Enabled,
-		Provider:    cfg.Skills.Search.Provider,
-		APIKey:      cfg.Skills.Search.APIKey,
-		MaxResults:  cfg.Skills.Search.MaxResults,
-		TimeoutSecs: cfg.Skills.Search.TimeoutSecs,
+		Enabled:           cfg.Skills.Search.Enabled,
+		Provider:          cfg.Skills.Search.Provider,
+		APIKey:            cfg.Skills.Search.APIKey,
+		MaxResults:        cfg.Skills.Search.MaxResults,
+		TimeoutSecs:       cfg.Skills.Search.TimeoutSecs,
+		RequestsPerMinute: cfg.Skills.Search.RequestsPerMinute,
 	})
 	logger.Info("Checking search skill",
 		zap.Bool("search_enabled", cfg.Skills.Search.Enabled),
 		zap.String("provider", cfg.Skills.Search.Provider),
 		zap.Bool("has_api_key", cfg.Skills.Search.APIKey != ""))
 	if searchSkill.IsEnabled() {
+		searchSkill.SetCache(skillCache)
 		registry.Register(searchSkill)
 		logger.Info("Search skill registered", zap.String("provider", cfg.Skills.Search.Provider))
 	} else {
@@ -114,6 +326,32 @@ func RegisterSkills(cfg *config.Config, st *store.Store, registry *skills.Regist
 			zap.Bool("has_llm_client", llmClient != nil))
 	}
 
+	// Images are made on request and sent over the channel the user asked on
+	if imageCfg := cfg.Skills.ImageGen; imageCfg.Enabled {
+		apiKey := imageCfg.APIKey
+		if apiKey == "" && (imageCfg.Provider == "" || imageCfg.Provider == "openai") {
+			apiKey = cfg.LLM.Providers["openai"].APIKey
+		}
+		imageSkill, err := imagegen.NewImageGenSkill(st.DB(), imagegen.Config{
+			Enabled:    imageCfg.Enabled,
+			Provider:   imageCfg.Provider,
+			APIKey:     apiKey,
+			Model:      imageCfg.Model,
+			BaseURL:    imageCfg.BaseURL,
+			Workflow:   imageCfg.Workflow,
+			DailyLimit: imageCfg.DailyLimit,
+			OutputDir:  filepath.Join(cfg.Storage.DataDir, "images"),
+		})
+		if err != nil {
+			logger.Error("Failed to create image generation skill", zap.Error(err))
+		} else if imageSkill.IsEnabled() {
+			registry.Register(imageSkill)
+		} else {
+			logger.Warn("Image generation skill NOT registered - missing API key or workflow",
+				zap.String("provider", imageCfg.Provider))
+		}
+	}
+
 	// Register Threads skill
 	threadsSkill := threads.NewThreadsSkill(threads.Config{
 		Enabled:       cfg.Skills.Threads.Enabled,
@@ -136,4 +374,119 @@ func RegisterSkills(cfg *config.Config, st *store.Store, registry *skills.Regist
 	} else {
 		logger.Warn("Daun skill NOT registered - missing API key")
 	}
+
+	// Skills installed with 'myrai skills install'
+	for _, skill := range skills.LoadPackages(SkillPackagesDir(cfg), logger) {
+		if err := registry.Register(skill.ToRegistrySkill()); err != nil {
+			logger.Warn("Installed skill not registered", zap.String("skill", skill.Manifest.Name), zap.Error(err))
+		}
+	}
+
+	// Skills defined in YAML in the workspace's skills directory
+	for _, skill := range declarative.Load(filepath.Join(cfg.Storage.DataDir, "skills"), logger) {
+		if err := registry.Register(skill); err != nil {
+			logger.Warn("Skill definition not registered", zap.String("skill", skill.Name()), zap.Error(err))
+		}
+	}
+
+	applyFilePolicy(registry, fileAccessPolicy(cfg, logger))
+}
+
+// SkillPackagesDir returns where 'myrai skills install' puts packages
+func SkillPackagesDir(cfg *config.Config) string {
+	if cfg.Skills.Packages.Dir != "" {
+		return cfg.Skills.Packages.Dir
+	}
+	return skills.DefaultPackagesDir()
+}
+
+// commandSandbox builds the policy the system skill runs commands under.
+// A policy that can't be built refuses every command rather than letting
+// them run unsandboxed.
+func commandSandbox(cfg config.ExecConfig, logger *zap.Logger) *sandbox.Policy {
+	policy, err := sandbox.New(cfg)
+	if err != nil {
+		logger.Error("Invalid tools.exec settings; commands are refused until they're fixed", zap.Error(err))
+		return sandbox.Refuse(err)
+	}
+	return policy
+}
+
+// fileAccessPolicy builds the policy the file tools work under. The config
+// file holds API keys, so it is always denied. A policy that can't be built
+// refuses every path rather than leaving the tools unrestricted.
+func fileAccessPolicy(cfg *config.Config, logger *zap.Logger) *security.FilePolicy {
+	fs := cfg.Tools.Filesystem
+	deny := slices.Clone(fs.Deny)
+	if cfg.File != "" {
+		deny = append(deny, cfg.File)
+	}
+	policy, err := security.NewFilePolicy(security.FilePolicyOptions{
+		Allow:       fs.Allow,
+		Deny:        deny,
+		ReadOnly:    fs.ReadOnly,
+		MaxFileSize: int64(fs.MaxFileSizeMB) << 20,
+	})
+	if err != nil {
+		logger.Error("Invalid tools.filesystem settings; file tools are refused until they're fixed", zap.Error(err))
+		return security.DenyAllFiles(err)
+	}
+	return policy
+}
+
+// applyFilePolicy hands policy to every skill whose tools touch files
+func applyFilePolicy(registry *skills.Registry, policy *security.FilePolicy) {
+	for _, skill := range registry.ListSkills() {
+		if s, ok := skill.(interface{ SetFilePolicy(*security.FilePolicy) }); ok {
+			s.SetFilePolicy(policy)
+		}
+	}
+}
+
+// newSkillCache creates the cache skills share, or nil when it is disabled.
+// Persisted entries live in the main database.
+func newSkillCache(cfg *config.Config, st *store.Store, logger *zap.Logger) *cache.Cache {
+	if !cfg.Skills.Cache.Enabled {
+		return nil
+	}
+	ttls := make(map[string]time.Duration, len(cfg.Skills.Cache.TTL))
+	for name, seconds := range cfg.Skills.Cache.TTL {
+		ttls[name] = time.Duration(seconds) * time.Second
+	}
+	var db *gorm.DB
+	if cfg.Skills.Cache.Persist {
+		db = st.DB()
+	}
+	c, err := cache.New(cache.Options{MaxEntries: cfg.Skills.Cache.MaxEntries, TTLs: ttls}, db, logger)
+	if err != nil {
+		logger.Warn("Skill cache unavailable", zap.Error(err))
+		return nil
+	}
+	return c
+}
+
+// dashboardSources opens the stores the life dashboard reads from. A store
+// that fails to open leaves its section of the dashboard empty.
+func dashboardSources(st *store.Store, logger *zap.Logger) []interface{} {
+	var sources []interface{}
+	add := func(name string, source interface{}, err error) {
+		if err != nil {
+			logger.Warn("Dashboard source unavailable", zap.String("source", name), zap.Error(err))
+			return
+		}
+		sources = append(sources, source)
+	}
+
+	healthStore, err := health.NewStore(st.DB())
+	add("health", healthStore, err)
+	tasksStore, err := tasks.NewStore(st.DB())
+	add("tasks", tasksStore, err)
+	shoppingStore, err := shopping.NewStore(st.DB())
+	add("shopping", shoppingStore, err)
+	expensesStore, err := expenses.NewStore(st.DB())
+	add("expenses", expensesStore, err)
+	calendarStore, err := calendar.NewStore(st.DB())
+	add("calendar", calendarStore, err)
+
+	return sources
 }
@@ -0,0 +1,302 @@
+// Package artifacts keeps what the assistant produces beyond its replies:
+// tool outputs too long to send the model whole, and generated files such as
+// charts, images and exports. Each is stored under the data directory with
+// an ID, so the model, the CLI and the API can fetch it again later.
+package artifacts
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"gorm.io/gorm"
+)
+
+// PrefixArtifact is the ID prefix for artifacts
+const PrefixArtifact = "art"
+
+// Artifact kinds
+const (
+	KindOutput = "output" // a tool result too long for the model's context
+	KindFile   = "file"   // a file a tool generated for the user
+)
+
+// ErrNotFound is returned for an unknown artifact ID
+var ErrNotFound = errors.New("artifact not found")
+
+// unsafeName matches characters not kept in stored file names
+var unsafeName = regexp.MustCompile(`[^\p{L}\p{N}._ -]+`)
+
+func init() {
+	store.RegisterMigrations("artifacts", store.Migration{
+		Version: 1,
+		Name:    "create artifacts",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Artifact{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Artifact{})
+		},
+	})
+	store.RegisterPurge("artifacts", func(db *gorm.DB, before time.Time) (int64, error) {
+		var old []Artifact
+		if err := db.Where("created_at < ?", before).Find(&old).Error; err != nil {
+			return 0, err
+		}
+		if len(old) == 0 {
+			return 0, nil
+		}
+		ids := make([]string, len(old))
+		for i, a := range old {
+			ids[i] = a.ID
+		}
+		result := db.Where("id IN ?", ids).Delete(&Artifact{})
+		if result.Error == nil {
+			for _, a := range old {
+				os.RemoveAll(filepath.Dir(a.StoragePath))
+			}
+		}
+		return result.RowsAffected, result.Error
+	})
+}
+
+// Artifact is one stored output or file
+type Artifact struct {
+	ID             string    `gorm:"primaryKey" json:"id"`
+	UserID         string    `gorm:"index" json:"user_id"`
+	ConversationID string    `gorm:"index" json:"conversation_id,omitempty"`
+	Kind           string    `gorm:"index" json:"kind"`
+	Name           string    `json:"name"`
+	MimeType       string    `json:"mime_type"`
+	SizeBytes      int64     `json:"size_bytes"`
+	Source         string    `json:"source,omitempty"` // the tool that produced it
+	StoragePath    string    `json:"-"`
+	CreatedAt      time.Time `gorm:"index" json:"created_at"`
+}
+
+// Text reports whether the artifact can be read as text
+func (a *Artifact) Text() bool {
+	return strings.HasPrefix(a.MimeType, "text/") || a.MimeType == "application/json"
+}
+
+// DefaultDir is where artifacts are kept under the data directory
+func DefaultDir(dataDir string) string {
+	return filepath.Join(dataDir, "artifacts")
+}
+
+// Store keeps artifact files under dir and their details in the database
+type Store struct {
+	db  *gorm.DB
+	dir string
+}
+
+// NewStore opens the artifact store, creating its table and folder if
+// needed
+func NewStore(db *gorm.DB, dir string) (*Store, error) {
+	if err := store.Migrate(db, "artifacts"); err != nil {
+		return nil, fmt.Errorf("failed to migrate artifacts: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create artifacts directory: %w", err)
+	}
+	return &Store{db: db, dir: dir}, nil
+}
+
+// Save stores data as a new artifact, filling in a's ID, size, path and,
+// when unset, its MIME type
+func (s *Store) Save(a *Artifact, data []byte) error {
+	if a.MimeType == "" {
+		a.MimeType = detectType(a.Name, data)
+	}
+	return s.save(a, func(dst string) (int64, error) {
+		return int64(len(data)), os.WriteFile(dst, data, 0600)
+	})
+}
+
+// SaveFile copies the file at path into the store as a new artifact. An
+// empty name takes the file's.
+func (s *Store) SaveFile(a *Artifact, path string) error {
+	if a.Name == "" {
+		a.Name = filepath.Base(path)
+	}
+	if a.MimeType == "" {
+		head := make([]byte, 512)
+		if f, err := os.Open(path); err == nil {
+			n, _ := io.ReadFull(f, head)
+			head = head[:n]
+			f.Close()
+		}
+		a.MimeType = detectType(a.Name, head)
+	}
+	return s.save(a, func(dst string) (int64, error) {
+		return copyFile(path, dst)
+	})
+}
+
+func (s *Store) save(a *Artifact, write func(dst string) (int64, error)) error {
+	a.ID = idgen.Generate(PrefixArtifact)
+	if a.Kind == "" {
+		a.Kind = KindFile
+	}
+	name := strings.TrimSpace(unsafeName.ReplaceAllString(filepath.Base(a.Name), "_"))
+	if name == "" || name == "." || name == ".." {
+		name = "artifact"
+	}
+	a.Name = name
+
+	folder := filepath.Join(s.dir, a.ID)
+	if err := os.MkdirAll(folder, 0700); err != nil {
+		return fmt.Errorf("failed to create artifact folder: %w", err)
+	}
+	a.StoragePath = filepath.Join(folder, name)
+	size, err := write(a.StoragePath)
+	if err != nil {
+		os.RemoveAll(folder)
+		return fmt.Errorf("failed to store artifact: %w", err)
+	}
+	a.SizeBytes = size
+
+	if err := s.db.Create(a).Error; err != nil {
+		os.RemoveAll(folder)
+		return fmt.Errorf("failed to save artifact: %w", err)
+	}
+	return nil
+}
+
+// Get returns the artifact with id
+func (s *Store) Get(id string) (*Artifact, error) {
+	var a Artifact
+	if err := s.db.First(&a, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Lookup returns the artifact stored at path, for files already in the
+// store that are attached again
+func (s *Store) Lookup(path string) (*Artifact, bool) {
+	rel, err := filepath.Rel(s.dir, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return nil, false
+	}
+	a, err := s.Get(strings.SplitN(filepath.ToSlash(rel), "/", 2)[0])
+	if err != nil || a.StoragePath != filepath.Clean(path) {
+		return nil, false
+	}
+	return a, true
+}
+
+// Filter selects artifacts to list
+type Filter struct {
+	UserID         string // empty for everyone
+	ConversationID string // empty for every conversation
+	Kind           string // empty for every kind
+	Limit          int    // zero for no limit
+}
+
+// List returns matching artifacts, newest first
+func (s *Store) List(f Filter) ([]Artifact, error) {
+	query := s.db.Order("created_at DESC")
+	if f.UserID != "" {
+		query = query.Where("user_id = ?", f.UserID)
+	}
+	if f.ConversationID != "" {
+		query = query.Where("conversation_id = ?", f.ConversationID)
+	}
+	if f.Kind != "" {
+		query = query.Where("kind = ?", f.Kind)
+	}
+	if f.Limit > 0 {
+		query = query.Limit(f.Limit)
+	}
+	var list []Artifact
+	err := query.Find(&list).Error
+	return list, err
+}
+
+// Read returns up to limit bytes of a text artifact from offset, and
+// whether more follows. Reads end on a character boundary.
+func (s *Store) Read(a *Artifact, offset, limit int64) (string, bool, error) {
+	if !a.Text() {
+		return "", false, fmt.Errorf("%s is %s, not text; download it instead", a.ID, a.MimeType)
+	}
+	f, err := os.Open(a.StoragePath)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+	if offset < 0 || offset > a.SizeBytes {
+		return "", false, fmt.Errorf("offset %d is outside the artifact's %d bytes", offset, a.SizeBytes)
+	}
+	buf := make([]byte, limit)
+	n, err := f.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return "", false, err
+	}
+	buf = buf[:n]
+	more := offset+int64(n) < a.SizeBytes
+	if more && n > 0 {
+		// Leave a split character for the next read
+		i := n - 1
+		for i > 0 && !utf8.RuneStart(buf[i]) {
+			i--
+		}
+		if !utf8.FullRune(buf[i:]) {
+			buf = buf[:i]
+		}
+	}
+	return string(buf), more, nil
+}
+
+// Delete removes an artifact and its file
+func (s *Store) Delete(id string) error {
+	a, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(a).Error; err != nil {
+		return err
+	}
+	os.RemoveAll(filepath.Dir(a.StoragePath))
+	return nil
+}
+
+// detectType guesses a MIME type from the name's extension, falling back to
+// sniffing the content
+func detectType(name string, data []byte) string {
+	if t := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); t != "" {
+		return t
+	}
+	return http.DetectContentType(data)
+}
+
+func copyFile(src, dst string) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(out, in)
+	if err != nil {
+		out.Close()
+		return n, err
+	}
+	return n, out.Close()
+}
@@ -0,0 +1,130 @@
+package artifacts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func newTestStore(t *testing.T) (*Store, *gorm.DB) {
+	st := testutil.NewTestStore(t)
+	t.Cleanup(func() { st.Close() })
+	arts, err := NewStore(st.DB(), filepath.Join(t.TempDir(), "artifacts"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	return arts, st.DB()
+}
+
+func TestStore_SaveAndRead(t *testing.T) {
+	arts, _ := newTestStore(t)
+
+	// "é" is two bytes, so a 5-byte read would split one
+	content := "abcé" + strings.Repeat("x", 10)
+	a := &Artifact{UserID: "u1", ConversationID: "conv_1", Kind: KindOutput, Name: "../web search.txt", Source: "web_search"}
+	if err := arts.Save(a, []byte(content)); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if !strings.HasPrefix(a.ID, PrefixArtifact+"_") || a.Name != "web search.txt" || a.SizeBytes != int64(len(content)) {
+		t.Errorf("Unexpected artifact: %+v", a)
+	}
+	if !a.Text() {
+		t.Errorf("Expected %s to be text", a.MimeType)
+	}
+
+	got, err := arts.Get(a.ID)
+	if err != nil || got.StoragePath != a.StoragePath || got.Source != "web_search" {
+		t.Fatalf("Get = %+v, %v", got, err)
+	}
+
+	text, more, err := arts.Read(got, 0, 4)
+	if err != nil || text != "abc" || !more {
+		t.Errorf("Read(0, 4) = %q, %t, %v; want the split character left out", text, more, err)
+	}
+	text, more, err = arts.Read(got, 3, 100)
+	if err != nil || text != content[3:] || more {
+		t.Errorf("Read(3, 100) = %q, %t, %v", text, more, err)
+	}
+	if _, _, err := arts.Read(got, 1000, 10); err == nil {
+		t.Error("Expected an offset past the end to be refused")
+	}
+}
+
+func TestStore_SaveFileAndLookup(t *testing.T) {
+	arts, _ := newTestStore(t)
+
+	src := filepath.Join(t.TempDir(), "chart.png")
+	png := []byte("\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 16))
+	if err := os.WriteFile(src, png, 0644); err != nil {
+		t.Fatal(err)
+	}
+	a := &Artifact{UserID: "u1"}
+	if err := arts.SaveFile(a, src); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+	if a.Name != "chart.png" || a.MimeType != "image/png" || a.Kind != KindFile || a.SizeBytes != int64(len(png)) {
+		t.Errorf("Unexpected artifact: %+v", a)
+	}
+	if data, _ := os.ReadFile(a.StoragePath); string(data) != string(png) {
+		t.Error("Expected the file to be copied into the store")
+	}
+	if _, _, err := arts.Read(a, 0, 10); err == nil {
+		t.Error("Expected reading an image as text to fail")
+	}
+
+	if found, ok := arts.Lookup(a.StoragePath); !ok || found.ID != a.ID {
+		t.Errorf("Lookup of the stored copy = %+v, %t", found, ok)
+	}
+	if _, ok := arts.Lookup(src); ok {
+		t.Error("Expected a file outside the store not to be found")
+	}
+
+	list, err := arts.List(Filter{UserID: "u1", Kind: KindFile})
+	if err != nil || len(list) != 1 {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+	if list, _ := arts.List(Filter{UserID: "u2"}); len(list) != 0 {
+		t.Errorf("Expected no artifacts for u2, got %d", len(list))
+	}
+
+	if err := arts.Delete(a.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := arts.Get(a.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if _, err := os.Stat(a.StoragePath); !os.IsNotExist(err) {
+		t.Error("Expected the file to be removed")
+	}
+}
+
+func TestPurge(t *testing.T) {
+	arts, db := newTestStore(t)
+
+	old := &Artifact{Name: "old.txt"}
+	recent := &Artifact{Name: "recent.txt"}
+	for _, a := range []*Artifact{old, recent} {
+		if err := arts.Save(a, []byte("data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Model(&Artifact{}).Where("id = ?", old.ID).Update("created_at", time.Now().AddDate(0, 0, -40))
+
+	n, err := store.Purge(db, "artifacts", time.Now().AddDate(0, 0, -30))
+	if err != nil || n != 1 {
+		t.Fatalf("Purge = %d, %v; want 1", n, err)
+	}
+	if _, err := os.Stat(old.StoragePath); !os.IsNotExist(err) {
+		t.Error("Expected the purged artifact's file to be removed")
+	}
+	if _, err := arts.Get(recent.ID); err != nil {
+		t.Errorf("Expected the recent artifact to be kept: %v", err)
+	}
+}
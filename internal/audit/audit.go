@@ -0,0 +1,82 @@
+// Package audit keeps a record of actions Myrai took on the user's machine,
+// such as shell commands run with 'myrai do', so they can be reviewed later.
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"gorm.io/gorm"
+)
+
+// PrefixEntry is the ID prefix for audit entries
+const PrefixEntry = "audit"
+
+func init() {
+	store.RegisterMigrations("audit", store.Migration{
+		Version: 1,
+		Name:    "create audit log",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Entry{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Entry{})
+		},
+	})
+	store.RegisterPurge("audit", func(db *gorm.DB, before time.Time) (int64, error) {
+		result := db.Where("created_at < ?", before).Delete(&Entry{})
+		return result.RowsAffected, result.Error
+	})
+}
+
+// Entry is one recorded action
+type Entry struct {
+	ID        string    `gorm:"primaryKey" json:"id"`
+	Source    string    `gorm:"index" json:"source"` // what took the action, e.g. "cli_do"
+	Action    string    `gorm:"index" json:"action"` // e.g. "shell_command"
+	Request   string    `gorm:"type:text" json:"request,omitempty"`
+	Detail    string    `gorm:"type:text" json:"detail"` // the command itself
+	Directory string    `json:"directory,omitempty"`
+	ExitCode  int       `json:"exit_code"`
+	Error     string    `json:"error,omitempty"`
+	Duration  int64     `json:"duration_ms"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// Log records and lists audit entries
+type Log struct {
+	db *gorm.DB
+}
+
+// NewLog opens the audit log, creating its table if needed
+func NewLog(db *gorm.DB) (*Log, error) {
+	if err := store.Migrate(db, "audit"); err != nil {
+		return nil, fmt.Errorf("failed to migrate audit log: %w", err)
+	}
+	return &Log{db: db}, nil
+}
+
+// Record saves entry, filling in its ID and time
+func (l *Log) Record(entry *Entry) error {
+	if entry.ID == "" {
+		entry.ID = idgen.Generate(PrefixEntry)
+	}
+	return l.db.Create(entry).Error
+}
+
+// Recent lists the latest entries, newest first. An empty source lists
+// entries from every source.
+func (l *Log) Recent(source string, limit int) ([]Entry, error) {
+	query := l.db.Order("created_at DESC")
+	if source != "" {
+		query = query.Where("source = ?", source)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var entries []Entry
+	err := query.Find(&entries).Error
+	return entries, err
+}
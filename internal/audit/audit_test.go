@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+)
+
+func TestLog(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+
+	log, err := NewLog(st.DB())
+	if err != nil {
+		t.Fatalf("NewLog failed: %v", err)
+	}
+
+	for _, entry := range []*Entry{
+		{Source: "cli_do", Action: "shell_command", Detail: "ls"},
+		{Source: "other", Action: "shell_command", Detail: "pwd"},
+		{Source: "cli_do", Action: "shell_command", Detail: "df -h", ExitCode: 1},
+	} {
+		if err := log.Record(entry); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		if entry.ID == "" {
+			t.Error("expected an ID to be generated")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	entries, err := log.Recent("cli_do", 10)
+	if err != nil {
+		t.Fatalf("Recent failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Detail != "df -h" || entries[1].Detail != "ls" {
+		t.Errorf("expected the cli_do entries newest first, got %+v", entries)
+	}
+
+	if all, _ := log.Recent("", 0); len(all) != 3 {
+		t.Errorf("expected 3 entries from every source, got %d", len(all))
+	}
+
+	deleted, err := store.Purge(st.DB(), "audit", time.Now().Add(time.Hour))
+	if err != nil || deleted != 3 {
+		t.Errorf("expected purging to delete 3 entries, got %d, %v", deleted, err)
+	}
+}
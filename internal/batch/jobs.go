@@ -0,0 +1,394 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PrefixJob is the ID prefix for batch jobs
+const PrefixJob = "batch"
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobDone      = "done"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// ErrJobNotFound is returned for an unknown job ID
+var ErrJobNotFound = errors.New("batch job not found")
+
+// ErrJobFinished is returned when cancelling a job that already ended
+var ErrJobFinished = errors.New("batch job already finished")
+
+func init() {
+	store.RegisterMigrations("batch", store.Migration{
+		Version: 1,
+		Name:    "create batch jobs",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Job{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Job{})
+		},
+	})
+	store.RegisterPurge("batch", func(db *gorm.DB, before time.Time) (int64, error) {
+		result := db.Where("created_at < ? AND status IN ?", before,
+			[]string{JobDone, JobFailed, JobCancelled}).Delete(&Job{})
+		return result.RowsAffected, result.Error
+	})
+}
+
+// Job is a batch submitted to the gateway and processed in the background
+type Job struct {
+	ID         string     `gorm:"primaryKey" json:"id"`
+	Status     string     `gorm:"index" json:"status"`
+	Source     string     `json:"source"` // name of the submitted input
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Succeeded  int        `json:"succeeded"`
+	Failed     int        `json:"failed"`
+	Skipped    int        `json:"skipped"`
+	TokensUsed int        `json:"tokens_used"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `gorm:"index" json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// TableName keeps batch jobs apart from scheduled jobs
+func (Job) TableName() string {
+	return "batch_jobs"
+}
+
+// Finished reports whether the job has stopped for good
+func (j *Job) Finished() bool {
+	return j.Status == JobDone || j.Status == JobFailed || j.Status == JobCancelled
+}
+
+// Jobs queues submitted batches and runs them one at a time, keeping their
+// input and results under dir
+type Jobs struct {
+	db     *gorm.DB
+	agent  agent.Chatter
+	dir    string
+	config Config
+	logger *zap.Logger
+
+	wake    chan struct{}
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+// NewJobs opens the job queue, creating its table if needed
+func NewJobs(db *gorm.DB, ag agent.Chatter, dir string, logger *zap.Logger) (*Jobs, error) {
+	if err := store.Migrate(db, "batch"); err != nil {
+		return nil, fmt.Errorf("failed to migrate batch jobs: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	cfg := DefaultConfig()
+	cfg.SkipInvalid = false
+	return &Jobs{
+		db:      db,
+		agent:   ag,
+		dir:     dir,
+		config:  cfg,
+		logger:  logger,
+		wake:    make(chan struct{}, 1),
+		cancels: make(map[string]context.CancelFunc),
+	}, nil
+}
+
+// SetConfig changes how later jobs are processed
+func (j *Jobs) SetConfig(cfg Config) {
+	j.config = cfg
+}
+
+// Start runs queued jobs until ctx is done. Jobs a restart interrupted are
+// queued again and start over.
+func (j *Jobs) Start(ctx context.Context) {
+	if err := j.db.Model(&Job{}).Where("status = ?", JobRunning).Updates(map[string]interface{}{
+		"status": JobQueued, "processed": 0, "succeeded": 0, "failed": 0, "skipped": 0, "tokens_used": 0,
+	}).Error; err != nil {
+		j.logger.Warn("Failed to requeue interrupted batch jobs", zap.Error(err))
+	}
+	j.notify()
+	go j.run(ctx)
+}
+
+// Submit stores input and queues it. name is the input's file name, whose
+// extension picks the format as for 'myrai batch': JSON lines for .json and
+// .jsonl, otherwise one message per line.
+func (j *Jobs) Submit(name string, input []byte) (*Job, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext != ".json" && ext != ".jsonl" {
+		ext = ".txt"
+	}
+
+	job := &Job{ID: idgen.Generate(PrefixJob), Status: JobQueued, Source: filepath.Base(name)}
+	jobDir := filepath.Join(j.dir, job.ID)
+	if err := os.MkdirAll(jobDir, 0700); err != nil {
+		return nil, err
+	}
+	inputPath := filepath.Join(jobDir, "input"+ext)
+	if err := os.WriteFile(inputPath, input, 0600); err != nil {
+		os.RemoveAll(jobDir)
+		return nil, err
+	}
+
+	items, err := NewProcessor(j.agent, j.config, j.logger).loadInputFile(inputPath)
+	if err == nil && len(items) == 0 {
+		err = fmt.Errorf("the input has no messages")
+	}
+	if err != nil {
+		os.RemoveAll(jobDir)
+		return nil, err
+	}
+	job.Total = len(items)
+
+	if err := j.db.Create(job).Error; err != nil {
+		os.RemoveAll(jobDir)
+		return nil, err
+	}
+	j.notify()
+	return job, nil
+}
+
+// Get returns the job with id
+func (j *Jobs) Get(id string) (*Job, error) {
+	var job Job
+	if err := j.db.First(&job, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// List returns the latest jobs, newest first
+func (j *Jobs) List(limit int) ([]Job, error) {
+	query := j.db.Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var jobs []Job
+	err := query.Find(&jobs).Error
+	return jobs, err
+}
+
+// Cancel stops a queued or running job. Items already answered keep their
+// results.
+func (j *Jobs) Cancel(id string) (*Job, error) {
+	job, err := j.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Finished() {
+		return job, ErrJobFinished
+	}
+
+	j.mu.Lock()
+	cancel, running := j.cancels[id]
+	j.mu.Unlock()
+	if running {
+		// The runner records the cancellation when the batch stops
+		cancel()
+		return job, nil
+	}
+
+	now := time.Now()
+	result := j.db.Model(&Job{}).Where("id = ? AND status = ?", id, JobQueued).
+		Updates(map[string]interface{}{"status": JobCancelled, "finished_at": now})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		// It started in the meantime
+		return j.Cancel(id)
+	}
+	return j.Get(id)
+}
+
+// Results reads the items the job has answered so far
+func (j *Jobs) Results(job *Job) (*Result, error) {
+	result := &Result{Total: job.Total}
+	if job.StartedAt != nil {
+		result.StartTime = *job.StartedAt
+	}
+	if job.FinishedAt != nil {
+		result.EndTime = *job.FinishedAt
+		result.Duration = result.EndTime.Sub(result.StartTime)
+	}
+
+	file, err := os.Open(j.resultsPath(job.ID))
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var item OutputItem
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			// A running job may be halfway through writing the last line
+			break
+		}
+		result.Items = append(result.Items, item)
+		switch {
+		case item.Success:
+			result.Success++
+		case item.Error == "skipped" || item.Error == "cancelled":
+			result.Skipped++
+		default:
+			result.Failed++
+		}
+	}
+	return result, scanner.Err()
+}
+
+func (j *Jobs) resultsPath(id string) string {
+	return filepath.Join(j.dir, id, "results.jsonl")
+}
+
+func (j *Jobs) notify() {
+	select {
+	case j.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (j *Jobs) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-j.wake:
+		}
+
+		for ctx.Err() == nil {
+			var job Job
+			err := j.db.Where("status = ?", JobQueued).Order("created_at ASC").First(&job).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				break
+			}
+			if err != nil {
+				j.logger.Error("Failed to load queued batch job", zap.Error(err))
+				break
+			}
+			j.runJob(ctx, &job)
+		}
+	}
+}
+
+// runJob processes one job, appending each answer to its results file as
+// it arrives and keeping the counts up to date
+func (j *Jobs) runJob(ctx context.Context, job *Job) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	j.mu.Lock()
+	j.cancels[job.ID] = cancel
+	j.mu.Unlock()
+	defer func() {
+		j.mu.Lock()
+		delete(j.cancels, job.ID)
+		j.mu.Unlock()
+	}()
+
+	// Only start it if it wasn't cancelled since it was picked
+	now := time.Now()
+	result := j.db.Model(&Job{}).Where("id = ? AND status = ?", job.ID, JobQueued).
+		Updates(map[string]interface{}{"status": JobRunning, "started_at": now})
+	if result.Error != nil {
+		j.logger.Error("Failed to start batch job", zap.String("id", job.ID), zap.Error(result.Error))
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+	job.Status, job.StartedAt = JobRunning, &now
+	j.logger.Info("Batch job started", zap.String("id", job.ID), zap.Int("items", job.Total))
+
+	err := j.process(jobCtx, job)
+
+	switch {
+	case ctx.Err() != nil:
+		// Shutting down: Start queues it again next time
+		return
+	case jobCtx.Err() != nil:
+		job.Status = JobCancelled
+	case err != nil:
+		job.Status, job.Error = JobFailed, err.Error()
+	default:
+		job.Status = JobDone
+	}
+	finished := time.Now()
+	job.FinishedAt = &finished
+	if err := j.db.Save(job).Error; err != nil {
+		j.logger.Error("Failed to record batch job", zap.String("id", job.ID), zap.Error(err))
+	}
+	j.logger.Info("Batch job finished",
+		zap.String("id", job.ID),
+		zap.String("status", job.Status),
+		zap.Int("succeeded", job.Succeeded),
+		zap.Int("failed", job.Failed),
+	)
+}
+
+func (j *Jobs) process(ctx context.Context, job *Job) error {
+	matches, _ := filepath.Glob(filepath.Join(j.dir, job.ID, "input.*"))
+	if len(matches) == 0 {
+		return fmt.Errorf("the job's input is missing")
+	}
+	out, err := os.Create(j.resultsPath(job.ID))
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	encoder := json.NewEncoder(out)
+
+	processor := NewProcessor(j.agent, j.config, j.logger)
+	processor.SetOnResult(func(item OutputItem) {
+		if err := encoder.Encode(item); err != nil {
+			j.logger.Warn("Failed to write batch result", zap.String("id", job.ID), zap.Error(err))
+		}
+		job.Processed++
+		job.TokensUsed += item.TokensUsed
+		switch {
+		case item.Success:
+			job.Succeeded++
+		case item.Error == "skipped" || item.Error == "cancelled":
+			job.Skipped++
+		default:
+			job.Failed++
+		}
+		j.db.Model(&Job{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"processed": job.Processed, "succeeded": job.Succeeded, "failed": job.Failed,
+			"skipped": job.Skipped, "tokens_used": job.TokensUsed,
+		})
+	})
+	_, err = processor.ProcessFile(ctx, matches[0], "")
+	return err
+}
@@ -0,0 +1,193 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"go.uber.org/zap"
+)
+
+// echoAgent answers each message with itself, or blocks until cancelled
+type echoAgent struct {
+	block bool
+}
+
+func (a *echoAgent) Chat(ctx context.Context, req agent.ChatRequest) (*agent.ChatResponse, error) {
+	if a.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &agent.ChatResponse{Content: "echo: " + req.Message, TokensUsed: 3}, nil
+}
+
+func newTestJobs(t *testing.T, ag agent.Chatter) *Jobs {
+	st := testutil.NewTestStore(t)
+	t.Cleanup(func() { st.Close() })
+	jobs, err := NewJobs(st.DB(), ag, filepath.Join(t.TempDir(), "batch"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewJobs failed: %v", err)
+	}
+	cfg := jobs.config
+	cfg.RetryCount, cfg.RetryDelay, cfg.ValidateInput = 0, 0, false
+	jobs.SetConfig(cfg)
+	return jobs
+}
+
+func waitForStatus(t *testing.T, jobs *Jobs, id, status string) *Job {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := jobs.Get(id)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected status %s, got %s", status, job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJobs_SubmitAndRun(t *testing.T) {
+	jobs := newTestJobs(t, &echoAgent{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobs.Start(ctx)
+
+	input := `{"id":"a","message":"first"}
+{"id":"b","message":"second"}
+`
+	job, err := jobs.Submit("items.jsonl", []byte(input))
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if job.Total != 2 || job.Status != JobQueued {
+		t.Errorf("Expected a queued job of 2 items, got %+v", job)
+	}
+
+	job = waitForStatus(t, jobs, job.ID, JobDone)
+	if job.Processed != 2 || job.Succeeded != 2 || job.TokensUsed != 6 {
+		t.Errorf("Expected 2 answered items using 6 tokens, got %+v", job)
+	}
+	if job.FinishedAt == nil {
+		t.Error("Expected the finish time to be recorded")
+	}
+
+	result, err := jobs.Results(job)
+	if err != nil {
+		t.Fatalf("Results failed: %v", err)
+	}
+	if len(result.Items) != 2 || result.Success != 2 {
+		t.Fatalf("Expected 2 successful results, got %+v", result)
+	}
+	answers := map[string]string{}
+	for _, item := range result.Items {
+		answers[item.ID] = item.Response
+	}
+	if answers["a"] != "echo: first" || answers["b"] != "echo: second" {
+		t.Errorf("Unexpected results: %v", answers)
+	}
+}
+
+func TestJobs_SubmitText(t *testing.T) {
+	jobs := newTestJobs(t, &echoAgent{})
+	job, err := jobs.Submit("questions.txt", []byte("one\n\n# comment\ntwo\nthree\n"))
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if job.Total != 3 || job.Source != "questions.txt" {
+		t.Errorf("Expected 3 items from questions.txt, got %+v", job)
+	}
+}
+
+func TestJobs_SubmitRejectsBadInput(t *testing.T) {
+	jobs := newTestJobs(t, &echoAgent{})
+	for name, input := range map[string]string{
+		"empty.jsonl":   "",
+		"invalid.jsonl": `{"id":"a","message":`,
+		"blank.txt":     "\n# nothing here\n",
+	} {
+		if _, err := jobs.Submit(name, []byte(input)); err == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+	list, err := jobs.List(0)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(list) != 0 {
+		t.Errorf("Expected no jobs, got %d", len(list))
+	}
+}
+
+func TestJobs_CancelQueued(t *testing.T) {
+	jobs := newTestJobs(t, &echoAgent{})
+	job, err := jobs.Submit("items.txt", []byte("hello\n"))
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+
+	job, err = jobs.Cancel(job.ID)
+	if err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if job.Status != JobCancelled {
+		t.Errorf("Expected cancelled, got %s", job.Status)
+	}
+	if _, err := jobs.Cancel(job.ID); err != ErrJobFinished {
+		t.Errorf("Expected ErrJobFinished, got %v", err)
+	}
+	if _, err := jobs.Cancel("batch_missing"); err != ErrJobNotFound {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestJobs_CancelRunning(t *testing.T) {
+	jobs := newTestJobs(t, &echoAgent{block: true})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobs.Start(ctx)
+
+	var input string
+	for i := 0; i < 10; i++ {
+		input += fmt.Sprintf("message %d\n", i)
+	}
+	job, err := jobs.Submit("items.txt", []byte(input))
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	waitForStatus(t, jobs, job.ID, JobRunning)
+
+	if _, err := jobs.Cancel(job.ID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	job = waitForStatus(t, jobs, job.ID, JobCancelled)
+	if job.Processed != 10 || job.Succeeded != 0 {
+		t.Errorf("Expected every item passed over, got %+v", job)
+	}
+}
+
+func TestJobs_StartRequeuesInterrupted(t *testing.T) {
+	jobs := newTestJobs(t, &echoAgent{})
+	job, err := jobs.Submit("items.txt", []byte("hello\n"))
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	jobs.db.Model(&Job{}).Where("id = ?", job.ID).Updates(map[string]interface{}{"status": JobRunning, "processed": 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobs.Start(ctx)
+
+	job = waitForStatus(t, jobs, job.ID, JobDone)
+	if job.Processed != 1 || job.Succeeded != 1 {
+		t.Errorf("Expected the job to run again from the start, got %+v", job)
+	}
+}
@@ -0,0 +1,161 @@
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Output formats supported by the batch processor
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+)
+
+// ValidOutputFormat reports whether format is a supported output format
+func ValidOutputFormat(format string) bool {
+	switch format {
+	case FormatText, FormatJSON, FormatJSONL, FormatCSV:
+		return true
+	}
+	return false
+}
+
+// ResolveOutputFormat picks the explicit format if set, otherwise infers it
+// from the output file extension.
+func ResolveOutputFormat(format, path string) string {
+	if format != "" {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	case ".csv":
+		return FormatCSV
+	}
+	return FormatText
+}
+
+// WriteResults writes result in format, which defaults to text. schema, if
+// set, gives CSV output a column per property.
+func WriteResults(w io.Writer, result *Result, format string, schema *Schema) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, result)
+	case FormatJSONL:
+		return writeJSONL(w, result)
+	case FormatCSV:
+		return writeCSV(w, result, schema)
+	default:
+		return writeText(w, result)
+	}
+}
+
+func writeJSON(w io.Writer, result *Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// writeJSONL writes one OutputItem per line so results can be streamed into
+// data pipelines without loading the whole file.
+func writeJSONL(w io.Writer, result *Result) error {
+	encoder := json.NewEncoder(w)
+	for _, item := range result.Items {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes one row per item. When a schema with top-level properties is
+// configured, each property becomes its own column.
+func writeCSV(w io.Writer, result *Result, schema *Schema) error {
+	columns := schema.Columns()
+
+	writer := csv.NewWriter(w)
+	header := []string{"id", "input", "response", "success", "error", "tokens_used", "response_time_ms", "timestamp"}
+	header = append(header, columns...)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, item := range result.Items {
+		row := []string{
+			item.ID,
+			item.Input,
+			item.Response,
+			strconv.FormatBool(item.Success),
+			item.Error,
+			strconv.Itoa(item.TokensUsed),
+			strconv.FormatInt(item.ResponseTime.Milliseconds(), 10),
+			item.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
+		}
+
+		if len(columns) > 0 {
+			var fields map[string]interface{}
+			if len(item.Structured) > 0 {
+				// Output that isn't an object leaves the columns empty;
+				// say why in the error column
+				if err := json.Unmarshal(item.Structured, &fields); err != nil {
+					msg := fmt.Sprintf("failed to parse structured output: %v", err)
+					if row[4] != "" {
+						msg = row[4] + "; " + msg
+					}
+					row[4] = msg
+				}
+			}
+			for _, col := range columns {
+				row = append(row, csvValue(fields[col]))
+			}
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(data)
+	}
+}
+
+func writeText(w io.Writer, result *Result) error {
+	for _, item := range result.Items {
+		fmt.Fprintf(w, "=== %s ===\n", item.ID)
+		fmt.Fprintf(w, "Input: %s\n", item.Input)
+		fmt.Fprintf(w, "Response: %s\n", item.Response)
+		if item.Error != "" {
+			fmt.Fprintf(w, "Error: %s\n", item.Error)
+		}
+		fmt.Fprintf(w, "Tokens: %d | Time: %v\n\n", item.TokensUsed, item.ResponseTime)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"sync"
@@ -16,9 +17,11 @@ import (
 )
 
 type Processor struct {
-	agent  *agent.Agent
-	config Config
-	logger *zap.Logger
+	agent    agent.Chatter
+	config   Config
+	logger   *zap.Logger
+	progress *ProgressRenderer
+	onResult func(OutputItem)
 }
 
 type Config struct {
@@ -28,6 +31,14 @@ type Config struct {
 	RetryDelay       time.Duration
 	SkipInvalid      bool
 	ValidateInput    bool
+
+	// OutputFormat is one of text, json, jsonl or csv. Empty infers it from
+	// the output file extension.
+	OutputFormat string
+
+	// Schema, when set, requires every response to be JSON conforming to it.
+	// Invalid responses are retried with the validation error as feedback.
+	Schema *Schema
 }
 
 type InputItem struct {
@@ -40,6 +51,7 @@ type OutputItem struct {
 	ID           string            `json:"id"`
 	Input        string            `json:"input"`
 	Response     string            `json:"response"`
+	Structured   json.RawMessage   `json:"structured,omitempty"`
 	TokensUsed   int               `json:"tokens_used"`
 	ResponseTime time.Duration     `json:"response_time"`
 	Success      bool              `json:"success"`
@@ -70,7 +82,7 @@ func DefaultConfig() Config {
 	}
 }
 
-func NewProcessor(ag *agent.Agent, cfg Config, logger *zap.Logger) *Processor {
+func NewProcessor(ag agent.Chatter, cfg Config, logger *zap.Logger) *Processor {
 	if cfg.MaxConcurrency <= 0 {
 		cfg.MaxConcurrency = 1
 	}
@@ -81,6 +93,17 @@ func NewProcessor(ag *agent.Agent, cfg Config, logger *zap.Logger) *Processor {
 	}
 }
 
+// SetProgressRenderer enables live progress output for subsequent runs
+func (p *Processor) SetProgressRenderer(r *ProgressRenderer) {
+	p.progress = r
+}
+
+// SetOnResult calls fn with each item as it finishes, for callers that
+// record progress or stream results as the batch runs
+func (p *Processor) SetOnResult(fn func(OutputItem)) {
+	p.onResult = fn
+}
+
 func (p *Processor) ProcessFile(ctx context.Context, inputPath, outputPath string) (*Result, error) {
 	startTime := time.Now()
 
@@ -95,6 +118,10 @@ func (p *Processor) ProcessFile(ctx context.Context, inputPath, outputPath strin
 		Items:     make([]OutputItem, 0, len(items)),
 	}
 
+	if p.progress != nil {
+		p.progress.Start(len(items))
+	}
+
 	itemsChan := make(chan InputItem, len(items))
 	resultsChan := make(chan OutputItem, len(items))
 
@@ -122,12 +149,22 @@ func (p *Processor) ProcessFile(ctx context.Context, inputPath, outputPath strin
 		if output.Success {
 			result.Success++
 		} else {
-			if output.Error == "skipped" {
+			if output.Error == "skipped" || output.Error == "cancelled" {
 				result.Skipped++
 			} else {
 				result.Failed++
 			}
 		}
+		if p.progress != nil {
+			p.progress.Record(output)
+		}
+		if p.onResult != nil {
+			p.onResult(output)
+		}
+	}
+
+	if p.progress != nil {
+		p.progress.Stop()
 	}
 
 	result.EndTime = time.Now()
@@ -144,6 +181,12 @@ func (p *Processor) ProcessFile(ctx context.Context, inputPath, outputPath strin
 
 func (p *Processor) worker(ctx context.Context, items <-chan InputItem, results chan<- OutputItem) {
 	for item := range items {
+		// Once the batch is cancelled, the remaining items are passed over
+		// rather than each failing through its retries
+		if ctx.Err() != nil {
+			results <- OutputItem{ID: item.ID, Input: item.Message, Error: "cancelled", Timestamp: time.Now()}
+			continue
+		}
 		output := p.processItem(ctx, item)
 		results <- output
 	}
@@ -169,23 +212,69 @@ func (p *Processor) processItem(ctx context.Context, item InputItem) OutputItem
 		}
 	}
 
+	message := item.Message
+	if p.config.Schema != nil {
+		message = message + "\n\n" + schemaInstruction(p.config.Schema)
+	}
+
+	resp, err := p.chatWithRetry(ctx, agent.ChatRequest{Message: message}, &output)
+	if err != nil {
+		output.Error = err.Error()
+		output.Success = false
+		return output
+	}
+
+	output.Response = resp.Content
+	output.TokensUsed = resp.TokensUsed
+
+	if p.config.Schema != nil {
+		structured, err := ParseStructured(resp.Content, p.config.Schema)
+		for attempt := 0; err != nil && attempt < p.config.RetryCount; attempt++ {
+			p.logger.Debug("Structured output invalid, retrying",
+				zap.String("id", item.ID),
+				zap.Int("attempt", attempt+1),
+				zap.Error(err),
+			)
+			resp, err = p.chatWithRetry(ctx, agent.ChatRequest{
+				ConversationID: resp.ConversationID,
+				Message:        fmt.Sprintf("Your previous response was not valid: %v\n%s", err, schemaInstruction(p.config.Schema)),
+			}, &output)
+			if err != nil {
+				break
+			}
+			output.Response = resp.Content
+			output.TokensUsed += resp.TokensUsed
+			structured, err = ParseStructured(resp.Content, p.config.Schema)
+		}
+		if err != nil {
+			output.Error = err.Error()
+			output.Success = false
+			return output
+		}
+		output.Structured = structured
+	}
+
+	output.Success = true
+
+	return output
+}
+
+// chatWithRetry sends a request to the agent, retrying transport failures
+func (p *Processor) chatWithRetry(ctx context.Context, req agent.ChatRequest, output *OutputItem) (*agent.ChatResponse, error) {
 	var resp *agent.ChatResponse
 	var err error
 
 	for attempt := 0; attempt <= p.config.RetryCount; attempt++ {
 		processCtx, cancel := context.WithTimeout(ctx, p.config.Timeout)
-		
+
 		start := time.Now()
-		resp, err = p.agent.Chat(processCtx, agent.ChatRequest{
-			Message: item.Message,
-			Stream:  false,
-		})
-		output.ResponseTime = time.Since(start)
-		
+		resp, err = p.agent.Chat(processCtx, req)
+		output.ResponseTime += time.Since(start)
+
 		cancel()
 
 		if err == nil {
-			break
+			return resp, nil
 		}
 
 		if attempt < p.config.RetryCount {
@@ -193,17 +282,7 @@ func (p *Processor) processItem(ctx context.Context, item InputItem) OutputItem
 		}
 	}
 
-	if err != nil {
-		output.Error = err.Error()
-		output.Success = false
-		return output
-	}
-
-	output.Response = resp.Content
-	output.TokensUsed = resp.TokensUsed
-	output.Success = true
-
-	return output
+	return nil, err
 }
 
 func (p *Processor) loadInputFile(path string) ([]InputItem, error) {
@@ -221,7 +300,7 @@ func (p *Processor) loadInputFile(path string) ([]InputItem, error) {
 	return p.loadTextFile(file)
 }
 
-func (p *Processor) loadJSONFile(file *os.File) ([]InputItem, error) {
+func (p *Processor) loadJSONFile(file io.Reader) ([]InputItem, error) {
 	var items []InputItem
 	decoder := json.NewDecoder(file)
 	
@@ -275,24 +354,7 @@ func (p *Processor) saveOutputFile(path string, result *Result) error {
 	}
 	defer file.Close()
 
-	ext := strings.ToLower(path[len(path)-4:])
-	if ext == "json" {
-		encoder := json.NewEncoder(file)
-		encoder.SetIndent("", "  ")
-		return encoder.Encode(result)
-	}
-
-	for _, item := range result.Items {
-		fmt.Fprintf(file, "=== %s ===\n", item.ID)
-		fmt.Fprintf(file, "Input: %s\n", item.Input)
-		fmt.Fprintf(file, "Response: %s\n", item.Response)
-		if item.Error != "" {
-			fmt.Fprintf(file, "Error: %s\n", item.Error)
-		}
-		fmt.Fprintf(file, "Tokens: %d | Time: %v\n\n", item.TokensUsed, item.ResponseTime)
-	}
-
-	return nil
+	return WriteResults(file, result, ResolveOutputFormat(p.config.OutputFormat, path), p.config.Schema)
 }
 
 func (r *Result) Summary() string {
@@ -1,7 +1,9 @@
 package batch
 
 import (
+	"encoding/csv"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -482,3 +484,122 @@ func BenchmarkResult_ToJSON(b *testing.B) {
 		result.ToJSON()
 	}
 }
+
+func TestParseStructured_ValidatesSchema(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{
+		"type": "object",
+		"required": ["sentiment", "score"],
+		"properties": {
+			"sentiment": {"type": "string", "enum": ["positive", "negative", "neutral"]},
+			"score": {"type": "number"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := ParseStructured("Sure!\n```json\n{\"sentiment\": \"positive\", \"score\": 0.9}\n```", schema)
+	if err != nil {
+		t.Fatalf("expected valid structured output, got %v", err)
+	}
+	if string(raw) != `{"score":0.9,"sentiment":"positive"}` {
+		t.Errorf("unexpected normalized JSON: %s", raw)
+	}
+
+	if _, err := ParseStructured(`{"sentiment": "angry", "score": 1}`, schema); err == nil {
+		t.Error("expected enum violation")
+	}
+	if _, err := ParseStructured(`{"sentiment": "neutral"}`, schema); err == nil {
+		t.Error("expected missing required property")
+	}
+	if _, err := ParseStructured(`no json here`, schema); err == nil {
+		t.Error("expected error when response has no JSON")
+	}
+}
+
+func TestResolveOutputFormat(t *testing.T) {
+	tests := map[string]string{
+		"out.json":  FormatJSON,
+		"out.jsonl": FormatJSONL,
+		"out.CSV":   FormatCSV,
+		"out.txt":   FormatText,
+	}
+	for path, want := range tests {
+		if got := ResolveOutputFormat("", path); got != want {
+			t.Errorf("ResolveOutputFormat(%q) = %q, want %q", path, got, want)
+		}
+	}
+	if got := ResolveOutputFormat(FormatCSV, "out.json"); got != FormatCSV {
+		t.Errorf("explicit format should win, got %q", got)
+	}
+}
+
+func TestSaveOutputFile_JSONLAndCSV(t *testing.T) {
+	dir := t.TempDir()
+	schema, _ := ParseSchema([]byte(`{"type":"object","properties":{"label":{"type":"string"}}}`))
+
+	result := &Result{
+		Items: []OutputItem{
+			{ID: "a", Input: "x", Response: `{"label":"cat"}`, Structured: []byte(`{"label":"cat"}`), Success: true},
+			{ID: "b", Input: "y", Error: "boom"},
+			{ID: "c", Input: "z", Response: `["cat"]`, Structured: []byte(`["cat"]`), Success: true},
+		},
+	}
+
+	processor := &Processor{config: Config{Schema: schema}}
+
+	jsonlPath := dir + "/out.jsonl"
+	if err := processor.saveOutputFile(jsonlPath, result); err != nil {
+		t.Fatalf("saveOutputFile jsonl failed: %v", err)
+	}
+	data, _ := os.ReadFile(jsonlPath)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 JSONL lines, got %d", len(lines))
+	}
+
+	csvPath := dir + "/out.csv"
+	if err := processor.saveOutputFile(csvPath, result); err != nil {
+		t.Fatalf("saveOutputFile csv failed: %v", err)
+	}
+	file, _ := os.Open(csvPath)
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("expected header + 3 rows, got %d", len(rows))
+	}
+	if rows[0][len(rows[0])-1] != "label" {
+		t.Errorf("expected schema column in header, got %q", rows[0])
+	}
+	if rows[1][len(rows[1])-1] != "cat" {
+		t.Errorf("expected structured value in row, got %q", rows[1])
+	}
+	if !strings.Contains(rows[3][4], "failed to parse structured output") || rows[3][len(rows[3])-1] != "" {
+		t.Errorf("expected the parse error in the error column, got %q", rows[3])
+	}
+}
+
+func TestProgressRenderer_Line(t *testing.T) {
+	var buf strings.Builder
+	r := NewProgressRenderer(&buf, 0.5)
+	r.Start(4)
+	r.Record(OutputItem{Success: true, TokensUsed: 1500})
+	r.Record(OutputItem{Error: "boom", TokensUsed: 500})
+	r.Stop()
+
+	r.mu.Lock()
+	line := r.line(time.Now())
+	r.mu.Unlock()
+
+	for _, want := range []string{"2/4 (50%)", "✓ 1 ✗ 1", "2.0k tokens", "$1.0000", "ETA"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("progress line %q missing %q", line, want)
+		}
+	}
+	if !strings.Contains(buf.String(), "\r") {
+		t.Error("expected in-place redraw using carriage return")
+	}
+}
@@ -0,0 +1,191 @@
+package batch
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// throughputWindow is the number of recent completions used to estimate the
+// current rate. A rolling window keeps the ETA responsive to rate limiting and
+// slow items instead of averaging over the whole run.
+const throughputWindow = 20
+
+// ProgressRenderer draws a single self-updating progress line for batch runs
+type ProgressRenderer struct {
+	out          io.Writer
+	costPer1K    float64
+	refresh      time.Duration
+	barWidth     int
+	total        int
+	done         int
+	success      int
+	failed       int
+	skipped      int
+	tokens       int
+	startTime    time.Time
+	completions  []time.Time
+	lastLineSize int
+	stopCh       chan struct{}
+	stoppedCh    chan struct{}
+	mu           sync.Mutex
+}
+
+// NewProgressRenderer creates a renderer writing to out. costPer1K is the
+// price per 1,000 tokens used for the cost estimate; zero hides the cost.
+func NewProgressRenderer(out io.Writer, costPer1K float64) *ProgressRenderer {
+	return &ProgressRenderer{
+		out:       out,
+		costPer1K: costPer1K,
+		refresh:   500 * time.Millisecond,
+		barWidth:  24,
+	}
+}
+
+// Start begins rendering for a run of total items
+func (r *ProgressRenderer) Start(total int) {
+	r.mu.Lock()
+	r.total = total
+	r.startTime = time.Now()
+	r.stopCh = make(chan struct{})
+	r.stoppedCh = make(chan struct{})
+	r.mu.Unlock()
+
+	go r.loop()
+}
+
+// Record registers a completed item
+func (r *ProgressRenderer) Record(item OutputItem) {
+	r.mu.Lock()
+	r.done++
+	r.tokens += item.TokensUsed
+	switch {
+	case item.Success:
+		r.success++
+	case item.Error == "skipped":
+		r.skipped++
+	default:
+		r.failed++
+	}
+	r.completions = append(r.completions, time.Now())
+	if len(r.completions) > throughputWindow {
+		r.completions = r.completions[len(r.completions)-throughputWindow:]
+	}
+	r.mu.Unlock()
+}
+
+// Stop renders the final state and ends the refresh loop
+func (r *ProgressRenderer) Stop() {
+	r.mu.Lock()
+	stopCh := r.stopCh
+	r.mu.Unlock()
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-r.stoppedCh
+	fmt.Fprintln(r.out)
+}
+
+func (r *ProgressRenderer) loop() {
+	defer close(r.stoppedCh)
+
+	ticker := time.NewTicker(r.refresh)
+	defer ticker.Stop()
+
+	for {
+		r.draw()
+		select {
+		case <-r.stopCh:
+			r.draw()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *ProgressRenderer) draw() {
+	r.mu.Lock()
+	line := r.line(time.Now())
+	pad := r.lastLineSize - len(line)
+	r.lastLineSize = len(line)
+	r.mu.Unlock()
+
+	if pad < 0 {
+		pad = 0
+	}
+	fmt.Fprintf(r.out, "\r%s%s", line, strings.Repeat(" ", pad))
+}
+
+// line formats the progress line; callers must hold r.mu
+func (r *ProgressRenderer) line(now time.Time) string {
+	percent := 0.0
+	if r.total > 0 {
+		percent = float64(r.done) / float64(r.total)
+	}
+	filled := int(percent * float64(r.barWidth))
+
+	var sb strings.Builder
+	sb.WriteString("[")
+	sb.WriteString(strings.Repeat("█", filled))
+	sb.WriteString(strings.Repeat("░", r.barWidth-filled))
+	sb.WriteString("] ")
+	sb.WriteString(fmt.Sprintf("%d/%d (%.0f%%)", r.done, r.total, percent*100))
+	sb.WriteString(fmt.Sprintf(" | ✓ %d ✗ %d", r.success, r.failed))
+	if r.skipped > 0 {
+		sb.WriteString(fmt.Sprintf(" ⊘ %d", r.skipped))
+	}
+	sb.WriteString(fmt.Sprintf(" | %s tokens", formatTokens(r.tokens)))
+	if r.costPer1K > 0 {
+		sb.WriteString(fmt.Sprintf(" | $%.4f", float64(r.tokens)/1000*r.costPer1K))
+	}
+
+	rate := r.rate(now)
+	sb.WriteString(fmt.Sprintf(" | %.1f/s", rate))
+	if r.done < r.total {
+		if rate > 0 {
+			eta := time.Duration(float64(r.total-r.done) / rate * float64(time.Second))
+			sb.WriteString(" | ETA " + eta.Round(time.Second).String())
+		} else {
+			sb.WriteString(" | ETA --")
+		}
+	} else {
+		sb.WriteString(" | done in " + now.Sub(r.startTime).Round(time.Second).String())
+	}
+
+	return sb.String()
+}
+
+// rate returns items per second over the rolling window; callers must hold r.mu
+func (r *ProgressRenderer) rate(now time.Time) float64 {
+	if len(r.completions) == 0 {
+		return 0
+	}
+
+	from := r.startTime
+	count := len(r.completions)
+	if len(r.completions) == throughputWindow {
+		// Window is full: measure from the oldest completion it holds
+		from = r.completions[0]
+		count--
+	}
+
+	elapsed := now.Sub(from).Seconds()
+	if elapsed <= 0 || count == 0 {
+		return 0
+	}
+	return float64(count) / elapsed
+}
+
+func formatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
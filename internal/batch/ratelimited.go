@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/config"
 	"go.uber.org/zap"
 	"golang.org/x/time/rate"
 )
@@ -56,6 +57,41 @@ func Tier5Config() RateLimiterConfig {
 	}
 }
 
+// TierConfig returns the Moonshot/Kimi preset for a tier name ("3", "4" or "5")
+func TierConfig(tier string) (RateLimiterConfig, bool) {
+	switch tier {
+	case "3":
+		return Tier3Config(), true
+	case "4":
+		return Tier4Config(), true
+	case "5":
+		return Tier5Config(), true
+	}
+	return RateLimiterConfig{}, false
+}
+
+// ProviderLimits converts the config into provider rate limits so they can be
+// enforced by the LLM client's shared, header-aware limiter
+func (c RateLimiterConfig) ProviderLimits() config.RateLimitConfig {
+	return config.RateLimitConfig{
+		RPM:            c.RPM,
+		TPM:            c.TPM,
+		MaxConcurrency: c.MaxConcurrency,
+		Burst:          c.Burst,
+	}
+}
+
+// RateLimiterConfigFromProvider builds a batch rate limiter config from a
+// provider's configured limits
+func RateLimiterConfigFromProvider(rl config.RateLimitConfig) RateLimiterConfig {
+	return RateLimiterConfig{
+		RPM:            rl.RPM,
+		TPM:            rl.TPM,
+		MaxConcurrency: rl.MaxConcurrency,
+		Burst:          rl.Burst,
+	}
+}
+
 // RateLimitedProcessor extends Processor with rate limiting
 type RateLimitedProcessor struct {
 	*Processor
@@ -122,6 +158,9 @@ func (rp *RateLimitedProcessor) ProcessFileWithRateLimit(ctx context.Context, in
 		Total:     len(items),
 		StartTime: startTime,
 	}
+	if rp.progress != nil {
+		rp.progress.Start(len(items))
+	}
 
 	itemsChan := make(chan InputItem, len(items))
 	resultsChan := make(chan OutputItem, len(items))
@@ -165,6 +204,14 @@ func (rp *RateLimitedProcessor) ProcessFileWithRateLimit(ctx context.Context, in
 		rp.tokensPerMin += int64(output.TokensUsed)
 		rp.requestsCount++
 		rp.mu.Unlock()
+
+		if rp.progress != nil {
+			rp.progress.Record(output)
+		}
+	}
+
+	if rp.progress != nil {
+		rp.progress.Stop()
 	}
 
 	result.EndTime = time.Now()
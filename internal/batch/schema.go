@@ -0,0 +1,210 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Schema is a subset of JSON Schema used to validate structured batch output.
+// Supported keywords: type, properties, required, items, enum.
+type Schema struct {
+	Type        string             `json:"type,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+
+	raw json.RawMessage
+}
+
+// LoadSchema reads a JSON schema from disk
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+	return ParseSchema(data)
+}
+
+// ParseSchema parses a JSON schema document
+func ParseSchema(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	s.raw = append(json.RawMessage{}, data...)
+	return &s, nil
+}
+
+// String returns the schema document as JSON
+func (s *Schema) String() string {
+	if len(s.raw) > 0 {
+		return string(s.raw)
+	}
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// Columns returns the sorted top-level property names for object schemas
+func (s *Schema) Columns() []string {
+	if s == nil || len(s.Properties) == 0 {
+		return nil
+	}
+	cols := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		cols = append(cols, name)
+	}
+	sort.Strings(cols)
+	return cols
+}
+
+// Validate checks a decoded JSON value against the schema
+func (s *Schema) Validate(value interface{}) error {
+	return s.validate("$", value)
+}
+
+func (s *Schema) validate(path string, value interface{}) error {
+	if s == nil {
+		return nil
+	}
+
+	if len(s.Enum) > 0 {
+		matched := false
+		for _, e := range s.Enum {
+			if fmt.Sprint(e) == fmt.Sprint(value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: value %v not in enum %v", path, value, s.Enum)
+		}
+	}
+
+	switch s.Type {
+	case "":
+		return nil
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object", path)
+		}
+		for _, req := range s.Required {
+			if _, exists := obj[req]; !exists {
+				return fmt.Errorf("%s: missing required property %q", path, req)
+			}
+		}
+		for name, prop := range s.Properties {
+			if v, exists := obj[name]; exists {
+				if err := prop.validate(path+"."+name, v); err != nil {
+					return err
+				}
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array", path)
+		}
+		for i, v := range arr {
+			if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), v); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: expected string", path)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: expected number", path)
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != float64(int64(n)) {
+			return fmt.Errorf("%s: expected integer", path)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean", path)
+		}
+	case "null":
+		if value != nil {
+			return fmt.Errorf("%s: expected null", path)
+		}
+	default:
+		return fmt.Errorf("%s: unsupported schema type %q", path, s.Type)
+	}
+
+	return nil
+}
+
+// ExtractJSON pulls a JSON document out of a model response, tolerating
+// markdown code fences and surrounding prose.
+func ExtractJSON(content string) (json.RawMessage, error) {
+	content = strings.TrimSpace(content)
+
+	if idx := strings.Index(content, "```"); idx >= 0 {
+		rest := content[idx+3:]
+		if nl := strings.Index(rest, "\n"); nl >= 0 {
+			rest = rest[nl+1:]
+		}
+		if end := strings.Index(rest, "```"); end >= 0 {
+			content = strings.TrimSpace(rest[:end])
+		}
+	}
+
+	if json.Valid([]byte(content)) {
+		return json.RawMessage(content), nil
+	}
+
+	start := strings.IndexAny(content, "{[")
+	if start < 0 {
+		return nil, fmt.Errorf("no JSON found in response")
+	}
+	closer := byte('}')
+	if content[start] == '[' {
+		closer = ']'
+	}
+	end := strings.LastIndexByte(content, closer)
+	if end <= start {
+		return nil, fmt.Errorf("no JSON found in response")
+	}
+
+	candidate := content[start : end+1]
+	if !json.Valid([]byte(candidate)) {
+		return nil, fmt.Errorf("response contains malformed JSON")
+	}
+	return json.RawMessage(candidate), nil
+}
+
+// ParseStructured extracts JSON from a response and validates it against the schema
+func ParseStructured(content string, schema *Schema) (json.RawMessage, error) {
+	raw, err := ExtractJSON(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+	if err := schema.Validate(value); err != nil {
+		return nil, fmt.Errorf("schema validation failed: %w", err)
+	}
+
+	// Re-encode to normalise whitespace for JSONL/CSV output
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+func schemaInstruction(schema *Schema) string {
+	return "Respond ONLY with a single JSON value (no prose, no code fences) that conforms to this JSON schema:\n" + schema.String()
+}
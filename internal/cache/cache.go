@@ -0,0 +1,360 @@
+// Package cache keeps the results of expensive external lookups (weather,
+// web search, GitHub) for a short while, so identical questions asked close
+// together don't hit the API again. One cache is shared by all skills; each
+// skill uses its own namespace.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/metrics"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultMaxEntries bounds the cache when no size is configured
+const DefaultMaxEntries = 1000
+
+// pruneEvery is how many writes pass between sweeps of expired persisted
+// entries
+const pruneEvery = 100
+
+type bypassKey struct{}
+
+// WithBypass returns a context whose lookups skip the cache. Fresh results
+// are still stored, so the next request benefits from them.
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// Bypassed reports whether ctx asks to skip the cache
+func Bypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassKey{}).(bool)
+	return bypass
+}
+
+// Options configures a cache
+type Options struct {
+	MaxEntries int
+	// TTLs overrides the skills' default time to live per namespace
+	TTLs map[string]time.Duration
+}
+
+// Entry is a cached value. Entries are only written to the database when the
+// cache is persisted.
+type Entry struct {
+	Key       string    `gorm:"primaryKey;column:cache_key"`
+	Namespace string    `gorm:"index"`
+	Value     []byte    `gorm:"type:blob"`
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+// TableName sets the table name
+func (Entry) TableName() string { return "skill_cache" }
+
+// Stats counts how a namespace's lookups went
+type Stats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Bypassed  int64 `json:"bypassed"`
+	Evictions int64 `json:"evictions"`
+	Entries   int   `json:"entries"`
+}
+
+// HitRate is the share of lookups answered from the cache
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses + s.Bypassed
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// Cache is a size-bounded, least-recently-used cache with a time to live per
+// entry, optionally persisted so results survive a restart. A nil *Cache is
+// valid and caches nothing.
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttls       map[string]time.Duration
+	entries    map[string]*list.Element
+	order      *list.List // most recently used first
+	stats      map[string]*Stats
+	db         *gorm.DB
+	writes     int
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+func init() {
+	store.RegisterMigrations("cache", store.Migration{
+		Version: 1,
+		Name:    "create cache table",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Entry{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Entry{})
+		},
+	})
+}
+
+// New creates a cache. With a database, entries are also saved there and
+// read back after a restart.
+func New(opts Options, db *gorm.DB, logger *zap.Logger) (*Cache, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxEntries
+	}
+	c := &Cache{
+		maxEntries: opts.MaxEntries,
+		ttls:       opts.TTLs,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		stats:      make(map[string]*Stats),
+		db:         db,
+		logger:     logger,
+		now:        time.Now,
+	}
+	if db != nil {
+		if err := store.Migrate(db, "cache"); err != nil {
+			return nil, fmt.Errorf("failed to migrate cache schema: %w", err)
+		}
+		c.prune()
+	}
+	return c, nil
+}
+
+// Key builds a cache key from the parts of a query. Case and surrounding
+// space are ignored, so "Tokyo" and " tokyo" share an entry.
+func Key(parts ...interface{}) string {
+	normalized := make([]string, len(parts))
+	for i, part := range parts {
+		normalized[i] = strings.ToLower(strings.TrimSpace(fmt.Sprint(part)))
+	}
+	return strings.Join(normalized, "|")
+}
+
+// TTL returns how long namespace keeps entries: the configured override, or
+// fallback
+func (c *Cache) TTL(namespace string, fallback time.Duration) time.Duration {
+	if c == nil {
+		return fallback
+	}
+	if ttl, ok := c.ttls[namespace]; ok {
+		return ttl
+	}
+	return fallback
+}
+
+// Get decodes a fresh entry into out and reports whether there was one
+func (c *Cache) Get(ctx context.Context, namespace, key string, out interface{}) bool {
+	if c == nil {
+		return false
+	}
+	if Bypassed(ctx) {
+		c.count(namespace, "bypass")
+		return false
+	}
+	value, ok := c.lookup(namespace, key)
+	if ok && json.Unmarshal(value, out) == nil {
+		c.count(namespace, "hit")
+		return true
+	}
+	c.count(namespace, "miss")
+	return false
+}
+
+// Set stores value for ttl. A zero or negative ttl stores nothing.
+func (c *Cache) Set(namespace, key string, value interface{}, ttl time.Duration) {
+	if c == nil || ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.logger.Debug("Value not cacheable", zap.String("namespace", namespace), zap.Error(err))
+		return
+	}
+	entry := &Entry{
+		Key:       fullKey(namespace, key),
+		Namespace: namespace,
+		Value:     data,
+		ExpiresAt: c.now().Add(ttl),
+	}
+
+	c.mu.Lock()
+	c.store(entry)
+	c.writes++
+	sweep := c.writes%pruneEvery == 0
+	c.mu.Unlock()
+
+	if c.db != nil {
+		err := c.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(entry).Error
+		if err != nil {
+			c.logger.Warn("Failed to persist cache entry", zap.String("namespace", namespace), zap.Error(err))
+		}
+		if sweep {
+			c.prune()
+		}
+	}
+}
+
+// Fetch returns the cached result for key, or calls fn and caches what it
+// returns. Errors are never cached.
+func Fetch[T any](ctx context.Context, c *Cache, namespace, key string, ttl time.Duration, fn func() (T, error)) (T, error) {
+	var cached T
+	if c.Get(ctx, namespace, key, &cached) {
+		return cached, nil
+	}
+	value, err := fn()
+	if err != nil {
+		return value, err
+	}
+	c.Set(namespace, key, value, c.TTL(namespace, ttl))
+	return value, nil
+}
+
+// Purge drops a namespace's entries, or every entry when namespace is empty,
+// and returns how many were in memory
+func (c *Cache) Purge(namespace string) int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	removed := 0
+	for key, el := range c.entries {
+		if namespace == "" || el.Value.(*Entry).Namespace == namespace {
+			c.order.Remove(el)
+			delete(c.entries, key)
+			removed++
+		}
+	}
+	c.mu.Unlock()
+
+	if c.db != nil {
+		query := c.db.Session(&gorm.Session{AllowGlobalUpdate: true})
+		if namespace != "" {
+			query = query.Where("namespace = ?", namespace)
+		}
+		if err := query.Delete(&Entry{}).Error; err != nil {
+			c.logger.Warn("Failed to purge persisted cache", zap.Error(err))
+		}
+	}
+	return removed
+}
+
+// Stats returns the counters for each namespace that has been used
+func (c *Cache) Stats() map[string]Stats {
+	out := make(map[string]Stats)
+	if c == nil {
+		return out
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, s := range c.stats {
+		out[name] = *s
+	}
+	for _, el := range c.entries {
+		ns := el.Value.(*Entry).Namespace
+		s := out[ns]
+		s.Entries++
+		out[ns] = s
+	}
+	return out
+}
+
+// lookup returns a fresh value from memory, falling back to the database
+func (c *Cache) lookup(namespace, key string) ([]byte, bool) {
+	full := fullKey(namespace, key)
+	now := c.now()
+
+	c.mu.Lock()
+	if el, ok := c.entries[full]; ok {
+		entry := el.Value.(*Entry)
+		if now.Before(entry.ExpiresAt) {
+			c.order.MoveToFront(el)
+			c.mu.Unlock()
+			return entry.Value, true
+		}
+		c.order.Remove(el)
+		delete(c.entries, full)
+	}
+	c.mu.Unlock()
+
+	if c.db == nil {
+		return nil, false
+	}
+	var entry Entry
+	err := c.db.Where("cache_key = ? AND expires_at > ?", full, now).Limit(1).Find(&entry).Error
+	if err != nil || entry.Key == "" {
+		return nil, false
+	}
+	c.mu.Lock()
+	c.store(&entry)
+	c.mu.Unlock()
+	return entry.Value, true
+}
+
+// store adds or replaces an entry in memory, evicting the least recently
+// used ones beyond the size bound. The caller holds the lock.
+func (c *Cache) store(entry *Entry) {
+	if el, ok := c.entries[entry.Key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[entry.Key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		evicted := oldest.Value.(*Entry)
+		c.order.Remove(oldest)
+		delete(c.entries, evicted.Key)
+		c.statsFor(evicted.Namespace).Evictions++
+	}
+}
+
+func (c *Cache) count(namespace, result string) {
+	c.mu.Lock()
+	s := c.statsFor(namespace)
+	switch result {
+	case "hit":
+		s.Hits++
+	case "miss":
+		s.Misses++
+	case "bypass":
+		s.Bypassed++
+	}
+	c.mu.Unlock()
+	metrics.SkillCacheRequests.WithLabelValues(namespace, result).Inc()
+}
+
+// statsFor returns a namespace's counters. The caller holds the lock.
+func (c *Cache) statsFor(namespace string) *Stats {
+	s, ok := c.stats[namespace]
+	if !ok {
+		s = &Stats{}
+		c.stats[namespace] = s
+	}
+	return s
+}
+
+// prune deletes expired persisted entries
+func (c *Cache) prune() {
+	if err := c.db.Where("expires_at <= ?", c.now()).Delete(&Entry{}).Error; err != nil {
+		c.logger.Warn("Failed to prune cache", zap.Error(err))
+	}
+}
+
+func fullKey(namespace, key string) string {
+	return namespace + ":" + key
+}
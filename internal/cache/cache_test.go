@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type forecast struct {
+	City string  `json:"city"`
+	Temp float64 `json:"temp"`
+}
+
+func TestFetch_CachesUntilExpiry(t *testing.T) {
+	c, err := New(Options{}, nil, nil)
+	require.NoError(t, err)
+	now := time.Date(2025, 5, 6, 9, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	calls := 0
+	lookup := func() (forecast, error) {
+		calls++
+		return forecast{City: "Tokyo", Temp: 21.5}, nil
+	}
+
+	got, err := Fetch(ctx, c, "weather", Key("Tokyo"), 10*time.Minute, lookup)
+	require.NoError(t, err)
+	assert.Equal(t, forecast{City: "Tokyo", Temp: 21.5}, got)
+	got, err = Fetch(ctx, c, "weather", Key(" tokyo "), 10*time.Minute, lookup)
+	require.NoError(t, err)
+	assert.Equal(t, "Tokyo", got.City)
+	assert.Equal(t, 1, calls)
+
+	// A bypassed lookup calls through and refreshes the entry
+	_, err = Fetch(WithBypass(ctx), c, "weather", Key("Tokyo"), 10*time.Minute, lookup)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	now = now.Add(11 * time.Minute)
+	_, err = Fetch(ctx, c, "weather", Key("Tokyo"), 10*time.Minute, lookup)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	stats := c.Stats()["weather"]
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, int64(1), stats.Bypassed)
+	assert.Equal(t, 1, stats.Entries)
+}
+
+func TestFetch_ErrorsAreNotCached(t *testing.T) {
+	c, err := New(Options{}, nil, nil)
+	require.NoError(t, err)
+	calls := 0
+	failing := func() (string, error) {
+		calls++
+		return "", errors.New("rate limited")
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := Fetch(context.Background(), c, "search", "q", time.Minute, failing)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c, err := New(Options{MaxEntries: 2}, nil, nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	c.Set("search", "a", "A", time.Hour)
+	c.Set("search", "b", "B", time.Hour)
+	var v string
+	require.True(t, c.Get(ctx, "search", "a", &v))
+	c.Set("search", "c", "C", time.Hour)
+
+	assert.True(t, c.Get(ctx, "search", "a", &v))
+	assert.False(t, c.Get(ctx, "search", "b", &v))
+	assert.True(t, c.Get(ctx, "search", "c", &v))
+	assert.Equal(t, int64(1), c.Stats()["search"].Evictions)
+}
+
+func TestCache_TTLOverridesAndNil(t *testing.T) {
+	c, err := New(Options{TTLs: map[string]time.Duration{"github": 0}}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), c.TTL("github", 5*time.Minute))
+	assert.Equal(t, 5*time.Minute, c.TTL("weather", 5*time.Minute))
+
+	// A zero TTL turns caching off for the namespace
+	calls := 0
+	for i := 0; i < 2; i++ {
+		Fetch(context.Background(), c, "github", "repo", 5*time.Minute, func() (int, error) {
+			calls++
+			return calls, nil
+		})
+	}
+	assert.Equal(t, 2, calls)
+
+	var none *Cache
+	got, err := Fetch(context.Background(), none, "weather", "x", time.Minute, func() (int, error) { return 7, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 7, got)
+	assert.Empty(t, none.Stats())
+}
+
+func TestCache_Persisted(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	first, err := New(Options{}, db, nil)
+	require.NoError(t, err)
+	first.Set("weather", "tokyo", forecast{City: "Tokyo", Temp: 21.5}, time.Hour)
+	first.Set("search", "news", "headlines", time.Hour)
+
+	// A new cache on the same database, as after a restart
+	second, err := New(Options{}, db, nil)
+	require.NoError(t, err)
+	var got forecast
+	require.True(t, second.Get(ctx, "weather", "tokyo", &got))
+	assert.Equal(t, 21.5, got.Temp)
+
+	second.Purge("weather")
+	third, err := New(Options{}, db, nil)
+	require.NoError(t, err)
+	assert.False(t, third.Get(ctx, "weather", "tokyo", &got))
+	var s string
+	assert.True(t, third.Get(ctx, "search", "news", &s))
+}
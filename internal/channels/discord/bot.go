@@ -4,11 +4,25 @@ package discord
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/aliases"
+	"github.com/gmsas95/myrai-cli/internal/channels"
+	"github.com/gmsas95/myrai-cli/internal/channels/inbound"
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/incident"
+	"github.com/gmsas95/myrai-cli/internal/journal"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"github.com/gmsas95/myrai-cli/internal/persona"
+	"github.com/gmsas95/myrai-cli/internal/security"
+	"github.com/gmsas95/myrai-cli/internal/skills/voice"
 	"github.com/gmsas95/myrai-cli/internal/store"
 	"go.uber.org/zap"
 )
@@ -18,17 +32,30 @@ type Config struct {
 	Token    string
 	Enabled  bool
 	GuildID  string   // Optional: restrict to specific server
-	Channels []string // Optional: whitelist channels
+	Channels []string // Optional: whitelist channels; their threads are allowed too
 	AllowDM  bool     // Allow direct messages
+	// GroupMode decides which server messages to answer: mention (default)
+	// for mentions and replies to the bot, all, or off
+	GroupMode string
 }
 
 // Bot represents a Discord bot instance
 type Bot struct {
-	session *discordgo.Session
-	agent   *agent.Agent
-	store   *store.Store
-	config  Config
-	logger  *zap.Logger
+	session   *discordgo.Session
+	agent     *agent.Agent
+	store     *store.Store
+	config    Config
+	logger    *zap.Logger
+	aliases   *aliases.Manager
+	household *household.Manager
+	notifier  *notify.Dispatcher
+	incident  *incident.Mode
+	filter    *security.ContentFilter
+	journal   *journal.Journal
+	persona   string // answered as instead of the current persona
+	voice     *voice.Replier
+	inbound   *inbound.Guard // Rate limits and caps for incoming messages
+	health    channels.Tracker
 }
 
 // NewBot creates a new Discord bot
@@ -50,12 +77,32 @@ func NewBot(cfg Config, agentInstance *agent.Agent, st *store.Store, logger *zap
 		logger:  logger,
 	}
 
+	if st != nil {
+		if mgr, err := aliases.NewManager(st.DB()); err == nil {
+			bot.aliases = mgr
+		} else {
+			logger.Warn("Aliases unavailable", zap.Error(err))
+		}
+		if mgr, err := household.NewManager(st.DB()); err == nil {
+			bot.household = mgr
+		} else {
+			logger.Warn("Household profiles unavailable", zap.Error(err))
+		}
+	}
+
 	// Register handlers
 	session.AddHandler(bot.messageCreate)
 	session.AddHandler(bot.ready)
+	session.AddHandler(func(*discordgo.Session, *discordgo.Connect) { bot.health.Connected() })
+	session.AddHandler(func(*discordgo.Session, *discordgo.Disconnect) { bot.health.Disconnected(nil) })
 
 	// Set intents
 	session.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages
+	if cfg.GroupMode == config.GroupModeAll {
+		// Reading messages that don't mention the bot takes the privileged
+		// message content intent
+		session.Identify.Intents |= discordgo.IntentsMessageContent
+	}
 
 	return bot, nil
 }
@@ -63,6 +110,7 @@ func NewBot(cfg Config, agentInstance *agent.Agent, st *store.Store, logger *zap
 // Start starts the Discord bot
 func (b *Bot) Start() error {
 	if err := b.session.Open(); err != nil {
+		b.health.Disconnected(err)
 		return fmt.Errorf("failed to open discord connection: %w", err)
 	}
 
@@ -73,6 +121,62 @@ func (b *Bot) Start() error {
 	return nil
 }
 
+// SetNotifier makes the bot a delivery channel for notifications and lets
+// users adjust their preferences with /notifications
+func (b *Bot) SetNotifier(d *notify.Dispatcher) {
+	b.notifier = d
+	d.Register("discord", b)
+}
+
+// SetInboundLimits screens incoming messages with cfg's rate limits, size
+// cap and attachment types. Called again, it replaces the limits.
+func (b *Bot) SetInboundLimits(cfg config.InboundConfig) {
+	if b.inbound == nil {
+		b.inbound = inbound.New(cfg)
+		return
+	}
+	b.inbound.SetLimits(cfg)
+}
+
+// SetIncidentMode lets admins toggle incident mode with /incident
+func (b *Bot) SetIncidentMode(mode *incident.Mode) {
+	b.incident = mode
+}
+
+// SetContentFilter screens every reply and notification with filter before
+// it is sent. Filtered replies are logged and recorded in j.
+func (b *Bot) SetContentFilter(filter *security.ContentFilter, j *journal.Journal) {
+	b.filter = filter
+	b.journal = j
+}
+
+// SetPersona makes the bot answer as the named persona instead of the
+// current one; "" keeps the current one
+func (b *Bot) SetPersona(name string) {
+	b.persona = name
+}
+
+// SetVoiceReplies lets channels turn on spoken replies with /voice
+func (b *Bot) SetVoiceReplies(r *voice.Replier) {
+	b.voice = r
+}
+
+// Deliver sends a notification to a channel, splitting long messages
+func (b *Bot) Deliver(ctx context.Context, target, text string) error {
+	for _, part := range splitMessage(b.filterReply(ctx, target, text), 2000) {
+		if _, err := b.session.ChannelMessageSend(target, part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Health reports whether the bot is connected to Discord, and its last
+// message and error
+func (b *Bot) Health() channels.Health {
+	return b.health.Health("discord")
+}
+
 // Stop stops the Discord bot
 func (b *Bot) Stop() error {
 	return b.session.Close()
@@ -92,6 +196,7 @@ func (b *Bot) messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m.Author.ID == s.State.User.ID {
 		return
 	}
+	b.health.Received()
 
 	// Check if DM is allowed
 	if m.GuildID == "" && !b.config.AllowDM {
@@ -104,17 +209,8 @@ func (b *Bot) messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 	}
 
 	// Check channel whitelist
-	if len(b.config.Channels) > 0 {
-		allowed := false
-		for _, ch := range b.config.Channels {
-			if m.ChannelID == ch {
-				allowed = true
-				break
-			}
-		}
-		if !allowed {
-			return
-		}
+	if len(b.config.Channels) > 0 && !b.channelAllowed(s, m.ChannelID) {
+		return
 	}
 
 	// Check if bot is mentioned or DM
@@ -127,8 +223,8 @@ func (b *Bot) messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 		}
 	}
 
-	// In guilds, only respond to mentions
-	if !isDM && !isMentioned {
+	// In servers, answer only the messages meant for the bot
+	if !isDM && !b.addressedToBot(s, m, isMentioned) {
 		return
 	}
 
@@ -145,42 +241,187 @@ func (b *Bot) messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 		return
 	}
 
+	// Turn away floods, oversized messages and unwanted files
+	im := inbound.Message{ChatID: m.ChannelID, UserID: m.Author.ID, Text: content}
+	for _, a := range m.Attachments {
+		im.Attachments = append(im.Attachments, inbound.Attachment{Name: a.Filename, MimeType: a.ContentType})
+	}
+	if verdict := b.inbound.Check(im); !verdict.Allowed {
+		b.logger.Info("Inbound message rejected",
+			zap.String("channel_id", m.ChannelID),
+			zap.String("user_id", m.Author.ID),
+			zap.String("reason", verdict.Reason))
+		if verdict.Notice != "" {
+			s.ChannelMessageSend(m.ChannelID, verdict.Notice)
+		}
+		return
+	}
+
+	// Once a household is set up, only linked members may chat
+	if !b.isHouseholdMember(m, content) {
+		s.ChannelMessageSend(m.ChannelID, "🏠 This assistant belongs to a household. Ask the owner for an invite, then send /join <code>.")
+		return
+	}
+
+	// Direct messages are where notifications for this person can go
+	if b.notifier != nil && isDM {
+		userID := household.UserID(b.profileContext(m))
+		if err := b.notifier.Remember(userID, "discord", m.ChannelID); err != nil {
+			b.logger.Warn("Failed to remember notification address", zap.Error(err))
+		}
+	}
+
 	// Handle commands
 	if strings.HasPrefix(content, "/") {
 		b.handleCommand(s, m, content)
 		return
 	}
 
-	// Process with agent
-	ctx := context.Background()
+	b.chat(s, m, content)
+}
+
+// addressedToBot reports whether a server message is meant for the bot
+// under its group mode. In mention mode that's a mention of the bot or a
+// reply to one of its messages.
+func (b *Bot) addressedToBot(s *discordgo.Session, m *discordgo.MessageCreate, mentioned bool) bool {
+	switch b.config.GroupMode {
+	case config.GroupModeOff:
+		return false
+	case config.GroupModeAll:
+		return true
+	}
+	if mentioned {
+		return true
+	}
+	ref := m.ReferencedMessage
+	return ref != nil && ref.Author != nil && ref.Author.ID == s.State.User.ID
+}
+
+// channelAllowed reports whether the channel whitelist covers a channel,
+// or the channel a thread was started in. Each thread is a channel of its
+// own, so it keeps a conversation separate from its parent's.
+func (b *Bot) channelAllowed(s *discordgo.Session, channelID string) bool {
+	ids := []string{channelID}
+	ch, err := s.State.Channel(channelID)
+	if err != nil {
+		ch, err = s.Channel(channelID)
+	}
+	if err == nil && ch.IsThread() {
+		ids = append(ids, ch.ParentID)
+	}
+	for _, allowed := range b.config.Channels {
+		for _, id := range ids {
+			if id == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// chat sends content to the agent and replies in the message's channel
+func (b *Bot) chat(s *discordgo.Session, m *discordgo.MessageCreate, content string) {
+	ctx := b.profileContext(m)
 
 	// Show typing indicator
 	s.ChannelTyping(m.ChannelID)
 
 	resp, err := b.agent.Chat(ctx, agent.ChatRequest{
-		Message: content,
-		Stream:  false,
+		ConversationID: b.getConversationID(m.ChannelID),
+		Message:        content,
+		Channel:        "discord",
+		Stream:         false,
 	})
 
 	if err != nil {
 		b.logger.Error("Agent error", zap.Error(err))
+		b.health.Failed(err)
 		s.ChannelMessageSend(m.ChannelID, "❌ Error: "+err.Error())
 		return
 	}
 
-	// Send response (split if too long)
-	if len(resp.Content) > 2000 {
-		// Discord has 2000 char limit
-		parts := splitMessage(resp.Content, 2000)
-		for _, part := range parts {
-			s.ChannelMessageSend(m.ChannelID, part)
+	b.setConversationID(m.ChannelID, resp.ConversationID)
+	reply := b.filterReply(ctx, m.ChannelID, resp.Content)
+
+	// Post-processing has split the reply to Discord's 2000 character limit.
+	// A withheld reply is replaced by the filter's notice as a whole.
+	parts := resp.Parts
+	switch {
+	case reply == resp.Content:
+	case reply == security.FamilyBlockedReply:
+		parts = []string{reply}
+	default:
+		parts = make([]string, len(resp.Parts))
+		for i, part := range resp.Parts {
+			parts[i] = b.filter.Apply(part).Text
+		}
+	}
+	for i, part := range parts {
+		if i > 0 {
 			time.Sleep(100 * time.Millisecond) // Rate limit
 		}
-	} else {
-		s.ChannelMessageSend(m.ChannelID, resp.Content)
+		s.ChannelMessageSend(m.ChannelID, part)
+	}
+	b.sendAttachments(s, m.ChannelID, resp.Attachments)
+	b.sendVoiceReply(s, m.ChannelID, reply)
+}
+
+// sendAttachments uploads the files tools produced during the turn
+func (b *Bot) sendAttachments(s *discordgo.Session, channelID string, paths []string) {
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			b.logger.Warn("Failed to open attachment", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		_, err = s.ChannelFileSend(channelID, filepath.Base(path), f)
+		f.Close()
+		if err != nil {
+			b.logger.Warn("Failed to send attachment",
+				zap.String("channel_id", channelID),
+				zap.String("path", path),
+				zap.Error(err))
+		}
 	}
 }
 
+// sendVoiceReply uploads a spoken copy of a reply in channels that turned
+// voice replies on
+func (b *Bot) sendVoiceReply(s *discordgo.Session, channelID, reply string) {
+	if b.voice == nil || !b.voice.Enabled("discord", channelID) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	path, err := b.voice.VoiceNote(ctx, reply)
+	if err != nil {
+		b.logger.Warn("Failed to speak reply", zap.String("channel_id", channelID), zap.Error(err))
+		return
+	}
+	if path == "" {
+		return
+	}
+	defer os.Remove(path)
+	b.sendAttachments(s, channelID, []string{path})
+}
+
+// filterReply runs text through the content filter, if any, and logs what
+// it changed without repeating the filtered words
+func (b *Bot) filterReply(ctx context.Context, channelID, text string) string {
+	result := b.filter.Apply(text)
+	if !result.Filtered {
+		return text
+	}
+	b.logger.Warn("Reply filtered",
+		zap.String("channel_id", channelID),
+		zap.String("profile", b.filter.Profile()),
+		zap.Strings("categories", result.Categories),
+		zap.Bool("withheld", result.Blocked))
+	b.journal.Record(household.UserID(ctx), journal.KindFiltered, result.Summary(),
+		fmt.Sprintf("Discord channel %s, %s filter", channelID, b.filter.Profile()), nil)
+	return result.Text
+}
+
 // handleCommand handles bot commands
 func (b *Bot) handleCommand(s *discordgo.Session, m *discordgo.MessageCreate, cmd string) {
 	parts := strings.Fields(cmd)
@@ -197,15 +438,106 @@ func (b *Bot) handleCommand(s *discordgo.Session, m *discordgo.MessageCreate, cm
 Commands:
 • "/help" - Show this help
 • "/new" - Start new conversation
+• "/title <title>" - Rename this conversation
+• "/model fast|smart|auto" - Pick the model for this conversation
 • "/status" - Check bot status
 • "/ping" - Test latency
+• "/pin <text>" - Pin a fact (or "/pin file <name>", "/pin tool")
+• "/pins" - Show pinned items
+• "/unpin <number|all>" - Remove pinned items
+• "/alias add <name> <prompt>" - Create a shortcut (then use "/<name>")
+• "/alias list" - Show your aliases
+• "/whoami" - Show your household profile
+• "/household" - Show household members
+• "/invite <profile>" - Invite a member (owner only)
+• "/join <code>" - Link this account to a household profile
+• "/notifications" - Quiet hours, routing and digests
+• "/incident on [minutes]" - Verbose logging for troubleshooting (admin)
+• "/voice on|off" - Also reply with voice notes
 
 Or just mention me and ask anything!`
 		s.ChannelMessageSend(m.ChannelID, help)
 
 	case "/new":
+		b.clearConversationID(m.ChannelID)
 		s.ChannelMessageSend(m.ChannelID, "🆕 New conversation started!")
 
+	case "/title":
+		convID := b.getConversationID(m.ChannelID)
+		if convID == "" {
+			s.ChannelMessageSend(m.ChannelID, "❌ Nothing to title yet. Send a message first.")
+			return
+		}
+		title := strings.TrimSpace(strings.TrimPrefix(cmd, command))
+		if title == "" {
+			current := agent.DefaultTitle
+			if conv, err := b.store.GetConversation(convID); err == nil {
+				current = conv.Title
+			}
+			s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("💬 **%s**\nUse \"/title <new title>\" to rename it.", current))
+			return
+		}
+		if err := b.agent.RenameConversation(convID, title); err != nil {
+			s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+			return
+		}
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("✏️ Conversation renamed to **%s**", title))
+
+	case "/model":
+		convID := b.getConversationID(m.ChannelID)
+		route := strings.TrimSpace(strings.TrimPrefix(cmd, command))
+		if route == "" {
+			s.ChannelMessageSend(m.ChannelID, b.agent.DescribeRoute(convID)+"\nUse \"/model fast|smart|auto\" to change it.")
+			return
+		}
+		if convID == "" {
+			s.ChannelMessageSend(m.ChannelID, "❌ No conversation yet. Send a message first.")
+			return
+		}
+		if err := b.agent.SetConversationRoute(convID, route); err != nil {
+			s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+			return
+		}
+		s.ChannelMessageSend(m.ChannelID, "🔀 "+b.agent.DescribeRoute(convID))
+
+	case "/pin":
+		convID := b.getConversationID(m.ChannelID)
+		if convID == "" {
+			s.ChannelMessageSend(m.ChannelID, "❌ No active conversation yet. Send a message first, then pin.")
+			return
+		}
+		pin, err := b.agent.PinFromArgs(convID, strings.TrimSpace(strings.TrimPrefix(cmd, command)))
+		if err != nil {
+			s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+			return
+		}
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("📌 Pinned %s (%d tokens)", pin.Kind, pin.Tokens))
+
+	case "/pins":
+		var pins []store.Pin
+		if convID := b.getConversationID(m.ChannelID); convID != "" {
+			var err error
+			pins, err = b.agent.Pins(convID)
+			if err != nil {
+				b.logger.Error("Failed to list pins", zap.Error(err))
+				s.ChannelMessageSend(m.ChannelID, "❌ Failed to retrieve pinned items.")
+				return
+			}
+		}
+		s.ChannelMessageSend(m.ChannelID, agent.FormatPins(pins, b.agent.PinBudget()))
+
+	case "/unpin":
+		convID := b.getConversationID(m.ChannelID)
+		if len(parts) < 2 || convID == "" {
+			s.ChannelMessageSend(m.ChannelID, "Usage: /unpin <number|all>")
+			return
+		}
+		if err := b.agent.Unpin(convID, parts[1]); err != nil {
+			s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("❌ Pin %s not found.", parts[1]))
+			return
+		}
+		s.ChannelMessageSend(m.ChannelID, "✅ Unpinned.")
+
 	case "/status":
 		status := fmt.Sprintf("🟢 Online | Latency: %dms", s.HeartbeatLatency().Milliseconds())
 		s.ChannelMessageSend(m.ChannelID, status)
@@ -216,7 +548,57 @@ Or just mention me and ask anything!`
 		latency := time.Since(start).Milliseconds()
 		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("Latency: %dms", latency))
 
+	case "/alias":
+		if b.aliases == nil {
+			s.ChannelMessageSend(m.ChannelID, "❌ Aliases not available - database not connected.")
+			return
+		}
+		s.ChannelMessageSend(m.ChannelID, b.aliases.Command(aliasUser(m), strings.TrimSpace(strings.TrimPrefix(cmd, command))))
+
+	case "/whoami", "/household", "/invite", "/join":
+		if b.household == nil {
+			s.ChannelMessageSend(m.ChannelID, "❌ Household profiles not available - database not connected.")
+			return
+		}
+		s.ChannelMessageSend(m.ChannelID, b.household.Command("discord", m.Author.ID, strings.TrimPrefix(command, "/"), strings.TrimSpace(strings.TrimPrefix(cmd, command))))
+
+	case "/notifications":
+		if b.notifier == nil {
+			s.ChannelMessageSend(m.ChannelID, "❌ Notifications not available - server not running.")
+			return
+		}
+		userID := household.UserID(b.profileContext(m))
+		s.ChannelMessageSend(m.ChannelID, b.notifier.Preferences().Command(userID, strings.TrimSpace(strings.TrimPrefix(cmd, command))))
+
+	case "/incident":
+		if b.incident == nil {
+			s.ChannelMessageSend(m.ChannelID, "❌ Incident mode not available - server not running.")
+			return
+		}
+		if !b.isAdmin(m) {
+			s.ChannelMessageSend(m.ChannelID, "❌ Only the household owner can use /incident.")
+			return
+		}
+		s.ChannelMessageSend(m.ChannelID, b.incident.Command(strings.TrimSpace(strings.TrimPrefix(cmd, command))))
+
+	case "/voice":
+		if b.voice == nil {
+			s.ChannelMessageSend(m.ChannelID, "🔇 Voice replies aren't enabled. Set skills.voice.replies in the config.")
+			return
+		}
+		s.ChannelMessageSend(m.ChannelID, b.voice.Command("discord", m.ChannelID, strings.TrimSpace(strings.TrimPrefix(cmd, command))))
+
 	default:
+		if b.aliases != nil {
+			text, ok, err := b.aliases.Resolve(aliasUser(m), strings.TrimPrefix(command, "/"), parts[1:])
+			if err != nil {
+				b.logger.Error("Failed to resolve alias", zap.Error(err))
+			} else if ok {
+				b.chat(s, m, text)
+				return
+			}
+		}
+
 		// Unknown command, treat as normal message
 		// Re-process without the command prefix
 		content := strings.TrimPrefix(cmd, command)
@@ -228,6 +610,98 @@ Or just mention me and ask anything!`
 	}
 }
 
+// aliasUser returns the alias owner ID for the author of a message
+func aliasUser(m *discordgo.MessageCreate) string {
+	if m.Author == nil {
+		return aliases.DefaultUser
+	}
+	return "discord:" + m.Author.ID
+}
+
+// isHouseholdMember reports whether the author may use the bot. Everyone may
+// while no household profiles exist; /help and /join are always open.
+func (b *Bot) isHouseholdMember(m *discordgo.MessageCreate, content string) bool {
+	if b.household == nil || m.Author == nil || !b.household.Active() {
+		return true
+	}
+	if fields := strings.Fields(content); len(fields) > 0 && (fields[0] == "/help" || fields[0] == "/join") {
+		return true
+	}
+	profile, err := b.household.ForChannel("discord", m.Author.ID)
+	return err == nil && profile != nil
+}
+
+// isAdmin reports whether the author may run admin commands: the household
+// owner once profiles exist, otherwise anyone
+func (b *Bot) isAdmin(m *discordgo.MessageCreate) bool {
+	if b.household == nil || !b.household.Active() {
+		return true
+	}
+	profile := household.FromContext(b.profileContext(m))
+	return profile != nil && profile.IsOwner()
+}
+
+// profileContext returns a context carrying the bot's persona and the
+// author's household profile, if any
+func (b *Bot) profileContext(m *discordgo.MessageCreate) context.Context {
+	ctx := persona.WithPersona(context.Background(), b.persona)
+	if b.household == nil || m.Author == nil {
+		return ctx
+	}
+	profile, err := b.household.ForChannel("discord", m.Author.ID)
+	if err != nil {
+		b.logger.Warn("Failed to load household profile", zap.Error(err))
+		return ctx
+	}
+	return household.WithProfile(ctx, profile)
+}
+
+// chatKey converts a Discord channel snowflake into the numeric chat ID used
+// by the store's chat mappings
+func chatKey(channelID string) (int64, bool) {
+	id, err := strconv.ParseInt(channelID, 10, 64)
+	return id, err == nil
+}
+
+// getConversationID returns the active conversation for a channel
+func (b *Bot) getConversationID(channelID string) string {
+	id, ok := chatKey(channelID)
+	if !ok || b.store == nil {
+		return ""
+	}
+	mapping, err := b.store.GetChatMapping(id, "discord")
+	if err != nil {
+		return ""
+	}
+	return mapping.ConversationID
+}
+
+// setConversationID persists the active conversation for a channel
+func (b *Bot) setConversationID(channelID, convID string) {
+	id, ok := chatKey(channelID)
+	if !ok || b.store == nil || convID == "" {
+		return
+	}
+	if err := b.store.SetChatMapping(id, "discord", convID); err != nil {
+		b.logger.Warn("Failed to persist conversation mapping",
+			zap.Error(err),
+			zap.String("channel_id", channelID))
+	}
+}
+
+// clearConversationID ends the active conversation for a channel
+func (b *Bot) clearConversationID(channelID string) {
+	id, ok := chatKey(channelID)
+	if !ok || b.store == nil {
+		return
+	}
+	if err := b.store.DeactivateChatMapping(id, "discord"); err != nil {
+		b.logger.Warn("Failed to deactivate conversation mapping",
+			zap.Error(err),
+			zap.String("channel_id", channelID))
+	}
+}
+
 // splitMessage splits a message into chunks under max length
 func splitMessage(text string, maxLen int) []string {
 	var parts []string
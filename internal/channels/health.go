@@ -0,0 +1,79 @@
+// Package channels holds what the chat channels have in common. Each
+// channel lives in its own package below this one.
+package channels
+
+import (
+	"sync"
+	"time"
+)
+
+// Health is how a channel is doing, as shown on the admin endpoints
+type Health struct {
+	Name        string     `json:"name"`
+	Connected   bool       `json:"connected"`
+	Since       *time.Time `json:"since,omitempty"`        // when it last connected or disconnected
+	LastMessage *time.Time `json:"last_message,omitempty"` // when it last received a message
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// Tracker keeps a channel's health up to date. The zero value is a channel
+// that hasn't connected yet.
+type Tracker struct {
+	mu     sync.Mutex
+	health Health
+}
+
+// Connected records that the channel is connected
+func (t *Tracker) Connected() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.health.Connected {
+		now := time.Now()
+		t.health.Connected = true
+		t.health.Since = &now
+	}
+}
+
+// Disconnected records that the channel lost its connection, because of
+// err if it isn't nil
+func (t *Tracker) Disconnected(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	if t.health.Connected || t.health.Since == nil {
+		t.health.Connected = false
+		t.health.Since = &now
+	}
+	if err != nil {
+		t.health.LastError = err.Error()
+		t.health.LastErrorAt = &now
+	}
+}
+
+// Received records that a message came in
+func (t *Tracker) Received() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.health.LastMessage = &now
+}
+
+// Failed records an error handling a message, which leaves the connection
+// as it is
+func (t *Tracker) Failed(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.health.LastError = err.Error()
+	t.health.LastErrorAt = &now
+}
+
+// Health returns the channel's health under name
+func (t *Tracker) Health(name string) Health {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.health
+	h.Name = name
+	return h
+}
@@ -0,0 +1,44 @@
+package channels
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTracker(t *testing.T) {
+	var tracker Tracker
+
+	h := tracker.Health("telegram")
+	if h.Name != "telegram" || h.Connected || h.Since != nil {
+		t.Fatalf("Expected a channel that hasn't connected, got %+v", h)
+	}
+
+	tracker.Connected()
+	since := tracker.Health("telegram").Since
+	tracker.Connected()
+	if h := tracker.Health("telegram"); !h.Connected || h.Since != since {
+		t.Errorf("Expected connected since the first call, got %+v", h)
+	}
+
+	tracker.Received()
+	tracker.Failed(errors.New("reply failed"))
+	h = tracker.Health("telegram")
+	if !h.Connected || h.LastMessage == nil || h.LastError != "reply failed" {
+		t.Errorf("Expected a connected channel with the message and error recorded, got %+v", h)
+	}
+
+	tracker.Disconnected(errors.New("connection reset"))
+	h = tracker.Health("telegram")
+	if h.Connected || h.Since == since || h.LastError != "connection reset" {
+		t.Errorf("Expected a disconnected channel with the new error, got %+v", h)
+	}
+}
+
+func TestTracker_DisconnectedBeforeConnecting(t *testing.T) {
+	var tracker Tracker
+	tracker.Disconnected(errors.New("bad token"))
+	h := tracker.Health("discord")
+	if h.Connected || h.Since == nil || h.LastError != "bad token" {
+		t.Errorf("Expected a failed start to be recorded, got %+v", h)
+	}
+}
@@ -0,0 +1,234 @@
+// Package inbound screens messages arriving on chat channels before they
+// reach the agent: per-user and per-chat rate limits with a cooldown, a cap
+// on message size, and an allow list of attachment types. It keeps a noisy
+// group chat from running up the LLM bill.
+package inbound
+
+import (
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+// Reasons a message is turned away
+const (
+	ReasonRateLimit  = "rate_limit"
+	ReasonCooldown   = "cooldown"
+	ReasonTooLong    = "too_long"
+	ReasonAttachment = "attachment"
+)
+
+// window is the span the per-minute limits count messages over
+const window = time.Minute
+
+// Message is what the guard sees of an incoming message
+type Message struct {
+	ChatID      string
+	UserID      string
+	Text        string // the text or caption
+	Attachments []Attachment
+}
+
+// Attachment describes a file sent with a message
+type Attachment struct {
+	Name     string
+	MimeType string
+}
+
+// Verdict is the guard's decision on a message
+type Verdict struct {
+	Allowed bool
+	Reason  string // why it was turned away
+	Notice  string // a reply for the sender; empty to stay quiet
+}
+
+// Guard applies a channel's inbound limits. A nil *Guard lets every message
+// through.
+type Guard struct {
+	mu        sync.Mutex
+	cfg       config.InboundConfig
+	users     map[string]*sender
+	chats     map[string]*sender
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// sender is the recent traffic of one user or chat
+type sender struct {
+	times         []time.Time
+	cooldownUntil time.Time
+}
+
+// New returns a guard enforcing cfg
+func New(cfg config.InboundConfig) *Guard {
+	return &Guard{
+		cfg:   cfg,
+		users: make(map[string]*sender),
+		chats: make(map[string]*sender),
+		now:   time.Now,
+	}
+}
+
+// SetLimits replaces the guard's limits, keeping the traffic it has seen
+func (g *Guard) SetLimits(cfg config.InboundConfig) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cfg = cfg
+}
+
+// Check decides whether m may reach the agent. Every message counts toward
+// the rate limits, including ones turned away for their size.
+func (g *Guard) Check(m Message) Verdict {
+	if g == nil {
+		return Verdict{Allowed: true}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	g.sweep(now)
+
+	if v, limited := g.limit(g.users, m.UserID, g.cfg.MessagesPerMinute, now,
+		"🐢 You're sending messages faster than I can keep up. I'll answer again in %s."); limited {
+		return v
+	}
+	if m.ChatID != "" {
+		if v, limited := g.limit(g.chats, m.ChatID, g.cfg.ChatMessagesPerMinute, now,
+			"🐢 This chat is sending messages faster than I can keep up. I'll answer again in %s."); limited {
+			return v
+		}
+	}
+
+	if max := g.cfg.MaxMessageChars; max > 0 && utf8.RuneCountInString(m.Text) > max {
+		return Verdict{
+			Reason: ReasonTooLong,
+			Notice: fmt.Sprintf("✂️ That message is too long for me. Please keep it under %d characters.", max),
+		}
+	}
+
+	for _, a := range m.Attachments {
+		if !attachmentAllowed(g.cfg.AllowedAttachments, a) {
+			return Verdict{
+				Reason: ReasonAttachment,
+				Notice: fmt.Sprintf("📎 Sorry, I can't accept %s files here.", describe(a)),
+			}
+		}
+	}
+
+	return Verdict{Allowed: true}
+}
+
+// limit counts a message from key against perMinute. Going over starts a
+// cooldown, announced once with notice; messages during it are dropped
+// quietly so the bot doesn't add to the flood.
+func (g *Guard) limit(senders map[string]*sender, key string, perMinute int, now time.Time, notice string) (Verdict, bool) {
+	if perMinute <= 0 || key == "" {
+		return Verdict{}, false
+	}
+	s := senders[key]
+	if s == nil {
+		s = &sender{}
+		senders[key] = s
+	}
+	if now.Before(s.cooldownUntil) {
+		return Verdict{Reason: ReasonCooldown}, true
+	}
+
+	s.times = recent(s.times, now)
+	if len(s.times) >= perMinute {
+		cooldown := time.Duration(g.cfg.CooldownSeconds) * time.Second
+		if cooldown <= 0 {
+			cooldown = window
+		}
+		s.cooldownUntil = now.Add(cooldown)
+		s.times = nil
+		return Verdict{Reason: ReasonRateLimit, Notice: fmt.Sprintf(notice, formatWait(cooldown))}, true
+	}
+	s.times = append(s.times, now)
+	return Verdict{}, false
+}
+
+// sweep forgets senders that have been quiet for a window, at most once a
+// window
+func (g *Guard) sweep(now time.Time) {
+	if now.Sub(g.lastSweep) < window {
+		return
+	}
+	g.lastSweep = now
+	for _, senders := range []map[string]*sender{g.users, g.chats} {
+		for key, s := range senders {
+			if len(recent(s.times, now)) == 0 && !now.Before(s.cooldownUntil) {
+				delete(senders, key)
+			}
+		}
+	}
+}
+
+// recent drops the times older than the window
+func recent(times []time.Time, now time.Time) []time.Time {
+	i := 0
+	for i < len(times) && now.Sub(times[i]) >= window {
+		i++
+	}
+	return times[i:]
+}
+
+// attachmentAllowed reports whether a matches the allow list of MIME types
+// ("application/pdf"), type families ("image/*") and extensions (".pdf").
+// An empty list allows everything.
+func attachmentAllowed(allow []string, a Attachment) bool {
+	if len(allow) == 0 {
+		return true
+	}
+	mimeType := strings.ToLower(a.MimeType)
+	if i := strings.IndexByte(mimeType, ';'); i >= 0 {
+		mimeType = strings.TrimSpace(mimeType[:i])
+	}
+	ext := strings.ToLower(filepath.Ext(a.Name))
+	if mimeType == "" && ext != "" {
+		mimeType, _, _ = strings.Cut(mime.TypeByExtension(ext), ";")
+	}
+	for _, entry := range allow {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case strings.HasPrefix(entry, "."):
+			if ext == entry {
+				return true
+			}
+		case strings.HasSuffix(entry, "/*"):
+			if strings.HasPrefix(mimeType, strings.TrimSuffix(entry, "*")) {
+				return true
+			}
+		case entry != "" && entry == mimeType:
+			return true
+		}
+	}
+	return false
+}
+
+// describe names an attachment's type for a notice
+func describe(a Attachment) string {
+	if ext := filepath.Ext(a.Name); ext != "" {
+		return strings.ToLower(ext)
+	}
+	if a.MimeType != "" {
+		return a.MimeType
+	}
+	return "these"
+}
+
+func formatWait(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%d seconds", int(d.Seconds()))
+	}
+	if minutes := int(d.Round(time.Minute).Minutes()); minutes > 1 {
+		return fmt.Sprintf("%d minutes", minutes)
+	}
+	return "a minute"
+}
@@ -0,0 +1,100 @@
+package inbound
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+func newTestGuard(cfg config.InboundConfig) (*Guard, *time.Time) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	g := New(cfg)
+	g.now = func() time.Time { return now }
+	return g, &now
+}
+
+func TestGuard_RateLimitAndCooldown(t *testing.T) {
+	g, now := newTestGuard(config.InboundConfig{MessagesPerMinute: 3, CooldownSeconds: 30})
+	msg := Message{ChatID: "c1", UserID: "u1", Text: "hi"}
+
+	for i := 0; i < 3; i++ {
+		if v := g.Check(msg); !v.Allowed {
+			t.Fatalf("Expected message %d to be allowed, got %+v", i+1, v)
+		}
+	}
+	v := g.Check(msg)
+	if v.Allowed || v.Reason != ReasonRateLimit || !strings.Contains(v.Notice, "30 seconds") {
+		t.Errorf("Expected the fourth message to start a cooldown, got %+v", v)
+	}
+	if v := g.Check(msg); v.Allowed || v.Reason != ReasonCooldown || v.Notice != "" {
+		t.Errorf("Expected a quiet drop during the cooldown, got %+v", v)
+	}
+	if v := g.Check(Message{ChatID: "c1", UserID: "u2"}); !v.Allowed {
+		t.Errorf("Expected other users to be unaffected, got %+v", v)
+	}
+
+	*now = now.Add(31 * time.Second)
+	if v := g.Check(msg); !v.Allowed {
+		t.Errorf("Expected messages again after the cooldown, got %+v", v)
+	}
+}
+
+func TestGuard_ChatLimit(t *testing.T) {
+	g, now := newTestGuard(config.InboundConfig{ChatMessagesPerMinute: 2})
+	for _, user := range []string{"u1", "u2"} {
+		if v := g.Check(Message{ChatID: "group", UserID: user}); !v.Allowed {
+			t.Fatalf("Expected %s to be allowed, got %+v", user, v)
+		}
+	}
+	v := g.Check(Message{ChatID: "group", UserID: "u3"})
+	if v.Allowed || !strings.Contains(v.Notice, "This chat") || !strings.Contains(v.Notice, "a minute") {
+		t.Errorf("Expected the group's third message to be turned away, got %+v", v)
+	}
+
+	// The sweep forgets quiet senders
+	*now = now.Add(2 * time.Minute)
+	g.Check(Message{ChatID: "other", UserID: "u9"})
+	if len(g.chats) != 1 {
+		t.Errorf("Expected only the active chat to be kept, got %d", len(g.chats))
+	}
+}
+
+func TestGuard_SizeAndAttachments(t *testing.T) {
+	g, _ := newTestGuard(config.InboundConfig{
+		MaxMessageChars:    5,
+		AllowedAttachments: []string{"image/*", ".pdf"},
+	})
+
+	if v := g.Check(Message{UserID: "u1", Text: "héllo"}); !v.Allowed {
+		t.Errorf("Expected five characters to fit, got %+v", v)
+	}
+	if v := g.Check(Message{UserID: "u1", Text: "héllo!"}); v.Allowed || v.Reason != ReasonTooLong {
+		t.Errorf("Expected six characters to be too long, got %+v", v)
+	}
+
+	tests := []struct {
+		attachment Attachment
+		allowed    bool
+	}{
+		{Attachment{Name: "photo.jpg", MimeType: "image/jpeg"}, true},
+		{Attachment{Name: "report.PDF", MimeType: "application/pdf"}, true},
+		{Attachment{Name: "scan.png"}, true},
+		{Attachment{Name: "setup.exe", MimeType: "application/x-msdownload"}, false},
+		{Attachment{MimeType: "audio/ogg; codecs=opus"}, false},
+	}
+	for _, tt := range tests {
+		v := g.Check(Message{UserID: "u1", Attachments: []Attachment{tt.attachment}})
+		if v.Allowed != tt.allowed {
+			t.Errorf("%+v: allowed = %t, want %t (%s)", tt.attachment, v.Allowed, tt.allowed, v.Notice)
+		}
+	}
+}
+
+func TestGuard_Nil(t *testing.T) {
+	var g *Guard
+	if v := g.Check(Message{UserID: "u1", Text: strings.Repeat("x", 10000)}); !v.Allowed {
+		t.Errorf("Expected a nil guard to allow everything, got %+v", v)
+	}
+}
@@ -2,19 +2,36 @@ package telegram
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/aliases"
+	"github.com/gmsas95/myrai-cli/internal/channels"
+	"github.com/gmsas95/myrai-cli/internal/channels/inbound"
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/incident"
+	"github.com/gmsas95/myrai-cli/internal/journal"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"github.com/gmsas95/myrai-cli/internal/persona"
 	"github.com/gmsas95/myrai-cli/internal/security"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/skills/documents"
+	"github.com/gmsas95/myrai-cli/internal/skills/kb"
+	"github.com/gmsas95/myrai-cli/internal/skills/voice"
 	"github.com/gmsas95/myrai-cli/internal/store"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
@@ -31,17 +48,42 @@ type Bot struct {
 	wg        sync.WaitGroup
 	enabled   bool
 	allowList map[int64]bool // Allowed user IDs
-	// Track conversations per chat
-	conversations map[int64]string // chatID -> conversationID
+	allowMu   sync.RWMutex
+	aliases   *aliases.Manager
+	household *household.Manager
+	notifier  *notify.Dispatcher
+	incident  *incident.Mode
+	filter    *security.ContentFilter
+	journal   *journal.Journal
+	persona   string // answered as instead of the current persona
+	kb        *kb.Store
+	kbScope   skills.ContextHook
+	voice     *voice.Replier
+	inbound   *inbound.Guard // Rate limits and caps for incoming messages
+	groupMode string         // Which group messages to answer: mention, all or off
+	health    channels.Tracker
+	mention   *regexp.Regexp // The bot's @username
+	// Forum topic of the message being handled, by chat
+	topics  map[int64]int
+	topicMu sync.RWMutex
+	// Track conversations per chat and forum topic
+	conversations map[chatTopic]string
 	convMu        sync.RWMutex
 }
 
+// chatTopic identifies a chat, or a forum topic within one
+type chatTopic struct {
+	chatID int64
+	topic  int
+}
+
 // Config holds Telegram bot configuration
 type Config struct {
 	Token      string
 	Enabled    bool
 	AllowList  []int64 // List of allowed user IDs (empty = allow all)
 	WebhookURL string  // Optional webhook URL (empty = use polling)
+	GroupMode  string  // Which group messages to answer: mention (default), all or off
 }
 
 // NewBot creates a new Telegram bot
@@ -60,12 +102,7 @@ func NewBot(cfg Config, agent *agent.Agent, store *store.Store, logger *zap.Logg
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	allowList := make(map[int64]bool)
-	for _, id := range cfg.AllowList {
-		allowList[id] = true
-	}
-
-	return &Bot{
+	bot := &Bot{
 		api:           api,
 		agent:         agent,
 		store:         store,
@@ -73,9 +110,149 @@ func NewBot(cfg Config, agent *agent.Agent, store *store.Store, logger *zap.Logg
 		ctx:           ctx,
 		cancel:        cancel,
 		enabled:       true,
-		allowList:     allowList,
-		conversations: make(map[int64]string),
-	}, nil
+		groupMode:     cfg.GroupMode,
+		mention:       mentionPattern(api.Self.UserName),
+		topics:        make(map[int64]int),
+		conversations: make(map[chatTopic]string),
+	}
+
+	bot.SetAllowList(cfg.AllowList)
+
+	if store != nil {
+		if mgr, err := aliases.NewManager(store.DB()); err == nil {
+			bot.aliases = mgr
+		} else {
+			logger.Warn("Aliases unavailable", zap.Error(err))
+		}
+		if mgr, err := household.NewManager(store.DB()); err == nil {
+			bot.household = mgr
+		} else {
+			logger.Warn("Household profiles unavailable", zap.Error(err))
+		}
+	}
+
+	return bot, nil
+}
+
+// SetNotifier makes the bot a delivery channel for notifications and lets
+// users adjust their preferences with /notifications
+func (b *Bot) SetNotifier(d *notify.Dispatcher) {
+	if !b.enabled {
+		return
+	}
+	b.notifier = d
+	d.Register("telegram", b)
+}
+
+// SetAllowList replaces the users allowed to use the bot; an empty list
+// allows everyone
+func (b *Bot) SetAllowList(ids []int64) {
+	allowList := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		allowList[id] = true
+	}
+	b.allowMu.Lock()
+	b.allowList = allowList
+	b.allowMu.Unlock()
+}
+
+// allowed reports whether the allow list lets a user use the bot
+func (b *Bot) allowed(userID int64) bool {
+	b.allowMu.RLock()
+	defer b.allowMu.RUnlock()
+	return len(b.allowList) == 0 || b.allowList[userID]
+}
+
+// SetInboundLimits screens incoming messages with cfg's rate limits, size
+// cap and attachment types. Called again, it replaces the limits.
+func (b *Bot) SetInboundLimits(cfg config.InboundConfig) {
+	if b.inbound == nil {
+		b.inbound = inbound.New(cfg)
+		return
+	}
+	b.inbound.SetLimits(cfg)
+}
+
+// SetIncidentMode lets admins toggle incident mode with /incident
+func (b *Bot) SetIncidentMode(mode *incident.Mode) {
+	b.incident = mode
+}
+
+// SetContentFilter screens every reply and notification with filter before
+// it is sent. Filtered replies are logged and recorded in j.
+func (b *Bot) SetContentFilter(filter *security.ContentFilter, j *journal.Journal) {
+	b.filter = filter
+	b.journal = j
+}
+
+// SetPersona makes the bot answer as the named persona instead of the
+// current one; "" keeps the current one
+func (b *Bot) SetPersona(name string) {
+	b.persona = name
+}
+
+// SetKnowledgeBase keeps uploaded documents in kb so they can be asked about
+// later. scope gives the context the kb skill would see, so uploads land
+// where the skill looks for them.
+func (b *Bot) SetKnowledgeBase(store *kb.Store, scope skills.ContextHook) {
+	b.kb = store
+	b.kbScope = scope
+}
+
+// SetVoiceReplies lets chats turn on spoken replies with /voice
+func (b *Bot) SetVoiceReplies(r *voice.Replier) {
+	b.voice = r
+}
+
+// Deliver sends a notification to a chat
+func (b *Bot) Deliver(ctx context.Context, target, text string) error {
+	chatID, err := strconv.ParseInt(target, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid telegram chat: %s", target)
+	}
+	_, err = b.sendMessage(chatID, b.filterReply(ctx, chatID, text))
+	return err
+}
+
+// filterReply runs text through the content filter, if any, and logs what
+// it changed without repeating the filtered words
+func (b *Bot) filterReply(ctx context.Context, chatID int64, text string) string {
+	result := b.filter.Apply(text)
+	if !result.Filtered {
+		return text
+	}
+	b.logger.Warn("Reply filtered",
+		zap.Int64("chat_id", chatID),
+		zap.String("profile", b.filter.Profile()),
+		zap.Strings("categories", result.Categories),
+		zap.Bool("withheld", result.Blocked))
+	b.journal.Record(household.UserID(ctx), journal.KindFiltered, result.Summary(),
+		fmt.Sprintf("Telegram chat %d, %s filter", chatID, b.filter.Profile()), nil)
+	return result.Text
+}
+
+// sendReply sends the agent's reply as its post-processed parts, returning
+// the text delivered. A reply the content filter withholds is replaced by the
+// filter's notice as a whole.
+func (b *Bot) sendReply(ctx context.Context, chatID int64, resp *agent.ChatResponse) (string, error) {
+	reply := b.filterReply(ctx, chatID, resp.Content)
+	parts := resp.Parts
+	switch {
+	case reply == resp.Content:
+	case reply == security.FamilyBlockedReply:
+		parts = []string{reply}
+	default:
+		parts = make([]string, len(resp.Parts))
+		for i, part := range resp.Parts {
+			parts[i] = b.filter.Apply(part).Text
+		}
+	}
+	for _, part := range parts {
+		if _, err := b.sendMessage(chatID, part); err != nil {
+			return reply, err
+		}
+	}
+	return reply, nil
 }
 
 // Start starts the bot
@@ -103,10 +280,7 @@ func (b *Bot) Stop() {
 func (b *Bot) run() {
 	defer b.wg.Done()
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-
-	updates := b.api.GetUpdatesChan(u)
+	updates := b.pollUpdates()
 
 	for {
 		select {
@@ -116,28 +290,140 @@ func (b *Bot) run() {
 			if !ok {
 				return
 			}
-			if err := b.handleUpdate(update); err != nil {
+			b.health.Received()
+			if err := b.handleUpdate(update.Update, update.topic); err != nil {
 				b.logger.Error("Failed to handle update", zap.Error(err))
+				b.health.Failed(err)
 			}
 		}
 	}
 }
 
-func (b *Bot) handleUpdate(update tgbotapi.Update) error {
+// topicUpdate is an update with the forum topic its message was posted in,
+// which the Bot API library predates
+type topicUpdate struct {
+	tgbotapi.Update
+	topic int
+}
+
+// pollUpdates long-polls getUpdates until the bot stops
+func (b *Bot) pollUpdates() <-chan topicUpdate {
+	ch := make(chan topicUpdate, 100)
+	go func() {
+		defer close(ch)
+		offset := 0
+		for b.ctx.Err() == nil {
+			params := tgbotapi.Params{}
+			params.AddNonZero("offset", offset)
+			params.AddNonZero("timeout", 60)
+			resp, err := b.api.MakeRequest("getUpdates", params)
+			var updates []topicUpdate
+			if err == nil {
+				updates, err = decodeUpdates(resp.Result)
+			}
+			if err != nil {
+				b.logger.Warn("Failed to get updates, retrying in 3 seconds", zap.Error(err))
+				b.health.Disconnected(err)
+				select {
+				case <-b.ctx.Done():
+					return
+				case <-time.After(3 * time.Second):
+				}
+				continue
+			}
+			b.health.Connected()
+			for _, update := range updates {
+				if update.UpdateID >= offset {
+					offset = update.UpdateID + 1
+				}
+				select {
+				case ch <- update:
+				case <-b.ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch
+}
+
+// decodeUpdates decodes a getUpdates result, reading each message's forum
+// topic alongside the library's types
+func decodeUpdates(data []byte) ([]topicUpdate, error) {
+	var updates []tgbotapi.Update
+	if err := json.Unmarshal(data, &updates); err != nil {
+		return nil, err
+	}
+	var topics []struct {
+		Message *struct {
+			ThreadID int  `json:"message_thread_id"`
+			IsTopic  bool `json:"is_topic_message"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(data, &topics); err != nil {
+		return nil, err
+	}
+	out := make([]topicUpdate, len(updates))
+	for i, update := range updates {
+		out[i].Update = update
+		if i < len(topics) && topics[i].Message != nil && topics[i].Message.IsTopic {
+			out[i].topic = topics[i].Message.ThreadID
+		}
+	}
+	return out, nil
+}
+
+func (b *Bot) handleUpdate(update tgbotapi.Update, topic int) error {
 	// Handle messages
-	if update.Message == nil {
+	if update.Message == nil || update.Message.From == nil {
 		return nil
 	}
 
 	msg := update.Message
 	userID := msg.From.ID
 
+	// In groups, answer only the messages meant for the bot
+	if !msg.Chat.IsPrivate() && !b.addressedToBot(msg) {
+		return nil
+	}
+
+	// Replies and the conversation belong to the message's forum topic
+	b.setTopic(msg.Chat.ID, topic)
+	defer b.setTopic(msg.Chat.ID, 0)
+
 	// Check allowlist
-	if len(b.allowList) > 0 && !b.allowList[userID] {
+	if !b.allowed(userID) {
 		b.sendMessage(msg.Chat.ID, "⛔ You are not authorized to use this bot.")
 		return nil
 	}
 
+	// Turn away floods, oversized messages and unwanted files
+	if verdict := b.inbound.Check(inboundMessage(msg)); !verdict.Allowed {
+		b.logger.Info("Inbound message rejected",
+			zap.Int64("chat_id", msg.Chat.ID),
+			zap.Int64("user_id", userID),
+			zap.String("reason", verdict.Reason))
+		if verdict.Notice != "" {
+			_, err := b.sendMessage(msg.Chat.ID, verdict.Notice)
+			return err
+		}
+		return nil
+	}
+
+	// Once a household is set up, only linked members may chat
+	if !b.isHouseholdMember(msg) {
+		_, err := b.sendMessage(msg.Chat.ID, "🏠 This assistant belongs to a household. Ask the owner for an invite, then send /join <code>.")
+		return err
+	}
+
+	// Private chats are where notifications for this person can go
+	if b.notifier != nil && msg.Chat.IsPrivate() {
+		userID := household.UserID(b.profileContext(msg))
+		if err := b.notifier.Remember(userID, "telegram", strconv.FormatInt(msg.Chat.ID, 10)); err != nil {
+			b.logger.Warn("Failed to remember notification address", zap.Error(err))
+		}
+	}
+
 	// Handle commands
 	if msg.IsCommand() {
 		return b.handleCommand(msg)
@@ -166,6 +452,87 @@ func (b *Bot) handleUpdate(update tgbotapi.Update) error {
 	return nil
 }
 
+// addressedToBot reports whether a group message is meant for the bot under
+// its group mode. In mention mode that's a mention of the bot, which is
+// removed from the text, a reply to one of its messages, or a command not
+// addressed to another bot.
+func (b *Bot) addressedToBot(msg *tgbotapi.Message) bool {
+	switch b.groupMode {
+	case config.GroupModeOff:
+		return false
+	case config.GroupModeAll:
+		return true
+	}
+
+	if msg.IsCommand() {
+		_, at, addressed := strings.Cut(msg.CommandWithAt(), "@")
+		return !addressed || strings.EqualFold(at, b.api.Self.UserName)
+	}
+	if reply := msg.ReplyToMessage; reply != nil && reply.From != nil && reply.From.ID == b.api.Self.ID {
+		return true
+	}
+	for _, entity := range append(msg.Entities, msg.CaptionEntities...) {
+		if entity.Type == "text_mention" && entity.User != nil && entity.User.ID == b.api.Self.ID {
+			return true
+		}
+	}
+	if b.mention.MatchString(msg.Text) || b.mention.MatchString(msg.Caption) {
+		msg.Text = strings.TrimSpace(b.mention.ReplaceAllString(msg.Text, ""))
+		msg.Caption = strings.TrimSpace(b.mention.ReplaceAllString(msg.Caption, ""))
+		return true
+	}
+	return false
+}
+
+// mentionPattern matches @username in any case
+func mentionPattern(username string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)@` + regexp.QuoteMeta(username) + `\b`)
+}
+
+// setTopic records the forum topic of the message being handled in a chat,
+// so replies and the conversation go to that topic; 0 clears it
+func (b *Bot) setTopic(chatID int64, topic int) {
+	b.topicMu.Lock()
+	defer b.topicMu.Unlock()
+	if topic == 0 {
+		delete(b.topics, chatID)
+		return
+	}
+	b.topics[chatID] = topic
+}
+
+// topic returns the forum topic being answered in a chat, 0 for none
+func (b *Bot) topic(chatID int64) int {
+	b.topicMu.RLock()
+	defer b.topicMu.RUnlock()
+	return b.topics[chatID]
+}
+
+// inboundMessage describes msg to the inbound guard
+func inboundMessage(msg *tgbotapi.Message) inbound.Message {
+	m := inbound.Message{
+		ChatID: strconv.FormatInt(msg.Chat.ID, 10),
+		UserID: strconv.FormatInt(msg.From.ID, 10),
+		Text:   msg.Text,
+	}
+	if m.Text == "" {
+		m.Text = msg.Caption
+	}
+	switch {
+	case len(msg.Photo) > 0:
+		m.Attachments = append(m.Attachments, inbound.Attachment{Name: "photo.jpg", MimeType: "image/jpeg"})
+	case msg.Document != nil:
+		m.Attachments = append(m.Attachments, inbound.Attachment{Name: msg.Document.FileName, MimeType: msg.Document.MimeType})
+	case msg.Voice != nil:
+		m.Attachments = append(m.Attachments, inbound.Attachment{Name: "voice.ogg", MimeType: msg.Voice.MimeType})
+	case msg.Audio != nil:
+		m.Attachments = append(m.Attachments, inbound.Attachment{Name: msg.Audio.FileName, MimeType: msg.Audio.MimeType})
+	case msg.Video != nil:
+		m.Attachments = append(m.Attachments, inbound.Attachment{Name: msg.Video.FileName, MimeType: msg.Video.MimeType})
+	}
+	return m
+}
+
 func (b *Bot) handleCommand(msg *tgbotapi.Message) error {
 	chatID := msg.Chat.ID
 
@@ -193,8 +560,22 @@ Just send me a message!`)
 /new - Start new conversation
 /history - Show conversation history
 /resume <number> - Resume a previous conversation
+/title <title> - Rename this conversation
+/model fast|smart|auto - Pick the model for this conversation
 /documents - Show all uploaded documents
 /skills - Show all available skills
+/pin <text> - Pin a fact (or /pin file <name>, /pin tool)
+/pins - Show pinned items
+/unpin <number|all> - Remove pinned items
+/alias add <name> <prompt> - Create a shortcut (then use /<name>)
+/alias list - Show your aliases
+/whoami - Show your household profile
+/household - Show household members
+/invite <profile> - Invite a member (owner only)
+/join <code> - Link this account to a household profile
+/notifications - Quiet hours, routing and digests
+/incident on [minutes] - Verbose logging for troubleshooting (admin)
+/voice on|off - Also reply with voice notes
 /status - Show bot status
 
 *Features:*
@@ -219,6 +600,12 @@ Just chat naturally or ask me to:
 	case "resume":
 		return b.handleResumeCommand(msg)
 
+	case "title":
+		return b.handleTitleCommand(msg)
+
+	case "model":
+		return b.handleModelCommand(msg)
+
 	case "status":
 		_, err := b.sendMessage(chatID, "✅ Bot is running and ready!")
 		return err
@@ -226,7 +613,7 @@ Just chat naturally or ask me to:
 	case "restart":
 		// Clear all conversations
 		b.convMu.Lock()
-		b.conversations = make(map[int64]string)
+		b.conversations = make(map[chatTopic]string)
 		b.convMu.Unlock()
 
 		_, err := b.sendMessage(chatID, "🔄 Restarting...\n\nConversations cleared. Bot will restart shortly.")
@@ -245,12 +632,169 @@ Just chat naturally or ask me to:
 	case "skills":
 		return b.handleSkillsCommand(chatID)
 
+	case "pin":
+		return b.handlePinCommand(msg)
+
+	case "pins":
+		return b.handlePinsCommand(chatID)
+
+	case "unpin":
+		return b.handleUnpinCommand(msg)
+
+	case "alias":
+		return b.handleAliasCommand(msg)
+
+	case "whoami", "household", "invite", "join":
+		return b.handleHouseholdCommand(msg)
+
+	case "notifications":
+		return b.handleNotificationsCommand(msg)
+
+	case "incident":
+		return b.handleIncidentCommand(msg)
+
+	case "voice":
+		if b.voice == nil {
+			_, err := b.sendMessage(chatID, "🔇 Voice replies aren't enabled. Set skills.voice.replies in the config.")
+			return err
+		}
+		_, err := b.sendMessage(chatID, b.voice.Command("telegram", strconv.FormatInt(chatID, 10), msg.CommandArguments()))
+		return err
+
 	default:
+		if handled, err := b.runAlias(msg); handled {
+			return err
+		}
 		_, err := b.sendMessage(chatID, "❓ Unknown command. Use /help for available commands.")
 		return err
 	}
 }
 
+// aliasUser returns the alias owner ID for the sender of a message
+func aliasUser(msg *tgbotapi.Message) string {
+	if msg.From == nil {
+		return aliases.DefaultUser
+	}
+	return fmt.Sprintf("telegram:%d", msg.From.ID)
+}
+
+// isHouseholdMember reports whether the sender may use the bot. Everyone may
+// while no household profiles exist; /start, /help and /join are always open.
+func (b *Bot) isHouseholdMember(msg *tgbotapi.Message) bool {
+	if b.household == nil || msg.From == nil || !b.household.Active() {
+		return true
+	}
+	switch msg.Command() {
+	case "start", "help", "join":
+		return true
+	}
+	profile, err := b.household.ForChannel("telegram", strconv.FormatInt(msg.From.ID, 10))
+	return err == nil && profile != nil
+}
+
+// profileContext returns the bot context carrying the bot's persona and the
+// sender's household profile, if any
+func (b *Bot) profileContext(msg *tgbotapi.Message) context.Context {
+	ctx := persona.WithPersona(b.ctx, b.persona)
+	if b.household == nil || msg.From == nil {
+		return ctx
+	}
+	profile, err := b.household.ForChannel("telegram", strconv.FormatInt(msg.From.ID, 10))
+	if err != nil {
+		b.logger.Warn("Failed to load household profile", zap.Error(err))
+		return ctx
+	}
+	return household.WithProfile(ctx, profile)
+}
+
+// kbUserID returns the user whose knowledge base a message's uploads go in
+func (b *Bot) kbUserID(msg *tgbotapi.Message) string {
+	ctx := b.profileContext(msg)
+	if b.kbScope != nil {
+		ctx = b.kbScope(ctx, "kb")
+	}
+	if userID, ok := ctx.Value("user_id").(string); ok {
+		return userID
+	}
+	return household.UserID(ctx)
+}
+
+// publishUpload announces a saved file on the skills event bus
+func (b *Bot) publishUpload(msg *tgbotapi.Message, f *store.File) {
+	registry := b.agent.GetSkillsRegistry()
+	if registry == nil {
+		return
+	}
+
+	ctx := b.profileContext(msg)
+	registry.Events().Publish(ctx, events.Event{
+		Type:   events.FileUploaded,
+		UserID: household.UserID(ctx),
+		Source: "telegram",
+		Data: map[string]interface{}{
+			"file_id":   f.ID,
+			"filename":  f.Filename,
+			"mime_type": f.MimeType,
+			"size":      f.SizeBytes,
+		},
+	})
+}
+
+// handleHouseholdCommand runs /whoami, /household, /invite and /join
+func (b *Bot) handleHouseholdCommand(msg *tgbotapi.Message) error {
+	if b.household == nil || msg.From == nil {
+		_, err := b.sendMessage(msg.Chat.ID, "❌ Household profiles not available - database not connected.")
+		return err
+	}
+	reply := b.household.Command("telegram", strconv.FormatInt(msg.From.ID, 10), msg.Command(), msg.CommandArguments())
+	_, err := b.sendMessage(msg.Chat.ID, reply)
+	return err
+}
+
+// handleNotificationsCommand shows and changes the sender's notification
+// preferences
+func (b *Bot) handleNotificationsCommand(msg *tgbotapi.Message) error {
+	if b.notifier == nil {
+		_, err := b.sendMessage(msg.Chat.ID, "❌ Notifications not available - server not running.")
+		return err
+	}
+	userID := household.UserID(b.profileContext(msg))
+	_, err := b.sendMessage(msg.Chat.ID, b.notifier.Preferences().Command(userID, msg.CommandArguments()))
+	return err
+}
+
+// handleAliasCommand manages the sender's aliases
+func (b *Bot) handleAliasCommand(msg *tgbotapi.Message) error {
+	if b.aliases == nil {
+		_, err := b.sendMessage(msg.Chat.ID, "❌ Aliases not available - database not connected.")
+		return err
+	}
+	_, err := b.sendMessage(msg.Chat.ID, b.aliases.Command(aliasUser(msg), msg.CommandArguments()))
+	return err
+}
+
+// runAlias expands an alias command and processes it as a regular message.
+// It reports false when the command is not a known alias.
+func (b *Bot) runAlias(msg *tgbotapi.Message) (bool, error) {
+	if b.aliases == nil {
+		return false, nil
+	}
+
+	text, ok, err := b.aliases.Resolve(aliasUser(msg), msg.Command(), strings.Fields(msg.CommandArguments()))
+	if err != nil {
+		b.logger.Error("Failed to resolve alias", zap.Error(err))
+		return false, nil
+	}
+	if !ok {
+		return false, nil
+	}
+
+	expanded := *msg
+	expanded.Text = text
+	expanded.Entities = nil
+	return true, b.handleMessage(&expanded)
+}
+
 // handleHistoryCommand shows conversation history for the chat
 func (b *Bot) handleHistoryCommand(chatID int64) error {
 	if b.store == nil {
@@ -258,7 +802,7 @@ func (b *Bot) handleHistoryCommand(chatID int64) error {
 		return err
 	}
 
-	mappings, err := b.store.GetChatConversationHistory(chatID, "telegram", 10)
+	mappings, err := b.store.GetThreadConversationHistory(chatID, int64(b.topic(chatID)), "telegram", 10)
 	if err != nil {
 		b.logger.Error("Failed to get conversation history", zap.Error(err))
 		_, err := b.sendMessage(chatID, "❌ Failed to retrieve conversation history.")
@@ -320,7 +864,7 @@ func (b *Bot) handleResumeCommand(msg *tgbotapi.Message) error {
 	}
 
 	// Get conversation history
-	mappings, err := b.store.GetChatConversationHistory(chatID, "telegram", 10)
+	mappings, err := b.store.GetThreadConversationHistory(chatID, int64(b.topic(chatID)), "telegram", 10)
 	if err != nil {
 		b.logger.Error("Failed to get conversation history", zap.Error(err))
 		_, err := b.sendMessage(chatID, "❌ Failed to retrieve conversation history.")
@@ -353,6 +897,124 @@ func (b *Bot) handleResumeCommand(msg *tgbotapi.Message) error {
 	return err
 }
 
+// handleTitleCommand shows or renames the active conversation. A title set
+// here is kept when the topic changes.
+func (b *Bot) handleTitleCommand(msg *tgbotapi.Message) error {
+	chatID := msg.Chat.ID
+
+	convID := b.getConversationID(chatID)
+	if convID == "" {
+		_, err := b.sendMessage(chatID, "❌ Nothing to title yet. Send a message first.")
+		return err
+	}
+
+	title := strings.TrimSpace(msg.CommandArguments())
+	if title == "" {
+		current := agent.DefaultTitle
+		if b.store != nil {
+			if conv, err := b.store.GetConversation(convID); err == nil {
+				current = conv.Title
+			}
+		}
+		_, err := b.sendMessage(chatID, fmt.Sprintf("💬 *%s*\n\nUse `/title <new title>` to rename it.", current))
+		return err
+	}
+
+	if err := b.agent.RenameConversation(convID, title); err != nil {
+		_, err := b.sendMessage(chatID, fmt.Sprintf("❌ %v", err))
+		return err
+	}
+	_, err := b.sendMessage(chatID, fmt.Sprintf("✏️ Conversation renamed to *%s*", title))
+	return err
+}
+
+// handleModelCommand pins the active conversation to the fast or smart
+// model, or with auto lets each turn be routed
+func (b *Bot) handleModelCommand(msg *tgbotapi.Message) error {
+	chatID := msg.Chat.ID
+	convID := b.getConversationID(chatID)
+
+	route := strings.TrimSpace(msg.CommandArguments())
+	if route == "" {
+		_, err := b.sendMessage(chatID, b.agent.DescribeRoute(convID)+"\n\nUse `/model fast|smart|auto` to change it.")
+		return err
+	}
+	if convID == "" {
+		_, err := b.sendMessage(chatID, "❌ No conversation yet. Send a message first.")
+		return err
+	}
+	if err := b.agent.SetConversationRoute(convID, route); err != nil {
+		_, err := b.sendMessage(chatID, fmt.Sprintf("❌ %v", err))
+		return err
+	}
+	_, err := b.sendMessage(chatID, "🔀 "+b.agent.DescribeRoute(convID))
+	return err
+}
+
+// handlePinCommand pins a fact, file or tool output to the active conversation
+func (b *Bot) handlePinCommand(msg *tgbotapi.Message) error {
+	chatID := msg.Chat.ID
+
+	convID := b.getConversationID(chatID)
+	if convID == "" {
+		_, err := b.sendMessage(chatID, "❌ No active conversation yet. Send a message first, then pin.")
+		return err
+	}
+
+	pin, err := b.agent.PinFromArgs(convID, msg.CommandArguments())
+	if err != nil {
+		_, err := b.sendMessage(chatID, fmt.Sprintf("❌ %v", err))
+		return err
+	}
+
+	_, err = b.sendMessage(chatID, fmt.Sprintf("📌 Pinned %s (%d tokens)", pin.Kind, pin.Tokens))
+	return err
+}
+
+// handlePinsCommand lists the pins of the active conversation
+func (b *Bot) handlePinsCommand(chatID int64) error {
+	convID := b.getConversationID(chatID)
+	if convID == "" {
+		_, err := b.sendMessage(chatID, agent.FormatPins(nil, b.agent.PinBudget()))
+		return err
+	}
+
+	pins, err := b.agent.Pins(convID)
+	if err != nil {
+		b.logger.Error("Failed to list pins", zap.Error(err))
+		_, err := b.sendMessage(chatID, "❌ Failed to retrieve pinned items.")
+		return err
+	}
+
+	_, err = b.sendMessage(chatID, agent.FormatPins(pins, b.agent.PinBudget()))
+	return err
+}
+
+// handleUnpinCommand removes a pin from the active conversation
+func (b *Bot) handleUnpinCommand(msg *tgbotapi.Message) error {
+	chatID := msg.Chat.ID
+
+	ref := strings.TrimSpace(msg.CommandArguments())
+	if ref == "" {
+		_, err := b.sendMessage(chatID, "❌ Please specify a pin number.\n\nExample: `/unpin 1` or `/unpin all`")
+		return err
+	}
+
+	convID := b.getConversationID(chatID)
+	if convID == "" {
+		_, err := b.sendMessage(chatID, "📭 No active conversation.")
+		return err
+	}
+
+	if err := b.agent.Unpin(convID, ref); err != nil {
+		_, err := b.sendMessage(chatID, fmt.Sprintf("❌ Pin %s not found.", ref))
+		return err
+	}
+
+	_, err := b.sendMessage(chatID, "✅ Unpinned.")
+	return err
+}
+
 // handleDocumentsCommand shows all uploaded documents
 func (b *Bot) handleDocumentsCommand(chatID int64) error {
 	if b.store == nil {
@@ -482,14 +1144,13 @@ func (b *Bot) handleMessage(msg *tgbotapi.Message) error {
 	b.api.Send(typing)
 
 	// Process through agent
-	ctx, cancel := context.WithTimeout(b.ctx, 60*time.Second)
+	ctx, cancel := context.WithTimeout(b.profileContext(msg), 60*time.Second)
 	defer cancel()
 
-	var responseText strings.Builder
-
 	resp, err := b.agent.Chat(ctx, agent.ChatRequest{
 		ConversationID: convID,
 		Message:        sanitizedText,
+		Channel:        "telegram",
 		Stream:         false, // Non-streaming for Telegram
 		OnToolExecuting: func(toolName string) {
 			// Show tool execution feedback
@@ -508,24 +1169,17 @@ func (b *Bot) handleMessage(msg *tgbotapi.Message) error {
 		b.setConversationID(chatID, resp.ConversationID)
 	}
 
-	responseText.WriteString(resp.Content)
-
-	// Format response for Telegram (respecting message limits)
-	response := responseText.String()
-
 	// Check for empty response
-	if strings.TrimSpace(response) == "" {
+	if strings.TrimSpace(resp.Content) == "" {
 		b.logger.Warn("Empty response from agent, sending fallback message")
 		_, err = b.sendMessage(chatID, "🤖 I processed your request but didn't generate a response. Please try again.")
 		return err
 	}
 
-	if len(response) > 4096 {
-		response = response[:4093] + "..."
-	}
-
-	// Send response
-	_, err = b.sendMessage(chatID, response)
+	// Send response, already split to Telegram's message limit
+	response, err := b.sendReply(ctx, chatID, resp)
+	b.sendAttachments(chatID, resp.Attachments)
+	b.sendVoiceReply(chatID, response)
 	return err
 }
 
@@ -570,7 +1224,7 @@ func (b *Bot) handlePhoto(msg *tgbotapi.Message) error {
 	defer os.Remove(filePath) // Clean up after processing
 
 	// Process image through skills registry first
-	ctx, cancel := context.WithTimeout(b.ctx, 60*time.Second)
+	ctx, cancel := context.WithTimeout(b.profileContext(msg), 60*time.Second)
 	defer cancel()
 
 	prompt := "Analyze this image and describe what you see."
@@ -578,9 +1232,21 @@ func (b *Bot) handlePhoto(msg *tgbotapi.Message) error {
 		prompt = msg.Caption
 	}
 
-	// Try to process image using the process_image skill if available
+	// Vision-capable models see the photo itself
+	var images []llm.ImagePart
+	if b.agent.SupportsVision() {
+		if img, err := llm.ImageFromFile(filePath); err != nil {
+			b.logger.Warn("Failed to read photo", zap.Error(err))
+		} else {
+			images = []llm.ImagePart{img}
+		}
+	}
+
+	// Otherwise try to process image using the process_image skill if available
 	var imageAnalysis string
-	if result, err := b.agent.ExecuteTool(ctx, "process_image", map[string]interface{}{
+	if len(images) > 0 {
+		b.logger.Info("Sending photo to the model", zap.String("file", filePath))
+	} else if result, err := b.agent.ExecuteTool(ctx, "process_image", map[string]interface{}{
 		"file_path": filePath,
 		"query":     prompt,
 	}); err == nil {
@@ -623,21 +1289,27 @@ func (b *Bot) handlePhoto(msg *tgbotapi.Message) error {
 			// Continue anyway, not critical
 		} else {
 			b.logger.Info("Image saved to database", zap.String("file_id", fileRecord.ID))
+			b.publishUpload(msg, fileRecord)
 		}
 	}
 
 	// Build message - include image analysis if we got it, otherwise just the path
 	var message string
-	if imageAnalysis != "" {
+	switch {
+	case len(images) > 0:
+		message = prompt
+	case imageAnalysis != "":
 		message = fmt.Sprintf("Image Analysis:\n%s\n\nUser question: %s", imageAnalysis, prompt)
-	} else {
+	default:
 		message = fmt.Sprintf("[Image attached: %s]\n\n%s", filePath, prompt)
 	}
 
 	resp, err := b.agent.Chat(ctx, agent.ChatRequest{
 		ConversationID: b.getConversationID(chatID),
 		Message:        message,
+		Channel:        "telegram",
 		Stream:         false,
+		Images:         images,
 	})
 
 	if err != nil {
@@ -654,7 +1326,8 @@ func (b *Bot) handlePhoto(msg *tgbotapi.Message) error {
 		b.store.UpdateFileProcessedText(fileRecord.ID, resp.Content)
 	}
 
-	_, err = b.sendMessage(chatID, resp.Content)
+	_, err = b.sendReply(ctx, chatID, resp)
+	b.sendAttachments(chatID, resp.Attachments)
 	return err
 }
 
@@ -684,6 +1357,24 @@ func (b *Bot) handleDocument(msg *tgbotapi.Message) error {
 	}
 	defer os.Remove(filePath) // Clean up after processing
 
+	// Keep a copy in the knowledge base so it can be asked about later
+	storagePath := filePath
+	var kbDoc *kb.Document
+	if b.kb != nil {
+		kbDoc, err = b.kb.Add(kb.AddOptions{
+			Path:     filePath,
+			Filename: doc.FileName,
+			MimeType: doc.MimeType,
+			UserID:   b.kbUserID(msg),
+			Source:   "telegram",
+		})
+		if err != nil {
+			b.logger.Warn("Failed to add document to knowledge base", zap.String("filename", doc.FileName), zap.Error(err))
+		} else {
+			storagePath = kbDoc.StoragePath
+		}
+	}
+
 	// Save file to database for global access
 	var fileRecord *store.File
 	if b.store != nil {
@@ -692,7 +1383,7 @@ func (b *Bot) handleDocument(msg *tgbotapi.Message) error {
 			Filename:    doc.FileName,
 			MimeType:    doc.MimeType,
 			SizeBytes:   int64(doc.FileSize),
-			StoragePath: filePath,
+			StoragePath: storagePath,
 			ConversationID: func() *string {
 				if convID != "" {
 					return &convID
@@ -707,14 +1398,21 @@ func (b *Bot) handleDocument(msg *tgbotapi.Message) error {
 			// Continue anyway, not critical
 		} else {
 			b.logger.Info("File saved to database", zap.String("file_id", fileRecord.ID), zap.String("filename", doc.FileName))
+			b.publishUpload(msg, fileRecord)
 		}
 	}
 
 	// Process through agent with the document
-	ctx, cancel := context.WithTimeout(b.ctx, 120*time.Second)
+	ctx, cancel := context.WithTimeout(b.profileContext(msg), 120*time.Second)
 	defer cancel()
 
-	prompt := fmt.Sprintf("Please analyze this document: %s", filePath)
+	prompt := fmt.Sprintf("Please analyze this document: %s", storagePath)
+	if tool := documents.ToolFor(doc.FileName); tool != "" {
+		prompt += fmt.Sprintf("\n\nRead it with %s.", tool)
+	}
+	if kbDoc != nil {
+		prompt += fmt.Sprintf("\n\nIt has been added to the user's knowledge base as %q (id %s); query_documents will find it later.", kbDoc.Title, kbDoc.ID)
+	}
 	if msg.Caption != "" {
 		prompt = fmt.Sprintf("%s\n\nUser request: %s", prompt, msg.Caption)
 	}
@@ -722,6 +1420,7 @@ func (b *Bot) handleDocument(msg *tgbotapi.Message) error {
 	resp, err := b.agent.Chat(ctx, agent.ChatRequest{
 		ConversationID: b.getConversationID(chatID),
 		Message:        prompt,
+		Channel:        "telegram",
 		Stream:         false,
 	})
 
@@ -739,7 +1438,8 @@ func (b *Bot) handleDocument(msg *tgbotapi.Message) error {
 		b.store.UpdateFileProcessedText(fileRecord.ID, resp.Content)
 	}
 
-	_, err = b.sendMessage(chatID, resp.Content)
+	_, err = b.sendReply(ctx, chatID, resp)
+	b.sendAttachments(chatID, resp.Attachments)
 	return err
 }
 
@@ -763,7 +1463,11 @@ func (b *Bot) downloadFile(fileID string, fileType string) (string, error) {
 	if fileType == "image" {
 		ext = "jpg"
 	} else if fileType == "document" {
+		// Keep the sent file's extension, which tells the tools how to read it
 		ext = "pdf"
+		if e := strings.TrimPrefix(filepath.Ext(file.FilePath), "."); e != "" {
+			ext = strings.ToLower(e)
+		}
 	}
 	localPath := filepath.Join(tempDir, fmt.Sprintf("%s-%d.%s", fileID, time.Now().Unix(), ext))
 
@@ -796,9 +1500,11 @@ func (b *Bot) downloadFile(fileID string, fileType string) (string, error) {
 
 // getConversationID returns the conversation ID for a chat (checks memory first, then database)
 func (b *Bot) getConversationID(chatID int64) string {
+	key := chatTopic{chatID, b.topic(chatID)}
+
 	// Check in-memory cache first
 	b.convMu.RLock()
-	convID := b.conversations[chatID]
+	convID := b.conversations[key]
 	b.convMu.RUnlock()
 
 	if convID != "" {
@@ -807,10 +1513,10 @@ func (b *Bot) getConversationID(chatID int64) string {
 
 	// Try to load from database
 	if b.store != nil {
-		mapping, err := b.store.GetChatMapping(chatID, "telegram")
+		mapping, err := b.store.GetThreadMapping(chatID, int64(key.topic), "telegram")
 		if err == nil && mapping != nil {
 			b.convMu.Lock()
-			b.conversations[chatID] = mapping.ConversationID
+			b.conversations[key] = mapping.ConversationID
 			b.convMu.Unlock()
 			return mapping.ConversationID
 		}
@@ -819,20 +1525,22 @@ func (b *Bot) getConversationID(chatID int64) string {
 	return ""
 }
 
-// setConversationID sets the conversation ID for a chat and persists it
+// setConversationID sets the conversation ID for a chat, or its current
+// forum topic, and persists it
 func (b *Bot) setConversationID(chatID int64, convID string) {
 	if convID == "" {
 		return
 	}
+	key := chatTopic{chatID, b.topic(chatID)}
 
 	// Update in-memory cache
 	b.convMu.Lock()
-	b.conversations[chatID] = convID
+	b.conversations[key] = convID
 	b.convMu.Unlock()
 
 	// Persist to database
 	if b.store != nil {
-		if err := b.store.SetChatMapping(chatID, "telegram", convID); err != nil {
+		if err := b.store.SetThreadMapping(chatID, int64(key.topic), "telegram", convID); err != nil {
 			b.logger.Warn("Failed to persist conversation mapping",
 				zap.Error(err),
 				zap.Int64("chat_id", chatID),
@@ -845,16 +1553,19 @@ func (b *Bot) setConversationID(chatID int64, convID string) {
 	}
 }
 
-// clearConversationID clears the conversation mapping for a chat
+// clearConversationID clears the conversation mapping for a chat, or its
+// current forum topic
 func (b *Bot) clearConversationID(chatID int64) {
+	key := chatTopic{chatID, b.topic(chatID)}
+
 	// Clear in-memory cache
 	b.convMu.Lock()
-	delete(b.conversations, chatID)
+	delete(b.conversations, key)
 	b.convMu.Unlock()
 
 	// Deactivate in database
 	if b.store != nil {
-		if err := b.store.DeactivateChatMapping(chatID, "telegram"); err != nil {
+		if err := b.store.DeactivateThreadMapping(chatID, int64(key.topic), "telegram"); err != nil {
 			b.logger.Warn("Failed to deactivate conversation mapping",
 				zap.Error(err),
 				zap.Int64("chat_id", chatID))
@@ -863,6 +1574,10 @@ func (b *Bot) clearConversationID(chatID int64) {
 }
 
 func (b *Bot) sendMessage(chatID int64, text string) (int, error) {
+	if topic := b.topic(chatID); topic != 0 {
+		return b.sendTopicMessage(chatID, topic, text)
+	}
+
 	// Escape special characters for Markdown
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = tgbotapi.ModeMarkdown
@@ -880,6 +1595,91 @@ func (b *Bot) sendMessage(chatID int64, text string) (int, error) {
 	return sent.MessageID, nil
 }
 
+// sendTopicMessage sends text into a forum topic. The Bot API library
+// predates topics, so the request is built by hand.
+func (b *Bot) sendTopicMessage(chatID int64, topic int, text string) (int, error) {
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonZero("message_thread_id", topic)
+	params.AddNonEmpty("text", text)
+	params.AddNonEmpty("parse_mode", tgbotapi.ModeMarkdown)
+
+	resp, err := b.api.MakeRequest("sendMessage", params)
+	if err != nil {
+		// Try without markdown if it fails
+		delete(params, "parse_mode")
+		resp, err = b.api.MakeRequest("sendMessage", params)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var sent tgbotapi.Message
+	if err := json.Unmarshal(resp.Result, &sent); err != nil {
+		return 0, err
+	}
+	return sent.MessageID, nil
+}
+
+// sendFile uploads the file at path with method (sendPhoto, sendDocument or
+// sendVoice) as field, into the chat's current forum topic if any
+func (b *Bot) sendFile(chatID int64, method, field, path string) error {
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonZero("message_thread_id", b.topic(chatID))
+	_, err := b.api.UploadFiles(method, params, []tgbotapi.RequestFile{{Name: field, Data: tgbotapi.FilePath(path)}})
+	return err
+}
+
+// sendAttachments sends the files tools produced during the turn, images as
+// photos and anything else as documents
+func (b *Bot) sendAttachments(chatID int64, paths []string) {
+	for _, path := range paths {
+		method, field := "sendDocument", "document"
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".png", ".jpg", ".jpeg", ".gif":
+			method, field = "sendPhoto", "photo"
+		}
+		if err := b.sendFile(chatID, method, field, path); err != nil {
+			b.logger.Warn("Failed to send attachment",
+				zap.Int64("chat_id", chatID),
+				zap.String("path", path),
+				zap.Error(err))
+		}
+	}
+}
+
+// sendVoiceReply speaks a reply as a voice message in chats that turned
+// voice replies on
+func (b *Bot) sendVoiceReply(chatID int64, reply string) {
+	if b.voice == nil || !b.voice.Enabled("telegram", strconv.FormatInt(chatID, 10)) {
+		return
+	}
+	b.api.Send(tgbotapi.NewChatAction(chatID, tgbotapi.ChatRecordVoice))
+
+	ctx, cancel := context.WithTimeout(b.ctx, 60*time.Second)
+	defer cancel()
+	path, err := b.voice.VoiceNote(ctx, reply)
+	if err != nil {
+		b.logger.Warn("Failed to speak reply", zap.Int64("chat_id", chatID), zap.Error(err))
+		return
+	}
+	if path == "" {
+		return
+	}
+	defer os.Remove(path)
+
+	if err := b.sendFile(chatID, "sendVoice", "voice", path); err != nil {
+		b.logger.Warn("Failed to send voice reply", zap.Int64("chat_id", chatID), zap.Error(err))
+	}
+}
+
+// Health reports whether the bot is reaching Telegram, and its last
+// message and error
+func (b *Bot) Health() channels.Health {
+	return b.health.Health("telegram")
+}
+
 // GetBotInfo returns bot information
 func (b *Bot) GetBotInfo() map[string]interface{} {
 	if !b.enabled {
@@ -894,3 +1694,27 @@ func (b *Bot) GetBotInfo() map[string]interface{} {
 		"firstName": b.api.Self.FirstName,
 	}
 }
+
+// isAdmin reports whether the sender may run admin commands: the household
+// owner once profiles exist, otherwise anyone the allow list lets in
+func (b *Bot) isAdmin(msg *tgbotapi.Message) bool {
+	if b.household == nil || !b.household.Active() {
+		return true
+	}
+	profile := household.FromContext(b.profileContext(msg))
+	return profile != nil && profile.IsOwner()
+}
+
+// handleIncidentCommand turns incident mode on or off
+func (b *Bot) handleIncidentCommand(msg *tgbotapi.Message) error {
+	if b.incident == nil {
+		_, err := b.sendMessage(msg.Chat.ID, "❌ Incident mode not available - server not running.")
+		return err
+	}
+	if !b.isAdmin(msg) {
+		_, err := b.sendMessage(msg.Chat.ID, "❌ Only the household owner can use /incident.")
+		return err
+	}
+	_, err := b.sendMessage(msg.Chat.ID, b.incident.Command(msg.CommandArguments()))
+	return err
+}
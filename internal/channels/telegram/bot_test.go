@@ -0,0 +1,67 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestDecodeUpdates(t *testing.T) {
+	data := []byte(`[
+		{"update_id": 1, "message": {"message_id": 10, "text": "hi", "chat": {"id": -100, "type": "supergroup"}, "message_thread_id": 7, "is_topic_message": true}},
+		{"update_id": 2, "message": {"message_id": 11, "text": "reply", "chat": {"id": -200, "type": "group"}, "message_thread_id": 10}},
+		{"update_id": 3, "edited_message": {"message_id": 12, "chat": {"id": 5, "type": "private"}}}
+	]`)
+	updates, err := decodeUpdates(data)
+	if err != nil {
+		t.Fatalf("decodeUpdates failed: %v", err)
+	}
+	if len(updates) != 3 {
+		t.Fatalf("Expected 3 updates, got %d", len(updates))
+	}
+	if updates[0].topic != 7 || updates[0].Message.Text != "hi" {
+		t.Errorf("Expected the first update in topic 7, got %+v", updates[0])
+	}
+	// A reply thread outside a forum isn't a topic
+	if updates[1].topic != 0 || updates[2].topic != 0 {
+		t.Errorf("Expected no topic, got %d and %d", updates[1].topic, updates[2].topic)
+	}
+}
+
+func TestBot_AddressedToBot(t *testing.T) {
+	self := tgbotapi.User{ID: 99, UserName: "myrai_bot"}
+	b := &Bot{api: &tgbotapi.BotAPI{Self: self}, mention: mentionPattern(self.UserName)}
+	other := &tgbotapi.User{ID: 5}
+
+	tests := []struct {
+		name string
+		msg  tgbotapi.Message
+		want bool
+		text string
+	}{
+		{"chatter", tgbotapi.Message{Text: "lunch?"}, false, "lunch?"},
+		{"mention", tgbotapi.Message{Text: "@Myrai_Bot what's the weather"}, true, "what's the weather"},
+		{"other bot", tgbotapi.Message{Text: "@myrai_botanist hi"}, false, "@myrai_botanist hi"},
+		{"reply", tgbotapi.Message{Text: "and tomorrow?", ReplyToMessage: &tgbotapi.Message{From: &self}}, true, "and tomorrow?"},
+		{"reply to someone else", tgbotapi.Message{Text: "sure", ReplyToMessage: &tgbotapi.Message{From: other}}, false, "sure"},
+		{"command", tgbotapi.Message{Text: "/new", Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Length: 4}}}, true, "/new"},
+		{"command for us", tgbotapi.Message{Text: "/new@myrai_bot", Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Length: 14}}}, true, "/new@myrai_bot"},
+		{"command for another bot", tgbotapi.Message{Text: "/new@other_bot", Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Length: 14}}}, false, "/new@other_bot"},
+	}
+	for _, tt := range tests {
+		msg := tt.msg
+		if got := b.addressedToBot(&msg); got != tt.want || msg.Text != tt.text {
+			t.Errorf("%s: addressedToBot = %t with text %q, want %t with %q", tt.name, got, msg.Text, tt.want, tt.text)
+		}
+	}
+
+	b.groupMode = config.GroupModeAll
+	if !b.addressedToBot(&tgbotapi.Message{Text: "lunch?"}) {
+		t.Error("Expected every message to be answered in all mode")
+	}
+	b.groupMode = config.GroupModeOff
+	if b.addressedToBot(&tgbotapi.Message{Text: "@myrai_bot hi"}) {
+		t.Error("Expected no message to be answered in off mode")
+	}
+}
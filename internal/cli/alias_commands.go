@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/aliases"
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+// reservedCommands are top-level CLI commands that aliases may not shadow.
+// Keep it in step with the switch in cmd/myrai/main.go, which only tries
+// aliases for words it doesn't dispatch; TestReservedCommands checks it.
+var reservedCommands = map[string]bool{
+	"onboard": true, "profile": true, "profiles": true, "project": true, "persona": true, "user": true,
+	"batch": true, "config": true, "skills": true, "channels": true, "gateway": true, "status": true,
+	"doctor": true, "memory": true, "chain": true, "tools": true, "intent": true, "marketplace": true,
+	"job": true, "alias": true, "plan": true, "prompt": true, "artifacts": true, "artifact": true,
+	"household": true, "locale": true, "calendar": true, "kb": true, "vector": true, "db": true,
+	"backup": true, "git": true, "do": true, "conversations": true, "conversation": true, "convs": true,
+	"privacy": true, "secret": true, "secrets": true, "upgrade": true, "tui": true, "help": true, "version": true,
+}
+
+// HandleAliasCommand handles alias management commands
+func HandleAliasCommand(args []string) {
+	if len(args) == 0 {
+		PrintAliasHelp()
+		return
+	}
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("Error initializing store: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	mgr, err := aliases.NewManager(st.DB())
+	if err != nil {
+		fmt.Printf("Error initializing aliases: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "add", "set":
+		if len(args) < 3 {
+			fmt.Println("Usage: myrai alias add <name> \"<prompt>\"")
+			os.Exit(1)
+		}
+		name := strings.ToLower(args[1])
+		if reservedCommands[name] {
+			fmt.Printf("❌ '%s' is a built-in command and cannot be used as an alias\n", name)
+			os.Exit(1)
+		}
+		alias, err := mgr.Add(aliases.DefaultUser, name, strings.Join(args[2:], " "))
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Alias '%s' saved\n", alias.Name)
+		fmt.Printf("   Use it as: myrai %s  or  /%s in any channel\n", alias.Name, alias.Name)
+
+	case "list", "ls":
+		list, err := mgr.List(aliases.DefaultUser)
+		if err != nil {
+			fmt.Printf("Error listing aliases: %v\n", err)
+			os.Exit(1)
+		}
+		if len(list) == 0 {
+			fmt.Println("No aliases defined. Add one with: myrai alias add <name> \"<prompt>\"")
+			return
+		}
+		fmt.Println("Aliases:")
+		for _, a := range list {
+			fmt.Printf("  %-16s %s\n", a.Name, a.Template)
+		}
+
+	case "remove", "rm", "delete":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai alias remove <name>")
+			os.Exit(1)
+		}
+		if err := mgr.Remove(aliases.DefaultUser, args[1]); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Alias '%s' removed\n", args[1])
+
+	default:
+		PrintAliasHelp()
+	}
+}
+
+// ResolveAlias expands a CLI alias invocation such as "myrai standup". It
+// returns false when name is not a defined alias.
+func ResolveAlias(name string, args []string) (string, bool) {
+	if !aliases.ValidName(name) {
+		return "", false
+	}
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		return "", false
+	}
+
+	st, err := store.New(cfg)
+	if err != nil {
+		return "", false
+	}
+	// Closed before returning so the app can reopen the store
+	defer st.Close()
+
+	mgr, err := aliases.NewManager(st.DB())
+	if err != nil {
+		return "", false
+	}
+
+	text, ok, err := mgr.Resolve(aliases.DefaultUser, name, args)
+	if err != nil {
+		return "", false
+	}
+	return text, ok
+}
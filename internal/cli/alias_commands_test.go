@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestReservedCommands checks that every command cmd/myrai dispatches is
+// reserved, as aliases named after one could never run
+func TestReservedCommands(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "../../cmd/myrai/main.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse main.go: %v", err)
+	}
+
+	var commands []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		sw, ok := n.(*ast.SwitchStmt)
+		if !ok {
+			return true
+		}
+		// The top-level dispatch switches on os.Args[1]
+		index, ok := sw.Tag.(*ast.IndexExpr)
+		if !ok {
+			return true
+		}
+		if sel, ok := index.X.(*ast.SelectorExpr); !ok || sel.Sel.Name != "Args" {
+			return true
+		}
+		for _, stmt := range sw.Body.List {
+			for _, expr := range stmt.(*ast.CaseClause).List {
+				if lit, ok := expr.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					name, _ := strconv.Unquote(lit.Value)
+					commands = append(commands, name)
+				}
+			}
+		}
+		return false
+	})
+
+	if len(commands) == 0 {
+		t.Fatal("Expected to find the command switch in main.go")
+	}
+	for _, name := range commands {
+		if strings.HasPrefix(name, "-") {
+			continue
+		}
+		if !reservedCommands[name] {
+			t.Errorf("Expected %q to be reserved, as main.go dispatches it", name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/gmsas95/myrai-cli/internal/artifacts"
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+// artifactPreviewBytes is how much of a text artifact 'artifacts show' prints
+const artifactPreviewBytes = 2000
+
+// HandleArtifactsCommand lists, shows, exports and deletes the tool outputs
+// and generated files kept as artifacts
+func HandleArtifactsCommand(args []string) {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("Error initializing store: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+	arts, err := artifacts.NewStore(st.DB(), artifacts.DefaultDir(cfg.Storage.DataDir))
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		filter := artifacts.Filter{
+			ConversationID: flagValue(args, "-c", "--conversation"),
+			Kind:           flagValue(args, "--kind"),
+			Limit:          30,
+		}
+		if n, err := strconv.Atoi(flagValue(args, "-n", "--limit")); err == nil && n > 0 {
+			filter.Limit = n
+		}
+		list, err := arts.List(filter)
+		if err != nil {
+			fmt.Printf("❌ Failed to list artifacts: %v\n", err)
+			os.Exit(1)
+		}
+		if len(list) == 0 {
+			fmt.Println("📭 No artifacts yet. Long tool outputs and files Myrai generates are kept here.")
+			return
+		}
+		for _, a := range list {
+			fmt.Printf("%-22s %-6s %9s  %-28s %s\n", a.ID, a.Kind, formatArtifactSize(a.SizeBytes),
+				truncateString(a.Name, 28), a.CreatedAt.Local().Format("2006-01-02 15:04"))
+		}
+
+	case "show":
+		a := mustGetArtifact(arts, args)
+		fmt.Printf("ID:           %s\n", a.ID)
+		fmt.Printf("Name:         %s\n", a.Name)
+		fmt.Printf("Kind:         %s\n", a.Kind)
+		fmt.Printf("Type:         %s\n", a.MimeType)
+		fmt.Printf("Size:         %s\n", formatArtifactSize(a.SizeBytes))
+		if a.Source != "" {
+			fmt.Printf("Tool:         %s\n", a.Source)
+		}
+		if a.ConversationID != "" {
+			fmt.Printf("Conversation: %s\n", a.ConversationID)
+		}
+		fmt.Printf("Created:      %s\n", a.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+		if !a.Text() {
+			fmt.Printf("\nSave it with: myrai artifacts export %s\n", a.ID)
+			return
+		}
+		text, more, err := arts.Read(a, 0, artifactPreviewBytes)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println()
+		fmt.Println(text)
+		if more {
+			fmt.Printf("\n… see all of it with: myrai artifacts export %s -o -\n", a.ID)
+		}
+
+	case "export", "save":
+		a := mustGetArtifact(arts, args)
+		output := flagValue(args, "-o", "--output")
+		if output == "" {
+			output = a.Name
+		}
+		if err := exportArtifact(a, output); err != nil {
+			fmt.Printf("❌ Failed to export %s: %v\n", a.ID, err)
+			os.Exit(1)
+		}
+		if output != "-" {
+			fmt.Printf("✅ Saved %s to %s\n", a.ID, output)
+		}
+
+	case "delete", "rm":
+		a := mustGetArtifact(arts, args)
+		if err := arts.Delete(a.ID); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Artifact %s deleted\n", a.ID)
+
+	default:
+		PrintArtifactsHelp()
+	}
+}
+
+func mustGetArtifact(arts *artifacts.Store, args []string) *artifacts.Artifact {
+	if len(args) < 2 {
+		fmt.Printf("Usage: myrai artifacts %s <id>\n", args[0])
+		os.Exit(1)
+	}
+	a, err := arts.Get(args[1])
+	if errors.Is(err, artifacts.ErrNotFound) {
+		fmt.Printf("❌ Artifact not found: %s\n", args[1])
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	return a
+}
+
+// exportArtifact copies the artifact's file to output, or to stdout for "-"
+func exportArtifact(a *artifacts.Artifact, output string) error {
+	in, err := os.Open(a.StoragePath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if output == "-" {
+		_, err = io.Copy(os.Stdout, in)
+		return err
+	}
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func formatArtifactSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
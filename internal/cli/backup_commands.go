@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/skills/notes"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+// HandleBackupCommand packs the database, workspace, documents and notes
+// into a single archive, and restores from one
+func HandleBackupCommand(args []string) {
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		PrintBackupHelp()
+		return
+	}
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	notesDir := notes.Dir(cfg.Skills.Notes.VaultDir)
+
+	switch args[0] {
+	case "create":
+		path := store.ArchivePath(&cfg.Storage, time.Now())
+		for i := 1; i < len(args); i++ {
+			if (args[i] == "--to" || args[i] == "-o") && i+1 < len(args) {
+				path = args[i+1]
+				i++
+			}
+		}
+		if !store.IsArchive(path) {
+			path += ".tar.zst"
+		}
+
+		backup, err := store.CreateArchive(&cfg.Storage, notesDir, path)
+		if err != nil {
+			fmt.Printf("❌ Backup failed: %v\n", err)
+			os.Exit(1)
+		}
+		size := int64(0)
+		if info, err := os.Stat(backup.Path); err == nil {
+			size = info.Size()
+		}
+		fmt.Printf("✅ Backup saved to %s (%.1f MB, schema version %d)\n", backup.Path, float64(size)/(1<<20), backup.SchemaVersion)
+
+	case "restore":
+		if len(args) < 2 || strings.HasPrefix(args[1], "-") {
+			fmt.Println("Usage: myrai backup restore <file.tar.zst|backup-id> [-y]")
+			os.Exit(1)
+		}
+		restoreBackup(cfg, notesDir, args[1], hasFlag(args, "--yes", "-y"))
+
+	case "list", "ls":
+		listBackups(cfg)
+		archives, err := store.ListArchives(store.ArchiveDir(&cfg.Storage))
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if len(archives) > 0 {
+			fmt.Println()
+			fmt.Printf("Archives in %s (newest first):\n", store.ArchiveDir(&cfg.Storage))
+			for _, a := range archives {
+				fmt.Printf("  %s\n", filepath.Base(a))
+			}
+		}
+
+	default:
+		fmt.Printf("Unknown backup command: %s\n\n", args[0])
+		PrintBackupHelp()
+		os.Exit(1)
+	}
+}
+
+// restoreBackup restores an archive file, or a backup directory by id
+func restoreBackup(cfg *config.Config, notesDir, source string, yes bool) {
+	info, err := os.Stat(source)
+	isFile := err == nil && !info.IsDir()
+
+	if !yes {
+		fmt.Printf("This replaces the database, workspace and documents with %s.\n", source)
+		if isFile {
+			fmt.Printf("Notes in the archive are copied into %s.\n", notesDir)
+		}
+		fmt.Println("Stop any running Myrai server first. The current data is backed up before restoring.")
+		fmt.Print("Continue? (y/N): ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answer = strings.TrimSpace(strings.ToLower(answer))
+		if answer != "y" && answer != "yes" {
+			fmt.Println("Restore cancelled")
+			return
+		}
+	}
+
+	var backup *store.Backup
+	if isFile {
+		backup, err = store.RestoreArchive(&cfg.Storage, notesDir, source)
+	} else {
+		backup, err = store.RestoreBackup(&cfg.Storage, source)
+	}
+	if err != nil {
+		fmt.Printf("❌ Restore failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Restored backup from %s (schema version %d)\n", backup.CreatedAt.Format("2006-01-02 15:04"), backup.SchemaVersion)
+	fmt.Println("   The replaced data was backed up first; see 'myrai backup list'.")
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/gmsas95/myrai-cli/internal/skills"
 	"github.com/gmsas95/myrai-cli/internal/store"
 	"go.uber.org/zap"
+	"golang.org/x/term"
 )
 
 func HandleBatchCommand(args []string) {
@@ -22,12 +23,21 @@ func HandleBatchCommand(args []string) {
 		PrintBatchHelp()
 		return
 	}
+	switch args[0] {
+	case "submit", "status", "results", "cancel":
+		handleBatchJobCommand(args[0], args[1:])
+		return
+	}
 
 	inputFile := ""
 	outputFile := ""
 	concurrency := 3
 	timeout := 60
 	tier := ""
+	outputFormat := ""
+	schemaFile := ""
+	quiet := false
+	pricePer1K := 0.0
 
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
@@ -56,6 +66,23 @@ func HandleBatchCommand(args []string) {
 				tier = args[i+1]
 				i++
 			}
+		case "-f", "--output-format":
+			if i+1 < len(args) {
+				outputFormat = args[i+1]
+				i++
+			}
+		case "-q", "--quiet":
+			quiet = true
+		case "--price-per-1k":
+			if i+1 < len(args) {
+				fmt.Sscanf(args[i+1], "%f", &pricePer1K)
+				i++
+			}
+		case "--json-schema":
+			if i+1 < len(args) {
+				schemaFile = args[i+1]
+				i++
+			}
 		case "-h", "--help":
 			PrintBatchHelp()
 			return
@@ -73,40 +100,50 @@ func HandleBatchCommand(args []string) {
 		os.Exit(1)
 	}
 
-	logger, _ := zap.NewDevelopment()
-	defer logger.Sync()
-
-	cfg, err := config.Load("", "")
-	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
+	if outputFormat != "" && !batch.ValidOutputFormat(outputFormat) {
+		fmt.Printf("Error: Unknown output format: %s (use text, json, jsonl or csv)\n", outputFormat)
 		os.Exit(1)
 	}
 
-	st, err := store.New(cfg)
-	if err != nil {
-		fmt.Printf("Error initializing store: %v\n", err)
-		os.Exit(1)
+	var schema *batch.Schema
+	if schemaFile != "" {
+		var err error
+		schema, err = batch.LoadSchema(schemaFile)
+		if err != nil {
+			fmt.Printf("Error loading JSON schema: %v\n", err)
+			os.Exit(1)
+		}
 	}
-	defer st.Close()
 
-	workspacePath := cfg.Storage.DataDir
-	pm, err := persona.NewPersonaManager(workspacePath, logger)
-	if err != nil {
-		logger.Warn("Failed to initialize persona manager", zap.Error(err))
+	// Keep the log quiet while the progress line is being redrawn
+	showProgress := !quiet && term.IsTerminal(int(os.Stderr.Fd()))
+	logCfg := zap.NewDevelopmentConfig()
+	if showProgress || quiet {
+		logCfg.Level = zap.NewAtomicLevelAt(zap.WarnLevel)
 	}
+	logger, _ := logCfg.Build()
+	defer logger.Sync()
 
-	provider, err := cfg.DefaultProvider()
-	if err != nil {
-		fmt.Printf("Error getting LLM provider: %v\n", err)
-		os.Exit(1)
+	// info prints status lines unless --quiet was given
+	info := func(format string, a ...interface{}) {
+		if !quiet {
+			fmt.Printf(format, a...)
+		}
 	}
-	llmClient := llm.NewClient(provider)
 
-	skillsRegistry := skills.NewRegistry(st)
-	app.RegisterSkills(cfg, st, skillsRegistry, logger, llmClient)
-
-	agentInstance := agent.New(llmClient, nil, st, logger, pm)
-	agentInstance.SetSkillsRegistry(skillsRegistry)
+	var (
+		chatter agent.Chatter
+		limits  config.RateLimitConfig
+	)
+	if client := RemoteClient(); client != nil {
+		// The gateway applies its own rate limits
+		chatter = remoteAgent{client: client}
+		info("🌐 Sending the batch to the gateway at %s\n", client.URL())
+	} else {
+		agentInstance, st, providerLimits := localBatchAgent(tier, logger)
+		defer st.Close()
+		chatter, limits = agentInstance, providerLimits
+	}
 
 	batchConfig := batch.Config{
 		MaxConcurrency: concurrency,
@@ -115,41 +152,35 @@ func HandleBatchCommand(args []string) {
 		RetryDelay:     1 * time.Second,
 		SkipInvalid:    true,
 		ValidateInput:  true,
+		OutputFormat:   outputFormat,
+		Schema:         schema,
 	}
 
-	baseProcessor := batch.NewProcessor(agentInstance, batchConfig, logger)
+	baseProcessor := batch.NewProcessor(chatter, batchConfig, logger)
+	if showProgress {
+		baseProcessor.SetProgressRenderer(batch.NewProgressRenderer(os.Stderr, pricePer1K))
+	}
 
-	var result *batch.Result
+	var (
+		result *batch.Result
+		err    error
+	)
 
 	ctx := context.Background()
 
-	if tier != "" {
-		var rlConfig batch.RateLimiterConfig
-		switch tier {
-		case "3":
-			rlConfig = batch.Tier3Config()
-			fmt.Println("⚡ Using Tier 3 rate limits: 200 concurrent, 5000 RPM, 3M TPM")
-		case "4":
-			rlConfig = batch.Tier4Config()
-			fmt.Println("⚡ Using Tier 4 rate limits: 400 concurrent, 5000 RPM, 4M TPM")
-		case "5":
-			rlConfig = batch.Tier5Config()
-			fmt.Println("⚡ Using Tier 5 rate limits: 1000 concurrent, 10000 RPM, 5M TPM")
-		default:
-			fmt.Printf("Unknown tier: %s. Using default limits.\n", tier)
-			rlConfig = batch.RateLimiterConfig{MaxConcurrency: concurrency}
-		}
-
+	if limits.MaxConcurrency > 0 {
+		// RPM and TPM are already enforced by the client's shared limiter;
+		// the processor only needs to size its worker pool.
+		rlConfig := batch.RateLimiterConfig{MaxConcurrency: limits.MaxConcurrency}
 		processor := batch.NewRateLimitedProcessor(baseProcessor, rlConfig)
-		fmt.Printf("🤖 Processing batch file: %s\n", inputFile)
-		fmt.Printf("   Concurrency: %d | Timeout: %ds | Tier: %s\n", rlConfig.MaxConcurrency, timeout, tier)
-		fmt.Println()
+		info("⚡ Rate limits: %d concurrent, %s RPM, %s TPM\n", limits.MaxConcurrency, limitString(limits.RPM), limitString(limits.TPM))
+		info("🤖 Processing batch file: %s\n", inputFile)
+		info("   Concurrency: %d | Timeout: %ds\n\n", rlConfig.MaxConcurrency, timeout)
 
 		result, err = processor.ProcessFileWithRateLimit(ctx, inputFile, outputFile)
 	} else {
-		fmt.Printf("🤖 Processing batch file: %s\n", inputFile)
-		fmt.Printf("   Concurrency: %d | Timeout: %ds\n", concurrency, timeout)
-		fmt.Println()
+		info("🤖 Processing batch file: %s\n", inputFile)
+		info("   Concurrency: %d | Timeout: %ds\n\n", concurrency, timeout)
 
 		result, err = baseProcessor.ProcessFile(ctx, inputFile, outputFile)
 	}
@@ -161,7 +192,7 @@ func HandleBatchCommand(args []string) {
 	fmt.Println(result.Summary())
 
 	if outputFile != "" {
-		fmt.Printf("✓ Results saved to: %s\n", outputFile)
+		info("✓ Results saved to: %s\n", outputFile)
 	}
 
 	if result.Failed > 0 {
@@ -173,3 +204,60 @@ func HandleBatchCommand(args []string) {
 		}
 	}
 }
+
+// localBatchAgent builds the agent that answers the batch on this machine,
+// with the provider's rate limits, or --tier's
+func localBatchAgent(tier string, logger *zap.Logger) (*agent.Agent, *store.Store, config.RateLimitConfig) {
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("Error initializing store: %v\n", err)
+		os.Exit(1)
+	}
+
+	workspacePath := cfg.Storage.DataDir
+	pm, err := persona.NewPersonaManager(workspacePath, logger)
+	if err != nil {
+		logger.Warn("Failed to initialize persona manager", zap.Error(err))
+	}
+
+	provider, err := cfg.DefaultProvider()
+	if err != nil {
+		fmt.Printf("Error getting LLM provider: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Rate limits come from the provider config; --tier overrides them with a
+	// Moonshot/Kimi preset. The LLM client enforces them for every request.
+	if tier != "" {
+		preset, ok := batch.TierConfig(tier)
+		if ok {
+			provider.RateLimit = preset.ProviderLimits()
+		} else {
+			fmt.Printf("Unknown tier: %s. Using provider limits.\n", tier)
+		}
+	}
+	limits := provider.RateLimit
+	llmClient := llm.NewClient(provider)
+
+	skillsRegistry := skills.NewRegistry(st)
+	app.RegisterSkills(cfg, st, skillsRegistry, logger, llmClient)
+
+	agentInstance := agent.New(llmClient, nil, st, logger, pm)
+	agentInstance.SetSkillsRegistry(skillsRegistry)
+	agentInstance.SetLoopOptions(agent.LoopOptionsFromConfig(cfg.Agent.Loop))
+
+	return agentInstance, st, limits
+}
+
+func limitString(n int) string {
+	if n <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d", n)
+}
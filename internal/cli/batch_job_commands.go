@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/batch"
+	"github.com/gmsas95/myrai-cli/internal/remote"
+)
+
+// batchJobPollInterval is how often 'batch submit --wait' checks on a job
+const batchJobPollInterval = 2 * time.Second
+
+// handleBatchJobCommand submits batches to a gateway, which processes them
+// in the background, and checks on, cancels and downloads its jobs
+func handleBatchJobCommand(command string, args []string) {
+	client := batchJobClient(args)
+	ctx, cancel := context.WithTimeout(context.Background(), remoteRequestTimeout)
+	defer cancel()
+
+	id := batchJobArg(args)
+
+	switch command {
+	case "submit":
+		input := flagValue(args, "-i", "--input")
+		if input == "" {
+			input = id
+		}
+		if input == "" {
+			fmt.Println("Usage: myrai batch submit -i <input_file> --remote <url> [--wait]")
+			os.Exit(1)
+		}
+		data, err := os.ReadFile(input)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		job, err := client.SubmitBatch(ctx, input, data)
+		if err != nil {
+			fmt.Printf("❌ Failed to submit the batch: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Submitted %s: %d items queued on %s\n", job.ID, job.Total, client.URL())
+		if !hasFlag(args, "--wait", "-w") {
+			fmt.Printf("Check on it with 'myrai batch status %s' and fetch the answers with 'myrai batch results %s'.\n", job.ID, job.ID)
+			return
+		}
+		job = waitForBatchJob(client, job.ID)
+		fmt.Println()
+		printBatchJob(job)
+
+	case "status":
+		if id == "" {
+			jobs, err := client.BatchJobs(ctx, 20)
+			if err != nil {
+				fmt.Printf("❌ Failed to list batch jobs: %v\n", err)
+				os.Exit(1)
+			}
+			if len(jobs) == 0 {
+				fmt.Println("No batch jobs on the gateway.")
+				return
+			}
+			for _, job := range jobs {
+				fmt.Printf("%-22s %-10s %5d/%-5d %-24s %s\n", job.ID, job.Status, job.Processed, job.Total,
+					truncateString(job.Source, 24), job.CreatedAt.Local().Format("2006-01-02 15:04"))
+			}
+			return
+		}
+		job, err := client.BatchJob(ctx, id)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		printBatchJob(job)
+
+	case "cancel":
+		if id == "" {
+			fmt.Println("Usage: myrai batch cancel <job_id> --remote <url>")
+			os.Exit(1)
+		}
+		job, err := client.CancelBatch(ctx, id)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if job.Status == batch.JobRunning {
+			fmt.Printf("✓ Cancelling %s; the items in flight will finish first\n", job.ID)
+		} else {
+			fmt.Printf("✓ Cancelled %s\n", job.ID)
+		}
+
+	case "results":
+		if id == "" {
+			fmt.Println("Usage: myrai batch results <job_id> --remote <url> [-o <output_file>] [-f <format>]")
+			os.Exit(1)
+		}
+		output := flagValue(args, "-o", "--output")
+		format := flagValue(args, "-f", "--output-format")
+		if format == "" && output == "" {
+			format = batch.FormatJSONL
+		}
+		format = batch.ResolveOutputFormat(format, output)
+		if !batch.ValidOutputFormat(format) {
+			fmt.Printf("❌ Unknown output format: %s (use text, json, jsonl or csv)\n", format)
+			os.Exit(1)
+		}
+
+		job, err := client.BatchJob(ctx, id)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if !job.Finished() {
+			fmt.Fprintf(os.Stderr, "⚠️  %s is %s; these are the %d of %d answers so far\n", job.ID, job.Status, job.Processed, job.Total)
+		}
+
+		out := os.Stdout
+		if output != "" {
+			file, err := os.Create(output)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			defer file.Close()
+			out = file
+		}
+		if err := client.BatchResults(ctx, job.ID, format, out); err != nil {
+			fmt.Printf("❌ Failed to download results: %v\n", err)
+			os.Exit(1)
+		}
+		if output != "" {
+			fmt.Printf("✓ Results saved to: %s\n", output)
+		}
+	}
+}
+
+// batchJobClient is the gateway given with --remote, before or after the
+// command
+func batchJobClient(args []string) *remote.Client {
+	if url := flagValue(args, "--remote"); url != "" {
+		os.Setenv(RemoteURLEnv, url)
+	}
+	if token := flagValue(args, "--token"); token != "" {
+		os.Setenv(RemoteTokenEnv, token)
+	}
+	client := RemoteClient()
+	if client == nil {
+		fmt.Println("❌ Batch jobs run on a gateway; pass --remote <url> --token <token>, or set MYRAI_REMOTE_URL and MYRAI_REMOTE_TOKEN.")
+		fmt.Println("To process a batch here, use 'myrai batch -i <file>'.")
+		os.Exit(1)
+	}
+	return client
+}
+
+// batchJobArg is the first argument that isn't a flag or a flag's value
+func batchJobArg(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-i", "--input", "-o", "--output", "-f", "--output-format", "--remote", "--token":
+			i++
+			continue
+		}
+		if !strings.HasPrefix(args[i], "-") {
+			return args[i]
+		}
+	}
+	return ""
+}
+
+// waitForBatchJob polls the job until it finishes, showing its progress
+func waitForBatchJob(client *remote.Client, id string) *batch.Job {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), remoteRequestTimeout)
+		job, err := client.BatchJob(ctx, id)
+		cancel()
+		if err != nil {
+			fmt.Printf("\n❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\r⏳ %s: %d/%d done", job.Status, job.Processed, job.Total)
+		if job.Finished() {
+			fmt.Println()
+			return job
+		}
+		time.Sleep(batchJobPollInterval)
+	}
+}
+
+func printBatchJob(job *batch.Job) {
+	fmt.Printf("Job:       %s\n", job.ID)
+	fmt.Printf("Status:    %s\n", job.Status)
+	fmt.Printf("Input:     %s\n", job.Source)
+	percent := 0
+	if job.Total > 0 {
+		percent = job.Processed * 100 / job.Total
+	}
+	fmt.Printf("Progress:  %d/%d (%d%%)\n", job.Processed, job.Total, percent)
+	fmt.Printf("Succeeded: %d | Failed: %d | Skipped: %d\n", job.Succeeded, job.Failed, job.Skipped)
+	fmt.Printf("Tokens:    %d\n", job.TokensUsed)
+	fmt.Printf("Submitted: %s\n", job.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	if job.StartedAt != nil {
+		fmt.Printf("Started:   %s\n", job.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if job.FinishedAt != nil {
+		fmt.Printf("Finished:  %s\n", job.FinishedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if job.Error != "" {
+		fmt.Printf("Error:     %s\n", job.Error)
+	}
+}
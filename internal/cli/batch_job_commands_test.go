@@ -0,0 +1,21 @@
+package cli
+
+import "testing"
+
+func TestBatchJobArg(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"batch_1"}, "batch_1"},
+		{[]string{"--remote", "http://host:8080", "batch_1", "-o", "out.csv"}, "batch_1"},
+		{[]string{"-o", "out.csv", "--wait", "batch_2"}, "batch_2"},
+		{[]string{"--remote", "http://host:8080", "--token", "secret"}, ""},
+	}
+	for _, tt := range tests {
+		if got := batchJobArg(tt.args); got != tt.want {
+			t.Errorf("batchJobArg(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/skills/calendar"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+// HandleCalendarCommand imports and exports iCalendar files. Without
+// --profile it acts on the default user's calendar.
+func HandleCalendarCommand(args []string) {
+	if len(args) == 0 {
+		PrintCalendarHelp()
+		return
+	}
+
+	profileName := ""
+	rng := "month"
+	out := ""
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--profile" && i+1 < len(args):
+			profileName = args[i+1]
+			i++
+		case args[i] == "--range" && i+1 < len(args):
+			rng = args[i+1]
+			i++
+		case (args[i] == "--out" || args[i] == "-o") && i+1 < len(args):
+			out = args[i+1]
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	if len(rest) == 0 {
+		PrintCalendarHelp()
+		return
+	}
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("Error initializing store: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	calendarStore, err := calendar.NewStore(st.DB())
+	if err != nil {
+		fmt.Printf("Error initializing calendar: %v\n", err)
+		os.Exit(1)
+	}
+
+	// A shared calendar belongs to the shared user whichever profile asks
+	shared := slices.ContainsFunc(cfg.Household.Shared, func(s string) bool { return strings.EqualFold(s, "calendar") })
+	userID := household.SharedUserID
+	if profileName != "" && !shared {
+		hh, err := household.NewManager(st.DB())
+		if err != nil {
+			fmt.Printf("Error initializing household: %v\n", err)
+			os.Exit(1)
+		}
+		profile, err := hh.Get(profileName)
+		if err != nil || profile == nil {
+			fmt.Printf("❌ Profile not found: %s\n", profileName)
+			os.Exit(1)
+		}
+		userID = profile.UserID
+	}
+
+	switch rest[0] {
+	case "export":
+		from, to, err := calendar.ExportRange(rng, time.Now())
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		events, err := calendarStore.GetEventsForExport(userID, from, to)
+		if err != nil {
+			fmt.Printf("❌ Failed to load events: %v\n", err)
+			os.Exit(1)
+		}
+
+		if out == "-" {
+			if err := calendar.ExportICS(os.Stdout, "Myrai", events); err != nil {
+				os.Exit(1)
+			}
+			return
+		}
+		if out == "" {
+			out = fmt.Sprintf("myrai-%s-%s.ics", strings.ToLower(rng), time.Now().Format("2006-01-02"))
+		}
+		f, err := os.Create(out)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if err := calendar.ExportICS(f, "Myrai", events); err != nil {
+			f.Close()
+			fmt.Printf("❌ Failed to write %s: %v\n", out, err)
+			os.Exit(1)
+		}
+		if err := f.Close(); err != nil {
+			fmt.Printf("❌ Failed to write %s: %v\n", out, err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Exported %d events to %s\n", len(events), out)
+
+	case "import":
+		if len(rest) < 2 {
+			fmt.Println("Usage: myrai calendar import <file.ics> [--profile name]")
+			os.Exit(1)
+		}
+		f, err := os.Open(rest[1])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		result, err := calendar.ImportICS(calendarStore, userID, f)
+		if err != nil {
+			fmt.Printf("❌ Import failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Imported %s: %d added, %d updated", rest[1], result.Added, result.Updated)
+		if result.Skipped > 0 {
+			fmt.Printf(", %d skipped (duplicates, cancelled or unreadable)", result.Skipped)
+		}
+		fmt.Println()
+
+	default:
+		PrintCalendarHelp()
+	}
+}
@@ -2,13 +2,19 @@ package cli
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
 	"github.com/gmsas95/myrai-cli/internal/app"
 	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/daemon"
+	"github.com/gmsas95/myrai-cli/internal/doctor"
 	"github.com/gmsas95/myrai-cli/internal/editor"
 	"github.com/gmsas95/myrai-cli/internal/onboarding"
 	"github.com/gmsas95/myrai-cli/internal/persona"
@@ -28,7 +34,7 @@ func HandleProjectCommand(args []string) {
 	logger, _ := zap.NewDevelopment()
 	defer logger.Sync()
 
-	workspace := onboarding.GetWorkspacePath()
+	workspace := workspacePath()
 	pm, err := persona.NewPersonaManager(workspace, logger)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
@@ -64,12 +70,24 @@ func HandleProjectCommand(args []string) {
 			os.Exit(1)
 		}
 
+		all := slices.Contains(args[1:], "--all")
+		if !all {
+			projects = slices.DeleteFunc(projects, func(p *persona.Project) bool { return p.IsArchived })
+		}
+
 		if len(projects) == 0 {
 			fmt.Println("No projects found. Create one with: myrai project new <name> <type>")
+			if !all {
+				fmt.Println("Archived projects are listed with: myrai project list --all")
+			}
 			return
 		}
 
-		fmt.Println("Active Projects:")
+		if all {
+			fmt.Println("Projects:")
+		} else {
+			fmt.Println("Active Projects:")
+		}
 		fmt.Println("================")
 		for _, p := range projects {
 			status := "📁"
@@ -91,6 +109,9 @@ func HandleProjectCommand(args []string) {
 
 		if err := pm.SwitchProject(name); err != nil {
 			fmt.Printf("Error switching project: %v\n", err)
+			if errors.Is(err, persona.ErrArchived) {
+				fmt.Printf("Unarchive it first with: myrai project unarchive %s\n", name)
+			}
 			os.Exit(1)
 		}
 		fmt.Printf("✓ Switched to project '%s'\n", name)
@@ -102,20 +123,50 @@ func HandleProjectCommand(args []string) {
 		}
 		name := args[1]
 
-		projects, _ := pm.ListProjects()
-		var found bool
-		for _, p := range projects {
-			if p.Name == name {
-				found = true
-				break
-			}
+		if err := pm.ArchiveProject(name); err != nil {
+			fmt.Printf("Error archiving project: %v\n", err)
+			os.Exit(1)
 		}
-		if !found {
-			fmt.Printf("Project '%s' not found\n", name)
+		fmt.Printf("✓ Archived project '%s'\n", name)
+
+	case "unarchive", "restore":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai project unarchive <name>")
 			os.Exit(1)
 		}
+		name := args[1]
 
-		fmt.Printf("✓ Archived project '%s'\n", name)
+		if err := pm.UnarchiveProject(name); err != nil {
+			fmt.Printf("Error unarchiving project: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Unarchived project '%s'\n", name)
+
+	case "rename", "mv":
+		if len(args) < 3 {
+			fmt.Println("Usage: myrai project rename <name> <new-name>")
+			os.Exit(1)
+		}
+
+		project, err := pm.RenameProject(args[1], args[2])
+		if err != nil {
+			fmt.Printf("Error renaming project: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Renamed project '%s' to '%s'\n", args[1], project.Name)
+
+	case "duplicate", "copy", "cp":
+		if len(args) < 3 {
+			fmt.Println("Usage: myrai project duplicate <name> <new-name>")
+			os.Exit(1)
+		}
+
+		project, err := pm.DuplicateProject(args[1], args[2])
+		if err != nil {
+			fmt.Printf("Error duplicating project: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Created project '%s' from '%s' (%s)\n", project.Name, args[1], project.Type)
 
 	case "delete":
 		if len(args) < 2 {
@@ -140,19 +191,201 @@ func HandleProjectCommand(args []string) {
 		}
 		fmt.Printf("✓ Deleted project '%s'\n", name)
 
+	case "glossary":
+		handleProjectGlossary(pm, args[1:])
+
+	case "tools":
+		handleProjectTools(pm, args[1:])
+
 	default:
 		PrintProjectHelp()
 	}
 }
 
+// handleProjectGlossary lists and edits a project's glossary. It works on
+// the current project unless --project names another.
+func handleProjectGlossary(pm *persona.PersonaManager, args []string) {
+	projectName, rest := targetProject(pm, args)
+
+	action := "list"
+	if len(rest) > 0 {
+		action = rest[0]
+	}
+
+	switch action {
+	case "list", "ls":
+		terms, err := pm.Glossary(projectName)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(terms) == 0 {
+			fmt.Printf("The glossary of '%s' is empty.\n", projectName)
+			fmt.Println("Add a term with: myrai project glossary add <term> <definition>")
+			return
+		}
+		fmt.Printf("Glossary for %s:\n", projectName)
+		fmt.Println("================")
+		for _, t := range terms {
+			source := ""
+			if t.Source != persona.GlossaryManual {
+				source = fmt.Sprintf(" (learned from %s)", t.Source)
+			}
+			fmt.Printf("%s: %s%s\n", t.Term, t.Definition, source)
+		}
+
+	case "add", "set":
+		if len(rest) < 3 {
+			fmt.Println("Usage: myrai project glossary add <term> <definition>")
+			os.Exit(1)
+		}
+		term := rest[1]
+		definition := strings.Join(rest[2:], " ")
+		if err := pm.SetGlossaryTerm(projectName, term, definition); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Added '%s' to the glossary of '%s'\n", term, projectName)
+
+	case "remove", "rm":
+		if len(rest) < 2 {
+			fmt.Println("Usage: myrai project glossary remove <term>")
+			os.Exit(1)
+		}
+		term := strings.Join(rest[1:], " ")
+		if err := pm.RemoveGlossaryTerm(projectName, term); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Removed '%s' from the glossary of '%s'\n", term, projectName)
+
+	default:
+		PrintProjectHelp()
+	}
+}
+
+// handleProjectTools shows and edits a project's tool profile. It works on
+// the current project unless --project names another.
+func handleProjectTools(pm *persona.PersonaManager, args []string) {
+	projectName, rest := targetProject(pm, args)
+
+	profile, err := pm.ToolProfile(projectName)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if profile == nil {
+		profile = &persona.ToolProfile{}
+	}
+
+	action := "show"
+	if len(rest) > 0 {
+		action = rest[0]
+	}
+
+	switch action {
+	case "show", "list", "ls":
+		fmt.Printf("Tools for %s:\n", projectName)
+		fmt.Println("================")
+		fmt.Println(profile)
+		return
+
+	case "allow", "deny":
+		if len(rest) < 2 {
+			fmt.Printf("Usage: myrai project tools %s <skill|tool>...\n", action)
+			os.Exit(1)
+		}
+		for _, name := range rest[1:] {
+			profile.Allow = slices.DeleteFunc(profile.Allow, func(n string) bool { return n == name })
+			profile.Deny = slices.DeleteFunc(profile.Deny, func(n string) bool { return n == name })
+			if action == "allow" {
+				profile.Allow = append(profile.Allow, name)
+			} else {
+				profile.Deny = append(profile.Deny, name)
+			}
+		}
+
+	case "remove", "rm":
+		if len(rest) < 2 {
+			fmt.Println("Usage: myrai project tools remove <skill|tool>...")
+			os.Exit(1)
+		}
+		for _, name := range rest[1:] {
+			profile.Allow = slices.DeleteFunc(profile.Allow, func(n string) bool { return n == name })
+			profile.Deny = slices.DeleteFunc(profile.Deny, func(n string) bool { return n == name })
+		}
+
+	case "model":
+		if len(rest) < 2 {
+			fmt.Println("Usage: myrai project tools model <model|default>")
+			os.Exit(1)
+		}
+		profile.Model = rest[1]
+		if profile.Model == "default" {
+			profile.Model = ""
+		}
+
+	case "reset":
+		profile = &persona.ToolProfile{}
+
+	default:
+		PrintProjectHelp()
+		return
+	}
+
+	if err := pm.SetToolProfile(projectName, profile); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Updated the tools of '%s'\n", projectName)
+	fmt.Println(profile)
+}
+
+// targetProject takes --project <name> out of args, returning that project,
+// or the current one without it, and the remaining arguments
+func targetProject(pm *persona.PersonaManager, args []string) (string, []string) {
+	var projectName string
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--project" && i+1 < len(args) {
+			projectName = args[i+1]
+			i++
+			continue
+		}
+		rest = append(rest, args[i])
+	}
+	if projectName == "" {
+		current := pm.GetCurrentProject()
+		if current == nil {
+			fmt.Println("No current project. Switch to one with: myrai project switch <name>")
+			os.Exit(1)
+		}
+		projectName = current.Name
+	}
+	return projectName, rest
+}
+
+// workspacePath returns the data directory the agent keeps persona and
+// project files in, so these commands change what it uses
+func workspacePath() string {
+	if cfg, err := config.Load("", ""); err == nil && cfg.Storage.DataDir != "" {
+		return cfg.Storage.DataDir
+	}
+	return onboarding.GetWorkspacePath()
+}
+
 func HandlePersonaCommand(args []string) {
-	if len(args) == 0 {
-		logger, _ := zap.NewDevelopment()
-		workspace := onboarding.GetWorkspacePath()
-		pm, _ := persona.NewPersonaManager(workspace, logger)
+	logger, _ := zap.NewDevelopment()
+	workspace := workspacePath()
+	pm, err := persona.NewPersonaManager(workspace, logger)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 
+	if len(args) == 0 {
 		identity := pm.GetIdentity()
-		fmt.Println("Current AI Identity:")
+		fmt.Printf("Current AI Identity (%s persona):\n", pm.CurrentPersona())
 		fmt.Println("====================")
 		fmt.Printf("Name: %s\n", identity.Name)
 		fmt.Printf("Personality: %s\n", identity.Personality)
@@ -160,42 +393,142 @@ func HandlePersonaCommand(args []string) {
 		fmt.Printf("Values: %v\n", identity.Values)
 		fmt.Printf("Expertise: %v\n", identity.Expertise)
 		fmt.Println()
-		fmt.Println("To edit: myrai persona edit")
+		fmt.Println("To edit: myrai persona wizard, or myrai persona set <field> <value>")
 		return
 	}
 
+	// The persona edit and show work on, the current one by default
+	name := pm.CurrentPersona()
+	if len(args) > 1 {
+		name = args[1]
+	}
+
 	switch args[0] {
 	case "edit":
-		workspace := onboarding.GetWorkspacePath()
-		identityPath := workspace + "/IDENTITY.md"
-
-		if err := editor.Open(identityPath); err != nil {
+		if _, err := pm.PersonaIdentity(name); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := editor.Open(pm.IdentityPath(name)); err != nil {
 			fmt.Printf("Error opening editor: %v\n", err)
 			os.Exit(1)
 		}
 
+		// Free-form edits may not parse back the way they read
+		identity, err := pm.PersonaIdentity(name)
+		if err == nil {
+			err = identity.Validate()
+		}
+		if err != nil {
+			fmt.Printf("⚠️  The identity file has a problem: %v\n", err)
+			fmt.Printf("Fix it with: myrai persona wizard %s\n", name)
+		}
+
+	case "wizard":
+		HandlePersonaWizard(pm, name)
+
+	case "set":
+		// args[1] is the field here, so the persona comes from --persona
+		name = pm.CurrentPersona()
+		var rest []string
+		for i := 1; i < len(args); i++ {
+			if args[i] == "--persona" && i+1 < len(args) {
+				name = args[i+1]
+				i++
+				continue
+			}
+			rest = append(rest, args[i])
+		}
+		HandlePersonaSet(pm, name, rest)
+
 	case "show":
-		workspace := onboarding.GetWorkspacePath()
-		data, err := os.ReadFile(workspace + "/IDENTITY.md")
+		data, err := os.ReadFile(pm.IdentityPath(name))
 		if err != nil {
 			fmt.Printf("Error reading identity: %v\n", err)
 			os.Exit(1)
 		}
 		fmt.Println(string(data))
 
+	case "list", "ls":
+		names, err := pm.ListPersonas()
+		if err != nil {
+			fmt.Printf("Error listing personas: %v\n", err)
+			os.Exit(1)
+		}
+		current := pm.CurrentPersona()
+		fmt.Println("Personas:")
+		fmt.Println("=========")
+		for _, n := range names {
+			status := "  "
+			if n == current {
+				status = "▶️"
+			}
+			identity, err := pm.PersonaIdentity(n)
+			if err != nil {
+				fmt.Printf("%s %s (%v)\n", status, n, err)
+				continue
+			}
+			fmt.Printf("%s %s - %s\n", status, n, identity.Name)
+		}
+
+	case "new", "create":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai persona new <name> [--from <persona>]")
+			os.Exit(1)
+		}
+		from := pm.CurrentPersona()
+		for i := 2; i < len(args)-1; i++ {
+			if args[i] == "--from" {
+				from = args[i+1]
+			}
+		}
+		identity, err := pm.PersonaIdentity(from)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := pm.CreatePersona(name, identity); err != nil {
+			fmt.Printf("Error creating persona: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Created persona '%s' from '%s'\n", name, from)
+		fmt.Printf("Edit it with: myrai persona edit %s\n", name)
+
+	case "use", "switch":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai persona use <name>")
+			os.Exit(1)
+		}
+		if err := pm.SwitchPersona(name); err != nil {
+			fmt.Printf("Error switching persona: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Now answering as '%s'\n", name)
+
+	case "delete", "rm":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai persona delete <name>")
+			os.Exit(1)
+		}
+		if err := pm.DeletePersona(name); err != nil {
+			fmt.Printf("Error deleting persona: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Deleted persona '%s'\n", name)
+
 	case "proposals", "proposal", "history", "rollback", "analyze", "config":
 		// Evolution system commands
 		HandlePersonaEvolutionCommand(args)
 
 	default:
-		fmt.Println("Usage: myrai persona [edit|show|proposals|history|rollback|analyze]")
+		fmt.Println("Usage: myrai persona [list|use|new|delete|wizard|set|edit|show|proposals|history|rollback|analyze]")
 	}
 }
 
 func HandleUserCommand(args []string) {
 	if len(args) == 0 {
 		logger, _ := zap.NewDevelopment()
-		workspace := onboarding.GetWorkspacePath()
+		workspace := workspacePath()
 		pm, _ := persona.NewPersonaManager(workspace, logger)
 
 		user := pm.GetUserProfile()
@@ -212,7 +545,7 @@ func HandleUserCommand(args []string) {
 
 	switch args[0] {
 	case "edit":
-		workspace := onboarding.GetWorkspacePath()
+		workspace := workspacePath()
 		userPath := workspace + "/USER.md"
 
 		if err := editor.Open(userPath); err != nil {
@@ -221,7 +554,7 @@ func HandleUserCommand(args []string) {
 		}
 
 	case "show":
-		workspace := onboarding.GetWorkspacePath()
+		workspace := workspacePath()
 		data, err := os.ReadFile(workspace + "/USER.md")
 		if err != nil {
 			fmt.Printf("Error reading profile: %v\n", err)
@@ -287,11 +620,58 @@ func HandleConfigCommand(args []string) {
 		}
 		fmt.Println(string(data))
 
+	case "validate", "check":
+		path := ""
+		if len(args) > 1 {
+			path = args[1]
+		}
+		if !validateConfig(path) {
+			os.Exit(1)
+		}
+
+	case "schema":
+		data, err := json.MarshalIndent(config.Schema(), "", "  ")
+		if err != nil {
+			fmt.Printf("Error generating schema: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+
 	default:
 		PrintConfigHelp()
 	}
 }
 
+// validateConfig prints the problems in a config file, or the default one,
+// and reports whether it loads
+func validateConfig(path string) bool {
+	issues, err := config.Validate(path, "")
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+
+	failures := 0
+	for _, issue := range issues {
+		if issue.Warning {
+			fmt.Printf("⚠️  %s\n", issue)
+		} else {
+			fmt.Printf("❌ %s\n", issue)
+			failures++
+		}
+	}
+	switch {
+	case failures > 0:
+		fmt.Printf("\n%d error(s), %d warning(s)\n", failures, len(issues)-failures)
+		return false
+	case len(issues) > 0:
+		fmt.Printf("\n✅ Config is valid, with %d warning(s)\n", len(issues))
+	default:
+		fmt.Println("✅ Config is valid")
+	}
+	return true
+}
+
 func printConfigValue(cfg *config.Config, key string) {
 	switch key {
 	case "llm.default_provider":
@@ -316,12 +696,32 @@ func HandleSkillsCommand(args []string) {
 	logger, _ := zap.NewDevelopment()
 	defer logger.Sync()
 
+	if client := RemoteClient(); client != nil {
+		if len(args) > 0 && args[0] != "list" {
+			fmt.Println("❌ Only 'myrai skills list' works with --remote; manage skills on the gateway's machine")
+			os.Exit(1)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), remoteRequestTimeout)
+		defer cancel()
+		printRemoteSkills(ctx, client)
+		return
+	}
+
 	cfg, err := config.Load("", "")
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Packages are managed without loading the skills
+	if len(args) > 0 {
+		switch args[0] {
+		case "install", "update", "remove", "uninstall":
+			handleSkillPackages(cfg, args, logger)
+			return
+		}
+	}
+
 	st, err := store.New(cfg)
 	if err != nil {
 		fmt.Printf("Error initializing store: %v\n", err)
@@ -371,7 +771,7 @@ func HandleSkillsCommand(args []string) {
 		}
 
 	default:
-		fmt.Println("Usage: myrai skills [list|info <skill>]")
+		fmt.Println("Usage: myrai skills [list|info <skill>|install <source>|update [name]|remove <name>]")
 	}
 }
 
@@ -444,25 +844,17 @@ func HandleGatewayCommand(args []string, application *app.App) {
 
 	switch args[0] {
 	case "run", "start":
+		release, err := daemon.Acquire(daemon.PidFile(application.Config.Storage.DataDir))
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer release()
+
 		fmt.Println("Starting Myrai server...")
 		fmt.Printf("URL: http://localhost:%d\n", application.Config.Server.Port)
 		application.RunServer()
 
-	case "stop":
-		fmt.Println("To stop the server, press Ctrl+C in the terminal where it's running")
-		fmt.Println("Or use: pkill -f myrai")
-
-	case "status":
-		fmt.Println("Gateway Status:")
-		fmt.Println("==============")
-		fmt.Printf("Address: %s:%d\n", application.Config.Server.Address, application.Config.Server.Port)
-		fmt.Printf("URL: http://localhost:%d\n", application.Config.Server.Port)
-		fmt.Printf("Data Directory: %s\n", application.Config.Storage.DataDir)
-
-	case "logs":
-		fmt.Println("Logs are written to stdout/stderr")
-		fmt.Println("To save logs to a file: myrai gateway run > myrai.log 2>&1")
-
 	default:
 		PrintGatewayHelp()
 	}
@@ -479,12 +871,21 @@ func HandleStatusCommand() {
 	fmt.Println("===============")
 	fmt.Println()
 	fmt.Printf("Version: %s\n", Version)
-	fmt.Printf("Config:  %s\n", onboarding.GetWorkspacePath()+"/config.yaml")
+	if profile := config.ActiveProfile(); profile != "" {
+		fmt.Printf("Profile: %s\n", profile)
+	}
+	fmt.Printf("Config:  %s\n", cfg.File)
 	fmt.Printf("Data:    %s\n", cfg.Storage.DataDir)
 	fmt.Println()
+	printReloadStatus(cfg.Storage.DataDir)
 	fmt.Println("Server Configuration:")
 	fmt.Printf("  Address: %s:%d\n", cfg.Server.Address, cfg.Server.Port)
 	fmt.Printf("  URL: http://localhost:%d\n", cfg.Server.Port)
+	if pid, ok := daemon.Running(daemon.PidFile(cfg.Storage.DataDir)); ok {
+		fmt.Printf("  Gateway: ✅ running (pid %d)\n", pid)
+	} else {
+		fmt.Println("  Gateway: ❌ not running")
+	}
 	fmt.Println()
 	fmt.Println("Channels:")
 	fmt.Printf("  Telegram: %s\n", channelStatus(cfg.Channels.Telegram.Enabled))
@@ -496,35 +897,90 @@ func HandleStatusCommand() {
 	fmt.Println("Run 'myrai doctor' for diagnostics")
 }
 
+// printReloadStatus shows what the server's last config reload applied
+func printReloadStatus(dataDir string) {
+	status, err := app.ReadReloadStatus(dataDir)
+	if err != nil || status == nil {
+		return
+	}
+	fmt.Printf("Last config reload: %s\n", status.Time.Format("2006-01-02 15:04:05"))
+	switch {
+	case status.Error != "":
+		fmt.Printf("  ❌ Failed, kept the previous config: %s\n", status.Error)
+	case len(status.Reloaded) == 0:
+		fmt.Println("  Nothing to apply")
+	default:
+		fmt.Printf("  Applied: %s\n", strings.Join(status.Reloaded, ", "))
+	}
+	if len(status.RestartNeeded) > 0 {
+		fmt.Printf("  ⚠️  Restart to apply: %s\n", strings.Join(status.RestartNeeded, ", "))
+	}
+	fmt.Println()
+}
+
+// HandleDoctorCommand checks the setup, and tests the database, provider,
+// channel tokens, vector indexes and external tools for real
 func HandleDoctorCommand() {
 	fmt.Println("Myrai Diagnostics")
 	fmt.Println("====================")
 	fmt.Println()
 
 	issues := 0
+	report := func(r doctor.Result) {
+		if printDoctorResult(r) {
+			issues++
+		}
+	}
 
 	cfg, err := config.Load("", "")
 	if err != nil {
 		fmt.Println("❌ Config: Error loading configuration")
 		fmt.Printf("   %v\n", err)
-		issues++
-	} else {
-		fmt.Println("✅ Config: Loaded successfully")
+		fmt.Println()
+		fmt.Println("⚠️  Fix the configuration to run the other checks.")
+		return
 	}
+	fmt.Println("✅ Config: Loaded successfully")
 
+	ctx := context.Background()
 	if _, err := os.Stat(cfg.Storage.DataDir); os.IsNotExist(err) {
 		fmt.Println("❌ Data Directory: Does not exist")
 		issues++
 	} else {
 		fmt.Println("✅ Data Directory: Exists")
+
+		if st, err := store.New(cfg); err != nil {
+			report(doctor.Result{Name: "database", Status: doctor.Fail, Message: err.Error()})
+		} else {
+			report(doctor.CheckDatabase(ctx, st.DB()))
+			if cfg.Vector.Enabled {
+				if searcher, err := openVectorIndexes(cfg, st); err != nil {
+					report(doctor.Result{Name: "vector", Status: doctor.Fail, Message: err.Error()})
+				} else {
+					for _, r := range doctor.CheckVectorIndexes(ctx, searcher) {
+						report(r)
+					}
+				}
+			}
+			st.Close()
+		}
 	}
 
 	if cfg.LLM.DefaultProvider == "" {
 		fmt.Println("⚠️  LLM Provider: Not configured")
 		fmt.Println("   Run: myrai onboard")
 		issues++
+	} else if provider, err := cfg.DefaultProvider(); err != nil {
+		report(doctor.Result{Name: "llm", Status: doctor.Fail, Message: err.Error(), Fix: "Run: myrai onboard"})
 	} else {
-		fmt.Printf("✅ LLM Provider: %s\n", cfg.LLM.DefaultProvider)
+		report(doctor.CheckProvider(ctx, cfg.LLM.DefaultProvider, provider))
+	}
+
+	if cfg.Channels.Telegram.Enabled {
+		report(doctor.CheckTelegram(ctx, cfg.Channels.Telegram.BotToken))
+	}
+	if cfg.Channels.Discord.Enabled {
+		report(doctor.CheckDiscord(ctx, cfg.Channels.Discord.Token))
 	}
 
 	if _, err := exec.LookPath("curl"); err != nil {
@@ -547,6 +1003,13 @@ func HandleDoctorCommand() {
 		fmt.Println("✅ Chrome: Found")
 	}
 
+	if !slices.Contains(cfg.Skills.Disabled, "voice") {
+		report(doctor.CheckBinary(ctx, "ffmpeg", "voice messages", "Install: sudo apt-get install ffmpeg", "-version"))
+	}
+	if !slices.Contains(cfg.Skills.Disabled, "documents") {
+		report(doctor.CheckBinary(ctx, "tesseract", "OCR in the documents skill", "Install: sudo apt-get install tesseract-ocr", "--version"))
+	}
+
 	fmt.Println()
 	if issues == 0 {
 		fmt.Println("✅ All checks passed!")
@@ -554,3 +1017,23 @@ func HandleDoctorCommand() {
 		fmt.Printf("⚠️  Found %d issue(s). Run 'myrai onboard' to fix configuration.\n", issues)
 	}
 }
+
+// printDoctorResult prints a check's outcome and reports whether it's an
+// issue
+func printDoctorResult(r doctor.Result) bool {
+	icon := map[doctor.Status]string{
+		doctor.OK:   "✅",
+		doctor.Warn: "⚠️ ",
+		doctor.Fail: "❌",
+		doctor.Skip: "➖",
+	}[r.Status]
+	line := fmt.Sprintf("%s %s: %s", icon, r.Name, r.Message)
+	if r.LatencyMs > 0 {
+		line += fmt.Sprintf(" (%dms)", r.LatencyMs)
+	}
+	fmt.Println(line)
+	if r.Fix != "" {
+		fmt.Println("   " + r.Fix)
+	}
+	return r.Status == doctor.Warn || r.Status == doctor.Fail
+}
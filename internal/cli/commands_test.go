@@ -1,7 +1,12 @@
 package cli
 
 import (
+	"bufio"
+	"io"
+	"strings"
 	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/persona"
 )
 
 func TestChannelStatus(t *testing.T) {
@@ -46,6 +51,11 @@ func TestPrintFunctions(t *testing.T) {
 	PrintExtendedHelp()
 	PrintProjectHelp()
 	PrintBatchHelp()
+	PrintAliasHelp()
+	PrintHouseholdHelp()
+	PrintPlanHelp()
+	PrintLocaleHelp()
+	PrintUpgradeHelp()
 	PrintConfigHelp()
 	PrintChannelsHelp()
 	PrintGatewayHelp()
@@ -60,8 +70,37 @@ func TestHandleCommandsNoArgs(t *testing.T) {
 	HandleChannelsCommand([]string{})
 	HandleGatewayCommand([]string{}, nil)
 	HandleBatchCommand([]string{})
+	HandleAliasCommand([]string{})
+	HandleHouseholdCommand([]string{})
+	HandlePlanCommand([]string{})
+	HandleLocaleCommand([]string{})
 }
 
 func TestHandleBatchCommandHelp(t *testing.T) {
 	HandleBatchCommand([]string{"-h"})
 }
+
+func TestPromptIdentityField(t *testing.T) {
+	identity := &persona.Identity{Name: "Myrai", Voice: "Warm"}
+
+	// An invalid answer is asked again
+	reader := bufio.NewReader(strings.NewReader("# Heading\nTerse\n"))
+	if !promptIdentityField(reader, io.Discard, identity, "voice") || identity.Voice != "Terse" {
+		t.Errorf("Expected voice Terse, got %q", identity.Voice)
+	}
+
+	// Enter keeps the value, '-' clears it
+	reader = bufio.NewReader(strings.NewReader("\n-\n"))
+	promptIdentityField(reader, io.Discard, identity, "voice")
+	if identity.Voice != "Terse" {
+		t.Errorf("Expected Enter to keep the voice, got %q", identity.Voice)
+	}
+	promptIdentityField(reader, io.Discard, identity, "voice")
+	if identity.Voice != "" {
+		t.Errorf("Expected '-' to clear the voice, got %q", identity.Voice)
+	}
+
+	if promptIdentityField(reader, io.Discard, identity, "name") {
+		t.Error("Expected false once input runs out")
+	}
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/app"
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+// HandleConversationsCommand lists, shows, renames and deletes
+// conversations. Resuming a local one needs the whole app, so the caller
+// handles 'resume' with ConversationToResume unless a gateway is used.
+func HandleConversationsCommand(args []string) {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+	if args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		PrintConversationsHelp()
+		return
+	}
+	if client := RemoteClient(); client != nil {
+		handleRemoteConversations(client, args)
+		return
+	}
+
+	st := openConversationStore()
+	defer st.Close()
+
+	switch args[0] {
+	case "list", "ls":
+		convs, err := app.RecentConversations(st)
+		if err != nil {
+			fmt.Printf("❌ Failed to list conversations: %v\n", err)
+			os.Exit(1)
+		}
+		app.PrintConversationList(st, convs, "")
+		if len(convs) > 0 {
+			fmt.Println()
+			fmt.Println("Continue one with 'myrai conversations resume <number>'.")
+		}
+
+	case "show":
+		limit := 20
+		var rest []string
+		for i := 1; i < len(args); i++ {
+			if (args[i] == "--limit" || args[i] == "-n") && i+1 < len(args) {
+				n, err := strconv.Atoi(args[i+1])
+				if err != nil || n < 0 {
+					fmt.Printf("❌ Invalid limit: %s\n", args[i+1])
+					os.Exit(1)
+				}
+				limit = n
+				i++
+				continue
+			}
+			rest = append(rest, args[i])
+		}
+		conv := resolveConversationArg(st, rest, "show <number|id> [--limit n]")
+		fmt.Printf("💬 %s\n", app.ConversationTitle(st, conv))
+		fmt.Printf("   %s · %d messages · updated %s\n\n", conv.ID, conv.MessageCount, conv.UpdatedAt.Format("Jan 2, 3:04 PM"))
+		if err := app.PrintConversationMessages(st, conv.ID, limit); err != nil {
+			fmt.Printf("❌ Failed to read messages: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "rename", "title":
+		if len(args) < 3 {
+			fmt.Println("Usage: myrai conversations rename <number|id> <title>")
+			os.Exit(1)
+		}
+		conv := resolveConversationArg(st, args[1:2], "rename <number|id> <title>")
+		conv.Title = strings.Join(args[2:], " ")
+		if err := st.RenameConversation(conv.ID, conv.Title); err != nil {
+			fmt.Printf("❌ Failed to rename conversation: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✏️  Renamed to %q\n", conv.Title)
+
+	case "delete", "rm":
+		var refs []string
+		for _, arg := range args[1:] {
+			if !strings.HasPrefix(arg, "-") {
+				refs = append(refs, arg)
+			}
+		}
+		conv := resolveConversationArg(st, refs, "delete <number|id> [--forget] [-y]")
+		title := app.ConversationTitle(st, conv)
+
+		if hasFlag(args, "--forget") {
+			if !hasFlag(args, "--yes", "-y") && !confirmPrivacy(fmt.Sprintf("This permanently deletes %q and its messages.", title)) {
+				return
+			}
+			if err := st.ForgetConversation(conv.ID); err != nil {
+				fmt.Printf("❌ Failed to delete conversation: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("🗑️  Permanently deleted %q\n", title)
+			return
+		}
+
+		if !hasFlag(args, "--yes", "-y") && !confirmPrivacy(fmt.Sprintf("This deletes %q.", title)) {
+			return
+		}
+		if err := st.DeleteConversation(conv.ID); err != nil {
+			fmt.Printf("❌ Failed to delete conversation: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🗑️  Deleted %q; --forget also erases its messages\n", title)
+
+	default:
+		fmt.Printf("Unknown conversations command: %s\n\n", args[0])
+		PrintConversationsHelp()
+		os.Exit(1)
+	}
+}
+
+// ConversationToResume returns the conversation 'myrai conversations resume'
+// names, exiting if it can't be found. The store is closed again so the app
+// can open it.
+func ConversationToResume(args []string) *store.Conversation {
+	st := openConversationStore()
+	defer st.Close()
+	return resolveConversationArg(st, args, "resume <number|id>")
+}
+
+func openConversationStore() *store.Store {
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("Error initializing store: %v\n", err)
+		os.Exit(1)
+	}
+	return st
+}
+
+// resolveConversationArg finds the conversation in args, exiting with usage
+// if there isn't exactly one
+func resolveConversationArg(st *store.Store, args []string, usage string) *store.Conversation {
+	if len(args) != 1 {
+		fmt.Printf("Usage: myrai conversations %s\n", usage)
+		os.Exit(1)
+	}
+	conv, err := app.ResolveConversation(st, args[0])
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	return conv
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+// HandleDBCommand shows and applies the versioned migrations of the core
+// tables and of each skill's tables
+func HandleDBCommand(args []string) {
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		PrintDBHelp()
+		return
+	}
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Opening the store backs up the data and applies the core migrations
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("Error initializing store: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	switch args[0] {
+	case "status":
+		printDBStatus(st)
+
+	case "migrate":
+		report := st.MigrationReport()
+		if report.Backup != nil {
+			fmt.Printf("💾 Backup saved to %s\n", report.Backup.Path)
+		}
+		applied := 0
+		for _, m := range report.Applied {
+			fmt.Printf("   ✓ %-14s %03d %s\n", store.CoreComponent, m.Version, m.Name)
+			applied++
+		}
+		for _, component := range store.Components() {
+			list, err := store.ApplyComponentMigrations(st.DB(), component, cfg.Storage.DataDir)
+			for _, m := range list {
+				fmt.Printf("   ✓ %-14s %03d %s\n", component, m.Version, m.Name)
+				applied++
+			}
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if applied == 0 {
+			fmt.Println("✅ All migrations are applied")
+		} else {
+			fmt.Printf("✅ Applied %d migration(s)\n", applied)
+		}
+
+	case "rollback":
+		if len(args) < 2 || strings.HasPrefix(args[1], "-") {
+			fmt.Println("Usage: myrai db rollback <component> [-y]")
+			fmt.Println("Components: " + strings.Join(store.Components(), ", "))
+			os.Exit(1)
+		}
+		rollbackMigration(cfg, st, args[1], hasFlag(args, "--yes", "-y"))
+
+	default:
+		fmt.Printf("Unknown db command: %s\n\n", args[0])
+		PrintDBHelp()
+		os.Exit(1)
+	}
+}
+
+func printDBStatus(st *store.Store) {
+	fmt.Println("Component       Version  Pending")
+	for _, component := range store.Components() {
+		version, err := store.ComponentVersion(st.DB(), component)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		pending, err := store.PendingComponentMigrations(st.DB(), component)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		latest := 0
+		if list := store.ComponentMigrations(component); len(list) > 0 {
+			latest = list[len(list)-1].Version
+		}
+
+		var names []string
+		for _, m := range pending {
+			names = append(names, fmt.Sprintf("%03d %s", m.Version, m.Name))
+		}
+		status := "-"
+		if len(names) > 0 {
+			status = strings.Join(names, ", ")
+		}
+		fmt.Printf("%-15s %3d/%-4d %s\n", component, version, latest, status)
+	}
+}
+
+func rollbackMigration(cfg *config.Config, st *store.Store, component string, yes bool) {
+	known := false
+	for _, c := range store.Components() {
+		known = known || c == component
+	}
+	if !known {
+		fmt.Printf("❌ Unknown component: %s\n", component)
+		fmt.Println("Components: " + strings.Join(store.Components(), ", "))
+		os.Exit(1)
+	}
+
+	if !yes {
+		fmt.Printf("This undoes the latest %s migration, which may delete data.\n", component)
+		fmt.Println("Stop any running Myrai server first. The data is backed up before rolling back.")
+		fmt.Print("Continue? (y/N): ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answer = strings.TrimSpace(strings.ToLower(answer))
+		if answer != "y" && answer != "yes" {
+			fmt.Println("Rollback cancelled")
+			return
+		}
+	}
+
+	backup, err := store.CreateBackup(&cfg.Storage, store.BackupLabelPreRollback)
+	if err != nil {
+		fmt.Printf("❌ Backup failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("💾 Backup saved to %s\n", backup.Path)
+
+	m, err := store.RollbackMigration(st.DB(), component, cfg.Storage.DataDir)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Rolled back %s %03d %s\n", component, m.Version, m.Name)
+	fmt.Println("   It is applied again the next time Myrai starts; install the matching")
+	fmt.Println("   release or restore the backup if you want to keep it undone.")
+}
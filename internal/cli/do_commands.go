@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/audit"
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/security"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+// auditSourceDo marks audit entries of commands run with 'myrai do'
+const auditSourceDo = "cli_do"
+
+// commandSuggestion is the shell command the model proposes for a request
+type commandSuggestion struct {
+	Command     string `json:"command"`
+	Explanation string `json:"explanation"`
+	Risky       bool   `json:"risky"`
+}
+
+// HandleDoCommand asks the model for a shell command that does what args
+// describe, and runs it once the user agrees
+func HandleDoCommand(args []string) {
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		PrintDoHelp()
+		return
+	}
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	if hasFlag(args, "--history") {
+		printDoHistory(cfg)
+		return
+	}
+
+	var words []string
+	for _, arg := range args {
+		if arg != "--yes" && arg != "-y" {
+			words = append(words, arg)
+		}
+	}
+	request := strings.TrimSpace(strings.Join(words, " "))
+	if request == "" {
+		PrintDoHelp()
+		os.Exit(1)
+	}
+
+	provider, err := cfg.DefaultProvider()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	shell := userShell()
+	dir, _ := os.Getwd()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	reply, err := llm.NewClient(provider).SimpleChat(ctx, suggestionPrompt(runtime.GOOS, filepath.Base(shell), dir), request)
+	if err != nil {
+		fmt.Printf("❌ Failed to get a command: %v\n", err)
+		os.Exit(1)
+	}
+	suggestion, err := parseSuggestion(reply)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if suggestion.Command == "" {
+		fmt.Printf("🤷 %s\n", suggestion.Explanation)
+		os.Exit(1)
+	}
+
+	fmt.Println()
+	fmt.Printf("  $ %s\n\n", suggestion.Command)
+	if suggestion.Explanation != "" {
+		fmt.Printf("💡 %s\n", suggestion.Explanation)
+	}
+
+	if err := security.ValidateCommand(suggestion.Command); err != nil {
+		fmt.Printf("⛔ %v\n", err)
+	} else if suggestion.Risky {
+		fmt.Println("⚠️  This command changes or deletes things; check it before running it.")
+	}
+	if needsConfirmation(suggestion, hasFlag(args, "--yes", "-y")) {
+		fmt.Print("Run it? (y/N): ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answer = strings.TrimSpace(strings.ToLower(answer))
+		if answer != "y" && answer != "yes" {
+			fmt.Println("Cancelled")
+			return
+		}
+	}
+	fmt.Println()
+
+	entry := runSuggestedCommand(shell, suggestion.Command)
+	entry.Request = request
+	entry.Directory = dir
+	recordDo(cfg, entry)
+
+	if entry.Error != "" && entry.ExitCode == 0 {
+		fmt.Printf("❌ %s\n", entry.Error)
+		os.Exit(1)
+	}
+	if entry.ExitCode != 0 {
+		os.Exit(entry.ExitCode)
+	}
+}
+
+// needsConfirmation reports whether to ask before running s. --yes never
+// skips asking about a command the agent itself couldn't run, or one the
+// model flags as risky.
+func needsConfirmation(s *commandSuggestion, yes bool) bool {
+	return !yes || s.Risky || security.ValidateCommand(s.Command) != nil
+}
+
+// suggestionPrompt tells the model how to answer for this machine
+func suggestionPrompt(goos, shell, dir string) string {
+	return fmt.Sprintf(`You turn requests into a single shell command.
+The user runs %s with the %s shell, in the directory %s.
+
+Reply with only a JSON object, no other text:
+{"command": "<the command>", "explanation": "<one or two sentences on what it does>", "risky": <true or false>}
+
+Prefer standard tools that are installed by default. Set "risky" to true if
+the command deletes or overwrites files, changes system settings, needs
+sudo, or affects other machines. If no shell command can do what is asked,
+set "command" to "" and say why in "explanation".`, goos, shell, dir)
+}
+
+// parseSuggestion reads the model's reply, tolerating code fences or text
+// around the JSON
+func parseSuggestion(reply string) (*commandSuggestion, error) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("the model didn't suggest a command; try rephrasing")
+	}
+	var suggestion commandSuggestion
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &suggestion); err != nil {
+		return nil, fmt.Errorf("couldn't read the model's suggestion: %w", err)
+	}
+	suggestion.Command = strings.TrimSpace(suggestion.Command)
+	suggestion.Explanation = strings.TrimSpace(suggestion.Explanation)
+	return &suggestion, nil
+}
+
+// userShell is the shell commands are run with
+func userShell() string {
+	if runtime.GOOS == "windows" {
+		return "cmd"
+	}
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
+
+// runSuggestedCommand runs command on the terminal, returning what
+// happened for the audit log
+func runSuggestedCommand(shell, command string) *audit.Entry {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command(shell, "/C", command)
+	} else {
+		cmd = exec.Command(shell, "-c", command)
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	// Ctrl+C stops the command but not Myrai, so it's still recorded
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	entry := &audit.Entry{Source: auditSourceDo, Action: "shell_command", Detail: command}
+	start := time.Now()
+	err := cmd.Run()
+	entry.Duration = time.Since(start).Milliseconds()
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		entry.ExitCode = exitErr.ExitCode()
+		entry.Error = err.Error()
+	case err != nil:
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+// recordDo adds entry to the audit log. Failing to is reported but
+// doesn't undo what already ran.
+func recordDo(cfg *config.Config, entry *audit.Entry) {
+	st, err := store.New(cfg)
+	if err == nil {
+		defer st.Close()
+		var auditLog *audit.Log
+		if auditLog, err = audit.NewLog(st.DB()); err == nil {
+			err = auditLog.Record(entry)
+		}
+	}
+	if err != nil {
+		fmt.Printf("⚠️  Couldn't record the command in the audit log: %v\n", err)
+	}
+}
+
+func printDoHistory(cfg *config.Config) {
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("Error initializing store: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+	auditLog, err := audit.NewLog(st.DB())
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	entries, err := auditLog.Recent(auditSourceDo, 20)
+	if err != nil {
+		fmt.Printf("❌ Failed to read the audit log: %v\n", err)
+		os.Exit(1)
+	}
+	if len(entries) == 0 {
+		fmt.Println("📭 No commands run with 'myrai do' yet")
+		return
+	}
+	for _, e := range entries {
+		status := "✅"
+		if e.ExitCode != 0 || e.Error != "" {
+			status = fmt.Sprintf("❌ exit %d", e.ExitCode)
+		}
+		fmt.Printf("%s  %s\n", e.CreatedAt.Format("Jan 2 15:04"), status)
+		fmt.Printf("   %s\n", e.Request)
+		fmt.Printf("   $ %s\n", e.Detail)
+	}
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestParseSuggestion(t *testing.T) {
+	reply := "Here you go:\n```json\n{\"command\": \" du -sh * \", \"explanation\": \"Shows sizes.\", \"risky\": false}\n```"
+	s, err := parseSuggestion(reply)
+	if err != nil {
+		t.Fatalf("parseSuggestion failed: %v", err)
+	}
+	if s.Command != "du -sh *" || s.Explanation != "Shows sizes." || s.Risky {
+		t.Errorf("unexpected suggestion: %+v", s)
+	}
+
+	s, err = parseSuggestion(`{"command": "", "explanation": "That needs a browser."}`)
+	if err != nil || s.Command != "" {
+		t.Errorf("expected an empty command, got %+v, %v", s, err)
+	}
+
+	for _, reply := range []string{"I can't help with that", `{"command": `} {
+		if _, err := parseSuggestion(reply); err == nil {
+			t.Errorf("expected an error for %q", reply)
+		}
+	}
+}
+
+func TestNeedsConfirmation(t *testing.T) {
+	tests := []struct {
+		name       string
+		suggestion commandSuggestion
+		yes        bool
+		want       bool
+	}{
+		{"asks by default", commandSuggestion{Command: "du -sh *"}, false, true},
+		{"--yes skips safe commands", commandSuggestion{Command: "du -sh *"}, true, false},
+		{"--yes still asks about risky commands", commandSuggestion{Command: "rm old.log", Risky: true}, true, true},
+		{"--yes still asks about blocked commands", commandSuggestion{Command: "rm -rf /"}, true, true},
+	}
+	for _, tt := range tests {
+		if got := needsConfirmation(&tt.suggestion, tt.yes); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRunSuggestedCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	entry := runSuggestedCommand("/bin/sh", "exit 3")
+	if entry.ExitCode != 3 || entry.Error == "" {
+		t.Errorf("expected exit code 3 to be recorded, got %+v", entry)
+	}
+	if entry.Source != auditSourceDo || entry.Detail != "exit 3" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+
+	entry = runSuggestedCommand("/bin/sh", "true")
+	if entry.ExitCode != 0 || entry.Error != "" {
+		t.Errorf("expected success, got %+v", entry)
+	}
+}
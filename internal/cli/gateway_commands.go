@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/daemon"
+)
+
+const (
+	// daemonStartWait is how long start waits for the gateway to come up
+	daemonStartWait = 60 * time.Second
+	// daemonStopWait is how long stop waits for a graceful shutdown
+	daemonStopWait = 30 * time.Second
+)
+
+// GatewayNeedsApp reports whether a gateway command runs the server in this
+// process. The others only manage a gateway running elsewhere, so they
+// don't need the app initialized.
+func GatewayNeedsApp(args []string) bool {
+	return len(args) > 0 && (args[0] == "run" || args[0] == "start") && !hasFlag(args, "--daemon", "-d")
+}
+
+// HandleGatewayServiceCommand starts, stops and inspects a background
+// gateway, and installs it as a system service
+func HandleGatewayServiceCommand(args []string) {
+	if len(args) == 0 {
+		PrintGatewayHelp()
+		return
+	}
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	pidFile := daemon.PidFile(cfg.Storage.DataDir)
+
+	switch args[0] {
+	case "run", "start":
+		startDaemon(cfg)
+
+	case "stop":
+		stopDaemon(pidFile)
+
+	case "restart":
+		if _, ok := daemon.Running(pidFile); ok {
+			stopDaemon(pidFile)
+		}
+		startDaemon(cfg)
+
+	case "status":
+		printGatewayStatus(cfg)
+
+	case "logs":
+		lines := 50
+		for i := 1; i < len(args)-1; i++ {
+			if args[i] == "-n" {
+				if n, err := strconv.Atoi(args[i+1]); err == nil && n > 0 {
+					lines = n
+				}
+			}
+		}
+		printGatewayLogs(cfg, lines)
+
+	case "install-service":
+		installService(cfg, hasFlag(args, "--system"), hasFlag(args, "--print"))
+
+	case "uninstall-service":
+		uninstallService(cfg, hasFlag(args, "--system"))
+
+	default:
+		fmt.Printf("Unknown gateway command: %s\n\n", args[0])
+		PrintGatewayHelp()
+		os.Exit(1)
+	}
+}
+
+func startDaemon(cfg *config.Config) {
+	logFile := daemon.LogFile(cfg.Storage.DataDir)
+	fmt.Println("Starting Myrai server in the background...")
+	pid, err := daemon.Start(daemon.PidFile(cfg.Storage.DataDir), logFile, []string{"gateway", "run"}, daemonStartWait)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Gateway running (pid %d)\n", pid)
+	fmt.Printf("   URL:  http://localhost:%d\n", cfg.Server.Port)
+	fmt.Printf("   Logs: %s\n", logFile)
+}
+
+func stopDaemon(pidFile string) {
+	pid, err := daemon.Stop(pidFile, daemonStopWait)
+	switch {
+	case errors.Is(err, daemon.ErrNotRunning):
+		fmt.Println("The gateway is not running")
+	case err != nil:
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	default:
+		fmt.Printf("✅ Gateway stopped (pid %d)\n", pid)
+	}
+}
+
+func printGatewayStatus(cfg *config.Config) {
+	fmt.Println("Gateway Status:")
+	fmt.Println("==============")
+	pidFile := daemon.PidFile(cfg.Storage.DataDir)
+	if pid, ok := daemon.Running(pidFile); ok {
+		since := daemon.Since(pidFile)
+		fmt.Printf("Running: ✅ pid %d, since %s (%s)\n", pid, since.Format("2006-01-02 15:04:05"),
+			time.Since(since).Round(time.Second))
+		if status := probeReadiness(cfg); status != "" {
+			fmt.Printf("Ready:   %s\n", status)
+		}
+	} else {
+		fmt.Println("Running: ❌ not running")
+	}
+	fmt.Printf("Address: %s:%d\n", cfg.Server.Address, cfg.Server.Port)
+	fmt.Printf("URL: http://localhost:%d\n", cfg.Server.Port)
+	fmt.Printf("Data Directory: %s\n", cfg.Storage.DataDir)
+}
+
+// probeReadiness asks the running gateway's /readyz how it is
+func probeReadiness(cfg *config.Config) string {
+	client := &http.Client{Timeout: 20 * time.Second}
+	resp, err := client.Get(defaultGatewayURL(cfg) + "/readyz")
+	if err != nil {
+		return "⚠️  not answering HTTP"
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	status := body.Status
+	for name, check := range body.Checks {
+		if check != "ok" {
+			status += fmt.Sprintf(", %s %s", name, check)
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "❌ " + status + " (run 'myrai doctor')"
+	}
+	return "✅ " + status
+}
+
+func printGatewayLogs(cfg *config.Config, lines int) {
+	logFile := daemon.LogFile(cfg.Storage.DataDir)
+	f, err := os.Open(logFile)
+	if err != nil {
+		fmt.Println("Logs of a foreground gateway are written to stdout/stderr")
+		fmt.Println("To save logs to a file: myrai gateway run > myrai.log 2>&1")
+		fmt.Println("A gateway run as a systemd service logs to: journalctl --user -u " + daemon.Service{Profile: config.ActiveProfile()}.Name())
+		return
+	}
+	defer f.Close()
+
+	var tail []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		tail = append(tail, scanner.Text())
+		if len(tail) > lines {
+			tail = tail[1:]
+		}
+	}
+	fmt.Printf("==> %s <==\n", logFile)
+	for _, line := range tail {
+		fmt.Println(line)
+	}
+}
+
+func gatewayService(cfg *config.Config, system bool) (daemon.Service, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return daemon.Service{}, err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	s := daemon.Service{
+		Executable: exe,
+		DataDir:    cfg.Storage.DataDir,
+		LogFile:    daemon.LogFile(cfg.Storage.DataDir),
+		System:     system,
+		Profile:    config.ActiveProfile(),
+	}
+	if u, err := user.Current(); err == nil {
+		s.User = u.Username
+	}
+	return s, nil
+}
+
+func installService(cfg *config.Config, system, printOnly bool) {
+	s, err := gatewayService(cfg, system)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	def, err := s.Render()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if printOnly {
+		fmt.Print(def)
+		return
+	}
+	path, err := s.ServiceFile()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(path, []byte(def), 0644); err != nil {
+		fmt.Printf("❌ Failed to write %s: %v\n", path, err)
+		if system {
+			fmt.Println("   System units need root: sudo myrai gateway install-service --system")
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Service written to %s\n", path)
+	if _, ok := daemon.Running(daemon.PidFile(cfg.Storage.DataDir)); ok {
+		fmt.Println("   Stop the running gateway first: myrai gateway stop")
+	}
+	fmt.Println("   Enable and start it with:")
+	for _, cmd := range s.EnableCommands(path) {
+		fmt.Println("     " + cmd)
+	}
+}
+
+func uninstallService(cfg *config.Config, system bool) {
+	s, err := gatewayService(cfg, system)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	path, err := s.ServiceFile()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		fmt.Printf("No service installed at %s\n", path)
+		return
+	}
+
+	if err := s.Disable(path); err != nil {
+		fmt.Printf("⚠️  Couldn't stop the service: %v\n", err)
+	}
+	if err := os.Remove(path); err != nil {
+		fmt.Printf("❌ Failed to remove %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Removed %s\n", path)
+}
@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/persona"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/skills/agentic"
+	"go.uber.org/zap"
+)
+
+// maxGitDiffChars caps how much of a diff is sent to the model
+const maxGitDiffChars = 60000
+
+// HandleGitCommand writes commit messages and pull request descriptions
+// from the repository in the current directory
+func HandleGitCommand(args []string) {
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		PrintGitHelp()
+		return
+	}
+
+	switch args[0] {
+	case "commit-msg", "commit-message":
+		gitCommitMessage(args[1:])
+	case "pr-desc", "pr-description", "pr":
+		gitPRDescription(args[1:])
+	default:
+		fmt.Printf("Unknown git command: %s\n\n", args[0])
+		PrintGitHelp()
+		os.Exit(1)
+	}
+}
+
+func gitCommitMessage(args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	git := agentic.NewAgenticSkill(".")
+
+	diff, err := gitTool(ctx, git, "git_diff", map[string]interface{}{"staged": true})
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if strings.TrimSpace(diff) == "" {
+		fmt.Println("Nothing is staged. Stage changes with 'git add' first.")
+		os.Exit(1)
+	}
+	recent, _ := gitTool(ctx, git, "git_log", map[string]interface{}{"limit": float64(10)})
+
+	message, err := generateGitText(ctx, commitMessagePrompt(recent), truncateDiff(diff))
+	if err != nil {
+		fmt.Printf("❌ Failed to write a commit message: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(message)
+
+	if !hasFlag(args, "--commit", "-c") {
+		return
+	}
+	fmt.Println()
+	if !hasFlag(args, "--yes", "-y") && !confirmPrivacy("Commit the staged changes with this message?") {
+		return
+	}
+	cmd := exec.Command("git", "commit", "-F", "-")
+	if hasFlag(args, "--edit", "-e") {
+		cmd.Args = append(cmd.Args, "--edit")
+	}
+	cmd.Stdin = strings.NewReader(message + "\n")
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("❌ git commit failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func gitPRDescription(args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	git := agentic.NewAgenticSkill(".")
+
+	base := flagValue(args, "--base", "-b")
+	if base == "" {
+		base = defaultBaseBranch()
+	}
+
+	diff, err := gitTool(ctx, git, "git_diff", map[string]interface{}{"base": base})
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if strings.TrimSpace(diff) == "" {
+		fmt.Printf("This branch has no changes against %s.\n", base)
+		os.Exit(1)
+	}
+	commits, _ := gitTool(ctx, git, "git_log", map[string]interface{}{"base": base, "limit": float64(50)})
+
+	input := fmt.Sprintf("Commits:\n%s\n\nDiff against %s:\n%s", commits, base, truncateDiff(diff))
+	description, err := generateGitText(ctx, prDescriptionPrompt(), input)
+	if err != nil {
+		fmt.Printf("❌ Failed to write a PR description: %v\n", err)
+		os.Exit(1)
+	}
+
+	if output := flagValue(args, "--output", "-o"); output != "" {
+		if err := os.WriteFile(output, []byte(description+"\n"), 0644); err != nil {
+			fmt.Printf("❌ Failed to write %s: %v\n", output, err)
+			os.Exit(1)
+		}
+		fmt.Printf("📝 PR description written to %s\n", output)
+		return
+	}
+	fmt.Println(description)
+}
+
+// gitTool runs one of the agentic skill's git tools, returning its output
+// as text
+func gitTool(ctx context.Context, skill skills.Skill, name string, args map[string]interface{}) (string, error) {
+	for _, tool := range skill.Tools() {
+		if tool.Name != name {
+			continue
+		}
+		result, err := tool.Handler(ctx, args)
+		if err != nil {
+			return "", err
+		}
+		switch r := result.(type) {
+		case map[string]string:
+			return r["diff"], nil
+		case []map[string]string:
+			var lines []string
+			for _, commit := range r {
+				lines = append(lines, commit["hash"]+" "+commit["message"])
+			}
+			return strings.Join(lines, "\n"), nil
+		}
+		return fmt.Sprint(result), nil
+	}
+	return "", fmt.Errorf("tool %s not found", name)
+}
+
+// defaultBaseBranch is main, or master in repositories that have no main
+func defaultBaseBranch() string {
+	if exec.Command("git", "rev-parse", "--verify", "--quiet", "main").Run() != nil &&
+		exec.Command("git", "rev-parse", "--verify", "--quiet", "master").Run() == nil {
+		return "master"
+	}
+	return "main"
+}
+
+func truncateDiff(diff string) string {
+	if len(diff) <= maxGitDiffChars {
+		return diff
+	}
+	return strings.ToValidUTF8(diff[:maxGitDiffChars], "") + "\n... (diff truncated)"
+}
+
+func commitMessagePrompt(recentCommits string) string {
+	prompt := `Write a git commit message for the staged diff the user sends.
+
+Use the Conventional Commits format: a subject line "type(scope): summary"
+of at most 72 characters, where type is one of feat, fix, docs, style,
+refactor, perf, test, build, ci or chore and the scope is optional. Write the
+summary in the imperative mood. If the change needs explaining, add a blank
+line and a short body wrapped at 72 characters saying what changed and why.
+
+Reply with the commit message only, without code fences or commentary.`
+	if recentCommits != "" {
+		prompt += "\n\nRecent commits in this repository, to match their conventions:\n" + recentCommits
+	}
+	return prompt
+}
+
+func prDescriptionPrompt() string {
+	return `Write a pull request description for the branch the user describes
+with its commits and diff.
+
+Start with a title line, then a blank line and a Markdown body: one or two
+sentences on what the change does and why, a "## Changes" list of the main
+changes, and a "## Testing" section saying how to verify it. Be specific and
+brief. Don't invent tests, issues or links that aren't in the diff.
+
+Reply with the description only, without code fences around it.`
+}
+
+// generateGitText asks the default model, writing in the persona's style
+func generateGitText(ctx context.Context, instructions, input string) (string, error) {
+	cfg, err := config.Load("", "")
+	if err != nil {
+		return "", err
+	}
+	provider, err := cfg.DefaultProvider()
+	if err != nil {
+		return "", err
+	}
+
+	if style := personaStyle(); style != "" {
+		instructions += "\n\n" + style
+	}
+	text, err := llm.NewClient(provider).SimpleChat(ctx, instructions, input)
+	if err != nil {
+		return "", err
+	}
+	return stripFences(text), nil
+}
+
+// personaStyle describes how the current persona writes, if it says
+func personaStyle() string {
+	pm, err := persona.NewPersonaManager(workspacePath(), zap.NewNop())
+	if err != nil {
+		return ""
+	}
+	var notes []string
+	if identity := pm.GetIdentity(); identity != nil && identity.Voice != "" {
+		notes = append(notes, "Write in this voice: "+identity.Voice)
+	}
+	if user := pm.GetUserProfile(); user != nil && user.CommunicationStyle != "" {
+		notes = append(notes, "The user prefers: "+user.CommunicationStyle)
+	}
+	return strings.Join(notes, "\n")
+}
+
+// stripFences removes a code fence the model put around its whole answer
+func stripFences(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") || !strings.HasSuffix(text, "```") || len(text) < 6 {
+		return text
+	}
+	text = strings.TrimSuffix(text, "```")
+	if i := strings.Index(text, "\n"); i >= 0 {
+		text = text[i+1:]
+	} else {
+		text = strings.TrimPrefix(text, "```")
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/skills"
+)
+
+func TestStripFences(t *testing.T) {
+	tests := map[string]string{
+		"feat: add x":                       "feat: add x",
+		"```\nfeat: add x\n```":             "feat: add x",
+		"```text\nfix(api): y\n\nbody\n```": "fix(api): y\n\nbody",
+		"Use ```go``` here":                 "Use ```go``` here",
+	}
+	for in, want := range tests {
+		if got := stripFences(in); got != want {
+			t.Errorf("stripFences(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGitTool(t *testing.T) {
+	skill := skills.NewBaseSkill("git", "test", "1.0.0")
+	skill.AddTool(skills.Tool{
+		Name: "git_log",
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return []map[string]string{{"hash": "abc123", "message": "first"}, {"hash": "def456", "message": "second"}}, nil
+		},
+	})
+
+	got, err := gitTool(context.Background(), skill, "git_log", nil)
+	if err != nil || got != "abc123 first\ndef456 second" {
+		t.Errorf("unexpected log %q, %v", got, err)
+	}
+	if _, err := gitTool(context.Background(), skill, "git_diff", nil); err == nil {
+		t.Error("expected an error for a missing tool")
+	}
+}
+
+func TestTruncateDiff(t *testing.T) {
+	if got := truncateDiff("small"); got != "small" {
+		t.Errorf("small diffs are kept whole, got %q", got)
+	}
+	got := truncateDiff(strings.Repeat("x", maxGitDiffChars+10))
+	if !strings.HasSuffix(got, "(diff truncated)") {
+		t.Error("expected large diffs to be truncated")
+	}
+}
@@ -8,20 +8,42 @@ func PrintExtendedHelp() {
 	fmt.Println("Usage:")
 	fmt.Println("  myrai                          Run in server mode (default)")
 	fmt.Println("  myrai --server                 Run in server mode")
-	fmt.Println("  myrai --tui                    Run beautiful TUI mode")
+	fmt.Println("  myrai tui                      Run beautiful TUI mode (or --tui)")
 	fmt.Println("  myrai --cli                    Run interactive CLI mode")
 	fmt.Println("  myrai -m 'message'             Send one-shot message")
+	fmt.Println("    -f, --file <path>            Attach a file (repeatable)")
+	fmt.Println("    -o, --output <path>          Write the answer to a file")
+	fmt.Println("  cat log | myrai -m 'explain'   Send piped input along with the message")
+	fmt.Println("  myrai do 'task'                Suggest a shell command, run it if confirmed")
+	fmt.Println("  myrai git commit-msg           Write a commit message for staged changes")
+	fmt.Println("  myrai git pr-desc              Write a PR description for this branch")
+	fmt.Println()
+	fmt.Println("Conversations:")
+	fmt.Println("  myrai conversations            List recent conversations")
+	fmt.Println("  myrai conversations show <n>   Show a conversation")
+	fmt.Println("  myrai conversations resume <n> Continue a conversation")
+	fmt.Println("  myrai conversations rename <n> <title>")
+	fmt.Println("  myrai conversations delete <n> [--forget]")
 	fmt.Println()
 	fmt.Println("Setup & Configuration:")
 	fmt.Println("  myrai onboard                  Run setup wizard")
+	fmt.Println("  myrai onboard --non-interactive --provider <p> --model <m>")
+	fmt.Println("                                 Set up without prompts (see onboard --help)")
+	fmt.Println("  myrai profile list             List profiles (separate instances)")
+	fmt.Println("  myrai profile create <name>    Create a profile, e.g. work")
+	fmt.Println("  myrai profile delete <name>    Delete a profile and its data")
 	fmt.Println("  myrai config get <key>         Get configuration value")
 	fmt.Println("  myrai config set <key> <val>   Set configuration value")
 	fmt.Println("  myrai config edit              Edit config in $EDITOR")
 	fmt.Println("  myrai config path              Show config file path")
+	fmt.Println("  myrai secret set <NAME>        Store an API key or token")
+	fmt.Println("  myrai secret migrate           Move secrets out of .env")
 	fmt.Println()
 	fmt.Println("Server Management:")
 	fmt.Println("  myrai gateway run              Start server (foreground)")
-	fmt.Println("  myrai gateway status           Show gateway configuration")
+	fmt.Println("  myrai gateway start --daemon   Start server in the background")
+	fmt.Println("  myrai gateway stop             Stop the running server")
+	fmt.Println("  myrai gateway status           Show whether the server is running")
 	fmt.Println("  myrai channels status          Show channel status")
 	fmt.Println()
 	fmt.Println("System & Diagnostics:")
@@ -32,6 +54,9 @@ func PrintExtendedHelp() {
 	fmt.Println("Skills:")
 	fmt.Println("  myrai skills                   List available skills")
 	fmt.Println("  myrai skills info <skill>      Show skill details")
+	fmt.Println("  myrai skills install <source>  Install a skill package by name or git URL")
+	fmt.Println("  myrai skills update [name]     Update installed skill packages")
+	fmt.Println("  myrai skills remove <name>     Remove a skill package")
 	fmt.Println()
 	fmt.Println("Neural Clusters (Memory Management):")
 	fmt.Println("  myrai memory clusters             List all neural clusters")
@@ -52,19 +77,90 @@ func PrintExtendedHelp() {
 	fmt.Println()
 	fmt.Println("Project Management:")
 	fmt.Println("  myrai project new <name> <type>   Create new project")
-	fmt.Println("  myrai project list                List all projects")
+	fmt.Println("  myrai project list [--all]        List projects, --all with archived")
 	fmt.Println("  myrai project switch <name>       Switch to project")
 	fmt.Println("  myrai project archive <name>      Archive a project")
+	fmt.Println("  myrai project unarchive <name>    Make an archived project active")
+	fmt.Println("  myrai project rename <old> <new>  Rename a project")
+	fmt.Println("  myrai project duplicate <p> <new> Copy a project's settings")
 	fmt.Println("  myrai project delete <name>       Delete a project")
+	fmt.Println("  myrai project glossary            Show the project's glossary")
+	fmt.Println("  myrai project tools               Show the project's tools and model")
 	fmt.Println()
 	fmt.Println("Batch Processing:")
 	fmt.Println("  myrai batch -i <file>             Process prompts from file")
 	fmt.Println("  myrai batch -i in.txt -o out.json Process and save results")
 	fmt.Println()
+	fmt.Println("Aliases:")
+	fmt.Println("  myrai alias add <name> \"<prompt>\" Define a shortcut prompt")
+	fmt.Println("  myrai alias list                  List aliases")
+	fmt.Println("  myrai alias remove <name>         Remove an alias")
+	fmt.Println("  myrai <alias> [args...]           Run an alias")
+	fmt.Println()
+	fmt.Println("Task Plans:")
+	fmt.Println("  myrai plan list [status]          List task plans")
+	fmt.Println("  myrai plan show <id>              Show a plan's steps and progress")
+	fmt.Println("  myrai plan run <id>               Execute (or resume) a plan step by step")
+	fmt.Println()
+	fmt.Println("System Prompt:")
+	fmt.Println("  myrai prompt inspect              Show the assembled system prompt by layer")
+	fmt.Println()
+	fmt.Println("Artifacts:")
+	fmt.Println("  myrai artifacts list              List stored tool outputs and generated files")
+	fmt.Println("  myrai artifacts show <id>         Show an artifact's details and text")
+	fmt.Println("  myrai artifacts export <id>       Save an artifact to a file")
+	fmt.Println()
+	fmt.Println("Household:")
+	fmt.Println("  myrai household add <name>        Add a profile (the first is the owner)")
+	fmt.Println("  myrai household list              List profiles and linked accounts")
+	fmt.Println("  myrai household invite <name>     Create a /join code for a profile")
+	fmt.Println()
+	fmt.Println("Calendar:")
+	fmt.Println("  myrai calendar export             Export the next month to an .ics file")
+	fmt.Println("  myrai calendar import <file.ics>  Import events from another calendar app")
+	fmt.Println()
+	fmt.Println("Knowledge Base:")
+	fmt.Println("  myrai kb add <file>...            Keep documents to ask questions about")
+	fmt.Println("  myrai kb add-url <url>            Keep a web page as Markdown")
+	fmt.Println("  myrai kb list                     List documents")
+	fmt.Println("  myrai kb search <query>           Find passages in your documents")
+	fmt.Println()
+	fmt.Println("Vector Search:")
+	fmt.Println("  myrai vector status               Show the embedding model of each index")
+	fmt.Println("  myrai vector reindex [index]      Re-embed after changing models")
+	fmt.Println("  myrai vector migrate <from> <to>  Copy embeddings between backends")
+	fmt.Println()
+	fmt.Println("Locale:")
+	fmt.Println("  myrai locale show                 Show date, currency and unit settings")
+	fmt.Println("  myrai locale set language en-GB   Use a region's formats")
+	fmt.Println()
+	fmt.Println("Upgrade:")
+	fmt.Println("  myrai upgrade                     Back up data and apply pending migrations")
+	fmt.Println("  myrai upgrade --rollback [id]     Restore the latest (or given) backup")
+	fmt.Println("  myrai upgrade --list              List backups")
+	fmt.Println("  myrai db status                   Show applied and pending migrations")
+	fmt.Println("  myrai db rollback <component>     Undo a component's latest migration")
+	fmt.Println()
+	fmt.Println("Backup:")
+	fmt.Println("  myrai backup create [--to file]   Save everything to a .tar.zst archive")
+	fmt.Println("  myrai backup restore <file|id>    Restore an archive or backup")
+	fmt.Println("  myrai backup list                 List backups and archives")
+	fmt.Println()
+	fmt.Println("Privacy:")
+	fmt.Println("  myrai privacy purge               Delete data from before a date")
+	fmt.Println("  myrai privacy redact              Blank the text of old messages")
+	fmt.Println()
 	fmt.Println("Persona Commands:")
 	fmt.Println("  myrai persona                     Show current AI identity")
-	fmt.Println("  myrai persona edit                Edit AI identity")
-	fmt.Println("  myrai persona show                Show full identity file")
+	fmt.Println("  myrai persona wizard [name]       Edit AI identity step by step")
+	fmt.Println("  myrai persona set <field> <value> Set name, personality, voice,")
+	fmt.Println("                                    values or expertise")
+	fmt.Println("  myrai persona edit [name]         Edit the identity file by hand")
+	fmt.Println("  myrai persona show [name]         Show full identity file")
+	fmt.Println("  myrai persona list                List persona profiles")
+	fmt.Println("  myrai persona new <name>          Create a persona from the current one")
+	fmt.Println("  myrai persona use <name>          Answer as another persona")
+	fmt.Println("  myrai persona delete <name>       Delete a persona")
 	fmt.Println("  myrai persona proposals           List all proposals")
 	fmt.Println("  myrai persona proposals pending   List pending proposals")
 	fmt.Println("  myrai persona proposal show <id>  Show proposal details")
@@ -82,12 +178,18 @@ func PrintExtendedHelp() {
 	fmt.Println("Flags:")
 	fmt.Println("  --config <path>          Path to config file")
 	fmt.Println("  --data <path>            Path to data directory")
+	fmt.Println("  --profile <name>         Use a profile's config and data (first flag only)")
+	fmt.Println("  --remote <url>           Chat through the gateway at url instead of locally")
+	fmt.Println("  --token <token>          The gateway's security.gateway_token (for --remote)")
+	fmt.Println("  --project <name>         Work in a project instead of the current one")
 	fmt.Println("  --help, -h               Show this help")
 	fmt.Println("  --version, -v            Show version")
 	fmt.Println()
 	fmt.Println("Examples:")
-	fmt.Println("  myrai gateway run &                          # Start server in background")
+	fmt.Println("  myrai gateway start --daemon                 # Start server in background")
 	fmt.Println("  myrai -m \"What's the weather in KL?\"       # One-shot query")
+	fmt.Println("  myrai --project api -m \"Review main.go\"     # One-shot query in a project")
+	fmt.Println("  myrai --remote https://vps:8080 --token T --cli  # Chat with a remote gateway")
 	fmt.Println("  myrai doctor                                 # Check setup")
 	fmt.Println()
 }
@@ -96,11 +198,39 @@ func PrintProjectHelp() {
 	fmt.Println("Project Management Commands:")
 	fmt.Println()
 	fmt.Println("  myrai project new <name> <type>    Create new project")
-	fmt.Println("  myrai project list                 List all projects")
+	fmt.Println("  myrai project list [--all]         List projects, --all with archived")
 	fmt.Println("  myrai project switch <name>        Switch to project")
 	fmt.Println("  myrai project archive <name>       Archive a project")
+	fmt.Println("  myrai project unarchive <name>     Make an archived project active")
+	fmt.Println("  myrai project rename <name> <new>  Rename a project, keeping its memories")
+	fmt.Println("  myrai project duplicate <name> <new>")
+	fmt.Println("                                     Copy a project's type, context,")
+	fmt.Println("                                     glossary and tools (not memories)")
 	fmt.Println("  myrai project delete <name>        Delete a project")
 	fmt.Println()
+	fmt.Println("Archived projects can't be switched to until they're unarchived.")
+	fmt.Println()
+	fmt.Println("The current project's description, type and memories go into every")
+	fmt.Println("chat; 'myrai --project <name> -m ...' uses another one for one run.")
+	fmt.Println()
+	fmt.Println("Glossary (current project, or --project <name>):")
+	fmt.Println("  myrai project glossary                        List terms")
+	fmt.Println("  myrai project glossary add <term> <meaning>   Add or correct a term")
+	fmt.Println("  myrai project glossary remove <term>          Remove a term")
+	fmt.Println()
+	fmt.Println("Terms are also learned from what you tell the assistant, e.g.")
+	fmt.Println("\"Falcon is our billing service\", and from processed documents.")
+	fmt.Println()
+	fmt.Println("Tools (current project, or --project <name>):")
+	fmt.Println("  myrai project tools                           Show the tool profile")
+	fmt.Println("  myrai project tools allow <skill|tool>...     Offer only these")
+	fmt.Println("  myrai project tools deny <skill|tool>...      Never offer these")
+	fmt.Println("  myrai project tools remove <skill|tool>...    Drop from allow/deny")
+	fmt.Println("  myrai project tools model <model|default>     Pin a model")
+	fmt.Println("  myrai project tools reset                     Offer everything again")
+	fmt.Println()
+	fmt.Println("e.g. 'myrai project tools deny exec_command github' for a writing project.")
+	fmt.Println()
 	fmt.Println("Project Types:")
 	fmt.Println("  coding     - Software development")
 	fmt.Println("  writing    - Content creation")
@@ -108,6 +238,346 @@ func PrintProjectHelp() {
 	fmt.Println("  business   - Business projects")
 }
 
+func PrintAliasHelp() {
+	fmt.Println("Alias Commands:")
+	fmt.Println()
+	fmt.Println("  myrai alias add <name> \"<prompt>\"   Define or replace an alias")
+	fmt.Println("  myrai alias list                    List aliases")
+	fmt.Println("  myrai alias remove <name>           Remove an alias")
+	fmt.Println()
+	fmt.Println("Using aliases:")
+	fmt.Println("  myrai <name> [args...]              Run from the CLI")
+	fmt.Println("  /<name> [args...]                   Run from Telegram, Discord or interactive mode")
+	fmt.Println()
+	fmt.Println("Arguments:")
+	fmt.Println("  $1..$9      Individual arguments")
+	fmt.Println("  $@          All arguments (also {{args}})")
+	fmt.Println("  Templates without placeholders get the arguments appended.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  myrai alias add standup \"Summarize my completed tasks from yesterday and today's calendar\"")
+	fmt.Println("  myrai alias add tr \"Translate '$2' into $1\"")
+	fmt.Println("  myrai tr French \"good morning\"")
+}
+
+func PrintPlanHelp() {
+	fmt.Println("Task Plan Commands:")
+	fmt.Println()
+	fmt.Println("  myrai plan list [status]                 List plans (active, paused, completed, failed)")
+	fmt.Println("  myrai plan show <id>                     Show steps, results and artifacts")
+	fmt.Println("  myrai plan run <id> [-c <conversation>]  Execute the remaining steps")
+	fmt.Println("  myrai plan resume <id> [-c <conv>]       Same as run; continues after the last finished step")
+	fmt.Println("  myrai plan pause <id>                    Stop a running plan after its current step")
+	fmt.Println("  myrai plan delete <id>                   Delete a plan")
+	fmt.Println()
+	fmt.Println("Plans are created when Myrai breaks a complex task into steps")
+	fmt.Println("(create_task_plan). Each step runs as its own agent turn and its status,")
+	fmt.Println("result and artifacts are saved, so a plan can be paused and resumed later")
+	fmt.Println("in the same or another conversation.")
+}
+
+func PrintPromptHelp() {
+	fmt.Println("System Prompt Commands:")
+	fmt.Println()
+	fmt.Println("  myrai prompt inspect [-c <conversation>] [-m <message>] [--persona <name>] [--layers]")
+	fmt.Println("                                           Print the system prompt with tokens per layer")
+	fmt.Println()
+	fmt.Println("The system prompt is assembled from layers, in order: time, identity")
+	fmt.Println("(IDENTITY.md or the persona), user (USER.md), project, tools (TOOLS.md),")
+	fmt.Println("agents (AGENTS.md), skills and household. A conversation's pins and the")
+	fmt.Println("memories relevant to a message follow as separate system messages; pass")
+	fmt.Println("-c to include the pins and -m to see what a message would recall.")
+	fmt.Println("--layers prints only the token counts.")
+}
+
+func PrintArtifactsHelp() {
+	fmt.Println("Artifact Commands:")
+	fmt.Println()
+	fmt.Println("  myrai artifacts list [-c <conversation>] [--kind output|file] [-n <limit>]")
+	fmt.Println("                                           List artifacts, newest first")
+	fmt.Println("  myrai artifacts show <id>                Show details and the start of text artifacts")
+	fmt.Println("  myrai artifacts export <id> [-o <file>]  Save an artifact; -o - writes it to stdout")
+	fmt.Println("  myrai artifacts delete <id>              Delete an artifact")
+	fmt.Println()
+	fmt.Println("Tool outputs too long for the model are kept as 'output' artifacts; the")
+	fmt.Println("model sees the start and reads on with get_artifact. Files tools generate,")
+	fmt.Println("such as charts and images, are kept as 'file' artifacts and sent on")
+	fmt.Println("channels that take files. The API serves them at /api/artifacts.")
+}
+
+func PrintHouseholdHelp() {
+	fmt.Println("Household Commands:")
+	fmt.Println()
+	fmt.Println("  myrai household add <name> [display name] [--owner]  Add a profile")
+	fmt.Println("  myrai household list                                 List profiles and linked accounts")
+	fmt.Println("  myrai household remove <name>                        Remove a profile")
+	fmt.Println("  myrai household role <name> <owner|member>           Change a profile's role")
+	fmt.Println("  myrai household persona <name> \"<instructions>\"      Set how Myrai talks to a profile")
+	fmt.Println("  myrai household invite <name>                        Create a /join code for a profile")
+	fmt.Println("  myrai household link <name> <channel> <account id>   Link a chat account directly")
+	fmt.Println("  myrai household unlink <channel> <account id>        Unlink a chat account")
+	fmt.Println()
+	fmt.Println("The first profile becomes the owner and keeps any existing data. Once a")
+	fmt.Println("profile exists, only linked Telegram and Discord accounts can chat.")
+	fmt.Println()
+	fmt.Println("Skills listed in household.shared (default: shopping, calendar) share one")
+	fmt.Println("copy of their data; all other skills keep each profile's data private.")
+	fmt.Println()
+	fmt.Println("In chat: /whoami, /household, /invite <profile> (owners), /join <code>")
+}
+
+func PrintCalendarHelp() {
+	fmt.Println("Calendar Commands:")
+	fmt.Println()
+	fmt.Println("  myrai calendar export [--range r] [-o file] [--profile name]  Write events to an .ics file")
+	fmt.Println("  myrai calendar import <file.ics> [--profile name]             Add events from an .ics file")
+	fmt.Println()
+	fmt.Println("Ranges count from today: day, week, month (default), year or all.")
+	fmt.Println("Recurring events are exported whenever their series reaches the range.")
+	fmt.Println("Use -o - to write to standard output.")
+	fmt.Println()
+	fmt.Println("Importing the same file again, or a file exported by Myrai, updates the")
+	fmt.Println("events instead of duplicating them. Events with the same title and start")
+	fmt.Println("as an existing one are skipped.")
+}
+
+func PrintKBHelp() {
+	fmt.Println("Knowledge Base Commands:")
+	fmt.Println()
+	fmt.Println("  myrai kb add <file>... [--title t] [--profile name]      Add documents")
+	fmt.Println("  myrai kb add-url <url>... [--title t] [--profile name]   Add web pages")
+	fmt.Println("  myrai kb list [--profile name]                           List documents")
+	fmt.Println("  myrai kb search <query> [-n limit] [--profile name]      Find matching passages")
+	fmt.Println("  myrai kb remove <id> [--profile name]                    Remove a document")
+	fmt.Println()
+	fmt.Println("PDFs, text, Markdown, CSV, JSON and HTML files are read directly; images")
+	fmt.Println("are read with OCR (needs tesseract). PDFs need pdftotext or pdfcpu.")
+	fmt.Println("Each file is copied into the data directory, so the original can be")
+	fmt.Println("moved or deleted. Documents sent to the Telegram bot are added too.")
+	fmt.Println()
+	fmt.Println("Web pages are saved as Markdown with their main content only, keeping the")
+	fmt.Println("source URL for answers to cite. Adding a page again refreshes it. With")
+	fmt.Println("skills.browser.enabled, pages that need scripts are loaded in the browser.")
+	fmt.Println()
+	fmt.Println("With vector search enabled, passages are found by meaning; otherwise by")
+	fmt.Println("the words of the query.")
+}
+
+func PrintVectorHelp() {
+	fmt.Println("Vector Search Commands:")
+	fmt.Println()
+	fmt.Println("  myrai vector status                Show which embedding model built each index")
+	fmt.Println("  myrai vector reindex [index]...    Embed memories, notes and documents again")
+	fmt.Println("  myrai vector migrate <from> <to>   Copy embeddings between backends")
+	fmt.Println()
+	fmt.Println("Indexes: memories, notes, kb. Reindex without names rebuilds them all.")
+	fmt.Println()
+	fmt.Println("Embeddings are searched where vector.backend says: local (the database,")
+	fmt.Println("no other service needed), qdrant or pgvector. To switch, migrate the")
+	fmt.Println("embeddings, e.g. 'myrai vector migrate local qdrant', then change")
+	fmt.Println("vector.backend. Memories and documents are kept in the backend; notes")
+	fmt.Println("are always searched locally.")
+	fmt.Println()
+	fmt.Println("Embeddings come from vector.provider: local (built in, no service needed),")
+	fmt.Println("openai, google or ollama, with vector.embedding_model choosing the model.")
+	fmt.Println("Embeddings from different models can't be compared, so after changing")
+	fmt.Println("either, run 'myrai vector reindex'. The server warns about stale indexes.")
+}
+
+func PrintLocaleHelp() {
+	fmt.Println("Locale Commands:")
+	fmt.Println()
+	fmt.Println("  myrai locale show [--profile name]                   Show language and regional settings")
+	fmt.Println("  myrai locale set <setting> <value> [--profile name]  Change a setting")
+	fmt.Println("  myrai locale reset [--profile name]                  Go back to the default (en-US)")
+	fmt.Println("  myrai locale languages                               List language presets")
+	fmt.Println()
+	fmt.Println("Settings:")
+	fmt.Println("  language    Load a region's defaults, e.g. en-GB, en-MY, de-DE")
+	fmt.Println("  date_order  mdy, dmy or ymd (how 3/4 is read and dates are shown)")
+	fmt.Println("  clock       12h or 24h")
+	fmt.Println("  first_day   First day of the week, e.g. monday")
+	fmt.Println("  currency    Default currency code, e.g. MYR")
+	fmt.Println("  units       metric or imperial")
+	fmt.Println()
+	fmt.Println("Tasks, calendar, expenses and shopping parse and format with these")
+	fmt.Println("settings. Household members can set their own with --profile or by")
+	fmt.Println("asking Myrai in chat.")
+}
+
+func PrintUpgradeHelp() {
+	fmt.Println("Upgrade Commands:")
+	fmt.Println()
+	fmt.Println("  myrai upgrade                       Back up data and apply pending migrations")
+	fmt.Println("  myrai upgrade --rollback [id] [-y]  Restore the latest (or given) backup")
+	fmt.Println("  myrai upgrade --backup              Take a backup now")
+	fmt.Println("  myrai upgrade --list                List backups")
+	fmt.Println("  myrai upgrade --encrypt             Encrypt data written before storage encryption was on")
+	fmt.Println("  myrai upgrade --decrypt             Decrypt all data before turning encryption off")
+	fmt.Println()
+	fmt.Println("Run 'myrai upgrade' after installing a new release. Before any schema or")
+	fmt.Println("workspace migration runs, the database and persona workspace are copied to")
+	fmt.Println("<data_dir>/backups (the last 5 backups are kept). Migrations run in a")
+	fmt.Println("transaction, so a failed migration leaves the database unchanged.")
+	fmt.Println()
+	fmt.Println("Stop the server before rolling back. Rolling back backs up the current")
+	fmt.Println("data first, so it can itself be undone.")
+}
+
+func PrintDBHelp() {
+	fmt.Println("Database Commands:")
+	fmt.Println()
+	fmt.Println("  myrai db status                     Show applied and pending migrations")
+	fmt.Println("  myrai db migrate                    Apply pending migrations")
+	fmt.Println("  myrai db rollback <component> [-y]  Undo a component's latest migration")
+	fmt.Println()
+	fmt.Println("The core tables and each skill's tables (tasks, health, calendar, ...) are")
+	fmt.Println("separate components with their own migration versions. Pending migrations")
+	fmt.Println("also apply when Myrai starts, after the data has been backed up.")
+	fmt.Println()
+}
+
+func PrintBackupHelp() {
+	fmt.Println("Backup Commands:")
+	fmt.Println()
+	fmt.Println("  myrai backup create [--to file.tar.zst]  Save a backup archive")
+	fmt.Println("  myrai backup restore <file|id> [-y]      Restore an archive, or a backup by id")
+	fmt.Println("  myrai backup list                        List backups and archives")
+	fmt.Println()
+	fmt.Println("An archive holds a consistent snapshot of the database, the persona")
+	fmt.Println("workspace, uploaded documents and your notes. Without --to it is written")
+	fmt.Println("to <data_dir>/backups, or storage.backup.dir. Set")
+	fmt.Println("storage.backup.interval_hours to take one on a schedule while the server")
+	fmt.Println("runs; the newest storage.backup.keep (default 7) are kept.")
+	fmt.Println()
+	fmt.Println("Stop the server before restoring. The current data is backed up first.")
+	fmt.Println()
+}
+
+func PrintOnboardHelp() {
+	fmt.Println("Setup:")
+	fmt.Println()
+	fmt.Println("  myrai onboard                              Run the interactive setup wizard")
+	fmt.Println("  myrai onboard --non-interactive [options]  Set up without prompts")
+	fmt.Println("  myrai onboard --from answers.yaml [options] Set up from an answers file")
+	fmt.Println()
+	fmt.Println("Options (they override the answers file):")
+	fmt.Println("  --provider <name>            LLM provider, e.g. openai, anthropic, ollama")
+	fmt.Println("  --model <model>              Default model")
+	fmt.Println("  --api-key-env <VAR>          Variable holding the API key (default: the provider's, e.g. OPENAI_API_KEY)")
+	fmt.Println("  --api-key <key>              The API key itself (visible in process lists)")
+	fmt.Println("  --base-url <url>             Custom endpoint")
+	fmt.Println("  --name <name>                Your name")
+	fmt.Println("  --style <style>              How Myrai should communicate")
+	fmt.Println("  --expertise <a,b>            Your areas of expertise")
+	fmt.Println("  --goals <a,b>                What you want to use Myrai for")
+	fmt.Println("  --data-dir <dir>             Where data is kept (default: ~/.local/share/myrai)")
+	fmt.Println("  --config-dir <dir>           Where myrai.yaml and .env go (default: ~/.config/myrai)")
+	fmt.Println("  --profile <name>             Set up this profile instead (first option only)")
+	fmt.Println("  --telegram-token-env <VAR>   Enable Telegram with the bot token in VAR")
+	fmt.Println("  --search-provider <name>     Web search provider (default: brave)")
+	fmt.Println("  --search-api-key-env <VAR>   Enable web search with the key in VAR")
+	fmt.Println("  --vision-model <model>       Enable vision with this model")
+	fmt.Println("  --check                      Call the provider first and stop if the key or model is wrong")
+	fmt.Println("  --overwrite                  Replace an existing config and .env")
+	fmt.Println()
+	fmt.Println("Answers files use the same names: provider, model, api_key_env, base_url,")
+	fmt.Println("name, communication_style, expertise, goals, profile, data_dir, config_dir,")
+	fmt.Println("telegram_token_env, search_provider, search_api_key_env, vision_model, check.")
+	fmt.Println()
+	fmt.Println("Example:")
+	fmt.Println("  myrai onboard --non-interactive --provider openai --model gpt-5.2 --api-key-env OPENAI_API_KEY")
+	fmt.Println()
+}
+
+func PrintProfileHelp() {
+	fmt.Println("Profile Commands:")
+	fmt.Println()
+	fmt.Println("  myrai profile [list]           List profiles; * marks the one in use")
+	fmt.Println("  myrai profile create <name>    Create a profile")
+	fmt.Println("  myrai profile delete <name> [--yes]")
+	fmt.Println("                                 Delete a profile with its config and data")
+	fmt.Println()
+	fmt.Println("A profile is a separate instance, e.g. a personal and a work assistant")
+	fmt.Println("on one machine. Each keeps its own myrai.yaml, .env, data and workspace")
+	fmt.Println("in ~/.config/myrai/profiles/<name>. Without a profile, Myrai uses the")
+	fmt.Println("default instance.")
+	fmt.Println()
+	fmt.Println("Select one with --profile before the command, or with MYRAI_PROFILE:")
+	fmt.Println("  myrai profile create work")
+	fmt.Println("  myrai --profile work onboard")
+	fmt.Println("  myrai --profile work gateway start --daemon")
+	fmt.Println("  MYRAI_PROFILE=work myrai -m \"What's on today?\"")
+	fmt.Println()
+	fmt.Println("Give each profile that runs a server its own server.port.")
+	fmt.Println()
+}
+
+func PrintGitHelp() {
+	fmt.Println("Git Commands:")
+	fmt.Println()
+	fmt.Println("  myrai git commit-msg                    Write a commit message for the staged changes")
+	fmt.Println("  myrai git commit-msg --commit [-e] [-y] ...and commit with it (-e to edit it first)")
+	fmt.Println("  myrai git pr-desc [--base main]         Write a PR description for this branch")
+	fmt.Println("  myrai git pr-desc -o pr.md              ...and save it to a file")
+	fmt.Println()
+	fmt.Println("Commit messages follow Conventional Commits and the repository's recent")
+	fmt.Println("commits; both are written in your persona's voice. --base defaults to")
+	fmt.Println("main, or master if there is no main.")
+	fmt.Println()
+}
+
+func PrintDoHelp() {
+	fmt.Println("Shell Command Suggestions:")
+	fmt.Println()
+	fmt.Println("  myrai do \"<what you want done>\" [--yes]   Suggest a shell command and run it")
+	fmt.Println("  myrai do --history                        Show commands run this way")
+	fmt.Println()
+	fmt.Println("The command is shown with an explanation and only runs once you")
+	fmt.Println("confirm; --yes skips asking unless the command looks dangerous or")
+	fmt.Println("changes or deletes things.")
+	fmt.Println("Every command run is recorded in the audit log.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  myrai do \"find files over 100MB in my home directory\"")
+	fmt.Println("  myrai do \"show which process is listening on port 8080\"")
+	fmt.Println()
+}
+
+func PrintConversationsHelp() {
+	fmt.Println("Conversation Commands:")
+	fmt.Println()
+	fmt.Println("  myrai conversations [list]                    List recent conversations")
+	fmt.Println("  myrai conversations show <ref> [-n 20]        Show a conversation's last messages")
+	fmt.Println("  myrai conversations resume <ref>              Continue a conversation in the CLI")
+	fmt.Println("  myrai conversations rename <ref> <title>      Rename a conversation")
+	fmt.Println("  myrai conversations delete <ref> [--forget]   Delete a conversation")
+	fmt.Println()
+	fmt.Println("<ref> is the number 'list' shows or a conversation ID (or its start).")
+	fmt.Println("Deleted conversations are hidden; --forget permanently erases the")
+	fmt.Println("messages too, with what was learned from them. -y skips confirming.")
+	fmt.Println()
+	fmt.Println("In 'myrai --cli', /history, /resume <n>, /title <title> and /new do")
+	fmt.Println("the same for the current session.")
+	fmt.Println()
+}
+
+func PrintPrivacyHelp() {
+	fmt.Println("Privacy Commands:")
+	fmt.Println()
+	fmt.Println("  myrai privacy purge --before <date> [--skill name] [-y]  Permanently delete old data")
+	fmt.Println("  myrai privacy redact --before <date> [-y]                Replace old message text")
+	fmt.Println()
+	fmt.Println("<date> is YYYY-MM-DD or an age such as 90d. Without --skill, purge deletes")
+	fmt.Println("old conversations and the old records of every skill that supports it:")
+	fmt.Println("health logs and measurements, expenses, finished tasks and past events.")
+	fmt.Println("Redacting keeps token counts, so usage stats still add up.")
+	fmt.Println()
+	fmt.Println("To do this automatically, set privacy.retention_days and")
+	fmt.Println("privacy.redact_after_days. In chat, ask Myrai to forget the conversation.")
+	fmt.Println()
+}
+
 func PrintBatchHelp() {
 	fmt.Println("Batch Processing Commands:")
 	fmt.Println()
@@ -118,7 +588,11 @@ func PrintBatchHelp() {
 	fmt.Println("  -o, --output <file>      Output file (optional)")
 	fmt.Println("  -c, --concurrency <n>    Max concurrent requests (default: 3)")
 	fmt.Println("  -t, --timeout <sec>      Request timeout in seconds (default: 60)")
-	fmt.Println("  --tier <3|4|5>           Use rate limits for Moonshot tier (optional)")
+	fmt.Println("  --tier <3|4|5>           Override provider rate limits with a Moonshot tier preset")
+	fmt.Println("  -f, --output-format <f>  Output format: text, json, jsonl, csv (default: from extension)")
+	fmt.Println("  --json-schema <file>     Validate each response as JSON against a schema")
+	fmt.Println("  --price-per-1k <usd>     Token price used for the live cost estimate")
+	fmt.Println("  -q, --quiet              Disable progress output (for CI)")
 	fmt.Println("  -h, --help               Show this help")
 	fmt.Println()
 	fmt.Println("Input Formats:")
@@ -130,8 +604,23 @@ func PrintBatchHelp() {
 	fmt.Println("  myrai batch -i prompts.jsonl -o results.json")
 	fmt.Println("  myrai batch -i prompts.txt -c 5 -t 120")
 	fmt.Println("  myrai batch -i big_file.jsonl --tier 3 -o results.json")
+	fmt.Println("  myrai batch -i reviews.jsonl --json-schema sentiment.json -o out.csv")
+	fmt.Println()
+	fmt.Println("Gateway Jobs:")
+	fmt.Println("  A gateway can process a batch in the background while you disconnect.")
+	fmt.Println("  myrai batch submit -i <input> --remote <url> [--wait]   Queue a batch")
+	fmt.Println("  myrai batch status [job_id] --remote <url>              List jobs, or show one's progress")
+	fmt.Println("  myrai batch results <job_id> --remote <url> [-o <file>] [-f <format>]")
+	fmt.Println("                                                          Download the answers so far")
+	fmt.Println("  myrai batch cancel <job_id> --remote <url>              Stop a queued or running job")
+	fmt.Println("  --token <token> (or MYRAI_REMOTE_TOKEN) is the gateway's security.gateway_token.")
 	fmt.Println()
-	fmt.Println("Moonshot Tier Limits:")
+	fmt.Println("Rate Limits:")
+	fmt.Println("  Configured per provider in myrai.yaml and applied to every request:")
+	fmt.Println("    llm.providers.<name>.rate_limit: {rpm, tpm, max_concurrency, burst}")
+	fmt.Println("  Provider rate-limit headers and HTTP 429 responses trigger automatic backoff.")
+	fmt.Println()
+	fmt.Println("Moonshot Tier Presets:")
 	fmt.Println("  Tier 3: 200 concurrent, 5000 RPM, 3M TPM")
 	fmt.Println("  Tier 4: 400 concurrent, 5000 RPM, 4M TPM")
 	fmt.Println("  Tier 5: 1000 concurrent, 10000 RPM, 5M TPM")
@@ -145,6 +634,27 @@ func PrintConfigHelp() {
 	fmt.Println("  myrai config edit             Open config in editor")
 	fmt.Println("  myrai config path             Show config file path")
 	fmt.Println("  myrai config show             Display full config")
+	fmt.Println("  myrai config validate [file]  Check config for errors and unknown keys")
+	fmt.Println("  myrai config schema           Print a JSON schema for editors")
+	fmt.Println()
+}
+
+func PrintSecretHelp() {
+	fmt.Println("Secret Commands:")
+	fmt.Println()
+	fmt.Println("  myrai secret set <NAME> [value]   Store a secret (prompted for if no value)")
+	fmt.Println("  myrai secret get <NAME>           Print a secret")
+	fmt.Println("  myrai secret list                 List stored secrets")
+	fmt.Println("  myrai secret delete <NAME>        Delete a secret")
+	fmt.Println("  myrai secret migrate [--keep] [file]")
+	fmt.Println("                                    Move the secrets in a .env file into the backend")
+	fmt.Println()
+	fmt.Println("Secrets are named like environment variables, e.g. OPENAI_API_KEY, and")
+	fmt.Println("are kept in the backend set by secrets.backend in the config:")
+	fmt.Println("  env        the environment and .env files (default)")
+	fmt.Println("  keychain   macOS Keychain, libsecret or Windows Credential Manager")
+	fmt.Println("  age        an age-encrypted file, unlocked by secrets.identity or")
+	fmt.Println("             MYRAI_SECRETS_PASSPHRASE")
 	fmt.Println()
 }
 
@@ -161,10 +671,28 @@ func PrintChannelsHelp() {
 func PrintGatewayHelp() {
 	fmt.Println("Gateway Commands:")
 	fmt.Println()
-	fmt.Println("  myrai gateway run      Start the server (foreground)")
-	fmt.Println("  myrai gateway status   Show gateway configuration")
-	fmt.Println("  myrai gateway stop     Show how to stop the server")
-	fmt.Println("  myrai gateway logs     Show logging information")
+	fmt.Println("  myrai gateway run                  Start the server (foreground)")
+	fmt.Println("  myrai gateway start --daemon       Start the server in the background")
+	fmt.Println("  myrai gateway stop                 Stop the running server")
+	fmt.Println("  myrai gateway restart              Stop and start it in the background")
+	fmt.Println("  myrai gateway status               Show whether it's running, and its configuration")
+	fmt.Println("  myrai gateway logs [-n 50]         Show the background server's latest log lines")
+	fmt.Println("  myrai gateway install-service      Write a systemd user unit (Linux) or launchd")
+	fmt.Println("                                     agent (macOS) that runs the server")
+	fmt.Println("  myrai gateway uninstall-service    Stop and remove that service")
+	fmt.Println("  myrai gateway profile              Save profiles from the running server")
+	fmt.Println()
+	fmt.Println("Service options:")
+	fmt.Println("  --system       A system-wide systemd unit instead (needs root)")
+	fmt.Println("  --print        Print the unit instead of installing it")
+	fmt.Println()
+	fmt.Println("Profile options (needs security.admin_password):")
+	fmt.Println("  --cpu [30s]    CPU profile over the given time")
+	fmt.Println("  --heap         Heap profile (default)")
+	fmt.Println("  --goroutine    Goroutine dump (default)")
+	fmt.Println("  --allocs       Allocation profile")
+	fmt.Println("  --url <url>    Server address (default from config)")
+	fmt.Println("  --out <dir>    Where to save (default <data_dir>/profiles)")
 	fmt.Println()
 	fmt.Println("Aliases: start = run")
 }
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+// HandleHouseholdCommand manages household profiles. The CLI runs on the
+// host, so it acts with owner rights.
+func HandleHouseholdCommand(args []string) {
+	if len(args) == 0 {
+		PrintHouseholdHelp()
+		return
+	}
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("Error initializing store: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	mgr, err := household.NewManager(st.DB())
+	if err != nil {
+		fmt.Printf("Error initializing household: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai household add <name> [display name] [--owner]")
+			os.Exit(1)
+		}
+		role := household.RoleMember
+		var display []string
+		for _, a := range args[2:] {
+			if a == "--owner" {
+				role = household.RoleOwner
+				continue
+			}
+			display = append(display, a)
+		}
+		profile, err := mgr.Add(args[1], strings.Join(display, " "), role)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Profile '%s' added as %s\n", profile.Name, profile.Role)
+		if profile.UserID == household.SharedUserID {
+			fmt.Println("   Existing data now belongs to this profile and the household.")
+		}
+		fmt.Printf("   Link a chat account with: myrai household invite %s\n", profile.Name)
+
+	case "list", "ls":
+		profiles, err := mgr.List()
+		if err != nil {
+			fmt.Printf("Error listing profiles: %v\n", err)
+			os.Exit(1)
+		}
+		if len(profiles) == 0 {
+			fmt.Println("No household profiles. Add the owner with: myrai household add <name>")
+			return
+		}
+		fmt.Println("Household:")
+		for _, p := range profiles {
+			var accounts []string
+			links, _ := mgr.Links(p.ID)
+			for _, l := range links {
+				accounts = append(accounts, l.Channel+":"+l.ExternalID)
+			}
+			fmt.Printf("  %-16s %-7s %-20s %s\n", p.Name, p.Role, p.Label(), strings.Join(accounts, ", "))
+		}
+		fmt.Printf("\nShared skills: %s\n", strings.Join(cfg.Household.Shared, ", "))
+
+	case "remove", "rm", "delete":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai household remove <name>")
+			os.Exit(1)
+		}
+		if err := mgr.Remove(args[1]); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Profile '%s' removed\n", args[1])
+
+	case "role":
+		if len(args) < 3 {
+			fmt.Println("Usage: myrai household role <name> <owner|member>")
+			os.Exit(1)
+		}
+		profile, err := mgr.SetRole(args[1], args[2])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ %s is now %s\n", profile.Name, profile.Role)
+
+	case "persona":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai household persona <name> \"<instructions>\"")
+			os.Exit(1)
+		}
+		profile, err := mgr.SetPersona(args[1], strings.Join(args[2:], " "))
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if profile.Persona == "" {
+			fmt.Printf("✅ Persona for '%s' cleared\n", profile.Name)
+		} else {
+			fmt.Printf("✅ Persona for '%s' updated\n", profile.Name)
+		}
+
+	case "link":
+		if len(args) < 4 {
+			fmt.Println("Usage: myrai household link <name> <telegram|discord> <account id>")
+			os.Exit(1)
+		}
+		if _, err := mgr.Link(args[1], args[2], args[3]); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ %s:%s linked to '%s'\n", args[2], args[3], args[1])
+
+	case "unlink":
+		if len(args) < 3 {
+			fmt.Println("Usage: myrai household unlink <telegram|discord> <account id>")
+			os.Exit(1)
+		}
+		if err := mgr.Unlink(args[1], args[2]); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ %s:%s unlinked\n", args[1], args[2])
+
+	case "invite":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai household invite <name>")
+			os.Exit(1)
+		}
+		owner, err := mgr.Owner()
+		if err != nil || owner == nil {
+			fmt.Println("❌ Add the household owner first: myrai household add <name>")
+			os.Exit(1)
+		}
+		invite, err := mgr.CreateInvite(owner, args[1])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✉️  Invite code for '%s': %s\n", strings.ToLower(args[1]), invite.Code)
+		fmt.Printf("   Send /join %s to the bot from their Telegram or Discord account.\n", invite.Code)
+		fmt.Printf("   Expires %s\n", invite.ExpiresAt.Format("2006-01-02 15:04"))
+
+	default:
+		PrintHouseholdHelp()
+	}
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+// Environment variables that point the CLI at a gateway elsewhere; the
+// --remote and --token flags set them
+const (
+	RemoteURLEnv   = "MYRAI_REMOTE_URL"
+	RemoteTokenEnv = "MYRAI_REMOTE_TOKEN"
+)
+
+// ParseGlobalFlags takes the flags that pick which instance to use off the
+// start of args: --profile <name>, also accepted after onboard, and
+// --remote <url> with --token <token>. A profile must exist unless it is
+// being set up or managed.
+func ParseGlobalFlags(args []string) ([]string, error) {
+	profile := config.ActiveProfile()
+	for {
+		name, value, rest, found := leadingFlag(args, "profile", "remote", "token")
+		if !found {
+			break
+		}
+		if value == "" {
+			return nil, fmt.Errorf("--%s needs a value", name)
+		}
+		switch name {
+		case "profile":
+			profile = value
+		case "remote":
+			os.Setenv(RemoteURLEnv, value)
+		case "token":
+			os.Setenv(RemoteTokenEnv, value)
+		}
+		args = rest
+	}
+	if len(args) > 0 && args[0] == "onboard" {
+		if _, value, rest, found := leadingFlag(args[1:], "profile"); found {
+			if value == "" {
+				return nil, fmt.Errorf("--profile needs a value")
+			}
+			profile = value
+			args = append([]string{"onboard"}, rest...)
+		}
+	}
+	if profile == "" {
+		return args, nil
+	}
+
+	if err := config.ValidateProfileName(profile); err != nil {
+		return nil, err
+	}
+	managing := len(args) > 0 && (args[0] == "onboard" || args[0] == "profile" || args[0] == "profiles")
+	if !managing && !config.ProfileExists(profile) {
+		return nil, fmt.Errorf("profile %q doesn't exist; create it with 'myrai profile create %s'", profile, profile)
+	}
+	os.Setenv(config.ProfileEnv, profile)
+	return args, nil
+}
+
+// leadingFlag finds one of names as --name <value> or --name=<value> at the
+// start of args
+func leadingFlag(args []string, names ...string) (name, value string, rest []string, found bool) {
+	if len(args) == 0 || !strings.HasPrefix(args[0], "-") {
+		return "", "", args, false
+	}
+	flag, value, inline := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+	for _, name := range names {
+		if flag != name {
+			continue
+		}
+		if inline {
+			return name, value, args[1:], true
+		}
+		if len(args) < 2 {
+			return name, "", nil, true
+		}
+		return name, args[1], args[2:], true
+	}
+	return "", "", args, false
+}
+
+// HandleProfileCommand manages profiles, separate instances with their own
+// config, data and workspace
+func HandleProfileCommand(args []string) {
+	if len(args) == 0 || args[0] == "list" || args[0] == "ls" {
+		listProfiles()
+		return
+	}
+
+	switch args[0] {
+	case "create", "new":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai profile create <name>")
+			os.Exit(1)
+		}
+		name := args[1]
+		if err := config.CreateProfile(name); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Created profile %s in %s\n", name, config.ProfileDir(name))
+		fmt.Println()
+		fmt.Printf("Set it up with 'myrai --profile %s onboard', then use it with\n", name)
+		fmt.Printf("'myrai --profile %s <command>' or MYRAI_PROFILE=%s.\n", name, name)
+	case "delete", "remove", "rm":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai profile delete <name> [--yes]")
+			os.Exit(1)
+		}
+		name := args[1]
+		if name == config.ActiveProfile() {
+			fmt.Printf("❌ Profile %s is in use; run this without --profile or MYRAI_PROFILE\n", name)
+			os.Exit(1)
+		}
+		if !config.ProfileExists(name) {
+			fmt.Printf("❌ Profile %q doesn't exist\n", name)
+			os.Exit(1)
+		}
+		if !hasFlag(args[2:], "--yes", "-y") &&
+			!confirmPrivacy(fmt.Sprintf("This deletes %s with the profile's config, secrets, memory and conversations.", config.ProfileDir(name))) {
+			return
+		}
+		if err := config.DeleteProfile(name); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Deleted profile %s\n", name)
+	case "help", "--help", "-h":
+		PrintProfileHelp()
+	default:
+		fmt.Printf("Unknown profile command: %s\n\n", args[0])
+		PrintProfileHelp()
+		os.Exit(1)
+	}
+}
+
+func listProfiles() {
+	names, err := config.ListProfiles()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	active := config.ActiveProfile()
+
+	fmt.Println("Profiles:")
+	fmt.Println()
+	printProfileLine("default", active == "", "")
+	for _, name := range names {
+		printProfileLine(name, name == active, config.ProfileDir(name))
+	}
+	fmt.Println()
+	fmt.Println("Use one with 'myrai --profile <name> <command>' or MYRAI_PROFILE=<name>")
+}
+
+func printProfileLine(name string, active bool, dir string) {
+	marker := " "
+	if active {
+		marker = "*"
+	}
+	status := ""
+	if dir != "" {
+		if _, err := os.Stat(filepath.Join(dir, "myrai.yaml")); err != nil {
+			status = "  (not set up; run 'myrai --profile " + name + " onboard')"
+		}
+	}
+	fmt.Printf("  %s %-16s %s%s\n", marker, name, dir, status)
+}
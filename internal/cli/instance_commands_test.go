@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+func TestParseGlobalFlagsProfile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(config.ProfileEnv, "")
+	if err := config.CreateProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args    []string
+		rest    string
+		profile string
+		wantErr bool
+	}{
+		{args: []string{"status"}, rest: "status"},
+		{args: []string{"--profile", "work", "gateway", "run"}, rest: "gateway run", profile: "work"},
+		{args: []string{"--profile=work", "-m", "hi"}, rest: "-m hi", profile: "work"},
+		{args: []string{"onboard", "--profile", "new", "-y"}, rest: "onboard -y", profile: "new"},
+		{args: []string{"--profile", "new", "profile", "list"}, rest: "profile list", profile: "new"},
+		{args: []string{"--profile", "missing", "status"}, wantErr: true},
+		{args: []string{"--profile", "../etc", "onboard"}, wantErr: true},
+		{args: []string{"--profile"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		os.Setenv(config.ProfileEnv, "")
+		rest, err := ParseGlobalFlags(tt.args)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseGlobalFlags(%v): expected an error", tt.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseGlobalFlags(%v): %v", tt.args, err)
+			continue
+		}
+		if strings.Join(rest, " ") != tt.rest || config.ActiveProfile() != tt.profile {
+			t.Errorf("ParseGlobalFlags(%v) = %v with profile %q, want %q with %q", tt.args, rest, config.ActiveProfile(), tt.rest, tt.profile)
+		}
+	}
+
+	// A profile named in the environment must exist too
+	os.Setenv(config.ProfileEnv, "missing")
+	if _, err := ParseGlobalFlags([]string{"status"}); err == nil {
+		t.Error("Expected MYRAI_PROFILE naming a missing profile to fail")
+	}
+	if _, err := os.Stat(filepath.Join(config.ProfilesDir(), "new")); !os.IsNotExist(err) {
+		t.Error("Expected selecting a profile not to create it")
+	}
+}
+
+func TestParseGlobalFlagsRemote(t *testing.T) {
+	t.Setenv(config.ProfileEnv, "")
+	t.Setenv(RemoteURLEnv, "")
+	t.Setenv(RemoteTokenEnv, "")
+
+	rest, err := ParseGlobalFlags([]string{"--remote", "https://vps:8080", "--token=abc", "conversations", "list"})
+	if err != nil {
+		t.Fatalf("ParseGlobalFlags failed: %v", err)
+	}
+	if strings.Join(rest, " ") != "conversations list" {
+		t.Errorf("Expected the command left, got %v", rest)
+	}
+	if os.Getenv(RemoteURLEnv) != "https://vps:8080" || os.Getenv(RemoteTokenEnv) != "abc" {
+		t.Errorf("Expected the gateway settings in the environment, got %q and %q", os.Getenv(RemoteURLEnv), os.Getenv(RemoteTokenEnv))
+	}
+	if client := RemoteClient(); client == nil || client.URL() != "https://vps:8080" {
+		t.Errorf("Expected a client for the gateway, got %v", client)
+	}
+
+	if _, err := ParseGlobalFlags([]string{"--token"}); err == nil {
+		t.Error("Expected --token without a value to fail")
+	}
+	// Flags after the command belong to it
+	rest, _ = ParseGlobalFlags([]string{"-m", "hi", "--remote", "x"})
+	if len(rest) != 4 {
+		t.Errorf("Expected later flags left alone, got %v", rest)
+	}
+}
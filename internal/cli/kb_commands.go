@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/skills/browser"
+	"github.com/gmsas95/myrai-cli/internal/skills/kb"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/vector"
+	"go.uber.org/zap"
+)
+
+// HandleKBCommand adds files and web pages to, lists, searches and removes knowledge base
+// documents. Without --profile it acts on the default user's documents.
+func HandleKBCommand(args []string) {
+	if len(args) == 0 {
+		PrintKBHelp()
+		return
+	}
+
+	profileName := ""
+	title := ""
+	limit := 5
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--profile" && i+1 < len(args):
+			profileName = args[i+1]
+			i++
+		case args[i] == "--title" && i+1 < len(args):
+			title = args[i+1]
+			i++
+		case (args[i] == "--limit" || args[i] == "-n") && i+1 < len(args):
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n <= 0 {
+				fmt.Printf("❌ Invalid limit: %s\n", args[i+1])
+				os.Exit(1)
+			}
+			limit = n
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	if len(rest) == 0 {
+		PrintKBHelp()
+		return
+	}
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("Error initializing store: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	kbStore, err := kb.NewStore(st.DB(), kb.DefaultDir(cfg.Storage.DataDir), nil)
+	if err != nil {
+		fmt.Printf("Error initializing knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+	// Documents are embedded as they are in the server, so both search alike
+	if cfg.Vector.Enabled {
+		if searcher, err := vector.NewSearcher(&cfg.Vector, st, zap.NewNop()); err != nil {
+			fmt.Printf("⚠️  Vector search unavailable, matching words instead: %v\n", err)
+		} else {
+			kbStore.SetEmbedder(searcher)
+			kbStore.SetVectorStore(searcher.Vectors())
+		}
+	}
+
+	if cfg.Skills.Browser.Enabled {
+		kbStore.SetRenderer(browser.NewBrowserSkill(browser.Config{
+			Enabled:  true,
+			Headless: cfg.Skills.Browser.Headless,
+		}))
+	}
+
+	// A shared knowledge base belongs to the shared user whichever profile asks
+	shared := slices.ContainsFunc(cfg.Household.Shared, func(s string) bool { return strings.EqualFold(s, "kb") })
+	userID := household.SharedUserID
+	if profileName != "" && !shared {
+		hh, err := household.NewManager(st.DB())
+		if err != nil {
+			fmt.Printf("Error initializing household: %v\n", err)
+			os.Exit(1)
+		}
+		profile, err := hh.Get(profileName)
+		if err != nil || profile == nil {
+			fmt.Printf("❌ Profile not found: %s\n", profileName)
+			os.Exit(1)
+		}
+		userID = profile.UserID
+	}
+
+	switch rest[0] {
+	case "add":
+		if len(rest) < 2 {
+			fmt.Println("Usage: myrai kb add <file>... [--title t] [--profile name]")
+			os.Exit(1)
+		}
+		if title != "" && len(rest) > 2 {
+			fmt.Println("❌ --title can only be used when adding one file")
+			os.Exit(1)
+		}
+		failed := false
+		for _, path := range rest[1:] {
+			doc, err := kbStore.Add(kb.AddOptions{Path: path, Title: title, UserID: userID, Source: "cli"})
+			if err != nil {
+				fmt.Printf("❌ %s: %v\n", path, err)
+				failed = true
+				continue
+			}
+			fmt.Printf("✅ Added %s (%s, %d passages)\n", doc.Title, doc.ID, doc.Chunks)
+		}
+		if failed {
+			os.Exit(1)
+		}
+
+	case "add-url":
+		if len(rest) < 2 {
+			fmt.Println("Usage: myrai kb add-url <url>... [--title t] [--profile name]")
+			os.Exit(1)
+		}
+		if title != "" && len(rest) > 2 {
+			fmt.Println("❌ --title can only be used when adding one page")
+			os.Exit(1)
+		}
+		failed := false
+		for _, link := range rest[1:] {
+			doc, err := kbStore.AddURL(context.Background(), link, kb.AddOptions{Title: title, UserID: userID, Source: "cli"})
+			if err != nil {
+				fmt.Printf("❌ %s: %v\n", link, err)
+				failed = true
+				continue
+			}
+			fmt.Printf("✅ Added %s (%s, %d passages)\n", doc.Title, doc.ID, doc.Chunks)
+		}
+		if failed {
+			os.Exit(1)
+		}
+
+	case "list":
+		docs, err := kbStore.List(userID)
+		if err != nil {
+			fmt.Printf("❌ Failed to list documents: %v\n", err)
+			os.Exit(1)
+		}
+		if len(docs) == 0 {
+			fmt.Println("No documents yet. Add one with: myrai kb add <file>")
+			return
+		}
+		for _, doc := range docs {
+			fmt.Printf("%s  %-30s %4d passages  %s  %s\n",
+				doc.ID, doc.Title, doc.Chunks, doc.Source, doc.CreatedAt.Format("2006-01-02"))
+		}
+
+	case "search":
+		query := strings.Join(rest[1:], " ")
+		if query == "" {
+			fmt.Println("Usage: myrai kb search <query> [-n limit] [--profile name]")
+			os.Exit(1)
+		}
+		matches, err := kbStore.Search(userID, query, limit)
+		if err != nil {
+			fmt.Printf("❌ Search failed: %v\n", err)
+			os.Exit(1)
+		}
+		if len(matches) == 0 {
+			fmt.Println("No matching passages.")
+			return
+		}
+		for _, m := range matches {
+			fmt.Printf("📄 %s (%s, passage %d, score %.2f)\n", m.Title, m.DocumentID, m.Position+1, m.Score)
+			if m.SourceURL != "" {
+				fmt.Printf("   %s\n", m.SourceURL)
+			}
+			fmt.Printf("   %s\n\n", strings.ReplaceAll(truncateString(m.Content, 400), "\n", "\n   "))
+		}
+
+	case "remove", "rm":
+		if len(rest) < 2 {
+			fmt.Println("Usage: myrai kb remove <document id> [--profile name]")
+			os.Exit(1)
+		}
+		if err := kbStore.Remove(userID, rest[1]); err != nil {
+			fmt.Printf("❌ Document not found: %s\n", rest[1])
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Removed %s\n", rest[1])
+
+	default:
+		PrintKBHelp()
+	}
+}
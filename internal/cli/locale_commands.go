@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+// HandleLocaleCommand shows and changes language and regional preferences.
+// Without --profile it acts on the default user, which is also the first
+// household owner.
+func HandleLocaleCommand(args []string) {
+	if len(args) == 0 {
+		PrintLocaleHelp()
+		return
+	}
+
+	if args[0] == "languages" {
+		fmt.Println("Language presets:")
+		for _, tag := range locale.Languages() {
+			l, _ := locale.ForLanguage(tag)
+			fmt.Printf("  %-6s %s\n", tag, l.Summary())
+		}
+		return
+	}
+
+	profileName := ""
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--profile" && i+1 < len(args) {
+			profileName = args[i+1]
+			i++
+			continue
+		}
+		rest = append(rest, args[i])
+	}
+	if len(rest) == 0 {
+		PrintLocaleHelp()
+		return
+	}
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("Error initializing store: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	mgr, err := locale.NewManager(st.DB())
+	if err != nil {
+		fmt.Printf("Error initializing locales: %v\n", err)
+		os.Exit(1)
+	}
+
+	userID := household.SharedUserID
+	if profileName != "" {
+		hh, err := household.NewManager(st.DB())
+		if err != nil {
+			fmt.Printf("Error initializing household: %v\n", err)
+			os.Exit(1)
+		}
+		profile, err := hh.Get(profileName)
+		if err != nil || profile == nil {
+			fmt.Printf("❌ Profile not found: %s\n", profileName)
+			os.Exit(1)
+		}
+		userID = profile.UserID
+	}
+
+	switch rest[0] {
+	case "show":
+		l := mgr.Get(userID)
+		if !mgr.IsSet(userID) {
+			fmt.Println("No locale set; using the default.")
+		}
+		fmt.Printf("🌐 %s\n", l.Summary())
+
+	case "set":
+		if len(rest) < 3 {
+			fmt.Println("Usage: myrai locale set <language|date_order|clock|first_day|currency|units> <value> [--profile name]")
+			os.Exit(1)
+		}
+		l, err := locale.Apply(mgr.Get(userID), rest[1], rest[2])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if err := mgr.Set(userID, l); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Locale updated: %s\n", l.Summary())
+
+	case "reset":
+		if err := mgr.Reset(userID); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Locale reset to the default: %s\n", locale.Default().Summary())
+
+	default:
+		PrintLocaleHelp()
+	}
+}
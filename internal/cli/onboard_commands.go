@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/onboarding"
+	"go.uber.org/zap"
+)
+
+// HandleOnboardCommand runs setup without prompts, from flags and an
+// optional answers file. The interactive wizard is run by the caller when
+// there are no arguments.
+func HandleOnboardCommand(args []string) {
+	if hasFlag(args, "help", "--help", "-h") {
+		PrintOnboardHelp()
+		return
+	}
+
+	answers, overwrite, err := parseOnboardArgs(args)
+	if err != nil {
+		fmt.Printf("❌ %v\n\n", err)
+		PrintOnboardHelp()
+		os.Exit(1)
+	}
+
+	result, err := onboarding.RunNonInteractive(*answers, overwrite, zap.NewNop())
+	if err != nil {
+		fmt.Printf("❌ Onboarding failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("✓ Config written:", result.ConfigPath)
+	fmt.Println("✓ Secrets written:", result.EnvPath)
+	fmt.Println("✓ Workspace and persona files in:", result.DataDir)
+	fmt.Println()
+	fmt.Println("Run 'myrai doctor' to check the setup, then 'myrai server' to start.")
+}
+
+// parseOnboardArgs reads the answers file given with --from, then lets the
+// other flags override it
+func parseOnboardArgs(args []string) (*onboarding.Answers, bool, error) {
+	answers := &onboarding.Answers{}
+	if from := flagValue(args, "--from"); from != "" {
+		loaded, err := onboarding.LoadAnswers(from)
+		if err != nil {
+			return nil, false, err
+		}
+		answers = loaded
+	} else if !hasFlag(args, "--non-interactive", "-y") {
+		return nil, false, fmt.Errorf("pass --non-interactive or --from <answers.yaml> to set up without prompts")
+	}
+
+	overwrite := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--non-interactive", "-y":
+			continue
+		case "--overwrite":
+			overwrite = true
+			continue
+		case "--check":
+			answers.Check = true
+			continue
+		}
+
+		if i+1 >= len(args) {
+			return nil, false, fmt.Errorf("unknown or incomplete option %s", args[i])
+		}
+		value := args[i+1]
+		switch args[i] {
+		case "--from":
+		case "--provider":
+			answers.Provider = value
+		case "--model":
+			answers.Model = value
+		case "--api-key":
+			answers.APIKey = value
+		case "--api-key-env":
+			answers.APIKeyEnv = value
+		case "--base-url":
+			answers.BaseURL = value
+		case "--name":
+			answers.Name = value
+		case "--style":
+			answers.CommunicationStyle = value
+		case "--expertise":
+			answers.Expertise = splitList(value)
+		case "--goals":
+			answers.Goals = splitList(value)
+		case "--data-dir":
+			answers.DataDir = value
+		case "--config-dir":
+			answers.ConfigDir = value
+		case "--telegram-token-env":
+			answers.TelegramTokenEnv = value
+		case "--search-provider":
+			answers.SearchProvider = value
+		case "--search-api-key-env":
+			answers.SearchAPIKeyEnv = value
+		case "--vision-model":
+			answers.VisionModel = value
+		default:
+			return nil, false, fmt.Errorf("unknown option %s", args[i])
+		}
+		i++
+	}
+	return answers, overwrite, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
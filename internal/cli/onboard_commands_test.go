@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseOnboardArgs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.yaml")
+	os.WriteFile(path, []byte("provider: groq\nmodel: llama-3.3-70b\nname: Sam\n"), 0644)
+
+	answers, overwrite, err := parseOnboardArgs([]string{"--from", path, "--model", "llama-4", "--expertise", "Go, Rust", "--overwrite"})
+	if err != nil {
+		t.Fatalf("parseOnboardArgs failed: %v", err)
+	}
+	if answers.Provider != "groq" || answers.Name != "Sam" {
+		t.Errorf("expected answers from the file, got %+v", answers)
+	}
+	if answers.Model != "llama-4" || len(answers.Expertise) != 2 || answers.Expertise[1] != "Rust" {
+		t.Errorf("expected flags to override the file, got %+v", answers)
+	}
+	if !overwrite {
+		t.Error("expected --overwrite")
+	}
+
+	if _, _, err := parseOnboardArgs([]string{"--provider", "openai"}); err == nil {
+		t.Error("expected --non-interactive or --from to be required")
+	}
+	if _, _, err := parseOnboardArgs([]string{"--non-interactive", "--bogus", "x"}); err == nil {
+		t.Error("expected unknown options to be refused")
+	}
+	if _, _, err := parseOnboardArgs([]string{"--non-interactive", "--model"}); err == nil {
+		t.Error("expected a missing value to be refused")
+	}
+}
@@ -3,11 +3,11 @@ package cli
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/gmsas95/myrai-cli/internal/config"
-	"github.com/gmsas95/myrai-cli/internal/onboarding"
 	"github.com/gmsas95/myrai-cli/internal/persona"
 	"github.com/gmsas95/myrai-cli/internal/store"
 	"go.uber.org/zap"
@@ -411,8 +411,7 @@ func initPersonaEvolution(logger *zap.Logger) (*config.Config, *store.Store, *pe
 		os.Exit(1)
 	}
 
-	workspace := onboarding.GetWorkspacePath()
-	pm, err := persona.NewPersonaManager(workspace, logger)
+	pm, err := persona.NewPersonaManager(cfg.Storage.DataDir, logger)
 	if err != nil {
 		fmt.Printf("Error initializing persona manager: %v\n", err)
 		os.Exit(1)
@@ -483,3 +482,109 @@ func applyProposal(pm *persona.PersonaManager, proposal *persona.EvolutionPropos
 
 	return nil
 }
+
+// identityFieldHelp describes each identity field in the persona wizard
+var identityFieldHelp = map[string]string{
+	"name":        "What should the assistant be called?",
+	"personality": "Describe its personality in one line",
+	"voice":       "How should it talk? e.g. terse, warm, formal",
+	"values":      "What does it value? (comma-separated)",
+	"expertise":   "What is it expert in? (comma-separated)",
+}
+
+// HandlePersonaWizard walks through a persona's identity field by field,
+// checking each answer, and saves it once confirmed
+func HandlePersonaWizard(pm *persona.PersonaManager, name string) {
+	identity, err := pm.PersonaIdentity(name)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Editing the %s persona. Press Enter to keep a value, '-' to clear it.\n", name)
+	reader := bufio.NewReader(os.Stdin)
+	for _, field := range persona.IdentityFields {
+		if !promptIdentityField(reader, os.Stdout, identity, field) {
+			fmt.Println("\nCancelled")
+			return
+		}
+	}
+
+	fmt.Println()
+	fmt.Println(identity.String())
+	fmt.Print("Save this identity? (Y/n): ")
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	if response != "" && response != "y" && response != "yes" {
+		fmt.Println("Cancelled")
+		return
+	}
+
+	if err := pm.SavePersonaIdentity(name, identity); err != nil {
+		fmt.Printf("Error saving persona: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Saved the %s persona\n", name)
+}
+
+// promptIdentityField asks for one field until the answer is valid, and
+// reports false once input runs out
+func promptIdentityField(reader *bufio.Reader, out io.Writer, identity *persona.Identity, field string) bool {
+	for {
+		current, _ := identity.Get(field)
+		fmt.Fprintf(out, "\n%s\n", identityFieldHelp[field])
+		if current != "" {
+			fmt.Fprintf(out, "[%s] ", current)
+		}
+		fmt.Fprint(out, "> ")
+
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return false
+		}
+		line = strings.TrimSpace(line)
+		switch line {
+		case "":
+			return true
+		case "-":
+			line = ""
+		}
+
+		if err := identity.Set(field, line); err != nil {
+			fmt.Fprintf(out, "❌ %v\n", err)
+			continue
+		}
+		return true
+	}
+}
+
+// HandlePersonaSet changes one identity field of a persona from the command
+// line, e.g. 'myrai persona set voice terse and to the point'
+func HandlePersonaSet(pm *persona.PersonaManager, name string, args []string) {
+	if len(args) < 1 {
+		fmt.Printf("Usage: myrai persona set <%s> <value> [--persona <name>]\n", strings.Join(persona.IdentityFields, "|"))
+		os.Exit(1)
+	}
+
+	identity, err := pm.PersonaIdentity(name)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	field := strings.ToLower(args[0])
+	if err := identity.Set(field, strings.Join(args[1:], " ")); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := pm.SavePersonaIdentity(name, identity); err != nil {
+		fmt.Printf("Error saving persona: %v\n", err)
+		os.Exit(1)
+	}
+
+	value, _ := identity.Get(field)
+	if value == "" {
+		fmt.Printf("✓ Cleared %s of the %s persona\n", field, name)
+		return
+	}
+	fmt.Printf("✓ Set %s of the %s persona to: %s\n", field, name, value)
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/app"
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/persona"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/strutil"
+	"go.uber.org/zap"
+)
+
+// HandlePlanCommand inspects and runs task plans created by the agent
+func HandlePlanCommand(args []string) {
+	if len(args) == 0 {
+		PrintPlanHelp()
+		return
+	}
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("Error initializing store: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	switch args[0] {
+	case "list", "ls":
+		status := ""
+		if len(args) > 1 {
+			status = args[1]
+		}
+		plans, err := st.ListPlans(status, 50)
+		if err != nil {
+			fmt.Printf("Error listing plans: %v\n", err)
+			os.Exit(1)
+		}
+		if len(plans) == 0 {
+			fmt.Println("No task plans yet. Ask Myrai to plan a complex task to create one.")
+			return
+		}
+		fmt.Println("Task plans:")
+		for _, p := range plans {
+			done, total := p.Progress()
+			goal := strutil.Truncate(p.Goal, 50)
+			fmt.Printf("  %-22s %-9s %2d/%-2d  %s\n", p.ID, p.Status, done, total, goal)
+		}
+
+	case "show":
+		plan := mustGetPlan(st, args)
+		fmt.Print(agent.FormatPlan(plan))
+
+	case "run", "resume":
+		plan := mustGetPlan(st, args)
+		convID := ""
+		for i, a := range args {
+			if (a == "--conversation" || a == "-c") && i+1 < len(args) {
+				convID = args[i+1]
+			}
+		}
+		runPlan(cfg, st, plan.ID, convID)
+
+	case "pause":
+		plan := mustGetPlan(st, args)
+		if plan.Status != store.PlanStatusActive {
+			fmt.Printf("Plan %s is %s, not active\n", plan.ID, plan.Status)
+			return
+		}
+		plan.Status = store.PlanStatusPaused
+		if err := st.UpdatePlan(plan); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("⏸️  Plan %s paused. It stops after the current step; resume with: myrai plan resume %s\n", plan.ID, plan.ID)
+
+	case "delete", "rm":
+		plan := mustGetPlan(st, args)
+		if err := st.DeletePlan(plan.ID); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Plan %s deleted\n", plan.ID)
+
+	default:
+		PrintPlanHelp()
+	}
+}
+
+func mustGetPlan(st *store.Store, args []string) *store.Plan {
+	if len(args) < 2 {
+		fmt.Printf("Usage: myrai plan %s <id>\n", args[0])
+		os.Exit(1)
+	}
+	plan, err := st.GetPlan(args[1])
+	if err != nil {
+		fmt.Printf("❌ Plan not found: %s\n", args[1])
+		os.Exit(1)
+	}
+	return plan
+}
+
+func runPlan(cfg *config.Config, st *store.Store, planID, convID string) {
+	logCfg := zap.NewDevelopmentConfig()
+	logCfg.Level = zap.NewAtomicLevelAt(zap.WarnLevel)
+	logger, _ := logCfg.Build()
+	defer logger.Sync()
+
+	pm, err := persona.NewPersonaManager(cfg.Storage.DataDir, logger)
+	if err != nil {
+		logger.Warn("Failed to initialize persona manager", zap.Error(err))
+	}
+
+	provider, err := cfg.DefaultProvider()
+	if err != nil {
+		fmt.Printf("Error getting LLM provider: %v\n", err)
+		os.Exit(1)
+	}
+	llmClient := llm.NewClient(provider)
+
+	skillsRegistry := skills.NewRegistry(st)
+	app.RegisterSkills(cfg, st, skillsRegistry, logger, llmClient)
+
+	agentInstance := agent.New(llmClient, nil, st, logger, pm)
+	agentInstance.SetSkillsRegistry(skillsRegistry)
+	agentInstance.SetLoopOptions(agent.LoopOptionsFromConfig(cfg.Agent.Loop))
+
+	// Ctrl+C pauses the plan; the interrupted step is retried on resume
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("▶️  Running plan %s (Ctrl+C to pause)\n\n", planID)
+	plan, err := agentInstance.RunPlan(ctx, planID, convID, func(step store.PlanStep) {
+		icon := "✅"
+		if step.Status == store.StepStatusFailed {
+			icon = "❌"
+		}
+		fmt.Printf("%s %d. %s\n", icon, step.Position, step.Description)
+	})
+	if plan != nil {
+		fmt.Println()
+		fmt.Print(agent.FormatPlan(plan))
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			fmt.Printf("\n⏸️  Plan paused. Resume with: myrai plan resume %s\n", planID)
+			return
+		}
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+// HandlePrivacyCommand permanently deletes or redacts old data
+func HandlePrivacyCommand(args []string) {
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		PrintPrivacyHelp()
+		return
+	}
+
+	var before time.Time
+	var skill string
+	for i := 1; i < len(args); i++ {
+		switch {
+		case args[i] == "--before" && i+1 < len(args):
+			t, err := parseCutoff(args[i+1], time.Now())
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			before = t
+			i++
+		case args[i] == "--skill" && i+1 < len(args):
+			skill = args[i+1]
+			i++
+		}
+	}
+	if before.IsZero() && (args[0] == "purge" || args[0] == "redact") {
+		fmt.Printf("Usage: myrai privacy %s --before <YYYY-MM-DD|Nd>\n", args[0])
+		os.Exit(1)
+	}
+	yes := hasFlag(args, "--yes", "-y")
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "purge":
+		components := store.PurgeComponents()
+		if skill != "" {
+			if !slices.Contains(components, skill) {
+				fmt.Printf("❌ Nothing to purge for %s\n", skill)
+				fmt.Println("Components: " + strings.Join(components, ", "))
+				os.Exit(1)
+			}
+			components = []string{skill}
+		}
+		if !yes && !confirmPrivacy(fmt.Sprintf("This permanently deletes %s data from before %s.",
+			strings.Join(components, ", "), before.Format("2006-01-02"))) {
+			return
+		}
+
+		st := openPrivacyStore(cfg)
+		defer st.Close()
+		for _, component := range components {
+			n, err := store.Purge(st.DB(), component, before)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("   ✓ %-14s %d deleted\n", component, n)
+		}
+		printBackupNote(cfg)
+
+	case "redact":
+		if !yes && !confirmPrivacy(fmt.Sprintf("This permanently replaces the text of messages from before %s with %s.",
+			before.Format("2006-01-02"), store.RedactedContent)) {
+			return
+		}
+
+		st := openPrivacyStore(cfg)
+		defer st.Close()
+		n, err := st.RedactMessages(before)
+		if err != nil {
+			fmt.Printf("❌ Failed after %d message(s): %v\n", n, err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Redacted %d message(s); token counts are kept\n", n)
+		printBackupNote(cfg)
+
+	default:
+		fmt.Printf("Unknown privacy command: %s\n\n", args[0])
+		PrintPrivacyHelp()
+		os.Exit(1)
+	}
+}
+
+// parseCutoff reads a date (YYYY-MM-DD, local time) or an age in days such
+// as 90d
+func parseCutoff(s string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("invalid age %q, use e.g. 90d", s)
+		}
+		return now.AddDate(0, 0, -n), nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD or e.g. 90d", s)
+	}
+	return t, nil
+}
+
+func openPrivacyStore(cfg *config.Config) *store.Store {
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("Error initializing store: %v\n", err)
+		os.Exit(1)
+	}
+	return st
+}
+
+func confirmPrivacy(what string) bool {
+	fmt.Println(what)
+	fmt.Print("Continue? (y/N): ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	if answer != "y" && answer != "yes" {
+		fmt.Println("Cancelled")
+		return false
+	}
+	return true
+}
+
+func printBackupNote(cfg *config.Config) {
+	backups, _ := store.ListBackups(&cfg.Storage)
+	archives, _ := store.ListArchives(store.ArchiveDir(&cfg.Storage))
+	if len(backups)+len(archives) > 0 {
+		fmt.Println("   Backups taken before now still hold the old data; see 'myrai backup list'.")
+	}
+}
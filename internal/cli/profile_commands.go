@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+// maxCPUProfile caps how long a CPU profile may run
+const maxCPUProfile = 5 * time.Minute
+
+// profileRequest is one file to fetch from the running server
+type profileRequest struct {
+	name    string
+	path    string
+	ext     string
+	timeout time.Duration
+}
+
+// HandleGatewayProfileCommand fetches profiles from a running server's
+// diagnostics endpoints and stores them for go tool pprof
+func HandleGatewayProfileCommand(args []string) {
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	baseURL := defaultGatewayURL(cfg)
+	outDir := filepath.Join(cfg.Storage.DataDir, "profiles")
+	var requests []profileRequest
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--cpu":
+			duration := 30 * time.Second
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				duration, err = time.ParseDuration(args[i+1])
+				if err != nil || duration < time.Second || duration > maxCPUProfile {
+					fmt.Printf("Invalid CPU profile duration %q: use e.g. 30s, at most %s\n", args[i+1], maxCPUProfile)
+					os.Exit(1)
+				}
+				i++
+			}
+			seconds := int(duration.Seconds())
+			requests = append(requests, profileRequest{
+				name:    "cpu",
+				path:    fmt.Sprintf("/debug/pprof/profile?seconds=%d", seconds),
+				ext:     ".pb.gz",
+				timeout: duration + 30*time.Second,
+			})
+		case "--heap":
+			requests = append(requests, profileRequest{name: "heap", path: "/debug/pprof/heap?gc=1", ext: ".pb.gz"})
+		case "--allocs":
+			requests = append(requests, profileRequest{name: "allocs", path: "/debug/pprof/allocs", ext: ".pb.gz"})
+		case "--goroutine":
+			requests = append(requests, profileRequest{name: "goroutine", path: "/debug/pprof/goroutine?debug=1", ext: ".txt"})
+		case "--url":
+			if i+1 < len(args) {
+				baseURL = strings.TrimRight(args[i+1], "/")
+				i++
+			}
+		case "--out", "-o":
+			if i+1 < len(args) {
+				outDir = args[i+1]
+				i++
+			}
+		case "--help", "-h", "help":
+			PrintGatewayHelp()
+			return
+		default:
+			fmt.Printf("Unknown profile option: %s\n\n", args[i])
+			PrintGatewayHelp()
+			os.Exit(1)
+		}
+	}
+
+	if cfg.Security.AdminPassword == "" {
+		fmt.Println("❌ Diagnostics need an admin password: set security.admin_password")
+		fmt.Println("   (or MYRAI_SECURITY_ADMIN_PASSWORD) and restart the server")
+		os.Exit(1)
+	}
+	if len(requests) == 0 {
+		requests = []profileRequest{
+			{name: "heap", path: "/debug/pprof/heap?gc=1", ext: ".pb.gz"},
+			{name: "goroutine", path: "/debug/pprof/goroutine?debug=1", ext: ".txt"},
+		}
+	}
+	// The runtime snapshot is cheap and puts the profiles in context
+	requests = append(requests, profileRequest{name: "runtime", path: "/debug/runtime", ext: ".json"})
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		fmt.Printf("Error creating %s: %v\n", outDir, err)
+		os.Exit(1)
+	}
+
+	stamp := time.Now().Format("20060102-150405")
+	var saved []string
+	for _, req := range requests {
+		if req.name == "cpu" {
+			fmt.Printf("⏳ Collecting CPU profile (%s)...\n", strings.TrimPrefix(req.path, "/debug/pprof/profile?"))
+		}
+		path := filepath.Join(outDir, req.name+"-"+stamp+req.ext)
+		if err := fetchProfile(baseURL+req.path, cfg.Security.AdminPassword, req.timeout, path); err != nil {
+			fmt.Printf("❌ %s: %v\n", req.name, err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Saved %s\n", path)
+		saved = append(saved, path)
+	}
+
+	fmt.Println()
+	fmt.Println("Analyze with:")
+	for _, path := range saved {
+		if strings.HasSuffix(path, ".pb.gz") {
+			fmt.Printf("  go tool pprof -http=:8081 %s\n", path)
+		}
+	}
+	fmt.Println("Compare two heap profiles to see what grew:")
+	fmt.Println("  go tool pprof -base <older-heap.pb.gz> <newer-heap.pb.gz>")
+}
+
+// defaultGatewayURL is the local address of the configured server
+func defaultGatewayURL(cfg *config.Config) string {
+	host := cfg.Server.Address
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return fmt.Sprintf("http://%s:%d", host, cfg.Server.Port)
+}
+
+func fetchProfile(url, password string, timeout time.Duration, path string) error {
+	if timeout == 0 {
+		timeout = 60 * time.Second
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("admin", password)
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return fmt.Errorf("is the server running? %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return fmt.Errorf("the server rejected the admin password")
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
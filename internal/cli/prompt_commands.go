@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/app"
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/persona"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/vector"
+	"go.uber.org/zap"
+)
+
+// HandlePromptCommand shows how the system prompt is put together
+func HandlePromptCommand(args []string) {
+	if len(args) == 0 || args[0] != "inspect" {
+		PrintPromptHelp()
+		return
+	}
+	args = args[1:]
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("Error initializing store: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	logCfg := zap.NewDevelopmentConfig()
+	logCfg.Level = zap.NewAtomicLevelAt(zap.ErrorLevel) // keep skill setup notes out of the output
+	logger, _ := logCfg.Build()
+	defer logger.Sync()
+
+	pm, err := persona.NewPersonaManager(cfg.Storage.DataDir, logger)
+	if err != nil {
+		fmt.Printf("❌ Failed to load persona: %v\n", err)
+		os.Exit(1)
+	}
+
+	provider, err := cfg.DefaultProvider()
+	if err != nil {
+		fmt.Printf("Error getting LLM provider: %v\n", err)
+		os.Exit(1)
+	}
+	llmClient := llm.NewClient(provider)
+
+	skillsRegistry := skills.NewRegistry(st)
+	app.RegisterSkills(cfg, st, skillsRegistry, logger, llmClient)
+
+	agentInstance := agent.New(llmClient, nil, st, logger, pm)
+	agentInstance.SetSkillsRegistry(skillsRegistry)
+
+	var searcher *vector.Searcher
+	if cfg.Vector.Enabled {
+		if searcher, err = vector.NewSearcher(&cfg.Vector, st, logger); err != nil {
+			logger.Warn("Failed to create vector searcher", zap.Error(err))
+		}
+	}
+	contextManager := agent.NewContextManager(st, searcher, llmClient, logger)
+	contextManager.SetProvider(provider)
+	agentInstance.SetContextManager(contextManager)
+
+	ctx := context.Background()
+	if name := flagValue(args, "--persona"); name != "" {
+		ctx = persona.WithPersona(ctx, name)
+	}
+
+	inspection, err := agentInstance.InspectPrompt(ctx, flagValue(args, "--conversation", "-c"), flagValue(args, "--message", "-m"))
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Layers:")
+	for _, layer := range inspection.Layers {
+		fmt.Printf("  %-10s %6d tokens  (message %d)\n", layer.Name, layer.Tokens, layer.Message+1)
+	}
+	messages := "1 system message"
+	if n := len(inspection.Messages); n > 1 {
+		messages = fmt.Sprintf("%d system messages", n)
+	}
+	fmt.Printf("  %-10s %6d tokens  (%s, with per-message overhead)\n", "total", inspection.Tokens, messages)
+	if hasFlag(args, "--layers") {
+		return
+	}
+
+	for i, msg := range inspection.Messages {
+		fmt.Println()
+		fmt.Printf("%s system message %d %s\n", strings.Repeat("─", 3), i+1, strings.Repeat("─", 40))
+		fmt.Println(msg.Content)
+	}
+}
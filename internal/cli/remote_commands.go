@@ -0,0 +1,402 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/app"
+	"github.com/gmsas95/myrai-cli/internal/remote"
+	"github.com/gmsas95/myrai-cli/internal/skills/documents"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+// remoteRequestTimeout bounds the requests that don't wait for the model
+const remoteRequestTimeout = 30 * time.Second
+
+// RemoteClient is the gateway set with --remote or MYRAI_REMOTE_URL, or
+// nil when the CLI runs its own agent. It exits if the settings are wrong.
+func RemoteClient() *remote.Client {
+	url := os.Getenv(RemoteURLEnv)
+	if url == "" {
+		return nil
+	}
+	client, err := remote.NewClient(url, os.Getenv(RemoteTokenEnv))
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		fmt.Println("Pass --remote <url> --token <token>, or set MYRAI_REMOTE_URL and MYRAI_REMOTE_TOKEN.")
+		os.Exit(1)
+	}
+	return client
+}
+
+// RunRemoteOneShot answers req with the gateway. Only text can be sent, so
+// documents and images are refused rather than pointing the server at
+// paths it can't read.
+func RunRemoteOneShot(client *remote.Client, req app.OneShotRequest) {
+	for _, file := range req.Files {
+		if documents.ToolFor(file) != "" {
+			fmt.Fprintf(os.Stderr, "❌ Can't attach %s with --remote; only text files are sent to the gateway\n", file)
+			os.Exit(1)
+		}
+	}
+	prompt, _, err := app.BuildOneShotPrompt(req, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "🤖 Myrai at %s is thinking...\n\n", client.URL())
+	result, err := client.Chat(context.Background(), "", prompt, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if req.Output != "" {
+		if err := os.WriteFile(req.Output, []byte(result.Content), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to write %s: %v\n", req.Output, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "📝 Answer written to %s\n", req.Output)
+	} else {
+		fmt.Println(result.Content)
+	}
+	fmt.Fprintf(os.Stderr, "\n⏱️  Response time: %v | Tokens: %d\n", time.Duration(result.ResponseTime)*time.Millisecond, result.TokensUsed)
+}
+
+// RunRemoteInteractive chats on the terminal with the gateway, continuing
+// the conversation resume names if it's given
+func RunRemoteInteractive(client *remote.Client, resume string) {
+	session := &remoteSession{client: client}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteRequestTimeout)
+	err := client.Ping(ctx)
+	cancel()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("🤖 Myrai - Interactive Mode (gateway at %s)\n", client.URL())
+	fmt.Println("Type 'exit' or 'quit' to exit, 'help' for commands")
+	fmt.Println()
+	if resume != "" {
+		session.command("/resume " + resume)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print("👤 You: ")
+		input, err := reader.ReadString('\n')
+		if err != nil && input == "" {
+			fmt.Println()
+			return
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+
+		switch strings.ToLower(input) {
+		case "exit", "quit", "q":
+			fmt.Println("👋 Goodbye!")
+			return
+		case "help", "h":
+			app.PrintInteractiveHelp()
+			continue
+		case "new", "n":
+			session.command("/new")
+			continue
+		case "clear", "cls":
+			fmt.Print("\033[H\033[2J")
+			continue
+		}
+		if strings.HasPrefix(input, "/") {
+			session.command(input)
+			continue
+		}
+
+		fmt.Println()
+		fmt.Print("🤖 Myrai: ")
+		start := time.Now()
+		result, err := client.Chat(context.Background(), session.conversationID, input, func(chunk string) {
+			fmt.Print(chunk)
+		})
+		if err != nil {
+			fmt.Printf("\n❌ Error: %v\n", err)
+			continue
+		}
+		session.conversationID = result.ConversationID
+
+		fmt.Println()
+		fmt.Printf("\n⏱️  Response time: %v | Tokens: %d\n", time.Since(start), result.TokensUsed)
+		fmt.Println()
+	}
+}
+
+// remoteSession is the conversation an interactive session with a gateway
+// is in
+type remoteSession struct {
+	client         *remote.Client
+	conversationID string
+}
+
+// command runs a slash command; aliases and the other local slash commands
+// aren't available against a gateway
+func (s *remoteSession) command(input string) {
+	parts := strings.Fields(input)
+	args := strings.TrimSpace(strings.TrimPrefix(input, parts[0]))
+	ctx, cancel := context.WithTimeout(context.Background(), remoteRequestTimeout)
+	defer cancel()
+
+	switch strings.ToLower(parts[0]) {
+	case "/new":
+		s.conversationID = ""
+		fmt.Println("🆕 New conversation started")
+
+	case "/history", "/conversations":
+		convs, err := s.client.Conversations(ctx, app.ConversationListLimit)
+		if err != nil {
+			fmt.Printf("❌ Failed to list conversations: %v\n", err)
+			return
+		}
+		fmt.Println()
+		app.PrintConversationList(nil, convs, s.conversationID)
+		fmt.Println()
+		fmt.Println("Use /resume <number> to continue one.")
+
+	case "/resume":
+		if args == "" {
+			fmt.Println("Usage: /resume <number|id>  (see /history)")
+			return
+		}
+		conv, err := resolveRemoteConversation(ctx, s.client, args)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		s.conversationID = conv.ID
+		fmt.Printf("✅ Resumed: %s (%d messages)\n\n", app.ConversationTitle(nil, conv), conv.MessageCount)
+		if msgs, err := s.client.Messages(ctx, conv.ID); err == nil {
+			app.PrintMessages(msgs, 4)
+		}
+
+	case "/title":
+		if s.conversationID == "" {
+			fmt.Println("❌ Nothing to title yet; send a message first")
+			return
+		}
+		if args == "" {
+			fmt.Println("Usage: /title <new title>")
+			return
+		}
+		if err := s.client.RenameConversation(ctx, s.conversationID, args); err != nil {
+			fmt.Printf("❌ Failed to rename conversation: %v\n", err)
+			return
+		}
+		fmt.Printf("✏️  Conversation renamed to %q\n", args)
+
+	case "/model":
+		if s.conversationID == "" {
+			fmt.Println("❌ No conversation yet; send a message first")
+			return
+		}
+		route, err := agent.ParseRoute(args)
+		if err != nil || args == "" {
+			fmt.Println("Usage: /model fast|smart|auto")
+			return
+		}
+		if err := s.client.SetConversationRoute(ctx, s.conversationID, route); err != nil {
+			fmt.Printf("❌ Failed to set the model: %v\n", err)
+			return
+		}
+		if route == agent.RouteAuto {
+			fmt.Println("🔀 Each turn is routed to the fast or smart model again")
+		} else {
+			fmt.Printf("🔀 This conversation now uses the %s model\n", route)
+		}
+
+	case "/skills":
+		printRemoteSkills(ctx, s.client)
+
+	case "/help":
+		fmt.Println()
+		fmt.Println("Slash Commands:")
+		fmt.Println("  /skills     - List the gateway's skills")
+		fmt.Println("  /history    - List recent conversations")
+		fmt.Println("  /resume <n> - Continue a conversation from /history")
+		fmt.Println("  /title <t>  - Rename this conversation")
+		fmt.Println("  /model <m>  - Use the fast or smart model, or auto to route each turn")
+		fmt.Println("  /new        - Start a new conversation")
+		fmt.Println("  /help       - Show this help")
+		fmt.Println()
+
+	default:
+		fmt.Printf("❓ %s isn't available with a remote gateway\n", parts[0])
+		fmt.Println("Type /help for available slash commands")
+	}
+}
+
+// resolveRemoteConversation finds a conversation by its number in the
+// gateway's recent list, or by its ID or the start of it
+func resolveRemoteConversation(ctx context.Context, client *remote.Client, ref string) (*store.Conversation, error) {
+	if n, err := strconv.Atoi(ref); err == nil {
+		convs, err := client.Conversations(ctx, app.ConversationListLimit)
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 || n > len(convs) {
+			return nil, fmt.Errorf("no conversation %d; there are %d recent ones", n, len(convs))
+		}
+		return &convs[n-1], nil
+	}
+	return client.Conversation(ctx, ref)
+}
+
+// handleRemoteConversations is 'myrai conversations' against a gateway
+func handleRemoteConversations(client *remote.Client, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteRequestTimeout)
+	defer cancel()
+
+	resolve := func(refs []string, usage string) *store.Conversation {
+		if len(refs) != 1 {
+			fmt.Printf("Usage: myrai conversations %s\n", usage)
+			os.Exit(1)
+		}
+		conv, err := resolveRemoteConversation(ctx, client, refs[0])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		return conv
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		convs, err := client.Conversations(ctx, app.ConversationListLimit)
+		if err != nil {
+			fmt.Printf("❌ Failed to list conversations: %v\n", err)
+			os.Exit(1)
+		}
+		app.PrintConversationList(nil, convs, "")
+		if len(convs) > 0 {
+			fmt.Println()
+			fmt.Println("Continue one with 'myrai conversations resume <number>'.")
+		}
+
+	case "show":
+		limit := 20
+		var rest []string
+		for i := 1; i < len(args); i++ {
+			if (args[i] == "--limit" || args[i] == "-n") && i+1 < len(args) {
+				n, err := strconv.Atoi(args[i+1])
+				if err != nil || n < 0 {
+					fmt.Printf("❌ Invalid limit: %s\n", args[i+1])
+					os.Exit(1)
+				}
+				limit = n
+				i++
+				continue
+			}
+			rest = append(rest, args[i])
+		}
+		conv := resolve(rest, "show <number|id> [--limit n]")
+		msgs, err := client.Messages(ctx, conv.ID)
+		if err != nil {
+			fmt.Printf("❌ Failed to read messages: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("💬 %s\n", app.ConversationTitle(nil, conv))
+		fmt.Printf("   %s · %d messages · updated %s\n\n", conv.ID, conv.MessageCount, conv.UpdatedAt.Format("Jan 2, 3:04 PM"))
+		app.PrintMessages(msgs, limit)
+
+	case "resume":
+		conv := resolve(args[1:], "resume <number|id>")
+		RunRemoteInteractive(client, conv.ID)
+
+	case "rename", "title":
+		if len(args) < 3 {
+			fmt.Println("Usage: myrai conversations rename <number|id> <title>")
+			os.Exit(1)
+		}
+		conv := resolve(args[1:2], "rename <number|id> <title>")
+		title := strings.Join(args[2:], " ")
+		if err := client.RenameConversation(ctx, conv.ID, title); err != nil {
+			fmt.Printf("❌ Failed to rename conversation: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✏️  Renamed to %q\n", title)
+
+	case "delete", "rm":
+		if hasFlag(args, "--forget") {
+			fmt.Println("❌ --forget isn't available with --remote; run it on the gateway's machine")
+			os.Exit(1)
+		}
+		var refs []string
+		for _, arg := range args[1:] {
+			if !strings.HasPrefix(arg, "-") {
+				refs = append(refs, arg)
+			}
+		}
+		conv := resolve(refs, "delete <number|id> [-y]")
+		title := app.ConversationTitle(nil, conv)
+		if !hasFlag(args, "--yes", "-y") && !confirmPrivacy(fmt.Sprintf("This deletes %q on %s.", title, client.URL())) {
+			return
+		}
+		if err := client.DeleteConversation(ctx, conv.ID); err != nil {
+			fmt.Printf("❌ Failed to delete conversation: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🗑️  Deleted %q\n", title)
+
+	default:
+		fmt.Printf("Unknown conversations command: %s\n\n", args[0])
+		PrintConversationsHelp()
+		os.Exit(1)
+	}
+}
+
+func printRemoteSkills(ctx context.Context, client *remote.Client) {
+	skills, err := client.Skills(ctx)
+	if err != nil {
+		fmt.Printf("❌ Failed to list skills: %v\n", err)
+		return
+	}
+	fmt.Printf("Skills on %s:\n", client.URL())
+	fmt.Println("=================")
+	for _, skill := range skills {
+		enabled := "✅"
+		if !skill.Enabled {
+			enabled = "❌"
+		}
+		fmt.Printf("  %s %s %s (%d tools)\n", enabled, skill.Name, skill.Version, skill.Tools)
+		if skill.Description != "" {
+			fmt.Printf("     %s\n", skill.Description)
+		}
+	}
+	fmt.Printf("\nTotal: %d skills\n", len(skills))
+}
+
+// remoteAgent answers batch items with the gateway
+type remoteAgent struct {
+	client *remote.Client
+}
+
+func (r remoteAgent) Chat(ctx context.Context, req agent.ChatRequest) (*agent.ChatResponse, error) {
+	result, err := r.client.Chat(ctx, req.ConversationID, req.Message, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &agent.ChatResponse{
+		Content:        result.Content,
+		ConversationID: result.ConversationID,
+		TokensUsed:     result.TokensUsed,
+		ResponseTime:   time.Duration(result.ResponseTime) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/onboarding"
+	"github.com/gmsas95/myrai-cli/internal/secrets"
+)
+
+// HandleSecretCommand manages API keys and tokens in the secrets backend
+// configured under secrets: in the config file
+func HandleSecretCommand(args []string) {
+	if len(args) == 0 {
+		PrintSecretHelp()
+		return
+	}
+
+	switch args[0] {
+	case "set":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai secret set <NAME> [value]")
+			fmt.Println("Without a value it is prompted for, or read from stdin")
+			os.Exit(1)
+		}
+		key := args[1]
+		if !secrets.ValidKey(key) {
+			fmt.Printf("❌ Invalid secret name: %s (use letters, digits and _)\n", key)
+			os.Exit(1)
+		}
+		value := ""
+		if len(args) > 2 {
+			value = strings.Join(args[2:], " ")
+		} else {
+			var err error
+			value, err = readSecretValue(key)
+			if err != nil {
+				fmt.Printf("Error reading value: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if value == "" {
+			fmt.Println("❌ Empty value, nothing stored")
+			os.Exit(1)
+		}
+		s := openSecretStore()
+		if err := s.Set(key, value); err != nil {
+			fmt.Printf("Error storing secret: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Stored %s in %s\n", key, s.Name())
+
+	case "get":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai secret get <NAME>")
+			os.Exit(1)
+		}
+		value, err := openSecretStore().Get(args[1])
+		if errors.Is(err, secrets.ErrNotFound) {
+			fmt.Printf("❌ No secret named %s\n", args[1])
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error reading secret: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(value)
+
+	case "list", "ls":
+		s := openSecretStore()
+		keys, err := s.List()
+		if err != nil {
+			fmt.Printf("Error listing secrets: %v\n", err)
+			os.Exit(1)
+		}
+		if len(keys) == 0 {
+			fmt.Printf("No secrets in %s\n", s.Name())
+			return
+		}
+		fmt.Printf("Secrets in %s:\n", s.Name())
+		for _, key := range keys {
+			fmt.Printf("  %s\n", key)
+		}
+
+	case "delete", "rm":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai secret delete <NAME>")
+			os.Exit(1)
+		}
+		err := openSecretStore().Delete(args[1])
+		if errors.Is(err, secrets.ErrNotFound) {
+			fmt.Printf("❌ No secret named %s\n", args[1])
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error deleting secret: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Deleted %s\n", args[1])
+
+	case "migrate":
+		keep := false
+		path := ""
+		for _, arg := range args[1:] {
+			if arg == "--keep" {
+				keep = true
+			} else {
+				path = arg
+			}
+		}
+		migrateSecrets(path, keep)
+
+	default:
+		PrintSecretHelp()
+	}
+}
+
+// migrateSecrets moves the secrets in a .env file, by default the first one
+// myrai reads, into the configured backend
+func migrateSecrets(path string, keep bool) {
+	if path == "" {
+		for _, p := range config.EnvFilePaths() {
+			if _, err := os.Stat(p); err == nil {
+				path = p
+				break
+			}
+		}
+		if path == "" {
+			fmt.Println("No .env file found to migrate")
+			return
+		}
+	}
+
+	s := openSecretStore()
+	if s.Name() == secrets.BackendEnv {
+		fmt.Println("❌ The env backend keeps secrets in .env files already")
+		fmt.Println("Set secrets.backend to keychain or age in the config first")
+		os.Exit(1)
+	}
+
+	migrated, err := secrets.MigrateEnvFile(s, path, keep)
+	for _, key := range migrated {
+		fmt.Printf("  %s\n", key)
+	}
+	if err != nil {
+		fmt.Printf("Error migrating %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	switch {
+	case len(migrated) == 0:
+		fmt.Printf("No secrets found in %s\n", path)
+	case keep:
+		fmt.Printf("✅ Copied %d secret(s) from %s to %s\n", len(migrated), path, s.Name())
+	default:
+		fmt.Printf("✅ Moved %d secret(s) from %s to %s\n", len(migrated), path, s.Name())
+	}
+}
+
+// openSecretStore opens the configured backend, asking for the passphrase
+// of an age file if it's needed and not in the environment
+func openSecretStore() secrets.Store {
+	opts := config.SecretsOptions(onboarding.GetConfigPath())
+	if opts.Backend == secrets.BackendAge && opts.Identity == "" && os.Getenv(secrets.PassphraseEnv) == "" &&
+		term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "Passphrase for %s: ", opts.File)
+		passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			fmt.Printf("Error reading passphrase: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Passphrase = string(passphrase)
+	}
+
+	s, err := secrets.Open(opts)
+	if err != nil {
+		fmt.Printf("Error opening secrets: %v\n", err)
+		os.Exit(1)
+	}
+	return s
+}
+
+// readSecretValue prompts for a value without echoing it, or reads the first
+// line of stdin when it isn't a terminal
+func readSecretValue(key string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", key)
+		value, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return strings.TrimSpace(string(value)), err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/gmsas95/myrai-cli/internal/app"
 	"github.com/gmsas95/myrai-cli/internal/config"
 	"github.com/gmsas95/myrai-cli/internal/mcp"
 	"github.com/gmsas95/myrai-cli/internal/skills"
@@ -44,21 +45,8 @@ func HandleEnhancedSkillsCommand(args []string) {
 	skillsDir := getSkillsDir(cfg)
 
 	switch args[0] {
-	case "install":
-		if len(args) < 2 {
-			fmt.Println("Usage: myrai skills install <github.com/user/repo>")
-			os.Exit(1)
-		}
-		repo := args[1]
-		installFromGitHub(registry, skillsDir, repo, logger)
-
-	case "update":
-		if len(args) < 2 {
-			fmt.Println("Usage: myrai skills update <skill-name>")
-			os.Exit(1)
-		}
-		skillName := args[1]
-		updateSkill(registry, skillsDir, skillName, logger)
+	case "install", "update":
+		handleSkillPackages(cfg, args, logger)
 
 	case "search":
 		if len(args) < 2 {
@@ -93,12 +81,7 @@ func HandleEnhancedSkillsCommand(args []string) {
 		disableSkill(registry, skillName)
 
 	case "uninstall", "remove":
-		if len(args) < 2 {
-			fmt.Println("Usage: myrai skills uninstall <skill-name>")
-			os.Exit(1)
-		}
-		skillName := args[1]
-		uninstallSkill(registry, skillsDir, skillName, logger)
+		handleSkillPackages(cfg, args, logger)
 
 	case "validate":
 		if len(args) < 2 {
@@ -212,39 +195,99 @@ func HandleMCPCommand(args []string) {
 
 // ==================== Skills Commands ====================
 
-func installFromGitHub(registry *skills.EnhancedRegistry, skillsDir, repo string, logger *zap.Logger) {
-	loader := skills.NewSkillLoader(registry, skillsDir)
-	installer := skills.NewGitHubInstaller(loader, skillsDir, logger)
+// handleSkillPackages installs, updates and removes skill packages
+func handleSkillPackages(cfg *config.Config, args []string, logger *zap.Logger) {
+	installer := skills.NewPackageInstaller(getSkillsDir(cfg), cfg.Skills.Packages.Registry, cfg.Skills.Packages.TrustedKeys, logger)
+	ctx := context.Background()
 
-	fmt.Printf("📦 Installing skill from %s...\n", repo)
+	switch args[0] {
+	case "install":
+		var source, digest string
+		for i := 1; i < len(args); i++ {
+			if args[i] == "--sha256" && i+1 < len(args) {
+				digest = args[i+1]
+				i++
+			} else if source == "" {
+				source = args[i]
+			}
+		}
+		if source == "" {
+			fmt.Println("Usage: myrai skills install <name[@version]|git-url[@ref]> [--sha256 <digest>]")
+			os.Exit(1)
+		}
 
-	skill, err := installer.InstallFromGitHub(repo)
-	if err != nil {
-		fmt.Printf("❌ Failed to install skill: %v\n", err)
-		os.Exit(1)
-	}
+		fmt.Printf("📦 Installing skill from %s...\n", source)
+		record, err := installer.Install(ctx, source, digest)
+		if err != nil {
+			fmt.Printf("❌ Failed to install skill: %v\n", err)
+			os.Exit(1)
+		}
+		printSkillPackage(getSkillsDir(cfg), record, "installed")
 
-	fmt.Printf("✅ Skill '%s' installed successfully!\n", skill.Manifest.Name)
-	fmt.Printf("   Version: %s\n", skill.Manifest.Version)
-	fmt.Printf("   Tools: %d\n", len(skill.Tools))
-	if len(skill.Manifest.Tags) > 0 {
-		fmt.Printf("   Tags: %s\n", strings.Join(skill.Manifest.Tags, ", "))
+	case "update":
+		names := args[1:]
+		if len(names) == 0 || names[0] == "--all" {
+			records, err := installer.Installed()
+			if err != nil {
+				fmt.Printf("❌ Failed to list installed skills: %v\n", err)
+				os.Exit(1)
+			}
+			names = nil
+			for _, record := range records {
+				names = append(names, record.Name)
+			}
+			if len(names) == 0 {
+				fmt.Println("No skill packages installed.")
+				return
+			}
+		}
+
+		failed := false
+		for _, name := range names {
+			fmt.Printf("🔄 Updating skill '%s'...\n", name)
+			record, err := installer.Update(ctx, name)
+			if err != nil {
+				fmt.Printf("❌ Failed to update skill '%s': %v\n", name, err)
+				failed = true
+				continue
+			}
+			printSkillPackage(getSkillsDir(cfg), record, "updated")
+		}
+		if failed {
+			os.Exit(1)
+		}
+
+	case "remove", "uninstall":
+		if len(args) < 2 {
+			fmt.Println("Usage: myrai skills remove <skill-name>")
+			os.Exit(1)
+		}
+		if err := installer.Remove(args[1]); err != nil {
+			fmt.Printf("❌ Failed to remove skill: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Skill '%s' removed\n", args[1])
 	}
 }
 
-func updateSkill(registry *skills.EnhancedRegistry, skillsDir, skillName string, logger *zap.Logger) {
-	loader := skills.NewSkillLoader(registry, skillsDir)
-	installer := skills.NewGitHubInstaller(loader, skillsDir, logger)
-
-	fmt.Printf("🔄 Updating skill '%s'...\n", skillName)
+// printSkillPackage reports an installed package and the tools it loads with
+func printSkillPackage(skillsDir string, record *skills.PackageRecord, verb string) {
+	fmt.Printf("✅ Skill '%s' %s!\n", record.Name, verb)
+	fmt.Printf("   Version: %s\n", record.Version)
+	fmt.Printf("   Checksum: sha256:%s\n", record.Digest)
+	if record.Signed {
+		fmt.Println("   Signature: verified")
+	}
 
-	skill, err := installer.UpdateSkill(skillName)
+	skill, err := skills.NewSkillLoader(nil, skillsDir).LoadSkill(filepath.Join(skillsDir, record.Name, "SKILL.md"))
 	if err != nil {
-		fmt.Printf("❌ Failed to update skill: %v\n", err)
-		os.Exit(1)
+		fmt.Printf("⚠️  Installed, but the skill failed to load: %v\n", err)
+		return
+	}
+	fmt.Printf("   Tools: %d\n", len(skill.Tools))
+	if len(skill.Manifest.Tags) > 0 {
+		fmt.Printf("   Tags: %s\n", strings.Join(skill.Manifest.Tags, ", "))
 	}
-
-	fmt.Printf("✅ Skill '%s' updated to version %s!\n", skill.Manifest.Name, skill.Manifest.Version)
 }
 
 func searchSkills(registry *skills.EnhancedRegistry, query string, logger *zap.Logger) {
@@ -331,31 +374,6 @@ func disableSkill(registry *skills.EnhancedRegistry, skillName string) {
 	fmt.Printf("✅ Skill '%s' disabled\n", skillName)
 }
 
-func uninstallSkill(registry *skills.EnhancedRegistry, skillsDir, skillName string, logger *zap.Logger) {
-	skill, ok := registry.GetRuntimeSkill(skillName)
-	if !ok {
-		fmt.Printf("❌ Skill '%s' not found\n", skillName)
-		os.Exit(1)
-	}
-
-	if skill.Source == skills.SourceGitHub {
-		loader := skills.NewSkillLoader(registry, skillsDir)
-		installer := skills.NewGitHubInstaller(loader, skillsDir, logger)
-		if err := installer.UninstallSkill(skillName); err != nil {
-			fmt.Printf("❌ Failed to uninstall skill: %v\n", err)
-			os.Exit(1)
-		}
-	} else {
-		// Just unregister for local/builtin skills
-		if err := registry.UnregisterSkill(skillName); err != nil {
-			fmt.Printf("❌ Failed to unregister skill: %v\n", err)
-			os.Exit(1)
-		}
-	}
-
-	fmt.Printf("✅ Skill '%s' uninstalled\n", skillName)
-}
-
 func validateSkill(skillPath string) {
 	content, err := os.ReadFile(skillPath)
 	if err != nil {
@@ -408,6 +426,8 @@ func listSkills(registry *skills.EnhancedRegistry) {
 			source = "(local)"
 		case "builtin":
 			source = "(builtin)"
+		case "package":
+			source = "(package)"
 		}
 
 		fmt.Printf("\n%s %s %s\n", status, skill.Manifest.Name, source)
@@ -644,8 +664,7 @@ func discoverAndAddServer(adapter *mcp.Adapter, configPath, serverName string) {
 // ==================== Helper Functions ====================
 
 func getSkillsDir(cfg *config.Config) string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".myrai", "skills")
+	return app.SkillPackagesDir(cfg)
 }
 
 func getMCPConfigPath(cfg *config.Config) string {
@@ -661,20 +680,22 @@ func PrintEnhancedSkillsHelp() {
 	fmt.Println("Usage: myrai skills <command> [args]")
 	fmt.Println()
 	fmt.Println("Commands:")
-	fmt.Println("  install <repo>       Install skill from GitHub (e.g., github.com/user/repo@v1.0.0)")
-	fmt.Println("  update <name>        Update skill to latest version")
+	fmt.Println("  install <source>     Install a skill package by registry name or git URL")
+	fmt.Println("                       (--sha256 <digest> to pin its checksum)")
+	fmt.Println("  update [name...]     Update installed packages to their latest version")
 	fmt.Println("  search <query>       Search for skills locally and on GitHub")
 	fmt.Println("  watch <path>         Watch directory for SKILL.md changes (hot-reload)")
 	fmt.Println("  enable <name>        Enable a skill")
 	fmt.Println("  disable <name>       Disable a skill")
-	fmt.Println("  uninstall <name>     Remove a skill")
+	fmt.Println("  remove <name>        Remove an installed skill package")
 	fmt.Println("  validate <path>       Validate a SKILL.md file")
 	fmt.Println("  list                 List all installed skills")
 	fmt.Println("  stats                Show skill registry statistics")
 	fmt.Println()
 	fmt.Println("Examples:")
-	fmt.Println("  myrai skills install github.com/myrai-agents/docker-helper")
+	fmt.Println("  myrai skills install docker-helper")
 	fmt.Println("  myrai skills install github.com/myrai-agents/docker-helper@v1.2.0")
+	fmt.Println("  myrai skills install https://git.example.com/me/skill.git --sha256 <digest>")
 	fmt.Println("  myrai skills search docker")
 	fmt.Println("  myrai skills watch ./my-custom-skills/")
 }
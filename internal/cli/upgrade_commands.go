@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+// HandleUpgradeCommand applies pending data migrations after installing a new
+// release, and manages the backups taken before each migration
+func HandleUpgradeCommand(args []string) {
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	action := ""
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "":
+		runUpgrade(cfg)
+
+	case "--rollback", "rollback":
+		id := ""
+		if len(args) > 1 {
+			id = args[1]
+		}
+		runRollback(cfg, id, hasFlag(args, "--yes", "-y"))
+
+	case "--backup", "backup":
+		backup, err := store.CreateBackup(&cfg.Storage, store.BackupLabelManual)
+		if err != nil {
+			fmt.Printf("❌ Backup failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Backup %s saved to %s\n", backup.ID, backup.Path)
+
+	case "--list", "list":
+		listBackups(cfg)
+
+	case "--encrypt", "encrypt":
+		runEncrypt(cfg, true)
+
+	case "--decrypt", "decrypt":
+		runEncrypt(cfg, false)
+
+	case "--help", "-h", "help":
+		PrintUpgradeHelp()
+
+	default:
+		fmt.Printf("Unknown upgrade option: %s\n\n", action)
+		PrintUpgradeHelp()
+		os.Exit(1)
+	}
+}
+
+func runUpgrade(cfg *config.Config) {
+	// Opening the store backs up the data directory and applies any
+	// pending migrations
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("❌ Upgrade failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	report := st.MigrationReport()
+	version, _ := store.SchemaVersion(st.DB())
+
+	if len(report.Applied) == 0 {
+		fmt.Printf("✅ Data is up to date (schema version %d)\n", version)
+		return
+	}
+
+	if report.Backup != nil {
+		fmt.Printf("💾 Backup saved to %s\n", report.Backup.Path)
+	}
+	for _, m := range report.Applied {
+		fmt.Printf("   ✓ %03d %s\n", m.Version, m.Name)
+	}
+	fmt.Printf("✅ Upgraded to schema version %d\n", version)
+	if report.Backup != nil {
+		fmt.Println("   If something is wrong, restore the previous data with: myrai upgrade --rollback")
+	}
+}
+
+// runEncrypt encrypts the sensitive fields written before storage encryption
+// was turned on, or decrypts them all before turning it off
+func runEncrypt(cfg *config.Config, encrypt bool) {
+	if !cfg.Storage.Encryption.Enabled {
+		fmt.Println("❌ Storage encryption is off. Set storage.encryption.enabled: true in the config first.")
+		os.Exit(1)
+	}
+
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	backup, err := store.CreateBackup(&cfg.Storage, store.BackupLabelManual)
+	if err != nil {
+		fmt.Printf("❌ Backup failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("💾 Backup saved to %s\n", backup.Path)
+
+	n, err := st.EncryptExisting(encrypt)
+	if err != nil {
+		fmt.Printf("❌ Failed after %d value(s): %v\n", n, err)
+		fmt.Println("   Run the command again to finish, or restore with: myrai upgrade --rollback")
+		os.Exit(1)
+	}
+	if encrypt {
+		fmt.Printf("✅ Encrypted %d value(s)\n", n)
+		fmt.Println("   Backups taken before now are not encrypted. Delete them from")
+		fmt.Printf("   %s once you no longer need them.\n", filepath.Join(cfg.Storage.DataDir, "backups"))
+		return
+	}
+	fmt.Printf("✅ Decrypted %d value(s)\n", n)
+	fmt.Println("   Set storage.encryption.enabled: false before starting Myrai again.")
+}
+
+func runRollback(cfg *config.Config, id string, yes bool) {
+	if strings.HasPrefix(id, "-") {
+		id = ""
+	}
+
+	backups, err := store.ListBackups(&cfg.Storage)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if len(backups) == 0 {
+		fmt.Println("No backups found. Nothing to roll back to.")
+		return
+	}
+
+	if !yes {
+		target := id
+		if target == "" {
+			target = "the most recent backup"
+		}
+		fmt.Printf("This replaces the database and workspace with %s.\n", target)
+		fmt.Println("Stop any running Myrai server first. The current data is backed up before restoring.")
+		fmt.Print("Continue? (y/N): ")
+		reader := bufio.NewReader(os.Stdin)
+		answer, _ := reader.ReadString('\n')
+		answer = strings.TrimSpace(strings.ToLower(answer))
+		if answer != "y" && answer != "yes" {
+			fmt.Println("Rollback cancelled")
+			return
+		}
+	}
+
+	backup, err := store.RestoreBackup(&cfg.Storage, id)
+	if err != nil {
+		fmt.Printf("❌ Rollback failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Restored backup %s (schema version %d)\n", backup.ID, backup.SchemaVersion)
+	fmt.Println("   Reinstall the matching Myrai release if the new one keeps failing.")
+}
+
+func listBackups(cfg *config.Config) {
+	backups, err := store.ListBackups(&cfg.Storage)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if len(backups) == 0 {
+		fmt.Println("No backups yet. Create one with: myrai upgrade --backup")
+		return
+	}
+
+	fmt.Println("Backups (newest first):")
+	for _, b := range backups {
+		fmt.Printf("  %-34s schema v%-3d %s\n", b.ID, b.SchemaVersion, b.CreatedAt.Format("2006-01-02 15:04"))
+	}
+}
+
+func hasFlag(args []string, names ...string) bool {
+	for _, a := range args {
+		for _, n := range names {
+			if a == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// flagValue returns the value given after any of names in args, or ""
+func flagValue(args []string, names ...string) string {
+	for i := 0; i+1 < len(args); i++ {
+		for _, n := range names {
+			if args[i] == n {
+				return args[i+1]
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/skills/kb"
+	"github.com/gmsas95/myrai-cli/internal/skills/notes"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/vector"
+	"go.uber.org/zap"
+)
+
+// HandleVectorCommand shows which embedding model built each vector index,
+// rebuilds indexes after the model changes and copies embeddings between
+// vector backends
+func HandleVectorCommand(args []string) {
+	if len(args) == 0 {
+		PrintVectorHelp()
+		return
+	}
+
+	cfg, err := config.Load("", "")
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	if !cfg.Vector.Enabled {
+		fmt.Println("❌ Vector search is disabled (set vector.enabled: true)")
+		os.Exit(1)
+	}
+
+	st, err := store.New(cfg)
+	if err != nil {
+		fmt.Printf("Error initializing store: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	searcher, err := openVectorIndexes(cfg, st)
+	if err != nil {
+		fmt.Printf("Error initializing vector search: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "status":
+		statuses, err := searcher.Indexes()
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Embeddings: %s/%s\n", searcher.ProviderName(), searcher.Model())
+		fmt.Printf("Backend:    %s\n\n", searcher.Vectors().Name())
+		for _, s := range statuses {
+			switch {
+			case !s.Recorded:
+				fmt.Printf("  %-10s not recorded yet\n", s.Name)
+			case s.Stale:
+				fmt.Printf("❌ %-10s built with %s/%s (%d dims), run 'myrai vector reindex %s'\n",
+					s.Name, s.Provider, s.Model, s.Dimension, s.Name)
+			default:
+				fmt.Printf("✅ %-10s %s/%s (%d dims), %s\n",
+					s.Name, s.Provider, s.Model, s.Dimension, s.UpdatedAt.Format("2006-01-02 15:04"))
+			}
+		}
+
+	case "reindex":
+		names := args[1:]
+		if len(names) == 0 {
+			names = searcher.IndexNames()
+		}
+		failed := false
+		for _, name := range names {
+			fmt.Printf("Reindexing %s with %s/%s...\n", name, searcher.ProviderName(), searcher.Model())
+			count, err := searcher.Reindex(name)
+			if err != nil {
+				fmt.Printf("❌ %s: %v\n", name, err)
+				failed = true
+				continue
+			}
+			fmt.Printf("✅ %s: %d embedded\n", name, count)
+		}
+		if failed {
+			os.Exit(1)
+		}
+
+	case "migrate":
+		if len(args) != 3 {
+			fmt.Println("Usage: myrai vector migrate <from> <to>   (local, qdrant or pgvector)")
+			os.Exit(1)
+		}
+		from, err := openVectorBackend(cfg, st, args[1])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer from.Close()
+		to, err := openVectorBackend(cfg, st, args[2])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer to.Close()
+		if from.Name() == to.Name() {
+			fmt.Println("❌ Choose two different backends")
+			os.Exit(1)
+		}
+
+		copied, err := vector.Migrate(context.Background(), from, to, vector.LocalCollections())
+		if err != nil {
+			fmt.Printf("❌ Copied %d embeddings before failing: %v\n", copied, err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Copied %d embeddings from %s to %s\n", copied, from.Name(), to.Name())
+		if cfg.Vector.Backend != to.Name() {
+			fmt.Printf("Set vector.backend: %s to search them there.\n", to.Name())
+		}
+
+	default:
+		fmt.Printf("Unknown vector command: %s\n", args[0])
+		PrintVectorHelp()
+		os.Exit(1)
+	}
+}
+
+// openVectorBackend opens the named backend with the configured settings
+func openVectorBackend(cfg *config.Config, st *store.Store, backend string) (vector.VectorStore, error) {
+	vectorCfg := cfg.Vector
+	vectorCfg.Backend = backend
+	return vector.NewVectorStore(&vectorCfg, st.DB())
+}
+
+// openVectorIndexes creates a searcher with the notes and knowledge base
+// indexes registered, as the server has them
+func openVectorIndexes(cfg *config.Config, st *store.Store) (*vector.Searcher, error) {
+	searcher, err := vector.NewSearcher(&cfg.Vector, st, zap.NewNop())
+	if err != nil {
+		return nil, err
+	}
+
+	notesSkill := notes.NewNotesSkill(cfg.Skills.Notes.VaultDir)
+	if err := notesSkill.EnableSemanticSearch(st.DB(), searcher); err != nil {
+		return nil, err
+	}
+	searcher.RegisterIndex("notes", notesSkill.Index().Reindex)
+
+	kbStore, err := kb.NewStore(st.DB(), kb.DefaultDir(cfg.Storage.DataDir), nil)
+	if err != nil {
+		return nil, err
+	}
+	kbStore.SetEmbedder(searcher)
+	kbStore.SetVectorStore(searcher.Vectors())
+	searcher.RegisterIndex(kb.Collection, kbStore.Reindex)
+
+	return searcher, nil
+}
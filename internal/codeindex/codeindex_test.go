@@ -0,0 +1,189 @@
+package codeindex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// wordEmbedder embeds text by which of a few words it contains
+type wordEmbedder struct{ calls atomic.Int32 }
+
+func (e *wordEmbedder) GenerateEmbedding(text string) ([]float32, error) {
+	e.calls.Add(1)
+	text = strings.ToLower(text)
+	var v []float32
+	for _, w := range []string{"invoice", "retry", "parse"} {
+		if strings.Contains(text, w) {
+			v = append(v, 1)
+		} else {
+			v = append(v, 0)
+		}
+	}
+	return v, nil
+}
+
+func writeFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func testProject(t *testing.T) string {
+	root := t.TempDir()
+	writeFile(t, root, ".gitignore", "generated/\n*.min.js\n!keep.min.js\n")
+	writeFile(t, root, "billing/invoice.go", `package billing
+
+// Invoice is a bill sent to a customer
+type Invoice struct {
+	Total int
+}
+
+// Send emails the invoice
+func (i *Invoice) Send(to string) error {
+	return nil
+}
+`)
+	writeFile(t, root, "net/client.py", `import time
+
+class Client:
+    def fetch(self, url):
+        return self.retry(url)
+
+    def retry(self, url, attempts=3):
+        time.sleep(1)
+
+def parse_config(path):
+    pass
+`)
+	writeFile(t, root, "generated/api.go", "package generated\n\nfunc Big() {}\n")
+	writeFile(t, root, "web/app.min.js", "function x(){}")
+	writeFile(t, root, "web/keep.min.js", "function kept() {}\n")
+	writeFile(t, root, "node_modules/lib/index.js", "function lib() {}\n")
+	writeFile(t, root, "README.md", "# Project\n")
+	return root
+}
+
+func TestIndexSymbolsAndIgnore(t *testing.T) {
+	root := testProject(t)
+	idx, err := Open(root, nil, nil, 0, nil)
+	require.NoError(t, err)
+	defer idx.Close()
+	require.NoError(t, idx.Sync())
+
+	var paths []string
+	for _, f := range idx.Files() {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"billing/invoice.go", "net/client.py", "web/keep.min.js"}, paths)
+
+	files := idx.Files()
+	goSymbols := files[0].Symbols
+	require.Len(t, goSymbols, 2)
+	assert.Equal(t, "type Invoice struct", goSymbols[0].Signature)
+	assert.Equal(t, "func (i *Invoice) Send(to string) error", goSymbols[1].Signature)
+	assert.Equal(t, 11, goSymbols[1].EndLine)
+
+	pySymbols := files[1].Symbols
+	require.Len(t, pySymbols, 4)
+	assert.Equal(t, "Client", pySymbols[0].Name)
+	assert.Equal(t, 8, pySymbols[0].EndLine, "the class ends before the next top-level function")
+
+	m := idx.Map(MapOptions{})
+	assert.Contains(t, m.Text, "billing/invoice.go\n  4: type Invoice struct\n")
+	assert.Contains(t, m.Text, "    4: def fetch(self, url):", "methods are nested under their class")
+	assert.Equal(t, 3, m.Total)
+
+	focused := idx.Map(MapOptions{Focus: "net"})
+	assert.Equal(t, 1, focused.Total)
+	small := idx.Map(MapOptions{MaxChars: 10})
+	assert.Equal(t, 1, small.Shown)
+	assert.Contains(t, small.Text, "2 more files")
+
+	// Words of names are matched without embeddings, camelCase or not
+	matches, err := idx.Search("how is the config parsed? parse config", 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "parse_config", matches[0].Symbol)
+
+	// Changes are picked up; removed files dropped
+	writeFile(t, root, "net/client.py", "def only():\n    pass\n")
+	os.Chtimes(filepath.Join(root, "net/client.py"), time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	require.NoError(t, os.Remove(filepath.Join(root, "billing/invoice.go")))
+	require.NoError(t, idx.Sync())
+	files = idx.Files()
+	require.Len(t, files, 2)
+	assert.Equal(t, "only", files[0].Symbols[0].Name)
+}
+
+func TestIndexEmbeddingsPersist(t *testing.T) {
+	root := testProject(t)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	// Every connection to :memory: is a database of its own
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	embedder := &wordEmbedder{}
+	idx, err := Open(root, db, embedder, 0, nil)
+	require.NoError(t, err)
+	require.NoError(t, idx.Sync())
+	n, err := idx.WaitEmbedded()
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	matches, err := idx.Search("where do we retry failed requests", 3)
+	require.NoError(t, err)
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "net/client.py", matches[0].Path)
+	assert.Equal(t, "Client", matches[0].Symbol)
+	idx.Close()
+
+	// A new index of the same project reuses what was stored
+	calls := embedder.calls.Load()
+	again, err := Open(root, db, embedder, 0, nil)
+	require.NoError(t, err)
+	defer again.Close()
+	require.NoError(t, again.Sync())
+	_, err = again.WaitEmbedded()
+	require.NoError(t, err)
+	assert.Equal(t, calls, embedder.calls.Load(), "unchanged files aren't embedded again")
+	assert.Equal(t, 3, again.Stats().Files)
+}
+
+func TestWatcher(t *testing.T) {
+	root := testProject(t)
+	m := NewManager(nil, nil, Options{Watch: true}, nil)
+	defer m.Close()
+	idx, err := m.Index(root)
+	require.NoError(t, err)
+	assert.Equal(t, 3, idx.Stats().Files)
+
+	writeFile(t, root, "pkg/new.go", "package pkg\n\nfunc Added() {}\n")
+	assert.Eventually(t, func() bool { return idx.Stats().Files == 4 }, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestIgnore(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, ".gitignore", "/build.go\ndocs/**/draft_*.py\n")
+	writeFile(t, root, "sub/.gitignore", "local.go\n")
+	ig := &Ignore{}
+	ig.Load(root, "")
+	ig.Load(root, "sub")
+
+	assert.True(t, ig.Ignored("build.go", false))
+	assert.False(t, ig.Ignored("cmd/build.go", false), "anchored patterns only match at their folder")
+	assert.True(t, ig.Ignored("docs/a/b/draft_x.py", false))
+	assert.True(t, ig.Ignored("sub/deep/local.go", false))
+	assert.False(t, ig.Ignored("local.go", false), "rules apply below their .gitignore only")
+	assert.True(t, ig.Ignored("node_modules/x/y.js", false))
+}
@@ -0,0 +1,146 @@
+package codeindex
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// skipDirs are never indexed, ignored by git or not
+var skipDirs = map[string]bool{
+	".git": true, ".hg": true, ".svn": true, "node_modules": true,
+	"vendor": true, "__pycache__": true, ".venv": true, "venv": true,
+	".tox": true, ".mypy_cache": true, ".next": true, ".nuxt": true,
+	"dist": true, "build": true, "target": true, ".gradle": true,
+	".idea": true, ".vscode": true, "coverage": true,
+}
+
+// ignoreRule is one line of a .gitignore
+type ignoreRule struct {
+	base     string // folder of the .gitignore, relative to the root
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool // matches from base rather than at any depth
+}
+
+// Ignore decides which paths of a project are left out, from its .gitignore
+// files. Paths are relative to the root, with forward slashes.
+type Ignore struct {
+	mu    sync.RWMutex
+	rules []ignoreRule
+}
+
+// Load reads the .gitignore in dir, a folder relative to root, if any.
+// Rules of deeper folders are loaded later and so take precedence.
+func (ig *Ignore) Load(root, dir string) {
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(dir), ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: dir}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+
+	ig.mu.Lock()
+	ig.rules = append(ig.rules, rules...)
+	ig.mu.Unlock()
+}
+
+// Ignored reports whether rel, or a folder it is in, is ignored
+func (ig *Ignore) Ignored(rel string, isDir bool) bool {
+	parts := strings.Split(rel, "/")
+	for i := range parts {
+		last := i == len(parts)-1
+		if ig.match(strings.Join(parts[:i+1], "/"), isDir || !last) {
+			return true
+		}
+	}
+	return false
+}
+
+// match applies the rules to rel itself, the last matching rule winning
+func (ig *Ignore) match(rel string, isDir bool) bool {
+	name := path.Base(rel)
+	if isDir && skipDirs[name] {
+		return true
+	}
+
+	ig.mu.RLock()
+	defer ig.mu.RUnlock()
+	ignored := false
+	for _, r := range ig.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		sub := rel
+		if r.base != "" {
+			if !strings.HasPrefix(rel, r.base+"/") {
+				continue
+			}
+			sub = rel[len(r.base)+1:]
+		}
+		var ok bool
+		if r.anchored {
+			ok = matchPath(strings.Split(r.pattern, "/"), strings.Split(sub, "/"))
+		} else {
+			ok, _ = path.Match(r.pattern, name)
+		}
+		if ok {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// matchPath matches path segments against pattern segments, where ** stands
+// for any number of segments
+func matchPath(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchPath(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchPath(pattern[1:], segments[1:])
+}
@@ -0,0 +1,741 @@
+// Package codeindex keeps a persistent index of a project's source files:
+// the symbols each declares, for a compact map of the repository, and
+// embeddings of those symbols, to find code by what it does rather than by
+// grepping for words
+package codeindex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/vector"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultMaxFiles caps how many files of a project are indexed
+	DefaultMaxFiles = 20000
+	// maxFileSize skips larger files, which are mostly generated
+	maxFileSize = 512 * 1024
+	// maxChunkLines caps how much of a long symbol is embedded
+	maxChunkLines = 80
+	// maxEmbedChars caps the text of one embedding
+	maxEmbedChars = 4000
+	// headLines is what is embedded of a file without symbols
+	headLines = 60
+)
+
+// ErrTooManyFiles is returned when a project has more files than the index
+// takes; the files beyond the cap are left out
+var ErrTooManyFiles = errors.New("too many files")
+
+// Embedder turns text into a vector. *vector.Searcher implements it.
+type Embedder interface {
+	GenerateEmbedding(text string) ([]float32, error)
+}
+
+// CodeFile records the symbols of an indexed file, so unchanged files
+// aren't parsed again after a restart
+type CodeFile struct {
+	Root     string `gorm:"primaryKey"`
+	Path     string `gorm:"primaryKey"`
+	Language string
+	ModTime  int64 // UnixNano of the file when it was indexed
+	Size     int64
+	Symbols  string // JSON list of Symbol
+}
+
+// TableName sets the table name
+func (CodeFile) TableName() string { return "code_files" }
+
+// CodeChunk stores the embedding of a symbol, or of the head of a file
+// without symbols
+type CodeChunk struct {
+	ID        uint   `gorm:"primaryKey"`
+	Root      string `gorm:"index:idx_code_chunks_file"`
+	Path      string `gorm:"index:idx_code_chunks_file"`
+	ModTime   int64  // of the file the embedding was made from
+	Symbol    string
+	Line      int
+	EndLine   int
+	Embedding []byte
+}
+
+// TableName sets the table name
+func (CodeChunk) TableName() string { return "code_chunks" }
+
+func init() {
+	store.RegisterMigrations("codeindex", store.Migration{
+		Version: 1,
+		Name:    "create code index tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&CodeFile{}, &CodeChunk{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&CodeFile{}, &CodeChunk{})
+		},
+	})
+}
+
+// File is an indexed source file
+type File struct {
+	Path     string   `json:"path"` // relative to the root, with forward slashes
+	Language string   `json:"language"`
+	Size     int64    `json:"size"`
+	Symbols  []Symbol `json:"symbols"`
+	modTime  int64
+}
+
+// chunk is an embedded piece of a file
+type chunk struct {
+	symbol    string
+	line      int
+	endLine   int
+	embedding []float32
+}
+
+// Match is a piece of code found by Search
+type Match struct {
+	Path      string  `json:"path"`
+	Symbol    string  `json:"symbol,omitempty"`
+	Kind      string  `json:"kind,omitempty"`
+	Signature string  `json:"signature,omitempty"`
+	Line      int     `json:"line"`
+	EndLine   int     `json:"end_line"`
+	Score     float64 `json:"score"`
+}
+
+// Stats describes an index
+type Stats struct {
+	Files    int  `json:"files"`
+	Symbols  int  `json:"symbols"`
+	Embedded int  `json:"embedded_files"`
+	Pending  int  `json:"pending_files"` // waiting to be embedded
+	Capped   bool `json:"capped,omitempty"`
+}
+
+// Index is the index of one project
+type Index struct {
+	root     string
+	db       *gorm.DB
+	embedder Embedder
+	maxFiles int
+	logger   *zap.Logger
+	ignore   *Ignore
+
+	mu      sync.RWMutex
+	files   map[string]*File
+	chunks  map[string][]chunk // by file, made from the file at chunkAt
+	chunkAt map[string]int64
+	pending map[string]bool // files waiting to be embedded
+	capped  bool
+
+	kick chan struct{}
+	stop chan struct{}
+	wg   sync.WaitGroup
+
+	// watched is set while a Watcher keeps the index current, so queries
+	// don't need to walk the project first
+	watched atomic.Bool
+}
+
+// Open loads the index of the project at root saved in db, which may be
+// nil to keep it in memory only. With an embedder, symbols are embedded in
+// the background for Search.
+func Open(root string, db *gorm.DB, embedder Embedder, maxFiles int, logger *zap.Logger) (*Index, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if maxFiles <= 0 {
+		maxFiles = DefaultMaxFiles
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	i := &Index{
+		root:     abs,
+		db:       db,
+		embedder: embedder,
+		maxFiles: maxFiles,
+		logger:   logger,
+		ignore:   &Ignore{},
+		files:    make(map[string]*File),
+		chunks:   make(map[string][]chunk),
+		chunkAt:  make(map[string]int64),
+		pending:  make(map[string]bool),
+		kick:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+	if db != nil {
+		if err := store.Migrate(db, "codeindex"); err != nil {
+			return nil, fmt.Errorf("failed to migrate code index: %w", err)
+		}
+		if err := i.load(); err != nil {
+			return nil, err
+		}
+	}
+	if embedder != nil {
+		i.wg.Add(1)
+		go i.embedLoop()
+	}
+	return i, nil
+}
+
+// load reads what was indexed before
+func (i *Index) load() error {
+	var files []CodeFile
+	if err := i.db.Where("root = ?", i.root).Find(&files).Error; err != nil {
+		return fmt.Errorf("failed to load code index: %w", err)
+	}
+	for _, cf := range files {
+		f := &File{Path: cf.Path, Language: cf.Language, Size: cf.Size, modTime: cf.ModTime}
+		json.Unmarshal([]byte(cf.Symbols), &f.Symbols)
+		i.files[cf.Path] = f
+	}
+	if i.embedder == nil {
+		return nil
+	}
+
+	var chunks []CodeChunk
+	if err := i.db.Where("root = ?", i.root).Order("path, line").Find(&chunks).Error; err != nil {
+		return fmt.Errorf("failed to load code embeddings: %w", err)
+	}
+	for _, c := range chunks {
+		i.chunks[c.Path] = append(i.chunks[c.Path], chunk{
+			symbol:    c.Symbol,
+			line:      c.Line,
+			endLine:   c.EndLine,
+			embedding: vector.DecodeEmbedding(c.Embedding),
+		})
+		i.chunkAt[c.Path] = c.ModTime
+	}
+	return nil
+}
+
+// Root is the folder the index covers
+func (i *Index) Root() string {
+	return i.root
+}
+
+// Close stops embedding in the background
+func (i *Index) Close() {
+	select {
+	case <-i.stop:
+	default:
+		close(i.stop)
+	}
+	i.wg.Wait()
+}
+
+// Refresh brings the index up to date with the project unless a watcher
+// already does
+func (i *Index) Refresh() error {
+	if i.watched.Load() {
+		return nil
+	}
+	return i.Sync()
+}
+
+// Sync walks the project, re-indexing files that changed since they were
+// indexed and dropping those that are gone or now ignored
+func (i *Index) Sync() error {
+	ignore := &Ignore{}
+	present := make(map[string]bool)
+	capped := false
+	err := filepath.WalkDir(i.root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if path == i.root {
+				return err
+			}
+			return nil
+		}
+		rel := i.rel(path)
+		if d.IsDir() {
+			if rel != "" && ignore.Ignored(rel, true) {
+				return filepath.SkipDir
+			}
+			ignore.Load(i.root, rel)
+			return nil
+		}
+		if Language(rel) == "" || ignore.Ignored(rel, false) {
+			return nil
+		}
+		if len(present) >= i.maxFiles {
+			capped = true
+			return filepath.SkipAll
+		}
+		present[rel] = true
+		if err := i.update(rel); err != nil {
+			i.logger.Debug("Failed to index file", zap.String("path", rel), zap.Error(err))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", i.root, err)
+	}
+
+	i.mu.Lock()
+	i.ignore = ignore
+	i.capped = capped
+	var gone []string
+	for path := range i.files {
+		if !present[path] {
+			gone = append(gone, path)
+		}
+	}
+	i.mu.Unlock()
+	for _, path := range gone {
+		i.Remove(path)
+	}
+	return nil
+}
+
+// rel turns an absolute path under the root into an index path
+func (i *Index) rel(path string) string {
+	rel, err := filepath.Rel(i.root, path)
+	if err != nil || rel == "." {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Ignored reports whether rel is left out of the index
+func (i *Index) Ignored(rel string, isDir bool) bool {
+	i.mu.RLock()
+	ignore := i.ignore
+	i.mu.RUnlock()
+	return ignore.Ignored(rel, isDir)
+}
+
+// Update re-indexes the file at rel if it changed, or drops it if it no
+// longer exists or is no longer indexed
+func (i *Index) Update(rel string) error {
+	if Language(rel) == "" || i.Ignored(rel, false) {
+		i.Remove(rel)
+		return nil
+	}
+	return i.update(rel)
+}
+
+func (i *Index) update(rel string) error {
+	info, err := os.Stat(filepath.Join(i.root, filepath.FromSlash(rel)))
+	if err != nil || info.IsDir() || info.Size() > maxFileSize {
+		i.Remove(rel)
+		return nil
+	}
+	modTime := info.ModTime().UnixNano()
+
+	i.mu.RLock()
+	existing := i.files[rel]
+	embedded := i.chunkAt[rel] == modTime
+	i.mu.RUnlock()
+	if existing != nil && existing.modTime == modTime && existing.Size == info.Size() {
+		if !embedded {
+			i.queue(rel)
+		}
+		return nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(i.root, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+	f := &File{
+		Path:     rel,
+		Language: Language(rel),
+		Size:     info.Size(),
+		Symbols:  ExtractSymbols(rel, content),
+		modTime:  modTime,
+	}
+	i.mu.Lock()
+	i.files[rel] = f
+	i.mu.Unlock()
+
+	if i.db != nil {
+		symbols, _ := json.Marshal(f.Symbols)
+		if err := i.db.Save(&CodeFile{
+			Root:     i.root,
+			Path:     rel,
+			Language: f.Language,
+			ModTime:  modTime,
+			Size:     f.Size,
+			Symbols:  string(symbols),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to save %s: %w", rel, err)
+		}
+	}
+	i.queue(rel)
+	return nil
+}
+
+// Remove drops the file at rel
+func (i *Index) Remove(rel string) {
+	i.mu.Lock()
+	_, known := i.files[rel]
+	delete(i.files, rel)
+	delete(i.chunks, rel)
+	delete(i.chunkAt, rel)
+	delete(i.pending, rel)
+	i.mu.Unlock()
+
+	if known && i.db != nil {
+		if err := i.db.Where("root = ? AND path = ?", i.root, rel).Delete(&CodeFile{}).Error; err != nil {
+			i.logger.Warn("Failed to drop indexed file", zap.String("path", rel), zap.Error(err))
+		}
+		i.db.Where("root = ? AND path = ?", i.root, rel).Delete(&CodeChunk{})
+	}
+}
+
+// queue has the background embedder embed rel
+func (i *Index) queue(rel string) {
+	if i.embedder == nil {
+		return
+	}
+	i.mu.Lock()
+	i.pending[rel] = true
+	i.mu.Unlock()
+	select {
+	case i.kick <- struct{}{}:
+	default:
+	}
+}
+
+// embedLoop embeds queued files until the index is closed
+func (i *Index) embedLoop() {
+	defer i.wg.Done()
+	for {
+		select {
+		case <-i.stop:
+			return
+		case <-i.kick:
+		}
+		for {
+			select {
+			case <-i.stop:
+				return
+			default:
+			}
+			rel, ok := i.nextPending()
+			if !ok {
+				break
+			}
+			if err := i.embedFile(rel); err != nil {
+				i.logger.Warn("Failed to embed code", zap.String("path", rel), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (i *Index) nextPending() (string, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for rel := range i.pending {
+		delete(i.pending, rel)
+		return rel, true
+	}
+	return "", false
+}
+
+// embedFile embeds the symbols of rel, or its head when it has none
+func (i *Index) embedFile(rel string) error {
+	i.mu.RLock()
+	f := i.files[rel]
+	i.mu.RUnlock()
+	if f == nil {
+		return nil
+	}
+	content, err := os.ReadFile(filepath.Join(i.root, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil // removed since; the next sync drops it
+	}
+	lines := strings.Split(string(content), "\n")
+
+	var chunks []chunk
+	for _, s := range topLevel(f.Symbols) {
+		chunks = append(chunks, chunk{symbol: s.Name, line: s.Line, endLine: s.EndLine})
+	}
+	if len(chunks) == 0 {
+		chunks = []chunk{{line: 1, endLine: min(len(lines), headLines)}}
+	}
+	for n := range chunks {
+		embedding, err := i.embedder.GenerateEmbedding(chunkText(rel, lines, chunks[n]))
+		if err != nil {
+			i.queueLater(rel)
+			return err
+		}
+		chunks[n].embedding = embedding
+	}
+
+	i.mu.Lock()
+	if cur := i.files[rel]; cur != f {
+		// Changed while embedding; it is queued again
+		i.mu.Unlock()
+		return nil
+	}
+	i.chunks[rel] = chunks
+	i.chunkAt[rel] = f.modTime
+	i.mu.Unlock()
+
+	if i.db == nil {
+		return nil
+	}
+	return i.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("root = ? AND path = ?", i.root, rel).Delete(&CodeChunk{}).Error; err != nil {
+			return err
+		}
+		for _, c := range chunks {
+			if err := tx.Create(&CodeChunk{
+				Root:      i.root,
+				Path:      rel,
+				ModTime:   f.modTime,
+				Symbol:    c.symbol,
+				Line:      c.line,
+				EndLine:   c.endLine,
+				Embedding: vector.EncodeEmbedding(c.embedding),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// queueLater puts a file back after a failed embedding without waking the
+// loop, so a provider that is down isn't hammered; the next change or sync
+// retries it
+func (i *Index) queueLater(rel string) {
+	i.mu.Lock()
+	i.pending[rel] = true
+	i.mu.Unlock()
+}
+
+// topLevel drops symbols nested in others, such as methods of a class,
+// which are embedded with it
+func topLevel(symbols []Symbol) []Symbol {
+	var out []Symbol
+	end := 0
+	for _, s := range symbols {
+		if s.Line <= end {
+			continue
+		}
+		out = append(out, s)
+		end = s.EndLine
+	}
+	return out
+}
+
+func chunkText(rel string, lines []string, c chunk) string {
+	from, to := c.line-1, c.endLine
+	if to-from > maxChunkLines {
+		to = from + maxChunkLines
+	}
+	if from < 0 {
+		from = 0
+	}
+	if to > len(lines) {
+		to = len(lines)
+	}
+	text := rel + "\n" + strings.Join(lines[from:to], "\n")
+	if len(text) > maxEmbedChars {
+		text = strings.ToValidUTF8(text[:maxEmbedChars], "")
+	}
+	return text
+}
+
+// WaitEmbedded embeds every queued file now, for callers that need the
+// index complete, and returns how many files are embedded
+func (i *Index) WaitEmbedded() (int, error) {
+	if i.embedder == nil {
+		return 0, nil
+	}
+	for {
+		rel, ok := i.nextPending()
+		if !ok {
+			break
+		}
+		if err := i.embedFile(rel); err != nil {
+			return 0, err
+		}
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.chunkAt), nil
+}
+
+// Reindex embeds every file again, after the embedding model changes
+func (i *Index) Reindex() (int, error) {
+	if i.embedder == nil {
+		return 0, nil
+	}
+	i.mu.Lock()
+	i.chunks = make(map[string][]chunk)
+	i.chunkAt = make(map[string]int64)
+	for rel := range i.files {
+		i.pending[rel] = true
+	}
+	i.mu.Unlock()
+	if i.db != nil {
+		if err := i.db.Where("root = ?", i.root).Delete(&CodeChunk{}).Error; err != nil {
+			return 0, fmt.Errorf("failed to drop code embeddings: %w", err)
+		}
+	}
+	return i.WaitEmbedded()
+}
+
+// Files returns the indexed files in path order
+func (i *Index) Files() []*File {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	files := make([]*File, 0, len(i.files))
+	for _, f := range i.files {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(a, b int) bool { return files[a].Path < files[b].Path })
+	return files
+}
+
+// Stats describes the index
+func (i *Index) Stats() Stats {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	st := Stats{Files: len(i.files), Embedded: len(i.chunkAt), Pending: len(i.pending), Capped: i.capped}
+	for _, f := range i.files {
+		st.Symbols += len(f.Symbols)
+	}
+	return st
+}
+
+// Semantic reports whether symbols are embedded for Search
+func (i *Index) Semantic() bool {
+	return i.embedder != nil
+}
+
+// Search returns up to limit symbols most related to query: by meaning when
+// the index is embedded, and by the words of their names and signatures
+// otherwise or for files not embedded yet
+func (i *Index) Search(query string, limit int) ([]Match, error) {
+	var queryEmbedding []float32
+	if i.embedder != nil {
+		var err error
+		if queryEmbedding, err = i.embedder.GenerateEmbedding(query); err != nil {
+			return nil, fmt.Errorf("failed to embed query: %w", err)
+		}
+	}
+	terms := queryTerms(query)
+
+	i.mu.RLock()
+	var matches []Match
+	for rel, f := range i.files {
+		chunks, embedded := i.chunks[rel]
+		if queryEmbedding != nil && embedded && i.chunkAt[rel] == f.modTime {
+			for _, c := range chunks {
+				m := Match{Path: rel, Symbol: c.symbol, Line: c.line, EndLine: c.endLine,
+					Score: vector.CosineSimilarity(queryEmbedding, c.embedding)}
+				if s := f.symbolAt(c.line); s != nil {
+					m.Kind, m.Signature = s.Kind, s.Signature
+				}
+				matches = append(matches, m)
+			}
+			continue
+		}
+		for _, s := range f.Symbols {
+			if score := termScore(terms, rel+" "+s.Name+" "+s.Signature); score > 0 {
+				matches = append(matches, Match{Path: rel, Symbol: s.Name, Kind: s.Kind, Signature: s.Signature,
+					Line: s.Line, EndLine: s.EndLine, Score: score / 2})
+			}
+		}
+	}
+	i.mu.RUnlock()
+
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		return matches[a].Path < matches[b].Path || matches[a].Path == matches[b].Path && matches[a].Line < matches[b].Line
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+func (f *File) symbolAt(line int) *Symbol {
+	for n := range f.Symbols {
+		if f.Symbols[n].Line == line {
+			return &f.Symbols[n]
+		}
+	}
+	return nil
+}
+
+// queryTerms splits a query into lower-case words, splitting camelCase and
+// snake_case so "parseConfig" finds parse_config
+func queryTerms(query string) []string {
+	var terms []string
+	for _, w := range splitWords(query) {
+		if len(w) > 2 && !stopWords[w] {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "was": true, "what": true,
+	"when": true, "where": true, "who": true, "how": true, "does": true, "with": true,
+	"from": true, "that": true, "this": true, "about": true, "code": true, "which": true,
+}
+
+func splitWords(s string) []string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+	runes := []rune(s)
+	for n, r := range runes {
+		isLetter := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r > 127
+		if !isLetter {
+			flush()
+			continue
+		}
+		if r >= 'A' && r <= 'Z' && len(cur) > 0 {
+			prev := runes[n-1]
+			nextLower := n+1 < len(runes) && runes[n+1] >= 'a' && runes[n+1] <= 'z'
+			if prev >= 'a' && prev <= 'z' || prev >= 'A' && prev <= 'Z' && nextLower {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return words
+}
+
+// termScore is the share of terms found among the words of content
+func termScore(terms []string, content string) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	words := strings.Join(splitWords(content), " ")
+	found := 0
+	for _, t := range terms {
+		if strings.Contains(words, t) {
+			found++
+		}
+	}
+	return float64(found) / float64(len(terms))
+}
@@ -0,0 +1,126 @@
+package codeindex
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Options configures the indexes a Manager opens
+type Options struct {
+	Watch    bool // follow changes on disk instead of walking the project per query
+	MaxFiles int
+}
+
+// Manager opens the index of each project the first time it is asked for
+type Manager struct {
+	db       *gorm.DB
+	embedder Embedder
+	opts     Options
+	logger   *zap.Logger
+
+	mu       sync.Mutex
+	indexes  map[string]*Index
+	watchers map[string]*Watcher
+}
+
+// NewManager creates a manager keeping indexes in db. embedder may be nil
+// to index symbols only.
+func NewManager(db *gorm.DB, embedder Embedder, opts Options, logger *zap.Logger) *Manager {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Manager{
+		db:       db,
+		embedder: embedder,
+		opts:     opts,
+		logger:   logger,
+		indexes:  make(map[string]*Index),
+		watchers: make(map[string]*Watcher),
+	}
+}
+
+// Index returns the up-to-date index of the project at root
+func (m *Manager) Index(root string) (*Index, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if idx := m.indexes[abs]; idx != nil {
+		return idx, idx.Refresh()
+	}
+
+	idx, err := Open(abs, m.db, m.embedder, m.opts.MaxFiles, m.logger)
+	if err != nil {
+		return nil, err
+	}
+	if m.opts.Watch {
+		if w, err := NewWatcher(idx, m.logger); err != nil {
+			m.logger.Warn("Code index watcher disabled", zap.String("root", abs), zap.Error(err))
+		} else if err := w.Start(); err != nil {
+			// Too many folders for the OS's watch limit, most likely; queries
+			// walk the project instead
+			w.Stop()
+			m.logger.Warn("Code index watcher disabled", zap.String("root", abs), zap.Error(err))
+		} else {
+			m.watchers[abs] = w
+		}
+	}
+	if err := idx.Refresh(); err != nil {
+		idx.Close()
+		return nil, fmt.Errorf("failed to index %s: %w", root, err)
+	}
+	m.indexes[abs] = idx
+	m.logger.Info("Indexed project", zap.String("root", abs), zap.Int("files", idx.Stats().Files))
+	return idx, nil
+}
+
+// Reindex embeds the open projects again, after the embedding model
+// changes, and returns how many files are embedded. Projects not open are
+// embedded again when next opened.
+func (m *Manager) Reindex() (int, error) {
+	if m.embedder == nil {
+		return 0, nil
+	}
+	if m.db != nil {
+		if err := m.db.Where("1 = 1").Delete(&CodeChunk{}).Error; err != nil {
+			return 0, fmt.Errorf("failed to drop code embeddings: %w", err)
+		}
+	}
+	m.mu.Lock()
+	indexes := make([]*Index, 0, len(m.indexes))
+	for _, idx := range m.indexes {
+		indexes = append(indexes, idx)
+	}
+	m.mu.Unlock()
+
+	total := 0
+	for _, idx := range indexes {
+		n, err := idx.Reindex()
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// Close stops watching and embedding
+func (m *Manager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for root, w := range m.watchers {
+		w.Stop()
+		delete(m.watchers, root)
+	}
+	for root, idx := range m.indexes {
+		idx.Close()
+		delete(m.indexes, root)
+	}
+}
@@ -0,0 +1,96 @@
+package codeindex
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultMapChars caps the size of a repository map
+const DefaultMapChars = 8000
+
+// MapOptions selects what a repository map shows
+type MapOptions struct {
+	Focus    string // folder, file or glob to limit the map to
+	Query    string // files relevant to it come first
+	MaxChars int
+}
+
+// RepoMap is a compact outline of a project: its files with the
+// declarations in each
+type RepoMap struct {
+	Text  string `json:"map"`
+	Shown int    `json:"files_shown"`
+	Total int    `json:"files_total"`
+}
+
+// Map outlines the indexed files
+func (i *Index) Map(opts MapOptions) RepoMap {
+	if opts.MaxChars <= 0 {
+		opts.MaxChars = DefaultMapChars
+	}
+	focus := strings.Trim(path.Clean("/"+strings.ReplaceAll(opts.Focus, `\`, "/")), "/")
+
+	var files []*File
+	for _, f := range i.Files() {
+		if focus == "" || f.Path == focus || strings.HasPrefix(f.Path, focus+"/") || globMatch(focus, f.Path) {
+			files = append(files, f)
+		}
+	}
+
+	if terms := queryTerms(opts.Query); len(terms) > 0 {
+		scores := make(map[string]float64, len(files))
+		for _, f := range files {
+			text := f.Path
+			for _, s := range f.Symbols {
+				text += " " + s.Name
+			}
+			scores[f.Path] = termScore(terms, text)
+		}
+		sort.SliceStable(files, func(a, b int) bool { return scores[files[a].Path] > scores[files[b].Path] })
+	}
+
+	var b strings.Builder
+	shown := 0
+	for _, f := range files {
+		entry := mapEntry(f)
+		if b.Len()+len(entry) > opts.MaxChars && shown > 0 {
+			break
+		}
+		b.WriteString(entry)
+		shown++
+	}
+	if shown < len(files) {
+		fmt.Fprintf(&b, "… %d more files; narrow the map with focus or query\n", len(files)-shown)
+	}
+	return RepoMap{Text: b.String(), Shown: shown, Total: len(files)}
+}
+
+// mapEntry outlines one file, nesting symbols declared inside others
+func mapEntry(f *File) string {
+	var b strings.Builder
+	b.WriteString(f.Path + "\n")
+	var ends []int
+	for _, s := range f.Symbols {
+		for len(ends) > 0 && s.Line > ends[len(ends)-1] {
+			ends = ends[:len(ends)-1]
+		}
+		fmt.Fprintf(&b, "%s%d: %s\n", strings.Repeat("  ", len(ends)+1), s.Line, s.Signature)
+		ends = append(ends, s.EndLine)
+	}
+	return b.String()
+}
+
+// globMatch matches a glob like "*.go" against the file name, or one with
+// slashes against the whole path
+func globMatch(pattern, rel string) bool {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return false
+	}
+	if strings.Contains(pattern, "/") {
+		return matchPath(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+	}
+	ok, _ := path.Match(pattern, path.Base(rel))
+	return ok
+}
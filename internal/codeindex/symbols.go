@@ -0,0 +1,255 @@
+package codeindex
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxSignature caps how much of a declaration is kept
+const maxSignature = 160
+
+// Symbol is a declaration in a file. Lines count from 1.
+type Symbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Line      int    `json:"line"`
+	EndLine   int    `json:"end_line"`
+	Signature string `json:"signature"`
+}
+
+var languages = map[string]string{
+	".go": "go", ".py": "python", ".js": "javascript", ".jsx": "javascript",
+	".mjs": "javascript", ".cjs": "javascript", ".ts": "typescript",
+	".tsx": "typescript", ".rs": "rust", ".java": "java", ".kt": "kotlin",
+	".scala": "scala", ".cs": "csharp", ".rb": "ruby", ".php": "php",
+	".swift": "swift", ".c": "c", ".h": "c", ".cc": "cpp", ".cpp": "cpp",
+	".cxx": "cpp", ".hpp": "cpp", ".sh": "shell", ".bash": "shell",
+	".lua": "lua", ".ex": "elixir", ".exs": "elixir", ".dart": "dart",
+}
+
+// Language returns the language of a source file, or "" for files that
+// aren't indexed
+func Language(path string) string {
+	return languages[strings.ToLower(filepath.Ext(path))]
+}
+
+type symbolPattern struct {
+	re   *regexp.Regexp
+	kind string
+}
+
+// patterns find declarations in languages without a parser here. The first
+// group is the name.
+var patterns = map[string][]symbolPattern{
+	"python": {
+		{regexp.MustCompile(`^\s*class\s+(\w+)`), "class"},
+		{regexp.MustCompile(`^\s*(?:async\s+)?def\s+(\w+)\s*\(`), "function"},
+	},
+	"javascript": jsPatterns,
+	"typescript": append([]symbolPattern{
+		{regexp.MustCompile(`^\s*(?:export\s+)?(?:declare\s+)?interface\s+(\w+)`), "interface"},
+		{regexp.MustCompile(`^\s*(?:export\s+)?(?:declare\s+)?type\s+(\w+)\s*(?:<[^=]*>)?\s*=`), "type"},
+		{regexp.MustCompile(`^\s*(?:export\s+)?(?:const\s+)?enum\s+(\w+)`), "enum"},
+	}, jsPatterns...),
+	"rust": {
+		{regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:unsafe\s+)?fn\s+(\w+)`), "function"},
+		{regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?struct\s+(\w+)`), "struct"},
+		{regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?enum\s+(\w+)`), "enum"},
+		{regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?trait\s+(\w+)`), "interface"},
+		{regexp.MustCompile(`^\s*impl(?:<[^>]*>)?\s+(?:\w+\s+for\s+)?(\w+)`), "impl"},
+	},
+	"java":   classPatterns,
+	"kotlin": classPatterns,
+	"scala":  classPatterns,
+	"csharp": classPatterns,
+	"dart":   classPatterns,
+	"swift": {
+		{regexp.MustCompile(`^\s*(?:(?:public|private|internal|open|final)\s+)*(?:class|struct|enum|protocol|extension)\s+(\w+)`), "class"},
+		{regexp.MustCompile(`^\s*(?:(?:public|private|internal|open|static|override)\s+)*func\s+(\w+)`), "function"},
+	},
+	"ruby": {
+		{regexp.MustCompile(`^\s*(?:class|module)\s+([\w:]+)`), "class"},
+		{regexp.MustCompile(`^\s*def\s+(?:self\.)?(\w+[?!]?)`), "function"},
+	},
+	"php": {
+		{regexp.MustCompile(`^\s*(?:abstract\s+|final\s+)?(?:class|interface|trait)\s+(\w+)`), "class"},
+		{regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|abstract|final)\s+)*function\s+(\w+)`), "function"},
+	},
+	"c":   cPatterns,
+	"cpp": append([]symbolPattern{{regexp.MustCompile(`^\s*(?:class|struct|namespace)\s+(\w+)\s*[:{]?\s*$`), "class"}}, cPatterns...),
+	"shell": {
+		{regexp.MustCompile(`^\s*(?:function\s+)?([\w-]+)\s*\(\)\s*\{?`), "function"},
+	},
+	"lua": {
+		{regexp.MustCompile(`^\s*(?:local\s+)?function\s+([\w.:]+)`), "function"},
+	},
+	"elixir": {
+		{regexp.MustCompile(`^\s*defmodule\s+([\w.]+)`), "module"},
+		{regexp.MustCompile(`^\s*defp?\s+(\w+[?!]?)`), "function"},
+	},
+}
+
+var jsPatterns = []symbolPattern{
+	{regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(\w+)`), "class"},
+	{regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*(\w+)\s*[(<]`), "function"},
+	{regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+(\w+)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:\([^)]*\)|\w+)\s*(?::[^=]+)?=>`), "function"},
+	{regexp.MustCompile(`^\s+(?:(?:public|private|protected|static|async|readonly|get|set)\s+)*(\w+)\s*\([^)]*\)\s*(?::[^{]+)?\{\s*$`), "method"},
+}
+
+var classPatterns = []symbolPattern{
+	{regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|abstract|final|static|sealed|partial|data|open|case)\s+)*(?:class|interface|enum|record|object|struct|trait|mixin)\s+(\w+)`), "class"},
+	{regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|static|final|abstract|override|async|virtual|suspend|synchronized)\s+)+[\w<>\[\],.? ]*?\s*(\w+)\s*\([^;]*$`), "method"},
+	{regexp.MustCompile(`^\s*(?:fun|def)\s+(?:<[^>]*>\s*)?(?:[\w.]+\.)?(\w+)\s*[(\[]`), "function"},
+}
+
+var cPatterns = []symbolPattern{
+	{regexp.MustCompile(`^(?:typedef\s+)?(?:struct|union|enum)\s+(\w+)\s*\{`), "struct"},
+	{regexp.MustCompile(`^(?:static\s+|inline\s+|extern\s+)*[A-Za-z_][\w\s\*:<>,]*?[\s\*&]+(\w+)\s*\([^;]*$`), "function"},
+}
+
+// keywords are words the patterns may take for names
+var keywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true,
+	"return": true, "else": true, "new": true, "function": true, "do": true,
+}
+
+// ExtractSymbols finds the declarations in a source file
+func ExtractSymbols(path string, content []byte) []Symbol {
+	lang := Language(path)
+	if lang == "go" {
+		if symbols, ok := goSymbols(content); ok {
+			return symbols
+		}
+	}
+	list := patterns[lang]
+	if len(list) == 0 {
+		return nil
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var symbols []Symbol
+	var indents []int
+	inComment := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		// Skip block comments, which often quote code
+		if inComment {
+			if strings.Contains(trimmed, "*/") {
+				inComment = false
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "/*") && !strings.Contains(trimmed, "*/") {
+			inComment = true
+			continue
+		}
+		if strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "#") && lang != "c" && lang != "cpp" || strings.HasPrefix(trimmed, "*") {
+			continue
+		}
+		for _, p := range list {
+			m := p.re.FindStringSubmatch(line)
+			if m == nil || keywords[m[1]] {
+				continue
+			}
+			symbols = append(symbols, Symbol{
+				Name:      m[1],
+				Kind:      p.kind,
+				Line:      i + 1,
+				Signature: signature(trimmed),
+			})
+			indents = append(indents, len(line)-len(strings.TrimLeft(line, " \t")))
+			break
+		}
+	}
+
+	// A declaration runs until the next one at the same or a lower indent
+	for n := range symbols {
+		end := len(lines)
+		for m := n + 1; m < len(symbols); m++ {
+			if indents[m] <= indents[n] {
+				end = symbols[m].Line - 1
+				break
+			}
+		}
+		for end > symbols[n].Line && strings.TrimSpace(lines[end-1]) == "" {
+			end--
+		}
+		symbols[n].EndLine = end
+	}
+	return symbols
+}
+
+// goSymbols reads the declarations of a Go file with the Go parser
+func goSymbols(content []byte) ([]Symbol, bool) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, false
+	}
+	text := func(from, to token.Pos) string {
+		start, end := fset.Position(from).Offset, fset.Position(to).Offset
+		if start < 0 || end > len(content) || start >= end {
+			return ""
+		}
+		return signature(string(content[start:end]))
+	}
+
+	var symbols []Symbol
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			kind := "function"
+			if d.Recv != nil {
+				kind = "method"
+			}
+			end := d.End()
+			if d.Body != nil {
+				end = d.Body.Lbrace
+			}
+			symbols = append(symbols, Symbol{
+				Name:      d.Name.Name,
+				Kind:      kind,
+				Line:      fset.Position(d.Pos()).Line,
+				EndLine:   fset.Position(d.End()).Line,
+				Signature: text(d.Pos(), end),
+			})
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				kind := "type"
+				sigEnd := ts.End()
+				switch t := ts.Type.(type) {
+				case *ast.StructType:
+					kind, sigEnd = "struct", t.Fields.Opening+1
+				case *ast.InterfaceType:
+					kind, sigEnd = "interface", t.Methods.Opening+1
+				}
+				symbols = append(symbols, Symbol{
+					Name:      ts.Name.Name,
+					Kind:      kind,
+					Line:      fset.Position(ts.Pos()).Line,
+					EndLine:   fset.Position(ts.End()).Line,
+					Signature: "type " + text(ts.Pos(), sigEnd),
+				})
+			}
+		}
+	}
+	return symbols, true
+}
+
+// signature collapses a declaration onto one short line
+func signature(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "{"), " ")
+	if len(s) > maxSignature {
+		s = strings.ToValidUTF8(s[:maxSignature], "") + "…"
+	}
+	return s
+}
@@ -0,0 +1,187 @@
+package codeindex
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// watchDebounce is how long a file must be quiet before it is re-indexed,
+// as editors, formatters and git checkouts write in bursts
+const watchDebounce = 500 * time.Millisecond
+
+// Watcher re-indexes files as they change on disk
+type Watcher struct {
+	index   *Index
+	fs      *fsnotify.Watcher
+	logger  *zap.Logger
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	pending map[string]bool
+}
+
+// NewWatcher creates a watcher that keeps index current
+func NewWatcher(index *Index, logger *zap.Logger) (*Watcher, error) {
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Watcher{index: index, fs: fs, logger: logger, pending: make(map[string]bool)}, nil
+}
+
+// Start indexes the whole project, then follows changes until Stop is
+// called
+func (w *Watcher) Start() error {
+	if err := w.index.Sync(); err != nil {
+		return err
+	}
+	if err := w.watchTree(w.index.Root()); err != nil {
+		return err
+	}
+	w.index.watched.Store(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		timer := time.NewTimer(watchDebounce)
+		timer.Stop()
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-w.fs.Events:
+				if !ok {
+					return
+				}
+				if w.handle(event) {
+					timer.Reset(watchDebounce)
+				}
+			case err, ok := <-w.fs.Errors:
+				if !ok {
+					return
+				}
+				w.logger.Warn("Code index watcher error", zap.Error(err))
+			case <-timer.C:
+				w.flush()
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops following changes. Queries walk the project themselves again.
+func (w *Watcher) Stop() {
+	w.index.watched.Store(false)
+	if w.cancel != nil {
+		w.cancel()
+		w.wg.Wait()
+	}
+	w.fs.Close()
+}
+
+// handle queues the file an event touched and reports whether it did
+func (w *Watcher) handle(event fsnotify.Event) bool {
+	rel := w.index.rel(event.Name)
+	if rel == "" {
+		return false
+	}
+
+	// New folders are watched too; a changed .gitignore or a renamed or
+	// removed folder has the whole project checked
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if w.index.Ignored(rel, true) {
+				return false
+			}
+			if err := w.watchTree(event.Name); err != nil {
+				w.logger.Warn("Failed to watch folder", zap.String("path", event.Name), zap.Error(err))
+			}
+			w.queueAll()
+			return true
+		}
+	}
+	if filepath.Base(rel) == ".gitignore" {
+		w.queueAll()
+		return true
+	}
+	if Language(rel) == "" {
+		if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+			w.queueAll()
+			return true
+		}
+		return false
+	}
+	if w.index.Ignored(rel, false) {
+		return false
+	}
+
+	w.mu.Lock()
+	w.pending[rel] = true
+	w.mu.Unlock()
+	return true
+}
+
+// queueAll has the next flush check the whole project
+func (w *Watcher) queueAll() {
+	w.mu.Lock()
+	w.pending[""] = true
+	w.mu.Unlock()
+}
+
+// flush re-indexes the files changed since the last flush
+func (w *Watcher) flush() {
+	w.mu.Lock()
+	pending := w.pending
+	w.pending = make(map[string]bool)
+	w.mu.Unlock()
+
+	if pending[""] {
+		if err := w.index.Sync(); err != nil {
+			w.logger.Warn("Failed to re-index code", zap.Error(err))
+		}
+		return
+	}
+	for rel := range pending {
+		if err := w.index.Update(rel); err != nil {
+			w.logger.Warn("Failed to re-index file", zap.String("path", rel), zap.Error(err))
+		}
+	}
+	w.logger.Debug("Re-indexed changed files", zap.Int("count", len(pending)))
+}
+
+// watchTree watches dir and its folders that aren't ignored; fsnotify
+// doesn't watch recursively
+func (w *Watcher) watchTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if rel := w.index.rel(path); rel != "" && w.index.Ignored(rel, true) {
+			return filepath.SkipDir
+		}
+		if err := w.fs.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
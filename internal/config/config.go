@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
@@ -13,16 +14,29 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	LLM      LLMConfig      `mapstructure:"llm"`
-	Storage  StorageConfig  `mapstructure:"storage"`
-	Channels ChannelsConfig `mapstructure:"channels"`
-	Tools    ToolsConfig    `mapstructure:"tools"`
-	Security SecurityConfig `mapstructure:"security"`
-	Skills   SkillsConfig   `mapstructure:"skills"`
-	MCP      MCPConfig      `mapstructure:"mcp"`
-	Cron     CronConfig     `mapstructure:"cron"`
-	Vector   VectorConfig   `mapstructure:"vector"`
+	Server    ServerConfig    `mapstructure:"server"`
+	LLM       LLMConfig       `mapstructure:"llm"`
+	Storage   StorageConfig   `mapstructure:"storage"`
+	Channels  ChannelsConfig  `mapstructure:"channels"`
+	Tools     ToolsConfig     `mapstructure:"tools"`
+	Security  SecurityConfig  `mapstructure:"security"`
+	Skills    SkillsConfig    `mapstructure:"skills"`
+	MCP       MCPConfig       `mapstructure:"mcp"`
+	Cron      CronConfig      `mapstructure:"cron"`
+	Vector    VectorConfig    `mapstructure:"vector"`
+	Agent     AgentConfig     `mapstructure:"agent"`
+	Household HouseholdConfig `mapstructure:"household"`
+	Journal   JournalConfig   `mapstructure:"journal"`
+	Location  LocationConfig  `mapstructure:"location"`
+	MQTT      MQTTConfig      `mapstructure:"mqtt"`
+	Secrets   SecretsConfig   `mapstructure:"secrets"`
+	Privacy   PrivacyConfig   `mapstructure:"privacy"`
+
+	// File is the config file Load read, or would read once created
+	File string `mapstructure:"-"`
+	// Generated lists the settings Load made up because none were
+	// configured, e.g. "security.jwt_secret"
+	Generated []string `mapstructure:"-"`
 }
 
 type ServerConfig struct {
@@ -30,25 +44,86 @@ type ServerConfig struct {
 	Port         int    `mapstructure:"port"`
 	ReadTimeout  int    `mapstructure:"read_timeout"`
 	WriteTimeout int    `mapstructure:"write_timeout"`
+	LogLevel     string `mapstructure:"log_level"` // debug, info, warn or error; incident mode raises it temporarily
+	// Tailscale makes the server reachable from anywhere on your tailnet
+	Tailscale TailscaleConfig `mapstructure:"tailscale"`
+}
+
+// TailscaleConfig joins the server to a tailnet as its own machine, so the
+// API and web UI can be reached remotely without opening ports or running a
+// separate VPN. Setting AuthKey turns it on.
+type TailscaleConfig struct {
+	AuthKey  string `mapstructure:"auth_key"`  // from the Tailscale admin console
+	Hostname string `mapstructure:"hostname"`  // machine name on the tailnet
+	StateDir string `mapstructure:"state_dir"` // node keys; defaults to <data_dir>/tailscale
+	HTTPS    bool   `mapstructure:"https"`     // serve on :443 with a tailnet certificate instead of :80
 }
 
 type LLMConfig struct {
 	DefaultProvider string              `mapstructure:"default_provider"`
 	Providers       map[string]Provider `mapstructure:"providers"`
+	Offline         OfflineConfig       `mapstructure:"offline"`
+}
+
+// OfflineConfig keeps the assistant working when the network is down: turns
+// go to a local model, and skills that need the internet are switched off
+// until it can be reached again
+type OfflineConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Provider string `mapstructure:"provider"` // the local provider in llm.providers, e.g. ollama
+	// CheckURL is probed to tell whether the network is up; empty probes
+	// the default provider
+	CheckURL      string   `mapstructure:"check_url"`
+	CheckInterval int      `mapstructure:"check_interval"` // seconds between probes
+	NetworkSkills []string `mapstructure:"network_skills"` // skills switched off while offline
 }
 
 type Provider struct {
-	APIKey    string `mapstructure:"api_key"`
-	BaseURL   string `mapstructure:"base_url"`
-	Model     string `mapstructure:"model"`
-	Timeout   int    `mapstructure:"timeout"`
-	MaxTokens int    `mapstructure:"max_tokens"`
+	APIKey        string          `mapstructure:"api_key"`
+	BaseURL       string          `mapstructure:"base_url"`
+	Model         string          `mapstructure:"model"`
+	API           string          `mapstructure:"api"` // "openai", "anthropic" or "gemini"; defaults from the base URL
+	Timeout       int             `mapstructure:"timeout"`
+	MaxTokens     int             `mapstructure:"max_tokens"`
+	ContextWindow int             `mapstructure:"context_window"` // overrides the model's window, e.g. for a smaller Ollama num_ctx
+	Vision        bool            `mapstructure:"vision"`         // the model accepts images, for models Myrai doesn't recognise
+	ToolCalling   string          `mapstructure:"tool_calling"`   // "native" or "prompt"; defaults from the model
+	RateLimit     RateLimitConfig `mapstructure:"rate_limit"`
+}
+
+// RateLimitConfig holds client-side rate limits for a provider. Zero values
+// mean unlimited; limits reported by the provider's response headers are
+// always honoured on top of these.
+type RateLimitConfig struct {
+	RPM            int `mapstructure:"rpm"`             // Requests per minute
+	TPM            int `mapstructure:"tpm"`             // Tokens per minute
+	MaxConcurrency int `mapstructure:"max_concurrency"` // Concurrent in-flight requests
+	Burst          int `mapstructure:"burst"`           // Request burst size (defaults to RPM/10)
 }
 
 type StorageConfig struct {
-	DataDir    string `mapstructure:"data_dir"`
-	SQLitePath string `mapstructure:"sqlite_path"`
-	BadgerPath string `mapstructure:"badger_path"`
+	DataDir    string           `mapstructure:"data_dir"`
+	SQLitePath string           `mapstructure:"sqlite_path"`
+	BadgerPath string           `mapstructure:"badger_path"`
+	Encryption EncryptionConfig `mapstructure:"encryption"`
+	Backup     BackupConfig     `mapstructure:"backup"`
+}
+
+// BackupConfig takes scheduled backups while the server runs. Each one is a
+// single .tar.zst archive that 'myrai backup restore' reads.
+type BackupConfig struct {
+	IntervalHours int    `mapstructure:"interval_hours"` // 0 turns scheduled backups off
+	Keep          int    `mapstructure:"keep"`           // archives kept; older ones are deleted
+	Dir           string `mapstructure:"dir"`            // defaults to <data_dir>/backups
+}
+
+// EncryptionConfig encrypts message bodies, document text and health notes
+// in the database
+type EncryptionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// KeySource is passphrase (MYRAI_STORAGE_PASSPHRASE, which the secrets
+	// backend can hold) or keychain (a random key kept in the OS keychain)
+	KeySource string `mapstructure:"key_source"`
 }
 
 type ChannelsConfig struct {
@@ -59,10 +134,30 @@ type ChannelsConfig struct {
 }
 
 type TelegramConfig struct {
-	Enabled   bool    `mapstructure:"enabled"`
-	BotToken  string  `mapstructure:"bot_token"`
-	Webhook   string  `mapstructure:"webhook"`
-	AllowList []int64 `mapstructure:"allow_list"`
+	Enabled       bool          `mapstructure:"enabled"`
+	BotToken      string        `mapstructure:"bot_token"`
+	Webhook       string        `mapstructure:"webhook"`
+	AllowList     []int64       `mapstructure:"allow_list"`
+	ContentFilter string        `mapstructure:"content_filter"` // off or family
+	GroupMode     string        `mapstructure:"group_mode"`     // mention, all or off
+	Inbound       InboundConfig `mapstructure:"inbound"`
+}
+
+// Group modes decide which messages in group chats the bots answer
+const (
+	GroupModeMention = "mention" // mentions, replies to the bot and commands
+	GroupModeAll     = "all"     // every message
+	GroupModeOff     = "off"     // none; the bot only answers in private
+)
+
+// InboundConfig screens messages arriving on a chat channel before they
+// reach the agent. Zero values turn a check off.
+type InboundConfig struct {
+	MessagesPerMinute     int      `mapstructure:"messages_per_minute"`      // Per user
+	ChatMessagesPerMinute int      `mapstructure:"chat_messages_per_minute"` // Per chat, for groups
+	CooldownSeconds       int      `mapstructure:"cooldown_seconds"`         // Quiet time after a limit is hit
+	MaxMessageChars       int      `mapstructure:"max_message_chars"`        // Longest text or caption accepted
+	AllowedAttachments    []string `mapstructure:"allowed_attachments"`      // MIME types, image/* or .ext; empty allows all
 }
 
 type WhatsAppConfig struct {
@@ -70,8 +165,11 @@ type WhatsAppConfig struct {
 }
 
 type DiscordConfig struct {
-	Enabled bool   `mapstructure:"enabled"`
-	Token   string `mapstructure:"token"`
+	Enabled       bool          `mapstructure:"enabled"`
+	Token         string        `mapstructure:"token"`
+	ContentFilter string        `mapstructure:"content_filter"` // off or family
+	GroupMode     string        `mapstructure:"group_mode"`     // mention, all or off
+	Inbound       InboundConfig `mapstructure:"inbound"`
 }
 
 type SlackConfig struct {
@@ -84,6 +182,93 @@ type ToolsConfig struct {
 	Enabled     []string `mapstructure:"enabled"`
 	AllowedCmds []string `mapstructure:"allowed_commands"`
 	Sandbox     bool     `mapstructure:"sandbox"`
+	// Exec sets where and under what limits the system skill runs commands
+	Exec ExecConfig `mapstructure:"exec"`
+	// Filesystem limits which files the file tools may read and write
+	Filesystem FilesystemConfig `mapstructure:"filesystem"`
+	// Coding controls how the agentic skill patches code and runs tests
+	Coding CodingConfig `mapstructure:"coding"`
+}
+
+// CodingConfig controls the tools that change code and run tests. Tests
+// run under tools.exec, like other commands.
+type CodingConfig struct {
+	// Approval is how a proposed patch is approved: "code" sends the user
+	// a code to tell the assistant, "none" applies patches when asked.
+	// Empty is "code".
+	Approval string `mapstructure:"approval"`
+	// TestCommand is run by run_tests, e.g. "make check". Empty detects
+	// it from the project: go test, npm test, cargo test or pytest.
+	TestCommand        string `mapstructure:"test_command"`
+	TestTimeoutSeconds int    `mapstructure:"test_timeout_seconds"`
+	// LanguageServers maps a language (go, python, javascript, typescript,
+	// rust, c, cpp) to the language server to run for it, e.g.
+	// "pylsp". "off" disables one. Unlisted languages use gopls,
+	// pyright, typescript-language-server, rust-analyzer or clangd when
+	// installed.
+	LanguageServers map[string]string `mapstructure:"language_servers"`
+	// Index is the symbol and embedding index behind get_repo_map and
+	// query_code_semantic
+	Index CodeIndexConfig `mapstructure:"index"`
+}
+
+// CodeIndexConfig controls the code index. Symbols are embedded when vector
+// search is enabled.
+type CodeIndexConfig struct {
+	Watch    bool `mapstructure:"watch"`     // re-index files as they change instead of per query
+	MaxFiles int  `mapstructure:"max_files"` // files indexed per project
+}
+
+// FilesystemConfig is the path policy of the tools that read and write
+// files. A denied path is reported to the model, so it can try another.
+type FilesystemConfig struct {
+	// Allow lists the paths tools may use, e.g. "~/projects" or
+	// "~/Documents/**/*.pdf". Empty allows any path not denied.
+	Allow []string `mapstructure:"allow"`
+	// Deny lists paths no tool may use. Bare names like "*.pem" match
+	// anywhere. The config file is always denied.
+	Deny     []string `mapstructure:"deny"`
+	ReadOnly []string `mapstructure:"read_only"` // folders tools may read but not change
+	// MaxFileSizeMB is the largest file a tool may read or write
+	MaxFileSizeMB int `mapstructure:"max_file_size_mb"`
+}
+
+// ExecConfig sandboxes the commands the system skill runs. Zero limits are
+// unlimited.
+type ExecConfig struct {
+	// Workdir jails commands: they run there and may not name paths outside
+	// it. Empty runs them in the server's directory, as before.
+	Workdir        string `mapstructure:"workdir"`
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"` // the longest a command may run
+	MemoryMB       int    `mapstructure:"memory_mb"`
+	CPUSeconds     int    `mapstructure:"cpu_seconds"`
+	MaxProcesses   int    `mapstructure:"max_processes"` // needs cgroups or a container
+	MaxOutputKB    int    `mapstructure:"max_output_kb"`
+	// Cgroups runs host commands in a transient systemd scope, so memory
+	// and process limits hold for everything a command starts
+	Cgroups bool `mapstructure:"cgroups"`
+	// Backend runs commands no policy rule matches: host or container
+	Backend   string              `mapstructure:"backend"`
+	Container ExecContainerConfig `mapstructure:"container"`
+	// Policy picks the backend by command; the first matching rule wins
+	Policy []ExecRule `mapstructure:"policy"`
+}
+
+// ExecContainerConfig runs commands in a disposable Docker or Podman
+// container
+type ExecContainerConfig struct {
+	Runtime string  `mapstructure:"runtime"` // docker or podman; whichever is installed when empty
+	Image   string  `mapstructure:"image"`
+	Network bool    `mapstructure:"network"` // off by default
+	CPUs    float64 `mapstructure:"cpus"`
+}
+
+// ExecRule sends commands matching a pattern to a backend
+type ExecRule struct {
+	// Match is a pattern for the whole command line, where * matches
+	// anything, e.g. "curl *" or "git status"
+	Match   string `mapstructure:"match"`
+	Backend string `mapstructure:"backend"` // host, container or deny
 }
 
 type SecurityConfig struct {
@@ -91,21 +276,67 @@ type SecurityConfig struct {
 	AdminPassword string   `mapstructure:"admin_password"`
 	AllowOrigins  []string `mapstructure:"allow_origins"`
 	GatewayToken  string   `mapstructure:"gateway_token"`
+	// ContentFilter tunes the word lists of channels with a content filter
+	ContentFilter ContentFilterConfig `mapstructure:"content_filter"`
+}
+
+// ContentFilterConfig adds to or relaxes the built-in family-safe word lists
+type ContentFilterConfig struct {
+	WordList string   `mapstructure:"word_list"` // file of extra words to mask, one per line
+	Block    []string `mapstructure:"block"`     // extra words to mask
+	Allow    []string `mapstructure:"allow"`     // words never filtered
 }
 
 type SkillsConfig struct {
-	GitHub  GitHubSkillConfig  `mapstructure:"github"`
-	Weather WeatherSkillConfig `mapstructure:"weather"`
-	Browser BrowserSkillConfig `mapstructure:"browser"`
-	Brave   BraveSkillConfig   `mapstructure:"brave"`
-	Search  SearchSkillConfig  `mapstructure:"search"`
-	Vision  VisionSkillConfig  `mapstructure:"vision"`
-	Threads ThreadsSkillConfig `mapstructure:"threads"`
-	Daun    DaunSkillConfig    `mapstructure:"daun"`
+	GitHub        GitHubSkillConfig        `mapstructure:"github"`
+	Weather       WeatherSkillConfig       `mapstructure:"weather"`
+	Browser       BrowserSkillConfig       `mapstructure:"browser"`
+	Brave         BraveSkillConfig         `mapstructure:"brave"`
+	Search        SearchSkillConfig        `mapstructure:"search"`
+	Vision        VisionSkillConfig        `mapstructure:"vision"`
+	Documents     DocumentsSkillConfig     `mapstructure:"documents"`
+	ImageGen      ImageGenSkillConfig      `mapstructure:"image_generation"`
+	Voice         VoiceSkillConfig         `mapstructure:"voice"`
+	Threads       ThreadsSkillConfig       `mapstructure:"threads"`
+	Daun          DaunSkillConfig          `mapstructure:"daun"`
+	Email         EmailSkillConfig         `mapstructure:"email"`
+	Cache         SkillCacheConfig         `mapstructure:"cache"`
+	HomeAssistant HomeAssistantSkillConfig `mapstructure:"homeassistant"`
+	Calendar      CalendarSkillConfig      `mapstructure:"calendar"`
+	Notes         NotesSkillConfig         `mapstructure:"notes"`
+	Packages      SkillPackagesConfig      `mapstructure:"packages"`
+	Forges        []ForgeConfig            `mapstructure:"forges"`
+	// Holidays are skipped by schedules that repeat "except holidays":
+	// YYYY-MM-DD for one day, or MM-DD for the same date every year
+	Holidays []string `mapstructure:"holidays"`
+	// Disabled skills stay registered, but their tools aren't offered
+	Disabled []string `mapstructure:"disabled"`
 }
 
 type GitHubSkillConfig struct {
 	Token string `mapstructure:"token"`
+	// WebhookSecret enables POST /api/webhooks/github and checks each
+	// delivery's signature with it
+	WebhookSecret string          `mapstructure:"webhook_secret"`
+	Triggers      []GitHubTrigger `mapstructure:"triggers"`
+}
+
+// GitHubTrigger runs a prompt when a webhook event arrives, such as a new
+// issue (issues.opened) or failed CI (ci.failed)
+type GitHubTrigger struct {
+	Event    string `mapstructure:"event"`    // Event type without the github. prefix; * wildcards allowed
+	Repo     string `mapstructure:"repo"`     // owner/name to limit it to; empty for all
+	Prompt   string `mapstructure:"prompt"`   // {{repo}}, {{number}}, {{title}}, {{url}} and {{summary}} are filled in
+	User     string `mapstructure:"user"`     // User the prompt runs for
+	Cooldown int    `mapstructure:"cooldown"` // Seconds to ignore repeats of the prompt
+}
+
+// ForgeConfig is a GitLab or Gitea server the forge skill works with
+type ForgeConfig struct {
+	Name  string `mapstructure:"name"`  // How the agent refers to it; defaults to the URL's host
+	Type  string `mapstructure:"type"`  // gitlab or gitea
+	URL   string `mapstructure:"url"`   // e.g. https://gitlab.example.com
+	Token string `mapstructure:"token"` // Personal access token
 }
 
 type WeatherSkillConfig struct {
@@ -116,6 +347,7 @@ type BrowserSkillConfig struct {
 	Enabled        bool   `mapstructure:"enabled"`
 	Headless       bool   `mapstructure:"headless"`
 	ExecutablePath string `mapstructure:"executable_path"`
+	IdleMinutes    int    `mapstructure:"idle_minutes"` // Close browsers unused this long; profiles keep their logins
 }
 
 type BraveSkillConfig struct {
@@ -123,11 +355,34 @@ type BraveSkillConfig struct {
 }
 
 type SearchSkillConfig struct {
-	Enabled     bool   `mapstructure:"enabled"`
-	Provider    string `mapstructure:"provider"` // brave, serper, google, duckduckgo
-	APIKey      string `mapstructure:"api_key"`
-	MaxResults  int    `mapstructure:"max_results"`
-	TimeoutSecs int    `mapstructure:"timeout_seconds"`
+	Enabled           bool   `mapstructure:"enabled"`
+	Provider          string `mapstructure:"provider"` // brave, serper, google, duckduckgo
+	APIKey            string `mapstructure:"api_key"`
+	MaxResults        int    `mapstructure:"max_results"`
+	TimeoutSecs       int    `mapstructure:"timeout_seconds"`
+	RequestsPerMinute int    `mapstructure:"requests_per_minute"` // Per provider; cached answers don't count
+}
+
+type DocumentsSkillConfig struct {
+	// OCRLanguages are the tesseract language packs scans are read with when
+	// no language is asked for, e.g. [eng, deu]
+	OCRLanguages []string `mapstructure:"ocr_languages"`
+}
+
+type ImageGenSkillConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	Provider   string `mapstructure:"provider"`    // openai, stability, automatic1111, comfyui
+	APIKey     string `mapstructure:"api_key"`     // OpenAI or Stability; the OpenAI LLM provider's key is used when empty
+	Model      string `mapstructure:"model"`       // e.g. gpt-image-1, dall-e-3; core or ultra for Stability
+	BaseURL    string `mapstructure:"base_url"`    // of a local Automatic1111 or ComfyUI server
+	Workflow   string `mapstructure:"workflow"`    // ComfyUI workflow saved in API format
+	DailyLimit int    `mapstructure:"daily_limit"` // Images per user per day; 0 for no limit
+}
+
+// VoiceSkillConfig lets chats turn on spoken replies with /voice on
+type VoiceSkillConfig struct {
+	Replies       bool `mapstructure:"replies"`         // Allow /voice on; needs piper and ffmpeg
+	MaxReplyChars int  `mapstructure:"max_reply_chars"` // Longer replies are only sent as text
 }
 
 type VisionSkillConfig struct {
@@ -146,6 +401,75 @@ type DaunSkillConfig struct {
 	AccessToken string `mapstructure:"access_token"`
 }
 
+// SkillCacheConfig controls the cache shared by skills that call external
+// APIs, such as weather, search and GitHub
+type SkillCacheConfig struct {
+	Enabled    bool           `mapstructure:"enabled"`
+	MaxEntries int            `mapstructure:"max_entries"`
+	Persist    bool           `mapstructure:"persist"` // Keep entries across restarts
+	TTL        map[string]int `mapstructure:"ttl"`     // Seconds per skill, overriding its default; 0 disables
+}
+
+// EmailSkillConfig configures the mailbox the email skill reads and sends
+// from. Provider is "imap" (IMAP plus SMTP) or "gmail" (Gmail API over OAuth).
+type EmailSkillConfig struct {
+	Enabled          bool   `mapstructure:"enabled"`
+	Provider         string `mapstructure:"provider"`
+	Address          string `mapstructure:"address"` // From address for sent mail
+	IMAPHost         string `mapstructure:"imap_host"`
+	SMTPHost         string `mapstructure:"smtp_host"`
+	Username         string `mapstructure:"username"`
+	Password         string `mapstructure:"password"`
+	Mailbox          string `mapstructure:"mailbox"`
+	ClientID         string `mapstructure:"client_id"`
+	ClientSecret     string `mapstructure:"client_secret"`
+	RedirectURL      string `mapstructure:"redirect_url"`
+	DraftExpiryHours int    `mapstructure:"draft_expiry_hours"`
+	MaxResults       int    `mapstructure:"max_results"`
+}
+
+// HomeAssistantSkillConfig connects to a Home Assistant instance. The
+// assistant may only call services in AllowedDomains, and state changes of
+// the Watch entities are published as events.
+type HomeAssistantSkillConfig struct {
+	Enabled        bool     `mapstructure:"enabled"`
+	URL            string   `mapstructure:"url"`             // e.g. http://homeassistant.local:8123
+	Token          string   `mapstructure:"token"`           // long-lived access token
+	AllowedDomains []string `mapstructure:"allowed_domains"` // e.g. light, switch
+	Watch          []string `mapstructure:"watch"`           // entity IDs; * wildcards allowed
+	TimeoutSecs    int      `mapstructure:"timeout_secs"`
+}
+
+// CalendarSkillConfig configures the calendar skill. Once Google Calendar is
+// connected it syncs both ways every SyncIntervalMinutes while the server
+// runs; 0 leaves syncing to the sync_calendar tool.
+type CalendarSkillConfig struct {
+	Enabled             bool   `mapstructure:"enabled"`
+	DefaultTimezone     string `mapstructure:"default_timezone"`
+	GoogleClientID      string `mapstructure:"google_client_id"`
+	GoogleClientSecret  string `mapstructure:"google_client_secret"`
+	SyncIntervalMinutes int    `mapstructure:"sync_interval_minutes"`
+}
+
+// NotesSkillConfig points the notes skill at a Markdown vault, such as an
+// Obsidian vault. Without a vault, notes live in ~/.myrai/notes.
+type NotesSkillConfig struct {
+	VaultDir string `mapstructure:"vault_dir"`
+	// Watch re-indexes notes as they change on disk while the server runs
+	Watch bool `mapstructure:"watch"`
+}
+
+// SkillPackagesConfig is for skills installed with 'myrai skills install'
+type SkillPackagesConfig struct {
+	// Dir holds the installed packages; ~/.myrai/skills by default
+	Dir string `mapstructure:"dir"`
+	// Registry is the URL of the index skill names are looked up in
+	Registry string `mapstructure:"registry"`
+	// TrustedKeys are base64 ed25519 public keys. When set, only packages
+	// signed by one of them are installed.
+	TrustedKeys []string `mapstructure:"trusted_keys"`
+}
+
 // MCPConfig holds MCP server configuration
 type MCPConfig struct {
 	Enabled bool   `mapstructure:"enabled"`
@@ -163,15 +487,198 @@ type CronConfig struct {
 // VectorConfig holds vector search configuration
 type VectorConfig struct {
 	Enabled        bool   `mapstructure:"enabled"`
-	Provider       string `mapstructure:"provider"` // "local", "openai", "ollama"
-	EmbeddingModel string `mapstructure:"embedding_model"`
-	Dimension      int    `mapstructure:"dimension"`
-	OpenAIAPIKey   string `mapstructure:"openai_api_key"`
+	Provider       string `mapstructure:"provider"`        // "local", "openai", "google", "ollama"
+	EmbeddingModel string `mapstructure:"embedding_model"` // empty for the provider's default
+	Dimension      int    `mapstructure:"dimension"`       // of local embeddings
+	OpenAIAPIKey   string `mapstructure:"openai_api_key"`  // defaults to the openai LLM provider's key
+	GoogleAPIKey   string `mapstructure:"google_api_key"`  // defaults to the google LLM provider's key
 	OllamaHost     string `mapstructure:"ollama_host"`
+	// Backend is where embeddings are searched: "local" (the database),
+	// "qdrant" or "pgvector"
+	Backend  string         `mapstructure:"backend"`
+	Qdrant   QdrantConfig   `mapstructure:"qdrant"`
+	Pgvector PgvectorConfig `mapstructure:"pgvector"`
+}
+
+// QdrantConfig locates a Qdrant server
+type QdrantConfig struct {
+	URL              string `mapstructure:"url"`
+	APIKey           string `mapstructure:"api_key"`
+	CollectionPrefix string `mapstructure:"collection_prefix"`
+}
+
+// PgvectorConfig locates a Postgres database with the pgvector extension
+type PgvectorConfig struct {
+	DSN         string `mapstructure:"dsn"`
+	TablePrefix string `mapstructure:"table_prefix"`
+}
+
+// AgentConfig holds agent behaviour configuration
+type AgentConfig struct {
+	Loop LoopConfig `mapstructure:"loop"`
+	// Personas binds channels to persona profiles, e.g. telegram: terse and
+	// cli: verbose. Keys are cli, api, telegram and discord; channels not
+	// listed use the current persona.
+	Personas    map[string]string `mapstructure:"personas"`
+	Async       AsyncConfig       `mapstructure:"async"`
+	PostProcess PostProcessConfig `mapstructure:"postprocess"`
+	Titles      TitlesConfig      `mapstructure:"titles"`
+	Routing     RoutingConfig     `mapstructure:"routing"`
+	// PinBudget caps the tokens of the items pinned to one conversation;
+	// 0 uses the default of 2000
+	PinBudget int `mapstructure:"pin_budget"`
+}
+
+// RoutingConfig sends simple turns, such as a short factual question, to a
+// fast and cheap model and everything else to the smart one. Both are models
+// of the default provider.
+type RoutingConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	FastModel  string `mapstructure:"fast_model"`  // Required when enabled
+	SmartModel string `mapstructure:"smart_model"` // Empty uses the provider's model
+	// MaxFastChars is the longest message the fast model may answer
+	MaxFastChars int `mapstructure:"max_fast_chars"`
+	// StickyTurns keeps a conversation on the smart model for this many
+	// turns after one needed it, so a task isn't handed back mid-way
+	StickyTurns int `mapstructure:"sticky_turns"`
+}
+
+// TitlesConfig names conversations from their first exchange and renames
+// them when the topic moves on. Titles set by the user are kept.
+type TitlesConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Model writes the titles; empty uses the default provider's model.
+	// A small, cheap model of the same provider does the job.
+	Model string `mapstructure:"model"`
+	// RetitleAfter is how many messages pass before checking whether the
+	// conversation has changed topic; zero never re-titles
+	RetitleAfter int `mapstructure:"retitle_after"`
+}
+
+// PostProcessConfig is the chain a reply runs through before delivery.
+// Steps run in order: redact_secrets, profanity, replace and split.
+type PostProcessConfig struct {
+	Steps        []string            `mapstructure:"steps"`
+	MaxLength    int                 `mapstructure:"max_length"` // Characters per message for split; zero takes the channel's limit
+	Replacements []ReplacementConfig `mapstructure:"replacements"`
+	// Channels overrides the chain for cli, api, telegram or discord
+	Channels map[string]PostProcessOverride `mapstructure:"channels"`
+}
+
+// PostProcessOverride changes the reply chain for one channel. Steps and
+// max_length replace the defaults; replacements add to them.
+type PostProcessOverride struct {
+	Steps        []string            `mapstructure:"steps"`
+	MaxLength    int                 `mapstructure:"max_length"`
+	Replacements []ReplacementConfig `mapstructure:"replacements"`
+}
+
+// ReplacementConfig rewrites every match of a regular expression in replies.
+// Replace may refer to groups as $1 or ${name}.
+type ReplacementConfig struct {
+	Pattern string `mapstructure:"pattern"`
+	Replace string `mapstructure:"replace"`
+}
+
+// AsyncConfig bounds the background workers that run jobs the agent queues
+// with ask_async. Zero values take the defaults.
+type AsyncConfig struct {
+	Workers        int `mapstructure:"workers"`         // Jobs running at the same time
+	TimeoutMinutes int `mapstructure:"timeout_minutes"` // Wall-clock limit per job
+	MaxAttempts    int `mapstructure:"max_attempts"`    // Runs before a job restarts keep interrupting is given up
+}
+
+// LoopConfig bounds the agent's tool-use loop. Zero budgets mean unlimited.
+type LoopConfig struct {
+	MaxIterations      int     `mapstructure:"max_iterations"`       // LLM round-trips per request
+	TimeoutSeconds     int     `mapstructure:"timeout_seconds"`      // Wall-clock limit per request
+	MaxTokens          int     `mapstructure:"max_tokens"`           // Token budget per request
+	MaxCost            float64 `mapstructure:"max_cost"`             // Cost budget per request (needs price_per_1k)
+	PricePer1K         float64 `mapstructure:"price_per_1k"`         // Price per 1,000 tokens used for cost tracking
+	StopOnToolError    bool    `mapstructure:"stop_on_tool_error"`   // Answer instead of continuing after a failed tool
+	RequireFinalAnswer bool    `mapstructure:"require_final_answer"` // Validate the final answer before returning it
+	MaxParallelTools   int     `mapstructure:"max_parallel_tools"`   // Tool calls from one reply run at the same time
+	ToolTimeoutSeconds int     `mapstructure:"tool_timeout_seconds"` // Limit per tool call
+}
+
+// HouseholdConfig controls how household profiles share skill data
+type HouseholdConfig struct {
+	Shared []string `mapstructure:"shared"` // Skills whose data every profile shares
+}
+
+// JournalConfig controls the activity journal's evening summary
+type JournalConfig struct {
+	EveningSummary string `mapstructure:"evening_summary"` // HH:MM, or "off"
+}
+
+// PrivacyConfig limits how long conversations are kept. Zero keeps them
+// forever.
+type PrivacyConfig struct {
+	// RetentionDays deletes conversations with no messages in this many days
+	RetentionDays int `mapstructure:"retention_days"`
+	// RedactAfterDays replaces older message bodies with "[redacted]",
+	// keeping token counts for usage stats
+	RedactAfterDays int `mapstructure:"redact_after_days"`
+}
+
+// LocationConfig controls location check-ins. Even when enabled, nothing is
+// stored for a user until they turn on location sharing.
+type LocationConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	RetentionDays int  `mapstructure:"retention_days"` // Default days to keep check-ins
+	MaxPoints     int  `mapstructure:"max_points"`     // Check-ins kept per user
+}
+
+// MQTTConfig connects to an MQTT broker so device and service messages reach
+// the event bus
+type MQTTConfig struct {
+	Enabled       bool               `mapstructure:"enabled"`
+	Broker        string             `mapstructure:"broker"` // e.g. tcp://localhost:1883
+	ClientID      string             `mapstructure:"client_id"`
+	Username      string             `mapstructure:"username"`
+	Password      string             `mapstructure:"password"`
+	Subscriptions []MQTTSubscription `mapstructure:"subscriptions"`
+	PublishTopics []string           `mapstructure:"publish_topics"` // Topic filters the publish tool may write to
+}
+
+// MQTTSubscription turns messages on a topic into events, and optionally
+// runs a prompt for each one
+type MQTTSubscription struct {
+	Topic    string `mapstructure:"topic"`    // Topic filter; + and # wildcards allowed
+	Event    string `mapstructure:"event"`    // Published as mqtt.<event>
+	QoS      int    `mapstructure:"qos"`      // 0 or 1
+	Prompt   string `mapstructure:"prompt"`   // Run for each message; {{topic}} and {{payload}} are filled in
+	User     string `mapstructure:"user"`     // User the event and prompt belong to
+	Cooldown int    `mapstructure:"cooldown"` // Seconds to ignore repeats of the prompt
 }
 
 // Load loads configuration from file, env, and defaults
+// Load reads the configuration from configPath, or the default location
+// when empty. Problems that don't stop it working, like unknown keys, are
+// printed as warnings; the rest are returned as a *ValidationError.
 func Load(configPath, dataDir string) (*Config, error) {
+	cfg, issues, err := load(configPath, dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []Issue
+	for _, issue := range issues {
+		if issue.Warning {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", issue)
+		} else {
+			errs = append(errs, issue)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, &ValidationError{Issues: errs}
+	}
+	return cfg, nil
+}
+
+// load reads the configuration and returns the issues found in it. A config
+// that reads but fails overall validation is returned with the error.
+func load(configPath, dataDir string) (*Config, []Issue, error) {
 	if err := LoadEnvFiles(); err != nil {
 		// Log but don't fail - .env files are optional
 		fmt.Fprintf(os.Stderr, "Warning: error loading .env files: %v\n", err)
@@ -188,7 +695,7 @@ func Load(configPath, dataDir string) (*Config, error) {
 	dataDir = expandPath(dataDir)
 
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create data directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
 	v.Set("storage.data_dir", dataDir)
@@ -200,8 +707,9 @@ func Load(configPath, dataDir string) (*Config, error) {
 		configDir := getDefaultConfigDir()
 		configPath = filepath.Join(configDir, "myrai.yaml")
 
-		// Check new location first, then fall back to old location for backwards compatibility
-		if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		// Check new location first, then fall back to old location for
+		// backwards compatibility. Profiles are newer than both.
+		if _, err := os.Stat(configPath); os.IsNotExist(err) && ActiveProfile() == "" {
 			// Try old location in data directory
 			oldConfigPath := filepath.Join(dataDir, "myrai.yaml")
 			if _, err := os.Stat(oldConfigPath); err == nil {
@@ -221,16 +729,36 @@ func Load(configPath, dataDir string) (*Config, error) {
 		// Ensure config directory exists
 		configDir = filepath.Dir(configPath)
 		if err := os.MkdirAll(configDir, 0755); err != nil {
-			return nil, fmt.Errorf("failed to create config directory: %w", err)
+			return nil, nil, fmt.Errorf("failed to create config directory: %w", err)
 		}
 	}
 
 	configPath = expandPath(configPath)
 
+	// Secrets go into the environment first, so placeholders and the env
+	// overrides below find them
+	loadSecrets(configPath)
+
+	// YAML and JSON files are checked key by key and may use ${VAR}
+	// placeholders; other formats are read as they are
+	var check *fileCheck
 	if _, err := os.Stat(configPath); err == nil {
-		v.SetConfigFile(configPath)
-		if err := v.ReadInConfig(); err != nil {
-			return nil, fmt.Errorf("failed to read config: %w", err)
+		switch strings.ToLower(filepath.Ext(configPath)) {
+		case ".yaml", ".yml", ".json":
+			data, c, err := readConfigFile(configPath)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read config: %w", err)
+			}
+			check = c
+			v.SetConfigType("yaml")
+			if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+				return nil, nil, fmt.Errorf("failed to read config: %w", err)
+			}
+		default:
+			v.SetConfigFile(configPath)
+			if err := v.ReadInConfig(); err != nil {
+				return nil, nil, fmt.Errorf("failed to read config: %w", err)
+			}
 		}
 	}
 
@@ -250,17 +778,37 @@ func Load(configPath, dataDir string) (*Config, error) {
 
 	var cfg Config
 	if err := v.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		return nil, nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	cfg.Security.ContentFilter.WordList = expandPath(cfg.Security.ContentFilter.WordList)
+	cfg.Server.Tailscale.StateDir = expandPath(cfg.Server.Tailscale.StateDir)
+	cfg.Skills.Notes.VaultDir = expandPath(cfg.Skills.Notes.VaultDir)
+	cfg.Skills.Packages.Dir = expandPath(cfg.Skills.Packages.Dir)
+	cfg.Storage.Backup.Dir = expandPath(cfg.Storage.Backup.Dir)
+	cfg.Tools.Exec.Workdir = expandPath(cfg.Tools.Exec.Workdir)
+	for _, paths := range [][]string{cfg.Tools.Filesystem.Allow, cfg.Tools.Filesystem.Deny, cfg.Tools.Filesystem.ReadOnly} {
+		for i := range paths {
+			paths[i] = expandPath(paths[i])
+		}
 	}
+	cfg.Secrets = resolveSecrets(configPath, cfg.Secrets)
+	cfg.File = configPath
 
 	loadEnvOverrides(&cfg)
 	loadStandardEnvVars(&cfg)
 
+	var issues []Issue
+	if check != nil {
+		issues = check.issues
+	}
+	issues = append(issues, checkRequired(&cfg, check)...)
+	sortIssues(issues)
+
 	if err := validate(&cfg); err != nil {
-		return nil, err
+		return &cfg, issues, err
 	}
 
-	return &cfg, nil
+	return &cfg, issues, nil
 }
 
 func expandPath(path string) string {
@@ -376,6 +924,36 @@ func loadStandardEnvVars(cfg *Config) {
 	if model := os.Getenv("MYRAI_SKILLS_VISION_VISION_MODEL"); model != "" {
 		cfg.Skills.Vision.VisionModel = model
 	}
+
+	if password := ResolveEnvWithAliases("MYRAI_SKILLS_EMAIL_PASSWORD"); password != "" {
+		cfg.Skills.Email.Password = password
+	}
+	if secret := ResolveEnvWithAliases("MYRAI_SKILLS_EMAIL_CLIENT_SECRET"); secret != "" {
+		cfg.Skills.Email.ClientSecret = secret
+	}
+
+	if token := ResolveEnvWithAliases("MYRAI_SKILLS_HOMEASSISTANT_TOKEN"); token != "" {
+		cfg.Skills.HomeAssistant.Token = token
+	}
+
+	if secret := ResolveEnvWithAliases("MYRAI_SKILLS_CALENDAR_GOOGLE_CLIENT_SECRET"); secret != "" {
+		cfg.Skills.Calendar.GoogleClientSecret = secret
+	}
+
+	if key := ResolveEnvWithAliases("MYRAI_VECTOR_QDRANT_API_KEY"); key != "" {
+		cfg.Vector.Qdrant.APIKey = key
+	}
+	if dsn := ResolveEnvWithAliases("MYRAI_VECTOR_PGVECTOR_DSN"); dsn != "" {
+		cfg.Vector.Pgvector.DSN = dsn
+	}
+
+	// Embeddings use the LLM providers' keys unless given their own
+	if cfg.Vector.OpenAIAPIKey == "" {
+		cfg.Vector.OpenAIAPIKey = cfg.LLM.Providers["openai"].APIKey
+	}
+	if cfg.Vector.GoogleAPIKey == "" {
+		cfg.Vector.GoogleAPIKey = cfg.LLM.Providers["google"].APIKey
+	}
 }
 
 func loadProviderFromEnv(cfg *Config, name, envKey, defaultBaseURL, defaultModel string) {
@@ -409,6 +987,8 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.port", 8080)
 	v.SetDefault("server.read_timeout", 30)
 	v.SetDefault("server.write_timeout", 30)
+	v.SetDefault("server.log_level", "info")
+	v.SetDefault("server.tailscale.hostname", "myrai")
 
 	// LLM defaults
 	v.SetDefault("llm.default_provider", "kimi")
@@ -416,10 +996,34 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("llm.providers.kimi.model", "kimi-k2.5")
 	v.SetDefault("llm.providers.kimi.timeout", 120)
 	v.SetDefault("llm.providers.kimi.max_tokens", 4096)
+	v.SetDefault("llm.offline.provider", "ollama")
+	v.SetDefault("llm.offline.check_interval", 30)
+	v.SetDefault("llm.offline.network_skills", []string{"search", "weather", "browser", "github", "forge", "email", "imagegen", "threads", "daun"})
+
+	// Storage defaults
+	v.SetDefault("storage.encryption.enabled", false)
+	v.SetDefault("storage.encryption.key_source", "passphrase")
+	v.SetDefault("storage.backup.interval_hours", 0)
+	v.SetDefault("storage.backup.keep", 7)
 
 	// Tools defaults
 	v.SetDefault("tools.enabled", []string{"read_file", "write_file", "list_dir", "exec_command", "web_search"})
 	v.SetDefault("tools.sandbox", true)
+	v.SetDefault("tools.exec.timeout_seconds", 300)
+	v.SetDefault("tools.exec.max_output_kb", 256)
+	v.SetDefault("tools.exec.backend", "host")
+	v.SetDefault("tools.exec.container.image", "alpine:3.20")
+	v.SetDefault("tools.coding.index.watch", true)
+	v.SetDefault("tools.coding.index.max_files", 20000)
+	v.SetDefault("tools.filesystem.deny", []string{"~/.ssh", "~/.gnupg", "~/.aws", "*.pem", ".env"})
+	v.SetDefault("tools.filesystem.max_file_size_mb", 50)
+
+	// Channel defaults
+	for _, channel := range []string{"telegram", "discord"} {
+		v.SetDefault("channels."+channel+".group_mode", GroupModeMention)
+		v.SetDefault("channels."+channel+".inbound.messages_per_minute", 20)
+		v.SetDefault("channels."+channel+".inbound.cooldown_seconds", 60)
+	}
 
 	// Security defaults
 	v.SetDefault("security.allow_origins", []string{"*"})
@@ -434,22 +1038,68 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("cron.interval_minutes", 1)
 	v.SetDefault("cron.max_concurrent", 3)
 
+	// Agent loop defaults
+	v.SetDefault("agent.loop.max_iterations", 10)
+	v.SetDefault("agent.loop.timeout_seconds", 300)
+	v.SetDefault("agent.loop.max_parallel_tools", 4)
+	v.SetDefault("agent.loop.tool_timeout_seconds", 120)
+	v.SetDefault("agent.async.workers", 2)
+	v.SetDefault("agent.postprocess.steps", []string{"redact_secrets", "replace", "split"})
+	v.SetDefault("agent.titles.enabled", true)
+	v.SetDefault("agent.titles.retitle_after", 20)
+	v.SetDefault("agent.routing.max_fast_chars", 280)
+	v.SetDefault("agent.routing.sticky_turns", 3)
+	v.SetDefault("agent.async.timeout_minutes", 30)
+	v.SetDefault("agent.async.max_attempts", 3)
+
+	// Household defaults
+	v.SetDefault("household.shared", []string{"shopping", "calendar"})
+
+	// Journal defaults
+	v.SetDefault("journal.evening_summary", "20:00")
+
+	// Location defaults
+	v.SetDefault("location.enabled", false)
+	v.SetDefault("location.retention_days", 7)
+	v.SetDefault("location.max_points", 500)
+
+	// MQTT defaults
+	v.SetDefault("mqtt.enabled", false)
+	v.SetDefault("mqtt.broker", "tcp://localhost:1883")
+	v.SetDefault("mqtt.client_id", "myrai")
+
 	// Vector defaults
 	v.SetDefault("vector.enabled", false)
 	v.SetDefault("vector.provider", "local")
-	v.SetDefault("vector.embedding_model", "all-MiniLM-L6-v2")
 	v.SetDefault("vector.dimension", 384)
 	v.SetDefault("vector.ollama_host", "http://localhost:11434")
+	v.SetDefault("vector.backend", "local")
+	v.SetDefault("vector.qdrant.url", "http://localhost:6333")
+	v.SetDefault("vector.qdrant.collection_prefix", "myrai_")
+	v.SetDefault("vector.pgvector.table_prefix", "myrai_")
 
 	// Search defaults
 	v.SetDefault("skills.search.enabled", true)
-	v.SetDefault("skills.search.provider", "duckduckgo")
+	// No provider picks the one the API key is for, or DuckDuckGo without one
+	v.SetDefault("skills.search.provider", "")
 	v.SetDefault("skills.search.max_results", 5)
 	v.SetDefault("skills.search.timeout_seconds", 30)
+	v.SetDefault("skills.search.requests_per_minute", 30)
 
 	// Browser defaults
 	v.SetDefault("skills.browser.enabled", true)
 	v.SetDefault("skills.browser.headless", true)
+	v.SetDefault("skills.browser.idle_minutes", 15)
+
+	// Image generation defaults
+	v.SetDefault("skills.image_generation.enabled", false)
+	v.SetDefault("skills.image_generation.provider", "openai")
+	v.SetDefault("skills.image_generation.daily_limit", 20)
+	v.SetDefault("skills.voice.replies", false)
+	v.SetDefault("skills.voice.max_reply_chars", 1500)
+
+	// Documents defaults
+	v.SetDefault("skills.documents.ocr_languages", []string{"eng"})
 
 	// Vision defaults
 	v.SetDefault("vision.enabled", true)
@@ -459,9 +1109,37 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("skills.threads.enabled", false)
 	v.SetDefault("skills.threads.timeout_seconds", 30)
 	v.SetDefault("skills.threads.max_text_length", 500)
+
+	// Skill cache defaults
+	v.SetDefault("skills.cache.enabled", true)
+	v.SetDefault("skills.cache.max_entries", 1000)
+	v.SetDefault("skills.cache.persist", false)
+
+	// Email defaults
+	v.SetDefault("skills.email.enabled", false)
+	v.SetDefault("skills.email.provider", "imap")
+	v.SetDefault("skills.email.mailbox", "INBOX")
+	v.SetDefault("skills.email.draft_expiry_hours", 24)
+	v.SetDefault("skills.email.max_results", 10)
+
+	// Home Assistant defaults
+	v.SetDefault("skills.homeassistant.enabled", false)
+	v.SetDefault("skills.homeassistant.allowed_domains", []string{"light", "switch", "fan", "scene", "media_player", "climate", "input_boolean"})
+	v.SetDefault("skills.homeassistant.timeout_secs", 10)
+
+	// Calendar defaults
+	v.SetDefault("skills.calendar.enabled", true)
+	v.SetDefault("skills.calendar.sync_interval_minutes", 15)
+
+	// Notes defaults
+	v.SetDefault("skills.notes.watch", true)
 }
 
 func getDefaultDataDir() string {
+	if profile := ActiveProfile(); profile != "" {
+		return filepath.Join(ProfileDir(profile), "data")
+	}
+
 	// Try XDG_DATA_HOME first
 	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
 		return filepath.Join(xdg, "myrai")
@@ -476,8 +1154,17 @@ func getDefaultDataDir() string {
 	return filepath.Join(home, ".local", "share", "myrai")
 }
 
-// getDefaultConfigDir returns the default config directory following XDG spec
+// getDefaultConfigDir returns the default config directory following XDG
+// spec, or the active profile's directory
 func getDefaultConfigDir() string {
+	if profile := ActiveProfile(); profile != "" {
+		return ProfileDir(profile)
+	}
+	return baseConfigDir()
+}
+
+// baseConfigDir is the config directory of the default instance
+func baseConfigDir() string {
 	// Try XDG_CONFIG_HOME first
 	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
 		return filepath.Join(xdg, "myrai")
@@ -516,6 +1203,9 @@ func loadEnvOverrides(cfg *Config) {
 	}
 
 	cfg.Server.Address = GetEnvDefault("MYRAI_SERVER_ADDRESS", cfg.Server.Address)
+	if key := ResolveEnvWithAliases("MYRAI_SERVER_TAILSCALE_AUTH_KEY"); key != "" {
+		cfg.Server.Tailscale.AuthKey = key
+	}
 	if port := os.Getenv("MYRAI_SERVER_PORT"); port != "" {
 		if p, err := strconv.Atoi(port); err == nil {
 			cfg.Server.Port = p
@@ -558,10 +1248,12 @@ func validate(cfg *Config) error {
 
 	if cfg.Security.JWTSecret == "" {
 		cfg.Security.JWTSecret = generateRandomString(32)
+		cfg.Generated = append(cfg.Generated, "security.jwt_secret")
 	}
 
 	if cfg.Security.GatewayToken == "" {
 		cfg.Security.GatewayToken = generateRandomString(32)
+		cfg.Generated = append(cfg.Generated, "security.gateway_token")
 	}
 
 	return nil
@@ -13,10 +13,29 @@ type EnvVar struct {
 }
 
 func LoadEnvFiles() error {
+	for _, path := range EnvFilePaths() {
+		if _, err := os.Stat(path); err == nil {
+			if err := loadEnvFile(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// EnvFilePaths returns the .env files LoadEnvFiles reads, in order of
+// preference
+func EnvFilePaths() []string {
 	envPaths := []string{
 		"./.env",
 	}
 
+	// A profile only sees its own secrets
+	if profile := ActiveProfile(); profile != "" {
+		return append(envPaths, filepath.Join(ProfileDir(profile), ".env"))
+	}
+
 	if home, err := os.UserHomeDir(); err == nil {
 		// Check multiple locations in order of preference
 		envPaths = append(envPaths,
@@ -29,15 +48,7 @@ func LoadEnvFiles() error {
 		)
 	}
 
-	for _, path := range envPaths {
-		if _, err := os.Stat(path); err == nil {
-			if err := loadEnvFile(path); err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
+	return envPaths
 }
 
 func loadEnvFile(path string) error {
@@ -108,6 +119,8 @@ var envAliases = map[string][]string{
 	"MYRAI_SKILLS_DAUN_ACCESS_TOKEN":         {"DAUN_ACCESS_TOKEN", "DAUN_API_KEY"},
 	"MYRAI_SECURITY_JWT_SECRET":              {"MYRAI_JWT_SECRET"},
 	"MYRAI_SECURITY_ADMIN_PASSWORD":          {"MYRAI_ADMIN_PASSWORD"},
+	"MYRAI_SERVER_TAILSCALE_AUTH_KEY":        {"TS_AUTHKEY"},
+	"MYRAI_VECTOR_QDRANT_API_KEY":            {"QDRANT_API_KEY"},
 }
 
 func ResolveEnvWithAliases(canonicalKey string) string {
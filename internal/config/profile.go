@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// ProfileEnv names the profile to use. A profile is a separate instance
+// with its own config, secrets, data and workspace, e.g. one assistant for
+// home and one for work.
+const ProfileEnv = "MYRAI_PROFILE"
+
+var profileNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,63}$`)
+
+// ActiveProfile is the profile selected with --profile or MYRAI_PROFILE,
+// or "" for the default instance
+func ActiveProfile() string {
+	return os.Getenv(ProfileEnv)
+}
+
+// ValidateProfileName checks a profile name is safe to use as a directory
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, - and _", name)
+	}
+	if name == "default" {
+		return fmt.Errorf("%q is the instance without a profile", name)
+	}
+	return nil
+}
+
+// ProfilesDir holds one directory per profile
+func ProfilesDir() string {
+	return filepath.Join(baseConfigDir(), "profiles")
+}
+
+// ProfileDir is where a profile keeps myrai.yaml and .env; its data and
+// workspace are in the data directory inside it
+func ProfileDir(name string) string {
+	return filepath.Join(ProfilesDir(), name)
+}
+
+// ProfileExists reports whether the profile has been created
+func ProfileExists(name string) bool {
+	info, err := os.Stat(ProfileDir(name))
+	return err == nil && info.IsDir()
+}
+
+// ListProfiles returns the names of the created profiles
+func ListProfiles() ([]string, error) {
+	entries, err := os.ReadDir(ProfilesDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && ValidateProfileName(entry.Name()) == nil {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// CreateProfile makes the directories for a new profile
+func CreateProfile(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if ProfileExists(name) {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	return os.MkdirAll(filepath.Join(ProfileDir(name), "data"), 0700)
+}
+
+// DeleteProfile removes a profile with its config, secrets and data
+func DeleteProfile(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if !ProfileExists(name) {
+		return fmt.Errorf("profile %q doesn't exist", name)
+	}
+	return os.RemoveAll(ProfileDir(name))
+}
+
+// DefaultConfigDir is the config directory of the active profile
+func DefaultConfigDir() string {
+	return getDefaultConfigDir()
+}
+
+// DefaultDataDir is the data directory of the active profile
+func DefaultDataDir() string {
+	return getDefaultDataDir()
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+	t.Setenv(ProfileEnv, "")
+
+	if got := getDefaultConfigDir(); got != filepath.Join(home, "config", "myrai") {
+		t.Errorf("Expected the default config dir without a profile, got %s", got)
+	}
+	for _, name := range []string{"", "default", "../x", "a b", ".hidden"} {
+		if err := CreateProfile(name); err == nil {
+			t.Errorf("Expected %q to be refused", name)
+		}
+	}
+
+	if err := CreateProfile("work"); err != nil {
+		t.Fatalf("CreateProfile failed: %v", err)
+	}
+	if err := CreateProfile("work"); err == nil {
+		t.Error("Expected creating an existing profile to fail")
+	}
+	CreateProfile("home")
+	if names, _ := ListProfiles(); strings.Join(names, ",") != "home,work" {
+		t.Errorf("Expected home and work, got %v", names)
+	}
+
+	t.Setenv(ProfileEnv, "work")
+	workDir := filepath.Join(home, "config", "myrai", "profiles", "work")
+	if got := DefaultConfigDir(); got != workDir {
+		t.Errorf("Expected the profile's config dir, got %s", got)
+	}
+	if got := DefaultDataDir(); got != filepath.Join(workDir, "data") {
+		t.Errorf("Expected the profile's data dir, got %s", got)
+	}
+	paths := EnvFilePaths()
+	if last := paths[len(paths)-1]; last != filepath.Join(workDir, ".env") {
+		t.Errorf("Expected the profile's .env, got %v", paths)
+	}
+	for _, path := range paths {
+		if strings.Contains(path, filepath.Join(".config", "myrai", ".env")) {
+			t.Errorf("Expected the default instance's .env to be skipped, got %v", paths)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(workDir, "myrai.yaml"), []byte("server:\n  port: 8181\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load("", "")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.File != filepath.Join(workDir, "myrai.yaml") || cfg.Server.Port != 8181 || cfg.Storage.DataDir != filepath.Join(workDir, "data") {
+		t.Errorf("Expected the work profile loaded, got %s on port %d with data in %s", cfg.File, cfg.Server.Port, cfg.Storage.DataDir)
+	}
+
+	if err := DeleteProfile("work"); err != nil {
+		t.Fatalf("DeleteProfile failed: %v", err)
+	}
+	if ProfileExists("work") {
+		t.Error("Expected the profile removed")
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gmsas95/myrai-cli/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+// SecretsConfig selects where API keys and tokens are kept. Whatever the
+// backend, a variable set in the environment or a .env file wins.
+type SecretsConfig struct {
+	Backend  string `mapstructure:"backend" yaml:"backend"`   // env (default), keychain or age
+	File     string `mapstructure:"file" yaml:"file"`         // age: the encrypted file, secrets.age next to the config by default
+	Identity string `mapstructure:"identity" yaml:"identity"` // age: key file; without one MYRAI_SECRETS_PASSPHRASE is the passphrase
+}
+
+// SecretsOptions returns how to open the secrets store configured in the
+// file at configPath. The secrets section is read before anything else, so
+// it can't use ${VAR} placeholders; MYRAI_SECRETS_BACKEND, _FILE and
+// _IDENTITY override it.
+func SecretsOptions(configPath string) secrets.Options {
+	var file struct {
+		Secrets SecretsConfig `yaml:"secrets"`
+	}
+	if data, err := os.ReadFile(configPath); err == nil {
+		// A broken file is reported when the config itself is read
+		_ = yaml.Unmarshal(data, &file)
+	}
+	sc := resolveSecrets(configPath, file.Secrets)
+	return secrets.Options{Backend: sc.Backend, File: sc.File, Identity: sc.Identity}
+}
+
+// resolveSecrets applies the environment overrides and defaults to sc
+func resolveSecrets(configPath string, sc SecretsConfig) SecretsConfig {
+	if v := os.Getenv("MYRAI_SECRETS_BACKEND"); v != "" {
+		sc.Backend = v
+	}
+	if v := os.Getenv("MYRAI_SECRETS_FILE"); v != "" {
+		sc.File = v
+	}
+	if v := os.Getenv("MYRAI_SECRETS_IDENTITY"); v != "" {
+		sc.Identity = v
+	}
+	if sc.Backend == "" {
+		sc.Backend = secrets.BackendEnv
+	}
+	if sc.File == "" {
+		sc.File = filepath.Join(filepath.Dir(configPath), "secrets.age")
+	}
+	sc.File = expandPath(sc.File)
+	sc.Identity = expandPath(sc.Identity)
+	return sc
+}
+
+// loadSecrets exports the secrets in the configured store into the
+// environment, where the config and skills look for them. A store that
+// can't be opened is only warned about: the environment may have all that's
+// needed.
+func loadSecrets(configPath string) {
+	opts := SecretsOptions(configPath)
+	if opts.Backend == secrets.BackendEnv {
+		return
+	}
+	store, err := secrets.Open(opts)
+	if err == nil {
+		err = secrets.Export(store)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load secrets from %s: %v\n", opts.Backend, err)
+	}
+}
@@ -0,0 +1,521 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Issue is a problem found in the configuration. Warnings are reported but
+// the config still loads; anything else stops Load.
+type Issue struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+	Warning bool   `json:"warning,omitempty"`
+}
+
+// String formats the issue as file:line: key: message
+func (i Issue) String() string {
+	var sb strings.Builder
+	if i.File != "" {
+		sb.WriteString(i.File)
+		if i.Line > 0 {
+			fmt.Fprintf(&sb, ":%d", i.Line)
+		}
+		sb.WriteString(": ")
+	}
+	if i.Key != "" {
+		sb.WriteString(i.Key + ": ")
+	}
+	sb.WriteString(i.Message)
+	return sb.String()
+}
+
+// ValidationError is returned by Load when the configuration has errors
+type ValidationError struct {
+	Issues []Issue
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		lines[i] = issue.String()
+	}
+	return "invalid config:\n  " + strings.Join(lines, "\n  ")
+}
+
+// Validate loads the configuration like Load and returns every issue found,
+// warnings included. The error is only for a file that can't be read at all.
+func Validate(configPath, dataDir string) ([]Issue, error) {
+	cfg, issues, err := load(configPath, dataDir)
+	if err != nil {
+		if cfg == nil {
+			return issues, err
+		}
+		// The file was read, but the config is unusable as a whole
+		issues = append(issues, Issue{File: cfg.File, Key: "llm", Message: err.Error()})
+	}
+	return issues, nil
+}
+
+// placeholder matches ${VAR} and ${VAR:-default} in config values
+var placeholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// fileCheck holds what was learned reading a config file: where each key is,
+// and the issues found on the way
+type fileCheck struct {
+	file   string
+	lines  map[string]int // key path -> line
+	issues []Issue
+}
+
+// readConfigFile parses a YAML (or JSON) config file, warning about keys
+// Config doesn't have and resolving environment variable placeholders. It
+// returns the file as YAML for viper to read.
+func readConfigFile(path string) ([]byte, *fileCheck, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	check := &fileCheck{file: path, lines: make(map[string]int)}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 {
+		return data, check, nil
+	}
+	check.walk(doc.Content[0], reflect.TypeOf(Config{}), "")
+
+	out, err := yaml.Marshal(doc.Content[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, check, nil
+}
+
+// walk checks node against the type it decodes into, recording the line of
+// each key and resolving placeholders in values
+func (c *fileCheck) walk(node *yaml.Node, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		c.expand(node, path)
+		return
+	case yaml.SequenceNode:
+		elem := reflect.TypeOf((*interface{})(nil)).Elem()
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			elem = t.Elem()
+		}
+		for i, item := range node.Content {
+			c.walk(item, elem, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return
+	case yaml.MappingNode:
+	default:
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "<<" {
+			c.walk(value, t, path)
+			continue
+		}
+		name := strings.ToLower(key.Value)
+		keyPath := name
+		if path != "" {
+			keyPath = path + "." + name
+		}
+		c.lines[keyPath] = key.Line
+
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByKey(t, name)
+			if !ok {
+				c.issues = append(c.issues, Issue{File: c.file, Line: key.Line, Key: keyPath, Message: "unknown key, it is ignored", Warning: true})
+				continue
+			}
+			c.walk(value, field.Type, keyPath)
+		case reflect.Map:
+			c.walk(value, t.Elem(), keyPath)
+		default:
+			c.walk(value, reflect.TypeOf((*interface{})(nil)).Elem(), keyPath)
+		}
+	}
+}
+
+// expand resolves placeholders in a scalar, warning about variables that
+// aren't set
+func (c *fileCheck) expand(node *yaml.Node, path string) {
+	if !strings.Contains(node.Value, "${") {
+		return
+	}
+	node.Value = placeholder.ReplaceAllStringFunc(node.Value, func(match string) string {
+		m := placeholder.FindStringSubmatch(match)
+		if value, ok := os.LookupEnv(m[1]); ok {
+			return value
+		}
+		if strings.Contains(match, ":-") {
+			return m[2]
+		}
+		c.issues = append(c.issues, Issue{File: c.file, Line: node.Line, Key: path,
+			Message: fmt.Sprintf("environment variable %s is not set", m[1]), Warning: true})
+		return ""
+	})
+	// The value may now need quoting, or look like another type; viper
+	// converts strings to the field's type anyway
+	node.Tag = "!!str"
+	node.Style = 0
+}
+
+// fieldByKey returns the field of struct t with mapstructure key name
+func fieldByKey(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if tag == "-" || field.PkgPath != "" {
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		if strings.EqualFold(tag, name) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// checkRequired reports settings an enabled feature can't work without. Keys
+// point at the line of the setting if it's in the file, or else the feature.
+func checkRequired(cfg *Config, check *fileCheck) []Issue {
+	var issues []Issue
+	require := func(enabled string, missing bool, key, hint string) {
+		if !missing {
+			return
+		}
+		issue := Issue{Key: key, Message: fmt.Sprintf("required when %s is true", enabled)}
+		if hint != "" {
+			issue.Message += " (" + hint + ")"
+		}
+		if check != nil {
+			issue.File = check.file
+			issue.Line = check.line(key, enabled)
+		}
+		issues = append(issues, issue)
+	}
+
+	telegram := cfg.Channels.Telegram
+	require("channels.telegram.enabled", telegram.Enabled && telegram.BotToken == "",
+		"channels.telegram.bot_token", "set TELEGRAM_BOT_TOKEN in the environment or .env")
+	discord := cfg.Channels.Discord
+	require("channels.discord.enabled", discord.Enabled && discord.Token == "",
+		"channels.discord.token", "set DISCORD_BOT_TOKEN in the environment or .env")
+
+	email := cfg.Skills.Email
+	if email.Enabled {
+		switch email.Provider {
+		case "gmail":
+			require("skills.email.enabled", email.ClientID == "", "skills.email.client_id", "for gmail")
+			require("skills.email.enabled", email.ClientSecret == "", "skills.email.client_secret", "for gmail")
+		case "", "imap":
+			require("skills.email.enabled", email.IMAPHost == "", "skills.email.imap_host", "")
+			require("skills.email.enabled", email.SMTPHost == "", "skills.email.smtp_host", "")
+			require("skills.email.enabled", email.Username == "", "skills.email.username", "")
+			require("skills.email.enabled", email.Password == "", "skills.email.password", "")
+		default:
+			issue := Issue{Key: "skills.email.provider", Message: fmt.Sprintf("unknown provider %q, use imap or gmail", email.Provider)}
+			if check != nil {
+				issue.File, issue.Line = check.file, check.line("skills.email.provider")
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	ha := cfg.Skills.HomeAssistant
+	require("skills.homeassistant.enabled", ha.Enabled && ha.URL == "", "skills.homeassistant.url", "")
+	require("skills.homeassistant.enabled", ha.Enabled && ha.Token == "", "skills.homeassistant.token", "")
+
+	require("mqtt.enabled", cfg.MQTT.Enabled && cfg.MQTT.Broker == "", "mqtt.broker", "")
+
+	for i, forge := range cfg.Skills.Forges {
+		var key, message string
+		switch {
+		case forge.Type != "gitlab" && forge.Type != "gitea":
+			key, message = fmt.Sprintf("skills.forges[%d].type", i), fmt.Sprintf("unknown type %q, use gitlab or gitea", forge.Type)
+		case forge.URL == "":
+			key, message = fmt.Sprintf("skills.forges[%d].url", i), "required"
+		default:
+			continue
+		}
+		issue := Issue{Key: key, Message: message}
+		if check != nil {
+			issue.File, issue.Line = check.file, check.line(key, fmt.Sprintf("skills.forges[%d]", i))
+		}
+		issues = append(issues, issue)
+	}
+
+	enc := cfg.Storage.Encryption
+	if enc.Enabled {
+		switch enc.KeySource {
+		case "", "passphrase":
+			require("storage.encryption.enabled", os.Getenv("MYRAI_STORAGE_PASSPHRASE") == "",
+				"MYRAI_STORAGE_PASSPHRASE", "set it in the environment or with 'myrai secret set'")
+		case "keychain":
+		default:
+			issue := Issue{Key: "storage.encryption.key_source", Message: fmt.Sprintf("unknown key source %q, use passphrase or keychain", enc.KeySource)}
+			if check != nil {
+				issue.File, issue.Line = check.file, check.line("storage.encryption.key_source")
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	switch strings.ToLower(cfg.Server.LogLevel) {
+	case "", "debug", "info", "warn", "error":
+	default:
+		issue := Issue{Key: "server.log_level", Message: fmt.Sprintf("unknown level %q, use debug, info, warn or error", cfg.Server.LogLevel)}
+		if check != nil {
+			issue.File, issue.Line = check.file, check.line("server.log_level")
+		}
+		issues = append(issues, issue)
+	}
+
+	switch cfg.Tools.Coding.Approval {
+	case "", "code", "none":
+	default:
+		issue := Issue{Key: "tools.coding.approval", Message: fmt.Sprintf("unknown approval %q, use code or none", cfg.Tools.Coding.Approval)}
+		if check != nil {
+			issue.File, issue.Line = check.file, check.line("tools.coding.approval")
+		}
+		issues = append(issues, issue)
+	}
+
+	switch cfg.Tools.Exec.Backend {
+	case "", "host", "container":
+	default:
+		issue := Issue{Key: "tools.exec.backend", Message: fmt.Sprintf("unknown backend %q, use host or container", cfg.Tools.Exec.Backend)}
+		if check != nil {
+			issue.File, issue.Line = check.file, check.line("tools.exec.backend")
+		}
+		issues = append(issues, issue)
+	}
+	for i, rule := range cfg.Tools.Exec.Policy {
+		switch rule.Backend {
+		case "host", "container", "deny":
+			continue
+		}
+		key := fmt.Sprintf("tools.exec.policy[%d].backend", i)
+		issue := Issue{Key: key, Message: fmt.Sprintf("unknown backend %q, use host, container or deny", rule.Backend)}
+		if check != nil {
+			issue.File, issue.Line = check.file, check.line(key, fmt.Sprintf("tools.exec.policy[%d]", i))
+		}
+		issues = append(issues, issue)
+	}
+
+	fs := map[string][]string{
+		"tools.filesystem.allow":     cfg.Tools.Filesystem.Allow,
+		"tools.filesystem.deny":      cfg.Tools.Filesystem.Deny,
+		"tools.filesystem.read_only": cfg.Tools.Filesystem.ReadOnly,
+	}
+	for _, key := range []string{"tools.filesystem.allow", "tools.filesystem.deny", "tools.filesystem.read_only"} {
+		for _, path := range fs[key] {
+			if strings.TrimSpace(path) != "" {
+				continue
+			}
+			issue := Issue{Key: key, Message: "empty path; remove it or name a path"}
+			if check != nil {
+				issue.File, issue.Line = check.file, check.line(key)
+			}
+			issues = append(issues, issue)
+			break
+		}
+	}
+
+	channels := make([]string, 0, len(cfg.Agent.Personas))
+	for channel := range cfg.Agent.Personas {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	for _, channel := range channels {
+		switch channel {
+		case "cli", "api", "telegram", "discord":
+			continue
+		}
+		key := "agent.personas." + channel
+		issue := Issue{Key: key, Message: "unknown channel; use cli, api, telegram or discord", Warning: true}
+		if check != nil {
+			issue.File, issue.Line = check.file, check.line(key)
+		}
+		issues = append(issues, issue)
+	}
+
+	for _, c := range []struct{ channel, mode string }{
+		{"telegram", cfg.Channels.Telegram.GroupMode},
+		{"discord", cfg.Channels.Discord.GroupMode},
+	} {
+		switch c.mode {
+		case "", GroupModeMention, GroupModeAll, GroupModeOff:
+			continue
+		}
+		key := "channels." + c.channel + ".group_mode"
+		issue := Issue{Key: key, Message: fmt.Sprintf("unknown group mode %q; use mention, all or off", c.mode)}
+		if check != nil {
+			issue.File, issue.Line = check.file, check.line(key)
+		}
+		issues = append(issues, issue)
+	}
+
+	if offline := cfg.LLM.Offline; offline.Enabled {
+		key := "llm.offline.provider"
+		var issue *Issue
+		if _, ok := cfg.LLM.Providers[offline.Provider]; !ok {
+			issue = &Issue{Key: key, Message: fmt.Sprintf("provider %q not found in llm.providers", offline.Provider)}
+		} else if offline.Provider == cfg.LLM.DefaultProvider {
+			issue = &Issue{Key: key, Message: "same as the default provider, so there is nothing to fall back to", Warning: true}
+		}
+		if issue != nil {
+			if check != nil {
+				issue.File, issue.Line = check.file, check.line(key)
+			}
+			issues = append(issues, *issue)
+		}
+	}
+
+	if cfg.Agent.Routing.Enabled && cfg.Agent.Routing.FastModel == "" {
+		key := "agent.routing.fast_model"
+		issue := Issue{Key: key, Message: "required when routing is enabled"}
+		if check != nil {
+			issue.File, issue.Line = check.file, check.line(key)
+		}
+		issues = append(issues, issue)
+	}
+
+	pp := cfg.Agent.PostProcess
+	issues = append(issues, checkPostProcess("agent.postprocess", pp.Steps, pp.Replacements, check)...)
+	channels = channels[:0]
+	for channel := range pp.Channels {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	for _, channel := range channels {
+		key := "agent.postprocess.channels." + channel
+		switch channel {
+		case "cli", "api", "telegram", "discord":
+		default:
+			issue := Issue{Key: key, Message: "unknown channel; use cli, api, telegram or discord", Warning: true}
+			if check != nil {
+				issue.File, issue.Line = check.file, check.line(key)
+			}
+			issues = append(issues, issue)
+		}
+		override := pp.Channels[channel]
+		issues = append(issues, checkPostProcess(key, override.Steps, override.Replacements, check)...)
+	}
+
+	return issues
+}
+
+// checkPostProcess reports unknown reply post-processing steps and
+// replacement patterns that don't compile
+func checkPostProcess(key string, steps []string, replacements []ReplacementConfig, check *fileCheck) []Issue {
+	var issues []Issue
+	add := func(key, message string) {
+		issue := Issue{Key: key, Message: message}
+		if check != nil {
+			issue.File, issue.Line = check.file, check.line(key)
+		}
+		issues = append(issues, issue)
+	}
+	for _, step := range steps {
+		switch strings.ToLower(strings.TrimSpace(step)) {
+		case "redact_secrets", "profanity", "replace", "split":
+		default:
+			add(key+".steps", fmt.Sprintf("unknown step %q; use redact_secrets, profanity, replace or split", step))
+		}
+	}
+	for i, r := range replacements {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			add(fmt.Sprintf("%s.replacements[%d].pattern", key, i), fmt.Sprintf("invalid pattern: %v", err))
+		}
+	}
+	return issues
+}
+
+// line returns the line of the first of keys found in the file
+func (c *fileCheck) line(keys ...string) int {
+	for _, key := range keys {
+		if line, ok := c.lines[key]; ok {
+			return line
+		}
+	}
+	return 0
+}
+
+// Schema returns a JSON schema of the configuration file, for editors that
+// complete and check YAML against one
+func Schema() map[string]interface{} {
+	schema := typeSchema(reflect.TypeOf(Config{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "Myrai configuration"
+	return schema
+}
+
+// typeSchema describes the values t decodes from
+func typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		props := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+			if tag == "-" || tag == "" || field.PkgPath != "" {
+				continue
+			}
+			props[tag] = typeSchema(field.Type)
+		}
+		return map[string]interface{}{"type": "object", "properties": props, "additionalProperties": false}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{}
+}
+
+// sortIssues orders issues by file position, errors first on the same line
+func sortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return !issues[i].Warning && issues[j].Warning
+	})
+}
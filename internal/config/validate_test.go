@@ -0,0 +1,187 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "myrai.yaml")
+	content := `server:
+  port: ${TEST_MYRAI_PORT}
+  log_level: verbose
+  colour: blue
+llm:
+  default_provider: test
+  providers:
+    test:
+      api_key: "${TEST_MYRAI_KEY}"
+      base_url: ${TEST_MYRAI_UNSET:-http://localhost:11434/v1}
+      modle: llama3
+channels:
+  telegram:
+    enabled: true
+skills:
+  github:
+    token: ${TEST_MYRAI_UNSET}
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_MYRAI_PORT", "9090")
+	t.Setenv("TEST_MYRAI_KEY", "sk: #secret")
+	t.Setenv("TELEGRAM_BOT_TOKEN", "")
+	t.Setenv("MYRAI_CHANNELS_TELEGRAM_BOT_TOKEN", "")
+
+	issues, err := Validate(file, dir)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	var got []string
+	for _, issue := range issues {
+		got = append(got, strings.TrimPrefix(issue.String(), file+":"))
+	}
+	want := []string{
+		"3: server.log_level: unknown level \"verbose\", use debug, info, warn or error",
+		"4: server.colour: unknown key, it is ignored",
+		"11: llm.providers.test.modle: unknown key, it is ignored",
+		"14: channels.telegram.bot_token: required when channels.telegram.enabled is true (set TELEGRAM_BOT_TOKEN in the environment or .env)",
+		"17: skills.github.token: environment variable TEST_MYRAI_UNSET is not set",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Errors stop Load; the placeholders resolve once they're fixed
+	if _, err := Load(file, dir); err == nil || !strings.Contains(err.Error(), "server.log_level") {
+		t.Errorf("Expected Load to fail on the log level, got %v", err)
+	}
+	fixed := strings.Replace(strings.Replace(content, "verbose", "warn", 1), "enabled: true", "enabled: false", 1)
+	if err := os.WriteFile(file, []byte(fixed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(file, dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	provider := cfg.LLM.Providers["test"]
+	if cfg.Server.Port != 9090 || provider.APIKey != "sk: #secret" || provider.BaseURL != "http://localhost:11434/v1" {
+		t.Errorf("Expected the placeholders resolved, got port %d and %+v", cfg.Server.Port, provider)
+	}
+}
+
+func TestCheckRequired_ExecBackends(t *testing.T) {
+	cfg := &Config{}
+	cfg.Tools.Exec.Backend = "vm"
+	cfg.Tools.Exec.Policy = []ExecRule{{Match: "ls", Backend: "host"}, {Match: "curl *", Backend: "jail"}}
+
+	var got []string
+	for _, issue := range checkRequired(cfg, nil) {
+		got = append(got, issue.Key)
+	}
+	want := []string{"tools.exec.backend", "tools.exec.policy[1].backend"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected issues for %v, got %v", want, got)
+	}
+}
+
+func TestCheckRequired_FilesystemPaths(t *testing.T) {
+	cfg := &Config{}
+	cfg.Tools.Filesystem.Allow = []string{"/srv/projects", ""}
+	cfg.Tools.Filesystem.Deny = []string{"*.pem"}
+
+	issues := checkRequired(cfg, nil)
+	if len(issues) != 1 || issues[0].Key != "tools.filesystem.allow" {
+		t.Errorf("Expected an issue for the empty allowed path, got %v", issues)
+	}
+}
+
+func TestCheckRequired_ChannelPersonas(t *testing.T) {
+	cfg := &Config{}
+	cfg.Agent.Personas = map[string]string{"telegram": "terse", "cli": "verbose", "irc": "work"}
+
+	issues := checkRequired(cfg, nil)
+	if len(issues) != 1 || issues[0].Key != "agent.personas.irc" || !issues[0].Warning {
+		t.Errorf("Expected a warning for the unknown channel, got %v", issues)
+	}
+}
+
+func TestCheckRequired_PostProcess(t *testing.T) {
+	cfg := &Config{}
+	cfg.Agent.PostProcess = PostProcessConfig{
+		Steps:        []string{"redact_secrets", "shout"},
+		Replacements: []ReplacementConfig{{Pattern: "ok"}, {Pattern: "(unclosed"}},
+		Channels: map[string]PostProcessOverride{
+			"telegram": {MaxLength: 1000},
+			"irc":      {},
+		},
+	}
+
+	var got []string
+	for _, issue := range checkRequired(cfg, nil) {
+		got = append(got, issue.Key)
+	}
+	want := []string{"agent.postprocess.steps", "agent.postprocess.replacements[1].pattern", "agent.postprocess.channels.irc"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected issues for %v, got %v", want, got)
+	}
+}
+
+func TestCheckRequired_Routing(t *testing.T) {
+	cfg := &Config{}
+	cfg.Agent.Routing = RoutingConfig{Enabled: true, SmartModel: "gpt-4o"}
+	issues := checkRequired(cfg, nil)
+	if len(issues) != 1 || issues[0].Key != "agent.routing.fast_model" || issues[0].Warning {
+		t.Errorf("Expected an error for the missing fast model, got %+v", issues)
+	}
+
+	cfg.Agent.Routing.FastModel = "gpt-4o-mini"
+	if issues := checkRequired(cfg, nil); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %+v", issues)
+	}
+}
+
+func TestCheckRequired_Offline(t *testing.T) {
+	cfg := &Config{}
+	cfg.LLM = LLMConfig{
+		DefaultProvider: "openai",
+		Providers:       map[string]Provider{"openai": {}},
+		Offline:         OfflineConfig{Enabled: true, Provider: "ollama"},
+	}
+	issues := checkRequired(cfg, nil)
+	if len(issues) != 1 || issues[0].Key != "llm.offline.provider" || issues[0].Warning {
+		t.Errorf("Expected an error for the missing local provider, got %+v", issues)
+	}
+
+	cfg.LLM.Offline.Provider = "openai"
+	if issues := checkRequired(cfg, nil); len(issues) != 1 || !issues[0].Warning {
+		t.Errorf("Expected a warning for falling back to the default provider, got %+v", issues)
+	}
+
+	cfg.LLM.Providers["ollama"] = Provider{BaseURL: "http://localhost:11434/v1"}
+	cfg.LLM.Offline.Provider = "ollama"
+	if issues := checkRequired(cfg, nil); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %+v", issues)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	schema := Schema()
+	props := schema["properties"].(map[string]interface{})
+	if _, ok := props["file"]; ok {
+		t.Error("Expected fields that aren't read from the file to be left out")
+	}
+
+	server := props["server"].(map[string]interface{})
+	port := server["properties"].(map[string]interface{})["port"].(map[string]interface{})
+	if server["additionalProperties"] != false || port["type"] != "integer" {
+		t.Errorf("Unexpected server schema %v", server)
+	}
+	providers := props["llm"].(map[string]interface{})["properties"].(map[string]interface{})["providers"].(map[string]interface{})
+	if provider := providers["additionalProperties"].(map[string]interface{}); provider["type"] != "object" {
+		t.Errorf("Expected providers to map names to provider objects, got %v", providers)
+	}
+}
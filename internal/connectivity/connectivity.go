@@ -0,0 +1,156 @@
+// Package connectivity tells whether the network is up. A Monitor probes a
+// URL now and then, and at once when a request fails to get through, so the
+// assistant can switch to a local model while offline and back when the
+// connection returns.
+package connectivity
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Probe limits
+const (
+	DefaultInterval = 30 * time.Second
+	probeTimeout    = 5 * time.Second
+)
+
+// Monitor tracks whether its URL can be reached. Any HTTP response counts
+// as online; only failing to connect counts as offline. A nil *Monitor is
+// valid and always online.
+type Monitor struct {
+	url      string
+	interval time.Duration
+	client   *http.Client
+	logger   *zap.Logger
+
+	offline atomic.Bool
+	probing sync.Mutex
+
+	mu       sync.Mutex
+	onChange []func(online bool)
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewMonitor returns a monitor probing url every interval, DefaultInterval
+// if it is zero. It assumes the network is up until a probe says otherwise.
+func NewMonitor(url string, interval time.Duration, logger *zap.Logger) *Monitor {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Monitor{
+		url:      url,
+		interval: interval,
+		client:   &http.Client{Timeout: probeTimeout},
+		logger:   logger,
+	}
+}
+
+// Online reports whether the network was up at the last probe
+func (m *Monitor) Online() bool {
+	return m == nil || !m.offline.Load()
+}
+
+// OnChange calls fn whenever the network goes down or comes back
+func (m *Monitor) OnChange(fn func(online bool)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onChange = append(m.onChange, fn)
+}
+
+// Check probes the network now and returns whether it is up. Concurrent
+// checks share one probe.
+func (m *Monitor) Check(ctx context.Context) bool {
+	if m == nil {
+		return true
+	}
+	if !m.probing.TryLock() {
+		// Another check is probing; wait for its answer
+		m.probing.Lock()
+		m.probing.Unlock()
+		return m.Online()
+	}
+	defer m.probing.Unlock()
+
+	online := m.probe(ctx)
+	if ctx.Err() != nil {
+		return m.Online() // cancelled, which says nothing about the network
+	}
+	if m.offline.Swap(!online) == online {
+		m.changed(online)
+	}
+	return online
+}
+
+// ReportFailure tells the monitor a request failed to get through, and
+// checks the network at once rather than at the next probe
+func (m *Monitor) ReportFailure(err error) {
+	if m == nil {
+		return
+	}
+	m.logger.Debug("Request failed to get through, checking the network", zap.Error(err))
+	m.Check(context.Background())
+}
+
+// Start probes the network in the background until Stop
+func (m *Monitor) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.Check(ctx)
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.Check(ctx)
+			}
+		}
+	}()
+}
+
+// Stop ends the background probes
+func (m *Monitor) Stop() {
+	if m != nil && m.cancel != nil {
+		m.cancel()
+		m.wg.Wait()
+	}
+}
+
+func (m *Monitor) probe(ctx context.Context) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, m.url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return true
+}
+
+func (m *Monitor) changed(online bool) {
+	if online {
+		m.logger.Info("Network is back; leaving offline mode", zap.String("url", m.url))
+	} else {
+		m.logger.Warn("Network is unreachable; switching to offline mode", zap.String("url", m.url))
+	}
+	m.mu.Lock()
+	callbacks := append([]func(bool){}, m.onChange...)
+	m.mu.Unlock()
+	for _, fn := range callbacks {
+		fn(online)
+	}
+}
@@ -0,0 +1,44 @@
+package connectivity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestMonitor_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized) // any answer means the network is up
+	}))
+	m := NewMonitor(server.URL, 0, zap.NewNop())
+
+	var changes []bool
+	m.OnChange(func(online bool) { changes = append(changes, online) })
+
+	assert.True(t, m.Check(context.Background()))
+	assert.True(t, m.Online())
+	assert.Empty(t, changes, "staying online is no change")
+
+	server.Close()
+	m.ReportFailure(assert.AnError)
+	assert.False(t, m.Online())
+	assert.Equal(t, []bool{false}, changes)
+
+	back := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer back.Close()
+	m.url = back.URL
+	assert.True(t, m.Check(context.Background()))
+	assert.Equal(t, []bool{false, true}, changes)
+}
+
+func TestMonitor_Nil(t *testing.T) {
+	var m *Monitor
+	assert.True(t, m.Online())
+	assert.True(t, m.Check(context.Background()))
+	m.ReportFailure(assert.AnError)
+	m.Stop()
+}
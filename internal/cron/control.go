@@ -0,0 +1,135 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"go.uber.org/zap"
+)
+
+// ErrTaskNotFound is returned for a background task that was never added
+var ErrTaskNotFound = errors.New("task not found")
+
+// TaskInfo describes a background task, for the admin endpoints
+type TaskInfo struct {
+	Name      string     `json:"name"`
+	Interval  string     `json:"interval"`
+	Enabled   bool       `json:"enabled"`
+	Running   bool       `json:"running"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"` // nil until the first check
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// Tasks lists the background tasks in the order they were added
+func (r *Runner) Tasks() []TaskInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]TaskInfo, 0, len(r.tasks))
+	for _, t := range r.tasks {
+		info := TaskInfo{
+			Name:     t.name,
+			Interval: t.interval.String(),
+			Enabled:  !t.disabled,
+			Running:  t.busy,
+		}
+		if !t.next.IsZero() {
+			next := t.next
+			info.NextRunAt = &next
+		}
+		if !t.lastRun.IsZero() {
+			last := t.lastRun
+			info.LastRunAt = &last
+		}
+		if t.lastErr != nil {
+			info.LastError = t.lastErr.Error()
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// RunTask runs the named task now, even if it is disabled, and starts its
+// interval over. A task that is still running isn't started again.
+func (r *Runner) RunTask(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := r.findTask(name)
+	if t == nil {
+		return ErrTaskNotFound
+	}
+	if t.busy {
+		return fmt.Errorf("task %s is already running", name)
+	}
+	r.logger.Info("Running background task on request", zap.String("task", name))
+	r.startTask(t, time.Now())
+	return nil
+}
+
+// SetTaskEnabled stops the named task from running on its interval, or lets
+// it run again. A run already going is left to finish.
+func (r *Runner) SetTaskEnabled(name string, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := r.findTask(name)
+	if t == nil {
+		return ErrTaskNotFound
+	}
+	t.disabled = !enabled
+	return nil
+}
+
+// findTask returns the named task, nil if there is none; r.mu must be held
+func (r *Runner) findTask(name string) *task {
+	for _, t := range r.tasks {
+		if t.name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// RunJob runs a scheduled job now, in the background, even if it is
+// disabled. Its next run is counted from now.
+func (r *Runner) RunJob(jobID string) (*store.ScheduledJob, error) {
+	job, err := r.store.GetJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	r.logger.Info("Running scheduled job on request", zap.String("job_id", job.ID), zap.String("name", job.Name))
+
+	run := *job
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.executeJob(&run)
+	}()
+	return job, nil
+}
+
+// SetJobActive disables a scheduled job, or enables it again. An enabled
+// job is next due a full interval from now, rather than at once for the
+// runs it missed.
+func (r *Runner) SetJobActive(jobID string, active bool) (*store.ScheduledJob, error) {
+	job, err := r.store.GetJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if active && !job.IsActive {
+		next, err := r.calculateNextRun(job.CronExpression, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression: %w", err)
+		}
+		job.NextRunAt = &next
+	}
+	job.IsActive = active
+	if err := r.store.UpdateJob(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
@@ -0,0 +1,88 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"go.uber.org/zap"
+)
+
+func TestRunner_TaskControl(t *testing.T) {
+	r := NewRunner(Config{}, nil, nil, zap.NewNop())
+	defer r.Stop()
+
+	ran := make(chan struct{}, 1)
+	r.AddTask("backup", time.Hour, func(ctx context.Context) error {
+		ran <- struct{}{}
+		return errors.New("disk full")
+	})
+
+	if err := r.SetTaskEnabled("backup", false); err != nil {
+		t.Fatalf("Expected the task to be disabled, got %v", err)
+	}
+	r.runDueTasks()
+	select {
+	case <-ran:
+		t.Fatal("Expected a disabled task not to run when due")
+	default:
+	}
+
+	if err := r.RunTask("backup"); err != nil {
+		t.Fatalf("Expected the task to run on request, got %v", err)
+	}
+	<-ran
+	r.wg.Wait()
+
+	tasks := r.Tasks()
+	if len(tasks) != 1 {
+		t.Fatalf("Expected 1 task, got %d", len(tasks))
+	}
+	task := tasks[0]
+	if task.Enabled || task.NextRunAt == nil || task.LastRunAt == nil || task.LastError != "disk full" {
+		t.Errorf("Expected a disabled task with its run recorded, got %+v", task)
+	}
+
+	if err := r.RunTask("missing"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+	if err := r.SetTaskEnabled("missing", true); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestRunner_SetJobActive(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	t.Cleanup(func() { st.Close() })
+	r := NewRunner(Config{}, nil, st, zap.NewNop())
+
+	job, err := r.AddJob("digest", "1h", "Summarize my day")
+	if err != nil {
+		t.Fatalf("Failed to add job: %v", err)
+	}
+
+	job, err = r.SetJobActive(job.ID, false)
+	if err != nil || job.IsActive {
+		t.Fatalf("Expected the job to be disabled, got %+v, %v", job, err)
+	}
+
+	// Re-enabled, it waits an interval instead of catching up at once
+	past := time.Now().Add(-24 * time.Hour)
+	job.NextRunAt = &past
+	if err := st.UpdateJob(job); err != nil {
+		t.Fatalf("Failed to update job: %v", err)
+	}
+	job, err = r.SetJobActive(job.ID, true)
+	if err != nil || !job.IsActive {
+		t.Fatalf("Expected the job to be enabled, got %+v, %v", job, err)
+	}
+	if !job.NextRunAt.After(time.Now()) {
+		t.Errorf("Expected the next run in the future, got %v", job.NextRunAt)
+	}
+	due, err := st.GetDueJobs(10)
+	if err != nil || len(due) != 0 {
+		t.Errorf("Expected no due jobs, got %d, %v", len(due), err)
+	}
+}
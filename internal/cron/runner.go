@@ -8,6 +8,10 @@ import (
 	"time"
 
 	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/journal"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"github.com/gmsas95/myrai-cli/internal/recurrence"
 	"github.com/gmsas95/myrai-cli/internal/store"
 	"go.uber.org/zap"
 )
@@ -24,11 +28,29 @@ type Runner struct {
 	agent     *agent.Agent
 	store     *store.Store
 	logger    *zap.Logger
+	notifier  *notify.Dispatcher
+	journal   *journal.Journal
 	ctx       context.Context
 	cancel    context.CancelFunc
 	wg        sync.WaitGroup
 	running   bool
 	mu        sync.RWMutex
+	tasks     []*task
+	// reconfigured wakes the loop when the check interval changes
+	reconfigured chan struct{}
+}
+
+// task is a function the runner calls on its own interval, next to the
+// scheduled agent jobs
+type task struct {
+	name     string
+	interval time.Duration
+	fn       func(ctx context.Context) error
+	next     time.Time
+	busy     bool
+	disabled bool
+	lastRun  time.Time
+	lastErr  error
 }
 
 // NewRunner creates a new cron runner
@@ -50,9 +72,65 @@ func NewRunner(config Config, agentInstance *agent.Agent, st *store.Store, logge
 		logger: logger,
 		ctx:    ctx,
 		cancel: cancel,
+		reconfigured: make(chan struct{}, 1),
 	}
 }
 
+// Reconfigure changes the check interval and concurrency of a running
+// runner. Zero values keep the defaults, as in NewRunner.
+func (r *Runner) Reconfigure(config Config) {
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = 1
+	}
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = 3
+	}
+
+	r.mu.Lock()
+	changed := config.CheckInterval != r.config.CheckInterval
+	r.config = config
+	r.mu.Unlock()
+
+	if changed {
+		select {
+		case r.reconfigured <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// SetNotifier sends job results to the user through d
+func (r *Runner) SetNotifier(d *notify.Dispatcher) {
+	r.notifier = d
+}
+
+// SetJournal records each job run in j
+func (r *Runner) SetJournal(j *journal.Journal) {
+	r.journal = j
+}
+
+// AddTask runs fn every interval, first on the next check. A run that is
+// still going when the task is due again is not overlapped.
+func (r *Runner) AddTask(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tasks = append(r.tasks, &task{name: name, interval: interval, fn: fn})
+}
+
+// RemoveTask stops running the named task, reporting whether it was added.
+// A run already going is left to finish.
+func (r *Runner) RemoveTask(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, t := range r.tasks {
+		if t.name == name {
+			r.tasks = append(r.tasks[:i], r.tasks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 // Start starts the cron runner
 func (r *Runner) Start() error {
 	r.mu.Lock()
@@ -95,22 +173,71 @@ func (r *Runner) IsRunning() bool {
 func (r *Runner) run() {
 	defer r.wg.Done()
 
-	ticker := time.NewTicker(time.Duration(r.config.CheckInterval) * time.Minute)
+	ticker := time.NewTicker(r.checkInterval())
 	defer ticker.Stop()
 
 	// Check immediately on start
+	r.runDueTasks()
 	r.checkAndRunJobs()
 
 	for {
 		select {
 		case <-r.ctx.Done():
 			return
+		case <-r.reconfigured:
+			ticker.Reset(r.checkInterval())
 		case <-ticker.C:
+			r.runDueTasks()
 			r.checkAndRunJobs()
 		}
 	}
 }
 
+// checkInterval returns the time between checks
+func (r *Runner) checkInterval() time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return time.Duration(r.config.CheckInterval) * time.Minute
+}
+
+// runDueTasks starts the tasks whose interval has passed
+func (r *Runner) runDueTasks() {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tasks {
+		if t.busy || t.disabled || now.Before(t.next) {
+			continue
+		}
+		r.startTask(t, now)
+	}
+}
+
+// startTask runs t in the background; r.mu must be held
+func (r *Runner) startTask(t *task, now time.Time) {
+	t.busy = true
+	t.next = now.Add(t.interval)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		start := time.Now()
+		err := t.fn(r.ctx)
+
+		r.mu.Lock()
+		t.busy = false
+		t.lastRun, t.lastErr = start, err
+		r.mu.Unlock()
+
+		if err != nil {
+			r.logger.Warn("Background task failed", zap.String("task", t.name), zap.Error(err))
+			return
+		}
+		r.logger.Debug("Background task finished", zap.String("task", t.name), zap.Duration("took", time.Since(start)))
+	}()
+}
+
 // checkAndRunJobs checks for due jobs and executes them
 func (r *Runner) checkAndRunJobs() {
 	jobs, err := r.store.GetDueJobs(50)
@@ -126,7 +253,10 @@ func (r *Runner) checkAndRunJobs() {
 	r.logger.Info("Found scheduled jobs to run", zap.Int("count", len(jobs)))
 
 	// Execute jobs with semaphore for concurrency control
-	sem := make(chan struct{}, r.config.MaxConcurrent)
+	r.mu.RLock()
+	maxConcurrent := r.config.MaxConcurrent
+	r.mu.RUnlock()
+	sem := make(chan struct{}, maxConcurrent)
 	var wg sync.WaitGroup
 
 	for _, job := range jobs {
@@ -186,16 +316,34 @@ func (r *Runner) executeJob(job *store.ScheduledJob) {
 		Stream:       false,
 	})
 
+	summary := fmt.Sprintf("Ran scheduled job %q", job.Name)
 	if err != nil {
 		r.logger.Error("Job execution failed",
 			zap.String("job_id", job.ID),
 			zap.Error(err),
 		)
+		r.journal.Record(household.SharedUserID, journal.KindScheduled, summary, job.Prompt, err)
 	} else {
+		r.journal.Record(household.SharedUserID, journal.KindScheduled, summary, resp.Content, nil)
+
 		r.logger.Info("Job completed",
 			zap.String("job_id", job.ID),
 			zap.Int("tokens_used", resp.TokensUsed),
 		)
+
+		if r.notifier != nil && resp.Content != "" {
+			if _, err := r.notifier.Send(ctx, notify.Notification{
+				UserID:   household.SharedUserID,
+				Category: "scheduled",
+				Title:    job.Name,
+				Body:     resp.Content,
+			}); err != nil {
+				r.logger.Warn("Failed to send job result",
+					zap.String("job_id", job.ID),
+					zap.Error(err),
+				)
+			}
+		}
 	}
 }
 
@@ -225,6 +373,15 @@ func (r *Runner) calculateNextRun(cronExpr string, from time.Time) (time.Time, e
 		return from.Add(duration), nil
 	}
 
+	// Recurrence rules, as RRULE or in words like "every weekday at 9am"
+	if rule, err := recurrence.ParseAny(cronExpr); err == nil {
+		next, ok := rule.Next(from, from)
+		if !ok {
+			return from, fmt.Errorf("schedule has no more runs: %s", cronExpr)
+		}
+		return next, nil
+	}
+
 	// Fallback: use simple cron parsing
 	return r.parseCronExpression(cronExpr, from)
 }
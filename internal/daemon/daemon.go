@@ -0,0 +1,159 @@
+// Package daemon runs the gateway in the background, tracked by a pidfile,
+// and installs it as a systemd or launchd service
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotRunning is returned when no gateway is running
+var ErrNotRunning = errors.New("the gateway is not running")
+
+// PidFile returns where the running gateway records its process ID
+func PidFile(dataDir string) string {
+	return filepath.Join(dataDir, "myrai.pid")
+}
+
+// LogFile returns where a gateway started in the background writes its
+// output
+func LogFile(dataDir string) string {
+	return filepath.Join(dataDir, "logs", "gateway.log")
+}
+
+// Running reports the process ID recorded in pidFile while that process is
+// alive. A pidfile left by a gateway that died is removed.
+func Running(pidFile string) (int, bool) {
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 || !alive(pid) {
+		os.Remove(pidFile)
+		return 0, false
+	}
+	return pid, true
+}
+
+// Since returns when the gateway recorded in pidFile started
+func Since(pidFile string) time.Time {
+	info, err := os.Stat(pidFile)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// Acquire records the current process in pidFile, failing while another
+// gateway is running. release removes the pidfile again.
+func Acquire(pidFile string) (release func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(pidFile), 0755); err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		f, err := os.OpenFile(pidFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			if err != nil {
+				os.Remove(pidFile)
+				return nil, err
+			}
+			pid := os.Getpid()
+			return func() {
+				// Only remove the pidfile while it's still ours
+				if running, ok := Running(pidFile); ok && running == pid {
+					os.Remove(pidFile)
+				}
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) || attempt > 0 {
+			return nil, err
+		}
+		if pid, ok := Running(pidFile); ok {
+			return nil, fmt.Errorf("the gateway is already running (pid %d)", pid)
+		}
+		// Running removed the stale pidfile; try once more
+	}
+}
+
+// Start runs the current executable with args in the background, detached
+// from the terminal and with its output appended to logFile, and waits until
+// it has recorded itself in pidFile
+func Start(pidFile, logFile string, args []string, wait time.Duration) (int, error) {
+	if pid, ok := Running(pidFile); ok {
+		return pid, fmt.Errorf("the gateway is already running (pid %d)", pid)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(logFile), 0755); err != nil {
+		return 0, err
+	}
+	logOut, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer logOut.Close()
+
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = logOut
+	cmd.Stderr = logOut
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	deadline := time.After(wait)
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		select {
+		case err := <-exited:
+			return 0, fmt.Errorf("the gateway exited during startup (%v); see %s", err, logFile)
+		case <-deadline:
+			return cmd.Process.Pid, fmt.Errorf("the gateway didn't finish starting within %s; see %s", wait, logFile)
+		case <-tick.C:
+			if pid, ok := Running(pidFile); ok && pid == cmd.Process.Pid {
+				return pid, nil
+			}
+		}
+	}
+}
+
+// Stop asks the gateway recorded in pidFile to shut down and waits for it
+// to exit
+func Stop(pidFile string, timeout time.Duration) (int, error) {
+	pid, ok := Running(pidFile)
+	if !ok {
+		return 0, ErrNotRunning
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return pid, err
+	}
+	if err := terminate(p); err != nil {
+		return pid, fmt.Errorf("failed to signal pid %d: %w", pid, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !alive(pid) {
+			// A gateway that was killed leaves its pidfile behind
+			Running(pidFile)
+			return pid, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return pid, fmt.Errorf("pid %d still running after %s", pid, timeout)
+}
@@ -0,0 +1,124 @@
+package daemon
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "myrai.pid")
+
+	release, err := Acquire(pidFile)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if pid, ok := Running(pidFile); !ok || pid != os.Getpid() {
+		t.Fatalf("Expected this process recorded, got %d %v", pid, ok)
+	}
+	if _, err := Acquire(pidFile); err == nil {
+		t.Error("Expected a second gateway to be refused")
+	}
+	release()
+	if _, err := os.Stat(pidFile); !os.IsNotExist(err) {
+		t.Error("Expected release to remove the pidfile")
+	}
+
+	// A pidfile left by a gateway that died doesn't block the next one
+	os.WriteFile(pidFile, []byte("999999999\n"), 0644)
+	if _, ok := Running(pidFile); ok {
+		t.Error("Expected a dead process not to count as running")
+	}
+	os.WriteFile(pidFile, []byte("999999999\n"), 0644)
+	release, err = Acquire(pidFile)
+	if err != nil {
+		t.Fatalf("Expected a stale pidfile to be replaced, got %v", err)
+	}
+	release()
+}
+
+func TestStop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep and SIGTERM")
+	}
+	pidFile := filepath.Join(t.TempDir(), "myrai.pid")
+	if _, err := Stop(pidFile, time.Second); err != ErrNotRunning {
+		t.Errorf("Expected ErrNotRunning, got %v", err)
+	}
+
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("sleep unavailable: %v", err)
+	}
+	// Reap the child so it doesn't linger as a zombie once signalled
+	go cmd.Wait()
+	os.WriteFile(pidFile, []byte(strconv.Itoa(cmd.Process.Pid)), 0644)
+
+	pid, err := Stop(pidFile, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if pid != cmd.Process.Pid {
+		t.Errorf("Expected pid %d stopped, got %d", cmd.Process.Pid, pid)
+	}
+	if _, err := os.Stat(pidFile); !os.IsNotExist(err) {
+		t.Error("Expected the pidfile of the stopped process removed")
+	}
+}
+
+func TestServiceDefinitions(t *testing.T) {
+	s := Service{Executable: "/opt/my rai/myrai", DataDir: "/home/me/.myrai", LogFile: "/home/me/.myrai/logs/gateway.log"}
+
+	unit, err := s.SystemdUnit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`ExecStart="/opt/my rai/myrai" gateway run`, "Restart=on-failure", "WantedBy=default.target"} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("Expected the unit to contain %q:\n%s", want, unit)
+		}
+	}
+	if strings.Contains(unit, "User=") {
+		t.Error("Expected no User= in a user unit")
+	}
+
+	s.System, s.User = true, "me"
+	unit, _ = s.SystemdUnit()
+	if !strings.Contains(unit, "User=me") || !strings.Contains(unit, "WantedBy=multi-user.target") {
+		t.Errorf("Expected a system unit running as me:\n%s", unit)
+	}
+
+	plist, err := s.LaunchdPlist()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<string>" + LaunchdLabel + "</string>", "<string>/opt/my rai/myrai</string>", "<string>/home/me/.myrai/logs/gateway.log</string>"} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("Expected the plist to contain %q:\n%s", want, plist)
+		}
+	}
+}
+
+func TestServiceDefinitionsForProfile(t *testing.T) {
+	s := Service{Executable: "/usr/bin/myrai", DataDir: "/data", LogFile: "/data/logs/gateway.log", Profile: "work"}
+
+	if s.Name() != "myrai-work" || s.Label() != LaunchdLabel+".work" {
+		t.Errorf("Expected names with the profile, got %s and %s", s.Name(), s.Label())
+	}
+	unit, _ := s.SystemdUnit()
+	if !strings.Contains(unit, `ExecStart="/usr/bin/myrai" --profile work gateway run`) {
+		t.Errorf("Expected the unit to run the profile:\n%s", unit)
+	}
+	plist, _ := s.LaunchdPlist()
+	if !strings.Contains(plist, "<string>--profile</string>\n\t\t<string>work</string>") {
+		t.Errorf("Expected the plist to run the profile:\n%s", plist)
+	}
+	if path, err := s.ServiceFile(); err == nil && !strings.Contains(path, "work") {
+		t.Errorf("Expected a service file per profile, got %s", path)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package daemon
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// alive reports whether a process with the ID exists; on Windows finding a
+// process opens it, which fails once it has exited
+func alive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// terminate stops the process. Windows has no SIGTERM, so the gateway
+// doesn't get to shut down gracefully.
+func terminate(p *os.Process) error {
+	return p.Kill()
+}
+
+// detach starts cmd in its own process group, so it outlives the console
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
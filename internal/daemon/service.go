@@ -0,0 +1,188 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+// The names of the installed systemd unit and launchd job
+const (
+	ServiceName  = "myrai"
+	LaunchdLabel = "com.myrai.gateway"
+)
+
+// Service describes how a service manager should run the gateway
+type Service struct {
+	Executable string // absolute path of the myrai binary
+	DataDir    string
+	LogFile    string
+	User       string // for system-wide systemd units only
+	System     bool   // a system unit rather than a user unit
+	Profile    string // run this profile, so several instances can be installed
+}
+
+// Name is the systemd unit name, with the profile appended if there is one
+func (s Service) Name() string {
+	if s.Profile != "" {
+		return ServiceName + "-" + s.Profile
+	}
+	return ServiceName
+}
+
+// Label is the launchd job label, with the profile appended if there is one
+func (s Service) Label() string {
+	if s.Profile != "" {
+		return LaunchdLabel + "." + s.Profile
+	}
+	return LaunchdLabel
+}
+
+// ServiceFile returns where the service definition for this platform is
+// installed
+func (s Service) ServiceFile() (string, error) {
+	switch runtime.GOOS {
+	case "linux":
+		if s.System {
+			return filepath.Join("/etc/systemd/system", s.Name()+".service"), nil
+		}
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(configDir, "systemd", "user", s.Name()+".service"), nil
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, "Library", "LaunchAgents", s.Label()+".plist"), nil
+	default:
+		return "", fmt.Errorf("service install isn't supported on %s; use 'myrai gateway start --daemon'", runtime.GOOS)
+	}
+}
+
+// Render returns the service definition for this platform
+func (s Service) Render() (string, error) {
+	if runtime.GOOS == "darwin" {
+		return s.LaunchdPlist()
+	}
+	return s.SystemdUnit()
+}
+
+// SystemdUnit returns a systemd unit that runs the gateway and restarts it
+// when it fails
+func (s Service) SystemdUnit() (string, error) {
+	return render(systemdTemplate, s)
+}
+
+// LaunchdPlist returns a launchd job that runs the gateway at login and
+// keeps it running
+func (s Service) LaunchdPlist() (string, error) {
+	return render(launchdTemplate, s)
+}
+
+// EnableCommands returns the commands that load an installed service
+func (s Service) EnableCommands(path string) []string {
+	if runtime.GOOS == "darwin" {
+		return []string{"launchctl load -w " + path}
+	}
+	if s.System {
+		return []string{"sudo systemctl daemon-reload", "sudo systemctl enable --now " + s.Name()}
+	}
+	return []string{
+		"systemctl --user daemon-reload",
+		"systemctl --user enable --now " + s.Name(),
+		"loginctl enable-linger $USER   # keep it running after you log out",
+	}
+}
+
+// Disable stops the installed service and keeps it from starting again,
+// so its file can be removed
+func (s Service) Disable(path string) error {
+	var cmd *exec.Cmd
+	switch {
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("launchctl", "unload", "-w", path)
+	case s.System:
+		cmd = exec.Command("systemctl", "disable", "--now", s.Name())
+	default:
+		cmd = exec.Command("systemctl", "--user", "disable", "--now", s.Name())
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %v: %s", strings.Join(cmd.Args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func render(tmpl *template.Template, s Service) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, s); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+var systemdTemplate = template.Must(template.New("systemd").Parse(`[Unit]
+Description=Myrai personal assistant gateway
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=simple
+ExecStart="{{.Executable}}"{{if .Profile}} --profile {{.Profile}}{{end}} gateway run
+WorkingDirectory={{.DataDir}}
+Restart=on-failure
+RestartSec=5
+{{- if .System}}
+{{- if .User}}
+User={{.User}}
+{{- end}}
+{{- end}}
+
+[Install]
+WantedBy={{if .System}}multi-user.target{{else}}default.target{{end}}
+`))
+
+var launchdTemplate = template.Must(template.New("launchd").Funcs(template.FuncMap{
+	"xml": func(s string) string {
+		var b strings.Builder
+		template.HTMLEscape(&b, []byte(s))
+		return b.String()
+	},
+}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{xml .Label}}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{xml .Executable}}</string>
+{{- if .Profile}}
+		<string>--profile</string>
+		<string>{{xml .Profile}}</string>
+{{- end}}
+		<string>gateway</string>
+		<string>run</string>
+	</array>
+	<key>WorkingDirectory</key>
+	<string>{{xml .DataDir}}</string>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>StandardOutPath</key>
+	<string>{{xml .LogFile}}</string>
+	<key>StandardErrorPath</key>
+	<string>{{xml .LogFile}}</string>
+</dict>
+</plist>
+`))
@@ -24,6 +24,12 @@ func NewHandler(cfg *config.Config, sr *skills.Registry, logger *zap.Logger) *Ha
 	}
 }
 
+// SetSkillsRegistry sets the skills listed at /api/skills, for servers
+// given their registry after the routes are set up
+func (h *Handler) SetSkillsRegistry(sr *skills.Registry) {
+	h.skills = sr
+}
+
 // RegisterRoutes registers all dashboard routes
 func (h *Handler) RegisterRoutes(app *fiber.App) {
 	api := app.Group("/api")
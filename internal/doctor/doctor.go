@@ -0,0 +1,226 @@
+// Package doctor checks that myrai's database, LLM provider, channels and
+// the tools its skills shell out to actually work. The checks back both
+// 'myrai doctor' and the server's /readyz endpoint.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/llm"
+	"github.com/gmsas95/myrai-cli/internal/vector"
+	"gorm.io/gorm"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	OK   Status = "ok"
+	Warn Status = "warn" // works, but something needs attention
+	Fail Status = "fail"
+	Skip Status = "skip" // not configured
+)
+
+// Result is the outcome of one check
+type Result struct {
+	Name      string `json:"name"`
+	Status    Status `json:"status"`
+	Message   string `json:"message,omitempty"`
+	Fix       string `json:"fix,omitempty"`
+	LatencyMs int64  `json:"latency_ms,omitempty"`
+}
+
+// The APIs the channel checks call, replaced in tests
+var (
+	telegramAPI = "https://api.telegram.org"
+	discordAPI  = "https://discord.com/api/v10"
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+func timed(start time.Time, r Result) Result {
+	r.LatencyMs = time.Since(start).Milliseconds()
+	return r
+}
+
+// CheckDatabase pings the database and runs a query on it
+func CheckDatabase(ctx context.Context, db *gorm.DB) Result {
+	start := time.Now()
+	r := Result{Name: "database"}
+	sqlDB, err := db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err == nil {
+		var one int
+		err = db.WithContext(ctx).Raw("SELECT 1").Scan(&one).Error
+	}
+	if err != nil {
+		r.Status, r.Message = Fail, err.Error()
+		return timed(start, r)
+	}
+	r.Status, r.Message = OK, "reachable"
+	return timed(start, r)
+}
+
+// CheckProvider sends the provider a one-token request, so a wrong key,
+// model or endpoint shows up before the first chat does
+func CheckProvider(ctx context.Context, name string, provider config.Provider) Result {
+	start := time.Now()
+	r := Result{Name: "llm"}
+	if provider.Model == "" {
+		r.Status, r.Message, r.Fix = Fail, "no model configured for "+name, "Run: myrai onboard"
+		return r
+	}
+
+	resp, err := llm.NewClient(provider).ChatCompletion(ctx, llm.ChatRequest{
+		Model:     provider.Model,
+		Messages:  []llm.Message{{Role: "user", Content: "ping"}},
+		MaxTokens: 1,
+	})
+	if err == nil && len(resp.Choices) == 0 {
+		err = errors.New("no response from model")
+	}
+	if err != nil {
+		r.Status, r.Message = Fail, fmt.Sprintf("%s/%s: %v", name, provider.Model, err)
+		r.Fix = "Check the API key, model and base URL of " + name
+		return timed(start, r)
+	}
+	r.Status, r.Message = OK, name+"/"+provider.Model+" answered"
+	return timed(start, r)
+}
+
+// CheckTelegram validates a bot token by asking Telegram who the bot is
+func CheckTelegram(ctx context.Context, token string) Result {
+	start := time.Now()
+	r := Result{Name: "telegram"}
+	var body struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+		Result      struct {
+			Username string `json:"username"`
+		} `json:"result"`
+	}
+	status, err := getJSON(ctx, telegramAPI+"/bot"+token+"/getMe", nil, &body)
+	switch {
+	case err != nil:
+		r.Status, r.Message = Fail, "unreachable: "+err.Error()
+	case status == http.StatusUnauthorized || status == http.StatusNotFound:
+		r.Status, r.Message, r.Fix = Fail, "bot token rejected", "Get a new token from @BotFather"
+	case !body.OK:
+		r.Status, r.Message = Fail, fmt.Sprintf("HTTP %d: %s", status, body.Description)
+	default:
+		r.Status, r.Message = OK, "@"+body.Result.Username
+	}
+	return timed(start, r)
+}
+
+// CheckDiscord validates a bot token by asking Discord who the bot is
+func CheckDiscord(ctx context.Context, token string) Result {
+	start := time.Now()
+	r := Result{Name: "discord"}
+	var body struct {
+		Username string `json:"username"`
+		Message  string `json:"message"`
+	}
+	header := http.Header{"Authorization": {"Bot " + token}}
+	status, err := getJSON(ctx, discordAPI+"/users/@me", header, &body)
+	switch {
+	case err != nil:
+		r.Status, r.Message = Fail, "unreachable: "+err.Error()
+	case status == http.StatusUnauthorized:
+		r.Status, r.Message, r.Fix = Fail, "bot token rejected", "Reset the token in the Discord developer portal"
+	case status != http.StatusOK:
+		r.Status, r.Message = Fail, fmt.Sprintf("HTTP %d: %s", status, body.Message)
+	default:
+		r.Status, r.Message = OK, body.Username
+	}
+	return timed(start, r)
+}
+
+// getJSON decodes the response to a GET into v and returns its status. The
+// error leaves out the URL, which holds the Telegram token.
+func getJSON(ctx context.Context, rawURL string, header http.Header, v interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return 0, errors.New("invalid URL")
+	}
+	for k, values := range header {
+		req.Header[k] = values
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	json.NewDecoder(resp.Body).Decode(v)
+	return resp.StatusCode, nil
+}
+
+// CheckBinary verifies a program a skill runs is installed and starts
+func CheckBinary(ctx context.Context, name, purpose, install string, versionArgs ...string) Result {
+	r := Result{Name: name}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		r.Status, r.Message, r.Fix = Warn, "not found (needed for "+purpose+")", install
+		return r
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, versionArgs...).CombinedOutput()
+	if err != nil {
+		r.Status, r.Message, r.Fix = Fail, fmt.Sprintf("%s doesn't run: %v", path, err), install
+		return r
+	}
+	version, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	r.Status, r.Message = OK, version
+	return r
+}
+
+// CheckVectorIndexes reads back each vector index and reports those built by
+// another embedding model, holding embeddings of the wrong dimension, or
+// whose backend can't be read
+func CheckVectorIndexes(ctx context.Context, searcher *vector.Searcher) []Result {
+	checks, err := searcher.Verify(ctx)
+	if err != nil {
+		return []Result{{Name: "vector", Status: Fail, Message: err.Error()}}
+	}
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		r := Result{Name: "vector/" + c.Name}
+		switch {
+		case c.Error != "":
+			r.Status, r.Message = Fail, searcher.Vectors().Name()+" backend: "+c.Error
+		case c.Mismatched > 0:
+			r.Status = Fail
+			r.Message = fmt.Sprintf("%d of %d embeddings aren't %d-dimensional", c.Mismatched, c.Embeddings, c.Dimension)
+			r.Fix = "Run: myrai vector reindex " + c.Name
+		case c.Stale:
+			r.Status = Warn
+			r.Message = fmt.Sprintf("built by %s/%s, active model is %s/%s", c.Provider, c.Model, searcher.ProviderName(), searcher.Model())
+			r.Fix = "Run: myrai vector reindex " + c.Name
+		case !c.Recorded:
+			r.Status, r.Message = Warn, "not built yet"
+		default:
+			r.Status, r.Message = OK, fmt.Sprintf("%s/%s", c.Provider, c.Model)
+			if c.Embeddings > 0 {
+				r.Message += fmt.Sprintf(", %d embeddings", c.Embeddings)
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
@@ -0,0 +1,133 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDatabase(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	r := CheckDatabase(context.Background(), st.DB())
+	assert.Equal(t, OK, r.Status, r.Message)
+
+	sqlDB, _ := st.DB().DB()
+	sqlDB.Close()
+	r = CheckDatabase(context.Background(), st.DB())
+	assert.Equal(t, Fail, r.Status)
+}
+
+func TestCheckProvider(t *testing.T) {
+	var maxTokens int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"message":"invalid api key"}}`))
+			return
+		}
+		var body struct {
+			MaxTokens int `json:"max_tokens"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		maxTokens = body.MaxTokens
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": "p"}}},
+		})
+	}))
+	defer srv.Close()
+
+	provider := config.Provider{APIKey: "good", BaseURL: srv.URL, Model: "test-model"}
+	r := CheckProvider(context.Background(), "openai", provider)
+	assert.Equal(t, OK, r.Status, r.Message)
+	assert.Equal(t, 1, maxTokens, "the check should cost a single token")
+
+	provider.APIKey = "bad"
+	r = CheckProvider(context.Background(), "openai", provider)
+	assert.Equal(t, Fail, r.Status)
+	assert.NotEmpty(t, r.Fix)
+}
+
+func TestCheckChannels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/botgood/getMe":
+			w.Write([]byte(`{"ok":true,"result":{"username":"myrai_bot"}}`))
+		case strings.HasPrefix(r.URL.Path, "/bot"):
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"ok":false,"description":"Unauthorized"}`))
+		case r.Header.Get("Authorization") == "Bot good":
+			w.Write([]byte(`{"username":"Myrai"}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"401: Unauthorized"}`))
+		}
+	}))
+	defer srv.Close()
+	defer func(tg, dc string) { telegramAPI, discordAPI = tg, dc }(telegramAPI, discordAPI)
+	telegramAPI, discordAPI = srv.URL, srv.URL
+
+	ctx := context.Background()
+	r := CheckTelegram(ctx, "good")
+	assert.Equal(t, OK, r.Status)
+	assert.Equal(t, "@myrai_bot", r.Message)
+	r = CheckTelegram(ctx, "bad")
+	assert.Equal(t, Fail, r.Status)
+	assert.NotContains(t, r.Message, "bad", "the token must not be echoed")
+
+	assert.Equal(t, OK, CheckDiscord(ctx, "good").Status)
+	assert.Equal(t, Fail, CheckDiscord(ctx, "bad").Status)
+
+	// Connection errors leave out the URL, which holds the Telegram token
+	telegramAPI = "http://127.0.0.1:1"
+	r = CheckTelegram(ctx, "secret-token")
+	assert.Equal(t, Fail, r.Status)
+	assert.NotContains(t, r.Message, "secret-token")
+}
+
+func TestReadiness(t *testing.T) {
+	var dbRuns, llmRuns int
+	llmStatus := OK
+	readiness := NewReadiness()
+	readiness.Add(true, 0, func(context.Context) Result {
+		dbRuns++
+		return Result{Name: "database", Status: OK}
+	})
+	readiness.Add(true, time.Hour, func(context.Context) Result {
+		llmRuns++
+		return Result{Name: "llm", Status: llmStatus}
+	})
+	readiness.Add(false, time.Hour, func(context.Context) Result {
+		return Result{Name: "telegram", Status: Fail}
+	})
+
+	ctx := context.Background()
+	ready, results := readiness.Check(ctx)
+	assert.True(t, ready, "a failing channel doesn't make the server unready")
+	assert.Len(t, results, 3)
+
+	llmStatus = Fail
+	ready, _ = readiness.Check(ctx)
+	assert.True(t, ready, "the provider's result is reused until it expires")
+	assert.Equal(t, 2, dbRuns)
+	assert.Equal(t, 1, llmRuns)
+
+	readiness.checks[1].at = time.Now().Add(-2 * time.Hour)
+	ready, _ = readiness.Check(ctx)
+	assert.False(t, ready)
+	assert.Equal(t, 2, llmRuns)
+
+	// Failures are retried sooner than successes
+	readiness.checks[1].at = time.Now().Add(-time.Minute)
+	llmStatus = OK
+	ready, _ = readiness.Check(ctx)
+	assert.True(t, ready)
+	assert.Equal(t, 3, llmRuns)
+}
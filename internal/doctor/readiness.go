@@ -0,0 +1,65 @@
+package doctor
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// retryFailedAfter bounds how long a failed check's result is reused, so a
+// readiness probe notices a recovery soon
+const retryFailedAfter = 30 * time.Second
+
+// Readiness runs the checks behind /readyz. Checks that cost tokens or call
+// other services keep their result for a while, so frequent probes stay
+// cheap.
+type Readiness struct {
+	mu     sync.Mutex
+	checks []*readinessCheck
+}
+
+type readinessCheck struct {
+	critical bool
+	ttl      time.Duration
+	run      func(context.Context) Result
+	last     Result
+	at       time.Time
+}
+
+// NewReadiness creates an empty set of readiness checks
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// Add adds a check run at most once per ttl. Only a failing critical check
+// makes the server not ready; others are reported as degraded.
+func (r *Readiness) Add(critical bool, ttl time.Duration, run func(context.Context) Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, &readinessCheck{critical: critical, ttl: ttl, run: run})
+}
+
+// Check runs the checks whose results are out of date and reports whether
+// every critical one passes
+func (r *Readiness) Check(ctx context.Context) (bool, []Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ready := true
+	results := make([]Result, 0, len(r.checks))
+	for _, c := range r.checks {
+		ttl := c.ttl
+		if c.last.Status == Fail {
+			ttl = min(ttl, retryFailedAfter)
+		}
+		if c.at.IsZero() || time.Since(c.at) >= ttl {
+			c.last = c.run(ctx)
+			c.at = time.Now()
+		}
+		if c.critical && c.last.Status == Fail {
+			ready = false
+		}
+		results = append(results, c.last)
+	}
+	return ready, results
+}
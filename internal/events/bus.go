@@ -0,0 +1,142 @@
+// Package events provides an in-process publish/subscribe bus that lets
+// skills announce what happened (a task was completed, a dose was missed)
+// without knowing who is listening.
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Event types published by the built-in skills
+const (
+	TaskCompleted       = "task.completed"
+	MedicationTaken     = "medication.taken"
+	MedicationMissed    = "medication.missed"
+	ShoppingItemChecked = "shopping_item.checked"
+	FileUploaded        = "file.uploaded"
+)
+
+// Event is something that happened in one skill that others may react to
+type Event struct {
+	Type   string                 `json:"type"`
+	UserID string                 `json:"user_id"`
+	Source string                 `json:"source"` // skill or channel that published it
+	Data   map[string]interface{} `json:"data,omitempty"`
+	Time   time.Time              `json:"time"`
+}
+
+// Category returns the part of the type before the first dot, e.g. "task"
+// for "task.completed"
+func (e Event) Category() string {
+	if i := strings.Index(e.Type, "."); i >= 0 {
+		return e.Type[:i]
+	}
+	return e.Type
+}
+
+// Handler receives published events. Handlers run on the publisher's
+// goroutine, so anything slow should be handed off.
+type Handler func(ctx context.Context, e Event)
+
+type subscription struct {
+	id      int
+	pattern string
+	handler Handler
+}
+
+// Bus delivers events to the handlers subscribed to them. A nil *Bus is
+// valid and drops everything, so publishers need not check for one.
+type Bus struct {
+	mu     sync.RWMutex
+	subs   []subscription
+	nextID int
+	logger *zap.Logger
+}
+
+// NewBus creates an empty bus
+func NewBus(logger *zap.Logger) *Bus {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Bus{logger: logger}
+}
+
+// Subscribe registers handler for events matching pattern: an exact type
+// ("task.completed"), a category wildcard ("task.*") or "*" for everything.
+// The returned function removes the subscription.
+func (b *Bus) Subscribe(pattern string, handler Handler) func() {
+	if b == nil {
+		return func() {}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, subscription{id: id, pattern: pattern, handler: handler})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, s := range b.subs {
+			if s.id == id {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish delivers e to every matching subscriber in the order they
+// subscribed. A handler that panics is logged and does not stop the others
+// or the publisher.
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	if b == nil || e.Type == "" {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	var handlers []Handler
+	for _, s := range b.subs {
+		if Matches(s.pattern, e.Type) {
+			handlers = append(handlers, s.handler)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		b.deliver(ctx, h, e)
+	}
+}
+
+func (b *Bus) deliver(ctx context.Context, h Handler, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error("Event handler panicked",
+				zap.String("event", e.Type),
+				zap.String("panic", fmt.Sprint(r)))
+		}
+	}()
+	h(ctx, e)
+}
+
+// Matches reports whether an event type matches a subscription pattern
+func Matches(pattern, eventType string) bool {
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasSuffix(pattern, ".*"):
+		return strings.HasPrefix(eventType, strings.TrimSuffix(pattern, "*"))
+	default:
+		return pattern == eventType
+	}
+}
@@ -0,0 +1,59 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatches(t *testing.T) {
+	assert.True(t, Matches("*", TaskCompleted))
+	assert.True(t, Matches("task.*", TaskCompleted))
+	assert.True(t, Matches(TaskCompleted, TaskCompleted))
+	assert.False(t, Matches("task.*", MedicationMissed))
+	assert.False(t, Matches("task.created", TaskCompleted))
+	assert.False(t, Matches("task.*", "taskforce.created"))
+}
+
+func TestBus_PublishSubscribe(t *testing.T) {
+	bus := NewBus(nil)
+
+	var got []string
+	bus.Subscribe("*", func(ctx context.Context, e Event) { got = append(got, "all:"+e.Type) })
+	unsubscribe := bus.Subscribe("medication.*", func(ctx context.Context, e Event) { got = append(got, "med:"+e.Type) })
+
+	bus.Publish(context.Background(), Event{Type: MedicationMissed, UserID: "alice"})
+	bus.Publish(context.Background(), Event{Type: TaskCompleted, UserID: "alice"})
+	assert.Equal(t, []string{"all:medication.missed", "med:medication.missed", "all:task.completed"}, got)
+
+	unsubscribe()
+	got = nil
+	bus.Publish(context.Background(), Event{Type: MedicationMissed})
+	assert.Equal(t, []string{"all:medication.missed"}, got)
+}
+
+func TestBus_PanickingHandler(t *testing.T) {
+	bus := NewBus(nil)
+
+	delivered := false
+	bus.Subscribe("*", func(ctx context.Context, e Event) { panic("boom") })
+	bus.Subscribe("*", func(ctx context.Context, e Event) {
+		delivered = true
+		assert.False(t, e.Time.IsZero())
+		assert.Equal(t, "task", e.Category())
+	})
+
+	assert.NotPanics(t, func() {
+		bus.Publish(context.Background(), Event{Type: TaskCompleted})
+	})
+	assert.True(t, delivered)
+}
+
+func TestBus_Nil(t *testing.T) {
+	var bus *Bus
+	assert.NotPanics(t, func() {
+		bus.Subscribe("*", func(ctx context.Context, e Event) {})()
+		bus.Publish(context.Background(), Event{Type: TaskCompleted})
+	})
+}
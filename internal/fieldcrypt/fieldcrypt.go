@@ -0,0 +1,330 @@
+// Package fieldcrypt encrypts sensitive database columns at rest. A field
+// tagged `gorm:"serializer:encrypted"` is sealed with AES-256-GCM once a key
+// is set, and stored as "enc:v1:" followed by base64. Values written before
+// encryption was turned on read back as they are, so existing databases keep
+// working until Rewrite converts them.
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/crypto/scrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Prefix marks an encrypted value
+const Prefix = "enc:v1:"
+
+// KeySize is the length of a key in bytes
+const KeySize = 32
+
+// ErrNoKey is returned reading an encrypted value without a key
+var ErrNoKey = errors.New("the database is encrypted, but no storage encryption key is configured")
+
+// ErrWrongKey is returned when a value doesn't decrypt with the key
+var ErrWrongKey = errors.New("wrong storage encryption key or passphrase")
+
+func init() {
+	schema.RegisterSerializer("encrypted", Serializer{})
+}
+
+// aead is the cipher values are sealed with; nil stores them in plaintext
+var aead atomic.Pointer[cipher.AEAD]
+
+// SetKey encrypts values written from now on with key, and decrypts values
+// read with it. A nil key turns encryption off.
+func SetKey(key []byte) error {
+	if key == nil {
+		aead.Store(nil)
+		return nil
+	}
+	c, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	aead.Store(&c)
+	return nil
+}
+
+// Enabled reports whether a key is set
+func Enabled() bool {
+	return aead.Load() != nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// DeriveKey derives a key from a passphrase with scrypt
+func DeriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, KeySize)
+}
+
+// NewKey returns a random key
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// IsEncrypted reports whether a stored value is encrypted
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Encrypt seals plaintext with the current key
+func Encrypt(plaintext []byte) (string, error) {
+	c := aead.Load()
+	if c == nil {
+		return "", ErrNoKey
+	}
+	return seal(*c, plaintext)
+}
+
+// Decrypt opens a value sealed by Encrypt; other values are returned as
+// they are
+func Decrypt(value string) ([]byte, error) {
+	if !IsEncrypted(value) {
+		return []byte(value), nil
+	}
+	c := aead.Load()
+	if c == nil {
+		return nil, ErrNoKey
+	}
+	return open(*c, value)
+}
+
+func seal(c cipher.AEAD, plaintext []byte) (string, error) {
+	nonce := make([]byte, c.NonceSize(), c.NonceSize()+len(plaintext)+c.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.Seal(nonce, nonce, plaintext, nil)
+	return Prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func open(c cipher.AEAD, value string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, Prefix))
+	if err != nil || len(data) < c.NonceSize() {
+		return nil, fmt.Errorf("corrupt encrypted value")
+	}
+	plaintext, err := c.Open(nil, data[:c.NonceSize()], data[c.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return plaintext, nil
+}
+
+// Serializer stores string and []byte fields encrypted while a key is set.
+// Empty values are left empty, so queries for empty values still work.
+type Serializer struct{}
+
+// Scan implements schema.SerializerInterface
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch v := dbValue.(type) {
+	case nil:
+		return field.Set(ctx, dst, reflect.Zero(field.FieldType).Interface())
+	case []byte:
+		stored = string(v)
+	case string:
+		stored = v
+	default:
+		return fmt.Errorf("unexpected type %T for encrypted field %s", dbValue, field.Name)
+	}
+
+	plaintext, err := Decrypt(stored)
+	if err != nil {
+		return fmt.Errorf("%s: %w", field.DBName, err)
+	}
+	value := reflect.New(field.FieldType).Elem()
+	switch field.FieldType.Kind() {
+	case reflect.String:
+		value.SetString(string(plaintext))
+	case reflect.Slice:
+		value.SetBytes(plaintext)
+	default:
+		return fmt.Errorf("encrypted field %s must be a string or []byte", field.Name)
+	}
+	field.ReflectValueOf(ctx, dst).Set(value)
+	return nil
+}
+
+// Value implements schema.SerializerValuerInterface
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	v := reflect.ValueOf(fieldValue)
+	var plaintext []byte
+	switch {
+	case !v.IsValid():
+		return nil, nil
+	case v.Kind() == reflect.String:
+		plaintext = []byte(v.String())
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		if v.IsNil() {
+			return nil, nil
+		}
+		plaintext = v.Bytes()
+	default:
+		return nil, fmt.Errorf("encrypted field %s must be a string or []byte", field.Name)
+	}
+
+	if len(plaintext) == 0 || !Enabled() {
+		// As the base type: drivers don't take named types like json.RawMessage
+		if v.Kind() == reflect.String {
+			return v.String(), nil
+		}
+		return plaintext, nil
+	}
+	return Encrypt(plaintext)
+}
+
+var (
+	modelsMu sync.Mutex
+	models   []interface{}
+)
+
+// Register records models with encrypted fields, for Rewrite
+func Register(m ...interface{}) {
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+	models = append(models, m...)
+}
+
+// rewriteBatch is how many rows Rewrite converts per transaction
+const rewriteBatch = 500
+
+// Rewrite encrypts the existing values of the encrypted fields of every
+// registered model with the current key, or decrypts them when encrypt is
+// false. Values already in the wanted form are skipped. It returns the
+// number of values changed.
+func Rewrite(db *gorm.DB, encrypt bool) (int, error) {
+	c := aead.Load()
+	if c == nil {
+		return 0, ErrNoKey
+	}
+
+	modelsMu.Lock()
+	list := append([]interface{}(nil), models...)
+	modelsMu.Unlock()
+
+	total := 0
+	for _, model := range list {
+		n, err := rewriteModel(db, *c, model, encrypt)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// rewriteModel converts the encrypted columns of one table
+func rewriteModel(db *gorm.DB, c cipher.AEAD, model interface{}, encrypt bool) (int, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return 0, err
+	}
+	if !db.Migrator().HasTable(stmt.Schema.Table) || stmt.Schema.PrioritizedPrimaryField == nil {
+		return 0, nil
+	}
+	var columns []string
+	for _, field := range stmt.Schema.Fields {
+		if strings.EqualFold(field.TagSettings["SERIALIZER"], "encrypted") && field.DBName != "" {
+			columns = append(columns, field.DBName)
+		}
+	}
+	if len(columns) == 0 {
+		return 0, nil
+	}
+	table, pk := stmt.Schema.Table, stmt.Schema.PrioritizedPrimaryField.DBName
+
+	changed := 0
+	for offset := 0; ; offset += rewriteBatch {
+		var rows []map[string]interface{}
+		err := db.Table(table).Select(append([]string{pk}, columns...)).
+			Order(pk).Limit(rewriteBatch).Offset(offset).Find(&rows).Error
+		if err != nil {
+			return changed, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		if len(rows) == 0 {
+			return changed, nil
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for _, row := range rows {
+				updates := make(map[string]interface{})
+				for _, column := range columns {
+					value, ok := rewriteValue(row[column], c, encrypt)
+					if !ok {
+						continue
+					}
+					if value == nil {
+						return fmt.Errorf("%s.%s of %v: %w", table, column, row[pk], ErrWrongKey)
+					}
+					updates[column] = value
+				}
+				if len(updates) == 0 {
+					continue
+				}
+				if err := tx.Table(table).Where(pk+" = ?", row[pk]).UpdateColumns(updates).Error; err != nil {
+					return err
+				}
+				changed += len(updates)
+			}
+			return nil
+		})
+		if err != nil {
+			return changed, fmt.Errorf("failed to rewrite %s: %w", table, err)
+		}
+	}
+}
+
+// rewriteValue returns a stored value converted to the wanted form, and
+// whether it needed converting. A nil value with true means it didn't
+// decrypt.
+func rewriteValue(stored interface{}, c cipher.AEAD, encrypt bool) (interface{}, bool) {
+	var s string
+	switch v := stored.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return nil, false
+	}
+	if s == "" || IsEncrypted(s) == encrypt {
+		return nil, false
+	}
+	if encrypt {
+		sealed, err := seal(c, []byte(s))
+		if err != nil {
+			return nil, true
+		}
+		return sealed, true
+	}
+	plaintext, err := open(c, s)
+	if err != nil {
+		return nil, true
+	}
+	return string(plaintext), true
+}
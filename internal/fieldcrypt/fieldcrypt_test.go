@@ -0,0 +1,45 @@
+package fieldcrypt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Cleanup(func() { SetKey(nil) })
+
+	if _, err := Decrypt(Prefix + "AAAA"); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Expected ErrNoKey without a key, got %v", err)
+	}
+	if plain, err := Decrypt("not encrypted"); err != nil || string(plain) != "not encrypted" {
+		t.Errorf("Expected plaintext passed through, got %q (%v)", plain, err)
+	}
+
+	key, err := DeriveKey("correct horse", []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetKey(key); err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := Encrypt([]byte("150/95"))
+	if err != nil || !IsEncrypted(sealed) {
+		t.Fatalf("Expected an encrypted value, got %q (%v)", sealed, err)
+	}
+	again, _ := Encrypt([]byte("150/95"))
+	if again == sealed {
+		t.Error("Expected a fresh nonce for each value")
+	}
+	if plain, err := Decrypt(sealed); err != nil || string(plain) != "150/95" {
+		t.Errorf("Expected 150/95, got %q (%v)", plain, err)
+	}
+
+	other, _ := NewKey()
+	SetKey(other)
+	if _, err := Decrypt(sealed); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("Expected ErrWrongKey, got %v", err)
+	}
+	if err := SetKey([]byte("short")); err == nil {
+		t.Error("Expected a short key to be rejected")
+	}
+}
@@ -0,0 +1,549 @@
+// Package household lets one Myrai instance serve several people. Each
+// member has a profile with its own persona and linked chat accounts. Skills
+// marked as shared (shopping lists, calendar) keep one copy of their data for
+// the whole household; all other skills keep private data per profile.
+package household
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"gorm.io/gorm"
+)
+
+// Roles
+const (
+	RoleOwner  = "owner"
+	RoleMember = "member"
+)
+
+// SharedUserID is the user ID shared skills store household data under. It
+// is the ID skills already fall back to without a profile, so data created
+// before the household was set up stays visible to everyone.
+const SharedUserID = "default_user"
+
+// ID prefixes
+const (
+	PrefixProfile = "prof"
+	PrefixLink    = "link"
+)
+
+// InviteTTL is how long an invite code can be redeemed
+const InviteTTL = 48 * time.Hour
+
+// DefaultShared lists the skills whose data is shared by default
+var DefaultShared = []string{"shopping", "calendar"}
+
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// Profile is a member of the household
+type Profile struct {
+	ID          string `gorm:"primaryKey" json:"id"`
+	Name        string `gorm:"uniqueIndex;not null" json:"name"`
+	DisplayName string `json:"display_name"`
+	Role        string `gorm:"not null" json:"role"`
+	// UserID keys the profile's private skill data. The first owner keeps
+	// SharedUserID so data from before the household existed stays theirs.
+	UserID    string    `gorm:"not null" json:"user_id"`
+	Persona   string    `gorm:"type:text" json:"persona,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName keeps household tables grouped together
+func (Profile) TableName() string { return "household_profiles" }
+
+// IsOwner reports whether the profile can manage members
+func (p *Profile) IsOwner() bool { return p.Role == RoleOwner }
+
+// Label returns the display name, falling back to the profile name
+func (p *Profile) Label() string {
+	if p.DisplayName != "" {
+		return p.DisplayName
+	}
+	return p.Name
+}
+
+// ChannelLink maps a chat account to a profile
+type ChannelLink struct {
+	ID         string    `gorm:"primaryKey" json:"id"`
+	ProfileID  string    `gorm:"index;not null" json:"profile_id"`
+	Channel    string    `gorm:"uniqueIndex:idx_household_link;not null" json:"channel"`
+	ExternalID string    `gorm:"uniqueIndex:idx_household_link;not null" json:"external_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName keeps household tables grouped together
+func (ChannelLink) TableName() string { return "household_links" }
+
+// Invite is a one-time code that links a chat account to a profile
+type Invite struct {
+	Code      string    `gorm:"primaryKey" json:"code"`
+	ProfileID string    `gorm:"not null" json:"profile_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName keeps household tables grouped together
+func (Invite) TableName() string { return "household_invites" }
+
+// Manager handles household profiles, channel links and invites
+type Manager struct {
+	db *gorm.DB
+}
+
+func init() {
+	store.RegisterMigrations("household", store.Migration{
+		Version: 1,
+		Name:    "create household tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Profile{}, &ChannelLink{}, &Invite{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Profile{}, &ChannelLink{}, &Invite{})
+		},
+	})
+}
+
+// NewManager creates a new household manager
+func NewManager(db *gorm.DB) (*Manager, error) {
+	if err := store.Migrate(db, "household"); err != nil {
+		return nil, fmt.Errorf("failed to migrate household schema: %w", err)
+	}
+	return &Manager{db: db}, nil
+}
+
+// ValidName reports whether name can be used as a profile name
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Active reports whether any profiles exist. Without profiles the instance
+// behaves as a single-user assistant.
+func (m *Manager) Active() bool {
+	var count int64
+	m.db.Model(&Profile{}).Count(&count)
+	return count > 0
+}
+
+// Add creates a profile. The first profile always becomes the owner.
+func (m *Manager) Add(name, displayName, role string) (*Profile, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !ValidName(name) {
+		return nil, fmt.Errorf("invalid profile name %q: use lowercase letters, digits, '-' or '_' (max 32 chars)", name)
+	}
+	if role == "" {
+		role = RoleMember
+	}
+	if role != RoleOwner && role != RoleMember {
+		return nil, fmt.Errorf("invalid role %q: use %s or %s", role, RoleOwner, RoleMember)
+	}
+
+	var count int64
+	if err := m.db.Model(&Profile{}).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if existing, _ := m.Get(name); existing != nil {
+		return nil, fmt.Errorf("profile already exists: %s", name)
+	}
+
+	profile := &Profile{
+		ID:          idgen.Generate(PrefixProfile),
+		Name:        name,
+		DisplayName: strings.TrimSpace(displayName),
+		Role:        role,
+	}
+	profile.UserID = profile.ID
+	if count == 0 {
+		profile.Role = RoleOwner
+		profile.UserID = SharedUserID
+	}
+
+	if err := m.db.Create(profile).Error; err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// Get looks up a profile by name or ID. It returns nil when none exists.
+func (m *Manager) Get(nameOrID string) (*Profile, error) {
+	var profile Profile
+	err := m.db.Where("name = ? OR id = ?", strings.ToLower(nameOrID), nameOrID).First(&profile).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// List returns all profiles, owners first
+func (m *Manager) List() ([]Profile, error) {
+	var profiles []Profile
+	err := m.db.Order("CASE WHEN role = 'owner' THEN 0 ELSE 1 END, name ASC").Find(&profiles).Error
+	return profiles, err
+}
+
+// Remove deletes a profile with its channel links and invites. The last
+// owner cannot be removed.
+func (m *Manager) Remove(name string) error {
+	profile, err := m.mustGet(name)
+	if err != nil {
+		return err
+	}
+	if profile.IsOwner() {
+		if err := m.ensureAnotherOwner(profile.ID); err != nil {
+			return err
+		}
+	}
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("profile_id = ?", profile.ID).Delete(&ChannelLink{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("profile_id = ?", profile.ID).Delete(&Invite{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(profile).Error
+	})
+}
+
+// SetRole changes a profile's role. The last owner cannot be demoted.
+func (m *Manager) SetRole(name, role string) (*Profile, error) {
+	if role != RoleOwner && role != RoleMember {
+		return nil, fmt.Errorf("invalid role %q: use %s or %s", role, RoleOwner, RoleMember)
+	}
+	profile, err := m.mustGet(name)
+	if err != nil {
+		return nil, err
+	}
+	if profile.IsOwner() && role != RoleOwner {
+		if err := m.ensureAnotherOwner(profile.ID); err != nil {
+			return nil, err
+		}
+	}
+	profile.Role = role
+	if err := m.db.Save(profile).Error; err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// SetPersona sets extra instructions used in conversations with a profile.
+// An empty persona clears it.
+func (m *Manager) SetPersona(name, persona string) (*Profile, error) {
+	profile, err := m.mustGet(name)
+	if err != nil {
+		return nil, err
+	}
+	profile.Persona = strings.TrimSpace(persona)
+	if err := m.db.Save(profile).Error; err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// Link connects a chat account to a profile, replacing any previous link
+// for that account
+func (m *Manager) Link(name, channel, externalID string) (*ChannelLink, error) {
+	profile, err := m.mustGet(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.link(profile, channel, externalID)
+}
+
+func (m *Manager) link(profile *Profile, channel, externalID string) (*ChannelLink, error) {
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	externalID = strings.TrimSpace(externalID)
+	if channel == "" || externalID == "" {
+		return nil, fmt.Errorf("channel and account ID are required")
+	}
+
+	link := &ChannelLink{
+		ID:         idgen.Generate(PrefixLink),
+		ProfileID:  profile.ID,
+		Channel:    channel,
+		ExternalID: externalID,
+	}
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("channel = ? AND external_id = ?", channel, externalID).Delete(&ChannelLink{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(link).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// Unlink disconnects a chat account from whichever profile it belongs to
+func (m *Manager) Unlink(channel, externalID string) error {
+	result := m.db.Where("channel = ? AND external_id = ?", strings.ToLower(channel), externalID).Delete(&ChannelLink{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no profile is linked to %s:%s", channel, externalID)
+	}
+	return nil
+}
+
+// Links returns the chat accounts linked to a profile
+func (m *Manager) Links(profileID string) ([]ChannelLink, error) {
+	var links []ChannelLink
+	err := m.db.Where("profile_id = ?", profileID).Order("channel ASC, external_id ASC").Find(&links).Error
+	return links, err
+}
+
+// ForChannel returns the profile linked to a chat account, or nil
+func (m *Manager) ForChannel(channel, externalID string) (*Profile, error) {
+	var link ChannelLink
+	err := m.db.Where("channel = ? AND external_id = ?", strings.ToLower(channel), externalID).First(&link).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m.Get(link.ProfileID)
+}
+
+// Owner returns the first owner profile, or nil before the household is set up
+func (m *Manager) Owner() (*Profile, error) {
+	var profile Profile
+	err := m.db.Where("role = ?", RoleOwner).Order("created_at ASC").First(&profile).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// CreateInvite issues a one-time code that links the redeeming chat account
+// to the named profile. Only owners may invite.
+func (m *Manager) CreateInvite(by *Profile, name string) (*Invite, error) {
+	if by == nil || !by.IsOwner() {
+		return nil, fmt.Errorf("only the household owner can invite members")
+	}
+	profile, err := m.mustGet(name)
+	if err != nil {
+		return nil, err
+	}
+
+	invite := &Invite{
+		Code:      newInviteCode(),
+		ProfileID: profile.ID,
+		ExpiresAt: time.Now().Add(InviteTTL),
+	}
+	if err := m.db.Create(invite).Error; err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// Redeem links a chat account using an invite code and returns the profile
+func (m *Manager) Redeem(code, channel, externalID string) (*Profile, error) {
+	var invite Invite
+	err := m.db.Where("code = ?", strings.ToUpper(strings.TrimSpace(code))).First(&invite).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("invalid invite code")
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Invites are single use, whether redeemed or expired
+	m.db.Delete(&invite)
+	if time.Now().After(invite.ExpiresAt) {
+		return nil, fmt.Errorf("invite code has expired")
+	}
+
+	profile, err := m.Get(invite.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("invited profile no longer exists")
+	}
+	if _, err := m.link(profile, channel, externalID); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (m *Manager) mustGet(name string) (*Profile, error) {
+	profile, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("profile not found: %s", name)
+	}
+	return profile, nil
+}
+
+func (m *Manager) ensureAnotherOwner(profileID string) error {
+	var owners int64
+	if err := m.db.Model(&Profile{}).Where("role = ? AND id <> ?", RoleOwner, profileID).Count(&owners).Error; err != nil {
+		return err
+	}
+	if owners == 0 {
+		return fmt.Errorf("the household needs at least one owner")
+	}
+	return nil
+}
+
+func newInviteCode() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%08X", uint32(time.Now().UnixNano()))
+	}
+	return strings.ToUpper(hex.EncodeToString(b))
+}
+
+type profileKey struct{}
+
+// WithProfile returns a context carrying the active profile
+func WithProfile(ctx context.Context, profile *Profile) context.Context {
+	if profile == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, profileKey{}, profile)
+}
+
+// FromContext returns the active profile, or nil
+func FromContext(ctx context.Context) *Profile {
+	profile, _ := ctx.Value(profileKey{}).(*Profile)
+	return profile
+}
+
+// UserID returns the user ID of the active profile, or SharedUserID without
+// one
+func UserID(ctx context.Context) string {
+	if profile := FromContext(ctx); profile != nil {
+		return profile.UserID
+	}
+	return SharedUserID
+}
+
+// ContextHook returns a function that scopes skill calls to the active
+// profile. Skills listed in shared read and write household data; all other
+// skills see the profile's private data. Calls without a profile are left
+// unchanged.
+func ContextHook(shared []string) func(ctx context.Context, skill string) context.Context {
+	sharedSet := make(map[string]bool, len(shared))
+	for _, s := range shared {
+		sharedSet[strings.ToLower(s)] = true
+	}
+
+	return func(ctx context.Context, skill string) context.Context {
+		profile := FromContext(ctx)
+		if profile == nil {
+			return ctx
+		}
+		userID := profile.UserID
+		if sharedSet[skill] {
+			userID = SharedUserID
+		}
+		return context.WithValue(ctx, "user_id", userID)
+	}
+}
+
+// PersonaPrompt returns the system prompt addition for the active profile,
+// or "" without one
+func PersonaPrompt(ctx context.Context) string {
+	profile := FromContext(ctx)
+	if profile == nil {
+		return ""
+	}
+	prompt := fmt.Sprintf("You are talking with %s, a %s of this household.", profile.Label(), profile.Role)
+	if profile.Persona != "" {
+		prompt += "\n\n" + profile.Persona
+	}
+	return prompt
+}
+
+// Command runs a household chat command for a chat account and returns the
+// reply. Supported commands are "whoami", "household", "invite <name>" and
+// "join <code>".
+func (m *Manager) Command(channel, externalID, command, args string) string {
+	fields := strings.Fields(args)
+
+	switch command {
+	case "join":
+		if len(fields) == 0 {
+			return "Usage: /join <code>\n\nAsk the household owner for an invite code."
+		}
+		profile, err := m.Redeem(fields[0], channel, externalID)
+		if err != nil {
+			return fmt.Sprintf("❌ %v", err)
+		}
+		return fmt.Sprintf("✅ Welcome, %s! This account is now linked to your profile.", profile.Label())
+	}
+
+	profile, err := m.ForChannel(channel, externalID)
+	if err != nil {
+		return "❌ Failed to load household profile."
+	}
+	if profile == nil {
+		return "👤 This account is not linked to a household profile.\nAsk the owner for an invite, then use /join <code>."
+	}
+
+	switch command {
+	case "whoami":
+		return fmt.Sprintf("👤 %s (%s, %s)", profile.Label(), profile.Name, profile.Role)
+
+	case "household":
+		profiles, err := m.List()
+		if err != nil {
+			return "❌ Failed to load household."
+		}
+		return FormatProfiles(profiles)
+
+	case "invite":
+		if len(fields) == 0 {
+			return "Usage: /invite <profile>\n\nCreate the profile first with: myrai household add <name>"
+		}
+		invite, err := m.CreateInvite(profile, fields[0])
+		if err != nil {
+			return fmt.Sprintf("❌ %v", err)
+		}
+		return fmt.Sprintf("✉️ Invite for %s: %s\n\nThey can send /join %s to link their account. The code expires in %d hours.",
+			strings.ToLower(fields[0]), invite.Code, invite.Code, int(InviteTTL.Hours()))
+	}
+
+	return "Usage: /whoami | /household | /invite <profile> | /join <code>"
+}
+
+// FormatProfiles renders profiles for display in a channel
+func FormatProfiles(profiles []Profile) string {
+	if len(profiles) == 0 {
+		return "🏠 No household profiles yet."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🏠 Household\n\n")
+	for _, p := range profiles {
+		icon := "👤"
+		if p.IsOwner() {
+			icon = "👑"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s (%s)", icon, p.Label(), p.Name))
+		if p.Persona != "" {
+			sb.WriteString(" - custom persona")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
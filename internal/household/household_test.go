@@ -0,0 +1,148 @@
+package household
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTestManager(t *testing.T) *Manager {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	m, err := NewManager(db)
+	require.NoError(t, err)
+	return m
+}
+
+func TestManager_Profiles(t *testing.T) {
+	m := setupTestManager(t)
+	assert.False(t, m.Active())
+
+	// The first profile becomes the owner and keeps the existing data
+	alice, err := m.Add("alice", "Alice", RoleMember)
+	require.NoError(t, err)
+	assert.Equal(t, RoleOwner, alice.Role)
+	assert.Equal(t, SharedUserID, alice.UserID)
+	assert.True(t, m.Active())
+
+	bob, err := m.Add("Bob", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", bob.Name)
+	assert.Equal(t, RoleMember, bob.Role)
+	assert.Equal(t, bob.ID, bob.UserID)
+
+	_, err = m.Add("bob", "", "")
+	assert.Error(t, err)
+	_, err = m.Add("Not Valid", "", "")
+	assert.Error(t, err)
+
+	// The last owner can be neither demoted nor removed
+	_, err = m.SetRole("alice", RoleMember)
+	assert.Error(t, err)
+	assert.Error(t, m.Remove("alice"))
+
+	_, err = m.SetRole("bob", RoleOwner)
+	require.NoError(t, err)
+	_, err = m.SetRole("alice", RoleMember)
+	require.NoError(t, err)
+
+	profiles, err := m.List()
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+	assert.Equal(t, "bob", profiles[0].Name)
+
+	require.NoError(t, m.Remove("alice"))
+	assert.Error(t, m.Remove("alice"))
+}
+
+func TestManager_InviteAndLinks(t *testing.T) {
+	m := setupTestManager(t)
+	owner, err := m.Add("mum", "", "")
+	require.NoError(t, err)
+	kid, err := m.Add("kid", "", "")
+	require.NoError(t, err)
+
+	_, err = m.CreateInvite(kid, "kid")
+	assert.Error(t, err, "members cannot invite")
+
+	invite, err := m.CreateInvite(owner, "kid")
+	require.NoError(t, err)
+
+	profile, err := m.Redeem(invite.Code, "telegram", "42")
+	require.NoError(t, err)
+	assert.Equal(t, kid.ID, profile.ID)
+
+	// Codes are single use
+	_, err = m.Redeem(invite.Code, "telegram", "43")
+	assert.Error(t, err)
+
+	linked, err := m.ForChannel("telegram", "42")
+	require.NoError(t, err)
+	require.NotNil(t, linked)
+	assert.Equal(t, "kid", linked.Name)
+
+	// Relinking an account moves it to the new profile
+	_, err = m.Link("mum", "telegram", "42")
+	require.NoError(t, err)
+	linked, _ = m.ForChannel("telegram", "42")
+	assert.Equal(t, "mum", linked.Name)
+
+	require.NoError(t, m.Unlink("telegram", "42"))
+	linked, err = m.ForChannel("telegram", "42")
+	require.NoError(t, err)
+	assert.Nil(t, linked)
+
+	expired, err := m.CreateInvite(owner, "kid")
+	require.NoError(t, err)
+	require.NoError(t, m.db.Model(expired).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	_, err = m.Redeem(expired.Code, "discord", "7")
+	assert.Error(t, err)
+}
+
+func TestManager_Command(t *testing.T) {
+	m := setupTestManager(t)
+	_, err := m.Add("mum", "Mum", "")
+	require.NoError(t, err)
+	_, err = m.Add("kid", "", "")
+	require.NoError(t, err)
+	_, err = m.Link("mum", "discord", "1")
+	require.NoError(t, err)
+
+	assert.Contains(t, m.Command("discord", "2", "whoami", ""), "not linked")
+
+	reply := m.Command("discord", "1", "invite", "kid")
+	require.Contains(t, reply, "/join ")
+	invite := &Invite{}
+	require.NoError(t, m.db.First(invite).Error)
+
+	assert.Contains(t, m.Command("discord", "2", "join", invite.Code), "Welcome, kid")
+	assert.Contains(t, m.Command("discord", "2", "whoami", ""), "kid (kid, member)")
+	assert.Contains(t, m.Command("discord", "2", "invite", "mum"), "only the household owner")
+	assert.Contains(t, m.Command("discord", "2", "household", ""), "👑 Mum (mum)")
+}
+
+func TestContextHook(t *testing.T) {
+	hook := ContextHook(DefaultShared)
+	kid := &Profile{ID: "prof_kid", Name: "kid", Role: RoleMember, UserID: "prof_kid", Persona: "Keep answers short."}
+
+	// Without a profile the caller's context is untouched
+	plain := hook(context.Background(), "health")
+	assert.Nil(t, plain.Value("user_id"))
+
+	ctx := WithProfile(context.Background(), kid)
+	assert.Equal(t, SharedUserID, hook(ctx, "shopping").Value("user_id"))
+	assert.Equal(t, SharedUserID, hook(ctx, "calendar").Value("user_id"))
+	assert.Equal(t, "prof_kid", hook(ctx, "health").Value("user_id"))
+	assert.Equal(t, "prof_kid", UserID(ctx))
+	assert.Equal(t, SharedUserID, UserID(context.Background()))
+
+	prompt := PersonaPrompt(ctx)
+	assert.Contains(t, prompt, "talking with kid")
+	assert.Contains(t, prompt, "Keep answers short.")
+	assert.Empty(t, PersonaPrompt(context.Background()))
+}
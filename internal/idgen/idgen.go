@@ -48,4 +48,8 @@ const (
 	PrefixEvent        = "evt"
 	PrefixProject      = "proj"
 	PrefixUser         = "usr"
+	PrefixContact      = "cont"
+	PrefixEmailDraft   = "mail"
+	PrefixPatch        = "patch"
+	PrefixImage        = "img"
 )
@@ -71,6 +71,7 @@ func TestConstants(t *testing.T) {
 		PrefixEvent,
 		PrefixProject,
 		PrefixUser,
+		PrefixContact,
 	}
 
 	for _, c := range constants {
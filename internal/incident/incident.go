@@ -0,0 +1,230 @@
+// Package incident provides a runtime diagnostics switch. While incident
+// mode is on, logs are written at debug level, API requests are traced and
+// tool calls are dumped with their results; it turns itself off after a set
+// time so a forgotten switch cannot flood the logs.
+package incident
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Duration limits
+const (
+	DefaultDuration = 15 * time.Minute
+	MaxDuration     = 4 * time.Hour
+)
+
+// DumpLimit caps how much of a tool's arguments or result is logged
+const DumpLimit = 4000
+
+// Status describes the current incident mode
+type Status struct {
+	Active    bool      `json:"active"`
+	Until     time.Time `json:"until,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Level     string    `json:"level"`
+	BaseLevel string    `json:"base_level"`
+}
+
+// Mode switches verbose diagnostics on and off. A nil *Mode is valid and
+// never active.
+type Mode struct {
+	level  zap.AtomicLevel
+	logger *zap.Logger
+
+	mu     sync.Mutex
+	base   zapcore.Level
+	until  time.Time
+	reason string
+	timer  *time.Timer
+	gen    int // invalidates timers from earlier activations
+}
+
+// New creates a switch over level. The level's current value is the one
+// restored when incident mode ends.
+func New(level zap.AtomicLevel, logger *zap.Logger) *Mode {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Mode{level: level, logger: logger, base: level.Level()}
+}
+
+// SetBaseLevel changes the level used outside incident mode
+func (m *Mode) SetBaseLevel(l zapcore.Level) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.base = l
+	if m.until.IsZero() {
+		m.level.SetLevel(l)
+	}
+}
+
+// Enable turns incident mode on for d, or extends it if already on. Zero
+// means DefaultDuration; longer than MaxDuration is an error.
+func (m *Mode) Enable(d time.Duration, reason string) (time.Time, error) {
+	if d == 0 {
+		d = DefaultDuration
+	}
+	if d < 0 || d > MaxDuration {
+		return time.Time{}, fmt.Errorf("duration must be between 1 minute and %s", formatDuration(MaxDuration))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+	m.gen++
+	gen := m.gen
+	m.until = time.Now().Add(d)
+	m.reason = strings.TrimSpace(reason)
+	m.level.SetLevel(zapcore.DebugLevel)
+	m.timer = time.AfterFunc(d, func() { m.expire(gen) })
+
+	m.logger.Warn("Incident mode enabled",
+		zap.Duration("duration", d),
+		zap.Time("until", m.until),
+		zap.String("reason", m.reason))
+	return m.until, nil
+}
+
+// Disable turns incident mode off. It reports whether it was on.
+func (m *Mode) Disable() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.until.IsZero() {
+		return false
+	}
+	m.gen++
+	m.reset()
+	m.logger.Warn("Incident mode disabled")
+	return true
+}
+
+func (m *Mode) expire(gen int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if gen != m.gen {
+		return
+	}
+	m.reset()
+	m.logger.Warn("Incident mode expired")
+}
+
+// reset restores normal logging; callers hold mu
+func (m *Mode) reset() {
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+	m.until = time.Time{}
+	m.reason = ""
+	m.level.SetLevel(m.base)
+}
+
+// Active reports whether incident mode is on
+func (m *Mode) Active() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return !m.until.IsZero()
+}
+
+// Status returns the current state
+func (m *Mode) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return Status{
+		Active:    !m.until.IsZero(),
+		Until:     m.until,
+		Reason:    m.reason,
+		Level:     m.level.Level().String(),
+		BaseLevel: m.base.String(),
+	}
+}
+
+// Command runs an "/incident" chat command and returns the reply. Forms are
+// "on [minutes] [reason]", "off" and "status".
+func (m *Mode) Command(args string) string {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		fields = []string{"status"}
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "on", "start", "enable":
+		d := DefaultDuration
+		rest := fields[1:]
+		if len(rest) > 0 {
+			if minutes, err := strconv.Atoi(rest[0]); err == nil {
+				d = time.Duration(minutes) * time.Minute
+				rest = rest[1:]
+			}
+		}
+		if d <= 0 {
+			return "❌ Duration must be at least 1 minute."
+		}
+		until, err := m.Enable(d, strings.Join(rest, " "))
+		if err != nil {
+			return fmt.Sprintf("❌ %v", err)
+		}
+		return fmt.Sprintf("🚨 Incident mode on until %s: debug logs, request tracing and tool-call dumps.\nTurns off automatically, or use /incident off.",
+			until.Format("15:04"))
+
+	case "off", "stop", "disable":
+		if !m.Disable() {
+			return "Incident mode is already off."
+		}
+		return "✅ Incident mode off. Logging is back to normal."
+
+	case "status":
+		return m.Status().String()
+	}
+
+	return "Usage: /incident on [minutes] [reason] | /incident off | /incident status"
+}
+
+// String renders the status for a chat reply
+func (s Status) String() string {
+	if !s.Active {
+		return fmt.Sprintf("✅ Incident mode off (log level %s).", s.BaseLevel)
+	}
+	msg := fmt.Sprintf("🚨 Incident mode on for another %s (log level %s, normally %s).",
+		formatDuration(time.Until(s.Until)), s.Level, s.BaseLevel)
+	if s.Reason != "" {
+		msg += "\nReason: " + s.Reason
+	}
+	return msg
+}
+
+// Truncate shortens s to DumpLimit bytes for logging
+func Truncate(s string) string {
+	if len(s) <= DumpLimit {
+		return s
+	}
+	return s[:DumpLimit] + fmt.Sprintf("... (%d bytes truncated)", len(s)-DumpLimit)
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "less than a minute"
+	}
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	if d > time.Hour {
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
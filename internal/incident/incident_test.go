@@ -0,0 +1,99 @@
+package incident
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestMode_EnableDisable(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	m := New(level, nil)
+	assert.False(t, m.Active())
+
+	until, err := m.Enable(0, "slow replies")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(DefaultDuration), until, time.Second)
+	assert.True(t, m.Active())
+	assert.Equal(t, zapcore.DebugLevel, level.Level())
+
+	status := m.Status()
+	assert.Equal(t, "slow replies", status.Reason)
+	assert.Equal(t, "info", status.BaseLevel)
+
+	assert.True(t, m.Disable())
+	assert.False(t, m.Disable())
+	assert.False(t, m.Active())
+	assert.Equal(t, zapcore.InfoLevel, level.Level())
+
+	_, err = m.Enable(MaxDuration+time.Minute, "")
+	assert.Error(t, err)
+}
+
+func TestMode_Expires(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zapcore.WarnLevel)
+	m := New(level, nil)
+
+	_, err := m.Enable(20*time.Millisecond, "")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return !m.Active() }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, zapcore.WarnLevel, level.Level())
+}
+
+func TestMode_ExtendIgnoresEarlierTimer(t *testing.T) {
+	m := New(zap.NewAtomicLevelAt(zapcore.InfoLevel), nil)
+
+	_, err := m.Enable(20*time.Millisecond, "")
+	require.NoError(t, err)
+	_, err = m.Enable(time.Hour, "")
+	require.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, m.Active())
+	m.Disable()
+}
+
+func TestMode_SetBaseLevel(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	m := New(level, nil)
+
+	m.SetBaseLevel(zapcore.InfoLevel)
+	assert.Equal(t, zapcore.InfoLevel, level.Level())
+
+	_, err := m.Enable(time.Minute, "")
+	require.NoError(t, err)
+	m.SetBaseLevel(zapcore.WarnLevel)
+	assert.Equal(t, zapcore.DebugLevel, level.Level())
+
+	m.Disable()
+	assert.Equal(t, zapcore.WarnLevel, level.Level())
+}
+
+func TestMode_Command(t *testing.T) {
+	m := New(zap.NewAtomicLevelAt(zapcore.InfoLevel), nil)
+
+	assert.Contains(t, m.Command(""), "off")
+	assert.Contains(t, m.Command("on 30 tool errors"), "Incident mode on")
+	assert.Equal(t, "tool errors", m.Status().Reason)
+	assert.Contains(t, m.Command("status"), "Reason: tool errors")
+	assert.Contains(t, m.Command("on 0"), "❌")
+	assert.Contains(t, m.Command("on 999"), "❌")
+	assert.Contains(t, m.Command("off"), "back to normal")
+	assert.Contains(t, m.Command("sideways"), "Usage:")
+}
+
+func TestNilMode(t *testing.T) {
+	var m *Mode
+	assert.False(t, m.Active())
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", Truncate("short"))
+	long := strings.Repeat("x", DumpLimit+10)
+	assert.True(t, strings.HasSuffix(Truncate(long), "(10 bytes truncated)"))
+}
@@ -0,0 +1,291 @@
+// Package journal keeps a human-readable record of what the assistant did on
+// its own: scheduled jobs, plan steps, messages it sent unprompted, files it
+// changed and commands it ran. It answers "what did you do today?".
+package journal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/strutil"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PrefixEntry is the ID prefix for journal entries
+const PrefixEntry = "act"
+
+// Entry kinds
+const (
+	KindScheduled = "scheduled" // a cron job ran
+	KindPlan      = "plan"      // a plan step ran
+	KindMessage   = "message"   // a notification was sent or queued
+	KindFile      = "file"      // a file was written
+	KindCommand   = "command"   // a shell command ran
+	KindTrigger   = "trigger"   // an outside event ran a prompt
+	KindFiltered  = "filtered"  // a reply was filtered before delivery
+)
+
+// Kinds lists every entry kind
+var Kinds = []string{KindScheduled, KindPlan, KindMessage, KindFile, KindCommand, KindTrigger, KindFiltered}
+
+// Entry statuses
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+// Retention is how long entries are kept
+const Retention = 90 * 24 * time.Hour
+
+// Entry is one thing the assistant did
+type Entry struct {
+	ID        string    `gorm:"primaryKey" json:"id"`
+	UserID    string    `gorm:"index;not null" json:"user_id"`
+	Kind      string    `gorm:"index;not null" json:"kind"`
+	Summary   string    `gorm:"not null" json:"summary"`
+	Detail    string    `gorm:"type:text" json:"detail,omitempty"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName sets the table name
+func (Entry) TableName() string { return "activity_journal" }
+
+// Journal records and lists entries. A nil *Journal records nothing, so
+// callers don't need to check whether one is configured.
+type Journal struct {
+	db     *gorm.DB
+	logger *zap.Logger
+	now    func() time.Time
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func init() {
+	store.RegisterMigrations("journal", store.Migration{
+		Version: 1,
+		Name:    "create activity journal",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Entry{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Entry{})
+		},
+	})
+}
+
+// New creates a journal, migrating its table
+func New(db *gorm.DB, logger *zap.Logger) (*Journal, error) {
+	if err := store.Migrate(db, "journal"); err != nil {
+		return nil, fmt.Errorf("failed to migrate activity journal: %w", err)
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Journal{db: db, logger: logger, now: time.Now}, nil
+}
+
+// Record adds an entry. A non-nil err marks it failed and is appended to the
+// detail. Failures to save are logged, never returned: the journal must not
+// get in the way of the action it describes.
+func (j *Journal) Record(userID, kind, summary, detail string, err error) {
+	if j == nil {
+		return
+	}
+	entry := &Entry{
+		ID:        idgen.Generate(PrefixEntry),
+		UserID:    userID,
+		Kind:      kind,
+		Summary:   summary,
+		Detail:    detail,
+		Status:    StatusOK,
+		CreatedAt: j.now(),
+	}
+	if err != nil {
+		entry.Status = StatusFailed
+		entry.Detail = strings.TrimSpace(entry.Detail + "\nError: " + err.Error())
+	}
+	if dbErr := j.db.Create(entry).Error; dbErr != nil {
+		j.logger.Warn("Failed to record activity",
+			zap.String("kind", kind),
+			zap.String("summary", summary),
+			zap.Error(dbErr))
+	}
+}
+
+// Filter selects entries to list
+type Filter struct {
+	UserIDs []string // empty for everyone
+	Kind    string   // empty for every kind
+	Since   time.Time
+	Until   time.Time // zero for now
+	Limit   int       // zero for no limit
+}
+
+// List returns matching entries, oldest first
+func (j *Journal) List(f Filter) ([]Entry, error) {
+	query := j.db.Model(&Entry{})
+	if len(f.UserIDs) > 0 {
+		query = query.Where("user_id IN ?", f.UserIDs)
+	}
+	if f.Kind != "" {
+		query = query.Where("kind = ?", f.Kind)
+	}
+	if !f.Since.IsZero() {
+		query = query.Where("created_at >= ?", f.Since)
+	}
+	if !f.Until.IsZero() {
+		query = query.Where("created_at < ?", f.Until)
+	}
+	if f.Limit > 0 {
+		// Keep the most recent entries when limiting
+		var entries []Entry
+		if err := query.Order("created_at DESC").Limit(f.Limit).Find(&entries).Error; err != nil {
+			return nil, err
+		}
+		for i, k := 0, len(entries)-1; i < k; i, k = i+1, k-1 {
+			entries[i], entries[k] = entries[k], entries[i]
+		}
+		return entries, nil
+	}
+
+	var entries []Entry
+	err := query.Order("created_at ASC").Find(&entries).Error
+	return entries, err
+}
+
+// Prune deletes entries older than Retention
+func (j *Journal) Prune() error {
+	return j.db.Where("created_at < ?", j.now().Add(-Retention)).Delete(&Entry{}).Error
+}
+
+// Format renders entries as one line each, grouped by day when they span
+// more than one
+func Format(entries []Entry, loc locale.Locale) string {
+	if len(entries) == 0 {
+		return "Nothing recorded."
+	}
+
+	multiDay := !sameDay(entries[0].CreatedAt, entries[len(entries)-1].CreatedAt)
+	var sb strings.Builder
+	var day time.Time
+	for i, e := range entries {
+		if multiDay && (i == 0 || !sameDay(day, e.CreatedAt)) {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(loc.WeekdayDate(e.CreatedAt) + "\n")
+			day = e.CreatedAt
+		}
+		icon := "•"
+		if e.Status == StatusFailed {
+			icon = "✗"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", loc.Time(e.CreatedAt), icon, e.Summary))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// DailySection returns the activity part of a briefing for one day, or ""
+// when nothing was recorded
+func (j *Journal) DailySection(userIDs []string, day time.Time, loc locale.Locale) (string, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	entries, err := j.List(Filter{UserIDs: userIDs, Since: start, Until: start.AddDate(0, 0, 1)})
+	if err != nil || len(entries) == 0 {
+		return "", err
+	}
+
+	failed := 0
+	for _, e := range entries {
+		if e.Status == StatusFailed {
+			failed++
+		}
+	}
+	header := fmt.Sprintf("🗒 What I did today (%d %s", len(entries), plural(len(entries), "action", "actions"))
+	if failed > 0 {
+		header += fmt.Sprintf(", %d failed", failed)
+	}
+	return header + ")\n" + Format(entries, loc), nil
+}
+
+// UsersActiveOn returns the users with entries on day
+func (j *Journal) UsersActiveOn(day time.Time) ([]string, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	var users []string
+	err := j.db.Model(&Entry{}).
+		Where("created_at >= ? AND created_at < ?", start, start.AddDate(0, 0, 1)).
+		Distinct().Pluck("user_id", &users).Error
+	return users, err
+}
+
+// StartDaily calls fn every day at the local time at ("20:00") until Stop
+// is called, and prunes old entries at the same time
+func (j *Journal) StartDaily(at string, fn func(ctx context.Context, day time.Time)) error {
+	clock, err := time.Parse("15:04", at)
+	if err != nil {
+		return fmt.Errorf("invalid time %q: use HH:MM", at)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		for {
+			timer := time.NewTimer(time.Until(nextAt(j.now(), clock)))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case now := <-timer.C:
+				if err := j.Prune(); err != nil {
+					j.logger.Warn("Failed to prune activity journal", zap.Error(err))
+				}
+				fn(ctx, now)
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops the daily loop
+func (j *Journal) Stop() {
+	if j != nil && j.cancel != nil {
+		j.cancel()
+		j.wg.Wait()
+	}
+}
+
+// nextAt returns the next time after now with clock's hour and minute
+func nextAt(now, clock time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// Summarize shortens s to one line for an entry summary
+func Summarize(s string, max int) string {
+	return strutil.Truncate(strings.Join(strings.Fields(s), " "), max)
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
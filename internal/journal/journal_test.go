@@ -0,0 +1,129 @@
+package journal
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupJournal(t *testing.T) *Journal {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	j, err := New(db, nil)
+	require.NoError(t, err)
+	return j
+}
+
+func TestJournal_RecordAndList(t *testing.T) {
+	j := setupJournal(t)
+	now := time.Date(2025, 3, 4, 9, 0, 0, 0, time.Local)
+	j.now = func() time.Time { return now }
+
+	j.Record("alice", KindScheduled, `Ran scheduled job "backup"`, "done", nil)
+	now = now.Add(time.Hour)
+	j.Record("alice", KindCommand, "Ran `rm -rf /tmp/x`", "", errors.New("permission denied"))
+	now = now.Add(time.Hour)
+	j.Record("bob", KindFile, "Wrote notes.txt", "", nil)
+
+	entries, err := j.List(Filter{UserIDs: []string{"alice"}})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, KindScheduled, entries[0].Kind)
+	assert.Equal(t, StatusFailed, entries[1].Status)
+	assert.Contains(t, entries[1].Detail, "Error: permission denied")
+
+	entries, err = j.List(Filter{Kind: KindFile})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "bob", entries[0].UserID)
+
+	// Limit keeps the most recent, still oldest first
+	entries, err = j.List(Filter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, KindCommand, entries[0].Kind)
+	assert.Equal(t, KindFile, entries[1].Kind)
+
+	users, err := j.UsersActiveOn(now)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"alice", "bob"}, users)
+}
+
+func TestJournal_Prune(t *testing.T) {
+	j := setupJournal(t)
+	now := time.Now()
+	j.now = func() time.Time { return now.Add(-Retention - time.Hour) }
+	j.Record("alice", KindPlan, "old", "", nil)
+	j.now = func() time.Time { return now }
+	j.Record("alice", KindPlan, "new", "", nil)
+
+	require.NoError(t, j.Prune())
+	entries, err := j.List(Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "new", entries[0].Summary)
+}
+
+func TestJournal_DailySection(t *testing.T) {
+	j := setupJournal(t)
+	day := time.Date(2025, 3, 4, 8, 30, 0, 0, time.Local)
+	j.now = func() time.Time { return day }
+	j.Record("alice", KindScheduled, `Ran scheduled job "digest"`, "", nil)
+	j.now = func() time.Time { return day.Add(2 * time.Hour) }
+	j.Record("alice", KindMessage, `Sent tasks notification "Report due"`, "", errors.New("offline"))
+
+	loc := locale.Default()
+	section, err := j.DailySection([]string{"alice"}, day, loc)
+	require.NoError(t, err)
+	assert.Contains(t, section, "What I did today (2 actions, 1 failed)")
+	assert.Contains(t, section, `• Ran scheduled job "digest"`)
+	assert.Contains(t, section, `✗ Sent tasks notification "Report due"`)
+
+	section, err = j.DailySection([]string{"alice"}, day.AddDate(0, 0, 1), loc)
+	require.NoError(t, err)
+	assert.Empty(t, section)
+}
+
+func TestFormat(t *testing.T) {
+	loc := locale.Default()
+	assert.Equal(t, "Nothing recorded.", Format(nil, loc))
+
+	first := time.Date(2025, 3, 3, 9, 0, 0, 0, time.Local)
+	text := Format([]Entry{
+		{Summary: "one", Status: StatusOK, CreatedAt: first},
+		{Summary: "two", Status: StatusOK, CreatedAt: first.AddDate(0, 0, 1)},
+	}, loc)
+	assert.Contains(t, text, loc.WeekdayDate(first))
+	assert.Contains(t, text, loc.WeekdayDate(first.AddDate(0, 0, 1)))
+}
+
+func TestNilJournal(t *testing.T) {
+	var j *Journal
+	j.Record("alice", KindFile, "Wrote x", "", nil)
+	j.Stop()
+}
+
+func TestNextAt(t *testing.T) {
+	clock, _ := time.Parse("15:04", "20:00")
+	morning := time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 4, 20, 0, 0, 0, time.UTC), nextAt(morning, clock))
+	evening := time.Date(2025, 3, 4, 20, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 5, 20, 0, 0, 0, time.UTC), nextAt(evening, clock))
+}
+
+func TestStartDaily_InvalidTime(t *testing.T) {
+	j := setupJournal(t)
+	assert.Error(t, j.StartDaily("8pm", nil))
+}
+
+func TestSummarize(t *testing.T) {
+	assert.Equal(t, "a b", Summarize("a\n  b", 10))
+	assert.Equal(t, "abcdefg...", Summarize("abcdefghijklmnop", 10))
+	assert.Equal(t, "ééééééé...", Summarize("éééééééééééé", 10))
+}
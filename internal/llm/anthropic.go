@@ -0,0 +1,315 @@
+package llm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+// anthropicVersion is the Messages API version requests are made against
+const anthropicVersion = "2023-06-01"
+
+// anthropicProtocol speaks the Anthropic Messages API
+type anthropicProtocol struct{}
+
+type anthropicRequest struct {
+	Model       string               `json:"model"`
+	MaxTokens   int                  `json:"max_tokens"`
+	System      string               `json:"system,omitempty"`
+	Messages    []anthropicMessage   `json:"messages"`
+	Tools       []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice  *anthropicToolChoice `json:"tool_choice,omitempty"`
+	Temperature *float64             `json:"temperature,omitempty"`
+	Stream      bool                 `json:"stream,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []anthropicBlock `json:"content"`
+}
+
+// anthropicBlock is a content block of any type; only the fields of its
+// type are set
+type anthropicBlock struct {
+	Type      string                `json:"type"`
+	Text      string                `json:"text,omitempty"`
+	Thinking  string                `json:"thinking,omitempty"`
+	Source    *anthropicImageSource `json:"source,omitempty"`
+	ID        string                `json:"id,omitempty"`
+	Name      string                `json:"name,omitempty"`
+	Input     json.RawMessage       `json:"input,omitempty"`
+	ToolUseID string                `json:"tool_use_id,omitempty"`
+	Content   string                `json:"content,omitempty"`
+}
+
+type anthropicImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type                   string `json:"type"`
+	Name                   string `json:"name,omitempty"`
+	DisableParallelToolUse bool   `json:"disable_parallel_tool_use,omitempty"`
+}
+
+type anthropicResponse struct {
+	ID         string           `json:"id"`
+	Model      string           `json:"model"`
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+	Usage      anthropicUsage   `json:"usage"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+func (anthropicProtocol) newRequest(ctx context.Context, provider config.Provider, req ChatRequest) (*http.Request, error) {
+	body := anthropicRequest{
+		Model:     req.Model,
+		MaxTokens: req.MaxTokens,
+		Stream:    req.Stream,
+	}
+	if body.MaxTokens <= 0 {
+		// Required by the API
+		body.MaxTokens = defaultResponseTokens
+	}
+	if req.Temperature > 0 {
+		// Claude's temperature only goes up to 1
+		t := min(req.Temperature, 1)
+		body.Temperature = &t
+	}
+	body.System, body.Messages = anthropicMessages(req.Messages)
+
+	for _, tool := range req.Tools {
+		schema := tool.Function.Parameters
+		if schema == nil {
+			schema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+		body.Tools = append(body.Tools, anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: schema,
+		})
+	}
+	if len(body.Tools) > 0 {
+		switch mode, name := toolChoice(req.ToolChoice); mode {
+		case "none":
+			body.ToolChoice = &anthropicToolChoice{Type: "none"}
+		case "required":
+			body.ToolChoice = &anthropicToolChoice{Type: "any"}
+		case "function":
+			body.ToolChoice = &anthropicToolChoice{Type: "tool", Name: name}
+		default:
+			body.ToolChoice = &anthropicToolChoice{Type: "auto"}
+		}
+		body.ToolChoice.DisableParallelToolUse = !req.ParallelToolCalls && body.ToolChoice.Type != "none"
+	}
+
+	httpReq, err := newJSONRequest(ctx, provider.BaseURL+"/messages", body, req.Stream)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("x-api-key", provider.APIKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	return httpReq, nil
+}
+
+// anthropicMessages converts chat messages to Claude's: system messages go
+// in the system prompt, tool results are sent by the user, and consecutive
+// messages from the same role are merged since roles must alternate.
+// Reasoning isn't sent back as Claude only accepts its own signed thinking.
+func anthropicMessages(msgs []Message) (string, []anthropicMessage) {
+	var system []string
+	var out []anthropicMessage
+	for _, msg := range msgs {
+		role := "user"
+		var blocks []anthropicBlock
+		switch msg.Role {
+		case "system":
+			if msg.Content != "" {
+				system = append(system, msg.Content)
+			}
+			continue
+		case "tool":
+			blocks = append(blocks, anthropicBlock{Type: "tool_result", ToolUseID: msg.ToolCallID, Content: msg.Content})
+		case "assistant":
+			role = "assistant"
+			if msg.Content != "" {
+				blocks = append(blocks, anthropicBlock{Type: "text", Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				blocks = append(blocks, anthropicBlock{
+					Type:  "tool_use",
+					ID:    tc.ID,
+					Name:  tc.Function.Name,
+					Input: toolArguments(tc.Function.Arguments),
+				})
+			}
+		default:
+			for _, img := range msg.Images {
+				source := &anthropicImageSource{Type: "url", URL: img.URL}
+				if len(img.Data) > 0 {
+					source = &anthropicImageSource{Type: "base64", MediaType: img.MIMEType, Data: base64.StdEncoding.EncodeToString(img.Data)}
+				}
+				blocks = append(blocks, anthropicBlock{Type: "image", Source: source})
+			}
+			if msg.Content != "" {
+				blocks = append(blocks, anthropicBlock{Type: "text", Text: msg.Content})
+			}
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+
+		if n := len(out); n > 0 && out[n-1].Role == role {
+			out[n-1].Content = append(out[n-1].Content, blocks...)
+		} else {
+			out = append(out, anthropicMessage{Role: role, Content: blocks})
+		}
+	}
+	return strings.Join(system, "\n\n"), out
+}
+
+// anthropicFinishReason maps a stop reason to the chat completions one
+func anthropicFinishReason(reason string) string {
+	switch reason {
+	case "":
+		return ""
+	case "tool_use":
+		return "tool_calls"
+	case "max_tokens":
+		return "length"
+	case "refusal":
+		return "content_filter"
+	}
+	return "stop"
+}
+
+func (anthropicProtocol) decode(r io.Reader) (*ChatResponse, error) {
+	var resp anthropicResponse
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return nil, err
+	}
+
+	msg := Message{Role: "assistant"}
+	var texts, thoughts []string
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			texts = append(texts, block.Text)
+		case "thinking":
+			thoughts = append(thoughts, block.Thinking)
+		case "tool_use":
+			msg.ToolCalls = append(msg.ToolCalls, newToolCall(block.ID, block.Name, string(toolArguments(string(block.Input))), nil))
+		}
+	}
+	msg.Content = strings.Join(texts, "")
+	msg.ReasoningContent = strings.Join(thoughts, "\n")
+
+	return &ChatResponse{
+		ID:      resp.ID,
+		Object:  "chat.completion",
+		Model:   resp.Model,
+		Choices: []Choice{{Message: msg, FinishReason: anthropicFinishReason(resp.StopReason)}},
+		Usage: Usage{
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
+		},
+	}, nil
+}
+
+// anthropicEvent is a streamed event of any type
+type anthropicEvent struct {
+	Type         string             `json:"type"`
+	Index        int                `json:"index"`
+	Message      *anthropicResponse `json:"message"`
+	ContentBlock *anthropicBlock    `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (anthropicProtocol) stream(r io.Reader, callback StreamCallback) error {
+	var id, model string
+	tools := make(map[int]int) // content block index to tool call index
+
+	emit := func(delta Delta, finishReason string) error {
+		return callback(StreamResponse{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Model:   model,
+			Choices: []StreamChoice{{Delta: delta, FinishReason: finishReason}},
+		})
+	}
+
+	return readSSE(r, func(data string) error {
+		var event anthropicEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil // Skip malformed events
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				id, model = event.Message.ID, event.Message.Model
+			}
+			return emit(Delta{Role: "assistant"}, "")
+		case "content_block_start":
+			block := event.ContentBlock
+			switch {
+			case block == nil:
+			case block.Type == "tool_use":
+				index := len(tools)
+				tools[event.Index] = index
+				return emit(Delta{ToolCalls: []ToolCall{newToolCall(block.ID, block.Name, "", &index)}}, "")
+			case block.Type == "text" && block.Text != "":
+				return emit(Delta{Content: block.Text}, "")
+			}
+		case "content_block_delta":
+			switch event.Delta.Type {
+			case "text_delta":
+				return emit(Delta{Content: event.Delta.Text}, "")
+			case "input_json_delta":
+				if index, ok := tools[event.Index]; ok && event.Delta.PartialJSON != "" {
+					return emit(Delta{ToolCalls: []ToolCall{newToolCall("", "", event.Delta.PartialJSON, &index)}}, "")
+				}
+			}
+		case "message_delta":
+			if event.Delta.StopReason != "" {
+				return emit(Delta{}, anthropicFinishReason(event.Delta.StopReason))
+			}
+		case "message_stop":
+			return errStreamDone
+		case "error":
+			if event.Error != nil {
+				return fmt.Errorf("API error (%s): %s", event.Error.Type, event.Error.Message)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,133 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+func TestProtocolFor(t *testing.T) {
+	for _, tc := range []struct {
+		provider config.Provider
+		want     protocol
+	}{
+		{config.Provider{BaseURL: "https://api.openai.com/v1"}, openAIProtocol{}},
+		{config.Provider{BaseURL: "https://api.anthropic.com/v1"}, anthropicProtocol{}},
+		{config.Provider{BaseURL: "https://generativelanguage.googleapis.com/v1beta"}, geminiProtocol{}},
+		{config.Provider{BaseURL: "https://generativelanguage.googleapis.com/v1beta/openai"}, openAIProtocol{}},
+		{config.Provider{BaseURL: "https://api.anthropic.com/v1", API: "openai"}, openAIProtocol{}},
+		{config.Provider{BaseURL: "https://proxy.internal/claude", API: "Anthropic"}, anthropicProtocol{}},
+	} {
+		if got := protocolFor(tc.provider); got != tc.want {
+			t.Errorf("protocolFor(%+v) = %T, want %T", tc.provider, got, tc.want)
+		}
+	}
+}
+
+func TestAnthropic_ChatCompletion(t *testing.T) {
+	var sent anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" || r.Header.Get("x-api-key") != "secret" || r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("Unexpected request %s with headers %v", r.URL.Path, r.Header)
+		}
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"id":"msg_1","model":"claude-sonnet-4","stop_reason":"tool_use",
+			"content":[{"type":"thinking","thinking":"Need the weather"},{"type":"text","text":"Checking."},
+				{"type":"tool_use","id":"toolu_2","name":"weather","input":{"city":"Oslo"}}],
+			"usage":{"input_tokens":30,"output_tokens":12}}`))
+	}))
+	defer server.Close()
+
+	call := newToolCall("toolu_1", "time", `{"zone":"UTC"}`, nil)
+	client := NewClient(config.Provider{BaseURL: server.URL, APIKey: "secret", API: APIAnthropic})
+	resp, err := client.ChatCompletion(context.Background(), ChatRequest{
+		Model: "claude-sonnet-4",
+		Messages: []Message{
+			{Role: "system", Content: "Be brief."},
+			{Role: "user", Content: "Time and weather in Oslo?"},
+			{Role: "assistant", ToolCalls: []ToolCall{call}},
+			{Role: "tool", ToolCallID: "toolu_1", Content: "12:00"},
+			{Role: "user", Content: "Thanks"},
+		},
+		Tools:       []Tool{{Type: "function", Function: ToolFunction{Name: "weather", Parameters: map[string]interface{}{"type": "object"}}}},
+		Temperature: 1.5,
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+
+	if sent.System != "Be brief." || sent.MaxTokens != defaultResponseTokens || *sent.Temperature != 1 {
+		t.Errorf("Expected the system prompt, default max_tokens and a capped temperature, got %+v", sent)
+	}
+	if len(sent.Messages) != 3 {
+		t.Fatalf("Expected user, assistant and merged user messages, got %+v", sent.Messages)
+	}
+	if use := sent.Messages[1].Content[0]; use.Type != "tool_use" || use.ID != "toolu_1" || string(use.Input) != `{"zone":"UTC"}` {
+		t.Errorf("Expected the tool call as tool_use, got %+v", use)
+	}
+	if blocks := sent.Messages[2].Content; len(blocks) != 2 || blocks[0].Type != "tool_result" || blocks[0].ToolUseID != "toolu_1" || blocks[1].Text != "Thanks" {
+		t.Errorf("Expected the tool result and message together, got %+v", blocks)
+	}
+	if len(sent.Tools) != 1 || sent.Tools[0].Name != "weather" || sent.ToolChoice.Type != "auto" || !sent.ToolChoice.DisableParallelToolUse {
+		t.Errorf("Expected the tool with sequential tool use, got %+v / %+v", sent.Tools, sent.ToolChoice)
+	}
+
+	choice := resp.Choices[0]
+	if choice.FinishReason != "tool_calls" || choice.Message.Content != "Checking." || choice.Message.ReasoningContent != "Need the weather" {
+		t.Errorf("Unexpected choice %+v", choice)
+	}
+	if tc := choice.Message.ToolCalls; len(tc) != 1 || tc[0].ID != "toolu_2" || tc[0].Function.Arguments != `{"city":"Oslo"}` {
+		t.Errorf("Expected the tool call, got %+v", tc)
+	}
+	if resp.Usage.TotalTokens != 42 {
+		t.Errorf("Expected 42 tokens used, got %d", resp.Usage.TotalTokens)
+	}
+}
+
+func TestAnthropic_Stream(t *testing.T) {
+	events := []string{
+		`{"type":"message_start","message":{"id":"msg_1","model":"claude-sonnet-4"}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Let me "}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"check."}}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"weather","input":{}}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"Oslo\"}"}}`,
+		`{"type":"content_block_start","index":2,"content_block":{"type":"tool_use","id":"toolu_2","name":"time","input":{}}}`,
+		`{"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"{}"}}`,
+		`{"type":"message_delta","delta":{"stop_reason":"tool_use"}}`,
+		`{"type":"message_stop"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, event := range events {
+			fmt.Fprintf(w, "event: x\ndata: %s\n\n", event)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(config.Provider{BaseURL: server.URL, API: APIAnthropic})
+	var toolCalls []ToolCall
+	handler := NewStreamHandler(nil, nil)
+	err := client.ChatCompletionStream(context.Background(), ChatRequest{Model: "claude-sonnet-4"}, func(chunk StreamResponse) error {
+		done, calls, err := handler.HandleChunk(chunk)
+		if done {
+			toolCalls = calls
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+
+	if handler.GetContent() != "Let me check." {
+		t.Errorf("Expected the streamed text, got %q", handler.GetContent())
+	}
+	if len(toolCalls) != 2 || toolCalls[0].ID != "toolu_1" || toolCalls[0].Function.Arguments != `{"city":"Oslo"}` || toolCalls[1].Function.Name != "time" {
+		t.Errorf("Expected both tool calls in order, got %+v", toolCalls)
+	}
+}
@@ -1,14 +1,11 @@
 package llm
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/gmsas95/myrai-cli/internal/config"
@@ -17,7 +14,11 @@ import (
 // Client provides LLM API access
 type Client struct {
 	provider config.Provider
+	protocol protocol
 	client   *http.Client
+	limiter  *RateLimiter
+	local    *Client      // used while offline, see SetOfflineFallback
+	conn     Connectivity // says when that is
 }
 
 // NewClient creates a new LLM client
@@ -29,30 +30,37 @@ func NewClient(provider config.Provider) *Client {
 
 	return &Client{
 		provider: provider,
+		protocol: protocolFor(provider),
 		client: &http.Client{
 			Timeout: time.Duration(timeout) * time.Second,
 		},
+		limiter: limiterFor(provider),
 	}
 }
 
 // Message represents a chat message
 type Message struct {
-	Role             string     `json:"role"`
-	Content          string     `json:"content,omitempty"`
-	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
-	ToolCallID       string     `json:"tool_call_id,omitempty"`
-	Name             string     `json:"name,omitempty"`
-	ReasoningContent string     `json:"reasoning_content,omitempty"`
+	Role             string      `json:"role"`
+	Content          string      `json:"content,omitempty"`
+	ToolCalls        []ToolCall  `json:"tool_calls,omitempty"`
+	ToolCallID       string      `json:"tool_call_id,omitempty"`
+	Name             string      `json:"name,omitempty"`
+	ReasoningContent string      `json:"reasoning_content,omitempty"`
+	Images           []ImagePart `json:"-"` // sent as content parts; see MarshalJSON
 }
 
 // ToolCall represents a tool call from the model
 type ToolCall struct {
+	Index    *int   `json:"index,omitempty"` // position among the calls, in streamed chunks
 	ID       string `json:"id"`
 	Type     string `json:"type"`
 	Function struct {
 		Name      string `json:"name"`
 		Arguments string `json:"arguments"`
 	} `json:"function"`
+	// Signature is opaque state some providers need back with the call,
+	// such as Gemini's thought signature
+	Signature string `json:"-"`
 }
 
 // Tool represents a tool definition
@@ -78,37 +86,66 @@ type ChatRequest struct {
 	Stream            bool            `json:"stream,omitempty"`
 	ParallelToolCalls bool            `json:"parallel_tool_calls,omitempty"`
 	ToolChoice        json.RawMessage `json:"tool_choice,omitempty"`
+	ResponseFormat    *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// Response formats, as accepted by OpenAI-compatible APIs
+const (
+	JSONSchemaMode = "json_schema" // the reply must match a JSON schema
+	JSONObjectMode = "json_object" // the reply must be a JSON object
+)
+
+// ResponseFormat constrains the reply to JSON
+type ResponseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+// JSONSchema names the schema a json_schema reply must match
+type JSONSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict,omitempty"`
 }
 
 // ChatResponse represents a non-streaming API response
 type ChatResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	Model   string `json:"model"`
-	Choices []struct {
-		Index        int     `json:"index"`
-		Message      Message `json:"message"`
-		FinishReason string  `json:"finish_reason"`
-	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	ID      string   `json:"id"`
+	Object  string   `json:"object"`
+	Created int64    `json:"created"`
+	Model   string   `json:"model"`
+	Choices []Choice `json:"choices"`
+	Usage   Usage    `json:"usage"`
+}
+
+// Choice is one of the model's replies
+type Choice struct {
+	Index        int     `json:"index"`
+	Message      Message `json:"message"`
+	FinishReason string  `json:"finish_reason"`
+}
+
+// Usage reports the tokens a request used
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 // StreamResponse represents a streaming API response chunk
 type StreamResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	Model   string `json:"model"`
-	Choices []struct {
-		Index        int    `json:"index"`
-		Delta        Delta  `json:"delta"`
-		FinishReason string `json:"finish_reason"`
-	} `json:"choices"`
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []StreamChoice `json:"choices"`
+}
+
+// StreamChoice is a chunk of one of the model's replies
+type StreamChoice struct {
+	Index        int    `json:"index"`
+	Delta        Delta  `json:"delta"`
+	FinishReason string `json:"finish_reason"`
 }
 
 // Delta represents a delta in streaming response
@@ -121,110 +158,119 @@ type Delta struct {
 
 // ChatCompletion sends a chat completion request (non-streaming)
 func (c *Client) ChatCompletion(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
-	req.Stream = false
-
-	body, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	if local := c.fallback(nil); local != nil {
+		req.Model = local.provider.Model
+		return local.ChatCompletion(ctx, req)
 	}
-
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.provider.BaseURL+"/chat/completions", bytes.NewReader(body))
+	resp, err := c.chatCompletion(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.provider.APIKey)
-
-	resp, err := c.client.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var result ChatResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		if local := c.fallback(err); local != nil {
+			req.Model = local.provider.Model
+			return local.ChatCompletion(ctx, req)
+		}
 	}
-
-	return &result, nil
+	return resp, err
 }
 
-// StreamCallback is called for each chunk in a streaming response
-type StreamCallback func(chunk StreamResponse) error
-
-// ChatCompletionStream sends a streaming chat completion request
-func (c *Client) ChatCompletionStream(ctx context.Context, req ChatRequest, callback StreamCallback) error {
-	req.Stream = true
+func (c *Client) chatCompletion(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	req.Stream = false
+	if err := c.adaptImages(ctx, &req); err != nil {
+		return nil, err
+	}
 
-	body, err := json.Marshal(req)
+	estimate := estimateTokens(req)
+	resp, release, err := c.send(ctx, req, estimate)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
 	}
+	defer release()
+	defer resp.Body.Close()
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.provider.BaseURL+"/chat/completions", bytes.NewReader(body))
+	result, err := c.protocol.decode(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.provider.APIKey)
-	httpReq.Header.Set("Accept", "text/event-stream")
+	c.limiter.Consume(result.Usage.TotalTokens - estimate)
 
-	resp, err := c.client.Do(httpReq)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
+	return result, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(bodyBytes))
-	}
+// send posts a chat completion request through the provider's rate limiter,
+// retrying requests rejected with HTTP 429. On success the caller must close
+// the response body and then call release.
+func (c *Client) send(ctx context.Context, req ChatRequest, tokens int) (*http.Response, func(), error) {
+	for attempt := 0; ; attempt++ {
+		release, err := c.limiter.Acquire(ctx, tokens)
+		if err != nil {
+			return nil, nil, fmt.Errorf("rate limiter: %w", err)
+		}
 
-	reader := bufio.NewReader(resp.Body)
-	for {
-		line, err := reader.ReadString('\n')
-		if err == io.EOF {
-			break
+		httpReq, err := c.protocol.newRequest(ctx, c.provider, req)
+		if err != nil {
+			release()
+			return nil, nil, fmt.Errorf("failed to create request: %w", err)
 		}
+
+		resp, err := c.client.Do(httpReq)
 		if err != nil {
-			return fmt.Errorf("failed to read stream: %w", err)
+			release()
+			if ctx.Err() == nil {
+				err = &unreachableError{err: err}
+			}
+			return nil, nil, fmt.Errorf("failed to send request: %w", err)
 		}
 
-		line = strings.TrimSpace(line)
-		if len(line) == 0 {
-			continue
+		c.limiter.Observe(resp.StatusCode, resp.Header)
+
+		if resp.StatusCode == http.StatusOK {
+			return resp, release, nil
 		}
 
-		// Parse SSE data
-		if !strings.HasPrefix(line, "data: ") {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		release()
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries {
 			continue
 		}
+		return nil, nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(bodyBytes))
+	}
+}
 
-		data := strings.TrimPrefix(line, "data: ")
+// StreamCallback is called for each chunk in a streaming response
+type StreamCallback func(chunk StreamResponse) error
 
-		// Check for stream end
-		if data == "[DONE]" {
-			break
+// ChatCompletionStream sends a streaming chat completion request
+func (c *Client) ChatCompletionStream(ctx context.Context, req ChatRequest, callback StreamCallback) error {
+	if local := c.fallback(nil); local != nil {
+		req.Model = local.provider.Model
+		return local.ChatCompletionStream(ctx, req, callback)
+	}
+	err := c.chatCompletionStream(ctx, req, callback)
+	if err != nil {
+		if local := c.fallback(err); local != nil {
+			req.Model = local.provider.Model
+			return local.ChatCompletionStream(ctx, req, callback)
 		}
+	}
+	return err
+}
 
-		var chunk StreamResponse
-		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
-			continue // Skip malformed chunks
-		}
+func (c *Client) chatCompletionStream(ctx context.Context, req ChatRequest, callback StreamCallback) error {
+	req.Stream = true
+	if err := c.adaptImages(ctx, &req); err != nil {
+		return err
+	}
 
-		if err := callback(chunk); err != nil {
-			return err
-		}
+	resp, release, err := c.send(ctx, req, estimateTokens(req))
+	if err != nil {
+		return err
 	}
+	defer release()
+	defer resp.Body.Close()
 
-	return nil
+	return c.protocol.stream(resp.Body, callback)
 }
 
 // SimpleChat sends a simple chat message and returns the response text
@@ -269,13 +315,14 @@ func (c *Client) SimpleChatStream(ctx context.Context, systemPrompt, userMessage
 	})
 }
 
-// CountTokens estimates token count (rough approximation)
+// CountTokens counts tokens with cl100k, close enough for most models; use
+// TokenizerFor when the model is known
 func CountTokens(text string) int {
-	// Very rough estimate: ~4 characters per token for English
-	return len(text) / 4
+	return defaultTokenizer.Count(text)
 }
 
-// GetModel returns the configured model
+// GetModel returns the model requests go to: the configured one, or the
+// local fallback's while offline
 func (c *Client) GetModel() string {
-	return c.provider.Model
+	return c.active().provider.Model
 }
@@ -0,0 +1,369 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+// geminiSkipSignature stands in for the thought signature Gemini 3 wants back
+// with function calls, for calls whose signature was lost with the history
+const geminiSkipSignature = "skip_thought_signature_validator"
+
+// geminiProtocol speaks the Google Gemini API
+type geminiProtocol struct{}
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent         `json:"contents"`
+	Tools             []geminiTool            `json:"tools,omitempty"`
+	ToolConfig        *geminiToolConfig       `json:"toolConfig,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+// geminiPart is a part of any kind; only the fields of its kind are set
+type geminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"`
+	ThoughtSignature string                  `json:"thoughtSignature,omitempty"`
+	InlineData       *geminiBlob             `json:"inlineData,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type geminiBlob struct {
+	MIMEType string `json:"mimeType"`
+	Data     []byte `json:"data"` // base64 in JSON
+}
+
+type geminiFunctionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type geminiFunctionResponse struct {
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+type geminiFunctionDeclaration struct {
+	Name                 string                 `json:"name"`
+	Description          string                 `json:"description,omitempty"`
+	ParametersJSONSchema map[string]interface{} `json:"parametersJsonSchema,omitempty"`
+}
+
+type geminiToolConfig struct {
+	FunctionCallingConfig struct {
+		Mode                 string   `json:"mode"`
+		AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+	} `json:"functionCallingConfig"`
+}
+
+type geminiGenerationConfig struct {
+	MaxOutputTokens    int             `json:"maxOutputTokens,omitempty"`
+	Temperature        *float64        `json:"temperature,omitempty"`
+	ResponseMIMEType   string          `json:"responseMimeType,omitempty"`
+	ResponseJSONSchema json.RawMessage `json:"responseJsonSchema,omitempty"`
+}
+
+type geminiResponse struct {
+	ResponseID   string `json:"responseId"`
+	ModelVersion string `json:"modelVersion"`
+	Candidates   []struct {
+		Index        int           `json:"index"`
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+func (geminiProtocol) newRequest(ctx context.Context, provider config.Provider, req ChatRequest) (*http.Request, error) {
+	var body geminiRequest
+	body.SystemInstruction, body.Contents = geminiContents(req.Messages)
+
+	var decls []geminiFunctionDeclaration
+	for _, tool := range req.Tools {
+		decls = append(decls, geminiFunctionDeclaration{
+			Name:                 tool.Function.Name,
+			Description:          tool.Function.Description,
+			ParametersJSONSchema: tool.Function.Parameters,
+		})
+	}
+	if len(decls) > 0 {
+		body.Tools = []geminiTool{{FunctionDeclarations: decls}}
+		body.ToolConfig = &geminiToolConfig{}
+		calling := &body.ToolConfig.FunctionCallingConfig
+		switch mode, name := toolChoice(req.ToolChoice); mode {
+		case "none":
+			calling.Mode = "NONE"
+		case "required":
+			calling.Mode = "ANY"
+		case "function":
+			calling.Mode = "ANY"
+			calling.AllowedFunctionNames = []string{name}
+		default:
+			calling.Mode = "AUTO"
+		}
+	}
+
+	gen := &geminiGenerationConfig{MaxOutputTokens: req.MaxTokens}
+	if req.Temperature > 0 {
+		gen.Temperature = &req.Temperature
+	}
+	if format := req.ResponseFormat; format != nil {
+		gen.ResponseMIMEType = "application/json"
+		if format.Type == JSONSchemaMode && format.JSONSchema != nil {
+			gen.ResponseJSONSchema = format.JSONSchema.Schema
+		}
+	}
+	if gen.MaxOutputTokens > 0 || gen.Temperature != nil || gen.ResponseMIMEType != "" {
+		body.GenerationConfig = gen
+	}
+
+	model := req.Model
+	if model == "" {
+		model = provider.Model
+	}
+	url := provider.BaseURL + "/models/" + strings.TrimPrefix(model, "models/")
+	if req.Stream {
+		url += ":streamGenerateContent?alt=sse"
+	} else {
+		url += ":generateContent"
+	}
+
+	httpReq, err := newJSONRequest(ctx, url, body, req.Stream)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("x-goog-api-key", provider.APIKey)
+	return httpReq, nil
+}
+
+// geminiContents converts chat messages to Gemini's: system messages become
+// the system instruction, tool results are sent by the user as function
+// responses, and consecutive messages from the same role are merged
+func geminiContents(msgs []Message) (*geminiContent, []geminiContent) {
+	var system []geminiPart
+	var out []geminiContent
+	callNames := make(map[string]string) // tool call ID to function name
+	for _, msg := range msgs {
+		role := "user"
+		var parts []geminiPart
+		switch msg.Role {
+		case "system":
+			if msg.Content != "" {
+				system = append(system, geminiPart{Text: msg.Content})
+			}
+			continue
+		case "tool":
+			name := msg.Name
+			if name == "" {
+				name = callNames[msg.ToolCallID]
+			}
+			parts = append(parts, geminiPart{FunctionResponse: &geminiFunctionResponse{
+				Name:     name,
+				Response: geminiToolResult(msg.Content),
+			}})
+		case "assistant":
+			role = "model"
+			if msg.Content != "" {
+				parts = append(parts, geminiPart{Text: msg.Content})
+			}
+			for i, tc := range msg.ToolCalls {
+				callNames[tc.ID] = tc.Function.Name
+				part := geminiPart{FunctionCall: &geminiFunctionCall{
+					Name: tc.Function.Name,
+					Args: toolArguments(tc.Function.Arguments),
+				}}
+				// Only the first call of a turn carries a signature
+				if tc.Signature != "" {
+					part.ThoughtSignature = tc.Signature
+				} else if i == 0 {
+					part.ThoughtSignature = geminiSkipSignature
+				}
+				parts = append(parts, part)
+			}
+		default:
+			for _, img := range msg.Images {
+				// Images are fetched inline by adaptImages
+				if len(img.Data) > 0 {
+					parts = append(parts, geminiPart{InlineData: &geminiBlob{MIMEType: img.MIMEType, Data: img.Data}})
+				}
+			}
+			if msg.Content != "" {
+				parts = append(parts, geminiPart{Text: msg.Content})
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+
+		if n := len(out); n > 0 && out[n-1].Role == role {
+			out[n-1].Parts = append(out[n-1].Parts, parts...)
+		} else {
+			out = append(out, geminiContent{Role: role, Parts: parts})
+		}
+	}
+
+	if len(system) == 0 {
+		return nil, out
+	}
+	return &geminiContent{Parts: system}, out
+}
+
+// geminiToolResult wraps a tool's output in the object Gemini expects
+func geminiToolResult(content string) json.RawMessage {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	wrapped, _ := json.Marshal(map[string]string{"result": content})
+	return wrapped
+}
+
+// geminiFinishReason maps a finish reason to the chat completions one
+func geminiFinishReason(reason string, toolCalls bool) string {
+	switch {
+	case reason == "" || reason == "FINISH_REASON_UNSPECIFIED":
+		return ""
+	case toolCalls:
+		return "tool_calls"
+	case reason == "STOP":
+		return "stop"
+	case reason == "MAX_TOKENS":
+		return "length"
+	}
+	// SAFETY, RECITATION, BLOCKLIST and the like
+	return "content_filter"
+}
+
+// geminiCallSeq numbers generated tool call IDs; Gemini doesn't always
+// assign them
+var geminiCallSeq atomic.Int64
+
+// geminiMessage converts a candidate's parts, numbering its tool calls from
+// firstTool when streaming
+func geminiMessage(parts []geminiPart, stream bool, firstTool int) (content, reasoning string, toolCalls []ToolCall) {
+	var texts, thoughts []string
+	for _, part := range parts {
+		switch {
+		case part.FunctionCall != nil:
+			id := part.FunctionCall.ID
+			if id == "" {
+				id = fmt.Sprintf("call_%d_%d", time.Now().Unix(), geminiCallSeq.Add(1))
+			}
+			var index *int
+			if stream {
+				i := firstTool + len(toolCalls)
+				index = &i
+			}
+			tc := newToolCall(id, part.FunctionCall.Name, string(toolArguments(string(part.FunctionCall.Args))), index)
+			tc.Signature = part.ThoughtSignature
+			toolCalls = append(toolCalls, tc)
+		case part.Thought:
+			thoughts = append(thoughts, part.Text)
+		case part.Text != "":
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, ""), strings.Join(thoughts, ""), toolCalls
+}
+
+// blocked reports a prompt Gemini refused to answer
+func (resp *geminiResponse) blocked() error {
+	if len(resp.Candidates) == 0 && resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+		return fmt.Errorf("prompt blocked: %s", resp.PromptFeedback.BlockReason)
+	}
+	return nil
+}
+
+func (geminiProtocol) decode(r io.Reader) (*ChatResponse, error) {
+	var resp geminiResponse
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return nil, err
+	}
+	if err := resp.blocked(); err != nil {
+		return nil, err
+	}
+
+	result := &ChatResponse{
+		ID:     resp.ResponseID,
+		Object: "chat.completion",
+		Model:  resp.ModelVersion,
+		Usage: Usage{
+			PromptTokens:     resp.UsageMetadata.PromptTokenCount,
+			CompletionTokens: resp.UsageMetadata.CandidatesTokenCount + resp.UsageMetadata.ThoughtsTokenCount,
+			TotalTokens:      resp.UsageMetadata.TotalTokenCount,
+		},
+	}
+	for _, candidate := range resp.Candidates {
+		content, reasoning, toolCalls := geminiMessage(candidate.Content.Parts, false, 0)
+		result.Choices = append(result.Choices, Choice{
+			Index: candidate.Index,
+			Message: Message{
+				Role:             "assistant",
+				Content:          content,
+				ReasoningContent: reasoning,
+				ToolCalls:        toolCalls,
+			},
+			FinishReason: geminiFinishReason(candidate.FinishReason, len(toolCalls) > 0),
+		})
+	}
+	return result, nil
+}
+
+func (geminiProtocol) stream(r io.Reader, callback StreamCallback) error {
+	tools := 0
+	return readSSE(r, func(data string) error {
+		var resp geminiResponse
+		if err := json.Unmarshal([]byte(data), &resp); err != nil {
+			return nil // Skip malformed chunks
+		}
+		if err := resp.blocked(); err != nil {
+			return err
+		}
+		if len(resp.Candidates) == 0 {
+			return nil
+		}
+
+		// Calls arrive whole, each in one chunk
+		candidate := resp.Candidates[0]
+		content, _, toolCalls := geminiMessage(candidate.Content.Parts, true, tools)
+		tools += len(toolCalls)
+		return callback(StreamResponse{
+			ID:     resp.ResponseID,
+			Object: "chat.completion.chunk",
+			Model:  resp.ModelVersion,
+			Choices: []StreamChoice{{
+				Delta:        Delta{Role: "assistant", Content: content, ToolCalls: toolCalls},
+				FinishReason: geminiFinishReason(candidate.FinishReason, tools > 0),
+			}},
+		})
+	})
+}
@@ -0,0 +1,129 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+func TestGemini_ChatCompletion(t *testing.T) {
+	var path string
+	var sent geminiRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if r.Header.Get("x-goog-api-key") != "secret" {
+			t.Errorf("Expected the API key header, got %v", r.Header)
+		}
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"responseId":"r1","modelVersion":"gemini-2.5-flash",
+			"candidates":[{"content":{"role":"model","parts":[
+				{"text":"Thinking it over","thought":true},
+				{"functionCall":{"name":"weather","args":{"city":"Oslo"}},"thoughtSignature":"sig"}]},
+				"finishReason":"STOP"}],
+			"usageMetadata":{"promptTokenCount":20,"candidatesTokenCount":5,"totalTokenCount":25}}`))
+	}))
+	defer server.Close()
+
+	call := newToolCall("call_1", "time", `{"zone":"UTC"}`, nil)
+	client := NewClient(config.Provider{BaseURL: server.URL, APIKey: "secret", API: APIGemini})
+	resp, err := client.ChatCompletion(context.Background(), ChatRequest{
+		Model: "models/gemini-2.5-flash",
+		Messages: []Message{
+			{Role: "system", Content: "Be brief."},
+			{Role: "user", Content: "Time and weather in Oslo?", Images: []ImagePart{{Data: []byte("png"), MIMEType: "image/png"}}},
+			{Role: "assistant", ToolCalls: []ToolCall{call}},
+			{Role: "tool", ToolCallID: "call_1", Content: "12:00"},
+		},
+		Tools:          []Tool{{Type: "function", Function: ToolFunction{Name: "weather", Parameters: map[string]interface{}{"type": "object"}}}},
+		ToolChoice:     json.RawMessage(`"required"`),
+		ResponseFormat: &ResponseFormat{Type: JSONSchemaMode, JSONSchema: &JSONSchema{Name: "w", Schema: json.RawMessage(`{"type":"object"}`)}},
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+
+	if path != "/models/gemini-2.5-flash:generateContent" {
+		t.Errorf("Unexpected path %s", path)
+	}
+	if sent.SystemInstruction == nil || sent.SystemInstruction.Parts[0].Text != "Be brief." {
+		t.Errorf("Expected the system instruction, got %+v", sent.SystemInstruction)
+	}
+	if len(sent.Contents) != 3 || sent.Contents[1].Role != "model" || sent.Contents[2].Role != "user" {
+		t.Fatalf("Expected user, model and user contents, got %+v", sent.Contents)
+	}
+	if img := sent.Contents[0].Parts[0].InlineData; img == nil || string(img.Data) != "png" {
+		t.Errorf("Expected the image inline, got %+v", sent.Contents[0].Parts)
+	}
+	if fc := sent.Contents[1].Parts[0]; fc.FunctionCall.Name != "time" || fc.ThoughtSignature != geminiSkipSignature {
+		t.Errorf("Expected the function call with a placeholder signature, got %+v", fc)
+	}
+	if fr := sent.Contents[2].Parts[0].FunctionResponse; fr.Name != "time" || string(fr.Response) != `{"result":"12:00"}` {
+		t.Errorf("Expected the wrapped function response, got %+v", fr)
+	}
+	if sent.ToolConfig.FunctionCallingConfig.Mode != "ANY" || sent.GenerationConfig.ResponseMIMEType != "application/json" {
+		t.Errorf("Expected forced tool use and JSON output, got %+v / %+v", sent.ToolConfig, sent.GenerationConfig)
+	}
+
+	choice := resp.Choices[0]
+	if choice.FinishReason != "tool_calls" || choice.Message.ReasoningContent != "Thinking it over" {
+		t.Errorf("Unexpected choice %+v", choice)
+	}
+	tc := choice.Message.ToolCalls
+	if len(tc) != 1 || tc[0].ID == "" || tc[0].Function.Arguments != `{"city":"Oslo"}` || tc[0].Signature != "sig" {
+		t.Fatalf("Expected the tool call with an ID and signature, got %+v", tc)
+	}
+	if resp.Usage.TotalTokens != 25 {
+		t.Errorf("Expected 25 tokens used, got %d", resp.Usage.TotalTokens)
+	}
+
+	// The signature goes back with the call
+	contents := []Message{{Role: "assistant", ToolCalls: tc}}
+	if _, out := geminiContents(contents); out[0].Parts[0].ThoughtSignature != "sig" {
+		t.Errorf("Expected the signature to be sent back, got %+v", out[0].Parts[0])
+	}
+}
+
+func TestGemini_Stream(t *testing.T) {
+	var path string
+	chunks := []string{
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"Let me "}]}}]}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"check."}]}}]}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"weather","args":{"city":"Oslo"}}},{"functionCall":{"name":"time","args":{}}}]},"finishReason":"STOP"}]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path + "?" + r.URL.RawQuery
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\r\n\r\n", chunk)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(config.Provider{BaseURL: server.URL, API: APIGemini, Model: "gemini-2.5-flash"})
+	var toolCalls []ToolCall
+	handler := NewStreamHandler(nil, nil)
+	err := client.ChatCompletionStream(context.Background(), ChatRequest{}, func(chunk StreamResponse) error {
+		done, calls, err := handler.HandleChunk(chunk)
+		if done {
+			toolCalls = calls
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+
+	if path != "/models/gemini-2.5-flash:streamGenerateContent?alt=sse" {
+		t.Errorf("Unexpected path %s", path)
+	}
+	if handler.GetContent() != "Let me check." {
+		t.Errorf("Expected the streamed text, got %q", handler.GetContent())
+	}
+	if len(toolCalls) != 2 || toolCalls[0].Function.Name != "weather" || toolCalls[1].Function.Arguments != `{}` {
+		t.Errorf("Expected both tool calls in order, got %+v", toolCalls)
+	}
+}
@@ -0,0 +1,61 @@
+package llm
+
+import "errors"
+
+// Connectivity says whether the network is up; connectivity.Monitor is one
+type Connectivity interface {
+	Online() bool
+	// ReportFailure is told of requests that didn't get through, and
+	// checks the network before returning
+	ReportFailure(err error)
+}
+
+// unreachableError is a request that failed before the provider answered,
+// e.g. because DNS or the connection failed
+type unreachableError struct {
+	err error
+}
+
+func (e *unreachableError) Error() string { return e.err.Error() }
+func (e *unreachableError) Unwrap() error { return e.err }
+
+// SetOfflineFallback sends requests to local, usually a model served on
+// this machine, while conn reports the network down. A request that
+// doesn't reach the provider has the network checked and, if it is down,
+// is sent to local instead.
+func (c *Client) SetOfflineFallback(local *Client, conn Connectivity) {
+	c.local, c.conn = local, conn
+}
+
+// Offline reports whether requests are going to the local fallback
+func (c *Client) Offline() bool {
+	return c.active() != c
+}
+
+// active is the client requests go to
+func (c *Client) active() *Client {
+	if c.local != nil && !c.conn.Online() {
+		return c.local
+	}
+	return c
+}
+
+// fallback returns the client to send a request to instead of c, nil if
+// there is none: the local one while offline, or once err shows the
+// provider couldn't be reached and the network turns out to be down
+func (c *Client) fallback(err error) *Client {
+	if c.local == nil {
+		return nil
+	}
+	if err != nil {
+		var unreachable *unreachableError
+		if !errors.As(err, &unreachable) {
+			return nil
+		}
+		c.conn.ReportFailure(err)
+	}
+	if c.conn.Online() {
+		return nil
+	}
+	return c.local
+}
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+// fakeConnectivity goes offline when told of a failure
+type fakeConnectivity struct {
+	offline  bool
+	failures int
+}
+
+func (f *fakeConnectivity) Online() bool { return !f.offline }
+
+func (f *fakeConnectivity) ReportFailure(err error) {
+	f.failures++
+	f.offline = true
+}
+
+func TestClient_OfflineFallback(t *testing.T) {
+	var models []string
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		models = append(models, req.Model)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": Message{Role: "assistant", Content: "local"}}},
+		})
+	}))
+	defer local.Close()
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	remote.Close() // the network is down
+
+	conn := &fakeConnectivity{}
+	client := NewClient(config.Provider{BaseURL: remote.URL, Model: "gpt-4o"})
+	client.SetOfflineFallback(NewClient(config.Provider{BaseURL: local.URL, Model: "llama3.2"}), conn)
+	if client.Offline() || client.GetModel() != "gpt-4o" {
+		t.Fatalf("Expected the provider's model while online, got %s", client.GetModel())
+	}
+
+	resp, err := client.ChatCompletion(context.Background(), ChatRequest{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("Expected the local model to answer, got %v", err)
+	}
+	if resp.Choices[0].Message.Content != "local" || conn.failures != 1 {
+		t.Errorf("Expected the failure reported and the local answer, got %q after %d failures", resp.Choices[0].Message.Content, conn.failures)
+	}
+	if !client.Offline() || client.GetModel() != "llama3.2" {
+		t.Errorf("Expected the local model while offline, got %s", client.GetModel())
+	}
+
+	// Offline, requests go straight to the local model
+	if _, err := client.SimpleChat(context.Background(), "", "again"); err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 2 || models[0] != "llama3.2" || models[1] != "llama3.2" || conn.failures != 1 {
+		t.Errorf("Expected both requests on the local model, got %v", models)
+	}
+}
+
+func TestClient_NoFallbackForAPIErrors(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad key", http.StatusUnauthorized)
+	}))
+	defer remote.Close()
+
+	conn := &fakeConnectivity{}
+	client := NewClient(config.Provider{BaseURL: remote.URL, Model: "gpt-4o"})
+	client.SetOfflineFallback(NewClient(config.Provider{BaseURL: "http://127.0.0.1:1", Model: "llama3.2"}), conn)
+	if _, err := client.SimpleChat(context.Background(), "", "hi"); err == nil {
+		t.Fatal("Expected the provider's error")
+	}
+	if conn.failures != 0 || client.Offline() {
+		t.Error("Expected an error from the provider not to count as offline")
+	}
+}
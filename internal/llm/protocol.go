@@ -0,0 +1,175 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+// APIs the client speaks, as set by a provider's api setting
+const (
+	APIOpenAI    = "openai"    // chat completions, also spoken by most other providers
+	APIAnthropic = "anthropic" // the Anthropic Messages API
+	APIGemini    = "gemini"    // the Google Gemini API
+)
+
+// protocol translates chat requests and responses to and from a provider's
+// wire format. The client deals in the OpenAI-compatible types throughout.
+type protocol interface {
+	// newRequest builds the HTTP request for a chat request
+	newRequest(ctx context.Context, provider config.Provider, req ChatRequest) (*http.Request, error)
+	// decode reads a non-streaming response
+	decode(r io.Reader) (*ChatResponse, error)
+	// stream reads a streaming response, calling callback for each chunk
+	stream(r io.Reader, callback StreamCallback) error
+}
+
+// protocolFor picks the provider's protocol, from its api setting or else
+// from its base URL
+func protocolFor(provider config.Provider) protocol {
+	switch strings.ToLower(provider.API) {
+	case APIAnthropic:
+		return anthropicProtocol{}
+	case APIGemini:
+		return geminiProtocol{}
+	case APIOpenAI:
+		return openAIProtocol{}
+	}
+
+	switch providerFlavor(provider.BaseURL) {
+	case flavorAnthropic:
+		return anthropicProtocol{}
+	case flavorGoogle:
+		// Google also serves an OpenAI-compatible endpoint under /openai
+		if !strings.Contains(provider.BaseURL, "/openai") {
+			return geminiProtocol{}
+		}
+	}
+	return openAIProtocol{}
+}
+
+// openAIProtocol speaks the chat completions API
+type openAIProtocol struct{}
+
+func (openAIProtocol) newRequest(ctx context.Context, provider config.Provider, req ChatRequest) (*http.Request, error) {
+	httpReq, err := newJSONRequest(ctx, provider.BaseURL+"/chat/completions", req, req.Stream)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+provider.APIKey)
+	return httpReq, nil
+}
+
+func (openAIProtocol) decode(r io.Reader) (*ChatResponse, error) {
+	var result ChatResponse
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (openAIProtocol) stream(r io.Reader, callback StreamCallback) error {
+	return readSSE(r, func(data string) error {
+		if data == "[DONE]" {
+			return errStreamDone
+		}
+
+		var chunk StreamResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil // Skip malformed chunks
+		}
+		return callback(chunk)
+	})
+}
+
+// newJSONRequest builds a POST request with body encoded as JSON
+func newJSONRequest(ctx context.Context, url string, body interface{}, stream bool) (*http.Request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+	return httpReq, nil
+}
+
+// errStreamDone stops readSSE before the end of the body
+var errStreamDone = errors.New("stream done")
+
+// readSSE calls fn with the data of each server-sent event until the body
+// ends or fn returns errStreamDone
+func readSSE(r io.Reader, fn func(data string) error) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read stream: %w", err)
+		}
+
+		if data, ok := strings.CutPrefix(strings.TrimSpace(line), "data:"); ok {
+			if ferr := fn(strings.TrimSpace(data)); ferr == errStreamDone {
+				return nil
+			} else if ferr != nil {
+				return ferr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// toolChoice reads an OpenAI tool_choice: "auto", "none", "required" or a
+// named function
+func toolChoice(raw json.RawMessage) (mode, name string) {
+	if len(raw) == 0 {
+		return "", ""
+	}
+	if err := json.Unmarshal(raw, &mode); err == nil {
+		return mode, ""
+	}
+
+	var named struct {
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(raw, &named); err == nil && named.Function.Name != "" {
+		return "function", named.Function.Name
+	}
+	return "", ""
+}
+
+// toolArguments returns a tool call's arguments as a JSON object, for APIs
+// that take them structured
+func toolArguments(arguments string) json.RawMessage {
+	args := strings.TrimSpace(arguments)
+	if args == "" || !json.Valid([]byte(args)) {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(args)
+}
+
+// newToolCall builds a tool call; index is set for streamed calls
+func newToolCall(id, name, arguments string, index *int) ToolCall {
+	tc := ToolCall{Index: index, ID: id, Type: "function"}
+	tc.Function.Name = name
+	tc.Function.Arguments = arguments
+	return tc
+}
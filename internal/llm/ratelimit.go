@@ -0,0 +1,317 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// maxRateLimitRetries is how many times a request rejected with HTTP 429
+	// is retried before the error is returned to the caller
+	maxRateLimitRetries = 3
+
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// RateLimiter enforces a provider's configured RPM, TPM and concurrency
+// limits and adapts to the rate-limit headers returned by the provider.
+// A single limiter is shared by all clients talking to the same provider.
+type RateLimiter struct {
+	limits atomic.Pointer[rateLimits]
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+	backoff     time.Duration
+}
+
+// rateLimits are the configured limits, replaced whole when the
+// configuration changes
+type rateLimits struct {
+	config   config.RateLimitConfig
+	requests *rate.Limiter
+	tokens   *rate.Limiter
+	slots    chan struct{}
+}
+
+// NewRateLimiter creates a rate limiter from provider configuration
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	l := &RateLimiter{}
+	l.SetLimits(cfg)
+	return l
+}
+
+// SetLimits replaces the configured limits. Requests already admitted
+// finish under the old ones.
+func (l *RateLimiter) SetLimits(cfg config.RateLimitConfig) {
+	lim := &rateLimits{config: cfg}
+
+	if cfg.RPM > 0 {
+		burst := cfg.Burst
+		if burst <= 0 {
+			burst = cfg.RPM / 10
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		lim.requests = rate.NewLimiter(rate.Limit(float64(cfg.RPM)/60.0), burst)
+	}
+
+	if cfg.TPM > 0 {
+		// Allow a full minute of tokens as burst so single large requests fit
+		lim.tokens = rate.NewLimiter(rate.Limit(float64(cfg.TPM)/60.0), cfg.TPM)
+	}
+
+	if cfg.MaxConcurrency > 0 {
+		lim.slots = make(chan struct{}, cfg.MaxConcurrency)
+	}
+
+	l.limits.Store(lim)
+}
+
+var (
+	limiters   = make(map[string]*RateLimiter)
+	limitersMu sync.Mutex
+)
+
+// limiterFor returns the shared limiter for a provider, creating it on first
+// use and applying the provider's limits if they have changed
+func limiterFor(provider config.Provider) *RateLimiter {
+	l, _ := applyRateLimits(provider)
+	return l
+}
+
+// ApplyRateLimits applies a provider's configured limits to the limiter its
+// clients share, reporting whether they changed
+func ApplyRateLimits(provider config.Provider) bool {
+	_, changed := applyRateLimits(provider)
+	return changed
+}
+
+func applyRateLimits(provider config.Provider) (*RateLimiter, bool) {
+	key := fmt.Sprintf("%s|%s", provider.BaseURL, provider.APIKey)
+
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	if l, ok := limiters[key]; ok {
+		if l.limits.Load().config == provider.RateLimit {
+			return l, false
+		}
+		l.SetLimits(provider.RateLimit)
+		return l, true
+	}
+	l := NewRateLimiter(provider.RateLimit)
+	limiters[key] = l
+	return l, false
+}
+
+// Acquire blocks until a request estimated at the given number of tokens may
+// be sent. The returned release function must be called once the response
+// has been fully consumed.
+func (l *RateLimiter) Acquire(ctx context.Context, tokens int) (func(), error) {
+	if err := l.waitPause(ctx); err != nil {
+		return nil, err
+	}
+
+	lim := l.limits.Load()
+	if lim.slots != nil {
+		select {
+		case lim.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if lim.slots != nil {
+			<-lim.slots
+		}
+	}
+
+	if lim.requests != nil {
+		if err := lim.requests.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	if lim.tokens != nil && tokens > 0 {
+		if tokens > lim.tokens.Burst() {
+			tokens = lim.tokens.Burst()
+		}
+		if err := lim.tokens.WaitN(ctx, tokens); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	return release, nil
+}
+
+// Consume charges tokens used beyond the estimate passed to Acquire
+func (l *RateLimiter) Consume(tokens int) {
+	lim := l.limits.Load()
+	if lim.tokens == nil || tokens <= 0 {
+		return
+	}
+	if tokens > lim.tokens.Burst() {
+		tokens = lim.tokens.Burst()
+	}
+	lim.tokens.ReserveN(time.Now(), tokens)
+}
+
+// Observe updates the limiter from a provider response. A 429 pauses all
+// requests for the Retry-After period or an exponentially growing backoff;
+// exhausted request or token quotas pause until the reported reset time.
+func (l *RateLimiter) Observe(statusCode int, header http.Header) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if statusCode == http.StatusTooManyRequests {
+		if l.backoff == 0 {
+			l.backoff = minBackoff
+		} else if l.backoff < maxBackoff {
+			l.backoff *= 2
+			if l.backoff > maxBackoff {
+				l.backoff = maxBackoff
+			}
+		}
+
+		wait := l.backoff
+		if retryAfter, ok := parseRetryAfter(header.Get("Retry-After"), now); ok && retryAfter > wait {
+			wait = retryAfter
+		}
+		if reset, ok := exhaustedReset(header, now); ok && reset > wait {
+			wait = reset
+		}
+		l.pauseLocked(now.Add(wait))
+		return
+	}
+
+	if statusCode >= 200 && statusCode < 300 {
+		l.backoff = 0
+	}
+
+	if reset, ok := exhaustedReset(header, now); ok {
+		l.pauseLocked(now.Add(reset))
+	}
+}
+
+// PausedFor returns how long requests are currently held back
+func (l *RateLimiter) PausedFor() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if d := time.Until(l.pausedUntil); d > 0 {
+		return d
+	}
+	return 0
+}
+
+func (l *RateLimiter) pauseLocked(until time.Time) {
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+func (l *RateLimiter) waitPause(ctx context.Context) error {
+	for {
+		wait := l.PausedFor()
+		if wait <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// rateLimitHeaders lists (remaining, reset) header pairs used by common
+// providers: OpenAI-compatible APIs and Anthropic.
+var rateLimitHeaders = [][2]string{
+	{"X-Ratelimit-Remaining-Requests", "X-Ratelimit-Reset-Requests"},
+	{"X-Ratelimit-Remaining-Tokens", "X-Ratelimit-Reset-Tokens"},
+	{"Anthropic-Ratelimit-Requests-Remaining", "Anthropic-Ratelimit-Requests-Reset"},
+	{"Anthropic-Ratelimit-Tokens-Remaining", "Anthropic-Ratelimit-Tokens-Reset"},
+}
+
+// exhaustedReset returns the longest reset delay among quotas the provider
+// reports as exhausted
+func exhaustedReset(header http.Header, now time.Time) (time.Duration, bool) {
+	var longest time.Duration
+	found := false
+	for _, pair := range rateLimitHeaders {
+		remaining := header.Get(pair[0])
+		if remaining == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(remaining, 64)
+		if err != nil || n > 0 {
+			continue
+		}
+		if reset, ok := parseReset(header.Get(pair[1]), now); ok {
+			if reset > longest {
+				longest = reset
+			}
+			found = true
+		}
+	}
+	return longest, found
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t.Sub(now), true
+	}
+	return 0, false
+}
+
+// parseReset parses a rate-limit reset header. Providers use Go-style
+// durations ("6m0s", "20ms"), plain seconds or RFC 3339 timestamps.
+func parseReset(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d, true
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Sub(now), true
+	}
+	return 0, false
+}
+
+// estimateTokens approximates the prompt size of a request for TPM limiting
+func estimateTokens(req ChatRequest) int {
+	total := 0
+	for _, msg := range req.Messages {
+		total += CountTokens(msg.Content) + len(msg.Images)*imageTokens
+	}
+	return total
+}
@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+func TestParseReset(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"6m0s", 6 * time.Minute},
+		{"20ms", 20 * time.Millisecond},
+		{"30", 30 * time.Second},
+		{"2026-01-01T12:00:05Z", 5 * time.Second},
+	}
+	for _, tt := range tests {
+		got, ok := parseReset(tt.value, now)
+		if !ok || got != tt.want {
+			t.Errorf("parseReset(%q) = %v, %v; want %v", tt.value, got, ok, tt.want)
+		}
+	}
+	if _, ok := parseReset("soon", now); ok {
+		t.Error("parseReset accepted an invalid value")
+	}
+}
+
+func TestRateLimiter_ObserveExhaustedQuota(t *testing.T) {
+	l := NewRateLimiter(config.RateLimitConfig{})
+
+	h := http.Header{}
+	h.Set("X-Ratelimit-Remaining-Requests", "5")
+	h.Set("X-Ratelimit-Reset-Requests", "10s")
+	l.Observe(http.StatusOK, h)
+	if l.PausedFor() != 0 {
+		t.Fatal("limiter paused while quota remains")
+	}
+
+	h.Set("X-Ratelimit-Remaining-Requests", "0")
+	l.Observe(http.StatusOK, h)
+	if d := l.PausedFor(); d <= 5*time.Second || d > 10*time.Second {
+		t.Fatalf("expected pause of ~10s, got %v", d)
+	}
+}
+
+func TestRateLimiter_ConcurrencySlots(t *testing.T) {
+	l := NewRateLimiter(config.RateLimitConfig{MaxConcurrency: 1})
+
+	release, err := l.Acquire(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx, 0); err == nil {
+		t.Fatal("second acquire should block while the only slot is taken")
+	}
+
+	release()
+	release2, err := l.Acquire(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release2()
+}
+
+func TestApplyRateLimits(t *testing.T) {
+	provider := config.Provider{BaseURL: "https://limits.example", APIKey: "key", RateLimit: config.RateLimitConfig{MaxConcurrency: 1}}
+	l := limiterFor(provider)
+	if ApplyRateLimits(provider) {
+		t.Fatal("unchanged limits reported as changed")
+	}
+
+	// A slot taken under the old limits is released into them
+	release, err := l.Acquire(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.RateLimit.MaxConcurrency = 2
+	if !ApplyRateLimits(provider) {
+		t.Fatal("new limits not applied")
+	}
+	release()
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		_, err := l.Acquire(ctx, 0)
+		cancel()
+		if err != nil {
+			t.Fatalf("acquire %d blocked under the raised limit: %v", i+1, err)
+		}
+	}
+	if limiterFor(provider) != l {
+		t.Error("clients of the same provider should keep sharing a limiter")
+	}
+}
+
+func TestClient_RetriesOn429(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0.05")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(config.Provider{BaseURL: server.URL, APIKey: "test", Model: "m"})
+
+	start := time.Now()
+	resp, err := client.ChatCompletion(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "ok" {
+		t.Errorf("unexpected content %q", resp.Choices[0].Message.Content)
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+	if time.Since(start) < minBackoff {
+		t.Error("retry did not back off")
+	}
+}
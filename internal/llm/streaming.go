@@ -3,6 +3,7 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"sort"
 	"strings"
 )
 
@@ -38,9 +39,12 @@ func (acc *ToolCallAccumulator) AddChunk(chunk StreamResponse) (complete bool, t
 
 	delta := chunk.Choices[0].Delta
 
-	// Accumulate tool calls
-	for _, tc := range delta.ToolCalls {
-		idx := chunk.Choices[0].Index
+	// Accumulate tool calls, which are split across chunks by their index
+	for i, tc := range delta.ToolCalls {
+		idx := i
+		if tc.Index != nil {
+			idx = *tc.Index
+		}
 		
 		if _, exists := acc.toolCalls[idx]; !exists {
 			acc.toolCalls[idx] = &accumulatingToolCall{
@@ -50,6 +54,9 @@ func (acc *ToolCallAccumulator) AddChunk(chunk StreamResponse) (complete bool, t
 		}
 		
 		atc := acc.toolCalls[idx]
+		if atc.ID == "" {
+			atc.ID = tc.ID
+		}
 		
 		if tc.Function.Name != "" {
 			atc.Function.Name = tc.Function.Name
@@ -63,27 +70,38 @@ func (acc *ToolCallAccumulator) AddChunk(chunk StreamResponse) (complete bool, t
 	// Check if finish_reason indicates completion
 	finishReason := chunk.Choices[0].FinishReason
 	if finishReason != "" && finishReason != "null" {
-		// Convert accumulated tool calls to final format
-		result := make([]ToolCall, 0, len(acc.toolCalls))
-		for _, atc := range acc.toolCalls {
-			result = append(result, ToolCall{
-				ID:   atc.ID,
-				Type: atc.Type,
-				Function: struct {
-					Name      string `json:"name"`
-					Arguments string `json:"arguments"`
-				}{
-					Name:      atc.Function.Name,
-					Arguments: atc.Function.Arguments.String(),
-				},
-			})
-		}
-		return true, result
+		return true, acc.calls()
 	}
 
 	return false, nil
 }
 
+// calls returns the accumulated tool calls in the order the model made them
+func (acc *ToolCallAccumulator) calls() []ToolCall {
+	indexes := make([]int, 0, len(acc.toolCalls))
+	for idx := range acc.toolCalls {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+
+	result := make([]ToolCall, 0, len(indexes))
+	for _, idx := range indexes {
+		atc := acc.toolCalls[idx]
+		result = append(result, ToolCall{
+			ID:   atc.ID,
+			Type: atc.Type,
+			Function: struct {
+				Name      string `json:"name"`
+				Arguments string `json:"arguments"`
+			}{
+				Name:      atc.Function.Name,
+				Arguments: atc.Function.Arguments.String(),
+			},
+		})
+	}
+	return result
+}
+
 // StreamHandler manages streaming responses with tool support
 type StreamHandler struct {
 	accumulator    *ToolCallAccumulator
@@ -139,21 +157,7 @@ func (sh *StreamHandler) HandleChunk(chunk StreamResponse) (bool, []ToolCall, er
 	if choice.FinishReason != "" && choice.FinishReason != "null" {
 		// Check if we have accumulated tool calls
 		if len(sh.accumulator.toolCalls) > 0 {
-			toolCalls := make([]ToolCall, 0, len(sh.accumulator.toolCalls))
-			for _, atc := range sh.accumulator.toolCalls {
-				toolCalls = append(toolCalls, ToolCall{
-					ID:   atc.ID,
-					Type: atc.Type,
-					Function: struct {
-						Name      string `json:"name"`
-						Arguments string `json:"arguments"`
-					}{
-						Name:      atc.Function.Name,
-						Arguments: atc.Function.Arguments.String(),
-					},
-				})
-			}
-			return true, toolCalls, nil
+			return true, sh.accumulator.calls(), nil
 		}
 		return true, nil, nil
 	}
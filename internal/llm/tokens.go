@@ -0,0 +1,193 @@
+package llm
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// Encodings used to count tokens. The BPE tables are bundled, so counting
+// never touches the network.
+const (
+	EncodingCL100K = "cl100k_base"
+	EncodingO200K  = "o200k_base"
+)
+
+const (
+	// defaultContextWindow is assumed for models the registry doesn't know
+	defaultContextWindow = 8192
+	// defaultResponseTokens is reserved for the reply when the provider
+	// doesn't set max_tokens
+	defaultResponseTokens = 4096
+	// messageOverhead covers the role and separators each message adds
+	messageOverhead = 4
+)
+
+// ModelInfo describes how a model counts tokens and how many it accepts
+type ModelInfo struct {
+	ContextWindow int
+	Encoding      string
+	// Ratio scales counts from Encoding for models with their own
+	// tokenizer, erring towards overcounting
+	Ratio float64
+	// JSONMode is the response_format the model's API honours, if any
+	JSONMode string
+}
+
+// models maps model name prefixes to what is known about them; the longest
+// matching prefix wins
+var models = map[string]ModelInfo{
+	"gpt-5":         {400000, EncodingO200K, 1, JSONSchemaMode},
+	"gpt-4.1":       {1047576, EncodingO200K, 1, JSONSchemaMode},
+	"gpt-4o":        {128000, EncodingO200K, 1, JSONSchemaMode},
+	"o1":            {200000, EncodingO200K, 1, JSONSchemaMode},
+	"o3":            {200000, EncodingO200K, 1, JSONSchemaMode},
+	"o4":            {200000, EncodingO200K, 1, JSONSchemaMode},
+	"gpt-4-turbo":   {128000, EncodingCL100K, 1, JSONObjectMode},
+	"gpt-4-32k":     {32768, EncodingCL100K, 1, JSONObjectMode},
+	"gpt-4":         {8192, EncodingCL100K, 1, JSONObjectMode},
+	"gpt-3.5-turbo": {16385, EncodingCL100K, 1, JSONObjectMode},
+	"claude":        {200000, EncodingCL100K, 1.2, ""},
+	"gemini":        {1048576, EncodingCL100K, 1.1, JSONSchemaMode},
+	"kimi-k2":       {262144, EncodingCL100K, 1.1, JSONObjectMode},
+	"kimi":          {131072, EncodingCL100K, 1.1, JSONObjectMode},
+	"moonshot":      {131072, EncodingCL100K, 1.1, JSONObjectMode},
+	"deepseek":      {128000, EncodingCL100K, 1.1, JSONObjectMode},
+	"grok":          {131072, EncodingCL100K, 1.1, JSONObjectMode},
+	"glm-4":         {128000, EncodingCL100K, 1.1, JSONObjectMode},
+	"llama-3":       {128000, EncodingCL100K, 1.1, JSONObjectMode},
+	"llama3.1":      {128000, EncodingCL100K, 1.1, JSONObjectMode},
+	"llama3.2":      {128000, EncodingCL100K, 1.1, JSONObjectMode},
+	"llama3.3":      {128000, EncodingCL100K, 1.1, JSONObjectMode},
+	"llama3":        {8192, EncodingCL100K, 1.1, JSONObjectMode},
+	"mistral-large": {131072, EncodingCL100K, 1.1, JSONObjectMode},
+	"mistral":       {32768, EncodingCL100K, 1.1, JSONObjectMode},
+	"mixtral":       {32768, EncodingCL100K, 1.1, JSONObjectMode},
+	"qwen":          {32768, EncodingCL100K, 1.1, JSONObjectMode},
+	"sonar":         {127072, EncodingCL100K, 1.1, JSONObjectMode},
+}
+
+// windowSuffix matches sizes spelled out in model names, e.g. moonshot-v1-32k
+var windowSuffix = regexp.MustCompile(`-(\d+)k\b`)
+
+// LookupModel returns what the registry knows about a model. Provider
+// prefixes such as "anthropic/" are ignored, and unknown models get a small
+// window counted with cl100k.
+func LookupModel(model string) ModelInfo {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	info := ModelInfo{ContextWindow: defaultContextWindow, Encoding: EncodingCL100K, Ratio: 1.1, JSONMode: JSONObjectMode}
+	best := -1
+	for prefix, known := range models {
+		if strings.HasPrefix(name, prefix) && len(prefix) > best {
+			info = known
+			best = len(prefix)
+		}
+	}
+	if m := windowSuffix.FindStringSubmatch(name); m != nil {
+		if k, err := strconv.Atoi(m[1]); err == nil && k > 0 {
+			info.ContextWindow = k * 1024
+		}
+	}
+	return info
+}
+
+// ContextWindow returns how many tokens the provider's model accepts,
+// preferring the provider's context_window setting
+func ContextWindow(provider config.Provider) int {
+	if provider.ContextWindow > 0 {
+		return provider.ContextWindow
+	}
+	return LookupModel(provider.Model).ContextWindow
+}
+
+// PromptBudget returns how many tokens a prompt to the provider may use: the
+// context window minus room for the reply and a margin for tool schemas
+func PromptBudget(provider config.Provider) int {
+	window := ContextWindow(provider)
+	response := provider.MaxTokens
+	if response <= 0 {
+		response = defaultResponseTokens
+	}
+	margin := min(window/10, 8000)
+	return max(window-response-margin, window/4)
+}
+
+// Tokenizer counts tokens the way a particular model does
+type Tokenizer struct {
+	encoding string
+	ratio    float64
+}
+
+// TokenizerFor returns the tokenizer for a model
+func TokenizerFor(model string) *Tokenizer {
+	info := LookupModel(model)
+	return &Tokenizer{encoding: info.Encoding, ratio: info.Ratio}
+}
+
+// Count returns the number of tokens in text
+func (t *Tokenizer) Count(text string) int {
+	if text == "" {
+		return 0
+	}
+	enc := loadEncoding(t.encoding)
+	if enc == nil {
+		// ~4 characters per token for English
+		return int(math.Ceil(float64(len(text)) / 4 * t.ratio))
+	}
+	return int(math.Ceil(float64(len(enc.Encode(text, nil, nil))) * t.ratio))
+}
+
+// CountMessage returns the tokens a message takes in a prompt, including
+// reasoning, tool calls, images and per-message overhead
+func (t *Tokenizer) CountMessage(msg Message) int {
+	tokens := messageOverhead + t.Count(msg.Content) + t.Count(msg.ReasoningContent) + len(msg.Images)*imageTokens
+	for _, tc := range msg.ToolCalls {
+		tokens += t.Count(tc.Function.Name) + t.Count(tc.Function.Arguments) + messageOverhead
+	}
+	return tokens
+}
+
+// CountMessages returns the tokens a list of messages takes in a prompt
+func (t *Tokenizer) CountMessages(msgs []Message) int {
+	total := 0
+	for _, msg := range msgs {
+		total += t.CountMessage(msg)
+	}
+	return total
+}
+
+var (
+	encodingsOnce sync.Once
+	encodingsMu   sync.Mutex
+	encodings     = make(map[string]*tiktoken.Tiktoken)
+)
+
+// loadEncoding returns the named encoding, loading it on first use, or nil
+// if it can't be loaded
+func loadEncoding(name string) *tiktoken.Tiktoken {
+	encodingsOnce.Do(func() {
+		tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+	})
+
+	encodingsMu.Lock()
+	defer encodingsMu.Unlock()
+	enc, ok := encodings[name]
+	if !ok {
+		enc, _ = tiktoken.GetEncoding(name)
+		encodings[name] = enc
+	}
+	return enc
+}
+
+// defaultTokenizer counts for models that aren't known
+var defaultTokenizer = &Tokenizer{encoding: EncodingCL100K, ratio: 1}
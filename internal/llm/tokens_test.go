@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+func TestLookupModel(t *testing.T) {
+	tests := []struct {
+		model    string
+		window   int
+		encoding string
+	}{
+		{"gpt-4o-mini", 128000, EncodingO200K},
+		{"gpt-4", 8192, EncodingCL100K},
+		{"gpt-4-turbo-preview", 128000, EncodingCL100K},
+		{"anthropic/claude-3.5-sonnet", 200000, EncodingCL100K},
+		{"moonshot-v1-32k", 32768, EncodingCL100K},
+		{"llama3.1:8b", 128000, EncodingCL100K},
+		{"llama3", 8192, EncodingCL100K},
+		{"some-new-model", defaultContextWindow, EncodingCL100K},
+	}
+	for _, tt := range tests {
+		info := LookupModel(tt.model)
+		if info.ContextWindow != tt.window || info.Encoding != tt.encoding {
+			t.Errorf("LookupModel(%q) = %+v, want window %d with %s", tt.model, info, tt.window, tt.encoding)
+		}
+	}
+}
+
+func TestPromptBudget(t *testing.T) {
+	budget := PromptBudget(config.Provider{Model: "gpt-4o", MaxTokens: 2000})
+	if budget != 128000-2000-8000 {
+		t.Errorf("Expected the window less the response and margin, got %d", budget)
+	}
+
+	budget = PromptBudget(config.Provider{Model: "gpt-4o", ContextWindow: 16384})
+	if budget != 16384-defaultResponseTokens-1638 {
+		t.Errorf("Expected context_window to override the registry, got %d", budget)
+	}
+
+	if budget := PromptBudget(config.Provider{Model: "tiny", ContextWindow: 4096, MaxTokens: 4096}); budget != 1024 {
+		t.Errorf("Expected a quarter of the window to stay for the prompt, got %d", budget)
+	}
+}
+
+func TestTokenizer_Count(t *testing.T) {
+	openai := TokenizerFor("gpt-4")
+	if n := openai.Count("hello world"); n != 2 {
+		t.Errorf("Expected 2 cl100k tokens, got %d", n)
+	}
+	if n := openai.Count("<|endoftext|>"); n == 0 {
+		t.Error("Expected special tokens in text to be counted as text")
+	}
+
+	claude := TokenizerFor("claude-sonnet-4")
+	text := "The quick brown fox jumps over the lazy dog, again and again."
+	if claude.Count(text) <= openai.Count(text) {
+		t.Error("Expected non-OpenAI models to be counted conservatively")
+	}
+
+	msg := Message{Role: "assistant", Content: "Checking", ToolCalls: []ToolCall{{}}}
+	msg.ToolCalls[0].Function.Name = "weather"
+	msg.ToolCalls[0].Function.Arguments = `{"city":"Paris"}`
+	if n := openai.CountMessage(msg); n <= openai.Count(msg.Content)+openai.Count(msg.ToolCalls[0].Function.Arguments) {
+		t.Errorf("Expected tool calls and overhead to be counted, got %d", n)
+	}
+}
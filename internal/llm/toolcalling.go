@@ -0,0 +1,50 @@
+package llm
+
+import "strings"
+
+// How tools are offered to a model, as set by a provider's tool_calling
+// setting
+const (
+	ToolCallingNative = "native" // through the API's tools parameter
+	ToolCallingPrompt = "prompt" // described in the prompt, with calls parsed from the reply
+)
+
+// promptToolModels are name prefixes of models that don't reliably emit
+// native tool calls, mostly local models served by Ollama. A trailing colon
+// matches the bare name or any of its tags, e.g. llama3 but not llama3.1.
+var promptToolModels = []string{
+	"gemma", "phi:", "phi3", "phi4:", "llama2", "llama3:", "codellama", "tinyllama",
+	"llava", "bakllava", "moondream", "deepseek-r1", "deepseek-coder:", "starcoder",
+	"orca", "vicuna", "falcon", "yi:", "tinydolphin", "neural-chat",
+}
+
+// SupportsTools reports whether a model is expected to emit native tool calls
+func SupportsTools(model string) bool {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name += ":"
+	for _, known := range promptToolModels {
+		if strings.HasPrefix(name, known) {
+			return false
+		}
+	}
+	return true
+}
+
+// ToolCalling returns how the client's model should be offered tools,
+// preferring the provider's tool_calling setting
+func (c *Client) ToolCalling() string {
+	c = c.active()
+	switch strings.ToLower(c.provider.ToolCalling) {
+	case ToolCallingNative:
+		return ToolCallingNative
+	case ToolCallingPrompt:
+		return ToolCallingPrompt
+	}
+	if SupportsTools(c.provider.Model) {
+		return ToolCallingNative
+	}
+	return ToolCallingPrompt
+}
@@ -0,0 +1,251 @@
+package llm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	// imageTokens approximates what an image costs in a prompt; a 1024px
+	// image is ~765 tokens for OpenAI and ~1,400 for Claude
+	imageTokens = 1000
+	// maxImageBytes limits images fetched to send inline
+	maxImageBytes = 20 << 20
+)
+
+// ImagePart is an image sent with a message, either by URL or inline
+type ImagePart struct {
+	URL      string // fetched by the provider, or by the client for providers that only take inline images
+	Data     []byte // the image itself
+	MIMEType string // of Data, e.g. image/jpeg
+	Detail   string // "low", "high" or "auto"; OpenAI only
+}
+
+// ImageFromFile reads an image to send inline
+func ImageFromFile(path string) (ImagePart, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ImagePart{}, err
+	}
+	return ImagePart{Data: data, MIMEType: http.DetectContentType(data)}, nil
+}
+
+// dataURL returns the image as a URL the APIs accept
+func (p ImagePart) dataURL() string {
+	if len(p.Data) == 0 {
+		return p.URL
+	}
+	return "data:" + p.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(p.Data)
+}
+
+// imageFromURL reads an image part back from a URL, decoding data URLs
+func imageFromURL(url, detail string) ImagePart {
+	if rest, ok := strings.CutPrefix(url, "data:"); ok {
+		if mime, encoded, ok := strings.Cut(rest, ";base64,"); ok {
+			if data, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+				return ImagePart{Data: data, MIMEType: mime, Detail: detail}
+			}
+		}
+	}
+	return ImagePart{URL: url, Detail: detail}
+}
+
+// contentPart is an element of a message's content when it has images
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// MarshalJSON sends messages with images as a list of content parts, the
+// form OpenAI-compatible APIs take for vision
+func (m Message) MarshalJSON() ([]byte, error) {
+	type plain Message
+	if len(m.Images) == 0 {
+		return json.Marshal(plain(m))
+	}
+
+	parts := make([]contentPart, 0, len(m.Images)+1)
+	if m.Content != "" {
+		parts = append(parts, contentPart{Type: "text", Text: m.Content})
+	}
+	for _, img := range m.Images {
+		parts = append(parts, contentPart{Type: "image_url", ImageURL: &imageURL{URL: img.dataURL(), Detail: img.Detail}})
+	}
+	return json.Marshal(struct {
+		plain
+		Content []contentPart `json:"content"`
+	}{plain(m), parts})
+}
+
+// UnmarshalJSON reads content given as a string or as content parts
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type plain Message
+	var raw struct {
+		plain
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*m = Message(raw.plain)
+
+	content := strings.TrimSpace(string(raw.Content))
+	switch {
+	case content == "" || content == "null":
+		return nil
+	case strings.HasPrefix(content, `"`):
+		return json.Unmarshal(raw.Content, &m.Content)
+	}
+
+	var parts []contentPart
+	if err := json.Unmarshal(raw.Content, &parts); err != nil {
+		return err
+	}
+	var texts []string
+	for _, part := range parts {
+		switch {
+		case part.Type == "text":
+			texts = append(texts, part.Text)
+		case part.ImageURL != nil:
+			m.Images = append(m.Images, imageFromURL(part.ImageURL.URL, part.ImageURL.Detail))
+		}
+	}
+	m.Content = strings.Join(texts, "\n")
+	return nil
+}
+
+// visionModels are name prefixes and fragments of models that accept images
+var visionModels = []string{
+	"gpt-4o", "gpt-4.1", "gpt-4-turbo", "gpt-5", "o1", "o3", "o4",
+	"claude-3", "claude-opus", "claude-sonnet", "claude-haiku",
+	"gemini", "gemma3", "kimi-k2.5", "grok-2-vision", "grok-4", "llama-4", "llama4",
+	"llava", "bakllava", "moondream", "minicpm-v", "pixtral", "qwen2.5vl",
+	"vision", "-vl",
+}
+
+// SupportsVision reports whether a model is known to accept images
+func SupportsVision(model string) bool {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	for _, known := range visionModels {
+		if strings.HasPrefix(name, known) || (strings.HasPrefix(known, "-") || known == "vision") && strings.Contains(name, known) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsVision reports whether the client's model accepts images, either
+// because it's known to or because the provider is configured with vision
+func (c *Client) SupportsVision() bool {
+	c = c.active()
+	return c.provider.Vision || SupportsVision(c.provider.Model)
+}
+
+// Provider APIs differ in how they take images
+const (
+	flavorOpenAI    = "openai"
+	flavorAnthropic = "anthropic"
+	flavorGoogle    = "google"
+	flavorOllama    = "ollama"
+)
+
+// providerFlavor tells which API a base URL belongs to
+func providerFlavor(baseURL string) string {
+	switch {
+	case strings.Contains(baseURL, "anthropic.com"):
+		return flavorAnthropic
+	case strings.Contains(baseURL, "googleapis.com"):
+		return flavorGoogle
+	case strings.Contains(baseURL, ":11434"), strings.Contains(baseURL, "ollama"):
+		return flavorOllama
+	}
+	return flavorOpenAI
+}
+
+// anthropicImageTypes are the image types Claude accepts
+var anthropicImageTypes = map[string]bool{
+	"image/jpeg": true, "image/png": true, "image/gif": true, "image/webp": true,
+}
+
+// adaptImages prepares a request's images for the provider. Gemini and
+// Ollama only take inline images, so URLs are fetched for them; only OpenAI
+// understands the detail setting. The caller's messages aren't modified.
+func (c *Client) adaptImages(ctx context.Context, req *ChatRequest) error {
+	flavor := providerFlavor(c.provider.BaseURL)
+	var messages []Message
+	for i, msg := range req.Messages {
+		if len(msg.Images) == 0 {
+			continue
+		}
+		if messages == nil {
+			messages = append([]Message(nil), req.Messages...)
+		}
+
+		images := make([]ImagePart, len(msg.Images))
+		for j, img := range msg.Images {
+			if flavor != flavorOpenAI {
+				img.Detail = ""
+			}
+			if len(img.Data) == 0 && (flavor == flavorGoogle || flavor == flavorOllama) {
+				fetched, err := c.fetchImage(ctx, img.URL)
+				if err != nil {
+					return err
+				}
+				img.Data, img.MIMEType = fetched.Data, fetched.MIMEType
+			}
+			if flavor == flavorAnthropic && len(img.Data) > 0 && !anthropicImageTypes[img.MIMEType] {
+				return fmt.Errorf("unsupported image type for Claude: %s", img.MIMEType)
+			}
+			images[j] = img
+		}
+		messages[i].Images = images
+	}
+	if messages != nil {
+		req.Messages = messages
+	}
+	return nil
+}
+
+// fetchImage downloads an image to send inline
+func (c *Client) fetchImage(ctx context.Context, url string) (ImagePart, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return ImagePart{}, fmt.Errorf("invalid image URL: %w", err)
+	}
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return ImagePart{}, fmt.Errorf("failed to fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ImagePart{}, fmt.Errorf("failed to fetch image: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return ImagePart{}, fmt.Errorf("failed to fetch image: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return ImagePart{}, fmt.Errorf("image too large: over %d MB", maxImageBytes>>20)
+	}
+	mime := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(mime, "image/") {
+		mime = http.DetectContentType(data)
+	}
+	return ImagePart{Data: data, MIMEType: mime}, nil
+}
@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+// pngHeader is enough of a PNG for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestMessage_ImagesJSON(t *testing.T) {
+	msg := Message{Role: "user", Content: "What is this?", Images: []ImagePart{
+		{Data: []byte("abc"), MIMEType: "image/png"},
+		{URL: "https://example.com/cat.jpg", Detail: "low"},
+	}}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `{"role":"user","content":[{"type":"text","text":"What is this?"},` +
+		`{"type":"image_url","image_url":{"url":"data:image/png;base64,YWJj"}},` +
+		`{"type":"image_url","image_url":{"url":"https://example.com/cat.jpg","detail":"low"}}]}`
+	if string(data) != want {
+		t.Errorf("Expected content parts, got %s", data)
+	}
+
+	var back Message
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if back.Content != "What is this?" || len(back.Images) != 2 || string(back.Images[0].Data) != "abc" || back.Images[1].URL != "https://example.com/cat.jpg" {
+		t.Errorf("Expected the message back, got %+v", back)
+	}
+
+	data, _ = json.Marshal(Message{Role: "user", Content: "plain"})
+	if string(data) != `{"role":"user","content":"plain"}` {
+		t.Errorf("Expected text-only messages to be unchanged, got %s", data)
+	}
+}
+
+func TestSupportsVision(t *testing.T) {
+	for model, want := range map[string]bool{
+		"gpt-4o-mini":                       true,
+		"anthropic/claude-sonnet-4":         true,
+		"gemini-2.5-flash":                  true,
+		"llava:13b":                         true,
+		"llama3.2-vision":                   true,
+		"qwen2-vl-7b":                       true,
+		"deepseek-chat":                     false,
+		"llama3.2":                          false,
+		"accounts/fireworks/models/mixtral": false,
+	} {
+		if got := SupportsVision(model); got != want {
+			t.Errorf("SupportsVision(%q) = %v, want %v", model, got, want)
+		}
+	}
+
+	if !NewClient(config.Provider{Model: "my-finetune", Vision: true}).SupportsVision() {
+		t.Error("Expected the provider's vision setting to be honoured")
+	}
+}
+
+func TestClient_AdaptImages(t *testing.T) {
+	images := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(pngHeader)
+	}))
+	defer images.Close()
+
+	var sent []Message
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		sent = req.Messages
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": Message{Role: "assistant", Content: "A cat"}}},
+		})
+	}))
+	defer api.Close()
+
+	msgs := []Message{{Role: "user", Content: "What is this?", Images: []ImagePart{{URL: images.URL + "/cat.png", Detail: "high"}}}}
+
+	// OpenAI fetches the URL itself
+	client := NewClient(config.Provider{BaseURL: api.URL, Model: "gpt-4o"})
+	if _, err := client.ChatCompletion(context.Background(), ChatRequest{Messages: msgs}); err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if img := sent[0].Images[0]; img.URL != images.URL+"/cat.png" || img.Detail != "high" {
+		t.Errorf("Expected the URL to be passed through, got %+v", img)
+	}
+
+	// Ollama only takes inline images
+	ollama := &Client{provider: config.Provider{BaseURL: "http://localhost:11434/v1", Model: "llava"}, client: http.DefaultClient}
+	req := ChatRequest{Messages: msgs}
+	if err := ollama.adaptImages(context.Background(), &req); err != nil {
+		t.Fatalf("adaptImages failed: %v", err)
+	}
+	img := req.Messages[0].Images[0]
+	if img.MIMEType != "image/png" || !strings.HasPrefix(string(img.Data), "\x89PNG") || img.Detail != "" {
+		t.Errorf("Expected the image inline without detail, got %+v", img)
+	}
+	if len(msgs[0].Images[0].Data) != 0 {
+		t.Error("Expected the caller's messages to be left alone")
+	}
+
+	claude := &Client{provider: config.Provider{BaseURL: "https://api.anthropic.com/v1"}}
+	req = ChatRequest{Messages: []Message{{Role: "user", Images: []ImagePart{{Data: []byte("BM"), MIMEType: "image/bmp"}}}}}
+	if err := claude.adaptImages(context.Background(), &req); err == nil {
+		t.Error("Expected Claude to reject BMP images")
+	}
+}
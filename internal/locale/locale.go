@@ -0,0 +1,292 @@
+// Package locale holds per-user language and regional preferences and the
+// date, time and money formatting derived from them. Skills read the active
+// locale from the tool call context.
+package locale
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Date orders
+const (
+	OrderMDY = "mdy" // 01/02/2006
+	OrderDMY = "dmy" // 02/01/2006
+	OrderYMD = "ymd" // 2006-01-02
+)
+
+// Measurement systems
+const (
+	UnitsMetric   = "metric"
+	UnitsImperial = "imperial"
+)
+
+// Locale describes how a user reads and writes dates, times and amounts
+type Locale struct {
+	Language       string       `json:"language"`          // BCP 47 tag, e.g. en-GB
+	DateOrder      string       `json:"date_order"`        // mdy, dmy or ymd
+	Clock24        bool         `json:"clock_24h"`         // 15:04 instead of 3:04 PM
+	FirstDayOfWeek time.Weekday `json:"first_day_of_week"` // 0 = Sunday
+	Currency       string       `json:"currency"`          // ISO 4217 code
+	Units          string       `json:"units"`             // metric or imperial
+}
+
+// presets maps language tags to their usual conventions
+var presets = map[string]Locale{
+	"en-us": {Language: "en-US", DateOrder: OrderMDY, FirstDayOfWeek: time.Sunday, Currency: "USD", Units: UnitsImperial},
+	"en-gb": {Language: "en-GB", DateOrder: OrderDMY, Clock24: true, FirstDayOfWeek: time.Monday, Currency: "GBP", Units: UnitsMetric},
+	"en-au": {Language: "en-AU", DateOrder: OrderDMY, FirstDayOfWeek: time.Monday, Currency: "AUD", Units: UnitsMetric},
+	"en-in": {Language: "en-IN", DateOrder: OrderDMY, FirstDayOfWeek: time.Monday, Currency: "INR", Units: UnitsMetric},
+	"en-my": {Language: "en-MY", DateOrder: OrderDMY, FirstDayOfWeek: time.Monday, Currency: "MYR", Units: UnitsMetric},
+	"en-sg": {Language: "en-SG", DateOrder: OrderDMY, FirstDayOfWeek: time.Monday, Currency: "SGD", Units: UnitsMetric},
+	"ms-my": {Language: "ms-MY", DateOrder: OrderDMY, FirstDayOfWeek: time.Monday, Currency: "MYR", Units: UnitsMetric},
+	"de-de": {Language: "de-DE", DateOrder: OrderDMY, Clock24: true, FirstDayOfWeek: time.Monday, Currency: "EUR", Units: UnitsMetric},
+	"fr-fr": {Language: "fr-FR", DateOrder: OrderDMY, Clock24: true, FirstDayOfWeek: time.Monday, Currency: "EUR", Units: UnitsMetric},
+	"es-es": {Language: "es-ES", DateOrder: OrderDMY, Clock24: true, FirstDayOfWeek: time.Monday, Currency: "EUR", Units: UnitsMetric},
+	"ja-jp": {Language: "ja-JP", DateOrder: OrderYMD, Clock24: true, FirstDayOfWeek: time.Sunday, Currency: "JPY", Units: UnitsMetric},
+	"zh-cn": {Language: "zh-CN", DateOrder: OrderYMD, Clock24: true, FirstDayOfWeek: time.Monday, Currency: "CNY", Units: UnitsMetric},
+}
+
+// currencySymbols are the symbols used when formatting amounts
+var currencySymbols = map[string]string{
+	"USD": "$", "EUR": "€", "GBP": "£", "JPY": "¥", "CNY": "¥", "INR": "₹",
+	"MYR": "RM", "SGD": "S$", "AUD": "A$",
+}
+
+// zeroDecimalCurrencies have no minor unit
+var zeroDecimalCurrencies = map[string]bool{"JPY": true, "KRW": true}
+
+// Default returns the locale used when a user has not set one
+func Default() Locale {
+	return presets["en-us"]
+}
+
+// ForLanguage returns the preset for a language tag such as "en-GB". The
+// second return value is false for unknown tags.
+func ForLanguage(tag string) (Locale, bool) {
+	l, ok := presets[strings.ToLower(strings.ReplaceAll(tag, "_", "-"))]
+	return l, ok
+}
+
+// Languages returns the language tags with built-in presets
+func Languages() []string {
+	tags := make([]string, 0, len(presets))
+	for _, l := range presets {
+		tags = append(tags, l.Language)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Validate checks that the locale's fields hold known values
+func (l Locale) Validate() error {
+	switch l.DateOrder {
+	case OrderMDY, OrderDMY, OrderYMD:
+	default:
+		return fmt.Errorf("invalid date order %q: use mdy, dmy or ymd", l.DateOrder)
+	}
+	switch l.Units {
+	case UnitsMetric, UnitsImperial:
+	default:
+		return fmt.Errorf("invalid units %q: use metric or imperial", l.Units)
+	}
+	if l.FirstDayOfWeek < time.Sunday || l.FirstDayOfWeek > time.Saturday {
+		return fmt.Errorf("invalid first day of week %d", l.FirstDayOfWeek)
+	}
+	if len(l.Currency) != 3 {
+		return fmt.Errorf("invalid currency %q: use a 3-letter code such as USD", l.Currency)
+	}
+	return nil
+}
+
+// Time formats a time of day, e.g. "3:04 PM" or "15:04"
+func (l Locale) Time(t time.Time) string {
+	if l.Clock24 {
+		return t.Format("15:04")
+	}
+	return t.Format("3:04 PM")
+}
+
+// Date formats a full date, e.g. "Jan 2, 2006", "2 Jan 2006" or "2006-01-02"
+func (l Locale) Date(t time.Time) string {
+	switch l.DateOrder {
+	case OrderDMY:
+		return t.Format("2 Jan 2006")
+	case OrderYMD:
+		return t.Format("2006-01-02")
+	}
+	return t.Format("Jan 2, 2006")
+}
+
+// ShortDate formats a date without the year, e.g. "Jan 2" or "2 Jan"
+func (l Locale) ShortDate(t time.Time) string {
+	switch l.DateOrder {
+	case OrderDMY:
+		return t.Format("2 Jan")
+	case OrderYMD:
+		return t.Format("01-02")
+	}
+	return t.Format("Jan 2")
+}
+
+// DateTime formats a full date with the time of day
+func (l Locale) DateTime(t time.Time) string {
+	return l.Date(t) + " " + l.Time(t)
+}
+
+// ShortDateTime formats a date without the year and the time of day, e.g.
+// "Jan 2, 3:04 PM" or "2 Jan, 15:04"
+func (l Locale) ShortDateTime(t time.Time) string {
+	return l.ShortDate(t) + ", " + l.Time(t)
+}
+
+// WeekdayDate formats a date with its weekday, e.g. "Monday, Jan 2"
+func (l Locale) WeekdayDate(t time.Time) string {
+	return t.Format("Monday") + ", " + l.ShortDate(t)
+}
+
+// WeekdayDateYear formats a full date with its weekday
+func (l Locale) WeekdayDateYear(t time.Time) string {
+	return t.Format("Monday") + ", " + l.Date(t)
+}
+
+// ShortWeekdayDate formats a date with an abbreviated weekday, e.g. "Mon, Jan 2"
+func (l Locale) ShortWeekdayDate(t time.Time) string {
+	return t.Format("Mon") + ", " + l.ShortDate(t)
+}
+
+// Money formats an amount in the given currency, or the locale's currency
+// when currency is empty, e.g. "$1,234.50" or "RM12.00"
+func (l Locale) Money(amount float64, currency string) string {
+	if currency == "" {
+		currency = l.Currency
+	}
+	currency = strings.ToUpper(currency)
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	decimals := 2
+	if zeroDecimalCurrencies[currency] {
+		decimals = 0
+	}
+	number := groupThousands(amount, decimals)
+
+	if symbol, ok := currencySymbols[currency]; ok {
+		return sign + symbol + number
+	}
+	return sign + number + " " + currency
+}
+
+// unitConversions maps units to their counterpart in the other measurement
+// system and the factor to convert to it
+var unitConversions = map[string]struct {
+	system string
+	to     string
+	factor float64
+}{
+	"lb":  {UnitsImperial, "kg", 0.453592},
+	"oz":  {UnitsImperial, "g", 28.3495},
+	"gal": {UnitsImperial, "L", 3.78541},
+	"kg":  {UnitsMetric, "lb", 2.20462},
+	"g":   {UnitsMetric, "oz", 0.035274},
+	"L":   {UnitsMetric, "gal", 0.264172},
+}
+
+// Quantity formats an amount with its unit. Weights and volumes given in the
+// other measurement system get the converted amount added, e.g. "2 lb
+// (~0.91 kg)" for a metric user.
+func (l Locale) Quantity(amount, unit string) string {
+	if unit == "" {
+		return amount
+	}
+	out := amount + " " + unit
+	conv, ok := unitConversions[unit]
+	if !ok || conv.system == l.Units {
+		return out
+	}
+	n, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return out
+	}
+	converted := strconv.FormatFloat(math.Round(n*conv.factor*100)/100, 'f', -1, 64)
+	return fmt.Sprintf("%s (~%s %s)", out, converted, conv.to)
+}
+
+// StartOfWeek returns midnight on the first day of the week containing t
+func (l Locale) StartOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) - int(l.FirstDayOfWeek) + 7) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// NumericDateLayouts returns the numeric date layouts to try when parsing,
+// in the locale's day/month order. ISO dates are always accepted.
+func (l Locale) NumericDateLayouts() []string {
+	layouts := []string{"2006-01-02", "2006-01-02 15:04", "2006-01-02 15:04:05", "2006/01/02"}
+	switch l.DateOrder {
+	case OrderDMY:
+		layouts = append(layouts, "02/01/2006", "2/1/2006", "02/01/2006 15:04", "2/1/06", "02.01.2006", "2-1-06")
+	case OrderMDY:
+		layouts = append(layouts, "01/02/2006", "1/2/2006", "01/02/2006 15:04", "1/2/06", "1-2-06")
+	}
+	return layouts
+}
+
+// DayFirst reports whether ambiguous numeric dates put the day first
+func (l Locale) DayFirst() bool {
+	return l.DateOrder == OrderDMY
+}
+
+// Summary describes the locale in one line, for prompts and settings output
+func (l Locale) Summary() string {
+	clock := "12-hour"
+	if l.Clock24 {
+		clock = "24-hour"
+	}
+	return fmt.Sprintf("%s, dates %s (e.g. %s), %s clock, week starts %s, currency %s, %s units",
+		l.Language, strings.ToUpper(l.DateOrder), l.Date(time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)),
+		clock, l.FirstDayOfWeek, l.Currency, l.Units)
+}
+
+func groupThousands(amount float64, decimals int) string {
+	s := fmt.Sprintf("%.*f", decimals, amount)
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i:]
+	}
+	if math.Abs(amount) < 1000 {
+		return intPart + frac
+	}
+
+	var sb strings.Builder
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String() + frac
+}
+
+type localeKey struct{}
+
+// WithLocale returns a context carrying the user's locale
+func WithLocale(ctx context.Context, l Locale) context.Context {
+	return context.WithValue(ctx, localeKey{}, l)
+}
+
+// FromContext returns the locale in ctx, or Default() when none is set
+func FromContext(ctx context.Context) Locale {
+	if l, ok := ctx.Value(localeKey{}).(Locale); ok {
+		return l
+	}
+	return Default()
+}
@@ -0,0 +1,122 @@
+package locale
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestLocale_Formatting(t *testing.T) {
+	ts := time.Date(2025, 3, 4, 15, 4, 0, 0, time.UTC)
+
+	us := Default()
+	assert.Equal(t, "Mar 4, 2025", us.Date(ts))
+	assert.Equal(t, "Mar 4, 3:04 PM", us.ShortDateTime(ts))
+	assert.Equal(t, "Tuesday, Mar 4", us.WeekdayDate(ts))
+
+	gb, ok := ForLanguage("en_GB")
+	require.True(t, ok)
+	assert.Equal(t, "4 Mar 2025", gb.Date(ts))
+	assert.Equal(t, "4 Mar, 15:04", gb.ShortDateTime(ts))
+	assert.Equal(t, "Tue, 4 Mar", gb.ShortWeekdayDate(ts))
+
+	jp, _ := ForLanguage("ja-JP")
+	assert.Equal(t, "2025-03-04 15:04", jp.DateTime(ts))
+
+	_, ok = ForLanguage("xx-YY")
+	assert.False(t, ok)
+}
+
+func TestLocale_Money(t *testing.T) {
+	us := Default()
+	assert.Equal(t, "$1,234.50", us.Money(1234.5, ""))
+	assert.Equal(t, "-$5.00", us.Money(-5, "usd"))
+	assert.Equal(t, "€12.00", us.Money(12, "EUR"))
+	assert.Equal(t, "¥1,500", us.Money(1500, "JPY"))
+	assert.Equal(t, "10.00 CHF", us.Money(10, "CHF"))
+
+	my, _ := ForLanguage("ms-MY")
+	assert.Equal(t, "RM1,000,000.00", my.Money(1000000, ""))
+}
+
+func TestLocale_StartOfWeek(t *testing.T) {
+	wed := time.Date(2025, 3, 5, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), Default().StartOfWeek(wed))
+
+	gb, _ := ForLanguage("en-GB")
+	assert.Equal(t, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), gb.StartOfWeek(wed))
+
+	// A Sunday belongs to the week that started the Monday before
+	sun := time.Date(2025, 3, 9, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), gb.StartOfWeek(sun))
+}
+
+func TestLocale_Quantity(t *testing.T) {
+	gb, _ := ForLanguage("en-GB")
+	assert.Equal(t, "2 lb (~0.91 kg)", gb.Quantity("2", "lb"))
+	assert.Equal(t, "500 g", gb.Quantity("500", "g"))
+	assert.Equal(t, "3", gb.Quantity("3", ""))
+
+	assert.Equal(t, "1 kg (~2.2 lb)", Default().Quantity("1", "kg"))
+	assert.Equal(t, "2 pack", Default().Quantity("2", "pack"))
+}
+
+func TestApply(t *testing.T) {
+	l, err := Apply(Default(), "language", "de-DE")
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", l.Currency)
+	assert.True(t, l.Clock24)
+
+	l, err = Apply(l, "currency", "myr")
+	require.NoError(t, err)
+	assert.Equal(t, "MYR", l.Currency)
+
+	l, err = Apply(l, "first_day", "sat")
+	require.NoError(t, err)
+	assert.Equal(t, time.Saturday, l.FirstDayOfWeek)
+
+	l, err = Apply(l, "clock", "12h")
+	require.NoError(t, err)
+	assert.False(t, l.Clock24)
+
+	_, err = Apply(l, "date_order", "dym")
+	assert.Error(t, err)
+	_, err = Apply(l, "units", "furlongs")
+	assert.Error(t, err)
+	_, err = Apply(l, "colour", "blue")
+	assert.Error(t, err)
+}
+
+func TestManager(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	m, err := NewManager(db)
+	require.NoError(t, err)
+
+	assert.Equal(t, Default(), m.Get("alice"))
+	assert.False(t, m.IsSet("alice"))
+
+	gb, _ := ForLanguage("en-GB")
+	require.NoError(t, m.Set("alice", gb))
+	assert.Equal(t, gb, m.Get("alice"))
+	assert.True(t, m.IsSet("alice"))
+	assert.Equal(t, Default(), m.Get("bob"))
+
+	assert.Error(t, m.Set("alice", Locale{DateOrder: "nope"}))
+
+	require.NoError(t, m.Reset("alice"))
+	assert.Equal(t, Default(), m.Get("alice"))
+}
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, Default(), FromContext(context.Background()))
+
+	gb, _ := ForLanguage("en-GB")
+	assert.Equal(t, gb, FromContext(WithLocale(context.Background(), gb)))
+}
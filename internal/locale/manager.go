@@ -0,0 +1,156 @@
+package locale
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"gorm.io/gorm"
+)
+
+// UserLocale is a user's saved locale
+type UserLocale struct {
+	UserID         string    `gorm:"primaryKey" json:"user_id"`
+	Language       string    `json:"language"`
+	DateOrder      string    `json:"date_order"`
+	Clock24        bool      `json:"clock_24h"`
+	FirstDayOfWeek int       `json:"first_day_of_week"`
+	Currency       string    `json:"currency"`
+	Units          string    `json:"units"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName sets the table name
+func (UserLocale) TableName() string { return "user_locales" }
+
+// Locale returns the saved preferences as a Locale
+func (u *UserLocale) Locale() Locale {
+	return Locale{
+		Language:       u.Language,
+		DateOrder:      u.DateOrder,
+		Clock24:        u.Clock24,
+		FirstDayOfWeek: time.Weekday(u.FirstDayOfWeek),
+		Currency:       u.Currency,
+		Units:          u.Units,
+	}
+}
+
+// Manager stores locales per user
+type Manager struct {
+	db *gorm.DB
+}
+
+func init() {
+	store.RegisterMigrations("locale", store.Migration{
+		Version: 1,
+		Name:    "create locale table",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&UserLocale{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&UserLocale{})
+		},
+	})
+}
+
+// NewManager creates a new locale manager
+func NewManager(db *gorm.DB) (*Manager, error) {
+	if err := store.Migrate(db, "locale"); err != nil {
+		return nil, fmt.Errorf("failed to migrate locale schema: %w", err)
+	}
+	return &Manager{db: db}, nil
+}
+
+// Get returns the user's locale, or Default() if they have not set one
+func (m *Manager) Get(userID string) Locale {
+	var saved UserLocale
+	if err := m.db.Where("user_id = ?", userID).First(&saved).Error; err != nil {
+		return Default()
+	}
+	return saved.Locale()
+}
+
+// IsSet reports whether the user has saved a locale
+func (m *Manager) IsSet(userID string) bool {
+	var count int64
+	m.db.Model(&UserLocale{}).Where("user_id = ?", userID).Count(&count)
+	return count > 0
+}
+
+// Set saves the user's locale
+func (m *Manager) Set(userID string, l Locale) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+	l.Currency = strings.ToUpper(l.Currency)
+
+	saved := &UserLocale{
+		UserID:         userID,
+		Language:       l.Language,
+		DateOrder:      l.DateOrder,
+		Clock24:        l.Clock24,
+		FirstDayOfWeek: int(l.FirstDayOfWeek),
+		Currency:       l.Currency,
+		Units:          l.Units,
+	}
+	return m.db.Save(saved).Error
+}
+
+// Reset removes the user's locale so the default applies again
+func (m *Manager) Reset(userID string) error {
+	return m.db.Where("user_id = ?", userID).Delete(&UserLocale{}).Error
+}
+
+// Apply returns l with one setting changed. Keys are language (which loads
+// that language's preset), date_order, clock, first_day, currency and units.
+func Apply(l Locale, key, value string) (Locale, error) {
+	value = strings.TrimSpace(value)
+
+	switch strings.ToLower(key) {
+	case "language", "lang":
+		preset, ok := ForLanguage(value)
+		if !ok {
+			return l, fmt.Errorf("unknown language %q (known: %s)", value, strings.Join(Languages(), ", "))
+		}
+		return preset, nil
+	case "date_order", "dates":
+		l.DateOrder = strings.ToLower(value)
+	case "clock":
+		switch strings.ToLower(value) {
+		case "24", "24h":
+			l.Clock24 = true
+		case "12", "12h":
+			l.Clock24 = false
+		default:
+			return l, fmt.Errorf("invalid clock %q: use 12h or 24h", value)
+		}
+	case "first_day", "week_start":
+		day, err := parseWeekday(value)
+		if err != nil {
+			return l, err
+		}
+		l.FirstDayOfWeek = day
+	case "currency":
+		l.Currency = strings.ToUpper(value)
+	case "units":
+		l.Units = strings.ToLower(value)
+	default:
+		return l, fmt.Errorf("unknown setting %q: use language, date_order, clock, first_day, currency or units", key)
+	}
+	return l, l.Validate()
+}
+
+func parseWeekday(value string) (time.Weekday, error) {
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 6 {
+		return time.Weekday(n), nil
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if v := strings.ToLower(value); v == name || (len(v) >= 3 && strings.HasPrefix(name, v)) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid first day of week %q", value)
+}
@@ -0,0 +1,161 @@
+// Package location keeps track of where the user is, for users who choose to
+// share it. Phones check in through the API (OwnTracks, iOS Shortcuts);
+// check-ins are kept only for a limited time, named places such as home and
+// work turn them into arrivals and departures, and reminders can fire on
+// either. Tools see the latest check-in through the context.
+package location
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ID prefixes
+const (
+	PrefixFix      = "loc"
+	PrefixPlace    = "plc"
+	PrefixReminder = "lrem"
+)
+
+// MaxAge is how old a check-in may be and still say where the user is
+const MaxAge = 6 * time.Hour
+
+// DefaultRadius is a place's radius in meters when none is given
+const DefaultRadius = 150.0
+
+// MaxRetentionDays is the longest a user may keep their check-ins
+const MaxRetentionDays = 90
+
+// PlaceAccuracy is the worst accuracy, in meters, a check-in may have and
+// still move the user into or out of a place
+const PlaceAccuracy = 500.0
+
+// Reminder triggers
+const (
+	TriggerArrive = "arrive"
+	TriggerLeave  = "leave"
+)
+
+// Fix is one check-in
+type Fix struct {
+	ID         string    `gorm:"primaryKey" json:"id"`
+	UserID     string    `gorm:"index;not null" json:"user_id"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	Accuracy   float64   `json:"accuracy,omitempty"` // meters
+	Source     string    `json:"source,omitempty"`   // e.g. owntracks, shortcuts
+	Place      string    `json:"place,omitempty"`    // the named place the fix is in
+	RecordedAt time.Time `gorm:"index" json:"recorded_at"`
+}
+
+// TableName sets the table name
+func (Fix) TableName() string { return "location_fixes" }
+
+// Coordinates formats the fix as "lat,lon", which weather and map services
+// accept in place of a city name
+func (f *Fix) Coordinates() string {
+	return fmt.Sprintf("%.4f,%.4f", f.Latitude, f.Longitude)
+}
+
+// Validate checks the coordinates are on Earth
+func (f *Fix) Validate() error {
+	if math.IsNaN(f.Latitude) || f.Latitude < -90 || f.Latitude > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if math.IsNaN(f.Longitude) || f.Longitude < -180 || f.Longitude > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+	if f.Accuracy < 0 {
+		return fmt.Errorf("accuracy cannot be negative")
+	}
+	return nil
+}
+
+// Settings holds a user's choice to share their location
+type Settings struct {
+	UserID        string    `gorm:"primaryKey" json:"user_id"`
+	Enabled       bool      `json:"enabled"`
+	RetentionDays int       `json:"retention_days"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName sets the table name
+func (Settings) TableName() string { return "location_settings" }
+
+// Place is a named circle on the map, such as home or work
+type Place struct {
+	ID        string    `gorm:"primaryKey" json:"id"`
+	UserID    string    `gorm:"index;not null" json:"user_id"`
+	Name      string    `gorm:"not null" json:"name"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Radius    float64   `json:"radius"` // meters
+	Inside    bool      `json:"inside"` // whether the last usable check-in was here
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName sets the table name
+func (Place) TableName() string { return "location_places" }
+
+// Contains reports whether the point lies within the place
+func (p *Place) Contains(lat, lon float64) bool {
+	return Distance(p.Latitude, p.Longitude, lat, lon) <= p.Radius
+}
+
+// Reminder is a message to send once when the user arrives at or leaves a
+// place
+type Reminder struct {
+	ID        string     `gorm:"primaryKey" json:"id"`
+	UserID    string     `gorm:"index;not null" json:"user_id"`
+	Place     string     `gorm:"not null" json:"place"`
+	Trigger   string     `gorm:"column:trigger_on;not null" json:"trigger"`
+	Message   string     `gorm:"not null" json:"message"`
+	CreatedAt time.Time  `json:"created_at"`
+	FiredAt   *time.Time `json:"fired_at,omitempty"`
+}
+
+// TableName sets the table name
+func (Reminder) TableName() string { return "location_reminders" }
+
+// Describe says when the reminder fires, e.g. "when you get home"
+func (r *Reminder) Describe() string {
+	if r.Trigger == TriggerLeave {
+		return "when you leave " + r.Place
+	}
+	if strings.EqualFold(r.Place, "home") {
+		return "when you get home"
+	}
+	return "when you get to " + r.Place
+}
+
+// Distance returns the great-circle distance in meters between two points
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371000.0
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+type fixKey struct{}
+
+// WithFix returns a context carrying the user's current location. A nil fix
+// leaves ctx unchanged.
+func WithFix(ctx context.Context, fix *Fix) context.Context {
+	if fix == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, fixKey{}, fix)
+}
+
+// FromContext returns the user's current location, or nil when it is unknown
+// or not shared
+func FromContext(ctx context.Context) *Fix {
+	fix, _ := ctx.Value(fixKey{}).(*Fix)
+	return fix
+}
@@ -0,0 +1,495 @@
+package location
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrNotSharing is returned for check-ins from a user who has not turned on
+// location sharing
+var ErrNotSharing = errors.New("location sharing is off")
+
+// PruneInterval is how often expired check-ins are deleted
+const PruneInterval = time.Hour
+
+// Options limits how much location history is kept
+type Options struct {
+	RetentionDays int // days to keep check-ins unless the user chooses otherwise
+	MaxPoints     int // check-ins kept per user; 0 for no limit
+}
+
+// CheckIn is the outcome of recording a fix
+type CheckIn struct {
+	Fix      *Fix     `json:"fix"`
+	Arrived  []string `json:"arrived,omitempty"`
+	Left     []string `json:"left,omitempty"`
+	Reminded int      `json:"reminded,omitempty"`
+}
+
+// Tracker stores check-ins for users who share their location and turns
+// them into arrivals, departures and reminders
+type Tracker struct {
+	db       *gorm.DB
+	opts     Options
+	notifier notify.Sender
+	logger   *zap.Logger
+	now      func() time.Time
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func init() {
+	store.RegisterMigrations("location", store.Migration{
+		Version: 1,
+		Name:    "create location tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Fix{}, &Settings{}, &Place{}, &Reminder{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Fix{}, &Settings{}, &Place{}, &Reminder{})
+		},
+	})
+}
+
+// NewTracker creates a tracker, migrating its tables
+func NewTracker(db *gorm.DB, opts Options, logger *zap.Logger) (*Tracker, error) {
+	if err := store.Migrate(db, "location"); err != nil {
+		return nil, fmt.Errorf("failed to migrate location schema: %w", err)
+	}
+	if opts.RetentionDays <= 0 {
+		opts.RetentionDays = 7
+	}
+	if opts.RetentionDays > MaxRetentionDays {
+		opts.RetentionDays = MaxRetentionDays
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Tracker{db: db, opts: opts, logger: logger, now: time.Now}, nil
+}
+
+// SetNotifier sets where arrival and departure reminders are sent. Without
+// one, reminders stay pending.
+func (t *Tracker) SetNotifier(n notify.Sender) {
+	t.notifier = n
+}
+
+// Settings returns a user's sharing settings; users who never chose get
+// sharing off
+func (t *Tracker) Settings(userID string) (*Settings, error) {
+	var s Settings
+	err := t.db.Where("user_id = ?", userID).First(&s).Error
+	if err == gorm.ErrRecordNotFound {
+		return &Settings{UserID: userID, RetentionDays: t.opts.RetentionDays}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.RetentionDays <= 0 {
+		s.RetentionDays = t.opts.RetentionDays
+	}
+	return &s, nil
+}
+
+// Sharing reports whether the user has turned on location sharing
+func (t *Tracker) Sharing(userID string) bool {
+	s, err := t.Settings(userID)
+	return err == nil && s.Enabled
+}
+
+// EnableSharing turns on location sharing for a user. retentionDays of 0
+// keeps the current setting.
+func (t *Tracker) EnableSharing(userID string, retentionDays int) (*Settings, error) {
+	if retentionDays < 0 || retentionDays > MaxRetentionDays {
+		return nil, fmt.Errorf("retention must be between 1 and %d days", MaxRetentionDays)
+	}
+	s, err := t.Settings(userID)
+	if err != nil {
+		return nil, err
+	}
+	s.Enabled = true
+	if retentionDays > 0 {
+		s.RetentionDays = retentionDays
+	}
+	s.UpdatedAt = t.now()
+	if err := t.db.Save(s).Error; err != nil {
+		return nil, err
+	}
+	return s, t.Prune(userID)
+}
+
+// DisableSharing turns off location sharing and deletes the user's
+// check-ins. Places and reminders are kept for when sharing is turned back
+// on. It returns how many check-ins were deleted.
+func (t *Tracker) DisableSharing(userID string) (int64, error) {
+	s, err := t.Settings(userID)
+	if err != nil {
+		return 0, err
+	}
+	s.Enabled = false
+	s.UpdatedAt = t.now()
+	if err := t.db.Save(s).Error; err != nil {
+		return 0, err
+	}
+	return t.Purge(userID, false)
+}
+
+// CheckIn records where the user is, moves them into and out of their
+// places and sends any reminders that fire
+func (t *Tracker) CheckIn(ctx context.Context, userID string, fix Fix) (*CheckIn, error) {
+	if !t.Sharing(userID) {
+		return nil, ErrNotSharing
+	}
+	if err := fix.Validate(); err != nil {
+		return nil, err
+	}
+	now := t.now()
+	if fix.RecordedAt.IsZero() || fix.RecordedAt.After(now) {
+		fix.RecordedAt = now
+	}
+	fix.ID = idgen.Generate(PrefixFix)
+	fix.UserID = userID
+
+	result := &CheckIn{Fix: &fix}
+
+	// Phones send check-ins out of order when they catch up after being
+	// offline; only the newest one says where the user is now
+	latest, err := t.latest(userID)
+	if err != nil {
+		return nil, err
+	}
+	current := latest == nil || !fix.RecordedAt.Before(latest.RecordedAt)
+
+	places, err := t.Places(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range places {
+		p := &places[i]
+		inside := p.Contains(fix.Latitude, fix.Longitude)
+		if inside && fix.Place == "" {
+			fix.Place = p.Name
+		}
+		if !current || fix.Accuracy > PlaceAccuracy || inside == p.Inside {
+			continue
+		}
+		p.Inside = inside
+		if err := t.db.Model(p).Update("inside", inside).Error; err != nil {
+			return nil, err
+		}
+		trigger := TriggerLeave
+		if inside {
+			trigger = TriggerArrive
+			result.Arrived = append(result.Arrived, p.Name)
+		} else {
+			result.Left = append(result.Left, p.Name)
+		}
+		result.Reminded += t.fire(ctx, userID, p.Name, trigger)
+	}
+
+	if err := t.db.Create(&fix).Error; err != nil {
+		return nil, err
+	}
+	if err := t.Prune(userID); err != nil {
+		t.logger.Warn("Failed to prune location history", zap.String("user_id", userID), zap.Error(err))
+	}
+	return result, nil
+}
+
+// fire sends the user's pending reminders for a place and trigger, and
+// returns how many were sent
+func (t *Tracker) fire(ctx context.Context, userID, place, trigger string) int {
+	if t.notifier == nil {
+		return 0
+	}
+	var reminders []Reminder
+	err := t.db.Where("user_id = ? AND LOWER(place) = ? AND trigger_on = ? AND fired_at IS NULL",
+		userID, strings.ToLower(place), trigger).Order("created_at ASC").Find(&reminders).Error
+	if err != nil {
+		t.logger.Warn("Failed to load location reminders", zap.Error(err))
+		return 0
+	}
+
+	sent := 0
+	for i := range reminders {
+		r := &reminders[i]
+		body := "You arrived at " + r.Place + "."
+		if trigger == TriggerLeave {
+			body = "You left " + r.Place + "."
+		}
+		_, err := t.notifier.Send(ctx, notify.Notification{
+			UserID:   userID,
+			Category: "location",
+			Title:    "📍 " + r.Message,
+			Body:     body,
+		})
+		if err != nil {
+			t.logger.Warn("Failed to send location reminder", zap.String("reminder_id", r.ID), zap.Error(err))
+			continue
+		}
+		now := t.now()
+		r.FiredAt = &now
+		if err := t.db.Save(r).Error; err != nil {
+			t.logger.Warn("Failed to record location reminder", zap.String("reminder_id", r.ID), zap.Error(err))
+		}
+		sent++
+	}
+	return sent
+}
+
+// Current returns where the user is, or nil when they don't share their
+// location or haven't checked in within MaxAge
+func (t *Tracker) Current(userID string) (*Fix, error) {
+	if !t.Sharing(userID) {
+		return nil, nil
+	}
+	fix, err := t.latest(userID)
+	if err != nil || fix == nil {
+		return nil, err
+	}
+	if t.now().Sub(fix.RecordedAt) > MaxAge {
+		return nil, nil
+	}
+
+	// Places saved since the check-in count too
+	places, err := t.Places(userID)
+	if err != nil {
+		return nil, err
+	}
+	fix.Place = ""
+	for i := range places {
+		if places[i].Contains(fix.Latitude, fix.Longitude) {
+			fix.Place = places[i].Name
+			break
+		}
+	}
+	return fix, nil
+}
+
+func (t *Tracker) latest(userID string) (*Fix, error) {
+	var fix Fix
+	err := t.db.Where("user_id = ?", userID).Order("recorded_at DESC").First(&fix).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &fix, err
+}
+
+// History returns the user's check-ins since a time, newest first
+func (t *Tracker) History(userID string, since time.Time) ([]Fix, error) {
+	var fixes []Fix
+	err := t.db.Where("user_id = ? AND recorded_at >= ?", userID, since).
+		Order("recorded_at DESC").Find(&fixes).Error
+	return fixes, err
+}
+
+// Purge deletes the user's check-ins and, with everything, their places and
+// reminders too. It returns how many records were deleted.
+func (t *Tracker) Purge(userID string, everything bool) (int64, error) {
+	res := t.db.Where("user_id = ?", userID).Delete(&Fix{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	deleted := res.RowsAffected
+	if !everything {
+		return deleted, nil
+	}
+	for _, model := range []interface{}{&Place{}, &Reminder{}} {
+		res := t.db.Where("user_id = ?", userID).Delete(model)
+		if res.Error != nil {
+			return deleted, res.Error
+		}
+		deleted += res.RowsAffected
+	}
+	return deleted, nil
+}
+
+// Prune deletes the user's check-ins that are past their retention or
+// beyond the per-user limit
+func (t *Tracker) Prune(userID string) error {
+	s, err := t.Settings(userID)
+	if err != nil {
+		return err
+	}
+	cutoff := t.now().AddDate(0, 0, -s.RetentionDays)
+	if err := t.db.Where("user_id = ? AND recorded_at < ?", userID, cutoff).Delete(&Fix{}).Error; err != nil {
+		return err
+	}
+	if t.opts.MaxPoints <= 0 {
+		return nil
+	}
+	keep := t.db.Model(&Fix{}).Select("id").Where("user_id = ?", userID).
+		Order("recorded_at DESC").Limit(t.opts.MaxPoints)
+	return t.db.Where("user_id = ? AND id NOT IN (?)", userID, keep).Delete(&Fix{}).Error
+}
+
+// PruneAll prunes every user's check-ins
+func (t *Tracker) PruneAll() error {
+	var users []string
+	if err := t.db.Model(&Fix{}).Distinct("user_id").Pluck("user_id", &users).Error; err != nil {
+		return err
+	}
+	for _, userID := range users {
+		if err := t.Prune(userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start prunes now and then every PruneInterval until Stop is called, so
+// history expires even when a phone stops checking in
+func (t *Tracker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(PruneInterval)
+		defer ticker.Stop()
+		for {
+			if err := t.PruneAll(); err != nil {
+				t.logger.Warn("Failed to prune location history", zap.Error(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the prune loop
+func (t *Tracker) Stop() {
+	if t.cancel != nil {
+		t.cancel()
+		t.wg.Wait()
+	}
+}
+
+// SetPlace saves a named place, replacing any place with the same name
+func (t *Tracker) SetPlace(userID, name string, lat, lon, radius float64) (*Place, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("place name is required")
+	}
+	if err := (&Fix{Latitude: lat, Longitude: lon}).Validate(); err != nil {
+		return nil, err
+	}
+	if radius <= 0 {
+		radius = DefaultRadius
+	}
+
+	place, err := t.FindPlace(userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if place == nil {
+		place = &Place{ID: idgen.Generate(PrefixPlace), UserID: userID, CreatedAt: t.now()}
+	}
+	place.Name = name
+	place.Latitude = lat
+	place.Longitude = lon
+	place.Radius = radius
+	if latest, err := t.latest(userID); err == nil && latest != nil {
+		place.Inside = place.Contains(latest.Latitude, latest.Longitude)
+	}
+	return place, t.db.Save(place).Error
+}
+
+// FindPlace returns the user's place with a name, ignoring case, or nil
+func (t *Tracker) FindPlace(userID, name string) (*Place, error) {
+	var place Place
+	err := t.db.Where("user_id = ? AND LOWER(name) = ?", userID, strings.ToLower(strings.TrimSpace(name))).
+		First(&place).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &place, err
+}
+
+// Places returns the user's places by name
+func (t *Tracker) Places(userID string) ([]Place, error) {
+	var places []Place
+	err := t.db.Where("user_id = ?", userID).Order("name ASC").Find(&places).Error
+	return places, err
+}
+
+// DeletePlace deletes a place and its pending reminders
+func (t *Tracker) DeletePlace(userID, name string) error {
+	place, err := t.FindPlace(userID, name)
+	if err != nil {
+		return err
+	}
+	if place == nil {
+		return fmt.Errorf("no place named %q", name)
+	}
+	if err := t.db.Where("user_id = ? AND LOWER(place) = ? AND fired_at IS NULL",
+		userID, strings.ToLower(place.Name)).Delete(&Reminder{}).Error; err != nil {
+		return err
+	}
+	return t.db.Delete(place).Error
+}
+
+// AddReminder adds a reminder for when the user arrives at or leaves one of
+// their places
+func (t *Tracker) AddReminder(userID, placeName, trigger, message string) (*Reminder, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+	if trigger == "" {
+		trigger = TriggerArrive
+	}
+	if trigger != TriggerArrive && trigger != TriggerLeave {
+		return nil, fmt.Errorf("trigger must be %s or %s", TriggerArrive, TriggerLeave)
+	}
+	place, err := t.FindPlace(userID, placeName)
+	if err != nil {
+		return nil, err
+	}
+	if place == nil {
+		return nil, fmt.Errorf("no place named %q: save it first", placeName)
+	}
+
+	r := &Reminder{
+		ID:        idgen.Generate(PrefixReminder),
+		UserID:    userID,
+		Place:     place.Name,
+		Trigger:   trigger,
+		Message:   message,
+		CreatedAt: t.now(),
+	}
+	return r, t.db.Create(r).Error
+}
+
+// Reminders returns the user's pending reminders, oldest first
+func (t *Tracker) Reminders(userID string) ([]Reminder, error) {
+	var reminders []Reminder
+	err := t.db.Where("user_id = ? AND fired_at IS NULL", userID).Order("created_at ASC").Find(&reminders).Error
+	return reminders, err
+}
+
+// CancelReminder deletes one of the user's pending reminders
+func (t *Tracker) CancelReminder(userID, id string) error {
+	res := t.db.Where("user_id = ? AND id = ? AND fired_at IS NULL", userID, id).Delete(&Reminder{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("no pending reminder %s", id)
+	}
+	return nil
+}
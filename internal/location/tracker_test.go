@@ -0,0 +1,193 @@
+package location
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Home and a point about 2 km away
+const (
+	homeLat, homeLon = 51.5007, -0.1246
+	awayLat, awayLon = 51.5145, -0.1000
+)
+
+type recordingNotifier struct {
+	sent []notify.Notification
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, notification notify.Notification) (string, error) {
+	n.sent = append(n.sent, notification)
+	return notify.StatusSent, nil
+}
+
+func setupTracker(t *testing.T, opts Options) (*Tracker, *recordingNotifier) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	tracker, err := NewTracker(db, opts, nil)
+	require.NoError(t, err)
+	notifier := &recordingNotifier{}
+	tracker.SetNotifier(notifier)
+	return tracker, notifier
+}
+
+func TestTracker_OptIn(t *testing.T) {
+	tracker, _ := setupTracker(t, Options{})
+	ctx := context.Background()
+
+	_, err := tracker.CheckIn(ctx, "alice", Fix{Latitude: homeLat, Longitude: homeLon})
+	assert.ErrorIs(t, err, ErrNotSharing)
+	history, err := tracker.History("alice", time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	settings, err := tracker.EnableSharing("alice", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 7, settings.RetentionDays)
+	_, err = tracker.CheckIn(ctx, "alice", Fix{Latitude: homeLat, Longitude: homeLon})
+	require.NoError(t, err)
+
+	current, err := tracker.Current("alice")
+	require.NoError(t, err)
+	require.NotNil(t, current)
+	assert.Equal(t, "51.5007,-0.1246", current.Coordinates())
+
+	// Turning sharing off forgets where the user has been
+	deleted, err := tracker.DisableSharing("alice")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	current, err = tracker.Current("alice")
+	require.NoError(t, err)
+	assert.Nil(t, current)
+
+	_, err = tracker.CheckIn(ctx, "alice", Fix{Latitude: 91, Longitude: 0})
+	assert.ErrorIs(t, err, ErrNotSharing)
+	_, err = tracker.EnableSharing("alice", 0)
+	require.NoError(t, err)
+	_, err = tracker.CheckIn(ctx, "alice", Fix{Latitude: 91, Longitude: 0})
+	assert.ErrorContains(t, err, "latitude")
+}
+
+func TestTracker_ArrivalReminders(t *testing.T) {
+	tracker, notifier := setupTracker(t, Options{})
+	ctx := context.Background()
+	_, err := tracker.EnableSharing("alice", 0)
+	require.NoError(t, err)
+
+	_, err = tracker.AddReminder("alice", "home", TriggerArrive, "Take the bins out")
+	assert.ErrorContains(t, err, "save it first")
+
+	_, err = tracker.SetPlace("alice", "Home", homeLat, homeLon, 0)
+	require.NoError(t, err)
+	arrive, err := tracker.AddReminder("alice", "home", "", "Take the bins out")
+	require.NoError(t, err)
+	assert.Equal(t, "when you get home", arrive.Describe())
+	_, err = tracker.AddReminder("alice", "home", TriggerLeave, "Lock the back door")
+	require.NoError(t, err)
+
+	// Out and about: nothing fires until the user gets home
+	result, err := tracker.CheckIn(ctx, "alice", Fix{Latitude: awayLat, Longitude: awayLon})
+	require.NoError(t, err)
+	assert.Empty(t, result.Arrived)
+	assert.Empty(t, notifier.sent)
+
+	// An imprecise fix near home doesn't count as arriving
+	result, err = tracker.CheckIn(ctx, "alice", Fix{Latitude: homeLat, Longitude: homeLon, Accuracy: 1500})
+	require.NoError(t, err)
+	assert.Empty(t, result.Arrived)
+
+	result, err = tracker.CheckIn(ctx, "alice", Fix{Latitude: homeLat + 0.0005, Longitude: homeLon, Accuracy: 20})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Home"}, result.Arrived)
+	assert.Equal(t, "Home", result.Fix.Place)
+	assert.Equal(t, 1, result.Reminded)
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, "location", notifier.sent[0].Category)
+	assert.Equal(t, "📍 Take the bins out", notifier.sent[0].Title)
+
+	// Staying home doesn't fire again
+	result, err = tracker.CheckIn(ctx, "alice", Fix{Latitude: homeLat, Longitude: homeLon})
+	require.NoError(t, err)
+	assert.Empty(t, result.Arrived)
+	assert.Len(t, notifier.sent, 1)
+
+	// A delayed check-in from earlier in the day doesn't move the user
+	result, err = tracker.CheckIn(ctx, "alice", Fix{
+		Latitude: awayLat, Longitude: awayLon, RecordedAt: time.Now().Add(-3 * time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Left)
+
+	result, err = tracker.CheckIn(ctx, "alice", Fix{Latitude: awayLat, Longitude: awayLon})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Home"}, result.Left)
+	require.Len(t, notifier.sent, 2)
+	assert.Equal(t, "📍 Lock the back door", notifier.sent[1].Title)
+
+	pending, err := tracker.Reminders("alice")
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestTracker_Retention(t *testing.T) {
+	tracker, _ := setupTracker(t, Options{RetentionDays: 2, MaxPoints: 3})
+	ctx := context.Background()
+	_, err := tracker.EnableSharing("alice", 0)
+	require.NoError(t, err)
+	_, err = tracker.EnableSharing("bob", 0)
+	require.NoError(t, err)
+
+	now := time.Now()
+	for _, age := range []time.Duration{72 * time.Hour, 5 * time.Hour, 4 * time.Hour, 3 * time.Hour, 2 * time.Hour} {
+		_, err := tracker.CheckIn(ctx, "alice", Fix{Latitude: homeLat, Longitude: homeLon, RecordedAt: now.Add(-age)})
+		require.NoError(t, err)
+	}
+	_, err = tracker.CheckIn(ctx, "bob", Fix{Latitude: homeLat, Longitude: homeLon})
+	require.NoError(t, err)
+
+	history, err := tracker.History("alice", time.Time{})
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.WithinDuration(t, now.Add(-2*time.Hour), history[0].RecordedAt, time.Second)
+	assert.WithinDuration(t, now.Add(-4*time.Hour), history[2].RecordedAt, time.Second)
+
+	// Check-ins older than MaxAge don't say where the user is
+	tracker.now = func() time.Time { return now.Add(MaxAge) }
+	current, err := tracker.Current("alice")
+	require.NoError(t, err)
+	assert.Nil(t, current)
+
+	// Retention still applies when the phone stops checking in
+	tracker.now = func() time.Time { return now.Add(48 * time.Hour) }
+	require.NoError(t, tracker.PruneAll())
+	history, err = tracker.History("alice", time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	// Purging everything removes places and reminders too
+	_, err = tracker.SetPlace("bob", "work", awayLat, awayLon, 200)
+	require.NoError(t, err)
+	_, err = tracker.AddReminder("bob", "work", TriggerArrive, "Book a desk")
+	require.NoError(t, err)
+	deleted, err := tracker.Purge("bob", true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	places, err := tracker.Places("bob")
+	require.NoError(t, err)
+	assert.Empty(t, places)
+
+	_, err = tracker.EnableSharing("bob", MaxRetentionDays+1)
+	assert.Error(t, err)
+}
+
+func TestDistance(t *testing.T) {
+	// London to Paris is about 344 km
+	assert.InDelta(t, 343500, Distance(51.5074, -0.1278, 48.8566, 2.3522), 1500)
+	assert.Zero(t, Distance(homeLat, homeLon, homeLat, homeLon))
+}
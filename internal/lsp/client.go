@@ -0,0 +1,471 @@
+// Package lsp is a small Language Server Protocol client, so code tools can
+// ask gopls, pyright and the like for definitions, references, symbols and
+// diagnostics instead of guessing with regular expressions
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// shutdownTimeout bounds how long a server gets to exit cleanly
+const shutdownTimeout = 3 * time.Second
+
+// ErrClosed is returned for calls on a server that has exited
+var ErrClosed = errors.New("language server exited")
+
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// openFile is a document the server has been told about
+type openFile struct {
+	version int
+	text    string
+}
+
+// Client talks to one language server process over stdio
+type Client struct {
+	root     string
+	language string
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	logger   *zap.Logger
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *message
+	opened  map[string]*openFile
+	diags   map[string][]Diagnostic
+	diagSeq map[string]int // bumped on each publishDiagnostics
+	changed chan struct{}  // closed and replaced when diagnostics arrive
+	done    chan struct{}
+	lastUse time.Time
+}
+
+// Start runs command in root and initializes it as a language server for
+// language
+func Start(ctx context.Context, root, language string, command []string, logger *zap.Logger) (*Client, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("no language server command for %s", language)
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = root
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", command[0], err)
+	}
+
+	c := &Client{
+		root:     root,
+		language: language,
+		cmd:      cmd,
+		stdin:    stdin,
+		logger:   logger,
+		pending:  make(map[int64]chan *message),
+		opened:   make(map[string]*openFile),
+		diags:    make(map[string][]Diagnostic),
+		diagSeq:  make(map[string]int),
+		changed:  make(chan struct{}),
+		done:     make(chan struct{}),
+		lastUse:  time.Now(),
+	}
+	go c.readLoop(bufio.NewReader(stdout))
+	go func() {
+		cmd.Wait()
+		c.mu.Lock()
+		select {
+		case <-c.done:
+		default:
+			close(c.done)
+		}
+		c.mu.Unlock()
+	}()
+
+	init := map[string]interface{}{
+		"processId": os.Getpid(),
+		"rootUri":   PathToURI(root),
+		"workspaceFolders": []map[string]string{
+			{"uri": PathToURI(root), "name": root},
+		},
+		"capabilities": map[string]interface{}{
+			"textDocument": map[string]interface{}{
+				"documentSymbol":     map[string]interface{}{"hierarchicalDocumentSymbolSupport": true},
+				"definition":         map[string]interface{}{"linkSupport": true},
+				"publishDiagnostics": map[string]interface{}{},
+				"synchronization":    map[string]interface{}{"didSave": false},
+			},
+			"workspace": map[string]interface{}{"workspaceFolders": true, "configuration": true},
+		},
+	}
+	if err := c.Call(ctx, "initialize", init, nil); err != nil {
+		c.kill()
+		return nil, fmt.Errorf("failed to initialize %s: %w", command[0], err)
+	}
+	if err := c.Notify("initialized", map[string]interface{}{}); err != nil {
+		c.kill()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Root is the folder the server works on
+func (c *Client) Root() string {
+	return c.root
+}
+
+// Alive reports whether the server process is still running
+func (c *Client) Alive() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// IdleSince returns when the client was last used
+func (c *Client) IdleSince() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastUse
+}
+
+// Call sends a request and decodes its result into result, unless nil
+func (c *Client) Call(ctx context.Context, method string, params, result interface{}) error {
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	ch := make(chan *message, 1)
+	c.pending[id] = ch
+	c.lastUse = time.Now()
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	rawID := json.RawMessage(strconv.FormatInt(id, 10))
+	if err := c.send(&message{ID: &rawID, Method: method}, params); err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return fmt.Errorf("%s: %s", method, resp.Error.Message)
+		}
+		if result != nil && len(resp.Result) > 0 {
+			return json.Unmarshal(resp.Result, result)
+		}
+		return nil
+	case <-c.done:
+		return ErrClosed
+	case <-ctx.Done():
+		c.Notify("$/cancelRequest", map[string]interface{}{"id": id})
+		return ctx.Err()
+	}
+}
+
+// Notify sends a notification
+func (c *Client) Notify(method string, params interface{}) error {
+	return c.send(&message{Method: method}, params)
+}
+
+func (c *Client) send(m *message, params interface{}) error {
+	m.JSONRPC = "2.0"
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		m.Params = data
+	}
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if !c.Alive() {
+		return ErrClosed
+	}
+	if _, err := fmt.Fprintf(c.stdin, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		return fmt.Errorf("failed to write to language server: %w", err)
+	}
+	return nil
+}
+
+// readLoop reads messages until the server's output ends
+func (c *Client) readLoop(r *bufio.Reader) {
+	for {
+		m, err := readMessage(r)
+		if err != nil {
+			if err != io.EOF {
+				c.logger.Debug("Language server output ended", zap.String("language", c.language), zap.Error(err))
+			}
+			return
+		}
+		switch {
+		case m.ID != nil && m.Method != "":
+			c.answer(m)
+		case m.ID != nil:
+			id, err := strconv.ParseInt(string(*m.ID), 10, 64)
+			if err != nil {
+				continue
+			}
+			c.mu.Lock()
+			ch := c.pending[id]
+			c.mu.Unlock()
+			if ch != nil {
+				ch <- m
+			}
+		case m.Method == "textDocument/publishDiagnostics":
+			var p struct {
+				URI         string       `json:"uri"`
+				Diagnostics []Diagnostic `json:"diagnostics"`
+			}
+			if json.Unmarshal(m.Params, &p) == nil {
+				c.mu.Lock()
+				c.diags[p.URI] = p.Diagnostics
+				c.diagSeq[p.URI]++
+				close(c.changed)
+				c.changed = make(chan struct{})
+				c.mu.Unlock()
+			}
+		}
+	}
+}
+
+// answer replies to a request from the server. Servers ask for settings
+// and to register capabilities; defaults are fine for both.
+func (c *Client) answer(m *message) {
+	var result interface{}
+	if m.Method == "workspace/configuration" {
+		var p struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		json.Unmarshal(m.Params, &p)
+		result = make([]interface{}, len(p.Items))
+	}
+	data, _ := json.Marshal(result)
+	c.send(&message{ID: m.ID, Result: data}, nil)
+}
+
+func readMessage(r *bufio.Reader) (*message, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %q", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("message without Content-Length")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	var m message
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	return &m, nil
+}
+
+// Open tells the server about the current content of path, re-sending it
+// when it changed since
+func (c *Client) Open(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	text := string(data)
+	uri := PathToURI(path)
+
+	c.mu.Lock()
+	f, ok := c.opened[uri]
+	if ok && f.text == text {
+		c.mu.Unlock()
+		return nil
+	}
+	if !ok {
+		f = &openFile{}
+		c.opened[uri] = f
+	}
+	f.version++
+	f.text = text
+	version := f.version
+	c.mu.Unlock()
+
+	if !ok {
+		return c.Notify("textDocument/didOpen", map[string]interface{}{
+			"textDocument": map[string]interface{}{
+				"uri":        uri,
+				"languageId": LanguageID(path),
+				"version":    version,
+				"text":       text,
+			},
+		})
+	}
+	return c.Notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": version},
+		"contentChanges": []map[string]string{{"text": text}},
+	})
+}
+
+func textPosition(path string, pos Position) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]string{"uri": PathToURI(path)},
+		"position":     pos,
+	}
+}
+
+// Definition returns where the symbol at pos in path is defined
+func (c *Client) Definition(ctx context.Context, path string, pos Position) ([]Location, error) {
+	if err := c.Open(path); err != nil {
+		return nil, err
+	}
+	var raw json.RawMessage
+	if err := c.Call(ctx, "textDocument/definition", textPosition(path, pos), &raw); err != nil {
+		return nil, err
+	}
+	return decodeLocations(raw)
+}
+
+// References returns where the symbol at pos in path is used
+func (c *Client) References(ctx context.Context, path string, pos Position, includeDeclaration bool) ([]Location, error) {
+	if err := c.Open(path); err != nil {
+		return nil, err
+	}
+	params := textPosition(path, pos)
+	params["context"] = map[string]bool{"includeDeclaration": includeDeclaration}
+	var raw json.RawMessage
+	if err := c.Call(ctx, "textDocument/references", params, &raw); err != nil {
+		return nil, err
+	}
+	return decodeLocations(raw)
+}
+
+// Symbols returns the declarations in path
+func (c *Client) Symbols(ctx context.Context, path string) ([]Symbol, error) {
+	if err := c.Open(path); err != nil {
+		return nil, err
+	}
+	var raw json.RawMessage
+	params := map[string]interface{}{"textDocument": map[string]string{"uri": PathToURI(path)}}
+	if err := c.Call(ctx, "textDocument/documentSymbol", params, &raw); err != nil {
+		return nil, err
+	}
+	return decodeSymbols(raw)
+}
+
+// Diagnostics returns the problems the server reports for path. Servers
+// push them after analysing a change, so it waits up to wait for a fresh
+// report.
+func (c *Client) Diagnostics(ctx context.Context, path string, wait time.Duration) ([]Diagnostic, error) {
+	uri := PathToURI(path)
+	c.mu.Lock()
+	seq := c.diagSeq[uri]
+	c.mu.Unlock()
+
+	if err := c.Open(path); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		c.mu.Lock()
+		if c.diagSeq[uri] != seq {
+			d := c.diags[uri]
+			c.mu.Unlock()
+			return d, nil
+		}
+		changed := c.changed
+		c.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			// Nothing new: the last report, if any, still stands
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return c.diags[uri], nil
+		case <-c.done:
+			return nil, ErrClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Close shuts the server down
+func (c *Client) Close() {
+	if !c.Alive() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := c.Call(ctx, "shutdown", nil, nil); err == nil {
+		c.Notify("exit", nil)
+	}
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		c.kill()
+	}
+}
+
+func (c *Client) kill() {
+	if c.cmd.Process != nil {
+		c.cmd.Process.Kill()
+	}
+}
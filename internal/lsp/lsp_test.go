@@ -0,0 +1,211 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The test binary doubles as a language server when this is set
+const fakeServerEnv = "LSP_FAKE_SERVER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeServerEnv) == "1" {
+		fakeServer()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeServer answers just enough of the protocol for the tests
+func fakeServer() {
+	r := bufio.NewReader(os.Stdin)
+	reply := func(id *json.RawMessage, result interface{}) {
+		data, _ := json.Marshal(result)
+		writeFake(message{ID: id, Result: data})
+	}
+	for {
+		m, err := readMessage(r)
+		if err != nil {
+			return
+		}
+		var p struct {
+			TextDocument struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"textDocument"`
+		}
+		json.Unmarshal(m.Params, &p)
+		uri := p.TextDocument.URI
+
+		switch m.Method {
+		case "initialize":
+			// Ask for settings first, as real servers do
+			writeFake(message{ID: rawID(99), Method: "workspace/configuration", Params: json.RawMessage(`{"items":[{}]}`)})
+			reply(m.ID, map[string]interface{}{"capabilities": map[string]interface{}{}})
+		case "textDocument/didOpen":
+			diags := []Diagnostic{}
+			if strings.Contains(p.TextDocument.Text, "undefinedThing") {
+				diags = append(diags, Diagnostic{
+					Range:    Range{Start: Position{Line: 3, Character: 1}},
+					Severity: 1,
+					Message:  "undefined: undefinedThing",
+				})
+			}
+			params, _ := json.Marshal(map[string]interface{}{"uri": uri, "diagnostics": diags})
+			writeFake(message{Method: "textDocument/publishDiagnostics", Params: params})
+		case "textDocument/documentSymbol":
+			reply(m.ID, []documentSymbol{{
+				Name: "Server", Kind: 23,
+				Range:          Range{Start: Position{Line: 2}, End: Position{Line: 6}},
+				SelectionRange: Range{Start: Position{Line: 2, Character: 5}},
+				Children: []documentSymbol{{
+					Name: "addr", Kind: 8,
+					Range:          Range{Start: Position{Line: 3}, End: Position{Line: 3}},
+					SelectionRange: Range{Start: Position{Line: 3, Character: 1}},
+				}},
+			}})
+		case "textDocument/definition":
+			reply(m.ID, []locationLink{{TargetURI: uri, TargetSelectionRange: Range{Start: Position{Line: 2, Character: 5}}}})
+		case "textDocument/references":
+			reply(m.ID, []Location{
+				{URI: uri, Range: Range{Start: Position{Line: 2, Character: 5}}},
+				{URI: uri, Range: Range{Start: Position{Line: 8, Character: 9}}},
+			})
+		case "shutdown":
+			reply(m.ID, nil)
+		case "exit":
+			return
+		default:
+			if m.ID != nil && m.Method != "" {
+				reply(m.ID, nil)
+			}
+		}
+	}
+}
+
+func rawID(id int) *json.RawMessage {
+	raw := json.RawMessage(fmt.Sprint(id))
+	return &raw
+}
+
+func writeFake(m message) {
+	m.JSONRPC = "2.0"
+	body, _ := json.Marshal(m)
+	fmt.Fprintf(os.Stdout, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func startFake(t *testing.T, root string) *Client {
+	t.Helper()
+	t.Setenv(fakeServerEnv, "1")
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip("no test executable")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c, err := Start(ctx, root, "go", []string{exe}, nil)
+	if err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestClient(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	os.WriteFile(path, []byte("package main\n\ntype Server struct {\n\taddr string\n}\n"), 0644)
+
+	c := startFake(t, root)
+	ctx := context.Background()
+
+	symbols, err := c.Symbols(ctx, path)
+	if err != nil {
+		t.Fatalf("Failed to get symbols: %v", err)
+	}
+	if len(symbols) != 2 || symbols[0].Kind != "struct" || symbols[0].Line != 3 ||
+		symbols[1].Name != "addr" || symbols[1].Container != "Server" {
+		t.Errorf("Unexpected symbols: %+v", symbols)
+	}
+
+	defs, err := c.Definition(ctx, path, Position{Line: 8, Character: 9})
+	if err != nil || len(defs) != 1 || URIToPath(defs[0].URI) != path || defs[0].Range.Start.Line != 2 {
+		t.Errorf("Unexpected definition %+v, %v", defs, err)
+	}
+
+	refs, err := c.References(ctx, path, Position{Line: 2, Character: 5}, true)
+	if err != nil || len(refs) != 2 {
+		t.Errorf("Unexpected references %+v, %v", refs, err)
+	}
+
+	diags, err := c.Diagnostics(ctx, path, time.Second)
+	if err != nil || len(diags) != 0 {
+		t.Errorf("Expected no diagnostics, got %+v, %v", diags, err)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	os.WriteFile(path, []byte("package main\n\nfunc main() {\n\tundefinedThing()\n}\n"), 0644)
+
+	c := startFake(t, root)
+	diags, err := c.Diagnostics(context.Background(), path, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to get diagnostics: %v", err)
+	}
+	if len(diags) != 1 || SeverityName(diags[0].Severity) != "error" || diags[0].Range.Start.Line != 3 {
+		t.Errorf("Unexpected diagnostics: %+v", diags)
+	}
+}
+
+func TestDecodeLocations(t *testing.T) {
+	for raw, want := range map[string]int{
+		`null`: 0,
+		`{"uri":"file:///a.go","range":{"start":{"line":1,"character":0},"end":{"line":1,"character":3}}}`: 1,
+		`[{"uri":"file:///a.go","range":{}},{"uri":"file:///b.go","range":{}}]`:                            2,
+	} {
+		locs, err := decodeLocations(json.RawMessage(raw))
+		if err != nil || len(locs) != want {
+			t.Errorf("%s: got %d locations, %v", raw, len(locs), err)
+		}
+	}
+
+	symbols, err := decodeSymbols(json.RawMessage(`[{"name":"main","kind":12,"location":{"uri":"file:///a.go","range":{"start":{"line":4},"end":{"line":9}}},"containerName":"pkg"}]`))
+	if err != nil || len(symbols) != 1 || symbols[0].Kind != "function" || symbols[0].Line != 5 || symbols[0].Container != "pkg" {
+		t.Errorf("Unexpected flat symbols %+v, %v", symbols, err)
+	}
+}
+
+func TestProjectRoot(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "go.mod"), []byte("module x\n"), 0644)
+	dir := filepath.Join(root, "internal", "pkg")
+	os.MkdirAll(dir, 0755)
+
+	if got := ProjectRoot(filepath.Join(dir, "a.go")); got != root {
+		t.Errorf("Expected %s, got %s", root, got)
+	}
+	if LanguageOf("x/app.TSX") != "typescript" || LanguageID("app.tsx") != "typescriptreact" || LanguageOf("README") != "" {
+		t.Error("Unexpected language detection")
+	}
+	if col := UTF16Column("héllo", 3); col != 2 {
+		t.Errorf("Expected column 2, got %d", col)
+	}
+
+	m := NewManager(map[string]string{"go": "off"}, nil)
+	defer m.Close()
+	if m.Available("main.go") {
+		t.Error("Expected a disabled server to be unavailable")
+	}
+	if _, err := m.ClientFor(context.Background(), "notes.txt"); err == nil {
+		t.Error("Expected no server for text files")
+	}
+}
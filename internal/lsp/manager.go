@@ -0,0 +1,215 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// idleTimeout is how long an unused server keeps running
+const idleTimeout = 10 * time.Minute
+
+// DefaultServers are the servers used for each language unless configured
+// otherwise
+var DefaultServers = map[string]string{
+	"go":         "gopls",
+	"python":     "pyright-langserver --stdio",
+	"javascript": "typescript-language-server --stdio",
+	"typescript": "typescript-language-server --stdio",
+	"rust":       "rust-analyzer",
+	"c":          "clangd",
+	"cpp":        "clangd",
+}
+
+var extensions = map[string]string{
+	".go":  "go",
+	".py":  "python",
+	".pyi": "python",
+	".js":  "javascript",
+	".jsx": "javascript",
+	".mjs": "javascript",
+	".cjs": "javascript",
+	".ts":  "typescript",
+	".tsx": "typescript",
+	".rs":  "rust",
+	".c":   "c",
+	".h":   "c",
+	".cc":  "cpp",
+	".cpp": "cpp",
+	".cxx": "cpp",
+	".hpp": "cpp",
+}
+
+// rootMarkers are files that mark the top of a project, nearest first
+var rootMarkers = []string{
+	"go.mod", "package.json", "tsconfig.json", "Cargo.toml",
+	"pyproject.toml", "setup.py", "compile_commands.json", ".git",
+}
+
+// LanguageOf returns the language of a file by its extension, or ""
+func LanguageOf(path string) string {
+	return extensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// LanguageID is the LSP language identifier of a file
+func LanguageID(path string) string {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".jsx":
+		return "javascriptreact"
+	case ".tsx":
+		return "typescriptreact"
+	}
+	return LanguageOf(path)
+}
+
+// ProjectRoot returns the nearest folder above path holding a project
+// marker, or the folder of path when there is none
+func ProjectRoot(path string) string {
+	dir := path
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	for d := dir; ; {
+		for _, marker := range rootMarkers {
+			if _, err := os.Stat(filepath.Join(d, marker)); err == nil {
+				return d
+			}
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+// Manager starts language servers on demand, one per language and project,
+// and stops them when idle
+type Manager struct {
+	servers map[string][]string
+	logger  *zap.Logger
+
+	mu      sync.Mutex
+	clients map[string]*Client // by language and root
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewManager creates a manager. overrides replaces the default server of a
+// language; "off" disables it.
+func NewManager(overrides map[string]string, logger *zap.Logger) *Manager {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	servers := make(map[string][]string)
+	for lang, command := range DefaultServers {
+		servers[lang] = strings.Fields(command)
+	}
+	for lang, command := range overrides {
+		lang = strings.ToLower(lang)
+		if command == "off" || command == "" {
+			delete(servers, lang)
+			continue
+		}
+		servers[lang] = strings.Fields(command)
+	}
+	m := &Manager{
+		servers: servers,
+		logger:  logger,
+		clients: make(map[string]*Client),
+		stop:    make(chan struct{}),
+	}
+	go m.reap()
+	return m
+}
+
+// Available reports whether a server for the language of path is
+// configured and installed
+func (m *Manager) Available(path string) bool {
+	command := m.servers[LanguageOf(path)]
+	if len(command) == 0 {
+		return false
+	}
+	_, err := exec.LookPath(command[0])
+	return err == nil
+}
+
+// ClientFor returns a running server for the file at path, starting one if
+// needed
+func (m *Manager) ClientFor(ctx context.Context, path string) (*Client, error) {
+	lang := LanguageOf(path)
+	if lang == "" {
+		return nil, fmt.Errorf("no language server for %s files", filepath.Ext(path))
+	}
+	command := m.servers[lang]
+	if len(command) == 0 {
+		return nil, fmt.Errorf("language server for %s is disabled", lang)
+	}
+	if _, err := exec.LookPath(command[0]); err != nil {
+		return nil, fmt.Errorf("%s is not installed; install it or set tools.coding.language_servers.%s", command[0], lang)
+	}
+
+	root := ProjectRoot(path)
+	key := lang + "\x00" + root
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c := m.clients[key]; c != nil && c.Alive() {
+		return c, nil
+	}
+	c, err := Start(ctx, root, lang, command, m.logger)
+	if err != nil {
+		return nil, err
+	}
+	m.logger.Info("Started language server",
+		zap.String("language", lang),
+		zap.String("command", command[0]),
+		zap.String("root", root),
+	)
+	m.clients[key] = c
+	return c, nil
+}
+
+// reap stops servers that have not been used for a while
+func (m *Manager) reap() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+		}
+		m.mu.Lock()
+		var idle []*Client
+		for key, c := range m.clients {
+			if !c.Alive() || time.Since(c.IdleSince()) > idleTimeout {
+				idle = append(idle, c)
+				delete(m.clients, key)
+			}
+		}
+		m.mu.Unlock()
+		for _, c := range idle {
+			c.Close()
+		}
+	}
+}
+
+// Close stops all servers
+func (m *Manager) Close() {
+	m.once.Do(func() { close(m.stop) })
+	m.mu.Lock()
+	clients := m.clients
+	m.clients = make(map[string]*Client)
+	m.mu.Unlock()
+	for _, c := range clients {
+		c.Close()
+	}
+}
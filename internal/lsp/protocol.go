@@ -0,0 +1,217 @@
+package lsp
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+)
+
+// Position is a zero-based line and UTF-16 character offset, as in LSP
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a span of a document
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range in a file
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// locationLink is what servers that support links return for definitions
+type locationLink struct {
+	TargetURI            string `json:"targetUri"`
+	TargetSelectionRange Range  `json:"targetSelectionRange"`
+}
+
+// Diagnostic is an error, warning or hint a server reports for a file
+type Diagnostic struct {
+	Range    Range       `json:"range"`
+	Severity int         `json:"severity"`
+	Code     interface{} `json:"code,omitempty"`
+	Source   string      `json:"source,omitempty"`
+	Message  string      `json:"message"`
+}
+
+// SeverityName names a diagnostic severity
+func SeverityName(severity int) string {
+	switch severity {
+	case 1:
+		return "error"
+	case 2:
+		return "warning"
+	case 3:
+		return "info"
+	case 4:
+		return "hint"
+	}
+	return "unknown"
+}
+
+// documentSymbol is a symbol in the hierarchical form
+type documentSymbol struct {
+	Name           string           `json:"name"`
+	Detail         string           `json:"detail"`
+	Kind           int              `json:"kind"`
+	Range          Range            `json:"range"`
+	SelectionRange Range            `json:"selectionRange"`
+	Children       []documentSymbol `json:"children"`
+}
+
+// symbolInformation is a symbol in the flat form
+type symbolInformation struct {
+	Name          string   `json:"name"`
+	Kind          int      `json:"kind"`
+	Location      Location `json:"location"`
+	ContainerName string   `json:"containerName"`
+}
+
+// Symbol is a declaration in a file. Lines are one-based.
+type Symbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Detail    string `json:"detail,omitempty"`
+	Container string `json:"container,omitempty"`
+	Line      int    `json:"line"`
+	EndLine   int    `json:"end_line"`
+}
+
+var symbolKinds = []string{
+	"", "file", "module", "namespace", "package", "class", "method", "property",
+	"field", "constructor", "enum", "interface", "function", "variable",
+	"constant", "string", "number", "boolean", "array", "object", "key", "null",
+	"enum_member", "struct", "event", "operator", "type_parameter",
+}
+
+// SymbolKindName names an LSP symbol kind
+func SymbolKindName(kind int) string {
+	if kind > 0 && kind < len(symbolKinds) {
+		return symbolKinds[kind]
+	}
+	return "symbol"
+}
+
+// decodeSymbols reads a documentSymbol response in either form, flattening
+// nested symbols
+func decodeSymbols(raw json.RawMessage) ([]Symbol, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil || len(items) == 0 {
+		return nil, err
+	}
+	var probe struct {
+		Location *Location `json:"location"`
+	}
+	json.Unmarshal(items[0], &probe)
+
+	var out []Symbol
+	if probe.Location != nil {
+		var infos []symbolInformation
+		if err := json.Unmarshal(raw, &infos); err != nil {
+			return nil, err
+		}
+		for _, si := range infos {
+			out = append(out, Symbol{
+				Name:      si.Name,
+				Kind:      SymbolKindName(si.Kind),
+				Container: si.ContainerName,
+				Line:      si.Location.Range.Start.Line + 1,
+				EndLine:   si.Location.Range.End.Line + 1,
+			})
+		}
+		return out, nil
+	}
+
+	var docs []documentSymbol
+	if err := json.Unmarshal(raw, &docs); err != nil {
+		return nil, err
+	}
+	var walk func(symbols []documentSymbol, container string)
+	walk = func(symbols []documentSymbol, container string) {
+		for _, ds := range symbols {
+			out = append(out, Symbol{
+				Name:      ds.Name,
+				Kind:      SymbolKindName(ds.Kind),
+				Detail:    ds.Detail,
+				Container: container,
+				Line:      ds.SelectionRange.Start.Line + 1,
+				EndLine:   ds.Range.End.Line + 1,
+			})
+			walk(ds.Children, ds.Name)
+		}
+	}
+	walk(docs, "")
+	return out, nil
+}
+
+// decodeLocations reads a definition or references response: null, a
+// Location, a list of them or a list of LocationLinks
+func decodeLocations(raw json.RawMessage) ([]Location, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	if raw[0] == '{' {
+		var loc Location
+		if err := json.Unmarshal(raw, &loc); err != nil {
+			return nil, err
+		}
+		return []Location{loc}, nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	out := make([]Location, 0, len(items))
+	for _, item := range items {
+		var link locationLink
+		if json.Unmarshal(item, &link) == nil && link.TargetURI != "" {
+			out = append(out, Location{URI: link.TargetURI, Range: link.TargetSelectionRange})
+			continue
+		}
+		var loc Location
+		if err := json.Unmarshal(item, &loc); err != nil {
+			return nil, err
+		}
+		out = append(out, loc)
+	}
+	return out, nil
+}
+
+// PathToURI turns an absolute path into a file URI
+func PathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if runtime.GOOS == "windows" {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// URIToPath turns a file URI into a path
+func URIToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	path := u.Path
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.FromSlash(path)
+}
+
+// UTF16Column converts a byte offset in a line to LSP's UTF-16 character
+// offset
+func UTF16Column(line string, byteOffset int) int {
+	if byteOffset > len(line) {
+		byteOffset = len(line)
+	}
+	return len(utf16.Encode([]rune(line[:byteOffset])))
+}
@@ -80,6 +80,13 @@ var (
 		Name: "myrai_skill_executions_total",
 		Help: "Total skill executions",
 	}, []string{"skill_name", "status"})
+
+	// SkillCacheRequests tracks skill cache lookups by result: hit, miss or
+	// bypass
+	SkillCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "myrai_skill_cache_requests_total",
+		Help: "Total skill cache lookups",
+	}, []string{"namespace", "result"})
 )
 
 // Metrics holds all application metrics
@@ -0,0 +1,281 @@
+// Package mqtt bridges an MQTT broker and the event bus. Messages on the
+// configured topics (a door sensor, a 3D printer, CI results) are published as
+// mqtt.<event> events so skills and triggers can react, and the assistant can
+// publish to an allow-list of topics to control devices.
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"go.uber.org/zap"
+)
+
+// EventPrefix starts the type of every event the bridge publishes
+const EventPrefix = "mqtt."
+
+// MaxPayload is the most of a message payload kept in events and shown to
+// the assistant
+const MaxPayload = 4096
+
+// MessageHandler receives a message on a subscribed topic
+type MessageHandler func(topic string, payload []byte)
+
+// Conn is a connection to a broker
+type Conn interface {
+	Subscribe(topic string, qos byte, handler MessageHandler) error
+	Publish(topic string, qos byte, retained bool, payload []byte) error
+	Close()
+}
+
+// Dialer connects to the broker. onConnect is called on every successful
+// connection, including reconnects, so subscriptions can be renewed.
+type Dialer func(cfg config.MQTTConfig, onConnect func(Conn)) (Conn, error)
+
+// Message is the last message seen on a topic
+type Message struct {
+	Topic      string    `json:"topic"`
+	Event      string    `json:"event"`
+	Payload    string    `json:"payload"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// Bridge subscribes to the configured topics and publishes what arrives on
+// the event bus
+type Bridge struct {
+	cfg    config.MQTTConfig
+	bus    *events.Bus
+	dial   Dialer
+	logger *zap.Logger
+
+	mu     sync.RWMutex
+	conn   Conn
+	latest map[string]Message
+}
+
+// NewBridge creates a bridge for cfg that publishes to bus. It connects when
+// started.
+func NewBridge(cfg config.MQTTConfig, bus *events.Bus, logger *zap.Logger) (*Bridge, error) {
+	if cfg.Broker == "" {
+		return nil, fmt.Errorf("mqtt broker is required")
+	}
+	for i, sub := range cfg.Subscriptions {
+		if err := ValidateFilter(sub.Topic); err != nil {
+			return nil, fmt.Errorf("subscription %d: %w", i+1, err)
+		}
+		if sub.QoS < 0 || sub.QoS > 1 {
+			return nil, fmt.Errorf("subscription %d: qos must be 0 or 1", i+1)
+		}
+	}
+	for _, filter := range cfg.PublishTopics {
+		if err := ValidateFilter(filter); err != nil {
+			return nil, fmt.Errorf("publish topic: %w", err)
+		}
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Bridge{
+		cfg:    cfg,
+		bus:    bus,
+		dial:   dialPaho,
+		logger: logger,
+		latest: make(map[string]Message),
+	}, nil
+}
+
+// Start connects to the broker. The connection retries in the background,
+// so a broker that is down does not stop the server from starting.
+func (b *Bridge) Start() error {
+	conn, err := b.dial(b.cfg, b.subscribe)
+	if err != nil {
+		return fmt.Errorf("failed to connect to mqtt broker: %w", err)
+	}
+	b.mu.Lock()
+	b.conn = conn
+	b.mu.Unlock()
+	return nil
+}
+
+// Stop disconnects from the broker
+func (b *Bridge) Stop() {
+	b.mu.Lock()
+	conn := b.conn
+	b.conn = nil
+	b.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+func (b *Bridge) subscribe(conn Conn) {
+	for _, sub := range b.cfg.Subscriptions {
+		if err := conn.Subscribe(sub.Topic, byte(sub.QoS), b.handler(sub)); err != nil {
+			b.logger.Warn("Failed to subscribe to mqtt topic", zap.String("topic", sub.Topic), zap.Error(err))
+			continue
+		}
+		b.logger.Info("Subscribed to mqtt topic", zap.String("topic", sub.Topic))
+	}
+}
+
+// handler publishes the messages of one subscription as events
+func (b *Bridge) handler(sub config.MQTTSubscription) MessageHandler {
+	eventType := EventType(sub)
+	userID := sub.User
+	if userID == "" {
+		userID = household.SharedUserID
+	}
+
+	return func(topic string, payload []byte) {
+		text := payloadText(payload)
+		now := time.Now()
+		b.mu.Lock()
+		b.latest[topic] = Message{Topic: topic, Event: eventType, Payload: text, ReceivedAt: now}
+		b.mu.Unlock()
+
+		data := map[string]interface{}{
+			"topic":   topic,
+			"payload": text,
+		}
+		var value interface{}
+		if json.Unmarshal(payload, &value) == nil {
+			data["value"] = value
+		}
+		b.bus.Publish(context.Background(), events.Event{
+			Type:   eventType,
+			UserID: userID,
+			Source: "mqtt",
+			Data:   data,
+			Time:   now,
+		})
+	}
+}
+
+// Publish sends a message to a topic the configuration allows publishing to
+func (b *Bridge) Publish(topic string, payload []byte, qos byte, retained bool) error {
+	if strings.ContainsAny(topic, "+#") || topic == "" {
+		return fmt.Errorf("cannot publish to %q: give a single topic without wildcards", topic)
+	}
+	if !b.CanPublish(topic) {
+		return fmt.Errorf("publishing to %q is not allowed: add it to mqtt.publish_topics", topic)
+	}
+	if qos > 1 {
+		return fmt.Errorf("qos must be 0 or 1")
+	}
+
+	b.mu.RLock()
+	conn := b.conn
+	b.mu.RUnlock()
+	if conn == nil {
+		return fmt.Errorf("not connected to the mqtt broker")
+	}
+	return conn.Publish(topic, qos, retained, payload)
+}
+
+// CanPublish reports whether topic matches one of the publish topic filters
+func (b *Bridge) CanPublish(topic string) bool {
+	for _, filter := range b.cfg.PublishTopics {
+		if TopicMatches(filter, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// PublishTopics returns the topic filters the bridge may publish to
+func (b *Bridge) PublishTopics() []string {
+	return append([]string(nil), b.cfg.PublishTopics...)
+}
+
+// Latest returns the last message on each topic matching filter, or on every
+// topic when filter is empty, by topic
+func (b *Bridge) Latest(filter string) []Message {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var messages []Message
+	for topic, m := range b.latest {
+		if filter == "" || TopicMatches(filter, topic) {
+			messages = append(messages, m)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].Topic < messages[j].Topic })
+	return messages
+}
+
+var eventNameCleaner = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// EventType returns the type of the events a subscription publishes:
+// mqtt.<event>, or a name derived from the topic when no event is set
+func EventType(sub config.MQTTSubscription) string {
+	name := sub.Event
+	if name == "" {
+		name = strings.Trim(strings.NewReplacer("/", "_", "+", "", "#", "").Replace(sub.Topic), "_")
+	}
+	name = strings.Trim(eventNameCleaner.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		name = "message"
+	}
+	return EventPrefix + name
+}
+
+// ValidateFilter checks an MQTT topic filter: + must fill a whole level and
+// # must be the last level
+func ValidateFilter(filter string) error {
+	if filter == "" {
+		return fmt.Errorf("topic is empty")
+	}
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		if strings.Contains(level, "+") && level != "+" {
+			return fmt.Errorf("invalid topic %q: + must be a whole level", filter)
+		}
+		if strings.Contains(level, "#") && (level != "#" || i != len(levels)-1) {
+			return fmt.Errorf("invalid topic %q: # must be the last level", filter)
+		}
+	}
+	return nil
+}
+
+// TopicMatches reports whether topic matches an MQTT topic filter
+func TopicMatches(filter, topic string) bool {
+	f := strings.Split(filter, "/")
+	t := strings.Split(topic, "/")
+	for i, level := range f {
+		if level == "#" {
+			return true
+		}
+		if i >= len(t) {
+			return false
+		}
+		if level != "+" && level != t[i] {
+			return false
+		}
+	}
+	return len(f) == len(t)
+}
+
+// payloadText returns the payload as text, truncated to MaxPayload
+func payloadText(payload []byte) string {
+	if !utf8.Valid(payload) {
+		return fmt.Sprintf("(%d bytes of binary data)", len(payload))
+	}
+	text := string(payload)
+	if len(text) > MaxPayload {
+		cut := MaxPayload
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + "…"
+	}
+	return text
+}
@@ -0,0 +1,234 @@
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type published struct {
+	topic    string
+	payload  string
+	qos      byte
+	retained bool
+}
+
+// fakeConn is a broker connection that delivers messages on demand
+type fakeConn struct {
+	mu        sync.Mutex
+	handlers  map[string]MessageHandler
+	published []published
+	closed    bool
+}
+
+func (c *fakeConn) Subscribe(topic string, qos byte, handler MessageHandler) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[topic] = handler
+	return nil
+}
+
+func (c *fakeConn) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.published = append(c.published, published{topic, string(payload), qos, retained})
+	return nil
+}
+
+func (c *fakeConn) Close() {
+	c.closed = true
+}
+
+// deliver sends a message to the subscriptions whose filter matches topic
+func (c *fakeConn) deliver(topic, payload string) {
+	c.mu.Lock()
+	var handlers []MessageHandler
+	for filter, h := range c.handlers {
+		if TopicMatches(filter, topic) {
+			handlers = append(handlers, h)
+		}
+	}
+	c.mu.Unlock()
+	for _, h := range handlers {
+		h(topic, []byte(payload))
+	}
+}
+
+func startBridge(t *testing.T, cfg config.MQTTConfig, bus *events.Bus) (*Bridge, *fakeConn) {
+	cfg.Broker = "tcp://broker:1883"
+	bridge, err := NewBridge(cfg, bus, nil)
+	require.NoError(t, err)
+	conn := &fakeConn{handlers: make(map[string]MessageHandler)}
+	bridge.dial = func(cfg config.MQTTConfig, onConnect func(Conn)) (Conn, error) {
+		onConnect(conn)
+		return conn, nil
+	}
+	require.NoError(t, bridge.Start())
+	return bridge, conn
+}
+
+func TestBridge_PublishesEvents(t *testing.T) {
+	bus := events.NewBus(nil)
+	var got []events.Event
+	bus.Subscribe("mqtt.*", func(ctx context.Context, e events.Event) {
+		got = append(got, e)
+	})
+
+	bridge, conn := startBridge(t, config.MQTTConfig{
+		Subscriptions: []config.MQTTSubscription{
+			{Topic: "octoprint/event/PrintDone", Event: "print_complete"},
+			{Topic: "home/door/+"},
+		},
+	}, bus)
+
+	conn.deliver("octoprint/event/PrintDone", `{"name":"benchy.gcode","filament_m":1.2}`)
+	conn.deliver("home/door/front", "open")
+	conn.deliver("home/window/back", "open")
+
+	require.Len(t, got, 2)
+	assert.Equal(t, "mqtt.print_complete", got[0].Type)
+	assert.Equal(t, "mqtt", got[0].Source)
+	assert.Equal(t, "default_user", got[0].UserID)
+	assert.Equal(t, map[string]interface{}{"name": "benchy.gcode", "filament_m": 1.2}, got[0].Data["value"])
+	assert.Equal(t, "mqtt.home_door", got[1].Type)
+	assert.Equal(t, "home/door/front", got[1].Data["topic"])
+	assert.Equal(t, "open", got[1].Data["payload"])
+
+	latest := bridge.Latest("home/#")
+	require.Len(t, latest, 1)
+	assert.Equal(t, "open", latest[0].Payload)
+	assert.Len(t, bridge.Latest(""), 2)
+
+	bridge.Stop()
+	assert.True(t, conn.closed)
+}
+
+func TestBridge_Publish(t *testing.T) {
+	bridge, conn := startBridge(t, config.MQTTConfig{
+		PublishTopics: []string{"home/lights/+/set"},
+	}, events.NewBus(nil))
+
+	require.NoError(t, bridge.Publish("home/lights/porch/set", []byte("ON"), 1, true))
+	require.Len(t, conn.published, 1)
+	assert.Equal(t, published{"home/lights/porch/set", "ON", 1, true}, conn.published[0])
+
+	assert.ErrorContains(t, bridge.Publish("home/lock/front/set", []byte("UNLOCK"), 0, false), "not allowed")
+	assert.ErrorContains(t, bridge.Publish("home/lights/#", []byte("ON"), 0, false), "wildcards")
+
+	bridge.Stop()
+	assert.ErrorContains(t, bridge.Publish("home/lights/porch/set", []byte("OFF"), 0, false), "not connected")
+}
+
+func TestNewBridge_Validates(t *testing.T) {
+	_, err := NewBridge(config.MQTTConfig{}, nil, nil)
+	assert.ErrorContains(t, err, "broker")
+	_, err = NewBridge(config.MQTTConfig{Broker: "tcp://b:1883", Subscriptions: []config.MQTTSubscription{{Topic: "a/#/b"}}}, nil, nil)
+	assert.ErrorContains(t, err, "last level")
+	_, err = NewBridge(config.MQTTConfig{Broker: "tcp://b:1883", PublishTopics: []string{"a/b+"}}, nil, nil)
+	assert.ErrorContains(t, err, "whole level")
+}
+
+func TestTopicMatches(t *testing.T) {
+	tests := []struct {
+		filter, topic string
+		want          bool
+	}{
+		{"a/b", "a/b", true},
+		{"a/b", "a/c", false},
+		{"a/+", "a/b", true},
+		{"a/+", "a/b/c", false},
+		{"a/+/c", "a/b/c", true},
+		{"a/#", "a", true},
+		{"a/#", "a/b/c", true},
+		{"#", "x/y", true},
+		{"a/b/c", "a/b", false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, TopicMatches(test.filter, test.topic), "%s vs %s", test.filter, test.topic)
+	}
+}
+
+type fakeAgent struct {
+	mu      sync.Mutex
+	prompts []string
+	err     error
+}
+
+func (a *fakeAgent) Chat(ctx context.Context, req agent.ChatRequest) (*agent.ChatResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.prompts = append(a.prompts, req.Message)
+	if a.err != nil {
+		return nil, a.err
+	}
+	return &agent.ChatResponse{Content: "Print finished; logged 1.2 m of filament"}, nil
+}
+
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []notify.Notification
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, notification notify.Notification) (string, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, notification)
+	return notify.StatusSent, nil
+}
+
+func TestTriggers_RunPrompt(t *testing.T) {
+	subs := []config.MQTTSubscription{
+		{Topic: "octoprint/event/PrintDone", Event: "print_complete", Prompt: "Tell me the print is done and log the filament used: {{payload}}", Cooldown: 60},
+		{Topic: "home/door/+", Event: "door"},
+	}
+	bus := events.NewBus(nil)
+	_, conn := startBridge(t, config.MQTTConfig{Subscriptions: subs}, bus)
+
+	a := &fakeAgent{}
+	notifier := &recordingNotifier{}
+	triggers := NewTriggers(subs, a, nil)
+	triggers.SetNotifier(notifier)
+	stop := triggers.Subscribe(bus)
+	defer stop()
+
+	conn.deliver("octoprint/event/PrintDone", `{"filament_m":1.2}`)
+	conn.deliver("home/door/front", "open")
+	triggers.Wait()
+
+	require.Len(t, a.prompts, 1)
+	assert.Equal(t, `Tell me the print is done and log the filament used: {"filament_m":1.2}`, a.prompts[0])
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, "devices", notifier.sent[0].Category)
+	assert.Equal(t, "print_complete", notifier.sent[0].Title)
+
+	// A repeat within the cooldown is ignored
+	conn.deliver("octoprint/event/PrintDone", `{"filament_m":1.2}`)
+	triggers.Wait()
+	assert.Len(t, a.prompts, 1)
+}
+
+func TestTriggers_FailureIsNotSent(t *testing.T) {
+	subs := []config.MQTTSubscription{{Topic: "ci/+/result", Event: "ci_result", Prompt: "Summarize the CI result"}}
+	bus := events.NewBus(nil)
+	_, conn := startBridge(t, config.MQTTConfig{Subscriptions: subs}, bus)
+
+	a := &fakeAgent{err: errors.New("provider down")}
+	notifier := &recordingNotifier{}
+	triggers := NewTriggers(subs, a, nil)
+	triggers.SetNotifier(notifier)
+	triggers.Subscribe(bus)
+
+	conn.deliver("ci/myrai/result", "failed")
+	triggers.Wait()
+	require.Len(t, a.prompts, 1)
+	assert.Equal(t, "Summarize the CI result\n\nMessage on ci/myrai/result:\nfailed", a.prompts[0])
+	assert.Empty(t, notifier.sent)
+}
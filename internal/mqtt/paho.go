@@ -0,0 +1,65 @@
+package mqtt
+
+import (
+	"fmt"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+// operationTimeout bounds how long a subscribe or publish may wait for the
+// broker
+const operationTimeout = 10 * time.Second
+
+// pahoConn adapts a paho client to Conn
+type pahoConn struct {
+	client paho.Client
+}
+
+// dialPaho connects with the Eclipse Paho client, retrying and reconnecting
+// in the background
+func dialPaho(cfg config.MQTTConfig, onConnect func(Conn)) (Conn, error) {
+	conn := &pahoConn{}
+	opts := paho.NewClientOptions().
+		AddBroker(cfg.Broker).
+		SetClientID(cfg.ClientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetConnectTimeout(operationTimeout).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(30 * time.Second).
+		SetOnConnectHandler(func(paho.Client) { onConnect(conn) })
+	conn.client = paho.NewClient(opts)
+
+	// With ConnectRetry the token completes straight away and the client
+	// keeps trying; an error here means the options are unusable
+	token := conn.client.Connect()
+	if token.WaitTimeout(operationTimeout) && token.Error() != nil {
+		return nil, token.Error()
+	}
+	return conn, nil
+}
+
+func (c *pahoConn) Subscribe(topic string, qos byte, handler MessageHandler) error {
+	token := c.client.Subscribe(topic, qos, func(_ paho.Client, m paho.Message) {
+		handler(m.Topic(), m.Payload())
+	})
+	return wait(token)
+}
+
+func (c *pahoConn) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	return wait(c.client.Publish(topic, qos, retained, payload))
+}
+
+func (c *pahoConn) Close() {
+	c.client.Disconnect(250)
+}
+
+func wait(token paho.Token) error {
+	if !token.WaitTimeout(operationTimeout) {
+		return fmt.Errorf("timed out waiting for the mqtt broker")
+	}
+	return token.Error()
+}
@@ -0,0 +1,77 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/triggers"
+	"go.uber.org/zap"
+)
+
+// Triggers runs the prompt of each subscription that has one when its event
+// arrives, and sends the answer to the user. A trigger that is still running
+// or cooling down skips further messages.
+type Triggers struct {
+	*triggers.Runner
+	subs []config.MQTTSubscription
+}
+
+// NewTriggers creates triggers for the subscriptions that have a prompt
+func NewTriggers(subs []config.MQTTSubscription, a agent.Chatter, logger *zap.Logger) *Triggers {
+	t := &Triggers{Runner: triggers.NewRunner("MQTT", a, logger)}
+	for _, sub := range subs {
+		if strings.TrimSpace(sub.Prompt) != "" {
+			t.subs = append(t.subs, sub)
+		}
+	}
+	return t
+}
+
+// Subscribe starts reacting to the subscriptions' events on bus. It returns
+// a function that stops.
+func (t *Triggers) Subscribe(bus *events.Bus) func() {
+	var unsubscribe []func()
+	for _, sub := range t.subs {
+		sub := sub
+		key := sub.Topic + " " + EventType(sub)
+		unsubscribe = append(unsubscribe, bus.Subscribe(EventType(sub), func(ctx context.Context, e events.Event) {
+			topic, _ := e.Data["topic"].(string)
+			if !TopicMatches(sub.Topic, topic) {
+				return
+			}
+			payload, _ := e.Data["payload"].(string)
+			// Bus handlers run on the MQTT client's goroutine, which Start
+			// doesn't hold up
+			t.Start(triggers.Run{
+				Key:          key,
+				Cooldown:     time.Duration(sub.Cooldown) * time.Second,
+				UserID:       e.UserID,
+				Prompt:       Prompt(sub.Prompt, topic, payload),
+				SystemPrompt: "You are reacting to a message from a device or service. Be concise.",
+				Event:        e.Type,
+				Summary:      fmt.Sprintf("Reacted to %s on %s", e.Type, topic),
+				Category:     "devices",
+				Title:        strings.TrimPrefix(e.Type, EventPrefix),
+			})
+		}))
+	}
+	return func() {
+		for _, u := range unsubscribe {
+			u()
+		}
+	}
+}
+
+// Prompt fills {{topic}} and {{payload}} into a trigger prompt. Without
+// placeholders the message is appended.
+func Prompt(template, topic, payload string) string {
+	if !strings.Contains(template, "{{payload}}") && !strings.Contains(template, "{{topic}}") {
+		return fmt.Sprintf("%s\n\nMessage on %s:\n%s", template, topic, payload)
+	}
+	return strings.NewReplacer("{{topic}}", topic, "{{payload}}", payload).Replace(template)
+}
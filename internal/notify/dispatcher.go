@@ -0,0 +1,346 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/journal"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PrefixNotification is the ID prefix for queued notifications
+const PrefixNotification = "ntf"
+
+// FlushInterval is how often queued notifications are checked
+const FlushInterval = time.Minute
+
+// Delivery outcomes
+const (
+	StatusSent   = "sent"
+	StatusQueued = "queued"
+)
+
+// Notification is a message the assistant sends without being asked
+type Notification struct {
+	UserID   string
+	Category string // e.g. health, tasks, scheduled
+	Title    string
+	Body     string
+	Urgent   bool // ignores quiet hours and digests
+	Secret   bool // body is kept out of the activity journal
+}
+
+// Text renders the notification as a single message
+func (n Notification) Text() string {
+	if n.Title == "" {
+		return n.Body
+	}
+	if n.Body == "" {
+		return "🔔 " + n.Title
+	}
+	return fmt.Sprintf("🔔 %s\n\n%s", n.Title, n.Body)
+}
+
+// Sender sends a notification. *Dispatcher implements it; packages that
+// notify users take a Sender rather than the dispatcher itself.
+type Sender interface {
+	Send(ctx context.Context, n Notification) (string, error)
+}
+
+// Deliverer delivers text to a target on one channel. The target is
+// whatever the channel recorded with Remember, e.g. a Telegram chat ID.
+type Deliverer interface {
+	Deliver(ctx context.Context, target, text string) error
+}
+
+// Address is where a user can be reached on a channel
+type Address struct {
+	UserID    string    `gorm:"primaryKey" json:"user_id"`
+	Channel   string    `gorm:"primaryKey" json:"channel"`
+	Target    string    `gorm:"not null" json:"target"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName sets the table name
+func (Address) TableName() string { return "notification_addresses" }
+
+// Pending is a notification waiting for quiet hours to end or for the digest
+type Pending struct {
+	ID        string    `gorm:"primaryKey" json:"id"`
+	UserID    string    `gorm:"index;not null" json:"user_id"`
+	Category  string    `json:"category"`
+	Channel   string    `json:"channel,omitempty"`
+	Title     string    `json:"title"`
+	Body      string    `gorm:"type:text" json:"body"`
+	DeliverAt time.Time `gorm:"index" json:"deliver_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName sets the table name
+func (Pending) TableName() string { return "notification_queue" }
+
+// Dispatcher is the single way outbound notifications leave the assistant,
+// so every sender gets the same quiet hours, routing and digests
+type Dispatcher struct {
+	db      *gorm.DB
+	prefs   *Manager
+	logger  *zap.Logger
+	journal *journal.Journal
+	now     func() time.Time
+	mu      sync.RWMutex
+	senders map[string]Deliverer
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher with no channels registered
+func NewDispatcher(db *gorm.DB, logger *zap.Logger) (*Dispatcher, error) {
+	prefs, err := NewManager(db)
+	if err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Dispatcher{
+		db:      db,
+		prefs:   prefs,
+		logger:  logger,
+		now:     time.Now,
+		senders: make(map[string]Deliverer),
+	}, nil
+}
+
+// Preferences returns the preferences manager
+func (d *Dispatcher) Preferences() *Manager {
+	return d.prefs
+}
+
+// SetJournal records every notification sent or queued in j
+func (d *Dispatcher) SetJournal(j *journal.Journal) {
+	d.journal = j
+}
+
+// Register makes a channel available for delivery
+func (d *Dispatcher) Register(channel string, sender Deliverer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.senders[channel] = sender
+}
+
+// Remember records where a user can be reached on a channel. Channels call
+// it for every message they receive, so "auto" routing follows the user.
+func (d *Dispatcher) Remember(userID, channel, target string) error {
+	return d.db.Save(&Address{UserID: userID, Channel: channel, Target: target}).Error
+}
+
+// Send delivers a notification now or queues it, depending on the user's
+// preferences. It returns StatusSent or StatusQueued.
+func (d *Dispatcher) Send(ctx context.Context, n Notification) (string, error) {
+	if n.Category == "" {
+		n.Category = "general"
+	}
+	prefs := d.prefs.Get(n.UserID)
+	channel := prefs.ChannelFor(n.Category)
+
+	now := d.now()
+	at := prefs.DeliverAt(n.Category, n.Urgent, now)
+	if at.After(now) {
+		pending := &Pending{
+			ID:        idgen.Generate(PrefixNotification),
+			UserID:    n.UserID,
+			Category:  strings.ToLower(n.Category),
+			Channel:   channel,
+			Title:     n.Title,
+			Body:      n.Body,
+			DeliverAt: at,
+		}
+		err := d.db.Create(pending).Error
+		d.journal.Record(n.UserID, journal.KindMessage,
+			fmt.Sprintf("Queued %s for %s", notificationLabel(n), at.Format("15:04")), journalDetail(n), err)
+		if err != nil {
+			return "", fmt.Errorf("failed to queue notification: %w", err)
+		}
+		return StatusQueued, nil
+	}
+
+	err := d.deliver(ctx, n.UserID, channel, n.Text())
+	d.journal.Record(n.UserID, journal.KindMessage, "Sent "+notificationLabel(n), journalDetail(n), err)
+	if err != nil {
+		return "", err
+	}
+	return StatusSent, nil
+}
+
+// Flush delivers queued notifications that are due. Notifications for the
+// same user and channel are combined into one digest message.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	var due []Pending
+	if err := d.db.Where("deliver_at <= ?", d.now()).Order("created_at ASC").Find(&due).Error; err != nil {
+		return err
+	}
+
+	type key struct{ user, channel string }
+	groups := make(map[key][]Pending)
+	var order []key
+	for _, p := range due {
+		k := key{p.UserID, p.Channel}
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], p)
+	}
+
+	var firstErr error
+	for _, k := range order {
+		items := groups[k]
+		err := d.deliver(ctx, k.user, k.channel, formatDigest(items))
+		d.journal.Record(k.user, journal.KindMessage,
+			fmt.Sprintf("Delivered queued notifications (%d)", len(items)), "", err)
+		if err != nil {
+			d.logger.Warn("Failed to deliver queued notifications",
+				zap.String("user_id", k.user),
+				zap.Int("count", len(items)),
+				zap.Error(err))
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		ids := make([]string, len(items))
+		for i, p := range items {
+			ids[i] = p.ID
+		}
+		if err := d.db.Where("id IN ?", ids).Delete(&Pending{}).Error; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Queued returns a user's undelivered notifications, soonest first
+func (d *Dispatcher) Queued(userID string) ([]Pending, error) {
+	var pending []Pending
+	err := d.db.Where("user_id = ?", userID).Order("deliver_at ASC").Find(&pending).Error
+	return pending, err
+}
+
+// Start flushes the queue every FlushInterval until Stop is called
+func (d *Dispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := d.Flush(ctx); err != nil {
+					d.logger.Warn("Notification flush failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the flush loop
+func (d *Dispatcher) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+		d.wg.Wait()
+	}
+}
+
+// notificationLabel names a notification in the activity journal
+func notificationLabel(n Notification) string {
+	if n.Title != "" || n.Secret {
+		return fmt.Sprintf("%s notification %q", n.Category, n.Title)
+	}
+	return fmt.Sprintf("%s notification %q", n.Category, journal.Summarize(n.Body, 60))
+}
+
+// journalDetail is what the activity journal keeps of a notification's body
+func journalDetail(n Notification) string {
+	if n.Secret {
+		return ""
+	}
+	return n.Body
+}
+
+// deliver sends text to the user on channel, or on the channel they used
+// most recently when channel is empty or cannot reach them
+func (d *Dispatcher) deliver(ctx context.Context, userID, channel, text string) error {
+	var addresses []Address
+	if err := d.db.Where("user_id = ?", userID).Order("updated_at DESC").Find(&addresses).Error; err != nil {
+		return err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var fallback *Address
+	for i := range addresses {
+		a := &addresses[i]
+		if _, ok := d.senders[a.Channel]; !ok {
+			continue
+		}
+		if channel == "" || a.Channel == channel {
+			return d.senders[a.Channel].Deliver(ctx, a.Target, text)
+		}
+		if fallback == nil {
+			fallback = a
+		}
+	}
+	if fallback != nil {
+		d.logger.Debug("Preferred channel unavailable, using fallback",
+			zap.String("user_id", userID),
+			zap.String("channel", channel),
+			zap.String("fallback", fallback.Channel))
+		return d.senders[fallback.Channel].Deliver(ctx, fallback.Target, text)
+	}
+	return fmt.Errorf("no channel available to reach user %s", userID)
+}
+
+// formatDigest combines queued notifications into one message
+func formatDigest(items []Pending) string {
+	if len(items) == 1 {
+		return Notification{Title: items[0].Title, Body: items[0].Body}.Text()
+	}
+
+	byCategory := make(map[string][]Pending)
+	var categories []string
+	for _, p := range items {
+		if _, ok := byCategory[p.Category]; !ok {
+			categories = append(categories, p.Category)
+		}
+		byCategory[p.Category] = append(byCategory[p.Category], p)
+	}
+	sort.Strings(categories)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📬 %d notifications\n", len(items)))
+	for _, c := range categories {
+		sb.WriteString(fmt.Sprintf("\n%s\n", strings.ToUpper(c[:1])+c[1:]))
+		for _, p := range byCategory[c] {
+			line := p.Title
+			if line == "" {
+				line = p.Body
+			} else if p.Body != "" {
+				line += ": " + p.Body
+			}
+			sb.WriteString("• " + line + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
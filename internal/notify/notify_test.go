@@ -0,0 +1,199 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/journal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type recordingSender struct {
+	sent []string
+}
+
+func (s *recordingSender) Deliver(ctx context.Context, target, text string) error {
+	s.sent = append(s.sent, target+": "+text)
+	return nil
+}
+
+func setupDispatcher(t *testing.T, now time.Time) *Dispatcher {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	d, err := NewDispatcher(db, nil)
+	require.NoError(t, err)
+	d.now = func() time.Time { return now }
+	return d
+}
+
+func TestPreferences_QuietHours(t *testing.T) {
+	p := &Preferences{}
+	require.NoError(t, p.SetQuietHours("22:00-7:00"))
+	assert.Equal(t, "07:00", p.QuietEnd)
+
+	late := time.Date(2025, 3, 4, 23, 30, 0, 0, time.UTC)
+	end, quiet := p.QuietUntil(late)
+	assert.True(t, quiet)
+	assert.Equal(t, time.Date(2025, 3, 5, 7, 0, 0, 0, time.UTC), end)
+
+	early := time.Date(2025, 3, 5, 6, 0, 0, 0, time.UTC)
+	end, quiet = p.QuietUntil(early)
+	assert.True(t, quiet)
+	assert.Equal(t, time.Date(2025, 3, 5, 7, 0, 0, 0, time.UTC), end)
+
+	_, quiet = p.QuietUntil(time.Date(2025, 3, 5, 12, 0, 0, 0, time.UTC))
+	assert.False(t, quiet)
+
+	assert.Error(t, p.SetQuietHours("late"))
+	require.NoError(t, p.SetQuietHours("off"))
+	_, quiet = p.QuietUntil(late)
+	assert.False(t, quiet)
+}
+
+func TestPreferences_DeliverAt(t *testing.T) {
+	p := &Preferences{}
+	require.NoError(t, p.SetQuietHours("22:00-07:00"))
+	require.NoError(t, p.SetDelivery("tasks", DeliverDigest))
+	require.NoError(t, p.SetDigestTime("6:30"))
+
+	night := time.Date(2025, 3, 4, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 5, 7, 0, 0, 0, time.UTC), p.DeliverAt("health", false, night))
+	assert.Equal(t, night, p.DeliverAt("health", true, night))
+
+	// A digest time inside quiet hours waits for them to end
+	assert.Equal(t, time.Date(2025, 3, 5, 7, 0, 0, 0, time.UTC), p.DeliverAt("tasks", false, night))
+
+	noon := time.Date(2025, 3, 5, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, noon, p.DeliverAt("health", false, noon))
+	assert.Equal(t, time.Date(2025, 3, 6, 7, 0, 0, 0, time.UTC), p.DeliverAt("tasks", false, noon))
+}
+
+func TestPreferences_Routing(t *testing.T) {
+	p := &Preferences{}
+	assert.Equal(t, "", p.ChannelFor("health"))
+
+	require.NoError(t, p.SetChannel("discord"))
+	require.NoError(t, p.SetRoute("Health", "telegram"))
+	assert.Equal(t, "telegram", p.ChannelFor("health"))
+	assert.Equal(t, "discord", p.ChannelFor("tasks"))
+
+	require.NoError(t, p.SetRoute("health", "default"))
+	assert.Equal(t, "discord", p.ChannelFor("health"))
+
+	assert.Error(t, p.SetRoute("work", "carrier-pigeon"))
+	assert.Error(t, p.SetDelivery("work", "weekly"))
+}
+
+func TestDispatcher_SendAndFlush(t *testing.T) {
+	now := time.Date(2025, 3, 4, 23, 0, 0, 0, time.Local)
+	d := setupDispatcher(t, now)
+	ctx := context.Background()
+
+	telegram, discord := &recordingSender{}, &recordingSender{}
+	d.Register("telegram", telegram)
+	d.Register("discord", discord)
+	require.NoError(t, d.Remember("alice", "telegram", "111"))
+	require.NoError(t, d.Remember("alice", "discord", "222"))
+
+	prefs := d.Preferences().Get("alice")
+	require.NoError(t, prefs.SetQuietHours("22:00-07:00"))
+	require.NoError(t, prefs.SetRoute("health", "telegram"))
+	require.NoError(t, prefs.SetChannel("discord"))
+	require.NoError(t, d.Preferences().Set(prefs))
+
+	// Urgent goes out during quiet hours on the routed channel
+	status, err := d.Send(ctx, Notification{UserID: "alice", Category: "health", Title: "Missed dose", Urgent: true})
+	require.NoError(t, err)
+	assert.Equal(t, StatusSent, status)
+	assert.Equal(t, []string{"111: 🔔 Missed dose"}, telegram.sent)
+
+	// Everything else waits for the morning
+	for _, title := range []string{"Report ready", "Backup done"} {
+		status, err = d.Send(ctx, Notification{UserID: "alice", Category: "scheduled", Title: title})
+		require.NoError(t, err)
+		assert.Equal(t, StatusQueued, status)
+	}
+	require.NoError(t, d.Flush(ctx))
+	assert.Empty(t, discord.sent)
+
+	d.now = func() time.Time { return now.Add(8 * time.Hour) }
+	require.NoError(t, d.Flush(ctx))
+	require.Len(t, discord.sent, 1)
+	assert.Contains(t, discord.sent[0], "2 notifications")
+	assert.Contains(t, discord.sent[0], "• Report ready")
+	assert.Contains(t, discord.sent[0], "• Backup done")
+
+	queued, err := d.Queued("alice")
+	require.NoError(t, err)
+	assert.Empty(t, queued)
+}
+
+func TestDispatcher_NoAddress(t *testing.T) {
+	d := setupDispatcher(t, time.Now())
+	d.Register("telegram", &recordingSender{})
+
+	_, err := d.Send(context.Background(), Notification{UserID: "bob", Title: "Hello", Urgent: true})
+	assert.Error(t, err)
+}
+
+func TestDispatcher_Journal(t *testing.T) {
+	d := setupDispatcher(t, time.Date(2025, 3, 4, 12, 0, 0, 0, time.Local))
+	j, err := journal.New(d.db, nil)
+	require.NoError(t, err)
+	d.SetJournal(j)
+	d.Register("telegram", &recordingSender{})
+	require.NoError(t, d.Remember("alice", "telegram", "111"))
+
+	_, err = d.Send(context.Background(), Notification{UserID: "alice", Category: "tasks", Title: "Report due"})
+	require.NoError(t, err)
+	_, err = d.Send(context.Background(), Notification{UserID: "bob", Title: "Hello"})
+	require.Error(t, err)
+
+	entries, err := j.List(journal.Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, journal.KindMessage, entries[0].Kind)
+	assert.Equal(t, `Sent tasks notification "Report due"`, entries[0].Summary)
+	assert.Equal(t, journal.StatusOK, entries[0].Status)
+	assert.Equal(t, "bob", entries[1].UserID)
+	assert.Equal(t, journal.StatusFailed, entries[1].Status)
+}
+
+func TestDispatcher_SecretBodyNotJournaled(t *testing.T) {
+	d := setupDispatcher(t, time.Date(2025, 3, 4, 12, 0, 0, 0, time.Local))
+	j, err := journal.New(d.db, nil)
+	require.NoError(t, err)
+	d.SetJournal(j)
+	sender := &recordingSender{}
+	d.Register("telegram", sender)
+	require.NoError(t, d.Remember("alice", "telegram", "111"))
+
+	_, err = d.Send(context.Background(), Notification{UserID: "alice", Category: "email", Title: "Approve email", Body: "Code: 123456", Secret: true})
+	require.NoError(t, err)
+
+	entries, err := j.List(journal.Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.NotContains(t, entries[0].Summary+entries[0].Detail, "123456")
+}
+
+func TestManager_Command(t *testing.T) {
+	d := setupDispatcher(t, time.Now())
+	m := d.Preferences()
+
+	assert.Contains(t, m.Command("alice", ""), "Quiet hours: off")
+	assert.Contains(t, m.Command("alice", "quiet 22:00-07:00"), "Quiet hours: 22:00-07:00")
+	assert.Contains(t, m.Command("alice", "route health telegram"), "health → telegram")
+	assert.Contains(t, m.Command("alice", "digest tasks"), "Digest at 08:00: tasks")
+	assert.Contains(t, m.Command("alice", "digest at 09:15"), "Digest at 09:15: tasks")
+	assert.Contains(t, m.Command("alice", "immediate tasks"), "Digest: none")
+	assert.Contains(t, m.Command("alice", "route health fax"), "❌")
+	assert.Contains(t, m.Command("alice", "bogus"), "Usage:")
+
+	assert.Contains(t, m.Command("alice", "reset"), "reset")
+	assert.Contains(t, m.Command("alice", "show"), "Quiet hours: off")
+}
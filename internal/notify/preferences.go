@@ -0,0 +1,419 @@
+// Package notify delivers messages the assistant sends on its own initiative
+// (reminders, scheduled job results, digests) and applies each user's
+// notification preferences: quiet hours, per-category channel routing and
+// digest delivery.
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"gorm.io/gorm"
+)
+
+// Delivery modes
+const (
+	DeliverImmediate = "immediate"
+	DeliverDigest    = "digest"
+)
+
+// ChannelAuto sends to wherever the user last talked to the assistant
+const ChannelAuto = "auto"
+
+// DefaultDigestTime is when digests go out unless the user picks a time
+const DefaultDigestTime = "08:00"
+
+// Channels lists the channels notifications can be routed to
+var Channels = []string{"telegram", "discord"}
+
+// Preferences are a user's notification settings
+type Preferences struct {
+	UserID string `gorm:"primaryKey" json:"user_id"`
+	// QuietStart and QuietEnd are "HH:MM" local times; non-urgent
+	// notifications that fall between them wait until QuietEnd. Both empty
+	// means no quiet hours.
+	QuietStart string `json:"quiet_start,omitempty"`
+	QuietEnd   string `json:"quiet_end,omitempty"`
+	// Channel is the default channel; empty or ChannelAuto follows the user
+	Channel string `json:"channel,omitempty"`
+	// Routes overrides the channel per category, e.g. health -> telegram
+	Routes map[string]string `json:"routes,omitempty" gorm:"serializer:json"`
+	// Digest lists categories collected into one message at DigestTime
+	Digest     []string  `json:"digest,omitempty" gorm:"serializer:json"`
+	DigestTime string    `json:"digest_time,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName sets the table name
+func (Preferences) TableName() string { return "notification_preferences" }
+
+// ChannelFor returns the channel a category is routed to, or "" to follow
+// the user
+func (p *Preferences) ChannelFor(category string) string {
+	channel := p.Channel
+	if routed, ok := p.Routes[strings.ToLower(category)]; ok {
+		channel = routed
+	}
+	if channel == ChannelAuto {
+		return ""
+	}
+	return channel
+}
+
+// DeliveryFor returns DeliverDigest or DeliverImmediate for a category
+func (p *Preferences) DeliveryFor(category string) string {
+	category = strings.ToLower(category)
+	for _, c := range p.Digest {
+		if c == category {
+			return DeliverDigest
+		}
+	}
+	return DeliverImmediate
+}
+
+// QuietUntil reports whether t falls in quiet hours and, if so, when they end
+func (p *Preferences) QuietUntil(t time.Time) (time.Time, bool) {
+	start, okStart := parseClock(p.QuietStart)
+	end, okEnd := parseClock(p.QuietEnd)
+	if !okStart || !okEnd || start == end {
+		return time.Time{}, false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	var quiet bool
+	if start < end {
+		quiet = minute >= start && minute < end
+	} else {
+		// Quiet hours span midnight, e.g. 22:00-07:00
+		quiet = minute >= start || minute < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+	return nextClock(end, t), true
+}
+
+// DeliverAt returns when a notification should be delivered. Urgent
+// notifications always go out at once; digest categories wait for the
+// digest time; anything landing in quiet hours waits for them to end.
+func (p *Preferences) DeliverAt(category string, urgent bool, now time.Time) time.Time {
+	if urgent {
+		return now
+	}
+
+	at := now
+	if p.DeliveryFor(category) == DeliverDigest {
+		clock, ok := parseClock(p.DigestTime)
+		if !ok {
+			clock, _ = parseClock(DefaultDigestTime)
+		}
+		at = nextClock(clock, now)
+	}
+	if end, quiet := p.QuietUntil(at); quiet {
+		at = end
+	}
+	return at
+}
+
+// SetQuietHours sets quiet hours from "HH:MM-HH:MM", or clears them with "off"
+func (p *Preferences) SetQuietHours(spec string) error {
+	spec = strings.TrimSpace(strings.ToLower(spec))
+	if spec == "off" || spec == "none" || spec == "" {
+		p.QuietStart, p.QuietEnd = "", ""
+		return nil
+	}
+
+	parts := strings.Split(spec, "-")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid quiet hours %q: use HH:MM-HH:MM, e.g. 22:00-07:00", spec)
+	}
+	start, okStart := parseClock(parts[0])
+	end, okEnd := parseClock(parts[1])
+	if !okStart || !okEnd || start == end {
+		return fmt.Errorf("invalid quiet hours %q: use HH:MM-HH:MM, e.g. 22:00-07:00", spec)
+	}
+	p.QuietStart, p.QuietEnd = formatClock(start), formatClock(end)
+	return nil
+}
+
+// SetChannel sets the default channel
+func (p *Preferences) SetChannel(channel string) error {
+	channel, err := normalizeChannel(channel)
+	if err != nil {
+		return err
+	}
+	p.Channel = channel
+	return nil
+}
+
+// SetRoute routes a category to a channel; "default" removes the route
+func (p *Preferences) SetRoute(category, channel string) error {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category == "" {
+		return fmt.Errorf("category is required")
+	}
+	if strings.EqualFold(channel, "default") {
+		delete(p.Routes, category)
+		return nil
+	}
+
+	channel, err := normalizeChannel(channel)
+	if err != nil {
+		return err
+	}
+	if p.Routes == nil {
+		p.Routes = make(map[string]string)
+	}
+	p.Routes[category] = channel
+	return nil
+}
+
+// SetDelivery switches a category between digest and immediate delivery
+func (p *Preferences) SetDelivery(category, mode string) error {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category == "" {
+		return fmt.Errorf("category is required")
+	}
+
+	var digest []string
+	for _, c := range p.Digest {
+		if c != category {
+			digest = append(digest, c)
+		}
+	}
+	switch strings.ToLower(mode) {
+	case DeliverDigest:
+		digest = append(digest, category)
+		sort.Strings(digest)
+	case DeliverImmediate:
+	default:
+		return fmt.Errorf("invalid delivery %q: use %s or %s", mode, DeliverDigest, DeliverImmediate)
+	}
+	p.Digest = digest
+	return nil
+}
+
+// SetDigestTime sets when digests are delivered
+func (p *Preferences) SetDigestTime(clock string) error {
+	minutes, ok := parseClock(clock)
+	if !ok {
+		return fmt.Errorf("invalid digest time %q: use HH:MM", clock)
+	}
+	p.DigestTime = formatClock(minutes)
+	return nil
+}
+
+// Summary describes the preferences in one line per setting
+func (p *Preferences) Summary() string {
+	var sb strings.Builder
+
+	if p.QuietStart != "" {
+		sb.WriteString(fmt.Sprintf("🌙 Quiet hours: %s-%s\n", p.QuietStart, p.QuietEnd))
+	} else {
+		sb.WriteString("🌙 Quiet hours: off\n")
+	}
+
+	channel := p.Channel
+	if channel == "" {
+		channel = ChannelAuto
+	}
+	sb.WriteString(fmt.Sprintf("📡 Default channel: %s\n", channel))
+
+	if len(p.Routes) > 0 {
+		categories := make([]string, 0, len(p.Routes))
+		for c := range p.Routes {
+			categories = append(categories, c)
+		}
+		sort.Strings(categories)
+		routes := make([]string, len(categories))
+		for i, c := range categories {
+			routes[i] = fmt.Sprintf("%s → %s", c, p.Routes[c])
+		}
+		sb.WriteString(fmt.Sprintf("🔀 Routes: %s\n", strings.Join(routes, ", ")))
+	}
+
+	if len(p.Digest) > 0 {
+		digestTime := p.DigestTime
+		if digestTime == "" {
+			digestTime = DefaultDigestTime
+		}
+		sb.WriteString(fmt.Sprintf("📬 Digest at %s: %s\n", digestTime, strings.Join(p.Digest, ", ")))
+	} else {
+		sb.WriteString("📬 Digest: none, everything is sent immediately\n")
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// Manager stores notification preferences per user
+type Manager struct {
+	db *gorm.DB
+}
+
+func init() {
+	store.RegisterMigrations("notify", store.Migration{
+		Version: 1,
+		Name:    "create notification tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Preferences{}, &Address{}, &Pending{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Preferences{}, &Address{}, &Pending{})
+		},
+	})
+}
+
+// NewManager creates a new preferences manager
+func NewManager(db *gorm.DB) (*Manager, error) {
+	if err := store.Migrate(db, "notify"); err != nil {
+		return nil, fmt.Errorf("failed to migrate notification schema: %w", err)
+	}
+	return &Manager{db: db}, nil
+}
+
+// Get returns the user's preferences, or empty defaults if none are saved
+func (m *Manager) Get(userID string) *Preferences {
+	var prefs Preferences
+	if err := m.db.Where("user_id = ?", userID).First(&prefs).Error; err != nil {
+		return &Preferences{UserID: userID}
+	}
+	return &prefs
+}
+
+// Set saves the user's preferences
+func (m *Manager) Set(prefs *Preferences) error {
+	return m.db.Save(prefs).Error
+}
+
+// Reset removes the user's preferences so the defaults apply again
+func (m *Manager) Reset(userID string) error {
+	return m.db.Where("user_id = ?", userID).Delete(&Preferences{}).Error
+}
+
+// Command runs a "/notifications" chat command for a user and returns the
+// reply
+func (m *Manager) Command(userID, args string) string {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		fields = []string{"show"}
+	}
+
+	prefs := m.Get(userID)
+	var err error
+
+	switch strings.ToLower(fields[0]) {
+	case "show", "list":
+		return "🔔 Notifications\n\n" + prefs.Summary()
+
+	case "quiet":
+		if len(fields) < 2 {
+			return "Usage: /notifications quiet <HH:MM-HH:MM|off>"
+		}
+		err = prefs.SetQuietHours(fields[1])
+
+	case "channel":
+		if len(fields) < 2 {
+			return fmt.Sprintf("Usage: /notifications channel <%s|%s>", strings.Join(Channels, "|"), ChannelAuto)
+		}
+		err = prefs.SetChannel(fields[1])
+
+	case "route":
+		if len(fields) < 3 {
+			return fmt.Sprintf("Usage: /notifications route <category> <%s|default>", strings.Join(Channels, "|"))
+		}
+		err = prefs.SetRoute(fields[1], fields[2])
+
+	case "digest":
+		if len(fields) < 2 {
+			return "Usage: /notifications digest <category> | /notifications digest at <HH:MM>"
+		}
+		if strings.EqualFold(fields[1], "at") && len(fields) > 2 {
+			err = prefs.SetDigestTime(fields[2])
+		} else {
+			err = prefs.SetDelivery(fields[1], DeliverDigest)
+		}
+
+	case "immediate":
+		if len(fields) < 2 {
+			return "Usage: /notifications immediate <category>"
+		}
+		err = prefs.SetDelivery(fields[1], DeliverImmediate)
+
+	case "reset":
+		if err := m.Reset(userID); err != nil {
+			return "❌ Failed to reset notification settings."
+		}
+		return "✅ Notification settings reset."
+
+	default:
+		return notificationsUsage
+	}
+
+	if err != nil {
+		return fmt.Sprintf("❌ %v", err)
+	}
+	if err := m.Set(prefs); err != nil {
+		return "❌ Failed to save notification settings."
+	}
+	return "✅ Updated\n\n" + prefs.Summary()
+}
+
+const notificationsUsage = `Usage:
+/notifications - Show your settings
+/notifications quiet 22:00-07:00 - Hold notifications overnight (or "off")
+/notifications channel telegram - Default channel (or "auto")
+/notifications route health telegram - Route a category (or "default")
+/notifications digest tasks - Collect a category into a daily digest
+/notifications digest at 08:00 - When the digest is sent
+/notifications immediate tasks - Send a category right away again
+/notifications reset - Restore the defaults`
+
+func normalizeChannel(channel string) (string, error) {
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	if channel == ChannelAuto {
+		return channel, nil
+	}
+	for _, c := range Channels {
+		if c == channel {
+			return channel, nil
+		}
+	}
+	return "", fmt.Errorf("unknown channel %q: use %s or %s", channel, strings.Join(Channels, ", "), ChannelAuto)
+}
+
+// parseClock parses "HH:MM" (or "H") into minutes after midnight
+func parseClock(s string) (int, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	hourStr, minuteStr, found := strings.Cut(s, ":")
+	hour, err := strconv.Atoi(hourStr)
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, false
+	}
+	minute := 0
+	if found {
+		if minute, err = strconv.Atoi(minuteStr); err != nil || minute < 0 || minute > 59 {
+			return 0, false
+		}
+	}
+	return hour*60 + minute, true
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// nextClock returns the first time at or after t whose clock reads minutes
+func nextClock(minutes int, t time.Time) time.Time {
+	at := time.Date(t.Year(), t.Month(), t.Day(), minutes/60, minutes%60, 0, 0, t.Location())
+	if at.Before(t) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at
+}
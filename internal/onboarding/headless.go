@@ -0,0 +1,194 @@
+package onboarding
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// localProviders run on the user's machine and need no API key
+var localProviders = map[string]bool{"ollama": true, "localai": true, "vllm": true}
+
+// Answers are what the wizard would ask, given up front so setup can run
+// without a terminal, e.g. in Docker or from Ansible
+type Answers struct {
+	Name               string   `yaml:"name"`
+	CommunicationStyle string   `yaml:"communication_style"`
+	Expertise          []string `yaml:"expertise"`
+	Goals              []string `yaml:"goals"`
+
+	Provider  string `yaml:"provider"`
+	Model     string `yaml:"model"`
+	APIKey    string `yaml:"api_key"`     // prefer APIKeyEnv, which keeps the key out of files and process lists
+	APIKeyEnv string `yaml:"api_key_env"` // variable holding the API key
+	BaseURL   string `yaml:"base_url"`
+
+	Profile   string `yaml:"profile"`    // set up this profile instead of the default instance
+	DataDir   string `yaml:"data_dir"`   // default: ~/.local/share/myrai, or the profile's data directory
+	ConfigDir string `yaml:"config_dir"` // default: ~/.config/myrai, or the profile's directory
+
+	TelegramTokenEnv string `yaml:"telegram_token_env"` // enables Telegram with the token in this variable
+	SearchProvider   string `yaml:"search_provider"`
+	SearchAPIKeyEnv  string `yaml:"search_api_key_env"`
+	VisionModel      string `yaml:"vision_model"` // enables vision with this model
+
+	Check bool `yaml:"check"` // call the provider before writing anything
+}
+
+// LoadAnswers reads answers from a YAML file
+func LoadAnswers(path string) (*Answers, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var answers Answers
+	if err := yaml.Unmarshal(data, &answers); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &answers, nil
+}
+
+// Providers lists the provider names setup knows
+func Providers() []string {
+	names := make([]string, 0, len(providerDefaults))
+	for name := range providerDefaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Result says where non-interactive setup wrote its files
+type Result struct {
+	ConfigPath string
+	EnvPath    string
+	DataDir    string
+}
+
+// RunNonInteractive writes the config, .env and persona files the wizard
+// would, from answers and without prompting. Existing config is only
+// replaced when overwrite is set.
+func RunNonInteractive(answers Answers, overwrite bool, logger *zap.Logger) (*Result, error) {
+	wc, err := answers.wizardConfig()
+	if err != nil {
+		return nil, err
+	}
+	if answers.Check {
+		if err := wc.CheckCredentials(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	dataDir, configDir := GetWorkspacePath(), filepath.Dir(GetConfigPath())
+	if answers.Profile != "" {
+		if err := config.ValidateProfileName(answers.Profile); err != nil {
+			return nil, err
+		}
+		configDir = config.ProfileDir(answers.Profile)
+		dataDir = filepath.Join(configDir, "data")
+	}
+	if answers.DataDir != "" {
+		dataDir = answers.DataDir
+	}
+	if answers.ConfigDir != "" {
+		configDir = answers.ConfigDir
+	}
+	result := &Result{
+		ConfigPath: filepath.Join(configDir, "myrai.yaml"),
+		EnvPath:    filepath.Join(configDir, ".env"),
+		DataDir:    dataDir,
+	}
+
+	if !overwrite {
+		for _, path := range []string{result.ConfigPath, result.EnvPath} {
+			if _, err := os.Stat(path); err == nil {
+				return nil, fmt.Errorf("%s already exists; pass --overwrite to replace it", path)
+			}
+		}
+	}
+
+	if err := createWorkspace(dataDir); err != nil {
+		return nil, err
+	}
+	w := &Wizard{logger: logger, workspace: dataDir, configDir: configDir, config: wc}
+	if err := w.createConfiguration(); err != nil {
+		return nil, fmt.Errorf("configuration creation failed: %w", err)
+	}
+	if err := w.createPersonaFiles(); err != nil {
+		return nil, fmt.Errorf("persona creation failed: %w", err)
+	}
+	return result, nil
+}
+
+// wizardConfig checks the answers and resolves the secrets they point to
+func (a Answers) wizardConfig() (*WizardConfig, error) {
+	provider := strings.ToLower(strings.TrimSpace(a.Provider))
+	if provider == "" {
+		return nil, fmt.Errorf("a provider is required (one of %s)", strings.Join(Providers(), ", "))
+	}
+	if _, ok := providerDefaults[provider]; !ok {
+		return nil, fmt.Errorf("unknown provider %q (one of %s)", provider, strings.Join(Providers(), ", "))
+	}
+	if a.Model == "" {
+		return nil, fmt.Errorf("a model is required")
+	}
+	if provider == "azure" && a.BaseURL == "" {
+		return nil, fmt.Errorf("azure needs a base URL")
+	}
+
+	apiKey := a.APIKey
+	if apiKey == "" && a.APIKeyEnv != "" {
+		if apiKey = os.Getenv(a.APIKeyEnv); apiKey == "" {
+			return nil, fmt.Errorf("%s is not set", a.APIKeyEnv)
+		}
+	}
+	if apiKey == "" {
+		apiKey = os.Getenv(providerKeyEnv[provider])
+	}
+	if apiKey == "" && !localProviders[provider] {
+		return nil, fmt.Errorf("%s needs an API key; pass --api-key-env or set %s", provider, providerKeyEnv[provider])
+	}
+
+	wc := &WizardConfig{
+		UserName:           a.Name,
+		CommunicationStyle: a.CommunicationStyle,
+		Expertise:          a.Expertise,
+		Goals:              a.Goals,
+		LLMProvider:        provider,
+		APIKey:             apiKey,
+		BaseURL:            a.BaseURL,
+		DefaultModel:       a.Model,
+		SearchProvider:     a.SearchProvider,
+		EnableVision:       a.VisionModel != "",
+		VisionModel:        a.VisionModel,
+	}
+	if wc.UserName == "" {
+		wc.UserName = "User"
+	}
+	if wc.CommunicationStyle == "" {
+		wc.CommunicationStyle = CommunicationStyles[0]
+	}
+
+	if a.TelegramTokenEnv != "" {
+		if wc.TelegramToken = os.Getenv(a.TelegramTokenEnv); wc.TelegramToken == "" {
+			return nil, fmt.Errorf("%s is not set", a.TelegramTokenEnv)
+		}
+		wc.EnableTelegram = true
+	}
+	if a.SearchAPIKeyEnv != "" {
+		if wc.SearchAPIKey = os.Getenv(a.SearchAPIKeyEnv); wc.SearchAPIKey == "" {
+			return nil, fmt.Errorf("%s is not set", a.SearchAPIKeyEnv)
+		}
+		if wc.SearchProvider == "" {
+			wc.SearchProvider = "brave"
+		}
+	}
+	return wc, nil
+}
@@ -0,0 +1,123 @@
+package onboarding
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestRunNonInteractive(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TEST_OPENAI_KEY", "sk-test123")
+	answers := Answers{
+		Name:      "Sam",
+		Provider:  "openai",
+		Model:     "gpt-5.2",
+		APIKeyEnv: "TEST_OPENAI_KEY",
+		DataDir:   filepath.Join(dir, "data"),
+		ConfigDir: filepath.Join(dir, "config"),
+	}
+
+	result, err := RunNonInteractive(answers, false, zap.NewNop())
+	if err != nil {
+		t.Fatalf("RunNonInteractive failed: %v", err)
+	}
+
+	config, err := os.ReadFile(result.ConfigPath)
+	if err != nil {
+		t.Fatalf("config not written: %v", err)
+	}
+	for _, want := range []string{"default_provider: openai", `model: "gpt-5.2"`, "https://api.openai.com/v1", answers.DataDir} {
+		if !strings.Contains(string(config), want) {
+			t.Errorf("expected config to contain %q", want)
+		}
+	}
+	env, err := os.ReadFile(result.EnvPath)
+	if err != nil || !strings.Contains(string(env), "OPENAI_API_KEY=sk-test123") {
+		t.Errorf("expected the key in .env, got %q, %v", env, err)
+	}
+	user, err := os.ReadFile(filepath.Join(answers.DataDir, "USER.md"))
+	if err != nil || !strings.Contains(string(user), "Sam") {
+		t.Errorf("expected persona files, got %q, %v", user, err)
+	}
+
+	if _, err := RunNonInteractive(answers, false, zap.NewNop()); err == nil || !strings.Contains(err.Error(), "--overwrite") {
+		t.Errorf("expected existing config to be kept, got %v", err)
+	}
+	answers.Model = "gpt-5.1"
+	if _, err := RunNonInteractive(answers, true, zap.NewNop()); err != nil {
+		t.Fatalf("overwrite failed: %v", err)
+	}
+	config, _ = os.ReadFile(result.ConfigPath)
+	if !strings.Contains(string(config), `model: "gpt-5.1"`) {
+		t.Error("expected --overwrite to replace the config")
+	}
+}
+
+func TestRunNonInteractiveProfile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	answers := Answers{Provider: "ollama", Model: "llama3", Profile: "work"}
+
+	result, err := RunNonInteractive(answers, false, zap.NewNop())
+	if err != nil {
+		t.Fatalf("RunNonInteractive failed: %v", err)
+	}
+	profileDir := filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "myrai", "profiles", "work")
+	if result.ConfigPath != filepath.Join(profileDir, "myrai.yaml") || result.DataDir != filepath.Join(profileDir, "data") {
+		t.Errorf("expected setup inside the profile, got %+v", result)
+	}
+	if _, err := os.Stat(result.ConfigPath); err != nil {
+		t.Errorf("expected the profile's config written: %v", err)
+	}
+
+	answers.Profile = "../oops"
+	if _, err := RunNonInteractive(answers, false, zap.NewNop()); err == nil {
+		t.Error("expected an invalid profile name to fail")
+	}
+}
+
+func TestAnswersValidation(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	tests := []struct {
+		name    string
+		answers Answers
+		wantErr string
+	}{
+		{"no provider", Answers{Model: "m"}, "provider is required"},
+		{"unknown provider", Answers{Provider: "nope", Model: "m"}, "unknown provider"},
+		{"no model", Answers{Provider: "openai", APIKey: "k"}, "model is required"},
+		{"unset key variable", Answers{Provider: "openai", Model: "m", APIKeyEnv: "TEST_UNSET_KEY"}, "TEST_UNSET_KEY is not set"},
+		{"no key", Answers{Provider: "anthropic", Model: "m"}, "ANTHROPIC_API_KEY"},
+		{"local without key", Answers{Provider: "ollama", Model: "llama3"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.answers.wizardConfig()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadAnswers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.yaml")
+	os.WriteFile(path, []byte("provider: groq\nmodel: llama-3.3-70b\napi_key_env: GROQ_KEY\nexpertise: [Go, Kubernetes]\n"), 0644)
+
+	answers, err := LoadAnswers(path)
+	if err != nil {
+		t.Fatalf("LoadAnswers failed: %v", err)
+	}
+	if answers.Provider != "groq" || answers.APIKeyEnv != "GROQ_KEY" || len(answers.Expertise) != 2 {
+		t.Errorf("unexpected answers: %+v", answers)
+	}
+}
@@ -0,0 +1,117 @@
+package onboarding
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/doctor"
+)
+
+// checkProvider sends the provider a one-token request; replaced in tests
+var checkProvider = doctor.CheckProvider
+
+// providerCheckTimeout bounds the live check, which local models may need
+// a while to answer while they load
+const providerCheckTimeout = 45 * time.Second
+
+// providerConfig is the provider setup is about to write
+func (wc *WizardConfig) providerConfig() config.Provider {
+	defaults := providerDefaults[wc.LLMProvider]
+	baseURL := defaults.baseURL
+	if wc.BaseURL != "" {
+		baseURL = wc.BaseURL
+	}
+	return config.Provider{
+		APIKey:    wc.APIKey,
+		BaseURL:   baseURL,
+		Model:     wc.DefaultModel,
+		Timeout:   defaults.timeout,
+		MaxTokens: 4096,
+	}
+}
+
+// CheckCredentials makes a live call with the provider, key and model, so
+// a typo shows up now rather than at the first chat. The error explains
+// what to fix.
+func (wc *WizardConfig) CheckCredentials(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, providerCheckTimeout)
+	defer cancel()
+	provider := wc.providerConfig()
+	result := checkProvider(ctx, wc.LLMProvider, provider)
+	if result.Status == doctor.OK {
+		return nil
+	}
+	return fmt.Errorf("%s\n%s", result.Message, explainProviderError(wc.LLMProvider, provider, result.Message))
+}
+
+// explainProviderError turns a failed check into what to do about it
+func explainProviderError(name string, provider config.Provider, message string) string {
+	msg := strings.ToLower(message)
+	switch {
+	case strings.Contains(msg, "status 401"), strings.Contains(msg, "status 403"),
+		strings.Contains(msg, "invalid api key"), strings.Contains(msg, "incorrect api key"):
+		return fmt.Sprintf("The API key was rejected. Check it was copied whole and that it is a %s key.", name)
+	case strings.Contains(msg, "status 404"), strings.Contains(msg, "model_not_found"),
+		strings.Contains(msg, "does not exist"):
+		return fmt.Sprintf("The model %q wasn't found. Check its name, or that your account has access to it.", provider.Model)
+	case strings.Contains(msg, "status 402"), strings.Contains(msg, "status 429"),
+		strings.Contains(msg, "quota"), strings.Contains(msg, "credit"):
+		return fmt.Sprintf("The key works but the account is out of credit or rate limited. Check billing with %s.", name)
+	case strings.Contains(msg, "no such host"), strings.Contains(msg, "connection refused"),
+		strings.Contains(msg, "deadline exceeded"), strings.Contains(msg, "timeout"):
+		if localProviders[name] {
+			return fmt.Sprintf("Couldn't reach %s. Is the %s server running?", provider.BaseURL, name)
+		}
+		return fmt.Sprintf("Couldn't reach %s. Check the address and your internet connection.", provider.BaseURL)
+	}
+	return "Check the API key, model and base URL."
+}
+
+// validateProvider checks the credentials just entered, offering to fix
+// them when the check fails. It returns an error only if the user quits.
+func (w *Wizard) validateProvider() error {
+	for {
+		spinner := w.startSpinner(fmt.Sprintf("🔌 Checking %s/%s...", w.config.LLMProvider, w.config.DefaultModel))
+		err := w.config.CheckCredentials(context.Background())
+		w.stopSpinner(spinner)
+		if err == nil {
+			fmt.Println("✓ The provider answered")
+			time.Sleep(500 * time.Millisecond)
+			return nil
+		}
+
+		fmt.Println()
+		fmt.Printf("❌ %v\n", err)
+		fmt.Println()
+		fmt.Println("  1. Re-enter the API key")
+		fmt.Println("  2. Pick another provider or model")
+		fmt.Println("  3. Continue anyway")
+		fmt.Println("  4. Quit setup")
+		fmt.Print("\nSelect (1-4) [default: 1]: ")
+		choice, readErr := w.reader.ReadString('\n')
+		if readErr != nil && choice == "" {
+			return fmt.Errorf("setup cancelled")
+		}
+
+		switch strings.TrimSpace(choice) {
+		case "2":
+			if err := w.setupAIConfiguration(); err != nil {
+				return err
+			}
+		case "3":
+			fmt.Println("⚠️  Continuing; run 'myrai doctor' once it's fixed")
+			return nil
+		case "4":
+			return fmt.Errorf("setup cancelled")
+		default:
+			fmt.Printf("Enter your %s API key: ", w.config.LLMProvider)
+			key, _ := w.reader.ReadString('\n')
+			if key = strings.TrimSpace(key); key != "" {
+				w.config.APIKey = key
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package onboarding
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/doctor"
+	"go.uber.org/zap"
+)
+
+// stubCheck accepts only the key "good"
+func stubCheck(t *testing.T) *[]config.Provider {
+	var seen []config.Provider
+	prev := checkProvider
+	checkProvider = func(ctx context.Context, name string, provider config.Provider) doctor.Result {
+		seen = append(seen, provider)
+		if provider.APIKey == "good" {
+			return doctor.Result{Status: doctor.OK}
+		}
+		return doctor.Result{Status: doctor.Fail, Message: "openai/gpt-5.2: API error (status 401): invalid key"}
+	}
+	t.Cleanup(func() { checkProvider = prev })
+	return &seen
+}
+
+func TestCheckCredentials(t *testing.T) {
+	seen := stubCheck(t)
+	wc := &WizardConfig{LLMProvider: "openai", DefaultModel: "gpt-5.2", APIKey: "typo"}
+
+	err := wc.CheckCredentials(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "API key was rejected") {
+		t.Errorf("expected an explained rejection, got %v", err)
+	}
+	if got := (*seen)[0]; got.BaseURL != "https://api.openai.com/v1" || got.Model != "gpt-5.2" {
+		t.Errorf("expected the provider's defaults to be checked, got %+v", got)
+	}
+
+	wc.APIKey = "good"
+	if err := wc.CheckCredentials(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateProvider(t *testing.T) {
+	stubCheck(t)
+	newWizard := func(input string) *Wizard {
+		return &Wizard{
+			reader: bufio.NewReader(strings.NewReader(input)),
+			config: &WizardConfig{LLMProvider: "openai", DefaultModel: "gpt-5.2", APIKey: "typo"},
+		}
+	}
+
+	w := newWizard("1\ngood\n")
+	if err := w.validateProvider(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.config.APIKey != "good" {
+		t.Errorf("expected the re-entered key, got %q", w.config.APIKey)
+	}
+
+	if err := newWizard("3\n").validateProvider(); err != nil {
+		t.Errorf("continuing anyway shouldn't fail, got %v", err)
+	}
+	if err := newWizard("4\n").validateProvider(); err == nil {
+		t.Error("expected quitting to cancel setup")
+	}
+	if err := newWizard("").validateProvider(); err == nil {
+		t.Error("expected end of input to cancel setup rather than loop")
+	}
+}
+
+func TestExplainProviderError(t *testing.T) {
+	provider := config.Provider{Model: "gpt-5.2", BaseURL: "http://localhost:11434/v1"}
+	tests := []struct {
+		name, message, want string
+	}{
+		{"openai", "API error (status 404): model_not_found", `model "gpt-5.2" wasn't found`},
+		{"openai", "API error (status 429): quota exceeded", "out of credit"},
+		{"ollama", "dial tcp: connection refused", "Is the ollama server running?"},
+		{"openai", "something else", "Check the API key, model and base URL."},
+	}
+	for _, tt := range tests {
+		if got := explainProviderError(tt.name, provider, tt.message); !strings.Contains(got, tt.want) {
+			t.Errorf("explainProviderError(%q) = %q, want it to contain %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestRunNonInteractiveCheck(t *testing.T) {
+	stubCheck(t)
+	dir := t.TempDir()
+	answers := Answers{
+		Provider:  "openai",
+		Model:     "gpt-5.2",
+		APIKey:    "typo",
+		DataDir:   filepath.Join(dir, "data"),
+		ConfigDir: filepath.Join(dir, "config"),
+		Check:     true,
+	}
+
+	if _, err := RunNonInteractive(answers, false, zap.NewNop()); err == nil {
+		t.Fatal("expected the failed check to stop setup")
+	}
+	if _, err := os.Stat(answers.ConfigDir); !os.IsNotExist(err) {
+		t.Errorf("expected nothing written after a failed check, got %v", err)
+	}
+
+	answers.APIKey = "good"
+	if _, err := RunNonInteractive(answers, false, zap.NewNop()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/config"
 	"github.com/gmsas95/myrai-cli/internal/persona"
 	"go.uber.org/zap"
 )
@@ -70,6 +71,9 @@ func (w *Wizard) Run() error {
 	if err := w.setupAIConfiguration(); err != nil {
 		return fmt.Errorf("AI configuration failed: %w", err)
 	}
+	if err := w.validateProvider(); err != nil {
+		return err
+	}
 
 	// Step 4: Optional integrations
 	if err := w.setupIntegrations(); err != nil {
@@ -135,29 +139,13 @@ func (w *Wizard) setupWorkspace() error {
 	fmt.Println("╚════════════════════════════════════════════════════════════════╝")
 	fmt.Println()
 
-	// Get default workspace path (data directory)
-	// Try XDG_DATA_HOME first, then fall back to ~/.local/share/myrai
-	defaultWorkspace := os.Getenv("XDG_DATA_HOME")
-	if defaultWorkspace == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			home = "."
-		}
-		defaultWorkspace = filepath.Join(home, ".local", "share", "myrai")
-	} else {
-		defaultWorkspace = filepath.Join(defaultWorkspace, "myrai")
-	}
-
-	// Get config directory for storing config and .env files
-	configDir := os.Getenv("XDG_CONFIG_HOME")
-	if configDir == "" {
-		home, _ := os.UserHomeDir()
-		if home == "" {
-			home = "."
-		}
-		configDir = filepath.Join(home, ".config", "myrai")
-	} else {
-		configDir = filepath.Join(configDir, "myrai")
+	// Get default workspace path (data directory) and the config directory
+	// for storing config and .env files, both inside the profile if one is
+	// selected
+	defaultWorkspace := config.DefaultDataDir()
+	configDir := config.DefaultConfigDir()
+	if profile := config.ActiveProfile(); profile != "" {
+		fmt.Printf("Setting up the %q profile.\n\n", profile)
 	}
 
 	// Store configDir in wizard for later use
@@ -173,22 +161,26 @@ func (w *Wizard) setupWorkspace() error {
 
 	w.workspace = workspace
 
-	// Create workspace
+	if err := createWorkspace(workspace); err != nil {
+		return err
+	}
+
+	fmt.Println("✓ Workspace created")
+	time.Sleep(500 * time.Millisecond)
+
+	return nil
+}
+
+// createWorkspace creates the data directory and its subdirectories
+func createWorkspace(workspace string) error {
 	if err := os.MkdirAll(workspace, 0755); err != nil {
 		return fmt.Errorf("failed to create workspace: %w", err)
 	}
-
-	// Create subdirectories
-	subdirs := []string{"projects", "diary", "memory"}
-	for _, dir := range subdirs {
+	for _, dir := range []string{"projects", "diary", "memory"} {
 		if err := os.MkdirAll(filepath.Join(workspace, dir), 0755); err != nil {
 			return err
 		}
 	}
-
-	fmt.Println("✓ Workspace created")
-	time.Sleep(500 * time.Millisecond)
-
 	return nil
 }
 
@@ -1261,6 +1253,60 @@ func (w *Wizard) setupIntegrations() error {
 	return nil
 }
 
+// providerDefaults are the endpoints and timeouts of the providers setup
+// knows
+var providerDefaults = map[string]struct {
+	baseURL string
+	timeout int
+}{
+	"openai":      {"https://api.openai.com/v1", 60},
+	"anthropic":   {"https://api.anthropic.com/v1", 60},
+	"google":      {"https://generativelanguage.googleapis.com/v1beta", 60},
+	"kimi":        {"https://api.moonshot.cn/v1", 60},
+	"deepseek":    {"https://api.deepseek.com/v1", 60},
+	"groq":        {"https://api.groq.com/openai/v1", 60},
+	"mistral":     {"https://api.mistral.ai/v1", 60},
+	"together":    {"https://api.together.xyz/v1", 60},
+	"cerebras":    {"https://api.cerebras.ai/v1", 60},
+	"xai":         {"https://api.x.ai/v1", 60},
+	"perplexity":  {"https://api.perplexity.ai/v1", 60},
+	"fireworks":   {"https://api.fireworks.ai/inference/v1", 60},
+	"novita":      {"https://api.novita.ai/v3/openai", 60},
+	"siliconflow": {"https://api.siliconflow.cn/v1", 60},
+	"zhipu":       {"https://open.bigmodel.cn/api/paas/v4", 60},
+	"moonshot":    {"https://api.moonshot.cn/v1", 60},
+	"openrouter":  {"https://openrouter.ai/api/v1", 60},
+	"ollama":      {"http://localhost:11434/v1", 120},
+	"localai":     {"http://localhost:8080/v1", 120},
+	"vllm":        {"http://localhost:8000/v1", 120},
+	"azure":       {"", 60},
+}
+
+// providerKeyEnv names the variable each provider's API key is kept in
+var providerKeyEnv = map[string]string{
+	"openai":      "OPENAI_API_KEY",
+	"anthropic":   "ANTHROPIC_API_KEY",
+	"google":      "GOOGLE_API_KEY",
+	"kimi":        "KIMI_API_KEY",
+	"deepseek":    "DEEPSEEK_API_KEY",
+	"groq":        "GROQ_API_KEY",
+	"mistral":     "MISTRAL_API_KEY",
+	"together":    "TOGETHER_API_KEY",
+	"cerebras":    "CEREBRAS_API_KEY",
+	"xai":         "XAI_API_KEY",
+	"perplexity":  "PERPLEXITY_API_KEY",
+	"fireworks":   "FIREWORKS_API_KEY",
+	"novita":      "NOVITA_API_KEY",
+	"siliconflow": "SILICONFLOW_API_KEY",
+	"zhipu":       "ZHIPU_API_KEY",
+	"moonshot":    "MOONSHOT_API_KEY",
+	"openrouter":  "OPENROUTER_API_KEY",
+	"ollama":      "OLLAMA_API_KEY",
+	"localai":     "LOCALAI_API_KEY",
+	"vllm":        "VLLM_API_KEY",
+	"azure":       "AZURE_OPENAI_API_KEY",
+}
+
 func (w *Wizard) createConfiguration() error {
 	// Create config directory
 	if err := os.MkdirAll(w.configDir, 0755); err != nil {
@@ -1269,37 +1315,9 @@ func (w *Wizard) createConfiguration() error {
 
 	configPath := filepath.Join(w.configDir, "myrai.yaml")
 
-	// Provider configurations
-	providerConfigs := map[string]struct {
-		baseURL string
-		timeout int
-	}{
-		"openai":      {"https://api.openai.com/v1", 60},
-		"anthropic":   {"https://api.anthropic.com/v1", 60},
-		"google":      {"https://generativelanguage.googleapis.com/v1beta", 60},
-		"kimi":        {"https://api.moonshot.cn/v1", 60},
-		"deepseek":    {"https://api.deepseek.com/v1", 60},
-		"groq":        {"https://api.groq.com/openai/v1", 60},
-		"mistral":     {"https://api.mistral.ai/v1", 60},
-		"together":    {"https://api.together.xyz/v1", 60},
-		"cerebras":    {"https://api.cerebras.ai/v1", 60},
-		"xai":         {"https://api.x.ai/v1", 60},
-		"perplexity":  {"https://api.perplexity.ai/v1", 60},
-		"fireworks":   {"https://api.fireworks.ai/inference/v1", 60},
-		"novita":      {"https://api.novita.ai/v3/openai", 60},
-		"siliconflow": {"https://api.siliconflow.cn/v1", 60},
-		"zhipu":       {"https://open.bigmodel.cn/api/paas/v4", 60},
-		"moonshot":    {"https://api.moonshot.cn/v1", 60},
-		"openrouter":  {"https://openrouter.ai/api/v1", 60},
-		"ollama":      {"http://localhost:11434/v1", 120},
-		"localai":     {"http://localhost:8080/v1", 120},
-		"vllm":        {"http://localhost:8000/v1", 120},
-		"azure":       {"", 60},
-	}
-
-	cfg, ok := providerConfigs[w.config.LLMProvider]
+	cfg, ok := providerDefaults[w.config.LLMProvider]
 	if !ok {
-		cfg = providerConfigs["kimi"]
+		cfg = providerDefaults["kimi"]
 	}
 
 	baseURL := cfg.baseURL
@@ -1335,16 +1353,16 @@ channels:
     bot_token: ""  # Loaded from environment variable TELEGRAM_BOT_TOKEN (see .env file)
     allow_list: []
 
-search:
-  enabled: %v
-  provider: "%s"
-  api_key: ""  # Loaded from environment variable MYRAI_SEARCH_API_KEY (see .env file)
-  max_results: 5
-  timeout_seconds: 30
-
-vision:
-  enabled: %v
-  vision_model: "%s"
+skills:
+  search:
+    enabled: %v
+    provider: "%s"
+    api_key: ""  # Loaded from environment variable MYRAI_SKILLS_SEARCH_API_KEY (see .env file)
+    max_results: 5
+    timeout_seconds: 30
+  vision:
+    enabled: %v
+    vision_model: "%s"
 
 tools:
   enabled:
@@ -1367,31 +1385,7 @@ security:
 	// Create .env file in config directory
 	envPath := filepath.Join(w.configDir, ".env")
 
-	envKeyMap := map[string]string{
-		"openai":      "OPENAI_API_KEY",
-		"anthropic":   "ANTHROPIC_API_KEY",
-		"google":      "GOOGLE_API_KEY",
-		"kimi":        "KIMI_API_KEY",
-		"deepseek":    "DEEPSEEK_API_KEY",
-		"groq":        "GROQ_API_KEY",
-		"mistral":     "MISTRAL_API_KEY",
-		"together":    "TOGETHER_API_KEY",
-		"cerebras":    "CEREBRAS_API_KEY",
-		"xai":         "XAI_API_KEY",
-		"perplexity":  "PERPLEXITY_API_KEY",
-		"fireworks":   "FIREWORKS_API_KEY",
-		"novita":      "NOVITA_API_KEY",
-		"siliconflow": "SILICONFLOW_API_KEY",
-		"zhipu":       "ZHIPU_API_KEY",
-		"moonshot":    "MOONSHOT_API_KEY",
-		"openrouter":  "OPENROUTER_API_KEY",
-		"ollama":      "OLLAMA_API_KEY",
-		"localai":     "LOCALAI_API_KEY",
-		"vllm":        "VLLM_API_KEY",
-		"azure":       "AZURE_OPENAI_API_KEY",
-	}
-
-	envKey, ok := envKeyMap[w.config.LLMProvider]
+	envKey, ok := providerKeyEnv[w.config.LLMProvider]
 	if !ok {
 		envKey = "KIMI_API_KEY"
 	}
@@ -1407,9 +1401,9 @@ security:
 	}
 
 	if w.config.SearchProvider != "" && w.config.SearchAPIKey != "" {
-		envContent += fmt.Sprintf("MYRAI_SEARCH_API_KEY=%s\n", w.config.SearchAPIKey)
+		envContent += fmt.Sprintf("MYRAI_SKILLS_SEARCH_API_KEY=%s\n", w.config.SearchAPIKey)
 		if w.config.SearchProvider != "brave" {
-			envContent += fmt.Sprintf("MYRAI_SEARCH_PROVIDER=%s\n", w.config.SearchProvider)
+			envContent += fmt.Sprintf("MYRAI_SKILLS_SEARCH_PROVIDER=%s\n", w.config.SearchProvider)
 		}
 	}
 
@@ -1573,34 +1567,13 @@ func CheckFirstRun() bool {
 	return os.IsNotExist(err)
 }
 
-// GetWorkspacePath returns the default workspace (data) path
+// GetWorkspacePath returns the default workspace (data) path of the
+// active profile
 func GetWorkspacePath() string {
-	// Try XDG_DATA_HOME first, then fall back to ~/.local/share/myrai
-	workspace := os.Getenv("XDG_DATA_HOME")
-	if workspace == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			home = "."
-		}
-		workspace = filepath.Join(home, ".local", "share", "myrai")
-	} else {
-		workspace = filepath.Join(workspace, "myrai")
-	}
-	return workspace
+	return config.DefaultDataDir()
 }
 
-// GetConfigPath returns the default config path
+// GetConfigPath returns the default config path of the active profile
 func GetConfigPath() string {
-	// Try XDG_CONFIG_HOME first, then fall back to ~/.config/myrai
-	configDir := os.Getenv("XDG_CONFIG_HOME")
-	if configDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			home = "."
-		}
-		configDir = filepath.Join(home, ".config", "myrai")
-	} else {
-		configDir = filepath.Join(configDir, "myrai")
-	}
-	return filepath.Join(configDir, "myrai.yaml")
+	return filepath.Join(config.DefaultConfigDir(), "myrai.yaml")
 }
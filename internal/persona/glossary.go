@@ -0,0 +1,243 @@
+package persona
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Glossary term sources
+const (
+	GlossaryManual       = "manual"
+	GlossaryConversation = "conversation"
+	GlossaryDocument     = "document"
+)
+
+const (
+	// maxGlossaryTerms caps a project's glossary; the oldest learned terms
+	// make room for new ones, manual terms are never dropped
+	maxGlossaryTerms = 100
+	// maxDefinitionLength caps a learned definition
+	maxDefinitionLength = 200
+)
+
+// GlossaryTerm is a project-specific term and what it means
+type GlossaryTerm struct {
+	Term       string    `json:"term"`
+	Definition string    `json:"definition"`
+	Source     string    `json:"source"` // manual, conversation, document
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// glossaryPatterns recognise sentences that define a term. Each has a
+// "term" and a "def" group.
+var glossaryPatterns = []*regexp.Regexp{
+	// "Falcon is our billing service", "QA-2 is the team's staging cluster"
+	regexp.MustCompile(`^(?P<term>"[^"]{2,40}"|[A-Z][\w\-]*(?: [A-Z][\w\-]*){0,3}) (?:is|are) (?P<def>(?:our|the team's|the company's|an internal|the internal) .+)$`),
+	// "SLO stands for service level objective"
+	regexp.MustCompile(`^(?P<term>[A-Z][A-Za-z0-9]{1,9}) (?:stands for|is short for) (?P<def>.+)$`),
+	// "by the tower we mean the main monolith"
+	regexp.MustCompile(`(?i)^by (?P<term>"[^"]{2,40}"|\S+(?: \S+){0,3}),? (?:i|we) mean (?P<def>.+)$`),
+	// "when we say a drop, we mean a release to the app stores"
+	regexp.MustCompile(`(?i)^when (?:i|we) say (?P<term>"[^"]{2,40}"|\S+(?: \S+){0,3}),? (?:i|we) mean (?P<def>.+)$`),
+	// "\"Green build\" means all checks passed", "Hotfix Friday means ..."
+	regexp.MustCompile(`^(?P<term>"[^"]{2,40}"|[A-Z][\w\-]*(?: [A-Z][\w\-]*){0,3}) means (?P<def>.+)$`),
+}
+
+// glossaryLeadIns are dropped from the start of a sentence before matching
+var glossaryLeadIns = []string{"so ", "fyi ", "fyi, ", "note that ", "just so you know, ", "btw ", "btw, ", "also, ", "also "}
+
+// notTerms are capitalised words that start ordinary sentences
+var notTerms = map[string]bool{
+	"it": true, "this": true, "that": true, "these": true, "those": true, "there": true,
+	"he": true, "she": true, "they": true, "we": true, "i": true, "you": true,
+	"what": true, "which": true, "who": true, "the": true, "a": true, "an": true,
+	"here": true, "today": true, "tomorrow": true, "everything": true, "nothing": true,
+}
+
+var sentenceEnd = regexp.MustCompile(`[.!?;]+(?:\s+|$)|\n+`)
+
+// ExtractGlossary finds the terms text defines, such as "Falcon is our
+// billing service" or "SLO stands for service level objective"
+func ExtractGlossary(text string) []GlossaryTerm {
+	var found []GlossaryTerm
+	seen := make(map[string]bool)
+
+	for _, sentence := range sentenceEnd.Split(text, -1) {
+		sentence = strings.TrimSpace(sentence)
+		for _, lead := range glossaryLeadIns {
+			if len(sentence) > len(lead) && strings.EqualFold(sentence[:len(lead)], lead) {
+				sentence = strings.TrimSpace(sentence[len(lead):])
+				break
+			}
+		}
+		for _, re := range glossaryPatterns {
+			m := re.FindStringSubmatch(sentence)
+			if m == nil {
+				continue
+			}
+			term := strings.Trim(m[re.SubexpIndex("term")], `",`)
+			// "when we say a drop" defines "drop"
+			for _, article := range []string{"a ", "an ", "the "} {
+				term = strings.TrimPrefix(term, article)
+			}
+			def := strings.TrimSpace(strings.TrimRight(m[re.SubexpIndex("def")], ",:"))
+			if !validTerm(term) || len(def) < 3 || len(def) > maxDefinitionLength {
+				continue
+			}
+			key := strings.ToLower(term)
+			if !seen[key] {
+				seen[key] = true
+				found = append(found, GlossaryTerm{Term: term, Definition: def})
+			}
+			break
+		}
+	}
+	return found
+}
+
+func validTerm(term string) bool {
+	if len(term) < 2 || len(term) > 40 {
+		return false
+	}
+	first := strings.ToLower(strings.Fields(term)[0])
+	return !notTerms[first]
+}
+
+// SetGlossaryTerm adds or replaces a term in a project's glossary
+func (pm *ProjectManager) SetGlossaryTerm(name, term, definition string) error {
+	term = strings.TrimSpace(term)
+	definition = strings.TrimSpace(definition)
+	if term == "" || definition == "" {
+		return fmt.Errorf("term and definition are required")
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	project, exists := pm.projects[sanitizeProjectName(name)]
+	if !exists {
+		return fmt.Errorf("project '%s' not found", name)
+	}
+
+	if !setGlossaryTerm(project, GlossaryTerm{Term: term, Definition: definition, Source: GlossaryManual}) {
+		return fmt.Errorf("the glossary of '%s' is full (%d terms)", project.Name, maxGlossaryTerms)
+	}
+	return pm.saveGlossary(project)
+}
+
+// RemoveGlossaryTerm removes a term from a project's glossary
+func (pm *ProjectManager) RemoveGlossaryTerm(name, term string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	project, exists := pm.projects[sanitizeProjectName(name)]
+	if !exists {
+		return fmt.Errorf("project '%s' not found", name)
+	}
+
+	for i, t := range project.Glossary {
+		if strings.EqualFold(t.Term, strings.TrimSpace(term)) {
+			project.Glossary = append(project.Glossary[:i], project.Glossary[i+1:]...)
+			return pm.saveGlossary(project)
+		}
+	}
+	return fmt.Errorf("term '%s' is not in the glossary of '%s'", term, project.Name)
+}
+
+// Glossary returns a copy of a project's glossary sorted by term
+func (pm *ProjectManager) Glossary(name string) ([]GlossaryTerm, error) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	project, exists := pm.projects[sanitizeProjectName(name)]
+	if !exists {
+		return nil, fmt.Errorf("project '%s' not found", name)
+	}
+
+	terms := make([]GlossaryTerm, len(project.Glossary))
+	copy(terms, project.Glossary)
+	sort.Slice(terms, func(i, j int) bool {
+		return strings.ToLower(terms[i].Term) < strings.ToLower(terms[j].Term)
+	})
+	return terms, nil
+}
+
+// LearnGlossary adds the terms text defines to a project's glossary.
+// Learned terms never replace ones that were set by hand. It returns the
+// terms that were added or changed.
+func (pm *ProjectManager) LearnGlossary(name, text, source string) ([]GlossaryTerm, error) {
+	found := ExtractGlossary(text)
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	project, exists := pm.projects[sanitizeProjectName(name)]
+	if !exists {
+		return nil, fmt.Errorf("project '%s' not found", name)
+	}
+
+	var learned []GlossaryTerm
+	for _, term := range found {
+		if existing := findGlossaryTerm(project, term.Term); existing != nil {
+			if existing.Source == GlossaryManual || existing.Definition == term.Definition {
+				continue
+			}
+		}
+		term.Source = source
+		if setGlossaryTerm(project, term) {
+			learned = append(learned, term)
+		}
+	}
+	if len(learned) == 0 {
+		return nil, nil
+	}
+	return learned, pm.saveGlossary(project)
+}
+
+func (pm *ProjectManager) saveGlossary(project *Project) error {
+	project.UpdatedAt = time.Now()
+	if err := pm.saveProjectInternal(project); err != nil {
+		return err
+	}
+	return pm.saveIndex()
+}
+
+func findGlossaryTerm(project *Project, term string) *GlossaryTerm {
+	for i := range project.Glossary {
+		if strings.EqualFold(project.Glossary[i].Term, term) {
+			return &project.Glossary[i]
+		}
+	}
+	return nil
+}
+
+// setGlossaryTerm replaces or appends term, dropping the oldest learned
+// term when the glossary is full. It reports false when only manual terms
+// are left to drop.
+func setGlossaryTerm(project *Project, term GlossaryTerm) bool {
+	term.UpdatedAt = time.Now()
+	if existing := findGlossaryTerm(project, term.Term); existing != nil {
+		*existing = term
+		return true
+	}
+	if len(project.Glossary) >= maxGlossaryTerms {
+		oldest := -1
+		for i, t := range project.Glossary {
+			if t.Source != GlossaryManual && (oldest < 0 || t.UpdatedAt.Before(project.Glossary[oldest].UpdatedAt)) {
+				oldest = i
+			}
+		}
+		if oldest < 0 {
+			return false
+		}
+		project.Glossary = append(project.Glossary[:oldest], project.Glossary[oldest+1:]...)
+	}
+	project.Glossary = append(project.Glossary, term)
+	return true
+}
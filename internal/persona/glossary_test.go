@@ -0,0 +1,93 @@
+package persona
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestExtractGlossary(t *testing.T) {
+	text := `So Falcon is our billing service. SLO stands for service level objective!
+When we say a drop, we mean a release to the app stores.
+"Green build" means all checks passed. It is our job to fix it.
+This means nothing. Sarah is my sister. Tomorrow is our deadline.`
+
+	got := ExtractGlossary(text)
+	want := map[string]string{
+		"Falcon":      "our billing service",
+		"SLO":         "service level objective",
+		"drop":        "a release to the app stores",
+		"Green build": "all checks passed",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d terms, got %+v", len(want), got)
+	}
+	for _, term := range got {
+		if want[term.Term] != term.Definition {
+			t.Errorf("Unexpected term %q: %q", term.Term, term.Definition)
+		}
+	}
+}
+
+func TestProjectGlossary(t *testing.T) {
+	pm := NewProjectManager(t.TempDir(), zap.NewNop())
+	if _, err := pm.CreateProject("Payments", "coding", "desc"); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	if err := pm.SetGlossaryTerm("Payments", "Falcon", "the billing service"); err != nil {
+		t.Fatalf("Failed to set term: %v", err)
+	}
+
+	// Learned terms never overwrite manual ones
+	learned, err := pm.LearnGlossary("Payments", "Falcon is our old name for the ledger. PSP stands for payment service provider.", GlossaryDocument)
+	if err != nil {
+		t.Fatalf("Failed to learn: %v", err)
+	}
+	if len(learned) != 1 || learned[0].Term != "PSP" {
+		t.Errorf("Expected only PSP to be learned, got %+v", learned)
+	}
+
+	// Saved with the project
+	reloaded := NewProjectManager(pm.basePath, zap.NewNop())
+	terms, err := reloaded.Glossary("Payments")
+	if err != nil {
+		t.Fatalf("Failed to read glossary: %v", err)
+	}
+	if len(terms) != 2 || terms[0].Definition != "the billing service" || terms[1].Source != GlossaryDocument {
+		t.Errorf("Unexpected glossary: %+v", terms)
+	}
+
+	if err := reloaded.RemoveGlossaryTerm("Payments", "psp"); err != nil {
+		t.Fatalf("Failed to remove term: %v", err)
+	}
+	if err := reloaded.RemoveGlossaryTerm("Payments", "psp"); err == nil {
+		t.Error("Removing a missing term should fail")
+	}
+}
+
+func TestSystemPromptIncludesGlossary(t *testing.T) {
+	pm, err := NewPersonaManager(t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to create persona manager: %v", err)
+	}
+	if pm.LearnGlossary("Falcon is our billing service.", GlossaryConversation) != nil {
+		t.Error("Nothing should be learned without a current project")
+	}
+
+	if _, err := pm.CreateProject("Payments", "coding", ""); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if err := pm.SwitchProject("Payments"); err != nil {
+		t.Fatalf("Failed to switch project: %v", err)
+	}
+	pm.GetSystemPrompt()
+
+	if learned := pm.LearnGlossary("Falcon is our billing service.", GlossaryConversation); len(learned) != 1 {
+		t.Fatalf("Expected one learned term, got %+v", learned)
+	}
+	if prompt := pm.GetSystemPrompt(); !strings.Contains(prompt, "Falcon: our billing service") {
+		t.Errorf("Glossary missing from system prompt:\n%s", prompt)
+	}
+}
@@ -0,0 +1,124 @@
+package persona
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// IdentityFields are the identity fields 'myrai persona set' and the
+// persona wizard change
+var IdentityFields = []string{"name", "personality", "voice", "values", "expertise"}
+
+const (
+	maxIdentityName = 60
+	maxIdentityText = 1000
+	maxIdentityItem = 100
+)
+
+// Validate reports the first field that wouldn't survive being saved as
+// Markdown and parsed back, such as a value spanning lines or a voice that
+// starts like a heading
+func (i *Identity) Validate() error {
+	if strings.TrimSpace(i.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if err := checkIdentityText("name", i.Name, maxIdentityName); err != nil {
+		return err
+	}
+	if err := checkIdentityText("personality", i.Personality, maxIdentityText); err != nil {
+		return err
+	}
+	if err := checkIdentityText("voice", i.Voice, maxIdentityText); err != nil {
+		return err
+	}
+	for field, items := range map[string][]string{"values": i.Values, "expertise": i.Expertise} {
+		for _, item := range items {
+			if strings.TrimSpace(item) == "" {
+				return fmt.Errorf("%s can't have empty items", field)
+			}
+			if err := checkIdentityText(field, item, maxIdentityItem); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkIdentityText checks a single-line field of at most max characters
+func checkIdentityText(field, text string, max int) error {
+	switch {
+	case strings.ContainsAny(text, "\r\n"):
+		return fmt.Errorf("%s must be a single line", field)
+	case len([]rune(text)) > max:
+		return fmt.Errorf("%s is longer than %d characters", field, max)
+	case strings.HasPrefix(text, "#") || strings.HasPrefix(text, "-") || strings.HasPrefix(text, "Name:"):
+		return fmt.Errorf("%s can't start with '#', '-' or 'Name:'", field)
+	}
+	return nil
+}
+
+// Get returns a field as Set takes it, lists comma-separated
+func (i *Identity) Get(field string) (string, error) {
+	switch field {
+	case "name":
+		return i.Name, nil
+	case "personality":
+		return i.Personality, nil
+	case "voice":
+		return i.Voice, nil
+	case "values":
+		return strings.Join(i.Values, ", "), nil
+	case "expertise":
+		return strings.Join(i.Expertise, ", "), nil
+	}
+	return "", fmt.Errorf("unknown field '%s'; use %s", field, strings.Join(IdentityFields, ", "))
+}
+
+// Set replaces a field with text as typed on the command line and checks
+// the result. Lists take comma-separated items; "" clears any field but the
+// name. The identity is left unchanged if the result isn't valid.
+func (i *Identity) Set(field, text string) error {
+	updated := i.Clone()
+	text = strings.TrimSpace(text)
+
+	switch field {
+	case "name":
+		updated.Name = text
+	case "personality":
+		updated.Personality = text
+	case "voice":
+		updated.Voice = text
+	case "values":
+		updated.Values = splitIdentityList(text)
+	case "expertise":
+		updated.Expertise = splitIdentityList(text)
+	default:
+		return fmt.Errorf("unknown field '%s'; use %s", field, strings.Join(IdentityFields, ", "))
+	}
+
+	if err := updated.Validate(); err != nil {
+		return err
+	}
+	*i = *updated
+	return nil
+}
+
+// Clone returns a copy of the identity
+func (i *Identity) Clone() *Identity {
+	c := *i
+	c.Values = slices.Clone(i.Values)
+	c.Expertise = slices.Clone(i.Expertise)
+	return &c
+}
+
+// splitIdentityList splits comma-separated items, dropping empty ones
+func splitIdentityList(text string) []string {
+	var items []string
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
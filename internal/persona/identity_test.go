@@ -0,0 +1,58 @@
+package persona
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIdentitySet(t *testing.T) {
+	identity := &Identity{Name: "Myrai", Voice: "Warm"}
+
+	if err := identity.Set("values", "Privacy, , Honesty "); err != nil {
+		t.Fatalf("Failed to set values: %v", err)
+	}
+	if strings.Join(identity.Values, "|") != "Privacy|Honesty" {
+		t.Errorf("Unexpected values: %q", identity.Values)
+	}
+
+	for field, text := range map[string]string{
+		"name":  "",
+		"voice": "## Voice",
+		"mood":  "happy",
+	} {
+		if err := identity.Set(field, text); err == nil {
+			t.Errorf("Expected an error setting %s to %q", field, text)
+		}
+	}
+	if identity.Name != "Myrai" || identity.Voice != "Warm" {
+		t.Errorf("A failed Set should leave the identity alone, got %+v", identity)
+	}
+
+	if err := identity.Set("voice", ""); err != nil || identity.Voice != "" {
+		t.Errorf("Expected voice to be cleared, got %q, %v", identity.Voice, err)
+	}
+
+	// What Set accepts survives a round trip through Markdown
+	identity.Set("personality", "Curious: asks before assuming")
+	parsed := parseIdentity(identity.String())
+	if parsed.Personality != identity.Personality || strings.Join(parsed.Values, "|") != "Privacy|Honesty" {
+		t.Errorf("Round trip changed the identity: %+v", parsed)
+	}
+}
+
+func TestIdentityValidate(t *testing.T) {
+	if err := (&Identity{Name: "Myrai", Expertise: []string{"Go"}}).Validate(); err != nil {
+		t.Errorf("Expected a valid identity, got %v", err)
+	}
+	invalid := []*Identity{
+		{Name: "  "},
+		{Name: "Myrai", Personality: "line one\nline two"},
+		{Name: "Myrai", Values: []string{"- nested"}},
+		{Name: strings.Repeat("x", maxIdentityName+1)},
+	}
+	for _, identity := range invalid {
+		if err := identity.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", identity)
+		}
+	}
+}
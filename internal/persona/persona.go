@@ -18,13 +18,17 @@ type PersonaManager struct {
 	logger        *zap.Logger
 
 	// Core files
-	identity *Identity
+	identity      *Identity // of the current persona
+	persona       string
+	personaPinned bool      // by UsePersona, so switches elsewhere are ignored
+	personaMod    time.Time // of the current persona file as last read or written
 	user     *UserProfile
 	tools    string
 	agents   string
 
 	// Runtime state
 	currentProject *Project
+	projectPinned  bool // by UseProject, so switches elsewhere are ignored
 	projects       *ProjectManager
 	timeAwareness  *TimeAwareness
 
@@ -36,7 +40,7 @@ type PersonaManager struct {
 	enableEvolution bool
 
 	// Caching
-	systemPromptCache string
+	systemPromptCache map[string][]PromptLayer // by persona, without the time layer
 	cacheValid        bool
 	cacheMu           sync.RWMutex
 
@@ -69,6 +73,7 @@ func NewPersonaManager(workspacePath string, logger *zap.Logger) (*PersonaManage
 		workspacePath: workspacePath,
 		logger:        logger,
 		identity:      &Identity{Name: "Myrai"},
+		persona:       DefaultPersona,
 		user: &UserProfile{
 			Preferences: make(map[string]string),
 			CreatedAt:   time.Now(),
@@ -88,6 +93,7 @@ func NewPersonaManager(workspacePath string, logger *zap.Logger) (*PersonaManage
 
 	// Initialize subsystems
 	pm.projects = NewProjectManager(filepath.Join(workspacePath, "projects"), logger)
+	pm.currentProject = pm.projects.GetCurrentProject()
 	pm.timeAwareness = NewTimeAwareness()
 
 	return pm, nil
@@ -98,10 +104,9 @@ func (pm *PersonaManager) Load() error {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
-	// Load IDENTITY.md
-	identityPath := filepath.Join(pm.workspacePath, "IDENTITY.md")
-	if data, err := os.ReadFile(identityPath); err == nil {
-		pm.identity = parseIdentity(string(data))
+	// Load the current persona: IDENTITY.md or personas/<name>.md
+	if !pm.personaPinned {
+		pm.loadCurrentPersona()
 	}
 
 	// Load USER.md
@@ -139,10 +144,10 @@ func (pm *PersonaManager) Save() error {
 
 // saveInternal saves files without locking (caller must hold lock)
 func (pm *PersonaManager) saveInternal() error {
-	// Save IDENTITY.md
-	identityPath := filepath.Join(pm.workspacePath, "IDENTITY.md")
+	// Save the current persona's identity
+	identityPath := pm.IdentityPath(pm.persona)
 	if err := os.WriteFile(identityPath, []byte(pm.identity.String()), 0644); err != nil {
-		return fmt.Errorf("failed to save IDENTITY.md: %w", err)
+		return fmt.Errorf("failed to save %s: %w", filepath.Base(identityPath), err)
 	}
 
 	// Save USER.md
@@ -154,66 +159,108 @@ func (pm *PersonaManager) saveInternal() error {
 	return nil
 }
 
+// Prompt layers, in the order they are assembled
+const (
+	LayerTime     = "time"     // the current date and time
+	LayerIdentity = "identity" // IDENTITY.md or personas/<name>.md
+	LayerUser     = "user"     // USER.md
+	LayerProject  = "project"  // the current project
+	LayerTools    = "tools"    // TOOLS.md
+	LayerAgents   = "agents"   // AGENTS.md
+)
+
+// PromptLayer is one named part of the system prompt
+type PromptLayer struct {
+	Name    string
+	Content string
+}
+
+// JoinLayers assembles layers into a system prompt, skipping empty ones
+func JoinLayers(layers []PromptLayer) string {
+	parts := make([]string, 0, len(layers))
+	for _, layer := range layers {
+		if layer.Content != "" {
+			parts = append(parts, layer.Content)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
 // GetSystemPrompt builds the complete system prompt with all context
 func (pm *PersonaManager) GetSystemPrompt() string {
-	// Build new prompt
+	return pm.SystemPromptFor("")
+}
+
+// SystemPromptFor builds the system prompt answering as the named persona,
+// e.g. the one a channel is bound to. "" means the current persona, and so
+// does a persona that doesn't exist.
+func (pm *PersonaManager) SystemPromptFor(name string) string {
+	return JoinLayers(pm.SystemPromptLayers(name))
+}
+
+// SystemPromptLayers returns the layers SystemPromptFor assembles, in order
+func (pm *PersonaManager) SystemPromptLayers(name string) []PromptLayer {
+	pm.syncProject()
+	pm.syncPersona()
+
+	name = sanitizeProjectName(name)
 	pm.mu.RLock()
-	var parts []string
+	if name == "" {
+		name = pm.persona
+	}
 
 	// Time awareness context (ALWAYS fresh, never cached)
-	parts = append(parts, pm.timeAwareness.GetContext())
+	timeLayer := PromptLayer{Name: LayerTime, Content: pm.timeAwareness.GetContext()}
 
 	// Check cache for the rest
 	pm.cacheMu.RLock()
-	if pm.cacheValid && pm.systemPromptCache != "" {
-		cached := pm.systemPromptCache
+	if cached, ok := pm.systemPromptCache[name]; pm.cacheValid && ok {
 		pm.cacheMu.RUnlock()
 		pm.mu.RUnlock()
-		// Prepend fresh time context to cached content
-		return parts[0] + "\n\n" + cached
+		return append([]PromptLayer{timeLayer}, cached...)
 	}
 	pm.cacheMu.RUnlock()
 
 	// Identity context
-	parts = append(parts, pm.getIdentityContext())
-
-	// User profile context
-	parts = append(parts, pm.getUserContext())
-
-	// Current project context
-	if pm.currentProject != nil {
-		parts = append(parts, pm.getProjectContext())
+	identity := pm.identity
+	if name != pm.persona {
+		other, err := pm.PersonaIdentity(name)
+		if err != nil {
+			pm.logger.Warn("Unknown persona, using the current one", zap.String("persona", name), zap.Error(err))
+		} else {
+			identity = other
+		}
+	}
+	layers := []PromptLayer{
+		{Name: LayerIdentity, Content: getIdentityContext(identity)},
+		{Name: LayerUser, Content: pm.getUserContext()},
+		{Name: LayerProject, Content: pm.getProjectContext()},
 	}
-
-	// Tools context
 	if pm.tools != "" {
-		parts = append(parts, "## Available Tools\n"+pm.tools)
+		layers = append(layers, PromptLayer{Name: LayerTools, Content: "## Available Tools\n" + pm.tools})
 	}
-
-	// Agents guidelines
 	if pm.agents != "" {
-		parts = append(parts, pm.agents)
+		layers = append(layers, PromptLayer{Name: LayerAgents, Content: pm.agents})
 	}
 	pm.mu.RUnlock()
 
-	// Join all parts except time (which is parts[0])
-	cachedParts := strings.Join(parts[1:], "\n\n")
-
-	// Cache only the non-time-sensitive parts
+	// Cache only the non-time-sensitive layers
 	pm.cacheMu.Lock()
-	pm.systemPromptCache = cachedParts
+	if !pm.cacheValid || pm.systemPromptCache == nil {
+		pm.systemPromptCache = make(map[string][]PromptLayer)
+	}
+	pm.systemPromptCache[name] = layers
 	pm.cacheValid = true
 	pm.cacheMu.Unlock()
 
-	// Return full prompt with fresh time context
-	return parts[0] + "\n\n" + cachedParts
+	return append([]PromptLayer{timeLayer}, layers...)
 }
 
 // InvalidateCache invalidates the system prompt cache
 func (pm *PersonaManager) InvalidateCache() {
 	pm.cacheMu.Lock()
 	pm.cacheValid = false
-	pm.systemPromptCache = ""
+	pm.systemPromptCache = nil
 	pm.cacheMu.Unlock()
 }
 
@@ -252,11 +299,50 @@ func (pm *PersonaManager) UpdateUserPreference(key, value string) error {
 
 // GetCurrentProject returns the current active project
 func (pm *PersonaManager) GetCurrentProject() *Project {
+	pm.syncProject()
 	pm.mu.RLock()
 	defer pm.mu.RUnlock()
 	return pm.currentProject
 }
 
+// UseProject makes name the current project of this process only, as for a
+// one-shot 'myrai --cli --project' run. The saved current project is left
+// alone, and switches made elsewhere are ignored from then on.
+func (pm *PersonaManager) UseProject(name string) error {
+	project, err := pm.projects.GetProject(name)
+	if err != nil {
+		return err
+	}
+	if project.IsArchived {
+		return fmt.Errorf("project '%s' is %w", name, ErrArchived)
+	}
+
+	pm.mu.Lock()
+	pm.currentProject = project
+	pm.projectPinned = true
+	pm.mu.Unlock()
+
+	pm.InvalidateCache()
+	return nil
+}
+
+// syncProject follows a project switch made by another process, such as
+// 'myrai project switch' while the gateway runs
+func (pm *PersonaManager) syncProject() {
+	pm.mu.RLock()
+	pinned := pm.projectPinned
+	pm.mu.RUnlock()
+	if pinned || pm.projects == nil || !pm.projects.Refresh() {
+		return
+	}
+
+	current := pm.projects.GetCurrentProject()
+	pm.mu.Lock()
+	pm.currentProject = current
+	pm.mu.Unlock()
+	pm.InvalidateCache()
+}
+
 // SwitchProject switches to a different project
 func (pm *PersonaManager) SwitchProject(name string) error {
 	project, err := pm.projects.LoadProject(name)
@@ -282,9 +368,42 @@ func (pm *PersonaManager) ListProjects() ([]*Project, error) {
 	return pm.projects.ListProjects()
 }
 
-// ArchiveProject archives a project
+// ArchiveProject archives a project. If it was the current one, the next
+// most recently used becomes current.
 func (pm *PersonaManager) ArchiveProject(name string) error {
-	return pm.projects.ArchiveProjectByName(name)
+	if err := pm.projects.ArchiveProjectByName(name); err != nil {
+		return err
+	}
+
+	pm.mu.Lock()
+	if pm.currentProject != nil && pm.currentProject.IsArchived {
+		pm.currentProject = pm.projects.GetCurrentProject()
+	}
+	pm.mu.Unlock()
+
+	pm.InvalidateCache()
+	return nil
+}
+
+// UnarchiveProject makes an archived project active again, without
+// switching to it
+func (pm *PersonaManager) UnarchiveProject(name string) error {
+	return pm.projects.UnarchiveProjectByName(name)
+}
+
+// RenameProject renames a project, keeping its memories
+func (pm *PersonaManager) RenameProject(name, newName string) (*Project, error) {
+	project, err := pm.projects.RenameProject(name, newName)
+	if err != nil {
+		return nil, err
+	}
+	pm.InvalidateCache()
+	return project, nil
+}
+
+// DuplicateProject creates newName with the settings of an existing project
+func (pm *PersonaManager) DuplicateProject(name, newName string) (*Project, error) {
+	return pm.projects.DuplicateProject(name, newName)
 }
 
 // DeleteProject deletes a project
@@ -292,6 +411,58 @@ func (pm *PersonaManager) DeleteProject(name string) error {
 	return pm.projects.DeleteProjectByName(name)
 }
 
+// Glossary returns a project's glossary
+func (pm *PersonaManager) Glossary(name string) ([]GlossaryTerm, error) {
+	return pm.projects.Glossary(name)
+}
+
+// SetGlossaryTerm adds or replaces a term in a project's glossary
+func (pm *PersonaManager) SetGlossaryTerm(name, term, definition string) error {
+	if err := pm.projects.SetGlossaryTerm(name, term, definition); err != nil {
+		return err
+	}
+	pm.InvalidateCache()
+	return nil
+}
+
+// RemoveGlossaryTerm removes a term from a project's glossary
+func (pm *PersonaManager) RemoveGlossaryTerm(name, term string) error {
+	if err := pm.projects.RemoveGlossaryTerm(name, term); err != nil {
+		return err
+	}
+	pm.InvalidateCache()
+	return nil
+}
+
+// ToolProfile returns a project's tool profile, nil if it has none
+func (pm *PersonaManager) ToolProfile(name string) (*ToolProfile, error) {
+	return pm.projects.ToolProfile(name)
+}
+
+// SetToolProfile replaces a project's tool profile; an empty one removes it
+func (pm *PersonaManager) SetToolProfile(name string, profile *ToolProfile) error {
+	return pm.projects.SetToolProfile(name, profile)
+}
+
+// LearnGlossary adds the terms text defines to the current project's
+// glossary. source is GlossaryConversation or GlossaryDocument.
+func (pm *PersonaManager) LearnGlossary(text, source string) []GlossaryTerm {
+	project := pm.GetCurrentProject()
+	if project == nil {
+		return nil
+	}
+	learned, err := pm.projects.LearnGlossary(project.Name, text, source)
+	if err != nil {
+		pm.logger.Warn("Failed to update project glossary", zap.String("project", project.Name), zap.Error(err))
+		return nil
+	}
+	if len(learned) > 0 {
+		pm.InvalidateCache()
+		pm.logger.Debug("Learned glossary terms", zap.String("project", project.Name), zap.Int("count", len(learned)))
+	}
+	return learned
+}
+
 // GetWorkspacePath returns the workspace path
 func (pm *PersonaManager) GetWorkspacePath() string {
 	return pm.workspacePath
@@ -303,6 +474,7 @@ func (pm *PersonaManager) ensureWorkspace() error {
 	dirs := []string{
 		pm.workspacePath,
 		filepath.Join(pm.workspacePath, "projects"),
+		filepath.Join(pm.workspacePath, "personas"),
 		filepath.Join(pm.workspacePath, "diary"),
 		filepath.Join(pm.workspacePath, "memory"),
 	}
@@ -316,25 +488,25 @@ func (pm *PersonaManager) ensureWorkspace() error {
 	return nil
 }
 
-func (pm *PersonaManager) getIdentityContext() string {
+func getIdentityContext(identity *Identity) string {
 	var parts []string
 	parts = append(parts, "## Your Identity")
-	parts = append(parts, fmt.Sprintf("You are %s, a helpful AI assistant.", pm.identity.Name))
+	parts = append(parts, fmt.Sprintf("You are %s, a helpful AI assistant.", identity.Name))
 
-	if pm.identity.Personality != "" {
-		parts = append(parts, fmt.Sprintf("Personality: %s", pm.identity.Personality))
+	if identity.Personality != "" {
+		parts = append(parts, fmt.Sprintf("Personality: %s", identity.Personality))
 	}
 
-	if pm.identity.Voice != "" {
-		parts = append(parts, fmt.Sprintf("Communication style: %s", pm.identity.Voice))
+	if identity.Voice != "" {
+		parts = append(parts, fmt.Sprintf("Communication style: %s", identity.Voice))
 	}
 
-	if len(pm.identity.Values) > 0 {
-		parts = append(parts, fmt.Sprintf("Values: %s", strings.Join(pm.identity.Values, ", ")))
+	if len(identity.Values) > 0 {
+		parts = append(parts, fmt.Sprintf("Values: %s", strings.Join(identity.Values, ", ")))
 	}
 
-	if len(pm.identity.Expertise) > 0 {
-		parts = append(parts, fmt.Sprintf("Areas of expertise: %s", strings.Join(pm.identity.Expertise, ", ")))
+	if len(identity.Expertise) > 0 {
+		parts = append(parts, fmt.Sprintf("Areas of expertise: %s", strings.Join(identity.Expertise, ", ")))
 	}
 
 	return strings.Join(parts, "\n")
@@ -384,6 +556,10 @@ func (pm *PersonaManager) getProjectContext() string {
 		parts = append(parts, fmt.Sprintf("Description: %s", pm.currentProject.Description))
 	}
 
+	if prompt := ProjectTypePrompt(pm.currentProject.Type); prompt != "" {
+		parts = append(parts, prompt)
+	}
+
 	if len(pm.currentProject.Context) > 0 {
 		parts = append(parts, "Context:")
 		for k, v := range pm.currentProject.Context {
@@ -391,6 +567,13 @@ func (pm *PersonaManager) getProjectContext() string {
 		}
 	}
 
+	if glossary, _ := pm.projects.Glossary(pm.currentProject.Name); len(glossary) > 0 {
+		parts = append(parts, "Glossary (what these terms mean in this project):")
+		for _, t := range glossary {
+			parts = append(parts, fmt.Sprintf("  - %s: %s", t.Term, t.Definition))
+		}
+	}
+
 	return strings.Join(parts, "\n")
 }
 
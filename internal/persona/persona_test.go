@@ -207,3 +207,55 @@ func TestGetSystemPromptContent(t *testing.T) {
 		}
 	}
 }
+
+func TestPersonaManagerFollowsCurrentProject(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := zap.NewNop()
+
+	gateway, err := NewPersonaManager(tempDir, logger)
+	if err != nil {
+		t.Fatalf("Failed to create PersonaManager: %v", err)
+	}
+	cli, err := NewPersonaManager(tempDir, logger)
+	if err != nil {
+		t.Fatalf("Failed to create PersonaManager: %v", err)
+	}
+
+	if _, err := cli.CreateProject("Web API", "coding", "REST API for user management"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.CreateProject("Blog", "writing", "Posts about gardening"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.SwitchProject("Web API"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A switch made by another process is picked up on the next prompt
+	prompt := gateway.GetSystemPrompt()
+	if !strings.Contains(prompt, "REST API for user management") || !strings.Contains(prompt, "pairing on a software project") {
+		t.Errorf("Expected the Web API project in the prompt, got %s", prompt)
+	}
+	if project := gateway.GetCurrentProject(); project == nil || project.ID() != "web_api" {
+		t.Errorf("Expected web_api current, got %v", project)
+	}
+
+	// A pinned project stays, whatever is switched to elsewhere
+	if err := gateway.UseProject("Blog"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.SwitchProject("Web API"); err != nil {
+		t.Fatal(err)
+	}
+	prompt = gateway.GetSystemPrompt()
+	if !strings.Contains(prompt, "Posts about gardening") || strings.Contains(prompt, "REST API") {
+		t.Errorf("Expected only the Blog project in the prompt, got %s", prompt)
+	}
+	if cli.GetCurrentProject().Name != "Web API" {
+		t.Error("Expected UseProject to leave the saved current project alone")
+	}
+
+	if err := gateway.UseProject("Missing"); err == nil {
+		t.Error("Expected an unknown project to be rejected")
+	}
+}
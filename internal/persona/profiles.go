@@ -0,0 +1,230 @@
+package persona
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// DefaultPersona is the persona kept in IDENTITY.md. Other personas, e.g. a
+// terse one for Telegram or a "work" one, are kept in personas/<name>.md in
+// the same format.
+const DefaultPersona = "default"
+
+// currentPersonaFile names the persona 'myrai persona use' made current
+const currentPersonaFile = "current"
+
+type personaKey struct{}
+
+// WithPersona returns a context in which the agent answers as the named
+// persona, e.g. the one a channel is bound to. "" leaves the current one.
+func WithPersona(ctx context.Context, name string) context.Context {
+	if name == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, personaKey{}, name)
+}
+
+// PersonaFrom returns the persona named in ctx, or "" for the current one
+func PersonaFrom(ctx context.Context) string {
+	name, _ := ctx.Value(personaKey{}).(string)
+	return name
+}
+
+// IdentityPath returns the file a persona's identity is kept in
+func (pm *PersonaManager) IdentityPath(name string) string {
+	name = sanitizeProjectName(name)
+	if name == "" || name == DefaultPersona {
+		return filepath.Join(pm.workspacePath, "IDENTITY.md")
+	}
+	return filepath.Join(pm.workspacePath, "personas", name+".md")
+}
+
+// ListPersonas returns the default persona followed by the others by name
+func (pm *PersonaManager) ListPersonas() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(pm.workspacePath, "personas"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".md")
+		if ok && !entry.IsDir() && name != DefaultPersona {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultPersona}, names...), nil
+}
+
+// CurrentPersona returns the name of the persona the agent answers as when
+// a channel doesn't name another
+func (pm *PersonaManager) CurrentPersona() string {
+	pm.syncPersona()
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.persona
+}
+
+// PersonaIdentity reads a persona's identity
+func (pm *PersonaManager) PersonaIdentity(name string) (*Identity, error) {
+	data, err := os.ReadFile(pm.IdentityPath(name))
+	if os.IsNotExist(err) {
+		if sanitizeProjectName(name) == DefaultPersona {
+			return &Identity{Name: "Myrai"}, nil
+		}
+		return nil, fmt.Errorf("persona '%s' not found", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseIdentity(string(data)), nil
+}
+
+// CreatePersona saves a new persona with the given identity
+func (pm *PersonaManager) CreatePersona(name string, identity *Identity) error {
+	safeName := sanitizeProjectName(name)
+	if safeName == "" || safeName == DefaultPersona || safeName == currentPersonaFile {
+		return fmt.Errorf("invalid persona name '%s'", name)
+	}
+	path := pm.IdentityPath(safeName)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("persona '%s' already exists", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(identity.String()), 0644)
+}
+
+// SavePersonaIdentity validates identity and saves it as a persona's,
+// regenerating its Markdown file
+func (pm *PersonaManager) SavePersonaIdentity(name string, identity *Identity) error {
+	if err := identity.Validate(); err != nil {
+		return err
+	}
+	if _, err := pm.PersonaIdentity(name); err != nil {
+		return err
+	}
+	if sanitizeProjectName(name) == pm.CurrentPersona() {
+		return pm.SetIdentity(identity)
+	}
+	if err := os.WriteFile(pm.IdentityPath(name), []byte(identity.String()), 0644); err != nil {
+		return fmt.Errorf("failed to save persona '%s': %w", name, err)
+	}
+	pm.InvalidateCache()
+	return nil
+}
+
+// DeletePersona removes a persona. The default and current personas can't
+// be deleted.
+func (pm *PersonaManager) DeletePersona(name string) error {
+	safeName := sanitizeProjectName(name)
+	if safeName == DefaultPersona {
+		return fmt.Errorf("the default persona can't be deleted")
+	}
+	if safeName == pm.CurrentPersona() {
+		return fmt.Errorf("persona '%s' is current; switch to another first", name)
+	}
+	if err := os.Remove(pm.IdentityPath(safeName)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("persona '%s' not found", name)
+		}
+		return err
+	}
+	pm.InvalidateCache()
+	return nil
+}
+
+// SwitchPersona makes a persona the current one, for this and every other
+// process sharing the workspace
+func (pm *PersonaManager) SwitchPersona(name string) error {
+	identity, err := pm.PersonaIdentity(name)
+	if err != nil {
+		return err
+	}
+	safeName := sanitizeProjectName(name)
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	path := filepath.Join(pm.workspacePath, "personas", currentPersonaFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(safeName+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to save current persona: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil {
+		pm.personaMod = info.ModTime()
+	}
+	pm.persona = safeName
+	pm.identity = identity
+	pm.InvalidateCache()
+	return nil
+}
+
+// UsePersona makes a persona current in this process only, as for the
+// persona the config binds to the CLI. Switches made elsewhere are ignored
+// from then on.
+func (pm *PersonaManager) UsePersona(name string) error {
+	identity, err := pm.PersonaIdentity(name)
+	if err != nil {
+		return err
+	}
+
+	pm.mu.Lock()
+	pm.persona = sanitizeProjectName(name)
+	pm.identity = identity
+	pm.personaPinned = true
+	pm.mu.Unlock()
+
+	pm.InvalidateCache()
+	return nil
+}
+
+// loadCurrentPersona reads which persona is current and its identity
+// (caller must hold lock)
+func (pm *PersonaManager) loadCurrentPersona() {
+	pm.persona = DefaultPersona
+	path := filepath.Join(pm.workspacePath, "personas", currentPersonaFile)
+	if info, err := os.Stat(path); err == nil {
+		pm.personaMod = info.ModTime()
+		if data, err := os.ReadFile(path); err == nil {
+			if name := sanitizeProjectName(strings.TrimSpace(string(data))); name != "" {
+				pm.persona = name
+			}
+		}
+	}
+
+	data, err := os.ReadFile(pm.IdentityPath(pm.persona))
+	if err != nil && pm.persona != DefaultPersona {
+		pm.logger.Warn("Current persona is missing, using the default", zap.String("persona", pm.persona))
+		pm.persona = DefaultPersona
+		data, err = os.ReadFile(pm.IdentityPath(DefaultPersona))
+	}
+	if err == nil {
+		pm.identity = parseIdentity(string(data))
+	}
+}
+
+// syncPersona follows a persona switch made by another process, such as
+// 'myrai persona use' while the gateway runs
+func (pm *PersonaManager) syncPersona() {
+	info, err := os.Stat(filepath.Join(pm.workspacePath, "personas", currentPersonaFile))
+	pm.mu.RLock()
+	unchanged := pm.personaPinned || err != nil || info.ModTime().Equal(pm.personaMod)
+	pm.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	pm.mu.Lock()
+	pm.loadCurrentPersona()
+	pm.mu.Unlock()
+	pm.InvalidateCache()
+}
@@ -0,0 +1,82 @@
+package persona
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestPersonaProfiles(t *testing.T) {
+	workspace := t.TempDir()
+	pm, err := NewPersonaManager(workspace, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to create PersonaManager: %v", err)
+	}
+	if err := pm.SetIdentity(&Identity{Name: "Myrai", Voice: "Friendly and detailed"}); err != nil {
+		t.Fatalf("Failed to set identity: %v", err)
+	}
+
+	if err := pm.CreatePersona("Terse", &Identity{Name: "Myrai", Voice: "One line answers"}); err != nil {
+		t.Fatalf("Failed to create persona: %v", err)
+	}
+	if err := pm.CreatePersona("terse", &Identity{Name: "Other"}); err == nil {
+		t.Error("Expected an error creating an existing persona")
+	}
+	if err := pm.CreatePersona(DefaultPersona, &Identity{Name: "Other"}); err == nil {
+		t.Error("Expected an error creating the default persona")
+	}
+
+	names, err := pm.ListPersonas()
+	if err != nil || strings.Join(names, ",") != "default,terse" {
+		t.Errorf("Unexpected personas: %v, %v", names, err)
+	}
+
+	// A channel's persona doesn't change the current one
+	if prompt := pm.SystemPromptFor("terse"); !strings.Contains(prompt, "One line answers") {
+		t.Error("Expected the terse persona's voice")
+	}
+	if prompt := pm.GetSystemPrompt(); !strings.Contains(prompt, "Friendly and detailed") {
+		t.Error("Expected the default persona's voice")
+	}
+	if prompt := pm.SystemPromptFor("missing"); !strings.Contains(prompt, "Friendly and detailed") {
+		t.Error("Expected an unknown persona to fall back to the current one")
+	}
+
+	if err := pm.SwitchPersona("terse"); err != nil {
+		t.Fatalf("Failed to switch persona: %v", err)
+	}
+	if err := pm.DeletePersona("terse"); err == nil {
+		t.Error("Expected an error deleting the current persona")
+	}
+
+	// Saved for other processes, and identity changes go to its file
+	reloaded, err := NewPersonaManager(workspace, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if reloaded.CurrentPersona() != "terse" || reloaded.GetIdentity().Voice != "One line answers" {
+		t.Errorf("Expected the terse persona to be current, got %s", reloaded.CurrentPersona())
+	}
+	if err := reloaded.SetIdentity(&Identity{Name: "Myrai", Voice: "Short"}); err != nil {
+		t.Fatalf("Failed to set identity: %v", err)
+	}
+	if identity, _ := reloaded.PersonaIdentity(DefaultPersona); identity.Voice != "Friendly and detailed" {
+		t.Errorf("Expected IDENTITY.md to be left alone, got %q", identity.Voice)
+	}
+
+	if err := pm.SwitchPersona("missing"); err == nil {
+		t.Error("Expected an error switching to a missing persona")
+	}
+}
+
+func TestPersonaContext(t *testing.T) {
+	ctx := context.Background()
+	if PersonaFrom(WithPersona(ctx, "")) != "" {
+		t.Error("Expected no persona")
+	}
+	if PersonaFrom(WithPersona(ctx, "work")) != "work" {
+		t.Error("Expected the work persona")
+	}
+}
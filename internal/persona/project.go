@@ -2,9 +2,12 @@ package persona
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -18,6 +21,9 @@ const (
 	projectIndexFile  = "project-index.json"
 )
 
+// ErrArchived is returned when switching to an archived project
+var ErrArchived = errors.New("archived")
+
 // Project represents a project with its context
 type Project struct {
 	Name        string                 `json:"name"`
@@ -29,6 +35,9 @@ type Project struct {
 	LastAccessed time.Time             `json:"last_accessed"`
 	Position    int                    `json:"position"` // LRU position (1 = most recent)
 	IsArchived  bool                   `json:"is_archived"`
+	Glossary    []GlossaryTerm         `json:"glossary,omitempty"`
+	Tools       *ToolProfile           `json:"tools,omitempty"` // skills, tools and model while current
+	Key         string                 `json:"key,omitempty"`   // memory ID kept across renames
 	Metadata    map[string]interface{} `json:"metadata"`
 }
 
@@ -37,6 +46,7 @@ type ProjectManager struct {
 	basePath string
 	logger   *zap.Logger
 	projects map[string]*Project
+	indexMod time.Time // of the index file as last read or written
 	mu       sync.RWMutex
 }
 
@@ -67,7 +77,7 @@ func (pm *ProjectManager) CreateProject(name, projectType, description string) (
 	safeName := sanitizeProjectName(name)
 
 	// Check if project exists
-	if _, exists := pm.projects[safeName]; exists {
+	if pm.nameTaken(safeName, nil) {
 		return nil, fmt.Errorf("project '%s' already exists", name)
 	}
 
@@ -113,7 +123,8 @@ func (pm *ProjectManager) CreateProject(name, projectType, description string) (
 	return project, nil
 }
 
-// LoadProject loads a project by name and updates its LRU position
+// LoadProject loads a project by name and updates its LRU position.
+// Archived projects have to be unarchived first.
 func (pm *ProjectManager) LoadProject(name string) (*Project, error) {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
@@ -124,10 +135,8 @@ func (pm *ProjectManager) LoadProject(name string) (*Project, error) {
 	if !exists {
 		return nil, fmt.Errorf("project '%s' not found", name)
 	}
-
-	// If archived, move to active
 	if project.IsArchived {
-		pm.unarchiveProject(project)
+		return nil, fmt.Errorf("project '%s' is %w", name, ErrArchived)
 	}
 
 	// Update LRU position
@@ -148,6 +157,44 @@ func (pm *ProjectManager) LoadProject(name string) (*Project, error) {
 	return project, nil
 }
 
+// GetProject returns a project by name without making it the current one
+func (pm *ProjectManager) GetProject(name string) (*Project, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	project, exists := pm.projects[sanitizeProjectName(name)]
+	if !exists {
+		return nil, fmt.Errorf("project '%s' not found", name)
+	}
+	if err := pm.loadProjectData(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// Refresh re-reads the project index if another process, such as
+// 'myrai project switch', changed it. It reports whether it did.
+func (pm *ProjectManager) Refresh() bool {
+	info, err := os.Stat(filepath.Join(pm.basePath, projectIndexFile))
+	if err != nil {
+		return false
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if info.ModTime().Equal(pm.indexMod) {
+		return false
+	}
+	previous := pm.projects
+	pm.projects = make(map[string]*Project)
+	if err := pm.loadIndex(); err != nil {
+		pm.logger.Warn("Failed to reload the project index", zap.Error(err))
+		pm.projects = previous
+		return false
+	}
+	return true
+}
+
 // GetCurrentProject returns the most recently used active project
 func (pm *ProjectManager) GetCurrentProject() *Project {
 	pm.mu.RLock()
@@ -301,6 +348,116 @@ func (pm *ProjectManager) ArchiveProjectByName(name string) error {
 	return pm.saveIndex()
 }
 
+// UnarchiveProjectByName moves an archived project back among the active
+// ones, as the least recently used, archiving the oldest if there are too
+// many
+func (pm *ProjectManager) UnarchiveProjectByName(name string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	project, exists := pm.projects[sanitizeProjectName(name)]
+	if !exists {
+		return fmt.Errorf("project '%s' not found", name)
+	}
+	if !project.IsArchived {
+		return fmt.Errorf("project '%s' is not archived", name)
+	}
+
+	pm.unarchiveProject(project)
+	return pm.saveIndex()
+}
+
+// RenameProject renames a project, moving its file. Its memories stay with
+// it.
+func (pm *ProjectManager) RenameProject(name, newName string) (*Project, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	safeName := sanitizeProjectName(name)
+	project, exists := pm.projects[safeName]
+	if !exists {
+		return nil, fmt.Errorf("project '%s' not found", name)
+	}
+	newSafeName := sanitizeProjectName(newName)
+	if newSafeName == "" {
+		return nil, fmt.Errorf("invalid project name '%s'", newName)
+	}
+	if pm.nameTaken(newSafeName, project) {
+		return nil, fmt.Errorf("project '%s' already exists", newName)
+	}
+
+	if err := pm.loadProjectData(project); err != nil {
+		return nil, err
+	}
+	oldPath := pm.projectPath(project)
+	if project.Key == "" {
+		project.Key = safeName
+	}
+	project.Name = newName
+	project.UpdatedAt = time.Now()
+	if err := pm.saveProjectInternal(project); err != nil {
+		return nil, err
+	}
+	if newPath := pm.projectPath(project); newPath != oldPath {
+		os.Remove(oldPath)
+	}
+
+	delete(pm.projects, safeName)
+	pm.projects[newSafeName] = project
+	if err := pm.saveIndex(); err != nil {
+		return nil, err
+	}
+
+	pm.logger.Info("Renamed project",
+		zap.String("from", name),
+		zap.String("to", newName),
+	)
+	return project, nil
+}
+
+// DuplicateProject creates newName as a copy of a project's type,
+// description, context, glossary and tool profile. Memories aren't copied.
+func (pm *ProjectManager) DuplicateProject(name, newName string) (*Project, error) {
+	source, err := pm.GetProject(name)
+	if err != nil {
+		return nil, err
+	}
+
+	pm.mu.RLock()
+	glossary := slices.Clone(source.Glossary)
+	values := maps.Clone(source.Context)
+	metadata := maps.Clone(source.Metadata)
+	var tools *ToolProfile
+	if source.Tools != nil {
+		tools = &ToolProfile{
+			Allow: slices.Clone(source.Tools.Allow),
+			Deny:  slices.Clone(source.Tools.Deny),
+			Model: source.Tools.Model,
+		}
+	}
+	pm.mu.RUnlock()
+
+	project, err := pm.CreateProject(newName, source.Type, source.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if values != nil {
+		project.Context = values
+	}
+	if metadata != nil {
+		project.Metadata = metadata
+	}
+	project.Glossary = glossary
+	project.Tools = tools
+	if err := pm.saveProjectInternal(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
 // DeleteProjectByName deletes a project by name
 func (pm *ProjectManager) DeleteProjectByName(name string) error {
 	pm.mu.Lock()
@@ -360,34 +517,61 @@ func (pm *ProjectManager) enforceProjectLimit() {
 }
 
 func (pm *ProjectManager) archiveProjectInternal(project *Project) {
+	src := pm.projectPath(project)
+	if err := pm.loadProjectData(project); err != nil {
+		pm.logger.Warn("Failed to read project", zap.String("project", project.Name), zap.Error(err))
+	}
 	project.IsArchived = true
 	project.UpdatedAt = time.Now()
 
-	src := filepath.Join(pm.basePath, "active", sanitizeProjectName(project.Name)+".json")
-	dst := filepath.Join(pm.basePath, "archived", sanitizeProjectName(project.Name)+".json")
-
-	os.Rename(src, dst)
+	// Written anew so the file records it's archived
+	if err := pm.saveProjectInternal(project); err == nil {
+		os.Remove(src)
+	}
 	pm.reorderPositions()
 }
 
 func (pm *ProjectManager) unarchiveProject(project *Project) {
 	pm.enforceProjectLimit()
 
+	src := pm.projectPath(project)
+	if err := pm.loadProjectData(project); err != nil {
+		pm.logger.Warn("Failed to read project", zap.String("project", project.Name), zap.Error(err))
+	}
 	project.IsArchived = false
-	project.Position = 1
 	project.UpdatedAt = time.Now()
 
-	src := filepath.Join(pm.basePath, "archived", sanitizeProjectName(project.Name)+".json")
-	dst := filepath.Join(pm.basePath, "active", sanitizeProjectName(project.Name)+".json")
+	// Last in LRU order, so the current project stays current
+	project.Position = 0
+	for _, p := range pm.projects {
+		if !p.IsArchived {
+			project.Position++
+		}
+	}
 
-	os.Rename(src, dst)
+	if err := pm.saveProjectInternal(project); err == nil {
+		os.Remove(src)
+	}
+}
 
-	// Shift others down
-	for _, p := range pm.projects {
-		if !p.IsArchived && p.Name != project.Name {
-			p.Position++
+// nameTaken reports whether a project other than self has the sanitized
+// name safeName or keeps its memories under it
+func (pm *ProjectManager) nameTaken(safeName string, self *Project) bool {
+	for key, p := range pm.projects {
+		if p != self && (key == safeName || p.ID() == safeName) {
+			return true
 		}
 	}
+	return false
+}
+
+// projectPath returns the file a project is kept in
+func (pm *ProjectManager) projectPath(project *Project) string {
+	dir := "active"
+	if project.IsArchived {
+		dir = "archived"
+	}
+	return filepath.Join(pm.basePath, dir, sanitizeProjectName(project.Name)+".json")
 }
 
 func (pm *ProjectManager) updateLRU(project *Project) {
@@ -423,12 +607,7 @@ func (pm *ProjectManager) reorderPositions() {
 }
 
 func (pm *ProjectManager) saveProjectInternal(project *Project) error {
-	dir := "active"
-	if project.IsArchived {
-		dir = "archived"
-	}
-
-	path := filepath.Join(pm.basePath, dir, sanitizeProjectName(project.Name)+".json")
+	path := pm.projectPath(project)
 	data, err := json.MarshalIndent(project, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal project: %w", err)
@@ -438,18 +617,16 @@ func (pm *ProjectManager) saveProjectInternal(project *Project) error {
 }
 
 func (pm *ProjectManager) loadProjectData(project *Project) error {
-	dir := "active"
-	if project.IsArchived {
-		dir = "archived"
-	}
-
-	path := filepath.Join(pm.basePath, dir, sanitizeProjectName(project.Name)+".json")
-	data, err := os.ReadFile(path)
+	data, err := os.ReadFile(pm.projectPath(project))
 	if err != nil {
 		return fmt.Errorf("failed to read project: %w", err)
 	}
 
-	return json.Unmarshal(data, project)
+	// The directory the file is in decides whether it's archived
+	archived := project.IsArchived
+	err = json.Unmarshal(data, project)
+	project.IsArchived = archived
+	return err
 }
 
 func (pm *ProjectManager) saveIndex() error {
@@ -459,22 +636,42 @@ func (pm *ProjectManager) saveIndex() error {
 		return fmt.Errorf("failed to marshal index: %w", err)
 	}
 
-	return os.WriteFile(path, data, 0644)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		pm.indexMod = info.ModTime()
+	}
+	return nil
 }
 
 func (pm *ProjectManager) loadIndex() error {
 	path := filepath.Join(pm.basePath, projectIndexFile)
-	data, err := os.ReadFile(path)
+	info, err := os.Stat(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil
 		}
 		return err
 	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
 
+	pm.indexMod = info.ModTime()
 	return json.Unmarshal(data, &pm.projects)
 }
 
+// ID returns the name the project's memories are kept under: its
+// sanitized name, or the one it had before it was first renamed
+func (p *Project) ID() string {
+	if p.Key != "" {
+		return p.Key
+	}
+	return sanitizeProjectName(p.Name)
+}
+
 func sanitizeProjectName(name string) string {
 	// Replace spaces and special chars with underscores
 	name = strings.ToLower(name)
@@ -531,6 +728,22 @@ func GetProjectTemplate(projectType string) map[string]string {
 	}
 }
 
+// ProjectTypePrompt returns how the assistant should work on a project of
+// the given type
+func ProjectTypePrompt(projectType string) string {
+	prompts := map[string]string{
+		"coding": "You are pairing on a software project. Give complete, working code in the project's language and framework, " +
+			"follow its existing conventions, explain trade-offs briefly, and point out bugs or security issues you notice.",
+		"writing": "You are helping with a piece of writing. Match the project's audience, format and tone, " +
+			"suggest concrete edits rather than general advice, and keep the author's voice.",
+		"research": "You are a research assistant. Separate established findings from speculation, " +
+			"say how confident you are, cite sources where you can, and suggest what to look into next.",
+		"business": "You are a business advisor. Tie answers to the project's objective, stakeholders and metrics, " +
+			"be concrete about costs, risks and next steps, and keep it brief.",
+	}
+	return prompts[projectType]
+}
+
 // AvailableProjectTypes returns list of available project types
 func AvailableProjectTypes() []string {
 	return []string{"coding", "writing", "research", "business"}
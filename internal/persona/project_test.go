@@ -1,6 +1,7 @@
 package persona
 
 import (
+	"errors"
 	"testing"
 
 	"go.uber.org/zap"
@@ -111,6 +112,102 @@ func TestProjectManagerArchive(t *testing.T) {
 	}
 }
 
+func TestProjectManagerUnarchive(t *testing.T) {
+	pm := NewProjectManager(t.TempDir(), zap.NewNop())
+	for _, name := range []string{"Old", "Current"} {
+		if _, err := pm.CreateProject(name, "coding", "desc"); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+	}
+	if err := pm.ArchiveProjectByName("Old"); err != nil {
+		t.Fatalf("Failed to archive project: %v", err)
+	}
+
+	// Archived projects can't be switched to, also after a reload
+	reloaded := NewProjectManager(pm.basePath, zap.NewNop())
+	if _, err := reloaded.LoadProject("Old"); !errors.Is(err, ErrArchived) {
+		t.Errorf("Expected ErrArchived, got %v", err)
+	}
+	if p, err := reloaded.GetProject("Old"); err != nil || !p.IsArchived {
+		t.Errorf("Expected Old to stay archived, got %+v, %v", p, err)
+	}
+
+	if err := reloaded.UnarchiveProjectByName("Old"); err != nil {
+		t.Fatalf("Failed to unarchive project: %v", err)
+	}
+	if current := reloaded.GetCurrentProject(); current == nil || current.Name != "Current" {
+		t.Errorf("Unarchiving should not change the current project, got %+v", current)
+	}
+	if _, err := reloaded.LoadProject("Old"); err != nil {
+		t.Errorf("Failed to switch to unarchived project: %v", err)
+	}
+	if err := reloaded.UnarchiveProjectByName("Old"); err == nil {
+		t.Error("Expected an error unarchiving an active project")
+	}
+}
+
+func TestProjectManagerRename(t *testing.T) {
+	pm := NewProjectManager(t.TempDir(), zap.NewNop())
+	if _, err := pm.CreateProject("Web API", "coding", "desc"); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := pm.CreateProject("Other", "writing", "desc"); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	if _, err := pm.RenameProject("Web API", "other"); err == nil {
+		t.Error("Expected an error renaming onto an existing project")
+	}
+
+	project, err := pm.RenameProject("Web API", "Gateway")
+	if err != nil {
+		t.Fatalf("Failed to rename project: %v", err)
+	}
+	if project.ID() != "web_api" {
+		t.Errorf("Expected memories to stay under web_api, got %s", project.ID())
+	}
+
+	reloaded := NewProjectManager(pm.basePath, zap.NewNop())
+	if _, err := reloaded.GetProject("Web API"); err == nil {
+		t.Error("Expected the old name to be gone")
+	}
+	loaded, err := reloaded.GetProject("Gateway")
+	if err != nil || loaded.ID() != "web_api" || loaded.Description != "desc" {
+		t.Errorf("Unexpected renamed project: %+v, %v", loaded, err)
+	}
+
+	// The old name stays taken by its memories
+	if _, err := reloaded.CreateProject("Web API", "coding", "desc"); err == nil {
+		t.Error("Expected an error reusing a renamed project's memory ID")
+	}
+}
+
+func TestProjectManagerDuplicate(t *testing.T) {
+	pm := NewProjectManager(t.TempDir(), zap.NewNop())
+	if _, err := pm.CreateProject("Novel", "writing", "desc"); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	pm.UpdateProjectContext("Novel", "genre", "noir")
+	pm.SetToolProfile("Novel", &ToolProfile{Deny: []string{"exec_command"}})
+
+	copied, err := pm.DuplicateProject("Novel", "Sequel")
+	if err != nil {
+		t.Fatalf("Failed to duplicate project: %v", err)
+	}
+	if copied.Type != "writing" || copied.Context["genre"] != "noir" || copied.ID() != "sequel" {
+		t.Errorf("Unexpected copy: %+v", copied)
+	}
+
+	// Changing the copy leaves the original alone
+	pm.SetToolProfile("Sequel", &ToolProfile{})
+	if profile, _ := pm.ToolProfile("Novel"); profile == nil {
+		t.Error("Expected the original to keep its tool profile")
+	}
+	if _, err := pm.DuplicateProject("Novel", "Sequel"); err == nil {
+		t.Error("Expected an error duplicating onto an existing project")
+	}
+}
+
 func TestProjectManagerDelete(t *testing.T) {
 	tempDir := t.TempDir()
 	logger := zap.NewNop()
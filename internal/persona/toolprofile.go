@@ -0,0 +1,103 @@
+package persona
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ToolProfile limits what the assistant may use while a project is current,
+// e.g. no exec_command in a writing project. Names in Allow and Deny are
+// skill names, covering all of a skill's tools, or tool names.
+type ToolProfile struct {
+	// Allow lists the only skills and tools offered; empty offers all
+	Allow []string `json:"allow,omitempty"`
+	// Deny lists skills and tools never offered, whatever Allow says
+	Deny []string `json:"deny,omitempty"`
+	// Model replaces the provider's default model, e.g. a coding model for
+	// a coding project
+	Model string `json:"model,omitempty"`
+}
+
+// Allows reports whether the profile offers tool, which belongs to skill.
+// A nil profile offers everything.
+func (p *ToolProfile) Allows(skill, tool string) bool {
+	if p == nil {
+		return true
+	}
+	named := func(names []string) bool {
+		return slices.Contains(names, tool) || (skill != "" && slices.Contains(names, skill))
+	}
+	if named(p.Deny) {
+		return false
+	}
+	return len(p.Allow) == 0 || named(p.Allow)
+}
+
+// IsEmpty reports whether the profile changes nothing
+func (p *ToolProfile) IsEmpty() bool {
+	return p == nil || (len(p.Allow) == 0 && len(p.Deny) == 0 && p.Model == "")
+}
+
+// String describes the profile for 'myrai project tools'
+func (p *ToolProfile) String() string {
+	if p.IsEmpty() {
+		return "All skills and tools, default model"
+	}
+	var b strings.Builder
+	if len(p.Allow) > 0 {
+		fmt.Fprintf(&b, "Allowed: %s\n", strings.Join(p.Allow, ", "))
+	} else {
+		b.WriteString("Allowed: all skills and tools\n")
+	}
+	if len(p.Deny) > 0 {
+		fmt.Fprintf(&b, "Denied: %s\n", strings.Join(p.Deny, ", "))
+	}
+	model := p.Model
+	if model == "" {
+		model = "default"
+	}
+	fmt.Fprintf(&b, "Model: %s", model)
+	return b.String()
+}
+
+// ToolProfile returns a copy of a project's tool profile, nil if it has none
+func (pm *ProjectManager) ToolProfile(name string) (*ToolProfile, error) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	project, exists := pm.projects[sanitizeProjectName(name)]
+	if !exists {
+		return nil, fmt.Errorf("project '%s' not found", name)
+	}
+	if project.Tools == nil {
+		return nil, nil
+	}
+	profile := *project.Tools
+	profile.Allow = slices.Clone(profile.Allow)
+	profile.Deny = slices.Clone(profile.Deny)
+	return &profile, nil
+}
+
+// SetToolProfile replaces a project's tool profile; an empty one removes it
+func (pm *ProjectManager) SetToolProfile(name string, profile *ToolProfile) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	project, exists := pm.projects[sanitizeProjectName(name)]
+	if !exists {
+		return fmt.Errorf("project '%s' not found", name)
+	}
+	if profile.IsEmpty() {
+		project.Tools = nil
+	} else {
+		project.Tools = profile
+	}
+
+	project.UpdatedAt = time.Now()
+	if err := pm.saveProjectInternal(project); err != nil {
+		return err
+	}
+	return pm.saveIndex()
+}
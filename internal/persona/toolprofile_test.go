@@ -0,0 +1,73 @@
+package persona
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestToolProfileAllows(t *testing.T) {
+	var none *ToolProfile
+	if !none.Allows("system", "exec_command") {
+		t.Error("A nil profile should allow everything")
+	}
+
+	writing := &ToolProfile{Deny: []string{"exec_command", "github"}}
+	if writing.Allows("system", "exec_command") {
+		t.Error("Denied tool should not be allowed")
+	}
+	if writing.Allows("github", "github_create_issue") {
+		t.Error("Tools of a denied skill should not be allowed")
+	}
+	if !writing.Allows("notes", "create_note") {
+		t.Error("Other tools should be allowed")
+	}
+
+	coding := &ToolProfile{Allow: []string{"github", "read_file"}, Deny: []string{"github_delete_repo"}}
+	if !coding.Allows("github", "github_list_issues") || !coding.Allows("", "read_file") {
+		t.Error("Allowed skills and tools should be allowed")
+	}
+	if coding.Allows("github", "github_delete_repo") {
+		t.Error("Deny should win over Allow")
+	}
+	if coding.Allows("notes", "create_note") {
+		t.Error("Tools outside Allow should not be allowed")
+	}
+}
+
+func TestProjectToolProfile(t *testing.T) {
+	pm := NewProjectManager(t.TempDir(), zap.NewNop())
+	if _, err := pm.CreateProject("Novel", "writing", "desc"); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	if profile, err := pm.ToolProfile("Novel"); err != nil || profile != nil {
+		t.Fatalf("Expected no profile, got %+v, %v", profile, err)
+	}
+
+	want := &ToolProfile{Deny: []string{"exec_command"}, Model: "gpt-4o-mini"}
+	if err := pm.SetToolProfile("Novel", want); err != nil {
+		t.Fatalf("Failed to set profile: %v", err)
+	}
+
+	// Saved with the project
+	reloaded := NewProjectManager(pm.basePath, zap.NewNop())
+	got, err := reloaded.ToolProfile("Novel")
+	if err != nil {
+		t.Fatalf("Failed to read profile: %v", err)
+	}
+	if got == nil || got.Model != "gpt-4o-mini" || len(got.Deny) != 1 || got.Deny[0] != "exec_command" {
+		t.Errorf("Unexpected profile: %+v", got)
+	}
+
+	if err := reloaded.SetToolProfile("Novel", &ToolProfile{}); err != nil {
+		t.Fatalf("Failed to clear profile: %v", err)
+	}
+	if got, _ := reloaded.ToolProfile("Novel"); got != nil {
+		t.Errorf("Expected an empty profile to be removed, got %+v", got)
+	}
+
+	if _, err := reloaded.ToolProfile("Missing"); err == nil {
+		t.Error("Expected an error for a missing project")
+	}
+}
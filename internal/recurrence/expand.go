@@ -0,0 +1,280 @@
+package recurrence
+
+import (
+	"slices"
+	"time"
+)
+
+// maxEmptyPeriods stops the search for rules that can never match, like
+// the 30th of February
+const maxEmptyPeriods = 1000
+
+// Next returns the first occurrence after after in the series that starts
+// at start. ok is false once the series has ended.
+func (r *Rule) Next(start, after time.Time) (next time.Time, ok bool) {
+	r.each(start, after, func(t time.Time) bool {
+		if t.After(after) {
+			next, ok = t, true
+			return false
+		}
+		return true
+	})
+	return next, ok
+}
+
+// Between returns the occurrences from from up to but not including to, at
+// most limit of them when limit is positive
+func (r *Rule) Between(start, from, to time.Time, limit int) []time.Time {
+	var out []time.Time
+	r.each(start, from, func(t time.Time) bool {
+		if !t.Before(to) {
+			return false
+		}
+		if !t.Before(from) {
+			out = append(out, t)
+		}
+		return limit <= 0 || len(out) < limit
+	})
+	return out
+}
+
+// OccursOn reports whether the series has an occurrence on day's date
+func (r *Rule) OccursOn(start, day time.Time) bool {
+	day = day.In(start.Location())
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	return len(r.Between(start, from, from.AddDate(0, 0, 1), 1)) > 0
+}
+
+// each calls fn with the series' occurrences in order until it returns
+// false or the series ends. Without a COUNT, periods well before skipTo
+// aren't expanded.
+func (r *Rule) each(start, skipTo time.Time, fn func(time.Time) bool) {
+	interval := max(r.Interval, 1)
+	holidays := r.Holidays
+	if holidays == nil {
+		holidays = DefaultHolidays
+	}
+
+	k := 0
+	if r.Count == 0 && skipTo.After(start) {
+		k = max(r.periodsBetween(start, skipTo)/interval-1, 0)
+	}
+
+	count, empty := 0, 0
+	for ; ; k++ {
+		candidates := r.period(start, k*interval)
+		if len(candidates) == 0 {
+			if empty++; empty > maxEmptyPeriods {
+				return
+			}
+			continue
+		}
+		empty = 0
+
+		for _, t := range candidates {
+			if t.Before(start) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return
+			}
+			if count++; r.Count > 0 && count > r.Count {
+				return
+			}
+			if r.SkipHolidays && holidays != nil && holidays.IsHoliday(t) {
+				continue
+			}
+			if !fn(t) {
+				return
+			}
+		}
+	}
+}
+
+// periodsBetween counts whole periods from start's to t's
+func (r *Rule) periodsBetween(start, t time.Time) int {
+	t = t.In(start.Location())
+	switch r.Freq {
+	case Daily:
+		return dayNumber(t) - dayNumber(start)
+	case Weekly:
+		return (dayNumber(t) - dayNumber(start)) / 7
+	case Monthly:
+		return (t.Year()-start.Year())*12 + int(t.Month()-start.Month())
+	case Yearly:
+		return t.Year() - start.Year()
+	}
+	return 0
+}
+
+// dayNumber numbers calendar days, ignoring time of day and zone
+func dayNumber(t time.Time) int {
+	return int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// period lists the occurrences in the nth period after start's, before
+// BYSETPOS, COUNT and UNTIL are applied
+func (r *Rule) period(start time.Time, n int) []time.Time {
+	loc := start.Location()
+	var days []time.Time
+
+	switch r.Freq {
+	case Daily:
+		d := time.Date(start.Year(), start.Month(), start.Day()+n, 0, 0, 0, 0, loc)
+		if r.inMonths(d) && r.onMonthDay(d) && r.onWeekday(d) {
+			days = append(days, d)
+		}
+	case Weekly:
+		weekStart := start.Day() - (int(start.Weekday())+6)%7 + 7*n
+		for i := 0; i < 7; i++ {
+			d := time.Date(start.Year(), start.Month(), weekStart+i, 0, 0, 0, 0, loc)
+			onDay := d.Weekday() == start.Weekday()
+			if len(r.ByDay) > 0 {
+				onDay = r.onWeekday(d)
+			}
+			if onDay && r.inMonths(d) && r.onMonthDay(d) {
+				days = append(days, d)
+			}
+		}
+	case Monthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(n), 1, 0, 0, 0, 0, loc)
+		if r.inMonths(first) {
+			days = r.monthDays(first, start)
+		}
+	case Yearly:
+		year := start.Year() + n
+		switch {
+		case len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0 && len(r.ByDay) > 0:
+			days = r.yearDays(year, loc)
+		case len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0:
+			if d := time.Date(year, start.Month(), start.Day(), 0, 0, 0, 0, loc); d.Day() == start.Day() {
+				days = append(days, d)
+			}
+		default:
+			for m := time.January; m <= time.December; m++ {
+				if len(r.ByMonth) == 0 || slices.Contains(r.ByMonth, m) {
+					days = append(days, r.monthDays(time.Date(year, m, 1, 0, 0, 0, 0, loc), start)...)
+				}
+			}
+		}
+	}
+
+	hours, minutes := r.ByHour, r.ByMinute
+	if len(hours) == 0 {
+		hours = []int{start.Hour()}
+	}
+	if len(minutes) == 0 {
+		minutes = []int{start.Minute()}
+	}
+	hours, minutes = sortedCopy(hours), sortedCopy(minutes)
+
+	var out []time.Time
+	for _, d := range days {
+		for _, h := range hours {
+			for _, m := range minutes {
+				out = append(out, time.Date(d.Year(), d.Month(), d.Day(), h, m, start.Second(), 0, loc))
+			}
+		}
+	}
+	return r.setPositions(out)
+}
+
+// monthDays lists the matching days of first's month
+func (r *Rule) monthDays(first, start time.Time) []time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if start.Day() > last {
+			return nil
+		}
+		return []time.Time{first.AddDate(0, 0, start.Day()-1)}
+	}
+
+	var days []time.Time
+	for i := 1; i <= last; i++ {
+		d := first.AddDate(0, 0, i-1)
+		if !r.onMonthDay(d) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !r.nthWeekday(d, (i-1)/7+1, -((last-i)/7+1)) {
+			continue
+		}
+		days = append(days, d)
+	}
+	return days
+}
+
+// yearDays lists the days of year matching BYDAY, counting from the year's
+// start or end
+func (r *Rule) yearDays(year int, loc *time.Location) []time.Time {
+	first := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	total := first.AddDate(1, 0, -1).YearDay()
+
+	var days []time.Time
+	for i := 1; i <= total; i++ {
+		d := first.AddDate(0, 0, i-1)
+		if r.nthWeekday(d, (i-1)/7+1, -((total-i)/7 + 1)) {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// nthWeekday reports whether d, the nth of its weekday counting from the
+// start or fromEnd counting from the end, matches BYDAY
+func (r *Rule) nthWeekday(d time.Time, nth, fromEnd int) bool {
+	for _, wd := range r.ByDay {
+		if wd.Day == d.Weekday() && (wd.N == 0 || wd.N == nth || wd.N == fromEnd) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) inMonths(d time.Time) bool {
+	return len(r.ByMonth) == 0 || slices.Contains(r.ByMonth, d.Month())
+}
+
+func (r *Rule) onWeekday(d time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(r.ByDay, func(wd Weekday) bool { return wd.Day == d.Weekday() })
+}
+
+func (r *Rule) onMonthDay(d time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, d.Location()).Day()
+	for _, md := range r.ByMonthDay {
+		if md == d.Day() || (md < 0 && last+md+1 == d.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// setPositions keeps the BYSETPOS picks of a period's occurrences
+func (r *Rule) setPositions(times []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 || len(times) == 0 {
+		return times
+	}
+	var out []time.Time
+	for _, pos := range r.BySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(times) + pos
+		}
+		if i >= 0 && i < len(times) && !slices.ContainsFunc(out, times[i].Equal) {
+			out = append(out, times[i])
+		}
+	}
+	slices.SortFunc(out, func(a, b time.Time) int { return a.Compare(b) })
+	return out
+}
+
+func sortedCopy(values []int) []int {
+	out := slices.Clone(values)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
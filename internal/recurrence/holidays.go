@@ -0,0 +1,42 @@
+package recurrence
+
+import (
+	"fmt"
+	"time"
+)
+
+// Holidays reports whether a day is a holiday
+type Holidays interface {
+	IsHoliday(day time.Time) bool
+}
+
+// DefaultHolidays are the holidays rules skip unless they set their own.
+// The app sets them from the holidays config at startup.
+var DefaultHolidays Holidays = Dates{}
+
+// Dates is a fixed list of holidays, keyed "2006-01-02" for a single day or
+// "01-02" for one that falls on the same date every year
+type Dates map[string]bool
+
+// ParseDates reads holidays written as YYYY-MM-DD or MM-DD
+func ParseDates(dates []string) (Dates, error) {
+	out := Dates{}
+	for _, d := range dates {
+		if _, err := time.Parse("2006-01-02", d); err == nil {
+			out[d] = true
+			continue
+		}
+		// Parsed in a leap year so 02-29 is allowed
+		if _, err := time.Parse("2006-01-02", "2000-"+d); err == nil {
+			out[d] = true
+			continue
+		}
+		return nil, fmt.Errorf("invalid holiday %q: use YYYY-MM-DD or MM-DD", d)
+	}
+	return out, nil
+}
+
+// IsHoliday implements Holidays
+func (d Dates) IsHoliday(day time.Time) bool {
+	return d[day.Format("2006-01-02")] || d[day.Format("01-02")]
+}
@@ -0,0 +1,866 @@
+package recurrence
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// token is a word of a phrase and where it sits in the original text
+type token struct {
+	text       string
+	start, end int
+}
+
+var tokenPattern = regexp.MustCompile(`(?i)[a-z0-9][a-z0-9:/-]*`)
+
+var weekdayNames = map[string]time.Weekday{
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+	"sunday": time.Sunday, "sun": time.Sunday,
+}
+
+var monthNames = map[string]time.Month{
+	"january": time.January, "jan": time.January, "february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March, "april": time.April, "apr": time.April, "may": time.May,
+	"june": time.June, "jun": time.June, "july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August, "september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October, "november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var numberWords = map[string]int{
+	"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+var ordinalWords = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "last": -1, "penultimate": -2,
+}
+
+var numericOrdinal = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th)$`)
+
+var unitFrequencies = map[string]Frequency{
+	"day": Daily, "days": Daily, "week": Weekly, "weeks": Weekly,
+	"month": Monthly, "months": Monthly, "year": Yearly, "years": Yearly,
+}
+
+var (
+	workdays = []Weekday{{Day: time.Monday}, {Day: time.Tuesday}, {Day: time.Wednesday}, {Day: time.Thursday}, {Day: time.Friday}}
+	weekend  = []Weekday{{Day: time.Saturday}, {Day: time.Sunday}}
+)
+
+// ParseText reads a phrase that is only a recurrence, such as "every 2nd
+// Tuesday", "last weekday of the month" or "every weekday except holidays".
+// Text it doesn't understand is an error.
+func ParseText(text string) (*Rule, error) {
+	toks := tokenize(text)
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("empty recurrence")
+	}
+	r, end, ok := parseAt(toks, 0)
+	if !ok {
+		return nil, fmt.Errorf("not a recurrence: %q", text)
+	}
+	if end < len(toks) {
+		return nil, fmt.Errorf("unrecognized recurrence %q in %q", text[toks[end].start:], text)
+	}
+	return r, nil
+}
+
+// ParseAny reads either an RRULE or a phrase
+func ParseAny(s string) (*Rule, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	if strings.HasPrefix(upper, "RRULE:") || strings.HasPrefix(upper, "FREQ=") {
+		return Parse(s)
+	}
+	return ParseText(s)
+}
+
+// Extract finds the first recurrence phrase in text, returning its rule and
+// the phrase as written, or nil when there is none
+func Extract(text string) (*Rule, string) {
+	toks := tokenize(text)
+	for i := range toks {
+		if r, end, ok := parseAt(toks, i); ok {
+			return r, text[toks[i].start:toks[end-1].end]
+		}
+	}
+	return nil, ""
+}
+
+func tokenize(text string) []token {
+	var toks []token
+	for _, loc := range tokenPattern.FindAllStringIndex(text, -1) {
+		toks = append(toks, token{text: strings.ToLower(text[loc[0]:loc[1]]), start: loc[0], end: loc[1]})
+	}
+	return toks
+}
+
+// parser reads a recurrence from tokens. Each method either consumes the
+// words it understands and returns true, or leaves pos where it was.
+type parser struct {
+	toks []token
+	pos  int
+	rule Rule
+	now  time.Time
+}
+
+func parseAt(toks []token, i int) (*Rule, int, bool) {
+	p := &parser{toks: toks, pos: i, now: time.Now()}
+	if !p.core() {
+		return nil, 0, false
+	}
+	p.modifiers()
+	if p.rule.Freq == "" || !p.rule.valid() {
+		return nil, 0, false
+	}
+	return &p.rule, p.pos, true
+}
+
+func (p *parser) peek(offset int) string {
+	if p.pos+offset < len(p.toks) {
+		return p.toks[p.pos+offset].text
+	}
+	return ""
+}
+
+func (p *parser) accept(words ...string) bool {
+	if slices.Contains(words, p.peek(0)) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// core reads the part that sets the frequency
+func (p *parser) core() bool {
+	save := p.pos
+	w := p.peek(0)
+	switch {
+	case w == "daily":
+		p.rule.Freq = Daily
+	case w == "weekly":
+		p.rule.Freq = Weekly
+	case w == "monthly":
+		p.rule.Freq = Monthly
+	case w == "yearly" || w == "annually":
+		p.rule.Freq = Yearly
+	case w == "biweekly" || w == "fortnightly":
+		p.rule.Freq, p.rule.Interval = Weekly, 2
+	case w == "weekdays":
+		p.rule.Freq, p.rule.ByDay = Weekly, slices.Clone(workdays)
+	case w == "weekends":
+		p.rule.Freq, p.rule.ByDay = Weekly, slices.Clone(weekend)
+	case isPluralWeekday(w):
+		p.rule.Freq, p.rule.ByDay = Weekly, p.weekdays()
+		return true
+	case w == "once" && slices.Contains([]string{"a", "per", "every", "each"}, p.peek(1)):
+		freq, ok := unitFrequencies[p.peek(2)]
+		if !ok {
+			return false
+		}
+		p.rule.Freq = freq
+		p.pos += 2
+	case w == "every" || w == "each":
+		p.pos++
+		if !p.every() {
+			p.pos = save
+			return false
+		}
+		return true
+	case w == "on" && (p.peek(1) == "the" || p.peek(1) == "weekdays" || p.peek(1) == "weekends" || isPluralWeekday(p.peek(1))):
+		p.pos++
+		if !p.core() {
+			p.pos = save
+			return false
+		}
+		return true
+	case w == "the":
+		p.pos++
+		if !p.ordinals(true) {
+			p.pos = save
+			return false
+		}
+		return true
+	case isOrdinal(w):
+		return p.ordinals(true)
+	default:
+		return false
+	}
+	p.pos++
+	return true
+}
+
+// every reads what follows "every"
+func (p *parser) every() bool {
+	interval := 1
+	if p.accept("other") {
+		interval = 2
+	} else if n, ok := number(p.peek(0)); ok {
+		interval = n
+		p.pos++
+	} else if n, _, ok := ordinal(p.peek(0)); ok && n > 1 {
+		// "every third day" repeats; "every third Friday" picks within a month
+		if freq, ok := unitFrequencies[p.peek(1)]; ok {
+			p.rule.Freq, p.rule.Interval = freq, n
+			p.pos += 2
+			return true
+		}
+	}
+
+	w := p.peek(0)
+	switch {
+	case unitFrequencies[w] != "":
+		p.rule.Freq = unitFrequencies[w]
+		p.pos++
+	case w == "weekday" || w == "weekdays":
+		p.rule.Freq, p.rule.ByDay = Weekly, slices.Clone(workdays)
+		p.pos++
+	case w == "weekend" || w == "weekends":
+		p.rule.Freq, p.rule.ByDay = Weekly, slices.Clone(weekend)
+		p.pos++
+	case isWeekday(w):
+		p.rule.Freq, p.rule.ByDay = Weekly, p.weekdays()
+	case monthNames[w] != 0:
+		p.rule.Freq = Yearly
+		p.rule.ByMonth = []time.Month{monthNames[w]}
+		p.pos++
+		if d, ok := dayOfMonth(p.peek(0)); ok {
+			p.rule.ByMonthDay = []int{d}
+			p.pos++
+		}
+	case interval == 1 && isOrdinal(w):
+		return p.ordinals(false)
+	default:
+		return false
+	}
+	if interval > 1 {
+		p.rule.Interval = interval
+	}
+	return true
+}
+
+// ordinals reads "2nd Tuesday", "last weekday", "1st and 15th" or "last
+// day", then "of the month" or "of March", which is required when
+// requireOf is set
+func (p *parser) ordinals(requireOf bool) bool {
+	save := p.pos
+	var ords []int
+	numeric := true
+	for {
+		n, isNumber, ok := ordinal(p.peek(0))
+		if !ok {
+			break
+		}
+		p.pos++
+		if n == 2 && p.accept("last") {
+			n = -2
+		} else if n == 2 && p.peek(0) == "to" && p.peek(1) == "last" {
+			n = -2
+			p.pos += 2
+		}
+		ords = append(ords, n)
+		numeric = numeric && isNumber
+		if p.peek(0) == "and" && isOrdinal(p.peek(1)) {
+			p.pos++
+		}
+	}
+	if len(ords) == 0 {
+		return false
+	}
+
+	var byDay []Weekday
+	var byMonthDay, setPos []int
+	w := p.peek(0)
+	switch {
+	case isWeekday(w) || isPluralWeekday(w):
+		day := weekdayOf(w)
+		for _, n := range ords {
+			if n < -5 || n > 5 {
+				p.pos = save
+				return false
+			}
+			byDay = append(byDay, Weekday{Day: day, N: n})
+		}
+		p.pos++
+	case w == "weekday":
+		byDay, setPos = slices.Clone(workdays), ords
+		p.pos++
+	case w == "weekend":
+		byDay, setPos = slices.Clone(weekend), ords
+		p.pos++
+		p.accept("day")
+	case w == "day":
+		byMonthDay = ords
+		p.pos++
+	case numeric:
+		byMonthDay = ords
+	default:
+		p.pos = save
+		return false
+	}
+	for _, d := range byMonthDay {
+		if d == 0 || d > 31 {
+			p.pos = save
+			return false
+		}
+	}
+
+	freq := Monthly
+	var months []time.Month
+	ofSave := p.pos
+	if p.accept("of") {
+		p.accept("the", "every", "each")
+		switch w := p.peek(0); {
+		case w == "month":
+			p.pos++
+		case w == "year":
+			freq = Yearly
+			p.pos++
+			if len(byMonthDay) > 0 {
+				// The first or last day of the year
+				months = []time.Month{time.January}
+				if byMonthDay[0] < 0 {
+					months = []time.Month{time.December}
+				}
+			}
+		case monthNames[w] != 0:
+			freq = Yearly
+			months = p.months()
+		default:
+			p.pos = ofSave
+		}
+	}
+	if requireOf && p.pos == ofSave {
+		p.pos = save
+		return false
+	}
+
+	if p.rule.Freq == "" || freq == Yearly {
+		p.rule.Freq = freq
+	}
+	p.rule.ByDay = append(p.rule.ByDay, byDay...)
+	p.rule.ByMonthDay = append(p.rule.ByMonthDay, byMonthDay...)
+	p.rule.BySetPos = append(p.rule.BySetPos, setPos...)
+	p.rule.ByMonth = append(p.rule.ByMonth, months...)
+	return true
+}
+
+// modifiers reads clauses like "on Mondays", "at 9am", "except holidays",
+// "until June 1" or "10 times" after the frequency
+func (p *parser) modifiers() {
+	for {
+		save := p.pos
+		p.accept("and")
+
+		ok := false
+		switch w := p.peek(0); {
+		case w == "on":
+			p.pos++
+			ok = p.on()
+		case w == "in":
+			p.pos++
+			if monthNames[p.peek(0)] != 0 {
+				p.rule.ByMonth = append(p.rule.ByMonth, p.months()...)
+				ok = true
+			}
+		case w == "at":
+			p.pos++
+			ok = p.times()
+		case w == "except" || w == "excluding" || w == "skipping" || (w == "but" && p.peek(1) == "not"):
+			p.pos++
+			p.accept("not")
+			ok = p.except()
+		case w == "for":
+			p.pos++
+			ok = p.count()
+		case w == "until" || w == "till" || w == "through" || w == "thru":
+			p.pos++
+			ok = p.until()
+		default:
+			ok = p.count()
+		}
+		if !ok {
+			p.pos = save
+			return
+		}
+	}
+}
+
+// on reads what follows "on" once the frequency is known
+func (p *parser) on() bool {
+	w := p.peek(0)
+	switch {
+	case w == "the":
+		p.pos++
+		return p.ordinals(false)
+	case isOrdinal(w):
+		return p.ordinals(false)
+	case w == "weekdays":
+		p.rule.ByDay = slices.Clone(workdays)
+	case w == "weekends":
+		p.rule.ByDay = slices.Clone(weekend)
+	case isWeekday(w) || isPluralWeekday(w):
+		if p.rule.Freq == Daily {
+			p.rule.Freq = Weekly
+		}
+		p.rule.ByDay = append(p.rule.ByDay, p.weekdays()...)
+		return true
+	case monthNames[w] != 0:
+		d, ok := dayOfMonth(p.peek(1))
+		if !ok {
+			return false
+		}
+		if p.rule.Freq == "" || p.rule.Freq == Monthly {
+			p.rule.Freq = Yearly
+		}
+		p.rule.ByMonth = append(p.rule.ByMonth, monthNames[w])
+		p.rule.ByMonthDay = append(p.rule.ByMonthDay, d)
+		p.pos++
+	default:
+		return false
+	}
+	if p.rule.Freq == Daily {
+		p.rule.Freq = Weekly
+	}
+	p.pos++
+	return true
+}
+
+// except reads what follows "except": holidays, or weekdays to leave out
+func (p *parser) except() bool {
+	p.accept("on", "for")
+	if p.peek(0) == "holidays" || p.peek(0) == "holiday" || (p.peek(1) == "holidays" && slices.Contains([]string{"public", "bank", "national"}, p.peek(0))) {
+		p.rule.SkipHolidays = true
+		if p.peek(0) != "holidays" && p.peek(0) != "holiday" {
+			p.pos++
+		}
+		p.pos++
+		return true
+	}
+
+	w := p.peek(0)
+	if !isWeekday(w) && !isPluralWeekday(w) && w != "weekends" {
+		return false
+	}
+	var skip []Weekday
+	if p.accept("weekends") {
+		skip = weekend
+	} else {
+		skip = p.weekdays()
+	}
+
+	days := p.rule.ByDay
+	if len(days) == 0 {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			days = append(days, Weekday{Day: d})
+		}
+		days = append(days[1:], days[0])
+	}
+	p.rule.ByDay = slices.DeleteFunc(slices.Clone(days), func(wd Weekday) bool {
+		return slices.ContainsFunc(skip, func(s Weekday) bool { return s.Day == wd.Day })
+	})
+	return len(p.rule.ByDay) > 0
+}
+
+// count reads "10 times" or "5 occurrences"
+func (p *parser) count() bool {
+	n, ok := number(p.peek(0))
+	if !ok || !slices.Contains([]string{"times", "occurrences", "occurrence", "time"}, p.peek(1)) {
+		return false
+	}
+	if !p.rule.Until.IsZero() {
+		return false
+	}
+	p.rule.Count = n
+	p.pos += 2
+	return true
+}
+
+// until reads an end date: "2030-06-01", "June 1", "1st June 2030"
+func (p *parser) until() bool {
+	p.accept("the")
+	var day time.Time
+	w := p.peek(0)
+	if t, err := time.ParseInLocation("2006-01-02", strings.ReplaceAll(w, "/", "-"), time.Local); err == nil {
+		day = t
+		p.pos++
+	} else {
+		var month time.Month
+		var dom int
+		if d, ok := dayOfMonth(w); ok && monthNames[p.peek(1)] != 0 {
+			dom, month = d, monthNames[p.peek(1)]
+		} else if d, ok := dayOfMonth(p.peek(1)); ok && monthNames[w] != 0 {
+			dom, month = d, monthNames[w]
+		} else {
+			return false
+		}
+		p.pos += 2
+
+		year := p.now.Year()
+		if y, err := strconv.Atoi(p.peek(0)); err == nil && y >= 1970 && y < 3000 {
+			year = y
+			p.pos++
+		} else if time.Date(year, month, dom, 23, 59, 59, 0, time.Local).Before(p.now) {
+			year++
+		}
+		day = time.Date(year, month, dom, 0, 0, 0, 0, time.Local)
+		if day.Day() != dom {
+			return false
+		}
+	}
+	if p.rule.Count > 0 {
+		return false
+	}
+	p.rule.Until = day.Add(24*time.Hour - time.Second)
+	return true
+}
+
+// times reads "9am", "9:30 pm", "17:00", "noon", joined by "and". RRULE
+// crosses hours with minutes, so times need the same minute.
+func (p *parser) times() bool {
+	save := p.pos
+	var hours, minutes []int
+	for {
+		h, m, ok := p.clock()
+		if !ok {
+			break
+		}
+		hours = append(hours, h)
+		minutes = append(minutes, m)
+		if p.peek(0) == "and" && p.peekClock(1) {
+			p.pos++
+		}
+	}
+	minutes = sortedCopy(minutes)
+	if len(hours) == 0 || len(minutes) > 1 {
+		p.pos = save
+		return false
+	}
+	p.rule.ByHour = sortedCopy(hours)
+	p.rule.ByMinute = minutes
+	return true
+}
+
+var clockPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+
+func (p *parser) peekClock(offset int) bool {
+	w := p.peek(offset)
+	return w == "noon" || w == "midnight" || clockPattern.MatchString(w)
+}
+
+func (p *parser) clock() (hour, minute int, ok bool) {
+	switch p.peek(0) {
+	case "noon":
+		p.pos++
+		return 12, 0, true
+	case "midnight":
+		p.pos++
+		return 0, 0, true
+	}
+	m := clockPattern.FindStringSubmatch(p.peek(0))
+	if m == nil {
+		return 0, 0, false
+	}
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	suffix := m[3]
+	if suffix == "" && (p.peek(1) == "am" || p.peek(1) == "pm") {
+		suffix = p.peek(1)
+		p.pos++
+	}
+	switch {
+	case suffix != "" && (hour < 1 || hour > 12):
+		return 0, 0, false
+	case suffix == "pm" && hour != 12:
+		hour += 12
+	case suffix == "am" && hour == 12:
+		hour = 0
+	case suffix == "" && m[2] == "" && hour > 23:
+		return 0, 0, false
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	p.pos++
+	return hour, minute, true
+}
+
+// weekdays reads a list of day names like "Monday and Thursday"
+func (p *parser) weekdays() []Weekday {
+	var days []Weekday
+	for {
+		w := p.peek(0)
+		if !isWeekday(w) && !isPluralWeekday(w) {
+			break
+		}
+		day := weekdayOf(w)
+		if !slices.ContainsFunc(days, func(d Weekday) bool { return d.Day == day }) {
+			days = append(days, Weekday{Day: day})
+		}
+		p.pos++
+		if (p.peek(0) == "and" || p.peek(0) == "or") && (isWeekday(p.peek(1)) || isPluralWeekday(p.peek(1))) {
+			p.pos++
+		}
+	}
+	return days
+}
+
+// months reads a list of month names
+func (p *parser) months() []time.Month {
+	var months []time.Month
+	for monthNames[p.peek(0)] != 0 {
+		months = append(months, monthNames[p.peek(0)])
+		p.pos++
+		if p.peek(0) == "and" && monthNames[p.peek(1)] != 0 {
+			p.pos++
+		}
+	}
+	return months
+}
+
+func isWeekday(w string) bool {
+	_, ok := weekdayNames[w]
+	return ok
+}
+
+func isPluralWeekday(w string) bool {
+	if !strings.HasSuffix(w, "s") || len(w) < 6 {
+		return false
+	}
+	_, ok := weekdayNames[strings.TrimSuffix(w, "s")]
+	return ok
+}
+
+func weekdayOf(w string) time.Weekday {
+	if d, ok := weekdayNames[w]; ok {
+		return d
+	}
+	return weekdayNames[strings.TrimSuffix(w, "s")]
+}
+
+// number reads a count like "3" or "three"
+func number(w string) (int, bool) {
+	if n, ok := numberWords[w]; ok {
+		return n, true
+	}
+	n, err := strconv.Atoi(w)
+	return n, err == nil && n > 0 && n < 1000
+}
+
+// ordinal reads "2nd" or "second"; isNumber tells which
+func ordinal(w string) (n int, isNumber, ok bool) {
+	if n, ok := ordinalWords[w]; ok {
+		return n, false, true
+	}
+	if m := numericOrdinal.FindStringSubmatch(w); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n, true, n > 0
+	}
+	return 0, false, false
+}
+
+func isOrdinal(w string) bool {
+	_, _, ok := ordinal(w)
+	return ok
+}
+
+// dayOfMonth reads "3" or "3rd"
+func dayOfMonth(w string) (int, bool) {
+	n, isNumber, ok := ordinal(w)
+	if !ok || !isNumber {
+		var err error
+		if n, err = strconv.Atoi(w); err != nil {
+			return 0, false
+		}
+	}
+	return n, n >= 1 && n <= 31
+}
+
+// valid rejects combinations the phrase allowed but RRULE doesn't
+func (r *Rule) valid() bool {
+	for _, d := range r.ByDay {
+		if d.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return false
+		}
+	}
+	return true
+}
+
+// Describe says when the rule repeats, in words: "every other Tuesday",
+// "the last weekday of every month at 09:00"
+func (r *Rule) Describe() string {
+	interval := max(r.Interval, 1)
+	every := func(unit string) string {
+		switch interval {
+		case 1:
+			return "every " + unit
+		case 2:
+			return "every other " + unit
+		}
+		return fmt.Sprintf("every %d %ss", interval, unit)
+	}
+
+	var s string
+	switch r.Freq {
+	case Daily:
+		s = every("day")
+		if len(r.ByDay) > 0 {
+			s += " on " + describeDays(r.ByDay)
+		}
+	case Weekly:
+		switch {
+		case len(r.ByDay) == 0:
+			s = every("week")
+		case interval == 1:
+			s = "every " + describeDays(r.ByDay)
+		case interval == 2 && len(r.ByDay) == 1:
+			s = every(r.ByDay[0].Day.String())
+		default:
+			s = every("week") + " on " + describeDays(r.ByDay)
+		}
+	case Monthly:
+		s = every("month")
+		if spec := r.describeWithinMonth(); spec != "" {
+			s = "the " + spec + " of " + s
+		}
+	case Yearly:
+		s = every("year")
+		switch {
+		case len(r.ByMonth) == 1 && len(r.ByMonthDay) == 1 && r.ByMonthDay[0] > 0 && len(r.ByDay) == 0:
+			s += fmt.Sprintf(" on %s %d", r.ByMonth[0], r.ByMonthDay[0])
+		case len(r.ByMonth) > 0:
+			months := make([]string, len(r.ByMonth))
+			for i, m := range r.ByMonth {
+				months[i] = m.String()
+			}
+			if spec := r.describeWithinMonth(); spec != "" {
+				s = "the " + spec + " of " + joinWords(months) + ", " + s
+			} else {
+				s += " in " + joinWords(months)
+			}
+		case len(r.ByDay) > 0:
+			s = "the " + r.describeWithinMonth() + " of " + s
+		}
+	}
+
+	if len(r.ByHour) > 0 {
+		minute := 0
+		if len(r.ByMinute) > 0 {
+			minute = r.ByMinute[0]
+		}
+		times := make([]string, len(r.ByHour))
+		for i, h := range r.ByHour {
+			times[i] = fmt.Sprintf("%02d:%02d", h, minute)
+		}
+		s += " at " + joinWords(times)
+	}
+	if r.SkipHolidays {
+		s += " except holidays"
+	}
+	if r.Count > 0 {
+		s += fmt.Sprintf(", %d times", r.Count)
+	}
+	if !r.Until.IsZero() {
+		s += ", until " + r.Until.In(time.Local).Format("Jan 2, 2006")
+	}
+	return s
+}
+
+// describeWithinMonth names the days a monthly rule picks: "2nd Tuesday",
+// "last weekday", "1st and 15th"
+func (r *Rule) describeWithinMonth() string {
+	var parts []string
+	switch {
+	case len(r.BySetPos) > 0 && len(r.ByDay) > 0:
+		kind := "day"
+		if sameDays(r.ByDay, workdays) {
+			kind = "weekday"
+		} else if sameDays(r.ByDay, weekend) {
+			kind = "weekend day"
+		}
+		for _, n := range r.BySetPos {
+			parts = append(parts, ordinalName(n))
+		}
+		return joinWords(parts) + " " + kind
+	case len(r.ByDay) > 0:
+		for _, d := range r.ByDay {
+			if d.N == 0 {
+				parts = append(parts, d.Day.String())
+			} else {
+				parts = append(parts, ordinalName(d.N)+" "+d.Day.String())
+			}
+		}
+	case len(r.ByMonthDay) > 0:
+		for _, d := range r.ByMonthDay {
+			parts = append(parts, ordinalName(d))
+		}
+		if r.ByMonthDay[len(r.ByMonthDay)-1] < 0 {
+			parts[len(parts)-1] += " day"
+		}
+	}
+	return joinWords(parts)
+}
+
+func describeDays(days []Weekday) string {
+	switch {
+	case sameDays(days, workdays):
+		return "weekday"
+	case sameDays(days, weekend):
+		return "weekend"
+	}
+	names := make([]string, len(days))
+	for i, d := range days {
+		names[i] = d.Day.String()
+	}
+	return joinWords(names)
+}
+
+func sameDays(a, b []Weekday) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, d := range b {
+		if !slices.ContainsFunc(a, func(x Weekday) bool { return x.Day == d.Day && x.N == 0 }) {
+			return false
+		}
+	}
+	return true
+}
+
+func ordinalName(n int) string {
+	switch {
+	case n == -1:
+		return "last"
+	case n == -2:
+		return "second to last"
+	case n < 0:
+		return fmt.Sprintf("%s from last", ordinalName(-n))
+	case n%100 >= 11 && n%100 <= 13:
+		return fmt.Sprintf("%dth", n)
+	case n%10 == 1:
+		return fmt.Sprintf("%dst", n)
+	case n%10 == 2:
+		return fmt.Sprintf("%dnd", n)
+	case n%10 == 3:
+		return fmt.Sprintf("%drd", n)
+	}
+	return fmt.Sprintf("%dth", n)
+}
+
+func joinWords(words []string) string {
+	if len(words) <= 1 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
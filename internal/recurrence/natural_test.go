@@ -0,0 +1,77 @@
+package recurrence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseText(t *testing.T) {
+	tests := []struct {
+		text     string
+		rrule    string
+		describe string
+	}{
+		{"daily", "RRULE:FREQ=DAILY", "every day"},
+		{"every 3 days", "RRULE:FREQ=DAILY;INTERVAL=3", "every 3 days"},
+		{"every other week", "RRULE:FREQ=WEEKLY;INTERVAL=2", "every other week"},
+		{"Every Monday and Thursday", "RRULE:FREQ=WEEKLY;BYDAY=MO,TH", "every Monday and Thursday"},
+		{"every other Tuesday", "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU", "every other Tuesday"},
+		{"every 2nd Tuesday", "RRULE:FREQ=MONTHLY;BYDAY=2TU", "the 2nd Tuesday of every month"},
+		{"last weekday of the month", "RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", "the last weekday of every month"},
+		{"every weekday except holidays", "RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;X-MYRAI-EXCEPT=HOLIDAYS", "every weekday except holidays"},
+		{"on the 1st and 15th of every month", "RRULE:FREQ=MONTHLY;BYMONTHDAY=1,15", "the 1st and 15th of every month"},
+		{"monthly on the last day", "RRULE:FREQ=MONTHLY;BYMONTHDAY=-1", "the last day of every month"},
+		{"every month on the second to last Friday", "RRULE:FREQ=MONTHLY;BYDAY=-2FR", "the second to last Friday of every month"},
+		{"the 4th Thursday of November", "RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", "the 4th Thursday of November, every year"},
+		{"every year on March 3", "RRULE:FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=3", "every year on March 3"},
+		{"every day except Sunday", "RRULE:FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR,SA", "every day on Monday, Tuesday, Wednesday, Thursday, Friday and Saturday"},
+		{"weekdays at 9am", "RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9;BYMINUTE=0", "every weekday at 09:00"},
+		{"every day at 8:00 and 8pm", "RRULE:FREQ=DAILY;BYHOUR=8,20;BYMINUTE=0", "every day at 08:00 and 20:00"},
+		{"fortnightly on Fridays for 6 times", "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;COUNT=6", "every other Friday, 6 times"},
+		{"every third day", "RRULE:FREQ=DAILY;INTERVAL=3", "every 3 days"},
+		{"once a month", "RRULE:FREQ=MONTHLY", "every month"},
+		{"weekly until 2030-06-01", "RRULE:FREQ=WEEKLY;UNTIL=", "every week, until Jun 1, 2030"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			r, err := ParseText(tt.text)
+			require.NoError(t, err)
+			assert.Contains(t, r.String(), tt.rrule)
+			assert.Equal(t, tt.describe, r.Describe())
+		})
+	}
+
+	for _, bad := range []string{"", "tomorrow", "every banana", "every day at 9:30 and 5pm", "every 2nd Tuesday please"} {
+		_, err := ParseText(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	r, phrase := Extract("Team standup every weekday at 9:30 in room 4")
+	require.NotNil(t, r)
+	assert.Equal(t, "every weekday at 9:30", phrase)
+	assert.Equal(t, "RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9;BYMINUTE=30", r.String())
+
+	r, phrase = Extract("Pay rent on the last day of the month")
+	require.NotNil(t, r)
+	assert.Equal(t, "on the last day of the month", phrase)
+
+	r, _ = Extract("Lunch with Sam on Friday")
+	assert.Nil(t, r)
+}
+
+func TestParseAny(t *testing.T) {
+	r, err := ParseAny("FREQ=WEEKLY;BYDAY=MO")
+	require.NoError(t, err)
+	assert.Equal(t, "every Monday", r.Describe())
+
+	r, err = ParseAny("every weekday at 9am")
+	require.NoError(t, err)
+	assert.Equal(t, []int{9}, r.ByHour)
+
+	_, err = ParseAny("0 9 * * *")
+	assert.Error(t, err, "cron expressions are left to the caller")
+}
@@ -0,0 +1,271 @@
+// Package recurrence describes repeating schedules. A Rule follows RFC 5545
+// RRULE semantics, can be read from and written to RRULE strings, parsed from
+// phrases like "every 2nd Tuesday" and expanded into occurrences. Tasks,
+// calendar events, medications and scheduled jobs all share it.
+package recurrence
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is how often a rule's period repeats
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// Weekday is a BYDAY entry. N picks the nth such weekday of the month (or
+// year), counting from the end when negative; 0 means every one.
+type Weekday struct {
+	Day time.Weekday
+	N   int
+}
+
+// Rule is a recurrence rule. Zero-valued parts are left out, as in RRULE.
+type Rule struct {
+	Freq       Frequency
+	Interval   int // every Interval periods; 0 means 1
+	ByDay      []Weekday
+	ByMonthDay []int // negative counts from the end of the month
+	ByMonth    []time.Month
+	ByHour     []int
+	ByMinute   []int
+	BySetPos   []int // picks among each period's occurrences, like BYDAY's N
+	Count      int   // total occurrences, counting any skipped as holidays
+	Until      time.Time
+
+	// SkipHolidays drops occurrences that fall on a holiday. RRULE has no
+	// such part, so it is written as X-MYRAI-EXCEPT=HOLIDAYS; Portable
+	// removes it for other calendars.
+	SkipHolidays bool
+	// Holidays overrides DefaultHolidays for this rule
+	Holidays Holidays
+}
+
+// extensionHolidays is the RRULE part that stands for SkipHolidays
+const extensionHolidays = "X-MYRAI-EXCEPT=HOLIDAYS"
+
+var dayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// untilLayouts are the forms UNTIL may take
+var untilLayouts = []string{"20060102T150405Z", "20060102T150405", "20060102"}
+
+// Parse reads an RRULE value, with or without the "RRULE:" prefix. Parts
+// this package can't expand, like BYWEEKNO, are an error rather than being
+// silently ignored.
+func Parse(rrule string) (*Rule, error) {
+	value := strings.TrimSpace(rrule)
+	if len(value) >= 6 && strings.EqualFold(value[:6], "RRULE:") {
+		value = value[6:]
+	}
+	if value == "" {
+		return nil, fmt.Errorf("empty recurrence rule")
+	}
+
+	r := &Rule{}
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		key = strings.ToUpper(key)
+
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(strings.ToUpper(val))
+			if !slices.Contains([]Frequency{Daily, Weekly, Monthly, Yearly}, r.Freq) {
+				return nil, fmt.Errorf("unsupported frequency %q", val)
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(val)
+			if err == nil && r.Interval < 1 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(val)
+			if err == nil && r.Count < 1 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "UNTIL":
+			r.Until, err = parseUntil(val)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(val)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseInts(val, -31, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseInts(val, 1, 12)
+			for _, m := range months {
+				r.ByMonth = append(r.ByMonth, time.Month(m))
+			}
+		case "BYHOUR":
+			r.ByHour, err = parseInts(val, 0, 23)
+		case "BYMINUTE":
+			r.ByMinute, err = parseInts(val, 0, 59)
+		case "BYSETPOS":
+			r.BySetPos, err = parseInts(val, -366, 366)
+		case "WKST":
+			// Weeks start on Monday; only matters for weekly rules with
+			// an interval and days before the start's weekday
+		case "X-MYRAI-EXCEPT":
+			r.SkipHolidays = strings.EqualFold(val, "HOLIDAYS")
+		default:
+			if !strings.HasPrefix(key, "X-") {
+				return nil, fmt.Errorf("unsupported rule part %s", key)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", key, val, err)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, fmt.Errorf("recurrence rule has no FREQ")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, fmt.Errorf("recurrence rule has both COUNT and UNTIL")
+	}
+	return r, nil
+}
+
+func parseUntil(val string) (time.Time, error) {
+	for _, layout := range untilLayouts {
+		loc := time.Local
+		if strings.HasSuffix(layout, "Z") {
+			loc = time.UTC
+		}
+		if t, err := time.ParseInLocation(layout, val, loc); err == nil {
+			if layout == "20060102" {
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("not a date")
+}
+
+func parseByDay(val string) ([]Weekday, error) {
+	var days []Weekday
+	for _, item := range strings.Split(strings.ToUpper(val), ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid day %q", item)
+		}
+		day, ok := dayCodes[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", item)
+		}
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			if n, err = strconv.Atoi(strings.TrimPrefix(prefix, "+")); err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("invalid day %q", item)
+			}
+		}
+		days = append(days, Weekday{Day: day, N: n})
+	}
+	return days, nil
+}
+
+func parseInts(val string, lo, hi int) ([]int, error) {
+	var out []int
+	for _, item := range strings.Split(val, ",") {
+		n, err := strconv.Atoi(strings.TrimPrefix(item, "+"))
+		if err != nil || n < lo || n > hi || (n == 0 && lo < 0) {
+			return nil, fmt.Errorf("invalid value %q", item)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// String writes the rule as an RRULE line, "RRULE:FREQ=...".
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]int, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			months[i] = int(m)
+		}
+		parts = append(parts, "BYMONTH="+joinInts(months))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = d.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if len(r.ByHour) > 0 {
+		parts = append(parts, "BYHOUR="+joinInts(r.ByHour))
+	}
+	if len(r.ByMinute) > 0 {
+		parts = append(parts, "BYMINUTE="+joinInts(r.ByMinute))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayouts[0]))
+	}
+	if r.SkipHolidays {
+		parts = append(parts, extensionHolidays)
+	}
+	return "RRULE:" + strings.Join(parts, ";")
+}
+
+// String writes the day as a BYDAY entry, like "TU" or "-1FR"
+func (d Weekday) String() string {
+	code := strings.ToUpper(d.Day.String()[:2])
+	if d.N != 0 {
+		return strconv.Itoa(d.N) + code
+	}
+	return code
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ",")
+}
+
+// Portable strips the parts only this package understands from an RRULE,
+// for calendars that reject unknown parts
+func Portable(rrule string) string {
+	prefix, value := "", rrule
+	if len(value) >= 6 && strings.EqualFold(value[:6], "RRULE:") {
+		prefix, value = value[:6], value[6:]
+	}
+	var parts []string
+	for _, part := range strings.Split(value, ";") {
+		if !strings.HasPrefix(strings.ToUpper(part), "X-") {
+			parts = append(parts, part)
+		}
+	}
+	return prefix + strings.Join(parts, ";")
+}
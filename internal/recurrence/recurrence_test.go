@@ -0,0 +1,108 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_RoundTrip(t *testing.T) {
+	rules := []string{
+		"RRULE:FREQ=DAILY",
+		"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH",
+		"RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		"RRULE:FREQ=MONTHLY;BYDAY=2TU;BYHOUR=9;BYMINUTE=30",
+		"RRULE:FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=3;COUNT=5",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;UNTIL=20300601T000000Z;X-MYRAI-EXCEPT=HOLIDAYS",
+	}
+	for _, s := range rules {
+		r, err := Parse(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, s, r.String())
+	}
+
+	r, err := Parse("freq=monthly;bymonthday=-1;wkst=SU")
+	require.NoError(t, err)
+	assert.Equal(t, "RRULE:FREQ=MONTHLY;BYMONTHDAY=-1", r.String())
+
+	for _, bad := range []string{"", "RRULE:", "INTERVAL=2", "FREQ=HOURLY", "FREQ=DAILY;BYWEEKNO=3", "FREQ=DAILY;BYDAY=XX", "FREQ=DAILY;COUNT=2;UNTIL=20300101"} {
+		_, err := Parse(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestPortable(t *testing.T) {
+	assert.Equal(t, "RRULE:FREQ=WEEKLY;BYDAY=MO", Portable("RRULE:FREQ=WEEKLY;BYDAY=MO;X-MYRAI-EXCEPT=HOLIDAYS"))
+	assert.Equal(t, "FREQ=DAILY", Portable("FREQ=DAILY"))
+}
+
+func occurrences(t *testing.T, rrule string, start time.Time, n int) []string {
+	t.Helper()
+	r, err := Parse(rrule)
+	require.NoError(t, err)
+	var out []string
+	for _, o := range r.Between(start, start, start.AddDate(10, 0, 0), n) {
+		out = append(out, o.Format("2006-01-02 15:04"))
+	}
+	return out
+}
+
+func TestRule_Between(t *testing.T) {
+	// Wednesday 1 January 2025
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, []string{"2025-01-02 09:00", "2025-01-14 09:00", "2025-01-16 09:00"},
+		occurrences(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH", start, 3))
+	assert.Equal(t, []string{"2025-01-14 09:00", "2025-02-11 09:00", "2025-03-11 09:00"},
+		occurrences(t, "FREQ=MONTHLY;BYDAY=2TU", start, 3))
+	assert.Equal(t, []string{"2025-01-31 09:00", "2025-02-28 09:00", "2025-03-31 09:00", "2025-04-30 09:00", "2025-05-30 09:00"},
+		occurrences(t, "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", start, 5))
+	assert.Equal(t, []string{"2025-01-31 09:00", "2025-03-31 09:00", "2025-05-31 09:00"},
+		occurrences(t, "FREQ=MONTHLY;BYMONTHDAY=31", start, 3), "months without a 31st are skipped")
+	assert.Equal(t, []string{"2028-02-29 09:00", "2032-02-29 09:00"},
+		occurrences(t, "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29", start, 2))
+	assert.Equal(t, []string{"2025-01-01 08:00", "2025-01-01 20:00", "2025-01-02 08:00"},
+		occurrences(t, "FREQ=DAILY;BYHOUR=8,20;BYMINUTE=0", start.Add(-2*time.Hour), 3))
+	assert.Equal(t, []string{"2025-01-01 09:00", "2025-01-02 09:00", "2025-01-03 09:00"},
+		occurrences(t, "FREQ=DAILY;COUNT=3", start, 10))
+	assert.Equal(t, []string{"2025-11-27 09:00"},
+		occurrences(t, "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH;UNTIL=20261101T000000Z", start, 10))
+	assert.Empty(t, occurrences(t, "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", start, 1))
+}
+
+func TestRule_NextAndOccursOn(t *testing.T) {
+	start := time.Date(2020, 1, 6, 9, 0, 0, 0, time.UTC) // a Monday
+	r, err := Parse("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO")
+	require.NoError(t, err)
+
+	// Years later the fortnight still lines up with the start
+	next, ok := r.Next(start, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC), next)
+	assert.True(t, r.OccursOn(start, time.Date(2025, 1, 27, 18, 0, 0, 0, time.UTC)))
+	assert.False(t, r.OccursOn(start, time.Date(2025, 1, 20, 9, 0, 0, 0, time.UTC)))
+
+	r.Count = 2
+	_, ok = r.Next(start, start.AddDate(0, 0, 14))
+	assert.False(t, ok, "series ended")
+}
+
+func TestRule_SkipHolidays(t *testing.T) {
+	holidays, err := ParseDates([]string{"12-25", "2025-12-26"})
+	require.NoError(t, err)
+	r, err := Parse("FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;X-MYRAI-EXCEPT=HOLIDAYS")
+	require.NoError(t, err)
+	r.Holidays = holidays
+
+	start := time.Date(2025, 12, 24, 9, 0, 0, 0, time.UTC)
+	next, ok := r.Next(start, start)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2025, 12, 29, 9, 0, 0, 0, time.UTC), next)
+	assert.True(t, r.OccursOn(start, time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, r.OccursOn(start, time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC)))
+
+	_, err = ParseDates([]string{"25/12"})
+	assert.Error(t, err)
+}
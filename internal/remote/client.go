@@ -0,0 +1,299 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/batch"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+// Client talks to a gateway's HTTP API, so the CLI can chat with a server
+// running elsewhere instead of building an agent of its own
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// ChatResult is the gateway's answer to a message
+type ChatResult struct {
+	Content        string `json:"content"`
+	ConversationID string `json:"conversation_id"`
+	TokensUsed     int    `json:"tokens_used"`
+	ResponseTime   int64  `json:"response_time"` // milliseconds
+}
+
+// SkillInfo describes a skill loaded on the gateway
+type SkillInfo struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Tools       int    `json:"tools"`
+}
+
+// NewClient returns a client for the gateway at baseURL, authenticating
+// with token: the gateway's security.gateway_token or a token from
+// /api/auth/login
+func NewClient(baseURL, token string) (*Client, error) {
+	u, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid gateway URL %q: use http(s)://host:port", baseURL)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("a token is required to use the gateway at %s", u.Host)
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(u.String(), "/"),
+		token:   token,
+		// Answers that use tools can take minutes; requests are bounded by
+		// their contexts instead
+		http: &http.Client{},
+	}, nil
+}
+
+// URL is the gateway's address
+func (c *Client) URL() string {
+	return c.baseURL
+}
+
+// Ping checks the gateway is up and accepts the token
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/api/conversations?limit=1", nil, nil)
+}
+
+// Chat sends message in conversationID, or a new conversation when it is
+// empty. With onChunk the answer is streamed to it as it's written.
+func (c *Client) Chat(ctx context.Context, conversationID, message string, onChunk func(string)) (*ChatResult, error) {
+	body := map[string]string{"conversation_id": conversationID, "message": message}
+	if onChunk == nil {
+		var result ChatResult
+		if err := c.do(ctx, http.MethodPost, "/api/chat", body, &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
+	}
+
+	start := time.Now()
+	resp, err := c.send(ctx, http.MethodPost, "/api/chat/stream", body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var content strings.Builder
+	result := &ChatResult{ConversationID: conversationID}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if data == "[DONE]" {
+			break
+		}
+		var event struct {
+			Chunk          string `json:"chunk"`
+			Error          string `json:"error"`
+			ConversationID string `json:"conversation_id"`
+			TokensUsed     int    `json:"tokens_used"`
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			continue
+		}
+		switch {
+		case event.Error != "":
+			return nil, fmt.Errorf("gateway: %s", event.Error)
+		case event.ConversationID != "":
+			result.ConversationID = event.ConversationID
+			result.TokensUsed = event.TokensUsed
+		case event.Chunk != "":
+			content.WriteString(event.Chunk)
+			onChunk(event.Chunk)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("gateway stream interrupted: %w", err)
+	}
+	result.Content = content.String()
+	result.ResponseTime = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// Conversations lists the most recent conversations that haven't been
+// deleted
+func (c *Client) Conversations(ctx context.Context, limit int) ([]store.Conversation, error) {
+	var convs []store.Conversation
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/conversations?limit=%d", limit), nil, &convs)
+	return convs, err
+}
+
+// Conversation finds a conversation by its ID or the start of it
+func (c *Client) Conversation(ctx context.Context, id string) (*store.Conversation, error) {
+	var conv store.Conversation
+	if err := c.do(ctx, http.MethodGet, "/api/conversations/"+url.PathEscape(id), nil, &conv); err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
+
+// Messages returns a conversation's messages, oldest first
+func (c *Client) Messages(ctx context.Context, conversationID string) ([]store.Message, error) {
+	var msgs []store.Message
+	err := c.do(ctx, http.MethodGet, "/api/conversations/"+url.PathEscape(conversationID)+"/messages?limit=-1", nil, &msgs)
+	return msgs, err
+}
+
+// RenameConversation sets a conversation's title
+func (c *Client) RenameConversation(ctx context.Context, conversationID, title string) error {
+	return c.do(ctx, http.MethodPut, "/api/conversations/"+url.PathEscape(conversationID), map[string]string{"title": title}, nil)
+}
+
+// SetConversationRoute pins a conversation to the fast or smart model, or
+// with auto lets the gateway route each turn
+func (c *Client) SetConversationRoute(ctx context.Context, conversationID, route string) error {
+	return c.do(ctx, http.MethodPut, "/api/conversations/"+url.PathEscape(conversationID), map[string]string{"route": route}, nil)
+}
+
+// DeleteConversation hides a conversation, as 'myrai conversations delete'
+// does locally
+func (c *Client) DeleteConversation(ctx context.Context, conversationID string) error {
+	return c.do(ctx, http.MethodDelete, "/api/conversations/"+url.PathEscape(conversationID), nil, nil)
+}
+
+// Skills lists the skills loaded on the gateway
+func (c *Client) Skills(ctx context.Context) ([]SkillInfo, error) {
+	var skills []SkillInfo
+	err := c.do(ctx, http.MethodGet, "/api/skills", nil, &skills)
+	return skills, err
+}
+
+// SubmitBatch queues a batch job on the gateway. name is the input's file
+// name, whose extension picks the format as for 'myrai batch'.
+func (c *Client) SubmitBatch(ctx context.Context, name string, input []byte) (*batch.Job, error) {
+	contentType := "application/x-ndjson"
+	if ext := strings.ToLower(filepath.Ext(name)); ext != ".json" && ext != ".jsonl" {
+		contentType = "text/plain"
+	}
+	var job batch.Job
+	err := c.do(ctx, http.MethodPost, "/api/batch?name="+url.QueryEscape(filepath.Base(name)),
+		rawBody{contentType: contentType, data: input}, &job)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// BatchJobs lists the gateway's latest batch jobs, newest first
+func (c *Client) BatchJobs(ctx context.Context, limit int) ([]batch.Job, error) {
+	var jobs []batch.Job
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/batch?limit=%d", limit), nil, &jobs)
+	return jobs, err
+}
+
+// BatchJob returns a batch job's status and progress
+func (c *Client) BatchJob(ctx context.Context, id string) (*batch.Job, error) {
+	var job batch.Job
+	if err := c.do(ctx, http.MethodGet, "/api/batch/"+url.PathEscape(id), nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CancelBatch stops a queued or running batch job
+func (c *Client) CancelBatch(ctx context.Context, id string) (*batch.Job, error) {
+	var job batch.Job
+	if err := c.do(ctx, http.MethodDelete, "/api/batch/"+url.PathEscape(id), nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// BatchResults copies a job's results so far to w, in one of the batch
+// output formats
+func (c *Client) BatchResults(ctx context.Context, id, format string, w io.Writer) error {
+	resp, err := c.send(ctx, http.MethodGet, "/api/batch/"+url.PathEscape(id)+"/results?format="+url.QueryEscape(format), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// rawBody is a request body sent as is rather than encoded as JSON
+type rawBody struct {
+	contentType string
+	data        []byte
+}
+
+// do sends a request and decodes the JSON answer into out, if given
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("unexpected answer from the gateway: %w", err)
+	}
+	return nil
+}
+
+// send makes a request, turning error statuses into errors
+func (c *Client) send(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	contentType := "application/json"
+	if raw, ok := body.(rawBody); ok {
+		reader, contentType = bytes.NewReader(raw.data), raw.contentType
+	} else if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("can't reach the gateway at %s: %w", c.baseURL, err)
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if json.Unmarshal(data, &apiErr) != nil || apiErr.Error == "" {
+		apiErr.Error = strings.TrimSpace(string(data))
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("the gateway rejected the token (%s); use its security.gateway_token", apiErr.Error)
+	}
+	return nil, fmt.Errorf("gateway: %s (status %d)", apiErr.Error, resp.StatusCode)
+}
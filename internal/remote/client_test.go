@@ -0,0 +1,151 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeGateway answers like the gateway's API for the token "secret"
+func fakeGateway(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/chat", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"content": "echo: " + req["message"], "conversation_id": "conv-1", "tokens_used": 7,
+		})
+	})
+	mux.HandleFunc("/api/chat/stream", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"chunk\":\"Hel\"}\n\ndata: {\"chunk\":\"lo\"}\n\n")
+		fmt.Fprint(w, "data: {\"conversation_id\":\"conv-2\",\"tokens_used\":3}\n\ndata: [DONE]\n\n")
+	})
+	mux.HandleFunc("/api/conversations/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			if req["title"] == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"title is required"}`)
+				return
+			}
+		}
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":"conversation not found"}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"conv-1","title":"Trip","message_count":2}`)
+	})
+	mux.HandleFunc("/api/batch", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, `{"id":"batch_1","status":"queued","source":%q,"total":%d}`,
+			r.URL.Query().Get("name")+" "+r.Header.Get("Content-Type"), strings.Count(string(body), "\n"))
+	})
+	mux.HandleFunc("/api/batch/batch_1/results", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "results as %s\n", r.URL.Query().Get("format"))
+	})
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid token"}`)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+}
+
+func TestNewClient(t *testing.T) {
+	for _, url := range []string{"", "my-server:8080", "ftp://host", "http://"} {
+		if _, err := NewClient(url, "secret"); err == nil {
+			t.Errorf("expected %q to be refused", url)
+		}
+	}
+	if _, err := NewClient("https://host:8080", ""); err == nil {
+		t.Error("expected a missing token to be refused")
+	}
+	c, err := NewClient("https://host:8080/", "secret")
+	if err != nil || c.URL() != "https://host:8080" {
+		t.Errorf("NewClient() = %v, %v", c, err)
+	}
+}
+
+func TestClientChat(t *testing.T) {
+	server := fakeGateway(t)
+	defer server.Close()
+	c, _ := NewClient(server.URL, "secret")
+	ctx := context.Background()
+
+	result, err := c.Chat(ctx, "", "hi", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.Content != "echo: hi" || result.ConversationID != "conv-1" || result.TokensUsed != 7 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	var streamed strings.Builder
+	result, err = c.Chat(ctx, "", "hi", func(chunk string) { streamed.WriteString(chunk) })
+	if err != nil {
+		t.Fatalf("streaming Chat() error = %v", err)
+	}
+	if streamed.String() != "Hello" || result.Content != "Hello" || result.ConversationID != "conv-2" || result.TokensUsed != 3 {
+		t.Errorf("unexpected streamed result %+v (chunks %q)", result, streamed.String())
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	server := fakeGateway(t)
+	defer server.Close()
+	ctx := context.Background()
+
+	bad, _ := NewClient(server.URL, "wrong")
+	if err := bad.Ping(ctx); err == nil || !strings.Contains(err.Error(), "gateway_token") {
+		t.Errorf("expected the rejected token explained, got %v", err)
+	}
+
+	c, _ := NewClient(server.URL, "secret")
+	conv, err := c.Conversation(ctx, "conv")
+	if err != nil || conv.Title != "Trip" {
+		t.Errorf("Conversation() = %+v, %v", conv, err)
+	}
+	if _, err := c.Conversation(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "conversation not found (status 404)") {
+		t.Errorf("expected the gateway's error, got %v", err)
+	}
+	if err := c.RenameConversation(ctx, "conv-1", ""); err == nil || !strings.Contains(err.Error(), "title is required") {
+		t.Errorf("expected the rename refused, got %v", err)
+	}
+}
+
+func TestClientBatch(t *testing.T) {
+	server := fakeGateway(t)
+	defer server.Close()
+	c, _ := NewClient(server.URL, "secret")
+	ctx := context.Background()
+
+	job, err := c.SubmitBatch(ctx, "/tmp/questions.txt", []byte("one\ntwo\n"))
+	if err != nil {
+		t.Fatalf("SubmitBatch() error = %v", err)
+	}
+	if job.ID != "batch_1" || job.Total != 2 || job.Source != "questions.txt text/plain" {
+		t.Errorf("SubmitBatch() = %+v", job)
+	}
+	job, err = c.SubmitBatch(ctx, "items.jsonl", []byte(`{"message":"hi"}`+"\n"))
+	if err != nil || job.Source != "items.jsonl application/x-ndjson" {
+		t.Errorf("SubmitBatch(jsonl) = %+v, %v", job, err)
+	}
+
+	var out strings.Builder
+	if err := c.BatchResults(ctx, "batch_1", "csv", &out); err != nil {
+		t.Fatalf("BatchResults() error = %v", err)
+	}
+	if out.String() != "results as csv\n" {
+		t.Errorf("BatchResults() wrote %q", out.String())
+	}
+}
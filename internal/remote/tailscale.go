@@ -0,0 +1,107 @@
+// Package remote makes the gateway reachable from outside the home network.
+// The server joins a tailnet as its own machine through Tailscale's embedded
+// node (tsnet): traffic is end-to-end encrypted with WireGuard, no ports are
+// opened on the router and nothing has to be installed on the host. Client
+// is the other end: the CLI using a gateway that runs elsewhere.
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"go.uber.org/zap"
+	"tailscale.com/tsnet"
+)
+
+// upTimeout bounds how long joining the tailnet may take
+const upTimeout = 2 * time.Minute
+
+// Tailscale is the server's node on the tailnet
+type Tailscale struct {
+	server *tsnet.Server
+	https  bool
+	logger *zap.Logger
+	url    string
+}
+
+// NewTailscale prepares a tailnet node from cfg. dataDir holds the node's
+// keys unless cfg.StateDir says otherwise.
+func NewTailscale(cfg config.TailscaleConfig, dataDir string, logger *zap.Logger) (*Tailscale, error) {
+	if cfg.AuthKey == "" {
+		return nil, fmt.Errorf("tailscale auth key is required")
+	}
+	hostname := strings.TrimSpace(cfg.Hostname)
+	if hostname == "" {
+		hostname = "myrai"
+	}
+	stateDir := cfg.StateDir
+	if stateDir == "" {
+		stateDir = filepath.Join(dataDir, "tailscale")
+	}
+	if err := os.MkdirAll(stateDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create tailscale state directory: %w", err)
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	sugar := logger.Sugar()
+	return &Tailscale{
+		server: &tsnet.Server{
+			Hostname: hostname,
+			Dir:      stateDir,
+			AuthKey:  cfg.AuthKey,
+			// tsnet's own logs are very chatty; keep them at debug
+			Logf:     func(format string, args ...any) { sugar.Debugf("tailscale: "+format, args...) },
+			UserLogf: func(format string, args ...any) { sugar.Infof("tailscale: "+format, args...) },
+		},
+		https:  cfg.HTTPS,
+		logger: logger,
+	}, nil
+}
+
+// Listen joins the tailnet and returns a listener for the server, on :443
+// with a tailnet certificate when HTTPS is on and on :80 otherwise
+func (t *Tailscale) Listen(ctx context.Context) (net.Listener, error) {
+	ctx, cancel := context.WithTimeout(ctx, upTimeout)
+	defer cancel()
+
+	status, err := t.server.Up(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join tailnet: %w", err)
+	}
+
+	host := t.server.Hostname
+	if status.Self != nil && status.Self.DNSName != "" {
+		host = strings.TrimSuffix(status.Self.DNSName, ".")
+	}
+
+	var ln net.Listener
+	if t.https {
+		ln, err = t.server.ListenTLS("tcp", ":443")
+		t.url = "https://" + host
+	} else {
+		ln, err = t.server.Listen("tcp", ":80")
+		t.url = "http://" + host
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ln, nil
+}
+
+// URL is where the server can be reached on the tailnet once Listen succeeded
+func (t *Tailscale) URL() string {
+	return t.url
+}
+
+// Close leaves the tailnet
+func (t *Tailscale) Close() error {
+	return t.server.Close()
+}
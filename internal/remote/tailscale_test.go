@@ -0,0 +1,32 @@
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+func TestNewTailscale(t *testing.T) {
+	dataDir := t.TempDir()
+
+	if _, err := NewTailscale(config.TailscaleConfig{}, dataDir, nil); err == nil {
+		t.Error("expected an error without an auth key")
+	}
+
+	ts, err := NewTailscale(config.TailscaleConfig{AuthKey: "tskey-auth-test"}, dataDir, nil)
+	if err != nil {
+		t.Fatalf("NewTailscale() error = %v", err)
+	}
+	if ts.server.Hostname != "myrai" {
+		t.Errorf("hostname = %q, want myrai", ts.server.Hostname)
+	}
+	stateDir := filepath.Join(dataDir, "tailscale")
+	if ts.server.Dir != stateDir {
+		t.Errorf("state dir = %q, want %q", ts.server.Dir, stateDir)
+	}
+	if info, err := os.Stat(stateDir); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("state dir not created privately: %v %v", info, err)
+	}
+}
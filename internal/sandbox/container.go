@@ -0,0 +1,102 @@
+package sandbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// containerWorkdir is where the jail is mounted in the container
+const containerWorkdir = "/work"
+
+// containerRunner runs each command in a new Docker or Podman container
+// that is removed when the command ends
+type containerRunner struct {
+	runtime string
+	image   string
+	network bool
+	cpus    float64
+	dir     string
+	limits  Limits
+}
+
+func (r *containerRunner) Name() string { return Container }
+
+func (r *containerRunner) Run(ctx context.Context, line string) (*Result, error) {
+	runtime, err := r.findRuntime()
+	if err != nil {
+		return nil, err
+	}
+	name := containerName()
+
+	cmd := exec.CommandContext(ctx, runtime, r.args(name, line)...)
+	out := &limitedBuffer{max: r.limits.MaxOutput}
+	cmd.Stdout, cmd.Stderr = out, out
+	cmd.WaitDelay = 5 * time.Second
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		// Killing the client leaves the container running
+		exec.Command(runtime, "rm", "-f", name).Run()
+	}
+	return result(out, err)
+}
+
+// findRuntime returns the configured runtime, or docker or podman,
+// whichever is installed
+func (r *containerRunner) findRuntime() (string, error) {
+	candidates := []string{"docker", "podman"}
+	if r.runtime != "" {
+		candidates = []string{r.runtime}
+	}
+	for _, name := range candidates {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	if r.runtime != "" {
+		return "", fmt.Errorf("container runtime %s not found", r.runtime)
+	}
+	return "", errors.New("the container backend needs docker or podman")
+}
+
+// args returns the run arguments for a locked-down, disposable container
+func (r *containerRunner) args(name, line string) []string {
+	args := []string{"run", "--rm", "--name", name,
+		"--read-only", "--tmpfs", "/tmp",
+		"--cap-drop", "ALL", "--security-opt", "no-new-privileges",
+	}
+	if !r.network {
+		args = append(args, "--network", "none")
+	}
+	if user := containerUser(); user != "" {
+		args = append(args, "--user", user)
+	}
+	if r.limits.MemoryMB > 0 {
+		args = append(args, "--memory", fmt.Sprintf("%dm", r.limits.MemoryMB))
+	}
+	if r.cpus > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(r.cpus, 'f', -1, 64))
+	}
+	if r.limits.MaxProcesses > 0 {
+		args = append(args, "--pids-limit", strconv.Itoa(r.limits.MaxProcesses))
+	}
+	if r.dir != "" {
+		args = append(args, "-v", r.dir+":"+containerWorkdir, "-w", containerWorkdir)
+	} else {
+		args = append(args, "-w", "/tmp")
+	}
+	// Memory is capped by the container; only the CPU time is left to sh
+	cpu := Limits{CPUSeconds: r.limits.CPUSeconds}
+	return append(args, r.image, "sh", "-c", ulimitPrefix(cpu)+line)
+}
+
+func containerName() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return "myrai-sandbox-" + hex.EncodeToString(b)
+}
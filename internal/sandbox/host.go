@@ -0,0 +1,40 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// hostRunner runs commands directly on the host
+type hostRunner struct {
+	dir        string
+	limits     Limits
+	systemdRun string // set to run commands in a transient cgroup scope
+}
+
+func (r *hostRunner) Name() string { return Host }
+
+func (r *hostRunner) Run(ctx context.Context, line string) (*Result, error) {
+	args := []string{"sh", "-c", ulimitPrefix(r.limits) + line}
+	if r.systemdRun != "" {
+		scope := []string{r.systemdRun, "--user", "--scope", "--quiet", "--collect"}
+		if r.limits.MemoryMB > 0 {
+			scope = append(scope, "-p", fmt.Sprintf("MemoryMax=%dM", r.limits.MemoryMB))
+		}
+		if r.limits.MaxProcesses > 0 {
+			scope = append(scope, "-p", fmt.Sprintf("TasksMax=%d", r.limits.MaxProcesses))
+		}
+		args = append(scope, args...)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = r.dir
+	out := &limitedBuffer{max: r.limits.MaxOutput}
+	cmd.Stdout, cmd.Stderr = out, out
+	// A timeout kills everything the command started, not just sh
+	killProcessGroup(cmd)
+	cmd.WaitDelay = 5 * time.Second
+	return result(out, cmd.Run())
+}
@@ -0,0 +1,55 @@
+package sandbox
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// shellOperators separate words the way sh would for the purpose of
+// finding paths, e.g. in "cat a>/etc/x"
+var shellOperators = strings.NewReplacer(";", " ", "|", " ", "&", " ", "<", " ", ">", " ", "(", " ", ")", " ")
+
+// checkPaths rejects a command line that names a path outside dir: an
+// absolute or home-relative path, or one that climbs out with "..".
+// Variables and command substitution could name any path, so they're
+// rejected too. This is a guard against mistakes on the host; only the
+// container backend isolates a command for real.
+func checkPaths(line, dir string) error {
+	if strings.ContainsAny(line, "$`") {
+		return fmt.Errorf("variables and command substitution aren't allowed in the sandbox")
+	}
+	for _, word := range strings.Fields(shellOperators.Replace(line)) {
+		word = strings.Trim(word, `"'`)
+		// Options such as --output=/etc/x carry a path after the =
+		if strings.HasPrefix(word, "-") {
+			if _, value, ok := strings.Cut(word, "="); ok {
+				word = value
+			} else {
+				continue
+			}
+		}
+
+		var path string
+		switch {
+		case strings.HasPrefix(word, "~"):
+			return fmt.Errorf("%s is outside the sandbox directory", word)
+		case strings.HasPrefix(word, "/") || filepath.IsAbs(word):
+			path = word
+		case strings.Contains(word, "..") && !strings.Contains(word, "://"):
+			path = filepath.Join(dir, word)
+		default:
+			continue
+		}
+		if !within(dir, path) {
+			return fmt.Errorf("%s is outside the sandbox directory", word)
+		}
+	}
+	return nil
+}
+
+// within reports whether path is dir or inside it
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && filepath.IsLocal(rel)
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroup starts cmd in a process group of its own and kills the
+// whole group when its context is done
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// containerUser runs containers as the current user, so files written in
+// the jail aren't owned by root
+func containerUser() string {
+	return fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package sandbox
+
+import "os/exec"
+
+// killProcessGroup kills cmd when its context is done; Windows has no
+// process groups to kill as a whole
+func killProcessGroup(cmd *exec.Cmd) {}
+
+// containerUser leaves containers on their image's user, as Windows has no
+// uid to map
+func containerUser() string { return "" }
@@ -0,0 +1,230 @@
+// Package sandbox runs the shell commands the system skill is asked to run,
+// on the host or in a disposable container, jailed to a working directory
+// and under time and resource limits
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+// Backends a command can be sent to
+const (
+	Host      = "host"
+	Container = "container"
+	Deny      = "deny"
+)
+
+// Limits bound what a command may use. Zero is unlimited.
+type Limits struct {
+	Timeout      time.Duration
+	MemoryMB     int
+	CPUSeconds   int
+	MaxProcesses int
+	MaxOutput    int // bytes of output kept
+}
+
+// Result is what a command did
+type Result struct {
+	Output    string
+	ExitCode  int
+	TimedOut  bool
+	Truncated bool // output beyond Limits.MaxOutput was dropped
+	Backend   string
+}
+
+// Runner runs a command line with sh in some environment
+type Runner interface {
+	Name() string
+	Run(ctx context.Context, line string) (*Result, error)
+}
+
+// Policy sends each command to the backend its rules pick, after checking
+// it stays inside the jail
+type Policy struct {
+	workdir string
+	limits  Limits
+	backend string
+	rules   []rule
+	runners map[string]Runner
+	err     error
+}
+
+type rule struct {
+	match   *regexp.Regexp
+	backend string
+}
+
+// New builds the policy cfg describes, creating the jail directory
+func New(cfg config.ExecConfig) (*Policy, error) {
+	limits := Limits{
+		Timeout:      time.Duration(cfg.TimeoutSeconds) * time.Second,
+		MemoryMB:     cfg.MemoryMB,
+		CPUSeconds:   cfg.CPUSeconds,
+		MaxProcesses: cfg.MaxProcesses,
+		MaxOutput:    cfg.MaxOutputKB * 1024,
+	}
+	p := &Policy{
+		workdir: cfg.Workdir,
+		limits:  limits,
+		backend: cfg.Backend,
+		runners: make(map[string]Runner),
+	}
+	if p.backend == "" {
+		p.backend = Host
+	}
+	for _, r := range cfg.Policy {
+		switch r.Backend {
+		case Host, Container, Deny:
+		default:
+			return nil, fmt.Errorf("unknown backend %q for %q", r.Backend, r.Match)
+		}
+		p.rules = append(p.rules, rule{match: compilePattern(r.Match), backend: r.Backend})
+	}
+
+	if p.workdir != "" {
+		if err := os.MkdirAll(p.workdir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create the jail directory: %w", err)
+		}
+	}
+
+	host := &hostRunner{dir: p.workdir, limits: limits}
+	if cfg.Cgroups {
+		path, err := exec.LookPath("systemd-run")
+		if err != nil {
+			return nil, errors.New("tools.exec.cgroups needs systemd-run")
+		}
+		host.systemdRun = path
+	}
+	p.runners[Host] = host
+	p.runners[Container] = &containerRunner{
+		runtime: cfg.Container.Runtime,
+		image:   cfg.Container.Image,
+		network: cfg.Container.Network,
+		cpus:    cfg.Container.CPUs,
+		dir:     p.workdir,
+		limits:  limits,
+	}
+	return p, nil
+}
+
+// Refuse returns a policy that runs nothing, for when the configured one
+// can't be built; commands fail with err rather than run unsandboxed
+func Refuse(err error) *Policy {
+	return &Policy{err: err}
+}
+
+// Default runs commands on the host in the current directory, with no
+// limits but the timeout each call gives
+func Default() *Policy {
+	p, _ := New(config.ExecConfig{})
+	return p
+}
+
+// Backend returns the backend the policy sends line to
+func (p *Policy) Backend(line string) string {
+	line = strings.Join(strings.Fields(line), " ")
+	for _, r := range p.rules {
+		if r.match.MatchString(line) {
+			return r.backend
+		}
+	}
+	return p.backend
+}
+
+// Run runs line on its backend for at most timeout, or the configured
+// limit if that's shorter
+func (p *Policy) Run(ctx context.Context, line string, timeout time.Duration) (*Result, error) {
+	if p.err != nil {
+		return nil, fmt.Errorf("command sandbox unavailable: %w", p.err)
+	}
+	backend := p.Backend(line)
+	if backend == Deny {
+		return nil, errors.New("this command is denied by tools.exec.policy")
+	}
+	// A container sees only the jail, whatever paths the line names
+	if p.workdir != "" && backend == Host {
+		if err := checkPaths(line, p.workdir); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.limits.Timeout > 0 && (timeout <= 0 || timeout > p.limits.Timeout) {
+		timeout = p.limits.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	result, err := p.runners[backend].Run(ctx, line)
+	if result != nil {
+		result.Backend = backend
+		result.TimedOut = errors.Is(ctx.Err(), context.DeadlineExceeded)
+	}
+	return result, err
+}
+
+// compilePattern turns a pattern where * matches anything into a regexp
+// for the whole line
+func compilePattern(pattern string) *regexp.Regexp {
+	pattern = strings.Join(strings.Fields(pattern), " ")
+	quoted := regexp.QuoteMeta(pattern)
+	return regexp.MustCompile("^" + strings.ReplaceAll(quoted, `\*`, ".*") + "$")
+}
+
+// ulimitPrefix sets the per-process limits sh can before running a line
+func ulimitPrefix(limits Limits) string {
+	var prefix string
+	if limits.CPUSeconds > 0 {
+		prefix += fmt.Sprintf("ulimit -t %d; ", limits.CPUSeconds)
+	}
+	if limits.MemoryMB > 0 {
+		prefix += fmt.Sprintf("ulimit -v %d; ", limits.MemoryMB*1024)
+	}
+	return prefix
+}
+
+// result reads what a finished command did
+func result(out *limitedBuffer, err error) (*Result, error) {
+	r := &Result{Output: out.String(), Truncated: out.truncated}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, err
+		}
+		r.ExitCode = exitErr.ExitCode()
+	}
+	return r, nil
+}
+
+// limitedBuffer keeps the first max bytes written to it. It doesn't embed
+// bytes.Buffer, whose ReadFrom would let io.Copy skip the limit.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.max > 0 && b.buf.Len()+len(p) > b.max {
+		b.truncated = true
+		b.buf.Write(p[:max(b.max-b.buf.Len(), 0)])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,170 @@
+package sandbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+func TestPolicy_Backend(t *testing.T) {
+	p, err := New(config.ExecConfig{
+		Backend: Container,
+		Policy: []config.ExecRule{
+			{Match: "git status", Backend: Host},
+			{Match: "curl *", Backend: Container},
+			{Match: "rm *", Backend: Deny},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"git status":             Host,
+		"git  status":            Host,
+		"git push":               Container,
+		"curl -s https://x.test": Container,
+		"rm -r notes":            Deny,
+		"ls":                     Container,
+	}
+	for line, want := range tests {
+		if got := p.Backend(line); got != want {
+			t.Errorf("Backend(%q) = %s, want %s", line, got, want)
+		}
+	}
+
+	if _, err := p.Run(context.Background(), "rm -r notes", time.Second); err == nil {
+		t.Error("Expected a denied command to be refused")
+	}
+	if _, err := New(config.ExecConfig{Policy: []config.ExecRule{{Match: "ls", Backend: "vm"}}}); err == nil {
+		t.Error("Expected an unknown backend to be rejected")
+	}
+}
+
+func TestCheckPaths(t *testing.T) {
+	dir := t.TempDir()
+	allowed := []string{
+		"ls -la",
+		"cat notes/today.md",
+		"cat " + filepath.Join(dir, "a.txt"),
+		"grep -r todo . | head",
+		"curl -s https://example.com/a/../b",
+		"ls a/../b",
+	}
+	for _, line := range allowed {
+		if err := checkPaths(line, dir); err != nil {
+			t.Errorf("Expected %q allowed, got %v", line, err)
+		}
+	}
+
+	denied := []string{
+		"cat /etc/passwd",
+		"ls ~/Documents",
+		"cat ../secret",
+		"cat a/../../secret",
+		"echo hi>/tmp/x",
+		"curl -o /tmp/x https://example.com",
+		"curl --output=/tmp/x https://example.com",
+		"cat $HOME/.ssh/id_rsa",
+		"cat `which myrai`",
+		"cd / && ls",
+	}
+	for _, line := range denied {
+		if err := checkPaths(line, dir); err == nil {
+			t.Errorf("Expected %q denied", line)
+		}
+	}
+}
+
+func TestHostRunner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	dir := t.TempDir()
+	p, err := New(config.ExecConfig{Workdir: dir, TimeoutSeconds: 1, MaxOutputKB: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	r, err := p.Run(ctx, "pwd; exit 3", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := filepath.EvalSymlinks(dir); !strings.Contains(r.Output, want) && !strings.Contains(r.Output, dir) {
+		t.Errorf("Expected the command to run in the jail, got %q", r.Output)
+	}
+	if r.ExitCode != 3 || r.Backend != Host {
+		t.Errorf("Expected exit code 3 on the host, got %d on %s", r.ExitCode, r.Backend)
+	}
+
+	// The configured timeout caps a longer one asked for
+	start := time.Now()
+	r, err = p.Run(ctx, "sleep 10 & sleep 10", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.TimedOut || time.Since(start) > 5*time.Second {
+		t.Errorf("Expected the command killed after 1s, got %+v after %s", r, time.Since(start))
+	}
+
+	r, err = p.Run(ctx, "yes | head -c 5000", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Truncated || len(r.Output) != 1024 {
+		t.Errorf("Expected the output cut at 1KB, got %d bytes", len(r.Output))
+	}
+
+	if _, err := p.Run(ctx, "cat /etc/hostname", 0); err == nil {
+		t.Error("Expected a path outside the jail refused")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContainerArgs(t *testing.T) {
+	r := &containerRunner{
+		image:  "alpine:3.20",
+		dir:    "/home/me/sandbox",
+		cpus:   0.5,
+		limits: Limits{MemoryMB: 256, MaxProcesses: 32, CPUSeconds: 10},
+	}
+	args := r.args("myrai-sandbox-1", "ls -la")
+	joined := strings.Join(args, " ")
+	for _, want := range []string{
+		"run --rm --name myrai-sandbox-1",
+		"--network none",
+		"--memory 256m",
+		"--cpus 0.5",
+		"--pids-limit 32",
+		"-v /home/me/sandbox:/work -w /work",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected %q in %s", want, joined)
+		}
+	}
+	if got := args[len(args)-4:]; !slices.Equal(got, []string{"alpine:3.20", "sh", "-c", "ulimit -t 10; ls -la"}) {
+		t.Errorf("Expected the line run by sh in the image, got %q", got)
+	}
+
+	r.network = true
+	if strings.Contains(strings.Join(r.args("n", "ls"), " "), "--network none") {
+		t.Error("Expected network access when enabled")
+	}
+}
+
+func TestRefuse(t *testing.T) {
+	p := Refuse(os.ErrNotExist)
+	if _, err := p.Run(context.Background(), "ls", time.Second); err == nil {
+		t.Error("Expected a policy that couldn't be built to run nothing")
+	}
+}
@@ -0,0 +1,218 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"filippo.io/age"
+)
+
+// PassphraseEnv holds the passphrase of an age secrets file that has no
+// identity file
+const PassphraseEnv = "MYRAI_SECRETS_PASSPHRASE"
+
+// AgeStore keeps secrets in a file encrypted with age, either to the X25519
+// key in an identity file or with a passphrase
+type AgeStore struct {
+	file       string
+	identities []age.Identity
+	recipients []age.Recipient
+	newKey     string // identity file to create on the first write
+	mu         sync.Mutex
+}
+
+// NewAgeStore opens the secrets in file. With an identity file they are
+// encrypted to its key, which is generated on the first write if the file
+// doesn't exist yet; without one they are encrypted with passphrase.
+func NewAgeStore(file, identity, passphrase string) (*AgeStore, error) {
+	if file == "" {
+		return nil, fmt.Errorf("age: no secrets file configured")
+	}
+	s := &AgeStore{file: file}
+
+	if identity == "" {
+		if passphrase == "" {
+			return nil, fmt.Errorf("age: set %s, or secrets.identity to use a key file", PassphraseEnv)
+		}
+		recipient, err := age.NewScryptRecipient(passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("age: %w", err)
+		}
+		id, err := age.NewScryptIdentity(passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("age: %w", err)
+		}
+		s.recipients = []age.Recipient{recipient}
+		s.identities = []age.Identity{id}
+		return s, nil
+	}
+
+	data, err := os.ReadFile(identity)
+	if os.IsNotExist(err) {
+		s.newKey = identity
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("age: %w", err)
+	}
+	ids, err := age.ParseIdentities(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("age: %s: %w", identity, err)
+	}
+	for _, id := range ids {
+		if x, ok := id.(*age.X25519Identity); ok {
+			s.identities = append(s.identities, x)
+			s.recipients = append(s.recipients, x.Recipient())
+		}
+	}
+	if len(s.recipients) == 0 {
+		return nil, fmt.Errorf("age: no X25519 key in %s", identity)
+	}
+	return s, nil
+}
+
+func (s *AgeStore) Name() string { return BackendAge }
+
+func (s *AgeStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *AgeStore) Set(key, value string) error {
+	if !ValidKey(key) {
+		return fmt.Errorf("invalid secret name: %q", key)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.read()
+	if err != nil {
+		return err
+	}
+	secrets[key] = value
+	return s.write(secrets)
+}
+
+func (s *AgeStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[key]; !ok {
+		return ErrNotFound
+	}
+	delete(secrets, key)
+	return s.write(secrets)
+}
+
+func (s *AgeStore) List() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(secrets))
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// read decrypts the secrets file; a missing file holds no secrets
+func (s *AgeStore) read() (map[string]string, error) {
+	secrets := make(map[string]string)
+	f, err := os.Open(s.file)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("age: %w", err)
+	}
+	defer f.Close()
+
+	if len(s.identities) == 0 {
+		return nil, fmt.Errorf("age: %s exists but its key file is missing", s.file)
+	}
+	r, err := age.Decrypt(f, s.identities...)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, fmt.Errorf("age: wrong passphrase or key for %s", s.file)
+		}
+		return nil, fmt.Errorf("age: %w", err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("age: %w", err)
+	}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("age: %s is not a secrets file: %w", s.file, err)
+	}
+	return secrets, nil
+}
+
+// write encrypts secrets into the file, creating the identity file first
+// if it doesn't exist
+func (s *AgeStore) write(secrets map[string]string) error {
+	if s.newKey != "" {
+		if err := s.generateKey(); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, s.recipients...)
+	if err != nil {
+		return fmt.Errorf("age: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("age: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("age: %w", err)
+	}
+	return writeFile(s.file, buf.Bytes())
+}
+
+// generateKey creates the identity file with a new X25519 key
+func (s *AgeStore) generateKey() error {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		return fmt.Errorf("age: %w", err)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# created: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "# public key: %s\n", id.Recipient())
+	fmt.Fprintf(&sb, "%s\n", id)
+	if err := writeFile(s.newKey, []byte(sb.String())); err != nil {
+		return fmt.Errorf("age: failed to create key file: %w", err)
+	}
+	s.identities = []age.Identity{id}
+	s.recipients = []age.Recipient{id.Recipient()}
+	s.newKey = ""
+	return nil
+}
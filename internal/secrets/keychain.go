@@ -0,0 +1,120 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+)
+
+// keychainService is the service secrets are filed under in the keychain
+const keychainService = "myrai"
+
+// keychainIndex is the entry listing the stored names, as keychains can't be
+// listed portably
+const keychainIndex = ".index"
+
+// KeychainStore keeps secrets in the OS keychain: the macOS Keychain, the
+// Secret Service (GNOME Keyring, KWallet) through libsecret's D-Bus API, or
+// the Windows Credential Manager
+type KeychainStore struct {
+	service string
+	mu      sync.Mutex
+}
+
+// NewKeychainStore files secrets under service
+func NewKeychainStore(service string) *KeychainStore {
+	return &KeychainStore{service: service}
+}
+
+func (k *KeychainStore) Name() string { return BackendKeychain }
+
+func (k *KeychainStore) Get(key string) (string, error) {
+	value, err := keyring.Get(k.service, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("keychain: %w", err)
+	}
+	return value, nil
+}
+
+func (k *KeychainStore) Set(key, value string) error {
+	if !ValidKey(key) {
+		return fmt.Errorf("invalid secret name: %q", key)
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := keyring.Set(k.service, key, value); err != nil {
+		return fmt.Errorf("keychain: %w", err)
+	}
+	return k.updateIndex(func(keys map[string]bool) { keys[key] = true })
+}
+
+func (k *KeychainStore) Delete(key string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	err := keyring.Delete(k.service, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("keychain: %w", err)
+	}
+	return k.updateIndex(func(keys map[string]bool) { delete(keys, key) })
+}
+
+func (k *KeychainStore) List() ([]string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	keys, err := k.index()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]string, 0, len(keys))
+	for key := range keys {
+		list = append(list, key)
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+// index returns the names recorded as stored
+func (k *KeychainStore) index() (map[string]bool, error) {
+	keys := make(map[string]bool)
+	value, err := keyring.Get(k.service, keychainIndex)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return keys, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("keychain: %w", err)
+	}
+	for _, key := range strings.Split(value, "\n") {
+		if key != "" {
+			keys[key] = true
+		}
+	}
+	return keys, nil
+}
+
+// updateIndex changes the recorded names with fn
+func (k *KeychainStore) updateIndex(fn func(keys map[string]bool)) error {
+	keys, err := k.index()
+	if err != nil {
+		return err
+	}
+	fn(keys)
+	list := make([]string, 0, len(keys))
+	for key := range keys {
+		list = append(list, key)
+	}
+	sort.Strings(list)
+	if err := keyring.Set(k.service, keychainIndex, strings.Join(list, "\n")); err != nil {
+		return fmt.Errorf("keychain: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+// Package secrets keeps API keys and tokens somewhere safer than a plaintext
+// .env file. Secrets are named like the environment variables they stand in
+// for, e.g. OPENAI_API_KEY, and are exported into the environment when the
+// config loads, so everything that reads them from there keeps working.
+package secrets
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Backends
+const (
+	BackendEnv      = "env"      // the environment and .env files only
+	BackendKeychain = "keychain" // macOS Keychain, libsecret or Windows Credential Manager
+	BackendAge      = "age"      // an age-encrypted file
+)
+
+var (
+	// ErrNotFound is returned for a secret the store doesn't have
+	ErrNotFound = errors.New("secret not found")
+	// ErrReadOnly is returned when changing secrets in the env backend
+	ErrReadOnly = errors.New("the env secrets backend is read-only; set the variable in your environment or .env file")
+)
+
+// Store holds named secrets
+type Store interface {
+	Name() string
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+	// List returns the names of the stored secrets, sorted
+	List() ([]string, error)
+}
+
+// Options selects and configures a backend
+type Options struct {
+	Backend  string // env (default), keychain or age
+	File     string // age: the encrypted file
+	Identity string // age: identity file
+	// Passphrase encrypts an age file without an identity file, defaulting
+	// to MYRAI_SECRETS_PASSPHRASE
+	Passphrase string
+}
+
+// Open returns the backend opts selects
+func Open(opts Options) (Store, error) {
+	switch strings.ToLower(opts.Backend) {
+	case "", BackendEnv:
+		return envStore{}, nil
+	case BackendKeychain, "keyring":
+		return NewKeychainStore(keychainService), nil
+	case BackendAge:
+		passphrase := opts.Passphrase
+		if passphrase == "" {
+			passphrase = os.Getenv(PassphraseEnv)
+		}
+		return NewAgeStore(opts.File, opts.Identity, passphrase)
+	default:
+		return nil, fmt.Errorf("unknown secrets backend: %s", opts.Backend)
+	}
+}
+
+// keyPattern is what secret names look like: environment variable names
+var keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidKey reports whether key can name a secret
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
+
+// IsSecretKey reports whether an environment variable name looks like it
+// holds a secret, e.g. OPENAI_API_KEY or TELEGRAM_BOT_TOKEN
+func IsSecretKey(key string) bool {
+	key = strings.ToUpper(key)
+	for _, suffix := range []string{"_KEY", "_TOKEN", "_SECRET", "_PASSWORD", "_PASSPHRASE"} {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Export sets an environment variable for each secret in s that isn't set
+// already, so the environment keeps the final say
+func Export(s Store) error {
+	if _, ok := s.(envStore); ok {
+		return nil
+	}
+	keys, err := s.List()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+		value, err := s.Get(key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read secret %s: %w", key, err)
+		}
+		os.Setenv(key, value)
+	}
+	return nil
+}
+
+// MigrateEnvFile moves the secrets in a .env file into s, returning their
+// names. Unless keep is set, they are then removed from the file; other
+// lines are left as they were.
+func MigrateEnvFile(s Store, path string, keep bool) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrated, kept []string
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		key, value, ok := parseEnvLine(line)
+		if !ok || !IsSecretKey(key) || value == "" {
+			kept = append(kept, line)
+			continue
+		}
+		if err := s.Set(key, value); err != nil {
+			return migrated, fmt.Errorf("failed to store %s: %w", key, err)
+		}
+		migrated = append(migrated, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return migrated, err
+	}
+	if keep || len(migrated) == 0 {
+		return migrated, nil
+	}
+
+	content := strings.Join(kept, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	return migrated, writeFile(path, []byte(content))
+}
+
+// parseEnvLine reads a KEY=value line of a .env file
+func parseEnvLine(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	line = strings.TrimPrefix(line, "export ")
+	key, value, ok := strings.Cut(line, "=")
+	if !ok {
+		return "", "", false
+	}
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return key, value, ValidKey(key)
+}
+
+// writeFile replaces path with data readable only by the user, without
+// leaving it half written
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// envStore reads secrets from the environment, where .env files have
+// already been loaded
+type envStore struct{}
+
+func (envStore) Name() string { return BackendEnv }
+
+func (envStore) Get(key string) (string, error) {
+	if value, ok := os.LookupEnv(key); ok {
+		return value, nil
+	}
+	return "", ErrNotFound
+}
+
+func (envStore) Set(key, value string) error { return ErrReadOnly }
+
+func (envStore) Delete(key string) error { return ErrReadOnly }
+
+// List returns the environment variables that look like secrets
+func (envStore) List() ([]string, error) {
+	var keys []string
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if IsSecretKey(key) && value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestAgeStore(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "secrets.age")
+	identity := filepath.Join(dir, "key.txt")
+
+	s, err := NewAgeStore(file, identity, "")
+	if err != nil {
+		t.Fatalf("NewAgeStore failed: %v", err)
+	}
+	if err := s.Set("OPENAI_API_KEY", "sk-test"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, err := os.Stat(identity); err != nil {
+		t.Fatalf("Expected the key file to be generated: %v", err)
+	}
+	data, _ := os.ReadFile(file)
+	if strings.Contains(string(data), "sk-test") {
+		t.Error("Expected the secrets file to be encrypted")
+	}
+
+	// A new store reads them with the generated key
+	s, err = NewAgeStore(file, identity, "")
+	if err != nil {
+		t.Fatalf("NewAgeStore failed: %v", err)
+	}
+	if value, err := s.Get("OPENAI_API_KEY"); err != nil || value != "sk-test" {
+		t.Errorf("Expected sk-test, got %q (%v)", value, err)
+	}
+	if err := s.Delete("OPENAI_API_KEY"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.Get("OPENAI_API_KEY"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// Passphrase-encrypted files need the same passphrase back
+	file = filepath.Join(dir, "passphrase.age")
+	s, err = NewAgeStore(file, "", "correct horse")
+	if err != nil {
+		t.Fatalf("NewAgeStore failed: %v", err)
+	}
+	if err := s.Set("GITHUB_TOKEN", "ghp-test"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	wrong, _ := NewAgeStore(file, "", "battery staple")
+	if _, err := wrong.List(); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("Expected a wrong passphrase error, got %v", err)
+	}
+}
+
+func TestMigrateEnvFile(t *testing.T) {
+	keyring.MockInit()
+	s := NewKeychainStore("myrai-test")
+
+	path := filepath.Join(t.TempDir(), ".env")
+	content := "# keys\nOPENAI_API_KEY=\"sk-test\"\nMYRAI_SERVER_PORT=9090\nexport TELEGRAM_BOT_TOKEN=123:abc\nGITHUB_TOKEN=\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	migrated, err := MigrateEnvFile(s, path, false)
+	if err != nil {
+		t.Fatalf("MigrateEnvFile failed: %v", err)
+	}
+	if strings.Join(migrated, ",") != "OPENAI_API_KEY,TELEGRAM_BOT_TOKEN" {
+		t.Errorf("Unexpected secrets migrated: %v", migrated)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "# keys\nMYRAI_SERVER_PORT=9090\nGITHUB_TOKEN=\n" {
+		t.Errorf("Expected only the secrets removed, got %q", data)
+	}
+
+	keys, err := s.List()
+	if err != nil || strings.Join(keys, ",") != "OPENAI_API_KEY,TELEGRAM_BOT_TOKEN" {
+		t.Errorf("Unexpected keychain contents %v (%v)", keys, err)
+	}
+
+	// Export leaves variables that are already set alone
+	t.Setenv("OPENAI_API_KEY", "from-env")
+	t.Setenv("TELEGRAM_BOT_TOKEN", "")
+	os.Unsetenv("TELEGRAM_BOT_TOKEN")
+	if err := Export(s); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if os.Getenv("OPENAI_API_KEY") != "from-env" || os.Getenv("TELEGRAM_BOT_TOKEN") != "123:abc" {
+		t.Errorf("Unexpected environment OPENAI_API_KEY=%q TELEGRAM_BOT_TOKEN=%q",
+			os.Getenv("OPENAI_API_KEY"), os.Getenv("TELEGRAM_BOT_TOKEN"))
+	}
+}
@@ -0,0 +1,337 @@
+package security
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Content filter profiles
+const (
+	FilterOff    = "off"
+	FilterFamily = "family" // mask profanity, withhold adult content
+)
+
+// Content filter categories
+const (
+	CategoryProfanity = "profanity"
+	CategoryAdult     = "adult"
+)
+
+// FamilyBlockedReply replaces a reply withheld by the family profile
+const FamilyBlockedReply = "🙈 I can't share that answer here. Please ask a grown-up to help with this one."
+
+// adultThreshold is the score at which a reply counts as adult content.
+// Explicit terms score it on their own; ambiguous ones ("sex", "naked") only
+// together, so a biology answer still gets through.
+const adultThreshold = 2
+
+const maskRune = "#"
+
+var defaultProfanity = []string{
+	"arse", "arsehole", "ass", "asshole", "bastard", "bitch", "bollocks",
+	"bullshit", "crap", "cunt", "damn", "dick", "dickhead", "douche",
+	"fuck", "fucker", "fck", "motherfucker", "goddamn", "jackass", "piss", "prick",
+	"shit", "slut", "twat", "wanker", "whore", "wtf", "stfu",
+}
+
+// defaultAdult maps adult terms to their weight
+var defaultAdult = map[string]int{
+	"porn": 2, "porno": 2, "pornography": 2, "pornographic": 2, "xxx": 2,
+	"hentai": 2, "blowjob": 2, "handjob": 2, "dildo": 2, "vibrator": 1,
+	"masturbate": 2, "masturbation": 2, "orgasm": 2, "erotic": 2, "erotica": 2,
+	"fetish": 2, "bdsm": 2, "onlyfans": 2, "stripper": 1, "escort": 1,
+	"sex": 1, "sexy": 1, "sexual": 1, "naked": 1, "nude": 1, "nudes": 2,
+	"boobs": 1, "horny": 2, "genitals": 1, "condom": 1, "hookup": 1,
+}
+
+var (
+	wordPattern = regexp.MustCompile(`[\p{L}\p{N}@$]+(?:[*.][\p{L}\p{N}@$]+)*`)
+	leetSpeak   = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s", ".", "", "*", "")
+	suffixes    = []string{"ings", "ing", "ers", "er", "ed", "es", "s", "y"}
+)
+
+// ContentFilterOptions adjusts the built-in word lists
+type ContentFilterOptions struct {
+	WordList string   // file of extra words to mask, one per line; # starts a comment
+	Block    []string // extra words to mask
+	Allow    []string // words never filtered
+}
+
+// FilterResult is what a filter did to a reply
+type FilterResult struct {
+	Text       string   // the text to deliver
+	Filtered   bool     // whether Text differs from the input
+	Blocked    bool     // whether the whole reply was withheld
+	Categories []string // what was found, sorted
+	Matches    int      // how many words were masked or counted as adult
+}
+
+// ContentFilter screens replies before they reach a channel, masking
+// profanity and withholding adult content. It works from local word lists
+// and sees through common disguises such as "sh1t", "f.u.c.k" or "fuuuck".
+// A nil *ContentFilter lets everything through.
+type ContentFilter struct {
+	profile   string
+	profanity map[string]bool
+	adult     map[string]int
+	allow     map[string]bool
+}
+
+// NewContentFilter builds the filter for a profile. The "off" profile, or an
+// empty one, returns nil.
+func NewContentFilter(profile string, opts ContentFilterOptions) (*ContentFilter, error) {
+	switch strings.ToLower(strings.TrimSpace(profile)) {
+	case "", FilterOff:
+		return nil, nil
+	case FilterFamily:
+	default:
+		return nil, fmt.Errorf("unknown content filter %q: use %s or %s", profile, FilterFamily, FilterOff)
+	}
+
+	f := &ContentFilter{
+		profile:   FilterFamily,
+		profanity: make(map[string]bool),
+		adult:     make(map[string]int),
+		allow:     make(map[string]bool),
+	}
+	for _, w := range defaultProfanity {
+		f.profanity[w] = true
+	}
+	for w, weight := range defaultAdult {
+		f.adult[w] = weight
+	}
+	for _, w := range opts.Block {
+		if w = normalizeWord(w); w != "" {
+			f.profanity[w] = true
+		}
+	}
+	if opts.WordList != "" {
+		words, err := readWordList(opts.WordList)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range words {
+			f.profanity[w] = true
+		}
+	}
+	for _, w := range opts.Allow {
+		if w = normalizeWord(w); w != "" {
+			f.allow[w] = true
+		}
+	}
+	return f, nil
+}
+
+// Profile returns the filter's profile name
+func (f *ContentFilter) Profile() string {
+	if f == nil {
+		return FilterOff
+	}
+	return f.profile
+}
+
+// Apply screens text. Adult content withholds the whole reply; profanity is
+// masked word by word, keeping the first letter ("s###"). The mask avoids
+// "*" so it can't turn into Markdown emphasis on Telegram or Discord.
+func (f *ContentFilter) Apply(text string) FilterResult {
+	if f == nil || text == "" {
+		return FilterResult{Text: text}
+	}
+
+	adultScore, adultMatches, masked := 0, 0, 0
+	out := wordPattern.ReplaceAllStringFunc(text, func(word string) string {
+		if weight, ok := f.lookupAdult(word); ok {
+			adultScore += weight
+			adultMatches++
+			return word
+		}
+		if f.isProfane(word) {
+			masked++
+			return mask(word)
+		}
+		return word
+	})
+
+	if adultScore >= adultThreshold {
+		categories := []string{CategoryAdult}
+		if masked > 0 {
+			categories = append(categories, CategoryProfanity)
+		}
+		return FilterResult{
+			Text:       FamilyBlockedReply,
+			Filtered:   true,
+			Blocked:    true,
+			Categories: categories,
+			Matches:    adultMatches + masked,
+		}
+	}
+	if masked == 0 {
+		return FilterResult{Text: text}
+	}
+	return FilterResult{
+		Text:       out,
+		Filtered:   true,
+		Categories: []string{CategoryProfanity},
+		Matches:    masked,
+	}
+}
+
+// MaskProfanity masks profanity the way Apply does but never withholds the
+// reply, for chains that only want clean language
+func (f *ContentFilter) MaskProfanity(text string) FilterResult {
+	if f == nil || text == "" {
+		return FilterResult{Text: text}
+	}
+	masked := 0
+	out := wordPattern.ReplaceAllStringFunc(text, func(word string) string {
+		if f.isProfane(word) {
+			masked++
+			return mask(word)
+		}
+		return word
+	})
+	if masked == 0 {
+		return FilterResult{Text: text}
+	}
+	return FilterResult{
+		Text:       out,
+		Filtered:   true,
+		Categories: []string{CategoryProfanity},
+		Matches:    masked,
+	}
+}
+
+// Summary describes a result for logs, without repeating the filtered words
+func (r FilterResult) Summary() string {
+	action := "masked"
+	if r.Blocked {
+		action = "withheld"
+	}
+	categories := append([]string(nil), r.Categories...)
+	sort.Strings(categories)
+	return fmt.Sprintf("Reply %s (%s, %d %s)", action, strings.Join(categories, ", "), r.Matches, pluralWord(r.Matches))
+}
+
+func (f *ContentFilter) isProfane(word string) bool {
+	for _, candidate := range candidates(word) {
+		if f.allow[candidate] {
+			return false
+		}
+		if f.profanity[candidate] {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *ContentFilter) lookupAdult(word string) (int, bool) {
+	for _, candidate := range candidates(word) {
+		if f.allow[candidate] {
+			return 0, false
+		}
+		if weight, ok := f.adult[candidate]; ok {
+			return weight, true
+		}
+	}
+	return 0, false
+}
+
+// candidates returns the spellings a word is checked under: as written,
+// with stretched letters squeezed ("fuuuck"), and without a common suffix
+// ("shitty" → "shit")
+func candidates(word string) []string {
+	base := normalizeWord(word)
+	if base == "" {
+		return nil
+	}
+	out := []string{base}
+	for _, keep := range []int{1, 2} {
+		if squeezed := squeeze(base, keep); squeezed != base {
+			out = append(out, squeezed)
+		}
+	}
+	for _, w := range append([]string(nil), out...) {
+		for _, suffix := range suffixes {
+			stem := strings.TrimSuffix(w, suffix)
+			if stem == w || utf8.RuneCountInString(stem) < 3 {
+				continue
+			}
+			out = append(out, stem)
+			// "shitty" → "shitt" → "shit"
+			if n := len(stem); n > 1 && stem[n-1] == stem[n-2] {
+				out = append(out, stem[:n-1])
+			}
+		}
+	}
+	return out
+}
+
+// normalizeWord lowercases a word and undoes leetspeak and separators
+func normalizeWord(word string) string {
+	return leetSpeak.Replace(strings.ToLower(strings.TrimSpace(word)))
+}
+
+// squeeze collapses runs of three or more of the same letter to keep letters
+func squeeze(word string, keep int) string {
+	runes := []rune(word)
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && runes[j] == runes[i] {
+			j++
+		}
+		if j-i >= 3 {
+			b.WriteString(strings.Repeat(string(runes[i]), keep))
+		} else {
+			b.WriteString(string(runes[i:j]))
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// mask keeps a word's first letter and hides the rest
+func mask(word string) string {
+	first, size := utf8.DecodeRuneInString(word)
+	if !unicode.IsLetter(first) {
+		return strings.Repeat(maskRune, utf8.RuneCountInString(word))
+	}
+	return string(first) + strings.Repeat(maskRune, utf8.RuneCountInString(word[size:]))
+}
+
+func readWordList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open word list: %w", err)
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if w := normalizeWord(line); w != "" {
+			words = append(words, w)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read word list: %w", err)
+	}
+	return words, nil
+}
+
+func pluralWord(n int) string {
+	if n == 1 {
+		return "word"
+	}
+	return "words"
+}
@@ -0,0 +1,130 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestContentFilter_Off(t *testing.T) {
+	for _, profile := range []string{"", "off", " OFF "} {
+		f, err := NewContentFilter(profile, ContentFilterOptions{})
+		if err != nil {
+			t.Fatalf("profile %q: %v", profile, err)
+		}
+		if f != nil {
+			t.Errorf("profile %q should not filter", profile)
+		}
+		if got := f.Apply("well, shit"); got.Filtered || got.Text != "well, shit" {
+			t.Errorf("nil filter changed the text: %+v", got)
+		}
+	}
+
+	if _, err := NewContentFilter("strict", ContentFilterOptions{}); err == nil {
+		t.Error("unknown profile should be rejected")
+	}
+}
+
+func TestContentFilter_MasksProfanity(t *testing.T) {
+	f, err := NewContentFilter(FilterFamily, ContentFilterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Well, Shit happens.", "Well, S### happens."},
+		{"That was a sh1tty movie", "That was a s##### movie"},
+		{"What the f.u.c.k", "What the f######"},
+		{"fuuuuck this homework", "f###### this homework"},
+		{"Damned if I know", "D##### if I know"},
+	}
+	for _, tt := range tests {
+		got := f.Apply(tt.input)
+		if got.Text != tt.want {
+			t.Errorf("Apply(%q) = %q, want %q", tt.input, got.Text, tt.want)
+		}
+		if !got.Filtered || got.Blocked || got.Categories[0] != CategoryProfanity {
+			t.Errorf("Apply(%q) result = %+v", tt.input, got)
+		}
+	}
+}
+
+func TestContentFilter_LeavesInnocentWords(t *testing.T) {
+	f, _ := NewContentFilter(FilterFamily, ContentFilterOptions{})
+
+	inputs := []string{
+		"Please assess the class assignment before the assembly.",
+		"Scunthorpe and Essex are places in England.",
+		"Cocktail sauce goes well with shrimp.",
+		"Bees can tell the sex of a flower's visitors.",
+		"Dickens wrote Great Expectations in 1861.",
+	}
+	for _, input := range inputs {
+		if got := f.Apply(input); got.Filtered {
+			t.Errorf("Apply(%q) filtered innocent text: %q", input, got.Text)
+		}
+	}
+}
+
+func TestContentFilter_WithholdsAdultContent(t *testing.T) {
+	f, _ := NewContentFilter(FilterFamily, ContentFilterOptions{})
+
+	got := f.Apply("Here are some p0rn sites you could try")
+	if !got.Blocked || got.Text != FamilyBlockedReply {
+		t.Errorf("explicit term not withheld: %+v", got)
+	}
+
+	// Two ambiguous terms together add up
+	got = f.Apply("The pictures show naked people having sex")
+	if !got.Blocked {
+		t.Errorf("ambiguous terms not withheld: %+v", got)
+	}
+	if got.Summary() != "Reply withheld (adult, 2 words)" {
+		t.Errorf("Summary() = %q", got.Summary())
+	}
+}
+
+func TestContentFilter_MaskProfanityOnly(t *testing.T) {
+	f, _ := NewContentFilter(FilterFamily, ContentFilterOptions{})
+
+	got := f.MaskProfanity("Damn, the porn filter blocked it")
+	if got.Blocked || got.Text != "D###, the porn filter blocked it" || got.Matches != 1 {
+		t.Errorf("MaskProfanity = %+v; want only the swear word masked", got)
+	}
+	if got := f.MaskProfanity("All clean"); got.Filtered {
+		t.Errorf("clean text was filtered: %+v", got)
+	}
+}
+
+func TestContentFilter_CustomWords(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "words.txt")
+	if err := os.WriteFile(path, []byte("# house rules\nstupid\nidiot # rude\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewContentFilter(FilterFamily, ContentFilterOptions{
+		WordList: path,
+		Block:    []string{"Dummy"},
+		Allow:    []string{"damn"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := f.Apply("Don't be stupid, dummy. Damn it.")
+	if got.Text != "Don't be s#####, d####. Damn it." {
+		t.Errorf("Apply() = %q", got.Text)
+	}
+	if got.Matches != 2 || !strings.Contains(got.Summary(), "masked") {
+		t.Errorf("result = %+v", got)
+	}
+
+	if _, err := NewContentFilter(FilterFamily, ContentFilterOptions{WordList: filepath.Join(dir, "missing.txt")}); err == nil {
+		t.Error("missing word list should be an error")
+	}
+}
@@ -0,0 +1,258 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FilePolicyOptions says which files tools may touch
+type FilePolicyOptions struct {
+	// Allow lists the paths tools may use. A pattern with a slash matches
+	// that path and everything under it, and a bare name like "*.pem"
+	// matches any file or folder of that name. * stays within one folder,
+	// ** crosses folders. Empty allows every path Deny doesn't match.
+	Allow []string
+	// Deny lists paths no tool may use, whatever Allow says
+	Deny []string
+	// ReadOnly lists folders tools may read but not change
+	ReadOnly []string
+	// MaxFileSize is the largest file, in bytes, a tool may read or write.
+	// Zero is unlimited.
+	MaxFileSize int64
+}
+
+// PathDeniedError is returned for a path a FilePolicy refuses. Its message
+// goes back to the model, so it says why and what is allowed instead.
+type PathDeniedError struct {
+	Path   string
+	Reason string
+}
+
+func (e *PathDeniedError) Error() string {
+	return fmt.Sprintf("access to %s denied by tools.filesystem: %s", e.Path, e.Reason)
+}
+
+// FilePolicy decides which paths the file tools may read and write.
+// Symlinks are followed before deciding, so a link can't lead a tool
+// somewhere the policy refuses. A nil *FilePolicy allows everything.
+type FilePolicy struct {
+	allow    []pathPattern
+	deny     []pathPattern
+	readOnly []string
+	maxSize  int64
+	err      error
+}
+
+type pathPattern struct {
+	pattern string
+	match   *regexp.Regexp
+}
+
+// NewFilePolicy builds the policy opts describe
+func NewFilePolicy(opts FilePolicyOptions) (*FilePolicy, error) {
+	p := &FilePolicy{maxSize: opts.MaxFileSize}
+	var err error
+	if p.allow, err = compilePathPatterns(opts.Allow); err != nil {
+		return nil, err
+	}
+	if p.deny, err = compilePathPatterns(opts.Deny); err != nil {
+		return nil, err
+	}
+	for _, root := range opts.ReadOnly {
+		if strings.TrimSpace(root) == "" {
+			return nil, errors.New("empty read-only folder")
+		}
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		p.readOnly = append(p.readOnly, abs)
+		if real := resolveSymlinks(abs); real != abs {
+			p.readOnly = append(p.readOnly, real)
+		}
+	}
+	return p, nil
+}
+
+// DenyAllFiles returns a policy that refuses every path, for when the
+// configured one can't be built; tools fail with err rather than run
+// unrestricted
+func DenyAllFiles(err error) *FilePolicy {
+	return &FilePolicy{err: err}
+}
+
+// CheckRead decides whether a tool may read path, a file or a folder. It
+// returns the path with symlinks resolved, which is what the tool should
+// open.
+func (p *FilePolicy) CheckRead(path string) (string, error) {
+	if p == nil {
+		return path, nil
+	}
+	abs, real, err := p.check(path)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(real); err == nil && info.Mode().IsRegular() && p.maxSize > 0 && info.Size() > p.maxSize {
+		return "", &PathDeniedError{Path: abs, Reason: fmt.Sprintf("the file is %s, over the %s limit", formatSize(info.Size()), formatSize(p.maxSize))}
+	}
+	return real, nil
+}
+
+// CheckWrite decides whether a tool may create, change or remove path,
+// writing size bytes to it. It returns the path with symlinks resolved.
+func (p *FilePolicy) CheckWrite(path string, size int64) (string, error) {
+	if p == nil {
+		return path, nil
+	}
+	abs, real, err := p.check(path)
+	if err != nil {
+		return "", err
+	}
+	for _, root := range p.readOnly {
+		if within(root, abs) || within(root, real) {
+			return "", &PathDeniedError{Path: abs, Reason: root + " is read-only"}
+		}
+	}
+	if p.maxSize > 0 && size > p.maxSize {
+		return "", &PathDeniedError{Path: abs, Reason: fmt.Sprintf("%s is over the %s file size limit", formatSize(size), formatSize(p.maxSize))}
+	}
+	return real, nil
+}
+
+// check applies the allow and deny lists to path and to where its
+// symlinks lead
+func (p *FilePolicy) check(path string) (abs, real string, err error) {
+	if p.err != nil {
+		return "", "", fmt.Errorf("file access unavailable, tools.filesystem is invalid: %w", p.err)
+	}
+	abs, err = filepath.Abs(path)
+	if err != nil {
+		return "", "", err
+	}
+	real = resolveSymlinks(abs)
+
+	if reason := p.refuse(abs); reason != "" {
+		return "", "", &PathDeniedError{Path: abs, Reason: reason}
+	}
+	if real != abs {
+		if reason := p.refuse(real); reason != "" {
+			return "", "", &PathDeniedError{Path: abs, Reason: fmt.Sprintf("it links to %s, and %s", real, reason)}
+		}
+	}
+	return abs, real, nil
+}
+
+// refuse says why path isn't allowed, or returns "" if it is
+func (p *FilePolicy) refuse(path string) string {
+	slashed := filepath.ToSlash(path)
+	for _, d := range p.deny {
+		if d.match.MatchString(slashed) {
+			return fmt.Sprintf("it matches the deny rule %q", d.pattern)
+		}
+	}
+	if len(p.allow) == 0 {
+		return ""
+	}
+	for _, a := range p.allow {
+		if a.match.MatchString(slashed) {
+			return ""
+		}
+	}
+	allowed := make([]string, len(p.allow))
+	for i, a := range p.allow {
+		allowed[i] = a.pattern
+	}
+	return "it is outside the allowed paths: " + strings.Join(allowed, ", ")
+}
+
+// resolveSymlinks follows the symlinks in path. Parts that don't exist yet,
+// like a file about to be written, are kept as they are.
+func resolveSymlinks(path string) string {
+	var rest []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...)
+		}
+		if filepath.Dir(dir) == dir {
+			return path
+		}
+		rest = append([]string{filepath.Base(dir)}, rest...)
+	}
+}
+
+// compilePathPatterns turns allow or deny globs into regexps for slashed
+// absolute paths
+func compilePathPatterns(patterns []string) ([]pathPattern, error) {
+	var compiled []pathPattern
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			return nil, errors.New("empty path pattern")
+		}
+		glob := filepath.ToSlash(pattern)
+		var expr string
+		if strings.Contains(glob, "/") {
+			// A path: it and everything under it
+			abs, err := filepath.Abs(pattern)
+			if err != nil {
+				return nil, err
+			}
+			expr = globToRegexp(filepath.ToSlash(abs))
+			// A folder that is itself a link also matches where it leads
+			if real := resolveSymlinks(abs); real != abs && !strings.ContainsAny(pattern, "*?") {
+				expr = "(" + expr + "|" + regexp.QuoteMeta(filepath.ToSlash(real)) + ")"
+			}
+			expr = "^" + expr + "(/.*)?$"
+		} else {
+			// A name: any file or folder called that
+			expr = "(^|/)" + globToRegexp(glob) + "(/.*)?$"
+		}
+		match, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, pathPattern{pattern: pattern, match: match})
+	}
+	return compiled, nil
+}
+
+// globToRegexp translates a glob where * stays within a folder, ** crosses
+// folders and ? is one character
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			b.WriteString("[^/]*")
+		case glob[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return b.String()
+}
+
+// within reports whether path is dir or inside it
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
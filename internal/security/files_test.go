@@ -0,0 +1,140 @@
+package security
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFilePolicy_AllowDeny(t *testing.T) {
+	dir := t.TempDir()
+	projects := filepath.Join(dir, "projects")
+	p, err := NewFilePolicy(FilePolicyOptions{
+		Allow: []string{projects, filepath.Join(dir, "shared", "*.txt")},
+		Deny:  []string{"*.pem", ".env", filepath.Join(projects, "secret")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := []string{
+		projects,
+		filepath.Join(projects, "app", "main.go"),
+		filepath.Join(dir, "shared", "todo.txt"),
+	}
+	for _, path := range allowed {
+		if _, err := p.CheckRead(path); err != nil {
+			t.Errorf("Expected %s allowed, got %v", path, err)
+		}
+	}
+
+	denied := []string{
+		dir,
+		filepath.Join(dir, "projects-old", "a.go"),
+		filepath.Join(dir, "shared", "nested", "todo.txt"),
+		filepath.Join(projects, "certs", "server.pem"),
+		filepath.Join(projects, ".env"),
+		filepath.Join(projects, "secret", "key"),
+		filepath.Join(projects, "..", "other"),
+	}
+	for _, path := range denied {
+		_, err := p.CheckRead(path)
+		var denial *PathDeniedError
+		if !errors.As(err, &denial) {
+			t.Errorf("Expected %s denied, got %v", path, err)
+		}
+	}
+
+	var nilPolicy *FilePolicy
+	if _, err := nilPolicy.CheckWrite("/etc/passwd", 10); err != nil {
+		t.Errorf("Expected a nil policy to allow everything, got %v", err)
+	}
+}
+
+func TestFilePolicy_ReadOnlyAndSize(t *testing.T) {
+	dir := t.TempDir()
+	docs := filepath.Join(dir, "docs")
+	if err := os.MkdirAll(docs, 0755); err != nil {
+		t.Fatal(err)
+	}
+	big := filepath.Join(dir, "big.log")
+	if err := os.WriteFile(big, make([]byte, 2048), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewFilePolicy(FilePolicyOptions{ReadOnly: []string{docs}, MaxFileSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := p.CheckRead(filepath.Join(docs, "manual.md")); err != nil {
+		t.Errorf("Expected a read-only folder readable, got %v", err)
+	}
+	if _, err := p.CheckWrite(filepath.Join(docs, "manual.md"), 10); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("Expected a write to a read-only folder denied, got %v", err)
+	}
+	if _, err := p.CheckWrite(filepath.Join(dir, "notes.md"), 10); err != nil {
+		t.Errorf("Expected a write elsewhere allowed, got %v", err)
+	}
+
+	if _, err := p.CheckRead(big); err == nil || !strings.Contains(err.Error(), "limit") {
+		t.Errorf("Expected a file over the size limit denied, got %v", err)
+	}
+	if _, err := p.CheckWrite(filepath.Join(dir, "out.txt"), 4096); err == nil {
+		t.Error("Expected a write over the size limit denied")
+	}
+}
+
+func TestFilePolicy_SymlinkEscape(t *testing.T) {
+	dir := t.TempDir()
+	workspace := filepath.Join(dir, "workspace")
+	outside := filepath.Join(dir, "outside")
+	for _, d := range []string{workspace, outside} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(outside, "id_rsa"), []byte("key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "id_rsa"), filepath.Join(workspace, "key")); err != nil {
+		t.Skip("symlinks unsupported:", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(workspace, "out")); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewFilePolicy(FilePolicyOptions{Allow: []string{workspace}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{
+		filepath.Join(workspace, "key"),
+		filepath.Join(workspace, "out", "id_rsa"),
+		filepath.Join(workspace, "out", "new.txt"),
+	} {
+		if _, err := p.CheckWrite(path, 0); err == nil || !strings.Contains(err.Error(), "links to") {
+			t.Errorf("Expected %s denied as a symlink escape, got %v", path, err)
+		}
+	}
+
+	real, err := p.CheckRead(filepath.Join(workspace, "new.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(real) != "new.txt" {
+		t.Errorf("Expected a file that doesn't exist yet kept, got %s", real)
+	}
+}
+
+func TestDenyAllFiles(t *testing.T) {
+	p := DenyAllFiles(errors.New("bad pattern"))
+	if _, err := p.CheckRead("README.md"); err == nil {
+		t.Error("Expected a policy that couldn't be built to allow nothing")
+	}
+	if _, err := NewFilePolicy(FilePolicyOptions{Deny: []string{" "}}); err == nil {
+		t.Error("Expected an empty pattern rejected")
+	}
+}
@@ -0,0 +1,138 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/journal"
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"gorm.io/gorm"
+)
+
+// MaxEntries caps how many entries one answer lists
+const MaxEntries = 200
+
+// ActivitySkill answers "what did you do?" from the activity journal
+type ActivitySkill struct {
+	*skills.BaseSkill
+	journal *journal.Journal
+	now     func() time.Time
+}
+
+// NewActivitySkill creates a new activity skill
+func NewActivitySkill(db *gorm.DB) (*ActivitySkill, error) {
+	j, err := journal.New(db, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ActivitySkill{
+		BaseSkill: skills.NewBaseSkill("activity", "Log of what the assistant did on its own", "1.0.0"),
+		journal:   j,
+		now:       time.Now,
+	}
+	s.registerTools()
+	return s, nil
+}
+
+func (s *ActivitySkill) registerTools() {
+	s.AddTool(skills.Tool{
+		Name:     "what_did_you_do",
+		ReadOnly: true,
+		Description: "List what the assistant did without being asked: scheduled jobs it ran, plan steps, " +
+			"notifications it sent, files it wrote and commands it ran. Use for questions like 'what did you do today?'",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"period": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"today", "yesterday", "week"},
+					"description": "Time period (default: today)",
+				},
+				"kind": map[string]interface{}{
+					"type":        "string",
+					"enum":        journal.Kinds,
+					"description": "Only list one kind of action",
+				},
+			},
+		},
+		Handler: s.handleWhatDidYouDo,
+	})
+}
+
+func (s *ActivitySkill) handleWhatDidYouDo(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	period, _ := args["period"].(string)
+	if period == "" {
+		period = "today"
+	}
+	kind, _ := args["kind"].(string)
+	if kind != "" && !isKind(kind) {
+		return nil, fmt.Errorf("unknown kind %q: use one of %s", kind, strings.Join(journal.Kinds, ", "))
+	}
+
+	now := s.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var since, until time.Time
+	switch period {
+	case "today":
+		since = today
+	case "yesterday":
+		since, until = today.AddDate(0, 0, -1), today
+	case "week":
+		since = today.AddDate(0, 0, -6)
+	default:
+		return nil, fmt.Errorf("unknown period %q: use today, yesterday or week", period)
+	}
+
+	// Scheduled jobs and other household-wide actions are recorded for the
+	// shared user, so everyone sees them alongside their own
+	userIDs := []string{getUserID(ctx)}
+	if userIDs[0] != household.SharedUserID {
+		userIDs = append(userIDs, household.SharedUserID)
+	}
+
+	entries, err := s.journal.List(journal.Filter{
+		UserIDs: userIDs,
+		Kind:    kind,
+		Since:   since,
+		Until:   until,
+		Limit:   MaxEntries,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	failed := 0
+	for _, e := range entries {
+		if e.Status == journal.StatusFailed {
+			failed++
+		}
+	}
+	return map[string]interface{}{
+		"period":  period,
+		"count":   len(entries),
+		"failed":  failed,
+		"entries": entries,
+		"summary": journal.Format(entries, locale.FromContext(ctx)),
+	}, nil
+}
+
+func isKind(kind string) bool {
+	for _, k := range journal.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func getUserID(ctx context.Context) string {
+	if userID, ok := ctx.Value("user_id").(string); ok && userID != "" {
+		return userID
+	}
+	return household.SharedUserID
+}
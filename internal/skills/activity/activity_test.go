@@ -0,0 +1,50 @@
+package activity
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/journal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestActivitySkill_WhatDidYouDo(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	s, err := NewActivitySkill(db)
+	require.NoError(t, err)
+
+	j, err := journal.New(db, nil)
+	require.NoError(t, err)
+	j.Record(household.SharedUserID, journal.KindScheduled, `Ran scheduled job "backup"`, "", nil)
+	j.Record("alice", journal.KindCommand, "Ran `make deploy`", "", errors.New("exit status 2"))
+	j.Record("bob", journal.KindFile, "Wrote bob.txt", "", nil)
+
+	ctx := context.WithValue(context.Background(), "user_id", "alice")
+	result, err := s.handleWhatDidYouDo(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	out := result.(map[string]interface{})
+	assert.Equal(t, 2, out["count"])
+	assert.Equal(t, 1, out["failed"])
+	assert.Contains(t, out["summary"], "backup")
+	assert.NotContains(t, out["summary"], "bob.txt")
+
+	result, err = s.handleWhatDidYouDo(ctx, map[string]interface{}{"kind": journal.KindCommand})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.(map[string]interface{})["count"])
+
+	// Nothing was recorded yesterday
+	result, err = s.handleWhatDidYouDo(ctx, map[string]interface{}{"period": "yesterday"})
+	require.NoError(t, err)
+	assert.Equal(t, "Nothing recorded.", result.(map[string]interface{})["summary"])
+
+	_, err = s.handleWhatDidYouDo(ctx, map[string]interface{}{"period": "decade"})
+	assert.Error(t, err)
+	_, err = s.handleWhatDidYouDo(ctx, map[string]interface{}{"kind": "dance"})
+	assert.Error(t, err)
+}
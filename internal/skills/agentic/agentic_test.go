@@ -2,9 +2,14 @@ package agentic
 
 import (
 	"context"
+	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/security"
 )
 
 func TestNewAgenticSkill(t *testing.T) {
@@ -228,6 +233,51 @@ type MyStruct struct {
 	}
 }
 
+func TestFilePolicyDenials(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "secret"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"main.go":        "package main // TODO: ship it",
+		"secret/keys.go": "package secret // TODO: rotate",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	policy, err := security.NewFilePolicy(security.FilePolicyOptions{
+		Allow: []string{tmpDir},
+		Deny:  []string{filepath.Join(tmpDir, "secret")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	skill := NewAgenticSkill("/tmp")
+	skill.SetFilePolicy(policy)
+	ctx := context.Background()
+
+	_, err = skill.handleAnalyzeCodeFile(ctx, map[string]interface{}{"path": filepath.Join(tmpDir, "secret", "keys.go")})
+	var denial *security.PathDeniedError
+	if !errors.As(err, &denial) {
+		t.Errorf("expected a denied file to be refused, got %v", err)
+	}
+	if _, err := skill.handleGitStatus(ctx, map[string]interface{}{"path": "/"}); !errors.As(err, &denial) {
+		t.Errorf("expected a path outside the allowed ones to be refused, got %v", err)
+	}
+
+	result, err := skill.handleSearchCode(ctx, map[string]interface{}{"pattern": "TODO", "path": tmpDir, "context": float64(0)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results := result.(map[string]string)["results"]
+	if !strings.Contains(results, "main.go:1:") || strings.Contains(results, "keys.go") {
+		t.Errorf("expected only matches in allowed files, got %q", results)
+	}
+}
+
 func TestHandleSearchCodeMissingPattern(t *testing.T) {
 	skill := NewAgenticSkill("/tmp")
 	ctx := context.Background()
@@ -250,6 +300,61 @@ func TestHandleGitStatusNotRepo(t *testing.T) {
 	}
 }
 
+func TestHandleGitDiffStagedAndBase(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\n"), 0644)
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	git("checkout", "-q", "-b", "feature")
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("two\n"), 0644)
+	git("commit", "-q", "-am", "second")
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("staged\n"), 0644)
+	git("add", "b.txt")
+
+	skill := NewAgenticSkill(dir)
+	ctx := context.Background()
+
+	result, err := skill.handleGitDiff(ctx, map[string]interface{}{"path": dir, "staged": true})
+	if err != nil {
+		t.Fatalf("staged diff failed: %v", err)
+	}
+	diff := result.(map[string]string)["diff"]
+	if !strings.Contains(diff, "+staged") || strings.Contains(diff, "+two") {
+		t.Errorf("expected only the staged change, got:\n%s", diff)
+	}
+
+	result, err = skill.handleGitDiff(ctx, map[string]interface{}{"path": dir, "base": "main"})
+	if err != nil {
+		t.Fatalf("branch diff failed: %v", err)
+	}
+	diff = result.(map[string]string)["diff"]
+	if !strings.Contains(diff, "+two") || strings.Contains(diff, "+staged") {
+		t.Errorf("expected only the branch's commits, got:\n%s", diff)
+	}
+
+	log, err := skill.handleGitLog(ctx, map[string]interface{}{"path": dir, "base": "main"})
+	if err != nil {
+		t.Fatalf("branch log failed: %v", err)
+	}
+	if commits := log.([]map[string]string); len(commits) != 1 || commits[0]["message"] != "second" {
+		t.Errorf("expected only the branch's commit, got %v", commits)
+	}
+
+	if _, err := skill.handleGitDiff(ctx, map[string]interface{}{"path": dir, "base": "--output=/tmp/x"}); err == nil {
+		t.Error("expected options passed as a revision to be refused")
+	}
+}
+
 func TestHandleGitBlameMissingFile(t *testing.T) {
 	skill := NewAgenticSkill("/tmp")
 	ctx := context.Background()
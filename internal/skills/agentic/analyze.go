@@ -21,6 +21,10 @@ func (s *AgenticSkill) handleAnalyzeProjectStructure(ctx context.Context, args m
 		path = p
 	}
 
+	if _, err := s.filePolicy().CheckRead(path); err != nil {
+		return nil, err
+	}
+
 	depth := 3
 	if d, ok := args["depth"].(float64); ok {
 		depth = int(d)
@@ -45,7 +49,11 @@ func (s *AgenticSkill) handleAnalyzeCodeFile(ctx context.Context, args map[strin
 		return nil, fmt.Errorf("path is required")
 	}
 
-	content, err := os.ReadFile(path)
+	real, err := s.filePolicy().CheckRead(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(real)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
@@ -68,6 +76,11 @@ func (s *AgenticSkill) handleAnalyzeCodeFile(ctx context.Context, args map[strin
 		analyzeJSFile(string(content), result)
 	}
 
+	// The language server knows the file better than the patterns above
+	if symbols := s.lspSymbols(ctx, real); len(symbols) > 0 {
+		result["symbols"] = symbols
+	}
+
 	return result, nil
 }
 
@@ -92,8 +105,12 @@ func (s *AgenticSkill) handleSearchCode(ctx context.Context, args map[string]int
 		context = int(c)
 	}
 
+	if _, err := s.filePolicy().CheckRead(path); err != nil {
+		return nil, err
+	}
+
 	cmd := exec.CommandContext(ctx, "sh", "-c",
-		fmt.Sprintf("grep -rn --include=%q -C %d %q %s 2>/dev/null | head -100",
+		fmt.Sprintf("grep -rn --null --include=%q -C %d %q %q 2>/dev/null | head -100",
 			filePattern, context, pattern, path))
 
 	output, err := cmd.Output()
@@ -102,7 +119,7 @@ func (s *AgenticSkill) handleSearchCode(ctx context.Context, args map[string]int
 	}
 
 	return map[string]string{
-		"results": string(output),
+		"results": strings.Join(s.allowedMatches(output), "\n"),
 	}, nil
 }
 
@@ -112,21 +129,20 @@ func (s *AgenticSkill) handleFindTodos(ctx context.Context, args map[string]inte
 		path = p
 	}
 
+	if _, err := s.filePolicy().CheckRead(path); err != nil {
+		return nil, err
+	}
+
 	patterns := []string{"TODO", "FIXME", "HACK", "XXX", "BUG", "NOTE"}
 	allResults := []map[string]string{}
 
 	for _, pattern := range patterns {
 		cmd := exec.CommandContext(ctx, "sh", "-c",
-			fmt.Sprintf("grep -rn --include='*.go' --include='*.py' --include='*.js' --include='*.ts' --include='*.md' %q %s 2>/dev/null | head -20",
+			fmt.Sprintf("grep -rn --null --include='*.go' --include='*.py' --include='*.js' --include='*.ts' --include='*.md' %q %q 2>/dev/null | head -20",
 				pattern, path))
 
 		output, _ := cmd.Output()
-		lines := strings.Split(string(output), "\n")
-
-		for _, line := range lines {
-			if line == "" {
-				continue
-			}
+		for _, line := range s.allowedMatches(output) {
 			parts := strings.SplitN(line, ":", 3)
 			if len(parts) >= 3 {
 				allResults = append(allResults, map[string]string{
@@ -142,6 +158,34 @@ func (s *AgenticSkill) handleFindTodos(ctx context.Context, args map[string]inte
 	return allResults, nil
 }
 
+// allowedMatches drops the lines grep --null printed for files the policy
+// refuses, such as denied files inside a searched folder, and puts back the
+// usual file:line: prefix
+func (s *AgenticSkill) allowedMatches(output []byte) []string {
+	policy := s.filePolicy()
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		file, rest, ok := strings.Cut(line, "\x00")
+		if !ok {
+			if line != "" {
+				lines = append(lines, line)
+			}
+			continue
+		}
+		if _, err := policy.CheckRead(file); err != nil {
+			continue
+		}
+		// Context lines have file-line- rather than file:line:
+		sep := ":"
+		digits := strings.TrimLeft(rest, "0123456789")
+		if strings.HasPrefix(digits, "-") {
+			sep = "-"
+		}
+		lines = append(lines, file+sep+rest)
+	}
+	return lines
+}
+
 func detectLanguages(path string) []string {
 	languages := map[string]bool{}
 	exts := map[string]string{
@@ -0,0 +1,197 @@
+package agentic
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/codeindex"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+)
+
+// snippetLines is how much of a matching symbol query_code_semantic shows
+const snippetLines = 12
+
+// SetCodeIndex sets the index behind get_repo_map and query_code_semantic.
+// Without one, projects are indexed in memory, without embeddings.
+func (s *AgenticSkill) SetCodeIndex(m *codeindex.Manager) {
+	s.mu.Lock()
+	s.index = m
+	s.mu.Unlock()
+}
+
+func (s *AgenticSkill) codeIndex() *codeindex.Manager {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.index == nil {
+		s.index = codeindex.NewManager(nil, nil, codeindex.Options{}, nil)
+	}
+	return s.index
+}
+
+func (s *AgenticSkill) registerIndexTools() {
+	s.AddTool(skills.Tool{
+		Name: "get_repo_map",
+		Description: "Outline a project: its source files with the types and functions each declares, " +
+			"skipping what .gitignore ignores. Start here on a large codebase, then narrow with focus or query.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "Project root (default: current directory)",
+				},
+				"focus": map[string]interface{}{
+					"type":        "string",
+					"description": "Folder, file or glob to limit the map to, e.g. 'internal/api' or '*.py'",
+				},
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "Show files relevant to this first",
+				},
+				"max_chars": map[string]interface{}{
+					"type":        "integer",
+					"description": "Size limit of the map (default: 8000)",
+				},
+			},
+		},
+		Handler: s.handleGetRepoMap,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "query_code_semantic",
+		Description: "Find the code that does something, described in words (e.g. 'where retries are scheduled'), from the project's code index",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "What the code does",
+				},
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "Project root (default: current directory)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum results (default: 8)",
+				},
+			},
+			"required": []string{"query"},
+		},
+		Handler: s.handleQueryCodeSemantic,
+	})
+}
+
+// openIndex returns the index of the project the arguments name
+func (s *AgenticSkill) openIndex(args map[string]interface{}) (*codeindex.Index, error) {
+	root, err := projectRoot(args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.filePolicy().CheckRead(root); err != nil {
+		return nil, err
+	}
+	return s.codeIndex().Index(root)
+}
+
+func (s *AgenticSkill) handleGetRepoMap(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	idx, err := s.openIndex(args)
+	if err != nil {
+		return nil, err
+	}
+	opts := codeindex.MapOptions{}
+	opts.Focus, _ = args["focus"].(string)
+	opts.Query, _ = args["query"].(string)
+	if n, ok := args["max_chars"].(float64); ok {
+		opts.MaxChars = int(n)
+	}
+
+	m := idx.Map(opts)
+	result := map[string]interface{}{
+		"root":        idx.Root(),
+		"map":         m.Text,
+		"files_shown": m.Shown,
+		"files_total": m.Total,
+	}
+	if m.Total == 0 {
+		result["message"] = "No source files found; check the path and focus."
+	}
+	if idx.Stats().Capped {
+		result["note"] = "The project has more files than the index takes (tools.coding.index.max_files); some are missing."
+	}
+	return result, nil
+}
+
+func (s *AgenticSkill) handleQueryCodeSemantic(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	query, _ := args["query"].(string)
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	limit := 8
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+
+	idx, err := s.openIndex(args)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := idx.Search(query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]interface{}, 0, len(matches))
+	for _, m := range matches {
+		r := map[string]interface{}{
+			"path":  m.Path,
+			"line":  m.Line,
+			"score": fmt.Sprintf("%.2f", m.Score),
+		}
+		if m.Symbol != "" {
+			r["symbol"] = m.Symbol
+		}
+		if m.Signature != "" {
+			r["signature"] = m.Signature
+		}
+		if code := snippet(filepath.Join(idx.Root(), filepath.FromSlash(m.Path)), m.Line, m.EndLine); code != "" {
+			r["code"] = code
+		}
+		results = append(results, r)
+	}
+
+	stats := idx.Stats()
+	result := map[string]interface{}{
+		"root":     idx.Root(),
+		"semantic": idx.Semantic(),
+		"results":  results,
+	}
+	switch {
+	case !idx.Semantic():
+		result["note"] = "Matched by names only; enable vector search to match by meaning."
+	case stats.Pending > 0:
+		result["note"] = fmt.Sprintf("%d of %d files are still being embedded and were matched by names only.", stats.Pending, stats.Files)
+	}
+	return result, nil
+}
+
+// snippet returns the first lines of a symbol
+func snippet(path string, line, endLine int) string {
+	data, err := os.ReadFile(path)
+	if err != nil || line < 1 {
+		return ""
+	}
+	lines := strings.Split(string(data), "\n")
+	to := min(endLine, line-1+snippetLines, len(lines))
+	if line > to {
+		return ""
+	}
+	out := strings.Join(lines[line-1:to], "\n")
+	if endLine > to {
+		out += "\n…"
+	}
+	return out
+}
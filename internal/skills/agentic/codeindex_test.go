@@ -0,0 +1,38 @@
+package agentic
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRepoMapAndQuery(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "retry.go"), []byte("package main\n\n// scheduleRetry queues a failed job again\nfunc scheduleRetry(job string) {\n\t_ = job\n}\n"), 0644)
+	os.WriteFile(filepath.Join(root, ".gitignore"), []byte("gen.go\n"), 0644)
+	os.WriteFile(filepath.Join(root, "gen.go"), []byte("package main\n\nfunc generated() {}\n"), 0644)
+
+	skill := NewAgenticSkill(t.TempDir())
+	ctx := context.Background()
+
+	result, err := skill.handleGetRepoMap(ctx, map[string]interface{}{"path": root})
+	if err != nil {
+		t.Fatalf("Failed to map: %v", err)
+	}
+	m := result.(map[string]interface{})
+	text := m["map"].(string)
+	if !strings.Contains(text, "retry.go\n  4: func scheduleRetry(job string)") || strings.Contains(text, "generated") {
+		t.Errorf("Unexpected map:\n%s", text)
+	}
+
+	result, err = skill.handleQueryCodeSemantic(ctx, map[string]interface{}{"path": root, "query": "schedule retry"})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	results := result.(map[string]interface{})["results"].([]map[string]interface{})
+	if len(results) != 1 || results[0]["symbol"] != "scheduleRetry" || !strings.Contains(results[0]["code"].(string), "_ = job") {
+		t.Errorf("Unexpected results: %v", results)
+	}
+}
@@ -0,0 +1,663 @@
+package agentic
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"github.com/gmsas95/myrai-cli/internal/sandbox"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+)
+
+const (
+	// PatchExpiry is how long a proposed patch waits to be applied
+	PatchExpiry = time.Hour
+	// MaxApprovalAttempts is how many wrong codes discard a patch
+	MaxApprovalAttempts = 3
+	// defaultTestTimeout bounds run_tests unless configured
+	defaultTestTimeout = 10 * time.Minute
+	// maxTestOutput is how much of the end of the test output is returned
+	maxTestOutput = 6000
+)
+
+// pendingPatch is a proposed patch waiting to be applied
+type pendingPatch struct {
+	id       string
+	userID   string
+	root     string
+	files    []FilePatch
+	digests  map[string]string // file → digest of the content it was checked against
+	codeHash string
+	attempts int
+	expires  time.Time
+}
+
+// backupRecord lists what applying a patch replaced, to revert it
+type backupRecord struct {
+	Root  string        `json:"root"`
+	Files []backupEntry `json:"files"`
+}
+
+type backupEntry struct {
+	Path    string `json:"path"`
+	Existed bool   `json:"existed"`
+	Backup  string `json:"backup,omitempty"`
+}
+
+// coding holds the state of the tools that patch code and run tests
+type coding struct {
+	cfg      config.CodingConfig
+	sandbox  *sandbox.Policy
+	notifier notify.Sender
+	pending  map[string]*pendingPatch
+}
+
+// SetCodingConfig sets how patches are approved and tests are run
+func (s *AgenticSkill) SetCodingConfig(cfg config.CodingConfig) {
+	s.mu.Lock()
+	s.coding.cfg = cfg
+	servers := s.servers
+	s.servers = nil // restarted with the new language servers on next use
+	s.mu.Unlock()
+	if servers != nil {
+		servers.Close()
+	}
+}
+
+// SetSandbox replaces the policy deciding where and under what limits
+// tests run
+func (s *AgenticSkill) SetSandbox(policy *sandbox.Policy) {
+	s.mu.Lock()
+	s.coding.sandbox = policy
+	s.mu.Unlock()
+}
+
+// SetNotifier sets where patch approval codes are sent. Without one,
+// patches can be proposed but only applied when approval is "none".
+func (s *AgenticSkill) SetNotifier(n notify.Sender) {
+	s.mu.Lock()
+	s.coding.notifier = n
+	s.mu.Unlock()
+}
+
+func (s *AgenticSkill) registerCodingTools() {
+	s.AddTool(skills.Tool{
+		Name: "propose_patch",
+		Description: "Propose a change to files as a unified diff (paths relative to the project root). " +
+			"It is checked against the files without changing anything; apply it with apply_patch once approved.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"diff": map[string]interface{}{
+					"type":        "string",
+					"description": "Unified diff with ---/+++ headers and @@ hunks, as made by git diff",
+				},
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "Project root the diff's paths are relative to (default: current directory)",
+				},
+			},
+			"required": []string{"diff"},
+		},
+		Handler: s.handleProposePatch,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "apply_patch",
+		Description: "Apply a proposed patch, backing up the files it changes. Needs the approval code the user was sent, unless approval is off.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"patch_id": map[string]interface{}{
+					"type":        "string",
+					"description": "ID from propose_patch",
+				},
+				"approval_code": map[string]interface{}{
+					"type":        "string",
+					"description": "The code the user tells you",
+				},
+			},
+			"required": []string{"patch_id"},
+		},
+		Handler: s.handleApplyPatch,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "revert_patch",
+		Description: "Undo an applied patch, restoring the files from their backups",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"patch_id": map[string]interface{}{
+					"type":        "string",
+					"description": "ID of the applied patch",
+				},
+			},
+			"required": []string{"patch_id"},
+		},
+		Handler: s.handleRevertPatch,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "run_tests",
+		Description: "Run the project's tests in the command sandbox and report failures, to check a change",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "Project root (default: current directory)",
+				},
+			},
+		},
+		Handler: s.handleRunTests,
+	})
+}
+
+func (s *AgenticSkill) codingState() (config.CodingConfig, *sandbox.Policy, notify.Sender) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.coding.cfg, s.coding.sandbox, s.coding.notifier
+}
+
+// checkPatch resolves the files a patch changes under root and applies it
+// in memory. It returns the new contents and the digests of the current
+// ones.
+func (s *AgenticSkill) checkPatch(root string, files []FilePatch) (map[string][]byte, map[string]string, error) {
+	policy := s.filePolicy()
+	out := make(map[string][]byte, len(files))
+	digests := make(map[string]string, len(files))
+	for _, fp := range files {
+		if !filepath.IsLocal(filepath.FromSlash(fp.Path())) || (fp.OldPath != "" && !filepath.IsLocal(filepath.FromSlash(fp.OldPath))) {
+			return nil, nil, fmt.Errorf("%s is outside the project", fp.Path())
+		}
+		if fp.OldPath != "" && fp.NewPath != "" && fp.OldPath != fp.NewPath {
+			return nil, nil, fmt.Errorf("renaming %s isn't supported; delete it and create %s instead", fp.OldPath, fp.NewPath)
+		}
+		path := filepath.Join(root, filepath.FromSlash(fp.Path()))
+		if _, err := os.Stat(path); err == nil {
+			if _, err := policy.CheckRead(path); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		content, err := os.ReadFile(path)
+		switch {
+		case os.IsNotExist(err):
+			if fp.OldPath != "" {
+				return nil, nil, fmt.Errorf("%s doesn't exist", fp.Path())
+			}
+			content = nil
+		case err != nil:
+			return nil, nil, err
+		case fp.OldPath == "":
+			return nil, nil, fmt.Errorf("%s already exists", fp.Path())
+		}
+
+		updated, err := ApplyPatch(content, fp)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := policy.CheckWrite(path, int64(len(updated))); err != nil {
+			return nil, nil, err
+		}
+		out[path] = updated
+		digests[path] = digest(content)
+	}
+	return out, digests, nil
+}
+
+func (s *AgenticSkill) handleProposePatch(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	diff, _ := args["diff"].(string)
+	if strings.TrimSpace(diff) == "" {
+		return nil, fmt.Errorf("diff is required")
+	}
+	root, err := projectRoot(args)
+	if err != nil {
+		return nil, err
+	}
+	files, err := ParsePatch(diff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid diff: %w", err)
+	}
+	_, digests, err := s.checkPatch(root, files)
+	if err != nil {
+		return nil, err
+	}
+	return s.requestApproval(ctx, &pendingPatch{
+		id:      idgen.Generate(idgen.PrefixPatch),
+		userID:  getUserID(ctx),
+		root:    root,
+		files:   files,
+		digests: digests,
+		expires: time.Now().Add(PatchExpiry),
+	})
+}
+
+// requestApproval saves a checked patch and, unless approval is off, sends
+// the user its approval code
+func (s *AgenticSkill) requestApproval(ctx context.Context, p *pendingPatch) (interface{}, error) {
+	cfg, _, notifier := s.codingState()
+
+	var changes []map[string]interface{}
+	for _, fp := range p.files {
+		added, removed := fp.counts()
+		status := "modified"
+		switch {
+		case fp.OldPath == "":
+			status = "created"
+		case fp.NewPath == "":
+			status = "deleted"
+		}
+		changes = append(changes, map[string]interface{}{
+			"path":    fp.Path(),
+			"status":  status,
+			"added":   added,
+			"removed": removed,
+		})
+	}
+	result := map[string]interface{}{
+		"patch_id": p.id,
+		"root":     p.root,
+		"files":    changes,
+	}
+
+	var code string
+	if cfg.Approval != "none" {
+		var err error
+		if code, err = newApprovalCode(); err != nil {
+			return nil, err
+		}
+		p.codeHash = hashCode(p.id, code)
+	}
+	s.savePending(p)
+
+	if cfg.Approval == "none" {
+		result["message"] = "The patch applies cleanly. Apply it with apply_patch."
+		return result, nil
+	}
+	if notifier == nil {
+		result["approval_sent"] = false
+		result["message"] = "The patch applies cleanly, but it can't be applied: approval codes need a notification channel, and none is set up"
+		return result, nil
+	}
+	var body strings.Builder
+	for _, c := range changes {
+		fmt.Fprintf(&body, "%s %s (+%d -%d)\n", c["status"], c["path"], c["added"], c["removed"])
+	}
+	fmt.Fprintf(&body, "\nIn %s. To apply it, tell me approval code %s. It expires in %.0f minutes.", p.root, code, PatchExpiry.Minutes())
+	if _, err := notifier.Send(ctx, notify.Notification{
+		UserID:   p.userID,
+		Category: "coding",
+		Title:    "Approve code change",
+		Body:     body.String(),
+		Urgent:   true,
+		Secret:   true,
+	}); err != nil {
+		result["approval_sent"] = false
+		result["message"] = "The patch applies cleanly, but the approval code couldn't be delivered: " + err.Error()
+		return result, nil
+	}
+	result["approval_sent"] = true
+	result["message"] = "The patch applies cleanly. Show the user the change; they've been sent an approval code, " +
+		"and it is only applied once they tell you that code."
+	return result, nil
+}
+
+func (s *AgenticSkill) savePending(p *pendingPatch) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.coding.pending == nil {
+		s.coding.pending = make(map[string]*pendingPatch)
+	}
+	now := time.Now()
+	for id, old := range s.coding.pending {
+		if now.After(old.expires) {
+			delete(s.coding.pending, id)
+		}
+	}
+	s.coding.pending[p.id] = p
+}
+
+// takePending checks the approval code of a pending patch and removes it.
+// A wrong code counts against the patch.
+func (s *AgenticSkill) takePending(ctx context.Context, id, code string, approval bool) (*pendingPatch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.coding.pending[id]
+	if !ok || p.userID != getUserID(ctx) {
+		return nil, fmt.Errorf("patch %s not found; propose it again", id)
+	}
+	if time.Now().After(p.expires) {
+		delete(s.coding.pending, id)
+		return nil, fmt.Errorf("patch %s expired; propose it again", id)
+	}
+	if approval {
+		if code == "" {
+			return nil, fmt.Errorf("approval_code is required. Ask the user for the code they were sent")
+		}
+		if p.codeHash == "" || subtle.ConstantTimeCompare([]byte(hashCode(p.id, code)), []byte(p.codeHash)) != 1 {
+			p.attempts++
+			left := MaxApprovalAttempts - p.attempts
+			if left <= 0 {
+				delete(s.coding.pending, id)
+				return nil, fmt.Errorf("wrong approval code; the patch was discarded after %d attempts", MaxApprovalAttempts)
+			}
+			return nil, fmt.Errorf("wrong approval code; %d attempts left. Ask the user for the code they were sent", left)
+		}
+	}
+	delete(s.coding.pending, id)
+	return p, nil
+}
+
+func (s *AgenticSkill) handleApplyPatch(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	id, _ := args["patch_id"].(string)
+	code, _ := args["approval_code"].(string)
+	if id == "" {
+		return nil, fmt.Errorf("patch_id is required")
+	}
+	cfg, _, _ := s.codingState()
+	p, err := s.takePending(ctx, id, strings.TrimSpace(code), cfg.Approval != "none")
+	if err != nil {
+		return nil, err
+	}
+
+	updated, digests, err := s.checkPatch(p.root, p.files)
+	if err != nil {
+		return nil, fmt.Errorf("the patch no longer applies: %w", err)
+	}
+	for path, d := range digests {
+		if p.digests[path] != d {
+			return nil, fmt.Errorf("%s changed since the patch was proposed; read it again and propose a new patch", path)
+		}
+	}
+
+	backupDir := filepath.Join(s.workspaceRoot, "patch-backups", p.id)
+	record, err := backupFiles(backupDir, p.root, updated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to back up files, nothing was changed: %w", err)
+	}
+
+	var written []string
+	for path, content := range updated {
+		if err := writeOrRemove(path, content); err != nil {
+			restoreFiles(record)
+			return nil, fmt.Errorf("failed to write %s, the other files were restored: %w", path, err)
+		}
+		written = append(written, path)
+	}
+
+	return map[string]interface{}{
+		"patch_id": p.id,
+		"applied":  written,
+		"backup":   backupDir,
+		"message":  "Applied. Run run_tests to check the change; revert_patch undoes it.",
+	}, nil
+}
+
+func (s *AgenticSkill) handleRevertPatch(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	id, _ := args["patch_id"].(string)
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return nil, fmt.Errorf("a valid patch_id is required")
+	}
+	backupDir := filepath.Join(s.workspaceRoot, "patch-backups", id)
+	data, err := os.ReadFile(filepath.Join(backupDir, "manifest.json"))
+	if err != nil {
+		return nil, fmt.Errorf("no backup of patch %s", id)
+	}
+	var record backupRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("backup of patch %s is unreadable: %w", id, err)
+	}
+	policy := s.filePolicy()
+	for _, f := range record.Files {
+		if _, err := policy.CheckWrite(f.Path, 0); err != nil {
+			return nil, err
+		}
+	}
+	if err := restoreFiles(&record); err != nil {
+		return nil, err
+	}
+	os.RemoveAll(backupDir)
+
+	restored := make([]string, 0, len(record.Files))
+	for _, f := range record.Files {
+		restored = append(restored, f.Path)
+	}
+	return map[string]interface{}{
+		"patch_id": id,
+		"restored": restored,
+	}, nil
+}
+
+// backupFiles copies the current version of each file into dir, with a
+// manifest to restore them from
+func backupFiles(dir, root string, files map[string][]byte) (*backupRecord, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	record := &backupRecord{Root: root}
+	n := 0
+	for path := range files {
+		entry := backupEntry{Path: path}
+		content, err := os.ReadFile(path)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return nil, err
+		default:
+			n++
+			entry.Existed = true
+			entry.Backup = filepath.Join(dir, fmt.Sprintf("%d.orig", n))
+			if err := os.WriteFile(entry.Backup, content, 0600); err != nil {
+				return nil, err
+			}
+		}
+		record.Files = append(record.Files, entry)
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), data, 0600); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// restoreFiles puts back what a backup recorded
+func restoreFiles(record *backupRecord) error {
+	for _, f := range record.Files {
+		var content []byte
+		if f.Existed {
+			var err error
+			if content, err = os.ReadFile(f.Backup); err != nil {
+				return fmt.Errorf("failed to read the backup of %s: %w", f.Path, err)
+			}
+		}
+		if err := writeOrRemove(f.Path, content); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", f.Path, err)
+		}
+	}
+	return nil
+}
+
+// writeOrRemove writes content to path, keeping its mode, or removes the
+// file when content is nil
+func writeOrRemove(path string, content []byte) error {
+	if content == nil {
+		err := os.Remove(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".myrai-tmp"
+	if err := os.WriteFile(tmp, content, mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// testCommands are tried in order when no test command is configured
+var testCommands = []struct {
+	marker  string
+	command string
+}{
+	{"go.mod", "go test ./..."},
+	{"Cargo.toml", "cargo test"},
+	{"package.json", "npm test --silent"},
+	{"pyproject.toml", "python -m pytest -q"},
+	{"pytest.ini", "python -m pytest -q"},
+	{"setup.py", "python -m pytest -q"},
+	{"Makefile", "make test"},
+}
+
+// DetectTestCommand returns the usual test command of the project in root
+func DetectTestCommand(root string) string {
+	for _, tc := range testCommands {
+		if _, err := os.Stat(filepath.Join(root, tc.marker)); err == nil {
+			return tc.command
+		}
+	}
+	return ""
+}
+
+// failureLine matches lines of test output that say what failed
+var failureLine = regexp.MustCompile(`^(--- FAIL|FAIL\b|panic:|FAILED\b|ERROR\b|E   |\s+✕|\s+●|test .* \.\.\. FAILED|error(\[E\d+\])?:)|_test\.go:\d+:|\.(py|js|ts|rs):\d+`)
+
+// Failures picks the lines of test output that say what failed
+func Failures(output string) []string {
+	var out []string
+	for _, line := range strings.Split(output, "\n") {
+		if failureLine.MatchString(line) {
+			out = append(out, strings.TrimRight(line, " \t"))
+			if len(out) == 40 {
+				break
+			}
+		}
+	}
+	return out
+}
+
+func (s *AgenticSkill) handleRunTests(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	root, err := projectRoot(args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.filePolicy().CheckRead(root); err != nil {
+		return nil, err
+	}
+	cfg, policy, _ := s.codingState()
+	if policy == nil {
+		policy = sandbox.Default()
+	}
+
+	command := cfg.TestCommand
+	if command == "" {
+		command = DetectTestCommand(root)
+	}
+	if command == "" {
+		return nil, fmt.Errorf("no test command found for %s; set tools.coding.test_command", root)
+	}
+	timeout := defaultTestTimeout
+	if cfg.TestTimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TestTimeoutSeconds) * time.Second
+	}
+
+	res, err := policy.Run(ctx, "cd "+shellQuote(root)+" && "+command, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", command, err)
+	}
+
+	output := res.Output
+	if len(output) > maxTestOutput {
+		output = "…" + output[len(output)-maxTestOutput:]
+	}
+	result := map[string]interface{}{
+		"command":   command,
+		"passed":    res.ExitCode == 0 && !res.TimedOut,
+		"exit_code": res.ExitCode,
+		"output":    output,
+	}
+	switch {
+	case res.TimedOut:
+		result["message"] = fmt.Sprintf("The tests didn't finish within %s.", timeout)
+	case res.ExitCode != 0:
+		result["failures"] = Failures(res.Output)
+		result["message"] = "Tests failed. Read the failures, fix the cause with propose_patch and apply_patch, then run the tests again."
+	default:
+		result["message"] = "All tests passed."
+	}
+	return result, nil
+}
+
+// projectRoot returns the absolute project root the arguments name
+func projectRoot(args map[string]interface{}) (string, error) {
+	root := "."
+	if p, ok := args["path"].(string); ok && p != "" {
+		root = p
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(abs)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", root)
+	}
+	return abs, nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func digest(content []byte) string {
+	if content == nil {
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// newApprovalCode returns a random 6-digit code
+func newApprovalCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate approval code: %w", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func hashCode(patchID, code string) string {
+	sum := sha256.Sum256([]byte(patchID + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+func getUserID(ctx context.Context) string {
+	if userID, ok := ctx.Value("user_id").(string); ok && userID != "" {
+		return userID
+	}
+	return "default_user"
+}
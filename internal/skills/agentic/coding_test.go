@@ -0,0 +1,176 @@
+package agentic
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+)
+
+type fakeNotifier struct {
+	sent []notify.Notification
+}
+
+func (f *fakeNotifier) Send(ctx context.Context, n notify.Notification) (string, error) {
+	f.sent = append(f.sent, n)
+	return "n1", nil
+}
+
+const testPatch = `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,4 @@
+ package main
+
+-func add(a, b int) int { return a - b }
++func add(a, b int) int { return a + b }
+
+--- /dev/null
++++ b/notes.txt
+@@ -0,0 +1,2 @@
++first
++second
+\ No newline at end of file
+`
+
+func TestApplyPatch(t *testing.T) {
+	files, err := ParsePatch(testPatch)
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if len(files) != 2 || files[0].Path() != "main.go" || files[1].OldPath != "" {
+		t.Fatalf("Unexpected files: %+v", files)
+	}
+
+	// The hunk still applies after lines were added above it
+	original := "// header\n\npackage main\n\nfunc add(a, b int) int { return a - b }\n\nfunc main() {}\n"
+	got, err := ApplyPatch([]byte(original), files[0])
+	if err != nil {
+		t.Fatalf("Failed to apply: %v", err)
+	}
+	if want := strings.Replace(original, "a - b", "a + b", 1); string(got) != want {
+		t.Errorf("Unexpected result:\n%s", got)
+	}
+
+	created, err := ApplyPatch(nil, files[1])
+	if err != nil || string(created) != "first\nsecond" {
+		t.Errorf("Unexpected new file %q, %v", created, err)
+	}
+
+	if _, err := ApplyPatch([]byte("package other\n"), files[0]); err == nil {
+		t.Error("Expected a hunk that doesn't match to fail")
+	}
+	for _, bad := range []string{"not a diff", "--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n-a\n"} {
+		if _, err := ParsePatch(bad); err == nil {
+			t.Errorf("Expected %q to be refused", bad)
+		}
+	}
+}
+
+func TestProposeAndApplyPatch(t *testing.T) {
+	root := t.TempDir()
+	main := filepath.Join(root, "main.go")
+	original := "package main\n\nfunc add(a, b int) int { return a - b }\n\nfunc main() {}\n"
+	os.WriteFile(main, []byte(original), 0644)
+
+	notifier := &fakeNotifier{}
+	skill := NewAgenticSkill(t.TempDir())
+	skill.SetNotifier(notifier)
+	ctx := context.Background()
+
+	result, err := skill.handleProposePatch(ctx, map[string]interface{}{"diff": testPatch, "path": root})
+	if err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+	proposal := result.(map[string]interface{})
+	id := proposal["patch_id"].(string)
+	if proposal["approval_sent"] != true || len(notifier.sent) != 1 {
+		t.Fatalf("Expected an approval code to be sent: %v", proposal)
+	}
+	if data, _ := os.ReadFile(main); string(data) != original {
+		t.Fatal("Expected proposing to change nothing")
+	}
+	code := regexp.MustCompile(`code (\d{6})`).FindStringSubmatch(notifier.sent[0].Body)[1]
+
+	if _, err := skill.handleApplyPatch(ctx, map[string]interface{}{"patch_id": id}); err == nil {
+		t.Error("Expected applying without the code to fail")
+	}
+	if _, err := skill.handleApplyPatch(ctx, map[string]interface{}{"patch_id": id, "approval_code": "000000x"}); err == nil {
+		t.Error("Expected a wrong code to fail")
+	}
+	if _, err := skill.handleApplyPatch(ctx, map[string]interface{}{"patch_id": id, "approval_code": code}); err != nil {
+		t.Fatalf("Failed to apply: %v", err)
+	}
+	if data, _ := os.ReadFile(main); !strings.Contains(string(data), "a + b") {
+		t.Errorf("Expected the change to be applied, got %s", data)
+	}
+	if _, err := os.Stat(filepath.Join(root, "notes.txt")); err != nil {
+		t.Error("Expected the new file to be created")
+	}
+
+	if _, err := skill.handleRevertPatch(ctx, map[string]interface{}{"patch_id": id}); err != nil {
+		t.Fatalf("Failed to revert: %v", err)
+	}
+	if data, _ := os.ReadFile(main); string(data) != original {
+		t.Errorf("Expected the original back, got %s", data)
+	}
+	if _, err := os.Stat(filepath.Join(root, "notes.txt")); !os.IsNotExist(err) {
+		t.Error("Expected the created file to be removed")
+	}
+
+	escape := "--- a/../outside.txt\n+++ b/../outside.txt\n@@ -1 +1 @@\n-a\n+b\n"
+	if _, err := skill.handleProposePatch(ctx, map[string]interface{}{"diff": escape, "path": root}); err == nil {
+		t.Error("Expected a path outside the project to be refused")
+	}
+}
+
+func TestApplyPatchRefusesChangedFiles(t *testing.T) {
+	root := t.TempDir()
+	main := filepath.Join(root, "main.go")
+	os.WriteFile(main, []byte("package main\n\nfunc add(a, b int) int { return a - b }\n\n"), 0644)
+
+	skill := NewAgenticSkill(t.TempDir())
+	skill.SetCodingConfig(config.CodingConfig{Approval: "none"})
+	ctx := context.Background()
+
+	result, err := skill.handleProposePatch(ctx, map[string]interface{}{"diff": testPatch, "path": root})
+	if err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+	id := result.(map[string]interface{})["patch_id"].(string)
+
+	os.WriteFile(main, []byte("package main\n\nfunc add(a, b int) int { return a - b }\n\n// edited\n"), 0644)
+	if _, err := skill.handleApplyPatch(ctx, map[string]interface{}{"patch_id": id}); err == nil {
+		t.Error("Expected a file changed since the proposal to be refused")
+	}
+}
+
+func TestRunTests(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	root := t.TempDir()
+	skill := NewAgenticSkill(t.TempDir())
+	skill.SetCodingConfig(config.CodingConfig{TestCommand: "echo '--- FAIL: TestAdd (0.00s)'; echo '    add_test.go:9: got 1'; exit 1"})
+
+	result, err := skill.handleRunTests(context.Background(), map[string]interface{}{"path": root})
+	if err != nil {
+		t.Fatalf("Failed to run tests: %v", err)
+	}
+	out := result.(map[string]interface{})
+	failures, _ := out["failures"].([]string)
+	if out["passed"] != false || len(failures) != 2 {
+		t.Errorf("Expected two failure lines, got %v", out)
+	}
+
+	os.WriteFile(filepath.Join(root, "go.mod"), []byte("module x\n"), 0644)
+	if cmd := DetectTestCommand(root); cmd != "go test ./..." {
+		t.Errorf("Expected go test, got %q", cmd)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
@@ -12,6 +13,9 @@ func (s *AgenticSkill) handleGitStatus(ctx context.Context, args map[string]inte
 	if p, ok := args["path"].(string); ok && p != "" {
 		path = p
 	}
+	if _, err := s.filePolicy().CheckRead(path); err != nil {
+		return nil, err
+	}
 
 	result := map[string]interface{}{}
 
@@ -44,6 +48,9 @@ func (s *AgenticSkill) handleGitLog(ctx context.Context, args map[string]interfa
 	if p, ok := args["path"].(string); ok && p != "" {
 		path = p
 	}
+	if _, err := s.filePolicy().CheckRead(path); err != nil {
+		return nil, err
+	}
 
 	limit := 10
 	if l, ok := args["limit"].(float64); ok {
@@ -54,13 +61,25 @@ func (s *AgenticSkill) handleGitLog(ctx context.Context, args map[string]interfa
 	if f, ok := args["file"].(string); ok {
 		file = f
 	}
+	if file != "" {
+		if _, err := s.filePolicy().CheckRead(filepath.Join(path, file)); err != nil {
+			return nil, err
+		}
+	}
 
-	var cmd *exec.Cmd
+	base, _ := args["base"].(string)
+	if strings.HasPrefix(base, "-") {
+		return nil, fmt.Errorf("invalid revision")
+	}
+
+	gitArgs := []string{"-C", path, "log", fmt.Sprintf("-%d", limit), "--oneline"}
+	if base != "" {
+		gitArgs = append(gitArgs, base+"..HEAD")
+	}
 	if file != "" {
-		cmd = exec.CommandContext(ctx, "git", "-C", path, "log", fmt.Sprintf("-%d", limit), "--oneline", file)
-	} else {
-		cmd = exec.CommandContext(ctx, "git", "-C", path, "log", fmt.Sprintf("-%d", limit), "--oneline")
+		gitArgs = append(gitArgs, "--", file)
 	}
+	cmd := exec.CommandContext(ctx, "git", gitArgs...)
 
 	output, err := cmd.Output()
 	if err != nil {
@@ -90,6 +109,9 @@ func (s *AgenticSkill) handleGitDiff(ctx context.Context, args map[string]interf
 	if p, ok := args["path"].(string); ok && p != "" {
 		path = p
 	}
+	if _, err := s.filePolicy().CheckRead(path); err != nil {
+		return nil, err
+	}
 
 	commit := ""
 	if c, ok := args["commit"].(string); ok {
@@ -100,22 +122,33 @@ func (s *AgenticSkill) handleGitDiff(ctx context.Context, args map[string]interf
 	if f, ok := args["file"].(string); ok {
 		file = f
 	}
-
-	var cmd *exec.Cmd
-	if commit != "" {
-		if file != "" {
-			cmd = exec.CommandContext(ctx, "git", "-C", path, "diff", commit, "--", file)
-		} else {
-			cmd = exec.CommandContext(ctx, "git", "-C", path, "diff", commit)
-		}
-	} else {
-		if file != "" {
-			cmd = exec.CommandContext(ctx, "git", "-C", path, "diff", "--", file)
-		} else {
-			cmd = exec.CommandContext(ctx, "git", "-C", path, "diff")
+	if file != "" {
+		if _, err := s.filePolicy().CheckRead(filepath.Join(path, file)); err != nil {
+			return nil, err
 		}
 	}
 
+	staged, _ := args["staged"].(bool)
+	base, _ := args["base"].(string)
+	if strings.HasPrefix(commit, "-") || strings.HasPrefix(base, "-") {
+		return nil, fmt.Errorf("invalid revision")
+	}
+
+	gitArgs := []string{"-C", path, "diff"}
+	switch {
+	case base != "":
+		// What the branch changed since it left base, as a pull request shows it
+		gitArgs = append(gitArgs, base+"...HEAD")
+	case staged:
+		gitArgs = append(gitArgs, "--cached")
+	case commit != "":
+		gitArgs = append(gitArgs, commit)
+	}
+	if file != "" {
+		gitArgs = append(gitArgs, "--", file)
+	}
+	cmd := exec.CommandContext(ctx, "git", gitArgs...)
+
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get diff: %w", err)
@@ -131,6 +164,9 @@ func (s *AgenticSkill) handleGitBlame(ctx context.Context, args map[string]inter
 	if file == "" {
 		return nil, fmt.Errorf("file is required")
 	}
+	if _, err := s.filePolicy().CheckRead(file); err != nil {
+		return nil, err
+	}
 
 	lineStart := 0
 	if ls, ok := args["line_start"].(float64); ok {
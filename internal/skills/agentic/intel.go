@@ -0,0 +1,359 @@
+package agentic
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/lsp"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+)
+
+const (
+	// lspTimeout bounds a language server query. The first one in a
+	// project also waits for the server to load it.
+	lspTimeout = time.Minute
+	// diagnosticsWait is how long to wait for a server to report problems
+	diagnosticsWait = 3 * time.Second
+	// maxReferences caps the references returned
+	maxReferences = 100
+)
+
+// languageServers returns the manager of language servers, starting it on
+// first use
+func (s *AgenticSkill) languageServers() *lsp.Manager {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.servers == nil {
+		s.servers = lsp.NewManager(s.coding.cfg.LanguageServers, nil)
+	}
+	return s.servers
+}
+
+func (s *AgenticSkill) registerIntelTools() {
+	position := map[string]interface{}{
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "File the symbol is in",
+		},
+		"line": map[string]interface{}{
+			"type":        "integer",
+			"description": "Line of the symbol, from 1",
+		},
+		"symbol": map[string]interface{}{
+			"type":        "string",
+			"description": "Name of the symbol on that line (or give column)",
+		},
+		"column": map[string]interface{}{
+			"type":        "integer",
+			"description": "Column of the symbol, from 1",
+		},
+	}
+
+	s.AddTool(skills.Tool{
+		Name:        "code_definition",
+		Description: "Go to the definition of a symbol using the language server (gopls, pyright, ...)",
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": position,
+			"required":   []string{"path", "line"},
+		},
+		Handler: s.handleCodeDefinition,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "code_references",
+		Description: "Find every use of a symbol across the project using the language server",
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": position,
+			"required":   []string{"path", "line"},
+		},
+		Handler: s.handleCodeReferences,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "code_symbols",
+		Description: "Outline a file: its types, functions, methods and fields with their lines, from the language server",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to the code file",
+				},
+			},
+			"required": []string{"path"},
+		},
+		Handler: s.handleCodeSymbols,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "code_diagnostics",
+		Description: "Report compile errors and warnings in a file from the language server, e.g. after applying a patch",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to the code file",
+				},
+			},
+			"required": []string{"path"},
+		},
+		Handler: s.handleCodeDiagnostics,
+	})
+}
+
+// intelFile checks the path argument and returns its absolute path
+func (s *AgenticSkill) intelFile(args map[string]interface{}) (string, error) {
+	path, _ := args["path"].(string)
+	if path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	real, err := s.filePolicy().CheckRead(path)
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(real)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(abs); err != nil || info.IsDir() {
+		return "", fmt.Errorf("%s is not a file", path)
+	}
+	return abs, nil
+}
+
+// intelPosition turns the line, column and symbol arguments into an LSP
+// position in path
+func intelPosition(path string, args map[string]interface{}) (lsp.Position, error) {
+	line, _ := args["line"].(float64)
+	if line < 1 {
+		return lsp.Position{}, fmt.Errorf("line is required and counts from 1")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return lsp.Position{}, err
+	}
+	lines := strings.Split(string(content), "\n")
+	if int(line) > len(lines) {
+		return lsp.Position{}, fmt.Errorf("%s has only %d lines", filepath.Base(path), len(lines))
+	}
+	text := lines[int(line)-1]
+
+	offset := len(text) - len(strings.TrimLeft(text, " \t"))
+	if symbol, _ := args["symbol"].(string); symbol != "" {
+		at := indexWord(text, symbol)
+		if at < 0 {
+			return lsp.Position{}, fmt.Errorf("%q is not on line %d: %s", symbol, int(line), strings.TrimSpace(text))
+		}
+		offset = at
+	} else if col, ok := args["column"].(float64); ok && col >= 1 {
+		offset = int(col) - 1
+	}
+	return lsp.Position{Line: int(line) - 1, Character: lsp.UTF16Column(text, offset)}, nil
+}
+
+// indexWord finds name in text as a whole identifier
+func indexWord(text, name string) int {
+	isIdent := func(b byte) bool {
+		return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+	}
+	for from := 0; ; {
+		i := strings.Index(text[from:], name)
+		if i < 0 {
+			return -1
+		}
+		i += from
+		end := i + len(name)
+		if (i == 0 || !isIdent(text[i-1])) && (end == len(text) || !isIdent(text[end])) {
+			return i
+		}
+		from = i + 1
+	}
+}
+
+// describeLocations lists locations with the line of code at each
+func (s *AgenticSkill) describeLocations(locs []lsp.Location, root string) []map[string]interface{} {
+	policy := s.filePolicy()
+	sources := make(map[string][]string)
+	out := make([]map[string]interface{}, 0, len(locs))
+	for _, loc := range locs {
+		path := lsp.URIToPath(loc.URI)
+		entry := map[string]interface{}{
+			"path":   displayPath(path, root),
+			"line":   loc.Range.Start.Line + 1,
+			"column": loc.Range.Start.Character + 1,
+		}
+		if _, err := policy.CheckRead(path); err == nil {
+			lines, ok := sources[path]
+			if !ok {
+				data, _ := os.ReadFile(path)
+				lines = strings.Split(string(data), "\n")
+				sources[path] = lines
+			}
+			if l := loc.Range.Start.Line; l < len(lines) {
+				entry["code"] = strings.TrimSpace(lines[l])
+			}
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// displayPath shows paths inside the project relative to it
+func displayPath(path, root string) string {
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+func (s *AgenticSkill) handleCodeDefinition(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	path, err := s.intelFile(args)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := intelPosition(path, args)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, lspTimeout)
+	defer cancel()
+	client, err := s.languageServers().ClientFor(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	locs, err := client.Definition(ctx, path, pos)
+	if err != nil {
+		return nil, fmt.Errorf("definition lookup failed: %w", err)
+	}
+	if len(locs) == 0 {
+		return nil, fmt.Errorf("no definition found; check the line and symbol")
+	}
+	return map[string]interface{}{
+		"root":        client.Root(),
+		"definitions": s.describeLocations(locs, client.Root()),
+	}, nil
+}
+
+func (s *AgenticSkill) handleCodeReferences(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	path, err := s.intelFile(args)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := intelPosition(path, args)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, lspTimeout)
+	defer cancel()
+	client, err := s.languageServers().ClientFor(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	locs, err := client.References(ctx, path, pos, true)
+	if err != nil {
+		return nil, fmt.Errorf("reference lookup failed: %w", err)
+	}
+	result := map[string]interface{}{
+		"root":  client.Root(),
+		"count": len(locs),
+	}
+	if len(locs) > maxReferences {
+		locs = locs[:maxReferences]
+		result["truncated"] = true
+	}
+	result["references"] = s.describeLocations(locs, client.Root())
+	return result, nil
+}
+
+func (s *AgenticSkill) handleCodeSymbols(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	path, err := s.intelFile(args)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, lspTimeout)
+	defer cancel()
+	client, err := s.languageServers().ClientFor(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	symbols, err := client.Symbols(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("symbol lookup failed: %w", err)
+	}
+	return map[string]interface{}{
+		"path":    args["path"],
+		"symbols": symbols,
+	}, nil
+}
+
+func (s *AgenticSkill) handleCodeDiagnostics(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	path, err := s.intelFile(args)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, lspTimeout)
+	defer cancel()
+	client, err := s.languageServers().ClientFor(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	diags, err := client.Diagnostics(ctx, path, diagnosticsWait)
+	if err != nil {
+		return nil, fmt.Errorf("diagnostics failed: %w", err)
+	}
+
+	problems := make([]map[string]interface{}, 0, len(diags))
+	errorCount := 0
+	for _, d := range diags {
+		severity := lsp.SeverityName(d.Severity)
+		if severity == "error" {
+			errorCount++
+		}
+		problem := map[string]interface{}{
+			"line":     d.Range.Start.Line + 1,
+			"column":   d.Range.Start.Character + 1,
+			"severity": severity,
+			"message":  d.Message,
+		}
+		if d.Source != "" {
+			problem["source"] = d.Source
+		}
+		problems = append(problems, problem)
+	}
+	return map[string]interface{}{
+		"path":        args["path"],
+		"errors":      errorCount,
+		"diagnostics": problems,
+	}, nil
+}
+
+// lspSymbols outlines a file with its language server when one is
+// installed, for analyze_code_file. It returns nil otherwise.
+func (s *AgenticSkill) lspSymbols(ctx context.Context, path string) []lsp.Symbol {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+	servers := s.languageServers()
+	if !servers.Available(abs) {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, lspTimeout)
+	defer cancel()
+	client, err := servers.ClientFor(ctx, abs)
+	if err != nil {
+		return nil
+	}
+	symbols, err := client.Symbols(ctx, abs)
+	if err != nil {
+		return nil
+	}
+	return symbols
+}
@@ -0,0 +1,53 @@
+package agentic
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+func TestIntelPosition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	os.WriteFile(path, []byte("package main\n\nfunc main() {\n\tserver := newServer(addr)\n}\n"), 0644)
+
+	pos, err := intelPosition(path, map[string]interface{}{"line": float64(4), "symbol": "newServer"})
+	if err != nil || pos.Line != 3 || pos.Character != 11 {
+		t.Errorf("Unexpected position %+v, %v", pos, err)
+	}
+	// Without a symbol or column, the first word of the line
+	if pos, _ := intelPosition(path, map[string]interface{}{"line": float64(4)}); pos.Character != 1 {
+		t.Errorf("Expected the first non-blank column, got %+v", pos)
+	}
+	if _, err := intelPosition(path, map[string]interface{}{"line": float64(4), "symbol": "Server"}); err == nil {
+		t.Error("Expected a symbol that is only part of a word to be refused")
+	}
+	if _, err := intelPosition(path, map[string]interface{}{"line": float64(40)}); err == nil {
+		t.Error("Expected a line past the end to be refused")
+	}
+}
+
+func TestIntelWithoutServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0644)
+
+	skill := NewAgenticSkill(t.TempDir())
+	skill.SetCodingConfig(config.CodingConfig{LanguageServers: map[string]string{"go": "no-such-language-server"}})
+
+	_, err := skill.handleCodeSymbols(context.Background(), map[string]interface{}{"path": path})
+	if err == nil || !strings.Contains(err.Error(), "not installed") {
+		t.Errorf("Expected a missing server to be reported, got %v", err)
+	}
+
+	// analyze_code_file falls back to parsing the file itself
+	result, err := skill.handleAnalyzeCodeFile(context.Background(), map[string]interface{}{"path": path})
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	if _, ok := result.(map[string]interface{})["symbols"]; ok {
+		t.Error("Expected no language server symbols")
+	}
+}
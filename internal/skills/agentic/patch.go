@@ -0,0 +1,264 @@
+package agentic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FilePatch is the change a unified diff makes to one file
+type FilePatch struct {
+	OldPath string // empty for a new file
+	NewPath string // empty for a deleted file
+	Hunks   []Hunk
+}
+
+// Path is the file the patch changes
+func (fp FilePatch) Path() string {
+	if fp.NewPath != "" {
+		return fp.NewPath
+	}
+	return fp.OldPath
+}
+
+// Hunk is one @@ section of a diff
+type Hunk struct {
+	OldStart int
+	Lines    []string // each starts with ' ', '-' or '+'
+	// NoNewline is set when the new version doesn't end with a newline
+	NoNewline bool
+}
+
+// counts returns the lines a patch adds and removes
+func (fp FilePatch) counts() (added, removed int) {
+	for _, h := range fp.Hunks {
+		for _, l := range h.Lines {
+			switch l[0] {
+			case '+':
+				added++
+			case '-':
+				removed++
+			}
+		}
+	}
+	return added, removed
+}
+
+// ParsePatch reads a unified diff, as made by diff -u or git diff
+func ParsePatch(diff string) ([]FilePatch, error) {
+	lines := strings.Split(strings.ReplaceAll(diff, "\r\n", "\n"), "\n")
+	var patches []FilePatch
+	var cur *FilePatch
+	var hunk *Hunk
+	oldLeft, newLeft := 0, 0
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if hunk != nil && (oldLeft > 0 || newLeft > 0) {
+			if line == "" {
+				// Editors and models drop the space of blank context lines
+				line = " "
+			}
+			switch line[0] {
+			case ' ':
+				oldLeft--
+				newLeft--
+			case '-':
+				oldLeft--
+			case '+':
+				newLeft--
+			case '\\':
+				continue
+			default:
+				return nil, fmt.Errorf("line %d: hunk of %s ends early", i+1, cur.Path())
+			}
+			if oldLeft < 0 || newLeft < 0 {
+				return nil, fmt.Errorf("line %d: hunk of %s is longer than its header says", i+1, cur.Path())
+			}
+			hunk.Lines = append(hunk.Lines, line)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, `\ No newline`):
+			if hunk != nil && len(hunk.Lines) > 0 && hunk.Lines[len(hunk.Lines)-1][0] != '-' {
+				hunk.NoNewline = true
+			}
+		case strings.HasPrefix(line, "--- "):
+			if i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "+++ ") {
+				return nil, fmt.Errorf("line %d: --- without +++", i+1)
+			}
+			patches = append(patches, FilePatch{
+				OldPath: diffPath(line[4:]),
+				NewPath: diffPath(lines[i+1][4:]),
+			})
+			cur, hunk = &patches[len(patches)-1], nil
+			i++
+		case strings.HasPrefix(line, "@@"):
+			if cur == nil {
+				return nil, fmt.Errorf("line %d: hunk before a file header", i+1)
+			}
+			oldStart, oldCount, newCount, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			cur.Hunks = append(cur.Hunks, Hunk{OldStart: oldStart})
+			hunk = &cur.Hunks[len(cur.Hunks)-1]
+			oldLeft, newLeft = oldCount, newCount
+		}
+		// Anything else, like "diff --git" and "index" lines, is ignored
+	}
+
+	if hunk != nil && (oldLeft > 0 || newLeft > 0) {
+		return nil, fmt.Errorf("last hunk of %s is cut short", cur.Path())
+	}
+	if len(patches) == 0 {
+		return nil, fmt.Errorf("no file changes found; expected a unified diff with ---/+++ headers")
+	}
+	for _, p := range patches {
+		if p.Path() == "" {
+			return nil, fmt.Errorf("a file header names no file")
+		}
+		if len(p.Hunks) == 0 && p.NewPath != "" {
+			return nil, fmt.Errorf("no hunks for %s", p.Path())
+		}
+	}
+	return patches, nil
+}
+
+// diffPath reads a file name from a ---/+++ line, dropping the a/ or b/
+// prefix and any timestamp. /dev/null becomes "".
+func diffPath(s string) string {
+	if tab := strings.IndexByte(s, '\t'); tab >= 0 {
+		s = s[:tab]
+	}
+	s = strings.TrimSpace(s)
+	if s == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/") {
+		s = s[2:]
+	}
+	return s
+}
+
+// parseHunkHeader reads "@@ -l,s +l,s @@"
+func parseHunkHeader(line string) (oldStart, oldCount, newCount int, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[0] != "@@" || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", line)
+	}
+	oldStart, oldCount, err = parseRange(fields[1][1:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", line)
+	}
+	_, newCount, err = parseRange(fields[2][1:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", line)
+	}
+	return oldStart, oldCount, newCount, nil
+}
+
+func parseRange(s string) (start, count int, err error) {
+	startStr, countStr, hasCount := strings.Cut(s, ",")
+	if start, err = strconv.Atoi(startStr); err != nil {
+		return 0, 0, err
+	}
+	count = 1
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
+
+// ApplyPatch applies fp to the content of a file, which is nil for a file
+// that doesn't exist yet. It returns nil for a deleted file. Hunks may have moved a few lines since the diff
+// was made; their context must still match exactly.
+func ApplyPatch(content []byte, fp FilePatch) ([]byte, error) {
+	var lines []string
+	trailingNewline := true
+	if len(content) > 0 {
+		text := string(content)
+		trailingNewline = strings.HasSuffix(text, "\n")
+		lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	}
+
+	offset := 0
+	for n, h := range fp.Hunks {
+		var old, repl []string
+		for _, l := range h.Lines {
+			switch l[0] {
+			case ' ':
+				old = append(old, l[1:])
+				repl = append(repl, l[1:])
+			case '-':
+				old = append(old, l[1:])
+			case '+':
+				repl = append(repl, l[1:])
+			}
+		}
+
+		base := h.OldStart - 1
+		if len(old) == 0 {
+			// A pure insertion comes after line OldStart
+			base = h.OldStart
+		}
+		at := findLines(lines, old, base+offset)
+		if at < 0 {
+			return nil, fmt.Errorf("hunk %d of %s doesn't match the file; read it again and redo the diff", n+1, fp.Path())
+		}
+
+		lines = append(lines[:at], append(repl, lines[at+len(old):]...)...)
+		offset = at - base + len(repl) - len(old)
+		if at+len(repl) == len(lines) {
+			trailingNewline = !h.NoNewline
+		}
+	}
+
+	if fp.NewPath == "" {
+		if len(lines) > 0 {
+			return nil, fmt.Errorf("the diff deletes %s but doesn't remove all of it; read it again and redo the diff", fp.Path())
+		}
+		return nil, nil
+	}
+	if len(lines) == 0 {
+		return []byte{}, nil
+	}
+	out := strings.Join(lines, "\n")
+	if trailingNewline {
+		out += "\n"
+	}
+	return []byte(out), nil
+}
+
+// findLines finds old in lines, looking outwards from want
+func findLines(lines, old []string, want int) int {
+	matches := func(at int) bool {
+		if at < 0 || at+len(old) > len(lines) {
+			return false
+		}
+		for i, l := range old {
+			if lines[at+i] != l {
+				return false
+			}
+		}
+		return true
+	}
+	if want < 0 {
+		want = 0
+	}
+	if want > len(lines) {
+		want = len(lines)
+	}
+	for d := 0; d <= len(lines); d++ {
+		if matches(want - d) {
+			return want - d
+		}
+		if d > 0 && matches(want+d) {
+			return want + d
+		}
+	}
+	return -1
+}
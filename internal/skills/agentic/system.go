@@ -1,16 +1,35 @@
 package agentic
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/load"
+	"github.com/shirou/gopsutil/v4/mem"
+	"github.com/shirou/gopsutil/v4/net"
+	"github.com/shirou/gopsutil/v4/process"
 )
 
+// cpuSampleInterval is how long CPU usage is measured over
+const cpuSampleInterval = 500 * time.Millisecond
+
+// pseudoFilesystems aren't worth reporting disk usage for
+var pseudoFilesystems = map[string]bool{
+	"proc": true, "sysfs": true, "devfs": true, "devtmpfs": true, "tmpfs": true,
+	"cgroup": true, "cgroup2": true, "overlay": true, "squashfs": true,
+	"autofs": true, "securityfs": true, "debugfs": true, "tracefs": true,
+	"mqueue": true, "pstore": true, "bpf": true, "configfs": true, "fusectl": true,
+	"nsfs": true, "hugetlbfs": true, "binfmt_misc": true, "devpts": true,
+}
+
 func (s *AgenticSkill) handleGetSystemResources(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	result := map[string]interface{}{
 		"timestamp": time.Now().Format(time.RFC3339),
@@ -19,98 +38,225 @@ func (s *AgenticSkill) handleGetSystemResources(ctx context.Context, args map[st
 		"cpus":      runtime.NumCPU(),
 	}
 
-	if output, err := exec.CommandContext(ctx, "cat", "/proc/cpuinfo").Output(); err == nil {
-		result["cpu_info"] = parseCPUInfo(string(output))
+	if info, err := host.InfoWithContext(ctx); err == nil {
+		result["hostname"] = info.Hostname
+		result["platform"] = strings.TrimSpace(info.Platform + " " + info.PlatformVersion)
+		result["kernel"] = info.KernelVersion
+		result["uptime"] = (time.Duration(info.Uptime) * time.Second).String()
 	}
 
-	if output, err := exec.CommandContext(ctx, "cat", "/proc/meminfo").Output(); err == nil {
-		result["memory"] = parseMemInfo(string(output))
+	cpuInfo := map[string]interface{}{"logical": runtime.NumCPU()}
+	if infos, err := cpu.InfoWithContext(ctx); err == nil && len(infos) > 0 {
+		cpuInfo["model"] = infos[0].ModelName
+		if infos[0].Mhz > 0 {
+			cpuInfo["mhz"] = infos[0].Mhz
+		}
+	}
+	if physical, err := cpu.CountsWithContext(ctx, false); err == nil && physical > 0 {
+		cpuInfo["physical"] = physical
 	}
+	if usage, err := cpu.PercentWithContext(ctx, cpuSampleInterval, false); err == nil && len(usage) > 0 {
+		cpuInfo["usage_percent"] = round1(usage[0])
+	}
+	result["cpu"] = cpuInfo
 
-	if output, err := exec.CommandContext(ctx, "df", "-h").Output(); err == nil {
-		result["disk_usage"] = string(output)
+	if vm, err := mem.VirtualMemoryWithContext(ctx); err == nil {
+		memory := map[string]interface{}{
+			"total":        formatBytes(vm.Total),
+			"used":         formatBytes(vm.Used),
+			"available":    formatBytes(vm.Available),
+			"used_percent": round1(vm.UsedPercent),
+		}
+		if swap, err := mem.SwapMemoryWithContext(ctx); err == nil && swap.Total > 0 {
+			memory["swap_total"] = formatBytes(swap.Total)
+			memory["swap_used"] = formatBytes(swap.Used)
+		}
+		result["memory"] = memory
 	}
 
-	if output, err := exec.CommandContext(ctx, "cat", "/proc/loadavg").Output(); err == nil {
-		result["load_average"] = strings.TrimSpace(string(output))
+	if disks := diskUsage(ctx); len(disks) > 0 {
+		result["disks"] = disks
 	}
 
-	if output, err := exec.CommandContext(ctx, "uptime", "-p").Output(); err == nil {
-		result["uptime"] = strings.TrimSpace(string(output))
+	// Windows has no load average; gopsutil approximates one after a while
+	if avg, err := load.AvgWithContext(ctx); err == nil && (avg.Load1 > 0 || runtime.GOOS != "windows") {
+		result["load_average"] = fmt.Sprintf("%.2f %.2f %.2f", avg.Load1, avg.Load5, avg.Load15)
 	}
 
+	for k, v := range resourceExtras(ctx) {
+		result[k] = v
+	}
 	return result, nil
 }
 
-func (s *AgenticSkill) handleListProcesses(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-	limit := 20
-	if l, ok := args["limit"].(float64); ok {
-		limit = int(l)
+// diskUsage reports the usage of each mounted disk
+func diskUsage(ctx context.Context) []map[string]interface{} {
+	partitions, err := disk.PartitionsWithContext(ctx, false)
+	if err != nil {
+		return nil
 	}
-
-	filter := ""
-	if f, ok := args["filter"].(string); ok {
-		filter = f
+	var disks []map[string]interface{}
+	seen := make(map[string]bool)
+	for _, p := range partitions {
+		if pseudoFilesystems[p.Fstype] || seen[p.Device] {
+			continue
+		}
+		usage, err := disk.UsageWithContext(ctx, p.Mountpoint)
+		if err != nil || usage.Total == 0 {
+			continue
+		}
+		seen[p.Device] = true
+		disks = append(disks, map[string]interface{}{
+			"mount":        p.Mountpoint,
+			"device":       p.Device,
+			"filesystem":   p.Fstype,
+			"total":        formatBytes(usage.Total),
+			"free":         formatBytes(usage.Free),
+			"used_percent": round1(usage.UsedPercent),
+		})
 	}
+	return disks
+}
 
-	var cmd *exec.Cmd
-	if filter != "" {
-		cmd = exec.CommandContext(ctx, "sh", "-c", fmt.Sprintf("ps aux | grep -i %s | head -%d", filter, limit))
-	} else {
-		cmd = exec.CommandContext(ctx, "sh", "-c", fmt.Sprintf("ps aux --sort=-%%mem | head -%d", limit+1))
+func (s *AgenticSkill) handleListProcesses(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	limit := 20
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
 	}
+	filter, _ := args["filter"].(string)
+	filter = strings.ToLower(filter)
 
-	output, err := cmd.Output()
+	procs, err := process.ProcessesWithContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list processes: %w", err)
 	}
 
-	lines := strings.Split(string(output), "\n")
-	processes := []map[string]string{}
-
-	for i, line := range lines {
-		if i == 0 || line == "" {
+	type entry struct {
+		info map[string]interface{}
+		mem  float32
+	}
+	var entries []entry
+	for _, p := range procs {
+		name, err := p.NameWithContext(ctx)
+		if err != nil {
+			continue // exited, or not ours to inspect
+		}
+		cmdline, _ := p.CmdlineWithContext(ctx)
+		if filter != "" && !strings.Contains(strings.ToLower(name), filter) && !strings.Contains(strings.ToLower(cmdline), filter) {
 			continue
 		}
-		fields := strings.Fields(line)
-		if len(fields) >= 11 {
-			processes = append(processes, map[string]string{
-				"user":    fields[0],
-				"pid":     fields[1],
-				"cpu":     fields[2],
-				"mem":     fields[3],
-				"command": strings.Join(fields[10:], " "),
-			})
+		memPercent, _ := p.MemoryPercentWithContext(ctx)
+		cpuPercent, _ := p.CPUPercentWithContext(ctx)
+		info := map[string]interface{}{
+			"pid":  p.Pid,
+			"name": name,
+			"cpu":  round1(cpuPercent),
+			"mem":  round1(float64(memPercent)),
+		}
+		if user, err := p.UsernameWithContext(ctx); err == nil {
+			info["user"] = user
 		}
+		if cmdline != "" {
+			info["command"] = truncateCommand(cmdline)
+		}
+		entries = append(entries, entry{info: info, mem: memPercent})
 	}
 
+	sort.Slice(entries, func(a, b int) bool { return entries[a].mem > entries[b].mem })
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	processes := make([]map[string]interface{}, len(entries))
+	for n, e := range entries {
+		processes[n] = e.info
+	}
 	return processes, nil
 }
 
 func (s *AgenticSkill) handleGetNetworkInfo(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	result := map[string]interface{}{}
 
-	if output, err := exec.CommandContext(ctx, "ip", "addr").Output(); err == nil {
-		result["interfaces"] = string(output)
-	} else if output, err := exec.CommandContext(ctx, "ifconfig").Output(); err == nil {
-		result["interfaces"] = string(output)
+	if ifaces, err := net.InterfacesWithContext(ctx); err == nil {
+		var list []map[string]interface{}
+		for _, iface := range ifaces {
+			addrs := make([]string, 0, len(iface.Addrs))
+			for _, a := range iface.Addrs {
+				addrs = append(addrs, a.Addr)
+			}
+			entry := map[string]interface{}{
+				"name":      iface.Name,
+				"addresses": addrs,
+				"up":        hasFlag(iface.Flags, "up"),
+			}
+			if iface.HardwareAddr != "" {
+				entry["mac"] = iface.HardwareAddr
+			}
+			if iface.MTU > 0 {
+				entry["mtu"] = iface.MTU
+			}
+			list = append(list, entry)
+		}
+		result["interfaces"] = list
 	}
 
-	if output, err := exec.CommandContext(ctx, "ip", "route").Output(); err == nil {
-		result["routes"] = string(output)
+	if ports := listeningPorts(ctx); ports != nil {
+		result["listening_ports"] = ports
 	}
 
-	if output, err := exec.CommandContext(ctx, "ss", "-tuln").Output(); err == nil {
-		result["listening_ports"] = string(output)
-	} else if output, err := exec.CommandContext(ctx, "netstat", "-tuln").Output(); err == nil {
-		result["listening_ports"] = string(output)
+	for k, v := range networkExtras(ctx) {
+		result[k] = v
 	}
+	return result, nil
+}
 
-	if output, err := exec.CommandContext(ctx, "cat", "/etc/resolv.conf").Output(); err == nil {
-		result["dns"] = string(output)
+// listeningPorts lists the TCP ports listened on and bound UDP ports, with
+// the process holding each when it can be told
+func listeningPorts(ctx context.Context) []map[string]interface{} {
+	conns, err := net.ConnectionsWithContext(ctx, "inet")
+	if err != nil {
+		return nil
 	}
+	names := make(map[int32]string)
+	ports := []map[string]interface{}{}
+	seen := make(map[string]bool)
+	for _, c := range conns {
+		proto := "tcp"
+		if c.Type == 2 { // SOCK_DGRAM
+			proto = "udp"
+			if c.Raddr.Port != 0 {
+				continue
+			}
+		} else if c.Status != "LISTEN" {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s:%d", proto, c.Laddr.IP, c.Laddr.Port)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
 
-	return result, nil
+		port := map[string]interface{}{
+			"protocol": proto,
+			"address":  c.Laddr.IP,
+			"port":     c.Laddr.Port,
+		}
+		if c.Pid > 0 {
+			port["pid"] = c.Pid
+			name, ok := names[c.Pid]
+			if !ok {
+				if p, err := process.NewProcessWithContext(ctx, c.Pid); err == nil {
+					name, _ = p.NameWithContext(ctx)
+				}
+				names[c.Pid] = name
+			}
+			if name != "" {
+				port["process"] = name
+			}
+		}
+		ports = append(ports, port)
+	}
+	sort.Slice(ports, func(a, b int) bool { return ports[a]["port"].(uint32) < ports[b]["port"].(uint32) })
+	return ports
 }
 
 func (s *AgenticSkill) handleGetEnvironment(ctx context.Context, args map[string]interface{}) (interface{}, error) {
@@ -136,42 +282,36 @@ func (s *AgenticSkill) handleGetEnvironment(ctx context.Context, args map[string
 	return env, nil
 }
 
-func parseCPUInfo(data string) []map[string]string {
-	cpus := []map[string]string{}
-	current := map[string]string{}
-
-	scanner := bufio.NewScanner(strings.NewReader(data))
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			if len(current) > 0 {
-				cpus = append(cpus, current)
-				current = map[string]string{}
-			}
-			continue
-		}
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			current[key] = value
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
 		}
 	}
+	return false
+}
 
-	return cpus
+// truncateCommand keeps long command lines readable
+func truncateCommand(cmdline string) string {
+	if len(cmdline) > 200 {
+		return strings.ToValidUTF8(cmdline[:200], "") + "…"
+	}
+	return cmdline
 }
 
-func parseMemInfo(data string) map[string]string {
-	memInfo := map[string]string{}
+func round1(f float64) float64 {
+	return float64(int64(f*10+0.5)) / 10
+}
 
-	scanner := bufio.NewScanner(strings.NewReader(data))
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) == 2 {
-			memInfo[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-		}
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
 	}
-
-	return memInfo
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
 }
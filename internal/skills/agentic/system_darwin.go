@@ -0,0 +1,52 @@
+//go:build darwin
+
+package agentic
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// resourceExtras adds how much memory macOS considers free, the figure its
+// memory pressure gauge is based on
+func resourceExtras(ctx context.Context) map[string]interface{} {
+	out, err := exec.CommandContext(ctx, "sysctl", "-n", "kern.memorystatus_level").Output()
+	if err != nil {
+		return nil
+	}
+	level, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil
+	}
+	return map[string]interface{}{"memory_free_percent": level}
+}
+
+// networkExtras adds the IPv4 routes and DNS settings
+func networkExtras(ctx context.Context) map[string]interface{} {
+	extras := map[string]interface{}{}
+	if out, err := exec.CommandContext(ctx, "netstat", "-rn", "-f", "inet").Output(); err == nil {
+		var routes []map[string]interface{}
+		for _, line := range strings.Split(string(out), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 4 || fields[0] == "Destination" || fields[0] == "Routing" || fields[0] == "Internet:" {
+				continue
+			}
+			route := map[string]interface{}{
+				"destination": fields[0],
+				"gateway":     fields[1],
+				"interface":   fields[3],
+			}
+			routes = append(routes, route)
+		}
+		if len(routes) > 0 {
+			extras["routes"] = routes
+		}
+	}
+	// Generated by macOS from its resolver configuration
+	if dns := resolvConf("/etc/resolv.conf"); len(dns) > 0 {
+		extras["dns"] = dns
+	}
+	return extras
+}
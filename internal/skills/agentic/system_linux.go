@@ -0,0 +1,96 @@
+//go:build linux
+
+package agentic
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// resourceExtras adds pressure stall information, which says how much work
+// waited on CPU, memory or IO over the last ten seconds
+func resourceExtras(ctx context.Context) map[string]interface{} {
+	pressure := map[string]interface{}{}
+	for _, res := range []string{"cpu", "memory", "io"} {
+		data, err := os.ReadFile("/proc/pressure/" + res)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 || fields[0] != "some" {
+				continue
+			}
+			if v, ok := strings.CutPrefix(fields[1], "avg10="); ok {
+				pressure[res+"_some_avg10"] = v + "%"
+			}
+		}
+	}
+	if len(pressure) == 0 {
+		return nil
+	}
+	return map[string]interface{}{"pressure": pressure}
+}
+
+// networkExtras adds the IPv4 routes and DNS settings
+func networkExtras(ctx context.Context) map[string]interface{} {
+	extras := map[string]interface{}{}
+	if routes := linuxRoutes(); len(routes) > 0 {
+		extras["routes"] = routes
+	}
+	if dns := resolvConf("/etc/resolv.conf"); len(dns) > 0 {
+		extras["dns"] = dns
+	}
+	return extras
+}
+
+// linuxRoutes reads /proc/net/route, whose addresses are little-endian hex
+func linuxRoutes() []map[string]interface{} {
+	data, err := os.ReadFile("/proc/net/route")
+	if err != nil {
+		return nil
+	}
+	var routes []map[string]interface{}
+	for n, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if n == 0 || len(fields) < 8 {
+			continue
+		}
+		dest, gateway, mask := hexIP(fields[1]), hexIP(fields[2]), hexIP(fields[7])
+		if dest == nil || gateway == nil || mask == nil {
+			continue
+		}
+		ones, _ := net.IPMask(mask.To4()).Size()
+		route := map[string]interface{}{
+			"destination": fmt.Sprintf("%s/%d", dest, ones),
+			"interface":   fields[0],
+		}
+		if ones == 0 {
+			route["destination"] = "default"
+		}
+		if !gateway.Equal(net.IPv4zero) {
+			route["gateway"] = gateway.String()
+		}
+		if metric, err := strconv.Atoi(fields[6]); err == nil && metric > 0 {
+			route["metric"] = metric
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+func hexIP(s string) net.IP {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 4 {
+		return nil
+	}
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, binary.LittleEndian.Uint32(b))
+	return ip
+}
@@ -0,0 +1,14 @@
+//go:build linux
+
+package agentic
+
+import "testing"
+
+func TestHexIP(t *testing.T) {
+	if ip := hexIP("0100A8C0"); ip.String() != "192.168.0.1" {
+		t.Errorf("Unexpected address %v", ip)
+	}
+	if ip := hexIP("zz"); ip != nil {
+		t.Errorf("Expected nil, got %v", ip)
+	}
+}
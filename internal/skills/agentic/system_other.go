@@ -0,0 +1,18 @@
+//go:build !linux && !darwin && !windows
+
+package agentic
+
+import "context"
+
+// resourceExtras has nothing to add on this OS
+func resourceExtras(ctx context.Context) map[string]interface{} {
+	return nil
+}
+
+// networkExtras adds the DNS settings
+func networkExtras(ctx context.Context) map[string]interface{} {
+	if dns := resolvConf("/etc/resolv.conf"); len(dns) > 0 {
+		return map[string]interface{}{"dns": dns}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package agentic
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSystemIntrospection(t *testing.T) {
+	skill := NewAgenticSkill(t.TempDir())
+	ctx := context.Background()
+
+	result, err := skill.handleGetSystemResources(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get resources: %v", err)
+	}
+	if _, ok := result.(map[string]interface{})["memory"]; !ok {
+		t.Errorf("Expected memory usage, got %v", result)
+	}
+
+	// The test binary finds itself by name, without shelling out to ps
+	self := filepath.Base(os.Args[0])
+	result, err = skill.handleListProcesses(ctx, map[string]interface{}{"filter": self})
+	if err != nil {
+		t.Fatalf("Failed to list processes: %v", err)
+	}
+	found := false
+	for _, p := range result.([]map[string]interface{}) {
+		if p["pid"] == int32(os.Getpid()) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected to find pid %d in %v", os.Getpid(), result)
+	}
+
+	if _, err := skill.handleGetNetworkInfo(ctx, nil); err != nil {
+		t.Errorf("Failed to get network info: %v", err)
+	}
+	if got := formatBytes(3 << 30); got != "3.0 GiB" {
+		t.Errorf("Unexpected size %q", got)
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+
+package agentic
+
+import (
+	"os"
+	"strings"
+)
+
+// resolvConf reads the name servers and search domains of a resolv.conf
+func resolvConf(path string) map[string]interface{} {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var servers, search []string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			servers = append(servers, fields[1])
+		case "search", "domain":
+			search = append(search, fields[1:]...)
+		}
+	}
+	dns := map[string]interface{}{}
+	if len(servers) > 0 {
+		dns["servers"] = servers
+	}
+	if len(search) > 0 {
+		dns["search"] = search
+	}
+	return dns
+}
@@ -0,0 +1,81 @@
+//go:build windows
+
+package agentic
+
+import (
+	"context"
+	"net"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// resourceExtras has nothing to add on Windows; gopsutil covers it
+func resourceExtras(ctx context.Context) map[string]interface{} {
+	return nil
+}
+
+// networkExtras adds the IPv4 routes and DNS servers
+func networkExtras(ctx context.Context) map[string]interface{} {
+	extras := map[string]interface{}{}
+
+	// "route print" has a table of: destination, netmask, gateway,
+	// interface address, metric
+	if out, err := exec.CommandContext(ctx, "route", "print", "-4").Output(); err == nil {
+		var routes []map[string]interface{}
+		for _, line := range strings.Split(string(out), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 5 || net.ParseIP(fields[0]) == nil || net.ParseIP(fields[1]) == nil {
+				continue
+			}
+			route := map[string]interface{}{
+				"destination": fields[0] + "/" + fields[1],
+				"gateway":     fields[2],
+				"interface":   fields[3],
+				"metric":      fields[4],
+			}
+			if fields[1] == "0.0.0.0" {
+				route["destination"] = "default"
+			}
+			routes = append(routes, route)
+		}
+		if len(routes) > 0 {
+			extras["routes"] = routes
+		}
+	}
+
+	// ipconfig lists DNS servers under each adapter, continuing on
+	// indented lines holding only an address
+	if out, err := exec.CommandContext(ctx, "ipconfig", "/all").Output(); err == nil {
+		var servers []string
+		inDNS := false
+		for _, line := range strings.Split(string(out), "\n") {
+			trimmed := strings.TrimSpace(line)
+			var addr string
+			switch {
+			case strings.HasPrefix(trimmed, "DNS Servers"):
+				inDNS = true
+				_, addr, _ = strings.Cut(trimmed, ":")
+			case inDNS:
+				addr = trimmed
+			default:
+				continue
+			}
+			addr = strings.TrimSpace(addr)
+			if zone := strings.IndexByte(addr, '%'); zone >= 0 {
+				addr = addr[:zone] // fe80::1%12
+			}
+			if net.ParseIP(addr) == nil {
+				inDNS = false
+				continue
+			}
+			if !slices.Contains(servers, addr) {
+				servers = append(servers, addr)
+			}
+		}
+		if len(servers) > 0 {
+			extras["dns"] = map[string]interface{}{"servers": servers}
+		}
+	}
+	return extras
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
 )
 
 func (s *AgenticSkill) handleCreateTaskPlan(ctx context.Context, args map[string]interface{}) (interface{}, error) {
@@ -12,23 +14,161 @@ func (s *AgenticSkill) handleCreateTaskPlan(ctx context.Context, args map[string
 		return nil, fmt.Errorf("goal is required")
 	}
 
-	steps := []string{}
-	if s, ok := args["steps"].([]interface{}); ok {
-		for _, step := range s {
-			if str, ok := step.(string); ok {
-				steps = append(steps, str)
-			}
-		}
+	steps := stringList(args["steps"])
+
+	if s.store == nil {
+		return map[string]interface{}{
+			"goal":       goal,
+			"steps":      steps,
+			"created_at": time.Now().Format(time.RFC3339),
+			"status":     "planning",
+		}, nil
+	}
+
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("at least one step is required")
+	}
+
+	plan := &store.Plan{Goal: goal}
+	if convID, ok := ctx.Value("conversation_id").(string); ok {
+		plan.ConversationID = convID
+	}
+	for _, step := range steps {
+		plan.Steps = append(plan.Steps, store.PlanStep{Description: step})
+	}
+	if err := s.store.CreatePlan(plan); err != nil {
+		return nil, fmt.Errorf("failed to save plan: %w", err)
 	}
 
 	return map[string]interface{}{
-		"goal":       goal,
-		"steps":      steps,
-		"created_at": time.Now().Format(time.RFC3339),
-		"status":     "planning",
+		"plan_id": plan.ID,
+		"goal":    plan.Goal,
+		"steps":   steps,
+		"status":  plan.Status,
+		"message": fmt.Sprintf("Plan saved with %d steps. Run it with run_task_plan or 'myrai plan run %s'.", len(steps), plan.ID),
 	}, nil
 }
 
+func (s *AgenticSkill) handleGetTaskPlan(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	plan, err := s.loadPlan(args)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (s *AgenticSkill) handleListTaskPlans(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	if s.store == nil {
+		return nil, fmt.Errorf("task plans are not available without a database")
+	}
+	status, _ := args["status"].(string)
+	plans, err := s.store.ListPlans(status, 20)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]map[string]interface{}, 0, len(plans))
+	for _, p := range plans {
+		done, total := p.Progress()
+		summaries = append(summaries, map[string]interface{}{
+			"plan_id":    p.ID,
+			"goal":       p.Goal,
+			"status":     p.Status,
+			"progress":   fmt.Sprintf("%d/%d", done, total),
+			"updated_at": p.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	return map[string]interface{}{"plans": summaries, "count": len(summaries)}, nil
+}
+
+func (s *AgenticSkill) handleUpdatePlanStep(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	plan, err := s.loadPlan(args)
+	if err != nil {
+		return nil, err
+	}
+
+	position, _ := args["step"].(float64)
+	if int(position) < 1 || int(position) > len(plan.Steps) {
+		return nil, fmt.Errorf("step must be between 1 and %d", len(plan.Steps))
+	}
+	step := &plan.Steps[int(position)-1]
+
+	if status, ok := args["status"].(string); ok && status != "" {
+		switch status {
+		case store.StepStatusPending, store.StepStatusRunning, store.StepStatusCompleted, store.StepStatusFailed, store.StepStatusSkipped:
+		default:
+			return nil, fmt.Errorf("invalid step status: %s", status)
+		}
+		now := time.Now()
+		if status == store.StepStatusRunning && step.StartedAt == nil {
+			step.StartedAt = &now
+		}
+		if status == store.StepStatusCompleted || status == store.StepStatusFailed || status == store.StepStatusSkipped {
+			step.CompletedAt = &now
+		}
+		step.Status = status
+	}
+	if result, ok := args["result"].(string); ok && result != "" {
+		step.Result = result
+	}
+	step.Artifacts = append(step.Artifacts, stringList(args["artifacts"])...)
+
+	if err := s.store.UpdatePlanStep(step); err != nil {
+		return nil, fmt.Errorf("failed to update step: %w", err)
+	}
+	return step, nil
+}
+
+func (s *AgenticSkill) handleSetTaskPlanStatus(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	plan, err := s.loadPlan(args)
+	if err != nil {
+		return nil, err
+	}
+
+	status, _ := args["status"].(string)
+	switch status {
+	case store.PlanStatusActive, store.PlanStatusPaused, store.PlanStatusFailed:
+	default:
+		return nil, fmt.Errorf("invalid plan status: %s", status)
+	}
+	if plan.Status == store.PlanStatusCompleted {
+		return nil, fmt.Errorf("plan %s is already completed", plan.ID)
+	}
+
+	plan.Status = status
+	if err := s.store.UpdatePlan(plan); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"plan_id": plan.ID, "status": plan.Status}, nil
+}
+
+func (s *AgenticSkill) loadPlan(args map[string]interface{}) (*store.Plan, error) {
+	if s.store == nil {
+		return nil, fmt.Errorf("task plans are not available without a database")
+	}
+	id, _ := args["plan_id"].(string)
+	if id == "" {
+		return nil, fmt.Errorf("plan_id is required")
+	}
+	plan, err := s.store.GetPlan(id)
+	if err != nil {
+		return nil, fmt.Errorf("plan not found: %s", id)
+	}
+	return plan, nil
+}
+
+func stringList(v interface{}) []string {
+	list := []string{}
+	if items, ok := v.([]interface{}); ok {
+		for _, item := range items {
+			if str, ok := item.(string); ok && str != "" {
+				list = append(list, str)
+			}
+		}
+	}
+	return list
+}
+
 func (s *AgenticSkill) handleReflectOnTask(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	task, _ := args["task"].(string)
 	currentState, _ := args["current_state"].(string)
@@ -9,11 +9,15 @@ func (s *AgenticSkill) registerTools() {
 	s.registerCodeTools()
 	s.registerGitTools()
 	s.registerTaskTools()
+	s.registerCodingTools()
+	s.registerIntelTools()
+	s.registerIndexTools()
 }
 
 func (s *AgenticSkill) registerSystemTools() {
 	s.AddTool(skills.Tool{
 		Name:        "get_system_resources",
+		ReadOnly:    true,
 		Description: "Get detailed system resource information (CPU, memory, disk, processes)",
 		Parameters: map[string]interface{}{
 			"type":       "object",
@@ -24,6 +28,7 @@ func (s *AgenticSkill) registerSystemTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "list_processes",
+		ReadOnly:    true,
 		Description: "List running processes with resource usage",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -43,6 +48,7 @@ func (s *AgenticSkill) registerSystemTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "get_network_info",
+		ReadOnly:    true,
 		Description: "Get network configuration and connections",
 		Parameters: map[string]interface{}{
 			"type":       "object",
@@ -55,6 +61,7 @@ func (s *AgenticSkill) registerSystemTools() {
 func (s *AgenticSkill) registerCodeTools() {
 	s.AddTool(skills.Tool{
 		Name:        "analyze_project_structure",
+		ReadOnly:    true,
 		Description: "Analyze project structure, identify language, framework, and key files",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -74,6 +81,7 @@ func (s *AgenticSkill) registerCodeTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "analyze_code_file",
+		ReadOnly:    true,
 		Description: "Analyze a code file - extract functions, classes, imports, TODOs",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -90,6 +98,7 @@ func (s *AgenticSkill) registerCodeTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "search_code",
+		ReadOnly:    true,
 		Description: "Search for code patterns across the project (grep with context)",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -118,6 +127,7 @@ func (s *AgenticSkill) registerCodeTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "find_todos",
+		ReadOnly:    true,
 		Description: "Find TODO, FIXME, HACK comments across the codebase",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -135,6 +145,7 @@ func (s *AgenticSkill) registerCodeTools() {
 func (s *AgenticSkill) registerGitTools() {
 	s.AddTool(skills.Tool{
 		Name:        "git_status",
+		ReadOnly:    true,
 		Description: "Get git repository status",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -150,6 +161,7 @@ func (s *AgenticSkill) registerGitTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "git_log",
+		ReadOnly:    true,
 		Description: "Get recent git commit history",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -166,6 +178,10 @@ func (s *AgenticSkill) registerGitTools() {
 					"type":        "string",
 					"description": "Filter to specific file (optional)",
 				},
+				"base": map[string]interface{}{
+					"type":        "string",
+					"description": "Only commits on the current branch since it left this branch, e.g. main (optional)",
+				},
 			},
 		},
 		Handler: s.handleGitLog,
@@ -173,6 +189,7 @@ func (s *AgenticSkill) registerGitTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "git_diff",
+		ReadOnly:    true,
 		Description: "Get git diff for current changes or specific commit",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -189,6 +206,14 @@ func (s *AgenticSkill) registerGitTools() {
 					"type":        "string",
 					"description": "Specific file to diff (optional)",
 				},
+				"staged": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Diff the changes staged for the next commit instead",
+				},
+				"base": map[string]interface{}{
+					"type":        "string",
+					"description": "Diff the current branch against the branch it left, e.g. main, as a pull request would (optional)",
+				},
 			},
 		},
 		Handler: s.handleGitDiff,
@@ -196,6 +221,7 @@ func (s *AgenticSkill) registerGitTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "git_blame",
+		ReadOnly:    true,
 		Description: "Get git blame for a file",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -222,7 +248,7 @@ func (s *AgenticSkill) registerGitTools() {
 func (s *AgenticSkill) registerTaskTools() {
 	s.AddTool(skills.Tool{
 		Name:        "create_task_plan",
-		Description: "Create a structured plan for completing a complex task",
+		Description: "Create and save a step-by-step plan for a complex task. Saved plans are executed one step at a time and can be paused and resumed later.",
 		Parameters: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -232,14 +258,104 @@ func (s *AgenticSkill) registerTaskTools() {
 				},
 				"steps": map[string]interface{}{
 					"type":        "array",
-					"description": "List of steps to complete",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Ordered list of steps to complete",
 				},
 			},
-			"required": []string{"goal"},
+			"required": []string{"goal", "steps"},
 		},
 		Handler: s.handleCreateTaskPlan,
 	})
 
+	s.AddTool(skills.Tool{
+		Name:        "get_task_plan",
+		ReadOnly:    true,
+		Description: "Show a saved task plan with the status, result and artifacts of each step",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"plan_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Plan ID",
+				},
+			},
+			"required": []string{"plan_id"},
+		},
+		Handler: s.handleGetTaskPlan,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "list_task_plans",
+		ReadOnly:    true,
+		Description: "List saved task plans and their progress",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"status": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"active", "paused", "completed", "failed"},
+					"description": "Only list plans with this status (optional)",
+				},
+			},
+		},
+		Handler: s.handleListTaskPlans,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "update_plan_step",
+		Description: "Record the outcome of a plan step: its status, a short result and any artifacts (files, URLs, IDs) it produced",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"plan_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Plan ID",
+				},
+				"step": map[string]interface{}{
+					"type":        "integer",
+					"description": "Step number (starting at 1)",
+				},
+				"status": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"pending", "running", "completed", "failed", "skipped"},
+					"description": "New step status (optional)",
+				},
+				"result": map[string]interface{}{
+					"type":        "string",
+					"description": "Short summary of the outcome (optional)",
+				},
+				"artifacts": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Artifacts produced by the step (optional)",
+				},
+			},
+			"required": []string{"plan_id", "step"},
+		},
+		Handler: s.handleUpdatePlanStep,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "set_task_plan_status",
+		Description: "Pause, resume or abandon a saved task plan",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"plan_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Plan ID",
+				},
+				"status": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"active", "paused", "failed"},
+					"description": "New plan status",
+				},
+			},
+			"required": []string{"plan_id", "status"},
+		},
+		Handler: s.handleSetTaskPlanStatus,
+	})
+
 	s.AddTool(skills.Tool{
 		Name:        "reflect_on_task",
 		Description: "Reflect on task progress and suggest next steps or corrections",
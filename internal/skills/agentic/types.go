@@ -1,12 +1,24 @@
 package agentic
 
 import (
+	"sync"
+
+	"github.com/gmsas95/myrai-cli/internal/codeindex"
+	"github.com/gmsas95/myrai-cli/internal/lsp"
+	"github.com/gmsas95/myrai-cli/internal/security"
 	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/store"
 )
 
 type AgenticSkill struct {
 	*skills.BaseSkill
 	workspaceRoot string
+	store         *store.Store
+	files         *security.FilePolicy
+	coding        coding
+	servers       *lsp.Manager // started on first use
+	index         *codeindex.Manager
+	mu            sync.RWMutex
 }
 
 func NewAgenticSkill(workspaceRoot string) *AgenticSkill {
@@ -17,3 +29,23 @@ func NewAgenticSkill(workspaceRoot string) *AgenticSkill {
 	s.registerTools()
 	return s
 }
+
+// SetStore enables persisted task plans. Without a store, create_task_plan
+// only echoes the plan back.
+func (s *AgenticSkill) SetStore(st *store.Store) {
+	s.store = st
+}
+
+// SetFilePolicy replaces the policy deciding which paths the analysis and
+// git tools may read
+func (s *AgenticSkill) SetFilePolicy(policy *security.FilePolicy) {
+	s.mu.Lock()
+	s.files = policy
+	s.mu.Unlock()
+}
+
+func (s *AgenticSkill) filePolicy() *security.FilePolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.files
+}
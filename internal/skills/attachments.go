@@ -0,0 +1,41 @@
+package skills
+
+import (
+	"context"
+	"sync"
+)
+
+type attachmentsKey struct{}
+
+// Attachments collects the files tools produce for the user during one chat
+// turn, e.g. rendered charts, so the channel can send them alongside the reply
+type Attachments struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+// WithAttachments returns a context tools can attach files to
+func WithAttachments(ctx context.Context) (context.Context, *Attachments) {
+	a := &Attachments{}
+	return context.WithValue(ctx, attachmentsKey{}, a), a
+}
+
+// Attach adds a file to the turn's attachments. It reports false when ctx
+// doesn't collect attachments, so the tool can mention the path instead.
+func Attach(ctx context.Context, path string) bool {
+	a, ok := ctx.Value(attachmentsKey{}).(*Attachments)
+	if !ok {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.paths = append(a.paths, path)
+	return true
+}
+
+// Paths returns the attached files in the order they were added
+func (a *Attachments) Paths() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.paths...)
+}
@@ -3,19 +3,28 @@ package browser
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/kb"
 	"github.com/gmsas95/myrai-cli/internal/skills"
 )
 
-// BrowserSkill provides browser automation capabilities
+// BrowserSkill provides browser automation capabilities. Each named profile
+// keeps its browser open between tool calls, so the agent can log in to a
+// site and keep working in it.
 type BrowserSkill struct {
 	*skills.BaseSkill
 	config Config
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	reaping  bool
 }
 
 // Config holds browser configuration
@@ -23,7 +32,10 @@ type Config struct {
 	Enabled        bool
 	Headless       bool
 	ExecutablePath string
-	UserDataDir    string
+	UserDataDir    string        // used by the default profile instead of one under ProfilesDir
+	ProfilesDir    string        // a user data dir per profile, keeping its cookies
+	ScreenshotDir  string        // defaults to a temporary dir
+	IdleTimeout    time.Duration // browsers unused this long are closed; defaults to 15 minutes
 	CDPPort        int
 }
 
@@ -43,6 +55,12 @@ func (s *BrowserSkill) IsEnabled() bool {
 	return s.config.Enabled
 }
 
+// profileParam is the argument every page tool takes to pick its browser
+var profileParam = map[string]interface{}{
+	"type":        "string",
+	"description": "Optional: named browser profile with its own cookies and logins, kept open between calls (default: 'default')",
+}
+
 func (s *BrowserSkill) registerTools() {
 	s.AddTool(skills.Tool{
 		Name:        "browser_navigate",
@@ -58,6 +76,7 @@ func (s *BrowserSkill) registerTools() {
 					"type":        "string",
 					"description": "Optional: CSS selector to wait for before returning",
 				},
+				"profile": profileParam,
 			},
 			"required": []string{"url"},
 		},
@@ -66,7 +85,7 @@ func (s *BrowserSkill) registerTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "browser_screenshot",
-		Description: "Take a screenshot of the current page",
+		Description: "Take a screenshot of the current page. When the model can view images, the screenshot is shown to it.",
 		Parameters: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -78,6 +97,7 @@ func (s *BrowserSkill) registerTools() {
 					"type":        "boolean",
 					"description": "Whether to capture full page (default: true)",
 				},
+				"profile": profileParam,
 			},
 		},
 		Handler: s.handleScreenshot,
@@ -97,6 +117,7 @@ func (s *BrowserSkill) registerTools() {
 					"type":        "string",
 					"description": "Optional: CSS selector to wait for after clicking",
 				},
+				"profile": profileParam,
 			},
 			"required": []string{"selector"},
 		},
@@ -121,12 +142,76 @@ func (s *BrowserSkill) registerTools() {
 					"type":        "boolean",
 					"description": "Whether to clear the field first (default: true)",
 				},
+				"press_enter": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Whether to press Enter after typing, e.g. to search (default: false)",
+				},
+				"profile": profileParam,
 			},
 			"required": []string{"selector", "text"},
 		},
 		Handler: s.handleType,
 	})
 
+	s.AddTool(skills.Tool{
+		Name:        "browser_fill_form",
+		Description: "Fill in several form fields at once: text inputs, text areas, selects and checkboxes. Optionally submits the form.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"fields": map[string]interface{}{
+					"type":        "array",
+					"description": "Fields to fill, in order",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"selector": map[string]interface{}{
+								"type":        "string",
+								"description": "CSS selector of the field",
+							},
+							"value": map[string]interface{}{
+								"type":        "string",
+								"description": "Text to enter, option value or label to select, or true/false for a checkbox",
+							},
+						},
+						"required": []string{"selector", "value"},
+					},
+				},
+				"submit": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Whether to submit the form afterwards (default: false)",
+				},
+				"submit_selector": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: CSS selector of the button to submit with (default: the form of the last field)",
+				},
+				"profile": profileParam,
+			},
+			"required": []string{"fields"},
+		},
+		Handler: s.handleFillForm,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "browser_submit",
+		Description: "Submit a form, by clicking its button or submitting it directly",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"selector": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: CSS selector of the form or submit button (default: the form with focus, else the first form)",
+				},
+				"wait_for": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: CSS selector to wait for after submitting",
+				},
+				"profile": profileParam,
+			},
+		},
+		Handler: s.handleSubmit,
+	})
+
 	s.AddTool(skills.Tool{
 		Name:        "browser_get_text",
 		Description: "Get text content from the page or a specific element",
@@ -141,6 +226,7 @@ func (s *BrowserSkill) registerTools() {
 					"type":        "integer",
 					"description": "Maximum characters to return (default: 5000)",
 				},
+				"profile": profileParam,
 			},
 		},
 		Handler: s.handleGetText,
@@ -161,11 +247,37 @@ func (s *BrowserSkill) registerTools() {
 					"type":        "integer",
 					"description": "Pixels to scroll (default: 500)",
 				},
+				"profile": profileParam,
 			},
 			"required": []string{"direction"},
 		},
 		Handler: s.handleScroll,
 	})
+
+	s.AddTool(skills.Tool{
+		Name:        "browser_profiles",
+		Description: "List browser profiles and which have a browser open, close a profile's browser, or delete a profile's saved cookies and logins",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"action": map[string]interface{}{
+					"type":        "string",
+					"description": "What to do (default: list)",
+					"enum":        []string{"list", "close", "delete"},
+				},
+				"profile": map[string]interface{}{
+					"type":        "string",
+					"description": "Profile to close or delete",
+				},
+			},
+		},
+		Handler: s.handleProfiles,
+	})
+}
+
+// pageInfo reads where the tab ended up, e.g. after a login redirect
+func pageInfo(url, title *string) chromedp.Action {
+	return chromedp.Tasks{chromedp.Location(url), chromedp.Title(title)}
 }
 
 func (s *BrowserSkill) handleNavigate(ctx context.Context, args map[string]interface{}) (interface{}, error) {
@@ -173,18 +285,16 @@ func (s *BrowserSkill) handleNavigate(ctx context.Context, args map[string]inter
 	if !ok || url == "" {
 		return nil, fmt.Errorf("url is required")
 	}
+	profile, err := checkProfile(stringArg(args, "profile"))
+	if err != nil {
+		return nil, err
+	}
 
 	// Ensure URL has protocol
 	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
 		url = "https://" + url
 	}
 
-	ctx, cancel, err := s.getContext(ctx)
-	if err != nil {
-		return nil, err
-	}
-	defer cancel()
-
 	var actions []chromedp.Action
 	actions = append(actions, chromedp.Navigate(url))
 
@@ -195,23 +305,49 @@ func (s *BrowserSkill) handleNavigate(ctx context.Context, args map[string]inter
 		actions = append(actions, chromedp.WaitReady("body"))
 	}
 
-	if err := chromedp.Run(ctx, actions...); err != nil {
+	var location, title string
+	actions = append(actions, pageInfo(&location, &title))
+	if err := s.run(ctx, profile, actions...); err != nil {
 		return nil, fmt.Errorf("failed to navigate: %w", err)
 	}
 
 	return map[string]string{
 		"status":  "success",
-		"url":     url,
-		"message": fmt.Sprintf("Navigated to %s", url),
+		"url":     location,
+		"title":   title,
+		"profile": profile,
+		"message": fmt.Sprintf("Navigated to %s", location),
 	}, nil
 }
 
+// RenderHTML loads url and returns the page's HTML once it is ready, for
+// pages that build their content with scripts. It uses a throwaway browser
+// so it neither disturbs nor sees the profiles' tabs.
+func (s *BrowserSkill) RenderHTML(ctx context.Context, url string) (string, error) {
+	allocCtx, allocCancel := chromedp.NewExecAllocator(ctx, s.allocatorOptions("")...)
+	defer allocCancel()
+	ctx, cancel := chromedp.NewContext(allocCtx)
+	defer cancel()
+	ctx, timeout := context.WithTimeout(ctx, 45*time.Second)
+	defer timeout()
+
+	var html string
+	if err := chromedp.Run(ctx,
+		chromedp.Navigate(url),
+		chromedp.WaitReady("body"),
+		chromedp.Sleep(time.Second),
+		chromedp.OuterHTML("html", &html, chromedp.ByQuery),
+	); err != nil {
+		return "", fmt.Errorf("failed to render page: %w", err)
+	}
+	return html, nil
+}
+
 func (s *BrowserSkill) handleScreenshot(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-	ctx, cancel, err := s.getContext(ctx)
+	profile, err := checkProfile(stringArg(args, "profile"))
 	if err != nil {
 		return nil, err
 	}
-	defer cancel()
 
 	fullPage := true
 	if fp, ok := args["full_page"].(bool); ok {
@@ -219,33 +355,76 @@ func (s *BrowserSkill) handleScreenshot(ctx context.Context, args map[string]int
 	}
 
 	var buf []byte
-	var actions []chromedp.Action
+	var action chromedp.Action
+	format := "png"
 
 	if selector, ok := args["selector"].(string); ok && selector != "" {
 		// Screenshot specific element
-		actions = append(actions, chromedp.Screenshot(selector, &buf, chromedp.ByQuery))
+		action = chromedp.Screenshot(selector, &buf, chromedp.ByQuery)
 	} else if fullPage {
-		// Full page screenshot
-		actions = append(actions, chromedp.FullScreenshot(&buf, 90))
+		// Full page screenshot; below full quality it's a JPEG
+		action = chromedp.FullScreenshot(&buf, 90)
+		format = "jpeg"
 	} else {
 		// Viewport screenshot
-		actions = append(actions, chromedp.CaptureScreenshot(&buf))
+		action = chromedp.CaptureScreenshot(&buf)
 	}
 
-	if err := chromedp.Run(ctx, actions...); err != nil {
+	var location, title string
+	if err := s.run(ctx, profile, action, pageInfo(&location, &title)); err != nil {
 		return nil, fmt.Errorf("failed to take screenshot: %w", err)
 	}
 
-	// Encode to base64 for transmission
-	encoded := base64.StdEncoding.EncodeToString(buf)
+	path, err := s.saveScreenshot(profile, format, buf)
+	if err != nil {
+		return nil, err
+	}
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"status":     "success",
-		"screenshot": encoded,
-		"format":     "png",
+		"path":       path,
+		"format":     format,
 		"size_bytes": len(buf),
-		"message":    "Screenshot captured. Use the base64 data to display or save.",
-	}, nil
+		"url":        location,
+		"title":      title,
+	}
+	if skills.ShowImage(ctx, path) {
+		result["message"] = "Screenshot captured and attached for you to look at."
+	} else {
+		result["message"] = "Screenshot saved. The current model can't view images; use analyze_image on the path to have it described."
+	}
+	return result, nil
+}
+
+// saveScreenshot writes a screenshot to the screenshot dir, clearing out
+// ones older than a week
+func (s *BrowserSkill) saveScreenshot(profile, format string, data []byte) (string, error) {
+	dir := s.config.ScreenshotDir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "myrai-browser")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create screenshot dir: %w", err)
+	}
+
+	if entries, err := os.ReadDir(dir); err == nil {
+		cutoff := time.Now().AddDate(0, 0, -7)
+		for _, e := range entries {
+			if info, err := e.Info(); err == nil && !e.IsDir() && info.ModTime().Before(cutoff) {
+				os.Remove(filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+
+	ext := ".png"
+	if format == "jpeg" {
+		ext = ".jpg"
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s%s", profile, time.Now().Format("20060102_150405.000"), ext))
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to save screenshot: %w", err)
+	}
+	return path, nil
 }
 
 func (s *BrowserSkill) handleClick(ctx context.Context, args map[string]interface{}) (interface{}, error) {
@@ -253,12 +432,10 @@ func (s *BrowserSkill) handleClick(ctx context.Context, args map[string]interfac
 	if !ok || selector == "" {
 		return nil, fmt.Errorf("selector is required")
 	}
-
-	ctx, cancel, err := s.getContext(ctx)
+	profile, err := checkProfile(stringArg(args, "profile"))
 	if err != nil {
 		return nil, err
 	}
-	defer cancel()
 
 	actions := []chromedp.Action{
 		chromedp.WaitVisible(selector, chromedp.ByQuery),
@@ -272,12 +449,16 @@ func (s *BrowserSkill) handleClick(ctx context.Context, args map[string]interfac
 		actions = append(actions, chromedp.Sleep(500*time.Millisecond))
 	}
 
-	if err := chromedp.Run(ctx, actions...); err != nil {
+	var location, title string
+	actions = append(actions, pageInfo(&location, &title))
+	if err := s.run(ctx, profile, actions...); err != nil {
 		return nil, fmt.Errorf("failed to click: %w", err)
 	}
 
 	return map[string]string{
 		"status":  "success",
+		"url":     location,
+		"title":   title,
 		"message": fmt.Sprintf("Clicked on %s", selector),
 	}, nil
 }
@@ -297,12 +478,12 @@ func (s *BrowserSkill) handleType(ctx context.Context, args map[string]interface
 	if cf, ok := args["clear_first"].(bool); ok {
 		clearFirst = cf
 	}
+	pressEnter, _ := args["press_enter"].(bool)
 
-	ctx, cancel, err := s.getContext(ctx)
+	profile, err := checkProfile(stringArg(args, "profile"))
 	if err != nil {
 		return nil, err
 	}
-	defer cancel()
 
 	actions := []chromedp.Action{
 		chromedp.WaitVisible(selector, chromedp.ByQuery),
@@ -313,23 +494,110 @@ func (s *BrowserSkill) handleType(ctx context.Context, args map[string]interface
 	}
 
 	actions = append(actions, chromedp.SendKeys(selector, text, chromedp.ByQuery))
+	if pressEnter {
+		actions = append(actions, chromedp.SendKeys(selector, kb.Enter, chromedp.ByQuery), chromedp.Sleep(time.Second))
+	}
 
-	if err := chromedp.Run(ctx, actions...); err != nil {
+	if err := s.run(ctx, profile, actions...); err != nil {
 		return nil, fmt.Errorf("failed to type: %w", err)
 	}
 
 	return map[string]string{
 		"status":  "success",
-		"message": fmt.Sprintf("Typed '%s' into %s", text, selector),
+		"message": fmt.Sprintf("Typed %d characters into %s", len([]rune(text)), selector),
+	}, nil
+}
+
+func (s *BrowserSkill) handleFillForm(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	fields, err := formFields(args["fields"])
+	if err != nil {
+		return nil, err
+	}
+	profile, err := checkProfile(stringArg(args, "profile"))
+	if err != nil {
+		return nil, err
+	}
+	submit, _ := args["submit"].(bool)
+	submitSelector := stringArg(args, "submit_selector")
+	if submitSelector != "" {
+		submit = true
+	}
+
+	var filled []string
+	var location, title string
+	fill := chromedp.ActionFunc(func(ctx context.Context) error {
+		for _, f := range fields {
+			if err := fillField(ctx, f); err != nil {
+				return fmt.Errorf("%s: %w", f.selector, err)
+			}
+			filled = append(filled, f.selector)
+		}
+		if !submit {
+			return nil
+		}
+		// Without a button, submit the form the last field belongs to
+		target := submitSelector
+		if target == "" {
+			target = fields[len(fields)-1].selector
+		}
+		return submitForm(ctx, target, submitSelector == "")
+	})
+
+	actions := []chromedp.Action{fill}
+	if submit {
+		actions = append(actions, chromedp.Sleep(time.Second), chromedp.WaitReady("body"))
+	}
+	actions = append(actions, pageInfo(&location, &title))
+	if err := s.run(ctx, profile, actions...); err != nil {
+		return nil, fmt.Errorf("failed to fill form after %d of %d fields: %w", len(filled), len(fields), err)
+	}
+
+	return map[string]interface{}{
+		"status":    "success",
+		"filled":    filled,
+		"submitted": submit,
+		"url":       location,
+		"title":     title,
+	}, nil
+}
+
+func (s *BrowserSkill) handleSubmit(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	profile, err := checkProfile(stringArg(args, "profile"))
+	if err != nil {
+		return nil, err
+	}
+	selector := stringArg(args, "selector")
+
+	actions := []chromedp.Action{
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			return submitForm(ctx, selector, false)
+		}),
+	}
+	if waitFor := stringArg(args, "wait_for"); waitFor != "" {
+		actions = append(actions, chromedp.WaitVisible(waitFor, chromedp.ByQuery))
+	} else {
+		actions = append(actions, chromedp.Sleep(time.Second), chromedp.WaitReady("body"))
+	}
+
+	var location, title string
+	actions = append(actions, pageInfo(&location, &title))
+	if err := s.run(ctx, profile, actions...); err != nil {
+		return nil, fmt.Errorf("failed to submit: %w", err)
+	}
+
+	return map[string]string{
+		"status":  "success",
+		"url":     location,
+		"title":   title,
+		"message": "Form submitted",
 	}, nil
 }
 
 func (s *BrowserSkill) handleGetText(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-	ctx, cancel, err := s.getContext(ctx)
+	profile, err := checkProfile(stringArg(args, "profile"))
 	if err != nil {
 		return nil, err
 	}
-	defer cancel()
 
 	maxLength := 5000
 	if ml, ok := args["max_length"].(float64); ok {
@@ -342,7 +610,7 @@ func (s *BrowserSkill) handleGetText(ctx context.Context, args map[string]interf
 		selector = sel
 	}
 
-	if err := chromedp.Run(ctx,
+	if err := s.run(ctx, profile,
 		chromedp.Text(selector, &text, chromedp.ByQuery),
 	); err != nil {
 		return nil, fmt.Errorf("failed to get text: %w", err)
@@ -368,18 +636,16 @@ func (s *BrowserSkill) handleScroll(ctx context.Context, args map[string]interfa
 	if !ok || direction == "" {
 		return nil, fmt.Errorf("direction is required")
 	}
+	profile, err := checkProfile(stringArg(args, "profile"))
+	if err != nil {
+		return nil, err
+	}
 
 	amount := 500
 	if a, ok := args["amount"].(float64); ok {
 		amount = int(a)
 	}
 
-	ctx, cancel, err := s.getContext(ctx)
-	if err != nil {
-		return nil, err
-	}
-	defer cancel()
-
 	var script string
 	switch direction {
 	case "up":
@@ -394,7 +660,7 @@ func (s *BrowserSkill) handleScroll(ctx context.Context, args map[string]interfa
 		return nil, fmt.Errorf("invalid direction: %s", direction)
 	}
 
-	if err := chromedp.Run(ctx,
+	if err := s.run(ctx, profile,
 		chromedp.Evaluate(script, nil),
 	); err != nil {
 		return nil, fmt.Errorf("failed to scroll: %w", err)
@@ -407,35 +673,37 @@ func (s *BrowserSkill) handleScroll(ctx context.Context, args map[string]interfa
 	}, nil
 }
 
-func (s *BrowserSkill) getContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
-	opts := []chromedp.ExecAllocatorOption{
-		chromedp.NoFirstRun,
-		chromedp.NoDefaultBrowserCheck,
+func (s *BrowserSkill) handleProfiles(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	action := stringArg(args, "action")
+	if action == "" || action == "list" {
+		return map[string]interface{}{"profiles": s.profiles()}, nil
 	}
 
-	if s.config.Headless {
-		opts = append(opts, chromedp.Headless)
+	name := stringArg(args, "profile")
+	if name == "" {
+		return nil, fmt.Errorf("profile is required to %s", action)
 	}
-
-	if s.config.ExecutablePath != "" {
-		opts = append(opts, chromedp.ExecPath(s.config.ExecutablePath))
-	}
-
-	if s.config.UserDataDir != "" {
-		opts = append(opts, chromedp.UserDataDir(s.config.UserDataDir))
+	profile, err := checkProfile(name)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create allocator
-	allocCtx, allocCancel := chromedp.NewExecAllocator(ctx, opts...)
-
-	// Create context
-	taskCtx, taskCancel := chromedp.NewContext(allocCtx)
-
-	// Return a combined cancel function that cleans up both
-	cancel := func() {
-		taskCancel()
-		allocCancel()
+	switch action {
+	case "close":
+		if !s.closeSession(profile) {
+			return map[string]string{"status": "success", "message": fmt.Sprintf("Profile %s had no browser open", profile)}, nil
+		}
+		return map[string]string{"status": "success", "message": fmt.Sprintf("Closed the browser for profile %s; its logins are kept", profile)}, nil
+	case "delete":
+		if err := s.deleteProfile(profile); err != nil {
+			return nil, err
+		}
+		return map[string]string{"status": "success", "message": fmt.Sprintf("Deleted profile %s and its cookies", profile)}, nil
 	}
+	return nil, fmt.Errorf("invalid action: %s", action)
+}
 
-	return taskCtx, cancel, nil
+func stringArg(args map[string]interface{}, key string) string {
+	v, _ := args[key].(string)
+	return strings.TrimSpace(v)
 }
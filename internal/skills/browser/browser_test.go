@@ -0,0 +1,145 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"", DefaultProfile, false},
+		{"work", "work", false},
+		{"shop_2-b", "shop_2-b", false},
+		{"../escape", "", true},
+		{"with space", "", true},
+		{strings.Repeat("a", 65), "", true},
+	}
+	for _, tt := range tests {
+		got, err := checkProfile(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkProfile(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("checkProfile(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProfileDir(t *testing.T) {
+	s := NewBrowserSkill(Config{ProfilesDir: "/data/profiles"})
+	if got := s.profileDir("work"); got != filepath.Join("/data/profiles", "work") {
+		t.Errorf("profileDir(work) = %q", got)
+	}
+
+	s = NewBrowserSkill(Config{ProfilesDir: "/data/profiles", UserDataDir: "/home/me/chrome"})
+	if got := s.profileDir(DefaultProfile); got != "/home/me/chrome" {
+		t.Errorf("default profile should use the user data dir, got %q", got)
+	}
+	if err := s.deleteProfile(DefaultProfile); err == nil {
+		t.Error("deleting the configured user data dir should fail")
+	}
+
+	s = NewBrowserSkill(Config{})
+	if got := s.profileDir("work"); got != "" {
+		t.Errorf("without a profiles dir, profileDir = %q, want temporary", got)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"work", "default", "bad name"} {
+		os.MkdirAll(filepath.Join(dir, name), 0700)
+	}
+	s := NewBrowserSkill(Config{ProfilesDir: dir})
+
+	profiles := s.profiles()
+	if len(profiles) != 2 {
+		t.Fatalf("Expected 2 profiles, got %v", profiles)
+	}
+	if profiles[0]["profile"] != "default" || profiles[1]["profile"] != "work" {
+		t.Errorf("Expected default and work in order, got %v", profiles)
+	}
+	if profiles[0]["open"] != false {
+		t.Errorf("No browser should be open, got %v", profiles[0])
+	}
+
+	if err := s.deleteProfile("work"); err != nil {
+		t.Fatalf("deleteProfile failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "work")); !os.IsNotExist(err) {
+		t.Error("Expected the profile dir to be removed")
+	}
+}
+
+func TestFormFields(t *testing.T) {
+	fields, err := formFields([]interface{}{
+		map[string]interface{}{"selector": "#email", "value": "me@example.com"},
+		map[string]interface{}{"selector": "#remember", "value": true},
+		map[string]interface{}{"selector": "#age", "value": float64(42)},
+	})
+	if err != nil {
+		t.Fatalf("formFields failed: %v", err)
+	}
+	want := []formField{{"#email", "me@example.com"}, {"#remember", "true"}, {"#age", "42"}}
+	if len(fields) != len(want) {
+		t.Fatalf("Expected %d fields, got %d", len(want), len(fields))
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("field %d = %+v, want %+v", i, fields[i], want[i])
+		}
+	}
+
+	for _, bad := range []interface{}{
+		nil,
+		[]interface{}{},
+		[]interface{}{"#email"},
+		[]interface{}{map[string]interface{}{"value": "x"}},
+		[]interface{}{map[string]interface{}{"selector": "#email"}},
+	} {
+		if _, err := formFields(bad); err == nil {
+			t.Errorf("formFields(%v) should fail", bad)
+		}
+	}
+}
+
+func TestParseChecked(t *testing.T) {
+	for value, want := range map[string]bool{"true": true, "Yes": true, "on": true, "false": false, "0": false} {
+		got, err := parseChecked(value)
+		if err != nil || got != want {
+			t.Errorf("parseChecked(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	if _, err := parseChecked("maybe"); err == nil {
+		t.Error("parseChecked(maybe) should fail")
+	}
+}
+
+func TestJSArgs(t *testing.T) {
+	if got := jsArgs(`input[name="q"]`, true); got != `"input[name=\"q\"]", true` {
+		t.Errorf("jsArgs = %s", got)
+	}
+}
+
+func TestSaveScreenshot(t *testing.T) {
+	dir := t.TempDir()
+	s := NewBrowserSkill(Config{ScreenshotDir: dir})
+
+	path, err := s.saveScreenshot("work", "jpeg", []byte("image"))
+	if err != nil {
+		t.Fatalf("saveScreenshot failed: %v", err)
+	}
+	if filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), "work_") || filepath.Ext(path) != ".jpg" {
+		t.Errorf("Unexpected screenshot path %s", path)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "image" {
+		t.Errorf("Screenshot content = %q", data)
+	}
+}
@@ -0,0 +1,167 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// formField is a field for browser_fill_form to fill
+type formField struct {
+	selector string
+	value    string
+}
+
+// formFields reads the fields argument, taking values of any JSON type
+func formFields(arg interface{}) ([]formField, error) {
+	list, ok := arg.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("fields is required")
+	}
+	fields := make([]formField, 0, len(list))
+	for i, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("field %d must be an object with selector and value", i+1)
+		}
+		selector, _ := m["selector"].(string)
+		if strings.TrimSpace(selector) == "" {
+			return nil, fmt.Errorf("field %d has no selector", i+1)
+		}
+		value, ok := m["value"]
+		if !ok || value == nil {
+			return nil, fmt.Errorf("field %d has no value", i+1)
+		}
+		fields = append(fields, formField{selector: selector, value: fmt.Sprint(value)})
+	}
+	return fields, nil
+}
+
+// parseChecked reads a checkbox value
+func parseChecked(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "on", "1", "checked":
+		return true, nil
+	case "false", "no", "off", "0", "unchecked", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("checkbox value must be true or false, not %q", value)
+}
+
+// jsArgs quotes values to pass to a script
+func jsArgs(values ...interface{}) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		b, _ := json.Marshal(v)
+		quoted[i] = string(b)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// fieldKindJS tells how a field takes its value
+const fieldKindJS = `(function(sel) {
+	const el = document.querySelector(sel);
+	if (!el) return "";
+	if (el.tagName === "SELECT") return "select";
+	if (el.type === "checkbox" || el.type === "radio") return "check";
+	return "text";
+})(%s)`
+
+// selectJS picks an option by value or by label, firing the events a user's
+// choice would
+const selectJS = `(function(sel, value) {
+	const el = document.querySelector(sel);
+	const opt = Array.from(el.options).find(o => o.value === value) ||
+		Array.from(el.options).find(o => o.text.trim().toLowerCase() === value.trim().toLowerCase());
+	if (!opt) return false;
+	el.value = opt.value;
+	el.dispatchEvent(new Event("input", {bubbles: true}));
+	el.dispatchEvent(new Event("change", {bubbles: true}));
+	return true;
+})(%s)`
+
+// checkJS clicks a checkbox or radio button if it isn't as wanted, so the
+// page's handlers run
+const checkJS = `(function(sel, want) {
+	const el = document.querySelector(sel);
+	if (el.checked !== want) el.click();
+	return el.checked === want;
+})(%s)`
+
+// submitJS submits a form. A selector names the form or the button to
+// click; without one, or with a field's selector when asked for its form,
+// the form is found from the element with focus or is the page's first.
+const submitJS = `(function(sel, formOf) {
+	const el = sel ? document.querySelector(sel) : null;
+	if (sel && !el) return "missing";
+	if (el && !formOf && el.tagName !== "FORM") { el.click(); return "clicked"; }
+	const form = el ? (el.tagName === "FORM" ? el : el.form) :
+		(document.activeElement && document.activeElement.form) || document.forms[0];
+	if (!form) return "no form";
+	if (form.requestSubmit) form.requestSubmit(); else form.submit();
+	return "submitted";
+})(%s)`
+
+// fillField fills one field according to its kind. Text is typed, so pages
+// that react to keystrokes see it as they would a user's.
+func fillField(ctx context.Context, f formField) error {
+	if err := chromedp.WaitReady(f.selector, chromedp.ByQuery).Do(ctx); err != nil {
+		return err
+	}
+	var kind string
+	if err := chromedp.Evaluate(fmt.Sprintf(fieldKindJS, jsArgs(f.selector)), &kind).Do(ctx); err != nil {
+		return err
+	}
+
+	switch kind {
+	case "select":
+		var ok bool
+		if err := chromedp.Evaluate(fmt.Sprintf(selectJS, jsArgs(f.selector, f.value)), &ok).Do(ctx); err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("no option %q", f.value)
+		}
+	case "check":
+		want, err := parseChecked(f.value)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		if err := chromedp.Evaluate(fmt.Sprintf(checkJS, jsArgs(f.selector, want)), &ok).Do(ctx); err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("could not set it to %t", want)
+		}
+	case "text":
+		if err := chromedp.Clear(f.selector, chromedp.ByQuery).Do(ctx); err != nil {
+			return err
+		}
+		if err := chromedp.SendKeys(f.selector, f.value, chromedp.ByQuery).Do(ctx); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("not found")
+	}
+	return nil
+}
+
+// submitForm submits the form for selector; with formOf, selector is one of
+// the form's fields rather than its button
+func submitForm(ctx context.Context, selector string, formOf bool) error {
+	var outcome string
+	if err := chromedp.Evaluate(fmt.Sprintf(submitJS, jsArgs(selector, formOf)), &outcome).Do(ctx); err != nil {
+		return err
+	}
+	switch outcome {
+	case "missing":
+		return fmt.Errorf("%s not found", selector)
+	case "no form":
+		return fmt.Errorf("no form to submit")
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	// DefaultProfile is the profile tools use when none is named
+	DefaultProfile = "default"
+	// defaultIdleTimeout closes browsers nobody has used for a while
+	defaultIdleTimeout = 15 * time.Minute
+	// actionTimeout bounds one tool's actions, so a selector that never
+	// appears doesn't hang the turn
+	actionTimeout = 60 * time.Second
+)
+
+var profileName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// session is a browser kept open between tool calls for one profile. Each
+// profile has its own user data dir, so its cookies and logins survive
+// restarts as well.
+type session struct {
+	profile  string
+	ctx      context.Context // the tab
+	cancel   context.CancelFunc
+	mu       sync.Mutex // actions on a tab run one at a time
+	lastUsed time.Time
+}
+
+// checkProfile returns the profile to use for a tool's profile argument
+func checkProfile(name string) (string, error) {
+	if name == "" {
+		return DefaultProfile, nil
+	}
+	if !profileName.MatchString(name) {
+		return "", fmt.Errorf("invalid profile name %q: use letters, digits, '-' and '_'", name)
+	}
+	return name, nil
+}
+
+// profileDir is where a profile keeps its cookies and storage. Without a
+// profiles dir, browsers start from a fresh temporary profile.
+func (s *BrowserSkill) profileDir(profile string) string {
+	if profile == DefaultProfile && s.config.UserDataDir != "" {
+		return s.config.UserDataDir
+	}
+	if s.config.ProfilesDir == "" {
+		return ""
+	}
+	return filepath.Join(s.config.ProfilesDir, profile)
+}
+
+func (s *BrowserSkill) allocatorOptions(userDataDir string) []chromedp.ExecAllocatorOption {
+	opts := append([]chromedp.ExecAllocatorOption(nil), chromedp.DefaultExecAllocatorOptions[:]...)
+	if !s.config.Headless {
+		opts = append(opts, chromedp.Flag("headless", false))
+	}
+	if s.config.ExecutablePath != "" {
+		opts = append(opts, chromedp.ExecPath(s.config.ExecutablePath))
+	}
+	if userDataDir != "" {
+		opts = append(opts, chromedp.UserDataDir(userDataDir))
+	}
+	return opts
+}
+
+// session returns the open browser for a profile, starting it if needed
+func (s *BrowserSkill) session(profile string) (*session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sess, ok := s.sessions[profile]; ok {
+		if sess.ctx.Err() == nil {
+			return sess, nil
+		}
+		sess.cancel() // the browser exited
+		delete(s.sessions, profile)
+	}
+
+	dir := s.profileDir(profile)
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create profile dir: %w", err)
+		}
+	}
+
+	// Sessions outlive the tool call that starts them
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), s.allocatorOptions(dir)...)
+	tabCtx, tabCancel := chromedp.NewContext(allocCtx)
+	cancel := func() {
+		tabCancel()
+		allocCancel()
+	}
+	// The first run starts the browser
+	if err := chromedp.Run(tabCtx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start browser: %w", err)
+	}
+
+	sess := &session{profile: profile, ctx: tabCtx, cancel: cancel, lastUsed: time.Now()}
+	if s.sessions == nil {
+		s.sessions = make(map[string]*session)
+	}
+	s.sessions[profile] = sess
+	if !s.reaping {
+		s.reaping = true
+		go s.reapIdle()
+	}
+	return sess, nil
+}
+
+// run performs actions in a profile's browser. They stop when ctx is done,
+// without closing the browser.
+func (s *BrowserSkill) run(ctx context.Context, profile string, actions ...chromedp.Action) error {
+	sess, err := s.session(profile)
+	if err != nil {
+		return err
+	}
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	runCtx, cancel := context.WithTimeout(sess.ctx, actionTimeout)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	err = chromedp.Run(runCtx, actions...)
+	s.mu.Lock()
+	sess.lastUsed = time.Now()
+	s.mu.Unlock()
+	return err
+}
+
+// reapIdle closes browsers that haven't been used within the idle timeout
+func (s *BrowserSkill) reapIdle() {
+	idle := s.config.IdleTimeout
+	if idle <= 0 {
+		idle = defaultIdleTimeout
+	}
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.mu.Lock()
+		for profile, sess := range s.sessions {
+			if time.Since(sess.lastUsed) > idle {
+				sess.cancel()
+				delete(s.sessions, profile)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// closeSession closes a profile's browser, reporting whether it was open.
+// The profile's cookies stay on disk.
+func (s *BrowserSkill) closeSession(profile string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[profile]
+	if ok {
+		sess.cancel()
+		delete(s.sessions, profile)
+	}
+	return ok
+}
+
+// Close closes every open browser
+func (s *BrowserSkill) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for profile, sess := range s.sessions {
+		sess.cancel()
+		delete(s.sessions, profile)
+	}
+}
+
+// profiles lists the saved profiles and whether each has a browser open
+func (s *BrowserSkill) profiles() []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := map[string]bool{}
+	var out []map[string]interface{}
+	add := func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		entry := map[string]interface{}{"profile": name, "open": false}
+		if sess, ok := s.sessions[name]; ok {
+			entry["open"] = true
+			entry["idle"] = time.Since(sess.lastUsed).Round(time.Second).String()
+		}
+		out = append(out, entry)
+	}
+
+	for name := range s.sessions {
+		add(name)
+	}
+	if s.config.ProfilesDir != "" {
+		entries, _ := os.ReadDir(s.config.ProfilesDir)
+		for _, e := range entries {
+			if e.IsDir() && profileName.MatchString(e.Name()) {
+				add(e.Name())
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i]["profile"].(string) < out[j]["profile"].(string)
+	})
+	return out
+}
+
+// deleteProfile closes a profile's browser and removes its saved data,
+// logging it out of everything
+func (s *BrowserSkill) deleteProfile(profile string) error {
+	s.closeSession(profile)
+	dir := s.profileDir(profile)
+	if dir == "" {
+		return nil
+	}
+	if dir == s.config.UserDataDir {
+		return fmt.Errorf("profile %s uses the configured user data dir, which is left to you to delete", profile)
+	}
+	// Chrome may still be writing as it exits
+	var err error
+	for i := 0; i < 5; i++ {
+		if err = os.RemoveAll(dir); err == nil {
+			return nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return fmt.Errorf("failed to delete profile: %w", err)
+}
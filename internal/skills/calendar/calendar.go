@@ -1,12 +1,17 @@
 package calendar
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/locale"
 	"github.com/gmsas95/myrai-cli/internal/skills"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -16,8 +21,8 @@ import (
 type CalendarSkill struct {
 	*skills.BaseSkill
 	store    *Store
-	parser   *EventParser
 	google   *GoogleCalendarProvider
+	sync     *SyncEngine
 	logger   *zap.Logger
 	config   CalendarSkillConfig
 }
@@ -42,11 +47,12 @@ func NewCalendarSkill(db *gorm.DB, config CalendarSkillConfig, logger *zap.Logge
 	googleConfig.ClientID = config.GoogleClientID
 	googleConfig.ClientSecret = config.GoogleSecret
 	
+	google := NewGoogleCalendarProvider(googleConfig, logger)
 	skill := &CalendarSkill{
 		BaseSkill: skills.NewBaseSkill("calendar", "Calendar Management", "1.0.0"),
 		store:     store,
-		parser:    NewEventParser(),
-		google:    NewGoogleCalendarProvider(googleConfig, logger),
+		google:    google,
+		sync:      NewSyncEngine(store, google, logger),
 		logger:    logger,
 		config:    config,
 	}
@@ -56,6 +62,11 @@ func NewCalendarSkill(db *gorm.DB, config CalendarSkillConfig, logger *zap.Logge
 	return skill, nil
 }
 
+// SyncEngine returns the engine that syncs connected Google calendars
+func (c *CalendarSkill) SyncEngine() *SyncEngine {
+	return c.sync
+}
+
 // registerTools registers all calendar tools
 func (c *CalendarSkill) registerTools() {
 	tools := []skills.Tool{
@@ -79,6 +90,7 @@ func (c *CalendarSkill) registerTools() {
 		},
 		{
 			Name:        "list_events",
+			ReadOnly:    true,
 			Description: "List your upcoming calendar events",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -103,6 +115,7 @@ func (c *CalendarSkill) registerTools() {
 		},
 		{
 			Name:        "get_event",
+			ReadOnly:    true,
 			Description: "Get details of a specific event",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -145,6 +158,51 @@ func (c *CalendarSkill) registerTools() {
 				"required": []string{"event_id"},
 			},
 		},
+		{
+			Name:        "reschedule_event",
+			Description: "Move an event to a new time. Without new_time, proposes free slots near the current time that fit working hours and leave a gap around other meetings; with apply=true, moves the event to new_time or the best proposal",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"event_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the event to move",
+					},
+					"new_time": map[string]interface{}{
+						"type":        "string",
+						"description": "New start, e.g. 'Friday 3pm', '4pm' (same day) or an ISO time from a proposed slot",
+					},
+					"apply": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Move the event; otherwise only propose",
+					},
+					"force": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Move to new_time even if it overlaps other events",
+					},
+					"within_days": map[string]interface{}{
+						"type":        "integer",
+						"description": "How many days ahead to look for free slots",
+						"default":     DefaultSearchDays,
+					},
+					"time_range": map[string]interface{}{
+						"type":        "string",
+						"description": "Acceptable hours (e.g., '9am-5pm')",
+						"default":     "9am-5pm",
+					},
+					"allow_weekends": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Also propose Saturday and Sunday",
+					},
+					"buffer_minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "Free minutes to keep before and after other events",
+						"default":     10,
+					},
+				},
+				"required": []string{"event_id"},
+			},
+		},
 		{
 			Name:        "delete_event",
 			Description: "Delete an event from your calendar",
@@ -165,6 +223,7 @@ func (c *CalendarSkill) registerTools() {
 		},
 		{
 			Name:        "check_availability",
+			ReadOnly:    true,
 			Description: "Check when you're free or busy",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -184,6 +243,7 @@ func (c *CalendarSkill) registerTools() {
 		},
 		{
 			Name:        "find_free_time",
+			ReadOnly:    true,
 			Description: "Find available time slots for a meeting",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -203,11 +263,25 @@ func (c *CalendarSkill) registerTools() {
 						"description": "Preferred time range (e.g., '9am-5pm')",
 						"default":     "9am-5pm",
 					},
+					"prefer": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"any", "morning", "afternoon", "evening"},
+						"description": "Part of the day to rank first",
+					},
+					"allow_weekends": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Also look on Saturdays and Sundays",
+					},
+					"buffer_minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "Minutes to keep free around other events (default 10)",
+					},
 				},
 			},
 		},
 		{
 			Name:        "get_schedule",
+			ReadOnly:    true,
 			Description: "Get your schedule for a specific day",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -222,6 +296,7 @@ func (c *CalendarSkill) registerTools() {
 		},
 		{
 			Name:        "get_calendar_stats",
+			ReadOnly:    true,
 			Description: "Get statistics about your calendar",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -241,6 +316,47 @@ func (c *CalendarSkill) registerTools() {
 				},
 			},
 		},
+		{
+			Name:        "export_calendar",
+			Description: "Export calendar events to an iCalendar (.ics) file that other calendar apps can import",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"range": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"day", "week", "month", "year", "all"},
+						"default":     "month",
+						"description": "Which events to export, counted from today",
+					},
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "File to write. Without it the iCalendar text is returned.",
+					},
+				},
+			},
+		},
+		{
+			Name:        "import_calendar",
+			Description: "Import events from an iCalendar (.ics) file, e.g. exported from another calendar app. Events imported before are updated, not duplicated.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Path of the .ics file",
+					},
+				},
+				"required": []string{"path"},
+			},
+		},
+		{
+			Name:        "sync_calendar",
+			Description: "Sync with Google Calendar now and show recent sync conflicts. Calendars also sync automatically in the background.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{},
+			},
+		},
 	}
 	
 	for _, tool := range tools {
@@ -261,6 +377,8 @@ func (c *CalendarSkill) handleTool(name string) skills.ToolHandler {
 			return c.handleGetEvent(ctx, args)
 		case "update_event":
 			return c.handleUpdateEvent(ctx, args)
+		case "reschedule_event":
+			return c.handleRescheduleEvent(ctx, args)
 		case "delete_event":
 			return c.handleDeleteEvent(ctx, args)
 		case "check_availability":
@@ -273,6 +391,12 @@ func (c *CalendarSkill) handleTool(name string) skills.ToolHandler {
 			return c.handleGetStats(ctx, args)
 		case "connect_google_calendar":
 			return c.handleConnectGoogle(ctx, args)
+		case "export_calendar":
+			return c.handleExportCalendar(ctx, args)
+		case "import_calendar":
+			return c.handleImportCalendar(ctx, args)
+		case "sync_calendar":
+			return c.handleSyncCalendar(ctx, args)
 		default:
 			return nil, fmt.Errorf("unknown tool: %s", name)
 		}
@@ -288,8 +412,10 @@ func (c *CalendarSkill) handleAddEvent(ctx context.Context, args map[string]inte
 		return nil, fmt.Errorf("description is required")
 	}
 	
-	// Parse the natural language description
-	parseResult, err := c.parser.ParseEvent(description)
+	// Parse the natural language description, reading numeric dates the way
+	// the user writes them
+	loc := locale.FromContext(ctx)
+	parseResult, err := NewEventParser().WithLocale(loc).ParseEvent(description)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse event: %w", err)
 	}
@@ -341,20 +467,40 @@ func (c *CalendarSkill) handleAddEvent(ctx context.Context, args map[string]inte
 		zap.Time("start", event.StartTime),
 	)
 	
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"event_id":    event.ID,
 		"title":       event.Title,
-		"start_time":  event.FormatTimeRange(),
+		"start_time":  event.FormatTimeRangeIn(loc),
 		"location":    event.Location,
 		"created":     true,
 		"confidence":  parseResult.Confidence,
-	}, nil
+	}
+	
+	// Report overlaps with other events and where the new one could go instead
+	if !event.AllDay {
+		conflicts, err := c.conflictsFor(userID, event.ID, event.StartTime, event.EndTime)
+		if err != nil {
+			c.logger.Warn("Failed to check for conflicts", zap.Error(err))
+		} else if len(conflicts) > 0 {
+			opts := DefaultSlotOptions(event.Duration())
+			slots, err := c.suggestSlots(ctx, userID, event.ID, event.StartTime, DefaultSearchDays, opts)
+			if err != nil {
+				c.logger.Warn("Failed to suggest free slots", zap.Error(err))
+			}
+			result["conflicts"] = formatConflicts(conflicts, loc)
+			result["suggested_slots"] = formatSlots(slots, loc)
+			result["message"] = conflictMessage(conflicts, len(slots))
+		}
+	}
+	
+	return result, nil
 }
 
 // handleListEvents lists events
 func (c *CalendarSkill) handleListEvents(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	when, _ := args["when"].(string)
 	search, _ := args["search"].(string)
+	loc := locale.FromContext(ctx)
 	limit := 20
 	if l, ok := args["limit"].(float64); ok {
 		limit = int(l)
@@ -401,7 +547,7 @@ func (c *CalendarSkill) handleListEvents(ctx context.Context, args map[string]in
 		formatted[i] = map[string]interface{}{
 			"id":           e.ID,
 			"title":        e.Title,
-			"time":         e.FormatTimeRange(),
+			"time":         e.FormatTimeRangeIn(loc),
 			"duration":     e.FormatDuration(),
 			"location":     e.Location,
 			"is_today":     e.IsToday(),
@@ -440,7 +586,7 @@ func (c *CalendarSkill) handleGetEvent(ctx context.Context, args map[string]inte
 		"id":          event.ID,
 		"title":       event.Title,
 		"description": event.Description,
-		"time":        event.FormatTimeRange(),
+		"time":        event.FormatTimeRangeIn(locale.FromContext(ctx)),
 		"duration":    event.FormatDuration(),
 		"location":    event.Location,
 		"status":      event.Status,
@@ -517,6 +663,7 @@ func (c *CalendarSkill) handleCheckAvailability(ctx context.Context, args map[st
 	}
 	
 	userID := c.getUserID(ctx)
+	loc := locale.FromContext(ctx)
 	
 	// Get events for the day
 	events, err := c.store.GetEventsForDay(userID, date)
@@ -528,26 +675,168 @@ func (c *CalendarSkill) handleCheckAvailability(ctx context.Context, args map[st
 	busySlots := []map[string]interface{}{}
 	for _, e := range events {
 		busySlots = append(busySlots, map[string]interface{}{
-			"start": e.StartTime.Format("3:04 PM"),
-			"end":   e.EndTime.Format("3:04 PM"),
+			"start": loc.Time(e.StartTime),
+			"end":   loc.Time(e.EndTime),
 			"title": e.Title,
 		})
 	}
 	
 	return map[string]interface{}{
-		"date":       date.Format("Monday, Jan 2"),
+		"date":       loc.WeekdayDate(date),
 		"busy_slots": busySlots,
 		"events_count": len(events),
 		"duration_requested": durationStr,
 	}, nil
 }
 
-// handleFindFreeTime finds free time slots
+// handleFindFreeTime finds free time slots, best first
 func (c *CalendarSkill) handleFindFreeTime(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-	// Simplified implementation
-	return map[string]interface{}{
-		"message": "Free time finder - suggest checking your calendar for gaps between events",
-	}, nil
+	durationStr, _ := args["duration"].(string)
+	duration, err := parseMeetingDuration(durationStr)
+	if err != nil {
+		return nil, err
+	}
+	
+	prefer, _ := args["prefer"].(string)
+	prefer = strings.ToLower(prefer)
+	switch prefer {
+	case "", "any":
+		prefer = ""
+	case PreferMorning, PreferAfternoon, PreferEvening:
+	default:
+		return nil, fmt.Errorf("invalid prefer %q: use morning, afternoon or evening", prefer)
+	}
+	
+	opts, days, err := slotOptionsFromArgs(args, "days", duration, 3)
+	if err != nil {
+		return nil, err
+	}
+	opts.Limit = 0
+	
+	userID := c.getUserID(ctx)
+	loc := locale.FromContext(ctx)
+	from, until := slotWindow(time.Time{}, days, opts)
+	busy, sources, err := c.busyBetween(ctx, userID, "", from.Add(-opts.Buffer), until.Add(opts.Buffer))
+	if err != nil {
+		return nil, err
+	}
+	free := FindFreeSlots(busy, from, until, time.Time{}, opts)
+	slots := RankFreeSlots(free, busy, time.Now(), prefer, 10)
+	
+	result := map[string]interface{}{
+		"duration":     duration.String(),
+		"days":         days,
+		"slots":        formatSlots(slots, loc),
+		"count":        len(slots),
+		"busy_sources": sources,
+	}
+	if len(slots) == 0 {
+		result["message"] = fmt.Sprintf("No free slot found in the next %d days. Try a wider time_range, allow_weekends or more days.", days)
+	}
+	return result, nil
+}
+
+// handleRescheduleEvent proposes or applies a new time for an event
+func (c *CalendarSkill) handleRescheduleEvent(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	eventID, _ := args["event_id"].(string)
+	newTime, _ := args["new_time"].(string)
+	apply, _ := args["apply"].(bool)
+	force, _ := args["force"].(bool)
+	
+	if eventID == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+	
+	userID := c.getUserID(ctx)
+	loc := locale.FromContext(ctx)
+	
+	event, err := c.store.GetEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil || event.UserID != userID || event.Status == EventStatusCancelled {
+		return nil, fmt.Errorf("event not found")
+	}
+	if event.AllDay {
+		return nil, fmt.Errorf("all-day events have no time to move; update the date instead")
+	}
+	
+	opts, days, err := slotOptionsFromArgs(args, "within_days", event.Duration(), DefaultSearchDays)
+	if err != nil {
+		return nil, err
+	}
+	
+	result := map[string]interface{}{
+		"event_id":     event.ID,
+		"title":        event.Title,
+		"current_time": event.FormatTimeRangeIn(loc),
+		"applied":      false,
+	}
+	
+	var start time.Time
+	if newTime != "" {
+		start, err = parseNewStart(newTime, event.StartTime, loc)
+		if err != nil {
+			return nil, err
+		}
+		if start.Before(time.Now()) {
+			return nil, fmt.Errorf("%s is in the past", loc.DateTime(start))
+		}
+		
+		conflicts, err := c.conflictsFor(userID, event.ID, start, start.Add(event.Duration()))
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) > 0 && !force {
+			// Offer the nearest free times to the one asked for
+			slots, err := c.suggestSlots(ctx, userID, event.ID, start, days, opts)
+			if err != nil {
+				return nil, err
+			}
+			result["requested_time"] = timeRange(start, start.Add(event.Duration()), loc)
+			result["conflicts"] = formatConflicts(conflicts, loc)
+			result["suggested_slots"] = formatSlots(slots, loc)
+			result["message"] = conflictMessage(conflicts, len(slots)) + " Pass force=true to move it anyway."
+			return result, nil
+		}
+	} else {
+		slots, err := c.suggestSlots(ctx, userID, event.ID, event.StartTime, days, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(slots) == 0 {
+			result["message"] = fmt.Sprintf("No free slot found in the next %d days. Try a wider time_range, allow_weekends or more within_days.", days)
+			return result, nil
+		}
+		if !apply {
+			result["suggested_slots"] = formatSlots(slots, loc)
+			result["message"] = "Pick a slot and call reschedule_event again with its start as new_time and apply=true."
+			return result, nil
+		}
+		start = slots[0].Start
+	}
+	
+	end := start.Add(event.Duration())
+	if !apply {
+		result["proposed_time"] = timeRange(start, end, loc)
+		result["message"] = "This time is free. Call again with apply=true to move the event."
+		return result, nil
+	}
+	
+	event.StartTime = start
+	event.EndTime = end
+	if err := c.store.UpdateEvent(event); err != nil {
+		return nil, fmt.Errorf("failed to reschedule event: %w", err)
+	}
+	
+	c.logger.Info("Event rescheduled",
+		zap.String("event_id", event.ID),
+		zap.Time("start", event.StartTime),
+	)
+	
+	result["new_time"] = event.FormatTimeRangeIn(loc)
+	result["applied"] = true
+	return result, nil
 }
 
 // handleGetSchedule gets schedule for a day
@@ -560,6 +849,7 @@ func (c *CalendarSkill) handleGetSchedule(ctx context.Context, args map[string]i
 	}
 	
 	userID := c.getUserID(ctx)
+	loc := locale.FromContext(ctx)
 	
 	events, err := c.store.GetEventsForDay(userID, date)
 	if err != nil {
@@ -570,14 +860,14 @@ func (c *CalendarSkill) handleGetSchedule(ctx context.Context, args map[string]i
 	schedule := []map[string]interface{}{}
 	for _, e := range events {
 		schedule = append(schedule, map[string]interface{}{
-			"time":    fmt.Sprintf("%s - %s", e.StartTime.Format("3:04 PM"), e.EndTime.Format("3:04 PM")),
+			"time":    fmt.Sprintf("%s - %s", loc.Time(e.StartTime), loc.Time(e.EndTime)),
 			"title":   e.Title,
 			"location": e.Location,
 		})
 	}
 	
 	return map[string]interface{}{
-		"date":     date.Format("Monday, Jan 2, 2006"),
+		"date":     loc.WeekdayDateYear(date),
 		"schedule": schedule,
 		"total":    len(events),
 	}, nil
@@ -587,7 +877,7 @@ func (c *CalendarSkill) handleGetSchedule(ctx context.Context, args map[string]i
 func (c *CalendarSkill) handleGetStats(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	userID := c.getUserID(ctx)
 	
-	stats, err := c.store.GetStats(userID)
+	stats, err := c.store.GetStatsForWeek(userID, locale.FromContext(ctx).FirstDayOfWeek)
 	if err != nil {
 		return nil, err
 	}
@@ -644,8 +934,260 @@ func (c *CalendarSkill) handleConnectGoogle(ctx context.Context, args map[string
 	}, nil
 }
 
+// handleExportCalendar writes the user's events as iCalendar
+func (c *CalendarSkill) handleExportCalendar(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	rng, _ := args["range"].(string)
+	path, _ := args["path"].(string)
+	
+	from, to, err := ExportRange(rng, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	events, err := c.store.GetEventsForExport(c.getUserID(ctx), from, to)
+	if err != nil {
+		return nil, err
+	}
+	
+	var buf bytes.Buffer
+	if err := ExportICS(&buf, "Myrai", events); err != nil {
+		return nil, err
+	}
+	
+	if path == "" {
+		return map[string]interface{}{
+			"events": len(events),
+			"ics":    buf.String(),
+		}, nil
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return map[string]interface{}{
+		"events": len(events),
+		"path":   path,
+	}, nil
+}
+
+// handleImportCalendar adds the events of an iCalendar file
+func (c *CalendarSkill) handleImportCalendar(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	path, _ := args["path"].(string)
+	if path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	
+	return ImportICS(c.store, c.getUserID(ctx), f)
+}
+
+// handleSyncCalendar syncs the user's Google Calendar now
+func (c *CalendarSkill) handleSyncCalendar(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := c.getUserID(ctx)
+	
+	result, err := c.sync.SyncUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("calendar sync failed: %w", err)
+	}
+	
+	conflicts, err := c.store.GetSyncConflicts(userID, 5)
+	if err != nil {
+		return nil, err
+	}
+	recent := make([]map[string]interface{}, len(conflicts))
+	for i, conflict := range conflicts {
+		recent[i] = map[string]interface{}{
+			"event_id": conflict.EventID,
+			"title":    conflict.Title,
+			"kept":     conflict.Winner,
+			"at":       conflict.CreatedAt.Format(time.RFC3339),
+		}
+	}
+	
+	return map[string]interface{}{
+		"added":            result.Added,
+		"updated":          result.Updated,
+		"deleted":          result.Deleted,
+		"pushed":           result.Pushed,
+		"conflicts":        result.Conflicts,
+		"errors":           result.Errors,
+		"recent_conflicts": recent,
+	}, nil
+}
+
 // Helper methods
 
+// conflictsFor returns the timed events overlapping start to end, other than
+// excludeID
+func (c *CalendarSkill) conflictsFor(userID, excludeID string, start, end time.Time) ([]CalendarEvent, error) {
+	events, err := c.store.FindConflicts(userID, start, end, excludeID)
+	if err != nil {
+		return nil, err
+	}
+	return busyEvents(events), nil
+}
+
+// suggestSlots finds free slots over the next days, starting no earlier
+// than preferred's day and ranked by closeness to it when it is set
+func (c *CalendarSkill) suggestSlots(ctx context.Context, userID, excludeID string, preferred time.Time, days int, opts SlotOptions) ([]ScheduleSuggestion, error) {
+	from, until := slotWindow(preferred, days, opts)
+	busy, _, err := c.busyBetween(ctx, userID, excludeID, from.Add(-opts.Buffer), until.Add(opts.Buffer))
+	if err != nil {
+		return nil, err
+	}
+	return FindFreeSlots(busy, from, until, preferred, opts), nil
+}
+
+// slotWindow is the span free slots are searched in: from the notice period
+// or preferred's day, whichever is later, for days days
+func slotWindow(preferred time.Time, days int, opts SlotOptions) (from, until time.Time) {
+	from = time.Now().Add(opts.Notice)
+	if day := startOfDay(preferred); day.After(from) {
+		from = day
+	}
+	return from, startOfDay(from).AddDate(0, 0, days)
+}
+
+// busyBetween merges the user's timed events with Google Calendar's
+// free/busy when it is connected. sources names where busy times came from.
+// Google's view is best effort: when it fails only local events are used.
+func (c *CalendarSkill) busyBetween(ctx context.Context, userID, excludeID string, from, until time.Time) (busy []CalendarEvent, sources []string, err error) {
+	events, err := c.store.FindConflicts(userID, from, until, excludeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	busy = busyEvents(events)
+	sources = []string{"calendar"}
+	
+	var excluded *CalendarEvent
+	if excludeID != "" {
+		excluded, _ = c.store.GetEvent(excludeID)
+	}
+	err = c.sync.WithGoogleClient(ctx, userID, func(client *http.Client) error {
+		slots, err := c.google.GetFreeBusy(ctx, client, "primary", from, until)
+		if err != nil {
+			return err
+		}
+		for _, slot := range slots {
+			// The event being moved is busy in Google too
+			if excluded != nil && !slot.Start.Before(excluded.StartTime) && !slot.End.After(excluded.EndTime) {
+				continue
+			}
+			busy = append(busy, CalendarEvent{Title: "Busy (Google Calendar)", StartTime: slot.Start, EndTime: slot.End})
+		}
+		sources = append(sources, "google")
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrNotConnected) {
+		c.logger.Warn("Google free/busy unavailable", zap.String("user_id", userID), zap.Error(err))
+	}
+	return MergeBusy(busy), sources, nil
+}
+
+// slotOptionsFromArgs reads time_range, allow_weekends, buffer_minutes and
+// the number of days to search from tool arguments
+func slotOptionsFromArgs(args map[string]interface{}, daysKey string, duration time.Duration, defaultDays int) (SlotOptions, int, error) {
+	opts := DefaultSlotOptions(duration)
+	
+	if timeRange, _ := args["time_range"].(string); timeRange != "" {
+		start, end, err := parseTimeRange(timeRange)
+		if err != nil {
+			return opts, 0, err
+		}
+		opts.DayStart, opts.DayEnd = start, end
+	}
+	if weekends, ok := args["allow_weekends"].(bool); ok {
+		opts.Weekends = weekends
+	}
+	if buffer, ok := args["buffer_minutes"].(float64); ok && buffer >= 0 {
+		opts.Buffer = time.Duration(buffer) * time.Minute
+	}
+	
+	days := defaultDays
+	if d, ok := args[daysKey].(float64); ok && d > 0 {
+		days = int(d)
+	}
+	if days > MaxSearchDays {
+		days = MaxSearchDays
+	}
+	return opts, days, nil
+}
+
+// parseNewStart reads a new start time. A day alone keeps the current time
+// of day, and a time alone keeps the current day.
+func parseNewStart(s string, current time.Time, loc locale.Locale) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.In(current.Location()), nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, s, current.Location()); err == nil {
+			return t, nil
+		}
+	}
+	
+	parser := NewEventParser().WithLocale(loc)
+	date := parser.extractDate(s)
+	clock, _ := parser.extractTime(s)
+	if date.IsZero() && clock.IsZero() {
+		return time.Time{}, fmt.Errorf("could not read a time from %q", s)
+	}
+	if date.IsZero() {
+		date = current
+	}
+	hour, minute := current.Hour(), current.Minute()
+	if !clock.IsZero() {
+		hour, minute = clock.Hour(), clock.Minute()
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, current.Location()), nil
+}
+
+func formatConflicts(events []CalendarEvent, loc locale.Locale) []map[string]interface{} {
+	conflicts := make([]map[string]interface{}, 0, len(events))
+	for _, e := range events {
+		conflicts = append(conflicts, map[string]interface{}{
+			"event_id": e.ID,
+			"title":    e.Title,
+			"time":     e.FormatTimeRangeIn(loc),
+		})
+	}
+	return conflicts
+}
+
+func formatSlots(slots []ScheduleSuggestion, loc locale.Locale) []map[string]interface{} {
+	formatted := make([]map[string]interface{}, 0, len(slots))
+	for _, slot := range slots {
+		entry := map[string]interface{}{
+			"start": slot.Start.Format(time.RFC3339),
+			"time":  timeRange(slot.Start, slot.End, loc),
+		}
+		if slot.Reason != "" {
+			entry["reason"] = slot.Reason
+		}
+		formatted = append(formatted, entry)
+	}
+	return formatted
+}
+
+func conflictMessage(conflicts []CalendarEvent, slots int) string {
+	titles := make([]string, len(conflicts))
+	for i, e := range conflicts {
+		titles[i] = fmt.Sprintf("%q", e.Title)
+	}
+	msg := "Overlaps with " + strings.Join(titles, ", ") + "."
+	if slots > 0 {
+		msg += " Free alternatives are listed; reschedule_event can move it."
+	}
+	return msg
+}
+
+func timeRange(start, end time.Time, loc locale.Locale) string {
+	return (&CalendarEvent{StartTime: start, EndTime: end}).FormatTimeRangeIn(loc)
+}
+
 func (c *CalendarSkill) getUserID(ctx context.Context) string {
 	if userID, ok := ctx.Value("user_id").(string); ok {
 		return userID
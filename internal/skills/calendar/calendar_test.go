@@ -2,11 +2,15 @@ package calendar
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/locale"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -218,6 +222,34 @@ func TestEventParser_ExtractDate(t *testing.T) {
 	}
 }
 
+func TestEventParser_ExtractDate_Locale(t *testing.T) {
+	ref := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	gb, _ := locale.ForLanguage("en-GB")
+	
+	date := NewEventParser().WithReference(ref).extractDate("dentist on 4/3")
+	assert.Equal(t, time.April, date.Month())
+	assert.Equal(t, 3, date.Day())
+	
+	date = NewEventParser().WithReference(ref).WithLocale(gb).extractDate("dentist on 4/3")
+	assert.Equal(t, time.March, date.Month())
+	assert.Equal(t, 4, date.Day())
+	
+	date = NewEventParser().WithReference(ref).WithLocale(gb).extractDate("party on 21st March")
+	assert.Equal(t, time.March, date.Month())
+	assert.Equal(t, 21, date.Day())
+}
+
+func TestCalendarEvent_FormatTimeRangeIn(t *testing.T) {
+	event := &CalendarEvent{
+		StartTime: time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2024, 3, 4, 15, 30, 0, 0, time.UTC),
+	}
+	gb, _ := locale.ForLanguage("en-GB")
+	
+	assert.Equal(t, "Mon, Mar 4 2:00 PM - 3:30 PM", event.FormatTimeRange())
+	assert.Equal(t, "Mon, 4 Mar 14:00 - 15:30", event.FormatTimeRangeIn(gb))
+}
+
 func TestEventParser_ExtractTime(t *testing.T) {
 	parser := NewEventParser()
 	
@@ -271,6 +303,9 @@ func TestEventParser_ExtractRecurrence(t *testing.T) {
 		{"every week", "RRULE:FREQ=WEEKLY"},
 		{"weekly", "RRULE:FREQ=WEEKLY"},
 		{"every Monday", "RRULE:FREQ=WEEKLY;BYDAY=MO"},
+		{"Planning every 2nd Tuesday at 10am", "RRULE:FREQ=MONTHLY;BYDAY=2TU"},
+		{"Payroll on the last weekday of the month", "RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1"},
+		{"Lunch with Sam on Friday", ""},
 	}
 	
 	for _, test := range tests {
@@ -529,3 +564,278 @@ func TestCalendarEvent_FormatDuration(t *testing.T) {
 		assert.Equal(t, test.expected, event.FormatDuration())
 	}
 }
+
+// Scheduling Tests
+
+func TestFindFreeSlots(t *testing.T) {
+	// Tuesday 4 March 2025
+	day := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+	busy := []CalendarEvent{
+		{Title: "Standup", StartTime: day.Add(9 * time.Hour), EndTime: day.Add(10 * time.Hour)},
+		{Title: "Review", StartTime: day.Add(11 * time.Hour), EndTime: day.Add(16 * time.Hour)},
+	}
+	opts := DefaultSlotOptions(time.Hour)
+	opts.Limit = 0
+	
+	slots := FindFreeSlots(busy, day, day.AddDate(0, 0, 1), time.Time{}, opts)
+	require.Len(t, slots, 0, "with 10-minute buffers no free hour is left")
+	
+	opts.Buffer = 0
+	slots = FindFreeSlots(busy, day, day.AddDate(0, 0, 1), time.Time{}, opts)
+	require.Len(t, slots, 2)
+	assert.Equal(t, day.Add(10*time.Hour), slots[0].Start)
+	assert.Equal(t, day.Add(16*time.Hour), slots[1].Start)
+	
+	// Preferred time ranks the same day first, then the same time later on
+	opts.Limit = 3
+	preferred := day.Add(14 * time.Hour)
+	slots = FindFreeSlots(busy, day, day.AddDate(0, 0, 7), preferred, opts)
+	require.Len(t, slots, 3)
+	assert.Equal(t, "Same day", slots[0].Reason)
+	assert.Equal(t, day.Add(16*time.Hour), slots[0].Start)
+	assert.Equal(t, day.Add(10*time.Hour), slots[1].Start)
+	assert.Equal(t, day.AddDate(0, 0, 1).Add(14*time.Hour), slots[2].Start)
+	assert.Equal(t, "Same time, different day", slots[2].Reason)
+	
+	// Weekends are skipped unless allowed
+	saturday := day.AddDate(0, 0, 4)
+	assert.Empty(t, FindFreeSlots(nil, saturday, saturday.AddDate(0, 0, 2), time.Time{}, opts))
+	opts.Weekends = true
+	assert.NotEmpty(t, FindFreeSlots(nil, saturday, saturday.AddDate(0, 0, 2), time.Time{}, opts))
+}
+
+func TestMergeBusy(t *testing.T) {
+	day := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+	merged := MergeBusy([]CalendarEvent{
+		{Title: "Review", StartTime: day.Add(11 * time.Hour), EndTime: day.Add(12 * time.Hour)},
+		{Title: "Standup", StartTime: day.Add(9 * time.Hour), EndTime: day.Add(10 * time.Hour)},
+		{Title: "Busy", StartTime: day.Add(9*time.Hour + 30*time.Minute), EndTime: day.Add(11 * time.Hour)},
+		{Title: "Lunch", StartTime: day.Add(13 * time.Hour), EndTime: day.Add(14 * time.Hour)},
+		{Title: "Empty", StartTime: day.Add(15 * time.Hour), EndTime: day.Add(15 * time.Hour)},
+	})
+	
+	require.Len(t, merged, 2)
+	assert.Equal(t, day.Add(9*time.Hour), merged[0].StartTime)
+	assert.Equal(t, day.Add(12*time.Hour), merged[0].EndTime, "overlapping and touching periods join")
+	assert.Equal(t, day.Add(13*time.Hour), merged[1].StartTime)
+}
+
+func TestRankFreeSlots(t *testing.T) {
+	day := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+	busy := MergeBusy([]CalendarEvent{
+		{Title: "Standup", StartTime: day.Add(9 * time.Hour), EndTime: day.Add(10 * time.Hour)},
+	})
+	opts := DefaultSlotOptions(time.Hour)
+	opts.Buffer = 0
+	opts.Limit = 0
+	free := FindFreeSlots(busy, day, day.AddDate(0, 0, 2), time.Time{}, opts)
+	now := day.Add(8 * time.Hour)
+	
+	slots := RankFreeSlots(free, busy, now, "", 4)
+	require.Len(t, slots, 4)
+	for i, a := range slots {
+		for _, b := range slots[i+1:] {
+			assert.False(t, a.Start.Before(b.End) && a.End.After(b.Start), "slots overlap")
+		}
+		if i > 0 {
+			assert.LessOrEqual(t, a.Score, slots[i-1].Score)
+		}
+	}
+	
+	slots = RankFreeSlots(free, busy, now, PreferAfternoon, 3)
+	require.Len(t, slots, 3)
+	for _, slot := range slots {
+		assert.Equal(t, PreferAfternoon, partOfDay(slot.Start))
+		assert.Contains(t, slot.Reason, "in the afternoon")
+	}
+	assert.True(t, sameDay(slots[0].Start, day), "sooner afternoon slots rank first")
+	
+	assert.Empty(t, RankFreeSlots(nil, busy, now, "", 5))
+}
+
+func TestCalendarSkill_FindFreeTimeUsesGoogleFreeBusy(t *testing.T) {
+	skill, _ := setupCalendarSkill(t)
+	tomorrow := startOfDay(time.Now()).AddDate(0, 0, 1)
+	
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/freeBusy", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"calendars": map[string]interface{}{
+				"primary": map[string]interface{}{
+					"busy": []map[string]string{{
+						"start": tomorrow.Add(-24 * time.Hour).Format(time.RFC3339),
+						"end":   tomorrow.AddDate(0, 0, 7).Format(time.RFC3339),
+					}},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+	skill.google.apiBase = server.URL
+	
+	ctx := context.Background()
+	result, err := skill.handleFindFreeTime(ctx, map[string]interface{}{"duration": "1 hour", "allow_weekends": true})
+	require.NoError(t, err)
+	assert.NotZero(t, result.(map[string]interface{})["count"], "not connected, so only local events count")
+	
+	require.NoError(t, skill.store.SaveCredentials(&CalendarCredentials{
+		UserID:      skill.getUserID(ctx),
+		Provider:    "google",
+		AccessToken: "token",
+		TokenExpiry: time.Now().Add(time.Hour),
+		IsActive:    true,
+	}))
+	result, err = skill.handleFindFreeTime(ctx, map[string]interface{}{"duration": "1 hour", "allow_weekends": true, "prefer": "morning"})
+	require.NoError(t, err)
+	res := result.(map[string]interface{})
+	assert.Equal(t, []string{"calendar", "google"}, res["busy_sources"])
+	assert.Zero(t, res["count"], "Google has the whole week blocked")
+	
+	_, err = skill.handleFindFreeTime(ctx, map[string]interface{}{"prefer": "night"})
+	assert.Error(t, err)
+}
+
+func TestParseTimeRange(t *testing.T) {
+	start, end, err := parseTimeRange("9am-5:30pm")
+	require.NoError(t, err)
+	assert.Equal(t, 9*time.Hour, start)
+	assert.Equal(t, 17*time.Hour+30*time.Minute, end)
+	
+	start, end, err = parseTimeRange("08:00 - 12:00")
+	require.NoError(t, err)
+	assert.Equal(t, 8*time.Hour, start)
+	assert.Equal(t, 12*time.Hour, end)
+	
+	_, _, err = parseTimeRange("5pm-9am")
+	assert.Error(t, err)
+	_, _, err = parseTimeRange("morning")
+	assert.Error(t, err)
+}
+
+func TestParseMeetingDuration(t *testing.T) {
+	d, err := parseMeetingDuration("30 minutes")
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, d)
+	
+	d, err = parseMeetingDuration("1.5 hours")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, d)
+	
+	_, err = parseMeetingDuration("a while")
+	assert.Error(t, err)
+}
+
+func TestCalendarSkill_AddEventReportsConflicts(t *testing.T) {
+	skill, _ := setupCalendarSkill(t)
+	ctx := context.WithValue(context.Background(), "user_id", "user1")
+	
+	tomorrow := startOfDay(time.Now().AddDate(0, 0, 1))
+	require.NoError(t, skill.store.CreateEvent(&CalendarEvent{
+		UserID:    "user1",
+		Title:     "Dentist",
+		StartTime: tomorrow.Add(10 * time.Hour),
+		EndTime:   tomorrow.Add(11 * time.Hour),
+		Status:    EventStatusConfirmed,
+	}))
+	
+	result, err := skill.handleAddEvent(ctx, map[string]interface{}{
+		"description": "Call with Sam tomorrow at 10:30am",
+	})
+	require.NoError(t, err)
+	
+	resultMap := result.(map[string]interface{})
+	assert.Equal(t, true, resultMap["created"])
+	conflicts := resultMap["conflicts"].([]map[string]interface{})
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "Dentist", conflicts[0]["title"])
+	assert.NotEmpty(t, resultMap["suggested_slots"])
+	assert.Contains(t, resultMap["message"], `"Dentist"`)
+	
+	// No conflict, no report
+	result, err = skill.handleAddEvent(ctx, map[string]interface{}{
+		"description": "Gym tomorrow at 6pm",
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, result.(map[string]interface{}), "conflicts")
+}
+
+func TestCalendarSkill_RescheduleEvent(t *testing.T) {
+	skill, _ := setupCalendarSkill(t)
+	ctx := context.WithValue(context.Background(), "user_id", "user1")
+	
+	// A weekday at least two days out, so working-hours slots exist
+	day := startOfDay(time.Now().AddDate(0, 0, 2))
+	for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		day = day.AddDate(0, 0, 1)
+	}
+	planning := &CalendarEvent{
+		UserID:    "user1",
+		Title:     "Planning",
+		StartTime: day.Add(10 * time.Hour),
+		EndTime:   day.Add(11 * time.Hour),
+		Status:    EventStatusConfirmed,
+	}
+	require.NoError(t, skill.store.CreateEvent(planning))
+	review := &CalendarEvent{
+		UserID:    "user1",
+		Title:     "Review",
+		StartTime: day.Add(14 * time.Hour),
+		EndTime:   day.Add(15 * time.Hour),
+		Status:    EventStatusConfirmed,
+	}
+	require.NoError(t, skill.store.CreateEvent(review))
+	
+	// Proposals only
+	result, err := skill.handleRescheduleEvent(ctx, map[string]interface{}{"event_id": planning.ID})
+	require.NoError(t, err)
+	resultMap := result.(map[string]interface{})
+	assert.Equal(t, false, resultMap["applied"])
+	slots := resultMap["suggested_slots"].([]map[string]interface{})
+	require.NotEmpty(t, slots)
+	assert.Equal(t, "Same day", slots[0]["reason"])
+	
+	// A taken time is refused with alternatives
+	result, err = skill.handleRescheduleEvent(ctx, map[string]interface{}{
+		"event_id": planning.ID,
+		"new_time": day.Add(14*time.Hour + 30*time.Minute).Format(time.RFC3339),
+		"apply":    true,
+	})
+	require.NoError(t, err)
+	resultMap = result.(map[string]interface{})
+	assert.Equal(t, false, resultMap["applied"])
+	assert.Len(t, resultMap["conflicts"], 1)
+	
+	// A free time is applied
+	result, err = skill.handleRescheduleEvent(ctx, map[string]interface{}{
+		"event_id": planning.ID,
+		"new_time": slots[0]["start"],
+		"apply":    true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, true, result.(map[string]interface{})["applied"])
+	
+	moved, err := skill.store.GetEvent(planning.ID)
+	require.NoError(t, err)
+	assert.Equal(t, slots[0]["start"], moved.StartTime.Format(time.RFC3339))
+	assert.Equal(t, time.Hour, moved.Duration())
+	
+	// Other users' events can't be moved
+	other := context.WithValue(context.Background(), "user_id", "user2")
+	_, err = skill.handleRescheduleEvent(other, map[string]interface{}{"event_id": planning.ID})
+	assert.Error(t, err)
+}
+
+func TestParseNewStart(t *testing.T) {
+	current := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+	
+	start, err := parseNewStart("4pm", current, locale.Default())
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 4, 16, 0, 0, 0, time.UTC), start)
+	
+	start, err = parseNewStart("2025-03-06T09:30", current, locale.Default())
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 6, 9, 30, 0, 0, time.UTC), start)
+	
+	_, err = parseNewStart("whenever", current, locale.Default())
+	assert.Error(t, err)
+}
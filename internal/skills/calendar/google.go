@@ -3,6 +3,7 @@ package calendar
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/recurrence"
 	"go.uber.org/zap"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -57,11 +59,23 @@ type googleEvent struct {
 	Updated string `json:"updated,omitempty"`
 }
 
+// googleAPIBase is the Google Calendar API root
+const googleAPIBase = "https://www.googleapis.com/calendar/v3"
+
+var (
+	// ErrSyncTokenExpired means Google no longer accepts a sync token and a
+	// full sync is needed
+	ErrSyncTokenExpired = errors.New("sync token expired")
+	// ErrRemoteEventGone means the event no longer exists in Google Calendar
+	ErrRemoteEventGone = errors.New("event no longer exists in Google Calendar")
+)
+
 // GoogleCalendarProvider implements Google Calendar API integration
 type GoogleCalendarProvider struct {
 	config     *oauth2.Config
 	httpClient *http.Client
 	logger     *zap.Logger
+	apiBase    string
 }
 
 // GoogleCalendarConfig contains Google Calendar OAuth configuration
@@ -94,8 +108,9 @@ func NewGoogleCalendarProvider(config GoogleCalendarConfig, logger *zap.Logger)
 	}
 	
 	return &GoogleCalendarProvider{
-		config: oauthConfig,
-		logger: logger,
+		config:  oauthConfig,
+		logger:  logger,
+		apiBase: googleAPIBase,
 	}
 }
 
@@ -135,7 +150,7 @@ func (g *GoogleCalendarProvider) RefreshToken(ctx context.Context, refreshToken
 
 // ListCalendars lists the user's calendars
 func (g *GoogleCalendarProvider) ListCalendars(ctx context.Context, client *http.Client) ([]Calendar, error) {
-	apiURL := "https://www.googleapis.com/calendar/v3/users/me/calendarList"
+	apiURL := g.apiBase + "/users/me/calendarList"
 	
 	resp, err := client.Get(apiURL)
 	if err != nil {
@@ -184,7 +199,7 @@ func (g *GoogleCalendarProvider) ListCalendars(ctx context.Context, client *http
 
 // ListEvents lists events from a calendar
 func (g *GoogleCalendarProvider) ListEvents(ctx context.Context, client *http.Client, calendarID string, timeMin, timeMax time.Time) ([]CalendarEvent, error) {
-	baseURL := "https://www.googleapis.com/calendar/v3/calendars/"
+	baseURL := g.apiBase + "/calendars/"
 	if calendarID == "primary" {
 		calendarID = "primary"
 	}
@@ -236,7 +251,7 @@ func (g *GoogleCalendarProvider) ListEvents(ctx context.Context, client *http.Cl
 
 // CreateEvent creates a new event in Google Calendar
 func (g *GoogleCalendarProvider) CreateEvent(ctx context.Context, client *http.Client, calendarID string, event *CalendarEvent) (*CalendarEvent, error) {
-	baseURL := "https://www.googleapis.com/calendar/v3/calendars/"
+	baseURL := g.apiBase + "/calendars/"
 	if strings.Contains(calendarID, "@") {
 		calendarID = neturl.PathEscape(calendarID)
 	}
@@ -273,7 +288,7 @@ func (g *GoogleCalendarProvider) CreateEvent(ctx context.Context, client *http.C
 
 // UpdateEvent updates an existing event
 func (g *GoogleCalendarProvider) UpdateEvent(ctx context.Context, client *http.Client, calendarID, eventID string, event *CalendarEvent) (*CalendarEvent, error) {
-	baseURL := "https://www.googleapis.com/calendar/v3/calendars/"
+	baseURL := g.apiBase + "/calendars/"
 	if strings.Contains(calendarID, "@") {
 		calendarID = neturl.PathEscape(calendarID)
 	}
@@ -299,6 +314,9 @@ func (g *GoogleCalendarProvider) UpdateEvent(ctx context.Context, client *http.C
 	}
 	defer resp.Body.Close()
 	
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil, fmt.Errorf("update event failed: %w", ErrRemoteEventGone)
+	}
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("update event failed: %s - %s", resp.Status, string(respBody))
@@ -315,7 +333,7 @@ func (g *GoogleCalendarProvider) UpdateEvent(ctx context.Context, client *http.C
 
 // DeleteEvent deletes an event
 func (g *GoogleCalendarProvider) DeleteEvent(ctx context.Context, client *http.Client, calendarID, eventID string) error {
-	baseURL := "https://www.googleapis.com/calendar/v3/calendars/"
+	baseURL := g.apiBase + "/calendars/"
 	if strings.Contains(calendarID, "@") {
 		calendarID = neturl.PathEscape(calendarID)
 	}
@@ -333,6 +351,9 @@ func (g *GoogleCalendarProvider) DeleteEvent(ctx context.Context, client *http.C
 	}
 	defer resp.Body.Close()
 	
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return fmt.Errorf("delete event failed: %w", ErrRemoteEventGone)
+	}
 	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("delete event failed: %s", resp.Status)
 	}
@@ -342,7 +363,7 @@ func (g *GoogleCalendarProvider) DeleteEvent(ctx context.Context, client *http.C
 
 // GetFreeBusy gets free/busy information
 func (g *GoogleCalendarProvider) GetFreeBusy(ctx context.Context, client *http.Client, calendarID string, start, end time.Time) ([]FreeBusySlot, error) {
-	apiURL := "https://www.googleapis.com/calendar/v3/freeBusy"
+	apiURL := g.apiBase + "/freeBusy"
 	
 	requestBody := map[string]interface{}{
 		"timeMin": start.Format(time.RFC3339),
@@ -528,7 +549,7 @@ func (g *GoogleCalendarProvider) convertToGoogleEvent(event *CalendarEvent) goog
 	
 	// Recurrence
 	if event.RecurrenceRule != "" {
-		ge.Recurrence = []string{event.RecurrenceRule}
+		ge.Recurrence = []string{recurrence.Portable(event.RecurrenceRule)}
 	}
 	
 	// Attendees
@@ -556,69 +577,84 @@ func (g *GoogleCalendarProvider) convertToGoogleEvent(event *CalendarEvent) goog
 	return ge
 }
 
-// Sync performs a sync with Google Calendar
-func (g *GoogleCalendarProvider) Sync(ctx context.Context, client *http.Client, calendarID, syncToken string) (*SyncResult, error) {
-	baseURL := "https://www.googleapis.com/calendar/v3/calendars/"
-	if strings.Contains(calendarID, "@") {
-		calendarID = neturl.PathEscape(calendarID)
-	}
-	
-	apiURL := fmt.Sprintf("%s%s/events", baseURL, calendarID)
-	
-	params := neturl.Values{}
-	params.Set("singleEvents", "true")
-	
-	if syncToken != "" {
-		params.Set("syncToken", syncToken)
-	} else {
-		// Initial sync - get events from last 30 days
-		params.Set("timeMin", time.Now().AddDate(0, 0, -30).Format(time.RFC3339))
-	}
-	
-	fullURL := apiURL + "?" + params.Encode()
-	
-	resp, err := client.Get(fullURL)
-	if err != nil {
-		return nil, fmt.Errorf("sync failed: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	// Handle sync token expiration
-	if resp.StatusCode == http.StatusGone {
-		// Token expired, do full sync
-		return g.Sync(ctx, client, calendarID, "")
-	}
-	
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("sync failed: %s - %s", resp.Status, string(body))
-	}
-	
-	var result struct {
-		Items      []googleEvent `json:"items"`
-		NextSyncToken string `json:"nextSyncToken"`
-	}
-	
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-	
-	syncResult := &SyncResult{
-		NextSyncToken: result.NextSyncToken,
-	}
-	
-	for _, item := range result.Items {
-		switch item.Status {
-		case "cancelled":
-			syncResult.Deleted++
-		default:
-			if item.Created == item.Updated {
-				syncResult.Added++
-			} else {
-				syncResult.Updated++
-			}
+// RemoteChange is an event that changed in Google Calendar since the last
+// sync. Cancelled events have Event.Status EventStatusCancelled.
+type RemoteChange struct {
+	Event   CalendarEvent
+	Updated time.Time
+}
+
+// EventChanges is one incremental sync of a calendar
+type EventChanges struct {
+	Changes       []RemoteChange
+	NextSyncToken string
+}
+
+// ListChanges returns the events changed since syncToken, following every
+// page. Without a token it lists events from the last 30 days onwards. An
+// expired token returns ErrSyncTokenExpired.
+func (g *GoogleCalendarProvider) ListChanges(ctx context.Context, client *http.Client, calendarID, syncToken string) (*EventChanges, error) {
+	baseURL := g.apiBase + "/calendars/"
+	apiURL := fmt.Sprintf("%s%s/events", baseURL, neturl.PathEscape(calendarID))
+	
+	changes := &EventChanges{}
+	pageToken := ""
+	for {
+		params := neturl.Values{}
+		params.Set("singleEvents", "true")
+		params.Set("showDeleted", "true")
+		params.Set("maxResults", "250")
+		if syncToken != "" {
+			params.Set("syncToken", syncToken)
+		} else {
+			params.Set("timeMin", time.Now().AddDate(0, 0, -30).Format(time.RFC3339))
+		}
+		if pageToken != "" {
+			params.Set("pageToken", pageToken)
+		}
+		
+		req, err := http.NewRequestWithContext(ctx, "GET", apiURL+"?"+params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("sync failed: %w", err)
+		}
+		
+		if resp.StatusCode == http.StatusGone {
+			resp.Body.Close()
+			return nil, ErrSyncTokenExpired
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("sync failed: %s - %s", resp.Status, string(body))
 		}
+		
+		var result struct {
+			Items         []googleEvent `json:"items"`
+			NextPageToken string        `json:"nextPageToken"`
+			NextSyncToken string        `json:"nextSyncToken"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		
+		for _, item := range result.Items {
+			updated, _ := time.Parse(time.RFC3339, item.Updated)
+			changes.Changes = append(changes.Changes, RemoteChange{
+				Event:   g.convertGoogleEvent(item),
+				Updated: updated,
+			})
+		}
+		
+		if result.NextPageToken == "" {
+			changes.NextSyncToken = result.NextSyncToken
+			return changes, nil
+		}
+		pageToken = result.NextPageToken
 	}
-	
-	return syncResult, nil
 }
@@ -0,0 +1,762 @@
+package calendar
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/recurrence"
+)
+
+// icsProductID identifies Myrai in exported files
+const icsProductID = "-//Myrai//Calendar//EN"
+
+// icsUIDSuffix marks the UIDs of exported events, so importing an export
+// again updates the same events
+const icsUIDSuffix = "@myrai"
+
+const (
+	icsDate     = "20060102"
+	icsDateTime = "20060102T150405"
+	icsUTC      = "20060102T150405Z"
+)
+
+// ExportRange returns the span an export of rng covers from now on: "day",
+// "week", "month" or "year". "all" returns zero times.
+func ExportRange(rng string, now time.Time) (from, to time.Time, err error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(rng) {
+	case "day", "today":
+		return today, today.AddDate(0, 0, 1), nil
+	case "week":
+		return today, today.AddDate(0, 0, 7), nil
+	case "", "month":
+		return today, today.AddDate(0, 1, 0), nil
+	case "year":
+		return today, today.AddDate(1, 0, 0), nil
+	case "all":
+		return time.Time{}, time.Time{}, nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown range %q: use day, week, month, year or all", rng)
+	}
+}
+
+// ExportICS writes events as an iCalendar (RFC 5545) file. Timed events
+// with a known time zone keep it, together with a VTIMEZONE describing its
+// daylight saving rules, so recurring events stay at the same local time.
+func ExportICS(w io.Writer, name string, events []CalendarEvent) error {
+	out := &icsWriter{}
+	out.line("BEGIN:VCALENDAR")
+	out.line("VERSION:2.0")
+	out.line("PRODID:" + icsProductID)
+	out.line("CALSCALE:GREGORIAN")
+	if name != "" {
+		out.line("X-WR-CALNAME:" + escapeICSText(name))
+	}
+
+	// One VTIMEZONE per zone, starting in the year of its first event
+	zoneYears := make(map[string]int)
+	var zones []*time.Location
+	for i := range events {
+		loc := eventLocation(&events[i])
+		if loc == nil {
+			continue
+		}
+		year := events[i].StartTime.In(loc).Year()
+		if first, ok := zoneYears[loc.String()]; !ok {
+			zoneYears[loc.String()] = year
+			zones = append(zones, loc)
+		} else if year < first {
+			zoneYears[loc.String()] = year
+		}
+	}
+	for _, loc := range zones {
+		writeVTimezone(out, loc, zoneYears[loc.String()])
+	}
+
+	stamp := time.Now().UTC().Format(icsUTC)
+	for i := range events {
+		writeVEvent(out, &events[i], stamp)
+	}
+	out.line("END:VCALENDAR")
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func writeVEvent(out *icsWriter, e *CalendarEvent, stamp string) {
+	out.line("BEGIN:VEVENT")
+	out.line("UID:" + eventUID(e))
+	out.line("DTSTAMP:" + stamp)
+	if !e.CreatedAt.IsZero() {
+		out.line("CREATED:" + e.CreatedAt.UTC().Format(icsUTC))
+	}
+	if !e.UpdatedAt.IsZero() {
+		out.line("LAST-MODIFIED:" + e.UpdatedAt.UTC().Format(icsUTC))
+	}
+
+	loc := eventLocation(e)
+	switch {
+	case e.AllDay:
+		end := e.EndTime
+		if !end.After(e.StartTime) {
+			end = e.StartTime.AddDate(0, 0, 1)
+		}
+		out.line("DTSTART;VALUE=DATE:" + e.StartTime.Format(icsDate))
+		out.line("DTEND;VALUE=DATE:" + end.Format(icsDate))
+	case loc != nil:
+		out.line("DTSTART;TZID=" + loc.String() + ":" + e.StartTime.In(loc).Format(icsDateTime))
+		if !e.EndTime.IsZero() {
+			out.line("DTEND;TZID=" + loc.String() + ":" + e.EndTime.In(loc).Format(icsDateTime))
+		}
+	default:
+		out.line("DTSTART:" + e.StartTime.UTC().Format(icsUTC))
+		if !e.EndTime.IsZero() {
+			out.line("DTEND:" + e.EndTime.UTC().Format(icsUTC))
+		}
+	}
+
+	if e.RecurrenceRule != "" {
+		out.line("RRULE:" + strings.TrimPrefix(recurrence.Portable(e.RecurrenceRule), "RRULE:"))
+	}
+	out.line("SUMMARY:" + escapeICSText(e.Title))
+	if e.Description != "" {
+		out.line("DESCRIPTION:" + escapeICSText(e.Description))
+	}
+	if e.Location != "" {
+		out.line("LOCATION:" + escapeICSText(e.Location))
+	}
+	switch e.Status {
+	case EventStatusTentative:
+		out.line("STATUS:TENTATIVE")
+	case EventStatusCancelled:
+		out.line("STATUS:CANCELLED")
+	default:
+		out.line("STATUS:CONFIRMED")
+	}
+	if e.ConferenceURL != "" {
+		out.line("URL:" + e.ConferenceURL)
+	}
+	if strings.Contains(e.Organizer, "@") {
+		out.line("ORGANIZER:mailto:" + e.Organizer)
+	}
+	if e.Attendees != "" {
+		var attendees []Attendee
+		if err := json.Unmarshal([]byte(e.Attendees), &attendees); err == nil {
+			for _, a := range attendees {
+				out.line("ATTENDEE" + attendeeParams(a) + ":mailto:" + a.Email)
+			}
+		}
+	}
+	out.line("END:VEVENT")
+}
+
+// eventUID is the UID an event is exported with: the original UID of an
+// imported event, or the event's own ID
+func eventUID(e *CalendarEvent) string {
+	if e.Source == "ics" && e.SourceID != "" {
+		return e.SourceID
+	}
+	return e.ID + icsUIDSuffix
+}
+
+// eventLocation is the named zone a timed event is exported in, or nil to
+// write it in UTC
+func eventLocation(e *CalendarEvent) *time.Location {
+	if e.AllDay || e.Timezone == "" || e.Timezone == "UTC" {
+		return nil
+	}
+	loc, err := time.LoadLocation(e.Timezone)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+func attendeeParams(a Attendee) string {
+	var params strings.Builder
+	if a.Name != "" {
+		params.WriteString(";CN=" + icsParamValue(a.Name))
+	}
+	switch a.ResponseStatus {
+	case "accepted":
+		params.WriteString(";PARTSTAT=ACCEPTED")
+	case "declined":
+		params.WriteString(";PARTSTAT=DECLINED")
+	case "tentative":
+		params.WriteString(";PARTSTAT=TENTATIVE")
+	case "needsAction":
+		params.WriteString(";PARTSTAT=NEEDS-ACTION")
+	}
+	if a.Optional {
+		params.WriteString(";ROLE=OPT-PARTICIPANT")
+	}
+	return params.String()
+}
+
+// writeVTimezone describes loc from year on: a single STANDARD block for
+// zones without daylight saving, otherwise yearly STANDARD and DAYLIGHT
+// rules derived from the zone's transitions that year
+func writeVTimezone(out *icsWriter, loc *time.Location, year int) {
+	out.line("BEGIN:VTIMEZONE")
+	out.line("TZID:" + loc.String())
+
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	mid := time.Date(year, 7, 1, 0, 0, 0, 0, loc)
+	end := time.Date(year+1, 1, 1, 0, 0, 0, 0, loc)
+	name, offset := start.Zone()
+	_, midOffset := mid.Zone()
+
+	if offset == midOffset {
+		out.line("BEGIN:STANDARD")
+		out.line("DTSTART:" + start.Format(icsDateTime))
+		out.line("TZOFFSETFROM:" + icsOffset(offset))
+		out.line("TZOFFSETTO:" + icsOffset(offset))
+		out.line("TZNAME:" + name)
+		out.line("END:STANDARD")
+	} else {
+		for _, at := range []time.Time{zoneTransition(start, mid), zoneTransition(mid, end)} {
+			_, from := at.Add(-time.Second).Zone()
+			toName, to := at.Zone()
+			kind := "STANDARD"
+			if to > from {
+				kind = "DAYLIGHT"
+			}
+			// Transitions are given in the local time before the change
+			wall := at.In(time.FixedZone("", from))
+			out.line("BEGIN:" + kind)
+			out.line("DTSTART:" + wall.Format(icsDateTime))
+			out.line("RRULE:FREQ=YEARLY;BYMONTH=" + strconv.Itoa(int(wall.Month())) + ";BYDAY=" + monthlyWeekday(wall))
+			out.line("TZOFFSETFROM:" + icsOffset(from))
+			out.line("TZOFFSETTO:" + icsOffset(to))
+			out.line("TZNAME:" + toName)
+			out.line("END:" + kind)
+		}
+	}
+	out.line("END:VTIMEZONE")
+}
+
+// zoneTransition finds the instant between a and b where the UTC offset
+// changes, to the second
+func zoneTransition(a, b time.Time) time.Time {
+	_, before := a.Zone()
+	for b.Sub(a) > time.Second {
+		m := a.Add(b.Sub(a) / 2)
+		if _, off := m.Zone(); off == before {
+			a = m
+		} else {
+			b = m
+		}
+	}
+	return b.Truncate(time.Second)
+}
+
+// monthlyWeekday writes t's day as an RRULE BYDAY, e.g. "2SU" or "-1SU"
+// for the last Sunday of the month
+func monthlyWeekday(t time.Time) string {
+	day := strings.ToUpper(t.Weekday().String()[:2])
+	lastDay := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if t.Day()+7 > lastDay {
+		return "-1" + day
+	}
+	return strconv.Itoa((t.Day()-1)/7+1) + day
+}
+
+func icsOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// icsWriter builds an iCalendar file, folding lines at 75 octets
+type icsWriter struct {
+	strings.Builder
+}
+
+func (w *icsWriter) line(s string) {
+	width := 0
+	for _, r := range s {
+		size := len(string(r))
+		if width+size > 75 {
+			w.WriteString("\r\n ")
+			width = 1
+		}
+		w.WriteRune(r)
+		width += size
+	}
+	w.WriteString("\r\n")
+}
+
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+func unescapeICSText(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+func icsParamValue(s string) string {
+	s = strings.ReplaceAll(s, `"`, "")
+	if strings.ContainsAny(s, ":;,") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// icsLine is one unfolded content line
+type icsLine struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ParseICS reads the events of an iCalendar file. Times keep their zone
+// when the TZID is a known IANA zone; other zones fall back to the offset
+// their VTIMEZONE declares. Events that can't be read are skipped and
+// counted in skipped.
+func ParseICS(r io.Reader) (events []CalendarEvent, skipped int, err error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	p := &icsParser{offsets: make(map[string]int)}
+	var vevents [][]icsLine
+	var stack []string
+	var current []icsLine
+	var tzid string
+	sawCalendar := false
+
+	for _, raw := range lines {
+		line, ok := parseICSLine(raw)
+		if !ok {
+			continue
+		}
+		switch line.name {
+		case "BEGIN":
+			component := strings.ToUpper(line.value)
+			stack = append(stack, component)
+			if component == "VCALENDAR" {
+				sawCalendar = true
+			}
+			if component == "VEVENT" && len(stack) == 2 {
+				current = nil
+			}
+			continue
+		case "END":
+			if len(stack) == 0 {
+				continue
+			}
+			if stack[len(stack)-1] == "VEVENT" && len(stack) == 2 {
+				vevents = append(vevents, current)
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		switch {
+		case len(stack) == 2 && stack[1] == "VEVENT":
+			current = append(current, line)
+		case len(stack) >= 2 && stack[1] == "VTIMEZONE":
+			if line.name == "TZID" {
+				tzid = line.value
+			}
+			// The standard offset stands in for zones Go doesn't know
+			if line.name == "TZOFFSETTO" && len(stack) == 3 {
+				if _, known := p.offsets[tzid]; !known || stack[2] == "STANDARD" {
+					if off, ok := parseICSOffset(line.value); ok {
+						p.offsets[tzid] = off
+					}
+				}
+			}
+		}
+	}
+	if !sawCalendar {
+		return nil, 0, fmt.Errorf("not an iCalendar file")
+	}
+
+	for _, props := range vevents {
+		event, err := p.event(props)
+		if err != nil {
+			skipped++
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, skipped, nil
+}
+
+// unfoldICS splits the file into content lines, joining folded lines
+func unfoldICS(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// parseICSLine splits NAME;PARAM=value;PARAM="quoted":value
+func parseICSLine(raw string) (icsLine, bool) {
+	line := icsLine{params: make(map[string]string)}
+	inQuotes := false
+	colon := -1
+	for i, r := range raw {
+		if r == '"' {
+			inQuotes = !inQuotes
+		} else if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return line, false
+	}
+	line.value = raw[colon+1:]
+
+	parts := splitICSParams(raw[:colon])
+	line.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		line.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return line, true
+}
+
+func splitICSParams(s string) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i, r := range s {
+		if r == '"' {
+			inQuotes = !inQuotes
+		} else if r == ';' && !inQuotes {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func parseICSOffset(s string) (int, bool) {
+	if len(s) < 5 || (s[0] != '+' && s[0] != '-') {
+		return 0, false
+	}
+	h, err1 := strconv.Atoi(s[1:3])
+	m, err2 := strconv.Atoi(s[3:5])
+	if err1 != nil || err2 != nil {
+		return 0, false
+	}
+	off := h*3600 + m*60
+	if s[0] == '-' {
+		off = -off
+	}
+	return off, true
+}
+
+type icsParser struct {
+	offsets map[string]int // VTIMEZONE standard offsets by TZID
+}
+
+func (p *icsParser) event(props []icsLine) (CalendarEvent, error) {
+	event := CalendarEvent{
+		CalendarID: "primary",
+		Status:     EventStatusConfirmed,
+		Source:     "ics",
+	}
+
+	var uid, recurrenceID string
+	var duration time.Duration
+	var hasStart, hasEnd, hasDuration bool
+	var attendees []Attendee
+
+	for _, prop := range props {
+		switch prop.name {
+		case "UID":
+			uid = prop.value
+		case "RECURRENCE-ID":
+			recurrenceID = prop.value
+		case "SUMMARY":
+			event.Title = unescapeICSText(prop.value)
+		case "DESCRIPTION":
+			event.Description = unescapeICSText(prop.value)
+		case "LOCATION":
+			event.Location = unescapeICSText(prop.value)
+		case "URL":
+			event.ConferenceURL = prop.value
+		case "DTSTART":
+			start, allDay, zone, err := p.time(prop)
+			if err != nil {
+				return event, err
+			}
+			event.StartTime, event.AllDay, event.Timezone = start, allDay, zone
+			hasStart = true
+		case "DTEND":
+			end, _, _, err := p.time(prop)
+			if err != nil {
+				return event, err
+			}
+			event.EndTime = end
+			hasEnd = true
+		case "DURATION":
+			d, err := parseICSDuration(prop.value)
+			if err != nil {
+				return event, err
+			}
+			duration, hasDuration = d, true
+		case "RRULE":
+			event.RecurrenceRule = "RRULE:" + prop.value
+			event.IsRecurring = true
+		case "STATUS":
+			switch strings.ToUpper(prop.value) {
+			case "TENTATIVE":
+				event.Status = EventStatusTentative
+			case "CANCELLED":
+				event.Status = EventStatusCancelled
+			}
+		case "ORGANIZER":
+			event.Organizer = stripMailto(prop.value)
+		case "ATTENDEE":
+			attendees = append(attendees, Attendee{
+				Email:          stripMailto(prop.value),
+				Name:           prop.params["CN"],
+				ResponseStatus: partstatToResponse(prop.params["PARTSTAT"]),
+				Optional:       strings.EqualFold(prop.params["ROLE"], "OPT-PARTICIPANT"),
+			})
+		}
+	}
+
+	if !hasStart {
+		return event, fmt.Errorf("event %q has no start", uid)
+	}
+	switch {
+	case hasEnd:
+	case hasDuration:
+		event.EndTime = event.StartTime.Add(duration)
+	case event.AllDay:
+		event.EndTime = event.StartTime.AddDate(0, 0, 1)
+	default:
+		event.EndTime = event.StartTime
+	}
+	if event.Title == "" {
+		event.Title = "(no title)"
+	}
+
+	event.SourceID = uid
+	if recurrenceID != "" {
+		// A changed occurrence of a series shares the series' UID
+		event.SourceID = uid + "#" + recurrenceID
+		event.RecurringEventID = uid
+		event.IsRecurring = true
+	}
+	if len(attendees) > 0 {
+		data, _ := json.Marshal(attendees)
+		event.Attendees = string(data)
+	}
+	return event, nil
+}
+
+// time reads a DATE or DATE-TIME value. zone is the IANA zone the time was
+// given in, if any.
+func (p *icsParser) time(prop icsLine) (t time.Time, allDay bool, zone string, err error) {
+	value := prop.value
+	if strings.EqualFold(prop.params["VALUE"], "DATE") || len(value) == len(icsDate) {
+		t, err = time.ParseInLocation(icsDate, value, time.Local)
+		return t, true, "", err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse(icsUTC, value)
+		return t, false, "", err
+	}
+	loc := time.Local
+	if tzid := prop.params["TZID"]; tzid != "" {
+		var named bool
+		loc, named = p.location(tzid)
+		if named {
+			zone = loc.String()
+		}
+	}
+	t, err = time.ParseInLocation(icsDateTime, value, loc)
+	return t, false, zone, err
+}
+
+// location resolves a TZID to an IANA zone, also accepting prefixed forms
+// like /mozilla.org/20050126_1/Europe/Berlin. Unknown zones use the offset
+// from their VTIMEZONE.
+func (p *icsParser) location(tzid string) (*time.Location, bool) {
+	if loc, err := time.LoadLocation(tzid); err == nil {
+		return loc, true
+	}
+	if parts := strings.Split(strings.Trim(tzid, "/"), "/"); len(parts) >= 2 {
+		if loc, err := time.LoadLocation(strings.Join(parts[len(parts)-2:], "/")); err == nil {
+			return loc, true
+		}
+	}
+	if off, ok := p.offsets[tzid]; ok {
+		return time.FixedZone(tzid, off), false
+	}
+	return time.Local, false
+}
+
+var icsDurationPattern = regexp.MustCompile(`^\+?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseICSDuration reads durations like PT1H30M, P1D or P2W
+func parseICSDuration(s string) (time.Duration, error) {
+	m := icsDurationPattern.FindStringSubmatch(strings.ToUpper(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+1] != "" {
+			n, _ := strconv.Atoi(m[i+1])
+			d += time.Duration(n) * unit
+		}
+	}
+	return d, nil
+}
+
+func stripMailto(s string) string {
+	if len(s) >= 7 && strings.EqualFold(s[:7], "mailto:") {
+		return s[7:]
+	}
+	return s
+}
+
+func partstatToResponse(partstat string) string {
+	switch strings.ToUpper(partstat) {
+	case "ACCEPTED":
+		return "accepted"
+	case "DECLINED":
+		return "declined"
+	case "TENTATIVE":
+		return "tentative"
+	case "NEEDS-ACTION":
+		return "needsAction"
+	}
+	return ""
+}
+
+// ImportResult counts what an ICS import did
+type ImportResult struct {
+	Added   int `json:"added"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+// ImportICS adds the events of an iCalendar file to userID's calendar.
+// Importing the same file again, or a file exported by Myrai, updates the
+// events instead of duplicating them, and an event with the same title and
+// start as an existing one is skipped.
+func ImportICS(store *Store, userID string, r io.Reader) (*ImportResult, error) {
+	events, skipped, err := ParseICS(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Skipped: skipped}
+	for i := range events {
+		event := &events[i]
+		event.UserID = userID
+
+		existing, err := findImported(store, userID, event.SourceID)
+		if err != nil {
+			return result, err
+		}
+		if existing != nil {
+			applyImported(existing, event)
+			if err := store.UpdateEvent(existing); err != nil {
+				return result, err
+			}
+			result.Updated++
+			continue
+		}
+
+		if event.Status == EventStatusCancelled {
+			result.Skipped++
+			continue
+		}
+		duplicate, err := hasEventAt(store, userID, event.Title, event.StartTime)
+		if err != nil {
+			return result, err
+		}
+		if duplicate {
+			result.Skipped++
+			continue
+		}
+		if err := store.CreateEvent(event); err != nil {
+			return result, err
+		}
+		result.Added++
+	}
+	return result, nil
+}
+
+// findImported finds the event an imported UID refers to: one of ours from
+// an earlier export, or one imported before
+func findImported(store *Store, userID, uid string) (*CalendarEvent, error) {
+	if uid == "" {
+		return nil, nil
+	}
+	if id, ok := strings.CutSuffix(uid, icsUIDSuffix); ok {
+		event, err := store.GetEvent(id)
+		if err != nil || (event != nil && event.UserID == userID) {
+			return event, err
+		}
+	}
+	return store.GetEventBySourceID(userID, uid)
+}
+
+func hasEventAt(store *Store, userID, title string, start time.Time) (bool, error) {
+	events, err := store.GetEventsByTitle(userID, title)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range events {
+		if e.StartTime.Equal(start) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// applyImported copies the imported details onto an existing event
+func applyImported(existing, imported *CalendarEvent) {
+	existing.Title = imported.Title
+	existing.Description = imported.Description
+	existing.Location = imported.Location
+	existing.StartTime = imported.StartTime
+	existing.EndTime = imported.EndTime
+	existing.AllDay = imported.AllDay
+	existing.Timezone = imported.Timezone
+	existing.IsRecurring = imported.IsRecurring
+	existing.RecurrenceRule = imported.RecurrenceRule
+	existing.Status = imported.Status
+	if imported.Attendees != "" {
+		existing.Attendees = imported.Attendees
+	}
+	if imported.Organizer != "" {
+		existing.Organizer = imported.Organizer
+	}
+	if imported.ConferenceURL != "" {
+		existing.ConferenceURL = imported.ConferenceURL
+	}
+}
@@ -0,0 +1,147 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestICS_RoundTrip(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	events := []CalendarEvent{
+		{
+			ID: "evt_1", Title: "Team sync; weekly, with notes", Description: "Line one\nLine two",
+			StartTime: time.Date(2030, 1, 7, 9, 30, 0, 0, berlin), EndTime: time.Date(2030, 1, 7, 10, 0, 0, 0, berlin),
+			Timezone: "Europe/Berlin", IsRecurring: true, RecurrenceRule: "RRULE:FREQ=WEEKLY;BYDAY=MO",
+			Attendees: `[{"email":"sam@example.com","name":"Sam, Jr.","response_status":"accepted"}]`,
+			Status:    EventStatusConfirmed,
+		},
+		{
+			ID: "evt_2", Title: strings.Repeat("Holiday ", 15), AllDay: true,
+			StartTime: time.Date(2030, 8, 1, 0, 0, 0, 0, time.Local), EndTime: time.Date(2030, 8, 3, 0, 0, 0, 0, time.Local),
+			Status: EventStatusTentative,
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportICS(&buf, "Myrai", events))
+	out := buf.String()
+
+	assert.Contains(t, out, "BEGIN:VTIMEZONE\r\nTZID:Europe/Berlin\r\n")
+	assert.Contains(t, out, "RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200")
+	assert.Contains(t, out, "DTSTART;TZID=Europe/Berlin:20300107T093000\r\n")
+	assert.Contains(t, out, `SUMMARY:Team sync\; weekly\, with notes`)
+	for _, line := range strings.Split(out, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "line not folded: %q", line)
+	}
+
+	parsed, skipped, err := ParseICS(&buf)
+	require.NoError(t, err)
+	assert.Zero(t, skipped)
+	require.Len(t, parsed, 2)
+
+	weekly := parsed[0]
+	assert.Equal(t, "evt_1@myrai", weekly.SourceID)
+	assert.Equal(t, events[0].Title, weekly.Title)
+	assert.Equal(t, events[0].Description, weekly.Description)
+	assert.True(t, weekly.StartTime.Equal(events[0].StartTime))
+	assert.Equal(t, "Europe/Berlin", weekly.Timezone)
+	assert.Equal(t, "RRULE:FREQ=WEEKLY;BYDAY=MO", weekly.RecurrenceRule)
+	assert.Contains(t, weekly.Attendees, `"name":"Sam, Jr."`)
+	assert.Contains(t, weekly.Attendees, `"response_status":"accepted"`)
+
+	holiday := parsed[1]
+	assert.Equal(t, events[1].Title, holiday.Title)
+	assert.True(t, holiday.AllDay)
+	assert.Equal(t, 2, int(holiday.EndTime.Sub(holiday.StartTime).Hours()/24))
+	assert.Equal(t, EventStatusTentative, holiday.Status)
+}
+
+func TestParseICS_ForeignFile(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\n" +
+		"BEGIN:VTIMEZONE\nTZID:Custom Zone\nBEGIN:STANDARD\nDTSTART:19700101T000000\nTZOFFSETFROM:+0800\nTZOFFSETTO:+0800\nEND:STANDARD\nEND:VTIMEZONE\n" +
+		"BEGIN:VEVENT\nUID:abc\nDTSTART;TZID=/mozilla.org/20050126_1/America/New_York:20300301T090000\n" +
+		"DURATION:PT1H30M\nSUMMARY:Long\n  folded title\nRRULE:FREQ=DAILY;COUNT=5\n" +
+		"BEGIN:VALARM\nTRIGGER:-PT15M\nDESCRIPTION:ignored\nEND:VALARM\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nUID:abc\nRECURRENCE-ID;TZID=America/New_York:20300303T090000\n" +
+		"DTSTART;TZID=Custom Zone:20300303T100000\nDTEND;TZID=Custom Zone:20300303T110000\nSUMMARY:Moved\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nUID:broken\nSUMMARY:No start\nEND:VEVENT\n" +
+		"END:VCALENDAR\n"
+
+	events, skipped, err := ParseICS(strings.NewReader(ics))
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped)
+	require.Len(t, events, 2)
+
+	series := events[0]
+	assert.Equal(t, "Long folded title", series.Title)
+	assert.Equal(t, "America/New_York", series.Timezone)
+	assert.Equal(t, 90*time.Minute, series.EndTime.Sub(series.StartTime))
+	assert.Equal(t, "RRULE:FREQ=DAILY;COUNT=5", series.RecurrenceRule)
+	assert.Empty(t, series.Description)
+
+	moved := events[1]
+	assert.Equal(t, "abc#20300303T090000", moved.SourceID)
+	assert.Equal(t, "abc", moved.RecurringEventID)
+	assert.Empty(t, moved.Timezone)
+	_, offset := moved.StartTime.Zone()
+	assert.Equal(t, 8*3600, offset)
+
+	_, _, err = ParseICS(strings.NewReader("hello"))
+	assert.Error(t, err)
+}
+
+func TestImportICS(t *testing.T) {
+	store, err := NewStore(setupCalendarTestDB(t))
+	require.NoError(t, err)
+
+	start := time.Date(2030, 5, 1, 12, 0, 0, 0, time.UTC)
+	existing := &CalendarEvent{UserID: "user1", CalendarID: "primary", Title: "Lunch", StartTime: start,
+		EndTime: start.Add(time.Hour), Status: EventStatusConfirmed}
+	require.NoError(t, store.CreateEvent(existing))
+
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:x1\r\nDTSTART:20300502T090000Z\r\nDTEND:20300502T100000Z\r\nSUMMARY:Dentist\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:x2\r\nDTSTART:20300501T120000Z\r\nSUMMARY:Lunch\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:" + existing.ID + "@myrai\r\nDTSTART:20300501T130000Z\r\nSUMMARY:Late lunch\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	result, err := ImportICS(store, "user1", strings.NewReader(ics))
+	require.NoError(t, err)
+	assert.Equal(t, &ImportResult{Added: 1, Updated: 1, Skipped: 1}, result)
+
+	updated, _ := store.GetEvent(existing.ID)
+	assert.Equal(t, "Late lunch", updated.Title)
+
+	// Importing the same file again updates the events it added
+	result, err = ImportICS(store, "user1", strings.NewReader(ics))
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Updated)
+
+	dentist, err := store.GetEventBySourceID("user1", "x1")
+	require.NoError(t, err)
+	require.NotNil(t, dentist)
+	assert.Equal(t, "ics", dentist.Source)
+}
+
+func TestExportRange(t *testing.T) {
+	now := time.Date(2030, 1, 31, 15, 0, 0, 0, time.UTC)
+
+	from, to, err := ExportRange("month", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2030, 3, 3, 0, 0, 0, 0, time.UTC), to)
+
+	from, to, err = ExportRange("all", now)
+	require.NoError(t, err)
+	assert.True(t, from.IsZero() && to.IsZero())
+
+	_, _, err = ExportRange("decade", now)
+	assert.Error(t, err)
+}
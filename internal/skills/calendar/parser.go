@@ -5,17 +5,22 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/gmsas95/myrai-cli/internal/recurrence"
 )
 
 // EventParser parses natural language into calendar events
 type EventParser struct {
 	referenceTime time.Time
+	locale        locale.Locale
 }
 
 // NewEventParser creates a new event parser
 func NewEventParser() *EventParser {
 	return &EventParser{
 		referenceTime: time.Now(),
+		locale:        locale.Default(),
 	}
 }
 
@@ -25,6 +30,12 @@ func (p *EventParser) WithReference(t time.Time) *EventParser {
 	return p
 }
 
+// WithLocale sets the locale used to read numeric dates like 3/4
+func (p *EventParser) WithLocale(l locale.Locale) *EventParser {
+	p.locale = l
+	return p
+}
+
 // ParseEvent parses natural language event description
 func (p *EventParser) ParseEvent(text string) (*ParseResult, error) {
 	text = strings.TrimSpace(text)
@@ -65,10 +76,10 @@ func (p *EventParser) ParseEvent(text string) (*ParseResult, error) {
 	}
 	
 	// Check for recurrence
-	recurrence := p.extractRecurrence(text)
-	if recurrence != "" {
+	rrule := p.extractRecurrence(text)
+	if rrule != "" {
 		result.IsRecurring = true
-		result.Recurrence = recurrence
+		result.Recurrence = rrule
 		result.Confidence += 0.1
 	}
 	
@@ -78,6 +89,17 @@ func (p *EventParser) ParseEvent(text string) (*ParseResult, error) {
 		result.EndTime = result.StartTime.Add(time.Hour)
 	}
 	
+	// A series starts on its first occurrence, e.g. the next 2nd Tuesday
+	if result.IsRecurring && !result.StartTime.IsZero() {
+		if rule, err := recurrence.Parse(result.Recurrence); err == nil {
+			if first, ok := rule.Next(result.StartTime, result.StartTime.Add(-time.Second)); ok {
+				shift := first.Sub(result.StartTime)
+				result.StartTime = result.StartTime.Add(shift)
+				result.EndTime = result.EndTime.Add(shift)
+			}
+		}
+	}
+	
 	return result, nil
 }
 
@@ -235,7 +257,17 @@ func (p *EventParser) extractDate(text string) time.Time {
 		}
 	}
 	
-	// Try second pattern (M/D or M/D/YYYY)
+	// Try third pattern (Day Month)
+	re = regexp.MustCompile(datePatterns[2])
+	matches = re.FindStringSubmatch(text)
+	if len(matches) >= 3 {
+		month := months[strings.ToLower(matches[2][:3])]
+		if day, err := parseInt(matches[1]); err == nil && day > 0 {
+			return time.Date(now.Year(), month, day, 0, 0, 0, 0, now.Location())
+		}
+	}
+	
+	// Try second pattern (M/D or D/M, as the user's locale writes dates)
 	re = regexp.MustCompile(datePatterns[1])
 	matches = re.FindStringSubmatch(text)
 	if len(matches) >= 3 {
@@ -247,6 +279,9 @@ func (p *EventParser) extractDate(text string) time.Time {
 		if d, err := parseInt(matches[2]); err == nil {
 			day = d
 		}
+		if p.locale.DayFirst() {
+			month, day = day, month
+		}
 		if month > 0 && day > 0 {
 			year := now.Year()
 			if len(matches) >= 4 && matches[3] != "" {
@@ -393,41 +428,15 @@ func (p *EventParser) extractAttendees(text string) []string {
 	return attendees
 }
 
-// extractRecurrence extracts recurrence pattern
+// extractRecurrence extracts a recurrence pattern as an RRULE. The event's
+// start time sets the time of day, so times in the phrase are dropped.
 func (p *EventParser) extractRecurrence(text string) string {
-	patterns := map[string]string{
-		`(?i)\bevery\s+day\b`:                "RRULE:FREQ=DAILY",
-		`(?i)\bevery\s+week\b`:               "RRULE:FREQ=WEEKLY",
-		`(?i)\bevery\s+month\b`:              "RRULE:FREQ=MONTHLY",
-		`(?i)\bdaily\b`:                      "RRULE:FREQ=DAILY",
-		`(?i)\bweekly\b`:                     "RRULE:FREQ=WEEKLY",
-		`(?i)\bmonthly\b`:                    "RRULE:FREQ=MONTHLY",
-		`(?i)\bevery\s+(mon|tues|wednes|thurs|fri|satur|sun)day\b`: "RRULE:FREQ=WEEKLY",
+	rule, _ := recurrence.Extract(text)
+	if rule == nil {
+		return ""
 	}
-	
-	for pattern, rrule := range patterns {
-		if regexp.MustCompile(pattern).MatchString(text) {
-			// For specific days, add BYDAY
-			if strings.Contains(pattern, "mon") {
-				return "RRULE:FREQ=WEEKLY;BYDAY=MO"
-			}
-			if strings.Contains(pattern, "tues") {
-				return "RRULE:FREQ=WEEKLY;BYDAY=TU"
-			}
-			if strings.Contains(pattern, "wednes") {
-				return "RRULE:FREQ=WEEKLY;BYDAY=WE"
-			}
-			if strings.Contains(pattern, "thurs") {
-				return "RRULE:FREQ=WEEKLY;BYDAY=TH"
-			}
-			if strings.Contains(pattern, "fri") {
-				return "RRULE:FREQ=WEEKLY;BYDAY=FR"
-			}
-			return rrule
-		}
-	}
-	
-	return ""
+	rule.ByHour, rule.ByMinute = nil, nil
+	return rule.String()
 }
 
 // inferTimeFromContext infers time from context keywords
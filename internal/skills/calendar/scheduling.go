@@ -0,0 +1,355 @@
+package calendar
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits on how far ahead free slots are searched
+const (
+	DefaultSearchDays = 7
+	MaxSearchDays     = 30
+)
+
+// SlotOptions constrains the times offered for an event. The defaults keep
+// suggestions considerate of the people attending: working hours on
+// weekdays, a gap around other meetings and some notice before it starts.
+type SlotOptions struct {
+	Duration time.Duration
+	DayStart time.Duration // earliest start, as time after midnight
+	DayEnd   time.Duration // latest end, as time after midnight
+	Weekends bool
+	Buffer   time.Duration // kept free before and after other events
+	Notice   time.Duration // minimum time from now to the start
+	Step     time.Duration // granularity of start times
+	Limit    int
+}
+
+// DefaultSlotOptions returns options for an event of duration d
+func DefaultSlotOptions(d time.Duration) SlotOptions {
+	if d <= 0 {
+		d = time.Hour
+	}
+	return SlotOptions{
+		Duration: d,
+		DayStart: 9 * time.Hour,
+		DayEnd:   17 * time.Hour,
+		Buffer:   10 * time.Minute,
+		Notice:   time.Hour,
+		Step:     30 * time.Minute,
+		Limit:    3,
+	}
+}
+
+// FindFreeSlots returns times between from and until when an event fits
+// around busy. With a non-zero preferred time, slots closest to it come
+// first; otherwise they are in chronological order.
+func FindFreeSlots(busy []CalendarEvent, from, until, preferred time.Time, opts SlotOptions) []ScheduleSuggestion {
+	if opts.Step <= 0 {
+		opts.Step = 30 * time.Minute
+	}
+	if opts.DayEnd <= opts.DayStart {
+		return nil
+	}
+
+	var slots []ScheduleSuggestion
+	for day := startOfDay(from); day.Before(until); day = day.AddDate(0, 0, 1) {
+		if !opts.Weekends && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+			continue
+		}
+		// Build each time from the date so DST changes don't shift the hours
+		for offset := opts.DayStart; offset+opts.Duration <= opts.DayEnd; offset += opts.Step {
+			start := atOffset(day, offset)
+			end := start.Add(opts.Duration)
+			if start.Before(from) || end.After(until) {
+				continue
+			}
+			if overlapsAny(busy, start.Add(-opts.Buffer), end.Add(opts.Buffer)) {
+				continue
+			}
+			slots = append(slots, ScheduleSuggestion{Start: start, End: end, Score: 1})
+		}
+	}
+
+	if !preferred.IsZero() {
+		tiers := make([]int, len(slots))
+		for i := range slots {
+			tiers[i], slots[i].Score, slots[i].Reason = rankSlot(slots[i].Start, preferred)
+		}
+		order := make([]int, len(slots))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			i, j := order[a], order[b]
+			if tiers[i] != tiers[j] {
+				return tiers[i] > tiers[j]
+			}
+			return slots[i].Score > slots[j].Score
+		})
+		ranked := make([]ScheduleSuggestion, len(slots))
+		for k, i := range order {
+			ranked[k] = slots[i]
+		}
+		slots = ranked
+	}
+	if opts.Limit > 0 && len(slots) > opts.Limit {
+		slots = slots[:opts.Limit]
+	}
+	return slots
+}
+
+// rankSlot rates how close start is to preferred. Slots on the same day
+// rank first, then ones at the same time on another day, then the rest;
+// within a tier, nearer is better.
+func rankSlot(start, preferred time.Time) (tier int, score float64, reason string) {
+	distance := start.Sub(preferred)
+	if distance < 0 {
+		distance = -distance
+	}
+	score = 1 - float64(distance)/float64((MaxSearchDays+1)*24*time.Hour)
+
+	switch {
+	case sameDay(start, preferred):
+		return 2, score, "Same day"
+	case start.Hour() == preferred.Hour() && start.Minute() == preferred.Minute():
+		return 1, score, "Same time, different day"
+	}
+	return 0, score, ""
+}
+
+// Parts of the day a free slot can be preferred in
+const (
+	PreferMorning   = "morning"   // before noon
+	PreferAfternoon = "afternoon" // noon to 5pm
+	PreferEvening   = "evening"   // from 5pm
+)
+
+// roomCap is how much free time around a slot still improves its rank
+const roomCap = 2 * time.Hour
+
+// MergeBusy sorts busy periods and joins those that overlap or touch, so a
+// time blocked in several calendars counts once
+func MergeBusy(events []CalendarEvent) []CalendarEvent {
+	sorted := make([]CalendarEvent, 0, len(events))
+	for _, e := range events {
+		if e.EndTime.After(e.StartTime) {
+			sorted = append(sorted, e)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].StartTime.Before(sorted[j].StartTime) })
+
+	var merged []CalendarEvent
+	for _, e := range sorted {
+		if n := len(merged); n > 0 && !e.StartTime.After(merged[n-1].EndTime) {
+			if e.EndTime.After(merged[n-1].EndTime) {
+				merged[n-1].EndTime = e.EndTime
+			}
+			continue
+		}
+		merged = append(merged, CalendarEvent{Title: e.Title, StartTime: e.StartTime, EndTime: e.EndTime})
+	}
+	return merged
+}
+
+// RankFreeSlots orders slots best first and keeps up to limit that don't
+// overlap each other. Sooner slots, slots with more free time around them
+// and slots in the preferred part of the day rank higher. busy must be
+// merged.
+func RankFreeSlots(slots []ScheduleSuggestion, busy []CalendarEvent, now time.Time, prefer string, limit int) []ScheduleSuggestion {
+	if len(slots) == 0 {
+		return nil
+	}
+	horizon := slots[len(slots)-1].Start.Sub(now)
+	for _, slot := range slots {
+		if d := slot.Start.Sub(now); d > horizon {
+			horizon = d
+		}
+	}
+
+	ranked := make([]ScheduleSuggestion, len(slots))
+	for i, slot := range slots {
+		soon := 1.0
+		if horizon > 0 {
+			soon = 1 - float64(slot.Start.Sub(now))/float64(horizon)
+		}
+		room := float64(freeAround(busy, slot.Start, slot.End)) / float64(roomCap)
+
+		var reasons []string
+		if i == 0 {
+			reasons = append(reasons, "soonest")
+		}
+		if room == 1 {
+			reasons = append(reasons, "nothing else nearby")
+		}
+		slot.Score = 0.5*soon + 0.5*room
+		if prefer != "" {
+			match := 0.0
+			if partOfDay(slot.Start) == prefer {
+				match = 1
+				reasons = append(reasons, "in the "+prefer)
+			}
+			slot.Score = 0.35*soon + 0.3*room + 0.35*match
+		}
+		slot.Reason = strings.Join(reasons, ", ")
+		if slot.Reason != "" {
+			slot.Reason = strings.ToUpper(slot.Reason[:1]) + slot.Reason[1:]
+		}
+		ranked[i] = slot
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+
+	var picked []ScheduleSuggestion
+	for _, slot := range ranked {
+		if limit > 0 && len(picked) == limit {
+			break
+		}
+		overlaps := false
+		for _, p := range picked {
+			if slot.Start.Before(p.End) && slot.End.After(p.Start) {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			picked = append(picked, slot)
+		}
+	}
+	return picked
+}
+
+// freeAround is the free time before or after start to end, whichever is
+// shorter, up to roomCap
+func freeAround(busy []CalendarEvent, start, end time.Time) time.Duration {
+	before, after := roomCap, roomCap
+	for _, e := range busy {
+		if !e.EndTime.After(start) && start.Sub(e.EndTime) < before {
+			before = start.Sub(e.EndTime)
+		}
+		if !e.StartTime.Before(end) && e.StartTime.Sub(end) < after {
+			after = e.StartTime.Sub(end)
+		}
+	}
+	return min(before, after)
+}
+
+// partOfDay names the part of the day t falls in
+func partOfDay(t time.Time) string {
+	switch {
+	case t.Hour() < 12:
+		return PreferMorning
+	case t.Hour() < 17:
+		return PreferAfternoon
+	}
+	return PreferEvening
+}
+
+// busyEvents drops all-day events, which mark a day rather than block it
+func busyEvents(events []CalendarEvent) []CalendarEvent {
+	var busy []CalendarEvent
+	for _, e := range events {
+		if !e.AllDay {
+			busy = append(busy, e)
+		}
+	}
+	return busy
+}
+
+func overlapsAny(events []CalendarEvent, start, end time.Time) bool {
+	for _, e := range events {
+		if e.StartTime.Before(end) && e.EndTime.After(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTimeRange reads working hours such as "9am-5pm" or "08:30-18:00"
+func parseTimeRange(s string) (start, end time.Duration, err error) {
+	parts := strings.Split(strings.ReplaceAll(s, " ", ""), "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid time range %q: use e.g. 9am-5pm", s)
+	}
+	if start, err = parseClock(parts[0]); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseClock(parts[1]); err != nil {
+		return 0, 0, err
+	}
+	if end <= start {
+		return 0, 0, fmt.Errorf("invalid time range %q: end must be after start", s)
+	}
+	return start, end, nil
+}
+
+var clockPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+
+// parseClock reads "9am", "5:30pm" or "17:00" as time after midnight
+func parseClock(s string) (time.Duration, error) {
+	m := clockPattern.FindStringSubmatch(strings.ToLower(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	h, _ := strconv.Atoi(m[1])
+	min := 0
+	if m[2] != "" {
+		min, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "pm":
+		if h != 12 {
+			h += 12
+		}
+	case "am":
+		if h == 12 {
+			h = 0
+		}
+	}
+	if h > 24 || min > 59 || (h == 24 && min > 0) {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(min)*time.Minute, nil
+}
+
+var durationPattern = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)\s*(m|min|mins|minute|minutes|h|hr|hrs|hour|hours)$`)
+
+// parseMeetingDuration reads durations like "30 minutes" or "1.5 hours"
+func parseMeetingDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Hour, nil
+	}
+	m := durationPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q: use e.g. 30 minutes or 1 hour", s)
+	}
+	n, _ := strconv.ParseFloat(m[1], 64)
+	unit := time.Minute
+	if strings.HasPrefix(strings.ToLower(m[2]), "h") {
+		unit = time.Hour
+	}
+	d := time.Duration(n * float64(unit))
+	if d <= 0 || d > 24*time.Hour {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+func atOffset(day time.Time, offset time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(),
+		int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, day.Location())
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
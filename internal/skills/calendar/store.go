@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/store"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -14,15 +15,35 @@ type Store struct {
 	db *gorm.DB
 }
 
+func init() {
+	store.RegisterMigrations("calendar", store.Migration{
+		Version: 1,
+		Name:    "create calendar tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&CalendarEvent{}, &Calendar{}, &CalendarCredentials{}, &SyncConflict{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&CalendarEvent{}, &Calendar{}, &CalendarCredentials{}, &SyncConflict{})
+		},
+	})
+	store.RegisterPurge("calendar", purge)
+}
+
+// purge deletes events that ended before cutoff. Events synced from Google
+// are deleted locally only.
+func purge(db *gorm.DB, before time.Time) (int64, error) {
+	result := db.Where("end_time < ?", before).Delete(&CalendarEvent{})
+	return result.RowsAffected, result.Error
+}
+
 // NewStore creates a new calendar store
 func NewStore(db *gorm.DB) (*Store, error) {
-	store := &Store{db: db}
-
-	// Auto-migrate schemas
-	if err := db.AutoMigrate(&CalendarEvent{}, &Calendar{}, &CalendarCredentials{}); err != nil {
+	if err := store.Migrate(db, "calendar"); err != nil {
 		return nil, fmt.Errorf("failed to migrate calendar schemas: %w", err)
 	}
 
+	store := &Store{db: db}
+
 	// Create indexes
 	store.createIndexes()
 
@@ -35,6 +56,9 @@ func (s *Store) createIndexes() {
 	s.db.Exec("CREATE INDEX IF NOT EXISTS idx_events_user_status ON calendar_events(user_id, status)")
 	s.db.Exec("CREATE INDEX IF NOT EXISTS idx_calendars_user ON calendars(user_id)")
 	s.db.Exec("CREATE INDEX IF NOT EXISTS idx_credentials_user ON calendar_credentials(user_id)")
+	// SaveCredentials upserts on (user_id, provider)
+	s.db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_credentials_user_provider ON calendar_credentials(user_id, provider)")
+	s.db.Exec("CREATE INDEX IF NOT EXISTS idx_events_user_source ON calendar_events(user_id, source_id)")
 }
 
 // Calendar operations
@@ -199,6 +223,29 @@ func (s *Store) ListEvents(userID string, filters EventFilters) (*EventList, err
 	}, nil
 }
 
+// GetEventsForExport gets the events starting between from and to, plus
+// recurring events that started earlier. A zero time leaves that end open.
+func (s *Store) GetEventsForExport(userID string, from, to time.Time) ([]CalendarEvent, error) {
+	query := s.db.Where("user_id = ? AND status != ?", userID, EventStatusCancelled)
+	if !to.IsZero() {
+		query = query.Where("start_time < ?", to)
+	}
+	if !from.IsZero() {
+		query = query.Where("start_time >= ? OR (is_recurring = ? AND recurrence_rule != ?)", from, true, "")
+	}
+
+	var events []CalendarEvent
+	err := query.Order("start_time ASC").Find(&events).Error
+	return events, err
+}
+
+// GetEventsByTitle gets a user's events with exactly this title
+func (s *Store) GetEventsByTitle(userID, title string) ([]CalendarEvent, error) {
+	var events []CalendarEvent
+	err := s.db.Where("user_id = ? AND title = ? AND status != ?", userID, title, EventStatusCancelled).Find(&events).Error
+	return events, err
+}
+
 // EventFilters contains filters for listing events
 type EventFilters struct {
 	CalendarID  string
@@ -291,6 +338,13 @@ func (s *Store) SaveCredentials(creds *CalendarCredentials) error {
 	}).Create(creds).Error
 }
 
+// UpdateCredentials saves changes to stored credentials, such as a
+// refreshed token
+func (s *Store) UpdateCredentials(creds *CalendarCredentials) error {
+	creds.UpdatedAt = time.Now()
+	return s.db.Save(creds).Error
+}
+
 // GetCredentials retrieves credentials for a user and provider
 func (s *Store) GetCredentials(userID, provider string) (*CalendarCredentials, error) {
 	var creds CalendarCredentials
@@ -308,6 +362,14 @@ func (s *Store) GetActiveCredentials(userID string) ([]CalendarCredentials, erro
 	return creds, err
 }
 
+// GetAllActiveCredentials gets the active credentials of every user for a
+// provider
+func (s *Store) GetAllActiveCredentials(provider string) ([]CalendarCredentials, error) {
+	var creds []CalendarCredentials
+	err := s.db.Where("provider = ? AND is_active = ?", provider, true).Find(&creds).Error
+	return creds, err
+}
+
 // DeleteCredentials deletes credentials
 func (s *Store) DeleteCredentials(credsID string) error {
 	return s.db.Where("id = ?", credsID).Delete(&CalendarCredentials{}).Error
@@ -324,6 +386,53 @@ func (s *Store) MarkEventSynced(eventID string, externalID string) error {
 	}).Error
 }
 
+// ClearNeedsSync marks an event as settled without touching its source,
+// for events that are never pushed to a provider
+func (s *Store) ClearNeedsSync(eventID string) error {
+	return s.db.Model(&CalendarEvent{}).Where("id = ?", eventID).Update("needs_sync", false).Error
+}
+
+// GetEventBySourceID finds a user's event by its ID in a provider. Events
+// added by other skills, which reuse SourceID for their own IDs, are ignored.
+func (s *Store) GetEventBySourceID(userID, sourceID string) (*CalendarEvent, error) {
+	var event CalendarEvent
+	err := s.db.Where("user_id = ? AND source_id = ? AND source != ?", userID, sourceID, "contacts").First(&event).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &event, err
+}
+
+// SaveSyncedEvent stores an event as it is in the provider, so it is not
+// pushed back on the next sync
+func (s *Store) SaveSyncedEvent(event *CalendarEvent) error {
+	now := time.Now()
+	if event.ID == "" {
+		event.ID = idgen.Generate(idgen.PrefixEvent)
+		event.CreatedAt = now
+	}
+	event.UpdatedAt = now
+	event.LastSynced = &now
+	event.NeedsSync = false
+	return s.db.Save(event).Error
+}
+
+// LogSyncConflict records how a sync conflict was resolved
+func (s *Store) LogSyncConflict(conflict *SyncConflict) error {
+	if conflict.ID == "" {
+		conflict.ID = idgen.Generate("sync")
+	}
+	conflict.CreatedAt = time.Now()
+	return s.db.Create(conflict).Error
+}
+
+// GetSyncConflicts gets a user's most recent sync conflicts
+func (s *Store) GetSyncConflicts(userID string, limit int) ([]SyncConflict, error) {
+	var conflicts []SyncConflict
+	err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Find(&conflicts).Error
+	return conflicts, err
+}
+
 // UpdateSyncToken updates the sync token for a calendar
 func (s *Store) UpdateSyncToken(calendarID string, syncToken string) error {
 	return s.db.Model(&Calendar{}).Where("id = ?", calendarID).Updates(map[string]interface{}{
@@ -332,15 +441,20 @@ func (s *Store) UpdateSyncToken(calendarID string, syncToken string) error {
 	}).Error
 }
 
-// GetStats gets calendar statistics
+// GetStats gets calendar statistics with weeks starting on Sunday
 func (s *Store) GetStats(userID string) (*CalendarStats, error) {
+	return s.GetStatsForWeek(userID, time.Sunday)
+}
+
+// GetStatsForWeek gets calendar statistics with weeks starting on firstDay
+func (s *Store) GetStatsForWeek(userID string, firstDay time.Weekday) (*CalendarStats, error) {
 	stats := &CalendarStats{
 		TopCategories: make(map[string]int),
 	}
 
 	now := time.Now()
 	weekStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	weekStart = weekStart.AddDate(0, 0, -int(weekStart.Weekday())) // Start of week (Sunday)
+	weekStart = weekStart.AddDate(0, 0, -((int(weekStart.Weekday())-int(firstDay)+7)%7))
 	weekEnd := weekStart.AddDate(0, 0, 7)
 	nextWeekStart := weekEnd
 	nextWeekEnd := nextWeekStart.AddDate(0, 0, 7)
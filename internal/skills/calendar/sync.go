@@ -0,0 +1,391 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// pushBatch caps the local changes pushed in one sync
+const pushBatch = 100
+
+// Conflict winners
+const (
+	ConflictLocal  = "local"
+	ConflictRemote = "remote"
+)
+
+// ErrNotConnected means the user hasn't connected Google Calendar
+var ErrNotConnected = errors.New("google calendar is not connected")
+
+// SyncEngine keeps the local calendar and Google Calendar in step. Each run
+// pulls what changed in Google since the last sync token, then pushes the
+// events created, updated or deleted locally since the last run. An event
+// changed on both sides in between keeps the newer edit, and the conflict
+// is logged.
+type SyncEngine struct {
+	store  *Store
+	google *GoogleCalendarProvider
+	logger *zap.Logger
+	mu     sync.Mutex // one sync at a time
+}
+
+// NewSyncEngine creates a sync engine
+func NewSyncEngine(store *Store, google *GoogleCalendarProvider, logger *zap.Logger) *SyncEngine {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &SyncEngine{
+		store:  store,
+		google: google,
+		logger: logger,
+	}
+}
+
+// SyncAll syncs every user who connected Google Calendar
+func (e *SyncEngine) SyncAll(ctx context.Context) error {
+	creds, err := e.store.GetAllActiveCredentials("google")
+	if err != nil {
+		return fmt.Errorf("failed to load credentials: %w", err)
+	}
+
+	var errs []error
+	for i := range creds {
+		result, err := e.syncCredentials(ctx, &creds[i])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", creds[i].UserID, err))
+			continue
+		}
+		if result.Added+result.Updated+result.Deleted+result.Pushed > 0 || len(result.Errors) > 0 {
+			e.logger.Info("Google Calendar synced",
+				zap.String("user_id", creds[i].UserID),
+				zap.Int("added", result.Added),
+				zap.Int("updated", result.Updated),
+				zap.Int("deleted", result.Deleted),
+				zap.Int("pushed", result.Pushed),
+				zap.Int("conflicts", result.Conflicts),
+				zap.Strings("errors", result.Errors),
+			)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SyncUser syncs one user's Google Calendar now
+func (e *SyncEngine) SyncUser(ctx context.Context, userID string) (*SyncResult, error) {
+	creds, err := e.store.GetCredentials(userID, "google")
+	if err != nil {
+		return nil, err
+	}
+	if creds == nil || !creds.IsActive {
+		return nil, ErrNotConnected
+	}
+	return e.syncCredentials(ctx, creds)
+}
+
+// WithGoogleClient calls fn with a client authorised as userID's Google
+// account, keeping the token if it was refreshed on the way
+func (e *SyncEngine) WithGoogleClient(ctx context.Context, userID string, fn func(client *http.Client) error) error {
+	creds, err := e.store.GetCredentials(userID, "google")
+	if err != nil {
+		return err
+	}
+	if creds == nil || !creds.IsActive {
+		return ErrNotConnected
+	}
+
+	refreshed, err := e.withClient(ctx, creds, fn)
+	if refreshed {
+		if saveErr := e.store.UpdateCredentials(creds); saveErr != nil {
+			e.logger.Warn("Failed to save calendar credentials", zap.String("user_id", userID), zap.Error(saveErr))
+		}
+	}
+	return err
+}
+
+// syncCredentials runs a sync with creds and records its outcome on them
+func (e *SyncEngine) syncCredentials(ctx context.Context, creds *CalendarCredentials) (*SyncResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var result *SyncResult
+	_, err := e.withClient(ctx, creds, func(client *http.Client) error {
+		var err error
+		result, err = e.sync(ctx, creds.UserID, client)
+		return err
+	})
+
+	creds.LastError = ""
+	if err != nil {
+		creds.LastError = err.Error()
+	}
+	if saveErr := e.store.UpdateCredentials(creds); saveErr != nil {
+		e.logger.Warn("Failed to save calendar credentials", zap.String("user_id", creds.UserID), zap.Error(saveErr))
+	}
+	return result, err
+}
+
+// withClient calls fn with a client for creds and copies a refreshed token
+// back into creds
+func (e *SyncEngine) withClient(ctx context.Context, creds *CalendarCredentials, fn func(client *http.Client) error) (refreshed bool, err error) {
+	tokens := e.google.config.TokenSource(ctx, &oauth2.Token{
+		AccessToken:  creds.AccessToken,
+		RefreshToken: creds.RefreshToken,
+		Expiry:       creds.TokenExpiry,
+	})
+	err = fn(oauth2.NewClient(ctx, tokens))
+
+	if token, tokenErr := tokens.Token(); tokenErr == nil && token.AccessToken != creds.AccessToken {
+		creds.AccessToken = token.AccessToken
+		creds.TokenExpiry = token.Expiry
+		if token.RefreshToken != "" {
+			creds.RefreshToken = token.RefreshToken
+		}
+		refreshed = true
+	}
+	return refreshed, err
+}
+
+func (e *SyncEngine) sync(ctx context.Context, userID string, client *http.Client) (*SyncResult, error) {
+	calendars, err := e.calendars(ctx, userID, client)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{}
+	for i := range calendars {
+		if err := e.pull(ctx, client, userID, &calendars[i], result); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", calendars[i].Name, err))
+		}
+	}
+	if err := e.push(ctx, client, userID, calendars, result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// calendars returns the user's synced Google calendars. On the first sync
+// they are discovered from Google and only the primary one is synced.
+func (e *SyncEngine) calendars(ctx context.Context, userID string, client *http.Client) ([]Calendar, error) {
+	all, err := e.store.GetUserCalendars(userID)
+	if err != nil {
+		return nil, err
+	}
+	var known []Calendar
+	for _, cal := range all {
+		if cal.Provider == "google" {
+			known = append(known, cal)
+		}
+	}
+
+	if len(known) == 0 {
+		remote, err := e.google.ListCalendars(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		for _, cal := range remote {
+			cal.UserID = userID
+			cal.IsVisible = cal.IsPrimary
+			if err := e.store.CreateCalendar(&cal); err != nil {
+				return nil, fmt.Errorf("failed to save calendar: %w", err)
+			}
+			known = append(known, cal)
+		}
+	}
+
+	var synced []Calendar
+	for _, cal := range known {
+		if cal.IsVisible {
+			synced = append(synced, cal)
+		}
+	}
+	return synced, nil
+}
+
+// pull applies the changes made in Google since the calendar's sync token
+func (e *SyncEngine) pull(ctx context.Context, client *http.Client, userID string, cal *Calendar, result *SyncResult) error {
+	changes, err := e.google.ListChanges(ctx, client, cal.ExternalID, cal.SyncToken)
+	if errors.Is(err, ErrSyncTokenExpired) {
+		e.logger.Info("Calendar sync token expired, running a full sync", zap.String("calendar", cal.Name))
+		changes, err = e.google.ListChanges(ctx, client, cal.ExternalID, "")
+	}
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, change := range changes.Changes {
+		if err := e.applyRemote(userID, cal, change, result); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", change.Event.Title, err))
+			failed = true
+		}
+	}
+
+	// Keep the old token on failure so the changes are fetched again
+	if failed || changes.NextSyncToken == "" {
+		return nil
+	}
+	if err := e.store.UpdateSyncToken(cal.ID, changes.NextSyncToken); err != nil {
+		return err
+	}
+	cal.SyncToken = changes.NextSyncToken
+	result.NextSyncToken = changes.NextSyncToken
+	return nil
+}
+
+// applyRemote stores one event as Google has it, unless the local copy
+// changed more recently
+func (e *SyncEngine) applyRemote(userID string, cal *Calendar, change RemoteChange, result *SyncResult) error {
+	remote := change.Event
+	local, err := e.store.GetEventBySourceID(userID, remote.SourceID)
+	if err != nil {
+		return err
+	}
+
+	if local == nil {
+		if remote.Status == EventStatusCancelled {
+			return nil
+		}
+		// Occurrences of a series created here are covered by the local event
+		if remote.RecurringEventID != "" {
+			if series, err := e.store.GetEventBySourceID(userID, remote.RecurringEventID); err != nil || series != nil {
+				return err
+			}
+		}
+		remote.UserID = userID
+		remote.CalendarID = cal.ExternalID
+		if err := e.store.SaveSyncedEvent(&remote); err != nil {
+			return err
+		}
+		result.Added++
+		return nil
+	}
+
+	if local.NeedsSync {
+		winner := ConflictRemote
+		if local.UpdatedAt.After(change.Updated) {
+			winner = ConflictLocal
+		}
+		result.Conflicts++
+		if err := e.store.LogSyncConflict(&SyncConflict{
+			UserID:        userID,
+			EventID:       local.ID,
+			Provider:      "google",
+			Title:         local.Title,
+			Winner:        winner,
+			LocalUpdated:  local.UpdatedAt,
+			RemoteUpdated: change.Updated,
+		}); err != nil {
+			e.logger.Warn("Failed to log sync conflict", zap.String("event_id", local.ID), zap.Error(err))
+		}
+		// The local edit is pushed next
+		if winner == ConflictLocal {
+			return nil
+		}
+	}
+
+	if remote.Status == EventStatusCancelled {
+		if local.Status == EventStatusCancelled && !local.NeedsSync {
+			return nil
+		}
+		local.Status = EventStatusCancelled
+		if err := e.store.SaveSyncedEvent(local); err != nil {
+			return err
+		}
+		result.Deleted++
+		return nil
+	}
+
+	remote.ID = local.ID
+	remote.UserID = local.UserID
+	remote.CalendarID = local.CalendarID
+	remote.Source = local.Source
+	remote.Reminders = local.Reminders
+	remote.CreatedAt = local.CreatedAt
+	if err := e.store.SaveSyncedEvent(&remote); err != nil {
+		return err
+	}
+	result.Updated++
+	return nil
+}
+
+// push sends local changes to Google
+func (e *SyncEngine) push(ctx context.Context, client *http.Client, userID string, calendars []Calendar, result *SyncResult) error {
+	events, err := e.store.GetEventsNeedingSync(userID, pushBatch)
+	if err != nil {
+		return fmt.Errorf("failed to load local changes: %w", err)
+	}
+
+	for i := range events {
+		event := &events[i]
+		cal := calendarFor(calendars, event.CalendarID)
+
+		// Birthdays, events in calendars that aren't synced and events
+		// deleted before they were ever pushed stay local
+		if event.Source == "contacts" || cal == nil || !cal.IsWritable ||
+			(event.Status == EventStatusCancelled && event.SourceID == "") {
+			if err := e.store.ClearNeedsSync(event.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := e.pushEvent(ctx, client, cal, event); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", event.Title, err))
+			continue
+		}
+		result.Pushed++
+	}
+	return nil
+}
+
+func (e *SyncEngine) pushEvent(ctx context.Context, client *http.Client, cal *Calendar, event *CalendarEvent) error {
+	if event.Status == EventStatusCancelled {
+		err := e.google.DeleteEvent(ctx, client, cal.ExternalID, event.SourceID)
+		if err != nil && !errors.Is(err, ErrRemoteEventGone) {
+			return err
+		}
+		return e.store.MarkEventSynced(event.ID, event.SourceID)
+	}
+
+	// Google needs a time zone to expand a timed series
+	out := *event
+	if out.IsRecurring && !out.AllDay && out.Timezone == "" {
+		out.Timezone = cal.Timezone
+	}
+
+	if event.SourceID != "" {
+		_, err := e.google.UpdateEvent(ctx, client, cal.ExternalID, event.SourceID, &out)
+		if err == nil {
+			return e.store.MarkEventSynced(event.ID, event.SourceID)
+		}
+		if !errors.Is(err, ErrRemoteEventGone) {
+			return err
+		}
+		// Deleted in Google but edited here since: create it again
+	}
+
+	created, err := e.google.CreateEvent(ctx, client, cal.ExternalID, &out)
+	if err != nil {
+		return err
+	}
+	return e.store.MarkEventSynced(event.ID, created.SourceID)
+}
+
+// calendarFor finds the synced calendar a local event belongs to. Events
+// without a calendar, or in "primary", go to the primary calendar.
+func calendarFor(calendars []Calendar, calendarID string) *Calendar {
+	for i := range calendars {
+		cal := &calendars[i]
+		if cal.ExternalID == calendarID || cal.ID == calendarID ||
+			(cal.IsPrimary && (calendarID == "" || calendarID == "primary")) {
+			return cal
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeGoogle serves the parts of the Google Calendar API the sync engine uses
+type fakeGoogle struct {
+	mu      sync.Mutex
+	pages   map[string][]map[string]interface{} // sync token -> response pages
+	created []googleEvent
+	updated map[string]googleEvent
+	deleted []string
+}
+
+func (f *fakeGoogle) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const events = "/calendars/me@example.com/events"
+	switch {
+	case r.URL.Path == "/users/me/calendarList":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"items": []map[string]interface{}{
+				{"id": "me@example.com", "summary": "Me", "primary": true, "accessRole": "owner", "timeZone": "Europe/Berlin"},
+				{"id": "holidays", "summary": "Holidays", "accessRole": "reader"},
+			},
+		})
+	case r.URL.Path == events && r.Method == http.MethodGet:
+		pages, ok := f.pages[r.URL.Query().Get("syncToken")]
+		if !ok {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		page := 0
+		if r.URL.Query().Get("pageToken") == "page2" {
+			page = 1
+		}
+		json.NewEncoder(w).Encode(pages[page])
+	case r.URL.Path == events && r.Method == http.MethodPost:
+		var ge googleEvent
+		json.NewDecoder(r.Body).Decode(&ge)
+		ge.ID = "g-new"
+		f.created = append(f.created, ge)
+		json.NewEncoder(w).Encode(ge)
+	case strings.HasPrefix(r.URL.Path, events+"/") && r.Method == http.MethodPut:
+		var ge googleEvent
+		json.NewDecoder(r.Body).Decode(&ge)
+		ge.ID = strings.TrimPrefix(r.URL.Path, events+"/")
+		f.updated[ge.ID] = ge
+		json.NewEncoder(w).Encode(ge)
+	case strings.HasPrefix(r.URL.Path, events+"/") && r.Method == http.MethodDelete:
+		f.deleted = append(f.deleted, strings.TrimPrefix(r.URL.Path, events+"/"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func remoteEvent(id, title, status string, updated time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"id":      id,
+		"summary": title,
+		"status":  status,
+		"updated": updated.UTC().Format(time.RFC3339),
+		"start":   map[string]string{"dateTime": "2030-01-10T10:00:00Z"},
+		"end":     map[string]string{"dateTime": "2030-01-10T11:00:00Z"},
+	}
+}
+
+func setupSyncEngine(t *testing.T) (*SyncEngine, *Store, *fakeGoogle) {
+	store, err := NewStore(setupCalendarTestDB(t))
+	require.NoError(t, err)
+
+	fake := &fakeGoogle{updated: map[string]googleEvent{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	google := NewGoogleCalendarProvider(DefaultGoogleCalendarConfig(), zap.NewNop())
+	google.apiBase = server.URL
+
+	require.NoError(t, store.SaveCredentials(&CalendarCredentials{
+		UserID:      "user1",
+		Provider:    "google",
+		AccessToken: "token",
+		TokenExpiry: time.Now().Add(time.Hour),
+		IsActive:    true,
+	}))
+
+	return NewSyncEngine(store, google, zap.NewNop()), store, fake
+}
+
+func TestSyncEngine_PullAndPush(t *testing.T) {
+	engine, store, fake := setupSyncEngine(t)
+	hourAgo := time.Now().Add(-time.Hour)
+
+	fake.pages = map[string][]map[string]interface{}{
+		"": {
+			{"items": []interface{}{remoteEvent("g1", "Standup", "confirmed", hourAgo)}, "nextPageToken": "page2"},
+			{"items": []interface{}{remoteEvent("g2", "Gone", "cancelled", hourAgo)}, "nextSyncToken": "s1"},
+		},
+	}
+
+	local := &CalendarEvent{UserID: "user1", CalendarID: "primary", Title: "Dentist", Source: "manual",
+		StartTime: time.Now().Add(24 * time.Hour), EndTime: time.Now().Add(25 * time.Hour), Status: EventStatusConfirmed}
+	require.NoError(t, store.CreateEvent(local))
+	birthday := &CalendarEvent{UserID: "user1", CalendarID: "primary", Title: "Birthday", Source: "contacts", SourceID: "cont_1",
+		StartTime: time.Now(), EndTime: time.Now().Add(24 * time.Hour), AllDay: true, Status: EventStatusConfirmed}
+	require.NoError(t, store.CreateEvent(birthday))
+
+	result, err := engine.SyncUser(context.Background(), "user1")
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, 1, result.Added)
+	assert.Equal(t, 1, result.Pushed)
+	assert.Equal(t, "s1", result.NextSyncToken)
+
+	// Only the primary calendar is synced, and it remembers its token
+	cal, err := store.GetPrimaryCalendar("user1")
+	require.NoError(t, err)
+	assert.Equal(t, "s1", cal.SyncToken)
+
+	pulled, err := store.GetEventBySourceID("user1", "g1")
+	require.NoError(t, err)
+	require.NotNil(t, pulled)
+	assert.Equal(t, "Standup", pulled.Title)
+	assert.False(t, pulled.NeedsSync)
+
+	// Local event pushed, birthday kept local
+	require.Len(t, fake.created, 1)
+	assert.Equal(t, "Dentist", fake.created[0].Summary)
+	pushed, _ := store.GetEvent(local.ID)
+	assert.Equal(t, "g-new", pushed.SourceID)
+	assert.False(t, pushed.NeedsSync)
+	kept, _ := store.GetEvent(birthday.ID)
+	assert.Equal(t, "cont_1", kept.SourceID)
+	assert.False(t, kept.NeedsSync)
+
+	// Next round: the standup was edited on both sides, the local edit is
+	// newer; the dentist was deleted in Google
+	pulled.Title = "Standup (moved)"
+	require.NoError(t, store.UpdateEvent(pulled))
+	fake.pages["s1"] = []map[string]interface{}{
+		{"items": []interface{}{
+			remoteEvent("g1", "Standup (remote)", "confirmed", hourAgo.Add(30*time.Minute)),
+			remoteEvent("g-new", "Dentist", "cancelled", time.Now()),
+		}, "nextSyncToken": "s2"},
+	}
+
+	result, err = engine.SyncUser(context.Background(), "user1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Conflicts)
+	assert.Equal(t, 1, result.Deleted)
+	assert.Equal(t, 1, result.Pushed)
+	assert.Equal(t, "Standup (moved)", fake.updated["g1"].Summary)
+
+	dentist, _ := store.GetEvent(local.ID)
+	assert.Equal(t, EventStatusCancelled, dentist.Status)
+
+	conflicts, err := store.GetSyncConflicts("user1", 10)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, ConflictLocal, conflicts[0].Winner)
+	assert.Equal(t, pulled.ID, conflicts[0].EventID)
+}
+
+func TestSyncEngine_RemoteWinsAndLocalDelete(t *testing.T) {
+	engine, store, fake := setupSyncEngine(t)
+
+	fake.pages = map[string][]map[string]interface{}{
+		"": {{"items": []interface{}{
+			remoteEvent("g1", "Lunch", "confirmed", time.Now().Add(-time.Hour)),
+			remoteEvent("g2", "Review", "confirmed", time.Now().Add(-time.Hour)),
+		}, "nextSyncToken": "s1"}},
+	}
+	_, err := engine.SyncUser(context.Background(), "user1")
+	require.NoError(t, err)
+
+	lunch, _ := store.GetEventBySourceID("user1", "g1")
+	review, _ := store.GetEventBySourceID("user1", "g2")
+	lunch.Title = "Lunch (local)"
+	require.NoError(t, store.UpdateEvent(lunch))
+	require.NoError(t, store.DeleteEvent(review.ID))
+
+	// The remote edit is newer. An expired token falls back to a full sync.
+	require.NoError(t, store.UpdateSyncToken(mustPrimary(t, store).ID, "expired"))
+	fake.pages[""] = []map[string]interface{}{
+		{"items": []interface{}{remoteEvent("g1", "Lunch (remote)", "confirmed", time.Now().Add(time.Minute))}, "nextSyncToken": "s2"},
+	}
+
+	result, err := engine.SyncUser(context.Background(), "user1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Conflicts)
+	assert.Equal(t, "s2", result.NextSyncToken)
+
+	lunch, _ = store.GetEvent(lunch.ID)
+	assert.Equal(t, "Lunch (remote)", lunch.Title)
+	assert.False(t, lunch.NeedsSync)
+	assert.Empty(t, fake.updated)
+	assert.Equal(t, []string{"g2"}, fake.deleted)
+
+	conflicts, _ := store.GetSyncConflicts("user1", 10)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, ConflictRemote, conflicts[0].Winner)
+}
+
+func mustPrimary(t *testing.T, store *Store) *Calendar {
+	cal, err := store.GetPrimaryCalendar("user1")
+	require.NoError(t, err)
+	require.NotNil(t, cal)
+	return cal
+}
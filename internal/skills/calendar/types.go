@@ -3,6 +3,8 @@ package calendar
 import (
 	"fmt"
 	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/locale"
 )
 
 // CalendarEvent represents a calendar event
@@ -180,10 +182,26 @@ type SyncResult struct {
 	Added       int       `json:"added"`
 	Updated     int       `json:"updated"`
 	Deleted     int       `json:"deleted"`
+	Pushed      int       `json:"pushed"`
+	Conflicts   int       `json:"conflicts"`
 	Errors      []string  `json:"errors,omitempty"`
 	NextSyncToken string  `json:"next_sync_token,omitempty"`
 }
 
+// SyncConflict records an event that changed both locally and in the
+// provider between two syncs, and which side was kept
+type SyncConflict struct {
+	ID            string    `json:"id" gorm:"primaryKey"`
+	UserID        string    `json:"user_id" gorm:"index"`
+	EventID       string    `json:"event_id"`
+	Provider      string    `json:"provider"`
+	Title         string    `json:"title"`
+	Winner        string    `json:"winner"` // local or remote
+	LocalUpdated  time.Time `json:"local_updated"`
+	RemoteUpdated time.Time `json:"remote_updated"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
 // CalendarStats represents calendar statistics
 type CalendarStats struct {
 	TotalEvents      int            `json:"total_events"`
@@ -263,15 +281,20 @@ func (e *CalendarEvent) FormatDuration() string {
 
 // FormatTimeRange formats the event time range
 func (e *CalendarEvent) FormatTimeRange() string {
+	return e.FormatTimeRangeIn(locale.Default())
+}
+
+// FormatTimeRangeIn formats the event time range in the given locale
+func (e *CalendarEvent) FormatTimeRangeIn(l locale.Locale) string {
 	if e.AllDay {
-		return e.StartTime.Format("Mon, Jan 2") + " (All day)"
+		return l.ShortWeekdayDate(e.StartTime) + " (All day)"
 	}
 	
-	startStr := e.StartTime.Format("Mon, Jan 2 3:04 PM")
-	endStr := e.EndTime.Format("3:04 PM")
+	startStr := l.ShortWeekdayDate(e.StartTime) + " " + l.Time(e.StartTime)
+	endStr := l.Time(e.EndTime)
 	
 	if e.StartTime.Day() != e.EndTime.Day() {
-		endStr = e.EndTime.Format("Mon, Jan 2 3:04 PM")
+		endStr = l.ShortWeekdayDate(e.EndTime) + " " + l.Time(e.EndTime)
 	}
 	
 	return startStr + " - " + endStr
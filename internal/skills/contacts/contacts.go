@@ -0,0 +1,650 @@
+package contacts
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/skills/calendar"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// BirthdayCalendar is where birthday events are kept. *calendar.Store
+// implements it.
+type BirthdayCalendar interface {
+	CreateEvent(event *calendar.CalendarEvent) error
+	GetEvent(eventID string) (*calendar.CalendarEvent, error)
+	UpdateEvent(event *calendar.CalendarEvent) error
+	DeleteEvent(eventID string) error
+}
+
+// ContactsSkill keeps track of the people in the user's life: how they are
+// related, their birthdays, how they like to be reached and when the user
+// last got in touch
+type ContactsSkill struct {
+	*skills.BaseSkill
+	store    *Store
+	logger   *zap.Logger
+	calendar BirthdayCalendar
+	scope    skills.ContextHook
+}
+
+// NewContactsSkill creates a new contacts skill
+func NewContactsSkill(db *gorm.DB, logger *zap.Logger) (*ContactsSkill, error) {
+	store, err := NewStore(db)
+	if err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	s := &ContactsSkill{
+		BaseSkill: skills.NewBaseSkill("contacts", "People, relationships, birthdays and keeping in touch", "1.0.0"),
+		store:     store,
+		logger:    logger,
+	}
+	s.registerTools()
+	return s, nil
+}
+
+// SetCalendar adds contacts' birthdays to cal as yearly all-day events.
+// scope gives the context the calendar skill would see, so the events land
+// in the same (possibly shared) calendar; nil keeps the caller's user.
+func (s *ContactsSkill) SetCalendar(cal BirthdayCalendar, scope skills.ContextHook) {
+	s.calendar = cal
+	s.scope = scope
+}
+
+func (s *ContactsSkill) registerTools() {
+	contactFields := map[string]interface{}{
+		"relationship": map[string]interface{}{
+			"type":        "string",
+			"description": "How the user knows them, e.g. sister, friend, colleague",
+		},
+		"birthday": map[string]interface{}{
+			"type":        "string",
+			"description": "Birthday, e.g. 'March 4' or '1990-03-04'; 'none' to remove",
+		},
+		"email": map[string]interface{}{
+			"type":        "string",
+			"description": "Email address",
+		},
+		"phone": map[string]interface{}{
+			"type":        "string",
+			"description": "Phone number",
+		},
+		"preferred_channel": map[string]interface{}{
+			"type":        "string",
+			"enum":        Channels,
+			"description": "How they prefer to be reached",
+		},
+		"reach_out_every_days": map[string]interface{}{
+			"type":        "integer",
+			"description": "Remind the user to get in touch after this many days without contact; 0 to stop",
+		},
+		"notes": map[string]interface{}{
+			"type":        "string",
+			"description": "Notes such as interests, kids' names or gift ideas",
+		},
+	}
+	withFields := func(extra map[string]interface{}) map[string]interface{} {
+		props := make(map[string]interface{}, len(contactFields)+len(extra))
+		for k, v := range contactFields {
+			props[k] = v
+		}
+		for k, v := range extra {
+			props[k] = v
+		}
+		return props
+	}
+	contactRef := map[string]interface{}{
+		"contact_id": map[string]interface{}{
+			"type":        "string",
+			"description": "ID of the contact",
+		},
+		"name": map[string]interface{}{
+			"type":        "string",
+			"description": "Name of the contact, when the ID is not known",
+		},
+	}
+
+	s.AddTool(skills.Tool{
+		Name:        "add_contact",
+		Description: "Save a person: relationship, birthday, contact details, preferred channel, notes and how often to keep in touch. Birthdays are added to the calendar.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": withFields(map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Their name",
+				},
+			}),
+			"required": []string{"name"},
+		},
+		Handler: s.handleAddContact,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "update_contact",
+		Description: "Change a saved person's details",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": withFields(map[string]interface{}{
+				"contact_id": contactRef["contact_id"],
+				"name":       contactRef["name"],
+				"new_name": map[string]interface{}{
+					"type":        "string",
+					"description": "New name for the contact",
+				},
+			}),
+		},
+		Handler: s.handleUpdateContact,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "find_contact",
+		ReadOnly:    true,
+		Description: "Look up people by name, relationship, email, phone or anything in their notes. Without a query, lists everyone.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "What to search for, e.g. 'Sam' or 'sister'",
+				},
+			},
+		},
+		Handler: s.handleFindContact,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "upcoming_birthdays",
+		ReadOnly:    true,
+		Description: "List birthdays coming up, soonest first",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"days": map[string]interface{}{
+					"type":        "integer",
+					"description": "How many days ahead to look (default: 30)",
+				},
+			},
+		},
+		Handler: s.handleUpcomingBirthdays,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "log_contact",
+		Description: "Record that the user got in touch with someone, which resets their keep-in-touch reminder",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"contact_id": contactRef["contact_id"],
+				"name":       contactRef["name"],
+				"note": map[string]interface{}{
+					"type":        "string",
+					"description": "What was talked about, added to their notes",
+				},
+			},
+		},
+		Handler: s.handleLogContact,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "reach_out_suggestions",
+		ReadOnly:    true,
+		Description: "List people the user meant to keep in touch with and hasn't for a while, most overdue first",
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		},
+		Handler: s.handleReachOutSuggestions,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "delete_contact",
+		Description: "Delete a saved person and their birthday event",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"contact_id": contactRef["contact_id"],
+				"name":       contactRef["name"],
+				"confirm": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Confirm deletion",
+				},
+			},
+		},
+		Handler: s.handleDeleteContact,
+	})
+}
+
+func (s *ContactsSkill) handleAddContact(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	name := strings.TrimSpace(stringArg(args, "name"))
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	userID := getUserID(ctx)
+	loc := locale.FromContext(ctx)
+	contact := &Contact{UserID: userID, Name: name}
+	if err := applyFields(contact, args, loc); err != nil {
+		return nil, err
+	}
+	if err := s.store.CreateContact(contact); err != nil {
+		return nil, fmt.Errorf("failed to save contact: %w", err)
+	}
+
+	result := contactResult(contact, loc)
+	result["created"] = true
+	if contact.HasBirthday() {
+		result["birthday_event"] = s.syncBirthdayEvent(ctx, contact)
+	}
+	return result, nil
+}
+
+func (s *ContactsSkill) handleUpdateContact(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := getUserID(ctx)
+	loc := locale.FromContext(ctx)
+	contact, err := s.resolve(userID, args)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *contact
+	if newName := strings.TrimSpace(stringArg(args, "new_name")); newName != "" {
+		contact.Name = newName
+	}
+	if err := applyFields(contact, args, loc); err != nil {
+		return nil, err
+	}
+	if err := s.store.UpdateContact(contact); err != nil {
+		return nil, fmt.Errorf("failed to update contact: %w", err)
+	}
+
+	result := contactResult(contact, loc)
+	result["updated"] = true
+	if birthdayChanged(&before, contact) {
+		result["birthday_event"] = s.syncBirthdayEvent(ctx, contact)
+	}
+	return result, nil
+}
+
+func (s *ContactsSkill) handleFindContact(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := getUserID(ctx)
+	loc := locale.FromContext(ctx)
+	query := strings.TrimSpace(stringArg(args, "query"))
+
+	var found []Contact
+	var err error
+	if query == "" {
+		found, err = s.store.ListContacts(userID)
+	} else {
+		found, err = s.store.FindContacts(userID, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]interface{}, 0, len(found))
+	for i := range found {
+		results = append(results, contactResult(&found[i], loc))
+	}
+	return map[string]interface{}{
+		"query":    query,
+		"contacts": results,
+		"count":    len(results),
+	}, nil
+}
+
+func (s *ContactsSkill) handleUpcomingBirthdays(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	days := 30
+	if d, ok := args["days"].(float64); ok && d > 0 {
+		days = int(d)
+	}
+	if days > 366 {
+		days = 366
+	}
+
+	userID := getUserID(ctx)
+	loc := locale.FromContext(ctx)
+	now := time.Now()
+	upcoming, err := s.store.UpcomingBirthdays(userID, now, days)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	birthdays := make([]map[string]interface{}, 0, len(upcoming))
+	for _, c := range upcoming {
+		next := c.NextBirthday(now)
+		entry := map[string]interface{}{
+			"contact_id": c.ID,
+			"name":       c.Name,
+			"date":       loc.WeekdayDate(next),
+			"in_days":    int(math.Round(next.Sub(today).Hours() / 24)),
+		}
+		if c.Relationship != "" {
+			entry["relationship"] = c.Relationship
+		}
+		if age := c.AgeOn(next); age > 0 {
+			entry["turns"] = age
+		}
+		if c.PreferredChannel != "" {
+			entry["preferred_channel"] = c.PreferredChannel
+		}
+		birthdays = append(birthdays, entry)
+	}
+	return map[string]interface{}{
+		"days":      days,
+		"birthdays": birthdays,
+		"count":     len(birthdays),
+	}, nil
+}
+
+func (s *ContactsSkill) handleLogContact(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := getUserID(ctx)
+	loc := locale.FromContext(ctx)
+	contact, err := s.resolve(userID, args)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	contact.LastContacted = &now
+	contact.ReachOutRemindedAt = nil
+	if note := strings.TrimSpace(stringArg(args, "note")); note != "" {
+		contact.Notes = strings.TrimSpace(contact.Notes + "\n" + loc.Date(now) + ": " + note)
+	}
+	if err := s.store.UpdateContact(contact); err != nil {
+		return nil, fmt.Errorf("failed to update contact: %w", err)
+	}
+
+	result := contactResult(contact, loc)
+	if due, ok := contact.ReachOutDue(); ok {
+		result["next_reach_out"] = loc.Date(due)
+	}
+	result["logged"] = true
+	return result, nil
+}
+
+func (s *ContactsSkill) handleReachOutSuggestions(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := getUserID(ctx)
+	loc := locale.FromContext(ctx)
+	now := time.Now()
+	due, err := s.store.DueToReachOut(userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]map[string]interface{}, 0, len(due))
+	for i := range due {
+		c := &due[i]
+		at, _ := c.ReachOutDue()
+		entry := contactResult(c, loc)
+		entry["overdue_days"] = int(now.Sub(at).Hours() / 24)
+		suggestions = append(suggestions, entry)
+	}
+	return map[string]interface{}{
+		"suggestions": suggestions,
+		"count":       len(suggestions),
+	}, nil
+}
+
+func (s *ContactsSkill) handleDeleteContact(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := getUserID(ctx)
+	contact, err := s.resolve(userID, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if confirm, _ := args["confirm"].(bool); !confirm {
+		return map[string]interface{}{
+			"contact_id":       contact.ID,
+			"name":             contact.Name,
+			"confirm_required": true,
+			"message":          "Set confirm=true to delete this contact",
+		}, nil
+	}
+
+	if contact.BirthdayEventID != "" && s.calendar != nil {
+		if err := s.calendar.DeleteEvent(contact.BirthdayEventID); err != nil {
+			s.logger.Warn("Failed to delete birthday event", zap.String("contact_id", contact.ID), zap.Error(err))
+		}
+	}
+	if err := s.store.DeleteContact(contact.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete contact: %w", err)
+	}
+	return map[string]interface{}{
+		"contact_id": contact.ID,
+		"name":       contact.Name,
+		"deleted":    true,
+	}, nil
+}
+
+// resolve finds the contact named by contact_id or name
+func (s *ContactsSkill) resolve(userID string, args map[string]interface{}) (*Contact, error) {
+	if id := stringArg(args, "contact_id"); id != "" {
+		contact, err := s.store.GetContact(id)
+		if err != nil {
+			return nil, err
+		}
+		if contact == nil || contact.UserID != userID {
+			return nil, fmt.Errorf("contact not found")
+		}
+		return contact, nil
+	}
+
+	name := strings.TrimSpace(stringArg(args, "name"))
+	if name == "" {
+		return nil, fmt.Errorf("contact_id or name is required")
+	}
+	matches, err := s.store.FindContacts(userID, name)
+	if err != nil {
+		return nil, err
+	}
+	for i := range matches {
+		if strings.EqualFold(matches[i].Name, name) {
+			return &matches[i], nil
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no contact matches %q", name)
+	case 1:
+		return &matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.Name
+	}
+	return nil, fmt.Errorf("%q matches several contacts (%s); use contact_id", name, strings.Join(names, ", "))
+}
+
+// syncBirthdayEvent creates, moves or removes the contact's birthday event
+// and reports whether the calendar now matches. Calendar failures are
+// logged rather than failing the contact change.
+func (s *ContactsSkill) syncBirthdayEvent(ctx context.Context, contact *Contact) bool {
+	if s.calendar == nil {
+		return false
+	}
+
+	if !contact.HasBirthday() {
+		if contact.BirthdayEventID == "" {
+			return true
+		}
+		if err := s.calendar.DeleteEvent(contact.BirthdayEventID); err != nil {
+			s.logger.Warn("Failed to delete birthday event", zap.String("contact_id", contact.ID), zap.Error(err))
+			return false
+		}
+		contact.BirthdayEventID = ""
+		return s.saveEventID(contact)
+	}
+
+	var event *calendar.CalendarEvent
+	if contact.BirthdayEventID != "" {
+		existing, err := s.calendar.GetEvent(contact.BirthdayEventID)
+		if err != nil {
+			s.logger.Warn("Failed to load birthday event", zap.String("contact_id", contact.ID), zap.Error(err))
+			return false
+		}
+		event = existing
+	}
+
+	start := contact.NextBirthday(time.Now())
+	if event == nil || event.Status == calendar.EventStatusCancelled {
+		event = &calendar.CalendarEvent{
+			UserID:     s.calendarUserID(ctx),
+			CalendarID: "primary",
+			Status:     calendar.EventStatusConfirmed,
+			Source:     "contacts",
+			SourceID:   contact.ID,
+		}
+	}
+	event.Title = "🎂 " + contact.Name + "'s birthday"
+	event.Description = "Birthday of " + contact.Name
+	if contact.Relationship != "" {
+		event.Description += " (" + contact.Relationship + ")"
+	}
+	event.StartTime = start
+	event.EndTime = start.AddDate(0, 0, 1)
+	event.AllDay = true
+	event.IsRecurring = true
+	event.RecurrenceRule = "RRULE:FREQ=YEARLY"
+
+	var err error
+	if event.ID == "" {
+		err = s.calendar.CreateEvent(event)
+	} else {
+		err = s.calendar.UpdateEvent(event)
+	}
+	if err != nil {
+		s.logger.Warn("Failed to save birthday event", zap.String("contact_id", contact.ID), zap.Error(err))
+		return false
+	}
+	if contact.BirthdayEventID == event.ID {
+		return true
+	}
+	contact.BirthdayEventID = event.ID
+	return s.saveEventID(contact)
+}
+
+func (s *ContactsSkill) saveEventID(contact *Contact) bool {
+	if err := s.store.UpdateContact(contact); err != nil {
+		s.logger.Warn("Failed to save birthday event ID", zap.String("contact_id", contact.ID), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// calendarUserID returns the user whose calendar birthday events go in
+func (s *ContactsSkill) calendarUserID(ctx context.Context) string {
+	if s.scope != nil {
+		ctx = s.scope(ctx, "calendar")
+	}
+	return getUserID(ctx)
+}
+
+// applyFields copies the contact fields present in args onto c
+func applyFields(c *Contact, args map[string]interface{}, loc locale.Locale) error {
+	if v, ok := args["relationship"].(string); ok {
+		c.Relationship = strings.TrimSpace(v)
+	}
+	if v, ok := args["birthday"].(string); ok && strings.TrimSpace(v) != "" {
+		if strings.EqualFold(strings.TrimSpace(v), "none") {
+			c.BirthMonth, c.BirthDay, c.BirthYear = 0, 0, 0
+		} else {
+			month, day, year, err := parseBirthday(v, loc)
+			if err != nil {
+				return err
+			}
+			c.BirthMonth, c.BirthDay, c.BirthYear = month, day, year
+		}
+	}
+	if v, ok := args["email"].(string); ok {
+		c.Email = strings.TrimSpace(v)
+	}
+	if v, ok := args["phone"].(string); ok {
+		c.Phone = strings.TrimSpace(v)
+	}
+	if v, ok := args["preferred_channel"].(string); ok && v != "" {
+		channel := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(v), " ", "_"))
+		if !isChannel(channel) {
+			return fmt.Errorf("unknown channel %q: use one of %s", v, strings.Join(Channels, ", "))
+		}
+		c.PreferredChannel = channel
+	}
+	if v, ok := args["reach_out_every_days"].(float64); ok {
+		if v < 0 {
+			return fmt.Errorf("reach_out_every_days cannot be negative")
+		}
+		c.ReachOutDays = int(v)
+		c.ReachOutRemindedAt = nil
+	}
+	if v, ok := args["notes"].(string); ok {
+		c.Notes = strings.TrimSpace(v)
+	}
+	return nil
+}
+
+func contactResult(c *Contact, loc locale.Locale) map[string]interface{} {
+	result := map[string]interface{}{
+		"contact_id": c.ID,
+		"name":       c.Name,
+		"summary":    c.Summary(loc),
+	}
+	if c.Relationship != "" {
+		result["relationship"] = c.Relationship
+	}
+	if c.HasBirthday() {
+		result["birthday"] = c.FormatBirthday(loc)
+	}
+	if c.Email != "" {
+		result["email"] = c.Email
+	}
+	if c.Phone != "" {
+		result["phone"] = c.Phone
+	}
+	if c.PreferredChannel != "" {
+		result["preferred_channel"] = c.PreferredChannel
+	}
+	if c.ReachOutDays > 0 {
+		result["reach_out_every_days"] = c.ReachOutDays
+	}
+	if c.Notes != "" {
+		result["notes"] = c.Notes
+	}
+	return result
+}
+
+func birthdayChanged(before, after *Contact) bool {
+	return before.BirthMonth != after.BirthMonth || before.BirthDay != after.BirthDay ||
+		before.Name != after.Name || before.Relationship != after.Relationship
+}
+
+func isChannel(channel string) bool {
+	for _, c := range Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+func stringArg(args map[string]interface{}, key string) string {
+	v, _ := args[key].(string)
+	return v
+}
+
+func getUserID(ctx context.Context) string {
+	if userID, ok := ctx.Value("user_id").(string); ok && userID != "" {
+		return userID
+	}
+	return "default_user"
+}
@@ -0,0 +1,253 @@
+package contacts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"github.com/gmsas95/myrai-cli/internal/skills/calendar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupContactsSkill(t *testing.T) (*ContactsSkill, *calendar.Store, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	skill, err := NewContactsSkill(db, nil)
+	require.NoError(t, err)
+	cal, err := calendar.NewStore(db)
+	require.NoError(t, err)
+	skill.SetCalendar(cal, nil)
+	return skill, cal, db
+}
+
+func TestContactsSkill_AddContactCreatesBirthdayEvent(t *testing.T) {
+	skill, cal, _ := setupContactsSkill(t)
+	ctx := context.WithValue(context.Background(), "user_id", "user1")
+
+	result, err := skill.handleAddContact(ctx, map[string]interface{}{
+		"name":              "Maya",
+		"relationship":      "sister",
+		"birthday":          "1990-03-04",
+		"preferred_channel": "whatsapp",
+	})
+	require.NoError(t, err)
+	resultMap := result.(map[string]interface{})
+	assert.Equal(t, true, resultMap["created"])
+	assert.Equal(t, true, resultMap["birthday_event"])
+	assert.Equal(t, "Mar 4, 1990", resultMap["birthday"])
+
+	contact, err := skill.store.GetContact(resultMap["contact_id"].(string))
+	require.NoError(t, err)
+	event, err := cal.GetEvent(contact.BirthdayEventID)
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	assert.Equal(t, "user1", event.UserID)
+	assert.Equal(t, "🎂 Maya's birthday", event.Title)
+	assert.True(t, event.AllDay)
+	assert.True(t, event.IsRecurring)
+	assert.Equal(t, time.March, event.StartTime.Month())
+	assert.Equal(t, 4, event.StartTime.Day())
+
+	// Changing the birthday moves the same event
+	_, err = skill.handleUpdateContact(ctx, map[string]interface{}{"name": "maya", "birthday": "June 9"})
+	require.NoError(t, err)
+	moved, err := cal.GetEvent(contact.BirthdayEventID)
+	require.NoError(t, err)
+	assert.Equal(t, time.June, moved.StartTime.Month())
+
+	// Deleting the contact cancels the event
+	result, err = skill.handleDeleteContact(ctx, map[string]interface{}{"name": "Maya"})
+	require.NoError(t, err)
+	assert.Equal(t, true, result.(map[string]interface{})["confirm_required"])
+	_, err = skill.handleDeleteContact(ctx, map[string]interface{}{"name": "Maya", "confirm": true})
+	require.NoError(t, err)
+	cancelled, err := cal.GetEvent(contact.BirthdayEventID)
+	require.NoError(t, err)
+	assert.Equal(t, calendar.EventStatusCancelled, cancelled.Status)
+}
+
+func TestContactsSkill_FindAndResolve(t *testing.T) {
+	skill, _, _ := setupContactsSkill(t)
+	ctx := context.WithValue(context.Background(), "user_id", "user1")
+
+	for _, args := range []map[string]interface{}{
+		{"name": "Sam Lee", "relationship": "colleague", "notes": "likes climbing"},
+		{"name": "Sam Ortiz", "relationship": "friend"},
+		{"name": "Priya", "relationship": "friend", "email": "priya@example.com"},
+	} {
+		_, err := skill.handleAddContact(ctx, args)
+		require.NoError(t, err)
+	}
+	other := context.WithValue(context.Background(), "user_id", "user2")
+	_, err := skill.handleAddContact(other, map[string]interface{}{"name": "Sam Hidden"})
+	require.NoError(t, err)
+
+	result, err := skill.handleFindContact(ctx, map[string]interface{}{"query": "friend"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.(map[string]interface{})["count"])
+
+	result, err = skill.handleFindContact(ctx, map[string]interface{}{"query": "climbing"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.(map[string]interface{})["count"])
+
+	result, err = skill.handleFindContact(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.(map[string]interface{})["count"])
+
+	_, err = skill.handleLogContact(ctx, map[string]interface{}{"name": "Sam"})
+	assert.ErrorContains(t, err, "several contacts")
+	_, err = skill.handleLogContact(ctx, map[string]interface{}{"name": "Nobody"})
+	assert.Error(t, err)
+	_, err = skill.handleAddContact(ctx, map[string]interface{}{"name": "Bad", "preferred_channel": "pigeon"})
+	assert.Error(t, err)
+}
+
+func TestContactsSkill_ReachOut(t *testing.T) {
+	skill, _, _ := setupContactsSkill(t)
+	ctx := context.WithValue(context.Background(), "user_id", "user1")
+
+	result, err := skill.handleAddContact(ctx, map[string]interface{}{
+		"name":                 "Grandpa",
+		"reach_out_every_days": float64(14),
+	})
+	require.NoError(t, err)
+	contact, err := skill.store.GetContact(result.(map[string]interface{})["contact_id"].(string))
+	require.NoError(t, err)
+
+	result, err = skill.handleReachOutSuggestions(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.(map[string]interface{})["count"])
+
+	// Three weeks without contact
+	contact.CreatedAt = time.Now().AddDate(0, 0, -21)
+	require.NoError(t, skill.store.UpdateContact(contact))
+	result, err = skill.handleReachOutSuggestions(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	suggestions := result.(map[string]interface{})["suggestions"].([]map[string]interface{})
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, 7, suggestions[0]["overdue_days"])
+
+	result, err = skill.handleLogContact(ctx, map[string]interface{}{"name": "grandpa", "note": "talked about the garden"})
+	require.NoError(t, err)
+	assert.Contains(t, result.(map[string]interface{})["notes"], "talked about the garden")
+
+	result, err = skill.handleReachOutSuggestions(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.(map[string]interface{})["count"])
+}
+
+func TestContactsSkill_UpcomingBirthdays(t *testing.T) {
+	skill, _, _ := setupContactsSkill(t)
+	ctx := context.WithValue(context.Background(), "user_id", "user1")
+
+	soon := time.Now().AddDate(0, 0, 3)
+	later := time.Now().AddDate(0, 0, 60)
+	_, err := skill.handleAddContact(ctx, map[string]interface{}{"name": "Later", "birthday": later.Format("2006-01-02")})
+	require.NoError(t, err)
+	_, err = skill.handleAddContact(ctx, map[string]interface{}{"name": "Soon", "birthday": soon.AddDate(-30, 0, 0).Format("2006-01-02")})
+	require.NoError(t, err)
+
+	result, err := skill.handleUpcomingBirthdays(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	birthdays := result.(map[string]interface{})["birthdays"].([]map[string]interface{})
+	require.Len(t, birthdays, 1)
+	assert.Equal(t, "Soon", birthdays[0]["name"])
+	assert.Equal(t, 3, birthdays[0]["in_days"])
+	assert.Equal(t, 30, birthdays[0]["turns"])
+
+	result, err = skill.handleUpcomingBirthdays(ctx, map[string]interface{}{"days": float64(90)})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.(map[string]interface{})["count"])
+}
+
+type recordingNotifier struct {
+	sent []notify.Notification
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, notification notify.Notification) (string, error) {
+	n.sent = append(n.sent, notification)
+	return notify.StatusSent, nil
+}
+
+func TestReminders_Check(t *testing.T) {
+	_, _, db := setupContactsSkill(t)
+	notifier := &recordingNotifier{}
+	reminders, err := NewReminders(db, notifier, nil)
+	require.NoError(t, err)
+
+	now := time.Date(2025, 3, 4, 10, 0, 0, 0, time.Local)
+	last := now.AddDate(0, 0, -40)
+	require.NoError(t, reminders.store.CreateContact(&Contact{
+		UserID: "user1", Name: "Maya", BirthMonth: 3, BirthDay: 4, BirthYear: 1990, PreferredChannel: "phone", Phone: "555-0100",
+	}))
+	require.NoError(t, reminders.store.CreateContact(&Contact{
+		UserID: "user1", Name: "Grandpa", ReachOutDays: 30, LastContacted: &last,
+	}))
+	require.NoError(t, reminders.store.CreateContact(&Contact{
+		UserID: "user1", Name: "Not Yet", BirthMonth: 3, BirthDay: 5, ReachOutDays: 90, LastContacted: &last,
+	}))
+
+	sent, err := reminders.Check(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	require.Len(t, notifier.sent, 2)
+	assert.Equal(t, "contacts", notifier.sent[0].Category)
+	assert.Equal(t, "user1", notifier.sent[0].UserID)
+	titles := []string{notifier.sent[0].Title, notifier.sent[1].Title}
+	assert.Contains(t, titles, "🎂 It's Maya's birthday today")
+	assert.Contains(t, titles, "Time to catch up with Grandpa")
+	for _, n := range notifier.sent {
+		if n.Title == "🎂 It's Maya's birthday today" {
+			assert.Equal(t, "They turn 35. They prefer a call: 555-0100.", n.Body)
+		}
+	}
+
+	// Nothing is repeated an hour later
+	sent, err = reminders.Check(context.Background(), now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+func TestParseBirthday(t *testing.T) {
+	us := locale.Default()
+	tests := []struct {
+		input            string
+		month, day, year int
+	}{
+		{"March 4", 3, 4, 0},
+		{"4 March 1990", 3, 4, 1990},
+		{"1990-03-04", 3, 4, 1990},
+		{"3/4", 3, 4, 0},
+		{"3/4/1990", 3, 4, 1990},
+	}
+	for _, test := range tests {
+		month, day, year, err := parseBirthday(test.input, us)
+		require.NoError(t, err, test.input)
+		assert.Equal(t, []int{test.month, test.day, test.year}, []int{month, day, year}, test.input)
+	}
+
+	gb, err := locale.Apply(us, "language", "en-GB")
+	require.NoError(t, err)
+	month, day, _, err := parseBirthday("3/4", gb)
+	require.NoError(t, err)
+	assert.Equal(t, []int{4, 3}, []int{month, day})
+
+	_, _, _, err = parseBirthday("someday", us)
+	assert.Error(t, err)
+}
+
+func TestContact_NextBirthday(t *testing.T) {
+	c := &Contact{BirthMonth: 2, BirthDay: 29}
+	// 29 February falls on 1 March in common years
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), c.NextBirthday(time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC), c.NextBirthday(time.Date(2028, 2, 1, 0, 0, 0, 0, time.UTC)))
+
+	c = &Contact{BirthMonth: 3, BirthDay: 4}
+	assert.Equal(t, time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC), c.NextBirthday(time.Date(2025, 3, 4, 18, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), c.NextBirthday(time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)))
+}
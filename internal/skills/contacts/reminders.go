@@ -0,0 +1,178 @@
+package contacts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReminderInterval is how often birthdays and keep-in-touch dates are checked
+const ReminderInterval = time.Hour
+
+// Reminders tells users when it is a contact's birthday and when it is time
+// to get back in touch. Each is sent once: birthdays once a year, reach-outs
+// once per overdue period until the user logs the contact.
+type Reminders struct {
+	store    *Store
+	notifier notify.Sender
+	logger   *zap.Logger
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewReminders creates reminders sent through notifier
+func NewReminders(db *gorm.DB, notifier notify.Sender, logger *zap.Logger) (*Reminders, error) {
+	store, err := NewStore(db)
+	if err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Reminders{store: store, notifier: notifier, logger: logger}, nil
+}
+
+// Start checks now and then every ReminderInterval until Stop is called
+func (r *Reminders) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(ReminderInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := r.Check(ctx, time.Now()); err != nil {
+				r.logger.Warn("Contact reminder check failed", zap.Error(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the check loop
+func (r *Reminders) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+		r.wg.Wait()
+	}
+}
+
+// Check sends the reminders due at now and returns how many were sent
+func (r *Reminders) Check(ctx context.Context, now time.Time) (int, error) {
+	candidates, err := r.store.reminderCandidates()
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range candidates {
+		c := &candidates[i]
+		changed := false
+
+		if c.HasBirthday() && c.BirthdayRemindedFor != now.Year() && sameDay(c.NextBirthday(now), now) {
+			if r.send(ctx, c, birthdayNotification(c, now)) {
+				c.BirthdayRemindedFor = now.Year()
+				changed = true
+				sent++
+			}
+		}
+
+		if due, ok := c.ReachOutDue(); ok && !due.After(now) &&
+			(c.ReachOutRemindedAt == nil || c.ReachOutRemindedAt.Before(due)) {
+			if r.send(ctx, c, reachOutNotification(c, now)) {
+				c.ReachOutRemindedAt = &now
+				changed = true
+				sent++
+			}
+		}
+
+		if changed {
+			if err := r.store.UpdateContact(c); err != nil {
+				r.logger.Warn("Failed to record contact reminder", zap.String("contact_id", c.ID), zap.Error(err))
+			}
+		}
+	}
+	return sent, nil
+}
+
+func (r *Reminders) send(ctx context.Context, c *Contact, n notify.Notification) bool {
+	n.UserID = c.UserID
+	n.Category = "contacts"
+	if _, err := r.notifier.Send(ctx, n); err != nil {
+		r.logger.Warn("Failed to send contact reminder",
+			zap.String("contact_id", c.ID),
+			zap.String("title", n.Title),
+			zap.Error(err))
+		return false
+	}
+	return true
+}
+
+func birthdayNotification(c *Contact, now time.Time) notify.Notification {
+	var body []string
+	if age := c.AgeOn(now); age > 0 {
+		body = append(body, fmt.Sprintf("They turn %d.", age))
+	}
+	if hint := channelHint(c); hint != "" {
+		body = append(body, hint)
+	}
+	return notify.Notification{
+		Title: fmt.Sprintf("🎂 It's %s's birthday today", c.Name),
+		Body:  strings.Join(body, " "),
+	}
+}
+
+func reachOutNotification(c *Contact, now time.Time) notify.Notification {
+	var body []string
+	if c.LastContacted != nil {
+		days := int(now.Sub(*c.LastContacted).Hours() / 24)
+		body = append(body, fmt.Sprintf("It's been %d days since you were last in touch.", days))
+	} else {
+		body = append(body, fmt.Sprintf("You wanted to get in touch every %d days.", c.ReachOutDays))
+	}
+	if hint := channelHint(c); hint != "" {
+		body = append(body, hint)
+	}
+	return notify.Notification{
+		Title: "Time to catch up with " + c.Name,
+		Body:  strings.Join(body, " "),
+	}
+}
+
+// channelHint suggests how to reach the contact
+func channelHint(c *Contact) string {
+	switch c.PreferredChannel {
+	case "":
+		return ""
+	case "in_person":
+		return "They'd rather meet in person."
+	case "phone":
+		if c.Phone != "" {
+			return "They prefer a call: " + c.Phone + "."
+		}
+		return "They prefer a call."
+	case "email":
+		if c.Email != "" {
+			return "They prefer email: " + c.Email + "."
+		}
+	}
+	return "They prefer " + c.PreferredChannel + "."
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
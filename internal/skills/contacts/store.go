@@ -0,0 +1,146 @@
+package contacts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"gorm.io/gorm"
+)
+
+// Store handles contact persistence
+type Store struct {
+	db *gorm.DB
+}
+
+func init() {
+	store.RegisterMigrations("contacts", store.Migration{
+		Version: 1,
+		Name:    "create contacts tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Contact{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Contact{})
+		},
+	})
+}
+
+// NewStore creates a new contacts store
+func NewStore(db *gorm.DB) (*Store, error) {
+	if err := store.Migrate(db, "contacts"); err != nil {
+		return nil, fmt.Errorf("failed to migrate contacts schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// CreateContact saves a new contact
+func (s *Store) CreateContact(c *Contact) error {
+	if c.ID == "" {
+		c.ID = idgen.Generate(idgen.PrefixContact)
+	}
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = time.Now()
+	return s.db.Create(c).Error
+}
+
+// GetContact returns a contact by ID, or nil if there is none
+func (s *Store) GetContact(id string) (*Contact, error) {
+	var c Contact
+	err := s.db.Where("id = ?", id).First(&c).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &c, err
+}
+
+// UpdateContact saves changes to a contact
+func (s *Store) UpdateContact(c *Contact) error {
+	c.UpdatedAt = time.Now()
+	return s.db.Save(c).Error
+}
+
+// DeleteContact removes a contact
+func (s *Store) DeleteContact(id string) error {
+	return s.db.Where("id = ?", id).Delete(&Contact{}).Error
+}
+
+// ListContacts returns a user's contacts by name
+func (s *Store) ListContacts(userID string) ([]Contact, error) {
+	var contacts []Contact
+	err := s.db.Where("user_id = ?", userID).Order("name ASC").Find(&contacts).Error
+	return contacts, err
+}
+
+// FindContacts returns a user's contacts whose name, relationship, email,
+// phone or notes contain query
+func (s *Store) FindContacts(userID, query string) ([]Contact, error) {
+	like := "%" + strings.ToLower(strings.TrimSpace(query)) + "%"
+	var contacts []Contact
+	err := s.db.Where("user_id = ?", userID).
+		Where("LOWER(name) LIKE ? OR LOWER(relationship) LIKE ? OR LOWER(email) LIKE ? OR phone LIKE ? OR LOWER(notes) LIKE ?",
+			like, like, like, like, like).
+		Order("name ASC").Find(&contacts).Error
+	return contacts, err
+}
+
+// UpcomingBirthdays returns a user's contacts with a birthday in the next
+// days, soonest first
+func (s *Store) UpcomingBirthdays(userID string, now time.Time, days int) ([]Contact, error) {
+	var all []Contact
+	if err := s.db.Where("user_id = ? AND birth_month > 0", userID).Find(&all).Error; err != nil {
+		return nil, err
+	}
+	return birthdaysWithin(all, now, days), nil
+}
+
+// DueToReachOut returns a user's contacts it is time to get in touch with,
+// most overdue first
+func (s *Store) DueToReachOut(userID string, now time.Time) ([]Contact, error) {
+	var all []Contact
+	if err := s.db.Where("user_id = ? AND reach_out_days > 0", userID).Find(&all).Error; err != nil {
+		return nil, err
+	}
+	return dueWithin(all, now), nil
+}
+
+// reminderCandidates returns every user's contacts that have a birthday or a
+// reach-out interval
+func (s *Store) reminderCandidates() ([]Contact, error) {
+	var all []Contact
+	err := s.db.Where("birth_month > 0 OR reach_out_days > 0").Order("user_id, name").Find(&all).Error
+	return all, err
+}
+
+func birthdaysWithin(contacts []Contact, now time.Time, days int) []Contact {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	limit := today.AddDate(0, 0, days)
+	var upcoming []Contact
+	for _, c := range contacts {
+		if c.HasBirthday() && c.NextBirthday(now).Before(limit) {
+			upcoming = append(upcoming, c)
+		}
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].NextBirthday(now).Before(upcoming[j].NextBirthday(now))
+	})
+	return upcoming
+}
+
+func dueWithin(contacts []Contact, now time.Time) []Contact {
+	var due []Contact
+	for _, c := range contacts {
+		if at, ok := c.ReachOutDue(); ok && !at.After(now) {
+			due = append(due, c)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		a, _ := due[i].ReachOutDue()
+		b, _ := due[j].ReachOutDue()
+		return a.Before(b)
+	})
+	return due
+}
@@ -0,0 +1,149 @@
+package contacts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/locale"
+)
+
+// Channels a contact prefers to be reached on
+var Channels = []string{"phone", "sms", "whatsapp", "telegram", "discord", "email", "in_person"}
+
+// Contact is a person the user knows
+type Contact struct {
+	ID           string `json:"id" gorm:"primaryKey"`
+	UserID       string `json:"user_id" gorm:"index"`
+	Name         string `json:"name" gorm:"index"`
+	Relationship string `json:"relationship,omitempty"` // e.g. sister, friend, colleague
+
+	// Birthday; year is 0 when unknown
+	BirthMonth int `json:"birth_month,omitempty"`
+	BirthDay   int `json:"birth_day,omitempty"`
+	BirthYear  int `json:"birth_year,omitempty"`
+
+	// Reaching out
+	Email            string     `json:"email,omitempty"`
+	Phone            string     `json:"phone,omitempty"`
+	PreferredChannel string     `json:"preferred_channel,omitempty"`
+	ReachOutDays     int        `json:"reach_out_days,omitempty"` // remind after this many days without contact; 0 for never
+	LastContacted    *time.Time `json:"last_contacted,omitempty"`
+
+	Notes string `json:"notes,omitempty" gorm:"type:text"`
+
+	// Reminder and calendar tracking
+	BirthdayEventID     string     `json:"birthday_event_id,omitempty"`
+	BirthdayRemindedFor int        `json:"-"` // year of the last birthday reminder
+	ReachOutRemindedAt  *time.Time `json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName sets the table name
+func (Contact) TableName() string { return "contacts" }
+
+// HasBirthday reports whether the birthday is known
+func (c *Contact) HasBirthday() bool {
+	return c.BirthMonth > 0 && c.BirthDay > 0
+}
+
+// NextBirthday returns the start of the contact's next birthday on or after
+// now's day. Birthdays on 29 February fall on 1 March in other years.
+func (c *Contact) NextBirthday(now time.Time) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	next := birthdayIn(c.BirthMonth, c.BirthDay, now.Year(), now.Location())
+	if next.Before(today) {
+		next = birthdayIn(c.BirthMonth, c.BirthDay, now.Year()+1, now.Location())
+	}
+	return next
+}
+
+// AgeOn returns the age the contact turns on their birthday in t's year, or
+// 0 when the birth year is unknown
+func (c *Contact) AgeOn(t time.Time) int {
+	if c.BirthYear == 0 {
+		return 0
+	}
+	return t.Year() - c.BirthYear
+}
+
+// FormatBirthday formats the birthday in the locale, e.g. "Mar 4" or
+// "4 Mar 1990"
+func (c *Contact) FormatBirthday(l locale.Locale) string {
+	if !c.HasBirthday() {
+		return ""
+	}
+	if c.BirthYear == 0 {
+		return l.ShortDate(time.Date(2000, time.Month(c.BirthMonth), c.BirthDay, 0, 0, 0, 0, time.UTC))
+	}
+	return l.Date(time.Date(c.BirthYear, time.Month(c.BirthMonth), c.BirthDay, 0, 0, 0, 0, time.UTC))
+}
+
+// ReachOutDue returns when the user should next get in touch, and whether a
+// reminder applies at all
+func (c *Contact) ReachOutDue() (time.Time, bool) {
+	if c.ReachOutDays <= 0 {
+		return time.Time{}, false
+	}
+	since := c.CreatedAt
+	if c.LastContacted != nil {
+		since = *c.LastContacted
+	}
+	return since.AddDate(0, 0, c.ReachOutDays), true
+}
+
+// Summary describes the contact in one line
+func (c *Contact) Summary(l locale.Locale) string {
+	parts := []string{c.Name}
+	if c.Relationship != "" {
+		parts[0] += " (" + c.Relationship + ")"
+	}
+	if c.HasBirthday() {
+		parts = append(parts, "birthday "+c.FormatBirthday(l))
+	}
+	if c.PreferredChannel != "" {
+		parts = append(parts, "prefers "+strings.ReplaceAll(c.PreferredChannel, "_", " "))
+	}
+	if c.LastContacted != nil {
+		parts = append(parts, "last in touch "+l.Date(*c.LastContacted))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func birthdayIn(month, day, year int, loc *time.Location) time.Time {
+	// time.Date normalizes 29 February in a common year to 1 March
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+}
+
+// parseBirthday reads a birthday such as "March 4", "4 March 1990",
+// "1990-03-04" or a numeric date in the locale's order, with or without the
+// year
+func parseBirthday(s string, l locale.Locale) (month, day, year int, err error) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "."))
+	if s == "" {
+		return 0, 0, 0, fmt.Errorf("birthday is empty")
+	}
+
+	withYear := append([]string{"January 2, 2006", "January 2 2006", "Jan 2, 2006", "Jan 2 2006",
+		"2 January 2006", "2 Jan 2006"}, l.NumericDateLayouts()...)
+	for _, layout := range withYear {
+		if t, err := time.Parse(layout, s); err == nil {
+			return int(t.Month()), t.Day(), t.Year(), nil
+		}
+	}
+
+	withoutYear := []string{"January 2", "Jan 2", "2 January", "2 Jan", "01-02"}
+	if l.DayFirst() {
+		withoutYear = append(withoutYear, "2/1", "02/01", "2.1")
+	} else {
+		withoutYear = append(withoutYear, "1/2", "01/02")
+	}
+	for _, layout := range withoutYear {
+		if t, err := time.Parse(layout, s); err == nil {
+			return int(t.Month()), t.Day(), 0, nil
+		}
+	}
+	return 0, 0, 0, fmt.Errorf("could not read birthday %q: use e.g. March 4 or 1990-03-04", s)
+}
@@ -67,6 +67,7 @@ func (s *DaunSkill) registerTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "daun_search_posts",
+		ReadOnly:    true,
 		Description: "Search posts on Daun.me by query or username",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -95,6 +96,7 @@ func (s *DaunSkill) registerTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "daun_get_feed",
+		ReadOnly:    true,
 		Description: "Get timeline feed from Daun.me (following or global)",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -120,6 +122,7 @@ func (s *DaunSkill) registerTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "daun_get_user",
+		ReadOnly:    true,
 		Description: "Get user profile information from Daun.me",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -0,0 +1,434 @@
+// Package declarative loads skills defined in YAML files rather than Go:
+// a prompt and tools that each make one HTTP request and pick the answer out
+// of the response. They suit integrations that are a few REST calls.
+//
+//	name: jira
+//	description: Jira issues
+//	prompt: Use the jira tools when the user mentions a ticket like ABC-123.
+//	base_url: https://example.atlassian.net/rest/api/3
+//	headers:
+//	  Authorization: Bearer ${JIRA_TOKEN}
+//	tools:
+//	  - name: jira_get_issue
+//	    description: Get a Jira issue
+//	    parameters:
+//	      - name: key
+//	        type: string
+//	        required: true
+//	        description: Issue key, e.g. ABC-123
+//	    request:
+//	      method: GET
+//	      url: /issue/{{key}}
+//	    response:
+//	      fields:
+//	        summary: .fields.summary
+//	        status: .fields.status.name
+//
+// {{param}} is replaced by a tool argument and ${NAME} by a secret or
+// environment variable. Secrets are only taken from the definition, never
+// from arguments.
+package declarative
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/circuitbreaker"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/gmsas95/myrai-cli/internal/strutil"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// defaultTimeout bounds a tool's request unless it sets its own
+	defaultTimeout = 30 * time.Second
+	// maxResponseBytes is how much of a response is read
+	maxResponseBytes = 1 << 20
+	// maxTextChars is how much of a response that isn't JSON is returned
+	maxTextChars = 20000
+)
+
+// Definition is a skill as written in YAML
+type Definition struct {
+	Name        string `yaml:"name"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description"`
+	// Prompt is added to the system prompt while the skill's tools are offered
+	Prompt string `yaml:"prompt"`
+	// BaseURL is prepended to tool URLs that start with '/'
+	BaseURL string `yaml:"base_url"`
+	// Headers are sent with every request, under the tool's own
+	Headers map[string]string `yaml:"headers"`
+	Tools   []ToolDefinition  `yaml:"tools"`
+}
+
+// ToolDefinition is a tool that makes one HTTP request
+type ToolDefinition struct {
+	skills.ManifestTool `yaml:",inline"`
+	Request             Request  `yaml:"request"`
+	Response            Response `yaml:"response"`
+}
+
+// Request is the template of a tool's HTTP request
+type Request struct {
+	Method  string            `yaml:"method"`
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+	// Query parameters that expand to "" are left out
+	Query map[string]string `yaml:"query"`
+	// Body is sent as JSON. A string that is just {{param}} becomes the
+	// argument as given, keeping its type; keys whose argument is missing
+	// are left out. A plain string body is sent as text.
+	Body           interface{} `yaml:"body"`
+	TimeoutSeconds int         `yaml:"timeout_seconds"`
+}
+
+// Response picks what the tool returns out of a JSON response, with
+// JQ-style paths like .items[].name. Without either, the whole response is
+// returned.
+type Response struct {
+	Extract string            `yaml:"extract"`
+	Fields  map[string]string `yaml:"fields"`
+}
+
+// Skill is a skill loaded from a definition
+type Skill struct {
+	*skills.BaseSkill
+	def    *Definition
+	client *circuitbreaker.HTTPClient
+}
+
+// Load reads every *.yaml and *.yml definition in dir. A definition that
+// can't be loaded is logged and skipped.
+func Load(dir string, logger *zap.Logger) []*Skill {
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	var loaded []*Skill
+	for _, path := range paths {
+		skill, err := LoadFile(path, logger)
+		if err != nil {
+			logger.Warn("Failed to load skill definition", zap.String("file", path), zap.Error(err))
+			continue
+		}
+		loaded = append(loaded, skill)
+	}
+	return loaded
+}
+
+// LoadFile reads a definition
+func LoadFile(path string, logger *zap.Logger) (*Skill, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var def Definition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	return New(&def, logger)
+}
+
+// New checks a definition and creates its skill
+func New(def *Definition, logger *zap.Logger) (*Skill, error) {
+	if def.Version == "" {
+		def.Version = "1.0.0"
+	}
+	manifest := &skills.SkillManifest{Name: def.Name, Version: def.Version, Description: def.Description}
+	for _, tool := range def.Tools {
+		manifest.Tools = append(manifest.Tools, tool.ManifestTool)
+	}
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Skill{
+		BaseSkill: skills.NewBaseSkill(def.Name, def.Description, def.Version),
+		def:       def,
+		client:    circuitbreaker.NewHTTPClient("skill-"+def.Name, 0, logger),
+	}
+	for i := range def.Tools {
+		tool := &def.Tools[i]
+		if tool.Command != "" {
+			return nil, fmt.Errorf("tool %s: declarative tools make requests, they can't run commands", tool.Name)
+		}
+		tool.Request.Method = strings.ToUpper(tool.Request.Method)
+		if tool.Request.Method == "" {
+			tool.Request.Method = http.MethodGet
+		}
+		switch tool.Request.Method {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead:
+		default:
+			return nil, fmt.Errorf("tool %s: unsupported method %s", tool.Name, tool.Request.Method)
+		}
+		if tool.Request.URL == "" {
+			return nil, fmt.Errorf("tool %s: request url is required", tool.Name)
+		}
+		for _, path := range append([]string{tool.Response.Extract}, mapValues(tool.Response.Fields)...) {
+			if _, err := parsePath(path); path != "" && err != nil {
+				return nil, fmt.Errorf("tool %s: %w", tool.Name, err)
+			}
+		}
+
+		s.AddTool(skills.Tool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  tool.ToJSONSchema(),
+			Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				return s.call(ctx, tool, args)
+			},
+		})
+	}
+	return s, nil
+}
+
+// Prompt returns the skill's instructions for the model
+func (s *Skill) Prompt() string {
+	return strings.TrimSpace(s.def.Prompt)
+}
+
+// call makes a tool's request and maps its response
+func (s *Skill) call(ctx context.Context, tool *ToolDefinition, args map[string]interface{}) (interface{}, error) {
+	for _, param := range tool.Parameters {
+		if _, ok := args[param.Name]; param.Required && !ok {
+			return nil, fmt.Errorf("%s is required", param.Name)
+		}
+	}
+
+	req, err := s.buildRequest(ctx, tool, args)
+	if err != nil {
+		return nil, err
+	}
+	timeout := defaultTimeout
+	if tool.Request.TimeoutSeconds > 0 {
+		timeout = time.Duration(tool.Request.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil && resp == nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, strutil.Truncate(strings.TrimSpace(string(body)), 500))
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		// Not JSON; there's nothing to extract from
+		return strutil.Truncate(string(body), maxTextChars), nil
+	}
+	return mapResponse(data, tool.Response)
+}
+
+// buildRequest expands a tool's request template with args
+func (s *Skill) buildRequest(ctx context.Context, tool *ToolDefinition, args map[string]interface{}) (*http.Request, error) {
+	rawURL := tool.Request.URL
+	if strings.HasPrefix(rawURL, "/") && s.def.BaseURL != "" {
+		rawURL = strings.TrimSuffix(s.def.BaseURL, "/") + rawURL
+	}
+	target, err := expand(rawURL, args, url.PathEscape)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid request url: %s", rawURL)
+	}
+
+	query := u.Query()
+	for key, tmpl := range tool.Request.Query {
+		value, err := expand(tmpl, args, nil)
+		if err != nil {
+			return nil, err
+		}
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	var body io.Reader
+	contentType := ""
+	switch b := tool.Request.Body.(type) {
+	case nil:
+	case string:
+		text, err := expand(b, args, nil)
+		if err != nil {
+			return nil, err
+		}
+		body, contentType = strings.NewReader(text), "text/plain; charset=utf-8"
+	default:
+		value, _, err := expandValue(b, args)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		body, contentType = bytes.NewReader(data), "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, tool.Request.Method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("User-Agent", "myrai-skill/"+s.def.Name)
+	for _, headers := range []map[string]string{s.def.Headers, tool.Request.Headers} {
+		for key, tmpl := range headers {
+			value, err := expand(tmpl, args, stripNewlines)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set(key, value)
+		}
+	}
+	return req, nil
+}
+
+// placeholder matches {{param}} and ${SECRET}, or ${secret:SECRET}
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}|\$\{(?:secret:)?([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expand replaces the placeholders in tmpl. Arguments go through escape,
+// if given; replaced text isn't expanded again, so an argument can't name a
+// secret.
+func expand(tmpl string, args map[string]interface{}, escape func(string) string) (string, error) {
+	var missing string
+	out := placeholder.ReplaceAllStringFunc(tmpl, func(match string) string {
+		m := placeholder.FindStringSubmatch(match)
+		if m[2] != "" {
+			value, ok := os.LookupEnv(m[2])
+			if !ok && missing == "" {
+				missing = m[2]
+			}
+			return value
+		}
+		value := formatArg(args[m[1]])
+		if escape != nil {
+			value = escape(value)
+		}
+		return value
+	})
+	if missing != "" {
+		return "", fmt.Errorf("secret %s is not set; add it with 'myrai secret set %s'", missing, missing)
+	}
+	return out, nil
+}
+
+// wholeArg matches a string that is only an argument placeholder
+var wholeArg = regexp.MustCompile(`^\{\{\s*([A-Za-z0-9_]+)\s*\}\}$`)
+
+// expandValue expands a body template. ok is false for a placeholder whose
+// argument is missing, so its key can be left out.
+func expandValue(v interface{}, args map[string]interface{}) (interface{}, bool, error) {
+	switch t := v.(type) {
+	case string:
+		if m := wholeArg.FindStringSubmatch(t); m != nil {
+			arg, ok := args[m[1]]
+			return arg, ok, nil
+		}
+		s, err := expand(t, args, nil)
+		return s, true, err
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for key, value := range t {
+			expanded, ok, err := expandValue(value, args)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				out[key] = expanded
+			}
+		}
+		return out, true, nil
+	case []interface{}:
+		out := make([]interface{}, 0, len(t))
+		for _, value := range t {
+			expanded, ok, err := expandValue(value, args)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				out = append(out, expanded)
+			}
+		}
+		return out, true, nil
+	}
+	return v, true, nil
+}
+
+// mapResponse applies a tool's response mapping
+func mapResponse(data interface{}, r Response) (interface{}, error) {
+	if len(r.Fields) > 0 {
+		out := make(map[string]interface{}, len(r.Fields))
+		for name, path := range r.Fields {
+			value, err := Extract(data, path)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			out[name] = value
+		}
+		return out, nil
+	}
+	if r.Extract != "" {
+		return Extract(data, r.Extract)
+	}
+	return data, nil
+}
+
+// formatArg formats an argument for a URL, query or header
+func formatArg(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func stripNewlines(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+func mapValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
@@ -0,0 +1,186 @@
+package declarative
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+const testDefinition = `
+name: tracker
+description: Issue tracker
+prompt: Use the tracker tools for ticket numbers.
+base_url: %s
+headers:
+  Authorization: Bearer ${TRACKER_TEST_TOKEN}
+tools:
+  - name: tracker_get_issue
+    description: Get an issue
+    parameters:
+      - name: key
+        type: string
+        required: true
+        description: Issue key
+      - name: fields
+        type: string
+        description: Fields to return
+    request:
+      url: /issue/{{key}}
+      query:
+        fields: "{{fields}}"
+    response:
+      fields:
+        summary: .fields.summary
+        labels: .fields.labels[].name
+  - name: tracker_create_issue
+    description: Create an issue
+    parameters:
+      - name: summary
+        type: string
+        required: true
+        description: Summary
+      - name: priority
+        type: integer
+        description: Priority
+    request:
+      method: post
+      url: /issue
+      body:
+        fields:
+          summary: "{{summary}}"
+          priority: "{{priority}}"
+          note: "Filed by ${TRACKER_TEST_USER}"
+    response:
+      extract: .key
+`
+
+func TestDeclarativeSkill(t *testing.T) {
+	t.Setenv("TRACKER_TEST_TOKEN", "secret-token")
+	t.Setenv("TRACKER_TEST_USER", "myrai")
+
+	var lastBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/issue/ABC 1":
+			if r.URL.Query().Has("fields") {
+				t.Error("Expected an empty query parameter to be left out")
+			}
+			io.WriteString(w, `{"key": "ABC 1", "fields": {"summary": "Broken", "labels": [{"name": "bug"}, {"name": "ui"}]}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/issue":
+			json.NewDecoder(r.Body).Decode(&lastBody)
+			io.WriteString(w, `{"key": "ABC-2"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "tracker.yaml")
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(testDefinition, server.URL)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded := Load(filepath.Dir(path), zap.NewNop())
+	if len(loaded) != 1 {
+		t.Fatalf("Expected one skill, got %d", len(loaded))
+	}
+	skill := loaded[0]
+	if skill.Name() != "tracker" || skill.Prompt() != "Use the tracker tools for ticket numbers." || len(skill.Tools()) != 2 {
+		t.Fatalf("Unexpected skill: %s, %q, %d tools", skill.Name(), skill.Prompt(), len(skill.Tools()))
+	}
+
+	ctx := context.Background()
+	get, create := skill.Tools()[0], skill.Tools()[1]
+
+	result, err := get.Handler(ctx, map[string]interface{}{"key": "ABC 1"})
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	want := map[string]interface{}{"summary": "Broken", "labels": []interface{}{"bug", "ui"}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Unexpected result: %#v", result)
+	}
+
+	if _, err := get.Handler(ctx, map[string]interface{}{}); err == nil {
+		t.Error("Expected a missing required argument to fail")
+	}
+
+	result, err = create.Handler(ctx, map[string]interface{}{"summary": "Crash ${TRACKER_TEST_TOKEN}"})
+	if err != nil || result != "ABC-2" {
+		t.Fatalf("Failed to create issue: %v, %v", result, err)
+	}
+	fields, _ := lastBody["fields"].(map[string]interface{})
+	if fields["summary"] != "Crash ${TRACKER_TEST_TOKEN}" {
+		t.Errorf("Expected arguments not to expand secrets, got %v", fields["summary"])
+	}
+	if _, ok := fields["priority"]; ok {
+		t.Error("Expected a missing optional argument to be left out of the body")
+	}
+	if fields["note"] != "Filed by myrai" {
+		t.Errorf("Expected the secret in the body template, got %v", fields["note"])
+	}
+
+	os.Unsetenv("TRACKER_TEST_TOKEN")
+	if _, err := get.Handler(ctx, map[string]interface{}{"key": "ABC 1"}); err == nil {
+		t.Error("Expected a missing secret to fail")
+	}
+}
+
+func TestLoadRejectsInvalidDefinitions(t *testing.T) {
+	tests := map[string]string{
+		"no-name.yaml":  "description: x\ntools: []\n",
+		"bad-path.yaml": "name: a\ndescription: x\ntools:\n  - name: t\n    description: t\n    request: {url: 'https://example.com'}\n    response: {extract: 'items'}\n",
+		"method.yaml":   "name: b\ndescription: x\ntools:\n  - name: t\n    description: t\n    request: {method: TRACE, url: 'https://example.com'}\n",
+		"command.yaml":  "name: c\ndescription: x\ntools:\n  - name: t\n    description: t\n    command: run.sh\n    request: {url: 'https://example.com'}\n",
+	}
+	dir := t.TempDir()
+	for name, content := range tests {
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+	if loaded := Load(dir, zap.NewNop()); len(loaded) != 0 {
+		t.Errorf("Expected every definition to be rejected, got %d", len(loaded))
+	}
+}
+
+func TestExtract(t *testing.T) {
+	var data interface{}
+	json.Unmarshal([]byte(`{"items": [{"name": "a", "tags": ["x"]}, {"name": "b", "tags": []}], "total": 2, "odd key": true}`), &data)
+
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{".", data},
+		{".total", 2.0},
+		{".items[0].name", "a"},
+		{".items[-1].name", "b"},
+		{".items[].name", []interface{}{"a", "b"}},
+		{".items[5]", nil},
+		{".missing.deeper", nil},
+		{`."odd key"`, true},
+	}
+	for _, tt := range tests {
+		got, err := Extract(data, tt.path)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Extract(%q) = %#v, %v; want %#v", tt.path, got, err, tt.want)
+		}
+	}
+
+	for _, path := range []string{"items", ".items[", ".total.x", ".items..name"} {
+		if _, err := Extract(data, path); err == nil {
+			t.Errorf("Expected an error for %q", path)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package declarative
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathStep is one step of a path: a field, an index or every element
+type pathStep struct {
+	field   string
+	index   int
+	isIndex bool
+	iterate bool
+}
+
+// Extract returns the value at a JQ-style path in decoded JSON: "." is the
+// whole value, .a.b a field, .a[0] an element (negative counts from the
+// end), .a[] every element, and ."a b" a field with any name. Missing
+// fields are null, as in jq.
+func Extract(data interface{}, path string) (interface{}, error) {
+	steps, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return walk(data, steps)
+}
+
+func walk(v interface{}, steps []pathStep) (interface{}, error) {
+	for i, step := range steps {
+		switch {
+		case step.iterate:
+			items, ok := v.([]interface{})
+			if !ok {
+				if v == nil {
+					return nil, nil
+				}
+				return nil, fmt.Errorf("cannot iterate over %s", typeName(v))
+			}
+			out := make([]interface{}, 0, len(items))
+			for _, item := range items {
+				value, err := walk(item, steps[i+1:])
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, value)
+			}
+			return out, nil
+
+		case step.isIndex:
+			items, ok := v.([]interface{})
+			if !ok {
+				if v == nil {
+					return nil, nil
+				}
+				return nil, fmt.Errorf("cannot index %s with a number", typeName(v))
+			}
+			idx := step.index
+			if idx < 0 {
+				idx += len(items)
+			}
+			if idx < 0 || idx >= len(items) {
+				v = nil
+			} else {
+				v = items[idx]
+			}
+
+		default:
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				if v == nil {
+					return nil, nil
+				}
+				return nil, fmt.Errorf("cannot get field %q of %s", step.field, typeName(v))
+			}
+			v = obj[step.field]
+		}
+	}
+	return v, nil
+}
+
+// parsePath splits a path into steps
+func parsePath(path string) ([]pathStep, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, ".") {
+		return nil, fmt.Errorf("invalid path %q: must start with '.'", path)
+	}
+
+	var steps []pathStep
+	rest := path
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unclosed '['", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			if inner == "" {
+				steps = append(steps, pathStep{iterate: true})
+			} else {
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid path %q: bad index %q", path, inner)
+				}
+				steps = append(steps, pathStep{index: n, isIndex: true})
+			}
+			rest = rest[end+1:]
+
+		case strings.HasPrefix(rest, `."`):
+			end := strings.Index(rest[2:], `"`)
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unclosed quote", path)
+			}
+			steps = append(steps, pathStep{field: rest[2 : 2+end]})
+			rest = rest[3+end:]
+
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if field := rest[:end]; field != "" {
+				steps = append(steps, pathStep{field: field})
+			} else if len(steps) > 0 || (rest != "" && rest[0] == '.') {
+				return nil, fmt.Errorf("invalid path %q: empty field", path)
+			}
+			rest = rest[end:]
+
+		default:
+			return nil, fmt.Errorf("invalid path %q at %q", path, rest)
+		}
+	}
+	return steps, nil
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	}
+	return "null"
+}
@@ -0,0 +1,130 @@
+package devices
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/gmsas95/myrai-cli/internal/mqtt"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+)
+
+// DevicesSkill reads and controls devices over MQTT
+type DevicesSkill struct {
+	*skills.BaseSkill
+	bridge *mqtt.Bridge
+}
+
+// NewDevicesSkill creates a new devices skill publishing through bridge
+func NewDevicesSkill(bridge *mqtt.Bridge) *DevicesSkill {
+	s := &DevicesSkill{
+		BaseSkill: skills.NewBaseSkill("devices", "Read and control devices over MQTT", "1.0.0"),
+		bridge:    bridge,
+	}
+	s.registerTools()
+	return s
+}
+
+func (s *DevicesSkill) registerTools() {
+	allowed := "none configured"
+	if topics := s.bridge.PublishTopics(); len(topics) > 0 {
+		allowed = strings.Join(topics, ", ")
+	}
+
+	s.AddTool(skills.Tool{
+		Name: "mqtt_publish",
+		Description: "Publish an MQTT message to control a device, e.g. turn on a light or pause a printer. " +
+			"Allowed topics: " + allowed,
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"topic": map[string]interface{}{
+					"type":        "string",
+					"description": "Topic to publish to",
+				},
+				"payload": map[string]interface{}{
+					"type":        "string",
+					"description": "Message payload, e.g. ON or a JSON object",
+				},
+				"retain": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Ask the broker to keep the message for new subscribers",
+				},
+				"qos": map[string]interface{}{
+					"type":        "integer",
+					"enum":        []int{0, 1},
+					"description": "Quality of service (default: 0)",
+				},
+			},
+			"required": []string{"topic", "payload"},
+		},
+		Handler: s.handlePublish,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:     "mqtt_last_messages",
+		ReadOnly: true,
+		Description: "Get the last message received on each subscribed MQTT topic, " +
+			"e.g. whether the door is open or how far along a print is",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"topic": map[string]interface{}{
+					"type":        "string",
+					"description": "Only topics matching this filter; + and # wildcards allowed",
+				},
+			},
+		},
+		Handler: s.handleLastMessages,
+	})
+}
+
+func (s *DevicesSkill) handlePublish(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	topic, _ := args["topic"].(string)
+	payload, _ := args["payload"].(string)
+	retain, _ := args["retain"].(bool)
+	qos := 0
+	if q, ok := args["qos"].(float64); ok {
+		qos = int(q)
+	}
+	if qos < 0 || qos > 1 {
+		return nil, fmt.Errorf("qos must be 0 or 1")
+	}
+
+	topic = strings.TrimSpace(topic)
+	if err := s.bridge.Publish(topic, []byte(payload), byte(qos), retain); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"published": true,
+		"topic":     topic,
+		"message":   fmt.Sprintf("Published %q to %s", payload, topic),
+	}, nil
+}
+
+func (s *DevicesSkill) handleLastMessages(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	filter, _ := args["topic"].(string)
+	filter = strings.TrimSpace(filter)
+	if filter != "" {
+		if err := mqtt.ValidateFilter(filter); err != nil {
+			return nil, err
+		}
+	}
+
+	l := locale.FromContext(ctx)
+	messages := s.bridge.Latest(filter)
+	result := make([]map[string]interface{}, 0, len(messages))
+	for _, m := range messages {
+		result = append(result, map[string]interface{}{
+			"topic":       m.Topic,
+			"event":       m.Event,
+			"payload":     m.Payload,
+			"received_at": l.DateTime(m.ReceivedAt),
+		})
+	}
+	return map[string]interface{}{
+		"count":    len(result),
+		"messages": result,
+	}, nil
+}
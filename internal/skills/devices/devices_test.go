@@ -0,0 +1,47 @@
+package devices
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/mqtt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDevicesSkill(t *testing.T) {
+	bridge, err := mqtt.NewBridge(config.MQTTConfig{
+		Broker:        "tcp://localhost:1883",
+		PublishTopics: []string{"home/lights/#"},
+	}, nil, nil)
+	require.NoError(t, err)
+	skill := NewDevicesSkill(bridge)
+	ctx := context.Background()
+
+	tool, ok := findTool(skill, "mqtt_publish")
+	require.True(t, ok)
+	assert.Contains(t, tool, "home/lights/#")
+
+	_, err = skill.handlePublish(ctx, map[string]interface{}{"topic": "garage/door/set", "payload": "OPEN"})
+	assert.ErrorContains(t, err, "not allowed")
+	_, err = skill.handlePublish(ctx, map[string]interface{}{"topic": "home/lights/porch", "payload": "ON", "qos": float64(2)})
+	assert.ErrorContains(t, err, "qos")
+	_, err = skill.handlePublish(ctx, map[string]interface{}{"topic": "home/lights/porch", "payload": "ON"})
+	assert.ErrorContains(t, err, "not connected")
+
+	result, err := skill.handleLastMessages(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.(map[string]interface{})["count"])
+	_, err = skill.handleLastMessages(ctx, map[string]interface{}{"topic": "home/#/x"})
+	assert.Error(t, err)
+}
+
+func findTool(skill *DevicesSkill, name string) (string, bool) {
+	for _, tool := range skill.Tools() {
+		if tool.Name == name {
+			return tool.Description, true
+		}
+	}
+	return "", false
+}
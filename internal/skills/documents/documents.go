@@ -6,9 +6,12 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/security"
 	"github.com/gmsas95/myrai-cli/internal/skills"
 )
 
@@ -23,6 +26,12 @@ type DocumentSkill struct {
 	ocrProcessor    OCRProcessor
 	visionProcessor VisionProcessor
 	
+	// Files the tools may read
+	files *security.FilePolicy
+
+	// Keeps OCR results; the knowledge base
+	archiver Archiver
+	
 	mu      sync.RWMutex
 	isReady bool
 }
@@ -50,6 +59,16 @@ type Config struct {
 	MaxFileSize     int64  // bytes
 	MaxPages        int    // for PDFs
 	MaxImageSize    int    // max dimension in pixels
+
+	// OCR of scans
+	OCRLanguages []string // read with when the user gives no hint
+	OutputDir    string   // searchable PDFs are written here
+}
+
+// Archiver keeps documents so they can be asked about later. The
+// knowledge base implements it.
+type Archiver interface {
+	Archive(ctx context.Context, path, filename string) (id string, err error)
 }
 
 // DefaultConfig returns default document configuration
@@ -64,6 +83,7 @@ func DefaultConfig() Config {
 		MaxFileSize:       50 * 1024 * 1024, // 50MB
 		MaxPages:          100,
 		MaxImageSize:      4096,
+		OCRLanguages:      []string{"eng"},
 	}
 }
 
@@ -77,6 +97,29 @@ func NewDocumentSkill(config Config) *DocumentSkill {
 	return ds
 }
 
+// SetFilePolicy replaces the policy deciding which files the tools may read
+func (ds *DocumentSkill) SetFilePolicy(policy *security.FilePolicy) {
+	ds.mu.Lock()
+	ds.files = policy
+	ds.mu.Unlock()
+}
+
+// SetArchiver sets where OCR results are kept
+func (ds *DocumentSkill) SetArchiver(archiver Archiver) {
+	ds.mu.Lock()
+	ds.archiver = archiver
+	ds.mu.Unlock()
+}
+
+// checkFile applies the file policy to a path a tool was given, returning
+// the path to open
+func (ds *DocumentSkill) checkFile(filePath string) (string, error) {
+	ds.mu.RLock()
+	policy := ds.files
+	ds.mu.RUnlock()
+	return policy.CheckRead(filePath)
+}
+
 // Initialize sets up the document skill
 func (ds *DocumentSkill) Initialize() error {
 	ds.mu.Lock()
@@ -519,9 +562,98 @@ func (ds *DocumentSkill) registerTools() {
 		Handler: ds.handleExtractReceipt,
 	})
 	
+	// Extract Spreadsheet
+	ds.AddTool(skills.Tool{
+		Name:        "extract_spreadsheet",
+		Description: "Read an Excel (XLSX) or CSV/TSV spreadsheet as Markdown tables. Can pick one sheet and a cell range",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to the spreadsheet",
+				},
+				"sheet": map[string]interface{}{
+					"type":        "string",
+					"description": "Sheet to read (default: all sheets)",
+				},
+				"range": map[string]interface{}{
+					"type":        "string",
+					"description": "Cells to read in A1 notation, e.g. 'A1:D20', 'B:D' or '2:50' (default: the whole sheet)",
+				},
+				"max_rows": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum rows per sheet, header included (default: 100)",
+				},
+			},
+			"required": []string{"file_path"},
+		},
+		Handler: ds.handleExtractSpreadsheet,
+	})
+
+	// Extract DOCX
+	ds.AddTool(skills.Tool{
+		Name:        "extract_docx",
+		Description: "Read a Word (DOCX) document as Markdown, keeping headings, lists and tables",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to the DOCX file",
+				},
+				"max_chars": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum characters of text to return (default: 20000)",
+				},
+			},
+			"required": []string{"file_path"},
+		},
+		Handler: ds.handleExtractDOCX,
+	})
+
+	// OCR Document
+	ds.AddTool(skills.Tool{
+		Name:        "ocr_document",
+		Description: "Read the text of a scanned PDF or a photo of a document with OCR, in the given languages. Pages scanned sideways or upside down are turned first. Can send back a searchable PDF, and keeps the text in the knowledge base",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to the scanned PDF or image",
+				},
+				"languages": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Languages of the text, e.g. ['de', 'en'] or ['deu', 'eng'] (default: the configured languages)",
+				},
+				"auto_rotate": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Turn pages the right way up before reading them (default: true)",
+				},
+				"searchable_pdf": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also send the user a PDF of the scan with selectable, searchable text (default: false)",
+				},
+				"save": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Keep the result in the knowledge base (default: true)",
+				},
+				"max_pages": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum pages of a PDF to read (default: 50)",
+				},
+			},
+			"required": []string{"file_path"},
+		},
+		Handler: ds.handleOCRDocument,
+	})
+
 	// Document Info
 	ds.AddTool(skills.Tool{
 		Name:        "document_info",
+		ReadOnly:    true,
 		Description: "Get document processing status and available capabilities",
 		Parameters: map[string]interface{}{
 			"type":       "object",
@@ -537,6 +669,10 @@ func (ds *DocumentSkill) handleProcessPDF(ctx context.Context, args map[string]i
 	if filePath == "" {
 		return nil, fmt.Errorf("file_path is required")
 	}
+	filePath, err := ds.checkFile(filePath)
+	if err != nil {
+		return nil, err
+	}
 	
 	options := ProcessOptions{
 		MaxPages: 50,
@@ -563,6 +699,10 @@ func (ds *DocumentSkill) handleProcessImage(ctx context.Context, args map[string
 	if filePath == "" {
 		return nil, fmt.Errorf("file_path is required")
 	}
+	filePath, err := ds.checkFile(filePath)
+	if err != nil {
+		return nil, err
+	}
 	
 	query, _ := args["query"].(string)
 	if query == "" {
@@ -583,6 +723,10 @@ func (ds *DocumentSkill) handleExtractReceipt(ctx context.Context, args map[stri
 	if filePath == "" {
 		return nil, fmt.Errorf("file_path is required")
 	}
+	filePath, err := ds.checkFile(filePath)
+	if err != nil {
+		return nil, err
+	}
 	
 	receipt, err := ds.ExtractReceipt(ctx, filePath)
 	if err != nil {
@@ -596,3 +740,198 @@ func (ds *DocumentSkill) handleExtractReceipt(ctx context.Context, args map[stri
 func (ds *DocumentSkill) handleDocumentInfo(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	return ds.GetInfo(), nil
 }
+
+// handleExtractSpreadsheet handles extract_spreadsheet tool
+func (ds *DocumentSkill) handleExtractSpreadsheet(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	filePath, _ := args["file_path"].(string)
+	if filePath == "" {
+		return nil, fmt.Errorf("file_path is required")
+	}
+	filePath, err := ds.checkFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := ds.checkSize(filePath); err != nil {
+		return nil, err
+	}
+
+	opts := SpreadsheetOptions{MaxRows: DefaultMaxRows}
+	opts.Sheet, _ = args["sheet"].(string)
+	opts.Range, _ = args["range"].(string)
+	if mr, ok := args["max_rows"].(float64); ok && mr > 0 {
+		opts.MaxRows = int(mr)
+	}
+
+	return ExtractSpreadsheet(filePath, opts)
+}
+
+// handleExtractDOCX handles extract_docx tool
+func (ds *DocumentSkill) handleExtractDOCX(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	filePath, _ := args["file_path"].(string)
+	if filePath == "" {
+		return nil, fmt.Errorf("file_path is required")
+	}
+	filePath, err := ds.checkFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := ds.checkSize(filePath); err != nil {
+		return nil, err
+	}
+
+	maxChars := 20000
+	if mc, ok := args["max_chars"].(float64); ok && mc > 0 {
+		maxChars = int(mc)
+	}
+
+	result, err := ExtractDOCX(filePath)
+	if err != nil {
+		return nil, err
+	}
+	response := map[string]interface{}{
+		"file_path":  result.FilePath,
+		"paragraphs": result.Paragraphs,
+		"tables":     result.Tables,
+		"markdown":   result.Markdown,
+	}
+	if result.Title != "" {
+		response["title"] = result.Title
+	}
+	if result.Author != "" {
+		response["author"] = result.Author
+	}
+	if runes := []rune(result.Markdown); len(runes) > maxChars {
+		response["markdown"] = string(runes[:maxChars]) + "\n... [truncated]"
+		response["truncated"] = true
+	}
+	return response, nil
+}
+
+// checkSize refuses files over the configured size limit
+func (ds *DocumentSkill) checkSize(filePath string) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("file not found: %w", err)
+	}
+	if ds.config.MaxFileSize > 0 && info.Size() > ds.config.MaxFileSize {
+		return fmt.Errorf("file too large: %d bytes (max: %d)", info.Size(), ds.config.MaxFileSize)
+	}
+	return nil
+}
+
+// handleOCRDocument handles ocr_document tool
+func (ds *DocumentSkill) handleOCRDocument(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	filePath, _ := args["file_path"].(string)
+	if filePath == "" {
+		return nil, fmt.Errorf("file_path is required")
+	}
+	filePath, err := ds.checkFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := ds.checkSize(filePath); err != nil {
+		return nil, err
+	}
+	if !ds.isReady {
+		if err := ds.Initialize(); err != nil {
+			return nil, err
+		}
+	}
+	ocr, ok := ds.ocrProcessor.(DocumentOCR)
+	if !ok {
+		return nil, fmt.Errorf("OCR is not available")
+	}
+
+	opts := OCROptions{
+		Languages:  ds.config.OCRLanguages,
+		AutoRotate: true,
+		MaxPages:   min(50, ds.config.MaxPages),
+	}
+	if langs, ok := args["languages"].([]interface{}); ok && len(langs) > 0 {
+		opts.Languages = nil
+		for _, l := range langs {
+			if s, ok := l.(string); ok {
+				opts.Languages = append(opts.Languages, s)
+			}
+		}
+	}
+	if ar, ok := args["auto_rotate"].(bool); ok {
+		opts.AutoRotate = ar
+	}
+	if mp, ok := args["max_pages"].(float64); ok && mp > 0 {
+		opts.MaxPages = int(mp)
+	}
+	name := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	if sp, _ := args["searchable_pdf"].(bool); sp {
+		dir := ds.config.OutputDir
+		if dir == "" {
+			dir = filepath.Join(os.TempDir(), "myrai-documents")
+		}
+		opts.SearchablePDF = filepath.Join(dir, fmt.Sprintf("%s_ocr_%d.pdf", name, time.Now().Unix()))
+	}
+
+	result, err := ocr.OCRDocument(ctx, filePath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	response := map[string]interface{}{
+		"file_path": result.FilePath,
+		"text":      result.Text,
+		"pages":     result.Pages,
+		"languages": result.Languages,
+	}
+	if len(result.MissingLanguages) > 0 {
+		response["missing_languages"] = result.MissingLanguages
+		response["note"] = fmt.Sprintf("Language packs not installed: %s. Install them with the tesseract-ocr-<lang> package.", strings.Join(result.MissingLanguages, ", "))
+	}
+	if len(result.Rotated) > 0 {
+		response["rotated"] = result.Rotated
+	}
+	if result.SearchablePDF != "" {
+		response["searchable_pdf"] = result.SearchablePDF
+		response["sent"] = skills.Attach(ctx, result.SearchablePDF)
+	}
+
+	save := true
+	if s, ok := args["save"].(bool); ok {
+		save = s
+	}
+	ds.mu.RLock()
+	archiver := ds.archiver
+	ds.mu.RUnlock()
+	if save && archiver != nil && result.Text != "" {
+		id, err := ds.archiveOCR(ctx, archiver, name, result)
+		if err != nil {
+			response["save_error"] = err.Error()
+		} else {
+			response["document_id"] = id
+		}
+	}
+	return response, nil
+}
+
+// archiveOCR keeps the searchable PDF in the knowledge base, or the text
+// when none was made
+func (ds *DocumentSkill) archiveOCR(ctx context.Context, archiver Archiver, name string, result *OCRResult) (string, error) {
+	if result.SearchablePDF != "" {
+		if id, err := archiver.Archive(ctx, result.SearchablePDF, name+".pdf"); err == nil {
+			return id, nil
+		}
+		// Without pdftotext the text layer can't be read back; keep the text
+	}
+
+	f, err := os.CreateTemp("", "myrai-ocr-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(result.Text)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	return archiver.Archive(ctx, f.Name(), name+".txt")
+}
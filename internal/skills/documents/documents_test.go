@@ -93,8 +93,8 @@ func TestDocumentSkill_Tools(t *testing.T) {
 
 	tools := skill.Tools()
 
-	if len(tools) != 4 {
-		t.Errorf("Expected 4 tools, got %d", len(tools))
+	if len(tools) != 7 {
+		t.Errorf("Expected 7 tools, got %d", len(tools))
 	}
 
 	toolNames := make(map[string]bool)
@@ -102,7 +102,7 @@ func TestDocumentSkill_Tools(t *testing.T) {
 		toolNames[tool.Name] = true
 	}
 
-	expectedTools := []string{"process_pdf", "process_image", "extract_receipt", "document_info"}
+	expectedTools := []string{"process_pdf", "process_image", "extract_receipt", "extract_spreadsheet", "extract_docx", "ocr_document", "document_info"}
 	for _, name := range expectedTools {
 		if !toolNames[name] {
 			t.Errorf("Expected tool '%s' not found", name)
@@ -0,0 +1,260 @@
+package documents
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxDocxPart limits how much of one part of a DOCX is read, so a
+// compressed bomb can't exhaust memory
+const maxDocxPart = 64 << 20
+
+// DocxResult holds the text of a Word document
+type DocxResult struct {
+	FilePath   string `json:"file_path"`
+	Title      string `json:"title,omitempty"`
+	Author     string `json:"author,omitempty"`
+	Paragraphs int    `json:"paragraphs"`
+	Tables     int    `json:"tables"`
+	Markdown   string `json:"markdown"`
+}
+
+var headingStyle = regexp.MustCompile(`^heading ?([1-6])$`)
+
+// ExtractDOCX reads a Word document as Markdown, keeping its headings,
+// lists and tables
+func ExtractDOCX(filePath string) (*DocxResult, error) {
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open document: %w", err)
+	}
+	defer r.Close()
+
+	parts := make(map[string]*zip.File, len(r.File))
+	for _, f := range r.File {
+		parts[f.Name] = f
+	}
+	body, ok := parts["word/document.xml"]
+	if !ok {
+		return nil, fmt.Errorf("not a Word document: no word/document.xml")
+	}
+
+	result := &DocxResult{FilePath: filePath}
+	if core, ok := parts["docProps/core.xml"]; ok {
+		result.Title, result.Author = docxProperties(core)
+	}
+	var styles map[string]string
+	if f, ok := parts["word/styles.xml"]; ok {
+		styles = docxStyles(f)
+	}
+
+	rc, err := body.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	defer rc.Close()
+	if err := parseDocxBody(io.LimitReader(rc, maxDocxPart), styles, result); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+	return result, nil
+}
+
+// docxProperties reads the title and author from docProps/core.xml
+func docxProperties(f *zip.File) (title, author string) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", ""
+	}
+	defer rc.Close()
+	var props struct {
+		Title   string `xml:"title"`
+		Creator string `xml:"creator"`
+	}
+	xml.NewDecoder(io.LimitReader(rc, maxDocxPart)).Decode(&props)
+	return strings.TrimSpace(props.Title), strings.TrimSpace(props.Creator)
+}
+
+// docxStyles maps style IDs to their lowercase names. Documents written in
+// other languages give headings localized IDs but keep names like
+// "heading 1".
+func docxStyles(f *zip.File) map[string]string {
+	rc, err := f.Open()
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+	var doc struct {
+		Styles []struct {
+			ID   string `xml:"styleId,attr"`
+			Name struct {
+				Val string `xml:"val,attr"`
+			} `xml:"name"`
+		} `xml:"style"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(rc, maxDocxPart)).Decode(&doc); err != nil {
+		return nil
+	}
+	styles := make(map[string]string, len(doc.Styles))
+	for _, s := range doc.Styles {
+		styles[s.ID] = strings.ToLower(s.Name.Val)
+	}
+	return styles
+}
+
+// headingLevel returns the Markdown heading level of a paragraph style, or
+// 0 for body text
+func headingLevel(styleID string, styles map[string]string) int {
+	for _, name := range []string{styles[styleID], strings.ToLower(styleID)} {
+		if name == "title" {
+			return 1
+		}
+		if m := headingStyle.FindStringSubmatch(name); m != nil {
+			level, _ := strconv.Atoi(m[1])
+			return level
+		}
+	}
+	return 0
+}
+
+// docxTable collects a table's cells as it's parsed
+type docxTable struct {
+	rows [][]string
+	row  []string
+	cell []string // paragraphs of the current cell
+}
+
+// parseDocxBody walks word/document.xml, writing paragraphs and tables as
+// Markdown blocks
+func parseDocxBody(r io.Reader, styles map[string]string, result *DocxResult) error {
+	var blocks []string
+	var tables []*docxTable
+	var para strings.Builder
+	var style string
+	list, listLevel, inText := false, 0, false
+
+	attr := func(e xml.StartElement, name string) string {
+		for _, a := range e.Attr {
+			if a.Name.Local == name {
+				return a.Value
+			}
+		}
+		return ""
+	}
+
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para.Reset()
+				style, list, listLevel = "", false, 0
+			case "pStyle":
+				style = attr(t, "val")
+			case "numPr":
+				list = true
+			case "ilvl":
+				listLevel, _ = strconv.Atoi(attr(t, "val"))
+			case "t":
+				inText = true
+			case "tab":
+				para.WriteString("\t")
+			case "br", "cr":
+				para.WriteString("\n")
+			case "tbl":
+				tables = append(tables, &docxTable{})
+			case "tr":
+				if n := len(tables); n > 0 {
+					tables[n-1].row = nil
+				}
+			case "tc":
+				if n := len(tables); n > 0 {
+					tables[n-1].cell = nil
+				}
+			}
+
+		case xml.CharData:
+			if inText {
+				para.Write(t)
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text := strings.TrimSpace(para.String())
+				if text == "" {
+					continue
+				}
+				result.Paragraphs++
+				if n := len(tables); n > 0 {
+					tables[n-1].cell = append(tables[n-1].cell, text)
+					continue
+				}
+				if level := headingLevel(style, styles); level > 0 {
+					text = strings.Repeat("#", level) + " " + strings.ReplaceAll(text, "\n", " ")
+				} else if list {
+					text = strings.Repeat("  ", listLevel) + "- " + strings.ReplaceAll(text, "\n", " ")
+				}
+				// Consecutive list items make one list
+				if n := len(blocks); list && n > 0 && strings.HasPrefix(strings.TrimLeft(blocks[n-1], " "), "- ") {
+					blocks[n-1] += "\n" + text
+				} else {
+					blocks = append(blocks, text)
+				}
+			case "tc":
+				if n := len(tables); n > 0 {
+					tbl := tables[n-1]
+					tbl.row = append(tbl.row, strings.Join(tbl.cell, "\n"))
+				}
+			case "tr":
+				if n := len(tables); n > 0 {
+					tbl := tables[n-1]
+					tbl.rows = append(tbl.rows, tbl.row)
+				}
+			case "tbl":
+				n := len(tables)
+				if n == 0 {
+					continue
+				}
+				tbl := tables[n-1]
+				tables = tables[:n-1]
+				if n > 1 {
+					// A table in a cell becomes text in the outer cell
+					var lines []string
+					for _, row := range tbl.rows {
+						lines = append(lines, strings.Join(row, " | "))
+					}
+					parent := tables[n-2]
+					parent.cell = append(parent.cell, strings.Join(lines, "\n"))
+					continue
+				}
+				result.Tables++
+				columns := 0
+				for _, row := range tbl.rows {
+					columns = max(columns, len(row))
+				}
+				if len(tbl.rows) > 0 && columns > 0 {
+					blocks = append(blocks, markdownTable(tbl.rows, columns))
+				}
+			}
+		}
+	}
+
+	result.Markdown = strings.Join(blocks, "\n\n")
+	return nil
+}
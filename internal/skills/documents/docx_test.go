@@ -0,0 +1,95 @@
+package documents
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testDocxBody = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:body>
+<w:p><w:pPr><w:pStyle w:val="Titel"/></w:pPr><w:r><w:t>Trip Plan</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">We leave on </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>Friday</w:t></w:r><w:r><w:t>.</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Packing</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Tent</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Pegs</w:t></w:r></w:p>
+<w:p></w:p>
+<w:tbl>
+<w:tr><w:tc><w:p><w:r><w:t>Day</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Stop</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>1</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Lake</w:t></w:r></w:p><w:p><w:r><w:t>Camp</w:t></w:r></w:p></w:tc></w:tr>
+</w:tbl>
+</w:body>
+</w:document>`
+
+const testDocxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:style w:type="paragraph" w:styleId="Titel"><w:name w:val="Title"/></w:style>
+<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/></w:style>
+</w:styles>`
+
+const testDocxCore = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:title>Summer trip</dc:title><dc:creator>Sam</dc:creator>
+</cp:coreProperties>`
+
+func writeDOCX(t *testing.T, path string, parts map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, content := range parts {
+		pw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pw.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractDOCX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trip.docx")
+	writeDOCX(t, path, map[string]string{
+		"word/document.xml": testDocxBody,
+		"word/styles.xml":   testDocxStyles,
+		"docProps/core.xml": testDocxCore,
+	})
+	if format := DetectFormat(path); format != FormatDOCX {
+		t.Fatalf("DetectFormat = %q, want docx", format)
+	}
+
+	result, err := ExtractDOCX(path)
+	if err != nil {
+		t.Fatalf("ExtractDOCX failed: %v", err)
+	}
+	if result.Title != "Summer trip" || result.Author != "Sam" {
+		t.Errorf("Unexpected properties %q by %q", result.Title, result.Author)
+	}
+	if result.Tables != 1 {
+		t.Errorf("Expected 1 table, got %d", result.Tables)
+	}
+
+	want := "# Trip Plan\n\n" +
+		"We leave on Friday.\n\n" +
+		"## Packing\n\n" +
+		"- Tent\n  - Pegs\n\n" +
+		"| Day | Stop |\n| --- | --- |\n| 1 | Lake<br>Camp |"
+	if result.Markdown != want {
+		t.Errorf("Markdown =\n%s\nwant\n%s", result.Markdown, want)
+	}
+}
+
+func TestExtractDOCX_NotWord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.docx")
+	writeDOCX(t, path, map[string]string{"xl/workbook.xml": "<workbook/>"})
+	if _, err := ExtractDOCX(path); err == nil {
+		t.Error("Expected an error for a zip without word/document.xml")
+	}
+}
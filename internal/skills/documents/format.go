@@ -0,0 +1,85 @@
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Formats DetectFormat tells apart
+const (
+	FormatPDF  = "pdf"
+	FormatXLSX = "xlsx"
+	FormatDOCX = "docx"
+	FormatCSV  = "csv"
+	FormatTSV  = "tsv"
+)
+
+// DetectFormat tells a document's format from its content where it can,
+// as uploads are often saved under the wrong extension, and otherwise from
+// its extension. It returns "" for other files.
+func DetectFormat(filePath string) string {
+	if f, err := os.Open(filePath); err == nil {
+		head := make([]byte, 4)
+		n, _ := f.Read(head)
+		f.Close()
+		switch {
+		case bytes.HasPrefix(head[:n], []byte("%PDF")):
+			return FormatPDF
+		case bytes.HasPrefix(head[:n], []byte("PK\x03\x04")):
+			if format := officeFormat(filePath); format != "" {
+				return format
+			}
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".pdf":
+		return FormatPDF
+	case ".xlsx", ".xlsm":
+		return FormatXLSX
+	case ".docx":
+		return FormatDOCX
+	case ".csv":
+		return FormatCSV
+	case ".tsv", ".tab":
+		return FormatTSV
+	}
+	return ""
+}
+
+// officeFormat tells an Office Open XML file by the part it's built around
+func officeFormat(filePath string) string {
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		return ""
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		switch f.Name {
+		case "xl/workbook.xml":
+			return FormatXLSX
+		case "word/document.xml":
+			return FormatDOCX
+		}
+	}
+	return ""
+}
+
+// ToolFor names the tool that reads a file of the given name, for prompts
+// about files users send
+func ToolFor(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".pdf":
+		return "process_pdf"
+	case ".xlsx", ".xlsm", ".csv", ".tsv":
+		return "extract_spreadsheet"
+	case ".docx":
+		return "extract_docx"
+	case ".png", ".jpg", ".jpeg", ".webp", ".gif", ".bmp", ".tif", ".tiff":
+		return "process_image"
+	}
+	return ""
+}
@@ -0,0 +1,288 @@
+package documents
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OCROptions tunes the OCR of a scanned document
+type OCROptions struct {
+	Languages     []string // hints, as tesseract codes ("deu") or ISO 639-1 ("de"); English when empty
+	AutoRotate    bool     // turn pages scanned sideways or upside down before reading them
+	MaxPages      int      // of a PDF; 0 for all
+	SearchablePDF string   // when set, also write the pages here as a PDF with a text layer
+}
+
+// OCRResult is the text read from a scanned document
+type OCRResult struct {
+	FilePath         string   `json:"file_path"`
+	Text             string   `json:"text"`
+	Pages            int      `json:"pages"`
+	Languages        []string `json:"languages"`                   // used to read it
+	MissingLanguages []string `json:"missing_languages,omitempty"` // hinted but not installed
+	Rotated          []int    `json:"rotated,omitempty"`           // degrees each page was turned clockwise, when any was
+	SearchablePDF    string   `json:"searchable_pdf,omitempty"`
+}
+
+// DocumentOCR reads whole scanned documents, PDFs as well as images
+type DocumentOCR interface {
+	OCRDocument(ctx context.Context, filePath string, opts OCROptions) (*OCRResult, error)
+}
+
+// isoLanguages maps ISO 639-1 codes to tesseract's language packs
+var isoLanguages = map[string]string{
+	"ar": "ara", "cs": "ces", "da": "dan", "de": "deu", "el": "ell",
+	"en": "eng", "es": "spa", "fi": "fin", "fr": "fra", "he": "heb",
+	"hi": "hin", "hu": "hun", "id": "ind", "it": "ita", "ja": "jpn",
+	"ko": "kor", "ms": "msa", "nl": "nld", "no": "nor", "pl": "pol",
+	"pt": "por", "ro": "ron", "ru": "rus", "sv": "swe", "th": "tha",
+	"tl": "tgl", "tr": "tur", "uk": "ukr", "vi": "vie", "zh": "chi_sim",
+}
+
+var osdRotate = regexp.MustCompile(`(?m)^Rotate:\s*(\d+)`)
+
+// resolveLanguages turns language hints into installed tesseract packs.
+// installed is nil when the installed packs couldn't be listed, in which
+// case the hints are used as they are.
+func resolveLanguages(hints, installed []string) (use, missing []string) {
+	have := make(map[string]bool, len(installed))
+	for _, lang := range installed {
+		have[lang] = true
+	}
+	seen := map[string]bool{}
+	for _, hint := range hints {
+		lang := strings.TrimSpace(hint)
+		if code, ok := isoLanguages[strings.ToLower(lang)]; ok {
+			lang = code
+		}
+		if lang == "" || seen[lang] {
+			continue
+		}
+		seen[lang] = true
+		if installed != nil && !have[lang] {
+			missing = append(missing, lang)
+			continue
+		}
+		use = append(use, lang)
+	}
+
+	if len(use) == 0 {
+		use = []string{"eng"}
+		if installed != nil && !have["eng"] {
+			for _, lang := range installed {
+				if lang != "osd" {
+					use = []string{lang}
+					break
+				}
+			}
+		}
+	}
+	return use, missing
+}
+
+// parseOSD reads the clockwise turn tesseract's orientation detection says
+// a page needs
+func parseOSD(output string) int {
+	m := osdRotate.FindStringSubmatch(output)
+	if m == nil {
+		return 0
+	}
+	degrees, _ := strconv.Atoi(m[1])
+	switch degrees {
+	case 90, 180, 270:
+		return degrees
+	}
+	return 0
+}
+
+// OCRDocument reads a scanned PDF or an image, optionally turning pages
+// the right way up first and writing a searchable PDF
+func (t *tesseractOCR) OCRDocument(ctx context.Context, filePath string, opts OCROptions) (*OCRResult, error) {
+	if !t.IsAvailable() {
+		return nil, fmt.Errorf("tesseract not found (install tesseract-ocr)")
+	}
+
+	tempDir, err := os.MkdirTemp("", "myrai-ocr-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	pages := []string{filePath}
+	if DetectFormat(filePath) == FormatPDF {
+		if pages, err = renderPages(ctx, filePath, tempDir, opts.MaxPages); err != nil {
+			return nil, err
+		}
+	}
+
+	installed, err := t.ListLanguages()
+	if err != nil {
+		installed = nil
+	}
+	langs, missing := resolveLanguages(opts.Languages, installed)
+	result := &OCRResult{
+		FilePath:         filePath,
+		Pages:            len(pages),
+		Languages:        langs,
+		MissingLanguages: missing,
+	}
+
+	if opts.AutoRotate {
+		rotated := make([]int, len(pages))
+		anyRotated := false
+		for i, page := range pages {
+			degrees := t.orientation(ctx, page)
+			if degrees == 0 {
+				continue
+			}
+			out := filepath.Join(tempDir, fmt.Sprintf("rotated_%d.png", i+1))
+			if err := rotateImage(page, out, degrees); err != nil {
+				continue // read as scanned rather than not at all
+			}
+			pages[i], rotated[i], anyRotated = out, degrees, true
+		}
+		if anyRotated {
+			result.Rotated = rotated
+		}
+	}
+
+	// tesseract reads a list of images as the pages of one document
+	list := filepath.Join(tempDir, "pages.txt")
+	if err := os.WriteFile(list, []byte(strings.Join(pages, "\n")+"\n"), 0600); err != nil {
+		return nil, err
+	}
+	outBase := filepath.Join(tempDir, "out")
+	args := []string{list, outBase, "-l", strings.Join(langs, "+"), "--psm", "3", "txt"}
+	if opts.SearchablePDF != "" {
+		args = append(args, "pdf")
+	}
+	if output, err := exec.CommandContext(ctx, t.binaryPath, args...).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("tesseract failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+
+	text, err := os.ReadFile(outBase + ".txt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OCR output: %w", err)
+	}
+	// Pages are separated by form feeds
+	result.Text = strings.TrimSpace(strings.ReplaceAll(string(text), "\f", "\n\n"))
+
+	if opts.SearchablePDF != "" {
+		if err := os.MkdirAll(filepath.Dir(opts.SearchablePDF), 0755); err != nil {
+			return nil, err
+		}
+		if err := copyFile(outBase+".pdf", opts.SearchablePDF); err != nil {
+			return nil, fmt.Errorf("failed to save searchable PDF: %w", err)
+		}
+		result.SearchablePDF = opts.SearchablePDF
+	}
+	return result, nil
+}
+
+// orientation asks tesseract how far a page must be turned clockwise to be
+// upright. It returns 0 when it can't tell, e.g. without the osd pack.
+func (t *tesseractOCR) orientation(ctx context.Context, page string) int {
+	output, err := exec.CommandContext(ctx, t.binaryPath, page, "stdout", "--psm", "0").CombinedOutput()
+	if err != nil {
+		return 0
+	}
+	return parseOSD(string(output))
+}
+
+// renderPages renders a PDF's pages as images for OCR, at a resolution
+// tesseract reads well
+func renderPages(ctx context.Context, filePath, dir string, maxPages int) ([]string, error) {
+	if _, err := exec.LookPath("pdftoppm"); err != nil {
+		return nil, fmt.Errorf("pdftoppm not found (install poppler-utils)")
+	}
+	args := []string{"-png", "-r", "300"}
+	if maxPages > 0 {
+		args = append(args, "-l", strconv.Itoa(maxPages))
+	}
+	args = append(args, filePath, filepath.Join(dir, "page"))
+	if output, err := exec.CommandContext(ctx, "pdftoppm", args...).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("pdftoppm failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+
+	pages, err := filepath.Glob(filepath.Join(dir, "page*.png"))
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no pages found in %s", filepath.Base(filePath))
+	}
+	// pdftoppm pads page numbers to the same width, so names sort in order
+	sort.Strings(pages)
+	return pages, nil
+}
+
+// rotateImage writes src turned clockwise by 90, 180 or 270 degrees to dst
+// as PNG
+func rotateImage(src, dst string, degrees int) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	img, _, err := image.Decode(bufio.NewReader(f))
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", filepath.Base(src), err)
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	var out *image.RGBA
+	if degrees == 180 {
+		out = image.NewRGBA(image.Rect(0, 0, w, h))
+	} else {
+		out = image.NewRGBA(image.Rect(0, 0, h, w))
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := img.At(b.Min.X+x, b.Min.Y+y)
+			switch degrees {
+			case 90:
+				out.Set(h-1-y, x, c)
+			case 180:
+				out.Set(w-1-x, h-1-y, c)
+			case 270:
+				out.Set(y, w-1-x, c)
+			default:
+				return fmt.Errorf("can't rotate by %d degrees", degrees)
+			}
+		}
+	}
+
+	file, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, out); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// copyFile copies src to dst
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0644)
+}
+
+var _ DocumentOCR = (*tesseractOCR)(nil)
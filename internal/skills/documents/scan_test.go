@@ -0,0 +1,164 @@
+package documents
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/skills"
+)
+
+func TestResolveLanguages(t *testing.T) {
+	installed := []string{"deu", "eng", "osd"}
+	tests := []struct {
+		hints, installed []string
+		use, missing     []string
+	}{
+		{nil, installed, []string{"eng"}, nil},
+		{[]string{"de", "EN", "deu"}, installed, []string{"deu", "eng"}, nil},
+		{[]string{"fr", "deu"}, installed, []string{"deu"}, []string{"fra"}},
+		{[]string{"jpn"}, []string{"osd", "spa"}, []string{"spa"}, []string{"jpn"}},
+		{[]string{"fr"}, nil, []string{"fra"}, nil},
+	}
+	for _, tt := range tests {
+		use, missing := resolveLanguages(tt.hints, tt.installed)
+		if !reflect.DeepEqual(use, tt.use) || !reflect.DeepEqual(missing, tt.missing) {
+			t.Errorf("resolveLanguages(%v, %v) = %v, %v; want %v, %v", tt.hints, tt.installed, use, missing, tt.use, tt.missing)
+		}
+	}
+}
+
+func TestParseOSD(t *testing.T) {
+	output := "Page number: 0\nOrientation in degrees: 270\nRotate: 90\nOrientation confidence: 11.93\nScript: Latin\n"
+	if got := parseOSD(output); got != 90 {
+		t.Errorf("parseOSD = %d, want 90", got)
+	}
+	if got := parseOSD("Rotate: 0\n"); got != 0 {
+		t.Errorf("parseOSD = %d, want 0", got)
+	}
+	if got := parseOSD("Too few characters. Skipping this page"); got != 0 {
+		t.Errorf("parseOSD = %d, want 0", got)
+	}
+}
+
+func TestRotateImage(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "scan.png")
+	// 3x2, with the top-left pixel marked
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	f, _ := os.Create(src)
+	png.Encode(f, img)
+	f.Close()
+
+	tests := []struct {
+		degrees int
+		w, h    int
+		x, y    int // where the marked pixel ends up
+	}{
+		{90, 2, 3, 1, 0},
+		{180, 3, 2, 2, 1},
+		{270, 2, 3, 0, 2},
+	}
+	for _, tt := range tests {
+		dst := filepath.Join(dir, "out.png")
+		if err := rotateImage(src, dst, tt.degrees); err != nil {
+			t.Fatalf("rotateImage(%d) failed: %v", tt.degrees, err)
+		}
+		f, _ := os.Open(dst)
+		out, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := out.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("rotated %d: size %dx%d, want %dx%d", tt.degrees, b.Dx(), b.Dy(), tt.w, tt.h)
+		}
+		if r, _, _, _ := out.At(tt.x, tt.y).RGBA(); r == 0 {
+			t.Errorf("rotated %d: marked pixel not at (%d,%d)", tt.degrees, tt.x, tt.y)
+		}
+	}
+}
+
+// fakeOCR reads every document as the same text
+type fakeOCR struct {
+	MockOCR
+	opts OCROptions
+}
+
+func (f *fakeOCR) OCRDocument(ctx context.Context, filePath string, opts OCROptions) (*OCRResult, error) {
+	f.opts = opts
+	result := &OCRResult{FilePath: filePath, Text: "Rechnung Nr. 42", Pages: 1, Languages: []string{"deu"}, MissingLanguages: []string{"fra"}}
+	if opts.SearchablePDF != "" {
+		os.MkdirAll(filepath.Dir(opts.SearchablePDF), 0755)
+		os.WriteFile(opts.SearchablePDF, []byte("%PDF-1.5"), 0644)
+		result.SearchablePDF = opts.SearchablePDF
+	}
+	return result, nil
+}
+
+type fakeArchiver struct{ filenames []string }
+
+func (a *fakeArchiver) Archive(ctx context.Context, path, filename string) (string, error) {
+	a.filenames = append(a.filenames, filename)
+	return "doc_1", nil
+}
+
+func TestDocumentSkill_HandleOCRDocument(t *testing.T) {
+	dir := t.TempDir()
+	scan := filepath.Join(dir, "invoice.png")
+	os.WriteFile(scan, []byte("scan"), 0644)
+
+	config := DefaultConfig()
+	config.OCRLanguages = []string{"deu"}
+	config.OutputDir = filepath.Join(dir, "ocr")
+	ds := NewDocumentSkill(config)
+	ocr := &fakeOCR{}
+	archiver := &fakeArchiver{}
+	ds.ocrProcessor, ds.isReady = ocr, true
+	ds.SetArchiver(archiver)
+
+	ctx, attachments := skills.WithAttachments(context.Background())
+	result, err := ds.handleOCRDocument(ctx, map[string]interface{}{
+		"file_path":      scan,
+		"languages":      []interface{}{"de", "fr"},
+		"searchable_pdf": true,
+	})
+	if err != nil {
+		t.Fatalf("handleOCRDocument failed: %v", err)
+	}
+	response := result.(map[string]interface{})
+
+	if !reflect.DeepEqual(ocr.opts.Languages, []string{"de", "fr"}) || !ocr.opts.AutoRotate {
+		t.Errorf("Unexpected options %+v", ocr.opts)
+	}
+	if filepath.Dir(ocr.opts.SearchablePDF) != config.OutputDir {
+		t.Errorf("Searchable PDF written to %s", ocr.opts.SearchablePDF)
+	}
+	if paths := attachments.Paths(); len(paths) != 1 || paths[0] != ocr.opts.SearchablePDF {
+		t.Errorf("Expected the searchable PDF to be sent, got %v", paths)
+	}
+	if response["note"] == nil {
+		t.Error("Expected a note about the missing language pack")
+	}
+	if response["document_id"] != "doc_1" || !reflect.DeepEqual(archiver.filenames, []string{"invoice.pdf"}) {
+		t.Errorf("Expected the PDF in the knowledge base, got %v", archiver.filenames)
+	}
+
+	// Without a searchable PDF the text is kept, in the configured languages
+	archiver.filenames = nil
+	if _, err := ds.handleOCRDocument(context.Background(), map[string]interface{}{"file_path": scan}); err != nil {
+		t.Fatalf("handleOCRDocument failed: %v", err)
+	}
+	if !reflect.DeepEqual(ocr.opts.Languages, []string{"deu"}) {
+		t.Errorf("Expected the configured languages, got %v", ocr.opts.Languages)
+	}
+	if !reflect.DeepEqual(archiver.filenames, []string{"invoice.txt"}) {
+		t.Errorf("Expected the text in the knowledge base, got %v", archiver.filenames)
+	}
+}
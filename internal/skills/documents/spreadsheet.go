@@ -0,0 +1,302 @@
+package documents
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// DefaultMaxRows is how many rows of each sheet a spreadsheet extraction
+// returns unless asked for more, keeping big sheets from flooding the context
+const DefaultMaxRows = 100
+
+// SpreadsheetOptions selects what to extract from a spreadsheet
+type SpreadsheetOptions struct {
+	Sheet   string // name of one sheet; all sheets when empty
+	Range   string // e.g. "A1:D20", "B:D" or "2:50"; the whole sheet when empty
+	MaxRows int    // rows per sheet, header included; 0 for all
+}
+
+// SpreadsheetResult holds the sheets extracted from a spreadsheet
+type SpreadsheetResult struct {
+	FilePath string       `json:"file_path"`
+	Format   string       `json:"format"` // xlsx, csv or tsv
+	Sheets   []string     `json:"sheets"` // every sheet in the file
+	Tables   []SheetTable `json:"tables"`
+}
+
+// SheetTable is the extracted part of one sheet
+type SheetTable struct {
+	Sheet     string     `json:"sheet"`
+	Range     string     `json:"range,omitempty"`
+	Rows      int        `json:"rows"`    // in the range, before MaxRows
+	Columns   int        `json:"columns"` // widest row in the range
+	Truncated bool       `json:"truncated,omitempty"`
+	Markdown  string     `json:"markdown"`
+	Cells     [][]string `json:"-"`
+}
+
+// Markdown returns every extracted sheet as Markdown tables under headings
+func (r *SpreadsheetResult) Markdown() string {
+	var b strings.Builder
+	for i, t := range r.Tables {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		if len(r.Tables) > 1 || r.Format == "xlsx" {
+			fmt.Fprintf(&b, "## %s\n\n", t.Sheet)
+		}
+		b.WriteString(t.Markdown)
+		if t.Truncated {
+			fmt.Fprintf(&b, "\n\n(%d of %d rows shown)", len(t.Cells), t.Rows)
+		}
+	}
+	return b.String()
+}
+
+// ExtractSpreadsheet reads an XLSX, CSV or TSV file into Markdown tables
+func ExtractSpreadsheet(filePath string, opts SpreadsheetOptions) (*SpreadsheetResult, error) {
+	format := DetectFormat(filePath)
+	sel, err := parseCellRange(opts.Range)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SpreadsheetResult{FilePath: filePath, Format: format}
+	switch format {
+	case FormatXLSX:
+		f, err := excelize.OpenFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open spreadsheet: %w", err)
+		}
+		defer f.Close()
+
+		result.Sheets = f.GetSheetList()
+		names := result.Sheets
+		if opts.Sheet != "" {
+			name, ok := findSheet(result.Sheets, opts.Sheet)
+			if !ok {
+				return nil, fmt.Errorf("no sheet %q; sheets are: %s", opts.Sheet, strings.Join(result.Sheets, ", "))
+			}
+			names = []string{name}
+		}
+		for _, name := range names {
+			rows, err := f.GetRows(name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read sheet %s: %w", name, err)
+			}
+			result.Tables = append(result.Tables, newSheetTable(name, rows, sel, opts.MaxRows))
+		}
+
+	case FormatCSV, FormatTSV:
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		rows, err := readDelimited(file, format)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", format, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+		result.Sheets = []string{name}
+		result.Tables = []SheetTable{newSheetTable(name, rows, sel, opts.MaxRows)}
+
+	default:
+		return nil, fmt.Errorf("not a spreadsheet: %s", filepath.Base(filePath))
+	}
+	return result, nil
+}
+
+// findSheet matches a sheet name regardless of case
+func findSheet(sheets []string, name string) (string, bool) {
+	for _, s := range sheets {
+		if strings.EqualFold(s, strings.TrimSpace(name)) {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// readDelimited reads CSV or TSV. CSV files saved in locales that use a
+// decimal comma are separated by semicolons, so the separator is taken from
+// the first line.
+func readDelimited(r io.Reader, format string) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimPrefix(string(data), "\ufeff") // Excel's byte order mark
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if format == FormatTSV {
+		reader.Comma = '\t'
+	} else {
+		first, _, _ := strings.Cut(text, "\n")
+		if strings.Count(first, ";") > strings.Count(first, ",") {
+			reader.Comma = ';'
+		} else if strings.Count(first, "\t") > strings.Count(first, ",") {
+			reader.Comma = '\t'
+		}
+	}
+	return reader.ReadAll()
+}
+
+// cellRange is a rectangle of cells, 1-based and inclusive. Zero bounds are
+// open, so "B:D" has no rows set and takes every row.
+type cellRange struct {
+	text                                 string
+	firstCol, firstRow, lastCol, lastRow int
+}
+
+var cellRef = regexp.MustCompile(`^\$?([A-Za-z]*)\$?([0-9]*)$`)
+
+// parseCellRange reads a range like "A1:D20", "A1", "B:D" or "2:50"
+func parseCellRange(s string) (cellRange, error) {
+	s = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+	if s == "" {
+		return cellRange{}, nil
+	}
+	// A sheet given with the range, as in Sheet1!A1:B2, is dropped; the
+	// sheet argument picks the sheet
+	if i := strings.LastIndex(s, "!"); i >= 0 {
+		s = s[i+1:]
+	}
+
+	start, end, isRange := strings.Cut(s, ":")
+	if !isRange {
+		end = start
+	}
+	r := cellRange{text: s}
+	var err error
+	if r.firstCol, r.firstRow, err = parseCellRef(start); err != nil {
+		return cellRange{}, err
+	}
+	if r.lastCol, r.lastRow, err = parseCellRef(end); err != nil {
+		return cellRange{}, err
+	}
+	if (r.lastCol > 0 && r.firstCol > r.lastCol) || (r.lastRow > 0 && r.firstRow > r.lastRow) {
+		return cellRange{}, fmt.Errorf("invalid range %s: start is after end", s)
+	}
+	return r, nil
+}
+
+func parseCellRef(s string) (col, row int, err error) {
+	m := cellRef.FindStringSubmatch(s)
+	if m == nil || (m[1] == "" && m[2] == "") {
+		return 0, 0, fmt.Errorf("invalid cell reference %q: use A1 notation, e.g. A1:D20", s)
+	}
+	if m[1] != "" {
+		if col, err = excelize.ColumnNameToNumber(m[1]); err != nil {
+			return 0, 0, fmt.Errorf("invalid column %q: %w", m[1], err)
+		}
+	}
+	if m[2] != "" {
+		if row, err = strconv.Atoi(m[2]); err != nil || row < 1 {
+			return 0, 0, fmt.Errorf("invalid row %q", m[2])
+		}
+	}
+	return col, row, nil
+}
+
+// apply cuts rows down to the range
+func (r cellRange) apply(rows [][]string) [][]string {
+	firstRow, lastRow := max(r.firstRow, 1), r.lastRow
+	if lastRow == 0 || lastRow > len(rows) {
+		lastRow = len(rows)
+	}
+	if firstRow > lastRow {
+		return nil
+	}
+	out := make([][]string, 0, lastRow-firstRow+1)
+	for _, row := range rows[firstRow-1 : lastRow] {
+		firstCol, lastCol := max(r.firstCol, 1), r.lastCol
+		if lastCol == 0 {
+			lastCol = len(row)
+		}
+		var cells []string
+		for c := firstCol; c <= lastCol; c++ {
+			if c <= len(row) {
+				cells = append(cells, row[c-1])
+			} else {
+				cells = append(cells, "")
+			}
+		}
+		out = append(out, cells)
+	}
+	return out
+}
+
+// newSheetTable extracts a range of a sheet, dropping blank rows and the
+// empty columns at the end
+func newSheetTable(name string, rows [][]string, sel cellRange, maxRows int) SheetTable {
+	var cells [][]string
+	for _, row := range sel.apply(rows) {
+		if strings.TrimSpace(strings.Join(row, "")) != "" {
+			cells = append(cells, row)
+		}
+	}
+
+	columns := 0
+	for _, row := range cells {
+		for c := len(row); c > 0; c-- {
+			if strings.TrimSpace(row[c-1]) != "" {
+				columns = max(columns, c)
+				break
+			}
+		}
+	}
+
+	table := SheetTable{Sheet: name, Range: sel.text, Rows: len(cells), Columns: columns}
+	if maxRows > 0 && len(cells) > maxRows {
+		cells = cells[:maxRows]
+		table.Truncated = true
+	}
+	table.Cells = cells
+	table.Markdown = markdownTable(cells, columns)
+	return table
+}
+
+// markdownTable renders rows as a Markdown table, the first row being the
+// header
+func markdownTable(rows [][]string, columns int) string {
+	if len(rows) == 0 || columns == 0 {
+		return "(empty)"
+	}
+	var b strings.Builder
+	writeRow := func(row []string) {
+		b.WriteString("|")
+		for c := 0; c < columns; c++ {
+			cell := ""
+			if c < len(row) {
+				cell = markdownCell(row[c])
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(rows[0])
+	b.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// markdownCell keeps a cell's text from breaking the table
+func markdownCell(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\n", "<br>")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
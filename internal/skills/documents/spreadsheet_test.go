@@ -0,0 +1,153 @@
+package documents
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func writeXLSX(t *testing.T, dir string) string {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetSheetRow("Sheet1", "A1", &[]interface{}{"Item", "Qty", "Price"})
+	f.SetSheetRow("Sheet1", "A2", &[]interface{}{"Apples", 3, 1.5})
+	f.SetSheetRow("Sheet1", "A3", &[]interface{}{"Pears | ripe", 2, 2})
+	f.SetSheetRow("Sheet1", "A5", &[]interface{}{"Plums", 7, 0.5})
+	f.NewSheet("Totals")
+	f.SetSheetRow("Totals", "A1", &[]interface{}{"Total", 10})
+
+	// Saved without an extension, as channels often do
+	path := filepath.Join(dir, "upload")
+	if err := f.SaveAs(path + ".xlsx"); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if err := os.Rename(path+".xlsx", path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExtractSpreadsheet_XLSX(t *testing.T) {
+	path := writeXLSX(t, t.TempDir())
+	if format := DetectFormat(path); format != FormatXLSX {
+		t.Fatalf("DetectFormat = %q, want xlsx", format)
+	}
+
+	result, err := ExtractSpreadsheet(path, SpreadsheetOptions{})
+	if err != nil {
+		t.Fatalf("ExtractSpreadsheet failed: %v", err)
+	}
+	if len(result.Sheets) != 2 || len(result.Tables) != 2 {
+		t.Fatalf("Expected 2 sheets, got %v", result.Sheets)
+	}
+
+	table := result.Tables[0]
+	if table.Rows != 4 || table.Columns != 3 {
+		t.Errorf("Expected 4 rows of 3 columns (blank row dropped), got %d x %d", table.Rows, table.Columns)
+	}
+	want := "| Item | Qty | Price |\n| --- | --- | --- |\n| Apples | 3 | 1.5 |\n| Pears \\| ripe | 2 | 2 |\n| Plums | 7 | 0.5 |"
+	if table.Markdown != want {
+		t.Errorf("Markdown =\n%s\nwant\n%s", table.Markdown, want)
+	}
+	if md := result.Markdown(); !strings.Contains(md, "## Sheet1") || !strings.Contains(md, "## Totals") {
+		t.Errorf("Expected sheet headings, got\n%s", md)
+	}
+}
+
+func TestExtractSpreadsheet_SheetAndRange(t *testing.T) {
+	path := writeXLSX(t, t.TempDir())
+
+	result, err := ExtractSpreadsheet(path, SpreadsheetOptions{Sheet: "sheet1", Range: "A2:B3"})
+	if err != nil {
+		t.Fatalf("ExtractSpreadsheet failed: %v", err)
+	}
+	if len(result.Tables) != 1 || result.Tables[0].Sheet != "Sheet1" {
+		t.Fatalf("Expected only Sheet1, got %+v", result.Tables)
+	}
+	if got := result.Tables[0].Cells; len(got) != 2 || len(got[0]) != 2 || got[1][0] != "Pears | ripe" {
+		t.Errorf("Unexpected cells %v", got)
+	}
+
+	result, err = ExtractSpreadsheet(path, SpreadsheetOptions{Sheet: "Sheet1", MaxRows: 2})
+	if err != nil {
+		t.Fatalf("ExtractSpreadsheet failed: %v", err)
+	}
+	if table := result.Tables[0]; !table.Truncated || len(table.Cells) != 2 || table.Rows != 4 {
+		t.Errorf("Expected 2 of 4 rows, got %d of %d", len(table.Cells), table.Rows)
+	}
+
+	if _, err := ExtractSpreadsheet(path, SpreadsheetOptions{Sheet: "Missing"}); err == nil || !strings.Contains(err.Error(), "Totals") {
+		t.Errorf("Expected an error listing the sheets, got %v", err)
+	}
+}
+
+func TestExtractSpreadsheet_CSV(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prices.csv")
+	os.WriteFile(path, []byte("\ufeffItem;Price\nBread;2,50\nMilk;1,20\n"), 0644)
+
+	result, err := ExtractSpreadsheet(path, SpreadsheetOptions{})
+	if err != nil {
+		t.Fatalf("ExtractSpreadsheet failed: %v", err)
+	}
+	if result.Format != FormatCSV || result.Sheets[0] != "prices" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	cells := result.Tables[0].Cells
+	if len(cells) != 3 || cells[0][0] != "Item" || cells[1][1] != "2,50" {
+		t.Errorf("Expected semicolon-separated cells, got %v", cells)
+	}
+	if strings.HasPrefix(result.Markdown(), "##") {
+		t.Error("A single CSV table shouldn't get a heading")
+	}
+}
+
+func TestParseCellRange(t *testing.T) {
+	tests := []struct {
+		in                                   string
+		firstCol, firstRow, lastCol, lastRow int
+		wantErr                              bool
+	}{
+		{"", 0, 0, 0, 0, false},
+		{"A1:D20", 1, 1, 4, 20, false},
+		{"b2", 2, 2, 2, 2, false},
+		{"B:D", 2, 0, 4, 0, false},
+		{"2:50", 0, 2, 0, 50, false},
+		{"Sheet1!$A$1:$C$3", 1, 1, 3, 3, false},
+		{"D1:A1", 0, 0, 0, 0, true},
+		{"A0", 0, 0, 0, 0, true},
+		{"1A", 0, 0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		r, err := parseCellRange(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCellRange(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if r.firstCol != tt.firstCol || r.firstRow != tt.firstRow || r.lastCol != tt.lastCol || r.lastRow != tt.lastRow {
+			t.Errorf("parseCellRange(%q) = %+v", tt.in, r)
+		}
+	}
+}
+
+func TestToolFor(t *testing.T) {
+	for name, want := range map[string]string{
+		"report.PDF":  "process_pdf",
+		"budget.xlsx": "extract_spreadsheet",
+		"data.csv":    "extract_spreadsheet",
+		"letter.docx": "extract_docx",
+		"scan.jpg":    "process_image",
+		"notes.txt":   "",
+	} {
+		if got := ToolFor(name); got != want {
+			t.Errorf("ToolFor(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
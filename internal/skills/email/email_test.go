@@ -0,0 +1,272 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type sentMessage struct {
+	recipients []string
+	raw        string
+	threadID   string
+}
+
+// fakeProvider is a mailbox held in memory
+type fakeProvider struct {
+	messages []Message
+	sent     []sentMessage
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Unread(ctx context.Context, limit int) ([]Message, error) {
+	var out []Message
+	for _, m := range p.messages {
+		if m.Unread && len(out) < limit {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (p *fakeProvider) Search(ctx context.Context, query string, limit int) ([]Message, error) {
+	var out []Message
+	for _, m := range p.messages {
+		if strings.Contains(strings.ToLower(m.Subject+" "+m.Body), strings.ToLower(query)) && len(out) < limit {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (p *fakeProvider) Get(ctx context.Context, id string) (*Message, error) {
+	for _, m := range p.messages {
+		if m.ID == id {
+			return &m, nil
+		}
+	}
+	return nil, fmt.Errorf("message %s not found", id)
+}
+
+func (p *fakeProvider) Thread(ctx context.Context, id string) ([]Message, error) {
+	m, err := p.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var out []Message
+	for _, other := range p.messages {
+		if other.ThreadID == m.ThreadID {
+			out = append(out, other)
+		}
+	}
+	return out, nil
+}
+
+func (p *fakeProvider) Send(ctx context.Context, from string, recipients []string, raw []byte, threadID string) error {
+	p.sent = append(p.sent, sentMessage{recipients, string(raw), threadID})
+	return nil
+}
+
+type recordingNotifier struct {
+	sent []notify.Notification
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, notification notify.Notification) (string, error) {
+	n.sent = append(n.sent, notification)
+	return notify.StatusSent, nil
+}
+
+var codePattern = regexp.MustCompile(`approval code (\d{6})`)
+
+func (n *recordingNotifier) lastCode(t *testing.T) string {
+	require.NotEmpty(t, n.sent)
+	match := codePattern.FindStringSubmatch(n.sent[len(n.sent)-1].Body)
+	require.NotNil(t, match)
+	return match[1]
+}
+
+func setupSkill(t *testing.T) (*EmailSkill, *fakeProvider, *recordingNotifier) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	base := time.Date(2025, 5, 6, 9, 0, 0, 0, time.UTC)
+	provider := &fakeProvider{messages: []Message{
+		{
+			ID: "1", ThreadID: "t1", MessageID: "<a1@example.com>",
+			From: "Alice <alice@example.com>", To: []string{"me@example.com", "bob@example.com"},
+			Subject: "Dinner on Friday?", Date: base, Unread: false,
+			Body: "Are you free for dinner on Friday at 7?",
+		},
+		{
+			ID: "2", ThreadID: "t1", MessageID: "<b2@example.com>", References: "<a1@example.com>",
+			From: "Bob <bob@example.com>", To: []string{"alice@example.com"}, Cc: []string{"me@example.com"},
+			Subject: "Re: Dinner on Friday?", Date: base.Add(time.Hour), Unread: true,
+			Body: "Works for me. I'll book the Thai place.\n\nOn Tue, Alice wrote:\n> Are you free for dinner on Friday at 7?",
+		},
+		{
+			ID: "3", ThreadID: "t2", From: "Billing <billing@example.com>",
+			Subject: "Your invoice", Date: base.Add(2 * time.Hour), Unread: true, Body: "Invoice #42 is due.",
+		},
+	}}
+	skill := newEmailSkill(store, provider, "Me <me@example.com>", config.EmailSkillConfig{}, nil)
+	skill.registerTools()
+	notifier := &recordingNotifier{}
+	skill.SetNotifier(notifier)
+	return skill, provider, notifier
+}
+
+func TestEmailSkill_ReadAndSearch(t *testing.T) {
+	skill, _, _ := setupSkill(t)
+	ctx := context.Background()
+
+	result, err := skill.handleListUnread(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.(map[string]interface{})["count"])
+
+	result, err = skill.handleSearch(ctx, map[string]interface{}{"query": "invoice"})
+	require.NoError(t, err)
+	messages := result.(map[string]interface{})["messages"].([]map[string]interface{})
+	require.Len(t, messages, 1)
+	assert.Equal(t, "3", messages[0]["id"])
+
+	result, err = skill.handleSummarizeThread(ctx, map[string]interface{}{"message_id": "2"})
+	require.NoError(t, err)
+	thread := result.(map[string]interface{})
+	assert.Equal(t, "Dinner on Friday?", thread["subject"])
+	assert.Len(t, thread["participants"], 2)
+	last := thread["messages"].([]map[string]interface{})[1]
+	assert.Equal(t, "Works for me. I'll book the Thai place.", last["body"])
+}
+
+func TestEmailSkill_ReplyNeedsApproval(t *testing.T) {
+	skill, provider, notifier := setupSkill(t)
+	ctx := context.Background()
+
+	result, err := skill.handleDraftReply(ctx, map[string]interface{}{
+		"message_id": "2",
+		"body":       "Great, see you both there!",
+		"reply_all":  true,
+	})
+	require.NoError(t, err)
+	draft := result.(map[string]interface{})
+	assert.Equal(t, "Bob <bob@example.com>", draft["to"])
+	assert.Equal(t, "alice@example.com", draft["cc"])
+	assert.Equal(t, "Re: Dinner on Friday?", draft["subject"])
+	assert.Equal(t, true, draft["approval_sent"])
+	draftID := draft["draft_id"].(string)
+
+	// The code goes to the user, never back to the assistant
+	require.Len(t, notifier.sent, 1)
+	assert.True(t, notifier.sent[0].Secret)
+	code := notifier.lastCode(t)
+	assert.NotContains(t, fmt.Sprint(result), code)
+
+	_, err = skill.handleSend(ctx, map[string]interface{}{"draft_id": draftID, "approval_code": "000000x"})
+	assert.ErrorContains(t, err, "2 attempts left")
+	assert.Empty(t, provider.sent)
+
+	result, err = skill.handleSend(ctx, map[string]interface{}{"draft_id": draftID, "approval_code": code})
+	require.NoError(t, err)
+	assert.Equal(t, true, result.(map[string]interface{})["sent"])
+	require.Len(t, provider.sent, 1)
+	sent := provider.sent[0]
+	assert.Equal(t, []string{"bob@example.com", "alice@example.com"}, sent.recipients)
+	assert.Equal(t, "t1", sent.threadID)
+	assert.Contains(t, sent.raw, "In-Reply-To: <b2@example.com>")
+	assert.Contains(t, sent.raw, "References: <a1@example.com> <b2@example.com>")
+
+	// A draft is sent only once
+	_, err = skill.handleSend(ctx, map[string]interface{}{"draft_id": draftID, "approval_code": code})
+	assert.ErrorContains(t, err, "is sent")
+}
+
+func TestEmailSkill_DraftLimits(t *testing.T) {
+	skill, provider, notifier := setupSkill(t)
+	ctx := context.Background()
+	newDraft := func() string {
+		result, err := skill.handleDraftEmail(ctx, map[string]interface{}{
+			"to": "carol@example.com", "subject": "Hello", "body": "Hi Carol",
+		})
+		require.NoError(t, err)
+		return result.(map[string]interface{})["draft_id"].(string)
+	}
+
+	// Too many wrong codes discard the draft
+	id := newDraft()
+	for i := 0; i < MaxApprovalAttempts; i++ {
+		_, err := skill.handleSend(ctx, map[string]interface{}{"draft_id": id, "approval_code": "wrong"})
+		require.Error(t, err)
+	}
+	_, err := skill.handleSend(ctx, map[string]interface{}{"draft_id": id, "approval_code": notifier.lastCode(t)})
+	assert.ErrorContains(t, err, "discarded")
+
+	// Drafts expire
+	id = newDraft()
+	code := notifier.lastCode(t)
+	skill.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+	_, err = skill.handleSend(ctx, map[string]interface{}{"draft_id": id, "approval_code": code})
+	assert.ErrorContains(t, err, "expired")
+	skill.now = time.Now
+
+	// Another user's draft can't be sent
+	id = newDraft()
+	other := context.WithValue(ctx, "user_id", "mallory")
+	_, err = skill.handleSend(other, map[string]interface{}{"draft_id": id, "approval_code": notifier.lastCode(t)})
+	assert.ErrorContains(t, err, "not found")
+	assert.Empty(t, provider.sent)
+
+	result, err := skill.handleListDrafts(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.(map[string]interface{})["count"])
+
+	_, err = skill.handleDraftEmail(ctx, map[string]interface{}{"to": "not an address", "subject": "x", "body": "y"})
+	assert.Error(t, err)
+}
+
+func TestEmailSkill_NoNotifier(t *testing.T) {
+	skill, _, _ := setupSkill(t)
+	skill.SetNotifier(nil)
+
+	result, err := skill.handleDraftEmail(context.Background(), map[string]interface{}{
+		"to": "carol@example.com", "subject": "Hello", "body": "Hi Carol",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, false, result.(map[string]interface{})["approval_sent"])
+}
+
+func TestParseMessage(t *testing.T) {
+	raw := "From: Alice <alice@example.com>\r\n" +
+		"To: me@example.com\r\n" +
+		"Subject: =?utf-8?q?Caf=C3=A9?=\r\n" +
+		"Message-ID: <x1@example.com>\r\n" +
+		"Date: Tue, 06 May 2025 09:00:00 +0000\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/html; charset=utf-8\r\n" +
+		"\r\n" +
+		"<html><head><style>p{}</style></head><body><p>Hello &amp; welcome</p><p>Bye</p></body></html>\r\n"
+	m, err := parseMessage(strings.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "Café", m.Subject)
+	assert.Equal(t, "<x1@example.com>", m.MessageID)
+	assert.Equal(t, `"Alice" <alice@example.com>`, m.From)
+	assert.Equal(t, "Hello & welcome\nBye", m.Body)
+
+	built := buildMessage("Me <me@example.com>", &Draft{To: "alice@example.com", Subject: "Re: Café", Body: "Thanks!"}, time.Now())
+	parsed, err := parseMessage(strings.NewReader(string(built)))
+	require.NoError(t, err)
+	assert.Equal(t, "Re: Café", parsed.Subject)
+	assert.Equal(t, "Thanks!", parsed.Body)
+}
@@ -0,0 +1,295 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const gmailAPI = "https://gmail.googleapis.com/gmail/v1/users/me"
+
+// GmailScopes are the OAuth scopes the Gmail provider asks for
+var GmailScopes = []string{
+	"https://www.googleapis.com/auth/gmail.readonly",
+	"https://www.googleapis.com/auth/gmail.send",
+}
+
+// GmailConfig contains the Gmail OAuth client settings
+type GmailConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// NewGmailOAuthConfig returns the OAuth configuration for Gmail
+func NewGmailOAuthConfig(cfg GmailConfig) *oauth2.Config {
+	redirect := cfg.RedirectURL
+	if redirect == "" {
+		redirect = "urn:ietf:wg:oauth:2.0:oob"
+	}
+	return &oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  redirect,
+		Scopes:       GmailScopes,
+		Endpoint:     google.Endpoint,
+	}
+}
+
+// GmailProvider reads and sends mail through the Gmail REST API
+type GmailProvider struct {
+	oauth   *oauth2.Config
+	store   *Store
+	baseURL string
+	client  *http.Client // overrides the OAuth client in tests
+}
+
+// NewGmailProvider creates a Gmail provider using the tokens saved in store
+func NewGmailProvider(cfg GmailConfig, store *Store) *GmailProvider {
+	return &GmailProvider{
+		oauth:   NewGmailOAuthConfig(cfg),
+		store:   store,
+		baseURL: gmailAPI,
+	}
+}
+
+// Name identifies the provider
+func (g *GmailProvider) Name() string { return "gmail" }
+
+// httpClient returns a client authorized with the saved token, saving it
+// again when it was refreshed
+func (g *GmailProvider) httpClient(ctx context.Context) (*http.Client, error) {
+	if g.client != nil {
+		return g.client, nil
+	}
+	creds, err := g.store.Credentials("gmail")
+	if err != nil {
+		return nil, err
+	}
+	if creds == nil {
+		return nil, fmt.Errorf("gmail is not connected; use connect_gmail first")
+	}
+	token := &oauth2.Token{
+		AccessToken:  creds.AccessToken,
+		RefreshToken: creds.RefreshToken,
+		Expiry:       creds.TokenExpiry,
+		TokenType:    "Bearer",
+	}
+	fresh, err := g.oauth.TokenSource(ctx, token).Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh gmail token: %w", err)
+	}
+	if fresh.AccessToken != creds.AccessToken {
+		creds.AccessToken = fresh.AccessToken
+		creds.TokenExpiry = fresh.Expiry
+		if fresh.RefreshToken != "" {
+			creds.RefreshToken = fresh.RefreshToken
+		}
+		g.store.SaveCredentials(creds)
+	}
+	return g.oauth.Client(ctx, fresh), nil
+}
+
+func (g *GmailProvider) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	client, err := g.httpClient(ctx)
+	if err != nil {
+		return err
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, g.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gmail request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("gmail API error: %s - %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+type gmailMessage struct {
+	ID           string   `json:"id"`
+	ThreadID     string   `json:"threadId"`
+	LabelIDs     []string `json:"labelIds"`
+	Snippet      string   `json:"snippet"`
+	InternalDate string   `json:"internalDate"`
+	Raw          string   `json:"raw"`
+	Payload      struct {
+		Headers []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"headers"`
+	} `json:"payload"`
+}
+
+func (m *gmailMessage) header(name string) string {
+	for _, h := range m.Payload.Headers {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+func (m *gmailMessage) unread() bool {
+	for _, label := range m.LabelIDs {
+		if label == "UNREAD" {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *gmailMessage) date() time.Time {
+	ms, err := strconv.ParseInt(m.InternalDate, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+// Unread returns up to limit unread inbox messages, newest first
+func (g *GmailProvider) Unread(ctx context.Context, limit int) ([]Message, error) {
+	return g.Search(ctx, "is:unread in:inbox", limit)
+}
+
+// Search returns up to limit messages matching a Gmail search query
+func (g *GmailProvider) Search(ctx context.Context, query string, limit int) ([]Message, error) {
+	params := neturl.Values{}
+	params.Set("q", query)
+	params.Set("maxResults", strconv.Itoa(limit))
+	var list struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
+	if err := g.do(ctx, http.MethodGet, "/messages?"+params.Encode(), nil, &list); err != nil {
+		return nil, err
+	}
+
+	messages := make([]Message, 0, len(list.Messages))
+	for _, item := range list.Messages {
+		var gm gmailMessage
+		path := "/messages/" + neturl.PathEscape(item.ID) +
+			"?format=metadata&metadataHeaders=From&metadataHeaders=To&metadataHeaders=Cc&metadataHeaders=Subject"
+		if err := g.do(ctx, http.MethodGet, path, nil, &gm); err != nil {
+			return nil, err
+		}
+		m := Message{
+			ID:       gm.ID,
+			ThreadID: gm.ThreadID,
+			From:     gm.header("From"),
+			Subject:  gm.header("Subject"),
+			Date:     gm.date(),
+			Snippet:  truncate(gm.Snippet, 160),
+			Unread:   gm.unread(),
+		}
+		if to := gm.header("To"); to != "" {
+			m.To = strings.Split(to, ", ")
+		}
+		if cc := gm.header("Cc"); cc != "" {
+			m.Cc = strings.Split(cc, ", ")
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// Get returns a message with its body
+func (g *GmailProvider) Get(ctx context.Context, id string) (*Message, error) {
+	var gm gmailMessage
+	if err := g.do(ctx, http.MethodGet, "/messages/"+neturl.PathEscape(id)+"?format=raw", nil, &gm); err != nil {
+		return nil, err
+	}
+	raw, err := base64.URLEncoding.DecodeString(gm.Raw)
+	if err != nil {
+		raw, err = base64.RawURLEncoding.DecodeString(gm.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode message %s: %w", id, err)
+		}
+	}
+	m, err := parseMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	m.ID = gm.ID
+	m.ThreadID = gm.ThreadID
+	m.Unread = gm.unread()
+	if m.Date.IsZero() {
+		m.Date = gm.date()
+	}
+	return m, nil
+}
+
+// Thread returns the messages in a message's thread, oldest first
+func (g *GmailProvider) Thread(ctx context.Context, id string) ([]Message, error) {
+	m, err := g.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var thread struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
+	if err := g.do(ctx, http.MethodGet, "/threads/"+neturl.PathEscape(m.ThreadID)+"?format=minimal", nil, &thread); err != nil {
+		return nil, err
+	}
+	ids := thread.Messages
+	if len(ids) > MaxThreadMessages {
+		ids = ids[len(ids)-MaxThreadMessages:]
+	}
+
+	messages := make([]Message, 0, len(ids))
+	for _, item := range ids {
+		if item.ID == m.ID {
+			messages = append(messages, *m)
+			continue
+		}
+		msg, err := g.Get(ctx, item.ID)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *msg)
+	}
+	return messages, nil
+}
+
+// Send sends a message, keeping it in its Gmail thread when replying
+func (g *GmailProvider) Send(ctx context.Context, from string, recipients []string, raw []byte, threadID string) error {
+	body := map[string]string{"raw": base64.URLEncoding.EncodeToString(raw)}
+	if threadID != "" {
+		body["threadId"] = threadID
+	}
+	return g.do(ctx, http.MethodPost, "/messages/send", body, nil)
+}
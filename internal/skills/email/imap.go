@@ -0,0 +1,383 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	_ "github.com/emersion/go-message/charset" // decode non-UTF-8 messages
+	"github.com/emersion/go-message/mail"
+)
+
+// MaxThreadMessages caps how many messages a thread returns
+const MaxThreadMessages = 20
+
+// IMAPConfig holds the servers and login for an IMAP/SMTP mailbox
+type IMAPConfig struct {
+	IMAPHost string // host:port, TLS
+	SMTPHost string // host:port; 465 uses TLS, other ports STARTTLS
+	Username string
+	Password string
+	Mailbox  string
+}
+
+// IMAPProvider reads mail over IMAP and sends it over SMTP. Each call opens
+// its own connection.
+type IMAPProvider struct {
+	cfg IMAPConfig
+}
+
+// NewIMAPProvider creates a provider for cfg
+func NewIMAPProvider(cfg IMAPConfig) (*IMAPProvider, error) {
+	if cfg.IMAPHost == "" || cfg.SMTPHost == "" {
+		return nil, fmt.Errorf("imap_host and smtp_host are required")
+	}
+	if cfg.Username == "" || cfg.Password == "" {
+		return nil, fmt.Errorf("username and password are required")
+	}
+	if cfg.Mailbox == "" {
+		cfg.Mailbox = "INBOX"
+	}
+	return &IMAPProvider{cfg: cfg}, nil
+}
+
+// Name identifies the provider
+func (p *IMAPProvider) Name() string { return "imap" }
+
+func (p *IMAPProvider) connect() (*client.Client, error) {
+	c, err := client.DialTLS(p.cfg.IMAPHost, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", p.cfg.IMAPHost, err)
+	}
+	c.Timeout = 30 * time.Second
+	if err := c.Login(p.cfg.Username, p.cfg.Password); err != nil {
+		c.Logout()
+		return nil, fmt.Errorf("imap login failed: %w", err)
+	}
+	// Read-only, so reading a message doesn't mark it as read
+	if _, err := c.Select(p.cfg.Mailbox, true); err != nil {
+		c.Logout()
+		return nil, fmt.Errorf("failed to open %s: %w", p.cfg.Mailbox, err)
+	}
+	return c, nil
+}
+
+// Unread returns up to limit unread messages, newest first
+func (p *IMAPProvider) Unread(ctx context.Context, limit int) ([]Message, error) {
+	criteria := imap.NewSearchCriteria()
+	criteria.WithoutFlags = []string{imap.SeenFlag}
+	return p.search(criteria, limit)
+}
+
+// Search returns up to limit messages containing query, newest first
+func (p *IMAPProvider) Search(ctx context.Context, query string, limit int) ([]Message, error) {
+	criteria := imap.NewSearchCriteria()
+	criteria.Text = strings.Fields(query)
+	return p.search(criteria, limit)
+}
+
+func (p *IMAPProvider) search(criteria *imap.SearchCriteria, limit int) ([]Message, error) {
+	c, err := p.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Logout()
+
+	uids, err := c.UidSearch(criteria)
+	if err != nil {
+		return nil, fmt.Errorf("imap search failed: %w", err)
+	}
+	// UIDs grow with arrival, so the last ones are the newest
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+	if len(uids) > limit {
+		uids = uids[len(uids)-limit:]
+	}
+	if len(uids) == 0 {
+		return nil, nil
+	}
+
+	seqset := new(imap.SeqSet)
+	seqset.AddNum(uids...)
+	items := []imap.FetchItem{imap.FetchEnvelope, imap.FetchFlags, imap.FetchUid}
+	messages, err := fetch(c, seqset, items, nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].Date.After(messages[j].Date) })
+	return messages, nil
+}
+
+// Get returns a message with its body
+func (p *IMAPProvider) Get(ctx context.Context, id string) (*Message, error) {
+	uid, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message id %q", id)
+	}
+	c, err := p.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Logout()
+
+	seqset := new(imap.SeqSet)
+	seqset.AddNum(uint32(uid))
+	section := &imap.BodySectionName{Peek: true}
+	items := []imap.FetchItem{imap.FetchFlags, imap.FetchUid, section.FetchItem()}
+	messages, err := fetch(c, seqset, items, section)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("message %s not found", id)
+	}
+	return &messages[0], nil
+}
+
+// Thread returns the messages sharing the message's subject, oldest first.
+// IMAP has no portable threading, so replies are matched by subject.
+func (p *IMAPProvider) Thread(ctx context.Context, id string) ([]Message, error) {
+	m, err := p.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	subject := baseSubject(m.Subject)
+	if subject == "" {
+		return []Message{*m}, nil
+	}
+
+	c, err := p.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Logout()
+
+	criteria := imap.NewSearchCriteria()
+	criteria.Header.Add("Subject", subject)
+	uids, err := c.UidSearch(criteria)
+	if err != nil {
+		return nil, fmt.Errorf("imap search failed: %w", err)
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+	if len(uids) > MaxThreadMessages {
+		uids = uids[len(uids)-MaxThreadMessages:]
+	}
+	if len(uids) == 0 {
+		return []Message{*m}, nil
+	}
+
+	seqset := new(imap.SeqSet)
+	seqset.AddNum(uids...)
+	section := &imap.BodySectionName{Peek: true}
+	items := []imap.FetchItem{imap.FetchFlags, imap.FetchUid, section.FetchItem()}
+	messages, err := fetch(c, seqset, items, section)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].Date.Before(messages[j].Date) })
+	return messages, nil
+}
+
+// fetch fetches messages by UID, parsing the body section when given
+func fetch(c *client.Client, seqset *imap.SeqSet, items []imap.FetchItem, section *imap.BodySectionName) ([]Message, error) {
+	ch := make(chan *imap.Message, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.UidFetch(seqset, items, ch)
+	}()
+
+	var messages []Message
+	var parseErr error
+	for im := range ch {
+		var m *Message
+		if section != nil {
+			body := im.GetBody(section)
+			if body == nil {
+				continue
+			}
+			parsed, err := parseMessage(body)
+			if err != nil {
+				parseErr = err
+				continue
+			}
+			m = parsed
+		} else {
+			m = fromEnvelope(im.Envelope)
+		}
+		m.ID = strconv.FormatUint(uint64(im.Uid), 10)
+		m.Unread = !hasFlag(im.Flags, imap.SeenFlag)
+		messages = append(messages, *m)
+	}
+	if err := <-done; err != nil {
+		return nil, fmt.Errorf("imap fetch failed: %w", err)
+	}
+	if len(messages) == 0 && parseErr != nil {
+		return nil, parseErr
+	}
+	return messages, nil
+}
+
+func fromEnvelope(env *imap.Envelope) *Message {
+	m := &Message{}
+	if env == nil {
+		return m
+	}
+	m.Subject = env.Subject
+	m.Date = env.Date
+	m.MessageID = env.MessageId
+	if len(env.From) > 0 {
+		m.From = formatIMAPAddress(env.From[0])
+	}
+	for _, a := range env.To {
+		m.To = append(m.To, formatIMAPAddress(a))
+	}
+	for _, a := range env.Cc {
+		m.Cc = append(m.Cc, formatIMAPAddress(a))
+	}
+	return m
+}
+
+func formatIMAPAddress(a *imap.Address) string {
+	if a.PersonalName != "" {
+		return fmt.Sprintf("%s <%s>", a.PersonalName, a.Address())
+	}
+	return a.Address()
+}
+
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMessage reads an RFC 5322 message, keeping the plain-text body or
+// the text of the HTML body when there is no plain one
+func parseMessage(r io.Reader) (*Message, error) {
+	mr, err := mail.CreateReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse message: %w", err)
+	}
+	defer mr.Close()
+
+	m := &Message{}
+	h := mr.Header
+	m.Subject, _ = h.Subject()
+	m.Date, _ = h.Date()
+	m.MessageID, _ = h.MessageID()
+	if m.MessageID != "" {
+		m.MessageID = "<" + m.MessageID + ">"
+	}
+	m.References = h.Get("References")
+	m.From = formatAddresses(h, "From")
+	m.ReplyTo = formatAddresses(h, "Reply-To")
+	if to := formatAddresses(h, "To"); to != "" {
+		m.To = strings.Split(to, ", ")
+	}
+	if cc := formatAddresses(h, "Cc"); cc != "" {
+		m.Cc = strings.Split(cc, ", ")
+	}
+
+	var plain, htmlBody string
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Keep what was read; one bad part shouldn't hide the message
+			break
+		}
+		inline, ok := part.Header.(*mail.InlineHeader)
+		if !ok {
+			continue
+		}
+		contentType, _, _ := inline.ContentType()
+		data, err := io.ReadAll(io.LimitReader(part.Body, 1<<20))
+		if err != nil {
+			continue
+		}
+		switch {
+		case contentType == "text/plain" && plain == "":
+			plain = string(data)
+		case contentType == "text/html" && htmlBody == "":
+			htmlBody = string(data)
+		}
+	}
+	m.Body = strings.TrimSpace(strings.ReplaceAll(plain, "\r\n", "\n"))
+	if m.Body == "" && htmlBody != "" {
+		m.Body = htmlToText(htmlBody)
+	}
+	m.Snippet = makeSnippet(stripQuoted(m.Body))
+	return m, nil
+}
+
+func formatAddresses(h mail.Header, key string) string {
+	addrs, err := h.AddressList(key)
+	if err != nil || len(addrs) == 0 {
+		return strings.TrimSpace(h.Get(key))
+	}
+	out := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		out = append(out, a.String())
+	}
+	return strings.Join(out, ", ")
+}
+
+// Send sends a message over SMTP
+func (p *IMAPProvider) Send(ctx context.Context, from string, recipients []string, raw []byte, threadID string) error {
+	host, port, err := net.SplitHostPort(p.cfg.SMTPHost)
+	if err != nil {
+		return fmt.Errorf("invalid smtp_host %q: %w", p.cfg.SMTPHost, err)
+	}
+	auth := smtp.PlainAuth("", p.cfg.Username, p.cfg.Password, host)
+	sender := bareAddress(from)
+
+	if port != "465" {
+		// SendMail upgrades with STARTTLS when the server offers it
+		return smtp.SendMail(p.cfg.SMTPHost, auth, sender, recipients, raw)
+	}
+
+	conn, err := tls.Dial("tcp", p.cfg.SMTPHost, &tls.Config{ServerName: host})
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", p.cfg.SMTPHost, err)
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if err := c.Auth(auth); err != nil {
+		return fmt.Errorf("smtp login failed: %w", err)
+	}
+	if err := c.Mail(sender); err != nil {
+		return err
+	}
+	for _, rcpt := range recipients {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("recipient %s refused: %w", rcpt, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
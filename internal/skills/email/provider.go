@@ -0,0 +1,178 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Provider reads and sends mail for one mailbox
+type Provider interface {
+	// Name identifies the provider, e.g. imap or gmail
+	Name() string
+	// Unread returns up to limit unread messages in the inbox, newest first
+	Unread(ctx context.Context, limit int) ([]Message, error)
+	// Search returns up to limit messages matching query, newest first
+	Search(ctx context.Context, query string, limit int) ([]Message, error)
+	// Get returns a message with its body
+	Get(ctx context.Context, id string) (*Message, error)
+	// Thread returns the messages in the thread containing a message, oldest
+	// first
+	Thread(ctx context.Context, id string) ([]Message, error)
+	// Send sends an RFC 5322 message to the recipients
+	Send(ctx context.Context, from string, recipients []string, raw []byte, threadID string) error
+}
+
+// buildMessage renders a draft as an RFC 5322 plain-text message
+func buildMessage(from string, d *Draft, now time.Time) []byte {
+	var b bytes.Buffer
+	header := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\r\n", name, value)
+		}
+	}
+	header("From", from)
+	header("To", d.To)
+	header("Cc", d.Cc)
+	header("Subject", mime.QEncoding.Encode("utf-8", d.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", newMessageID(from))
+	header("In-Reply-To", d.InReplyTo)
+	header("References", d.References)
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	b.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&b)
+	qp.Write([]byte(strings.ReplaceAll(d.Body, "\n", "\r\n")))
+	qp.Close()
+	return b.Bytes()
+}
+
+func newMessageID(from string) string {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if i := strings.LastIndex(addr.Address, "@"); i >= 0 {
+			domain = addr.Address[i+1:]
+		}
+	}
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(buf), domain)
+}
+
+// parseAddresses checks a comma-separated address list and returns the bare
+// addresses
+func parseAddresses(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	addrs, err := mail.ParseAddressList(list)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address list %q: %w", list, err)
+	}
+	out := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		out = append(out, a.Address)
+	}
+	return out, nil
+}
+
+// bareAddress returns the address part of "Name <addr>"
+func bareAddress(s string) string {
+	if addr, err := mail.ParseAddress(s); err == nil {
+		return addr.Address
+	}
+	return strings.TrimSpace(s)
+}
+
+// replySubject prefixes a subject with "Re: " unless it already has one
+func replySubject(subject string) string {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(subject)), "re:") {
+		return subject
+	}
+	return "Re: " + subject
+}
+
+// baseSubject strips reply and forward prefixes
+func baseSubject(subject string) string {
+	s := strings.TrimSpace(subject)
+	for {
+		lower := strings.ToLower(s)
+		switch {
+		case strings.HasPrefix(lower, "re:"), strings.HasPrefix(lower, "fw:"):
+			s = strings.TrimSpace(s[3:])
+		case strings.HasPrefix(lower, "fwd:"):
+			s = strings.TrimSpace(s[4:])
+		default:
+			return s
+		}
+	}
+}
+
+var quoteHeader = regexp.MustCompile(`(?m)^On .+wrote:\s*$`)
+
+// stripQuoted removes quoted replies so a thread reads without repeating
+// itself
+func stripQuoted(body string) string {
+	if loc := quoteHeader.FindStringIndex(body); loc != nil {
+		body = body[:loc[0]]
+	}
+	var kept []string
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), ">") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+var (
+	htmlBlocks = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	htmlBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|tr|li|h[1-6])>`)
+	htmlTags   = regexp.MustCompile(`<[^>]+>`)
+	blankRuns  = regexp.MustCompile(`\n{3,}`)
+	spaceRuns  = regexp.MustCompile(`[ \t]+`)
+)
+
+// htmlToText turns an HTML body into readable text
+func htmlToText(s string) string {
+	s = htmlBlocks.ReplaceAllString(s, "")
+	s = htmlBreaks.ReplaceAllString(s, "\n")
+	s = htmlTags.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = spaceRuns.ReplaceAllString(s, " ")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(blankRuns.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// truncate shortens s to at most n bytes on a rune boundary
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return strings.TrimSpace(s[:n]) + "…"
+}
+
+// makeSnippet returns the start of a body on one line
+func makeSnippet(body string) string {
+	return truncate(strings.Join(strings.Fields(body), " "), 160)
+}
@@ -0,0 +1,91 @@
+package email
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"gorm.io/gorm"
+)
+
+// Store handles draft and credential persistence
+type Store struct {
+	db *gorm.DB
+}
+
+func init() {
+	store.RegisterMigrations("email", store.Migration{
+		Version: 1,
+		Name:    "create email tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Draft{}, &Credentials{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Draft{}, &Credentials{})
+		},
+	})
+}
+
+// NewStore creates a new email store
+func NewStore(db *gorm.DB) (*Store, error) {
+	if err := store.Migrate(db, "email"); err != nil {
+		return nil, fmt.Errorf("failed to migrate email schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// CreateDraft saves a new draft
+func (s *Store) CreateDraft(d *Draft) error {
+	if d.ID == "" {
+		d.ID = idgen.Generate(idgen.PrefixEmailDraft)
+	}
+	d.CreatedAt = time.Now()
+	return s.db.Create(d).Error
+}
+
+// GetDraft returns a user's draft by ID, or nil if there is none
+func (s *Store) GetDraft(userID, id string) (*Draft, error) {
+	var d Draft
+	err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&d).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &d, err
+}
+
+// UpdateDraft saves changes to a draft
+func (s *Store) UpdateDraft(d *Draft) error {
+	return s.db.Save(d).Error
+}
+
+// PendingDrafts returns a user's drafts awaiting approval, newest first,
+// marking expired ones on the way
+func (s *Store) PendingDrafts(userID string, now time.Time) ([]Draft, error) {
+	if err := s.db.Model(&Draft{}).
+		Where("user_id = ? AND status = ? AND expires_at <= ?", userID, DraftPending, now).
+		Update("status", DraftExpired).Error; err != nil {
+		return nil, err
+	}
+	var drafts []Draft
+	err := s.db.Where("user_id = ? AND status = ?", userID, DraftPending).
+		Order("created_at DESC").Find(&drafts).Error
+	return drafts, err
+}
+
+// Credentials returns the saved tokens for a provider, or nil if there are
+// none
+func (s *Store) Credentials(provider string) (*Credentials, error) {
+	var c Credentials
+	err := s.db.Where("provider = ?", provider).First(&c).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &c, err
+}
+
+// SaveCredentials saves a provider's tokens
+func (s *Store) SaveCredentials(c *Credentials) error {
+	c.UpdatedAt = time.Now()
+	return s.db.Save(c).Error
+}
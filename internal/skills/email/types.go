@@ -0,0 +1,66 @@
+package email
+
+import (
+	"time"
+)
+
+// Draft statuses
+const (
+	DraftPending   = "pending"
+	DraftSent      = "sent"
+	DraftDiscarded = "discarded"
+	DraftExpired   = "expired"
+)
+
+// Message is an email as the assistant sees it
+type Message struct {
+	ID         string    `json:"id"`
+	ThreadID   string    `json:"thread_id,omitempty"`
+	MessageID  string    `json:"-"` // RFC 5322 Message-ID, for replies
+	References string    `json:"-"`
+	From       string    `json:"from"`
+	ReplyTo    string    `json:"-"`
+	To         []string  `json:"to,omitempty"`
+	Cc         []string  `json:"cc,omitempty"`
+	Subject    string    `json:"subject"`
+	Date       time.Time `json:"date"`
+	Snippet    string    `json:"snippet,omitempty"`
+	Body       string    `json:"body,omitempty"`
+	Unread     bool      `json:"unread"`
+}
+
+// Draft is an email waiting for the user to approve it. Nothing is sent
+// without the approval code, which only the user receives.
+type Draft struct {
+	ID         string     `json:"id" gorm:"primaryKey"`
+	UserID     string     `json:"user_id" gorm:"index"`
+	To         string     `json:"to"` // comma-separated
+	Cc         string     `json:"cc,omitempty"`
+	Subject    string     `json:"subject"`
+	Body       string     `json:"body" gorm:"type:text"`
+	InReplyTo  string     `json:"in_reply_to,omitempty"`
+	References string     `json:"-" gorm:"type:text"`
+	ThreadID   string     `json:"thread_id,omitempty"`
+	Status     string     `json:"status" gorm:"index"`
+	CodeHash   string     `json:"-"`
+	Attempts   int        `json:"-"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	SentAt     *time.Time `json:"sent_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TableName sets the table name
+func (Draft) TableName() string { return "email_drafts" }
+
+// Credentials holds the OAuth tokens for a connected Gmail account
+type Credentials struct {
+	Provider     string `gorm:"primaryKey"`
+	UserID       string // who connected it
+	AccessToken  string `gorm:"type:text"`
+	RefreshToken string `gorm:"type:text"`
+	TokenExpiry  time.Time
+	UpdatedAt    time.Time
+}
+
+// TableName sets the table name
+func (Credentials) TableName() string { return "email_credentials" }
@@ -17,6 +17,7 @@ const (
 	SourceGitHub  SkillSource = "github"
 	SourceLocal   SkillSource = "local"
 	SourceMCP     SkillSource = "mcp"
+	SourcePackage SkillSource = "package"
 )
 
 // SkillStatus represents the current status of a skill
@@ -3,9 +3,11 @@ package expenses
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/locale"
 	"github.com/gmsas95/myrai-cli/internal/skills"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -15,7 +17,6 @@ import (
 type ExpensesSkill struct {
 	*skills.BaseSkill
 	store  *Store
-	parser *ExpenseParser
 	logger *zap.Logger
 }
 
@@ -29,7 +30,6 @@ func NewExpensesSkill(db *gorm.DB, logger *zap.Logger) (*ExpensesSkill, error) {
 	skill := &ExpensesSkill{
 		BaseSkill: skills.NewBaseSkill("expenses", "Expense Tracking", "1.0.0"),
 		store:     store,
-		parser:    NewExpenseParser(),
 		logger:    logger,
 	}
 	
@@ -68,6 +68,7 @@ func (e *ExpensesSkill) registerTools() {
 		},
 		{
 			Name:        "list_expenses",
+			ReadOnly:    true,
 			Description: "List your expenses for a period",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -92,6 +93,7 @@ func (e *ExpensesSkill) registerTools() {
 		},
 		{
 			Name:        "get_expense_summary",
+			ReadOnly:    true,
 			Description: "Get spending summary and insights for a period",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -131,6 +133,7 @@ func (e *ExpensesSkill) registerTools() {
 		},
 		{
 			Name:        "check_budget",
+			ReadOnly:    true,
 			Description: "Check your budget status and spending",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -161,6 +164,23 @@ func (e *ExpensesSkill) registerTools() {
 				"required": []string{"expense_id"},
 			},
 		},
+		{
+			Name:        "import_expenses_csv",
+			Description: "Import transactions from a bank or card CSV export. Rows already imported are skipped.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Path to the CSV file",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "CSV text, if not reading from a file",
+					},
+				},
+			},
+		},
 		{
 			Name:        "process_receipt",
 			Description: "Process a receipt image to extract expense data",
@@ -203,6 +223,8 @@ func (e *ExpensesSkill) handleTool(name string) skills.ToolHandler {
 			return e.handleCheckBudget(ctx, args)
 		case "delete_expense":
 			return e.handleDeleteExpense(ctx, args)
+		case "import_expenses_csv":
+			return e.handleImportCSV(ctx, args)
 		case "process_receipt":
 			return e.handleProcessReceipt(ctx, args)
 		default:
@@ -220,7 +242,8 @@ func (e *ExpensesSkill) handleAddExpense(ctx context.Context, args map[string]in
 	}
 	
 	// Parse the expense
-	parseResult, err := e.parser.ParseExpense(description)
+	loc := locale.FromContext(ctx)
+	parseResult, err := e.newParser(ctx).ParseExpense(description)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse expense: %w", err)
 	}
@@ -264,14 +287,18 @@ func (e *ExpensesSkill) handleAddExpense(ctx context.Context, args map[string]in
 		zap.String("category", expense.Category),
 	)
 	
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"expense_id": expense.ID,
-		"amount":     expense.FormatAmount(),
+		"amount":     expense.FormatAmountIn(loc),
 		"category":   expense.Category,
 		"merchant":   expense.Merchant,
-		"date":       expense.Date.Format("Jan 2, 2006"),
+		"date":       loc.Date(expense.Date),
 		"added":      true,
-	}, nil
+	}
+	if alerts := e.budgetAlerts(userID, expense.Category, loc); len(alerts) > 0 {
+		result["budget_alerts"] = alerts
+	}
+	return result, nil
 }
 
 // handleListExpenses lists expenses
@@ -284,6 +311,7 @@ func (e *ExpensesSkill) handleListExpenses(ctx context.Context, args map[string]
 	}
 	
 	userID := e.getUserID(ctx)
+	loc := locale.FromContext(ctx)
 	
 	// Build filters
 	filters := ExpenseFilters{
@@ -328,11 +356,11 @@ func (e *ExpensesSkill) handleListExpenses(ctx context.Context, args map[string]
 	for i, exp := range list.Expenses {
 		formatted[i] = map[string]interface{}{
 			"id":          exp.ID,
-			"amount":      exp.FormatAmount(),
+			"amount":      exp.FormatAmountIn(loc),
 			"description": exp.Description,
 			"category":    exp.Category,
 			"merchant":    exp.Merchant,
-			"date":        exp.Date.Format("Jan 2"),
+			"date":        loc.ShortDate(exp.Date),
 		}
 	}
 	
@@ -351,6 +379,7 @@ func (e *ExpensesSkill) handleGetSummary(ctx context.Context, args map[string]in
 	}
 	
 	userID := e.getUserID(ctx)
+	loc := locale.FromContext(ctx)
 	now := time.Now()
 	
 	var start, end time.Time
@@ -382,17 +411,21 @@ func (e *ExpensesSkill) handleGetSummary(ctx context.Context, args map[string]in
 	for cat, amount := range summary.ByCategory {
 		categories = append(categories, map[string]interface{}{
 			"category": cat,
-			"amount":   fmt.Sprintf("%.2f", amount),
+			"amount":   loc.Money(amount, ""),
 		})
 	}
 	
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"period":       summary.Period,
-		"total_spent":  fmt.Sprintf("%.2f", summary.TotalSpent),
-		"total_income": fmt.Sprintf("%.2f", summary.TotalIncome),
-		"net":          fmt.Sprintf("%.2f", summary.NetAmount),
+		"total_spent":  loc.Money(summary.TotalSpent, ""),
+		"total_income": loc.Money(summary.TotalIncome, ""),
+		"net":          loc.Money(summary.NetAmount, ""),
 		"categories":   categories,
-	}, nil
+	}
+	if alerts := e.budgetAlerts(userID, "", loc); len(alerts) > 0 {
+		result["budget_alerts"] = alerts
+	}
+	return result, nil
 }
 
 // handleAddBudget adds a budget
@@ -514,7 +547,8 @@ func (e *ExpensesSkill) handleProcessReceipt(ctx context.Context, args map[strin
 		}, nil
 	}
 	
-	receipt, err := e.parser.ParseReceipt(ocrText)
+	parser := e.newParser(ctx)
+	receipt, err := parser.ParseReceipt(ocrText)
 	if err != nil {
 		return nil, err
 	}
@@ -526,7 +560,7 @@ func (e *ExpensesSkill) handleProcessReceipt(ctx context.Context, args map[strin
 		Amount:      receipt.Total,
 		Currency:    receipt.Currency,
 		Description: fmt.Sprintf("Receipt from %s", receipt.Merchant),
-		Category:    e.parser.inferCategory("", receipt.Merchant),
+		Category:    parser.inferCategory("", receipt.Merchant),
 		Merchant:    receipt.Merchant,
 		Date:        receipt.Date,
 		HasReceipt:  true,
@@ -541,12 +575,89 @@ func (e *ExpensesSkill) handleProcessReceipt(ctx context.Context, args map[strin
 		"expense_id": expense.ID,
 		"merchant":   receipt.Merchant,
 		"total":      receipt.Total,
-		"date":       receipt.Date.Format("Jan 2, 2006"),
+		"date":       locale.FromContext(ctx).Date(receipt.Date),
 		"items":      len(receipt.Items),
 		"confidence": receipt.Confidence,
 	}, nil
 }
 
+// handleImportCSV imports a bank export
+func (e *ExpensesSkill) handleImportCSV(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	path, _ := args["path"].(string)
+	content, _ := args["content"].(string)
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		content = string(data)
+	}
+	if strings.TrimSpace(content) == "" {
+		return nil, fmt.Errorf("path or content is required")
+	}
+
+	loc := locale.FromContext(ctx)
+	expenses, problems, err := ParseBankCSV(strings.NewReader(content), loc, e.newParser(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	userID := e.getUserID(ctx)
+	imported, duplicates, err := e.store.ImportExpenses(userID, expenses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import expenses: %w", err)
+	}
+
+	e.logger.Info("Expenses imported",
+		zap.Int("imported", imported),
+		zap.Int("duplicates", duplicates),
+		zap.Int("skipped", len(problems)),
+	)
+
+	result := map[string]interface{}{
+		"imported":   imported,
+		"duplicates": duplicates,
+		"skipped":    len(problems),
+	}
+	if len(problems) > 0 {
+		result["errors"] = problems
+	}
+	if alerts := e.budgetAlerts(userID, "", loc); len(alerts) > 0 {
+		result["budget_alerts"] = alerts
+	}
+	return result, nil
+}
+
+// budgetAlerts describes active budgets past their alert threshold. With a
+// category, only that category's budgets and overall budgets are checked.
+func (e *ExpensesSkill) budgetAlerts(userID, category string, loc locale.Locale) []string {
+	budgets, err := e.store.GetBudgets(userID, true)
+	if err != nil {
+		e.logger.Warn("Failed to check budgets", zap.Error(err))
+		return nil
+	}
+
+	var alerts []string
+	for i := range budgets {
+		budget := &budgets[i]
+		if category != "" && budget.Category != "" && budget.Category != category {
+			continue
+		}
+		status, err := e.store.GetBudgetStatus(budget)
+		if err != nil || !status.AlertTriggered {
+			continue
+		}
+		spent := fmt.Sprintf("%s of %s", loc.Money(status.SpentAmount, ""), loc.Money(status.BudgetAmount, ""))
+		if status.IsOverBudget {
+			alerts = append(alerts, fmt.Sprintf("%s is over budget: %s", status.Name, spent))
+		} else {
+			alerts = append(alerts, fmt.Sprintf("%s is %.0f%% used: %s", status.Name, status.PercentUsed, spent))
+		}
+	}
+	return alerts
+}
+
 // Helper methods
 
 func (e *ExpensesSkill) getUserID(ctx context.Context) string {
@@ -556,6 +667,11 @@ func (e *ExpensesSkill) getUserID(ctx context.Context) string {
 	return "default_user"
 }
 
+// newParser returns a parser for the current time in the caller's locale
+func (e *ExpensesSkill) newParser(ctx context.Context) *ExpenseParser {
+	return NewExpenseParser().WithLocale(locale.FromContext(ctx))
+}
+
 func (e *ExpensesSkill) parseDate(dateStr string) time.Time {
 	switch strings.ToLower(dateStr) {
 	case "today":
@@ -2,9 +2,11 @@ package expenses
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/locale"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -201,6 +203,44 @@ func TestExpenseParser_ExtractAmount(t *testing.T) {
 	}
 }
 
+func TestExpenseParser_Locale(t *testing.T) {
+	my, _ := locale.ForLanguage("ms-MY")
+	ref := time.Date(2025, 3, 5, 10, 0, 0, 0, time.UTC)
+	parser := NewExpenseParser().WithReference(ref).WithLocale(my)
+	
+	amount, currency := parser.extractAmount("nasi lemak RM8.50")
+	assert.InDelta(t, 8.50, amount, 0.01)
+	assert.Equal(t, "MYR", currency)
+	
+	// Amounts without a currency fall back to the user's
+	_, currency = parser.extractAmount("no amount here")
+	assert.Equal(t, "MYR", currency)
+	
+	// Weeks start on Monday
+	assert.Equal(t, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), parser.extractDate("this week"))
+	
+	receipt, err := parser.ParseReceipt("KEDAI RUNCIT\n04/03/2025\nTOTAL: RM12.00")
+	require.NoError(t, err)
+	assert.Equal(t, time.March, receipt.Date.Month())
+	assert.Equal(t, 4, receipt.Date.Day())
+	assert.Equal(t, "MYR", receipt.Currency)
+}
+
+func TestExpensesSkill_LocaleFormatting(t *testing.T) {
+	skill, _ := setupExpensesSkill(t)
+	my, _ := locale.ForLanguage("ms-MY")
+	ctx := locale.WithLocale(context.WithValue(context.Background(), "user_id", "user1"), my)
+	
+	result, err := skill.handleAddExpense(ctx, map[string]interface{}{
+		"description": "Lunch RM15 at Mamak",
+		"date":        "2025-03-04",
+	})
+	require.NoError(t, err)
+	resultMap := result.(map[string]interface{})
+	assert.Equal(t, "RM15.00", resultMap["amount"])
+	assert.Equal(t, "4 Mar 2025", resultMap["date"])
+}
+
 func TestExpenseParser_InferCategory(t *testing.T) {
 	parser := NewExpenseParser()
 	
@@ -315,6 +355,87 @@ func TestExpensesSkill_AddAndCheckBudget(t *testing.T) {
 	assert.GreaterOrEqual(t, len(budgets), 1)
 }
 
+func TestExpensesSkill_BudgetAlertOnAdd(t *testing.T) {
+	skill, _ := setupExpensesSkill(t)
+	ctx := context.WithValue(context.Background(), "user_id", "user1")
+
+	_, err := skill.handleAddBudget(ctx, map[string]interface{}{
+		"category": "food",
+		"amount":   float64(50),
+	})
+	require.NoError(t, err)
+
+	result, err := skill.handleAddExpense(ctx, map[string]interface{}{
+		"description": "Lunch $20",
+		"category":    "food",
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, result.(map[string]interface{}), "budget_alerts")
+
+	result, err = skill.handleAddExpense(ctx, map[string]interface{}{
+		"description": "Dinner $25",
+		"category":    "food",
+	})
+	require.NoError(t, err)
+	alerts := result.(map[string]interface{})["budget_alerts"].([]string)
+	require.Len(t, alerts, 1)
+	assert.Contains(t, alerts[0], "90% used")
+}
+
+func TestParseBankCSV_SignedAmounts(t *testing.T) {
+	csv := "Date,Description,Amount\n" +
+		"2025-03-01,STARBUCKS COFFEE,-4.50\n" +
+		"2025-03-01,STARBUCKS COFFEE,-4.50\n" +
+		"2025-03-02,Salary,\"2,500.00\"\n" +
+		"sometime,Broken row,-1\n"
+
+	expenses, problems, err := ParseBankCSV(strings.NewReader(csv), locale.Default(), NewExpenseParser())
+	require.NoError(t, err)
+	require.Len(t, expenses, 3)
+	assert.Len(t, problems, 1)
+
+	assert.Equal(t, 4.50, expenses[0].Amount)
+	assert.Equal(t, string(CategoryFood), expenses[0].Category)
+	assert.NotEqual(t, expenses[0].SourceID, expenses[1].SourceID)
+	assert.Equal(t, -2500.0, expenses[2].Amount)
+	assert.Equal(t, string(CategoryIncome), expenses[2].Category)
+}
+
+func TestParseBankCSV_DebitCredit(t *testing.T) {
+	csv := "Transaction Date,Payee,Debit,Credit\n" +
+		"2025-03-01,Uber trip,12.00,\n" +
+		"2025-03-02,Refund,,(5.00)\n"
+
+	expenses, _, err := ParseBankCSV(strings.NewReader(csv), locale.Default(), NewExpenseParser())
+	require.NoError(t, err)
+	require.Len(t, expenses, 2)
+	assert.Equal(t, 12.0, expenses[0].Amount)
+	assert.Equal(t, string(CategoryTransport), expenses[0].Category)
+	assert.Equal(t, -5.0, expenses[1].Amount)
+
+	_, _, err = ParseBankCSV(strings.NewReader("Payee,Amount\nx,1\n"), locale.Default(), NewExpenseParser())
+	assert.Error(t, err)
+}
+
+func TestExpensesSkill_ImportCSVSkipsDuplicates(t *testing.T) {
+	skill, _ := setupExpensesSkill(t)
+	ctx := context.WithValue(context.Background(), "user_id", "user1")
+	csv := "Date,Description,Amount\n2025-03-01,Netflix,-15.99\n2025-03-03,Whole Foods,-82.10\n"
+
+	result, err := skill.handleImportCSV(ctx, map[string]interface{}{"content": csv})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.(map[string]interface{})["imported"])
+
+	result, err = skill.handleImportCSV(ctx, map[string]interface{}{"content": csv})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.(map[string]interface{})["imported"])
+	assert.Equal(t, 2, result.(map[string]interface{})["duplicates"])
+
+	list, err := skill.store.ListExpenses("user1", ExpenseFilters{})
+	require.NoError(t, err)
+	assert.Len(t, list.Expenses, 2)
+}
+
 // Expense Helper Tests
 
 func TestExpense_IsIncome(t *testing.T) {
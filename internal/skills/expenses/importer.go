@@ -0,0 +1,251 @@
+package expenses
+
+import (
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/locale"
+)
+
+// MaxImportRows caps how many rows one CSV import reads
+const MaxImportRows = 5000
+
+// Header names used by common bank exports, lower-cased
+var (
+	dateHeaders        = []string{"date", "transaction date", "posted date", "posting date", "booking date", "value date"}
+	descriptionHeaders = []string{"description", "payee", "merchant", "details", "narrative", "memo", "name", "transaction description"}
+	amountHeaders      = []string{"amount", "value", "transaction amount"}
+	debitHeaders       = []string{"debit", "withdrawal", "withdrawals", "money out", "paid out"}
+	creditHeaders      = []string{"credit", "deposit", "deposits", "money in", "paid in"}
+	categoryHeaders    = []string{"category"}
+	currencyHeaders    = []string{"currency"}
+)
+
+// csvColumns holds the column index of each field, or -1
+type csvColumns struct {
+	date, description, amount, debit, credit, category, currency int
+}
+
+// ParseBankCSV reads a bank export into expenses. Spending becomes a positive
+// amount and money received a negative one, matching manual entries. With a
+// single amount column, negative values are spending when any appear;
+// otherwise every row is treated as spending. Rows that cannot be read are
+// reported in the returned messages and skipped.
+func ParseBankCSV(r io.Reader, loc locale.Locale, parser *ExpenseParser) ([]Expense, []string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	cols, err := findColumns(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	if len(rows) > MaxImportRows {
+		return nil, nil, fmt.Errorf("CSV has %d rows; at most %d can be imported at once", len(rows), MaxImportRows)
+	}
+
+	// A single signed column usually means negative is money out
+	negativeIsSpending := false
+	if cols.amount >= 0 {
+		for _, row := range rows {
+			if v, ok := parseCSVAmount(field(row, cols.amount)); ok && v < 0 {
+				negativeIsSpending = true
+				break
+			}
+		}
+	}
+
+	var expenses []Expense
+	var problems []string
+	seen := make(map[string]int)
+	for i, row := range rows {
+		line := i + 2 // 1-based, after the header
+		if isBlankRow(row) {
+			continue
+		}
+
+		date, ok := parseCSVDate(field(row, cols.date), loc)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("row %d: unrecognized date %q", line, field(row, cols.date)))
+			continue
+		}
+
+		amount, ok := rowAmount(row, cols, negativeIsSpending)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("row %d: no amount", line))
+			continue
+		}
+		if amount == 0 {
+			continue
+		}
+
+		description := strings.TrimSpace(field(row, cols.description))
+		category := strings.ToLower(strings.TrimSpace(field(row, cols.category)))
+		if category == "" {
+			category = parser.inferCategory(strings.ToLower(description), "")
+		}
+		if amount < 0 && category == string(CategoryOther) {
+			category = string(CategoryIncome)
+		}
+		currency := strings.ToUpper(strings.TrimSpace(field(row, cols.currency)))
+		if currency == "" {
+			currency = loc.Currency
+		}
+
+		// Identical rows in one file are distinct transactions, so the
+		// occurrence count is part of the identity
+		key := fmt.Sprintf("%s|%.2f|%s", date.Format("2006-01-02"), amount, strings.ToLower(description))
+		seen[key]++
+
+		expenses = append(expenses, Expense{
+			Amount:      amount,
+			Currency:    currency,
+			Description: description,
+			Category:    category,
+			Date:        date,
+			Source:      "import",
+			SourceID:    importID(key, seen[key]),
+		})
+	}
+
+	return expenses, problems, nil
+}
+
+func findColumns(header []string) (csvColumns, error) {
+	cols := csvColumns{-1, -1, -1, -1, -1, -1, -1}
+	for i, h := range header {
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		assign := func(target *int, names []string) {
+			if *target >= 0 {
+				return
+			}
+			for _, n := range names {
+				if name == n {
+					*target = i
+					return
+				}
+			}
+		}
+		assign(&cols.date, dateHeaders)
+		assign(&cols.description, descriptionHeaders)
+		assign(&cols.amount, amountHeaders)
+		assign(&cols.debit, debitHeaders)
+		assign(&cols.credit, creditHeaders)
+		assign(&cols.category, categoryHeaders)
+		assign(&cols.currency, currencyHeaders)
+	}
+
+	if cols.date < 0 {
+		return cols, fmt.Errorf("CSV needs a date column (one of: %s)", strings.Join(dateHeaders, ", "))
+	}
+	if cols.amount < 0 && cols.debit < 0 {
+		return cols, fmt.Errorf("CSV needs an amount or debit column")
+	}
+	return cols, nil
+}
+
+// rowAmount returns the row's amount with spending positive
+func rowAmount(row []string, cols csvColumns, negativeIsSpending bool) (float64, bool) {
+	if cols.amount >= 0 {
+		v, ok := parseCSVAmount(field(row, cols.amount))
+		if !ok {
+			return 0, false
+		}
+		if negativeIsSpending {
+			v = -v
+		}
+		return v, true
+	}
+
+	debit, hasDebit := parseCSVAmount(field(row, cols.debit))
+	credit, hasCredit := parseCSVAmount(field(row, cols.credit))
+	switch {
+	case hasDebit && debit != 0:
+		return abs(debit), true
+	case hasCredit && credit != 0:
+		return -abs(credit), true
+	case hasDebit || hasCredit:
+		return 0, true
+	}
+	return 0, false
+}
+
+// parseCSVAmount reads amounts such as "-12.50", "$1,234.00" or "(45.00)"
+func parseCSVAmount(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+	s = strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+		return -1
+	}, s)
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	if negative {
+		v = -v
+	}
+	return v, true
+}
+
+func parseCSVDate(s string, loc locale.Locale) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range loc.NumericDateLayouts() {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func field(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
+
+func isBlankRow(row []string) bool {
+	for _, f := range row {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func importID(key string, occurrence int) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d", key, occurrence)))
+	return hex.EncodeToString(sum[:10])
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -5,17 +5,21 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/locale"
 )
 
 // ExpenseParser parses natural language expense descriptions
 type ExpenseParser struct {
 	referenceTime time.Time
+	locale        locale.Locale
 }
 
 // NewExpenseParser creates a new expense parser
 func NewExpenseParser() *ExpenseParser {
 	return &ExpenseParser{
 		referenceTime: time.Now(),
+		locale:        locale.Default(),
 	}
 }
 
@@ -25,12 +29,19 @@ func (p *ExpenseParser) WithReference(t time.Time) *ExpenseParser {
 	return p
 }
 
+// WithLocale sets the locale that supplies the default currency, the first
+// day of the week and the day/month order of receipt dates
+func (p *ExpenseParser) WithLocale(l locale.Locale) *ExpenseParser {
+	p.locale = l
+	return p
+}
+
 // ParseExpense parses natural language expense description
 func (p *ExpenseParser) ParseExpense(text string) (*ParseResult, error) {
 	text = strings.TrimSpace(text)
 	
 	result := &ParseResult{
-		Currency:   "USD",
+		Currency:   p.locale.Currency,
 		Date:       p.referenceTime,
 		Confidence: 0.5,
 	}
@@ -70,7 +81,7 @@ func (p *ExpenseParser) ParseExpense(text string) (*ParseResult, error) {
 
 // extractAmount extracts the monetary amount from text
 func (p *ExpenseParser) extractAmount(text string) (float64, string) {
-	currency := "USD"
+	currency := p.locale.Currency
 	
 	// Currency symbols/prefixes
 	currencyPatterns := []struct {
@@ -84,6 +95,8 @@ func (p *ExpenseParser) extractAmount(text string) (float64, string) {
 		{`¥([0-9,]+\.?\d*)`, "¥", "JPY"},
 		{`([0-9,]+\.?\d*)\s*USD?`, "", "USD"},
 		{`([0-9,]+\.?\d*)\s*EUR?`, "", "EUR"},
+		{`\bRM\s?([0-9,]+\.?\d*)`, "RM", "MYR"},
+		{`([0-9,]+\.?\d*)\s*MYR`, "", "MYR"},
 	}
 	
 	for _, cp := range currencyPatterns {
@@ -184,14 +197,14 @@ func (p *ExpenseParser) extractDate(text string) time.Time {
 		return now.AddDate(0, 0, -1)
 	}
 	
-	// This week
+	// This week, from the user's first day of the week
 	if regexp.MustCompile(`(?i)\bthis week\b`).MatchString(text) {
-		return now.AddDate(0, 0, -int(now.Weekday()))
+		return p.locale.StartOfWeek(now)
 	}
 	
 	// Last week
 	if regexp.MustCompile(`(?i)\blast week\b`).MatchString(text) {
-		return now.AddDate(0, 0, -int(now.Weekday())-7)
+		return p.locale.StartOfWeek(now).AddDate(0, 0, -7)
 	}
 	
 	// Day of week
@@ -219,7 +232,7 @@ func (p *ExpenseParser) extractDate(text string) time.Time {
 func (p *ExpenseParser) ParseReceipt(ocrText string) (*ReceiptData, error) {
 	receipt := &ReceiptData{
 		Items:      []ReceiptItem{},
-		Currency:   "USD",
+		Currency:   p.locale.Currency,
 		Confidence: 0.5,
 		Date:       p.referenceTime,
 	}
@@ -270,9 +283,8 @@ func (p *ExpenseParser) ParseReceipt(ocrText string) (*ReceiptData, error) {
 		re := regexp.MustCompile(pattern)
 		matches := re.FindStringSubmatch(ocrText)
 		if len(matches) > 1 {
-			// Try to parse date
-			dateFormats := []string{"1/2/2006", "01/02/2006", "2006-01-02", "1-2-06"}
-			for _, format := range dateFormats {
+			// Try to parse date in the user's day/month order
+			for _, format := range p.locale.NumericDateLayouts() {
 				if d, err := time.Parse(format, matches[1]); err == nil {
 					receipt.Date = d
 					break
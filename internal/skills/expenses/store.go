@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/store"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -14,15 +15,34 @@ type Store struct {
 	db *gorm.DB
 }
 
+func init() {
+	store.RegisterMigrations("expenses", store.Migration{
+		Version: 1,
+		Name:    "create expenses tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Expense{}, &Budget{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Expense{}, &Budget{})
+		},
+	})
+	store.RegisterPurge("expenses", purge)
+}
+
+// purge deletes expenses dated before cutoff. Budgets are kept.
+func purge(db *gorm.DB, before time.Time) (int64, error) {
+	result := db.Where("date < ?", before).Delete(&Expense{})
+	return result.RowsAffected, result.Error
+}
+
 // NewStore creates a new expense store
 func NewStore(db *gorm.DB) (*Store, error) {
-	store := &Store{db: db}
-
-	// Auto-migrate schemas
-	if err := db.AutoMigrate(&Expense{}, &Budget{}); err != nil {
+	if err := store.Migrate(db, "expenses"); err != nil {
 		return nil, fmt.Errorf("failed to migrate expense schemas: %w", err)
 	}
 
+	store := &Store{db: db}
+
 	// Create indexes
 	store.createIndexes()
 
@@ -33,6 +53,7 @@ func NewStore(db *gorm.DB) (*Store, error) {
 func (s *Store) createIndexes() {
 	s.db.Exec("CREATE INDEX IF NOT EXISTS idx_expenses_user_date ON expenses(user_id, date)")
 	s.db.Exec("CREATE INDEX IF NOT EXISTS idx_expenses_user_category ON expenses(user_id, category)")
+	s.db.Exec("CREATE INDEX IF NOT EXISTS idx_expenses_user_source ON expenses(user_id, source_id)")
 	s.db.Exec("CREATE INDEX IF NOT EXISTS idx_budgets_user ON budgets(user_id)")
 }
 
@@ -377,3 +398,43 @@ func (s *Store) UpsertExpense(expense *Expense) error {
 		UpdateAll: true,
 	}).Create(expense).Error
 }
+
+// ImportExpenses saves imported expenses for a user, skipping any whose
+// SourceID was already imported. It returns how many were new.
+func (s *Store) ImportExpenses(userID string, expenses []Expense) (imported, duplicates int, err error) {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for i := range expenses {
+			e := &expenses[i]
+			e.UserID = userID
+
+			var count int64
+			if err := tx.Model(&Expense{}).
+				Where("user_id = ? AND source = ? AND source_id = ?", userID, e.Source, e.SourceID).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				duplicates++
+				continue
+			}
+
+			if e.ID == "" {
+				e.ID = idgen.Generate(idgen.PrefixExpense)
+			}
+			if e.Currency == "" {
+				e.Currency = "USD"
+			}
+			e.CreatedAt = time.Now()
+			e.UpdatedAt = time.Now()
+			if err := tx.Create(e).Error; err != nil {
+				return err
+			}
+			imported++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return imported, duplicates, nil
+}
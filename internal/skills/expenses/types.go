@@ -3,6 +3,8 @@ package expenses
 import (
 	"fmt"
 	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/locale"
 )
 
 // Expense represents a single expense transaction
@@ -209,6 +211,15 @@ func (e *Expense) FormatAmount() string {
 	return fmt.Sprintf("%.2f", e.Amount)
 }
 
+// FormatAmountIn formats the amount with its currency symbol in the given
+// locale, e.g. "$12.50" or "+RM100.00" for income
+func (e *Expense) FormatAmountIn(l locale.Locale) string {
+	if e.Amount < 0 {
+		return "+" + l.Money(-e.Amount, e.Currency)
+	}
+	return l.Money(e.Amount, e.Currency)
+}
+
 // GetTags returns the list of tags
 func (e *Expense) GetTags() []string {
 	if e.Tags == "" {
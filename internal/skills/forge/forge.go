@@ -0,0 +1,192 @@
+// Package forge works with repositories on self-hosted GitLab and Gitea
+// servers through one set of tools, like the github skill does for GitHub.
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/strutil"
+)
+
+// requestTimeout bounds each API call
+const requestTimeout = 30 * time.Second
+
+// Repo is a repository or project
+type Repo struct {
+	Path          string `json:"path"` // owner/name, or group/subgroup/name on GitLab
+	Description   string `json:"description,omitempty"`
+	URL           string `json:"url"`
+	DefaultBranch string `json:"default_branch,omitempty"`
+	Stars         int    `json:"stars"`
+	OpenIssues    int    `json:"open_issues,omitempty"`
+}
+
+// Issue is an issue
+type Issue struct {
+	Number  int      `json:"number"`
+	Title   string   `json:"title"`
+	State   string   `json:"state"`
+	Author  string   `json:"author,omitempty"`
+	Labels  []string `json:"labels,omitempty"`
+	URL     string   `json:"url"`
+	Created string   `json:"created_at,omitempty"`
+}
+
+// NewIssue is an issue to create
+type NewIssue struct {
+	Title  string
+	Body   string
+	Labels []string
+}
+
+// MergeRequest is a GitLab merge request or Gitea pull request
+type MergeRequest struct {
+	Number       int    `json:"number"`
+	Title        string `json:"title"`
+	State        string `json:"state"`
+	Author       string `json:"author,omitempty"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	URL          string `json:"url"`
+}
+
+// Pipeline is a CI run: a GitLab pipeline or Gitea Actions run
+type Pipeline struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Status  string `json:"status"`
+	Ref     string `json:"ref"`
+	SHA     string `json:"sha,omitempty"`
+	URL     string `json:"url"`
+	Created string `json:"created_at,omitempty"`
+}
+
+// Forge is a code hosting server. States are "open", "closed" and "all",
+// and for merge requests also "merged".
+type Forge interface {
+	Type() string
+	SearchRepos(ctx context.Context, query string, limit int) ([]Repo, error)
+	GetRepo(ctx context.Context, repo string) (*Repo, error)
+	ListIssues(ctx context.Context, repo, state string, limit int) ([]Issue, error)
+	CreateIssue(ctx context.Context, repo string, issue NewIssue) (*Issue, error)
+	ListMergeRequests(ctx context.Context, repo, state string, limit int) ([]MergeRequest, error)
+	// MergeRequestDiff returns the unified diff of a merge request
+	MergeRequestDiff(ctx context.Context, repo string, number int) (string, error)
+	ListPipelines(ctx context.Context, repo, ref string, limit int) ([]Pipeline, error)
+}
+
+// New creates the client for a configured server
+func New(cfg config.ForgeConfig) (Forge, error) {
+	base, err := url.Parse(strings.TrimRight(cfg.URL, "/"))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q", cfg.URL)
+	}
+	c := &client{base: base.String(), http: &http.Client{Timeout: requestTimeout}}
+	switch cfg.Type {
+	case "gitlab":
+		c.base += "/api/v4"
+		if cfg.Token != "" {
+			c.header, c.token = "PRIVATE-TOKEN", cfg.Token
+		}
+		return &GitLab{c: c}, nil
+	case "gitea":
+		c.base += "/api/v1"
+		if cfg.Token != "" {
+			c.header, c.token = "Authorization", "token "+cfg.Token
+		}
+		return &Gitea{c: c}, nil
+	}
+	return nil, fmt.Errorf("unknown forge type %q, use gitlab or gitea", cfg.Type)
+}
+
+// HostName is how the agent refers to a configured server: its name, or
+// the host of its URL
+func HostName(cfg config.ForgeConfig) string {
+	if cfg.Name != "" {
+		return cfg.Name
+	}
+	if u, err := url.Parse(cfg.URL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return cfg.URL
+}
+
+// client makes API requests to a server
+type client struct {
+	base   string
+	header string
+	token  string
+	http   *http.Client
+}
+
+// do sends a request to path under the API base and decodes the JSON
+// answer into out, unless out is nil
+func (c *client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	data, err := c.raw(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// raw sends a request and returns the response body
+func (c *client) raw(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.header != "" {
+		req.Header.Set(c.header, c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("API error: %s - %s", resp.Status, strutil.Truncate(string(data), 300))
+	}
+	return data, nil
+}
+
+// splitRepo splits owner/name
+func splitRepo(repo string) (string, string, error) {
+	owner, name, ok := strings.Cut(strings.Trim(repo, "/"), "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("repository must be owner/name, got %q", repo)
+	}
+	return owner, name, nil
+}
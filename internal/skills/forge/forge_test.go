@@ -0,0 +1,146 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"go.uber.org/zap"
+)
+
+func newGitLabServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "gl" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// The project path stays escaped
+		path := r.URL.EscapedPath()
+		switch {
+		case path == "/api/v4/projects/team%2Fsub%2Fapp/issues" && r.Method == http.MethodGet:
+			if r.URL.Query().Get("state") != "opened" {
+				t.Errorf("Expected GitLab's name for open, got %q", r.URL.Query().Get("state"))
+			}
+			io.WriteString(w, `[{"iid": 4, "title": "Flaky test", "state": "opened", "author": {"username": "ana"}, "labels": ["ci"], "web_url": "https://gl/4"}]`)
+		case path == "/api/v4/projects/team%2Fsub%2Fapp/issues" && r.Method == http.MethodPost:
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["labels"] != "bug,ui" || body["description"] != "Steps" {
+				t.Errorf("Unexpected issue: %v", body)
+			}
+			io.WriteString(w, `{"iid": 5, "title": "New", "state": "opened", "web_url": "https://gl/5"}`)
+		case path == "/api/v4/projects/team%2Fsub%2Fapp/merge_requests/2/diffs":
+			io.WriteString(w, `[{"old_path": "a.go", "new_path": "a.go", "diff": "@@ -1 +1 @@\n-x\n+y\n"}]`)
+		case path == "/api/v4/projects/team%2Fsub%2Fapp/pipelines":
+			io.WriteString(w, `[{"id": 9, "status": "failed", "ref": "main", "sha": "abc", "web_url": "https://gl/p/9"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func newGiteaServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token gt" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/git/api/v1/repos/me/site/labels":
+			io.WriteString(w, `[{"id": 3, "name": "Bug"}]`)
+		case "/git/api/v1/repos/me/site/issues":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if ids, _ := body["labels"].([]interface{}); len(ids) != 1 || ids[0] != 3.0 {
+				t.Errorf("Expected label IDs, got %v", body["labels"])
+			}
+			io.WriteString(w, `{"number": 8, "title": "New", "state": "open", "user": {"login": "me"}, "labels": [{"name": "Bug"}], "html_url": "https://gt/8"}`)
+		case "/git/api/v1/repos/me/site/pulls":
+			io.WriteString(w, `[{"number": 1, "title": "Open", "state": "open"}, {"number": 2, "title": "Done", "state": "closed", "merged": true, "head": {"ref": "feat"}, "base": {"ref": "main"}}]`)
+		case "/git/api/v1/repos/me/site/pulls/2.diff":
+			io.WriteString(w, "diff --git a/x b/x\n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestForgeSkill(t *testing.T) {
+	gitlab, gitea := newGitLabServer(t), newGiteaServer(t)
+	defer gitlab.Close()
+	defer gitea.Close()
+
+	s := NewForgeSkill([]config.ForgeConfig{
+		{Name: "work", Type: "gitlab", URL: gitlab.URL, Token: "gl"},
+		{Type: "gitea", URL: gitea.URL + "/git", Token: "gt"},
+		{Type: "bitbucket", URL: "https://example.com"},
+	}, zap.NewNop())
+	if s == nil || len(s.hosts) != 2 || len(s.Tools()) != 7 {
+		t.Fatalf("Expected two hosts and seven tools, got %+v", s)
+	}
+	ctx := context.Background()
+
+	// The first server is the default
+	result, err := s.handleListIssues(ctx, map[string]interface{}{"repo": "team/sub/app"})
+	if err != nil {
+		t.Fatalf("Failed to list GitLab issues: %v", err)
+	}
+	if issues := result.([]Issue); len(issues) != 1 || issues[0].Number != 4 || issues[0].State != "open" {
+		t.Errorf("Unexpected issues: %+v", issues)
+	}
+
+	result, err = s.handleCreateIssue(ctx, map[string]interface{}{
+		"repo": "team/sub/app", "title": "New", "body": "Steps", "labels": []interface{}{"bug", "ui"},
+	})
+	if err != nil || result.(*Issue).Number != 5 {
+		t.Fatalf("Failed to create GitLab issue: %+v, %v", result, err)
+	}
+
+	result, err = s.handleGetMergeRequestDiff(ctx, map[string]interface{}{"repo": gitlab.URL + "/team/sub/app/-/merge_requests/2", "number": 2.0})
+	if err != nil {
+		t.Fatalf("Failed to get GitLab diff: %v", err)
+	}
+	if diff := result.(map[string]interface{})["diff"].(string); !strings.HasPrefix(diff, "diff --git a/a.go b/a.go\n") || !strings.Contains(diff, "+y") {
+		t.Errorf("Unexpected diff: %q", diff)
+	}
+
+	result, err = s.handleListPipelines(ctx, map[string]interface{}{"host": "work", "repo": "team/sub/app"})
+	if err != nil || result.([]Pipeline)[0].Status != "failed" {
+		t.Fatalf("Failed to list pipelines: %+v, %v", result, err)
+	}
+
+	// Gitea is picked by host name, with labels looked up by name
+	giteaHost := strings.TrimPrefix(gitea.URL, "http://")
+	result, err = s.handleCreateIssue(ctx, map[string]interface{}{
+		"host": giteaHost, "repo": "me/site", "title": "New", "labels": []interface{}{"bug"},
+	})
+	if err != nil || result.(*Issue).Number != 8 {
+		t.Fatalf("Failed to create Gitea issue: %+v, %v", result, err)
+	}
+
+	result, err = s.handleListMergeRequests(ctx, map[string]interface{}{"host": giteaHost, "repo": "me/site", "state": "merged"})
+	if err != nil {
+		t.Fatalf("Failed to list Gitea pulls: %v", err)
+	}
+	if mrs := result.([]MergeRequest); len(mrs) != 1 || mrs[0].Number != 2 || mrs[0].State != "merged" || mrs[0].SourceBranch != "feat" {
+		t.Errorf("Unexpected pulls: %+v", mrs)
+	}
+
+	if _, err := s.handleListIssues(ctx, map[string]interface{}{"host": "nope", "repo": "a/b"}); err == nil {
+		t.Error("Expected an unknown host to fail")
+	}
+	if _, err := s.handleGetRepo(ctx, map[string]interface{}{"repo": "https://elsewhere.example/a/b"}); err == nil {
+		t.Error("Expected a URL on another server to fail")
+	}
+}
+
+func TestNewForgeSkillWithoutServers(t *testing.T) {
+	if s := NewForgeSkill([]config.ForgeConfig{{Type: "gitea", URL: "not a url"}}, zap.NewNop()); s != nil {
+		t.Error("Expected no skill without a usable server")
+	}
+}
@@ -0,0 +1,277 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Gitea is a Gitea or Forgejo server, using the REST API v1
+type Gitea struct {
+	c *client
+}
+
+func (g *Gitea) Type() string { return "gitea" }
+
+type giteaRepo struct {
+	FullName      string `json:"full_name"`
+	Description   string `json:"description"`
+	HTMLURL       string `json:"html_url"`
+	DefaultBranch string `json:"default_branch"`
+	Stars         int    `json:"stars_count"`
+	OpenIssues    int    `json:"open_issues_count"`
+}
+
+func (r giteaRepo) repo() Repo {
+	return Repo{
+		Path:          r.FullName,
+		Description:   r.Description,
+		URL:           r.HTMLURL,
+		DefaultBranch: r.DefaultBranch,
+		Stars:         r.Stars,
+		OpenIssues:    r.OpenIssues,
+	}
+}
+
+type giteaUser struct {
+	Login string `json:"login"`
+}
+
+// repoPath is the API path of a repository
+func (g *Gitea) repoPath(repo string) (string, error) {
+	owner, name, err := splitRepo(repo)
+	if err != nil {
+		return "", err
+	}
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name), nil
+}
+
+// giteaState maps a state to Gitea's names for it. Gitea lists merged pull
+// requests as closed.
+func giteaState(state string) string {
+	switch state {
+	case "":
+		return "open"
+	case "merged":
+		return "closed"
+	}
+	return state
+}
+
+func (g *Gitea) SearchRepos(ctx context.Context, query string, limit int) ([]Repo, error) {
+	var result struct {
+		Data []giteaRepo `json:"data"`
+	}
+	path := fmt.Sprintf("/repos/search?q=%s&limit=%d", url.QueryEscape(query), limit)
+	if err := g.c.do(ctx, "GET", path, nil, &result); err != nil {
+		return nil, err
+	}
+	repos := make([]Repo, 0, len(result.Data))
+	for _, r := range result.Data {
+		repos = append(repos, r.repo())
+	}
+	return repos, nil
+}
+
+func (g *Gitea) GetRepo(ctx context.Context, repo string) (*Repo, error) {
+	path, err := g.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	var r giteaRepo
+	if err := g.c.do(ctx, "GET", path, nil, &r); err != nil {
+		return nil, err
+	}
+	out := r.repo()
+	return &out, nil
+}
+
+type giteaIssue struct {
+	Number int       `json:"number"`
+	Title  string    `json:"title"`
+	State  string    `json:"state"`
+	User   giteaUser `json:"user"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	HTMLURL   string `json:"html_url"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (i giteaIssue) issue() Issue {
+	labels := make([]string, 0, len(i.Labels))
+	for _, l := range i.Labels {
+		labels = append(labels, l.Name)
+	}
+	return Issue{
+		Number:  i.Number,
+		Title:   i.Title,
+		State:   i.State,
+		Author:  i.User.Login,
+		Labels:  labels,
+		URL:     i.HTMLURL,
+		Created: i.CreatedAt,
+	}
+}
+
+func (g *Gitea) ListIssues(ctx context.Context, repo, state string, limit int) ([]Issue, error) {
+	path, err := g.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	var raw []giteaIssue
+	path += fmt.Sprintf("/issues?type=issues&state=%s&limit=%d", giteaState(state), limit)
+	if err := g.c.do(ctx, "GET", path, nil, &raw); err != nil {
+		return nil, err
+	}
+	issues := make([]Issue, 0, len(raw))
+	for _, i := range raw {
+		issues = append(issues, i.issue())
+	}
+	return issues, nil
+}
+
+func (g *Gitea) CreateIssue(ctx context.Context, repo string, issue NewIssue) (*Issue, error) {
+	path, err := g.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]interface{}{"title": issue.Title}
+	if issue.Body != "" {
+		body["body"] = issue.Body
+	}
+	if len(issue.Labels) > 0 {
+		ids, err := g.labelIDs(ctx, path, issue.Labels)
+		if err != nil {
+			return nil, err
+		}
+		body["labels"] = ids
+	}
+	var created giteaIssue
+	if err := g.c.do(ctx, "POST", path+"/issues", body, &created); err != nil {
+		return nil, err
+	}
+	i := created.issue()
+	return &i, nil
+}
+
+// labelIDs looks up labels by name, since Gitea takes them by ID
+func (g *Gitea) labelIDs(ctx context.Context, path string, names []string) ([]int, error) {
+	var labels []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := g.c.do(ctx, "GET", path+"/labels?limit=100", nil, &labels); err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, l := range labels {
+			if strings.EqualFold(l.Name, name) {
+				ids = append(ids, l.ID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no label %q in the repository", name)
+		}
+	}
+	return ids, nil
+}
+
+func (g *Gitea) ListMergeRequests(ctx context.Context, repo, state string, limit int) ([]MergeRequest, error) {
+	path, err := g.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	var raw []struct {
+		Number int       `json:"number"`
+		Title  string    `json:"title"`
+		State  string    `json:"state"`
+		Merged bool      `json:"merged"`
+		User   giteaUser `json:"user"`
+		Head   struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+		HTMLURL string `json:"html_url"`
+	}
+	path += fmt.Sprintf("/pulls?state=%s&limit=%d", giteaState(state), limit)
+	if err := g.c.do(ctx, "GET", path, nil, &raw); err != nil {
+		return nil, err
+	}
+	mrs := make([]MergeRequest, 0, len(raw))
+	for _, pr := range raw {
+		prState := pr.State
+		if pr.Merged {
+			prState = "merged"
+		}
+		if state == "merged" && !pr.Merged {
+			continue
+		}
+		mrs = append(mrs, MergeRequest{
+			Number:       pr.Number,
+			Title:        pr.Title,
+			State:        prState,
+			Author:       pr.User.Login,
+			SourceBranch: pr.Head.Ref,
+			TargetBranch: pr.Base.Ref,
+			URL:          pr.HTMLURL,
+		})
+	}
+	return mrs, nil
+}
+
+func (g *Gitea) MergeRequestDiff(ctx context.Context, repo string, number int) (string, error) {
+	path, err := g.repoPath(repo)
+	if err != nil {
+		return "", err
+	}
+	data, err := g.c.raw(ctx, "GET", fmt.Sprintf("%s/pulls/%d.diff", path, number), nil)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (g *Gitea) ListPipelines(ctx context.Context, repo, ref string, limit int) ([]Pipeline, error) {
+	path, err := g.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		WorkflowRuns []struct {
+			ID         int    `json:"id"`
+			Name       string `json:"name"`
+			Status     string `json:"status"`
+			HeadBranch string `json:"head_branch"`
+			HeadSHA    string `json:"head_sha"`
+			URL        string `json:"url"`
+			CreatedAt  string `json:"created_at"`
+		} `json:"workflow_runs"`
+	}
+	if err := g.c.do(ctx, "GET", fmt.Sprintf("%s/actions/tasks?limit=%d", path, limit), nil, &result); err != nil {
+		return nil, err
+	}
+	pipelines := make([]Pipeline, 0, len(result.WorkflowRuns))
+	for _, run := range result.WorkflowRuns {
+		if ref != "" && run.HeadBranch != ref {
+			continue
+		}
+		pipelines = append(pipelines, Pipeline{
+			ID:      run.ID,
+			Name:    run.Name,
+			Status:  run.Status,
+			Ref:     run.HeadBranch,
+			SHA:     run.HeadSHA,
+			URL:     run.URL,
+			Created: run.CreatedAt,
+		})
+	}
+	return pipelines, nil
+}
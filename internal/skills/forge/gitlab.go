@@ -0,0 +1,251 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// GitLab is a GitLab server, using the REST API v4
+type GitLab struct {
+	c *client
+}
+
+func (g *GitLab) Type() string { return "gitlab" }
+
+type gitlabProject struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+	Description       string `json:"description"`
+	WebURL            string `json:"web_url"`
+	DefaultBranch     string `json:"default_branch"`
+	StarCount         int    `json:"star_count"`
+	OpenIssuesCount   int    `json:"open_issues_count"`
+}
+
+func (p gitlabProject) repo() Repo {
+	return Repo{
+		Path:          p.PathWithNamespace,
+		Description:   p.Description,
+		URL:           p.WebURL,
+		DefaultBranch: p.DefaultBranch,
+		Stars:         p.StarCount,
+		OpenIssues:    p.OpenIssuesCount,
+	}
+}
+
+type gitlabUser struct {
+	Username string `json:"username"`
+}
+
+// project is the API path of a project, which may be in subgroups
+func (g *GitLab) project(repo string) (string, error) {
+	repo = strings.Trim(repo, "/")
+	if !strings.Contains(repo, "/") {
+		return "", fmt.Errorf("repository must be group/name, got %q", repo)
+	}
+	return "/projects/" + url.PathEscape(repo), nil
+}
+
+// gitlabState maps a state to GitLab's names for it
+func gitlabState(state string) string {
+	switch state {
+	case "", "open":
+		return "opened"
+	case "all":
+		return ""
+	}
+	return state
+}
+
+func (g *GitLab) SearchRepos(ctx context.Context, query string, limit int) ([]Repo, error) {
+	var projects []gitlabProject
+	path := fmt.Sprintf("/projects?search=%s&per_page=%d&order_by=last_activity_at", url.QueryEscape(query), limit)
+	if err := g.c.do(ctx, "GET", path, nil, &projects); err != nil {
+		return nil, err
+	}
+	repos := make([]Repo, 0, len(projects))
+	for _, p := range projects {
+		repos = append(repos, p.repo())
+	}
+	return repos, nil
+}
+
+func (g *GitLab) GetRepo(ctx context.Context, repo string) (*Repo, error) {
+	path, err := g.project(repo)
+	if err != nil {
+		return nil, err
+	}
+	var p gitlabProject
+	if err := g.c.do(ctx, "GET", path, nil, &p); err != nil {
+		return nil, err
+	}
+	r := p.repo()
+	return &r, nil
+}
+
+type gitlabIssue struct {
+	IID       int        `json:"iid"`
+	Title     string     `json:"title"`
+	State     string     `json:"state"`
+	Author    gitlabUser `json:"author"`
+	Labels    []string   `json:"labels"`
+	WebURL    string     `json:"web_url"`
+	CreatedAt string     `json:"created_at"`
+}
+
+func (i gitlabIssue) issue() Issue {
+	state := i.State
+	if state == "opened" {
+		state = "open"
+	}
+	return Issue{
+		Number:  i.IID,
+		Title:   i.Title,
+		State:   state,
+		Author:  i.Author.Username,
+		Labels:  i.Labels,
+		URL:     i.WebURL,
+		Created: i.CreatedAt,
+	}
+}
+
+func (g *GitLab) ListIssues(ctx context.Context, repo, state string, limit int) ([]Issue, error) {
+	path, err := g.project(repo)
+	if err != nil {
+		return nil, err
+	}
+	var raw []gitlabIssue
+	path += fmt.Sprintf("/issues?per_page=%d", limit)
+	if s := gitlabState(state); s != "" {
+		path += "&state=" + s
+	}
+	if err := g.c.do(ctx, "GET", path, nil, &raw); err != nil {
+		return nil, err
+	}
+	issues := make([]Issue, 0, len(raw))
+	for _, i := range raw {
+		issues = append(issues, i.issue())
+	}
+	return issues, nil
+}
+
+func (g *GitLab) CreateIssue(ctx context.Context, repo string, issue NewIssue) (*Issue, error) {
+	path, err := g.project(repo)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]interface{}{"title": issue.Title}
+	if issue.Body != "" {
+		body["description"] = issue.Body
+	}
+	if len(issue.Labels) > 0 {
+		body["labels"] = strings.Join(issue.Labels, ",")
+	}
+	var created gitlabIssue
+	if err := g.c.do(ctx, "POST", path+"/issues", body, &created); err != nil {
+		return nil, err
+	}
+	i := created.issue()
+	return &i, nil
+}
+
+type gitlabMergeRequest struct {
+	IID          int        `json:"iid"`
+	Title        string     `json:"title"`
+	State        string     `json:"state"`
+	Author       gitlabUser `json:"author"`
+	SourceBranch string     `json:"source_branch"`
+	TargetBranch string     `json:"target_branch"`
+	WebURL       string     `json:"web_url"`
+}
+
+func (g *GitLab) ListMergeRequests(ctx context.Context, repo, state string, limit int) ([]MergeRequest, error) {
+	path, err := g.project(repo)
+	if err != nil {
+		return nil, err
+	}
+	var raw []gitlabMergeRequest
+	path += fmt.Sprintf("/merge_requests?per_page=%d", limit)
+	if s := gitlabState(state); s != "" {
+		path += "&state=" + s
+	}
+	if err := g.c.do(ctx, "GET", path, nil, &raw); err != nil {
+		return nil, err
+	}
+	mrs := make([]MergeRequest, 0, len(raw))
+	for _, mr := range raw {
+		state := mr.State
+		if state == "opened" {
+			state = "open"
+		}
+		mrs = append(mrs, MergeRequest{
+			Number:       mr.IID,
+			Title:        mr.Title,
+			State:        state,
+			Author:       mr.Author.Username,
+			SourceBranch: mr.SourceBranch,
+			TargetBranch: mr.TargetBranch,
+			URL:          mr.WebURL,
+		})
+	}
+	return mrs, nil
+}
+
+func (g *GitLab) MergeRequestDiff(ctx context.Context, repo string, number int) (string, error) {
+	path, err := g.project(repo)
+	if err != nil {
+		return "", err
+	}
+	var files []struct {
+		OldPath string `json:"old_path"`
+		NewPath string `json:"new_path"`
+		Diff    string `json:"diff"`
+	}
+	if err := g.c.do(ctx, "GET", fmt.Sprintf("%s/merge_requests/%d/diffs?per_page=100", path, number), nil, &files); err != nil {
+		return "", err
+	}
+	// GitLab returns each file's hunks without the file header
+	var b strings.Builder
+	for _, f := range files {
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n%s", f.OldPath, f.NewPath, f.OldPath, f.NewPath, f.Diff)
+		if !strings.HasSuffix(f.Diff, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String(), nil
+}
+
+func (g *GitLab) ListPipelines(ctx context.Context, repo, ref string, limit int) ([]Pipeline, error) {
+	path, err := g.project(repo)
+	if err != nil {
+		return nil, err
+	}
+	path += fmt.Sprintf("/pipelines?per_page=%d", limit)
+	if ref != "" {
+		path += "&ref=" + url.QueryEscape(ref)
+	}
+	var raw []struct {
+		ID        int    `json:"id"`
+		Status    string `json:"status"`
+		Ref       string `json:"ref"`
+		SHA       string `json:"sha"`
+		WebURL    string `json:"web_url"`
+		CreatedAt string `json:"created_at"`
+	}
+	if err := g.c.do(ctx, "GET", path, nil, &raw); err != nil {
+		return nil, err
+	}
+	pipelines := make([]Pipeline, 0, len(raw))
+	for _, p := range raw {
+		pipelines = append(pipelines, Pipeline{
+			ID:      p.ID,
+			Status:  p.Status,
+			Ref:     p.Ref,
+			SHA:     p.SHA,
+			URL:     p.WebURL,
+			Created: p.CreatedAt,
+		})
+	}
+	return pipelines, nil
+}
@@ -0,0 +1,300 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"go.uber.org/zap"
+)
+
+// defaultDiffChars is how much of a merge request's diff is returned
+// unless asked for more
+const defaultDiffChars = 20000
+
+// host is a configured server
+type host struct {
+	name  string
+	url   *url.URL
+	forge Forge
+}
+
+// ForgeSkill offers the same tools for every configured GitLab and Gitea
+// server, picked with the host argument
+type ForgeSkill struct {
+	*skills.BaseSkill
+	hosts []host
+}
+
+// NewForgeSkill creates the skill for the configured servers, skipping and
+// logging invalid ones. It returns nil when none are usable.
+func NewForgeSkill(forges []config.ForgeConfig, logger *zap.Logger) *ForgeSkill {
+	s := &ForgeSkill{
+		BaseSkill: skills.NewBaseSkill("forge", "GitLab and Gitea repositories, issues, merge requests and pipelines", "1.0.0"),
+	}
+	for _, cfg := range forges {
+		f, err := New(cfg)
+		if err != nil {
+			logger.Warn("Skipping forge", zap.String("url", cfg.URL), zap.Error(err))
+			continue
+		}
+		u, _ := url.Parse(cfg.URL)
+		s.hosts = append(s.hosts, host{name: HostName(cfg), url: u, forge: f})
+	}
+	if len(s.hosts) == 0 {
+		return nil
+	}
+	s.registerTools()
+	return s
+}
+
+// hostParam describes the host argument, naming the configured servers
+func (s *ForgeSkill) hostParam() map[string]interface{} {
+	names := make([]string, 0, len(s.hosts))
+	for _, h := range s.hosts {
+		names = append(names, fmt.Sprintf("%s (%s)", h.name, h.forge.Type()))
+	}
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Server: " + strings.Join(names, ", ") + ". Defaults to " + s.hosts[0].name,
+	}
+}
+
+func (s *ForgeSkill) params(required []string, props map[string]interface{}) map[string]interface{} {
+	props["host"] = s.hostParam()
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+		"required":   required,
+	}
+}
+
+var (
+	repoProp = map[string]interface{}{
+		"type":        "string",
+		"description": "Repository path, e.g. group/project, or its web URL",
+	}
+	limitProp = map[string]interface{}{
+		"type":        "integer",
+		"description": "Maximum results (default: 10)",
+	}
+	numberProp = map[string]interface{}{
+		"type":        "integer",
+		"description": "Merge request or pull request number (IID on GitLab)",
+	}
+)
+
+func (s *ForgeSkill) registerTools() {
+	s.AddTool(skills.Tool{
+		Name:        "forge_search_repos",
+		ReadOnly:    true,
+		Description: "Search repositories on a GitLab or Gitea server",
+		Parameters: s.params([]string{"query"}, map[string]interface{}{
+			"query": map[string]interface{}{"type": "string", "description": "Search query"},
+			"limit": limitProp,
+		}),
+		Handler: s.handleSearchRepos,
+	})
+	s.AddTool(skills.Tool{
+		Name:        "forge_get_repo",
+		ReadOnly:    true,
+		Description: "Get information about a GitLab or Gitea repository",
+		Parameters:  s.params([]string{"repo"}, map[string]interface{}{"repo": repoProp}),
+		Handler:     s.handleGetRepo,
+	})
+	s.AddTool(skills.Tool{
+		Name:        "forge_list_issues",
+		ReadOnly:    true,
+		Description: "List issues of a GitLab or Gitea repository",
+		Parameters: s.params([]string{"repo"}, map[string]interface{}{
+			"repo":  repoProp,
+			"state": map[string]interface{}{"type": "string", "description": "open, closed or all (default: open)"},
+			"limit": limitProp,
+		}),
+		Handler: s.handleListIssues,
+	})
+	s.AddTool(skills.Tool{
+		Name:        "forge_create_issue",
+		Description: "Create an issue in a GitLab or Gitea repository",
+		Parameters: s.params([]string{"repo", "title"}, map[string]interface{}{
+			"repo":  repoProp,
+			"title": map[string]interface{}{"type": "string", "description": "Issue title"},
+			"body":  map[string]interface{}{"type": "string", "description": "Issue description (Markdown)"},
+			"labels": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Labels to add",
+			},
+		}),
+		Handler: s.handleCreateIssue,
+	})
+	s.AddTool(skills.Tool{
+		Name:        "forge_list_merge_requests",
+		ReadOnly:    true,
+		Description: "List merge requests (GitLab) or pull requests (Gitea) of a repository",
+		Parameters: s.params([]string{"repo"}, map[string]interface{}{
+			"repo":  repoProp,
+			"state": map[string]interface{}{"type": "string", "description": "open, closed, merged or all (default: open)"},
+			"limit": limitProp,
+		}),
+		Handler: s.handleListMergeRequests,
+	})
+	s.AddTool(skills.Tool{
+		Name:        "forge_get_merge_request_diff",
+		ReadOnly:    true,
+		Description: "Get the unified diff of a merge request or pull request, to review it",
+		Parameters: s.params([]string{"repo", "number"}, map[string]interface{}{
+			"repo":      repoProp,
+			"number":    numberProp,
+			"max_chars": map[string]interface{}{"type": "integer", "description": "Longest diff to return (default: 20000)"},
+		}),
+		Handler: s.handleGetMergeRequestDiff,
+	})
+	s.AddTool(skills.Tool{
+		Name:        "forge_list_pipelines",
+		ReadOnly:    true,
+		Description: "List recent CI pipelines (GitLab) or Actions runs (Gitea) of a repository, newest first",
+		Parameters: s.params([]string{"repo"}, map[string]interface{}{
+			"repo":  repoProp,
+			"ref":   map[string]interface{}{"type": "string", "description": "Only runs for this branch or tag"},
+			"limit": limitProp,
+		}),
+		Handler: s.handleListPipelines,
+	})
+}
+
+// pick returns the server and repository path the arguments refer to. A
+// repository given as a web URL picks the server whose URL it is under.
+func (s *ForgeSkill) pick(args map[string]interface{}, needRepo bool) (Forge, string, error) {
+	name, _ := args["host"].(string)
+	repo, _ := args["repo"].(string)
+	repo = strings.TrimSpace(repo)
+
+	if u, err := url.Parse(repo); err == nil && u.Host != "" {
+		for _, h := range s.hosts {
+			if h.url != nil && strings.EqualFold(h.url.Host, u.Host) && strings.HasPrefix(u.Path, strings.TrimRight(h.url.Path, "/")+"/") {
+				path := strings.TrimPrefix(u.Path, strings.TrimRight(h.url.Path, "/"))
+				// GitLab web URLs continue with /-/issues and the like
+				path, _, _ = strings.Cut(path, "/-/")
+				return h.forge, strings.TrimSuffix(strings.Trim(path, "/"), ".git"), nil
+			}
+		}
+		return nil, "", fmt.Errorf("%s is not on a configured server", u.Host)
+	}
+	if needRepo && repo == "" {
+		return nil, "", fmt.Errorf("repo is required")
+	}
+
+	if name == "" {
+		return s.hosts[0].forge, repo, nil
+	}
+	for _, h := range s.hosts {
+		if strings.EqualFold(h.name, name) || (h.url != nil && strings.EqualFold(h.url.Host, name)) {
+			return h.forge, repo, nil
+		}
+	}
+	return nil, "", fmt.Errorf("unknown host %q", name)
+}
+
+func limitArg(args map[string]interface{}) int {
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		return int(l)
+	}
+	return 10
+}
+
+func (s *ForgeSkill) handleSearchRepos(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	query, _ := args["query"].(string)
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	f, _, err := s.pick(args, false)
+	if err != nil {
+		return nil, err
+	}
+	return f.SearchRepos(ctx, query, limitArg(args))
+}
+
+func (s *ForgeSkill) handleGetRepo(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	f, repo, err := s.pick(args, true)
+	if err != nil {
+		return nil, err
+	}
+	return f.GetRepo(ctx, repo)
+}
+
+func (s *ForgeSkill) handleListIssues(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	f, repo, err := s.pick(args, true)
+	if err != nil {
+		return nil, err
+	}
+	state, _ := args["state"].(string)
+	return f.ListIssues(ctx, repo, state, limitArg(args))
+}
+
+func (s *ForgeSkill) handleCreateIssue(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	f, repo, err := s.pick(args, true)
+	if err != nil {
+		return nil, err
+	}
+	title, _ := args["title"].(string)
+	if strings.TrimSpace(title) == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	body, _ := args["body"].(string)
+	var labels []string
+	items, _ := args["labels"].([]interface{})
+	for _, item := range items {
+		if l, ok := item.(string); ok && strings.TrimSpace(l) != "" {
+			labels = append(labels, strings.TrimSpace(l))
+		}
+	}
+	return f.CreateIssue(ctx, repo, NewIssue{Title: title, Body: body, Labels: labels})
+}
+
+func (s *ForgeSkill) handleListMergeRequests(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	f, repo, err := s.pick(args, true)
+	if err != nil {
+		return nil, err
+	}
+	state, _ := args["state"].(string)
+	return f.ListMergeRequests(ctx, repo, state, limitArg(args))
+}
+
+func (s *ForgeSkill) handleGetMergeRequestDiff(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	f, repo, err := s.pick(args, true)
+	if err != nil {
+		return nil, err
+	}
+	number, _ := args["number"].(float64)
+	if number <= 0 {
+		return nil, fmt.Errorf("number is required")
+	}
+	maxChars := defaultDiffChars
+	if m, ok := args["max_chars"].(float64); ok && m > 0 {
+		maxChars = int(m)
+	}
+
+	diff, err := f.MergeRequestDiff(ctx, repo, int(number))
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{"number": int(number), "diff": diff}
+	if len(diff) > maxChars {
+		result["diff"] = diff[:maxChars]
+		result["truncated"] = true
+	}
+	return result, nil
+}
+
+func (s *ForgeSkill) handleListPipelines(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	f, repo, err := s.pick(args, true)
+	if err != nil {
+		return nil, err
+	}
+	ref, _ := args["ref"].(string)
+	return f.ListPipelines(ctx, repo, ref, limitArg(args))
+}
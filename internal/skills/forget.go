@@ -0,0 +1,39 @@
+package skills
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type forgetKey struct{}
+
+// ForgetRequest records that the user asked, during one chat turn, for the
+// conversation to be deleted. The agent deletes it once the turn is over,
+// as deleting it from a tool would leave the rest of the turn to be saved
+// into a conversation that no longer exists.
+type ForgetRequest struct {
+	requested atomic.Bool
+}
+
+// WithForgetRequest returns a context tools can ask to forget the
+// conversation from
+func WithForgetRequest(ctx context.Context) (context.Context, *ForgetRequest) {
+	f := &ForgetRequest{}
+	return context.WithValue(ctx, forgetKey{}, f), f
+}
+
+// ForgetConversation asks for the turn's conversation to be deleted. It
+// reports false when ctx isn't a chat turn that can be forgotten.
+func ForgetConversation(ctx context.Context) bool {
+	f, ok := ctx.Value(forgetKey{}).(*ForgetRequest)
+	if !ok {
+		return false
+	}
+	f.requested.Store(true)
+	return true
+}
+
+// Requested reports whether a tool asked to forget the conversation
+func (f *ForgetRequest) Requested() bool {
+	return f.requested.Load()
+}
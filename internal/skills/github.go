@@ -100,7 +100,7 @@ func (gi *GitHubInstaller) InstallFromGitHub(repo string) (*RuntimeSkill, error)
 
 	// Check minimum Myrai version
 	if manifest.MinMyraiVersion != "" {
-		currentVersion := "2.0.0" // TODO: Get actual version from build
+		currentVersion := Version
 		if !isVersionCompatible(currentVersion, manifest.MinMyraiVersion) {
 			return nil, fmt.Errorf("skill requires Myrai version %s or higher, current version is %s",
 				manifest.MinMyraiVersion, currentVersion)
@@ -116,7 +116,7 @@ func (gi *GitHubInstaller) InstallFromGitHub(repo string) (*RuntimeSkill, error)
 
 	// Copy extracted files to final location
 	extractedDir := filepath.Dir(skillPath)
-	if err := gi.copyDir(extractedDir, finalDir); err != nil {
+	if err := copyDir(extractedDir, finalDir); err != nil {
 		return nil, fmt.Errorf("failed to install skill files: %w", err)
 	}
 
@@ -437,7 +437,7 @@ func (gi *GitHubInstaller) findSkillFile(dir string) (string, error) {
 }
 
 // copyDir copies a directory recursively
-func (gi *GitHubInstaller) copyDir(src, dst string) error {
+func copyDir(src, dst string) error {
 	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -454,12 +454,12 @@ func (gi *GitHubInstaller) copyDir(src, dst string) error {
 			return os.MkdirAll(dstPath, info.Mode())
 		}
 
-		return gi.copyFile(path, dstPath)
+		return copyFile(path, dstPath)
 	})
 }
 
 // copyFile copies a single file
-func (gi *GitHubInstaller) copyFile(src, dst string) error {
+func copyFile(src, dst string) error {
 	source, err := os.Open(src)
 	if err != nil {
 		return err
@@ -531,9 +531,10 @@ func parseVersionInt(s string) (int, error) {
 	return result, err
 }
 
-// isVersionCompatible checks if the current version meets the minimum requirement
+// isVersionCompatible checks if the current version meets the minimum
+// requirement. Dev builds meet any requirement.
 func isVersionCompatible(current, minRequired string) bool {
-	if minRequired == "" {
+	if minRequired == "" || current == "dev" {
 		return true
 	}
 	return compareVersions(current, minRequired) >= 0
@@ -1,6 +1,7 @@
 package github
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -8,13 +9,22 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/cache"
 	"github.com/gmsas95/myrai-cli/internal/skills"
 )
 
+// CacheTTL is how long a GitHub API response is reused
+const CacheTTL = 5 * time.Minute
+
+// apiBase is where the GitHub REST API is
+const apiBase = "https://api.github.com"
+
 // GitHubSkill provides GitHub integration
 type GitHubSkill struct {
 	*skills.BaseSkill
-	token string
+	token   string
+	cache   *cache.Cache
+	apiBase string
 }
 
 // NewGitHubSkill creates a new GitHub skill
@@ -22,15 +32,19 @@ func NewGitHubSkill(token string) *GitHubSkill {
 	s := &GitHubSkill{
 		BaseSkill: skills.NewBaseSkill("github", "GitHub API integration", "1.0.0"),
 		token:     token,
+		apiBase:   apiBase,
 	}
 
 	s.registerTools()
+	s.registerPullTools()
+	s.registerIssueTools()
 	return s
 }
 
 func (s *GitHubSkill) registerTools() {
 	s.AddTool(skills.Tool{
 		Name:        "github_search_repos",
+		ReadOnly:    true,
 		Description: "Search for repositories on GitHub",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -51,6 +65,7 @@ func (s *GitHubSkill) registerTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "github_get_repo",
+		ReadOnly:    true,
 		Description: "Get information about a repository",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -71,6 +86,7 @@ func (s *GitHubSkill) registerTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "github_list_issues",
+		ReadOnly:    true,
 		Description: "List issues in a repository",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -99,6 +115,7 @@ func (s *GitHubSkill) registerTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "github_get_file",
+		ReadOnly:    true,
 		Description: "Get contents of a file from a repository",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -126,13 +143,40 @@ func (s *GitHubSkill) registerTools() {
 	})
 }
 
+// SetCache sets the cache that repeated requests are answered from
+func (s *GitHubSkill) SetCache(c *cache.Cache) {
+	s.cache = c
+}
+
 func (s *GitHubSkill) makeRequest(ctx context.Context, url string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	return cache.Fetch(ctx, s.cache, "github", url, CacheTTL, func() ([]byte, error) {
+		return s.fetch(ctx, url)
+	})
+}
+
+func (s *GitHubSkill) fetch(ctx context.Context, url string) ([]byte, error) {
+	return s.send(ctx, "GET", url, nil, "application/vnd.github.v3+json")
+}
+
+// send makes an API request, with body encoded as JSON if it isn't nil
+func (s *GitHubSkill) send(ctx context.Context, method, url string, body interface{}, accept string) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Accept", accept)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	if s.token != "" {
 		req.Header.Set("Authorization", "Bearer "+s.token)
 	}
@@ -144,7 +188,7 @@ func (s *GitHubSkill) makeRequest(ctx context.Context, url string) ([]byte, erro
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(body))
 	}
@@ -152,6 +196,24 @@ func (s *GitHubSkill) makeRequest(ctx context.Context, url string) ([]byte, erro
 	return io.ReadAll(resp.Body)
 }
 
+// write makes a request that changes something, which needs a token
+func (s *GitHubSkill) write(ctx context.Context, method, path string, body interface{}) (map[string]interface{}, error) {
+	if s.token == "" {
+		return nil, fmt.Errorf("a GitHub token is required to change anything; set skills.github.token")
+	}
+	data, err := s.send(ctx, method, s.apiBase+path, body, "application/vnd.github.v3+json")
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	if len(bytes.TrimSpace(data)) > 0 && data[0] == '{' {
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
 func (s *GitHubSkill) handleSearchRepos(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	query, _ := args["query"].(string)
 	if query == "" {
@@ -163,7 +225,7 @@ func (s *GitHubSkill) handleSearchRepos(ctx context.Context, args map[string]int
 		limit = int(l)
 	}
 
-	url := fmt.Sprintf("https://api.github.com/search/repositories?q=%s&per_page=%d", query, limit)
+	url := fmt.Sprintf("%s/search/repositories?q=%s&per_page=%d", s.apiBase, query, limit)
 	data, err := s.makeRequest(ctx, url)
 	if err != nil {
 		return nil, err
@@ -200,7 +262,7 @@ func (s *GitHubSkill) handleGetRepo(ctx context.Context, args map[string]interfa
 		return nil, fmt.Errorf("owner and repo are required")
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s", s.apiBase, owner, repo)
 	data, err := s.makeRequest(ctx, url)
 	if err != nil {
 		return nil, err
@@ -242,7 +304,7 @@ func (s *GitHubSkill) handleListIssues(ctx context.Context, args map[string]inte
 		limit = int(l)
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues?state=%s&per_page=%d", owner, repo, state, limit)
+	url := fmt.Sprintf("%s/repos/%s/%s/issues?state=%s&per_page=%d", s.apiBase, owner, repo, state, limit)
 	data, err := s.makeRequest(ctx, url)
 	if err != nil {
 		return nil, err
@@ -286,12 +348,12 @@ func (s *GitHubSkill) handleGetFile(ctx context.Context, args map[string]interfa
 		branch = "main"
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s?ref=%s", owner, repo, path, branch)
+	url := fmt.Sprintf("%s/repos/%s/%s/contents/%s?ref=%s", s.apiBase, owner, repo, path, branch)
 	data, err := s.makeRequest(ctx, url)
 	if err != nil {
 		// Try with master branch
 		if branch == "main" {
-			url = fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s?ref=master", owner, repo, path)
+			url = fmt.Sprintf("%s/repos/%s/%s/contents/%s?ref=master", s.apiBase, owner, repo, path)
 			data, err = s.makeRequest(ctx, url)
 		}
 		if err != nil {
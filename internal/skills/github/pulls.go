@@ -0,0 +1,456 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	neturl "net/url"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/skills"
+)
+
+// defaultDiffChars is how much of a pull request's diff is returned unless
+// asked for more
+const defaultDiffChars = 20000
+
+// repoParams are the parameters every repository tool takes
+func repoParams(extra map[string]interface{}) map[string]interface{} {
+	props := map[string]interface{}{
+		"owner": map[string]interface{}{
+			"type":        "string",
+			"description": "Repository owner",
+		},
+		"repo": map[string]interface{}{
+			"type":        "string",
+			"description": "Repository name",
+		},
+	}
+	for k, v := range extra {
+		props[k] = v
+	}
+	return props
+}
+
+func (s *GitHubSkill) registerPullTools() {
+	s.AddTool(skills.Tool{
+		Name:        "github_list_pulls",
+		ReadOnly:    true,
+		Description: "List pull requests in a repository",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": repoParams(map[string]interface{}{
+				"state": map[string]interface{}{
+					"type":        "string",
+					"description": "Pull request state: open, closed, all",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum results (default: 10)",
+				},
+			}),
+			"required": []string{"owner", "repo"},
+		},
+		Handler: s.handleListPulls,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "github_get_pull_diff",
+		ReadOnly:    true,
+		Description: "Get a pull request's description, changed files and unified diff, to review it",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": repoParams(map[string]interface{}{
+				"number": map[string]interface{}{
+					"type":        "integer",
+					"description": "Pull request number",
+				},
+				"max_chars": map[string]interface{}{
+					"type":        "integer",
+					"description": "Longest diff to return (default: 20000)",
+				},
+			}),
+			"required": []string{"owner", "repo", "number"},
+		},
+		Handler: s.handleGetPullDiff,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "github_review_pull",
+		Description: "Post a review on a pull request, with comments on lines of the diff",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": repoParams(map[string]interface{}{
+				"number": map[string]interface{}{
+					"type":        "integer",
+					"description": "Pull request number",
+				},
+				"event": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"COMMENT", "APPROVE", "REQUEST_CHANGES"},
+					"description": "Review verdict (default: COMMENT)",
+				},
+				"body": map[string]interface{}{
+					"type":        "string",
+					"description": "Overall review comment",
+				},
+				"comments": map[string]interface{}{
+					"type":        "array",
+					"description": "Comments on lines of the new version of changed files",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"path": map[string]interface{}{"type": "string", "description": "File path"},
+							"line": map[string]interface{}{"type": "integer", "description": "Line in the new version of the file"},
+							"body": map[string]interface{}{"type": "string", "description": "Comment"},
+						},
+						"required": []string{"path", "line", "body"},
+					},
+				},
+			}),
+			"required": []string{"owner", "repo", "number"},
+		},
+		Handler: s.handleReviewPull,
+	})
+}
+
+func (s *GitHubSkill) registerIssueTools() {
+	s.AddTool(skills.Tool{
+		Name:        "github_create_issue",
+		Description: "Create an issue in a repository",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": repoParams(map[string]interface{}{
+				"title": map[string]interface{}{
+					"type":        "string",
+					"description": "Issue title",
+				},
+				"body": map[string]interface{}{
+					"type":        "string",
+					"description": "Issue description (Markdown)",
+				},
+				"labels": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Labels to add",
+				},
+				"assignees": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Logins to assign",
+				},
+			}),
+			"required": []string{"owner", "repo", "title"},
+		},
+		Handler: s.handleCreateIssue,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "github_label_issue",
+		Description: "Add or remove labels of an issue or pull request, e.g. to triage it",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": repoParams(map[string]interface{}{
+				"number": map[string]interface{}{
+					"type":        "integer",
+					"description": "Issue or pull request number",
+				},
+				"add": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Labels to add",
+				},
+				"remove": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Labels to remove",
+				},
+			}),
+			"required": []string{"owner", "repo", "number"},
+		},
+		Handler: s.handleLabelIssue,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "github_comment",
+		Description: "Comment on an issue or pull request",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": repoParams(map[string]interface{}{
+				"number": map[string]interface{}{
+					"type":        "integer",
+					"description": "Issue or pull request number",
+				},
+				"body": map[string]interface{}{
+					"type":        "string",
+					"description": "Comment (Markdown)",
+				},
+			}),
+			"required": []string{"owner", "repo", "number", "body"},
+		},
+		Handler: s.handleComment,
+	})
+}
+
+// repoArgs returns the owner, repo and, if needed, number arguments
+func repoArgs(args map[string]interface{}, needNumber bool) (string, string, int, error) {
+	owner, _ := args["owner"].(string)
+	repo, _ := args["repo"].(string)
+	if owner == "" || repo == "" {
+		return "", "", 0, fmt.Errorf("owner and repo are required")
+	}
+	number := 0
+	if n, ok := args["number"].(float64); ok {
+		number = int(n)
+	}
+	if needNumber && number <= 0 {
+		return "", "", 0, fmt.Errorf("number is required")
+	}
+	return owner, repo, number, nil
+}
+
+// stringList reads an array of strings argument
+func stringList(v interface{}) []string {
+	items, _ := v.([]interface{})
+	var out []string
+	for _, item := range items {
+		if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+			out = append(out, strings.TrimSpace(s))
+		}
+	}
+	return out
+}
+
+func (s *GitHubSkill) handleListPulls(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	owner, repo, _, err := repoArgs(args, false)
+	if err != nil {
+		return nil, err
+	}
+	state, _ := args["state"].(string)
+	if state == "" {
+		state = "open"
+	}
+	limit := 10
+	if l, ok := args["limit"].(float64); ok {
+		limit = int(l)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls?state=%s&per_page=%d", s.apiBase, owner, repo, state, limit)
+	data, err := s.makeRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	var pulls []map[string]interface{}
+	if err := json.Unmarshal(data, &pulls); err != nil {
+		return nil, err
+	}
+
+	formatted := make([]map[string]interface{}, 0, len(pulls))
+	for _, pr := range pulls {
+		user, _ := pr["user"].(map[string]interface{})
+		formatted = append(formatted, map[string]interface{}{
+			"number":     pr["number"],
+			"title":      pr["title"],
+			"state":      pr["state"],
+			"draft":      pr["draft"],
+			"user":       user["login"],
+			"url":        pr["html_url"],
+			"created_at": pr["created_at"],
+		})
+	}
+	return formatted, nil
+}
+
+func (s *GitHubSkill) handleGetPullDiff(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	owner, repo, number, err := repoArgs(args, true)
+	if err != nil {
+		return nil, err
+	}
+	maxChars := defaultDiffChars
+	if m, ok := args["max_chars"].(float64); ok && m > 0 {
+		maxChars = int(m)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", s.apiBase, owner, repo, number)
+	data, err := s.makeRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	var pr map[string]interface{}
+	if err := json.Unmarshal(data, &pr); err != nil {
+		return nil, err
+	}
+
+	// The diff isn't cached: a review should see the latest push
+	diff, err := s.send(ctx, "GET", url, nil, "application/vnd.github.v3.diff")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = s.makeRequest(ctx, url+"/files?per_page=100")
+	if err != nil {
+		return nil, err
+	}
+	var files []map[string]interface{}
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, err
+	}
+	changed := make([]map[string]interface{}, 0, len(files))
+	for _, f := range files {
+		changed = append(changed, map[string]interface{}{
+			"path":      f["filename"],
+			"status":    f["status"],
+			"additions": f["additions"],
+			"deletions": f["deletions"],
+		})
+	}
+
+	result := map[string]interface{}{
+		"number": pr["number"],
+		"title":  pr["title"],
+		"body":   pr["body"],
+		"url":    pr["html_url"],
+		"files":  changed,
+		"diff":   string(diff),
+	}
+	if len(diff) > maxChars {
+		result["diff"] = string(diff[:maxChars])
+		result["truncated"] = true
+	}
+	return result, nil
+}
+
+func (s *GitHubSkill) handleReviewPull(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	owner, repo, number, err := repoArgs(args, true)
+	if err != nil {
+		return nil, err
+	}
+	event, _ := args["event"].(string)
+	event = strings.ToUpper(event)
+	if event == "" {
+		event = "COMMENT"
+	}
+	if event != "COMMENT" && event != "APPROVE" && event != "REQUEST_CHANGES" {
+		return nil, fmt.Errorf("event must be COMMENT, APPROVE or REQUEST_CHANGES")
+	}
+	body, _ := args["body"].(string)
+
+	review := map[string]interface{}{"event": event}
+	if body != "" {
+		review["body"] = body
+	}
+	raw, _ := args["comments"].([]interface{})
+	var comments []map[string]interface{}
+	for _, c := range raw {
+		comment, _ := c.(map[string]interface{})
+		path, _ := comment["path"].(string)
+		line, _ := comment["line"].(float64)
+		text, _ := comment["body"].(string)
+		if path == "" || line <= 0 || text == "" {
+			return nil, fmt.Errorf("each comment needs a path, line and body")
+		}
+		comments = append(comments, map[string]interface{}{
+			"path": path,
+			"line": int(line),
+			"side": "RIGHT",
+			"body": text,
+		})
+	}
+	if len(comments) > 0 {
+		review["comments"] = comments
+	}
+	if body == "" && len(comments) == 0 && event != "APPROVE" {
+		return nil, fmt.Errorf("a review needs a body or comments")
+	}
+
+	result, err := s.write(ctx, "POST", fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews", owner, repo, number), review)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"id":       result["id"],
+		"state":    result["state"],
+		"url":      result["html_url"],
+		"comments": len(comments),
+	}, nil
+}
+
+func (s *GitHubSkill) handleCreateIssue(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	owner, repo, _, err := repoArgs(args, false)
+	if err != nil {
+		return nil, err
+	}
+	title, _ := args["title"].(string)
+	if strings.TrimSpace(title) == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+
+	issue := map[string]interface{}{"title": title}
+	if body, _ := args["body"].(string); body != "" {
+		issue["body"] = body
+	}
+	if labels := stringList(args["labels"]); len(labels) > 0 {
+		issue["labels"] = labels
+	}
+	if assignees := stringList(args["assignees"]); len(assignees) > 0 {
+		issue["assignees"] = assignees
+	}
+
+	result, err := s.write(ctx, "POST", fmt.Sprintf("/repos/%s/%s/issues", owner, repo), issue)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"number": result["number"],
+		"title":  result["title"],
+		"url":    result["html_url"],
+	}, nil
+}
+
+func (s *GitHubSkill) handleLabelIssue(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	owner, repo, number, err := repoArgs(args, true)
+	if err != nil {
+		return nil, err
+	}
+	add, remove := stringList(args["add"]), stringList(args["remove"])
+	if len(add) == 0 && len(remove) == 0 {
+		return nil, fmt.Errorf("give labels to add or remove")
+	}
+
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d/labels", owner, repo, number)
+	if len(add) > 0 {
+		if _, err := s.write(ctx, "POST", path, map[string]interface{}{"labels": add}); err != nil {
+			return nil, err
+		}
+	}
+	for _, label := range remove {
+		if _, err := s.write(ctx, "DELETE", path+"/"+neturl.PathEscape(label), nil); err != nil {
+			return nil, err
+		}
+	}
+	return map[string]interface{}{
+		"number":  number,
+		"added":   add,
+		"removed": remove,
+	}, nil
+}
+
+func (s *GitHubSkill) handleComment(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	owner, repo, number, err := repoArgs(args, true)
+	if err != nil {
+		return nil, err
+	}
+	body, _ := args["body"].(string)
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("body is required")
+	}
+
+	result, err := s.write(ctx, "POST", fmt.Sprintf("/repos/%s/%s/issues/%d/comments", owner, repo, number), map[string]interface{}{"body": body})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"id":  result["id"],
+		"url": result["html_url"],
+	}, nil
+}
@@ -0,0 +1,99 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/triggers"
+	"go.uber.org/zap"
+)
+
+// Triggers runs the prompt of each configured trigger when a matching
+// webhook event arrives, and sends the answer to the user. A trigger that is
+// still running or cooling down skips further events for the same issue.
+type Triggers struct {
+	*triggers.Runner
+	triggers []config.GitHubTrigger
+}
+
+// NewTriggers creates triggers for the configured triggers that have an
+// event and a prompt
+func NewTriggers(list []config.GitHubTrigger, a agent.Chatter, logger *zap.Logger) *Triggers {
+	t := &Triggers{Runner: triggers.NewRunner("GitHub", a, logger)}
+	for _, trig := range list {
+		if strings.TrimSpace(trig.Event) != "" && strings.TrimSpace(trig.Prompt) != "" {
+			t.triggers = append(t.triggers, trig)
+		}
+	}
+	return t
+}
+
+// Subscribe starts reacting to the triggers' events on bus. It returns a
+// function that stops.
+func (t *Triggers) Subscribe(bus *events.Bus) func() {
+	var unsubscribe []func()
+	for i, trig := range t.triggers {
+		i, trig := i, trig
+		pattern := EventPrefix + strings.TrimPrefix(trig.Event, EventPrefix)
+		unsubscribe = append(unsubscribe, bus.Subscribe(pattern, func(ctx context.Context, e events.Event) {
+			repo, _ := e.Data["repo"].(string)
+			if trig.Repo != "" && !strings.EqualFold(trig.Repo, repo) {
+				return
+			}
+			userID := trig.User
+			if userID == "" {
+				userID = e.UserID
+			}
+			summary, _ := e.Data["summary"].(string)
+			// Start doesn't hold up the webhook response
+			t.Start(triggers.Run{
+				Key:          fmt.Sprintf("%d %s %v", i, repo, e.Data["number"]),
+				Cooldown:     time.Duration(trig.Cooldown) * time.Second,
+				UserID:       userID,
+				Prompt:       Prompt(trig.Prompt, e.Data),
+				SystemPrompt: "You are reacting to an event on GitHub. Use the GitHub tools if they help. Be concise.",
+				Event:        e.Type,
+				Summary:      "Reacted to " + summary,
+				Category:     "github",
+				Title:        strings.TrimPrefix(e.Type, EventPrefix),
+			})
+		}))
+	}
+	return func() {
+		for _, u := range unsubscribe {
+			u()
+		}
+	}
+}
+
+// Prompt fills {{repo}}, {{number}}, {{title}}, {{url}} and {{summary}}
+// from an event into a trigger prompt. Without placeholders the event's
+// summary and body are appended.
+func Prompt(template string, data map[string]interface{}) string {
+	field := func(name string) string {
+		if v, ok := data[name]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+	if !strings.Contains(template, "{{") {
+		prompt := fmt.Sprintf("%s\n\n%s\n%s", template, field("summary"), field("url"))
+		if body := field("body"); body != "" {
+			prompt += "\n\n" + body
+		}
+		return prompt
+	}
+	return strings.NewReplacer(
+		"{{repo}}", field("repo"),
+		"{{number}}", field("number"),
+		"{{title}}", field("title"),
+		"{{url}}", field("url"),
+		"{{summary}}", field("summary"),
+		"{{body}}", field("body"),
+	).Replace(template)
+}
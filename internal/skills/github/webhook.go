@@ -0,0 +1,162 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/strutil"
+)
+
+// EventPrefix starts the type of every event a GitHub webhook publishes
+const EventPrefix = "github."
+
+// CIFailed is published when a workflow run or check suite fails
+const CIFailed = EventPrefix + "ci.failed"
+
+// maxEventBody is how many characters of an issue or comment body an event
+// carries
+const maxEventBody = 2000
+
+// VerifySignature reports whether signature, the X-Hub-Signature-256
+// header, is the HMAC of body with secret
+func VerifySignature(secret string, body []byte, signature string) bool {
+	hexSum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok || secret == "" {
+		return false
+	}
+	sum, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(sum, mac.Sum(nil))
+}
+
+// webhookPayload holds the parts of webhook payloads that events carry
+type webhookPayload struct {
+	Action     string `json:"action"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+	Issue       *webhookItem `json:"issue"`
+	PullRequest *webhookItem `json:"pull_request"`
+	Comment     *struct {
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+	} `json:"comment"`
+	WorkflowRun *struct {
+		Name       string `json:"name"`
+		HeadBranch string `json:"head_branch"`
+		Conclusion string `json:"conclusion"`
+		HTMLURL    string `json:"html_url"`
+	} `json:"workflow_run"`
+	CheckSuite *struct {
+		HeadBranch string `json:"head_branch"`
+		Conclusion string `json:"conclusion"`
+		App        struct {
+			Name string `json:"name"`
+		} `json:"app"`
+	} `json:"check_suite"`
+}
+
+type webhookItem struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+// ParseWebhook turns a webhook delivery into an event of type
+// github.<event>.<action>, e.g. github.issues.opened. Failed workflow runs
+// and check suites become github.ci.failed. It returns nil for deliveries
+// that aren't worth an event, such as pings and passing CI.
+func ParseWebhook(eventName string, body []byte) (*events.Event, error) {
+	if eventName == "" {
+		return nil, fmt.Errorf("missing event name")
+	}
+	if eventName == "ping" {
+		return nil, nil
+	}
+
+	var p webhookPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	data := map[string]interface{}{
+		"event":  eventName,
+		"action": p.Action,
+		"repo":   p.Repository.FullName,
+		"sender": p.Sender.Login,
+	}
+	eventType := EventPrefix + eventName
+	if p.Action != "" {
+		eventType += "." + p.Action
+	}
+
+	item := p.Issue
+	if item == nil {
+		item = p.PullRequest
+	}
+	if item != nil {
+		data["number"] = item.Number
+		data["title"] = item.Title
+		data["url"] = item.HTMLURL
+		data["body"] = strutil.Truncate(item.Body, maxEventBody)
+		data["summary"] = fmt.Sprintf("%s #%d %s: %s", p.Repository.FullName, item.Number, p.Action, item.Title)
+	}
+	if p.Comment != nil {
+		data["body"] = strutil.Truncate(p.Comment.Body, maxEventBody)
+		data["url"] = p.Comment.HTMLURL
+		data["summary"] = fmt.Sprintf("%s commented on %s #%d: %s", p.Sender.Login, p.Repository.FullName, data["number"], data["title"])
+	}
+
+	switch {
+	case p.WorkflowRun != nil:
+		if p.Action != "completed" || !failed(p.WorkflowRun.Conclusion) {
+			return nil, nil
+		}
+		eventType = CIFailed
+		data["title"] = p.WorkflowRun.Name
+		data["url"] = p.WorkflowRun.HTMLURL
+		data["branch"] = p.WorkflowRun.HeadBranch
+		data["summary"] = fmt.Sprintf("Workflow %q %s on %s in %s", p.WorkflowRun.Name, p.WorkflowRun.Conclusion, p.WorkflowRun.HeadBranch, p.Repository.FullName)
+	case p.CheckSuite != nil:
+		if p.Action != "completed" || !failed(p.CheckSuite.Conclusion) {
+			return nil, nil
+		}
+		eventType = CIFailed
+		data["title"] = p.CheckSuite.App.Name
+		data["url"] = fmt.Sprintf("https://github.com/%s/actions", p.Repository.FullName)
+		data["branch"] = p.CheckSuite.HeadBranch
+		data["summary"] = fmt.Sprintf("Checks by %s %s on %s in %s", p.CheckSuite.App.Name, p.CheckSuite.Conclusion, p.CheckSuite.HeadBranch, p.Repository.FullName)
+	}
+	if _, ok := data["summary"]; !ok {
+		data["summary"] = fmt.Sprintf("%s in %s", strings.TrimPrefix(eventType, EventPrefix), p.Repository.FullName)
+	}
+
+	return &events.Event{
+		Type:   eventType,
+		Source: "github",
+		Data:   data,
+		Time:   time.Now(),
+	}, nil
+}
+
+func failed(conclusion string) bool {
+	switch conclusion {
+	case "failure", "timed_out", "startup_failure":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package github
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"action":"opened"}`)
+	if !VerifySignature("s3cret", body, sign("s3cret", body)) {
+		t.Error("Expected a valid signature")
+	}
+	if VerifySignature("s3cret", body, sign("other", body)) {
+		t.Error("Expected a signature with another secret to fail")
+	}
+	if VerifySignature("", body, sign("", body)) {
+		t.Error("Expected an empty secret to fail")
+	}
+	if VerifySignature("s3cret", body, "sha1=abc") {
+		t.Error("Expected a malformed signature to fail")
+	}
+}
+
+func TestParseWebhook(t *testing.T) {
+	issue := `{"action": "opened", "repository": {"full_name": "me/app"}, "sender": {"login": "ana"},
+		"issue": {"number": 7, "title": "Crash on start", "body": "Stack trace", "html_url": "https://github.com/me/app/issues/7"}}`
+	e, err := ParseWebhook("issues", []byte(issue))
+	if err != nil || e == nil {
+		t.Fatalf("Failed to parse issue event: %v", err)
+	}
+	if e.Type != "github.issues.opened" || e.Data["repo"] != "me/app" || e.Data["number"] != 7 || e.Data["title"] != "Crash on start" {
+		t.Errorf("Unexpected event: %s %v", e.Type, e.Data)
+	}
+
+	long, _ := json.Marshal(map[string]interface{}{
+		"action": "opened", "repository": map[string]string{"full_name": "me/app"},
+		"issue": map[string]interface{}{"number": 8, "body": strings.Repeat("é", maxEventBody+10)},
+	})
+	e, err = ParseWebhook("issues", long)
+	if err != nil || e == nil {
+		t.Fatalf("Failed to parse issue event: %v", err)
+	}
+	body, _ := e.Data["body"].(string)
+	if !utf8.ValidString(body) || utf8.RuneCountInString(body) != maxEventBody {
+		t.Errorf("Expected the body cut to %d valid characters, got %d", maxEventBody, utf8.RuneCountInString(body))
+	}
+
+	failedRun := `{"action": "completed", "repository": {"full_name": "me/app"},
+		"workflow_run": {"name": "CI", "head_branch": "main", "conclusion": "failure", "html_url": "https://github.com/me/app/actions/runs/1"}}`
+	e, err = ParseWebhook("workflow_run", []byte(failedRun))
+	if err != nil || e == nil || e.Type != CIFailed || e.Data["branch"] != "main" {
+		t.Fatalf("Expected a CI failure event, got %+v, %v", e, err)
+	}
+
+	passedRun := `{"action": "completed", "workflow_run": {"conclusion": "success"}}`
+	if e, err := ParseWebhook("workflow_run", []byte(passedRun)); err != nil || e != nil {
+		t.Errorf("Expected passing CI to be ignored, got %+v, %v", e, err)
+	}
+	if e, err := ParseWebhook("ping", []byte(`{}`)); err != nil || e != nil {
+		t.Errorf("Expected a ping to be ignored, got %+v, %v", e, err)
+	}
+	if _, err := ParseWebhook("issues", []byte(`not json`)); err == nil {
+		t.Error("Expected an invalid payload to fail")
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	data := map[string]interface{}{"repo": "me/app", "number": 7, "title": "Crash", "summary": "me/app #7 opened: Crash", "url": "u"}
+	if got := Prompt("Triage {{repo}}#{{number}}: {{title}}", data); got != "Triage me/app#7: Crash" {
+		t.Errorf("Unexpected prompt: %q", got)
+	}
+	if got := Prompt("Triage this.", data); got != "Triage this.\n\nme/app #7 opened: Crash\nu" {
+		t.Errorf("Unexpected prompt: %q", got)
+	}
+}
+
+func TestReviewAndLabelTools(t *testing.T) {
+	var review map[string]interface{}
+	var removed string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/me/app/pulls/3/reviews":
+			json.NewDecoder(r.Body).Decode(&review)
+			io.WriteString(w, `{"id": 1, "state": "COMMENTED"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/me/app/issues/3/labels":
+			io.WriteString(w, `[]`)
+		case r.Method == http.MethodDelete:
+			removed = r.URL.Path
+			io.WriteString(w, `[]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s := NewGitHubSkill("t")
+	s.apiBase = server.URL
+	ctx := context.Background()
+
+	_, err := s.handleReviewPull(ctx, map[string]interface{}{
+		"owner": "me", "repo": "app", "number": 3.0, "body": "Looks good",
+		"comments": []interface{}{map[string]interface{}{"path": "main.go", "line": 12.0, "body": "Typo"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to review: %v", err)
+	}
+	comments, _ := review["comments"].([]interface{})
+	if review["event"] != "COMMENT" || len(comments) != 1 {
+		t.Errorf("Unexpected review: %v", review)
+	}
+
+	_, err = s.handleLabelIssue(ctx, map[string]interface{}{
+		"owner": "me", "repo": "app", "number": 3.0,
+		"add": []interface{}{"bug"}, "remove": []interface{}{"needs triage"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to label: %v", err)
+	}
+	if removed != "/repos/me/app/issues/3/labels/needs triage" {
+		t.Errorf("Unexpected label removal: %q", removed)
+	}
+
+	if _, err := NewGitHubSkill("").handleCreateIssue(ctx, map[string]interface{}{"owner": "me", "repo": "app", "title": "x"}); err == nil {
+		t.Error("Expected writing without a token to fail")
+	}
+}
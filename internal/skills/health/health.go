@@ -3,10 +3,14 @@ package health
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/recurrence"
 	"github.com/gmsas95/myrai-cli/internal/skills"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -18,6 +22,7 @@ type HealthSkill struct {
 	store  *Store
 	parser *Parser
 	logger *zap.Logger
+	events *events.Bus
 }
 
 // NewHealthSkill creates a new health skill
@@ -38,6 +43,11 @@ func NewHealthSkill(db *gorm.DB, logger *zap.Logger) (*HealthSkill, error) {
 	return skill, nil
 }
 
+// SetEventBus sets the bus taken and missed doses are announced on
+func (h *HealthSkill) SetEventBus(bus *events.Bus) {
+	h.events = bus
+}
+
 func (h *HealthSkill) registerTools() {
 	tools := []skills.Tool{
 		{
@@ -62,6 +72,14 @@ func (h *HealthSkill) registerTools() {
 						"type":        "string",
 						"description": "Additional instructions or notes",
 					},
+					"critical": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Keep reminding until the user answers, for medications that must not be missed",
+					},
+					"escalate_minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "For critical medications, minutes between repeated reminders (default 15)",
+					},
 				},
 				"required": []string{"name", "schedule"},
 			},
@@ -93,8 +111,32 @@ func (h *HealthSkill) registerTools() {
 				"required": []string{"medication_id", "status"},
 			},
 		},
+		{
+			Name:        "answer_dose_reminder",
+			Description: "Answer a medication reminder when the user replies taken, snooze or skip",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"reply": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"taken", "snooze", "skip"},
+						"description": "The user's reply",
+					},
+					"medication_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Medication the reply is about (default: the latest reminder)",
+					},
+					"snooze_minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "How long to snooze (default 10)",
+					},
+				},
+				"required": []string{"reply"},
+			},
+		},
 		{
 			Name:        "list_medications",
+			ReadOnly:    true,
 			Description: "List all medications",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -108,6 +150,7 @@ func (h *HealthSkill) registerTools() {
 		},
 		{
 			Name:        "get_medication_schedule",
+			ReadOnly:    true,
 			Description: "Get today's medication schedule with what's taken and what's remaining",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -143,6 +186,7 @@ func (h *HealthSkill) registerTools() {
 		},
 		{
 			Name:        "get_health_metrics",
+			ReadOnly:    true,
 			Description: "Get health metrics history",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -160,6 +204,43 @@ func (h *HealthSkill) registerTools() {
 				},
 			},
 		},
+		{
+			Name:        "get_metric_trends",
+			ReadOnly:    true,
+			Description: "Analyze how a health metric is trending: moving average, change over the period and alerts when readings stay out of the healthy range. Can render a chart image that is sent to the user.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"metric_type": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"weight", "blood_pressure", "heart_rate", "temperature", "blood_sugar", "sleep", "steps", "water"},
+						"description": "Type of metric to analyze",
+					},
+					"period": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"week", "month", "quarter", "year"},
+						"description": "Time period (default: month)",
+					},
+					"window": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of readings in the moving average (default 7)",
+					},
+					"min": map[string]interface{}{
+						"type":        "number",
+						"description": "Lowest healthy value, overriding the default range",
+					},
+					"max": map[string]interface{}{
+						"type":        "number",
+						"description": "Highest healthy value, overriding the default range",
+					},
+					"chart": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Render a chart and send it as an image",
+					},
+				},
+				"required": []string{"metric_type"},
+			},
+		},
 		{
 			Name:        "add_appointment",
 			Description: "Schedule a medical appointment. Examples: 'Doctor checkup tomorrow at 2pm', 'Dentist next Monday at 10am for 1 hour'",
@@ -188,6 +269,7 @@ func (h *HealthSkill) registerTools() {
 		},
 		{
 			Name:        "list_appointments",
+			ReadOnly:    true,
 			Description: "List upcoming medical appointments",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -227,6 +309,7 @@ func (h *HealthSkill) registerTools() {
 		},
 		{
 			Name:        "get_health_summary",
+			ReadOnly:    true,
 			Description: "Get a comprehensive health summary including medications, upcoming appointments, and recent metrics",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -275,6 +358,8 @@ func (h *HealthSkill) handleTool(name string) skills.ToolHandler {
 			return h.handleAddMedication(ctx, args)
 		case "log_medication":
 			return h.handleLogMedication(ctx, args)
+		case "answer_dose_reminder":
+			return h.handleAnswerDoseReminder(ctx, args)
 		case "list_medications":
 			return h.handleListMedications(ctx, args)
 		case "get_medication_schedule":
@@ -283,6 +368,8 @@ func (h *HealthSkill) handleTool(name string) skills.ToolHandler {
 			return h.handleAddMetric(ctx, args)
 		case "get_health_metrics":
 			return h.handleGetMetrics(ctx, args)
+		case "get_metric_trends":
+			return h.handleGetMetricTrends(ctx, args)
 		case "add_appointment":
 			return h.handleAddAppointment(ctx, args)
 		case "list_appointments":
@@ -348,11 +435,16 @@ func (h *HealthSkill) handleAddMedication(ctx context.Context, args map[string]i
 		Frequency:   parsed.Frequency,
 		Times:       parsed.Times,
 		DaysOfWeek:  parsed.DaysOfWeek,
+		Recurrence:  parsed.Recurrence,
 		WithFood:    withFood || parsed.WithFood,
 		BeforeBed:   parsed.BeforeBed,
 		Notes:       notes,
+		Critical:    getBoolArg(args, "critical", false),
 		Enabled:     true,
 	}
+	if v, ok := args["escalate_minutes"].(float64); ok && v > 0 {
+		med.EscalateAfter = int(v)
+	}
 	
 	if err := h.store.CreateMedication(med); err != nil {
 		return nil, fmt.Errorf("failed to create medication: %w", err)
@@ -363,14 +455,18 @@ func (h *HealthSkill) handleAddMedication(ctx context.Context, args map[string]i
 		zap.String("name", med.Name),
 	)
 	
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"id":        med.ID,
 		"name":      med.Name,
 		"dosage":    med.Dosage,
 		"frequency": med.Frequency,
 		"times":     med.Times,
 		"message":   fmt.Sprintf("Added %s to your medications", med.Name),
-	}, nil
+	}
+	if rule, err := recurrence.Parse(med.Recurrence); err == nil {
+		result["repeats"] = rule.Describe()
+	}
+	return result, nil
 }
 
 func (h *HealthSkill) handleLogMedication(ctx context.Context, args map[string]interface{}) (interface{}, error) {
@@ -418,6 +514,11 @@ func (h *HealthSkill) handleLogMedication(ctx context.Context, args map[string]i
 	if err := h.store.CreateMedicationLog(log); err != nil {
 		return nil, err
 	}
+
+	if err := h.resolveDoseReminder(med, status, takenTime); err != nil {
+		h.logger.Warn("Failed to update medication reminder", zap.String("medication_id", med.ID), zap.Error(err))
+	}
+	publishDose(ctx, h.events, med, status, takenTime)
 	
 	return map[string]interface{}{
 		"success":      true,
@@ -428,6 +529,125 @@ func (h *HealthSkill) handleLogMedication(ctx context.Context, args map[string]i
 	}, nil
 }
 
+func (h *HealthSkill) handleAnswerDoseReminder(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := h.getUserID(ctx)
+	
+	reply := getStringArg(args, "reply", "")
+	medicationID := getStringArg(args, "medication_id", "")
+	
+	reminder, err := h.store.LatestPendingDoseReminder(userID, medicationID)
+	if err != nil {
+		return nil, err
+	}
+	if reminder == nil {
+		return nil, fmt.Errorf("no medication reminder is waiting for an answer")
+	}
+	
+	med, err := h.store.GetMedication(reminder.MedicationID)
+	if err != nil {
+		return nil, err
+	}
+	if med == nil {
+		return nil, fmt.Errorf("medication not found")
+	}
+	
+	now := time.Now()
+	switch reply {
+	case "snooze":
+		snooze := DefaultSnooze
+		if v, ok := args["snooze_minutes"].(float64); ok && v > 0 {
+			snooze = time.Duration(v) * time.Minute
+		}
+		until := now.Add(snooze)
+		reminder.SnoozedUntil = &until
+		if err := h.store.UpdateDoseReminder(reminder); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"success":    true,
+			"medication": med.Name,
+			"status":     "snoozed",
+			"message":    fmt.Sprintf("I'll remind you about %s again at %s", med.Name, until.Format("3:04 PM")),
+		}, nil
+		
+	case "taken", "skip":
+		status := DoseTaken
+		if reply == "skip" {
+			status = DoseSkipped
+		}
+		log := &MedicationLog{
+			UserID:        userID,
+			MedicationID:  med.ID,
+			ScheduledTime: reminder.ScheduledTime,
+			Status:        status,
+			ReminderSent:  true,
+		}
+		if status == DoseTaken {
+			log.TakenTime = &now
+		}
+		if err := h.store.CreateMedicationLog(log); err != nil {
+			return nil, err
+		}
+		
+		reminder.Status = status
+		reminder.SnoozedUntil = nil
+		if err := h.store.UpdateDoseReminder(reminder); err != nil {
+			return nil, err
+		}
+		publishDose(ctx, h.events, med, status, now)
+		
+		return map[string]interface{}{
+			"success":        true,
+			"medication":     med.Name,
+			"status":         status,
+			"scheduled_time": reminder.ScheduledTime.Format("3:04 PM"),
+			"message":        fmt.Sprintf("Logged %s as %s", med.Name, status),
+		}, nil
+	}
+	return nil, fmt.Errorf("reply must be taken, snooze or skip")
+}
+
+// resolveDoseReminder closes the pending reminder for a dose logged by hand,
+// so it isn't repeated
+func (h *HealthSkill) resolveDoseReminder(med *Medication, status string, at time.Time) error {
+	reminder, err := h.store.LatestPendingDoseReminder(med.UserID, med.ID)
+	if err != nil || reminder == nil {
+		return err
+	}
+	if diff := at.Sub(reminder.ScheduledTime); diff > MissedAfter || diff < -MissedAfter {
+		return nil
+	}
+	reminder.Status = status
+	reminder.SnoozedUntil = nil
+	return h.store.UpdateDoseReminder(reminder)
+}
+
+// publishDose announces a taken, skipped or missed dose on bus
+func publishDose(ctx context.Context, bus *events.Bus, med *Medication, status string, at time.Time) {
+	eventType := ""
+	switch status {
+	case "taken":
+		eventType = events.MedicationTaken
+	case "missed", "skipped":
+		eventType = events.MedicationMissed
+	}
+	if eventType == "" {
+		return
+	}
+	bus.Publish(ctx, events.Event{
+		Type:   eventType,
+		UserID: med.UserID,
+		Source: "health",
+		Data: map[string]interface{}{
+			"medication_id": med.ID,
+			"medication":    med.Name,
+			"status":        status,
+			"hour":          at.Hour(),
+		},
+		Time: at,
+	})
+}
+
 func (h *HealthSkill) handleListMedications(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	userID := h.getUserID(ctx)
 	activeOnly := getBoolArg(args, "active_only", true)
@@ -440,6 +660,9 @@ func (h *HealthSkill) handleListMedications(ctx context.Context, args map[string
 	var result []map[string]interface{}
 	for _, med := range meds {
 		schedule := med.Frequency
+		if rule, err := recurrence.Parse(med.Recurrence); err == nil {
+			schedule = rule.Describe()
+		}
 		if len(med.Times) > 0 {
 			schedule += " at " + strings.Join(med.Times, ", ")
 		}
@@ -491,6 +714,9 @@ func (h *HealthSkill) handleGetMedicationSchedule(ctx context.Context, args map[
 	var schedule []map[string]interface{}
 	
 	for _, med := range meds {
+		if !med.DueOn(checkDate) {
+			continue
+		}
 		for _, t := range med.Times {
 			// Parse time
 			parts := strings.Split(t, ":")
@@ -634,6 +860,102 @@ func (h *HealthSkill) handleGetMetrics(ctx context.Context, args map[string]inte
 	}, nil
 }
 
+func (h *HealthSkill) handleGetMetricTrends(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := h.getUserID(ctx)
+	
+	metricType := getStringArg(args, "metric_type", "")
+	period := getStringArg(args, "period", "month")
+	if metricType == "" {
+		return nil, fmt.Errorf("metric_type is required")
+	}
+	
+	days := map[string]int{"week": 7, "month": 30, "quarter": 90, "year": 365}[period]
+	if days == 0 {
+		return nil, fmt.Errorf("period must be week, month, quarter or year")
+	}
+	window := DefaultTrendWindow
+	if v, ok := args["window"].(float64); ok && v > 0 {
+		window = int(v)
+	}
+	
+	now := time.Now()
+	metrics, err := h.store.GetMetrics(userID, metricType, now.AddDate(0, 0, -days), now, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) == 0 {
+		return map[string]interface{}{
+			"metric_type": metricType,
+			"period":      period,
+			"count":       0,
+			"message":     fmt.Sprintf("No %s readings in the last %d days", strings.ReplaceAll(metricType, "_", " "), days),
+		}, nil
+	}
+	
+	// Blood pressure is tracked as systolic and diastolic readings
+	var subTypes []string
+	bySubType := make(map[string][]HealthMetric)
+	for _, m := range metrics {
+		if _, ok := bySubType[m.SubType]; !ok {
+			subTypes = append(subTypes, m.SubType)
+		}
+		bySubType[m.SubType] = append(bySubType[m.SubType], m)
+	}
+	
+	var trends []*MetricTrend
+	var alerts, charts []string
+	chartSent := false
+	for _, subType := range subTypes {
+		r := RangeFor(metricType, subType)
+		minV, hasMin := args["min"].(float64)
+		maxV, hasMax := args["max"].(float64)
+		if hasMin || hasMax {
+			custom := Range{}
+			if r != nil {
+				custom = *r
+			}
+			if hasMin {
+				custom.Min = minV
+			}
+			if hasMax {
+				custom.Max = maxV
+			}
+			r = &custom
+		}
+		
+		trend := AnalyzeTrend(bySubType[subType], window, r)
+		trends = append(trends, trend)
+		alerts = append(alerts, trend.Alerts...)
+		
+		if getBoolArg(args, "chart", false) {
+			path, err := trend.RenderChart(filepath.Join(os.TempDir(), "myrai-charts"))
+			if err != nil {
+				h.logger.Warn("Failed to render metric chart", zap.String("metric_type", metricType), zap.Error(err))
+				continue
+			}
+			charts = append(charts, path)
+			if skills.Attach(ctx, path) {
+				chartSent = true
+			}
+		}
+	}
+	
+	result := map[string]interface{}{
+		"metric_type": metricType,
+		"period":      period,
+		"count":       len(metrics),
+		"trends":      trends,
+	}
+	if len(alerts) > 0 {
+		result["alerts"] = alerts
+	}
+	if len(charts) > 0 {
+		result["charts"] = charts
+		result["chart_sent"] = chartSent
+	}
+	return result, nil
+}
+
 func (h *HealthSkill) handleAddAppointment(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	userID := h.getUserID(ctx)
 	
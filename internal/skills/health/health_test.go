@@ -2,9 +2,13 @@ package health
 
 import (
 	"context"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"github.com/gmsas95/myrai-cli/internal/skills"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -252,6 +256,36 @@ func TestHealthSkill_LogMedication(t *testing.T) {
 	assert.Equal(t, "taken", resp["status"])
 }
 
+func TestHealthSkill_LogMedicationPublishesEvents(t *testing.T) {
+	skill, db := setupTestSkill(t)
+	ctx := context.WithValue(context.Background(), "user_id", "user_123")
+
+	bus := events.NewBus(nil)
+	skill.SetEventBus(bus)
+	var published []events.Event
+	bus.Subscribe("medication.*", func(ctx context.Context, e events.Event) {
+		published = append(published, e)
+	})
+
+	store, _ := NewStore(db)
+	med := &Medication{UserID: "user_123", Name: "Test Med", Dosage: "10mg"}
+	store.CreateMedication(med)
+
+	for _, status := range []string{"missed", "taken"} {
+		_, err := skill.handleLogMedication(ctx, map[string]interface{}{
+			"medication_id": med.ID,
+			"status":        status,
+		})
+		require.NoError(t, err)
+	}
+
+	require.Len(t, published, 2)
+	assert.Equal(t, events.MedicationMissed, published[0].Type)
+	assert.Equal(t, "user_123", published[0].UserID)
+	assert.Equal(t, "Test Med", published[0].Data["medication"])
+	assert.Equal(t, events.MedicationTaken, published[1].Type)
+}
+
 func TestHealthSkill_ListMedications(t *testing.T) {
 	skill, db := setupTestSkill(t)
 	ctx := context.WithValue(context.Background(), "user_id", "user_123")
@@ -324,3 +358,156 @@ func TestHealthSkill_GetSummary(t *testing.T) {
 	assert.NotNil(t, resp["medications"])
 	assert.NotNil(t, resp["upcoming_appointments"])
 }
+
+func TestMedication_Recurrence(t *testing.T) {
+	parser := NewParser()
+
+	assert.Empty(t, parser.ParseMedication("Lisinopril 10mg daily at 8am").Recurrence)
+
+	parsed := parser.ParseMedication("Methotrexate 10mg every Monday at 9am")
+	assert.Equal(t, "RRULE:FREQ=WEEKLY;BYDAY=MO", parsed.Recurrence)
+
+	parsed = parser.ParseMedication("Prednisone 5mg every other day")
+	require.Equal(t, "RRULE:FREQ=DAILY;INTERVAL=2", parsed.Recurrence)
+
+	start := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	med := &Medication{Recurrence: parsed.Recurrence, StartDate: &start}
+	assert.True(t, med.DueOn(start.AddDate(0, 0, 2).Add(20*time.Hour)))
+	assert.False(t, med.DueOn(start.AddDate(0, 0, 3)))
+	assert.True(t, (&Medication{}).DueOn(start.AddDate(0, 0, 3)), "no rule means every day")
+}
+
+type recordingNotifier struct {
+	sent []notify.Notification
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, notification notify.Notification) (string, error) {
+	n.sent = append(n.sent, notification)
+	return notify.StatusSent, nil
+}
+
+func TestReminders_Check(t *testing.T) {
+	skill, db := setupTestSkill(t)
+	notifier := &recordingNotifier{}
+	reminders, err := NewReminders(db, notifier, nil)
+	require.NoError(t, err)
+
+	// Wednesday
+	now := time.Date(2025, 3, 5, 8, 0, 0, 0, time.Local)
+	daily := &Medication{UserID: "user1", Name: "Lisinopril", Dosage: "10mg", Times: []string{"08:00", "20:00"}, Enabled: true}
+	critical := &Medication{UserID: "user1", Name: "Insulin", Times: []string{"08:00"}, Critical: true, EscalateAfter: 10, Enabled: true}
+	weekends := &Medication{UserID: "user1", Name: "Vitamin D", Times: []string{"08:00"}, DaysOfWeek: []int{0, 6}, Enabled: true}
+	for _, med := range []*Medication{daily, critical, weekends} {
+		require.NoError(t, reminders.store.CreateMedication(med))
+	}
+
+	sent, err := reminders.Check(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent, "the 8am doses, but not the weekend one")
+	assert.Contains(t, notifier.sent[0].Title, "Lisinopril 10mg")
+	assert.Contains(t, notifier.sent[0].Body, "Reply taken, snooze or skip")
+	assert.False(t, notifier.sent[1].Urgent)
+
+	sent, err = reminders.Check(context.Background(), now.Add(5*time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, sent, "each dose is reminded once")
+
+	// The critical dose is repeated, urgently, until answered
+	sent, err = reminders.Check(context.Background(), now.Add(10*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	last := notifier.sent[len(notifier.sent)-1]
+	assert.Contains(t, last.Title, "Insulin")
+	assert.True(t, last.Urgent)
+
+	ctx := context.WithValue(context.Background(), "user_id", "user1")
+	result, err := skill.handleAnswerDoseReminder(ctx, map[string]interface{}{"reply": "taken", "medication_id": critical.ID})
+	require.NoError(t, err)
+	assert.Equal(t, DoseTaken, result.(map[string]interface{})["status"])
+
+	_, err = skill.handleAnswerDoseReminder(ctx, map[string]interface{}{"reply": "snooze", "snooze_minutes": float64(30)})
+	require.NoError(t, err)
+	reminder, err := reminders.store.GetDoseReminder(daily.ID, now)
+	require.NoError(t, err)
+	require.NotNil(t, reminder.SnoozedUntil)
+	snoozedUntil := *reminder.SnoozedUntil
+
+	sent, err = reminders.Check(context.Background(), now.Add(20*time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, sent, "taken doses stop repeating")
+
+	sent, err = reminders.Check(context.Background(), snoozedUntil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent, "snoozed dose comes back")
+
+	// Nobody answers, so the dose is logged as missed
+	_, err = reminders.Check(context.Background(), snoozedUntil.Add(time.Hour))
+	require.NoError(t, err)
+	reminder, err = reminders.store.GetDoseReminder(daily.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, DoseMissed, reminder.Status)
+	logs, err := reminders.store.GetMedicationLogs("user1", daily.ID, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, DoseMissed, logs[0].Status)
+
+	_, err = skill.handleAnswerDoseReminder(ctx, map[string]interface{}{"reply": "skip"})
+	assert.Error(t, err, "nothing left to answer")
+}
+
+func TestAnalyzeTrend(t *testing.T) {
+	start := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	var metrics []HealthMetric
+	for i, v := range []float64{128, 135, 142, 145, 148, 144, 150} {
+		metrics = append(metrics, HealthMetric{
+			Type: "blood_pressure", SubType: "systolic", Value: v, Unit: "mmHg",
+			MeasuredAt: start.AddDate(0, 0, i),
+		})
+	}
+
+	trend := AnalyzeTrend(metrics, 3, RangeFor("blood_pressure", "systolic"))
+	require.NotNil(t, trend)
+	assert.Equal(t, 7, trend.Count)
+	assert.Equal(t, 150.0, trend.Latest)
+	assert.Equal(t, 128.0, trend.Min)
+	assert.Equal(t, 147.3, trend.MovingAverage)
+	assert.Equal(t, "increasing", trend.Direction)
+	assert.Equal(t, 5, trend.OutOfRange)
+	require.Len(t, trend.Alerts, 1)
+	assert.Equal(t, "Systolic blood pressure has been above 140 in 5 of the last 7 readings", trend.Alerts[0])
+
+	// A single high reading is not an alert
+	trend = AnalyzeTrend(metrics[:3], 3, RangeFor("blood_pressure", "systolic"))
+	assert.Empty(t, trend.Alerts)
+	assert.Nil(t, AnalyzeTrend(nil, 3, nil))
+}
+
+func TestHealthSkill_GetMetricTrends(t *testing.T) {
+	skill, _ := setupTestSkill(t)
+	ctx := context.WithValue(context.Background(), "user_id", "user_123")
+
+	for i, v := range []float64{72, 75, 71, 78} {
+		require.NoError(t, skill.store.CreateMetric(&HealthMetric{
+			UserID: "user_123", Type: "heart_rate", Value: v, Unit: "bpm",
+			MeasuredAt: time.Now().Add(time.Duration(i-4) * time.Hour),
+		}))
+	}
+
+	ctx, attachments := skills.WithAttachments(ctx)
+	result, err := skill.handleGetMetricTrends(ctx, map[string]interface{}{"metric_type": "heart_rate", "chart": true})
+	require.NoError(t, err)
+	resp := result.(map[string]interface{})
+	assert.Equal(t, 4, resp["count"])
+	assert.Nil(t, resp["alerts"])
+	assert.Equal(t, true, resp["chart_sent"])
+
+	paths := attachments.Paths()
+	require.Len(t, paths, 1)
+	defer os.Remove(paths[0])
+	info, err := os.Stat(paths[0])
+	require.NoError(t, err)
+	assert.NotZero(t, info.Size())
+
+	_, err = skill.handleGetMetricTrends(ctx, map[string]interface{}{"metric_type": "heart_rate", "period": "decade"})
+	assert.Error(t, err)
+}
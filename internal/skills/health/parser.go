@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/recurrence"
 )
 
 // ParsedMedication represents a parsed medication input
@@ -16,6 +18,7 @@ type ParsedMedication struct {
 	Frequency   string
 	Times       []string
 	DaysOfWeek  []int
+	Recurrence  string // RRULE when not taken every day
 	WithFood    bool
 	BeforeBed   bool
 	Notes       string
@@ -119,6 +122,7 @@ func (p *Parser) ParseMedication(text string) *ParsedMedication {
 	
 	// Extract days
 	result.DaysOfWeek = p.extractDaysOfWeek(text)
+	result.Recurrence = p.extractRecurrence(text)
 	
 	// Extract instructions
 	result.WithFood = strings.Contains(text, "with food") || strings.Contains(text, "after meal") || strings.Contains(text, "with meals")
@@ -242,6 +246,22 @@ func (p *Parser) extractDaysOfWeek(text string) []int {
 	return days
 }
 
+// extractRecurrence finds which days a medication is taken on, like "every
+// other day" or "on Mondays and Thursdays". Every day is the default, so it
+// gives no rule; times of day are kept in Times instead.
+func (p *Parser) extractRecurrence(text string) string {
+	rule, _ := recurrence.Extract(text)
+	if rule == nil {
+		return ""
+	}
+	rule.ByHour, rule.ByMinute = nil, nil
+	if rule.Freq == recurrence.Daily && rule.Interval <= 1 && len(rule.ByDay) == 0 &&
+		!rule.SkipHolidays && rule.Count == 0 && rule.Until.IsZero() {
+		return ""
+	}
+	return rule.String()
+}
+
 // ParseMetric parses natural language metric input
 func (p *Parser) ParseMetric(text string) *ParsedMetric {
 	result := &ParsedMetric{}
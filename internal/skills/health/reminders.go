@@ -0,0 +1,248 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReminderInterval is how often medication doses are checked
+const ReminderInterval = time.Minute
+
+// DefaultEscalateAfter is how long a critical medication's reminder waits
+// for an answer before it is sent again
+const DefaultEscalateAfter = 15 * time.Minute
+
+// DefaultSnooze is how long a snoozed reminder waits when no time is given
+const DefaultSnooze = 10 * time.Minute
+
+// MaxPings caps how many times an unanswered critical dose is reminded
+const MaxPings = 4
+
+// MissedAfter is how long after its scheduled time an unanswered dose counts
+// as missed. Doses further in the past aren't reminded at all, so a restart
+// doesn't bring up the morning's doses in the evening.
+const MissedAfter = 2 * time.Hour
+
+// Reminders tells users when a medication dose is due. Each dose is reminded
+// once; critical medications are reminded again every EscalateAfter minutes
+// until the user answers taken, snooze or skip, and doses nobody answers are
+// logged as missed.
+type Reminders struct {
+	store    *Store
+	notifier notify.Sender
+	events   *events.Bus
+	logger   *zap.Logger
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewReminders creates reminders sent through notifier
+func NewReminders(db *gorm.DB, notifier notify.Sender, logger *zap.Logger) (*Reminders, error) {
+	store, err := NewStore(db)
+	if err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Reminders{store: store, notifier: notifier, logger: logger}, nil
+}
+
+// SetEventBus sets the bus missed doses are announced on
+func (r *Reminders) SetEventBus(bus *events.Bus) {
+	r.events = bus
+}
+
+// Start checks now and then every ReminderInterval until Stop is called
+func (r *Reminders) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(ReminderInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := r.Check(ctx, time.Now()); err != nil {
+				r.logger.Warn("Medication reminder check failed", zap.Error(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the check loop
+func (r *Reminders) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+		r.wg.Wait()
+	}
+}
+
+// Check sends the reminders due at now, repeats snoozed and unanswered
+// critical ones, and returns how many were sent
+func (r *Reminders) Check(ctx context.Context, now time.Time) (int, error) {
+	meds, err := r.store.reminderMedications()
+	if err != nil {
+		return 0, err
+	}
+	byID := make(map[string]*Medication, len(meds))
+
+	sent := 0
+	for i := range meds {
+		med := &meds[i]
+		byID[med.ID] = med
+
+		// Yesterday too, for late doses still due after midnight
+		for _, day := range []time.Time{now.AddDate(0, 0, -1), now} {
+			for _, dose := range med.DosesOn(day) {
+				remindAt := dose.Add(-time.Duration(med.RemindBefore) * time.Minute)
+				if now.Before(remindAt) || now.Sub(dose) >= MissedAfter {
+					continue
+				}
+				existing, err := r.store.GetDoseReminder(med.ID, dose)
+				if err != nil {
+					return sent, err
+				}
+				if existing != nil {
+					continue
+				}
+
+				reminder := &DoseReminder{UserID: med.UserID, MedicationID: med.ID, ScheduledTime: dose}
+				if !r.send(ctx, med, reminder, now) {
+					continue
+				}
+				sent++
+				if err := r.store.CreateDoseReminder(reminder); err != nil {
+					r.logger.Warn("Failed to record medication reminder", zap.String("medication_id", med.ID), zap.Error(err))
+				}
+			}
+		}
+	}
+
+	pending, err := r.store.PendingDoseReminders()
+	if err != nil {
+		return sent, err
+	}
+	for i := range pending {
+		reminder := &pending[i]
+		med := byID[reminder.MedicationID]
+		lastSent := reminder.CreatedAt
+		if reminder.LastSentAt != nil {
+			lastSent = *reminder.LastSentAt
+		}
+
+		switch {
+		case med == nil:
+			// Deleted or disabled since the reminder went out
+			reminder.Status = DoseSkipped
+		case reminder.SnoozedUntil != nil:
+			if now.Before(*reminder.SnoozedUntil) {
+				continue
+			}
+			if !r.send(ctx, med, reminder, now) {
+				continue
+			}
+			reminder.SnoozedUntil = nil
+			sent++
+		case now.Sub(reminder.ScheduledTime) >= MissedAfter && now.Sub(lastSent) >= med.escalateAfter():
+			reminder.Status = DoseMissed
+			r.logMissed(ctx, med, reminder)
+		case med.Critical && reminder.Pings < MaxPings && now.Sub(lastSent) >= med.escalateAfter():
+			if !r.send(ctx, med, reminder, now) {
+				continue
+			}
+			sent++
+		default:
+			continue
+		}
+
+		if err := r.store.UpdateDoseReminder(reminder); err != nil {
+			r.logger.Warn("Failed to update medication reminder", zap.String("reminder_id", reminder.ID), zap.Error(err))
+		}
+	}
+	return sent, nil
+}
+
+func (r *Reminders) send(ctx context.Context, med *Medication, reminder *DoseReminder, now time.Time) bool {
+	n := doseNotification(med, reminder, now)
+	n.UserID = med.UserID
+	n.Category = "health"
+	if _, err := r.notifier.Send(ctx, n); err != nil {
+		r.logger.Warn("Failed to send medication reminder",
+			zap.String("medication_id", med.ID),
+			zap.String("title", n.Title),
+			zap.Error(err))
+		return false
+	}
+	reminder.Pings++
+	reminder.LastSentAt = &now
+	return true
+}
+
+// logMissed records an unanswered dose in the medication log
+func (r *Reminders) logMissed(ctx context.Context, med *Medication, reminder *DoseReminder) {
+	log := &MedicationLog{
+		UserID:        med.UserID,
+		MedicationID:  med.ID,
+		ScheduledTime: reminder.ScheduledTime,
+		Status:        DoseMissed,
+		ReminderSent:  true,
+	}
+	if err := r.store.CreateMedicationLog(log); err != nil {
+		r.logger.Warn("Failed to log missed dose", zap.String("medication_id", med.ID), zap.Error(err))
+		return
+	}
+	publishDose(ctx, r.events, med, DoseMissed, reminder.ScheduledTime)
+}
+
+// doseNotification is the reminder for a dose. Repeats of a critical
+// medication's reminder are urgent so quiet hours don't hold them back.
+func doseNotification(med *Medication, reminder *DoseReminder, now time.Time) notify.Notification {
+	name := med.Name
+	if med.Dosage != "" && !strings.Contains(name, med.Dosage) {
+		name += " " + med.Dosage
+	}
+
+	title := "💊 Time for " + name
+	if reminder.Pings > 0 {
+		title = fmt.Sprintf("💊 Still waiting: %s was due at %s", name, reminder.ScheduledTime.Format("3:04 PM"))
+	} else if reminder.ScheduledTime.After(now) {
+		title = fmt.Sprintf("💊 %s is due at %s", name, reminder.ScheduledTime.Format("3:04 PM"))
+	}
+
+	var body []string
+	if med.WithFood {
+		body = append(body, "Take it with food.")
+	}
+	if med.Instructions != "" {
+		body = append(body, med.Instructions)
+	}
+	body = append(body, "Reply taken, snooze or skip.")
+
+	return notify.Notification{
+		Title:  title,
+		Body:   strings.Join(body, " "),
+		Urgent: med.Critical && reminder.Pings > 0,
+	}
+}
+
+func (m *Medication) escalateAfter() time.Duration {
+	if m.EscalateAfter > 0 {
+		return time.Duration(m.EscalateAfter) * time.Minute
+	}
+	return DefaultEscalateAfter
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/store"
 	"gorm.io/gorm"
 )
 
@@ -14,15 +15,52 @@ type Store struct {
 	db *gorm.DB
 }
 
+func init() {
+	store.RegisterMigrations("health", store.Migration{
+		Version: 1,
+		Name:    "create health tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Medication{}, &MedicationLog{}, &DoseReminder{}, &HealthMetric{}, &HealthAppointment{}, &HealthGoal{}, &HealthInsight{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Medication{}, &MedicationLog{}, &DoseReminder{}, &HealthMetric{}, &HealthAppointment{}, &HealthGoal{}, &HealthInsight{})
+		},
+	})
+	store.RegisterPurge("health", purge)
+}
+
+// purge deletes the dose log, measurements, appointments and insights from
+// before cutoff. Medications and goals are kept.
+func purge(db *gorm.DB, before time.Time) (int64, error) {
+	var total int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, t := range []struct {
+			model  interface{}
+			column string
+		}{
+			{&MedicationLog{}, "scheduled_time"},
+			{&HealthMetric{}, "measured_at"},
+			{&HealthAppointment{}, "date_time"},
+			{&HealthInsight{}, "created_at"},
+		} {
+			result := tx.Where(t.column+" < ?", before).Delete(t.model)
+			if result.Error != nil {
+				return result.Error
+			}
+			total += result.RowsAffected
+		}
+		return nil
+	})
+	return total, err
+}
+
 // NewStore creates a new health store
 func NewStore(db *gorm.DB) (*Store, error) {
-	store := &Store{db: db}
-
-	if err := db.AutoMigrate(&Medication{}, &MedicationLog{}, &HealthMetric{}, &HealthAppointment{}, &HealthGoal{}, &HealthInsight{}); err != nil {
+	if err := store.Migrate(db, "health"); err != nil {
 		return nil, fmt.Errorf("failed to migrate health schemas: %w", err)
 	}
 
-	return store, nil
+	return &Store{db: db}, nil
 }
 
 // Medication operations
@@ -155,6 +193,74 @@ func (s *Store) GetTodayLogs(userID string) ([]MedicationLog, error) {
 	return s.GetMedicationLogs(userID, "", startOfDay, endOfDay)
 }
 
+// DoseReminder operations
+
+// reminderMedications lists every user's enabled medications that have times
+func (s *Store) reminderMedications() ([]Medication, error) {
+	var meds []Medication
+	err := s.db.Where("enabled = ? AND times_json <> ''", true).Find(&meds).Error
+	for i := range meds {
+		json.Unmarshal([]byte(meds[i].TimesJSON), &meds[i].Times)
+		if meds[i].DaysJSON != "" {
+			json.Unmarshal([]byte(meds[i].DaysJSON), &meds[i].DaysOfWeek)
+		}
+	}
+	return meds, err
+}
+
+// CreateDoseReminder records a reminder for a scheduled dose
+func (s *Store) CreateDoseReminder(r *DoseReminder) error {
+	if r.ID == "" {
+		r.ID = idgen.Generate(idgen.PrefixHealth)
+	}
+	if r.Status == "" {
+		r.Status = DosePending
+	}
+	r.CreatedAt = time.Now()
+	r.UpdatedAt = time.Now()
+	return s.db.Create(r).Error
+}
+
+// UpdateDoseReminder saves a dose reminder
+func (s *Store) UpdateDoseReminder(r *DoseReminder) error {
+	r.UpdatedAt = time.Now()
+	return s.db.Save(r).Error
+}
+
+// GetDoseReminder returns the reminder for a medication's dose, or nil if
+// none was sent
+func (s *Store) GetDoseReminder(medicationID string, scheduled time.Time) (*DoseReminder, error) {
+	var r DoseReminder
+	err := s.db.Where("medication_id = ? AND scheduled_time = ?", medicationID, scheduled).First(&r).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &r, err
+}
+
+// PendingDoseReminders lists the reminders nobody has answered yet
+func (s *Store) PendingDoseReminders() ([]DoseReminder, error) {
+	var reminders []DoseReminder
+	err := s.db.Where("status = ?", DosePending).Order("scheduled_time ASC").Find(&reminders).Error
+	return reminders, err
+}
+
+// LatestPendingDoseReminder returns the user's most recent unanswered
+// reminder, for medicationID if set, or nil if there is none
+func (s *Store) LatestPendingDoseReminder(userID, medicationID string) (*DoseReminder, error) {
+	query := s.db.Where("user_id = ? AND status = ?", userID, DosePending)
+	if medicationID != "" {
+		query = query.Where("medication_id = ?", medicationID)
+	}
+
+	var r DoseReminder
+	err := query.Order("scheduled_time DESC").First(&r).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &r, err
+}
+
 // HealthMetric operations
 
 func (s *Store) CreateMetric(metric *HealthMetric) error {
@@ -0,0 +1,266 @@
+package health
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+// DefaultTrendWindow is how many readings the moving average spans
+const DefaultTrendWindow = 7
+
+// alertShare is the share of recent readings that must be out of range
+// before it counts as consistent rather than a one-off
+const alertShare = 0.7
+
+// alertReadings is how many of the latest readings alerts look at
+const alertReadings = 7
+
+// Range is the healthy range of a metric. A zero bound is not checked.
+type Range struct {
+	Min float64 `json:"min,omitempty"`
+	Max float64 `json:"max,omitempty"`
+}
+
+// DefaultRanges are the usual adult ranges, keyed by type or type/subtype.
+// Temperature is left out because readings come in either scale.
+var DefaultRanges = map[string]Range{
+	"blood_pressure":           {Min: 90, Max: 140},
+	"blood_pressure/systolic":  {Min: 90, Max: 140},
+	"blood_pressure/diastolic": {Min: 60, Max: 90},
+	"heart_rate":               {Min: 50, Max: 100},
+	"blood_sugar":              {Min: 70, Max: 140},
+}
+
+// TrendPoint is one reading and the moving average up to it
+type TrendPoint struct {
+	Time    time.Time `json:"time"`
+	Value   float64   `json:"value"`
+	Average float64   `json:"average"`
+}
+
+// MetricTrend summarizes how a metric moved over a period
+type MetricTrend struct {
+	Type          string       `json:"type"`
+	SubType       string       `json:"sub_type,omitempty"`
+	Unit          string       `json:"unit,omitempty"`
+	Count         int          `json:"count"`
+	Latest        float64      `json:"latest"`
+	Average       float64      `json:"average"`
+	Min           float64      `json:"min"`
+	Max           float64      `json:"max"`
+	MovingAverage float64      `json:"moving_average"`
+	Change        float64      `json:"change"`         // moving average now against the first reading
+	ChangePercent float64      `json:"change_percent"` // Change as a share of the first reading
+	Direction     string       `json:"direction"`      // increasing, decreasing, stable
+	Range         *Range       `json:"range,omitempty"`
+	OutOfRange    int          `json:"out_of_range,omitempty"`
+	Alerts        []string     `json:"alerts,omitempty"`
+	Points        []TrendPoint `json:"-"`
+}
+
+// RangeFor returns the default range for a metric, if it has one
+func RangeFor(metricType, subType string) *Range {
+	if r, ok := DefaultRanges[metricType+"/"+subType]; ok && subType != "" {
+		return &r
+	}
+	if r, ok := DefaultRanges[metricType]; ok {
+		return &r
+	}
+	return nil
+}
+
+// AnalyzeTrend computes moving averages, the change over the period and
+// out-of-range alerts for readings of one metric. window is the number of
+// readings averaged; r may be nil to skip range checks.
+func AnalyzeTrend(metrics []HealthMetric, window int, r *Range) *MetricTrend {
+	if len(metrics) == 0 {
+		return nil
+	}
+	if window <= 0 {
+		window = DefaultTrendWindow
+	}
+
+	readings := append([]HealthMetric(nil), metrics...)
+	sort.Slice(readings, func(i, j int) bool { return readings[i].MeasuredAt.Before(readings[j].MeasuredAt) })
+
+	trend := &MetricTrend{
+		Type:    readings[0].Type,
+		SubType: readings[0].SubType,
+		Unit:    readings[0].Unit,
+		Count:   len(readings),
+		Min:     math.Inf(1),
+		Max:     math.Inf(-1),
+		Range:   r,
+	}
+
+	sum := 0.0
+	for i, m := range readings {
+		sum += m.Value
+		trend.Min = math.Min(trend.Min, m.Value)
+		trend.Max = math.Max(trend.Max, m.Value)
+
+		from := max(i-window+1, 0)
+		windowSum := 0.0
+		for _, w := range readings[from : i+1] {
+			windowSum += w.Value
+		}
+		trend.Points = append(trend.Points, TrendPoint{
+			Time:    m.MeasuredAt,
+			Value:   m.Value,
+			Average: round1(windowSum / float64(i-from+1)),
+		})
+
+		if r != nil && (r.above(m.Value) || r.below(m.Value)) {
+			trend.OutOfRange++
+		}
+	}
+
+	last := trend.Points[len(trend.Points)-1]
+	first := readings[0].Value
+	trend.Latest = last.Value
+	trend.Average = round1(sum / float64(len(readings)))
+	trend.MovingAverage = last.Average
+	trend.Change = round1(last.Average - first)
+	if first != 0 {
+		trend.ChangePercent = round1(trend.Change / first * 100)
+	}
+
+	switch {
+	case len(readings) < 2 || math.Abs(trend.ChangePercent) < 2:
+		trend.Direction = "stable"
+	case trend.Change > 0:
+		trend.Direction = "increasing"
+	default:
+		trend.Direction = "decreasing"
+	}
+
+	if r != nil {
+		trend.Alerts = rangeAlerts(trend, readings, *r)
+	}
+	return trend
+}
+
+// rangeAlerts flags a metric that has been out of range in most of the latest
+// readings. A single high reading isn't worth an alert.
+func rangeAlerts(trend *MetricTrend, readings []HealthMetric, r Range) []string {
+	recent := readings[max(len(readings)-alertReadings, 0):]
+	if len(recent) < 3 {
+		return nil
+	}
+
+	above, below := 0, 0
+	for _, m := range recent {
+		if r.above(m.Value) {
+			above++
+		}
+		if r.below(m.Value) {
+			below++
+		}
+	}
+
+	name := trend.label()
+	var alerts []string
+	if float64(above) >= alertShare*float64(len(recent)) {
+		alerts = append(alerts, fmt.Sprintf("%s has been above %s in %d of the last %d readings",
+			name, formatValue(r.Max), above, len(recent)))
+	}
+	if float64(below) >= alertShare*float64(len(recent)) {
+		alerts = append(alerts, fmt.Sprintf("%s has been below %s in %d of the last %d readings",
+			name, formatValue(r.Min), below, len(recent)))
+	}
+	return alerts
+}
+
+func (r Range) above(v float64) bool { return r.Max != 0 && v > r.Max }
+
+func (r Range) below(v float64) bool { return r.Min != 0 && v < r.Min }
+
+// label names the metric for people, e.g. "diastolic blood pressure"
+func (t *MetricTrend) label() string {
+	name := strings.ReplaceAll(t.Type, "_", " ")
+	if t.SubType != "" {
+		name = t.SubType + " " + name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// RenderChart draws the readings, their moving average and the healthy range
+// as a PNG in dir and returns its path. It needs at least two readings.
+func (t *MetricTrend) RenderChart(dir string) (string, error) {
+	if len(t.Points) < 2 {
+		return "", fmt.Errorf("a chart needs at least two readings")
+	}
+
+	var times []time.Time
+	var values, averages []float64
+	for _, p := range t.Points {
+		times = append(times, p.Time)
+		values = append(values, p.Value)
+		averages = append(averages, p.Average)
+	}
+
+	series := []chart.Series{
+		chart.TimeSeries{
+			Name:    "Readings",
+			XValues: times,
+			YValues: values,
+			Style:   chart.Style{StrokeColor: chart.ColorBlue, DotColor: chart.ColorBlue, DotWidth: 3},
+		},
+		chart.TimeSeries{
+			Name:    "Moving average",
+			XValues: times,
+			YValues: averages,
+			Style:   chart.Style{StrokeColor: chart.ColorOrange, StrokeWidth: 2},
+		},
+	}
+	bounds := []time.Time{times[0], times[len(times)-1]}
+	limit := chart.Style{StrokeColor: drawing.ColorRed, StrokeDashArray: []float64{5, 5}}
+	if t.Range != nil && t.Range.Max != 0 {
+		series = append(series, chart.TimeSeries{Name: "Upper limit", XValues: bounds, YValues: []float64{t.Range.Max, t.Range.Max}, Style: limit})
+	}
+	if t.Range != nil && t.Range.Min != 0 {
+		series = append(series, chart.TimeSeries{Name: "Lower limit", XValues: bounds, YValues: []float64{t.Range.Min, t.Range.Min}, Style: limit})
+	}
+
+	graph := chart.Chart{
+		Title:  t.label(),
+		Width:  800,
+		Height: 400,
+		XAxis:  chart.XAxis{ValueFormatter: chart.TimeDateValueFormatter},
+		YAxis:  chart.YAxis{Name: t.Unit},
+		Series: series,
+	}
+	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create chart dir: %w", err)
+	}
+	name := strings.Trim(t.Type+"-"+t.SubType, "-")
+	path := filepath.Join(dir, fmt.Sprintf("%s-%d.png", name, time.Now().UnixNano()))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create chart: %w", err)
+	}
+	defer f.Close()
+
+	if err := graph.Render(chart.PNG, f); err != nil {
+		return "", fmt.Errorf("failed to render chart: %w", err)
+	}
+	return path, nil
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+func formatValue(v float64) string {
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", v), ".0")
+}
@@ -1,9 +1,19 @@
 package health
 
 import (
+	"slices"
 	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/fieldcrypt"
+	"github.com/gmsas95/myrai-cli/internal/recurrence"
 )
 
+func init() {
+	// Notes and instructions are encrypted at rest when storage
+	// encryption is on
+	fieldcrypt.Register(&Medication{}, &MedicationLog{}, &HealthMetric{}, &HealthAppointment{})
+}
+
 // Medication represents a medication with schedule
 type Medication struct {
 	ID          string    `json:"id" gorm:"primaryKey"`
@@ -22,6 +32,7 @@ type Medication struct {
 	TimesJSON   string    `json:"-" gorm:"type:text"` // Serialized times
 	DaysOfWeek  []int     `json:"days_of_week,omitempty" gorm:"-"` // 0=Sunday, 1=Monday, etc.
 	DaysJSON    string    `json:"-" gorm:"type:text"` // Serialized days
+	Recurrence  string    `json:"recurrence,omitempty"` // RRULE for which days, e.g. every other day; empty is every day
 	
 	// Timing
 	WithFood    bool   `json:"with_food,omitempty"`
@@ -33,8 +44,10 @@ type Medication struct {
 	RefillDate    *time.Time `json:"refill_date,omitempty"`
 	
 	// Reminders
-	RemindBefore int  `json:"remind_before,omitempty"` // minutes before
-	Enabled      bool `json:"enabled" gorm:"default:true"`
+	RemindBefore  int  `json:"remind_before,omitempty"` // minutes before
+	Critical      bool `json:"critical,omitempty"`       // unanswered reminders are repeated until answered
+	EscalateAfter int  `json:"escalate_after,omitempty"` // minutes between repeats for critical medications
+	Enabled       bool `json:"enabled" gorm:"default:true"`
 	
 	// Prescription info
 	PrescribedBy   string     `json:"prescribed_by,omitempty"`
@@ -43,9 +56,9 @@ type Medication struct {
 	EndDate        *time.Time `json:"end_date,omitempty"`
 	
 	// Notes
-	Instructions string `json:"instructions,omitempty"`
-	SideEffects  string `json:"side_effects,omitempty"`
-	Notes        string `json:"notes,omitempty"`
+	Instructions string `json:"instructions,omitempty" gorm:"serializer:encrypted"`
+	SideEffects  string `json:"side_effects,omitempty" gorm:"serializer:encrypted"`
+	Notes        string `json:"notes,omitempty" gorm:"serializer:encrypted"`
 	
 	// Timestamps
 	CreatedAt   time.Time  `json:"created_at"`
@@ -66,11 +79,11 @@ type MedicationLog struct {
 	// Details
 	QuantityTaken string `json:"quantity_taken,omitempty"`
 	WithFood      bool   `json:"with_food,omitempty"`
-	Notes         string `json:"notes,omitempty"`
+	Notes         string `json:"notes,omitempty" gorm:"serializer:encrypted"`
 	
 	// Side effects
 	HadSideEffects bool   `json:"had_side_effects,omitempty"`
-	SideEffects    string `json:"side_effects,omitempty"`
+	SideEffects    string `json:"side_effects,omitempty" gorm:"serializer:encrypted"`
 	
 	// Reminder tracking
 	ReminderSent bool `json:"reminder_sent,omitempty"`
@@ -78,6 +91,29 @@ type MedicationLog struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// Dose reminder statuses
+const (
+	DosePending = "pending"
+	DoseTaken   = "taken"
+	DoseSkipped = "skipped"
+	DoseMissed  = "missed"
+)
+
+// DoseReminder tracks the reminder for one scheduled dose, so each dose is
+// reminded once and critical ones can be repeated until answered
+type DoseReminder struct {
+	ID            string     `json:"id" gorm:"primaryKey"`
+	UserID        string     `json:"user_id" gorm:"index"`
+	MedicationID  string     `json:"medication_id" gorm:"index"`
+	ScheduledTime time.Time  `json:"scheduled_time" gorm:"index"`
+	Status        string     `json:"status" gorm:"index"` // pending, taken, skipped, missed
+	Pings         int        `json:"pings"`
+	LastSentAt    *time.Time `json:"last_sent_at,omitempty"`
+	SnoozedUntil  *time.Time `json:"snoozed_until,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
 // HealthMetric represents a health measurement
 type HealthMetric struct {
 	ID     string `json:"id" gorm:"primaryKey"`
@@ -100,7 +136,7 @@ type HealthMetric struct {
 	DeviceID    string `json:"device_id,omitempty"`
 	
 	// Notes
-	Notes       string `json:"notes,omitempty"`
+	Notes       string `json:"notes,omitempty" gorm:"serializer:encrypted"`
 	Tags        string `json:"tags,omitempty"` // Comma-separated tags
 	
 	// Related metrics (e.g., BP has two values)
@@ -117,7 +153,7 @@ type HealthAppointment struct {
 	
 	// Appointment details
 	Title       string     `json:"title"`
-	Description string     `json:"description,omitempty"`
+	Description string     `json:"description,omitempty" gorm:"serializer:encrypted"`
 	Type        string     `json:"type"` // checkup, specialist, test, procedure, follow_up, vaccination
 	
 	// Provider
@@ -148,7 +184,7 @@ type HealthAppointment struct {
 	
 	// Follow-up
 	FollowUpNeeded bool   `json:"follow_up_needed,omitempty"`
-	FollowUpNotes  string `json:"follow_up_notes,omitempty"`
+	FollowUpNotes  string `json:"follow_up_notes,omitempty" gorm:"serializer:encrypted"`
 	
 	// Insurance
 	InsuranceUsed  string `json:"insurance_used,omitempty"`
@@ -156,9 +192,9 @@ type HealthAppointment struct {
 	Copay          float64 `json:"copay,omitempty"`
 	
 	// Notes
-	Notes          string `json:"notes,omitempty"`
-	Outcome        string `json:"outcome,omitempty"` // What happened at the appointment
-	Prescriptions  string `json:"prescriptions,omitempty"` // New prescriptions from appointment
+	Notes          string `json:"notes,omitempty" gorm:"serializer:encrypted"`
+	Outcome        string `json:"outcome,omitempty" gorm:"serializer:encrypted"` // What happened at the appointment
+	Prescriptions  string `json:"prescriptions,omitempty" gorm:"serializer:encrypted"` // New prescriptions from appointment
 	
 	// Calendar sync
 	CalendarEventID string `json:"calendar_event_id,omitempty"`
@@ -280,6 +316,57 @@ func (m *MedicationLog) IsOverdue() bool {
 	return time.Now().After(m.ScheduledTime.Add(30 * time.Minute))
 }
 
+// DueOn checks if a medication is taken on day's date. The series starts on
+// StartDate, or when the medication was added.
+func (m *Medication) DueOn(day time.Time) bool {
+	if m.Recurrence == "" {
+		return true
+	}
+	rule, err := recurrence.Parse(m.Recurrence)
+	if err != nil {
+		return true
+	}
+	start := m.CreatedAt
+	if m.StartDate != nil {
+		start = *m.StartDate
+	}
+	start = start.In(day.Location())
+	return rule.OccursOn(time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, day.Location()), day)
+}
+
+// DosesOn lists the doses scheduled on day's date from Times, skipping days
+// outside DaysOfWeek, the recurrence, or the start and end dates
+func (m *Medication) DosesOn(day time.Time) []time.Time {
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	if m.StartDate != nil && date.Before(truncateDay(*m.StartDate, day.Location())) {
+		return nil
+	}
+	if m.EndDate != nil && date.After(truncateDay(*m.EndDate, day.Location())) {
+		return nil
+	}
+	if len(m.DaysOfWeek) > 0 && !slices.Contains(m.DaysOfWeek, int(date.Weekday())) {
+		return nil
+	}
+	if !m.DueOn(date) {
+		return nil
+	}
+	
+	var doses []time.Time
+	for _, t := range m.Times {
+		clock, err := time.Parse("15:04", t)
+		if err != nil {
+			continue
+		}
+		doses = append(doses, time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, date.Location()))
+	}
+	return doses
+}
+
+func truncateDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
 // IsDueSoon checks if a medication is due within the next hour
 func (s *MedicationSchedule) IsDueSoon() bool {
 	now := time.Now()
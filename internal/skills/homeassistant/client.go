@@ -0,0 +1,151 @@
+package homeassistant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// State is an entity's state as Home Assistant reports it
+type State struct {
+	EntityID    string                 `json:"entity_id"`
+	State       string                 `json:"state"`
+	Attributes  map[string]interface{} `json:"attributes"`
+	LastChanged time.Time              `json:"last_changed"`
+}
+
+// Domain is the part of the entity ID before the dot, e.g. "light"
+func (s State) Domain() string {
+	return domainOf(s.EntityID)
+}
+
+// Name is the entity's friendly name, or its ID without one
+func (s State) Name() string {
+	if name, ok := s.Attributes["friendly_name"].(string); ok && name != "" {
+		return name
+	}
+	return s.EntityID
+}
+
+// Client talks to the Home Assistant REST API
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a client for the instance at baseURL, authenticating
+// with a long-lived access token
+func NewClient(baseURL, token string, timeout time.Duration) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(strings.TrimSpace(baseURL), "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid home assistant url %q", baseURL)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("home assistant token is required")
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &Client{
+		baseURL: u.String(),
+		token:   token,
+		http:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// States returns every entity's state
+func (c *Client) States(ctx context.Context) ([]State, error) {
+	var states []State
+	if err := c.do(ctx, http.MethodGet, "/api/states", nil, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// State returns one entity's state
+func (c *Client) State(ctx context.Context, entityID string) (*State, error) {
+	var state State
+	if err := c.do(ctx, http.MethodGet, "/api/states/"+url.PathEscape(entityID), nil, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// CallService calls a service, e.g. light.turn_off, and returns the states
+// that changed because of it
+func (c *Client) CallService(ctx context.Context, domain, service string, data map[string]interface{}) ([]State, error) {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	var changed []State
+	path := "/api/services/" + url.PathEscape(domain) + "/" + url.PathEscape(service)
+	if err := c.do(ctx, http.MethodPost, path, data, &changed); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// websocketURL is the address of the instance's WebSocket API
+func (c *Client) websocketURL() string {
+	u, _ := url.Parse(c.baseURL + "/api/websocket")
+	if u.Scheme == "https" {
+		u.Scheme = "wss"
+	} else {
+		u.Scheme = "ws"
+	}
+	return u.String()
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("home assistant request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("home assistant rejected the access token")
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("not found in home assistant: %s", path)
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("home assistant returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode home assistant response: %w", err)
+	}
+	return nil
+}
+
+func domainOf(entityID string) string {
+	if i := strings.Index(entityID, "."); i > 0 {
+		return entityID[:i]
+	}
+	return ""
+}
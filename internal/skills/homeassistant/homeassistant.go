@@ -0,0 +1,361 @@
+// Package homeassistant lets the assistant read and control a Home Assistant
+// instance: list entities, read their state and call services such as
+// light.turn_off. State changes of watched entities are published on the
+// event bus so other skills and prompts can react to them.
+package homeassistant
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+)
+
+const (
+	// MaxEntities caps how many entities a listing returns
+	MaxEntities = 50
+	// MaxTargets caps how many entities one service call may act on by name
+	MaxTargets = 10
+)
+
+// HomeAssistantSkill reads and controls Home Assistant entities
+type HomeAssistantSkill struct {
+	*skills.BaseSkill
+	client  *Client
+	allowed map[string]bool
+}
+
+// NewHomeAssistantSkill creates a skill for the configured instance
+func NewHomeAssistantSkill(cfg config.HomeAssistantSkillConfig) (*HomeAssistantSkill, error) {
+	client, err := NewClient(cfg.URL, cfg.Token, time.Duration(cfg.TimeoutSecs)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return newHomeAssistantSkill(client, cfg.AllowedDomains), nil
+}
+
+func newHomeAssistantSkill(client *Client, allowedDomains []string) *HomeAssistantSkill {
+	s := &HomeAssistantSkill{
+		BaseSkill: skills.NewBaseSkill("homeassistant", "Read and control smart home devices through Home Assistant", "1.0.0"),
+		client:    client,
+		allowed:   make(map[string]bool),
+	}
+	for _, domain := range allowedDomains {
+		s.allowed[strings.ToLower(strings.TrimSpace(domain))] = true
+	}
+	s.registerTools()
+	return s
+}
+
+// Client returns the skill's Home Assistant client
+func (s *HomeAssistantSkill) Client() *Client {
+	return s.client
+}
+
+func (s *HomeAssistantSkill) registerTools() {
+	allowed := "none configured"
+	if domains := s.allowedDomains(); len(domains) > 0 {
+		allowed = strings.Join(domains, ", ")
+	}
+
+	s.AddTool(skills.Tool{
+		Name:        "ha_list_entities",
+		ReadOnly:    true,
+		Description: "List smart home entities (lights, switches, sensors...) with their current state",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"domain": map[string]interface{}{
+					"type":        "string",
+					"description": "Only this domain, e.g. light, switch, sensor, climate",
+				},
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "Only entities whose name or ID contains these words, e.g. living room",
+				},
+			},
+		},
+		Handler: s.handleListEntities,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "ha_get_state",
+		ReadOnly:    true,
+		Description: "Get the current state and attributes of one entity, e.g. whether the front door is open or the bedroom temperature",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"entity": map[string]interface{}{
+					"type":        "string",
+					"description": "Entity ID (sensor.bedroom_temperature) or name (Bedroom temperature)",
+				},
+			},
+			"required": []string{"entity"},
+		},
+		Handler: s.handleGetState,
+	})
+
+	s.AddTool(skills.Tool{
+		Name: "ha_call_service",
+		Description: "Control a device by calling a Home Assistant service, e.g. light.turn_off for " +
+			"\"turn off the living room lights\". Allowed domains: " + allowed,
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"domain": map[string]interface{}{
+					"type":        "string",
+					"description": "Service domain, e.g. light",
+				},
+				"service": map[string]interface{}{
+					"type":        "string",
+					"description": "Service, e.g. turn_on, turn_off, toggle, set_temperature",
+				},
+				"entity": map[string]interface{}{
+					"type":        "string",
+					"description": "Entity ID or name to act on",
+				},
+				"data": map[string]interface{}{
+					"type":        "object",
+					"description": "Extra service data, e.g. {\"brightness_pct\": 40} or {\"temperature\": 21}",
+				},
+			},
+			"required": []string{"domain", "service"},
+		},
+		Handler: s.handleCallService,
+	})
+}
+
+func (s *HomeAssistantSkill) handleListEntities(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	domain, _ := args["domain"].(string)
+	query, _ := args["query"].(string)
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	states, err := s.client.States(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var matched []State
+	for _, st := range states {
+		if domain != "" && st.Domain() != domain {
+			continue
+		}
+		if query != "" && !matchesQuery(st, query) {
+			continue
+		}
+		matched = append(matched, st)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].EntityID < matched[j].EntityID })
+
+	result := make([]map[string]interface{}, 0, len(matched))
+	for i, st := range matched {
+		if i == MaxEntities {
+			break
+		}
+		result = append(result, summarize(st))
+	}
+	out := map[string]interface{}{
+		"count":    len(matched),
+		"entities": result,
+	}
+	if len(matched) > MaxEntities {
+		out["note"] = fmt.Sprintf("Showing the first %d; narrow the list with domain or query", MaxEntities)
+	}
+	return out, nil
+}
+
+func (s *HomeAssistantSkill) handleGetState(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	ref, _ := args["entity"].(string)
+	st, err := s.resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	out := summarize(*st)
+	out["attributes"] = st.Attributes
+	if !st.LastChanged.IsZero() {
+		out["last_changed"] = locale.FromContext(ctx).DateTime(st.LastChanged)
+	}
+	return out, nil
+}
+
+func (s *HomeAssistantSkill) handleCallService(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	domain, _ := args["domain"].(string)
+	service, _ := args["service"].(string)
+	ref, _ := args["entity"].(string)
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	service = strings.ToLower(strings.TrimSpace(service))
+
+	// "light.turn_off" in one argument
+	if strings.Contains(service, ".") && (domain == "" || domain == domainOf(service)) {
+		domain, service = domainOf(service), service[len(domainOf(service))+1:]
+	}
+	if domain == "" || service == "" {
+		return nil, fmt.Errorf("domain and service are required")
+	}
+	if !s.allowed[domain] {
+		return nil, fmt.Errorf("calling %s services is not allowed: add %q to skills.homeassistant.allowed_domains", domain, domain)
+	}
+
+	data := map[string]interface{}{}
+	if extra, ok := args["data"].(map[string]interface{}); ok {
+		for k, v := range extra {
+			data[k] = v
+		}
+	}
+	var targets []string
+	if ref = strings.TrimSpace(ref); ref != "" {
+		matched, err := s.resolveAll(ctx, ref, domain)
+		if err != nil {
+			return nil, err
+		}
+		for _, st := range matched {
+			targets = append(targets, st.EntityID)
+		}
+		data["entity_id"] = targets
+	} else {
+		switch ids := data["entity_id"].(type) {
+		case string:
+			targets = []string{ids}
+		case []interface{}:
+			for _, id := range ids {
+				targets = append(targets, fmt.Sprint(id))
+			}
+		}
+	}
+	for _, id := range targets {
+		if !s.allowed[domainOf(id)] {
+			return nil, fmt.Errorf("%s is in the %s domain, which is not allowed", id, domainOf(id))
+		}
+	}
+
+	changed, err := s.client.CallService(ctx, domain, service, data)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]map[string]interface{}, 0, len(changed))
+	for _, st := range changed {
+		states = append(states, summarize(st))
+	}
+	target := domain + "." + service
+	if len(targets) > 0 {
+		target += " on " + strings.Join(targets, ", ")
+	}
+	return map[string]interface{}{
+		"success": true,
+		"changed": states,
+		"message": "Called " + target,
+	}, nil
+}
+
+// resolve finds the one entity ref names: an entity ID, a friendly name, or
+// words that only one entity's name contains
+func (s *HomeAssistantSkill) resolve(ctx context.Context, ref string) (*State, error) {
+	matched, err := s.resolveAll(ctx, ref, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(matched) > 1 {
+		return nil, fmt.Errorf("%q matches %d entities: %s", ref, len(matched), listNames(matched))
+	}
+	return &matched[0], nil
+}
+
+// resolveAll finds the entities ref names: an entity ID, a friendly name, or
+// every entity whose name contains its words ("living room lights").
+// domain narrows the search.
+func (s *HomeAssistantSkill) resolveAll(ctx context.Context, ref, domain string) ([]State, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, fmt.Errorf("entity is required")
+	}
+	if isEntityID(ref) {
+		st, err := s.client.State(ctx, strings.ToLower(ref))
+		if err != nil {
+			return nil, err
+		}
+		return []State{*st}, nil
+	}
+
+	states, err := s.client.States(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var partial []State
+	for _, st := range states {
+		if domain != "" && st.Domain() != domain {
+			continue
+		}
+		if strings.EqualFold(st.Name(), ref) {
+			return []State{st}, nil
+		}
+		if matchesQuery(st, ref) {
+			partial = append(partial, st)
+		}
+	}
+	switch {
+	case len(partial) == 0:
+		return nil, fmt.Errorf("no entity called %q: use ha_list_entities to find it", ref)
+	case len(partial) > MaxTargets:
+		return nil, fmt.Errorf("%q matches %d entities, be more specific: %s", ref, len(partial), listNames(partial))
+	}
+	sort.Slice(partial, func(i, j int) bool { return partial[i].EntityID < partial[j].EntityID })
+	return partial, nil
+}
+
+func (s *HomeAssistantSkill) allowedDomains() []string {
+	domains := make([]string, 0, len(s.allowed))
+	for d := range s.allowed {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// matchesQuery reports whether every word of query, ignoring a plural "s",
+// appears in the entity's name or ID
+func matchesQuery(st State, query string) bool {
+	haystack := strings.ToLower(st.Name() + " " + strings.ReplaceAll(st.EntityID, "_", " "))
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if word == "the" {
+			continue
+		}
+		if !strings.Contains(haystack, word) && !strings.Contains(haystack, strings.TrimSuffix(word, "s")) {
+			return false
+		}
+	}
+	return true
+}
+
+func listNames(states []State) string {
+	names := make([]string, 0, 6)
+	for i, st := range states {
+		if i == 5 {
+			names = append(names, "...")
+			break
+		}
+		names = append(names, fmt.Sprintf("%s (%s)", st.Name(), st.EntityID))
+	}
+	return strings.Join(names, ", ")
+}
+
+// isEntityID reports whether ref looks like domain.object_id
+func isEntityID(ref string) bool {
+	domain := domainOf(ref)
+	return domain != "" && !strings.ContainsAny(ref, " ") && len(ref) > len(domain)+1
+}
+
+func summarize(st State) map[string]interface{} {
+	out := map[string]interface{}{
+		"entity_id": st.EntityID,
+		"name":      st.Name(),
+		"state":     st.State,
+	}
+	if unit, ok := st.Attributes["unit_of_measurement"].(string); ok && unit != "" {
+		out["unit"] = unit
+	}
+	return out
+}
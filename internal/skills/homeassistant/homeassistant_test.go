@@ -0,0 +1,202 @@
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "secret-token"
+
+// fakeHomeAssistant serves the parts of the REST and WebSocket APIs the
+// skill uses
+type fakeHomeAssistant struct {
+	mu     sync.Mutex
+	states []State
+	calls  []string
+	events []map[string]interface{}
+}
+
+func (f *fakeHomeAssistant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/websocket" {
+		f.serveWebsocket(w, r)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/api/states":
+		json.NewEncoder(w).Encode(f.states)
+	case strings.HasPrefix(r.URL.Path, "/api/states/"):
+		id := strings.TrimPrefix(r.URL.Path, "/api/states/")
+		for _, st := range f.states {
+			if st.EntityID == id {
+				json.NewEncoder(w).Encode(st)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case strings.HasPrefix(r.URL.Path, "/api/services/") && r.Method == http.MethodPost:
+		var data map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&data)
+		body, _ := json.Marshal(data)
+		f.calls = append(f.calls, strings.TrimPrefix(r.URL.Path, "/api/services/")+" "+string(body))
+		json.NewEncoder(w).Encode([]State{})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeHomeAssistant) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	conn.WriteJSON(map[string]string{"type": "auth_required"})
+	var auth map[string]string
+	if conn.ReadJSON(&auth) != nil || auth["access_token"] != testToken {
+		conn.WriteJSON(map[string]string{"type": "auth_invalid", "message": "Invalid access token"})
+		return
+	}
+	conn.WriteJSON(map[string]string{"type": "auth_ok"})
+	var sub map[string]interface{}
+	if conn.ReadJSON(&sub) != nil || sub["type"] != "subscribe_events" {
+		return
+	}
+	conn.WriteJSON(map[string]interface{}{"id": 1, "type": "result", "success": true})
+	for _, e := range f.events {
+		conn.WriteJSON(map[string]interface{}{"id": 1, "type": "event", "event": e})
+	}
+	// Stay open until the watcher disconnects
+	conn.ReadMessage()
+}
+
+func stateChange(entityID, from, to string) map[string]interface{} {
+	state := func(s string) map[string]interface{} {
+		return map[string]interface{}{"entity_id": entityID, "state": s, "attributes": map[string]interface{}{"friendly_name": "Front door"}}
+	}
+	return map[string]interface{}{
+		"event_type": "state_changed",
+		"data":       map[string]interface{}{"entity_id": entityID, "old_state": state(from), "new_state": state(to)},
+	}
+}
+
+func setupSkill(t *testing.T) (*HomeAssistantSkill, *fakeHomeAssistant, *httptest.Server) {
+	fake := &fakeHomeAssistant{states: []State{
+		{EntityID: "light.living_room_ceiling", State: "on", Attributes: map[string]interface{}{"friendly_name": "Living room ceiling"}},
+		{EntityID: "light.living_room_lamp", State: "on", Attributes: map[string]interface{}{"friendly_name": "Living room lamp"}},
+		{EntityID: "light.kitchen", State: "off", Attributes: map[string]interface{}{"friendly_name": "Kitchen"}},
+		{EntityID: "lock.front_door", State: "locked", Attributes: map[string]interface{}{"friendly_name": "Front door lock"}},
+		{EntityID: "sensor.bedroom_temperature", State: "19.5", Attributes: map[string]interface{}{
+			"friendly_name": "Bedroom temperature", "unit_of_measurement": "°C",
+		}},
+	}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL, testToken, time.Second)
+	require.NoError(t, err)
+	return newHomeAssistantSkill(client, []string{"light", "switch"}), fake, server
+}
+
+func TestHomeAssistantSkill_ReadStates(t *testing.T) {
+	skill, _, _ := setupSkill(t)
+	ctx := context.Background()
+
+	result, err := skill.handleListEntities(ctx, map[string]interface{}{"domain": "light", "query": "living room"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.(map[string]interface{})["count"])
+
+	result, err = skill.handleGetState(ctx, map[string]interface{}{"entity": "bedroom temperature"})
+	require.NoError(t, err)
+	state := result.(map[string]interface{})
+	assert.Equal(t, "sensor.bedroom_temperature", state["entity_id"])
+	assert.Equal(t, "19.5", state["state"])
+	assert.Equal(t, "°C", state["unit"])
+
+	_, err = skill.handleGetState(ctx, map[string]interface{}{"entity": "living room"})
+	assert.ErrorContains(t, err, "matches 2 entities")
+	_, err = skill.handleGetState(ctx, map[string]interface{}{"entity": "light.garage"})
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestHomeAssistantSkill_CallService(t *testing.T) {
+	skill, fake, _ := setupSkill(t)
+	ctx := context.Background()
+
+	result, err := skill.handleCallService(ctx, map[string]interface{}{
+		"domain": "light", "service": "turn_off", "entity": "the living room lights",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, result.(map[string]interface{})["message"], "light.living_room_ceiling, light.living_room_lamp")
+
+	_, err = skill.handleCallService(ctx, map[string]interface{}{
+		"service": "light.turn_on", "entity": "Kitchen", "data": map[string]interface{}{"brightness_pct": 40.0},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`light/turn_off {"entity_id":["light.living_room_ceiling","light.living_room_lamp"]}`,
+		`light/turn_on {"brightness_pct":40,"entity_id":["light.kitchen"]}`,
+	}, fake.calls)
+
+	// Only allowed domains, even when the entity is smuggled in the data
+	_, err = skill.handleCallService(ctx, map[string]interface{}{"domain": "lock", "service": "unlock", "entity": "lock.front_door"})
+	assert.ErrorContains(t, err, "not allowed")
+	_, err = skill.handleCallService(ctx, map[string]interface{}{
+		"domain": "light", "service": "turn_on", "data": map[string]interface{}{"entity_id": []interface{}{"lock.front_door"}},
+	})
+	assert.ErrorContains(t, err, "not allowed")
+	assert.Len(t, fake.calls, 2)
+}
+
+func TestWatcher_PublishesWatchedChanges(t *testing.T) {
+	skill, fake, _ := setupSkill(t)
+	fake.events = []map[string]interface{}{
+		stateChange("binary_sensor.front_door", "off", "on"),
+		stateChange("binary_sensor.front_door", "on", "on"), // attributes only
+		stateChange("sensor.power_usage", "120", "130"),
+	}
+
+	bus := events.NewBus(nil)
+	received := make(chan events.Event, 4)
+	bus.Subscribe(EventStateChanged, func(ctx context.Context, e events.Event) { received <- e })
+
+	_, err := NewWatcher(skill.Client(), []string{"binary_sensor.[oops"}, bus, nil)
+	assert.Error(t, err)
+	watcher, err := NewWatcher(skill.Client(), []string{"binary_sensor.*"}, bus, nil)
+	require.NoError(t, err)
+	watcher.Start()
+	defer watcher.Stop()
+
+	select {
+	case e := <-received:
+		assert.Equal(t, "binary_sensor.front_door", e.Data["entity_id"])
+		assert.Equal(t, "Front door", e.Data["name"])
+		assert.Equal(t, "off", e.Data["old_state"])
+		assert.Equal(t, "on", e.Data["new_state"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("no state change published")
+	}
+	select {
+	case e := <-received:
+		t.Fatalf("unexpected event for %v", e.Data["entity_id"])
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,197 @@
+package homeassistant
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// EventStateChanged is published when a watched entity changes state
+const EventStateChanged = "homeassistant.state_changed"
+
+// Reconnect delays after the WebSocket connection drops
+const (
+	minBackoff = 5 * time.Second
+	maxBackoff = 5 * time.Minute
+)
+
+// wsMessage is a message on the Home Assistant WebSocket API
+type wsMessage struct {
+	ID          int    `json:"id,omitempty"`
+	Type        string `json:"type"`
+	AccessToken string `json:"access_token,omitempty"`
+	EventType   string `json:"event_type,omitempty"`
+	Success     *bool  `json:"success,omitempty"`
+	Message     string `json:"message,omitempty"`
+	Error       *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+	Event *struct {
+		EventType string `json:"event_type"`
+		Data      struct {
+			EntityID string `json:"entity_id"`
+			OldState *State `json:"old_state"`
+			NewState *State `json:"new_state"`
+		} `json:"data"`
+	} `json:"event,omitempty"`
+}
+
+// Watcher follows state changes over the WebSocket API and publishes those
+// of the watched entities on the event bus. It reconnects on its own when
+// Home Assistant restarts.
+type Watcher struct {
+	client   *Client
+	patterns []string
+	bus      *events.Bus
+	logger   *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWatcher creates a watcher for the entities matching patterns, such as
+// "binary_sensor.front_door" or "sensor.*_temperature"
+func NewWatcher(client *Client, patterns []string, bus *events.Bus, logger *zap.Logger) (*Watcher, error) {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid watch pattern %q", p)
+		}
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Watcher{client: client, patterns: patterns, bus: bus, logger: logger}, nil
+}
+
+// Watches reports whether changes of entityID are published
+func (w *Watcher) Watches(entityID string) bool {
+	for _, p := range w.patterns {
+		if ok, _ := path.Match(p, entityID); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Start connects in the background
+func (w *Watcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		backoff := minBackoff
+		for {
+			started := time.Now()
+			err := w.listen(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if time.Since(started) > maxBackoff {
+				backoff = minBackoff
+			}
+			w.logger.Warn("Home Assistant connection lost", zap.Error(err), zap.Duration("retry_in", backoff))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+	}()
+}
+
+// Stop disconnects and waits for the watcher to finish
+func (w *Watcher) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
+
+// listen authenticates, subscribes to state changes and publishes them
+// until the connection fails or ctx is done
+func (w *Watcher) listen(ctx context.Context) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, w.client.websocketURL(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	var msg wsMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		return err
+	}
+	if msg.Type == "auth_required" {
+		if err := conn.WriteJSON(wsMessage{Type: "auth", AccessToken: w.client.token}); err != nil {
+			return err
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+	}
+	if msg.Type != "auth_ok" {
+		return fmt.Errorf("home assistant rejected the access token: %s", msg.Message)
+	}
+
+	if err := conn.WriteJSON(wsMessage{ID: 1, Type: "subscribe_events", EventType: "state_changed"}); err != nil {
+		return err
+	}
+	w.logger.Info("Watching Home Assistant state changes", zap.Strings("entities", w.patterns))
+
+	for {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+		switch {
+		case msg.Type == "result" && msg.Success != nil && !*msg.Success:
+			reason := "unknown error"
+			if msg.Error != nil {
+				reason = msg.Error.Message
+			}
+			return fmt.Errorf("subscription refused: %s", reason)
+		case msg.Type == "event" && msg.Event != nil:
+			w.handle(msg)
+		}
+	}
+}
+
+func (w *Watcher) handle(msg wsMessage) {
+	data := msg.Event.Data
+	if data.NewState == nil || !w.Watches(data.EntityID) {
+		return
+	}
+	oldState := ""
+	if data.OldState != nil {
+		oldState = data.OldState.State
+	}
+	// Attribute updates (a sensor's last-seen time) are not state changes
+	if oldState == data.NewState.State {
+		return
+	}
+	w.bus.Publish(context.Background(), events.Event{
+		Type:   EventStateChanged,
+		UserID: household.SharedUserID,
+		Source: "homeassistant",
+		Data: map[string]interface{}{
+			"entity_id": data.EntityID,
+			"name":      data.NewState.Name(),
+			"domain":    data.NewState.Domain(),
+			"old_state": oldState,
+			"new_state": data.NewState.State,
+		},
+		Time: time.Now(),
+	})
+}
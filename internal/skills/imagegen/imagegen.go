@@ -0,0 +1,244 @@
+// Package imagegen creates images from text prompts, with OpenAI Images,
+// Stability AI, or a local Automatic1111 or ComfyUI server
+package imagegen
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"gorm.io/gorm"
+)
+
+// MaxCount is the most images one request makes
+const MaxCount = 4
+
+// Shapes of the images that can be asked for
+const (
+	ShapeSquare    = "square"
+	ShapePortrait  = "portrait"
+	ShapeLandscape = "landscape"
+)
+
+// Request describes the image to make
+type Request struct {
+	Prompt         string
+	NegativePrompt string // what to keep out of the image; not every provider takes it
+	Shape          string // square, portrait or landscape
+}
+
+// Image is a generated image
+type Image struct {
+	Data          []byte
+	Format        string // png, jpeg or webp
+	RevisedPrompt string // the prompt the provider actually used, when it rewrites prompts
+}
+
+// Provider generates images
+type Provider interface {
+	Name() string
+	IsAvailable() bool
+	Generate(ctx context.Context, req Request) (*Image, error)
+}
+
+// Config holds image generation configuration
+type Config struct {
+	Enabled    bool
+	Provider   string // openai, stability, automatic1111 or comfyui
+	APIKey     string // for OpenAI or Stability
+	Model      string // e.g. gpt-image-1 or dall-e-3 for OpenAI, core or ultra for Stability
+	BaseURL    string // of the API or local server
+	Workflow   string // ComfyUI workflow exported in API format
+	DailyLimit int    // images per user per day; 0 for no limit
+	OutputDir  string // where generated images are kept
+}
+
+// ImageGenSkill creates images on request
+type ImageGenSkill struct {
+	*skills.BaseSkill
+	config   Config
+	provider Provider
+	store    *Store
+}
+
+// NewImageGenSkill creates the image generation skill
+func NewImageGenSkill(db *gorm.DB, cfg Config) (*ImageGenSkill, error) {
+	store, err := NewStore(db)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := NewProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.OutputDir == "" {
+		cfg.OutputDir = filepath.Join(os.TempDir(), "myrai-images")
+	}
+
+	s := &ImageGenSkill{
+		BaseSkill: skills.NewBaseSkill("imagegen", "Create images from descriptions", "1.0.0"),
+		config:    cfg,
+		provider:  provider,
+		store:     store,
+	}
+	s.registerTools()
+	return s, nil
+}
+
+// NewProvider returns the provider the config names
+func NewProvider(cfg Config) (Provider, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "", "openai":
+		return NewOpenAIProvider(cfg.APIKey, cfg.Model, cfg.BaseURL), nil
+	case "stability":
+		return NewStabilityProvider(cfg.APIKey, cfg.Model, cfg.BaseURL), nil
+	case "automatic1111", "a1111", "sdwebui":
+		return NewAutomatic1111Provider(cfg.BaseURL), nil
+	case "comfyui":
+		return NewComfyUIProvider(cfg.BaseURL, cfg.Workflow), nil
+	}
+	return nil, fmt.Errorf("unknown image provider %q: use openai, stability, automatic1111 or comfyui", cfg.Provider)
+}
+
+// IsEnabled reports whether images can be generated
+func (s *ImageGenSkill) IsEnabled() bool {
+	return s.config.Enabled && s.provider.IsAvailable()
+}
+
+func (s *ImageGenSkill) registerTools() {
+	s.AddTool(skills.Tool{
+		Name:        "generate_image",
+		Description: "Create an image from a description and send it to the user. Describe the subject, style, composition and lighting in detail",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"prompt": map[string]interface{}{
+					"type":        "string",
+					"description": "Detailed description of the image",
+				},
+				"negative_prompt": map[string]interface{}{
+					"type":        "string",
+					"description": "What to keep out of the image, e.g. 'text, blurry' (ignored by OpenAI)",
+				},
+				"shape": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{ShapeSquare, ShapePortrait, ShapeLandscape},
+					"description": "Shape of the image (default: square)",
+				},
+				"count": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Number of images, 1 to %d (default: 1)", MaxCount),
+				},
+			},
+			"required": []string{"prompt"},
+		},
+		Handler: s.handleGenerate,
+	})
+}
+
+func (s *ImageGenSkill) handleGenerate(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	prompt, _ := args["prompt"].(string)
+	if strings.TrimSpace(prompt) == "" {
+		return nil, fmt.Errorf("prompt is required")
+	}
+	req := Request{Prompt: prompt, Shape: ShapeSquare}
+	req.NegativePrompt, _ = args["negative_prompt"].(string)
+	if shape, _ := args["shape"].(string); shape != "" {
+		switch shape {
+		case ShapeSquare, ShapePortrait, ShapeLandscape:
+			req.Shape = shape
+		default:
+			return nil, fmt.Errorf("shape must be square, portrait or landscape")
+		}
+	}
+	count := 1
+	if c, ok := args["count"].(float64); ok {
+		count = max(1, min(MaxCount, int(c)))
+	}
+
+	userID := getUserID(ctx)
+	remaining := -1
+	if s.config.DailyLimit > 0 {
+		used, err := s.store.CountSince(userID, startOfDay(time.Now()))
+		if err != nil {
+			return nil, err
+		}
+		remaining = s.config.DailyLimit - used
+		if remaining <= 0 {
+			return nil, fmt.Errorf("daily image limit of %d reached; it resets at midnight", s.config.DailyLimit)
+		}
+		count = min(count, remaining)
+	}
+
+	if err := os.MkdirAll(s.config.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create image directory: %w", err)
+	}
+
+	var paths []string
+	var revised string
+	sent := true
+	for i := 0; i < count; i++ {
+		img, err := s.provider.Generate(ctx, req)
+		if err != nil {
+			if len(paths) == 0 {
+				return nil, err
+			}
+			break // keep the images already made
+		}
+		id := idgen.Generate(idgen.PrefixImage)
+		path := filepath.Join(s.config.OutputDir, id+"."+img.Format)
+		if err := os.WriteFile(path, img.Data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to save image: %w", err)
+		}
+		if err := s.store.Record(&Generation{
+			ID:       id,
+			UserID:   userID,
+			Provider: s.provider.Name(),
+			Prompt:   prompt,
+			Path:     path,
+		}); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+		if img.RevisedPrompt != "" {
+			revised = img.RevisedPrompt
+		}
+		if !skills.Attach(ctx, path) {
+			sent = false
+		}
+	}
+
+	result := map[string]interface{}{
+		"success":  true,
+		"images":   paths,
+		"provider": s.provider.Name(),
+		"sent":     sent,
+	}
+	if !sent {
+		result["message"] = "Images saved; tell the user where to find them"
+	}
+	if revised != "" {
+		result["revised_prompt"] = revised
+	}
+	if remaining >= 0 {
+		result["remaining_today"] = remaining - len(paths)
+	}
+	return result, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func getUserID(ctx context.Context) string {
+	if userID, ok := ctx.Value("user_id").(string); ok {
+		return userID
+	}
+	return "default_user"
+}
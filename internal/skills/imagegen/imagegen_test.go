@@ -0,0 +1,229 @@
+package imagegen
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// pngData is the start of a PNG, enough to be recognised as one
+var pngData = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	return db
+}
+
+// openAIServer answers image requests like the OpenAI Images API
+func openAIServer(t *testing.T, requests *[]map[string]interface{}) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/images/generations", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		*requests = append(*requests, body)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]string{{"b64_json": base64.StdEncoding.EncodeToString(pngData), "revised_prompt": "A cat in a hat, watercolour"}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGenerateImage(t *testing.T) {
+	var requests []map[string]interface{}
+	srv := openAIServer(t, &requests)
+	dir := t.TempDir()
+	skill, err := NewImageGenSkill(newTestDB(t), Config{
+		Enabled:    true,
+		APIKey:     "sk-test",
+		Model:      "dall-e-3",
+		BaseURL:    srv.URL,
+		DailyLimit: 3,
+		OutputDir:  dir,
+	})
+	require.NoError(t, err)
+	require.True(t, skill.IsEnabled())
+
+	ctx := context.WithValue(context.Background(), "user_id", "alice")
+	ctx, attachments := skills.WithAttachments(ctx)
+	result, err := skill.handleGenerate(ctx, map[string]interface{}{
+		"prompt": "a cat in a hat",
+		"shape":  "landscape",
+		"count":  float64(2),
+	})
+	require.NoError(t, err)
+	res := result.(map[string]interface{})
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, "1792x1024", requests[0]["size"])
+	assert.Equal(t, "b64_json", requests[0]["response_format"])
+	paths := res["images"].([]string)
+	require.Len(t, paths, 2)
+	assert.Equal(t, paths, attachments.Paths(), "images are sent to the user")
+	assert.Equal(t, ".png", filepath.Ext(paths[0]))
+	data, _ := os.ReadFile(paths[0])
+	assert.Equal(t, pngData, data)
+	assert.Equal(t, true, res["sent"])
+	assert.Equal(t, 1, res["remaining_today"])
+	assert.Equal(t, "A cat in a hat, watercolour", res["revised_prompt"])
+
+	// Only what's left of the quota is made
+	result, err = skill.handleGenerate(ctx, map[string]interface{}{"prompt": "a dog", "count": float64(4)})
+	require.NoError(t, err)
+	assert.Len(t, result.(map[string]interface{})["images"], 1)
+
+	_, err = skill.handleGenerate(ctx, map[string]interface{}{"prompt": "a dog"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "daily image limit")
+
+	// Other users have their own quota
+	bob := context.WithValue(context.Background(), "user_id", "bob")
+	_, err = skill.handleGenerate(bob, map[string]interface{}{"prompt": "a dog"})
+	require.NoError(t, err)
+}
+
+func TestGenerateImage_QuotaResetsDaily(t *testing.T) {
+	db := newTestDB(t)
+	skill, err := NewImageGenSkill(db, Config{Enabled: true, APIKey: "sk-test", DailyLimit: 1, OutputDir: t.TempDir()})
+	require.NoError(t, err)
+
+	require.NoError(t, skill.store.Record(&Generation{ID: "img_old", UserID: "alice", CreatedAt: time.Now().Add(-25 * time.Hour)}))
+	count, err := skill.store.CountSince("alice", startOfDay(time.Now()))
+	require.NoError(t, err)
+	assert.Equal(t, 0, count, "yesterday's images don't count")
+}
+
+func TestNewProvider(t *testing.T) {
+	for name, want := range map[string]string{
+		"":              "openai",
+		"OpenAI":        "openai",
+		"stability":     "stability",
+		"automatic1111": "automatic1111",
+		"comfyui":       "comfyui",
+	} {
+		p, err := NewProvider(Config{Provider: name})
+		require.NoError(t, err)
+		assert.Equal(t, want, p.Name())
+	}
+	_, err := NewProvider(Config{Provider: "midjourney"})
+	assert.Error(t, err)
+
+	p, _ := NewProvider(Config{Provider: "comfyui"})
+	assert.False(t, p.IsAvailable(), "ComfyUI needs a workflow")
+}
+
+func TestStabilityProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2beta/stable-image/generate/core", r.URL.Path)
+		assert.Equal(t, "image/*", r.Header.Get("Accept"))
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "a lighthouse", r.FormValue("prompt"))
+		assert.Equal(t, "people", r.FormValue("negative_prompt"))
+		assert.Equal(t, "2:3", r.FormValue("aspect_ratio"))
+		w.Write(pngData)
+	}))
+	defer srv.Close()
+
+	img, err := NewStabilityProvider("sk-test", "", srv.URL).Generate(context.Background(), Request{
+		Prompt: "a lighthouse", NegativePrompt: "people", Shape: ShapePortrait,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "png", img.Format)
+}
+
+func TestProviderError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"message": "Your request was rejected by the safety system."}}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewOpenAIProvider("sk-test", "", srv.URL).Generate(context.Background(), Request{Prompt: "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "safety system")
+}
+
+func TestAutomatic1111Provider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sdapi/v1/txt2img", r.URL.Path)
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, float64(1216), body["width"])
+		assert.Equal(t, float64(832), body["height"])
+		json.NewEncoder(w).Encode(map[string]interface{}{"images": []string{base64.StdEncoding.EncodeToString(pngData)}})
+	}))
+	defer srv.Close()
+
+	img, err := NewAutomatic1111Provider(srv.URL).Generate(context.Background(), Request{Prompt: "a forest", Shape: ShapeLandscape})
+	require.NoError(t, err)
+	assert.Equal(t, pngData, img.Data)
+}
+
+func TestFillWorkflow(t *testing.T) {
+	workflow := `{"3": {"inputs": {"seed": "{{seed}}", "width": "{{width}}", "height": "{{height}}"}},
+		"6": {"inputs": {"text": "{{prompt}}"}}, "7": {"inputs": {"text": "{{negative_prompt}}"}}}`
+	filled, err := fillWorkflow(workflow, Request{Prompt: `a "quoted" cat`, NegativePrompt: "dogs", Shape: ShapeSquare}, 42)
+	require.NoError(t, err)
+
+	var nodes map[string]struct {
+		Inputs map[string]interface{} `json:"inputs"`
+	}
+	require.NoError(t, json.Unmarshal(filled, &nodes))
+	assert.Equal(t, float64(42), nodes["3"].Inputs["seed"])
+	assert.Equal(t, float64(1024), nodes["3"].Inputs["width"])
+	assert.Equal(t, `a "quoted" cat`, nodes["6"].Inputs["text"])
+	assert.Equal(t, "dogs", nodes["7"].Inputs["text"])
+}
+
+func TestComfyUIProvider(t *testing.T) {
+	workflow := filepath.Join(t.TempDir(), "workflow.json")
+	os.WriteFile(workflow, []byte(`{"6": {"inputs": {"text": "{{prompt}}"}}}`), 0644)
+
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/prompt":
+			var body struct {
+				Prompt map[string]interface{} `json:"prompt"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			assert.Contains(t, body.Prompt, "6")
+			w.Write([]byte(`{"prompt_id": "p1"}`))
+		case r.URL.Path == "/history/p1":
+			polls++
+			if polls == 1 {
+				w.Write([]byte(`{}`)) // still running
+				return
+			}
+			w.Write([]byte(`{"p1": {"outputs": {"9": {"images": [{"filename": "out_001.png", "subfolder": "", "type": "output"}]}}}}`))
+		case strings.HasPrefix(r.URL.Path, "/view"):
+			assert.Equal(t, "out_001.png", r.URL.Query().Get("filename"))
+			w.Write(pngData)
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewComfyUIProvider(srv.URL, workflow)
+	p.poll = time.Millisecond
+	img, err := p.Generate(context.Background(), Request{Prompt: "a castle"})
+	require.NoError(t, err)
+	assert.Equal(t, pngData, img.Data)
+	assert.Equal(t, 2, polls)
+}
@@ -0,0 +1,505 @@
+package imagegen
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxImageSize limits how much of a response is read as an image
+const maxImageSize = 32 << 20
+
+// imageFormat names the format of image data, for its file extension
+func imageFormat(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return "jpg"
+	case "image/webp":
+		return "webp"
+	}
+	return "png"
+}
+
+// apiError describes a failed response, with the provider's message if it
+// sent one
+func apiError(api string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var e struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+		Errors  []string        `json:"errors"`
+	}
+	msg := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &e) == nil {
+		var nested struct {
+			Message string `json:"message"`
+		}
+		switch {
+		case json.Unmarshal(e.Error, &nested) == nil && nested.Message != "":
+			msg = nested.Message
+		case e.Message != "":
+			msg = e.Message
+		case len(e.Errors) > 0:
+			msg = strings.Join(e.Errors, "; ")
+		}
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%s rate limit reached, try again later", api)
+	}
+	return fmt.Errorf("%s returned status %d: %s", api, resp.StatusCode, msg)
+}
+
+// OpenAIProvider uses the OpenAI Images API
+type OpenAIProvider struct {
+	apiKey  string
+	model   string
+	baseURL string
+	client  *http.Client
+}
+
+// NewOpenAIProvider creates an OpenAI Images provider
+func NewOpenAIProvider(apiKey, model, baseURL string) *OpenAIProvider {
+	if model == "" {
+		model = "gpt-image-1"
+	}
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	return &OpenAIProvider{
+		apiKey:  apiKey,
+		model:   model,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 3 * time.Minute},
+	}
+}
+
+// Name returns the provider name
+func (p *OpenAIProvider) Name() string { return "openai" }
+
+// IsAvailable checks if an API key is configured
+func (p *OpenAIProvider) IsAvailable() bool { return p.apiKey != "" }
+
+// size maps a shape to a size the model accepts
+func (p *OpenAIProvider) size(shape string) string {
+	tall, wide := "1024x1536", "1536x1024"
+	if strings.HasPrefix(p.model, "dall-e-3") {
+		tall, wide = "1024x1792", "1792x1024"
+	}
+	switch shape {
+	case ShapePortrait:
+		return tall
+	case ShapeLandscape:
+		return wide
+	}
+	return "1024x1024"
+}
+
+// Generate creates an image
+func (p *OpenAIProvider) Generate(ctx context.Context, req Request) (*Image, error) {
+	if !p.IsAvailable() {
+		return nil, fmt.Errorf("OpenAI API key not configured")
+	}
+
+	body := map[string]interface{}{
+		"model":  p.model,
+		"prompt": req.Prompt,
+		"size":   p.size(req.Shape),
+		"n":      1,
+	}
+	// DALL·E returns links unless asked for the image; gpt-image models
+	// always return it
+	if strings.HasPrefix(p.model, "dall-e") {
+		body["response_format"] = "b64_json"
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/images/generations", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError("OpenAI API", resp)
+	}
+
+	var result struct {
+		Data []struct {
+			B64JSON       string `json:"b64_json"`
+			URL           string `json:"url"`
+			RevisedPrompt string `json:"revised_prompt"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode OpenAI response: %w", err)
+	}
+	if len(result.Data) == 0 {
+		return nil, fmt.Errorf("OpenAI returned no image")
+	}
+
+	d := result.Data[0]
+	var data []byte
+	if d.B64JSON != "" {
+		if data, err = base64.StdEncoding.DecodeString(d.B64JSON); err != nil {
+			return nil, fmt.Errorf("failed to decode image: %w", err)
+		}
+	} else if data, err = download(ctx, p.client, d.URL); err != nil {
+		return nil, err
+	}
+	return &Image{Data: data, Format: imageFormat(data), RevisedPrompt: d.RevisedPrompt}, nil
+}
+
+// StabilityProvider uses Stability AI's Stable Image API
+type StabilityProvider struct {
+	apiKey  string
+	model   string
+	baseURL string
+	client  *http.Client
+}
+
+// NewStabilityProvider creates a Stability AI provider. The model is the
+// Stable Image service: core, ultra or sd3.
+func NewStabilityProvider(apiKey, model, baseURL string) *StabilityProvider {
+	if model == "" {
+		model = "core"
+	}
+	if baseURL == "" {
+		baseURL = "https://api.stability.ai"
+	}
+	return &StabilityProvider{
+		apiKey:  apiKey,
+		model:   model,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 3 * time.Minute},
+	}
+}
+
+// Name returns the provider name
+func (p *StabilityProvider) Name() string { return "stability" }
+
+// IsAvailable checks if an API key is configured
+func (p *StabilityProvider) IsAvailable() bool { return p.apiKey != "" }
+
+// Generate creates an image
+func (p *StabilityProvider) Generate(ctx context.Context, req Request) (*Image, error) {
+	if !p.IsAvailable() {
+		return nil, fmt.Errorf("Stability API key not configured")
+	}
+
+	aspect := "1:1"
+	switch req.Shape {
+	case ShapePortrait:
+		aspect = "2:3"
+	case ShapeLandscape:
+		aspect = "3:2"
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("prompt", req.Prompt)
+	if req.NegativePrompt != "" {
+		w.WriteField("negative_prompt", req.NegativePrompt)
+	}
+	w.WriteField("aspect_ratio", aspect)
+	w.WriteField("output_format", "png")
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/v2beta/stable-image/generate/"+p.model, &body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	httpReq.Header.Set("Content-Type", w.FormDataContentType())
+	httpReq.Header.Set("Accept", "image/*")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError("Stability API", resp)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize))
+	if err != nil {
+		return nil, err
+	}
+	return &Image{Data: data, Format: imageFormat(data)}, nil
+}
+
+// Automatic1111Provider uses a Stable Diffusion web UI (Automatic1111 or
+// Forge) started with --api
+type Automatic1111Provider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewAutomatic1111Provider creates a provider for a local web UI
+func NewAutomatic1111Provider(baseURL string) *Automatic1111Provider {
+	if baseURL == "" {
+		baseURL = "http://127.0.0.1:7860"
+	}
+	return &Automatic1111Provider{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+// Name returns the provider name
+func (p *Automatic1111Provider) Name() string { return "automatic1111" }
+
+// IsAvailable always reports true; an unreachable server fails the request
+func (p *Automatic1111Provider) IsAvailable() bool { return true }
+
+// Generate creates an image
+func (p *Automatic1111Provider) Generate(ctx context.Context, req Request) (*Image, error) {
+	width, height := localSize(req.Shape)
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"prompt":          req.Prompt,
+		"negative_prompt": req.NegativePrompt,
+		"width":           width,
+		"height":          height,
+		"steps":           30,
+	})
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/sdapi/v1/txt2img", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("Automatic1111 not reachable at %s: %w", p.baseURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError("Automatic1111", resp)
+	}
+
+	var result struct {
+		Images []string `json:"images"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Automatic1111 response: %w", err)
+	}
+	if len(result.Images) == 0 {
+		return nil, fmt.Errorf("Automatic1111 returned no image")
+	}
+	data, err := base64.StdEncoding.DecodeString(result.Images[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return &Image{Data: data, Format: imageFormat(data)}, nil
+}
+
+// ComfyUIProvider queues a workflow on a ComfyUI server. The workflow is
+// exported with "Save (API Format)" and marks where the request goes with
+// the placeholders "{{prompt}}", "{{negative_prompt}}", "{{width}}",
+// "{{height}}" and "{{seed}}".
+type ComfyUIProvider struct {
+	baseURL  string
+	workflow string // path
+	client   *http.Client
+	poll     time.Duration
+}
+
+// NewComfyUIProvider creates a provider for a ComfyUI server
+func NewComfyUIProvider(baseURL, workflow string) *ComfyUIProvider {
+	if baseURL == "" {
+		baseURL = "http://127.0.0.1:8188"
+	}
+	return &ComfyUIProvider{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		workflow: workflow,
+		client:   &http.Client{Timeout: time.Minute},
+		poll:     time.Second,
+	}
+}
+
+// Name returns the provider name
+func (p *ComfyUIProvider) Name() string { return "comfyui" }
+
+// IsAvailable checks if a workflow is configured
+func (p *ComfyUIProvider) IsAvailable() bool { return p.workflow != "" }
+
+// fillWorkflow puts the request into the workflow's placeholders
+func fillWorkflow(workflow string, req Request, seed int64) (json.RawMessage, error) {
+	quote := func(s string) string {
+		b, _ := json.Marshal(s)
+		return string(b[1 : len(b)-1])
+	}
+	width, height := localSize(req.Shape)
+	filled := strings.NewReplacer(
+		`"{{width}}"`, strconv.Itoa(width),
+		`"{{height}}"`, strconv.Itoa(height),
+		`"{{seed}}"`, strconv.FormatInt(seed, 10),
+		"{{prompt}}", quote(req.Prompt),
+		"{{negative_prompt}}", quote(req.NegativePrompt),
+	).Replace(workflow)
+	if !json.Valid([]byte(filled)) {
+		return nil, fmt.Errorf("ComfyUI workflow is not valid JSON")
+	}
+	return json.RawMessage(filled), nil
+}
+
+// Generate queues the workflow and waits for its first image
+func (p *ComfyUIProvider) Generate(ctx context.Context, req Request) (*Image, error) {
+	if !p.IsAvailable() {
+		return nil, fmt.Errorf("ComfyUI workflow not configured")
+	}
+	workflow, err := os.ReadFile(p.workflow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ComfyUI workflow: %w", err)
+	}
+	prompt, err := fillWorkflow(string(workflow), req, rand.Int63n(1<<32))
+	if err != nil {
+		return nil, err
+	}
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{"prompt": prompt})
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/prompt", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("ComfyUI not reachable at %s: %w", p.baseURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError("ComfyUI", resp)
+	}
+	var queued struct {
+		PromptID string `json:"prompt_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&queued); err != nil || queued.PromptID == "" {
+		return nil, fmt.Errorf("failed to queue ComfyUI workflow")
+	}
+
+	// The prompt shows up in the history once it has run
+	ticker := time.NewTicker(p.poll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+		image, done, err := p.result(ctx, queued.PromptID)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return image, nil
+		}
+	}
+}
+
+// result fetches the first image of a finished prompt. done is false while
+// it's still queued or running.
+func (p *ComfyUIProvider) result(ctx context.Context, promptID string) (*Image, bool, error) {
+	var history map[string]struct {
+		Outputs map[string]struct {
+			Images []struct {
+				Filename  string `json:"filename"`
+				Subfolder string `json:"subfolder"`
+				Type      string `json:"type"`
+			} `json:"images"`
+		} `json:"outputs"`
+		Status struct {
+			StatusStr string `json:"status_str"`
+		} `json:"status"`
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/history/"+url.PathEscape(promptID), nil)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, apiError("ComfyUI", resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		return nil, false, fmt.Errorf("failed to decode ComfyUI history: %w", err)
+	}
+
+	entry, ok := history[promptID]
+	if !ok {
+		return nil, false, nil
+	}
+	if entry.Status.StatusStr == "error" {
+		return nil, false, fmt.Errorf("ComfyUI workflow failed")
+	}
+	for _, output := range entry.Outputs {
+		for _, img := range output.Images {
+			q := url.Values{"filename": {img.Filename}, "subfolder": {img.Subfolder}, "type": {img.Type}}
+			data, err := download(ctx, p.client, p.baseURL+"/view?"+q.Encode())
+			if err != nil {
+				return nil, false, err
+			}
+			return &Image{Data: data, Format: imageFormat(data)}, true, nil
+		}
+	}
+	return nil, false, fmt.Errorf("ComfyUI workflow produced no image; it needs a Save Image node")
+}
+
+// localSize maps a shape to a size Stable Diffusion XL models draw well
+func localSize(shape string) (width, height int) {
+	switch shape {
+	case ShapePortrait:
+		return 832, 1216
+	case ShapeLandscape:
+		return 1216, 832
+	}
+	return 1024, 1024
+}
+
+// download fetches an image
+func download(ctx context.Context, client *http.Client, link string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", link, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxImageSize))
+}
+
+// Ensure every provider implements Provider
+var (
+	_ Provider = (*OpenAIProvider)(nil)
+	_ Provider = (*StabilityProvider)(nil)
+	_ Provider = (*Automatic1111Provider)(nil)
+	_ Provider = (*ComfyUIProvider)(nil)
+)
@@ -0,0 +1,66 @@
+package imagegen
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"gorm.io/gorm"
+)
+
+// Generation records an image that was made, counting towards the daily
+// limit
+type Generation struct {
+	ID        string    `gorm:"primaryKey" json:"id"`
+	UserID    string    `gorm:"index:idx_image_user_created" json:"-"`
+	Provider  string    `json:"provider"`
+	Prompt    string    `json:"prompt"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `gorm:"index:idx_image_user_created" json:"created_at"`
+}
+
+// TableName sets the table name
+func (Generation) TableName() string { return "image_generations" }
+
+// Store keeps the record of generated images
+type Store struct {
+	db *gorm.DB
+}
+
+func init() {
+	store.RegisterMigrations("imagegen", store.Migration{
+		Version: 1,
+		Name:    "create image generation table",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Generation{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Generation{})
+		},
+	})
+}
+
+// NewStore creates the image generation store
+func NewStore(db *gorm.DB) (*Store, error) {
+	if err := store.Migrate(db, "imagegen"); err != nil {
+		return nil, fmt.Errorf("failed to migrate image generation schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Record saves a generated image
+func (s *Store) Record(g *Generation) error {
+	if g.CreatedAt.IsZero() {
+		g.CreatedAt = time.Now()
+	}
+	return s.db.Create(g).Error
+}
+
+// CountSince counts the images a user has made since t
+func (s *Store) CountSince(userID string, t time.Time) (int, error) {
+	var count int64
+	err := s.db.Model(&Generation{}).
+		Where("user_id = ? AND created_at >= ?", userID, t).
+		Count(&count).Error
+	return int(count), err
+}
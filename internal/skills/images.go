@@ -0,0 +1,43 @@
+package skills
+
+import (
+	"context"
+	"sync"
+)
+
+type imagesKey struct{}
+
+// Images collects the images tools want the model to look at, e.g. browser
+// screenshots. The agent sends them with the tool results when the model
+// accepts images.
+type Images struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+// WithImages returns a context tools can show images to the model from
+func WithImages(ctx context.Context) (context.Context, *Images) {
+	i := &Images{}
+	return context.WithValue(ctx, imagesKey{}, i), i
+}
+
+// ShowImage queues an image for the model to see. It reports false when ctx
+// doesn't collect images, as when the model can't take them, so the tool can
+// describe the image some other way.
+func ShowImage(ctx context.Context, path string) bool {
+	i, ok := ctx.Value(imagesKey{}).(*Images)
+	if !ok {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.paths = append(i.paths, path)
+	return true
+}
+
+// Paths returns the queued images in the order they were added
+func (i *Images) Paths() []string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return append([]string(nil), i.paths...)
+}
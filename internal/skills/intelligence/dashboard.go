@@ -0,0 +1,105 @@
+package intelligence
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/skills/calendar"
+	"github.com/gmsas95/myrai-cli/internal/skills/expenses"
+	"github.com/gmsas95/myrai-cli/internal/skills/health"
+	"github.com/gmsas95/myrai-cli/internal/skills/shopping"
+	"github.com/gmsas95/myrai-cli/internal/skills/tasks"
+)
+
+// DashboardCacheTTL is how long a generated dashboard is reused. Skill
+// events for the user clear it sooner.
+const DashboardCacheTTL = time.Minute
+
+// HealthSource is the health data the dashboard reads. *health.Store
+// implements it.
+type HealthSource interface {
+	ListMedications(userID string, activeOnly bool) ([]health.Medication, error)
+	GetMedicationLogs(userID, medicationID string, start, end time.Time) ([]health.MedicationLog, error)
+	GetLatestMetric(userID, metricType string) (*health.HealthMetric, error)
+	GetUpcomingAppointments(userID string, limit int) ([]health.HealthAppointment, error)
+}
+
+// TaskSource is the task data the dashboard reads. *tasks.Store implements it.
+type TaskSource interface {
+	ListTasks(userID string, opts tasks.ListOptions) (*tasks.TaskList, error)
+	GetOverdueTasks(userID string) ([]tasks.Task, error)
+	GetTasksDueSoon(userID string, within time.Duration) ([]tasks.Task, error)
+}
+
+// ShoppingSource is the shopping data the dashboard reads. *shopping.Store
+// implements it.
+type ShoppingSource interface {
+	ListLists(userID string, activeOnly bool) ([]shopping.ShoppingList, error)
+	GetItemsByList(listID string) ([]shopping.ShoppingItem, error)
+}
+
+// ExpenseSource is the spending data the dashboard reads. *expenses.Store
+// implements it.
+type ExpenseSource interface {
+	GetExpensesByDateRange(userID string, start, end time.Time) ([]expenses.Expense, error)
+	GetBudgets(userID string, activeOnly bool) ([]expenses.Budget, error)
+	GetBudgetStatus(budget *expenses.Budget) (*expenses.BudgetStatus, error)
+}
+
+// CalendarSource is the calendar data the dashboard reads. *calendar.Store
+// implements it.
+type CalendarSource interface {
+	GetUpcomingEvents(userID string, limit int) ([]calendar.CalendarEvent, error)
+}
+
+type cachedDashboard struct {
+	dashboard *LifeDashboard
+	expires   time.Time
+}
+
+// dashboardCache keeps recently generated dashboards per user
+type dashboardCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]cachedDashboard
+}
+
+func newDashboardCache(ttl time.Duration) *dashboardCache {
+	return &dashboardCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cachedDashboard),
+	}
+}
+
+// get returns a copy of the user's cached dashboard, if still fresh
+func (c *dashboardCache) get(userID string) (*LifeDashboard, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[userID]
+	if !ok || c.now().After(entry.expires) {
+		delete(c.entries, userID)
+		return nil, false
+	}
+	d := *entry.dashboard
+	return &d, true
+}
+
+func (c *dashboardCache) put(userID string, d *LifeDashboard) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stored := *d
+	c.entries[userID] = cachedDashboard{dashboard: &stored, expires: c.now().Add(c.ttl)}
+}
+
+func (c *dashboardCache) invalidate(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, userID)
+}
+
+// startOfDay returns local midnight on t's day
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
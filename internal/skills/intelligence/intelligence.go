@@ -4,13 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/events"
 	"github.com/gmsas95/myrai-cli/internal/skills"
-	"github.com/gmsas95/myrai-cli/internal/skills/calendar"
-	"github.com/gmsas95/myrai-cli/internal/skills/expenses"
-	"github.com/gmsas95/myrai-cli/internal/skills/health"
-	"github.com/gmsas95/myrai-cli/internal/skills/shopping"
 	"github.com/gmsas95/myrai-cli/internal/skills/tasks"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -24,14 +22,16 @@ type IntelligenceSkill struct {
 	suggestionEngine *SuggestionEngine
 	logger           *zap.Logger
 	// External skill stores for dashboard data
-	healthStore   *health.Store
-	tasksStore    *tasks.Store
-	shoppingStore *shopping.Store
-	expensesStore *expenses.Store
-	calendarStore *calendar.Store
+	healthStore   HealthSource
+	tasksStore    TaskSource
+	shoppingStore ShoppingSource
+	expensesStore ExpenseSource
+	calendarStore CalendarSource
+	dashboards    *dashboardCache
 }
 
-// NewIntelligenceSkill creates a new intelligence skill with optional external stores
+// NewIntelligenceSkill creates a new intelligence skill with optional external
+// stores. Each dependency is matched by the source interface it implements.
 func NewIntelligenceSkill(db *gorm.DB, logger *zap.Logger, deps ...interface{}) (*IntelligenceSkill, error) {
 	store, err := NewStore(db)
 	if err != nil {
@@ -44,20 +44,21 @@ func NewIntelligenceSkill(db *gorm.DB, logger *zap.Logger, deps ...interface{})
 		analyzer:         NewPatternAnalyzer(store),
 		suggestionEngine: NewSuggestionEngine(store),
 		logger:           logger,
+		dashboards:       newDashboardCache(DashboardCacheTTL),
 	}
 
 	// Extract optional dependencies
 	for _, dep := range deps {
 		switch d := dep.(type) {
-		case *health.Store:
+		case HealthSource:
 			skill.healthStore = d
-		case *tasks.Store:
+		case TaskSource:
 			skill.tasksStore = d
-		case *shopping.Store:
+		case ShoppingSource:
 			skill.shoppingStore = d
-		case *expenses.Store:
+		case ExpenseSource:
 			skill.expensesStore = d
-		case *calendar.Store:
+		case CalendarSource:
 			skill.calendarStore = d
 		}
 	}
@@ -78,6 +79,10 @@ func (i *IntelligenceSkill) registerTools() {
 						"type":        "boolean",
 						"description": "Include AI-generated insights",
 					},
+					"refresh": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Rebuild the dashboard instead of using the last minute's copy",
+					},
 				},
 			},
 		},
@@ -133,6 +138,7 @@ func (i *IntelligenceSkill) registerTools() {
 		},
 		{
 			Name:        "list_patterns",
+			ReadOnly:    true,
 			Description: "View your detected behavior patterns",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -169,6 +175,7 @@ func (i *IntelligenceSkill) registerTools() {
 		},
 		{
 			Name:        "list_workflows",
+			ReadOnly:    true,
 			Description: "List your automated workflows",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -296,6 +303,15 @@ func (i *IntelligenceSkill) handleGetLifeDashboard(ctx context.Context, args map
 	userID := i.getUserID(ctx)
 	includeInsights := getBoolArg(args, "include_insights", true)
 
+	if !getBoolArg(args, "refresh", false) {
+		if dashboard, ok := i.dashboards.get(userID); ok {
+			if !includeInsights {
+				dashboard.Insights = nil
+			}
+			return dashboard, nil
+		}
+	}
+
 	// Build dashboard
 	dashboard := &LifeDashboard{
 		UserID:      userID,
@@ -309,15 +325,18 @@ func (i *IntelligenceSkill) handleGetLifeDashboard(ctx context.Context, args map
 	dashboard.Shopping = i.fetchShoppingData(userID)
 	dashboard.Social = i.fetchSocialData(userID)
 
-	// Generate insights
-	if includeInsights {
-		insights, _ := i.analyzer.GenerateInsights(userID)
-		dashboard.Insights = insights
-	}
+	// Generate insights; they are always cached so a later request that
+	// wants them can reuse this dashboard
+	insights, _ := i.analyzer.GenerateInsights(userID)
+	dashboard.Insights = insights
 
 	// Fetch upcoming items from calendar and tasks
 	dashboard.Upcoming = i.fetchUpcomingItems(userID)
 
+	i.dashboards.put(userID, dashboard)
+	if !includeInsights {
+		dashboard.Insights = nil
+	}
 	return dashboard, nil
 }
 
@@ -336,50 +355,86 @@ func (i *IntelligenceSkill) fetchHealthData(userID string) DashboardHealth {
 		return health
 	}
 
-	// Count doses for today
-	dosesToday := 0
-	dosesRemaining := 0
+	now := time.Now()
+	today := startOfDay(now)
 
+	// Every scheduled dose today that has no log yet is still to take
+	expectedToday := 0
 	for _, med := range medications {
-		// Count expected doses based on frequency
-		expectedDoses := len(med.Times)
-		dosesRemaining += expectedDoses
+		expectedToday += len(med.Times)
 	}
 
-	// Get today's logs
-	todayLogs, err := i.healthStore.GetTodayLogs(userID)
-	if err == nil {
-		for _, log := range todayLogs {
-			if log.Status == "taken" {
-				dosesToday++
-				dosesRemaining--
+	// Adherence looks back a week so early-morning dashboards aren't skewed
+	// by doses that simply aren't due yet
+	logs, err := i.healthStore.GetMedicationLogs(userID, "", today.AddDate(0, 0, -6), now)
+	if err != nil {
+		i.logger.Warn("Failed to fetch medication logs", zap.Error(err))
+	}
+	var taken, logged, loggedToday int
+	for _, log := range logs {
+		tookIt := log.Status == "taken" || log.Status == "late"
+		logged++
+		if tookIt {
+			taken++
+		}
+		if !log.ScheduledTime.Before(today) {
+			loggedToday++
+			if tookIt {
+				health.DosesToday++
 			}
 		}
 	}
 
-	health.DosesToday = dosesToday
-	health.DosesRemaining = dosesRemaining
+	health.DosesRemaining = expectedToday - loggedToday
+	if health.DosesRemaining < 0 {
+		health.DosesRemaining = 0
+	}
+	if logged > 0 {
+		health.MedicationAdherence = float64(taken) / float64(logged) * 100
+	}
 
-	// Calculate adherence (simplified)
-	if len(medications) > 0 && dosesRemaining > 0 {
-		expectedTotal := dosesToday + dosesRemaining
-		if expectedTotal > 0 {
-			health.MedicationAdherence = float64(dosesToday) / float64(expectedTotal) * 100
-		}
-	} else if dosesToday > 0 {
-		health.MedicationAdherence = 100.0
+	if metric, err := i.healthStore.GetLatestMetric(userID, "weight"); err == nil && metric != nil {
+		health.LatestWeight = metric.Value
+		health.WeightUnit = metric.Unit
+	}
+	if metric, err := i.healthStore.GetLatestMetric(userID, "steps"); err == nil && metric != nil && !metric.MeasuredAt.Before(today) {
+		health.StepsToday = int(metric.Value)
+	}
+	if metric, err := i.healthStore.GetLatestMetric(userID, "sleep"); err == nil && metric != nil && now.Sub(metric.MeasuredAt) < 24*time.Hour {
+		health.SleepLastNight = metric.Value
 	}
 
-	// Get health score from metrics
-	metric, err := i.healthStore.GetLatestMetric(userID, "weight")
-	if err == nil && metric != nil {
-		// Simple health score based on having metrics tracked
-		health.HealthScore = 75
+	if appts, err := i.healthStore.GetUpcomingAppointments(userID, 0); err == nil {
+		health.UpcomingAppointments = len(appts)
 	}
 
+	health.HealthScore = healthScore(health, logged > 0)
 	return health
 }
 
+// healthScore rates the day from 0 to 100 using adherence and, when
+// tracked, sleep and steps. It is 0 when there is nothing to rate.
+func healthScore(h DashboardHealth, hasAdherence bool) int {
+	var total float64
+	parts := 0
+	if hasAdherence {
+		total += h.MedicationAdherence
+		parts++
+	}
+	if h.SleepLastNight > 0 {
+		total += math.Min(h.SleepLastNight/8, 1) * 100
+		parts++
+	}
+	if h.StepsToday > 0 {
+		total += math.Min(float64(h.StepsToday)/8000, 1) * 100
+		parts++
+	}
+	if parts == 0 {
+		return 0
+	}
+	return int(math.Round(total / float64(parts)))
+}
+
 // fetchProductivityData gets real productivity data from tasks store
 func (i *IntelligenceSkill) fetchProductivityData(userID string) DashboardProductivity {
 	prod := DashboardProductivity{}
@@ -388,22 +443,36 @@ func (i *IntelligenceSkill) fetchProductivityData(userID string) DashboardProduc
 		return prod
 	}
 
-	// Get task stats
-	stats, err := i.tasksStore.GetStats(userID)
+	// Today's tasks are the ones due today, whether done or not
+	dayStart := startOfDay(time.Now())
+	dayEnd := dayStart.Add(24*time.Hour - time.Nanosecond)
+	list, err := i.tasksStore.ListTasks(userID, tasks.ListOptions{
+		Status:    []tasks.TaskStatus{tasks.TaskStatusPending, tasks.TaskStatusInProgress, tasks.TaskStatusSnoozed, tasks.TaskStatusCompleted},
+		DueAfter:  &dayStart,
+		DueBefore: &dayEnd,
+	})
 	if err != nil {
-		i.logger.Warn("Failed to fetch task stats", zap.Error(err))
+		i.logger.Warn("Failed to fetch today's tasks", zap.Error(err))
 		return prod
 	}
 
-	prod.TasksToday = stats.TotalCreated
-	prod.TasksCompleted = stats.Completed
+	prod.TasksToday = len(list.Tasks)
+	for _, task := range list.Tasks {
+		if task.Status == tasks.TaskStatusCompleted {
+			prod.TasksCompleted++
+		}
+	}
+	prod.OverdueTasks = list.Overdue
 
-	if stats.TotalCreated > 0 {
-		prod.CompletionRate = float64(stats.Completed) / float64(stats.TotalCreated) * 100
+	if prod.TasksToday > 0 {
+		prod.CompletionRate = float64(prod.TasksCompleted) / float64(prod.TasksToday) * 100
 	}
 
-	// Calculate focus score based on completion rate
-	prod.FocusScore = int(prod.CompletionRate)
+	// Focus is today's completion rate, less 10 points per overdue task
+	if prod.TasksToday > 0 || prod.OverdueTasks > 0 {
+		focus := prod.CompletionRate - float64(prod.OverdueTasks*10)
+		prod.FocusScore = int(math.Max(0, math.Round(focus)))
+	}
 
 	return prod
 }
@@ -418,7 +487,7 @@ func (i *IntelligenceSkill) fetchFinanceData(userID string) DashboardFinance {
 
 	// Get today's expenses
 	today := time.Now()
-	todayStart := today.Truncate(24 * time.Hour)
+	todayStart := startOfDay(today)
 	todayEnd := todayStart.Add(24 * time.Hour)
 
 	todayExpenses, err := i.expensesStore.GetExpensesByDateRange(userID, todayStart, todayEnd)
@@ -485,7 +554,7 @@ func (i *IntelligenceSkill) fetchShoppingData(userID string) DashboardShopping {
 	}
 
 	// Get all lists
-	lists, err := i.shoppingStore.ListLists(userID, false)
+	lists, err := i.shoppingStore.ListLists(userID, true)
 	if err != nil {
 		i.logger.Warn("Failed to fetch shopping lists", zap.Error(err))
 		return shopping
@@ -649,7 +718,7 @@ func (i *IntelligenceSkill) handleGetSuggestions(ctx context.Context, args map[s
 		return nil, err
 	}
 
-	var result []map[string]interface{}
+	result := []map[string]interface{}{}
 	for _, s := range suggestions {
 		result = append(result, map[string]interface{}{
 			"id":          s.ID,
@@ -957,6 +1026,10 @@ func (i *IntelligenceSkill) handleTrackEvent(ctx context.Context, args map[strin
 
 // TrackEvent is a helper method to track events from other skills
 func (i *IntelligenceSkill) TrackEvent(userID, eventType, category string, data map[string]interface{}) error {
+	return i.trackEventAt(userID, eventType, category, data, time.Now())
+}
+
+func (i *IntelligenceSkill) trackEventAt(userID, eventType, category string, data map[string]interface{}, at time.Time) error {
 	jsonData, _ := json.Marshal(data)
 
 	event := &BehaviorEvent{
@@ -964,8 +1037,40 @@ func (i *IntelligenceSkill) TrackEvent(userID, eventType, category string, data
 		EventType: eventType,
 		Category:  category,
 		Data:      string(jsonData),
-		Timestamp: time.Now(),
+		Timestamp: at,
 	}
 
 	return i.store.CreateBehaviorEvent(event)
 }
+
+// Subscribe records every event published on bus as a behavior event, so
+// pattern analysis and suggestions work from what the user actually did,
+// and drops the user's cached dashboard. It returns a function that stops recording.
+func (i *IntelligenceSkill) Subscribe(bus *events.Bus) func() {
+	return bus.Subscribe("*", func(ctx context.Context, e events.Event) {
+		// Something changed, so the cached dashboard is stale
+		i.dashboards.invalidate(e.UserID)
+		if err := i.trackEventAt(e.UserID, e.Type, eventCategory(e), e.Data, e.Time); err != nil {
+			i.logger.Warn("Failed to record event",
+				zap.String("event", e.Type),
+				zap.Error(err))
+		}
+	})
+}
+
+// eventCategory maps an event to the category patterns and suggestions are
+// grouped by
+func eventCategory(e events.Event) string {
+	switch e.Category() {
+	case "task":
+		return "tasks"
+	case "medication":
+		return "health"
+	case "shopping_item":
+		return "shopping"
+	case "file":
+		return "files"
+	default:
+		return e.Category()
+	}
+}
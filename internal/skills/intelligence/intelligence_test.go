@@ -5,6 +5,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/skills/health"
+	"github.com/gmsas95/myrai-cli/internal/skills/shopping"
+	"github.com/gmsas95/myrai-cli/internal/skills/tasks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -180,9 +184,28 @@ func TestSuggestionEngine_Generate(t *testing.T) {
 	store, _ := NewStore(db)
 	engine := NewSuggestionEngine(store)
 
+	for i := 0; i < 2; i++ {
+		require.NoError(t, store.CreateBehaviorEvent(&BehaviorEvent{
+			UserID:    "user_123",
+			EventType: events.MedicationMissed,
+			Category:  "health",
+			Data:      `{"medication":"Metformin"}`,
+		}))
+	}
+
 	suggestions, err := engine.GenerateSuggestions("user_123")
 	require.NoError(t, err)
-	assert.True(t, len(suggestions) > 0)
+	require.True(t, len(suggestions) > 0)
+
+	var missed *Suggestion
+	for _, s := range suggestions {
+		if s.Trigger == events.MedicationMissed {
+			missed = s
+		}
+	}
+	require.NotNil(t, missed)
+	assert.Equal(t, "You missed 2 doses this week", missed.Title)
+	assert.Contains(t, missed.Description, "Metformin")
 }
 
 // IntelligenceSkill Tests
@@ -202,6 +225,86 @@ func TestIntelligenceSkill_GetLifeDashboard(t *testing.T) {
 	assert.NotNil(t, dashboard.Productivity)
 }
 
+func TestIntelligenceSkill_LifeDashboardUsesStores(t *testing.T) {
+	db := setupTestDB(t)
+	healthStore, err := health.NewStore(db)
+	require.NoError(t, err)
+	tasksStore, err := tasks.NewStore(db)
+	require.NoError(t, err)
+	shoppingStore, err := shopping.NewStore(db)
+	require.NoError(t, err)
+
+	skill, err := NewIntelligenceSkill(db, zap.NewNop(), healthStore, tasksStore, shoppingStore)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), "user_id", "user_123")
+
+	today := startOfDay(time.Now())
+	med := &health.Medication{UserID: "user_123", Name: "Metformin", Times: []string{"08:00", "20:00"}, Enabled: true}
+	require.NoError(t, healthStore.CreateMedication(med))
+	require.NoError(t, healthStore.CreateMedicationLog(&health.MedicationLog{UserID: "user_123", MedicationID: med.ID, ScheduledTime: today, Status: "taken"}))
+	require.NoError(t, healthStore.CreateMedicationLog(&health.MedicationLog{UserID: "user_123", MedicationID: med.ID, ScheduledTime: today.Add(-12 * time.Hour), Status: "missed"}))
+
+	done, later, overdue := today.Add(time.Hour), today.Add(24*time.Hour-time.Minute), today.Add(-time.Hour)
+	require.NoError(t, tasksStore.CreateTask(&tasks.Task{UserID: "user_123", Title: "Done", DueDate: &done, Status: tasks.TaskStatusCompleted}))
+	require.NoError(t, tasksStore.CreateTask(&tasks.Task{UserID: "user_123", Title: "Later", DueDate: &later}))
+	require.NoError(t, tasksStore.CreateTask(&tasks.Task{UserID: "user_123", Title: "Late", DueDate: &overdue}))
+
+	list := &shopping.ShoppingList{UserID: "user_123", Name: "Groceries"}
+	require.NoError(t, shoppingStore.CreateList(list))
+	require.NoError(t, shoppingStore.CreateItem(&shopping.ShoppingItem{ListID: list.ID, UserID: "user_123", Name: "Milk"}))
+	require.NoError(t, shoppingStore.CreateItem(&shopping.ShoppingItem{ListID: list.ID, UserID: "user_123", Name: "Eggs", IsChecked: true}))
+
+	result, err := skill.handleGetLifeDashboard(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	dashboard := result.(*LifeDashboard)
+
+	assert.Equal(t, 1, dashboard.Health.DosesToday)
+	assert.Equal(t, 1, dashboard.Health.DosesRemaining)
+	assert.Equal(t, 50.0, dashboard.Health.MedicationAdherence)
+	assert.Equal(t, 50, dashboard.Health.HealthScore)
+
+	assert.Equal(t, 2, dashboard.Productivity.TasksToday)
+	assert.Equal(t, 1, dashboard.Productivity.TasksCompleted)
+	assert.Equal(t, 1, dashboard.Productivity.OverdueTasks)
+	assert.Equal(t, 40, dashboard.Productivity.FocusScore)
+
+	assert.Equal(t, 1, dashboard.Shopping.ActiveLists)
+	assert.Equal(t, 1, dashboard.Shopping.ItemsNeeded)
+	assert.Equal(t, 1, dashboard.Shopping.ItemsChecked)
+}
+
+func TestIntelligenceSkill_LifeDashboardCache(t *testing.T) {
+	db := setupTestDB(t)
+	shoppingStore, err := shopping.NewStore(db)
+	require.NoError(t, err)
+	skill, err := NewIntelligenceSkill(db, zap.NewNop(), shoppingStore)
+	require.NoError(t, err)
+	bus := events.NewBus(nil)
+	skill.Subscribe(bus)
+	ctx := context.WithValue(context.Background(), "user_id", "user_123")
+
+	activeLists := func(args map[string]interface{}) int {
+		result, err := skill.handleGetLifeDashboard(ctx, args)
+		require.NoError(t, err)
+		return result.(*LifeDashboard).Shopping.ActiveLists
+	}
+
+	assert.Equal(t, 0, activeLists(nil))
+	require.NoError(t, shoppingStore.CreateList(&shopping.ShoppingList{UserID: "user_123", Name: "Groceries"}))
+
+	// Served from cache until refreshed or an event arrives
+	assert.Equal(t, 0, activeLists(nil))
+	assert.Equal(t, 1, activeLists(map[string]interface{}{"refresh": true}))
+
+	require.NoError(t, shoppingStore.CreateList(&shopping.ShoppingList{UserID: "user_123", Name: "Hardware"}))
+	bus.Publish(ctx, events.Event{Type: events.ShoppingItemChecked, UserID: "user_123"})
+	assert.Equal(t, 2, activeLists(nil))
+
+	skill.dashboards.now = func() time.Time { return time.Now().Add(2 * DashboardCacheTTL) }
+	require.NoError(t, shoppingStore.CreateList(&shopping.ShoppingList{UserID: "user_123", Name: "Pharmacy"}))
+	assert.Equal(t, 3, activeLists(nil))
+}
+
 func TestIntelligenceSkill_WorkflowCRUD(t *testing.T) {
 	skill, _ := setupTestSkill(t)
 	ctx := context.WithValue(context.Background(), "user_id", "user_123")
@@ -252,6 +355,36 @@ func TestIntelligenceSkill_TrackEvent(t *testing.T) {
 	assert.True(t, result.(map[string]interface{})["success"].(bool))
 }
 
+func TestIntelligenceSkill_SubscribeRecordsEvents(t *testing.T) {
+	skill, _ := setupTestSkill(t)
+	bus := events.NewBus(nil)
+	stop := skill.Subscribe(bus)
+
+	bus.Publish(context.Background(), events.Event{
+		Type:   events.TaskCompleted,
+		UserID: "user_123",
+		Source: "tasks",
+		Data:   map[string]interface{}{"task_id": "task_1"},
+	})
+	bus.Publish(context.Background(), events.Event{Type: events.ShoppingItemChecked, UserID: "user_123"})
+
+	recorded, err := skill.store.GetBehaviorEvents("user_123", "", time.Now().Add(-time.Hour), time.Time{}, 10)
+	require.NoError(t, err)
+	require.Len(t, recorded, 2)
+
+	categories := map[string]string{}
+	for _, e := range recorded {
+		categories[e.EventType] = e.Category
+	}
+	assert.Equal(t, "tasks", categories[events.TaskCompleted])
+	assert.Equal(t, "shopping", categories[events.ShoppingItemChecked])
+
+	stop()
+	bus.Publish(context.Background(), events.Event{Type: events.TaskCompleted, UserID: "user_123"})
+	recorded, _ = skill.store.GetBehaviorEvents("user_123", "", time.Now().Add(-time.Hour), time.Time{}, 10)
+	assert.Len(t, recorded, 2)
+}
+
 func TestIntelligenceSkill_GetSuggestions(t *testing.T) {
 	skill, _ := setupTestSkill(t)
 	ctx := context.WithValue(context.Background(), "user_id", "user_123")
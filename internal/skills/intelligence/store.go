@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/store"
 	"gorm.io/gorm"
 )
 
@@ -13,15 +14,26 @@ type Store struct {
 	db *gorm.DB
 }
 
+func init() {
+	store.RegisterMigrations("intelligence", store.Migration{
+		Version: 1,
+		Name:    "create intelligence tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&UserPattern{}, &Suggestion{}, &AutomatedWorkflow{}, &WorkflowRun{}, &BehaviorEvent{}, &UserProfile{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&UserPattern{}, &Suggestion{}, &AutomatedWorkflow{}, &WorkflowRun{}, &BehaviorEvent{}, &UserProfile{})
+		},
+	})
+}
+
 // NewStore creates a new intelligence store
 func NewStore(db *gorm.DB) (*Store, error) {
-	store := &Store{db: db}
-
-	if err := db.AutoMigrate(&UserPattern{}, &Suggestion{}, &AutomatedWorkflow{}, &WorkflowRun{}, &BehaviorEvent{}, &UserProfile{}); err != nil {
+	if err := store.Migrate(db, "intelligence"); err != nil {
 		return nil, fmt.Errorf("failed to migrate intelligence schemas: %w", err)
 	}
 
-	return store, nil
+	return &Store{db: db}, nil
 }
 
 // Pattern operations
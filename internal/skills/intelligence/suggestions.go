@@ -3,7 +3,10 @@ package intelligence
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/events"
 )
 
 // SuggestionEngine generates proactive suggestions for users
@@ -56,31 +59,95 @@ func (e *SuggestionEngine) GenerateSuggestions(userID string) ([]*Suggestion, er
 	return suggestions, nil
 }
 
-// generateHealthSuggestions creates health-related suggestions
+// generateHealthSuggestions creates health-related suggestions from the
+// medication events the health skill publishes
 func (e *SuggestionEngine) generateHealthSuggestions(userID string) ([]*Suggestion, error) {
 	var suggestions []*Suggestion
+	now := time.Now()
 	
-	// Get recent medication logs
-	// Note: This would need access to health store, for now we'll simulate
+	// Missed doses over the last week
+	missed, err := e.store.GetBehaviorEvents(userID, events.MedicationMissed, now.AddDate(0, 0, -7), time.Time{}, 100)
+	if err != nil {
+		return nil, err
+	}
+	if len(missed) >= 2 {
+		names := medicationNames(missed)
+		suggestions = append(suggestions, &Suggestion{
+			UserID:      userID,
+			Type:        "insight",
+			Category:    "health",
+			Title:       fmt.Sprintf("You missed %d doses this week", len(missed)),
+			Description: fmt.Sprintf("Missed: %s. A reminder at your usual time could help.", strings.Join(names, ", ")),
+			Trigger:     events.MedicationMissed,
+			ActionType:  "add_reminder",
+			Priority:    "high",
+			SuggestedAt: now,
+			Status:      "pending",
+		})
+	}
 	
-	// Suggestion 1: Medication reminder
-	suggestion := &Suggestion{
-		UserID:      userID,
-		Type:        "proactive",
-		Category:    "health",
-		Title:       "Time for your evening medication",
-		Description: "You usually take your medication around this time",
-		ActionType:  "send_reminder",
-		Priority:    "high",
-		SuggestedAt: time.Now(),
-		ValidUntil:  &[]time.Time{time.Now().Add(30 * time.Minute)}[0],
-		Status:      "pending",
+	// Remind at the hour doses are usually taken, unless one has already
+	// been taken this hour
+	taken, err := e.store.GetBehaviorEvents(userID, events.MedicationTaken, now.AddDate(0, 0, -14), time.Time{}, 500)
+	if err != nil {
+		return nil, err
+	}
+	if len(taken) >= 3 {
+		hours := make(map[int]int)
+		takenThisHour := false
+		for _, event := range taken {
+			hours[event.HourOfDay]++
+			if event.Timestamp.YearDay() == now.YearDay() && event.Timestamp.Year() == now.Year() && event.HourOfDay == now.Hour() {
+				takenThisHour = true
+			}
+		}
+		usualHour, count := 0, 0
+		for hour, c := range hours {
+			if c > count || (c == count && hour < usualHour) {
+				usualHour, count = hour, c
+			}
+		}
+		if usualHour == now.Hour() && count >= 3 && !takenThisHour {
+			validUntil := now.Add(30 * time.Minute)
+			suggestions = append(suggestions, &Suggestion{
+				UserID:      userID,
+				Type:        "proactive",
+				Category:    "health",
+				Title:       "Time for your medication",
+				Description: fmt.Sprintf("You usually take %s around this time", strings.Join(medicationNames(taken), ", ")),
+				Trigger:     events.MedicationTaken,
+				ActionType:  "send_reminder",
+				Priority:    "high",
+				SuggestedAt: now,
+				ValidUntil:  &validUntil,
+				Status:      "pending",
+			})
+		}
 	}
-	suggestions = append(suggestions, suggestion)
 	
 	return suggestions, nil
 }
 
+// medicationNames lists the distinct medications named in events
+func medicationNames(behavior []BehaviorEvent) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, event := range behavior {
+		var data map[string]interface{}
+		if json.Unmarshal([]byte(event.Data), &data) != nil {
+			continue
+		}
+		if name, ok := data["medication"].(string); ok && name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		names = append(names, "your medication")
+	}
+	return names
+}
+
 // generateProductivitySuggestions creates productivity suggestions
 func (e *SuggestionEngine) generateProductivitySuggestions(userID string) ([]*Suggestion, error) {
 	var suggestions []*Suggestion
@@ -0,0 +1,159 @@
+package kb
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gmsas95/myrai-cli/internal/skills/documents"
+)
+
+// chunkSize is roughly how many characters each chunk holds, and
+// chunkOverlap how many of them repeat the end of the previous chunk so a
+// passage split across two is still found whole in one
+const (
+	chunkSize    = 1200
+	chunkOverlap = 200
+)
+
+var (
+	scriptPattern = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>`)
+	tagPattern    = regexp.MustCompile(`<[^>]+>`)
+	blankLines    = regexp.MustCompile(`\n{3,}`)
+)
+
+// textExtensions are read as they are
+var textExtensions = map[string]bool{
+	".txt": true, ".md": true, ".markdown": true, ".csv": true, ".tsv": true,
+	".json": true, ".yaml": true, ".yml": true, ".xml": true, ".log": true,
+	".rst": true, ".org": true,
+}
+
+// imageExtensions are read with OCR
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".tif": true, ".tiff": true,
+	".bmp": true, ".webp": true, ".gif": true,
+}
+
+// extractText reads the text of the file at path. filename tells the file
+// type, as uploads are often saved under a generic name.
+func extractText(path, filename string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	switch {
+	case ext == ".pdf":
+		return documents.NewPDFProcessor().ExtractText(path, documents.ProcessOptions{})
+	case ext == ".xlsx" || ext == ".xlsm":
+		result, err := documents.ExtractSpreadsheet(path, documents.SpreadsheetOptions{})
+		if err != nil {
+			return "", err
+		}
+		return result.Markdown(), nil
+	case ext == ".docx":
+		result, err := documents.ExtractDOCX(path)
+		if err != nil {
+			return "", err
+		}
+		return result.Markdown, nil
+	case imageExtensions[ext]:
+		return documents.NewTesseractOCR("").ExtractText(path)
+	case ext == ".html" || ext == ".htm":
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return stripHTML(string(content)), nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if textExtensions[ext] || (strings.HasPrefix(http.DetectContentType(content), "text/") && utf8.Valid(content)) {
+		return string(content), nil
+	}
+	return "", fmt.Errorf("unsupported file type: %s", filename)
+}
+
+func stripHTML(s string) string {
+	s = scriptPattern.ReplaceAllString(s, "")
+	s = tagPattern.ReplaceAllString(s, "\n")
+	return html.UnescapeString(s)
+}
+
+// normalizeText trims trailing spaces and collapses runs of blank lines
+func normalizeText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, func(r rune) bool { return r == ' ' || r == '\t' || r == '\f' })
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// chunkText splits text into chunks of about chunkSize characters, breaking
+// between paragraphs where possible and between words otherwise
+func chunkText(text string) []string {
+	var words []string
+	for _, para := range strings.Split(text, "\n\n") {
+		fields := strings.Fields(para)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(words) > 0 {
+			words = append(words, "\n\n")
+		}
+		words = append(words, fields...)
+	}
+
+	var chunks []string
+	for start := 0; start < len(words); {
+		size, end, paraEnd := 0, start, -1
+		for end < len(words) && (size < chunkSize || end == start) {
+			if words[end] == "\n\n" {
+				paraEnd = end
+			}
+			size += len(words[end]) + 1
+			end++
+		}
+		// Prefer ending at a paragraph break in the chunk's second half
+		if end < len(words) && paraEnd > start && paraEnd-start > (end-start)/2 {
+			end = paraEnd
+		}
+		chunks = append(chunks, joinWords(words[start:end]))
+		if end >= len(words) {
+			break
+		}
+
+		// Step back over the overlap, but always move forward
+		next, overlap := end, 0
+		for next > start+1 && overlap < chunkOverlap {
+			next--
+			overlap += len(words[next]) + 1
+		}
+		for next < end && words[next] == "\n\n" {
+			next++
+		}
+		start = next
+	}
+	return chunks
+}
+
+func joinWords(words []string) string {
+	var b strings.Builder
+	for i, w := range words {
+		if w == "\n\n" {
+			b.WriteString("\n\n")
+			continue
+		}
+		if i > 0 && words[i-1] != "\n\n" {
+			b.WriteByte(' ')
+		}
+		b.WriteString(w)
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,249 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/skills"
+)
+
+// KnowledgeBaseSkill answers questions from the user's documents
+type KnowledgeBaseSkill struct {
+	*skills.BaseSkill
+	store *Store
+}
+
+// NewKnowledgeBaseSkill creates the skill over store
+func NewKnowledgeBaseSkill(store *Store) *KnowledgeBaseSkill {
+	skill := &KnowledgeBaseSkill{
+		BaseSkill: skills.NewBaseSkill("kb", "Knowledge base of the user's documents", "1.0.0"),
+		store:     store,
+	}
+	skill.registerTools()
+	return skill
+}
+
+// Store returns the knowledge base, for channels that ingest uploads
+func (k *KnowledgeBaseSkill) Store() *Store {
+	return k.store
+}
+
+func (k *KnowledgeBaseSkill) registerTools() {
+	tools := []skills.Tool{
+		{
+			Name:        "query_documents",
+			ReadOnly:    true,
+			Description: "Search the user's documents (files they uploaded or added to the knowledge base) for passages that answer a question. Use this before saying you don't know something the user may have sent you, e.g. 'what's the excess on my car insurance?'. Cite the document title when answering, and the source_url as a link for passages from web pages.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"query": map[string]interface{}{
+						"type":        "string",
+						"description": "The question or topic to look up",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum passages to return (default 5)",
+					},
+				},
+				"required": []string{"query"},
+			},
+		},
+		{
+			Name:        "list_documents",
+			ReadOnly:    true,
+			Description: "List the documents in the user's knowledge base",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "add_document",
+			Description: "Add a local file (PDF, text, Markdown, HTML or an image to OCR) to the user's knowledge base so it can be searched later",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Path of the file to add",
+					},
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "Title for the document (defaults to the file name)",
+					},
+				},
+				"required": []string{"path"},
+			},
+		},
+		{
+			Name:        "ingest_url",
+			Description: "Save a web page (or a linked PDF) to the user's knowledge base as clean Markdown so it can be searched later, e.g. 'save this article' or 'add https://example.com/guide to my documents'. Saving a page again refreshes it.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"url": map[string]interface{}{
+						"type":        "string",
+						"description": "Address of the page",
+					},
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "Title for the document (defaults to the page's title)",
+					},
+				},
+				"required": []string{"url"},
+			},
+		},
+		{
+			Name:        "remove_document",
+			Description: "Remove a document from the user's knowledge base",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"document_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the document",
+					},
+				},
+				"required": []string{"document_id"},
+			},
+		},
+	}
+
+	for _, tool := range tools {
+		tool.Handler = k.handleTool(tool.Name)
+		k.AddTool(tool)
+	}
+}
+
+func (k *KnowledgeBaseSkill) handleTool(name string) skills.ToolHandler {
+	return func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		switch name {
+		case "query_documents":
+			return k.handleQuery(ctx, args)
+		case "list_documents":
+			return k.handleList(ctx, args)
+		case "add_document":
+			return k.handleAdd(ctx, args)
+		case "ingest_url":
+			return k.handleIngestURL(ctx, args)
+		case "remove_document":
+			return k.handleRemove(ctx, args)
+		default:
+			return nil, fmt.Errorf("unknown tool: %s", name)
+		}
+	}
+}
+
+func (k *KnowledgeBaseSkill) handleQuery(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	query, _ := args["query"].(string)
+	limit := 5
+	if v, ok := args["limit"].(float64); ok && v > 0 {
+		limit = int(v)
+	}
+
+	matches, err := k.store.Search(getUserID(ctx), query, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{
+		"query":   query,
+		"matches": matches,
+		"count":   len(matches),
+	}
+	if len(matches) == 0 {
+		result["message"] = "Nothing in the user's documents matches"
+	}
+	return result, nil
+}
+
+func (k *KnowledgeBaseSkill) handleList(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	docs, err := k.store.List(getUserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"documents": docs,
+		"count":     len(docs),
+	}, nil
+}
+
+func (k *KnowledgeBaseSkill) handleAdd(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	path, _ := args["path"].(string)
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	title, _ := args["title"].(string)
+
+	doc, err := k.store.Add(AddOptions{
+		Path:   path,
+		Title:  title,
+		UserID: getUserID(ctx),
+		Source: "chat",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"success":  true,
+		"document": doc,
+		"message":  fmt.Sprintf("Added %s to the knowledge base (%d passages)", doc.Title, doc.Chunks),
+	}, nil
+}
+
+// Archive adds a file other skills produced, such as the text of a scan,
+// to the knowledge base of the user the tool runs for
+func (k *KnowledgeBaseSkill) Archive(ctx context.Context, path, filename string) (string, error) {
+	doc, err := k.store.Add(AddOptions{
+		Path:     path,
+		Filename: filename,
+		UserID:   getUserID(ctx),
+		Source:   "ocr",
+	})
+	if err != nil {
+		return "", err
+	}
+	return doc.ID, nil
+}
+
+func (k *KnowledgeBaseSkill) handleIngestURL(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	url, _ := args["url"].(string)
+	title, _ := args["title"].(string)
+
+	doc, err := k.store.AddURL(ctx, url, AddOptions{
+		Title:  title,
+		UserID: getUserID(ctx),
+		Source: "web",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"success":    true,
+		"document":   doc,
+		"source_url": doc.SourceURL,
+		"message":    fmt.Sprintf("Saved %s to the knowledge base (%d passages)", doc.Title, doc.Chunks),
+	}, nil
+}
+
+func (k *KnowledgeBaseSkill) handleRemove(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	id, _ := args["document_id"].(string)
+	if id == "" {
+		return nil, fmt.Errorf("document_id is required")
+	}
+	if err := k.store.Remove(getUserID(ctx), id); err != nil {
+		return nil, fmt.Errorf("document not found: %s", id)
+	}
+	return map[string]interface{}{
+		"success": true,
+		"message": "Document removed",
+	}, nil
+}
+
+func getUserID(ctx context.Context) string {
+	if userID, ok := ctx.Value("user_id").(string); ok {
+		return userID
+	}
+	return "default_user"
+}
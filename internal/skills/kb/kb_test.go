@@ -0,0 +1,274 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/vector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// wordEmbedder embeds text by which of a few words it contains
+type wordEmbedder struct{}
+
+func (wordEmbedder) GenerateEmbedding(text string) ([]float32, error) {
+	text = strings.ToLower(text)
+	var v []float32
+	for _, w := range []string{"insurance", "excess", "boiler", "warranty"} {
+		if strings.Contains(text, w) {
+			v = append(v, 1)
+		} else {
+			v = append(v, 0)
+		}
+	}
+	return v, nil
+}
+
+func newTestStore(t *testing.T) *Store {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	store, err := NewStore(db, filepath.Join(t.TempDir(), "documents"), nil)
+	require.NoError(t, err)
+	return store
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestChunkText(t *testing.T) {
+	assert.Equal(t, []string{"Short note."}, chunkText("Short note."))
+
+	para := strings.Repeat("word ", 150)
+	text := strings.TrimSpace(para) + "\n\n" + strings.TrimSpace(para) + "\n\n" + strings.TrimSpace(para)
+	chunks := chunkText(text)
+	require.Greater(t, len(chunks), 1)
+	for _, c := range chunks {
+		assert.LessOrEqual(t, len(c), chunkSize+chunkOverlap)
+	}
+	// Chunks overlap so nothing is lost at the seams
+	assert.True(t, strings.HasSuffix(chunks[0], "word"))
+	assert.True(t, strings.HasPrefix(chunks[1], "word"))
+}
+
+func TestStore_AddAndSearch(t *testing.T) {
+	store := newTestStore(t)
+
+	src := writeFile(t, "upload.pdf", "ignored")
+	store.extract = func(path, filename string) (string, error) {
+		assert.Equal(t, "Car Insurance.pdf", filename)
+		return "Policy schedule\n\nThe compulsory excess is £250.\n\n\n\nRenewal date: 1 March", nil
+	}
+	doc, err := store.Add(AddOptions{Path: src, Filename: "Car Insurance.pdf", UserID: "alice", Source: "telegram"})
+	require.NoError(t, err)
+	assert.Equal(t, "Car Insurance", doc.Title)
+	assert.Equal(t, 1, doc.Chunks)
+
+	// The file is kept once the upload is cleaned up
+	require.NoError(t, os.Remove(src))
+	assert.FileExists(t, doc.StoragePath)
+	assert.True(t, strings.HasPrefix(doc.StoragePath, store.dir))
+
+	matches, err := store.Search("alice", "what is the excess?", 5)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "Car Insurance", matches[0].Title)
+	assert.Contains(t, matches[0].Content, "£250")
+
+	matches, err = store.Search("bob", "excess", 5)
+	require.NoError(t, err)
+	assert.Empty(t, matches, "documents are private to their user")
+
+	require.NoError(t, store.Remove("alice", doc.ID))
+	assert.NoDirExists(t, filepath.Dir(doc.StoragePath))
+	docs, err := store.List("alice")
+	require.NoError(t, err)
+	assert.Empty(t, docs)
+}
+
+func TestStore_SemanticSearch(t *testing.T) {
+	store := newTestStore(t)
+	store.SetEmbedder(wordEmbedder{})
+
+	_, err := store.Add(AddOptions{Path: writeFile(t, "policy.txt", "Insurance policy. Excess: £250."), UserID: "alice"})
+	require.NoError(t, err)
+	_, err = store.Add(AddOptions{Path: writeFile(t, "boiler.md", "# Boiler\n\nWarranty runs until 2027."), UserID: "alice"})
+	require.NoError(t, err)
+
+	matches, err := store.Search("alice", "how much is the insurance excess", 5)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "policy", matches[0].Title)
+
+	// A vector backend finds the same passages, still per user
+	store.SetVectorStore(vector.NewLocalStore(store.db))
+	matches, err = store.Search("alice", "how much is the insurance excess", 5)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "policy", matches[0].Title)
+	matches, err = store.Search("bob", "insurance excess", 5)
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	// Every passage is embedded again when the model changes
+	count, err := store.Reindex()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	_, err = newTestStore(t).Reindex()
+	assert.Error(t, err, "nothing to reindex with without an embedder")
+}
+
+func TestStore_AddRejectsUnreadableFiles(t *testing.T) {
+	store := newTestStore(t)
+
+	_, err := store.Add(AddOptions{Path: writeFile(t, "blob.bin", "\x00\x01\x02\x03"), UserID: "alice"})
+	assert.Error(t, err)
+	_, err = store.Add(AddOptions{Path: writeFile(t, "empty.txt", "  \n\n "), UserID: "alice"})
+	assert.Error(t, err)
+
+	entries, err := os.ReadDir(store.dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "nothing is stored for files that can't be read")
+}
+
+func TestKnowledgeBaseSkill_Tools(t *testing.T) {
+	skill := NewKnowledgeBaseSkill(newTestStore(t))
+	ctx := context.WithValue(context.Background(), "user_id", "alice")
+
+	added, err := skill.handleAdd(ctx, map[string]interface{}{
+		"path":  writeFile(t, "page.html", "<html><style>p{}</style><p>Boiler service is due in &quot;May&quot;</p></html>"),
+		"title": "Boiler",
+	})
+	require.NoError(t, err)
+	doc := added.(map[string]interface{})["document"].(*Document)
+	assert.Equal(t, "chat", doc.Source)
+
+	result, err := skill.handleQuery(ctx, map[string]interface{}{"query": "boiler service"})
+	require.NoError(t, err)
+	matches := result.(map[string]interface{})["matches"].([]Match)
+	require.Len(t, matches, 1)
+	assert.Equal(t, `Boiler service is due in "May"`, matches[0].Content)
+
+	_, err = skill.handleRemove(context.Background(), map[string]interface{}{"document_id": doc.ID})
+	assert.Error(t, err, "other users can't remove it")
+	_, err = skill.handleRemove(ctx, map[string]interface{}{"document_id": doc.ID})
+	require.NoError(t, err)
+}
+
+func TestKnowledgeBaseSkill_Archive(t *testing.T) {
+	store := newTestStore(t)
+	skill := NewKnowledgeBaseSkill(store)
+	ctx := context.WithValue(context.Background(), "user_id", "alice")
+
+	id, err := skill.Archive(ctx, writeFile(t, "myrai-ocr-123.txt", "Gas meter reading 04512"), "meter.txt")
+	require.NoError(t, err)
+
+	docs, err := store.List("alice")
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, id, docs[0].ID)
+	assert.Equal(t, "meter", docs[0].Title)
+	assert.Equal(t, "ocr", docs[0].Source)
+}
+
+// pageRenderer stands in for the browser, returning the page scripts build
+type pageRenderer struct{ calls int }
+
+func (r *pageRenderer) RenderHTML(ctx context.Context, url string) (string, error) {
+	r.calls++
+	return `<html><body><div id="app"><p>` + strings.Repeat("Rendered by scripts, finally. ", 10) + `</p></div></body></html>`, nil
+}
+
+func TestStore_AddURL(t *testing.T) {
+	version := "first"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/guide", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, `<html><head><title>Sourdough Guide</title><script>var x = 1;</script></head><body>
+<nav><a href="/">Home</a> <a href="/shop">Shop</a></nav>
+<div class="sidebar"><p>Subscribe to our newsletter for weekly recipes and more!</p></div>
+<article>
+  <h1>Sourdough Guide</h1>
+  <p>Feed the starter twice a day, at a <strong>1:1:1</strong> ratio of starter, flour and water (%s edition).</p>
+  <h2>Baking</h2>
+  <p>Bake at 250°C in a covered pot for 20 minutes, then uncovered for 25. See <a href="/tips#crust">crust tips</a>.</p>
+  <ul><li>Flour</li><li>Water<ul><li>Filtered</li></ul></li></ul>
+</article>
+<footer><p>Copyright 2025, all rights reserved by the bakery.</p></footer>
+</body></html>`, version)
+	})
+	mux.HandleFunc("/app", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>App</title></head><body><div id="app"></div></body></html>`)
+	})
+	mux.HandleFunc("/files/notes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "Plain text notes about proofing times.")
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	doc, err := store.AddURL(ctx, srv.URL+"/guide#top", AddOptions{UserID: "alice", Source: "web"})
+	require.NoError(t, err)
+	assert.Equal(t, "Sourdough Guide", doc.Title)
+	assert.Equal(t, srv.URL+"/guide", doc.SourceURL)
+
+	raw, err := os.ReadFile(doc.StoragePath)
+	require.NoError(t, err)
+	md := string(raw)
+	assert.True(t, strings.HasPrefix(md, "# Sourdough Guide\n\nSource: "+srv.URL+"/guide\n\nFeed the starter"), md)
+	assert.Contains(t, md, "**1:1:1**")
+	assert.Contains(t, md, "## Baking")
+	assert.Contains(t, md, "[crust tips]("+srv.URL+"/tips#crust)")
+	assert.Contains(t, md, "- Water\n  - Filtered")
+	for _, furniture := range []string{"Home", "newsletter", "Copyright", "var x"} {
+		assert.NotContains(t, md, furniture)
+	}
+
+	matches, err := store.Search("alice", "starter ratio", 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, matches)
+	assert.Equal(t, srv.URL+"/guide", matches[0].SourceURL, "answers can cite the page")
+
+	// Saving the page again refreshes it instead of keeping two copies
+	version = "second"
+	again, err := store.AddURL(ctx, srv.URL+"/guide", AddOptions{UserID: "alice"})
+	require.NoError(t, err)
+	docs, err := store.List("alice")
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, again.ID, docs[0].ID)
+	assert.NoFileExists(t, doc.StoragePath)
+
+	// Linked files are read like uploads
+	file, err := store.AddURL(ctx, srv.URL+"/files/notes", AddOptions{UserID: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "notes.txt", file.Filename)
+
+	// Pages built by scripts are loaded in the browser, when there is one
+	_, err = store.AddURL(ctx, srv.URL+"/app", AddOptions{UserID: "alice"})
+	assert.Error(t, err)
+	renderer := &pageRenderer{}
+	store.SetRenderer(renderer)
+	rendered, err := store.AddURL(ctx, srv.URL+"/app", AddOptions{UserID: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, renderer.calls)
+	assert.Equal(t, "App", rendered.Title)
+
+	_, err = store.AddURL(ctx, "file:///etc/passwd", AddOptions{UserID: "alice"})
+	assert.Error(t, err)
+}
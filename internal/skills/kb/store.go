@@ -0,0 +1,527 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/vector"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Collection is the knowledge base's vector index
+const Collection = "kb"
+
+// minSimilarity is how similar a passage must be to a query to be returned
+// by semantic search
+const minSimilarity = 0.3
+
+// unsafeFilename matches characters not kept in stored file names
+var unsafeFilename = regexp.MustCompile(`[^\p{L}\p{N}._ -]+`)
+
+// Embedder turns text into a vector. *vector.Searcher implements it.
+type Embedder interface {
+	GenerateEmbedding(text string) ([]float32, error)
+}
+
+func init() {
+	vector.RegisterLocalCollection(Collection, "kb_chunks", "user_id", "document_id")
+}
+
+// Store keeps documents under dir and their chunks in the database
+type Store struct {
+	db       *gorm.DB
+	dir      string
+	embedder Embedder
+	vectors  vector.VectorStore
+	renderer Renderer
+	client   *http.Client
+	logger   *zap.Logger
+
+	// extract reads a file's text; replaced in tests
+	extract func(path, filename string) (string, error)
+}
+
+// DefaultDir is where documents are kept under the data directory
+func DefaultDir(dataDir string) string {
+	return filepath.Join(dataDir, "documents")
+}
+
+func init() {
+	store.RegisterMigrations("kb", store.Migration{
+		Version: 1,
+		Name:    "create kb tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Document{}, &Chunk{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Document{}, &Chunk{})
+		},
+	})
+}
+
+// NewStore creates the knowledge base, keeping files under dir
+func NewStore(db *gorm.DB, dir string, logger *zap.Logger) (*Store, error) {
+	if err := store.Migrate(db, "kb"); err != nil {
+		return nil, fmt.Errorf("failed to migrate knowledge base: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create documents directory: %w", err)
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Store{
+		db:      db,
+		dir:     dir,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		logger:  logger,
+		extract: extractText,
+	}, nil
+}
+
+// SetEmbedder embeds chunks and queries with embedder. Without one, search
+// matches query words.
+func (s *Store) SetEmbedder(embedder Embedder) {
+	s.embedder = embedder
+}
+
+// SetVectorStore searches embedded passages in vs instead of comparing the
+// query with each of them
+func (s *Store) SetVectorStore(vs vector.VectorStore) {
+	s.vectors = vs
+}
+
+// Semantic reports whether documents are searched by meaning
+func (s *Store) Semantic() bool {
+	return s.embedder != nil
+}
+
+// Add copies a file into the knowledge base, then chunks and indexes its text
+func (s *Store) Add(opts AddOptions) (*Document, error) {
+	if opts.Filename == "" {
+		opts.Filename = filepath.Base(opts.Path)
+	}
+	info, err := os.Stat(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", opts.Path)
+	}
+
+	text, err := s.extract(opts.Path, opts.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", opts.Filename, err)
+	}
+	text = normalizeText(text)
+	if text == "" {
+		return nil, fmt.Errorf("no text found in %s", opts.Filename)
+	}
+
+	doc := newDocument(opts, info.Size(), text)
+	if err := s.save(doc, text, func(dst string) error { return copyFile(opts.Path, dst) }); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func newDocument(opts AddOptions, size int64, text string) *Document {
+	doc := &Document{
+		ID:         idgen.Generate(idgen.PrefixDocument),
+		UserID:     opts.UserID,
+		Title:      opts.Title,
+		Filename:   opts.Filename,
+		MimeType:   opts.MimeType,
+		SizeBytes:  size,
+		Source:     opts.Source,
+		SourceURL:  opts.SourceURL,
+		Characters: len(text),
+	}
+	if doc.Title == "" {
+		doc.Title = strings.TrimSuffix(opts.Filename, filepath.Ext(opts.Filename))
+	}
+	return doc
+}
+
+// save chunks and indexes the document's text, keeps its file with write
+// and stores both. A document from a URL replaces earlier copies of it.
+func (s *Store) save(doc *Document, text string, write func(dst string) error) error {
+	var chunks []Chunk
+	for i, content := range chunkText(text) {
+		chunk := Chunk{DocumentID: doc.ID, UserID: doc.UserID, Position: i, Content: content}
+		if s.embedder != nil {
+			embedding, err := s.embedder.GenerateEmbedding(doc.Title + "\n\n" + content)
+			if err != nil {
+				return fmt.Errorf("failed to embed %s: %w", doc.Filename, err)
+			}
+			chunk.Embedding = vector.EncodeEmbedding(embedding)
+		}
+		chunks = append(chunks, chunk)
+	}
+	doc.Chunks = len(chunks)
+
+	name := strings.TrimSpace(unsafeFilename.ReplaceAllString(filepath.Base(doc.Filename), "_"))
+	if name == "" || name == "." || name == ".." {
+		name = "document"
+	}
+	folder := filepath.Join(s.dir, doc.ID)
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return fmt.Errorf("failed to create document folder: %w", err)
+	}
+	doc.StoragePath = filepath.Join(folder, name)
+	if err := write(doc.StoragePath); err != nil {
+		os.RemoveAll(folder)
+		return fmt.Errorf("failed to store document: %w", err)
+	}
+
+	var replaced []Document
+	var replacedChunks []uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if doc.SourceURL != "" {
+			if err := tx.Where("user_id = ? AND source_url = ?", doc.UserID, doc.SourceURL).Find(&replaced).Error; err != nil {
+				return err
+			}
+			for _, old := range replaced {
+				var ids []uint
+				if err := tx.Model(&Chunk{}).Where("document_id = ?", old.ID).Pluck("id", &ids).Error; err != nil {
+					return err
+				}
+				replacedChunks = append(replacedChunks, ids...)
+				if err := tx.Where("document_id = ?", old.ID).Delete(&Chunk{}).Error; err != nil {
+					return err
+				}
+				if err := tx.Delete(&old).Error; err != nil {
+					return err
+				}
+			}
+		}
+		if err := tx.Create(doc).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(chunks, 100).Error
+	})
+	if err != nil {
+		os.RemoveAll(folder)
+		return fmt.Errorf("failed to save document: %w", err)
+	}
+	for _, old := range replaced {
+		os.RemoveAll(filepath.Dir(old.StoragePath))
+	}
+	s.dropPoints(replacedChunks)
+	s.storePoints(chunks)
+
+	s.logger.Info("Document added to knowledge base",
+		zap.String("document_id", doc.ID),
+		zap.String("filename", doc.Filename),
+		zap.Int("chunks", doc.Chunks))
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// List returns the user's documents, newest first
+func (s *Store) List(userID string) ([]Document, error) {
+	var docs []Document
+	err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&docs).Error
+	return docs, err
+}
+
+// Get returns one of the user's documents
+func (s *Store) Get(userID, id string) (*Document, error) {
+	var doc Document
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&doc).Error; err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// Remove deletes a document, its chunks and its stored file
+func (s *Store) Remove(userID, id string) error {
+	doc, err := s.Get(userID, id)
+	if err != nil {
+		return err
+	}
+	var chunkIDs []uint
+	if err := s.db.Model(&Chunk{}).Where("document_id = ?", doc.ID).Pluck("id", &chunkIDs).Error; err != nil {
+		return err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("document_id = ?", doc.ID).Delete(&Chunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(doc).Error
+	})
+	if err != nil {
+		return err
+	}
+	if doc.StoragePath != "" {
+		os.RemoveAll(filepath.Dir(doc.StoragePath))
+	}
+	s.dropPoints(chunkIDs)
+	return nil
+}
+
+// storePoints copies the chunks' embeddings to an external vector backend
+func (s *Store) storePoints(chunks []Chunk) {
+	if !vector.External(s.vectors) {
+		return
+	}
+	var points []vector.Point
+	for _, chunk := range chunks {
+		if len(chunk.Embedding) == 0 {
+			continue
+		}
+		points = append(points, vector.Point{
+			ID:     strconv.FormatUint(uint64(chunk.ID), 10),
+			Vector: vector.DecodeEmbedding(chunk.Embedding),
+			Payload: map[string]string{
+				"user_id":     chunk.UserID,
+				"document_id": chunk.DocumentID,
+			},
+		})
+	}
+	if err := s.vectors.Upsert(context.Background(), Collection, points); err != nil {
+		s.logger.Warn("Failed to store passages in vector backend", zap.String("backend", s.vectors.Name()), zap.Error(err))
+	}
+}
+
+// dropPoints removes deleted chunks from an external vector backend
+func (s *Store) dropPoints(chunkIDs []uint) {
+	if !vector.External(s.vectors) || len(chunkIDs) == 0 {
+		return
+	}
+	ids := make([]string, 0, len(chunkIDs))
+	for _, id := range chunkIDs {
+		ids = append(ids, strconv.FormatUint(uint64(id), 10))
+	}
+	if err := s.vectors.Delete(context.Background(), Collection, ids); err != nil {
+		s.logger.Warn("Failed to remove passages from vector backend", zap.String("backend", s.vectors.Name()), zap.Error(err))
+	}
+}
+
+// Reindex embeds every passage of every user's documents again, after the
+// embedding model changes, and returns how many it embedded
+func (s *Store) Reindex() (int, error) {
+	if s.embedder == nil {
+		return 0, fmt.Errorf("semantic search is not enabled")
+	}
+	var docs []Document
+	if err := s.db.Find(&docs).Error; err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, doc := range docs {
+		var chunks []Chunk
+		if err := s.db.Where("document_id = ?", doc.ID).Find(&chunks).Error; err != nil {
+			return count, err
+		}
+		for i, chunk := range chunks {
+			embedding, err := s.embedder.GenerateEmbedding(doc.Title + "\n\n" + chunk.Content)
+			if err != nil {
+				return count, fmt.Errorf("failed to embed %s: %w", doc.Filename, err)
+			}
+			chunks[i].Embedding = vector.EncodeEmbedding(embedding)
+			if err := s.db.Model(&Chunk{}).Where("id = ?", chunk.ID).
+				Update("embedding", chunks[i].Embedding).Error; err != nil {
+				return count, err
+			}
+			count++
+		}
+		s.storePoints(chunks)
+	}
+	return count, nil
+}
+
+// Search returns up to limit passages from the user's documents that best
+// answer query. Chunks are compared by meaning when both they and the query
+// are embedded, and by the query's words otherwise.
+func (s *Store) Search(userID, query string, limit int) ([]Match, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	if limit <= 0 {
+		limit = 5
+	}
+
+	var queryEmbedding []float32
+	if s.embedder != nil {
+		embedding, err := s.embedder.GenerateEmbedding(query)
+		if err != nil {
+			s.logger.Warn("Failed to embed query, matching words instead", zap.Error(err))
+		} else {
+			queryEmbedding = embedding
+		}
+	}
+	terms := queryTerms(query)
+
+	var matches []Match
+	var err error
+	if s.vectors != nil && queryEmbedding != nil {
+		matches, err = s.vectorMatches(userID, queryEmbedding, terms, limit)
+	} else {
+		matches, err = s.scanMatches(userID, queryEmbedding, terms)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(matches, func(a, b int) bool { return matches[a].Score > matches[b].Score })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	titles := make(map[string]Document)
+	for i := range matches {
+		doc, ok := titles[matches[i].DocumentID]
+		if !ok {
+			s.db.Where("id = ?", matches[i].DocumentID).First(&doc)
+			titles[matches[i].DocumentID] = doc
+		}
+		matches[i].Title = doc.Title
+		matches[i].Filename = doc.Filename
+		matches[i].SourceURL = doc.SourceURL
+	}
+	return matches, nil
+}
+
+// scanMatches scores each of the user's passages against the query
+func (s *Store) scanMatches(userID string, queryEmbedding []float32, terms []string) ([]Match, error) {
+	var chunks []Chunk
+	if err := s.db.Where("user_id = ?", userID).Find(&chunks).Error; err != nil {
+		return nil, err
+	}
+
+	var matches []Match
+	for _, chunk := range chunks {
+		var score float64
+		if queryEmbedding != nil && len(chunk.Embedding) > 0 {
+			if score = vector.CosineSimilarity(queryEmbedding, vector.DecodeEmbedding(chunk.Embedding)); score < minSimilarity {
+				continue
+			}
+		} else if score = termScore(terms, chunk.Content); score == 0 {
+			continue
+		}
+		matches = append(matches, Match{
+			DocumentID: chunk.DocumentID,
+			Position:   chunk.Position,
+			Content:    chunk.Content,
+			Score:      score,
+		})
+	}
+	return matches, nil
+}
+
+// vectorMatches finds embedded passages through the vector backend, and by
+// their words passages added while semantic search was off
+func (s *Store) vectorMatches(userID string, queryEmbedding []float32, terms []string, limit int) ([]Match, error) {
+	hits, err := s.vectors.Search(context.Background(), Collection, queryEmbedding, limit, map[string]string{"user_id": userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", s.vectors.Name(), err)
+	}
+	scores := make(map[uint]float64, len(hits))
+	var ids []uint
+	for _, hit := range hits {
+		id, err := strconv.ParseUint(hit.ID, 10, 64)
+		if err != nil || hit.Score < minSimilarity {
+			continue
+		}
+		scores[uint(id)] = hit.Score
+		ids = append(ids, uint(id))
+	}
+
+	var chunks []Chunk
+	if len(ids) > 0 {
+		if err := s.db.Where("id IN ? AND user_id = ?", ids, userID).Find(&chunks).Error; err != nil {
+			return nil, err
+		}
+	}
+	var plain []Chunk
+	if err := s.db.Where("user_id = ? AND (embedding IS NULL OR length(embedding) = 0)", userID).Find(&plain).Error; err != nil {
+		return nil, err
+	}
+
+	var matches []Match
+	for _, chunk := range chunks {
+		matches = append(matches, Match{
+			DocumentID: chunk.DocumentID,
+			Position:   chunk.Position,
+			Content:    chunk.Content,
+			Score:      scores[chunk.ID],
+		})
+	}
+	for _, chunk := range plain {
+		if score := termScore(terms, chunk.Content); score > 0 {
+			matches = append(matches, Match{
+				DocumentID: chunk.DocumentID,
+				Position:   chunk.Position,
+				Content:    chunk.Content,
+				Score:      score,
+			})
+		}
+	}
+	return matches, nil
+}
+
+// stopWords are left out of keyword search
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "was": true, "what": true,
+	"when": true, "where": true, "who": true, "how": true, "does": true, "did": true,
+	"with": true, "from": true, "that": true, "this": true, "about": true, "my": true,
+	"is": true, "of": true, "in": true, "on": true, "to": true, "a": true, "an": true,
+}
+
+func queryTerms(query string) []string {
+	var terms []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !(r == '-' || r == '_' || r == '.' || r == '/' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || r > 127)
+	}) {
+		w = strings.Trim(w, ".-_/")
+		if len(w) > 1 && !stopWords[w] {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// termScore is the share of terms found in content
+func termScore(terms []string, content string) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	content = strings.ToLower(content)
+	found := 0
+	for _, t := range terms {
+		if strings.Contains(content, t) {
+			found++
+		}
+	}
+	return float64(found) / float64(len(terms))
+}
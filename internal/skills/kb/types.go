@@ -0,0 +1,60 @@
+// Package kb keeps uploaded documents in a searchable knowledge base. Each
+// document is copied into the data directory, split into chunks and, with
+// vector search on, embedded so questions can be answered from it later.
+package kb
+
+import "time"
+
+// Document is a file added to the knowledge base
+type Document struct {
+	ID          string    `gorm:"primaryKey" json:"id"`
+	UserID      string    `gorm:"index" json:"-"`
+	Title       string    `json:"title"`
+	Filename    string    `json:"filename"`
+	MimeType    string    `json:"mime_type,omitempty"`
+	SizeBytes   int64     `json:"size_bytes"`
+	StoragePath string    `json:"storage_path"`
+	Source      string    `json:"source"`                            // cli, telegram, api, chat or web
+	SourceURL   string    `gorm:"index" json:"source_url,omitempty"` // the web page it was fetched from
+	Chunks      int       `json:"chunks"`
+	Characters  int       `json:"characters"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName sets the table name
+func (Document) TableName() string { return "kb_documents" }
+
+// Chunk is a passage of a document's text
+type Chunk struct {
+	ID         uint   `gorm:"primaryKey"`
+	DocumentID string `gorm:"index"`
+	UserID     string `gorm:"index"`
+	Position   int    // of the chunk within its document
+	Content    string
+	Embedding  []byte // empty when vector search was off at ingestion
+}
+
+// TableName sets the table name
+func (Chunk) TableName() string { return "kb_chunks" }
+
+// Match is a passage found for a query, with the document it came from
+type Match struct {
+	DocumentID string  `json:"document_id"`
+	Title      string  `json:"title"`
+	Filename   string  `json:"filename"`
+	SourceURL  string  `json:"source_url,omitempty"`
+	Position   int     `json:"chunk"`
+	Content    string  `json:"content"`
+	Score      float64 `json:"score"`
+}
+
+// AddOptions describes a file to add to the knowledge base
+type AddOptions struct {
+	Path      string // of the file to copy in
+	Filename  string // original name, used for the title and to tell the file type; defaults to Path's
+	Title     string
+	MimeType  string
+	UserID    string
+	Source    string
+	SourceURL string // set for files fetched from the web
+}
@@ -0,0 +1,419 @@
+package kb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"go.uber.org/zap"
+)
+
+// maxPageBytes caps how much of a page or file is downloaded
+const maxPageBytes = 20 << 20
+
+// minArticleChars is how much readable text a page needs to be taken as
+// fetched. Pages with less are usually built by scripts, so they are loaded
+// in the browser instead when one is available.
+const minArticleChars = 200
+
+var (
+	// unlikelyPattern matches class and id names of page furniture
+	unlikelyPattern = regexp.MustCompile(`(?i)comment|sidebar|footer|masthead|nav|menu|share|social|advert|promo|cookie|banner|popup|modal|related|subscribe|newsletter|breadcrumb`)
+	// likelyPattern matches class and id names of page content
+	likelyPattern = regexp.MustCompile(`(?i)article|content|post|entry|story|main|body`)
+	spacePattern  = regexp.MustCompile(`\s+`)
+)
+
+// contentTypeExtensions name downloads served without an extension, where
+// the mime package's first choice isn't the usual one
+var contentTypeExtensions = map[string]string{
+	"text/plain":      ".txt",
+	"text/markdown":   ".md",
+	"text/csv":        ".csv",
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+}
+
+// Renderer loads a page in a browser and returns its HTML once scripts have
+// run. *browser.BrowserSkill implements it.
+type Renderer interface {
+	RenderHTML(ctx context.Context, url string) (string, error)
+}
+
+// SetRenderer loads pages that need scripts to show their content with
+// renderer
+func (s *Store) SetRenderer(renderer Renderer) {
+	s.renderer = renderer
+}
+
+// AddURL fetches a web page, keeps its readable content as Markdown and
+// indexes it. Links to files such as PDFs are added like uploaded files.
+// Adding the same URL again replaces the earlier copy.
+func (s *Store) AddURL(ctx context.Context, rawURL string, opts AddOptions) (*Document, error) {
+	pageURL, err := parsePageURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	opts.SourceURL = pageURL.String()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.SourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Myrai/1.0)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", opts.SourceURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", opts.SourceURL, resp.Status)
+	}
+	body := io.LimitReader(resp.Body, maxPageBytes)
+
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if contentType != "text/html" && contentType != "application/xhtml+xml" {
+		return s.addDownload(body, contentType, resp.Request.URL, opts)
+	}
+
+	page, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", opts.SourceURL, err)
+	}
+	title, markdown := extractArticle(bytes.NewReader(page), resp.Request.URL)
+	if len(markdown) < minArticleChars && s.renderer != nil {
+		if rendered, err := s.renderer.RenderHTML(ctx, opts.SourceURL); err != nil {
+			s.logger.Warn("Failed to render page", zap.String("url", opts.SourceURL), zap.Error(err))
+		} else if t, md := extractArticle(strings.NewReader(rendered), resp.Request.URL); len(md) > len(markdown) {
+			markdown = md
+			if t != "" {
+				title = t
+			}
+		}
+	}
+	if markdown == "" {
+		return nil, fmt.Errorf("no readable text found at %s", opts.SourceURL)
+	}
+
+	if opts.Title == "" {
+		opts.Title = title
+	}
+	if opts.Title == "" {
+		opts.Title = pageURL.Host + pageURL.Path
+	}
+	opts.Filename = opts.Title + ".md"
+	opts.MimeType = "text/markdown"
+	text := fmt.Sprintf("# %s\n\nSource: %s\n\n%s\n", opts.Title, opts.SourceURL, markdown)
+
+	doc := newDocument(opts, int64(len(text)), text)
+	if err := s.save(doc, text, func(dst string) error { return os.WriteFile(dst, []byte(text), 0644) }); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// addDownload adds a linked file, such as a PDF, through a temporary copy
+func (s *Store) addDownload(body io.Reader, contentType string, fileURL *url.URL, opts AddOptions) (*Document, error) {
+	name := path.Base(fileURL.Path)
+	if name == "." || name == "/" {
+		name = fileURL.Host
+	}
+	if path.Ext(name) == "" {
+		if ext, ok := contentTypeExtensions[contentType]; ok {
+			name += ext
+		} else if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+
+	tmp, err := os.CreateTemp("", "myrai-kb-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to fetch %s: %w", opts.SourceURL, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	opts.Path = tmp.Name()
+	opts.Filename = name
+	opts.MimeType = contentType
+	return s.Add(opts)
+}
+
+// parsePageURL accepts web addresses with or without a scheme
+func parsePageURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid url: %s", raw)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("only http and https links can be added: %s", raw)
+	}
+	u.Fragment = ""
+	return u, nil
+}
+
+// extractArticle finds a page's main content, the way reader modes do, and
+// returns its title and the content as Markdown
+func extractArticle(r io.Reader, base *url.URL) (title, markdown string) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return "", ""
+	}
+
+	title = strings.TrimSpace(doc.Find(`meta[property="og:title"]`).AttrOr("content", ""))
+	if title == "" {
+		title = strings.TrimSpace(doc.Find("title").First().Text())
+	}
+	if title == "" {
+		title = strings.TrimSpace(doc.Find("h1").First().Text())
+	}
+	title = spacePattern.ReplaceAllString(title, " ")
+
+	doc.Find("script, style, noscript, template, iframe, svg, canvas, form, button, nav, header, footer, aside, [hidden], [aria-hidden=true]").Remove()
+	doc.Find("*").Each(func(_ int, s *goquery.Selection) {
+		if s.Is("html, body, article, main") {
+			return
+		}
+		names := s.AttrOr("class", "") + " " + s.AttrOr("id", "")
+		if unlikelyPattern.MatchString(names) && !likelyPattern.MatchString(names) {
+			s.Remove()
+		}
+	})
+
+	content := bestCandidate(doc)
+	markdown = normalizeText(toMarkdown(content, base))
+	// A heading repeating the title would only be noise under it
+	if first, rest, _ := strings.Cut(markdown, "\n"); strings.EqualFold(strings.TrimSpace(strings.TrimLeft(first, "# ")), title) {
+		markdown = strings.TrimSpace(rest)
+	}
+	return title, markdown
+}
+
+// bestCandidate picks the element holding most of the page's paragraphs.
+// Each paragraph counts toward its parent, and half as much toward its
+// grandparent.
+func bestCandidate(doc *goquery.Document) *goquery.Selection {
+	scores := make(map[interface{}]float64)
+	elements := make(map[interface{}]*goquery.Selection)
+	credit := func(s *goquery.Selection, score float64) {
+		if s.Length() == 0 {
+			return
+		}
+		node := s.Get(0)
+		elements[node] = s
+		scores[node] += score
+	}
+
+	doc.Find("p, pre, td").Each(func(_ int, p *goquery.Selection) {
+		text := strings.TrimSpace(p.Text())
+		if len(text) < 25 {
+			return
+		}
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+		credit(p.Parent(), score)
+		credit(p.Parent().Parent(), score/2)
+	})
+
+	var best interface{}
+	for node, score := range scores {
+		if best == nil || score > scores[best] {
+			best = node
+		}
+	}
+	if best == nil {
+		if main := doc.Find("article, main, [role=main]").First(); main.Length() > 0 {
+			return main
+		}
+		return doc.Find("body")
+	}
+	return elements[best]
+}
+
+// toMarkdown renders an element's content as Markdown
+func toMarkdown(s *goquery.Selection, base *url.URL) string {
+	w := &markdownWriter{base: base}
+	w.blocks(s)
+	w.flush()
+	return w.out.String()
+}
+
+type markdownWriter struct {
+	base *url.URL
+	out  strings.Builder
+	para strings.Builder // inline content waiting for its block to end
+}
+
+// blocks writes the children of s, starting a new block for each block
+// element
+func (w *markdownWriter) blocks(s *goquery.Selection) {
+	s.Contents().Each(func(_ int, c *goquery.Selection) {
+		switch name := goquery.NodeName(c); name {
+		case "#text":
+			w.para.WriteString(spacePattern.ReplaceAllString(c.Text(), " "))
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			w.block(strings.Repeat("#", int(name[1]-'0')) + " " + w.inline(c))
+		case "p":
+			w.block(w.inline(c))
+		case "ul", "ol":
+			w.block(w.list(c, 0))
+		case "pre":
+			w.block("```\n" + strings.Trim(c.Text(), "\n") + "\n```")
+		case "blockquote":
+			inner := strings.TrimSpace(toMarkdown(c, w.base))
+			w.block("> " + strings.ReplaceAll(inner, "\n", "\n> "))
+		case "table":
+			w.block(w.table(c))
+		case "hr":
+			w.block("---")
+		case "br":
+			w.para.WriteString("\n")
+		case "a", "span", "strong", "b", "em", "i", "code", "small", "sup", "sub", "abbr", "time", "mark", "cite", "q", "u", "s", "label", "img":
+			w.para.WriteString(w.inline(c))
+		default:
+			w.flush()
+			w.blocks(c)
+			w.flush()
+		}
+	})
+}
+
+func (w *markdownWriter) block(text string) {
+	w.flush()
+	if text = strings.TrimSpace(text); text != "" {
+		w.out.WriteString(text + "\n\n")
+	}
+}
+
+// flush ends the paragraph in progress
+func (w *markdownWriter) flush() {
+	var lines []string
+	for _, line := range strings.Split(w.para.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	w.para.Reset()
+	if len(lines) > 0 {
+		w.out.WriteString(strings.Join(lines, "\n") + "\n\n")
+	}
+}
+
+// inline renders s's content on one line, with links and emphasis
+func (w *markdownWriter) inline(s *goquery.Selection) string {
+	var b strings.Builder
+	s.Contents().Each(func(_ int, c *goquery.Selection) {
+		switch goquery.NodeName(c) {
+		case "#text":
+			b.WriteString(spacePattern.ReplaceAllString(c.Text(), " "))
+		case "br":
+			b.WriteString("\n")
+		case "img", "ul", "ol", "table":
+		case "a":
+			text := strings.TrimSpace(w.inline(c))
+			href := w.resolve(c.AttrOr("href", ""))
+			if text == "" || href == "" || strings.HasPrefix(href, "#") {
+				b.WriteString(text)
+			} else {
+				fmt.Fprintf(&b, "[%s](%s)", text, href)
+			}
+		case "strong", "b":
+			b.WriteString(wrap(w.inline(c), "**"))
+		case "em", "i":
+			b.WriteString(wrap(w.inline(c), "_"))
+		case "code":
+			b.WriteString(wrap(c.Text(), "`"))
+		default:
+			b.WriteString(w.inline(c))
+		}
+	})
+	return b.String()
+}
+
+// list renders a list, indenting lists nested in its items
+func (w *markdownWriter) list(s *goquery.Selection, depth int) string {
+	var b strings.Builder
+	ordered := goquery.NodeName(s) == "ol"
+	s.ChildrenFiltered("li").Each(func(i int, li *goquery.Selection) {
+		marker := "-"
+		if ordered {
+			marker = fmt.Sprintf("%d.", i+1)
+		}
+		text := strings.Join(strings.Fields(w.inline(li)), " ")
+		fmt.Fprintf(&b, "%s%s %s\n", strings.Repeat("  ", depth), marker, text)
+		li.ChildrenFiltered("ul, ol").Each(func(_ int, nested *goquery.Selection) {
+			b.WriteString(w.list(nested, depth+1))
+		})
+	})
+	return b.String()
+}
+
+// table renders a table as a Markdown table, taking the first row as its
+// header
+func (w *markdownWriter) table(s *goquery.Selection) string {
+	var b strings.Builder
+	s.Find("tr").Each(func(i int, tr *goquery.Selection) {
+		var cells []string
+		tr.Find("th, td").Each(func(_ int, cell *goquery.Selection) {
+			cells = append(cells, strings.ReplaceAll(strings.Join(strings.Fields(w.inline(cell)), " "), "|", `\|`))
+		})
+		if len(cells) == 0 {
+			return
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString(strings.Repeat("| --- ", len(cells)) + "|\n")
+		}
+	})
+	return b.String()
+}
+
+// resolve makes a link absolute, dropping script links
+func (w *markdownWriter) resolve(href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return ""
+	}
+	if strings.HasPrefix(href, "#") || w.base == nil {
+		return href
+	}
+	u, err := w.base.Parse(href)
+	if err != nil {
+		return href
+	}
+	return u.String()
+}
+
+// wrap surrounds text with a Markdown marker, outside any edge spaces
+func wrap(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	lead := text[:strings.Index(text, trimmed)]
+	trail := text[len(lead)+len(trimmed):]
+	return lead + marker + trimmed + marker + trail
+}
@@ -87,6 +87,7 @@ func (k *KnowledgeSkill) registerTools() {
 		},
 		{
 			Name:        "recall",
+			ReadOnly:    true,
 			Description: "Search and retrieve information from your knowledge graph. Ask natural language questions like 'Who did I meet last week?' or 'What are my preferences?'",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -116,6 +117,7 @@ func (k *KnowledgeSkill) registerTools() {
 		},
 		{
 			Name:        "get_entity",
+			ReadOnly:    true,
 			Description: "Get detailed information about a specific person, place, or thing you know",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -160,6 +162,7 @@ func (k *KnowledgeSkill) registerTools() {
 		},
 		{
 			Name:        "list_entities",
+			ReadOnly:    true,
 			Description: "List all entities of a certain type that you know about",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -180,6 +183,7 @@ func (k *KnowledgeSkill) registerTools() {
 		},
 		{
 			Name:        "get_stats",
+			ReadOnly:    true,
 			Description: "Get statistics about your knowledge graph",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/store"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -14,15 +15,27 @@ type Store struct {
 	db *gorm.DB
 }
 
+func init() {
+	store.RegisterMigrations("knowledge", store.Migration{
+		Version: 1,
+		Name:    "create knowledge tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Entity{}, &Relationship{}, &Memory{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Entity{}, &Relationship{}, &Memory{})
+		},
+	})
+}
+
 // NewStore creates a new knowledge store
 func NewStore(db *gorm.DB) (*Store, error) {
-	store := &Store{db: db}
-
-	// Auto-migrate schemas
-	if err := db.AutoMigrate(&Entity{}, &Relationship{}, &Memory{}); err != nil {
+	if err := store.Migrate(db, "knowledge"); err != nil {
 		return nil, fmt.Errorf("failed to migrate knowledge schemas: %w", err)
 	}
 
+	store := &Store{db: db}
+
 	// Create indexes for performance
 	store.createIndexes()
 
@@ -2,6 +2,7 @@ package skills
 
 import (
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -37,6 +38,9 @@ type ManifestTool struct {
 	Name        string          `yaml:"name" json:"name"`
 	Description string          `yaml:"description" json:"description"`
 	Parameters  []ToolParameter `yaml:"parameters" json:"parameters"`
+	// Command is a program in the skill's package that runs the tool. It
+	// gets the arguments as JSON on stdin; its output is the result.
+	Command string `yaml:"command,omitempty" json:"command,omitempty"`
 }
 
 // MCPServerConfig defines MCP server configuration in a skill
@@ -89,6 +93,10 @@ func (m *SkillManifest) Validate() error {
 		}
 		toolNames[tool.Name] = true
 
+		if tool.Command != "" && !filepath.IsLocal(filepath.FromSlash(tool.Command)) {
+			return fmt.Errorf("command of tool %s must be a path inside the skill package", tool.Name)
+		}
+
 		// Validate parameters
 		paramNames := make(map[string]bool)
 		for _, param := range tool.Parameters {
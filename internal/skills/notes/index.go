@@ -0,0 +1,339 @@
+package notes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/vector"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// relatedThreshold is how similar a note must be to a query to count as
+// related, as in memory search
+const relatedThreshold = 0.5
+
+// maxEmbedChars caps how much of a long note is embedded
+const maxEmbedChars = 8000
+
+// Embedder turns text into a vector. *vector.Searcher implements it.
+type Embedder interface {
+	GenerateEmbedding(text string) ([]float32, error)
+}
+
+// NoteEmbedding stores a note's embedding so unchanged notes aren't embedded
+// again after a restart
+type NoteEmbedding struct {
+	Path      string    `gorm:"primaryKey"`
+	ModTime   time.Time // of the note when it was embedded
+	Embedding []byte
+}
+
+// TableName sets the table name
+func (NoteEmbedding) TableName() string { return "note_embeddings" }
+
+// Related is a note similar to a query
+type Related struct {
+	*Note
+	Similarity float64 `json:"similarity"`
+}
+
+// Index keeps the vault's notes parsed in memory for tag, link and backlink
+// queries and, with an embedder, their embeddings for semantic search
+type Index struct {
+	vault    *Vault
+	db       *gorm.DB
+	embedder Embedder
+	logger   *zap.Logger
+
+	mu         sync.RWMutex
+	notes      map[string]*Note
+	embeddings map[string][]float32
+
+	// watched is set while a Watcher keeps the index current, so queries
+	// don't need to check the vault for changes first
+	watched atomic.Bool
+}
+
+// NewIndex creates an empty index of vault
+func NewIndex(vault *Vault, logger *zap.Logger) *Index {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Index{
+		vault:      vault,
+		logger:     logger,
+		notes:      make(map[string]*Note),
+		embeddings: make(map[string][]float32),
+	}
+}
+
+func init() {
+	store.RegisterMigrations("notes", store.Migration{
+		Version: 1,
+		Name:    "create notes tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&NoteEmbedding{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&NoteEmbedding{})
+		},
+	})
+}
+
+// EnableEmbeddings embeds notes with embedder and keeps the embeddings in db
+func (i *Index) EnableEmbeddings(db *gorm.DB, embedder Embedder) error {
+	if err := store.Migrate(db, "notes"); err != nil {
+		return fmt.Errorf("failed to migrate note embeddings: %w", err)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.db = db
+	i.embedder = embedder
+	return nil
+}
+
+// Semantic reports whether notes are embedded for semantic search
+func (i *Index) Semantic() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.embedder != nil
+}
+
+// Refresh brings the index up to date with the vault unless a watcher
+// already does
+func (i *Index) Refresh() error {
+	if i.watched.Load() {
+		return nil
+	}
+	return i.Sync()
+}
+
+// Sync re-reads notes that changed since they were indexed and drops those
+// that are gone
+func (i *Index) Sync() error {
+	paths, err := i.vault.Paths()
+	if err != nil {
+		return fmt.Errorf("failed to read notes: %w", err)
+	}
+
+	present := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		present[path] = true
+		if err := i.Update(path); err != nil {
+			i.logger.Warn("Failed to index note", zap.String("path", path), zap.Error(err))
+		}
+	}
+
+	i.mu.RLock()
+	var gone []string
+	for path := range i.notes {
+		if !present[path] {
+			gone = append(gone, path)
+		}
+	}
+	i.mu.RUnlock()
+	for _, path := range gone {
+		i.Remove(path)
+	}
+	return nil
+}
+
+// Reindex embeds every note again, after the embedding model changes, and
+// returns how many notes are embedded
+func (i *Index) Reindex() (int, error) {
+	i.mu.Lock()
+	db, embedder := i.db, i.embedder
+	if embedder != nil {
+		i.embeddings = make(map[string][]float32)
+	}
+	i.mu.Unlock()
+	if embedder == nil {
+		return 0, fmt.Errorf("semantic search is not enabled")
+	}
+
+	if err := db.Where("1 = 1").Delete(&NoteEmbedding{}).Error; err != nil {
+		return 0, fmt.Errorf("failed to drop note embeddings: %w", err)
+	}
+	if err := i.Sync(); err != nil {
+		return 0, err
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.embeddings), nil
+}
+
+// Update indexes the note at path if it changed, or drops it if it no longer
+// exists
+func (i *Index) Update(path string) error {
+	note, err := i.vault.Read(path)
+	if err != nil {
+		i.Remove(path)
+		return nil
+	}
+
+	i.mu.Lock()
+	existing := i.notes[path]
+	changed := existing == nil || !existing.ModTime.Equal(note.ModTime)
+	if changed {
+		i.notes[path] = note
+	}
+	_, embedded := i.embeddings[path]
+	i.mu.Unlock()
+
+	if !changed && embedded {
+		return nil
+	}
+	return i.embed(note)
+}
+
+// Remove drops the note at path
+func (i *Index) Remove(path string) {
+	i.mu.Lock()
+	delete(i.notes, path)
+	delete(i.embeddings, path)
+	db := i.db
+	i.mu.Unlock()
+
+	if db != nil {
+		if err := db.Where("path = ?", path).Delete(&NoteEmbedding{}).Error; err != nil {
+			i.logger.Warn("Failed to drop note embedding", zap.String("path", path), zap.Error(err))
+		}
+	}
+}
+
+// embed stores the note's embedding, reusing the saved one if the note
+// hasn't changed since
+func (i *Index) embed(note *Note) error {
+	i.mu.RLock()
+	db, embedder := i.db, i.embedder
+	i.mu.RUnlock()
+	if embedder == nil {
+		return nil
+	}
+
+	var saved NoteEmbedding
+	err := db.Where("path = ?", note.Path).First(&saved).Error
+	if err == nil && saved.ModTime.Equal(note.ModTime) {
+		i.mu.Lock()
+		i.embeddings[note.Path] = vector.DecodeEmbedding(saved.Embedding)
+		i.mu.Unlock()
+		return nil
+	}
+
+	embedding, err := embedder.GenerateEmbedding(embedText(note))
+	if err != nil {
+		return fmt.Errorf("failed to embed note: %w", err)
+	}
+	i.mu.Lock()
+	i.embeddings[note.Path] = embedding
+	i.mu.Unlock()
+
+	return db.Save(&NoteEmbedding{
+		Path:      note.Path,
+		ModTime:   note.ModTime,
+		Embedding: vector.EncodeEmbedding(embedding),
+	}).Error
+}
+
+// Notes returns the indexed notes, most recently changed first
+func (i *Index) Notes() []*Note {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	notes := make([]*Note, 0, len(i.notes))
+	for _, n := range i.notes {
+		notes = append(notes, n)
+	}
+	sort.Slice(notes, func(a, b int) bool { return notes[a].ModTime.After(notes[b].ModTime) })
+	return notes
+}
+
+// Find returns the note a title or wikilink target refers to
+func (i *Index) Find(name string) *Note {
+	key := linkKey(name)
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	var match *Note
+	for _, n := range i.notes {
+		for _, k := range n.Names() {
+			if k == key && (match == nil || n.Path < match.Path) {
+				match = n
+			}
+		}
+	}
+	return match
+}
+
+// Backlinks returns the notes linking to note
+func (i *Index) Backlinks(note *Note) []*Note {
+	names := make(map[string]bool)
+	for _, k := range note.Names() {
+		names[k] = true
+	}
+
+	var backlinks []*Note
+	for _, n := range i.Notes() {
+		if n.Path == note.Path {
+			continue
+		}
+		for _, link := range n.Links {
+			if names[linkKey(link)] {
+				backlinks = append(backlinks, n)
+				break
+			}
+		}
+	}
+	return backlinks
+}
+
+// Related returns up to limit notes most similar in meaning to query
+func (i *Index) Related(query string, limit int) ([]Related, error) {
+	i.mu.RLock()
+	embedder := i.embedder
+	i.mu.RUnlock()
+	if embedder == nil {
+		return nil, nil
+	}
+
+	queryEmbedding, err := embedder.GenerateEmbedding(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	i.mu.RLock()
+	var related []Related
+	for path, embedding := range i.embeddings {
+		note, ok := i.notes[path]
+		if !ok {
+			continue
+		}
+		if similarity := vector.CosineSimilarity(queryEmbedding, embedding); similarity >= relatedThreshold {
+			related = append(related, Related{Note: note, Similarity: similarity})
+		}
+	}
+	i.mu.RUnlock()
+
+	sort.Slice(related, func(a, b int) bool { return related[a].Similarity > related[b].Similarity })
+	if len(related) > limit {
+		related = related[:limit]
+	}
+	return related, nil
+}
+
+func embedText(note *Note) string {
+	text := note.Title
+	if len(note.Tags) > 0 {
+		text += "\nTags: " + strings.Join(note.Tags, ", ")
+	}
+	text += "\n\n" + note.Body
+	if len(text) > maxEmbedChars {
+		text = strings.ToValidUTF8(text[:maxEmbedChars], "")
+	}
+	return text
+}
@@ -8,34 +8,65 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/security"
 	"github.com/gmsas95/myrai-cli/internal/skills"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
 )
 
-// NotesSkill provides note-taking functionality
+// NotesSkill provides note-taking functionality on a Markdown vault, such as
+// an Obsidian vault. Notes edited elsewhere are picked up on the next query,
+// or right away while a Watcher runs.
 type NotesSkill struct {
 	*skills.BaseSkill
-	notesDir string
+	vault *Vault
+	index *Index
+}
+
+// Dir returns the notes directory for a configured vault directory, which
+// may be empty
+func Dir(vaultDir string) string {
+	if vaultDir != "" {
+		return vaultDir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".myrai", "notes")
 }
 
 // NewNotesSkill creates a new notes skill
 func NewNotesSkill(notesDir string) *NotesSkill {
-	if notesDir == "" {
-		home, _ := os.UserHomeDir()
-		notesDir = filepath.Join(home, ".myrai", "notes")
-	}
+	notesDir = Dir(notesDir)
 
 	// Create notes directory if it doesn't exist
-	os.MkdirAll(notesDir, 0755)
+	vault, _ := NewVault(notesDir)
 
 	s := &NotesSkill{
 		BaseSkill: skills.NewBaseSkill("notes", "Personal note-taking system", "1.0.0"),
-		notesDir:  notesDir,
+		vault:     vault,
+		index:     NewIndex(vault, nil),
 	}
 
 	s.registerTools()
 	return s
 }
 
+// SetFilePolicy replaces the policy deciding which notes the tools may read
+// and write
+func (s *NotesSkill) SetFilePolicy(policy *security.FilePolicy) {
+	s.vault.SetFilePolicy(policy)
+}
+
+// Index returns the index a Watcher keeps current
+func (s *NotesSkill) Index() *Index {
+	return s.index
+}
+
+// EnableSemanticSearch embeds notes with embedder so searches also find
+// notes that are related in meaning. Embeddings are kept in db.
+func (s *NotesSkill) EnableSemanticSearch(db *gorm.DB, embedder Embedder) error {
+	return s.index.EnableEmbeddings(db, embedder)
+}
+
 func (s *NotesSkill) registerTools() {
 	s.AddTool(skills.Tool{
 		Name:        "create_note",
@@ -49,7 +80,7 @@ func (s *NotesSkill) registerTools() {
 				},
 				"content": map[string]interface{}{
 					"type":        "string",
-					"description": "Note content (markdown supported)",
+					"description": "Note content (markdown supported, link other notes with [[Note title]])",
 				},
 				"tags": map[string]interface{}{
 					"type":        "array",
@@ -58,6 +89,10 @@ func (s *NotesSkill) registerTools() {
 						"type": "string",
 					},
 				},
+				"folder": map[string]interface{}{
+					"type":        "string",
+					"description": "Folder in the vault to create the note in",
+				},
 			},
 			"required": []string{"title", "content"},
 		},
@@ -66,7 +101,8 @@ func (s *NotesSkill) registerTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "read_note",
-		Description: "Read a note by title",
+		ReadOnly:    true,
+		Description: "Read a note by title, with its tags, the notes it links to and the notes linking to it",
 		Parameters: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -80,8 +116,41 @@ func (s *NotesSkill) registerTools() {
 		Handler: s.handleReadNote,
 	})
 
+	s.AddTool(skills.Tool{
+		Name:        "update_note",
+		Description: "Add to or rewrite an existing note, keeping its frontmatter",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"title": map[string]interface{}{
+					"type":        "string",
+					"description": "Note title",
+				},
+				"content": map[string]interface{}{
+					"type":        "string",
+					"description": "Text to append, or the note's new content",
+				},
+				"mode": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"append", "replace"},
+					"description": "append (default) or replace the note's content",
+				},
+				"tags": map[string]interface{}{
+					"type":        "array",
+					"description": "Tags to add to the note",
+					"items": map[string]interface{}{
+						"type": "string",
+					},
+				},
+			},
+			"required": []string{"title"},
+		},
+		Handler: s.handleUpdateNote,
+	})
+
 	s.AddTool(skills.Tool{
 		Name:        "list_notes",
+		ReadOnly:    true,
 		Description: "List all notes",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -90,6 +159,10 @@ func (s *NotesSkill) registerTools() {
 					"type":        "string",
 					"description": "Filter by tag",
 				},
+				"folder": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by folder",
+				},
 			},
 		},
 		Handler: s.handleListNotes,
@@ -97,7 +170,8 @@ func (s *NotesSkill) registerTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "search_notes",
-		Description: "Search notes by content",
+		ReadOnly:    true,
+		Description: "Search notes by content. Also returns notes related in meaning when semantic search is on.",
 		Parameters: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -105,12 +179,33 @@ func (s *NotesSkill) registerTools() {
 					"type":        "string",
 					"description": "Search query",
 				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum number of related notes (default 5)",
+				},
 			},
 			"required": []string{"query"},
 		},
 		Handler: s.handleSearchNotes,
 	})
 
+	s.AddTool(skills.Tool{
+		Name:        "get_backlinks",
+		ReadOnly:    true,
+		Description: "List the notes that link to a note with [[wikilinks]]",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"title": map[string]interface{}{
+					"type":        "string",
+					"description": "Note title",
+				},
+			},
+			"required": []string{"title"},
+		},
+		Handler: s.handleGetBacklinks,
+	})
+
 	s.AddTool(skills.Tool{
 		Name:        "delete_note",
 		Description: "Delete a note",
@@ -136,45 +231,62 @@ func (s *NotesSkill) sanitizeTitle(title string) string {
 	return strings.ToLower(title)
 }
 
+// find returns the note with title, also under the file names notes were
+// saved with before vault support
+func (s *NotesSkill) find(title string) (*Note, error) {
+	if err := s.index.Refresh(); err != nil {
+		return nil, err
+	}
+	if note := s.index.Find(title); note != nil {
+		return note, nil
+	}
+	if note := s.index.Find(s.sanitizeTitle(title)); note != nil {
+		return note, nil
+	}
+	return nil, fmt.Errorf("note not found: %s", title)
+}
+
 func (s *NotesSkill) handleCreateNote(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	title, _ := args["title"].(string)
 	content, _ := args["content"].(string)
-	tagsInterface, _ := args["tags"].([]interface{})
+	folder, _ := args["folder"].(string)
 
 	if title == "" || content == "" {
 		return nil, fmt.Errorf("title and content are required")
 	}
 
-	// Convert tags
-	tags := make([]string, 0, len(tagsInterface))
-	for _, t := range tagsInterface {
-		if tag, ok := t.(string); ok {
-			tags = append(tags, tag)
-		}
+	path, err := s.vault.NotePath(folder, title)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(s.vault.Dir(), filepath.FromSlash(path))); err == nil {
+		return nil, fmt.Errorf("note '%s' already exists; update it instead", title)
 	}
 
-	// Build note content
-	var noteContent strings.Builder
-	noteContent.WriteString(fmt.Sprintf("# %s\n\n", title))
-	noteContent.WriteString(fmt.Sprintf("Created: %s\n", time.Now().Format("2006-01-02 15:04:05")))
-	if len(tags) > 0 {
-		noteContent.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(tags, ", ")))
+	// Build note content with Obsidian-style frontmatter
+	front := struct {
+		Tags    []string `yaml:"tags,omitempty"`
+		Created string   `yaml:"created"`
+	}{
+		Tags:    stringArgs(args["tags"]),
+		Created: time.Now().Format("2006-01-02 15:04"),
 	}
-	noteContent.WriteString("\n---\n\n")
-	noteContent.WriteString(content)
+	header, err := marshalYAML(front)
+	if err != nil {
+		return nil, err
+	}
+	noteContent := "---\n" + header + "---\n\n" + content
 
 	// Save note
-	filename := s.sanitizeTitle(title) + ".md"
-	filepath := filepath.Join(s.notesDir, filename)
-
-	if err := os.WriteFile(filepath, []byte(noteContent.String()), 0644); err != nil {
+	if err := s.vault.Write(path, noteContent); err != nil {
 		return nil, fmt.Errorf("failed to save note: %w", err)
 	}
+	s.index.Update(path)
 
 	return map[string]interface{}{
 		"title":    title,
-		"filename": filename,
-		"saved_at": filepath,
+		"path":     path,
+		"saved_at": filepath.Join(s.vault.Dir(), filepath.FromSlash(path)),
 	}, nil
 }
 
@@ -184,49 +296,98 @@ func (s *NotesSkill) handleReadNote(ctx context.Context, args map[string]interfa
 		return nil, fmt.Errorf("title is required")
 	}
 
-	filename := s.sanitizeTitle(title) + ".md"
-	filepath := filepath.Join(s.notesDir, filename)
+	note, err := s.find(title)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"title":     note.Title,
+		"path":      note.Path,
+		"content":   note.Body,
+		"tags":      note.Tags,
+		"links":     note.Links,
+		"backlinks": titles(s.index.Backlinks(note)),
+	}
+	if len(note.Frontmatter) > 0 {
+		result["frontmatter"] = note.Frontmatter
+	}
+	return result, nil
+}
+
+func (s *NotesSkill) handleUpdateNote(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	title, _ := args["title"].(string)
+	content, _ := args["content"].(string)
+	mode, _ := args["mode"].(string)
+	tags := stringArgs(args["tags"])
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	if content == "" && len(tags) == 0 {
+		return nil, fmt.Errorf("content or tags are required")
+	}
 
-	content, err := os.ReadFile(filepath)
+	note, err := s.find(title)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(filepath.Join(s.vault.Dir(), filepath.FromSlash(note.Path)))
 	if err != nil {
-		return nil, fmt.Errorf("note not found: %s", title)
+		return nil, fmt.Errorf("failed to read note: %w", err)
+	}
+
+	front, body, hasFront := splitFrontmatter(string(raw))
+	switch {
+	case content == "":
+	case mode == "replace":
+		body = content
+	default:
+		body = strings.TrimRight(body, "\n") + "\n\n" + content
+	}
+	if len(tags) > 0 {
+		if front, err = addTags(front, tags); err != nil {
+			return nil, fmt.Errorf("failed to update frontmatter: %w", err)
+		}
+		hasFront = true
 	}
 
+	updated := body
+	if hasFront {
+		updated = "---\n" + front + "---\n\n" + body
+	}
+	if !strings.HasSuffix(updated, "\n") {
+		updated += "\n"
+	}
+	if err := s.vault.Write(note.Path, updated); err != nil {
+		return nil, fmt.Errorf("failed to save note: %w", err)
+	}
+	s.index.Update(note.Path)
+
 	return map[string]interface{}{
-		"title":   title,
-		"content": string(content),
+		"title":   note.Title,
+		"path":    note.Path,
+		"message": fmt.Sprintf("Updated '%s'", note.Title),
 	}, nil
 }
 
 func (s *NotesSkill) handleListNotes(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	tag, _ := args["tag"].(string)
+	folder, _ := args["folder"].(string)
+	folder = strings.Trim(filepath.ToSlash(folder), "/")
 
-	entries, err := os.ReadDir(s.notesDir)
-	if err != nil {
+	if err := s.index.Refresh(); err != nil {
 		return nil, fmt.Errorf("failed to list notes: %w", err)
 	}
 
 	notes := make([]map[string]interface{}, 0)
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
-			info, _ := entry.Info()
-			name := strings.TrimSuffix(entry.Name(), ".md")
-
-			// Read file to check tags if filtering
-			if tag != "" {
-				content, err := os.ReadFile(filepath.Join(s.notesDir, entry.Name()))
-				if err == nil && !strings.Contains(string(content), "Tags: "+tag) &&
-					!strings.Contains(string(content), "Tags: "+strings.Join([]string{"", tag}, ", ")) {
-					continue
-				}
-			}
-
-			notes = append(notes, map[string]interface{}{
-				"title":      name,
-				"filename":   entry.Name(),
-				"updated_at": info.ModTime().Format("2006-01-02 15:04:05"),
-			})
+	for _, note := range s.index.Notes() {
+		if tag != "" && !note.HasTag(tag) {
+			continue
+		}
+		if folder != "" && !strings.HasPrefix(strings.ToLower(note.Path), strings.ToLower(folder)+"/") {
+			continue
 		}
+		notes = append(notes, summary(note))
 	}
 
 	return notes, nil
@@ -237,48 +398,178 @@ func (s *NotesSkill) handleSearchNotes(ctx context.Context, args map[string]inte
 	if query == "" {
 		return nil, fmt.Errorf("query is required")
 	}
+	limit := 5
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
 
-	query = strings.ToLower(query)
-	entries, err := os.ReadDir(s.notesDir)
-	if err != nil {
+	if err := s.index.Refresh(); err != nil {
 		return nil, fmt.Errorf("failed to search notes: %w", err)
 	}
 
+	lower := strings.ToLower(query)
+	matched := make(map[string]bool)
 	results := make([]map[string]interface{}, 0)
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
-			content, err := os.ReadFile(filepath.Join(s.notesDir, entry.Name()))
-			if err != nil {
-				continue
-			}
-
-			if strings.Contains(strings.ToLower(string(content)), query) {
-				name := strings.TrimSuffix(entry.Name(), ".md")
-				info, _ := entry.Info()
-				results = append(results, map[string]interface{}{
-					"title":      name,
-					"filename":   entry.Name(),
-					"updated_at": info.ModTime().Format("2006-01-02 15:04:05"),
-				})
-			}
+	for _, note := range s.index.Notes() {
+		if strings.Contains(strings.ToLower(note.Title), lower) || strings.Contains(strings.ToLower(note.Body), lower) {
+			matched[note.Path] = true
+			results = append(results, summary(note))
 		}
 	}
 
-	return results, nil
+	if !s.index.Semantic() {
+		return results, nil
+	}
+
+	related, err := s.index.Related(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	relatedNotes := make([]map[string]interface{}, 0)
+	for _, r := range related {
+		if matched[r.Path] {
+			continue
+		}
+		item := summary(r.Note)
+		item["similarity"] = r.Similarity
+		relatedNotes = append(relatedNotes, item)
+	}
+	return map[string]interface{}{
+		"matches": results,
+		"related": relatedNotes,
+	}, nil
 }
 
-func (s *NotesSkill) handleDeleteNote(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+func (s *NotesSkill) handleGetBacklinks(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	title, _ := args["title"].(string)
 	if title == "" {
 		return nil, fmt.Errorf("title is required")
 	}
 
-	filename := s.sanitizeTitle(title) + ".md"
-	filepath := filepath.Join(s.notesDir, filename)
+	note, err := s.find(title)
+	if err != nil {
+		return nil, err
+	}
+
+	backlinks := make([]map[string]interface{}, 0)
+	for _, n := range s.index.Backlinks(note) {
+		backlinks = append(backlinks, summary(n))
+	}
+	return map[string]interface{}{
+		"title":     note.Title,
+		"count":     len(backlinks),
+		"backlinks": backlinks,
+	}, nil
+}
+
+func (s *NotesSkill) handleDeleteNote(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	title, _ := args["title"].(string)
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
 
-	if err := os.Remove(filepath); err != nil {
+	note, err := s.find(title)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.vault.Remove(note.Path); err != nil {
 		return nil, fmt.Errorf("failed to delete note: %w", err)
 	}
+	s.index.Remove(note.Path)
+
+	return fmt.Sprintf("Note '%s' deleted successfully", note.Title), nil
+}
 
-	return fmt.Sprintf("Note '%s' deleted successfully", title), nil
+// addTags adds tags to a frontmatter block, keeping the rest of it as written
+func addTags(front string, tags []string) (string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(front), &doc); err != nil {
+		return "", err
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("frontmatter is not a mapping")
+	}
+
+	var list *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "tags" {
+			list = root.Content[i+1]
+		}
+	}
+	if list == nil {
+		list = &yaml.Node{Kind: yaml.SequenceNode}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "tags"}, list)
+	}
+	if list.Kind == yaml.ScalarNode {
+		// "tags: a, b" becomes a list
+		existing := stringList(list.Value, ", ")
+		*list = yaml.Node{Kind: yaml.SequenceNode}
+		for _, t := range existing {
+			list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: t})
+		}
+	}
+
+	have := make(map[string]bool)
+	for _, item := range list.Content {
+		have[strings.ToLower(item.Value)] = true
+	}
+	for _, t := range tags {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "#")
+		if t == "" || have[strings.ToLower(t)] {
+			continue
+		}
+		have[strings.ToLower(t)] = true
+		list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: t})
+	}
+
+	return marshalYAML(&doc)
+}
+
+// marshalYAML writes frontmatter indented like Obsidian writes it
+func marshalYAML(v interface{}) (string, error) {
+	var buf strings.Builder
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func summary(note *Note) map[string]interface{} {
+	item := map[string]interface{}{
+		"title":      note.Title,
+		"path":       note.Path,
+		"updated_at": note.ModTime.Format("2006-01-02 15:04:05"),
+	}
+	if len(note.Tags) > 0 {
+		item["tags"] = note.Tags
+	}
+	return item
+}
+
+func titles(notes []*Note) []string {
+	out := make([]string, 0, len(notes))
+	for _, n := range notes {
+		out = append(out, n.Title)
+	}
+	return out
+}
+
+func stringArgs(v interface{}) []string {
+	items, _ := v.([]interface{})
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
 }
@@ -0,0 +1,198 @@
+package notes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func writeNote(t *testing.T, dir, rel, content string) {
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// wordEmbedder embeds text by which of a few words it contains
+type wordEmbedder struct{}
+
+func (wordEmbedder) GenerateEmbedding(text string) ([]float32, error) {
+	text = strings.ToLower(text)
+	var v []float32
+	for _, w := range []string{"garden", "tomato", "budget", "tax"} {
+		if strings.Contains(text, w) {
+			v = append(v, 1)
+		} else {
+			v = append(v, 0)
+		}
+	}
+	return v, nil
+}
+
+func TestParseNote(t *testing.T) {
+	note := ParseNote("Projects/Garden.md", `---
+title: Vegetable Garden
+aliases: [Veggies]
+tags:
+  - home
+  - "#project/garden"
+---
+Plant [[Tomatoes|the tomatoes]] and see [[Projects/Budget#Spring]].
+Also ![[Seed list]] #outdoors and [[tomatoes]] again.
+
+# Heading, not a tag
+`+"```\n#include <stdio.h>\n```\n")
+
+	assert.Equal(t, "Vegetable Garden", note.Title)
+	assert.Equal(t, []string{"Veggies"}, note.Aliases)
+	assert.Equal(t, []string{"home", "project/garden", "outdoors"}, note.Tags)
+	assert.Equal(t, []string{"Tomatoes", "Projects/Budget", "Seed list"}, note.Links)
+	assert.True(t, note.HasTag("project"))
+	assert.False(t, note.HasTag("proj"))
+
+	legacy := ParseNote("shopping_ideas.md", "# Shopping ideas\n\nCreated: 2025-01-01 10:00:00\nTags: home, budget\n\n---\n\nStuff")
+	assert.Equal(t, "shopping_ideas", legacy.Title)
+	assert.Equal(t, []string{"home", "budget"}, legacy.Tags)
+}
+
+func TestNotesSkill_Vault(t *testing.T) {
+	dir := t.TempDir()
+	writeNote(t, dir, "Projects/Garden.md", "---\ntags: [home]\n---\nPlant [[Tomatoes]] this spring.\n")
+	writeNote(t, dir, "Tomatoes.md", "Cherry tomatoes do well in pots.\n")
+	writeNote(t, dir, "Budget.md", "Tax return is due. See [[Garden]] costs.\n")
+	writeNote(t, dir, ".obsidian/workspace.md", "[[Tomatoes]]")
+
+	skill := NewNotesSkill(dir)
+	ctx := context.Background()
+
+	list, err := skill.handleListNotes(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Len(t, list, 3, "Obsidian's own folder is skipped")
+
+	list, err = skill.handleListNotes(ctx, map[string]interface{}{"folder": "projects"})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	read, err := skill.handleReadNote(ctx, map[string]interface{}{"title": "tomatoes"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Garden"}, read.(map[string]interface{})["backlinks"])
+
+	backlinks, err := skill.handleGetBacklinks(ctx, map[string]interface{}{"title": "Garden"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, backlinks.(map[string]interface{})["count"])
+
+	// Notes the assistant writes land in the vault as Obsidian would write them
+	_, err = skill.handleCreateNote(ctx, map[string]interface{}{
+		"title":   "Seed list",
+		"content": "Basil for the [[Garden]]",
+		"tags":    []interface{}{"home"},
+		"folder":  "Projects",
+	})
+	require.NoError(t, err)
+	raw, err := os.ReadFile(filepath.Join(dir, "Projects", "Seed list.md"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(raw), "---\ntags:\n  - home\ncreated: "))
+
+	_, err = skill.handleCreateNote(ctx, map[string]interface{}{"title": "Seed list", "content": "again", "folder": "Projects"})
+	assert.Error(t, err)
+
+	_, err = skill.handleUpdateNote(ctx, map[string]interface{}{"title": "Garden", "content": "- basil", "tags": []interface{}{"spring", "home"}})
+	require.NoError(t, err)
+	raw, err = os.ReadFile(filepath.Join(dir, "Projects", "Garden.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntags: [home, spring]\n---\n\nPlant [[Tomatoes]] this spring.\n\n- basil\n", string(raw))
+
+	// Edits made outside are picked up on the next query
+	writeNote(t, dir, "Tomatoes.md", "Moved to #greenhouse\n")
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "Tomatoes.md"), future, future))
+	list, err = skill.handleListNotes(ctx, map[string]interface{}{"tag": "greenhouse"})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	_, err = skill.handleCreateNote(ctx, map[string]interface{}{"title": "x", "content": "y", "folder": "../outside"})
+	assert.Error(t, err)
+}
+
+func TestNotesSkill_FilePolicy(t *testing.T) {
+	dir := t.TempDir()
+	writeNote(t, dir, "Journal/Today.md", "Went for a run.\n")
+	writeNote(t, dir, "Private/Diary.md", "Secret plans.\n")
+
+	policy, err := security.NewFilePolicy(security.FilePolicyOptions{
+		Deny:     []string{filepath.Join(dir, "Private")},
+		ReadOnly: []string{filepath.Join(dir, "Journal")},
+	})
+	require.NoError(t, err)
+	skill := NewNotesSkill(dir)
+	skill.SetFilePolicy(policy)
+	ctx := context.Background()
+
+	list, err := skill.handleListNotes(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Len(t, list, 1, "Denied notes are left out")
+
+	_, err = skill.handleUpdateNote(ctx, map[string]interface{}{"title": "Today", "content": "- swim"})
+	var denial *security.PathDeniedError
+	assert.ErrorAs(t, err, &denial, "Read-only notes can't be changed")
+
+	_, err = skill.handleCreateNote(ctx, map[string]interface{}{"title": "Plans", "content": "x", "folder": "Private"})
+	assert.ErrorAs(t, err, &denial)
+	_, err = skill.handleCreateNote(ctx, map[string]interface{}{"title": "Plans", "content": "x"})
+	assert.NoError(t, err)
+}
+
+func TestNotesSkill_SemanticSearch(t *testing.T) {
+	dir := t.TempDir()
+	writeNote(t, dir, "Garden.md", "Growing tomato plants")
+	writeNote(t, dir, "Money.md", "Yearly budget and tax")
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	skill := NewNotesSkill(dir)
+	require.NoError(t, skill.EnableSemanticSearch(db, wordEmbedder{}))
+
+	result, err := skill.handleSearchNotes(context.Background(), map[string]interface{}{"query": "what do I know about tax"})
+	require.NoError(t, err)
+	r := result.(map[string]interface{})
+	assert.Empty(t, r["matches"])
+	related := r["related"].([]map[string]interface{})
+	require.Len(t, related, 1)
+	assert.Equal(t, "Money", related[0]["title"])
+
+	var count int64
+	db.Model(&NoteEmbedding{}).Count(&count)
+	assert.Equal(t, int64(2), count, "embeddings are kept for the next start")
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	writeNote(t, dir, "Inbox.md", "Nothing yet")
+
+	skill := NewNotesSkill(dir)
+	watcher, err := NewWatcher(skill.Index(), nil)
+	require.NoError(t, err)
+	require.NoError(t, watcher.Start())
+	defer watcher.Stop()
+	assert.Len(t, skill.Index().Notes(), 1)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "Daily"), 0755))
+	time.Sleep(100 * time.Millisecond)
+	writeNote(t, dir, "Daily/2025-05-01.md", "Met [[Inbox]]")
+
+	assert.Eventually(t, func() bool {
+		note := skill.Index().Find("Inbox")
+		return note != nil && len(skill.Index().Backlinks(note)) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "Inbox.md")))
+	assert.Eventually(t, func() bool { return skill.Index().Find("Inbox") == nil }, 5*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,344 @@
+package notes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/security"
+	"gopkg.in/yaml.v3"
+)
+
+// wikiLinkPattern matches [[Note]], [[Note|alias]], [[Note#Heading]] and
+// embeds like ![[Note]]
+var wikiLinkPattern = regexp.MustCompile(`\[\[([^\]|#]*)(?:#[^\]|]*)?(?:\|[^\]]*)?\]\]`)
+
+// tagPattern matches inline #tags. Headings need a space after the #, so
+// they don't match.
+var tagPattern = regexp.MustCompile(`(?:^|\s)#([\p{L}_][\p{L}\p{N}_/-]*)`)
+
+// unsafeFilename matches characters Obsidian doesn't allow in note names
+var unsafeFilename = regexp.MustCompile(`[\\/:*?"<>|#^\[\]]`)
+
+// Note is a Markdown file in the vault
+type Note struct {
+	Path        string                 `json:"path"` // relative to the vault, with forward slashes
+	Title       string                 `json:"title"`
+	Aliases     []string               `json:"aliases,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Links       []string               `json:"links,omitempty"` // wikilink targets as written
+	Frontmatter map[string]interface{} `json:"frontmatter,omitempty"`
+	Body        string                 `json:"-"`
+	ModTime     time.Time              `json:"updated_at"`
+}
+
+// Vault reads and writes the Markdown files of a notes directory, including
+// those in subfolders. Obsidian's own folders, like .obsidian, are skipped.
+type Vault struct {
+	dir   string
+	files atomic.Pointer[security.FilePolicy]
+}
+
+// NewVault opens the vault at dir, creating it if needed
+func NewVault(dir string) (*Vault, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create notes directory: %w", err)
+	}
+	return &Vault{dir: dir}, nil
+}
+
+// Dir returns the vault's directory
+func (v *Vault) Dir() string {
+	return v.dir
+}
+
+// SetFilePolicy applies policy to the notes read and written, on top of
+// keeping them inside the vault
+func (v *Vault) SetFilePolicy(policy *security.FilePolicy) {
+	v.files.Store(policy)
+}
+
+// Paths lists the vault's notes relative to its directory
+func (v *Vault) Paths() ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(v.dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != v.dir && hidden(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isNote(d.Name()) {
+			rel, err := filepath.Rel(v.dir, path)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// Read parses the note at a vault-relative path
+func (v *Vault) Read(rel string) (*Note, error) {
+	full, err := v.abs(rel)
+	if err != nil {
+		return nil, err
+	}
+	if full, err = v.files.Load().CheckRead(full); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(full)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(full)
+	if err != nil {
+		return nil, err
+	}
+	note := ParseNote(filepath.ToSlash(rel), string(content))
+	note.ModTime = info.ModTime()
+	return note, nil
+}
+
+// Write saves content at a vault-relative path, creating folders as needed
+func (v *Vault) Write(rel, content string) error {
+	full, err := v.abs(rel)
+	if err != nil {
+		return err
+	}
+	if full, err = v.files.Load().CheckWrite(full, int64(len(content))); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(full, []byte(content), 0644)
+}
+
+// Remove deletes the note at a vault-relative path
+func (v *Vault) Remove(rel string) error {
+	full, err := v.abs(rel)
+	if err != nil {
+		return err
+	}
+	if _, err := v.files.Load().CheckWrite(full, 0); err != nil {
+		return err
+	}
+	return os.Remove(full)
+}
+
+// NotePath is where a new note with title goes, inside folder if given
+func (v *Vault) NotePath(folder, title string) (string, error) {
+	name := strings.TrimSpace(unsafeFilename.ReplaceAllString(title, " "))
+	if name == "" {
+		return "", fmt.Errorf("title is required")
+	}
+	rel := filepath.ToSlash(filepath.Join(folder, name+".md"))
+	if _, err := v.abs(rel); err != nil {
+		return "", err
+	}
+	return rel, nil
+}
+
+// abs resolves a vault-relative path, refusing paths that leave the vault
+func (v *Vault) abs(rel string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path is outside the notes vault: %s", rel)
+	}
+	return filepath.Join(v.dir, clean), nil
+}
+
+// ParseNote reads a note's frontmatter, tags and wikilinks. Tags come from
+// the frontmatter, inline #tags and the "Tags:" line older notes start with.
+func ParseNote(rel, content string) *Note {
+	note := &Note{
+		Path:  rel,
+		Title: strings.TrimSuffix(filepath.Base(filepath.FromSlash(rel)), filepath.Ext(rel)),
+	}
+
+	body := content
+	if front, rest, ok := splitFrontmatter(content); ok {
+		if err := yaml.Unmarshal([]byte(front), &note.Frontmatter); err == nil {
+			body = rest
+		}
+	}
+	note.Body = body
+
+	if title, ok := note.Frontmatter["title"].(string); ok && strings.TrimSpace(title) != "" {
+		note.Title = strings.TrimSpace(title)
+	}
+	note.Aliases = stringList(note.Frontmatter["aliases"], ",")
+
+	var tags []string
+	tags = append(tags, stringList(note.Frontmatter["tags"], ", ")...)
+	if note.Frontmatter == nil {
+		tags = append(tags, legacyTags(body)...)
+	}
+	for _, line := range strings.Split(stripCode(body), "\n") {
+		for _, m := range tagPattern.FindAllStringSubmatch(line, -1) {
+			tags = append(tags, m[1])
+		}
+	}
+	note.Tags = dedupe(tags, func(t string) string { return strings.ToLower(strings.TrimPrefix(t, "#")) })
+
+	var links []string
+	for _, m := range wikiLinkPattern.FindAllStringSubmatch(body, -1) {
+		if target := strings.TrimSpace(m[1]); target != "" {
+			links = append(links, target)
+		}
+	}
+	note.Links = dedupe(links, linkKey)
+	return note
+}
+
+// HasTag reports whether the note is tagged tag, or with a nested tag under
+// it, e.g. project/myrai under project
+func (n *Note) HasTag(tag string) bool {
+	tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+	for _, t := range n.Tags {
+		t = strings.ToLower(t)
+		if t == tag || strings.HasPrefix(t, tag+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Names are the keys links to this note can use: its path, file name, title
+// and aliases
+func (n *Note) Names() []string {
+	names := []string{
+		linkKey(n.Path),
+		linkKey(filepath.Base(n.Path)),
+		linkKey(n.Title),
+	}
+	for _, alias := range n.Aliases {
+		names = append(names, linkKey(alias))
+	}
+	return names
+}
+
+// linkKey normalizes a link target or note name for matching
+func linkKey(name string) string {
+	name = strings.TrimSpace(strings.ToLower(filepath.ToSlash(name)))
+	return strings.TrimSuffix(name, ".md")
+}
+
+// splitFrontmatter separates a leading YAML block fenced by --- lines
+func splitFrontmatter(content string) (front, body string, ok bool) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	if !strings.HasPrefix(content, "---\n") && !strings.HasPrefix(content, "---\r\n") {
+		return "", content, false
+	}
+	rest := content[strings.Index(content, "\n")+1:]
+	for offset := 0; offset < len(rest); {
+		end := strings.Index(rest[offset:], "\n")
+		line := rest[offset:]
+		if end >= 0 {
+			line = rest[offset : offset+end]
+		}
+		if strings.TrimRight(line, "\r") == "---" {
+			body := ""
+			if end >= 0 {
+				body = rest[offset+end+1:]
+			}
+			return rest[:offset], strings.TrimLeft(body, "\r\n"), true
+		}
+		if end < 0 {
+			break
+		}
+		offset += end + 1
+	}
+	return "", content, false
+}
+
+// legacyTags reads the "Tags: a, b" line notes created before vault support
+// carry in their header
+func legacyTags(body string) []string {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "---" {
+			break
+		}
+		if rest, ok := strings.CutPrefix(line, "Tags: "); ok {
+			var tags []string
+			for _, t := range strings.Split(rest, ",") {
+				if t = strings.TrimSpace(t); t != "" {
+					tags = append(tags, t)
+				}
+			}
+			return tags
+		}
+	}
+	return nil
+}
+
+// stripCode blanks out fenced code blocks so #include and the like aren't
+// read as tags
+func stripCode(body string) string {
+	var out []string
+	inCode := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if !inCode {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// stringList reads a frontmatter value written as a list or as a string
+// separated by any of seps
+func stringList(v interface{}, seps string) []string {
+	var out []string
+	switch val := v.(type) {
+	case string:
+		for _, s := range strings.FieldsFunc(val, func(r rune) bool { return strings.ContainsRune(seps, r) }) {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, strings.TrimPrefix(s, "#"))
+			}
+		}
+	case []interface{}:
+		for _, item := range val {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				out = append(out, strings.TrimPrefix(strings.TrimSpace(s), "#"))
+			}
+		}
+	}
+	return out
+}
+
+func dedupe(items []string, key func(string) string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, item := range items {
+		k := key(item)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, item)
+	}
+	return out
+}
+
+func isNote(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".md") && !hidden(name)
+}
+
+func hidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
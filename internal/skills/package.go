@@ -0,0 +1,516 @@
+package skills
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/circuitbreaker"
+	"go.uber.org/zap"
+)
+
+// A skill package is a directory, usually a git repository, holding a
+// SKILL.md manifest with the skill's prompt and tool definitions, and
+// optionally the programs its tools run. Installed packages live in their
+// own directory under the skills directory and are loaded with the other
+// skills when the agent starts.
+
+const (
+	// PackageRecordFile records where an installed package came from
+	PackageRecordFile = ".myrai-package.json"
+
+	// PackageSignatureFile holds the base64 ed25519 signature of a
+	// package's digest
+	PackageSignatureFile = "SKILL.sig"
+
+	// DefaultRegistryURL is the index skill names are looked up in
+	DefaultRegistryURL = "https://raw.githubusercontent.com/myrai-agents/skills/main/index.json"
+
+	// packageToolTimeout bounds a single run of a package's tool command
+	packageToolTimeout = 2 * time.Minute
+)
+
+// Version is the running Myrai version, checked against the
+// min_myrai_version of skills. cmd/myrai sets it from the build; dev builds
+// install any skill.
+var Version = "dev"
+
+// PackageRecord describes an installed skill package
+type PackageRecord struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Source is what the package was installed from, a registry name or a
+	// git URL, and is fetched again by update
+	Source      string    `json:"source"`
+	URL         string    `json:"url"`
+	Ref         string    `json:"ref,omitempty"`
+	Digest      string    `json:"digest"`
+	Signed      bool      `json:"signed"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// RegistryEntry is a skill listed in the registry index
+type RegistryEntry struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Git         string `json:"git"`
+	Ref         string `json:"ref,omitempty"`
+	SHA256      string `json:"sha256,omitempty"`
+}
+
+// PackageInstaller installs skill packages from git repositories or the
+// skill registry
+type PackageInstaller struct {
+	skillsDir   string
+	registryURL string
+	trustedKeys []string
+	client      *circuitbreaker.HTTPClient
+	logger      *zap.Logger
+	fetch       func(ctx context.Context, url, ref, dir string) error
+}
+
+// NewPackageInstaller creates an installer placing packages in skillsDir.
+// With trusted keys, only packages signed by one of them are installed.
+func NewPackageInstaller(skillsDir, registryURL string, trustedKeys []string, logger *zap.Logger) *PackageInstaller {
+	if registryURL == "" {
+		registryURL = DefaultRegistryURL
+	}
+	return &PackageInstaller{
+		skillsDir:   skillsDir,
+		registryURL: registryURL,
+		trustedKeys: trustedKeys,
+		client:      circuitbreaker.NewHTTPClient("skill-registry", 30*time.Second, logger),
+		logger:      logger,
+		fetch:       gitClone,
+	}
+}
+
+// DefaultPackagesDir returns where packages are installed unless
+// configured otherwise
+func DefaultPackagesDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".myrai", "skills")
+}
+
+// Install fetches a package, verifies it and places it in the skills
+// directory, replacing an installed version. source is a registry name
+// (docker-helper, docker-helper@1.2.0) or a git URL (github.com/user/repo,
+// user/repo@v1.2.0, https://host/repo.git, git@host:repo.git). digest, if
+// set, is the SHA-256 the package must have.
+func (pi *PackageInstaller) Install(ctx context.Context, source, digest string) (*PackageRecord, error) {
+	url, ref, want, err := pi.resolve(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	if digest != "" {
+		want = digest
+	}
+
+	tmp, err := os.MkdirTemp("", "myrai-skill-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	checkout := filepath.Join(tmp, "package")
+	pi.logger.Info("Fetching skill package", zap.String("url", url), zap.String("ref", ref))
+	if err := pi.fetch(ctx, url, ref, checkout); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	if err := os.RemoveAll(filepath.Join(checkout, ".git")); err != nil {
+		return nil, err
+	}
+
+	return pi.place(checkout, &PackageRecord{Source: source, URL: url, Ref: ref}, want)
+}
+
+// Update installs the latest version of a package from where it was
+// installed from. A version pinned in its source stays pinned.
+func (pi *PackageInstaller) Update(ctx context.Context, name string) (*PackageRecord, error) {
+	record, err := pi.Record(name)
+	if err != nil {
+		return nil, err
+	}
+	return pi.Install(ctx, record.Source, "")
+}
+
+// Remove deletes an installed package
+func (pi *PackageInstaller) Remove(name string) error {
+	record, err := pi.Record(name)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(pi.skillsDir, record.Name)); err != nil {
+		return fmt.Errorf("failed to remove skill '%s': %w", name, err)
+	}
+	pi.logger.Info("Skill package removed", zap.String("name", record.Name))
+	return nil
+}
+
+// Record returns how an installed package was installed
+func (pi *PackageInstaller) Record(name string) (*PackageRecord, error) {
+	if !isPackageName(name) {
+		return nil, fmt.Errorf("invalid skill name '%s'", name)
+	}
+	data, err := os.ReadFile(filepath.Join(pi.skillsDir, name, PackageRecordFile))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("skill '%s' is not an installed package", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var record PackageRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("invalid install record of skill '%s': %w", name, err)
+	}
+	return &record, nil
+}
+
+// Installed returns the installed packages by name
+func (pi *PackageInstaller) Installed() ([]*PackageRecord, error) {
+	entries, err := os.ReadDir(pi.skillsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*PackageRecord
+	for _, entry := range entries {
+		if !entry.IsDir() || !isPackageName(entry.Name()) {
+			continue
+		}
+		if record, err := pi.Record(entry.Name()); err == nil {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// Lookup finds a skill in the registry index
+func (pi *PackageInstaller) Lookup(ctx context.Context, name string) (*RegistryEntry, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pi.registryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "myrai-skill-installer")
+
+	resp, err := pi.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch skill registry: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("skill registry error (status %d)", resp.StatusCode)
+	}
+
+	var index struct {
+		Skills []RegistryEntry `json:"skills"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		return nil, fmt.Errorf("invalid skill registry: %w", err)
+	}
+	for i := range index.Skills {
+		if strings.EqualFold(index.Skills[i].Name, name) {
+			return &index.Skills[i], nil
+		}
+	}
+	return nil, fmt.Errorf("skill '%s' not found in the registry", name)
+}
+
+// resolve turns an install source into the git URL and ref to fetch, and
+// the digest the registry lists for it
+func (pi *PackageInstaller) resolve(ctx context.Context, source string) (url, ref, digest string, err error) {
+	source = strings.TrimSpace(source)
+	base, ref := source, ""
+	// The version follows the last '@', unless that's git@host
+	if idx := strings.LastIndex(source, "@"); idx > 0 && !strings.Contains(source[idx:], "/") && !strings.Contains(source[idx:], ":") {
+		base, ref = source[:idx], source[idx+1:]
+	}
+
+	switch {
+	case strings.Contains(base, "://"), strings.HasPrefix(base, "git@"):
+		return base, ref, "", nil
+	case strings.HasPrefix(base, "github.com/"):
+		return "https://" + strings.TrimSuffix(base, ".git") + ".git", ref, "", nil
+	case strings.Count(base, "/") == 1:
+		return "https://github.com/" + strings.TrimSuffix(base, ".git") + ".git", ref, "", nil
+	case isPackageName(base):
+		entry, err := pi.Lookup(ctx, base)
+		if err != nil {
+			return "", "", "", err
+		}
+		if entry.Git == "" {
+			return "", "", "", fmt.Errorf("registry lists no git URL for skill '%s'", base)
+		}
+		if ref == "" {
+			ref = entry.Ref
+		} else if entry.Version != "" && strings.TrimPrefix(ref, "v") != strings.TrimPrefix(entry.Version, "v") {
+			// Only the listed version has a known digest
+			return entry.Git, ref, "", nil
+		}
+		return entry.Git, ref, entry.SHA256, nil
+	}
+	return "", "", "", fmt.Errorf("don't know how to install '%s'; use a skill name or a git URL", source)
+}
+
+// place verifies a fetched package and moves it into the skills directory
+func (pi *PackageInstaller) place(dir string, record *PackageRecord, digest string) (*PackageRecord, error) {
+	content, err := os.ReadFile(filepath.Join(dir, "SKILL.md"))
+	if err != nil {
+		return nil, fmt.Errorf("package has no SKILL.md at its root")
+	}
+	manifest, _, err := ParseSkillMarkdown(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SKILL.md: %w", err)
+	}
+	if err := manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid SKILL.md: %w", err)
+	}
+	if !isPackageName(manifest.Name) {
+		return nil, fmt.Errorf("invalid skill name '%s'", manifest.Name)
+	}
+	if !isVersionCompatible(Version, manifest.MinMyraiVersion) {
+		return nil, fmt.Errorf("skill requires Myrai version %s or higher, current version is %s",
+			manifest.MinMyraiVersion, Version)
+	}
+
+	record.Digest, record.Signed, err = VerifyPackage(dir, digest, pi.trustedKeys)
+	if err != nil {
+		return nil, err
+	}
+	record.Name = manifest.Name
+	record.Version = manifest.Version
+	record.InstalledAt = time.Now()
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, PackageRecordFile), data, 0644); err != nil {
+		return nil, err
+	}
+
+	// Copy next to the installed version, then swap them
+	if err := os.MkdirAll(pi.skillsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create skills directory: %w", err)
+	}
+	final := filepath.Join(pi.skillsDir, manifest.Name)
+	staging := filepath.Join(pi.skillsDir, "."+manifest.Name+".new")
+	old := filepath.Join(pi.skillsDir, "."+manifest.Name+".old")
+	os.RemoveAll(staging)
+	os.RemoveAll(old)
+	if err := copyDir(dir, staging); err != nil {
+		os.RemoveAll(staging)
+		return nil, fmt.Errorf("failed to install skill files: %w", err)
+	}
+	if _, err := os.Stat(final); err == nil {
+		if err := os.Rename(final, old); err != nil {
+			os.RemoveAll(staging)
+			return nil, fmt.Errorf("failed to replace installed version: %w", err)
+		}
+	}
+	if err := os.Rename(staging, final); err != nil {
+		os.Rename(old, final)
+		return nil, fmt.Errorf("failed to install skill files: %w", err)
+	}
+	os.RemoveAll(old)
+
+	pi.logger.Info("Skill package installed",
+		zap.String("name", record.Name),
+		zap.String("version", record.Version),
+		zap.Bool("signed", record.Signed))
+	return record, nil
+}
+
+// PackageDigest returns the SHA-256 of a package's files and their paths,
+// leaving out its signature and install record. Links and other special
+// files aren't allowed in a package.
+func PackageDigest(dir string) (string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.IsDir() && info.Name() == ".git":
+			return filepath.SkipDir
+		case info.IsDir():
+			return nil
+		case !info.Mode().IsRegular():
+			return fmt.Errorf("package contains a link or special file: %s", rel)
+		case rel == PackageRecordFile || rel == PackageSignatureFile:
+			return nil
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, rel := range files {
+		f, err := os.Open(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return "", err
+		}
+		fh := sha256.New()
+		_, err = io.Copy(fh, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%x\n", rel, fh.Sum(nil))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyPackage checks a package against the digest it must have, if any,
+// and its signature. With trusted keys, the package must be signed by one
+// of them; without, a signature can't be checked and is ignored.
+func VerifyPackage(dir, digest string, trustedKeys []string) (string, bool, error) {
+	got, err := PackageDigest(dir)
+	if err != nil {
+		return "", false, err
+	}
+	if want := strings.ToLower(strings.TrimPrefix(digest, "sha256:")); want != "" && want != got {
+		return "", false, fmt.Errorf("checksum mismatch: expected %s, got %s", want, got)
+	}
+	if len(trustedKeys) == 0 {
+		return got, false, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, PackageSignatureFile))
+	if os.IsNotExist(err) {
+		return "", false, fmt.Errorf("package isn't signed; only signed packages can be installed")
+	}
+	if err != nil {
+		return "", false, err
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return "", false, fmt.Errorf("invalid %s: %w", PackageSignatureFile, err)
+	}
+	for _, key := range trustedKeys {
+		pub, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+		if err != nil || len(pub) != ed25519.PublicKeySize {
+			return "", false, fmt.Errorf("invalid trusted key %q", key)
+		}
+		if ed25519.Verify(pub, []byte(got), sig) {
+			return got, true, nil
+		}
+	}
+	return "", false, fmt.Errorf("package isn't signed by a trusted key")
+}
+
+// LoadPackages loads the skills of the packages installed in dir
+func LoadPackages(dir string, logger *zap.Logger) []*RuntimeSkill {
+	records, err := NewPackageInstaller(dir, "", nil, logger).Installed()
+	if err != nil {
+		logger.Warn("Failed to read installed skills", zap.Error(err))
+		return nil
+	}
+
+	loader := NewSkillLoader(nil, dir)
+	var loaded []*RuntimeSkill
+	for _, record := range records {
+		skill, err := loader.LoadSkill(filepath.Join(dir, record.Name, "SKILL.md"))
+		if err != nil {
+			logger.Warn("Failed to load installed skill", zap.String("skill", record.Name), zap.Error(err))
+			continue
+		}
+		skill.Source = SourcePackage
+		skill.SourceURL = record.Source
+		loaded = append(loaded, skill)
+	}
+	return loaded
+}
+
+// ToRegistrySkill adapts a runtime skill to the Skill the agent's
+// registry takes
+func (s *RuntimeSkill) ToRegistrySkill() Skill {
+	return &packageSkill{skill: s}
+}
+
+// packageSkill implements Skill for a runtime skill
+type packageSkill struct {
+	skill *RuntimeSkill
+}
+
+func (p *packageSkill) Name() string        { return p.skill.Manifest.Name }
+func (p *packageSkill) Description() string { return p.skill.Manifest.Description }
+func (p *packageSkill) Version() string     { return p.skill.Manifest.Version }
+func (p *packageSkill) Tools() []Tool       { return p.skill.Tools }
+func (p *packageSkill) IsEnabled() bool     { return p.skill.IsEnabled() }
+func (p *packageSkill) Enable() error       { return p.skill.Enable() }
+func (p *packageSkill) Disable() error      { return p.skill.Disable() }
+
+// commandToolHandler runs a package's program for a tool, passing the
+// arguments as JSON on stdin. JSON output is returned decoded.
+func commandToolHandler(dir string, tool ManifestTool) ToolHandler {
+	path := filepath.Join(dir, filepath.FromSlash(tool.Command))
+	return func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		input, err := json.Marshal(args)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, packageToolTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, path)
+		cmd.Dir = dir
+		cmd.Stdin = bytes.NewReader(input)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("%s failed: %w: %s", tool.Name, err, strings.TrimSpace(stderr.String()))
+		}
+		var result interface{}
+		if json.Unmarshal(out, &result) == nil {
+			return result, nil
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+}
+
+// gitClone makes a shallow clone of url at ref, a branch or tag, into dir
+func gitClone(ctx context.Context, url, ref, dir string) error {
+	args := []string{"clone", "--depth", "1", "--quiet"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	cmd := exec.CommandContext(ctx, "git", append(args, "--", url, dir)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// isPackageName reports whether name can name a skill's directory
+func isPackageName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && filepath.IsLocal(name) &&
+		!strings.ContainsAny(name, `/\:@`)
+}
@@ -0,0 +1,222 @@
+package skills
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+const testSkillMD = `---
+name: greeter
+version: %s
+description: Greets people
+tools:
+  - name: greet
+    description: Greet someone
+    command: bin/greet
+    parameters:
+      - name: who
+        type: string
+        description: Who to greet
+---
+Greet people warmly.
+`
+
+// writeTestPackage writes a package with a greet tool at the given version
+func writeTestPackage(t *testing.T, dir, version string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(fmt.Sprintf(testSkillMD, version)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\ncat >/dev/null\necho '{\"greeting\": \"hello\"}'\n"
+	if err := os.WriteFile(filepath.Join(dir, "bin", "greet"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestInstaller(t *testing.T, registryURL string, packages map[string]string) *PackageInstaller {
+	t.Helper()
+	pi := NewPackageInstaller(t.TempDir(), registryURL, nil, zap.NewNop())
+	pi.fetch = func(ctx context.Context, url, ref, dir string) error {
+		src, ok := packages[url+"@"+ref]
+		if !ok {
+			return fmt.Errorf("no package at %s@%s", url, ref)
+		}
+		return copyDir(src, dir)
+	}
+	return pi
+}
+
+func TestPackageDigest(t *testing.T) {
+	dir := t.TempDir()
+	writeTestPackage(t, dir, "1.0.0")
+
+	digest, err := PackageDigest(dir)
+	if err != nil {
+		t.Fatalf("Failed to digest package: %v", err)
+	}
+
+	// The install record and signature aren't part of the digest
+	os.WriteFile(filepath.Join(dir, PackageRecordFile), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(dir, PackageSignatureFile), []byte("sig"), 0644)
+	if again, _ := PackageDigest(dir); again != digest {
+		t.Error("Expected the digest to ignore the record and signature")
+	}
+
+	os.WriteFile(filepath.Join(dir, "bin", "greet"), []byte("#!/bin/sh\nrm -rf ~\n"), 0755)
+	if changed, _ := PackageDigest(dir); changed == digest {
+		t.Error("Expected a changed file to change the digest")
+	}
+}
+
+func TestVerifyPackageSignature(t *testing.T) {
+	dir := t.TempDir()
+	writeTestPackage(t, dir, "1.0.0")
+	digest, _ := PackageDigest(dir)
+
+	if _, _, err := VerifyPackage(dir, "sha256:"+digest, nil); err != nil {
+		t.Errorf("Expected the digest to match: %v", err)
+	}
+	if _, _, err := VerifyPackage(dir, "deadbeef", nil); err == nil {
+		t.Error("Expected a checksum mismatch")
+	}
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	trusted := []string{base64.StdEncoding.EncodeToString(pub)}
+
+	if _, _, err := VerifyPackage(dir, "", trusted); err == nil {
+		t.Error("Expected an unsigned package to be refused")
+	}
+
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(digest)))
+	os.WriteFile(filepath.Join(dir, PackageSignatureFile), []byte(sig), 0644)
+	if _, signed, err := VerifyPackage(dir, "", trusted); err != nil || !signed {
+		t.Errorf("Expected a valid signature, got %v, %v", signed, err)
+	}
+	if _, _, err := VerifyPackage(dir, "", []string{base64.StdEncoding.EncodeToString(otherPub)}); err == nil {
+		t.Error("Expected a signature by an untrusted key to be refused")
+	}
+}
+
+func TestInstallUpdateRemovePackage(t *testing.T) {
+	v1, v2 := t.TempDir(), t.TempDir()
+	writeTestPackage(t, v1, "1.0.0")
+	writeTestPackage(t, v2, "1.1.0")
+	digest1, _ := PackageDigest(v1)
+	digest2, _ := PackageDigest(v2)
+
+	latest := "v1.0.0"
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		digest := digest1
+		if latest == "v1.1.0" {
+			digest = digest2
+		}
+		fmt.Fprintf(w, `{"skills": [{"name": "greeter", "version": "%s", "git": "https://example.com/greeter.git", "ref": "%s", "sha256": "%s"}]}`,
+			latest[1:], latest, digest)
+	}))
+	defer registry.Close()
+
+	pi := newTestInstaller(t, registry.URL, map[string]string{
+		"https://example.com/greeter.git@v1.0.0":  v1,
+		"https://example.com/greeter.git@v1.1.0":  v2,
+		"https://github.com/someone/greeter.git@": v2,
+	})
+	ctx := context.Background()
+
+	record, err := pi.Install(ctx, "greeter", "")
+	if err != nil {
+		t.Fatalf("Failed to install from the registry: %v", err)
+	}
+	if record.Name != "greeter" || record.Version != "1.0.0" || record.Digest != digest1 {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+
+	loaded := LoadPackages(pi.skillsDir, zap.NewNop())
+	if len(loaded) != 1 || loaded[0].Source != SourcePackage || len(loaded[0].Tools) != 1 {
+		t.Fatalf("Expected the installed skill to load, got %+v", loaded)
+	}
+	if runtime.GOOS != "windows" {
+		result, err := loaded[0].Tools[0].Handler(ctx, map[string]interface{}{"who": "Ana"})
+		if err != nil {
+			t.Fatalf("Failed to run the tool command: %v", err)
+		}
+		if out, ok := result.(map[string]interface{}); !ok || out["greeting"] != "hello" {
+			t.Errorf("Unexpected tool result: %v", result)
+		}
+	}
+
+	latest = "v1.1.0"
+	if record, err := pi.Update(ctx, "greeter"); err != nil || record.Version != "1.1.0" {
+		t.Fatalf("Failed to update: %+v, %v", record, err)
+	}
+
+	if _, err := pi.Install(ctx, "greeter", "deadbeef"); err == nil {
+		t.Error("Expected a pinned checksum mismatch to fail")
+	}
+	if record, _ := pi.Record("greeter"); record == nil || record.Version != "1.1.0" {
+		t.Error("Expected a failed install to keep the installed version")
+	}
+
+	if record, err := pi.Install(ctx, "github.com/someone/greeter", ""); err != nil || record.URL != "https://github.com/someone/greeter.git" {
+		t.Fatalf("Failed to install from a git URL: %+v, %v", record, err)
+	}
+
+	if err := pi.Remove("greeter"); err != nil {
+		t.Fatalf("Failed to remove: %v", err)
+	}
+	if records, _ := pi.Installed(); len(records) != 0 {
+		t.Errorf("Expected no packages, got %d", len(records))
+	}
+	if err := pi.Remove("greeter"); err == nil {
+		t.Error("Expected an error removing a missing package")
+	}
+}
+
+func TestResolvePackageSource(t *testing.T) {
+	pi := NewPackageInstaller(t.TempDir(), "", nil, zap.NewNop())
+	tests := []struct {
+		source, url, ref string
+	}{
+		{"user/repo", "https://github.com/user/repo.git", ""},
+		{"github.com/user/repo@v1.2.0", "https://github.com/user/repo.git", "v1.2.0"},
+		{"https://git.example.com/me/skill.git@main", "https://git.example.com/me/skill.git", "main"},
+		{"git@github.com:user/repo.git", "git@github.com:user/repo.git", ""},
+	}
+	for _, tt := range tests {
+		url, ref, _, err := pi.resolve(context.Background(), tt.source)
+		if err != nil || url != tt.url || ref != tt.ref {
+			t.Errorf("resolve(%q) = %q, %q, %v; want %q, %q", tt.source, url, ref, err, tt.url, tt.ref)
+		}
+	}
+}
+
+func TestIsVersionCompatible(t *testing.T) {
+	tests := []struct {
+		current, min string
+		want         bool
+	}{
+		{"v2.1.0", "", true},
+		{"v2.1.0", "2.0.0", true},
+		{"2.1.0", "2.1.0", true},
+		{"v2.1.0", "2.2.0", false},
+		{"dev", "9.0.0", true},
+	}
+	for _, tt := range tests {
+		if got := isVersionCompatible(tt.current, tt.min); got != tt.want {
+			t.Errorf("isVersionCompatible(%q, %q) = %v, expected %v", tt.current, tt.min, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,405 @@
+package places
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/gmsas95/myrai-cli/internal/location"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"gorm.io/gorm"
+)
+
+// PlacesSkill lets users share their location, name the places they go and
+// set reminders for when they arrive or leave
+type PlacesSkill struct {
+	*skills.BaseSkill
+	tracker *location.Tracker
+}
+
+// NewPlacesSkill creates a new places skill
+func NewPlacesSkill(db *gorm.DB, opts location.Options) (*PlacesSkill, error) {
+	tracker, err := location.NewTracker(db, opts, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &PlacesSkill{
+		BaseSkill: skills.NewBaseSkill("places", "Location sharing, saved places and arrival reminders", "1.0.0"),
+		tracker:   tracker,
+	}
+	s.registerTools()
+	return s, nil
+}
+
+func (s *PlacesSkill) registerTools() {
+	s.AddTool(skills.Tool{
+		Name: "location_sharing",
+		Description: "Turn location sharing from the user's phone on or off, or check whether it is on. " +
+			"Nothing is stored until the user turns it on; turning it off deletes their location history.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"enabled": map[string]interface{}{
+					"type":        "boolean",
+					"description": "true to share, false to stop; leave out to check",
+				},
+				"retention_days": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Days to keep check-ins (1-%d)", location.MaxRetentionDays),
+				},
+			},
+		},
+		Handler: s.handleLocationSharing,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "where_am_i",
+		ReadOnly:    true,
+		Description: "Get where the user last checked in from their phone, and which saved place that is",
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		},
+		Handler: s.handleWhereAmI,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "save_place",
+		Description: "Save a named place such as home or work. Without coordinates, saves where the user is now.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Place name, e.g. home, work, gym",
+				},
+				"latitude": map[string]interface{}{
+					"type":        "number",
+					"description": "Latitude (default: current location)",
+				},
+				"longitude": map[string]interface{}{
+					"type":        "number",
+					"description": "Longitude (default: current location)",
+				},
+				"radius_meters": map[string]interface{}{
+					"type":        "number",
+					"description": fmt.Sprintf("How close counts as being there (default: %.0f)", location.DefaultRadius),
+				},
+			},
+			"required": []string{"name"},
+		},
+		Handler: s.handleSavePlace,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "list_places",
+		ReadOnly:    true,
+		Description: "List saved places and pending place reminders",
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		},
+		Handler: s.handleListPlaces,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "delete_place",
+		Description: "Delete a saved place and its pending reminders",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Place name",
+				},
+			},
+			"required": []string{"name"},
+		},
+		Handler: s.handleDeletePlace,
+	})
+
+	s.AddTool(skills.Tool{
+		Name: "remind_at_place",
+		Description: "Remind the user when they arrive at or leave a saved place. " +
+			"Use for requests like 'remind me to take the bins out when I get home'.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"place": map[string]interface{}{
+					"type":        "string",
+					"description": "Saved place name, e.g. home",
+				},
+				"message": map[string]interface{}{
+					"type":        "string",
+					"description": "What to remind the user of",
+				},
+				"when": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{location.TriggerArrive, location.TriggerLeave},
+					"description": "Remind on arriving or leaving (default: arrive)",
+				},
+			},
+			"required": []string{"place", "message"},
+		},
+		Handler: s.handleRemindAtPlace,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "cancel_place_reminder",
+		Description: "Cancel a pending place reminder",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"reminder_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Reminder ID",
+				},
+			},
+			"required": []string{"reminder_id"},
+		},
+		Handler: s.handleCancelPlaceReminder,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "forget_location",
+		Description: "Delete the user's location history, and optionally their saved places and place reminders",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"everything": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also delete saved places and reminders",
+				},
+				"confirm": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Must be true to delete",
+				},
+			},
+		},
+		Handler: s.handleForgetLocation,
+	})
+}
+
+func (s *PlacesSkill) handleLocationSharing(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := getUserID(ctx)
+	retention := 0
+	if r, ok := args["retention_days"].(float64); ok {
+		retention = int(r)
+		if retention < 1 {
+			return nil, fmt.Errorf("retention_days must be between 1 and %d", location.MaxRetentionDays)
+		}
+	}
+
+	enabled, set := args["enabled"].(bool)
+	switch {
+	case set && enabled:
+		settings, err := s.tracker.EnableSharing(userID, retention)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"sharing":        true,
+			"retention_days": settings.RetentionDays,
+			"message": fmt.Sprintf("Location sharing is on. Check-ins are kept for %d days. "+
+				"Point OwnTracks or a Shortcut at POST /api/location.", settings.RetentionDays),
+		}, nil
+	case set:
+		deleted, err := s.tracker.DisableSharing(userID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"sharing": false,
+			"deleted": deleted,
+			"message": fmt.Sprintf("Location sharing is off and %d check-ins were deleted", deleted),
+		}, nil
+	}
+
+	settings, err := s.tracker.Settings(userID)
+	if err != nil {
+		return nil, err
+	}
+	if retention > 0 && settings.Enabled {
+		if settings, err = s.tracker.EnableSharing(userID, retention); err != nil {
+			return nil, err
+		}
+	}
+	return map[string]interface{}{
+		"sharing":        settings.Enabled,
+		"retention_days": settings.RetentionDays,
+	}, nil
+}
+
+func (s *PlacesSkill) handleWhereAmI(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := getUserID(ctx)
+	if !s.tracker.Sharing(userID) {
+		return map[string]interface{}{
+			"sharing": false,
+			"message": "Location sharing is off",
+		}, nil
+	}
+	fix, err := s.tracker.Current(userID)
+	if err != nil {
+		return nil, err
+	}
+	if fix == nil {
+		return map[string]interface{}{
+			"sharing": true,
+			"known":   false,
+			"message": fmt.Sprintf("No check-in in the last %.0f hours", location.MaxAge.Hours()),
+		}, nil
+	}
+
+	l := locale.FromContext(ctx)
+	result := map[string]interface{}{
+		"sharing":     true,
+		"known":       true,
+		"latitude":    fix.Latitude,
+		"longitude":   fix.Longitude,
+		"checked_in":  l.DateTime(fix.RecordedAt),
+		"minutes_ago": int(math.Round(time.Since(fix.RecordedAt).Minutes())),
+	}
+	if fix.Accuracy > 0 {
+		result["accuracy_meters"] = fix.Accuracy
+	}
+	if fix.Place != "" {
+		result["place"] = fix.Place
+	}
+	return result, nil
+}
+
+func (s *PlacesSkill) handleSavePlace(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := getUserID(ctx)
+	name, _ := args["name"].(string)
+	radius, _ := args["radius_meters"].(float64)
+
+	lat, hasLat := args["latitude"].(float64)
+	lon, hasLon := args["longitude"].(float64)
+	source := "given coordinates"
+	if hasLat != hasLon {
+		return nil, fmt.Errorf("give both latitude and longitude, or neither to use the current location")
+	}
+	if !hasLat {
+		fix, err := s.tracker.Current(userID)
+		if err != nil {
+			return nil, err
+		}
+		if fix == nil {
+			return nil, fmt.Errorf("current location is unknown: turn on location sharing and check in, or give coordinates")
+		}
+		lat, lon = fix.Latitude, fix.Longitude
+		source = "current location"
+	}
+
+	place, err := s.tracker.SetPlace(userID, name, lat, lon, radius)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"saved":   true,
+		"place":   place,
+		"message": fmt.Sprintf("Saved %s (%.0f m around your %s)", place.Name, place.Radius, source),
+	}, nil
+}
+
+func (s *PlacesSkill) handleListPlaces(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := getUserID(ctx)
+	places, err := s.tracker.Places(userID)
+	if err != nil {
+		return nil, err
+	}
+	reminders, err := s.tracker.Reminders(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]map[string]interface{}, 0, len(reminders))
+	for _, r := range reminders {
+		pending = append(pending, map[string]interface{}{
+			"id":      r.ID,
+			"message": r.Message,
+			"when":    r.Describe(),
+		})
+	}
+	return map[string]interface{}{
+		"sharing":   s.tracker.Sharing(userID),
+		"places":    places,
+		"reminders": pending,
+	}, nil
+}
+
+func (s *PlacesSkill) handleDeletePlace(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	name, _ := args["name"].(string)
+	if err := s.tracker.DeletePlace(getUserID(ctx), name); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"deleted": true,
+		"message": fmt.Sprintf("Deleted %s", name),
+	}, nil
+}
+
+func (s *PlacesSkill) handleRemindAtPlace(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := getUserID(ctx)
+	place, _ := args["place"].(string)
+	message, _ := args["message"].(string)
+	when, _ := args["when"].(string)
+
+	if !s.tracker.Sharing(userID) {
+		return nil, fmt.Errorf("location sharing is off: turn it on so the reminder can fire")
+	}
+	reminder, err := s.tracker.AddReminder(userID, place, when, message)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"created":     true,
+		"reminder_id": reminder.ID,
+		"message":     fmt.Sprintf("I'll remind you %s: %s", reminder.Describe(), reminder.Message),
+	}, nil
+}
+
+func (s *PlacesSkill) handleCancelPlaceReminder(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	id, _ := args["reminder_id"].(string)
+	if err := s.tracker.CancelReminder(getUserID(ctx), id); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"cancelled": true,
+		"message":   "Reminder cancelled",
+	}, nil
+}
+
+func (s *PlacesSkill) handleForgetLocation(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	everything, _ := args["everything"].(bool)
+	if confirm, _ := args["confirm"].(bool); !confirm {
+		what := "location history"
+		if everything {
+			what = "location history, saved places and place reminders"
+		}
+		return map[string]interface{}{
+			"confirm_required": true,
+			"message":          fmt.Sprintf("Set confirm=true to delete your %s", what),
+		}, nil
+	}
+
+	deleted, err := s.tracker.Purge(getUserID(ctx), everything)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"deleted": deleted,
+		"message": fmt.Sprintf("Deleted %d records", deleted),
+	}, nil
+}
+
+func getUserID(ctx context.Context) string {
+	if userID, ok := ctx.Value("user_id").(string); ok && userID != "" {
+		return userID
+	}
+	return household.SharedUserID
+}
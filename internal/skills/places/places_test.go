@@ -0,0 +1,90 @@
+package places
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/location"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupPlacesSkill(t *testing.T) *PlacesSkill {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	skill, err := NewPlacesSkill(db, location.Options{})
+	require.NoError(t, err)
+	return skill
+}
+
+func TestPlacesSkill_RemindWhenHome(t *testing.T) {
+	skill := setupPlacesSkill(t)
+	ctx := context.WithValue(context.Background(), "user_id", "user1")
+
+	_, err := skill.handleSavePlace(ctx, map[string]interface{}{"name": "home"})
+	assert.ErrorContains(t, err, "current location is unknown")
+
+	result, err := skill.handleWhereAmI(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, false, result.(map[string]interface{})["sharing"])
+
+	result, err = skill.handleLocationSharing(ctx, map[string]interface{}{"enabled": true, "retention_days": float64(3)})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.(map[string]interface{})["retention_days"])
+
+	_, err = skill.tracker.CheckIn(ctx, "user1", location.Fix{Latitude: 40.7128, Longitude: -74.0060})
+	require.NoError(t, err)
+	_, err = skill.handleSavePlace(ctx, map[string]interface{}{"name": "home"})
+	require.NoError(t, err)
+
+	result, err = skill.handleWhereAmI(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "home", result.(map[string]interface{})["place"])
+
+	result, err = skill.handleRemindAtPlace(ctx, map[string]interface{}{"place": "Home", "message": "Call mum"})
+	require.NoError(t, err)
+	assert.Equal(t, "I'll remind you when you get home: Call mum", result.(map[string]interface{})["message"])
+
+	result, err = skill.handleListPlaces(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	resultMap := result.(map[string]interface{})
+	assert.Len(t, resultMap["places"], 1)
+	reminders := resultMap["reminders"].([]map[string]interface{})
+	require.Len(t, reminders, 1)
+
+	_, err = skill.handleCancelPlaceReminder(ctx, map[string]interface{}{"reminder_id": reminders[0]["id"]})
+	require.NoError(t, err)
+	_, err = skill.handleRemindAtPlace(ctx, map[string]interface{}{"place": "gym", "message": "Stretch"})
+	assert.ErrorContains(t, err, "no place named")
+}
+
+func TestPlacesSkill_SharingOffAndForget(t *testing.T) {
+	skill := setupPlacesSkill(t)
+	ctx := context.WithValue(context.Background(), "user_id", "user1")
+
+	_, err := skill.handleLocationSharing(ctx, map[string]interface{}{"enabled": true})
+	require.NoError(t, err)
+	_, err = skill.handleSavePlace(ctx, map[string]interface{}{"name": "work", "latitude": 40.75, "longitude": -73.99})
+	require.NoError(t, err)
+	_, err = skill.tracker.CheckIn(ctx, "user1", location.Fix{Latitude: 40.7128, Longitude: -74.0060})
+	require.NoError(t, err)
+
+	result, err := skill.handleLocationSharing(ctx, map[string]interface{}{"enabled": false})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.(map[string]interface{})["deleted"])
+	_, err = skill.handleRemindAtPlace(ctx, map[string]interface{}{"place": "work", "message": "Book a desk"})
+	assert.ErrorContains(t, err, "sharing is off")
+
+	result, err = skill.handleForgetLocation(ctx, map[string]interface{}{"everything": true})
+	require.NoError(t, err)
+	assert.Equal(t, true, result.(map[string]interface{})["confirm_required"])
+	result, err = skill.handleForgetLocation(ctx, map[string]interface{}{"everything": true, "confirm": true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.(map[string]interface{})["deleted"])
+
+	result, err = skill.handleListPlaces(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Empty(t, result.(map[string]interface{})["places"])
+}
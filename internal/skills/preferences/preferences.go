@@ -0,0 +1,260 @@
+package preferences
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"gorm.io/gorm"
+)
+
+// PreferencesSkill lets users view and change their language and regional
+// settings, which decide how dates, times, amounts and units are read and
+// shown by the other skills, and their notification settings
+type PreferencesSkill struct {
+	*skills.BaseSkill
+	locales       *locale.Manager
+	notifications *notify.Manager
+}
+
+// NewPreferencesSkill creates a new preferences skill
+func NewPreferencesSkill(db *gorm.DB) (*PreferencesSkill, error) {
+	locales, err := locale.NewManager(db)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := notify.NewManager(db)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &PreferencesSkill{
+		BaseSkill:     skills.NewBaseSkill("preferences", "Language, date, currency, unit and notification preferences", "1.0.0"),
+		locales:       locales,
+		notifications: notifications,
+	}
+	s.registerTools()
+	return s, nil
+}
+
+func (s *PreferencesSkill) registerTools() {
+	s.AddTool(skills.Tool{
+		Name:        "get_locale",
+		ReadOnly:    true,
+		Description: "Get the user's language and regional settings: date order, 12/24-hour clock, first day of the week, currency and measurement units",
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		},
+		Handler: s.handleGetLocale,
+	})
+
+	s.AddTool(skills.Tool{
+		Name: "set_locale",
+		Description: "Change the user's regional settings. Setting language loads that region's defaults (e.g. en-GB: " +
+			"day/month dates, 24-hour clock, Monday weeks, GBP, metric); other fields then override single settings.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"language": map[string]interface{}{
+					"type":        "string",
+					"description": "Language and region tag: " + strings.Join(locale.Languages(), ", "),
+				},
+				"date_order": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{locale.OrderMDY, locale.OrderDMY, locale.OrderYMD},
+					"description": "Order of day, month and year in dates",
+				},
+				"clock": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"12h", "24h"},
+					"description": "Clock format",
+				},
+				"first_day": map[string]interface{}{
+					"type":        "string",
+					"description": "First day of the week, e.g. monday or sunday",
+				},
+				"currency": map[string]interface{}{
+					"type":        "string",
+					"description": "ISO 4217 currency code, e.g. USD, EUR, MYR",
+				},
+				"units": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{locale.UnitsMetric, locale.UnitsImperial},
+					"description": "Measurement system",
+				},
+			},
+		},
+		Handler: s.handleSetLocale,
+	})
+
+	s.AddTool(skills.Tool{
+		Name:        "get_notification_settings",
+		ReadOnly:    true,
+		Description: "Get the user's notification settings: quiet hours, default channel, per-category channel routing and which categories arrive as a daily digest",
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		},
+		Handler: s.handleGetNotifications,
+	})
+
+	s.AddTool(skills.Tool{
+		Name: "set_notification_settings",
+		Description: "Change how the user is notified. Examples: quiet_hours '22:00-07:00'; send health notifications to telegram " +
+			"(category 'health', category_channel 'telegram'); collect tasks into a digest (category 'tasks', category_delivery 'digest').",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"quiet_hours": map[string]interface{}{
+					"type":        "string",
+					"description": "Quiet hours as HH:MM-HH:MM, or 'off'. Only urgent notifications are sent during them.",
+				},
+				"channel": map[string]interface{}{
+					"type":        "string",
+					"enum":        append(append([]string{}, notify.Channels...), notify.ChannelAuto),
+					"description": "Default channel; 'auto' uses wherever the user last talked to the assistant",
+				},
+				"category": map[string]interface{}{
+					"type":        "string",
+					"description": "Notification category the category_* fields apply to, e.g. health, tasks, scheduled",
+				},
+				"category_channel": map[string]interface{}{
+					"type":        "string",
+					"enum":        append(append([]string{}, notify.Channels...), "default"),
+					"description": "Channel for the category; 'default' removes the route",
+				},
+				"category_delivery": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{notify.DeliverImmediate, notify.DeliverDigest},
+					"description": "Send the category immediately or collect it into the daily digest",
+				},
+				"digest_time": map[string]interface{}{
+					"type":        "string",
+					"description": "When the daily digest is sent, HH:MM",
+				},
+			},
+		},
+		Handler: s.handleSetNotifications,
+	})
+}
+
+func (s *PreferencesSkill) handleGetLocale(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := getUserID(ctx)
+	l := s.locales.Get(userID)
+	return map[string]interface{}{
+		"locale":  l,
+		"summary": l.Summary(),
+		"is_set":  s.locales.IsSet(userID),
+	}, nil
+}
+
+func (s *PreferencesSkill) handleSetLocale(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := getUserID(ctx)
+	l := s.locales.Get(userID)
+
+	// The language preset goes first so the other fields refine it
+	keys := []string{"language", "date_order", "clock", "first_day", "currency", "units"}
+	changed := false
+	for _, key := range keys {
+		value, _ := args[key].(string)
+		if value == "" {
+			continue
+		}
+		var err error
+		if l, err = locale.Apply(l, key, value); err != nil {
+			return nil, err
+		}
+		changed = true
+	}
+	if !changed {
+		return nil, fmt.Errorf("no settings given")
+	}
+
+	if err := s.locales.Set(userID, l); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"locale":  l,
+		"summary": l.Summary(),
+		"updated": true,
+	}, nil
+}
+
+func (s *PreferencesSkill) handleGetNotifications(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	prefs := s.notifications.Get(getUserID(ctx))
+	return map[string]interface{}{
+		"settings": prefs,
+		"summary":  prefs.Summary(),
+	}, nil
+}
+
+func (s *PreferencesSkill) handleSetNotifications(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	prefs := s.notifications.Get(getUserID(ctx))
+	arg := func(key string) string {
+		value, _ := args[key].(string)
+		return strings.TrimSpace(value)
+	}
+
+	changed := false
+	apply := func(err error) error {
+		changed = true
+		return err
+	}
+
+	if v := arg("quiet_hours"); v != "" {
+		if err := apply(prefs.SetQuietHours(v)); err != nil {
+			return nil, err
+		}
+	}
+	if v := arg("channel"); v != "" {
+		if err := apply(prefs.SetChannel(v)); err != nil {
+			return nil, err
+		}
+	}
+	if v := arg("digest_time"); v != "" {
+		if err := apply(prefs.SetDigestTime(v)); err != nil {
+			return nil, err
+		}
+	}
+
+	category := arg("category")
+	if channel, delivery := arg("category_channel"), arg("category_delivery"); channel != "" || delivery != "" {
+		if category == "" {
+			return nil, fmt.Errorf("category is required with category_channel or category_delivery")
+		}
+		if channel != "" {
+			if err := apply(prefs.SetRoute(category, channel)); err != nil {
+				return nil, err
+			}
+		}
+		if delivery != "" {
+			if err := apply(prefs.SetDelivery(category, delivery)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !changed {
+		return nil, fmt.Errorf("no settings given")
+	}
+	if err := s.notifications.Set(prefs); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"settings": prefs,
+		"summary":  prefs.Summary(),
+		"updated":  true,
+	}, nil
+}
+
+func getUserID(ctx context.Context) string {
+	if userID, ok := ctx.Value("user_id").(string); ok && userID != "" {
+		return userID
+	}
+	return "default_user"
+}
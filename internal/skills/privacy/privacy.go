@@ -0,0 +1,58 @@
+package privacy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gmsas95/myrai-cli/internal/skills"
+)
+
+// PrivacySkill lets the user delete what they've said from within a chat
+type PrivacySkill struct {
+	*skills.BaseSkill
+}
+
+// NewPrivacySkill creates a new privacy skill
+func NewPrivacySkill() *PrivacySkill {
+	s := &PrivacySkill{
+		BaseSkill: skills.NewBaseSkill("privacy", "Delete conversations on request", "1.0.0"),
+	}
+	s.registerTools()
+	return s
+}
+
+func (s *PrivacySkill) registerTools() {
+	s.AddTool(skills.Tool{
+		Name: "forget_conversation",
+		Description: "Permanently delete this conversation: its messages, pinned items, uploaded files and the " +
+			"memories learned from it. Use when the user asks to forget or delete this chat. " +
+			"The next message starts a new conversation.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"confirm": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Must be true to delete",
+				},
+			},
+		},
+		Handler: s.handleForgetConversation,
+	})
+}
+
+func (s *PrivacySkill) handleForgetConversation(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	if confirm, _ := args["confirm"].(bool); !confirm {
+		return map[string]interface{}{
+			"confirm_required": true,
+			"message":          "Set confirm=true to permanently delete this conversation",
+		}, nil
+	}
+
+	if !skills.ForgetConversation(ctx) {
+		return nil, fmt.Errorf("this conversation can't be deleted from here; use 'myrai privacy purge' instead")
+	}
+	return map[string]interface{}{
+		"forgotten": true,
+		"message":   "The conversation is deleted once this reply is sent. Tell the user it has been forgotten.",
+	}, nil
+}
@@ -0,0 +1,33 @@
+package privacy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/skills"
+)
+
+func TestPrivacySkill_ForgetConversation(t *testing.T) {
+	skill := NewPrivacySkill()
+	ctx, forget := skills.WithForgetRequest(context.Background())
+
+	result, err := skill.handleForgetConversation(ctx, map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := result.(map[string]interface{}); r["confirm_required"] != true || forget.Requested() {
+		t.Fatalf("Expected confirmation to be asked for first, got %v", r)
+	}
+
+	if _, err := skill.handleForgetConversation(ctx, map[string]interface{}{"confirm": true}); err != nil {
+		t.Fatal(err)
+	}
+	if !forget.Requested() {
+		t.Error("Expected the turn to be marked for forgetting")
+	}
+
+	// Outside a chat turn there is no conversation to forget
+	if _, err := skill.handleForgetConversation(context.Background(), map[string]interface{}{"confirm": true}); err == nil {
+		t.Error("Expected an error outside a chat turn")
+	}
+}
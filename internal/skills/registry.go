@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 
+	"github.com/gmsas95/myrai-cli/internal/cache"
+	"github.com/gmsas95/myrai-cli/internal/events"
 	"github.com/gmsas95/myrai-cli/internal/store"
 )
 
@@ -20,31 +23,53 @@ type Skill interface {
 	Disable() error
 }
 
+// Prompter is a skill with instructions for the model, added to the system
+// prompt while its tools are offered
+type Prompter interface {
+	Prompt() string
+}
+
 // Tool represents a tool provided by a skill
 type Tool struct {
 	Name        string                 `json:"name"`
 	Description string                 `json:"description"`
 	Parameters  map[string]interface{} `json:"parameters"`
 	Handler     ToolHandler            `json:"-"`
+	// ReadOnly tools only look things up, so calls to them may run at the
+	// same time as other read-only calls. Tools that change anything, or
+	// share state such as a browser page, leave it false and run alone.
+	ReadOnly bool `json:"read_only,omitempty"`
 }
 
 // ToolHandler is the function that executes a tool
 type ToolHandler func(ctx context.Context, args map[string]interface{}) (interface{}, error)
 
+// ContextHook derives the context a tool runs with from the caller's
+// context and the name of the skill that owns the tool
+type ContextHook func(ctx context.Context, skill string) context.Context
+
 // Registry manages all skills
 type Registry struct {
-	skills map[string]Skill
-	tools  map[string]Tool
-	mu     sync.RWMutex
-	store  *store.Store
+	skills      map[string]Skill
+	tools       map[string]Tool
+	toolSkills  map[string]string // tool name -> skill name
+	disabled    map[string]bool   // skills whose tools aren't offered
+	contextHook ContextHook
+	events      *events.Bus
+	cache       *cache.Cache
+	mu          sync.RWMutex
+	store       *store.Store
+	toolErrors  toolErrors
 }
 
 // NewRegistry creates a new skill registry
 func NewRegistry(store *store.Store) *Registry {
 	r := &Registry{
-		skills: make(map[string]Skill),
-		tools:  make(map[string]Tool),
-		store:  store,
+		skills:     make(map[string]Skill),
+		tools:      make(map[string]Tool),
+		toolSkills: make(map[string]string),
+		disabled:   make(map[string]bool),
+		store:      store,
 	}
 	return r
 }
@@ -60,15 +85,56 @@ func (r *Registry) Register(skill Skill) error {
 	}
 
 	r.skills[name] = skill
+	if r.disabled[name] {
+		skill.Disable()
+	}
 
 	// Register tools
 	for _, tool := range skill.Tools() {
 		r.tools[tool.Name] = tool
+		r.toolSkills[tool.Name] = name
 	}
 
 	return nil
 }
 
+// SetContextHook sets a hook applied to the context of every tool call
+func (r *Registry) SetContextHook(hook ContextHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.contextHook = hook
+}
+
+// SetEventBus sets the bus skills publish to and subscribe on
+func (r *Registry) SetEventBus(bus *events.Bus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = bus
+}
+
+// Events returns the event bus, or nil if none has been set. A nil bus
+// accepts publishes and drops them.
+func (r *Registry) Events() *events.Bus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.events
+}
+
+// SetCache sets the cache skills share for external lookups
+func (r *Registry) SetCache(c *cache.Cache) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache = c
+}
+
+// Cache returns the shared cache, or nil if none has been set. A nil cache
+// caches nothing.
+func (r *Registry) Cache() *cache.Cache {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cache
+}
+
 // GetSkill retrieves a skill by name
 func (r *Registry) GetSkill(name string) (Skill, bool) {
 	r.mu.RLock()
@@ -82,9 +148,60 @@ func (r *Registry) GetTool(name string) (Tool, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	tool, ok := r.tools[name]
+	if !ok || r.disabled[r.toolSkills[name]] {
+		return Tool{}, false
+	}
 	return tool, ok
 }
 
+// ReadOnly reports whether a tool is marked read-only, see Tool.ReadOnly
+func (r *Registry) ReadOnly(name string) bool {
+	tool, ok := r.GetTool(name)
+	return ok && tool.ReadOnly
+}
+
+// ToolSkill returns the name of the skill that owns a tool, or "" if no
+// skill does
+func (r *Registry) ToolSkill(name string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.toolSkills[name]
+}
+
+// SetDisabled disables the named skills and re-enables any others it
+// disabled before, returning the skills that changed. Disabled skills stay
+// registered, but their tools aren't listed or run.
+func (r *Registry) SetDisabled(names []string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	disabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		disabled[name] = true
+	}
+
+	var changed []string
+	for name := range r.disabled {
+		if !disabled[name] {
+			if skill, ok := r.skills[name]; ok {
+				skill.Enable()
+			}
+			changed = append(changed, name)
+		}
+	}
+	for name := range disabled {
+		if !r.disabled[name] {
+			if skill, ok := r.skills[name]; ok {
+				skill.Disable()
+			}
+			changed = append(changed, name)
+		}
+	}
+	r.disabled = disabled
+	sort.Strings(changed)
+	return changed
+}
+
 // ExecuteTool executes a tool by name
 func (r *Registry) ExecuteTool(ctx context.Context, name string, args json.RawMessage) (interface{}, error) {
 	tool, ok := r.GetTool(name)
@@ -92,13 +209,30 @@ func (r *Registry) ExecuteTool(ctx context.Context, name string, args json.RawMe
 		return nil, fmt.Errorf("tool not found: %s", name)
 	}
 
+	r.mu.RLock()
+	hook, skill := r.contextHook, r.toolSkills[name]
+	r.mu.RUnlock()
+
 	// Parse arguments
 	var argsMap map[string]interface{}
 	if err := json.Unmarshal(args, &argsMap); err != nil {
-		return nil, fmt.Errorf("failed to parse tool arguments: %w", err)
+		err = fmt.Errorf("failed to parse tool arguments: %w", err)
+		r.toolErrors.record(skill, name, err)
+		return nil, err
 	}
 
-	return tool.Handler(ctx, argsMap)
+	if err := CheckTool(ctx, skill, name); err != nil {
+		return nil, err
+	}
+	if hook != nil {
+		ctx = hook(ctx, skill)
+	}
+
+	result, err := tool.Handler(ctx, argsMap)
+	if err != nil {
+		r.toolErrors.record(skill, name, err)
+	}
+	return result, err
 }
 
 // ListSkills returns all registered skills
@@ -113,6 +247,31 @@ func (r *Registry) ListSkills() []Skill {
 	return skills
 }
 
+// Prompts returns the instructions of the enabled skills ctx's tool filter
+// allows, ordered by skill name
+func (r *Registry) Prompts(ctx context.Context) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.skills))
+	for name := range r.skills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var prompts []string
+	for _, name := range names {
+		p, ok := r.skills[name].(Prompter)
+		if !ok || r.disabled[name] || !r.skills[name].IsEnabled() || CheckTool(ctx, name, "") != nil {
+			continue
+		}
+		if prompt := p.Prompt(); prompt != "" {
+			prompts = append(prompts, prompt)
+		}
+	}
+	return prompts
+}
+
 // ListTools returns all available tools
 func (r *Registry) ListTools() []Tool {
 	r.mu.RLock()
@@ -120,6 +279,9 @@ func (r *Registry) ListTools() []Tool {
 
 	tools := make([]Tool, 0, len(r.tools))
 	for _, tool := range r.tools {
+		if r.disabled[r.toolSkills[tool.Name]] {
+			continue
+		}
 		tools = append(tools, tool)
 	}
 	return tools
@@ -132,6 +294,9 @@ func (r *Registry) GetToolDefinitions() []map[string]interface{} {
 
 	defs := make([]map[string]interface{}, 0, len(r.tools))
 	for _, tool := range r.tools {
+		if r.disabled[r.toolSkills[tool.Name]] {
+			continue
+		}
 		defs = append(defs, map[string]interface{}{
 			"type": "function",
 			"function": map[string]interface{}{
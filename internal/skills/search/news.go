@@ -0,0 +1,216 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// statusError describes a failed response, telling the model when to retry
+// if the provider says it was rate limited
+func statusError(api string, resp *http.Response) error {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if after := resp.Header.Get("Retry-After"); after != "" {
+			return fmt.Errorf("%s rate limit reached, retry after %ss", api, after)
+		}
+		return fmt.Errorf("%s rate limit reached, try again later", api)
+	}
+	return fmt.Errorf("%s returned status %d", api, resp.StatusCode)
+}
+
+// SearchNews searches Brave's news index
+func (p *BraveProvider) SearchNews(ctx context.Context, query string, numResults int, freshness string) (*SearchResponse, error) {
+	if !p.IsAvailable() {
+		return nil, fmt.Errorf("Brave API key not configured")
+	}
+
+	u, _ := url.Parse(p.baseURL + "/news/search")
+	q := u.Query()
+	q.Set("q", query)
+	q.Set("count", fmt.Sprintf("%d", numResults))
+	q.Set("safesearch", "moderate")
+	q.Set("spellcheck", "true")
+	switch freshness {
+	case "day":
+		q.Set("freshness", "pd")
+	case "week":
+		q.Set("freshness", "pw")
+	case "month":
+		q.Set("freshness", "pm")
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Subscription-Token", p.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError("Brave API", resp)
+	}
+
+	var result struct {
+		Results []struct {
+			Title       string `json:"title"`
+			URL         string `json:"url"`
+			Description string `json:"description"`
+			Age         string `json:"age"`      // e.g. "3 hours ago"
+			PageAge     string `json:"page_age"` // e.g. 2024-05-01T10:00:00
+			MetaURL     struct {
+				Hostname string `json:"hostname"`
+			} `json:"meta_url"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Brave response: %w", err)
+	}
+
+	searchResults := []SearchResult{}
+	for _, r := range result.Results {
+		published := r.PageAge
+		if published == "" {
+			published = r.Age
+		}
+		source := r.MetaURL.Hostname
+		if source == "" {
+			source = "Brave News"
+		}
+		searchResults = append(searchResults, SearchResult{
+			Title:       r.Title,
+			URL:         r.URL,
+			Snippet:     r.Description,
+			PublishedAt: published,
+			Source:      source,
+		})
+	}
+
+	return &SearchResponse{
+		Query:        query,
+		Results:      searchResults,
+		TotalResults: len(searchResults),
+		Provider:     p.Name(),
+	}, nil
+}
+
+// SearchNews searches Google News through Serper
+func (p *SerperProvider) SearchNews(ctx context.Context, query string, numResults int, freshness string) (*SearchResponse, error) {
+	if !p.IsAvailable() {
+		return nil, fmt.Errorf("Serper API key not configured")
+	}
+
+	body := map[string]interface{}{
+		"q":   query,
+		"num": numResults,
+	}
+	switch freshness {
+	case "day":
+		body["tbs"] = "qdr:d"
+	case "week":
+		body["tbs"] = "qdr:w"
+	case "month":
+		body["tbs"] = "qdr:m"
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/news", strings.NewReader(string(jsonBody)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-API-KEY", p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError("Serper API", resp)
+	}
+
+	var result struct {
+		News []struct {
+			Title   string `json:"title"`
+			Link    string `json:"link"`
+			Snippet string `json:"snippet"`
+			Date    string `json:"date"`
+			Source  string `json:"source"`
+		} `json:"news"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Serper response: %w", err)
+	}
+
+	searchResults := []SearchResult{}
+	for _, r := range result.News {
+		source := r.Source
+		if source == "" {
+			source = "Serper (Google News)"
+		}
+		searchResults = append(searchResults, SearchResult{
+			Title:       r.Title,
+			URL:         r.Link,
+			Snippet:     r.Snippet,
+			PublishedAt: r.Date,
+			Source:      source,
+		})
+	}
+
+	return &SearchResponse{
+		Query:        query,
+		Results:      searchResults,
+		TotalResults: len(searchResults),
+		Provider:     p.Name(),
+	}, nil
+}
+
+// SearchNews has no news index to use with DuckDuckGo's HTML results, so
+// it searches the web for pages from the period, which finds recent
+// coverage
+func (p *DuckDuckGoProvider) SearchNews(ctx context.Context, query string, numResults int, freshness string) (*SearchResponse, error) {
+	dateFilter := ""
+	switch freshness {
+	case "day":
+		dateFilter = "d"
+	case "week":
+		dateFilter = "w"
+	case "month":
+		dateFilter = "m"
+	}
+	return p.search(ctx, query+" news", numResults, dateFilter)
+}
+
+// SearchNews searches with Custom Search for the newest pages from the
+// period
+func (p *GoogleProvider) SearchNews(ctx context.Context, query string, numResults int, freshness string) (*SearchResponse, error) {
+	extra := url.Values{"sort": {"date"}}
+	switch freshness {
+	case "day":
+		extra.Set("dateRestrict", "d1")
+	case "week":
+		extra.Set("dateRestrict", "w1")
+	case "month":
+		extra.Set("dateRestrict", "m1")
+	}
+	return p.search(ctx, query, numResults, extra)
+}
+
+// Ensure every provider can search news
+var (
+	_ NewsProvider = (*BraveProvider)(nil)
+	_ NewsProvider = (*SerperProvider)(nil)
+	_ NewsProvider = (*DuckDuckGoProvider)(nil)
+	_ NewsProvider = (*GoogleProvider)(nil)
+)
@@ -13,9 +13,23 @@ import (
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/gmsas95/myrai-cli/internal/cache"
 	"github.com/gmsas95/myrai-cli/internal/skills"
+	"golang.org/x/time/rate"
 )
 
+const (
+	// CacheTTL is how long identical searches are answered from the cache
+	CacheTTL = 15 * time.Minute
+	// NewsCacheTTL is shorter, as news searches are for what's new
+	NewsCacheTTL = 5 * time.Minute
+)
+
+// providerOrder is the order providers are picked in when none is
+// configured. Those that take a key come first, since a key is only given
+// for the provider it belongs to.
+var providerOrder = []string{"brave", "serper", "google", "duckduckgo"}
+
 // SearchResult represents a single search result
 type SearchResult struct {
 	Title       string `json:"title"`
@@ -41,21 +55,30 @@ type Provider interface {
 	IsAvailable() bool
 }
 
+// NewsProvider is a provider that can also search news articles.
+// Freshness is "day", "week", "month" or empty for any time.
+type NewsProvider interface {
+	SearchNews(ctx context.Context, query string, numResults int, freshness string) (*SearchResponse, error)
+}
+
 // SearchSkill provides web search capabilities
 type SearchSkill struct {
 	*skills.BaseSkill
 	config          Config
 	providers       map[string]Provider
+	limiters        map[string]*rate.Limiter // per provider, so API quotas and scraping limits hold
 	defaultProvider string
+	cache           *cache.Cache
 }
 
 // Config holds search configuration
 type Config struct {
-	Enabled     bool   `json:"enabled"`
-	Provider    string `json:"provider"` // brave, serper, google, duckduckgo
-	APIKey      string `json:"api_key"`
-	MaxResults  int    `json:"max_results"`
-	TimeoutSecs int    `json:"timeout_seconds"`
+	Enabled           bool   `json:"enabled"`
+	Provider          string `json:"provider"` // brave, serper, google, duckduckgo
+	APIKey            string `json:"api_key"`
+	MaxResults        int    `json:"max_results"`
+	TimeoutSecs       int    `json:"timeout_seconds"`
+	RequestsPerMinute int    `json:"requests_per_minute"` // per provider; cached answers don't count
 }
 
 // NewSearchSkill creates a new search skill
@@ -66,6 +89,9 @@ func NewSearchSkill(cfg Config) *SearchSkill {
 	if cfg.TimeoutSecs == 0 {
 		cfg.TimeoutSecs = 30
 	}
+	if cfg.RequestsPerMinute == 0 {
+		cfg.RequestsPerMinute = 30
+	}
 
 	s := &SearchSkill{
 		BaseSkill: skills.NewBaseSkill("search", "Web search for real-time information from the internet", "1.0.0"),
@@ -78,6 +104,11 @@ func NewSearchSkill(cfg Config) *SearchSkill {
 	return s
 }
 
+// SetCache sets the cache that repeated searches are answered from
+func (s *SearchSkill) SetCache(c *cache.Cache) {
+	s.cache = c
+}
+
 // IsEnabled returns whether the skill is enabled
 func (s *SearchSkill) IsEnabled() bool {
 	return s.config.Enabled
@@ -90,16 +121,23 @@ func (s *SearchSkill) registerProviders() {
 	s.providers["duckduckgo"] = NewDuckDuckGoProvider()
 	s.providers["google"] = NewGoogleProvider(s.config.APIKey)
 
-	// Set default provider
-	if s.config.Provider != "" {
+	// Requests are spaced out rather than sent in bursts, which is what
+	// free API tiers (Brave allows one a second) and DuckDuckGo expect
+	s.limiters = make(map[string]*rate.Limiter, len(s.providers))
+	for name := range s.providers {
+		s.limiters[name] = rate.NewLimiter(rate.Every(time.Minute/time.Duration(s.config.RequestsPerMinute)), 1)
+	}
+
+	// Use the configured provider if it can be used, else the first
+	// available one
+	if provider, ok := s.providers[s.config.Provider]; ok && provider.IsAvailable() {
 		s.defaultProvider = s.config.Provider
-	} else {
-		// Auto-select first available provider
-		for name, provider := range s.providers {
-			if provider.IsAvailable() {
-				s.defaultProvider = name
-				break
-			}
+		return
+	}
+	for _, name := range providerOrder {
+		if s.providers[name].IsAvailable() {
+			s.defaultProvider = name
+			return
 		}
 	}
 }
@@ -107,6 +145,7 @@ func (s *SearchSkill) registerProviders() {
 func (s *SearchSkill) registerTools() {
 	s.AddTool(skills.Tool{
 		Name:        "web_search",
+		ReadOnly:    true,
 		Description: "Search the web for current information, news, facts, or any topic. Use this when you need up-to-date information that might not be in your training data.",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -130,8 +169,40 @@ func (s *SearchSkill) registerTools() {
 		Handler: s.handleWebSearch,
 	})
 
+	s.AddTool(skills.Tool{
+		Name:        "news_search",
+		ReadOnly:    true,
+		Description: "Search recent news articles. Use this for current events, announcements and anything where the latest reporting matters; results include when each article was published.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "What to find news about",
+				},
+				"num_results": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of articles to return (default: 5, max: 20)",
+				},
+				"freshness": map[string]interface{}{
+					"type":        "string",
+					"description": "How recent articles must be (default: week)",
+					"enum":        []string{"day", "week", "month", "any"},
+				},
+				"provider": map[string]interface{}{
+					"type":        "string",
+					"description": "Search provider to use (brave, serper, google, duckduckgo). Leave empty for default.",
+					"enum":        []string{"", "brave", "serper", "google", "duckduckgo"},
+				},
+			},
+			"required": []string{"query"},
+		},
+		Handler: s.handleNewsSearch,
+	})
+
 	s.AddTool(skills.Tool{
 		Name:        "get_search_providers",
+		ReadOnly:    true,
 		Description: "Get information about available search providers and their status",
 		Parameters: map[string]interface{}{
 			"type":       "object",
@@ -146,8 +217,56 @@ func (s *SearchSkill) handleWebSearch(ctx context.Context, args map[string]inter
 	if !ok || query == "" {
 		return nil, fmt.Errorf("query is required")
 	}
+	numResults := s.numResults(args)
+	providerName, provider, err := s.provider(args)
+	if err != nil {
+		return nil, err
+	}
+
+	key := cache.Key(providerName, query, numResults)
+	return cache.Fetch(ctx, s.cache, "search", key, CacheTTL, func() (interface{}, error) {
+		return s.search(ctx, providerName, func(ctx context.Context) (*SearchResponse, error) {
+			return provider.Search(ctx, query, numResults)
+		})
+	})
+}
+
+func (s *SearchSkill) handleNewsSearch(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	query, ok := args["query"].(string)
+	if !ok || query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	numResults := s.numResults(args)
+	providerName, provider, err := s.provider(args)
+	if err != nil {
+		return nil, err
+	}
+	news, ok := provider.(NewsProvider)
+	if !ok {
+		return nil, fmt.Errorf("search provider %s can't search news", providerName)
+	}
+
+	freshness, _ := args["freshness"].(string)
+	switch freshness {
+	case "":
+		freshness = "week"
+	case "any":
+		freshness = ""
+	case "day", "week", "month":
+	default:
+		return nil, fmt.Errorf("invalid freshness: %s", freshness)
+	}
 
-	// Get number of results
+	key := cache.Key(providerName, query, numResults, freshness)
+	return cache.Fetch(ctx, s.cache, "search_news", key, NewsCacheTTL, func() (interface{}, error) {
+		return s.search(ctx, providerName, func(ctx context.Context) (*SearchResponse, error) {
+			return news.SearchNews(ctx, query, numResults, freshness)
+		})
+	})
+}
+
+// numResults reads how many results a search asks for, within 1 to 20
+func (s *SearchSkill) numResults(args map[string]interface{}) int {
 	numResults := s.config.MaxResults
 	if nr, ok := args["num_results"].(float64); ok {
 		numResults = int(nr)
@@ -158,29 +277,41 @@ func (s *SearchSkill) handleWebSearch(ctx context.Context, args map[string]inter
 			numResults = 1
 		}
 	}
+	return numResults
+}
 
-	// Get provider
+// provider returns the provider a search asks for, or the default
+func (s *SearchSkill) provider(args map[string]interface{}) (string, Provider, error) {
 	providerName := s.defaultProvider
 	if p, ok := args["provider"].(string); ok && p != "" {
 		providerName = p
 	}
 
-	// Get provider instance
 	provider, ok := s.providers[providerName]
 	if !ok {
-		return nil, fmt.Errorf("unknown search provider: %s", providerName)
+		return "", nil, fmt.Errorf("unknown search provider: %s", providerName)
 	}
 
 	if !provider.IsAvailable() {
-		return nil, fmt.Errorf("search provider %s is not available (missing API key or configuration)", providerName)
+		return "", nil, fmt.Errorf("search provider %s is not available (missing API key or configuration)", providerName)
 	}
+	return providerName, provider, nil
+}
 
-	// Perform search with timeout
+// search runs a search within the timeout once the provider's rate limit
+// allows, formatting the results
+func (s *SearchSkill) search(ctx context.Context, providerName string, fn func(context.Context) (*SearchResponse, error)) (interface{}, error) {
 	searchCtx, cancel := context.WithTimeout(ctx, time.Duration(s.config.TimeoutSecs)*time.Second)
 	defer cancel()
 
+	if limiter := s.limiters[providerName]; limiter != nil {
+		if err := limiter.Wait(searchCtx); err != nil {
+			return nil, fmt.Errorf("too many searches with %s, try again shortly: %w", providerName, err)
+		}
+	}
+
 	start := time.Now()
-	response, err := provider.Search(searchCtx, query, numResults)
+	response, err := fn(searchCtx)
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
@@ -235,14 +366,16 @@ func (s *SearchSkill) formatSearchResults(response *SearchResponse) map[string]i
 // ==================== BRAVE PROVIDER ====================
 
 type BraveProvider struct {
-	apiKey string
-	client *http.Client
+	apiKey  string
+	baseURL string
+	client  *http.Client
 }
 
 func NewBraveProvider(apiKey string) *BraveProvider {
 	return &BraveProvider{
-		apiKey: apiKey,
-		client: &http.Client{Timeout: 30 * time.Second},
+		apiKey:  apiKey,
+		baseURL: "https://api.search.brave.com/res/v1",
+		client:  &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
@@ -258,7 +391,7 @@ func (p *BraveProvider) Search(ctx context.Context, query string, numResults int
 	}
 
 	// Build URL
-	u, _ := url.Parse("https://api.search.brave.com/res/v1/web/search")
+	u, _ := url.Parse(p.baseURL + "/web/search")
 	q := u.Query()
 	q.Set("q", query)
 	q.Set("count", fmt.Sprintf("%d", numResults))
@@ -287,7 +420,7 @@ func (p *BraveProvider) Search(ctx context.Context, query string, numResults int
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Brave API returned status %d", resp.StatusCode)
+		return nil, statusError("Brave API", resp)
 	}
 
 	// Parse response
@@ -327,14 +460,16 @@ func (p *BraveProvider) Search(ctx context.Context, query string, numResults int
 // ==================== SERPER PROVIDER ====================
 
 type SerperProvider struct {
-	apiKey string
-	client *http.Client
+	apiKey  string
+	baseURL string
+	client  *http.Client
 }
 
 func NewSerperProvider(apiKey string) *SerperProvider {
 	return &SerperProvider{
-		apiKey: apiKey,
-		client: &http.Client{Timeout: 30 * time.Second},
+		apiKey:  apiKey,
+		baseURL: "https://google.serper.dev",
+		client:  &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
@@ -358,7 +493,7 @@ func (p *SerperProvider) Search(ctx context.Context, query string, numResults in
 
 	jsonBody, _ := json.Marshal(body)
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://google.serper.dev/search", strings.NewReader(string(jsonBody)))
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/search", strings.NewReader(string(jsonBody)))
 	if err != nil {
 		return nil, err
 	}
@@ -373,7 +508,7 @@ func (p *SerperProvider) Search(ctx context.Context, query string, numResults in
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Serper API returned status %d", resp.StatusCode)
+		return nil, statusError("Serper API", resp)
 	}
 
 	var result struct {
@@ -428,6 +563,12 @@ func (p *DuckDuckGoProvider) IsAvailable() bool {
 }
 
 func (p *DuckDuckGoProvider) Search(ctx context.Context, query string, numResults int) (*SearchResponse, error) {
+	return p.search(ctx, query, numResults, "")
+}
+
+// search scrapes the HTML results, optionally only pages from the last day
+// (d), week (w) or month (m)
+func (p *DuckDuckGoProvider) search(ctx context.Context, query string, numResults int, dateFilter string) (*SearchResponse, error) {
 	start := time.Now()
 
 	// Build URL
@@ -435,6 +576,9 @@ func (p *DuckDuckGoProvider) Search(ctx context.Context, query string, numResult
 	q := u.Query()
 	q.Set("q", query)
 	q.Set("kl", "us-en")
+	if dateFilter != "" {
+		q.Set("df", dateFilter)
+	}
 	u.RawQuery = q.Encode()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
@@ -456,7 +600,7 @@ func (p *DuckDuckGoProvider) Search(ctx context.Context, query string, numResult
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("DuckDuckGo returned status %d", resp.StatusCode)
+		return nil, statusError("DuckDuckGo", resp)
 	}
 
 	// Parse HTML using goquery
@@ -587,6 +731,11 @@ func (p *GoogleProvider) IsAvailable() bool {
 }
 
 func (p *GoogleProvider) Search(ctx context.Context, query string, numResults int) (*SearchResponse, error) {
+	return p.search(ctx, query, numResults, nil)
+}
+
+// search queries Custom Search, with extra parameters such as a sort order
+func (p *GoogleProvider) search(ctx context.Context, query string, numResults int, extra url.Values) (*SearchResponse, error) {
 	if !p.IsAvailable() {
 		return nil, fmt.Errorf("Google Custom Search API key not configured")
 	}
@@ -607,6 +756,9 @@ func (p *GoogleProvider) Search(ctx context.Context, query string, numResults in
 	q.Set("cx", cx)
 	q.Set("q", query)
 	q.Set("num", fmt.Sprintf("%d", min(numResults, 10))) // Google max is 10 per request
+	for k, v := range extra {
+		q[k] = v
+	}
 	u.RawQuery = q.Encode()
 
 	// Create request
@@ -624,6 +776,9 @@ func (p *GoogleProvider) Search(ctx context.Context, query string, numResults in
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, statusError("Google API", resp)
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("Google API returned status %d: %s", resp.StatusCode, string(body))
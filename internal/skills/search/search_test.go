@@ -2,7 +2,13 @@ package search
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestSearchSkillCreation(t *testing.T) {
@@ -93,7 +99,7 @@ func TestSearchSkillTools(t *testing.T) {
 	skill := NewSearchSkill(cfg)
 	tools := skill.Tools()
 
-	expectedTools := []string{"web_search", "get_search_providers"}
+	expectedTools := []string{"web_search", "news_search", "get_search_providers"}
 	if len(tools) != len(expectedTools) {
 		t.Errorf("Expected %d tools, got %d", len(expectedTools), len(tools))
 	}
@@ -174,3 +180,177 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Expected default TimeoutSecs 30, got %d", skill.config.TimeoutSecs)
 	}
 }
+
+func TestDefaultProviderSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected string
+	}{
+		{"configured", Config{Provider: "serper", APIKey: "key"}, "serper"},
+		{"key without provider", Config{APIKey: "key"}, "brave"},
+		{"no key", Config{}, "duckduckgo"},
+		{"configured without key", Config{Provider: "brave"}, "duckduckgo"},
+		{"unknown provider", Config{Provider: "bing", APIKey: "key"}, "brave"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skill := NewSearchSkill(tt.cfg)
+			if skill.defaultProvider != tt.expected {
+				t.Errorf("Expected default provider %s, got %s", tt.expected, skill.defaultProvider)
+			}
+		})
+	}
+}
+
+// toolHandler returns the handler of one of the skill's tools
+func toolHandler(t *testing.T, skill *SearchSkill, name string) func(context.Context, map[string]interface{}) (interface{}, error) {
+	t.Helper()
+	for _, tool := range skill.Tools() {
+		if tool.Name == name {
+			return tool.Handler
+		}
+	}
+	t.Fatalf("%s tool not found", name)
+	return nil
+}
+
+func TestBraveNewsSearch(t *testing.T) {
+	var gotQuery, gotFreshness string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/news/search" || r.Header.Get("X-Subscription-Token") != "test_key" {
+			http.NotFound(w, r)
+			return
+		}
+		gotQuery = r.URL.Query().Get("q")
+		gotFreshness = r.URL.Query().Get("freshness")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"results": []map[string]interface{}{{
+				"title":       "Launch delayed",
+				"url":         "https://news.example.com/launch",
+				"description": "The launch moved to Friday.",
+				"page_age":    "2026-10-15T08:00:00",
+				"meta_url":    map[string]string{"hostname": "news.example.com"},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	skill := NewSearchSkill(Config{Enabled: true, Provider: "brave", APIKey: "test_key"})
+	skill.providers["brave"].(*BraveProvider).baseURL = server.URL
+
+	result, err := toolHandler(t, skill, "news_search")(context.Background(), map[string]interface{}{
+		"query":     "rocket launch",
+		"freshness": "day",
+	})
+	if err != nil {
+		t.Fatalf("news_search failed: %v", err)
+	}
+	if gotQuery != "rocket launch" || gotFreshness != "pd" {
+		t.Errorf("Unexpected request: q=%q freshness=%q", gotQuery, gotFreshness)
+	}
+
+	results := result.(map[string]interface{})["results"].([]map[string]interface{})
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	if results[0]["published_at"] != "2026-10-15T08:00:00" || results[0]["source"] != "news.example.com" {
+		t.Errorf("Unexpected result: %v", results[0])
+	}
+}
+
+func TestSerperNewsSearch(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/news" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"news": []map[string]string{{
+				"title":   "Rates held",
+				"link":    "https://example.com/rates",
+				"snippet": "The bank held rates.",
+				"date":    "2 hours ago",
+				"source":  "Example Times",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	provider := NewSerperProvider("test_key")
+	provider.baseURL = server.URL
+
+	response, err := provider.SearchNews(context.Background(), "interest rates", 3, "week")
+	if err != nil {
+		t.Fatalf("SearchNews failed: %v", err)
+	}
+	if body["tbs"] != "qdr:w" || body["num"] != float64(3) {
+		t.Errorf("Unexpected request body: %v", body)
+	}
+	if len(response.Results) != 1 || response.Results[0].Source != "Example Times" || response.Results[0].PublishedAt != "2 hours ago" {
+		t.Errorf("Unexpected results: %+v", response.Results)
+	}
+}
+
+func TestNewsSearchFreshness(t *testing.T) {
+	skill := NewSearchSkill(Config{Enabled: true})
+	_, err := toolHandler(t, skill, "news_search")(context.Background(), map[string]interface{}{
+		"query":     "anything",
+		"freshness": "year",
+	})
+	if err == nil || !strings.Contains(err.Error(), "freshness") {
+		t.Errorf("Expected a freshness error, got %v", err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{"web": map[string]interface{}{"results": []interface{}{}}})
+	}))
+	defer server.Close()
+
+	// 300 a minute is one every 200ms
+	skill := NewSearchSkill(Config{Enabled: true, Provider: "brave", APIKey: "test_key", RequestsPerMinute: 300})
+	skill.providers["brave"].(*BraveProvider).baseURL = server.URL
+	search := toolHandler(t, skill, "web_search")
+
+	start := time.Now()
+	for _, query := range []string{"one", "two", "three"} {
+		if _, err := search(context.Background(), map[string]interface{}{"query": query}); err != nil {
+			t.Fatalf("web_search failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 350*time.Millisecond {
+		t.Errorf("Expected searches to be spaced out, 3 took %v", elapsed)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests.Load())
+	}
+
+	// A search that can't wait its turn fails instead of hanging
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := search(ctx, map[string]interface{}{"query": "four"}); err == nil {
+		t.Error("Expected a rate limit error")
+	}
+}
+
+func TestProviderRateLimitError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	provider := NewBraveProvider("test_key")
+	provider.baseURL = server.URL
+	_, err := provider.Search(context.Background(), "query", 5)
+	if err == nil || !strings.Contains(err.Error(), "retry after 30s") {
+		t.Errorf("Expected a retry hint, got %v", err)
+	}
+}
@@ -0,0 +1,514 @@
+package shopping
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/gmsas95/myrai-cli/internal/skills"
+	"go.uber.org/zap"
+)
+
+// unitAliases maps the ways units are written to the ones items are stored in
+var unitAliases = map[string]string{
+	"gram": "g", "kilogram": "kg", "kilo": "kg",
+	"pound": "lb", "lbs": "lb", "ounce": "oz",
+	"liter": "L", "litre": "L", "l": "L", "milliliter": "ml", "millilitre": "ml",
+	"gallon": "gal", "tablespoon": "tbsp", "teaspoon": "tsp",
+	"piece": "", "pcs": "", "pc": "", "whole": "", "each": "",
+}
+
+// measure is a unit's dimension and its size in that dimension's base unit
+type measure struct {
+	dimension string
+	factor    float64
+}
+
+// measures lists the units amounts can be converted between
+var measures = map[string]measure{
+	"g":     {"mass", 1},
+	"kg":    {"mass", 1000},
+	"oz":    {"mass", 28.35},
+	"lb":    {"mass", 453.59},
+	"ml":    {"volume", 1},
+	"L":     {"volume", 1000},
+	"gal":   {"volume", 3785.41},
+	"cup":   {"volume", 240},
+	"tbsp":  {"volume", 15},
+	"tsp":   {"volume", 5},
+	"":      {"count", 1},
+	"dozen": {"count", 12},
+}
+
+func (s *ShoppingSkill) pantryTools() []skills.Tool {
+	return []skills.Tool{
+		{
+			Name:        "update_pantry",
+			Description: "Record what the user has at home, e.g. after shopping ('2 lbs rice, 12 eggs, olive oil')",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"items": map[string]interface{}{
+						"type":        "string",
+						"description": "Items with quantities and units, comma separated",
+					},
+					"mode": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"add", "set", "remove"},
+						"description": "add to what is there (default), set the exact amount, or stop tracking the items",
+					},
+				},
+				"required": []string{"items"},
+			},
+		},
+		{
+			Name:        "get_pantry",
+			ReadOnly:    true,
+			Description: "List what the user has at home and what has run out",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "use_pantry_items",
+			Description: "Take used items out of the pantry, e.g. after cooking ('3 eggs, 200 g rice')",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"items": map[string]interface{}{
+						"type":        "string",
+						"description": "Items used with quantities and units, comma separated",
+					},
+					"add_to_list": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Add items that ran out to the default shopping list",
+					},
+				},
+				"required": []string{"items"},
+			},
+		},
+		{
+			Name:        "add_recipe_ingredients",
+			Description: "Add a recipe's ingredients to a shopping list, skipping what the pantry already has enough of. Read the ingredients from the recipe yourself and pass them with their amounts.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"recipe": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the recipe",
+					},
+					"ingredients": map[string]interface{}{
+						"type": "array",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"name":     map[string]interface{}{"type": "string"},
+								"quantity": map[string]interface{}{"type": "number", "description": "Amount needed; leave out for 'to taste'"},
+								"unit":     map[string]interface{}{"type": "string", "description": "e.g. g, kg, lb, oz, ml, cup, tbsp, tsp; empty for a count"},
+							},
+							"required": []string{"name"},
+						},
+						"description": "Ingredients the recipe needs",
+					},
+					"list_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the shopping list (or 'default' for user's default list)",
+					},
+				},
+				"required": []string{"ingredients"},
+			},
+		},
+	}
+}
+
+func (s *ShoppingSkill) handleUpdatePantry(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := s.getUserID(ctx)
+	mode := getStringArg(args, "mode", "add")
+	itemsText := getStringArg(args, "items", "")
+	if itemsText == "" {
+		return nil, fmt.Errorf("no items provided")
+	}
+
+	loc := locale.FromContext(ctx)
+	parsedItems := NewParser().WithLocale(loc).ParseItems(itemsText)
+	if len(parsedItems) == 0 {
+		return nil, fmt.Errorf("could not parse any items from input")
+	}
+
+	var updated []map[string]interface{}
+	var problems []string
+	for _, parsed := range parsedItems {
+		quantity, _ := strconv.ParseFloat(parsed.Quantity, 64)
+		unit := normalizeUnit(parsed.Unit)
+
+		item, err := s.store.GetPantryItem(userID, pantryKey(parsed.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case mode == "remove":
+			if item == nil {
+				problems = append(problems, fmt.Sprintf("%s isn't in the pantry", parsed.Name))
+				continue
+			}
+			if err := s.store.DeletePantryItem(item.ID); err != nil {
+				return nil, err
+			}
+			updated = append(updated, map[string]interface{}{"name": item.Name, "removed": true})
+			continue
+		case item == nil:
+			item = &PantryItem{
+				UserID:   userID,
+				Key:      pantryKey(parsed.Name),
+				Name:     parsed.Name,
+				Quantity: quantity,
+				Unit:     unit,
+				Category: parsed.Category,
+			}
+		case mode == "set":
+			item.Quantity, item.Unit = quantity, unit
+		default:
+			added, ok := convertAmount(quantity, unit, item.Unit)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s is kept in %s, not %s", item.Name, unitName(item.Unit), unitName(unit)))
+				continue
+			}
+			item.Quantity = round2(item.Quantity + added)
+		}
+
+		if err := s.store.SavePantryItem(item); err != nil {
+			return nil, fmt.Errorf("failed to save %s: %w", item.Name, err)
+		}
+		updated = append(updated, map[string]interface{}{
+			"name":     item.Name,
+			"quantity": loc.Quantity(formatAmount(item.Quantity), item.Unit),
+		})
+	}
+
+	result := map[string]interface{}{
+		"updated": updated,
+		"message": fmt.Sprintf("Updated %d pantry item(s)", len(updated)),
+	}
+	if len(problems) > 0 {
+		result["problems"] = problems
+	}
+	return result, nil
+}
+
+func (s *ShoppingSkill) handleGetPantry(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	items, err := s.store.ListPantry(s.getUserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	loc := locale.FromContext(ctx)
+	inStock := []map[string]interface{}{}
+	var out []string
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			out = append(out, item.Name)
+			continue
+		}
+		inStock = append(inStock, map[string]interface{}{
+			"name":     item.Name,
+			"quantity": loc.Quantity(formatAmount(item.Quantity), item.Unit),
+			"category": item.Category,
+		})
+	}
+
+	result := map[string]interface{}{
+		"count": len(inStock),
+		"items": inStock,
+	}
+	if len(out) > 0 {
+		result["out_of_stock"] = out
+	}
+	return result, nil
+}
+
+func (s *ShoppingSkill) handleUsePantryItems(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := s.getUserID(ctx)
+	itemsText := getStringArg(args, "items", "")
+	if itemsText == "" {
+		return nil, fmt.Errorf("no items provided")
+	}
+
+	loc := locale.FromContext(ctx)
+	parsedItems := NewParser().WithLocale(loc).ParseItems(itemsText)
+	if len(parsedItems) == 0 {
+		return nil, fmt.Errorf("could not parse any items from input")
+	}
+
+	var used []map[string]interface{}
+	var ranOut []*PantryItem
+	var problems []string
+	for _, parsed := range parsedItems {
+		item, err := s.store.GetPantryItem(userID, pantryKey(parsed.Name))
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			problems = append(problems, fmt.Sprintf("%s isn't in the pantry", parsed.Name))
+			continue
+		}
+
+		quantity, _ := strconv.ParseFloat(parsed.Quantity, 64)
+		amount, ok := convertAmount(quantity, normalizeUnit(parsed.Unit), item.Unit)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is kept in %s, not %s", item.Name, unitName(item.Unit), unitName(normalizeUnit(parsed.Unit))))
+			continue
+		}
+
+		item.Quantity = math.Max(round2(item.Quantity-amount), 0)
+		if err := s.store.SavePantryItem(item); err != nil {
+			return nil, fmt.Errorf("failed to update %s: %w", item.Name, err)
+		}
+		used = append(used, map[string]interface{}{
+			"name": item.Name,
+			"left": loc.Quantity(formatAmount(item.Quantity), item.Unit),
+		})
+		if item.Quantity == 0 {
+			ranOut = append(ranOut, item)
+		}
+	}
+
+	result := map[string]interface{}{
+		"used":    used,
+		"message": fmt.Sprintf("Took %d item(s) out of the pantry", len(used)),
+	}
+	if len(problems) > 0 {
+		result["problems"] = problems
+	}
+	if len(ranOut) == 0 {
+		return result, nil
+	}
+
+	var names []string
+	for _, item := range ranOut {
+		names = append(names, item.Name)
+	}
+	result["ran_out"] = names
+
+	if getBoolArg(args, "add_to_list", false) {
+		list, err := s.resolveList(ctx, "default")
+		if err != nil {
+			return nil, err
+		}
+		actorID, _ := s.actor(ctx)
+		for _, item := range ranOut {
+			if err := s.store.CreateItem(&ShoppingItem{
+				ListID:    list.ID,
+				UserID:    list.UserID,
+				AddedBy:   actorID,
+				AddedFrom: "pantry",
+				Name:      item.Name,
+				Quantity:  "1",
+				Category:  item.Category,
+				Priority:  "medium",
+			}); err != nil {
+				return nil, fmt.Errorf("failed to add %s to the list: %w", item.Name, err)
+			}
+		}
+		s.notifyChange(ctx, list, "added "+itemNames(names))
+		result["added_to_list"] = list.Name
+	}
+	return result, nil
+}
+
+func (s *ShoppingSkill) handleAddRecipeIngredients(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	userID := s.getUserID(ctx)
+	recipe := getStringArg(args, "recipe", "")
+	ingredients, ok := args["ingredients"].([]interface{})
+	if !ok || len(ingredients) == 0 {
+		return nil, fmt.Errorf("ingredients are required")
+	}
+
+	list, err := s.resolveList(ctx, getStringArg(args, "list_id", "default"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Ingredients already waiting on the list aren't added twice
+	onList := make(map[string]bool)
+	listItems, err := s.store.GetItemsByList(list.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range listItems {
+		if !item.IsChecked {
+			onList[pantryKey(item.Name)] = true
+		}
+	}
+
+	loc := locale.FromContext(ctx)
+	parser := NewParser()
+	actorID, _ := s.actor(ctx)
+	notes := ""
+	if recipe != "" {
+		notes = "for " + recipe
+	}
+
+	added := []map[string]interface{}{}
+	skipped := []map[string]interface{}{}
+	var names []string
+	for _, raw := range ingredients {
+		ingredient, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name := strings.TrimSpace(getStringArg(ingredient, "name", ""))
+		if name == "" {
+			continue
+		}
+		need, _ := ingredient["quantity"].(float64)
+		unit := normalizeUnit(getStringArg(ingredient, "unit", ""))
+		key := pantryKey(name)
+
+		if onList[key] {
+			skipped = append(skipped, map[string]interface{}{"name": name, "reason": "already on the list"})
+			continue
+		}
+
+		pantryItem, err := s.store.GetPantryItem(userID, key)
+		if err != nil {
+			return nil, err
+		}
+		if pantryItem != nil && pantryItem.Quantity > 0 {
+			have, comparable := convertAmount(pantryItem.Quantity, pantryItem.Unit, unit)
+			inPantry := loc.Quantity(formatAmount(pantryItem.Quantity), pantryItem.Unit)
+			switch {
+			case need == 0:
+				skipped = append(skipped, map[string]interface{}{"name": name, "reason": "in the pantry (" + inPantry + ")"})
+				continue
+			case !comparable:
+				// Can't tell 2 cups from 1 bag; trust that having some is enough
+				skipped = append(skipped, map[string]interface{}{"name": name, "reason": "in the pantry (" + inPantry + "), check it's enough"})
+				continue
+			case have >= need:
+				skipped = append(skipped, map[string]interface{}{"name": name, "reason": "in the pantry (" + inPantry + ")"})
+				continue
+			}
+			need = round2(need - have)
+		}
+
+		quantity := "1"
+		if need > 0 {
+			quantity = formatAmount(need)
+		}
+		item := &ShoppingItem{
+			ListID:    list.ID,
+			UserID:    list.UserID,
+			AddedBy:   actorID,
+			AddedFrom: "recipe",
+			Name:      name,
+			Quantity:  quantity,
+			Unit:      unit,
+			Category:  parser.SuggestCategory(name),
+			Priority:  "medium",
+			Notes:     notes,
+		}
+		if err := s.store.CreateItem(item); err != nil {
+			return nil, fmt.Errorf("failed to add item %s: %w", item.Name, err)
+		}
+		onList[key] = true
+		added = append(added, map[string]interface{}{
+			"id":       item.ID,
+			"name":     item.Name,
+			"quantity": loc.Quantity(item.Quantity, item.Unit),
+		})
+		names = append(names, item.Name)
+	}
+
+	if len(names) > 0 {
+		change := "added " + itemNames(names)
+		if recipe != "" {
+			change = fmt.Sprintf("added ingredients for %s: %s", recipe, itemNames(names))
+		}
+		s.notifyChange(ctx, list, change)
+	}
+
+	s.logger.Info("Recipe ingredients added to shopping list",
+		zap.String("list_id", list.ID),
+		zap.String("recipe", recipe),
+		zap.Int("added", len(added)),
+		zap.Int("skipped", len(skipped)),
+	)
+
+	return map[string]interface{}{
+		"list_id":   list.ID,
+		"list_name": list.Name,
+		"recipe":    recipe,
+		"added":     added,
+		"skipped":   skipped,
+		"message":   fmt.Sprintf("Added %d ingredient(s) to '%s', skipped %d", len(added), list.Name, len(skipped)),
+	}, nil
+}
+
+// pantryKey normalizes an item name so "Eggs" on a recipe finds "egg" in the
+// pantry
+func pantryKey(name string) string {
+	words := strings.Fields(strings.ToLower(name))
+	if len(words) == 0 {
+		return ""
+	}
+	last := words[len(words)-1]
+	switch {
+	case strings.HasSuffix(last, "ies") && len(last) > 4:
+		last = strings.TrimSuffix(last, "ies") + "y"
+	case strings.HasSuffix(last, "oes"):
+		last = strings.TrimSuffix(last, "es")
+	case strings.HasSuffix(last, "s") && !strings.HasSuffix(last, "ss") && len(last) > 3:
+		last = strings.TrimSuffix(last, "s")
+	}
+	words[len(words)-1] = last
+	return strings.Join(words, " ")
+}
+
+// normalizeUnit maps a written unit to the one items are stored in
+func normalizeUnit(unit string) string {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	if alias, ok := unitAliases[unit]; ok {
+		return alias
+	}
+	if singular := strings.TrimSuffix(unit, "s"); singular != unit {
+		if alias, ok := unitAliases[singular]; ok {
+			return alias
+		}
+		unit = singular
+	}
+	return unit
+}
+
+// convertAmount converts an amount between units of the same dimension. It
+// reports false for units that can't be compared, like cups and bags.
+func convertAmount(amount float64, from, to string) (float64, bool) {
+	if from == to {
+		return amount, true
+	}
+	f, okFrom := measures[from]
+	t, okTo := measures[to]
+	if !okFrom || !okTo || f.dimension != t.dimension {
+		return 0, false
+	}
+	return amount * f.factor / t.factor, true
+}
+
+func unitName(unit string) string {
+	if unit == "" {
+		return "a count"
+	}
+	return unit
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(round2(v), 'f', -1, 64)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -4,6 +4,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/locale"
 )
 
 // ParsedItem represents a single parsed item from natural language
@@ -15,6 +17,7 @@ type ParsedItem struct {
 	Priority       string
 	StoreAisle     string
 	EstimatedPrice float64
+	Currency       string
 	Notes          string
 }
 
@@ -36,6 +39,8 @@ type Parser struct {
 	unitPatterns []string
 	// Priority keywords
 	priorityKeywords map[string]string
+	// locale supplies the currency of bare and locally written prices
+	locale locale.Locale
 }
 
 // NewParser creates a new shopping parser
@@ -73,9 +78,16 @@ func NewParser() *Parser {
 			"optional":  "low",
 			"maybe":     "low",
 		},
+		locale: locale.Default(),
 	}
 }
 
+// WithLocale sets the locale whose currency prices are read in
+func (p *Parser) WithLocale(l locale.Locale) *Parser {
+	p.locale = l
+	return p
+}
+
 // ParseShoppingInput parses a natural language shopping request
 func (p *Parser) ParseShoppingInput(text string) *ParsedShoppingInput {
 	result := &ParsedShoppingInput{
@@ -154,7 +166,7 @@ func (p *Parser) parseSingleItem(text string) *ParsedItem {
 	item.Unit = unit
 	
 	// Extract price
-	item.EstimatedPrice = p.extractPrice(text)
+	item.EstimatedPrice, item.Currency = p.extractPrice(text)
 	
 	// The rest is the item name
 	item.Name = p.cleanItemName(remaining)
@@ -235,25 +247,38 @@ func (p *Parser) extractPriority(text string) string {
 	return "medium"
 }
 
-func (p *Parser) extractPrice(text string) float64 {
-	// Match price patterns like $5.99, 5.99 dollars, etc.
-	patterns := []string{
-		`\$\s*(\d+(?:\.\d{2})?)`,
-		`(\d+(?:\.\d{2})?)\s*dollars?`,
-		`(\d+(?:\.\d{2})?)\s*usd`,
+func (p *Parser) extractPrice(text string) (float64, string) {
+	// Match price patterns like $5.99, 5.99 dollars, RM4.50, etc.
+	patterns := []struct {
+		regex    string
+		currency string
+	}{
+		{`\$\s*(\d+(?:\.\d{2})?)`, "USD"},
+		{`(\d+(?:\.\d{2})?)\s*dollars?`, "USD"},
+		{`(\d+(?:\.\d{2})?)\s*usd`, "USD"},
+		{`€\s*(\d+(?:\.\d{2})?)`, "EUR"},
+		{`(\d+(?:\.\d{2})?)\s*(?:eur|euros?)\b`, "EUR"},
+		{`£\s*(\d+(?:\.\d{2})?)`, "GBP"},
+		{`\brm\s*(\d+(?:\.\d{2})?)`, "MYR"},
+		{`(\d+(?:\.\d{2})?)\s*(?:myr|ringgit)\b`, "MYR"},
 	}
 	
 	for _, pattern := range patterns {
-		re := regexp.MustCompile(`(?i)` + pattern)
+		re := regexp.MustCompile(`(?i)` + pattern.regex)
 		matches := re.FindStringSubmatch(text)
 		if len(matches) >= 2 {
 			if price, err := strconv.ParseFloat(matches[1], 64); err == nil {
-				return price
+				currency := pattern.currency
+				// "$" also writes Singapore, Australian and other dollars
+				if currency == "USD" && strings.HasSuffix(p.locale.Currency, "D") && !strings.Contains(strings.ToLower(text), "usd") {
+					currency = p.locale.Currency
+				}
+				return price, currency
 			}
 		}
 	}
 	
-	return 0
+	return 0, ""
 }
 
 func (p *Parser) extractListName(text string) string {
@@ -0,0 +1,271 @@
+package shopping
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"go.uber.org/zap"
+)
+
+// Members looks up household profiles. *household.Manager implements it.
+type Members interface {
+	Get(nameOrID string) (*household.Profile, error)
+	List() ([]household.Profile, error)
+}
+
+// SetNotifier sets where members of a shared list hear about changes. Without
+// one, shared lists still work but nobody is told.
+func (s *ShoppingSkill) SetNotifier(n notify.Sender) {
+	s.notifier = n
+}
+
+// SetHousehold sets where the people lists can be shared with are looked up.
+// shared is whether the household shares all shopping data, in which case
+// every list already belongs to everyone.
+func (s *ShoppingSkill) SetHousehold(m Members, shared bool) {
+	s.members = m
+	s.householdWide = shared
+}
+
+// actor returns the person making the request: their own user ID, even when
+// shopping data is shared, and their name for notifications
+func (s *ShoppingSkill) actor(ctx context.Context) (string, string) {
+	if profile := household.FromContext(ctx); profile != nil {
+		return profile.UserID, profile.Label()
+	}
+	return s.getUserID(ctx), ""
+}
+
+// accessibleList loads a list the requester owns or that is shared with them
+func (s *ShoppingSkill) accessibleList(ctx context.Context, listID string) (*ShoppingList, error) {
+	list, err := s.store.GetList(listID)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return nil, fmt.Errorf("list not found")
+	}
+	if list.UserID == s.getUserID(ctx) {
+		return list, nil
+	}
+	actorID, _ := s.actor(ctx)
+	member, err := s.store.IsMember(list.ID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	return list, nil
+}
+
+// accessibleItem loads an item on a list the requester can access
+func (s *ShoppingSkill) accessibleItem(ctx context.Context, itemID string) (*ShoppingItem, *ShoppingList, error) {
+	item, err := s.store.GetItem(itemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item == nil {
+		return nil, nil, fmt.Errorf("item not found")
+	}
+	list, err := s.accessibleList(ctx, item.ListID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return item, list, nil
+}
+
+// ownedList loads a list only its owner may share or delete
+func (s *ShoppingSkill) ownedList(ctx context.Context, listID string) (*ShoppingList, error) {
+	list, err := s.accessibleList(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	if list.UserID != s.getUserID(ctx) {
+		return nil, fmt.Errorf("only the list's owner can do that")
+	}
+	return list, nil
+}
+
+func (s *ShoppingSkill) handleShareList(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	listID := getStringArg(args, "list_id", "")
+	name := getStringArg(args, "member", "")
+	if listID == "" || name == "" {
+		return nil, fmt.Errorf("list_id and member are required")
+	}
+
+	list, err := s.ownedList(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	if s.householdWide {
+		return nil, fmt.Errorf("'%s' is already shared with the whole household", list.Name)
+	}
+
+	profile, err := s.member(name)
+	if err != nil {
+		return nil, err
+	}
+	actorID, actorName := s.actor(ctx)
+	if profile.UserID == actorID {
+		return nil, fmt.Errorf("'%s' is already your list", list.Name)
+	}
+
+	if err := s.store.ShareList(&ListMember{
+		ListID:   list.ID,
+		UserID:   profile.UserID,
+		Name:     profile.Label(),
+		SharedBy: actorID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to share list: %w", err)
+	}
+
+	s.send(ctx, profile.UserID, notify.Notification{
+		Title: fmt.Sprintf("%s shared the shopping list '%s' with you", nameOr(actorName, "Someone"), list.Name),
+		Body:  "You can add items and check them off, and you'll hear when others do.",
+	})
+
+	return map[string]interface{}{
+		"success": true,
+		"list":    list.Name,
+		"member":  profile.Label(),
+		"message": fmt.Sprintf("Shared '%s' with %s", list.Name, profile.Label()),
+	}, nil
+}
+
+func (s *ShoppingSkill) handleUnshareList(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	listID := getStringArg(args, "list_id", "")
+	name := getStringArg(args, "member", "")
+	if listID == "" || name == "" {
+		return nil, fmt.Errorf("list_id and member are required")
+	}
+
+	list, err := s.accessibleList(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := s.member(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Members may leave a list; only the owner may remove others
+	actorID, _ := s.actor(ctx)
+	if list.UserID != s.getUserID(ctx) && profile.UserID != actorID {
+		return nil, fmt.Errorf("only the list's owner can do that")
+	}
+	if err := s.store.UnshareList(list.ID, profile.UserID); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"list":    list.Name,
+		"member":  profile.Label(),
+		"message": fmt.Sprintf("'%s' is no longer shared with %s", list.Name, profile.Label()),
+	}, nil
+}
+
+// member looks up the household profile a list is shared with
+func (s *ShoppingSkill) member(name string) (*household.Profile, error) {
+	if s.members == nil {
+		return nil, fmt.Errorf("sharing needs household profiles; add them with 'myrai household add'")
+	}
+	profile, err := s.members.Get(strings.ToLower(strings.TrimSpace(name)))
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("no household member named '%s'", name)
+	}
+	return profile, nil
+}
+
+// sharedWith names who else can see a list
+func (s *ShoppingSkill) sharedWith(list *ShoppingList) []string {
+	members, err := s.store.ListMembers(list.ID)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, m := range members {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+// audience returns the user IDs that see a list: the whole household when
+// shopping data is shared, otherwise the owner and the members
+func (s *ShoppingSkill) audience(list *ShoppingList) []string {
+	var ids []string
+	if s.householdWide && s.members != nil {
+		profiles, err := s.members.List()
+		if err != nil {
+			s.logger.Warn("Failed to list household members", zap.Error(err))
+		}
+		for _, p := range profiles {
+			ids = append(ids, p.UserID)
+		}
+	} else {
+		ids = append(ids, list.UserID)
+	}
+
+	members, err := s.store.ListMembers(list.ID)
+	if err != nil {
+		s.logger.Warn("Failed to list shopping list members", zap.String("list_id", list.ID), zap.Error(err))
+	}
+	for _, m := range members {
+		ids = append(ids, m.UserID)
+	}
+	return ids
+}
+
+// notifyChange tells everyone else who sees a list what the requester changed
+func (s *ShoppingSkill) notifyChange(ctx context.Context, list *ShoppingList, change string) {
+	if s.notifier == nil {
+		return
+	}
+	actorID, actorName := s.actor(ctx)
+	title := fmt.Sprintf("🛒 %s %s", nameOr(actorName, "Someone"), change)
+
+	seen := map[string]bool{actorID: true}
+	for _, userID := range s.audience(list) {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		s.send(ctx, userID, notify.Notification{Title: title, Body: "List: " + list.Name})
+	}
+}
+
+func (s *ShoppingSkill) send(ctx context.Context, userID string, n notify.Notification) {
+	if s.notifier == nil {
+		return
+	}
+	n.UserID = userID
+	n.Category = "shopping"
+	if _, err := s.notifier.Send(ctx, n); err != nil {
+		s.logger.Warn("Failed to send shopping list notification",
+			zap.String("user_id", userID),
+			zap.String("title", n.Title),
+			zap.Error(err))
+	}
+}
+
+// itemNames lists up to three names and how many more there are
+func itemNames(names []string) string {
+	if len(names) <= 3 {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:3], ", "), len(names)-3)
+}
+
+func nameOr(name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return name
+}
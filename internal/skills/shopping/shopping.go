@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/gmsas95/myrai-cli/internal/notify"
 	"github.com/gmsas95/myrai-cli/internal/skills"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -12,9 +15,13 @@ import (
 // ShoppingSkill provides shopping list management
 type ShoppingSkill struct {
 	*skills.BaseSkill
-	store  *Store
-	parser *Parser
-	logger *zap.Logger
+	store    *Store
+	logger   *zap.Logger
+	events   *events.Bus
+	notifier notify.Sender
+	members  Members
+
+	householdWide bool
 }
 
 // NewShoppingSkill creates a new shopping skill
@@ -27,7 +34,6 @@ func NewShoppingSkill(db *gorm.DB, logger *zap.Logger) (*ShoppingSkill, error) {
 	skill := &ShoppingSkill{
 		BaseSkill: skills.NewBaseSkill("shopping", "Shopping Lists", "1.0.0"),
 		store:     store,
-		parser:    NewParser(),
 		logger:    logger,
 	}
 
@@ -35,6 +41,11 @@ func NewShoppingSkill(db *gorm.DB, logger *zap.Logger) (*ShoppingSkill, error) {
 	return skill, nil
 }
 
+// SetEventBus sets the bus checked-off items are announced on
+func (s *ShoppingSkill) SetEventBus(bus *events.Bus) {
+	s.events = bus
+}
+
 func (s *ShoppingSkill) registerTools() {
 	tools := []skills.Tool{
 		{
@@ -83,6 +94,7 @@ func (s *ShoppingSkill) registerTools() {
 		},
 		{
 			Name:        "get_shopping_list",
+			ReadOnly:    true,
 			Description: "Get shopping list details including all items",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -102,6 +114,7 @@ func (s *ShoppingSkill) registerTools() {
 		},
 		{
 			Name:        "get_shopping_lists",
+			ReadOnly:    true,
 			Description: "Get all shopping lists for the user",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -197,8 +210,45 @@ func (s *ShoppingSkill) registerTools() {
 				"required": []string{"list_id"},
 			},
 		},
+		{
+			Name:        "share_shopping_list",
+			Description: "Share a shopping list with another household member so both can add and check off items. Both hear about each other's changes.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"list_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the shopping list to share",
+					},
+					"member": map[string]interface{}{
+						"type":        "string",
+						"description": "Household member's profile name (e.g., 'maya')",
+					},
+				},
+				"required": []string{"list_id", "member"},
+			},
+		},
+		{
+			Name:        "unshare_shopping_list",
+			Description: "Stop sharing a shopping list with a household member, or leave a list shared with you",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"list_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the shopping list",
+					},
+					"member": map[string]interface{}{
+						"type":        "string",
+						"description": "Household member's profile name",
+					},
+				},
+				"required": []string{"list_id", "member"},
+			},
+		},
 		{
 			Name:        "get_shopping_suggestions",
+			ReadOnly:    true,
 			Description: "Get smart suggestions for items based on shopping patterns",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -216,6 +266,7 @@ func (s *ShoppingSkill) registerTools() {
 		},
 		{
 			Name:        "get_shopping_stats",
+			ReadOnly:    true,
 			Description: "Get shopping statistics and insights",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -224,6 +275,8 @@ func (s *ShoppingSkill) registerTools() {
 		},
 	}
 
+	tools = append(tools, s.pantryTools()...)
+
 	for _, tool := range tools {
 		tool.Handler = s.handleTool(tool.Name)
 		s.AddTool(tool)
@@ -253,6 +306,18 @@ func (s *ShoppingSkill) handleTool(name string) skills.ToolHandler {
 			return s.handleCompleteList(ctx, args)
 		case "delete_shopping_list":
 			return s.handleDeleteList(ctx, args)
+		case "share_shopping_list":
+			return s.handleShareList(ctx, args)
+		case "unshare_shopping_list":
+			return s.handleUnshareList(ctx, args)
+		case "update_pantry":
+			return s.handleUpdatePantry(ctx, args)
+		case "get_pantry":
+			return s.handleGetPantry(ctx, args)
+		case "use_pantry_items":
+			return s.handleUsePantryItems(ctx, args)
+		case "add_recipe_ingredients":
+			return s.handleAddRecipeIngredients(ctx, args)
 		case "get_shopping_suggestions":
 			return s.handleGetSuggestions(ctx, args)
 		case "get_shopping_stats":
@@ -321,8 +386,6 @@ func (s *ShoppingSkill) handleCreateList(ctx context.Context, args map[string]in
 }
 
 func (s *ShoppingSkill) handleAddItems(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-	userID := s.getUserID(ctx)
-
 	listID := getStringArg(args, "list_id", "default")
 	itemsText := getStringArg(args, "items", "")
 
@@ -331,54 +394,28 @@ func (s *ShoppingSkill) handleAddItems(ctx context.Context, args map[string]inte
 	}
 
 	// Parse items from natural language
-	parsedItems := s.parser.ParseItems(itemsText)
+	loc := locale.FromContext(ctx)
+	parsedItems := NewParser().WithLocale(loc).ParseItems(itemsText)
 
 	if len(parsedItems) == 0 {
 		return nil, fmt.Errorf("could not parse any items from input")
 	}
 
-	// Create default list if needed
-	if listID == "default" {
-		lists, err := s.store.ListLists(userID, true)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(lists) == 0 {
-			// Create default list
-			list := &ShoppingList{
-				UserID:   userID,
-				Name:     "My Shopping List",
-				Category: "general",
-				IsActive: true,
-			}
-			if err := s.store.CreateList(list); err != nil {
-				return nil, err
-			}
-			listID = list.ID
-		} else {
-			listID = lists[0].ID
-		}
-	}
-
-	// Verify list exists and belongs to user
-	list, err := s.store.GetList(listID)
+	// Verify list exists and the user can see it
+	list, err := s.resolveList(ctx, listID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get list: %w", err)
-	}
-	if list == nil {
-		return nil, fmt.Errorf("list not found")
-	}
-	if list.UserID != userID {
-		return nil, fmt.Errorf("unauthorized")
+		return nil, err
 	}
+	actorID, _ := s.actor(ctx)
 
 	// Add items
 	var addedItems []map[string]interface{}
+	var names []string
 	for _, parsed := range parsedItems {
 		item := &ShoppingItem{
-			ListID:         listID,
-			UserID:         userID,
+			ListID:         list.ID,
+			UserID:         list.UserID,
+			AddedBy:        actorID,
 			Name:           parsed.Name,
 			Quantity:       parsed.Quantity,
 			Unit:           parsed.Unit,
@@ -386,6 +423,7 @@ func (s *ShoppingSkill) handleAddItems(ctx context.Context, args map[string]inte
 			Priority:       parsed.Priority,
 			StoreAisle:     parsed.StoreAisle,
 			EstimatedPrice: parsed.EstimatedPrice,
+			Currency:       parsed.Currency,
 			Notes:          parsed.Notes,
 			IsChecked:      false,
 		}
@@ -394,22 +432,28 @@ func (s *ShoppingSkill) handleAddItems(ctx context.Context, args map[string]inte
 			return nil, fmt.Errorf("failed to add item %s: %w", item.Name, err)
 		}
 
-		addedItems = append(addedItems, map[string]interface{}{
+		added := map[string]interface{}{
 			"id":       item.ID,
 			"name":     item.Name,
-			"quantity": fmt.Sprintf("%s %s", item.Quantity, item.Unit),
+			"quantity": loc.Quantity(item.Quantity, item.Unit),
 			"category": item.Category,
 			"priority": item.Priority,
-		})
+		}
+		if item.EstimatedPrice > 0 {
+			added["estimated_price"] = loc.Money(item.EstimatedPrice, item.Currency)
+		}
+		addedItems = append(addedItems, added)
+		names = append(names, item.Name)
 	}
+	s.notifyChange(ctx, list, "added "+itemNames(names))
 
 	s.logger.Info("Items added to shopping list",
-		zap.String("list_id", listID),
+		zap.String("list_id", list.ID),
 		zap.Int("count", len(addedItems)),
 	)
 
 	return map[string]interface{}{
-		"list_id":     listID,
+		"list_id":     list.ID,
 		"list_name":   list.Name,
 		"added_count": len(addedItems),
 		"items":       addedItems,
@@ -417,6 +461,35 @@ func (s *ShoppingSkill) handleAddItems(ctx context.Context, args map[string]inte
 	}, nil
 }
 
+// resolveList loads the list items are added to. "default" is the user's
+// first active list, created if they have none.
+func (s *ShoppingSkill) resolveList(ctx context.Context, listID string) (*ShoppingList, error) {
+	if listID == "" || listID == "default" {
+		userID := s.getUserID(ctx)
+		lists, err := s.store.ListLists(userID, true)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(lists) == 0 {
+			// Create default list
+			list := &ShoppingList{
+				UserID:   userID,
+				Name:     "My Shopping List",
+				Category: "general",
+				IsActive: true,
+			}
+			if err := s.store.CreateList(list); err != nil {
+				return nil, err
+			}
+			listID = list.ID
+		} else {
+			listID = lists[0].ID
+		}
+	}
+	return s.accessibleList(ctx, listID)
+}
+
 func (s *ShoppingSkill) handleGetList(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	userID := s.getUserID(ctx)
 
@@ -427,16 +500,10 @@ func (s *ShoppingSkill) handleGetList(ctx context.Context, args map[string]inter
 		return nil, fmt.Errorf("list_id is required")
 	}
 
-	list, err := s.store.GetList(listID)
+	list, err := s.accessibleList(ctx, listID)
 	if err != nil {
 		return nil, err
 	}
-	if list == nil {
-		return nil, fmt.Errorf("list not found")
-	}
-	if list.UserID != userID {
-		return nil, fmt.Errorf("unauthorized")
-	}
 
 	items, err := s.store.GetItemsByList(listID)
 	if err != nil {
@@ -454,6 +521,12 @@ func (s *ShoppingSkill) handleGetList(ctx context.Context, args map[string]inter
 		"item_count":  len(items),
 		"items":       items,
 	}
+	if shared := s.sharedWith(list); len(shared) > 0 {
+		result["shared_with"] = shared
+	}
+	if list.UserID != userID {
+		result["shared_with_you"] = true
+	}
 
 	// Add grouped view if requested
 	if groupBy == "category" && len(items) > 0 {
@@ -486,7 +559,7 @@ func (s *ShoppingSkill) handleGetLists(ctx context.Context, args map[string]inte
 			}
 		}
 
-		summaries = append(summaries, map[string]interface{}{
+		summary := map[string]interface{}{
 			"id":            list.ID,
 			"name":          list.Name,
 			"category":      list.Category,
@@ -494,7 +567,14 @@ func (s *ShoppingSkill) handleGetLists(ctx context.Context, args map[string]inte
 			"total_items":   len(items),
 			"checked_items": checkedCount,
 			"progress":      fmt.Sprintf("%d/%d", checkedCount, len(items)),
-		})
+		}
+		if shared := s.sharedWith(&list); len(shared) > 0 {
+			summary["shared_with"] = shared
+		}
+		if list.UserID != userID {
+			summary["shared_with_you"] = true
+		}
+		summaries = append(summaries, summary)
 	}
 
 	return map[string]interface{}{
@@ -511,20 +591,38 @@ func (s *ShoppingSkill) handleCheckItem(ctx context.Context, args map[string]int
 		return nil, fmt.Errorf("item_id is required")
 	}
 
-	item, err := s.store.GetItem(itemID)
+	item, list, err := s.accessibleItem(ctx, itemID)
 	if err != nil {
 		return nil, err
 	}
-	if item == nil {
-		return nil, fmt.Errorf("item not found")
-	}
-	if item.UserID != userID {
-		return nil, fmt.Errorf("unauthorized")
-	}
 
-	if err := s.store.CheckItem(itemID); err != nil {
+	actorID, _ := s.actor(ctx)
+	checked, err := s.store.CheckItemBy(itemID, actorID)
+	if err != nil {
 		return nil, err
 	}
+	if !checked {
+		// Someone else got there first; nothing to undo or announce
+		return map[string]interface{}{
+			"success": true,
+			"item":    item.Name,
+			"message": fmt.Sprintf("'%s' was already checked off", item.Name),
+		}, nil
+	}
+	s.notifyChange(ctx, list, "checked off "+item.Name)
+
+	s.events.Publish(ctx, events.Event{
+		Type:   events.ShoppingItemChecked,
+		UserID: userID,
+		Source: s.Name(),
+		Data: map[string]interface{}{
+			"item_id":  item.ID,
+			"list_id":  item.ListID,
+			"item":     item.Name,
+			"category": item.Category,
+			"quantity": item.Quantity,
+		},
+	})
 
 	return map[string]interface{}{
 		"success": true,
@@ -534,27 +632,24 @@ func (s *ShoppingSkill) handleCheckItem(ctx context.Context, args map[string]int
 }
 
 func (s *ShoppingSkill) handleUncheckItem(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-	userID := s.getUserID(ctx)
 	itemID := getStringArg(args, "item_id", "")
 
 	if itemID == "" {
 		return nil, fmt.Errorf("item_id is required")
 	}
 
-	item, err := s.store.GetItem(itemID)
+	item, list, err := s.accessibleItem(ctx, itemID)
 	if err != nil {
 		return nil, err
 	}
-	if item == nil {
-		return nil, fmt.Errorf("item not found")
-	}
-	if item.UserID != userID {
-		return nil, fmt.Errorf("unauthorized")
-	}
 
-	if err := s.store.UncheckItem(itemID); err != nil {
+	unchecked, err := s.store.UncheckItemBy(itemID)
+	if err != nil {
 		return nil, err
 	}
+	if unchecked {
+		s.notifyChange(ctx, list, "put "+item.Name+" back on the list")
+	}
 
 	return map[string]interface{}{
 		"success": true,
@@ -564,27 +659,21 @@ func (s *ShoppingSkill) handleUncheckItem(ctx context.Context, args map[string]i
 }
 
 func (s *ShoppingSkill) handleRemoveItem(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-	userID := s.getUserID(ctx)
 	itemID := getStringArg(args, "item_id", "")
 
 	if itemID == "" {
 		return nil, fmt.Errorf("item_id is required")
 	}
 
-	item, err := s.store.GetItem(itemID)
+	item, list, err := s.accessibleItem(ctx, itemID)
 	if err != nil {
 		return nil, err
 	}
-	if item == nil {
-		return nil, fmt.Errorf("item not found")
-	}
-	if item.UserID != userID {
-		return nil, fmt.Errorf("unauthorized")
-	}
 
 	if err := s.store.DeleteItem(itemID); err != nil {
 		return nil, err
 	}
+	s.notifyChange(ctx, list, "removed "+item.Name)
 
 	return map[string]interface{}{
 		"success": true,
@@ -594,23 +683,15 @@ func (s *ShoppingSkill) handleRemoveItem(ctx context.Context, args map[string]in
 }
 
 func (s *ShoppingSkill) handleClearChecked(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-	userID := s.getUserID(ctx)
 	listID := getStringArg(args, "list_id", "")
 
 	if listID == "" {
 		return nil, fmt.Errorf("list_id is required")
 	}
 
-	list, err := s.store.GetList(listID)
-	if err != nil {
+	if _, err := s.accessibleList(ctx, listID); err != nil {
 		return nil, err
 	}
-	if list == nil {
-		return nil, fmt.Errorf("list not found")
-	}
-	if list.UserID != userID {
-		return nil, fmt.Errorf("unauthorized")
-	}
 
 	if err := s.store.ClearCheckedItems(listID); err != nil {
 		return nil, err
@@ -623,27 +704,21 @@ func (s *ShoppingSkill) handleClearChecked(ctx context.Context, args map[string]
 }
 
 func (s *ShoppingSkill) handleCompleteList(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-	userID := s.getUserID(ctx)
 	listID := getStringArg(args, "list_id", "")
 
 	if listID == "" {
 		return nil, fmt.Errorf("list_id is required")
 	}
 
-	list, err := s.store.GetList(listID)
+	list, err := s.accessibleList(ctx, listID)
 	if err != nil {
 		return nil, err
 	}
-	if list == nil {
-		return nil, fmt.Errorf("list not found")
-	}
-	if list.UserID != userID {
-		return nil, fmt.Errorf("unauthorized")
-	}
 
 	if err := s.store.CompleteList(listID); err != nil {
 		return nil, err
 	}
+	s.notifyChange(ctx, list, "finished the shopping")
 
 	return map[string]interface{}{
 		"success": true,
@@ -653,26 +728,21 @@ func (s *ShoppingSkill) handleCompleteList(ctx context.Context, args map[string]
 }
 
 func (s *ShoppingSkill) handleDeleteList(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-	userID := s.getUserID(ctx)
 	listID := getStringArg(args, "list_id", "")
 
 	if listID == "" {
 		return nil, fmt.Errorf("list_id is required")
 	}
 
-	list, err := s.store.GetList(listID)
+	list, err := s.ownedList(ctx, listID)
 	if err != nil {
 		return nil, err
 	}
-	if list == nil {
-		return nil, fmt.Errorf("list not found")
-	}
-	if list.UserID != userID {
-		return nil, fmt.Errorf("unauthorized")
-	}
 
 	listName := list.Name
 
+	// Members are told before they lose the list
+	s.notifyChange(ctx, list, "deleted the list")
 	if err := s.store.DeleteList(listID); err != nil {
 		return nil, err
 	}
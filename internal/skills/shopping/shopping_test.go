@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 
+	"github.com/gmsas95/myrai-cli/internal/household"
+	"github.com/gmsas95/myrai-cli/internal/notify"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -502,3 +504,148 @@ func TestShoppingSkill_GetStats(t *testing.T) {
 	assert.Equal(t, 2, resp["total_items"])
 	assert.Equal(t, 1, resp["checked_items"])
 }
+
+type recordingNotifier struct {
+	sent []notify.Notification
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, notification notify.Notification) (string, error) {
+	n.sent = append(n.sent, notification)
+	return notify.StatusSent, nil
+}
+
+func TestShoppingSkill_SharedList(t *testing.T) {
+	skill, db := setupTestSkill(t)
+	members, err := household.NewManager(db)
+	require.NoError(t, err)
+	notifier := &recordingNotifier{}
+	skill.SetHousehold(members, false)
+	skill.SetNotifier(notifier)
+
+	alex, err := members.Add("alex", "Alex", household.RoleOwner)
+	require.NoError(t, err)
+	maya, err := members.Add("maya", "Maya", household.RoleMember)
+	require.NoError(t, err)
+	asProfile := func(p *household.Profile) context.Context {
+		ctx := household.WithProfile(context.Background(), p)
+		return context.WithValue(ctx, "user_id", p.UserID)
+	}
+	alexCtx, mayaCtx := asProfile(alex), asProfile(maya)
+
+	created, err := skill.handleCreateList(alexCtx, map[string]interface{}{"name": "Groceries"})
+	require.NoError(t, err)
+	listID := created.(map[string]interface{})["id"].(string)
+
+	_, err = skill.handleGetList(mayaCtx, map[string]interface{}{"list_id": listID})
+	assert.Error(t, err, "not shared yet")
+
+	_, err = skill.handleShareList(alexCtx, map[string]interface{}{"list_id": listID, "member": "Maya"})
+	require.NoError(t, err)
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, maya.UserID, notifier.sent[0].UserID)
+
+	// Maya sees the list and her changes reach Alex
+	lists, err := skill.handleGetLists(mayaCtx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, lists.(map[string]interface{})["count"])
+
+	added, err := skill.handleAddItems(mayaCtx, map[string]interface{}{"list_id": listID, "items": "milk, eggs"})
+	require.NoError(t, err)
+	require.Len(t, notifier.sent, 2)
+	assert.Equal(t, alex.UserID, notifier.sent[1].UserID)
+	assert.Equal(t, "shopping", notifier.sent[1].Category)
+	assert.Contains(t, notifier.sent[1].Title, "Maya added")
+
+	itemID := added.(map[string]interface{})["items"].([]map[string]interface{})[0]["id"].(string)
+	_, err = skill.handleCheckItem(mayaCtx, map[string]interface{}{"item_id": itemID})
+	require.NoError(t, err)
+	item, err := skill.store.GetItem(itemID)
+	require.NoError(t, err)
+	assert.Equal(t, maya.UserID, item.CheckedBy)
+
+	// Checking it off again is harmless and nobody hears about it twice
+	result, err := skill.handleCheckItem(alexCtx, map[string]interface{}{"item_id": itemID})
+	require.NoError(t, err)
+	assert.Contains(t, result.(map[string]interface{})["message"], "already checked off")
+	assert.Len(t, notifier.sent, 3)
+
+	_, err = skill.handleDeleteList(mayaCtx, map[string]interface{}{"list_id": listID})
+	assert.Error(t, err, "only the owner deletes")
+
+	_, err = skill.handleUnshareList(alexCtx, map[string]interface{}{"list_id": listID, "member": "maya"})
+	require.NoError(t, err)
+	_, err = skill.handleGetList(mayaCtx, map[string]interface{}{"list_id": listID})
+	assert.Error(t, err)
+
+	skill.SetHousehold(members, true)
+	_, err = skill.handleShareList(alexCtx, map[string]interface{}{"list_id": listID, "member": "maya"})
+	assert.Error(t, err, "household lists are already shared")
+}
+
+func TestShoppingSkill_PantryAndRecipes(t *testing.T) {
+	skill, _ := setupTestSkill(t)
+	ctx := context.Background()
+
+	_, err := skill.handleUpdatePantry(ctx, map[string]interface{}{"items": "12 eggs, 1 kg rice, olive oil"})
+	require.NoError(t, err)
+	_, err = skill.handleUpdatePantry(ctx, map[string]interface{}{"items": "500 g rice"})
+	require.NoError(t, err)
+	rice, err := skill.store.GetPantryItem("default_user", pantryKey("rice"))
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, rice.Quantity)
+	assert.Equal(t, "kg", rice.Unit)
+
+	result, err := skill.handleAddRecipeIngredients(ctx, map[string]interface{}{
+		"recipe": "Fried rice",
+		"ingredients": []interface{}{
+			map[string]interface{}{"name": "Eggs", "quantity": float64(4)},
+			map[string]interface{}{"name": "rice", "quantity": float64(2000), "unit": "grams"},
+			map[string]interface{}{"name": "olive oil", "quantity": float64(2), "unit": "tbsp"},
+			map[string]interface{}{"name": "soy sauce", "quantity": float64(3), "unit": "tbsp"},
+			map[string]interface{}{"name": "salt"},
+		},
+	})
+	require.NoError(t, err)
+	r := result.(map[string]interface{})
+	added := r["added"].([]map[string]interface{})
+	skipped := r["skipped"].([]map[string]interface{})
+
+	var names []string
+	for _, a := range added {
+		names = append(names, a["name"].(string))
+	}
+	assert.Equal(t, []string{"rice", "soy sauce", "salt"}, names)
+	assert.Equal(t, "500 g (~17.64 oz)", added[0]["quantity"], "only the shortfall is bought")
+	assert.Len(t, skipped, 2)
+	assert.Contains(t, skipped[1]["reason"], "check it's enough")
+
+	items, err := skill.store.GetItemsByList(r["list_id"].(string))
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, "recipe", items[0].AddedFrom)
+	assert.Equal(t, "for Fried rice", items[0].Notes)
+
+	// Adding the recipe again doesn't duplicate what's waiting on the list
+	result, err = skill.handleAddRecipeIngredients(ctx, map[string]interface{}{
+		"ingredients": []interface{}{map[string]interface{}{"name": "Soy sauce", "quantity": float64(3), "unit": "tbsp"}},
+		"list_id":     r["list_id"],
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.(map[string]interface{})["added"])
+
+	result, err = skill.handleUsePantryItems(ctx, map[string]interface{}{"items": "12 eggs, 200 g rice, 2 cups flour", "add_to_list": true})
+	require.NoError(t, err)
+	r = result.(map[string]interface{})
+	assert.Equal(t, []string{"Eggs"}, r["ran_out"])
+	assert.Len(t, r["problems"], 1, "flour isn't tracked")
+	rice, err = skill.store.GetPantryItem("default_user", "rice")
+	require.NoError(t, err)
+	assert.Equal(t, 1.3, rice.Quantity)
+
+	pantry, err := skill.handleGetPantry(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Eggs"}, pantry.(map[string]interface{})["out_of_stock"])
+	items, err = skill.store.GetItemsByList(items[0].ListID)
+	require.NoError(t, err)
+	assert.Len(t, items, 4, "eggs went back on the list")
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/store"
 	"gorm.io/gorm"
 )
 
@@ -13,15 +14,26 @@ type Store struct {
 	db *gorm.DB
 }
 
+func init() {
+	store.RegisterMigrations("shopping", store.Migration{
+		Version: 1,
+		Name:    "create shopping tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&ShoppingList{}, &ShoppingItem{}, &StoreLocation{}, &ListMember{}, &PantryItem{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&ShoppingList{}, &ShoppingItem{}, &StoreLocation{}, &ListMember{}, &PantryItem{})
+		},
+	})
+}
+
 // NewStore creates a new shopping store
 func NewStore(db *gorm.DB) (*Store, error) {
-	store := &Store{db: db}
-
-	if err := db.AutoMigrate(&ShoppingList{}, &ShoppingItem{}, &StoreLocation{}); err != nil {
+	if err := store.Migrate(db, "shopping"); err != nil {
 		return nil, fmt.Errorf("failed to migrate shopping schemas: %w", err)
 	}
 
-	return store, nil
+	return &Store{db: db}, nil
 }
 
 // List operations
@@ -56,13 +68,16 @@ func (s *Store) UpdateList(list *ShoppingList) error {
 }
 
 func (s *Store) DeleteList(listID string) error {
-	// Delete items first
+	// Delete items and members first
 	s.db.Where("list_id = ?", listID).Delete(&ShoppingItem{})
+	s.db.Where("list_id = ?", listID).Delete(&ListMember{})
 	return s.db.Where("id = ?", listID).Delete(&ShoppingList{}).Error
 }
 
+// ListLists returns the user's lists and the lists shared with them
 func (s *Store) ListLists(userID string, activeOnly bool) ([]ShoppingList, error) {
-	query := s.db.Where("user_id = ?", userID)
+	shared := s.db.Model(&ListMember{}).Select("list_id").Where("user_id = ?", userID)
+	query := s.db.Where("user_id = ? OR id IN (?)", userID, shared)
 	if activeOnly {
 		query = query.Where("is_active = ? AND completed_at IS NULL", true)
 	}
@@ -72,6 +87,34 @@ func (s *Store) ListLists(userID string, activeOnly bool) ([]ShoppingList, error
 	return lists, err
 }
 
+// Sharing
+
+// ShareList gives a member access to a list. Sharing it again updates the
+// member's name.
+func (s *Store) ShareList(member *ListMember) error {
+	member.CreatedAt = time.Now()
+	return s.db.Save(member).Error
+}
+
+// UnshareList removes userID's access to a list
+func (s *Store) UnshareList(listID, userID string) error {
+	return s.db.Where("list_id = ? AND user_id = ?", listID, userID).Delete(&ListMember{}).Error
+}
+
+// ListMembers returns who a list is shared with
+func (s *Store) ListMembers(listID string) ([]ListMember, error) {
+	var members []ListMember
+	err := s.db.Where("list_id = ?", listID).Order("created_at ASC").Find(&members).Error
+	return members, err
+}
+
+// IsMember reports whether a list is shared with userID
+func (s *Store) IsMember(listID, userID string) (bool, error) {
+	var count int64
+	err := s.db.Model(&ListMember{}).Where("list_id = ? AND user_id = ?", listID, userID).Count(&count).Error
+	return count > 0, err
+}
+
 // Item operations
 
 func (s *Store) CreateItem(item *ShoppingItem) error {
@@ -115,26 +158,76 @@ func (s *Store) GetUncheckedItems(userID string) ([]ShoppingItem, error) {
 }
 
 func (s *Store) CheckItem(itemID string) error {
+	_, err := s.CheckItemBy(itemID, "")
+	return err
+}
+
+// CheckItemBy checks an item off for checkedBy. It only touches the item's
+// checked state, so members editing the same list at once don't overwrite
+// each other, and reports false when someone else checked it off first.
+func (s *Store) CheckItemBy(itemID, checkedBy string) (bool, error) {
 	now := time.Now()
-	return s.db.Model(&ShoppingItem{}).Where("id = ?", itemID).Updates(map[string]interface{}{
+	result := s.db.Model(&ShoppingItem{}).Where("id = ? AND is_checked = ?", itemID, false).Updates(map[string]interface{}{
 		"is_checked": true,
 		"checked_at": &now,
+		"checked_by": checkedBy,
 		"updated_at": now,
-	}).Error
+	})
+	return result.RowsAffected > 0, result.Error
 }
 
 func (s *Store) UncheckItem(itemID string) error {
-	return s.db.Model(&ShoppingItem{}).Where("id = ?", itemID).Updates(map[string]interface{}{
+	_, err := s.UncheckItemBy(itemID)
+	return err
+}
+
+// UncheckItemBy puts a checked item back on the list and reports false when
+// it wasn't checked
+func (s *Store) UncheckItemBy(itemID string) (bool, error) {
+	result := s.db.Model(&ShoppingItem{}).Where("id = ? AND is_checked = ?", itemID, true).Updates(map[string]interface{}{
 		"is_checked": false,
 		"checked_at": nil,
+		"checked_by": "",
 		"updated_at": time.Now(),
-	}).Error
+	})
+	return result.RowsAffected > 0, result.Error
 }
 
 func (s *Store) ClearCheckedItems(listID string) error {
 	return s.db.Where("list_id = ? AND is_checked = ?", listID, true).Delete(&ShoppingItem{}).Error
 }
 
+// Pantry operations
+
+func (s *Store) SavePantryItem(item *PantryItem) error {
+	if item.ID == "" {
+		item.ID = idgen.Generate(idgen.PrefixShopping)
+		item.CreatedAt = time.Now()
+	}
+	item.UpdatedAt = time.Now()
+	return s.db.Save(item).Error
+}
+
+// GetPantryItem finds a pantry item by its normalized key
+func (s *Store) GetPantryItem(userID, key string) (*PantryItem, error) {
+	var item PantryItem
+	err := s.db.Where("user_id = ? AND key = ?", userID, key).First(&item).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &item, err
+}
+
+func (s *Store) ListPantry(userID string) ([]PantryItem, error) {
+	var items []PantryItem
+	err := s.db.Where("user_id = ?", userID).Order("category, name").Find(&items).Error
+	return items, err
+}
+
+func (s *Store) DeletePantryItem(itemID string) error {
+	return s.db.Where("id = ?", itemID).Delete(&PantryItem{}).Error
+}
+
 // Statistics
 
 func (s *Store) GetStats(userID string) (*ShoppingStats, error) {
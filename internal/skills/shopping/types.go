@@ -76,6 +76,8 @@ type ShoppingItem struct {
 	
 	// Source
 	AddedFrom   string `json:"added_from,omitempty"` // voice, text, scan, recipe
+	AddedBy     string `json:"added_by,omitempty"`   // who added it, on shared lists
+	CheckedBy   string `json:"checked_by,omitempty"` // who checked it off, on shared lists
 	
 	// Notes
 	Notes       string `json:"notes,omitempty"`
@@ -85,6 +87,37 @@ type ShoppingItem struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// ListMember is someone a list is shared with. Members see the list next to
+// their own and can add, check off and remove items.
+type ListMember struct {
+	ListID    string    `json:"list_id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"primaryKey;index"`
+	Name      string    `json:"name"`
+	SharedBy  string    `json:"shared_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName sets the table name
+func (ListMember) TableName() string { return "shopping_list_members" }
+
+// PantryItem is something the user has at home. Recipes skip ingredients the
+// pantry already holds enough of.
+type PantryItem struct {
+	ID       string  `json:"id" gorm:"primaryKey"`
+	UserID   string  `json:"user_id" gorm:"index"`
+	Key      string  `json:"-" gorm:"index"` // normalized name items are matched by
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit,omitempty"`
+	Category string  `json:"category,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName sets the table name
+func (PantryItem) TableName() string { return "shopping_pantry_items" }
+
 // ListWithItems includes list and its items
 type ListWithItems struct {
 	List  ShoppingList   `json:"list"`
@@ -3,11 +3,15 @@ package system
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/sandbox"
+	"github.com/gmsas95/myrai-cli/internal/security"
 	"github.com/gmsas95/myrai-cli/internal/skills"
 )
 
@@ -15,23 +19,59 @@ import (
 type SystemSkill struct {
 	*skills.BaseSkill
 	allowedCommands []string
+	sandbox         *sandbox.Policy
+	files           *security.FilePolicy
+	mu              sync.RWMutex
 }
 
+// defaultAllowedCommands are allowed when none are configured
+var defaultAllowedCommands = []string{"ls", "cat", "grep", "find", "pwd", "echo", "mkdir", "touch", "head", "tail", "wc", "df", "du", "ps", "top", "htop", "curl", "wget", "ping", "nslookup"}
+
 // NewSystemSkill creates a new system skill
 func NewSystemSkill(allowedCommands []string) *SystemSkill {
-	if len(allowedCommands) == 0 {
-		allowedCommands = []string{"ls", "cat", "grep", "find", "pwd", "echo", "mkdir", "touch", "head", "tail", "wc", "df", "du", "ps", "top", "htop", "curl", "wget", "ping", "nslookup"}
-	}
-
 	s := &SystemSkill{
-		BaseSkill:       skills.NewBaseSkill("system", "System commands and file operations", "1.0.0"),
-		allowedCommands: allowedCommands,
+		BaseSkill: skills.NewBaseSkill("system", "System commands and file operations", "1.0.0"),
+		sandbox:   sandbox.Default(),
 	}
+	s.SetAllowedCommands(allowedCommands)
 
 	s.registerTools()
 	return s
 }
 
+// SetAllowedCommands replaces the commands execute_command may run, using
+// the defaults when none are given
+func (s *SystemSkill) SetAllowedCommands(allowedCommands []string) {
+	if len(allowedCommands) == 0 {
+		allowedCommands = defaultAllowedCommands
+	}
+	s.mu.Lock()
+	s.allowedCommands = allowedCommands
+	s.mu.Unlock()
+}
+
+// SetSandbox replaces the policy deciding where and under what limits
+// execute_command runs commands
+func (s *SystemSkill) SetSandbox(policy *sandbox.Policy) {
+	s.mu.Lock()
+	s.sandbox = policy
+	s.mu.Unlock()
+}
+
+// SetFilePolicy replaces the policy deciding which paths the file tools
+// may read and write
+func (s *SystemSkill) SetFilePolicy(policy *security.FilePolicy) {
+	s.mu.Lock()
+	s.files = policy
+	s.mu.Unlock()
+}
+
+func (s *SystemSkill) filePolicy() *security.FilePolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.files
+}
+
 func (s *SystemSkill) registerTools() {
 	s.AddTool(skills.Tool{
 		Name:        "execute_command",
@@ -55,6 +95,7 @@ func (s *SystemSkill) registerTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "read_file",
+		ReadOnly:    true,
 		Description: "Read the contents of a file",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -103,6 +144,7 @@ func (s *SystemSkill) registerTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "list_directory",
+		ReadOnly:    true,
 		Description: "List files in a directory",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -122,6 +164,7 @@ func (s *SystemSkill) registerTools() {
 
 	s.AddTool(skills.Tool{
 		Name:        "system_info",
+		ReadOnly:    true,
 		Description: "Get system information",
 		Parameters: map[string]interface{}{
 			"type":       "object",
@@ -144,12 +187,14 @@ func (s *SystemSkill) handleExecuteCommand(ctx context.Context, args map[string]
 
 	baseCmd := cmdParts[0]
 	allowed := false
+	s.mu.RLock()
 	for _, allowedCmd := range s.allowedCommands {
 		if baseCmd == allowedCmd {
 			allowed = true
 			break
 		}
 	}
+	s.mu.RUnlock()
 
 	if !allowed {
 		return nil, fmt.Errorf("command '%s' is not in allowed list", baseCmd)
@@ -167,24 +212,25 @@ func (s *SystemSkill) handleExecuteCommand(ctx context.Context, args map[string]
 		timeout = int(t)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
-	defer cancel()
+	s.mu.RLock()
+	policy := s.sandbox
+	s.mu.RUnlock()
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", command)
-	output, err := cmd.CombinedOutput()
+	run, err := policy.Run(ctx, command, time.Duration(timeout)*time.Second)
+	if err != nil {
+		return nil, err
+	}
 
 	result := map[string]interface{}{
-		"stdout":    string(output),
-		"exit_code": 0,
+		"stdout":    run.Output,
+		"exit_code": run.ExitCode,
+		"backend":   run.Backend,
 	}
-
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			result["exit_code"] = exitErr.ExitCode()
-		} else {
-			result["exit_code"] = 1
-			result["error"] = err.Error()
-		}
+	if run.TimedOut {
+		result["timed_out"] = true
+	}
+	if run.Truncated {
+		result["truncated"] = true
 	}
 
 	return result, nil
@@ -210,8 +256,11 @@ func (s *SystemSkill) handleReadFile(ctx context.Context, args map[string]interf
 		limit = int(l)
 	}
 
-	cmd := exec.Command("cat", path)
-	output, err := cmd.Output()
+	real, err := s.filePolicy().CheckRead(path)
+	if err != nil {
+		return nil, err
+	}
+	output, err := os.ReadFile(real)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
@@ -243,13 +292,30 @@ func (s *SystemSkill) handleWriteFile(ctx context.Context, args map[string]inter
 		return nil, fmt.Errorf("path contains invalid characters")
 	}
 
-	flag := ">"
+	size := int64(len(content))
 	if append {
-		flag = ">>"
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	real, err := s.filePolicy().CheckWrite(path, size)
+	if err != nil {
+		return nil, err
 	}
 
-	cmd := exec.Command("sh", "-c", fmt.Sprintf("echo %q %s %s", content, flag, path))
-	if err := cmd.Run(); err != nil {
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if append {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	f, err := os.OpenFile(real, flag, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	_, err = f.WriteString(content + "\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -266,13 +332,18 @@ func (s *SystemSkill) handleListDirectory(ctx context.Context, args map[string]i
 		return nil, fmt.Errorf("path contains invalid characters")
 	}
 
+	real, err := s.filePolicy().CheckRead(path)
+	if err != nil {
+		return nil, err
+	}
+
 	recursive, _ := args["recursive"].(bool)
 
 	var cmd *exec.Cmd
 	if recursive {
-		cmd = exec.Command("find", path, "-type", "f", "-o", "-type", "d")
+		cmd = exec.Command("find", real, "-type", "f", "-o", "-type", "d")
 	} else {
-		cmd = exec.Command("ls", "-la", path)
+		cmd = exec.Command("ls", "-la", "--", real)
 	}
 
 	output, err := cmd.Output()
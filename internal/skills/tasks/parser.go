@@ -6,17 +6,21 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/locale"
 )
 
 // DateParser parses natural language dates
 type DateParser struct {
 	referenceTime time.Time
+	locale        locale.Locale
 }
 
 // NewDateParser creates a new date parser
 func NewDateParser() *DateParser {
 	return &DateParser{
 		referenceTime: time.Now(),
+		locale:        locale.Default(),
 	}
 }
 
@@ -26,6 +30,12 @@ func (p *DateParser) WithReference(t time.Time) *DateParser {
 	return p
 }
 
+// WithLocale sets the locale used for numeric dates and week boundaries
+func (p *DateParser) WithLocale(l locale.Locale) *DateParser {
+	p.locale = l
+	return p
+}
+
 // ParseResult contains the parsed date information
 type ParseResult struct {
 	Date        time.Time
@@ -63,18 +73,14 @@ func (p *DateParser) Parse(input string) (*ParseResult, error) {
 
 // parseExactDate parses exact dates like "2024-01-15" or "Jan 15, 2024"
 func (p *DateParser) parseExactDate(input string) (*ParseResult, bool) {
-	formats := []string{
-		"2006-01-02",
-		"2006-01-02 15:04",
-		"2006-01-02 15:04:05",
-		"01/02/2006",
-		"01/02/2006 15:04",
+	// Numeric dates follow the user's day/month order
+	formats := append(p.locale.NumericDateLayouts(),
 		"Jan 2, 2006",
 		"Jan 2, 2006 3:04pm",
 		"January 2, 2006",
 		"2 Jan 2006",
 		"2 January 2006",
-	}
+	)
 	
 	for _, format := range formats {
 		if t, err := time.Parse(format, input); err == nil {
@@ -177,14 +183,10 @@ func (p *DateParser) parseNextPeriod(input string) (*ParseResult, bool) {
 	
 	switch input {
 	case "next week":
-		// Start of next week (Monday)
-		daysUntilMonday := (8 - int(now.Weekday())) % 7
-		if daysUntilMonday == 0 {
-			daysUntilMonday = 7
-		}
-		nextMonday := time.Date(now.Year(), now.Month(), now.Day()+daysUntilMonday, 0, 0, 0, 0, now.Location())
+		// Start of next week, on the user's first day of the week
+		nextWeek := p.locale.StartOfWeek(now).AddDate(0, 0, 7)
 		return &ParseResult{
-			Date:       nextMonday,
+			Date:       nextWeek,
 			HasTime:    false,
 			Confidence: 0.9,
 		}, true
@@ -215,11 +217,9 @@ func (p *DateParser) parseThisPeriod(input string) (*ParseResult, bool) {
 	
 	switch input {
 	case "this week":
-		// Start of this week (Monday)
-		daysSinceMonday := (int(now.Weekday()) + 6) % 7
-		thisMonday := time.Date(now.Year(), now.Month(), now.Day()-daysSinceMonday, 0, 0, 0, 0, now.Location())
+		// Start of this week, on the user's first day of the week
 		return &ParseResult{
-			Date:       thisMonday,
+			Date:       p.locale.StartOfWeek(now),
 			HasTime:    false,
 			Confidence: 0.9,
 		}, true
@@ -473,6 +473,12 @@ func (dp *DurationParser) Parse(input string) (time.Duration, error) {
 
 // FormatRelativeTime formats a time as a relative string
 func FormatRelativeTime(t time.Time) string {
+	return FormatRelativeTimeIn(t, locale.Default())
+}
+
+// FormatRelativeTimeIn formats a time as a relative string, falling back to
+// a date in the given locale for times more than a few weeks away
+func FormatRelativeTimeIn(t time.Time, l locale.Locale) string {
 	duration := time.Until(t)
 	isPast := duration < 0
 	duration = duration.Abs()
@@ -523,5 +529,5 @@ func FormatRelativeTime(t time.Time) string {
 		return fmt.Sprintf("in %d weeks", weeks)
 	}
 	
-	return t.Format("Jan 2, 2006")
+	return l.Date(t)
 }
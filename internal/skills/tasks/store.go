@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/gmsas95/myrai-cli/internal/idgen"
+	"github.com/gmsas95/myrai-cli/internal/recurrence"
+	"github.com/gmsas95/myrai-cli/internal/store"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -14,16 +16,49 @@ type Store struct {
 	db *gorm.DB
 }
 
+func init() {
+	store.RegisterMigrations("tasks", store.Migration{
+		Version: 1,
+		Name:    "create tasks tables",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.AutoMigrate(&Task{}, &Reminder{})
+		},
+		Down: func(tx *gorm.DB, workspace string) error {
+			return tx.Migrator().DropTable(&Task{}, &Reminder{})
+		},
+	})
+	store.RegisterPurge("tasks", purge)
+}
+
+// purge deletes tasks completed or cancelled before cutoff, with their
+// reminders. Open tasks are kept however old they are.
+func purge(db *gorm.DB, before time.Time) (int64, error) {
+	var deleted int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var ids []string
+		err := tx.Model(&Task{}).
+			Where("status IN ? AND updated_at < ?", []TaskStatus{TaskStatusCompleted, TaskStatusCancelled}, before).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		if err := tx.Where("task_id IN ?", ids).Delete(&Reminder{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id IN ?", ids).Delete(&Task{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
 // NewStore creates a new task store
 func NewStore(db *gorm.DB) (*Store, error) {
-	store := &Store{db: db}
-
-	// Auto-migrate schemas
-	if err := db.AutoMigrate(&Task{}, &Reminder{}); err != nil {
+	if err := store.Migrate(db, "tasks"); err != nil {
 		return nil, fmt.Errorf("failed to migrate task schemas: %w", err)
 	}
 
-	return store, nil
+	return &Store{db: db}, nil
 }
 
 // CreateTask creates a new task
@@ -240,7 +275,10 @@ func (s *Store) CreateNextRecurringTask(task *Task) (*Task, error) {
 	// Calculate next due date
 	var nextDueDate time.Time
 	if task.DueDate != nil {
-		nextDueDate = calculateNextDate(*task.DueDate, rule)
+		var ok bool
+		if nextDueDate, ok = nextOccurrence(*task.DueDate, rule); !ok {
+			return nil, nil
+		}
 	}
 
 	// Calculate next reminder
@@ -272,6 +310,7 @@ func (s *Store) CreateNextRecurringTask(task *Task) (*Task, error) {
 		RecurrenceEndDate:     rule.EndDate,
 		RecurrenceOccurrences: rule.Occurrences,
 		RecurrenceCount:       rule.Count + 1,
+		RecurrenceRRule:       rule.RRule,
 	}
 
 	if err := s.CreateTask(nextTask); err != nil {
@@ -281,6 +320,20 @@ func (s *Store) CreateNextRecurringTask(task *Task) (*Task, error) {
 	return nextTask, nil
 }
 
+// nextOccurrence finds the date after from that a task repeats on. ok is
+// false when its rule has run out.
+func nextOccurrence(from time.Time, rule *RecurrenceRule) (next time.Time, ok bool) {
+	if rule.RRule != "" {
+		if r, err := recurrence.Parse(rule.RRule); err == nil {
+			// Occurrences are counted on the task, and each one starts
+			// the series afresh
+			r.Count = 0
+			return r.Next(from, from)
+		}
+	}
+	return calculateNextDate(from, rule), true
+}
+
 // calculateNextDate calculates the next occurrence date
 func calculateNextDate(from time.Time, rule *RecurrenceRule) time.Time {
 	interval := rule.Interval
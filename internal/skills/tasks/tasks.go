@@ -8,6 +8,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/locale"
+	"github.com/gmsas95/myrai-cli/internal/recurrence"
 	"github.com/gmsas95/myrai-cli/internal/skills"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -18,9 +21,9 @@ type TaskSkill struct {
 	*skills.BaseSkill
 	store           *Store
 	scheduler       *ReminderService
-	dateParser      *DateParser
 	logger          *zap.Logger
 	reminderCallback ReminderCallback
+	events           *events.Bus
 }
 
 // TaskConfig contains task skill configuration
@@ -55,7 +58,6 @@ func NewTaskSkill(db *gorm.DB, config TaskConfig, logger *zap.Logger) (*TaskSkil
 		BaseSkill:        skills.NewBaseSkill("tasks", "Task & Reminder Management", "1.0.0"),
 		store:            store,
 		scheduler:        scheduler,
-		dateParser:       NewDateParser(),
 		logger:           logger,
 		reminderCallback: reminderCallback,
 	}
@@ -66,6 +68,11 @@ func NewTaskSkill(db *gorm.DB, config TaskConfig, logger *zap.Logger) (*TaskSkil
 	return skill, nil
 }
 
+// SetEventBus sets the bus completed tasks are announced on
+func (t *TaskSkill) SetEventBus(bus *events.Bus) {
+	t.events = bus
+}
+
 // registerTools registers all task management tools
 func (t *TaskSkill) registerTools() {
 	tools := []skills.Tool{
@@ -115,7 +122,7 @@ func (t *TaskSkill) registerTools() {
 					},
 					"recurrence": map[string]interface{}{
 						"type":        "string",
-						"description": "Recurrence pattern (e.g., 'daily', 'weekly', 'monthly', 'every Monday')",
+						"description": "Recurrence pattern (e.g., 'daily', 'weekly', 'monthly', 'every Monday', 'every 2nd Tuesday', 'last weekday of the month')",
 					},
 				},
 				"required": []string{"title"},
@@ -187,6 +194,7 @@ func (t *TaskSkill) registerTools() {
 		},
 		{
 			Name:        "list_tasks",
+			ReadOnly:    true,
 			Description: "List tasks with optional filters",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -266,6 +274,7 @@ func (t *TaskSkill) registerTools() {
 		},
 		{
 			Name:        "get_task_stats",
+			ReadOnly:    true,
 			Description: "Get task statistics and overview",
 			Parameters: map[string]interface{}{
 				"type": "object",
@@ -321,7 +330,7 @@ func (t *TaskSkill) handleCreateTask(ctx context.Context, args map[string]interf
 	category, _ := args["category"].(string)
 	location, _ := args["location"].(string)
 	remindAtStr, _ := args["remind_at"].(string)
-	recurrence, _ := args["recurrence"].(string)
+	recurrenceText, _ := args["recurrence"].(string)
 	
 	tags := ""
 	if tagsArr, ok := args["tags"].([]interface{}); ok {
@@ -348,7 +357,7 @@ func (t *TaskSkill) handleCreateTask(ctx context.Context, args map[string]interf
 			dateInput = fmt.Sprintf("%s at %s", dueDateStr, dueTimeStr)
 		}
 		
-		result, err := t.dateParser.ExtractDateTime(dateInput)
+		result, err := t.dateParser(ctx).ExtractDateTime(dateInput)
 		if err != nil {
 			return nil, fmt.Errorf("could not parse due date: %w", err)
 		}
@@ -358,7 +367,7 @@ func (t *TaskSkill) handleCreateTask(ctx context.Context, args map[string]interf
 	// Parse reminder
 	var remindAt *time.Time
 	if remindAtStr != "" {
-		remindAt = t.parseReminderTime(remindAtStr, dueDate)
+		remindAt = t.parseReminderTime(ctx, remindAtStr, dueDate)
 	}
 	
 	// Get user ID from context
@@ -379,8 +388,11 @@ func (t *TaskSkill) handleCreateTask(ctx context.Context, args map[string]interf
 	}
 	
 	// Parse recurrence
-	if recurrence != "" {
-		recurrenceRule := t.parseRecurrence(recurrence)
+	if recurrenceText != "" {
+		recurrenceRule, err := t.parseRecurrence(recurrenceText)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse recurrence: %w", err)
+		}
 		task.SetRecurrenceRule(recurrenceRule)
 	}
 	
@@ -400,17 +412,21 @@ func (t *TaskSkill) handleCreateTask(ctx context.Context, args map[string]interf
 		"status":      task.Status,
 		"priority":    task.Priority,
 		"created":     true,
-		"description": t.formatTaskDescription(task),
+		"description": t.formatTaskDescription(ctx, task),
 	}
 	
+	loc := locale.FromContext(ctx)
 	if dueDate != nil {
-		response["due_date"] = dueDate.Format("Jan 2, 2006 3:04 PM")
+		response["due_date"] = loc.DateTime(*dueDate)
 	}
 	if remindAt != nil {
-		response["reminder"] = FormatRelativeTime(*remindAt)
+		response["reminder"] = FormatRelativeTimeIn(*remindAt, loc)
 	}
 	if task.IsRecurring() {
 		response["recurrence"] = task.RecurrenceFrequency
+		if r, err := recurrence.Parse(task.RecurrenceRRule); err == nil {
+			response["repeats"] = r.Describe()
+		}
 	}
 	
 	t.logger.Info("Task created",
@@ -448,7 +464,7 @@ func (t *TaskSkill) handleUpdateTask(ctx context.Context, args map[string]interf
 		task.Priority = Priority(priority)
 	}
 	if dueDateStr, ok := args["due_date"].(string); ok && dueDateStr != "" {
-		result, err := t.dateParser.Parse(dueDateStr)
+		result, err := t.dateParser(ctx).Parse(dueDateStr)
 		if err == nil {
 			task.DueDate = &result.Date
 		}
@@ -577,7 +593,7 @@ func (t *TaskSkill) handleListTasks(ctx context.Context, args map[string]interfa
 	// Format tasks for display
 	formattedTasks := make([]map[string]interface{}, len(list.Tasks))
 	for i, task := range list.Tasks {
-		formattedTasks[i] = t.formatTaskForDisplay(&task)
+		formattedTasks[i] = t.formatTaskForDisplay(ctx, &task)
 	}
 	
 	return map[string]interface{}{
@@ -614,6 +630,18 @@ func (t *TaskSkill) handleCompleteTask(ctx context.Context, args map[string]inte
 		return nil, fmt.Errorf("failed to complete task: %w", err)
 	}
 	
+	t.events.Publish(ctx, events.Event{
+		Type:   events.TaskCompleted,
+		UserID: task.UserID,
+		Source: t.Name(),
+		Data: map[string]interface{}{
+			"task_id":  task.ID,
+			"title":    task.Title,
+			"priority": string(task.Priority),
+			"overdue":  task.DueDate != nil && task.DueDate.Before(time.Now()),
+		},
+	})
+	
 	result := map[string]interface{}{
 		"task_id":   taskID,
 		"completed": true,
@@ -628,7 +656,7 @@ func (t *TaskSkill) handleCompleteTask(ctx context.Context, args map[string]inte
 		} else if nextTask != nil {
 			result["next_occurrence"] = map[string]interface{}{
 				"task_id":  nextTask.ID,
-				"due_date": nextTask.DueDate.Format("Jan 2, 2006"),
+				"due_date": locale.FromContext(ctx).Date(*nextTask.DueDate),
 			}
 		}
 	}
@@ -648,7 +676,7 @@ func (t *TaskSkill) handleSnoozeTask(ctx context.Context, args map[string]interf
 	duration, err := durationParser.Parse(durationStr)
 	if err != nil {
 		// Try to parse as date
-		result, parseErr := t.dateParser.Parse(durationStr)
+		result, parseErr := t.dateParser(ctx).Parse(durationStr)
 		if parseErr != nil {
 			return nil, fmt.Errorf("invalid duration: %s", durationStr)
 		}
@@ -663,7 +691,7 @@ func (t *TaskSkill) handleSnoozeTask(ctx context.Context, args map[string]interf
 		"task_id":  taskID,
 		"snoozed":  true,
 		"duration": durationStr,
-		"until":    time.Now().Add(duration).Format("3:04 PM"),
+		"until":    locale.FromContext(ctx).Time(time.Now().Add(duration)),
 	}, nil
 }
 
@@ -709,7 +737,12 @@ func (t *TaskSkill) getUserID(ctx context.Context) string {
 	return "default_user"
 }
 
-func (t *TaskSkill) parseReminderTime(input string, dueDate *time.Time) *time.Time {
+// dateParser returns a parser for the current time in the caller's locale
+func (t *TaskSkill) dateParser(ctx context.Context) *DateParser {
+	return NewDateParser().WithLocale(locale.FromContext(ctx))
+}
+
+func (t *TaskSkill) parseReminderTime(ctx context.Context, input string, dueDate *time.Time) *time.Time {
 	// Handle relative reminders like "30 minutes before"
 	patterns := []string{
 		`(\d+)\s*minutes?\s*before`,
@@ -740,7 +773,7 @@ func (t *TaskSkill) parseReminderTime(input string, dueDate *time.Time) *time.Ti
 	}
 	
 	// Try to parse as absolute time
-	result, err := t.dateParser.Parse(input)
+	result, err := t.dateParser(ctx).Parse(input)
 	if err == nil {
 		return &result.Date
 	}
@@ -748,75 +781,46 @@ func (t *TaskSkill) parseReminderTime(input string, dueDate *time.Time) *time.Ti
 	return nil
 }
 
-func (t *TaskSkill) parseRecurrence(input string) *RecurrenceRule {
-	input = strings.ToLower(input)
-	
-	rule := &RecurrenceRule{Interval: 1}
-	
-	switch input {
-	case "daily", "every day", "each day":
-		rule.Frequency = FrequencyDaily
-	case "weekly", "every week", "each week":
-		rule.Frequency = FrequencyWeekly
-	case "monthly", "every month", "each month":
-		rule.Frequency = FrequencyMonthly
-	case "yearly", "every year", "each year", "annually":
-		rule.Frequency = FrequencyYearly
-	default:
-		// Check for patterns like "every N days"
-		if strings.HasPrefix(input, "every ") {
-			parts := strings.Fields(input[6:])
-			if len(parts) >= 2 {
-				if n, err := strconv.Atoi(parts[0]); err == nil {
-					rule.Interval = n
-					switch parts[1] {
-					case "day", "days":
-						rule.Frequency = FrequencyDaily
-					case "week", "weeks":
-						rule.Frequency = FrequencyWeekly
-					case "month", "months":
-						rule.Frequency = FrequencyMonthly
-					case "year", "years":
-						rule.Frequency = FrequencyYearly
-					}
-				}
-			}
-		}
-		
-		// Check for weekday patterns
-		days := map[string]int{
-			"sunday": 0, "monday": 1, "tuesday": 2, "wednesday": 3,
-			"thursday": 4, "friday": 5, "saturday": 6,
-		}
-		for day, num := range days {
-			if strings.Contains(input, day) {
-				rule.Frequency = FrequencyWeekly
-				rule.ByWeekday = []int{num}
-				break
-			}
-		}
+// parseRecurrence reads a repeat pattern like "weekly", "every 2nd Tuesday"
+// or "last weekday of the month"
+func (t *TaskSkill) parseRecurrence(input string) (*RecurrenceRule, error) {
+	r, err := recurrence.ParseText(input)
+	if err != nil {
+		return nil, err
 	}
 	
-	if rule.Frequency == "" {
-		return nil
+	rule := &RecurrenceRule{
+		Frequency:   Frequency(strings.ToLower(string(r.Freq))),
+		Interval:    max(r.Interval, 1),
+		Occurrences: r.Count,
+		RRule:       r.String(),
+	}
+	if !r.Until.IsZero() {
+		until := r.Until
+		rule.EndDate = &until
+	}
+	for _, d := range r.ByDay {
+		rule.ByWeekday = append(rule.ByWeekday, int(d.Day))
 	}
+	rule.ByMonthDay = r.ByMonthDay
 	
-	return rule
+	return rule, nil
 }
 
-func (t *TaskSkill) formatTaskDescription(task *Task) string {
+func (t *TaskSkill) formatTaskDescription(ctx context.Context, task *Task) string {
 	parts := []string{task.Title}
 	
 	if task.IsOverdue() {
 		parts = append(parts, "[OVERDUE]")
 	} else if task.DueDate != nil {
-		parts = append(parts, fmt.Sprintf("(Due: %s)", FormatRelativeTime(*task.DueDate)))
+		parts = append(parts, fmt.Sprintf("(Due: %s)", FormatRelativeTimeIn(*task.DueDate, locale.FromContext(ctx))))
 	}
 	
 	return strings.Join(parts, " ")
 }
 
-func (t *TaskSkill) formatTaskForDisplay(task *Task) map[string]interface{} {
+func (t *TaskSkill) formatTaskForDisplay(ctx context.Context, task *Task) map[string]interface{} {
+	loc := locale.FromContext(ctx)
 	result := map[string]interface{}{
 		"id":       task.ID,
 		"title":    task.Title,
@@ -829,13 +833,13 @@ func (t *TaskSkill) formatTaskForDisplay(task *Task) map[string]interface{} {
 	}
 	
 	if task.DueDate != nil {
-		result["due_date"] = task.DueDate.Format("Jan 2, 3:04 PM")
-		result["due_relative"] = FormatRelativeTime(*task.DueDate)
+		result["due_date"] = loc.ShortDateTime(*task.DueDate)
+		result["due_relative"] = FormatRelativeTimeIn(*task.DueDate, loc)
 		result["is_overdue"] = task.IsOverdue()
 	}
 	
 	if task.RemindAt != nil {
-		result["reminder"] = FormatRelativeTime(*task.RemindAt)
+		result["reminder"] = FormatRelativeTimeIn(*task.RemindAt, loc)
 	}
 	
 	if task.Tags != "" {
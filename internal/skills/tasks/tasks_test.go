@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/events"
+	"github.com/gmsas95/myrai-cli/internal/locale"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -318,6 +320,46 @@ func TestDateParser_ExtractDateTime(t *testing.T) {
 	assert.True(t, result.HasTime)
 }
 
+func TestDateParser_Locale(t *testing.T) {
+	// Wednesday 5 March 2025
+	ref := time.Date(2025, 3, 5, 10, 0, 0, 0, time.UTC)
+	gb, _ := locale.ForLanguage("en-GB")
+	
+	us := NewDateParser().WithReference(ref)
+	result, err := us.Parse("04/03/2025")
+	require.NoError(t, err)
+	assert.Equal(t, time.April, result.Date.Month())
+	
+	result, err = us.Parse("this week")
+	require.NoError(t, err)
+	assert.Equal(t, time.Sunday, result.Date.Weekday())
+	
+	uk := NewDateParser().WithReference(ref).WithLocale(gb)
+	result, err = uk.Parse("04/03/2025")
+	require.NoError(t, err)
+	assert.Equal(t, time.March, result.Date.Month())
+	assert.Equal(t, 4, result.Date.Day())
+	
+	result, err = uk.Parse("next week")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), result.Date)
+	
+	assert.Equal(t, "5 Mar 2025", FormatRelativeTimeIn(ref, gb))
+}
+
+func TestTaskSkill_LocaleFormatting(t *testing.T) {
+	skill, _ := setupTestSkill(t)
+	gb, _ := locale.ForLanguage("en-GB")
+	ctx := locale.WithLocale(context.WithValue(context.Background(), "user_id", "user1"), gb)
+	
+	result, err := skill.handleCreateTask(ctx, map[string]interface{}{
+		"title":    "Renew passport",
+		"due_date": "2030-06-01 15:30",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1 Jun 2030 15:30", result.(map[string]interface{})["due_date"])
+}
+
 func TestDurationParser_Parse(t *testing.T) {
 	parser := &DurationParser{}
 	
@@ -452,6 +494,31 @@ func TestTaskSkill_CompleteTask(t *testing.T) {
 	assert.Equal(t, true, resultMap["completed"])
 }
 
+func TestTaskSkill_CompleteTaskPublishesEvent(t *testing.T) {
+	skill, _ := setupTestSkill(t)
+	ctx := context.WithValue(context.Background(), "user_id", "user1")
+	
+	bus := events.NewBus(nil)
+	skill.SetEventBus(bus)
+	var published []events.Event
+	bus.Subscribe(events.TaskCompleted, func(ctx context.Context, e events.Event) {
+		published = append(published, e)
+	})
+	
+	createResult, err := skill.handleCreateTask(ctx, map[string]interface{}{"title": "Water plants"})
+	require.NoError(t, err)
+	taskID := createResult.(map[string]interface{})["task_id"].(string)
+	
+	_, err = skill.handleCompleteTask(ctx, map[string]interface{}{"task_id": taskID})
+	require.NoError(t, err)
+	
+	require.Len(t, published, 1)
+	assert.Equal(t, "user1", published[0].UserID)
+	assert.Equal(t, "tasks", published[0].Source)
+	assert.Equal(t, taskID, published[0].Data["task_id"])
+	assert.Equal(t, "Water plants", published[0].Data["title"])
+}
+
 func TestTaskSkill_SnoozeTask(t *testing.T) {
 	skill, _ := setupTestSkill(t)
 	ctx := context.WithValue(context.Background(), "user_id", "user1")
@@ -642,3 +709,24 @@ func TestCalculateNextDate(t *testing.T) {
 		})
 	}
 }
+
+func TestNextOccurrence_RRule(t *testing.T) {
+	skill, _ := setupTestSkill(t)
+	
+	rule, err := skill.parseRecurrence("last weekday of the month")
+	require.NoError(t, err)
+	assert.Equal(t, FrequencyMonthly, rule.Frequency)
+	
+	// Friday 31 January 2025, then Friday 28 February
+	next, ok := nextOccurrence(time.Date(2025, 1, 31, 17, 0, 0, 0, time.UTC), rule)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2025, 2, 28, 17, 0, 0, 0, time.UTC), next)
+	
+	rule, err = skill.parseRecurrence("every 2nd Tuesday until 2025-03-01")
+	require.NoError(t, err)
+	_, ok = nextOccurrence(time.Date(2025, 2, 11, 9, 0, 0, 0, time.UTC), rule)
+	assert.False(t, ok, "no 2nd Tuesday before the end date")
+	
+	_, err = skill.parseRecurrence("whenever I feel like it")
+	assert.Error(t, err)
+}
@@ -24,6 +24,7 @@ type Task struct {
 	RecurrenceEndDate    *time.Time `json:"recurrence_end_date,omitempty"`
 	RecurrenceOccurrences int    `json:"recurrence_occurrences,omitempty"`
 	RecurrenceCount      int    `json:"recurrence_count,omitempty"`
+	RecurrenceRRule      string `json:"recurrence_rrule,omitempty"` // RRULE for patterns beyond every N periods
 	
 	// Location
 	Location    string  `json:"location,omitempty"`
@@ -71,6 +72,7 @@ type RecurrenceRule struct {
 	EndDate    *time.Time `json:"end_date,omitempty"`
 	Occurrences int      `json:"occurrences,omitempty"`
 	Count       int       `json:"count"` // how many times created so far
+	RRule       string    `json:"rrule,omitempty"` // full rule, e.g. "RRULE:FREQ=MONTHLY;BYDAY=2TU"
 }
 
 // Frequency represents recurrence frequency
@@ -99,6 +101,7 @@ func (t *Task) GetRecurrenceRule() *RecurrenceRule {
 		EndDate:     t.RecurrenceEndDate,
 		Occurrences: t.RecurrenceOccurrences,
 		Count:       t.RecurrenceCount,
+		RRule:       t.RecurrenceRRule,
 	}
 }
 
@@ -132,6 +135,7 @@ func (t *Task) SetRecurrenceRule(rule *RecurrenceRule) {
 		t.RecurrenceEndDate = nil
 		t.RecurrenceOccurrences = 0
 		t.RecurrenceCount = 0
+		t.RecurrenceRRule = ""
 		return
 	}
 	t.RecurrenceFrequency = string(rule.Frequency)
@@ -139,6 +143,7 @@ func (t *Task) SetRecurrenceRule(rule *RecurrenceRule) {
 	t.RecurrenceEndDate = rule.EndDate
 	t.RecurrenceOccurrences = rule.Occurrences
 	t.RecurrenceCount = rule.Count
+	t.RecurrenceRRule = rule.RRule
 }
 
 // Reminder represents a reminder instance
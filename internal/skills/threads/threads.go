@@ -109,6 +109,7 @@ func (s *ThreadsSkill) registerTools() {
 	// Tool 3: Get user info
 	s.AddTool(skills.Tool{
 		Name:        "threads_get_user",
+		ReadOnly:    true,
 		Description: "Get information about the authenticated Threads user",
 		Parameters: map[string]interface{}{
 			"type":       "object",
@@ -120,6 +121,7 @@ func (s *ThreadsSkill) registerTools() {
 	// Tool 4: List recent posts
 	s.AddTool(skills.Tool{
 		Name:        "threads_list_posts",
+		ReadOnly:    true,
 		Description: "List recent posts from the authenticated user",
 		Parameters: map[string]interface{}{
 			"type": "object",
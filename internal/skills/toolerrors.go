@@ -0,0 +1,44 @@
+package skills
+
+import (
+	"sync"
+	"time"
+)
+
+// maxToolErrors is how many recent tool errors a registry keeps
+const maxToolErrors = 50
+
+// ToolError is a tool call that failed
+type ToolError struct {
+	Skill string    `json:"skill"`
+	Tool  string    `json:"tool"`
+	Error string    `json:"error"`
+	At    time.Time `json:"at"`
+}
+
+// toolErrors keeps the most recent tool errors, oldest first
+type toolErrors struct {
+	mu     sync.Mutex
+	errors []ToolError
+}
+
+func (e *toolErrors) record(skill, tool string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errors = append(e.errors, ToolError{Skill: skill, Tool: tool, Error: err.Error(), At: time.Now()})
+	if len(e.errors) > maxToolErrors {
+		e.errors = append([]ToolError(nil), e.errors[len(e.errors)-maxToolErrors:]...)
+	}
+}
+
+// ToolErrors returns the most recent tool calls that failed, newest first.
+// Calls a tool filter refused aren't counted.
+func (r *Registry) ToolErrors() []ToolError {
+	r.toolErrors.mu.Lock()
+	defer r.toolErrors.mu.Unlock()
+	errs := make([]ToolError, len(r.toolErrors.errors))
+	for i, e := range r.toolErrors.errors {
+		errs[len(errs)-1-i] = e
+	}
+	return errs
+}
@@ -0,0 +1,55 @@
+package skills
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRegistry_ToolErrors(t *testing.T) {
+	r := NewRegistry(nil)
+	skill := NewBaseSkill("files", "Files", "1.0.0")
+	skill.AddTool(Tool{
+		Name: "read_file",
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			if path, _ := args["path"].(string); path != "" {
+				return nil, fmt.Errorf("%s: no such file", path)
+			}
+			return "ok", nil
+		},
+	})
+	if err := r.Register(skill); err != nil {
+		t.Fatalf("Failed to register skill: %v", err)
+	}
+
+	r.ExecuteTool(context.Background(), "read_file", []byte(`{}`))
+	r.ExecuteTool(context.Background(), "read_file", []byte(`{"path": "a.txt"}`))
+	r.ExecuteTool(context.Background(), "read_file", []byte(`not json`))
+	denied := WithToolFilter(context.Background(), func(skill, tool string) error {
+		return errors.New("not in this project")
+	})
+	r.ExecuteTool(denied, "read_file", []byte(`{"path": "b.txt"}`))
+
+	errs := r.ToolErrors()
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 tool errors, got %d: %+v", len(errs), errs)
+	}
+	if errs[0].Error == "" || errs[1].Error != "a.txt: no such file" {
+		t.Errorf("Expected the newest error first, got %+v", errs)
+	}
+	if errs[1].Skill != "files" || errs[1].Tool != "read_file" {
+		t.Errorf("Expected the error to name the skill and tool, got %+v", errs[1])
+	}
+
+	for i := 0; i < maxToolErrors+5; i++ {
+		r.ExecuteTool(context.Background(), "read_file", []byte(fmt.Sprintf(`{"path": "%d"}`, i)))
+	}
+	errs = r.ToolErrors()
+	if len(errs) != maxToolErrors {
+		t.Fatalf("Expected %d tool errors kept, got %d", maxToolErrors, len(errs))
+	}
+	if want := fmt.Sprintf("%d: no such file", maxToolErrors+4); errs[0].Error != want {
+		t.Errorf("Expected the newest error %q, got %q", want, errs[0].Error)
+	}
+}
@@ -0,0 +1,34 @@
+package skills
+
+import "context"
+
+type toolFilterKey struct{}
+
+// ToolFilter decides whether a tool, owned by skill, may run. It returns
+// why not, which goes back to the model.
+type ToolFilter func(skill, tool string) error
+
+// WithToolFilter returns a context in which only the tools filter allows
+// are run, e.g. those a project's tool profile offers. A filter already in
+// ctx still applies.
+func WithToolFilter(ctx context.Context, filter ToolFilter) context.Context {
+	if outer, ok := ctx.Value(toolFilterKey{}).(ToolFilter); ok {
+		inner := filter
+		filter = func(skill, tool string) error {
+			if err := outer(skill, tool); err != nil {
+				return err
+			}
+			return inner(skill, tool)
+		}
+	}
+	return context.WithValue(ctx, toolFilterKey{}, filter)
+}
+
+// CheckTool applies the filter in ctx, if any, to a tool of skill
+func CheckTool(ctx context.Context, skill, tool string) error {
+	filter, ok := ctx.Value(toolFilterKey{}).(ToolFilter)
+	if !ok {
+		return nil
+	}
+	return filter(skill, tool)
+}
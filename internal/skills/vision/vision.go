@@ -63,6 +63,7 @@ func (s *VisionSkill) registerTools() {
 	// Analyze existing image
 	s.AddTool(skills.Tool{
 		Name:        "analyze_image",
+		ReadOnly:    true,
 		Description: "Analyze an image file from the filesystem",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -124,6 +125,7 @@ func (s *VisionSkill) registerTools() {
 	// Describe visual content (for uploaded images)
 	s.AddTool(skills.Tool{
 		Name:        "describe_image",
+		ReadOnly:    true,
 		Description: "Describe the contents of an image file in detail",
 		Parameters: map[string]interface{}{
 			"type": "object",
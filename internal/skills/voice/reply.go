@@ -0,0 +1,160 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// DefaultMaxReplyChars is the longest reply spoken unless configured; longer
+// replies are only sent as text
+const DefaultMaxReplyChars = 1500
+
+// Speaker turns text into an audio file
+type Speaker interface {
+	Speak(ctx context.Context, text string) (string, error)
+}
+
+// SettingsStore keeps which chats have voice replies on. The store's KV
+// methods implement it.
+type SettingsStore interface {
+	SetKV(key string, value []byte) error
+	GetKV(key string) ([]byte, error)
+}
+
+// Replier speaks chat replies as voice notes in the chats that turned them
+// on with /voice on
+type Replier struct {
+	speaker  Speaker
+	settings SettingsStore
+	maxChars int
+}
+
+// NewReplier creates a Replier. maxChars of 0 uses DefaultMaxReplyChars.
+func NewReplier(speaker Speaker, settings SettingsStore, maxChars int) *Replier {
+	if maxChars <= 0 {
+		maxChars = DefaultMaxReplyChars
+	}
+	return &Replier{speaker: speaker, settings: settings, maxChars: maxChars}
+}
+
+func settingKey(channel, chatID string) string {
+	return "voice_replies:" + channel + ":" + chatID
+}
+
+// Enabled reports whether a chat has voice replies on
+func (r *Replier) Enabled(channel, chatID string) bool {
+	value, err := r.settings.GetKV(settingKey(channel, chatID))
+	return err == nil && string(value) == "on"
+}
+
+// Command handles /voice on|off for a chat and returns the reply
+func (r *Replier) Command(channel, chatID, args string) string {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on":
+		if err := r.settings.SetKV(settingKey(channel, chatID), []byte("on")); err != nil {
+			return "❌ Failed to turn voice replies on: " + err.Error()
+		}
+		if err := r.check(); err != nil {
+			return "🔊 Voice replies on, but they can't be spoken yet: " + err.Error()
+		}
+		return "🔊 Voice replies on. Replies come with a voice note; /voice off stops them."
+	case "off":
+		if err := r.settings.SetKV(settingKey(channel, chatID), []byte("off")); err != nil {
+			return "❌ Failed to turn voice replies off: " + err.Error()
+		}
+		return "🔇 Voice replies off."
+	case "":
+		if r.Enabled(channel, chatID) {
+			return "🔊 Voice replies are on. Use /voice off to stop them."
+		}
+		return "🔇 Voice replies are off. Use /voice on to hear replies."
+	}
+	return "Usage: /voice on|off"
+}
+
+// check reports what's missing to speak replies
+func (r *Replier) check() error {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("ffmpeg is not installed")
+	}
+	if vs, ok := r.speaker.(*VoiceSkill); ok && !vs.CanSpeak() {
+		return fmt.Errorf("the piper voice model is not installed")
+	}
+	return nil
+}
+
+// VoiceNote speaks a reply as an OGG/Opus file, the format Telegram voice
+// messages use. It returns "" without an error when the reply is too long
+// or has nothing to say. The caller removes the file.
+func (r *Replier) VoiceNote(ctx context.Context, reply string) (string, error) {
+	text := SpeechText(reply)
+	if text == "" || len([]rune(text)) > r.maxChars {
+		return "", nil
+	}
+
+	wav, err := r.speaker.Speak(ctx, text)
+	if err != nil {
+		return "", fmt.Errorf("speech synthesis failed: %w", err)
+	}
+	defer os.Remove(wav)
+	return TranscodeOpus(ctx, wav)
+}
+
+// TranscodeOpus converts audio to mono OGG/Opus with ffmpeg, returning the
+// new file's path
+func TranscodeOpus(ctx context.Context, inputPath string) (string, error) {
+	out, err := os.CreateTemp("", "myrai_voice_*.ogg")
+	if err != nil {
+		return "", err
+	}
+	out.Close()
+
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-i", inputPath,
+		"-ac", "1",
+		"-c:a", "libopus",
+		"-b:a", "32k",
+		"-application", "voip",
+		"-y",
+		out.Name(),
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(out.Name())
+		return "", fmt.Errorf("ffmpeg failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+	return out.Name(), nil
+}
+
+var (
+	codeBlock    = regexp.MustCompile("(?s)```.*?```")
+	inlineCode   = regexp.MustCompile("`([^`]*)`")
+	mdLink       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	bareURL      = regexp.MustCompile(`https?://\S+`)
+	lineMarkup   = regexp.MustCompile(`(?m)^[ \t]*(#{1,6}[ \t]+|>[ \t]?|[-*+][ \t]+|\|)`)
+	emphasis     = regexp.MustCompile(`(\*\*|__|\*|~~)`)
+	tableRule    = regexp.MustCompile(`(?m)^[ \t|:]*-[ \t|:-]*$`)
+	extraSpace   = regexp.MustCompile(`[ \t]+`)
+	lineEndSpace = regexp.MustCompile(`(?m)[ \t]+$`)
+	extraNewline = regexp.MustCompile(`\n{3,}`)
+)
+
+// SpeechText strips Markdown from a reply so it reads naturally aloud:
+// code blocks are left out, links are read by their text and URLs dropped
+func SpeechText(s string) string {
+	s = codeBlock.ReplaceAllString(s, "")
+	s = mdLink.ReplaceAllString(s, "$1")
+	s = bareURL.ReplaceAllString(s, "")
+	s = inlineCode.ReplaceAllString(s, "$1")
+	s = tableRule.ReplaceAllString(s, "")
+	s = lineMarkup.ReplaceAllString(s, "")
+	s = emphasis.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(s, "|", ",")
+	s = extraSpace.ReplaceAllString(s, " ")
+	s = lineEndSpace.ReplaceAllString(s, "")
+	s = extraNewline.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,119 @@
+package voice
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type memorySettings map[string][]byte
+
+func (m memorySettings) SetKV(key string, value []byte) error {
+	m[key] = value
+	return nil
+}
+
+func (m memorySettings) GetKV(key string) ([]byte, error) {
+	value, ok := m[key]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return value, nil
+}
+
+type fakeSpeaker struct {
+	spoken []string
+	path   string
+}
+
+func (f *fakeSpeaker) Speak(ctx context.Context, text string) (string, error) {
+	f.spoken = append(f.spoken, text)
+	return f.path, nil
+}
+
+func TestReplier_Command(t *testing.T) {
+	r := NewReplier(&fakeSpeaker{}, memorySettings{}, 0)
+
+	if r.Enabled("telegram", "42") {
+		t.Fatal("voice replies should start off")
+	}
+	if reply := r.Command("telegram", "42", "on"); !strings.Contains(reply, "on") {
+		t.Errorf("unexpected reply %q", reply)
+	}
+	if !r.Enabled("telegram", "42") {
+		t.Error("voice replies should be on")
+	}
+	if r.Enabled("discord", "42") || r.Enabled("telegram", "43") {
+		t.Error("the setting is per chat")
+	}
+	if reply := r.Command("telegram", "42", ""); !strings.Contains(reply, "are on") {
+		t.Errorf("unexpected status %q", reply)
+	}
+
+	r.Command("telegram", "42", "OFF")
+	if r.Enabled("telegram", "42") {
+		t.Error("voice replies should be off")
+	}
+	if reply := r.Command("telegram", "42", "loud"); !strings.HasPrefix(reply, "Usage") {
+		t.Errorf("expected usage, got %q", reply)
+	}
+}
+
+func TestSpeechText(t *testing.T) {
+	reply := "## Summary\n\n**Done!** See [the docs](https://example.com/docs) or https://example.com.\n\n" +
+		"```go\nfmt.Println(\"hi\")\n```\n\n- Run `make test`\n- Ship it"
+	want := "Summary\n\nDone! See the docs or\n\nRun make test\nShip it"
+	if got := SpeechText(reply); got != want {
+		t.Errorf("SpeechText() = %q, want %q", got, want)
+	}
+}
+
+func TestReplier_VoiceNote_TooLong(t *testing.T) {
+	speaker := &fakeSpeaker{}
+	r := NewReplier(speaker, memorySettings{}, 10)
+
+	path, err := r.VoiceNote(context.Background(), "This reply is far too long to speak")
+	if err != nil || path != "" {
+		t.Errorf("VoiceNote() = %q, %v; want nothing", path, err)
+	}
+	path, err = r.VoiceNote(context.Background(), "```\ncode only\n```")
+	if err != nil || path != "" {
+		t.Errorf("VoiceNote() = %q, %v; want nothing", path, err)
+	}
+	if len(speaker.spoken) != 0 {
+		t.Errorf("nothing should be spoken, got %v", speaker.spoken)
+	}
+}
+
+func TestReplier_VoiceNote(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg not installed")
+	}
+	wav := filepath.Join(t.TempDir(), "speech.wav")
+	if out, err := exec.Command("ffmpeg", "-f", "lavfi", "-i", "sine=duration=1", "-y", wav).CombinedOutput(); err != nil {
+		t.Fatalf("failed to make test audio: %v: %s", err, out)
+	}
+
+	speaker := &fakeSpeaker{path: wav}
+	r := NewReplier(speaker, memorySettings{}, 0)
+	path, err := r.VoiceNote(context.Background(), "**Hello** there")
+	if err != nil {
+		t.Fatalf("VoiceNote() failed: %v", err)
+	}
+	defer os.Remove(path)
+
+	if speaker.spoken[0] != "Hello there" {
+		t.Errorf("spoke %q", speaker.spoken[0])
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "OggS") {
+		t.Error("voice note should be an OGG file")
+	}
+	if _, err := os.Stat(wav); !os.IsNotExist(err) {
+		t.Error("the synthesized WAV should be removed")
+	}
+}
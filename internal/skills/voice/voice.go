@@ -148,6 +148,16 @@ func (vs *VoiceSkill) IsReady() bool {
 	return vs.isReady
 }
 
+// CanSpeak reports whether a voice model is installed for speech
+func (vs *VoiceSkill) CanSpeak() bool {
+	if !vs.IsReady() {
+		if err := vs.Initialize(); err != nil {
+			return false
+		}
+	}
+	return vs.tts.IsReady()
+}
+
 // Transcribe transcribes audio file to text
 func (vs *VoiceSkill) Transcribe(ctx context.Context, audioPath string) (string, error) {
 	if !vs.IsReady() {
@@ -262,6 +272,7 @@ func (vs *VoiceSkill) registerTools() {
 	// Transcribe audio
 	vs.AddTool(skills.Tool{
 		Name:        "transcribe_audio",
+		ReadOnly:    true,
 		Description: "Transcribe audio file to text",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -304,6 +315,7 @@ func (vs *VoiceSkill) registerTools() {
 	// Get voice info
 	vs.AddTool(skills.Tool{
 		Name:        "voice_info",
+		ReadOnly:    true,
 		Description: "Get voice processing status and info",
 		Parameters: map[string]interface{}{
 			"type":       "object",
@@ -75,7 +75,7 @@ func (sl *SkillLoader) LoadSkill(skillPath string) (*RuntimeSkill, error) {
 			Name:        manifestTool.Name,
 			Description: manifestTool.Description,
 			Parameters:  manifestTool.ToJSONSchema(),
-			Handler:     sl.createToolHandler(manifest.Name, manifestTool),
+			Handler:     sl.createToolHandler(manifest.Name, filepath.Dir(skillPath), manifestTool),
 		}
 		skill.Tools = append(skill.Tools, tool)
 	}
@@ -162,8 +162,12 @@ func (sl *SkillLoader) LoadAllSkills() error {
 	return nil
 }
 
-// createToolHandler creates a handler for a manifest tool
-func (sl *SkillLoader) createToolHandler(skillName string, tool ManifestTool) ToolHandler {
+// createToolHandler creates a handler for a manifest tool. Tools with a
+// command run it from the skill's directory.
+func (sl *SkillLoader) createToolHandler(skillName, dir string, tool ManifestTool) ToolHandler {
+	if tool.Command != "" {
+		return commandToolHandler(dir, tool)
+	}
 	return func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		// This is a placeholder - actual implementation would:
 		// 1. Validate arguments against schema
@@ -6,14 +6,22 @@ import (
 	"fmt"
 	"net/url"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/gmsas95/myrai-cli/internal/cache"
+	"github.com/gmsas95/myrai-cli/internal/location"
 	"github.com/gmsas95/myrai-cli/internal/skills"
 )
 
+// CacheTTL is how long a location's weather is reused
+const CacheTTL = 10 * time.Minute
+
 // WeatherSkill provides weather information
 type WeatherSkill struct {
 	*skills.BaseSkill
+	cache *cache.Cache
 }
 
 // NewWeatherSkill creates a new weather skill
@@ -25,50 +33,60 @@ func NewWeatherSkill() *WeatherSkill {
 	return s
 }
 
+// SetCache sets the cache that repeated lookups are answered from
+func (s *WeatherSkill) SetCache(c *cache.Cache) {
+	s.cache = c
+}
+
 func (s *WeatherSkill) registerTools() {
 	s.AddTool(skills.Tool{
 		Name:        "get_weather",
+		ReadOnly:    true,
 		Description: "Get current weather for a location",
 		Parameters: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
 				"location": map[string]interface{}{
 					"type":        "string",
-					"description": "City name or location",
+					"description": "City name or location (default: where the user is, if they share their location)",
 				},
 			},
-			"required": []string{"location"},
 		},
 		Handler: s.handleGetWeather,
 	})
 
 	s.AddTool(skills.Tool{
 		Name:        "get_forecast",
+		ReadOnly:    true,
 		Description: "Get weather forecast for a location",
 		Parameters: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
 				"location": map[string]interface{}{
 					"type":        "string",
-					"description": "City name or location",
+					"description": "City name or location (default: where the user is, if they share their location)",
 				},
 				"days": map[string]interface{}{
 					"type":        "integer",
 					"description": "Number of days (default: 3)",
 				},
 			},
-			"required": []string{"location"},
 		},
 		Handler: s.handleGetForecast,
 	})
 }
 
 func (s *WeatherSkill) handleGetWeather(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-	location, _ := args["location"].(string)
-	if location == "" {
-		return nil, fmt.Errorf("location is required")
+	location, err := resolveLocation(ctx, args)
+	if err != nil {
+		return nil, err
 	}
+	return cache.Fetch(ctx, s.cache, "weather", cache.Key("current", location), CacheTTL, func() (interface{}, error) {
+		return s.getCurrent(ctx, location)
+	})
+}
 
+func (s *WeatherSkill) getCurrent(ctx context.Context, location string) (interface{}, error) {
 	// Try wttr.in first (simple format)
 	wttrURL := fmt.Sprintf("wttr.in/%s?format=3", sanitizeLocation(location))
 	output, err := exec.CommandContext(ctx, "curl", "-s", "--max-time", "10", wttrURL).Output()
@@ -86,31 +104,11 @@ func (s *WeatherSkill) handleGetWeather(ctx context.Context, args map[string]int
 
 func (s *WeatherSkill) getOpenMeteoCurrent(ctx context.Context, location string) (interface{}, error) {
 	// First, geocode the location using Open-Meteo geocoding API
-	geoURL := fmt.Sprintf("https://geocoding-api.open-meteo.com/v1/search?name=%s&count=1", url.QueryEscape(location))
-	geoOutput, err := exec.CommandContext(ctx, "curl", "-s", "--max-time", "10", geoURL).Output()
+	loc, err := geocode(ctx, location)
 	if err != nil {
-		return nil, fmt.Errorf("failed to geocode location: %w", err)
-	}
-
-	var geoResult struct {
-		Results []struct {
-			Name      string  `json:"name"`
-			Latitude  float64 `json:"latitude"`
-			Longitude float64 `json:"longitude"`
-			Country   string  `json:"country"`
-		} `json:"results"`
-	}
-	
-	if err := json.Unmarshal(geoOutput, &geoResult); err != nil {
-		return nil, fmt.Errorf("failed to parse geocoding response")
-	}
-	
-	if len(geoResult.Results) == 0 {
-		return nil, fmt.Errorf("location not found: %s", location)
+		return nil, err
 	}
 	
-	loc := geoResult.Results[0]
-	
 	// Now get weather data
 	weatherURL := fmt.Sprintf("https://api.open-meteo.com/v1/forecast?latitude=%.4f&longitude=%.4f&current_weather=true",
 		loc.Latitude, loc.Longitude)
@@ -135,7 +133,7 @@ func (s *WeatherSkill) getOpenMeteoCurrent(ctx context.Context, location string)
 
 	cw := weatherResult.CurrentWeather
 	return map[string]interface{}{
-		"location":    fmt.Sprintf("%s, %s", loc.Name, loc.Country),
+		"location":    loc.label(),
 		"temperature": fmt.Sprintf("%.1f°C", cw.Temperature),
 		"windspeed":   fmt.Sprintf("%.1f km/h", cw.Windspeed),
 		"condition":   weatherCodeToString(cw.WeatherCode),
@@ -144,16 +142,21 @@ func (s *WeatherSkill) getOpenMeteoCurrent(ctx context.Context, location string)
 }
 
 func (s *WeatherSkill) handleGetForecast(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-	location, _ := args["location"].(string)
-	if location == "" {
-		return nil, fmt.Errorf("location is required")
+	location, err := resolveLocation(ctx, args)
+	if err != nil {
+		return nil, err
 	}
 
 	days := 3
 	if d, ok := args["days"].(float64); ok && d > 0 && d <= 7 {
 		days = int(d)
 	}
+	return cache.Fetch(ctx, s.cache, "weather", cache.Key("forecast", location, days), CacheTTL, func() (interface{}, error) {
+		return s.getForecast(ctx, location, days)
+	})
+}
 
+func (s *WeatherSkill) getForecast(ctx context.Context, location string, days int) (interface{}, error) {
 	// Try wttr.in first
 	wttrURL := fmt.Sprintf("wttr.in/%s?%d", sanitizeLocation(location), days)
 	output, err := exec.CommandContext(ctx, "curl", "-s", "--max-time", "10", wttrURL).Output()
@@ -172,27 +175,11 @@ func (s *WeatherSkill) handleGetForecast(ctx context.Context, args map[string]in
 
 func (s *WeatherSkill) getOpenMeteoForecast(ctx context.Context, location string, days int) (interface{}, error) {
 	// Geocode first
-	geoURL := fmt.Sprintf("https://geocoding-api.open-meteo.com/v1/search?name=%s&count=1", url.QueryEscape(location))
-	geoOutput, err := exec.CommandContext(ctx, "curl", "-s", "--max-time", "10", geoURL).Output()
+	loc, err := geocode(ctx, location)
 	if err != nil {
-		return nil, fmt.Errorf("failed to geocode location: %w", err)
-	}
-
-	var geoResult struct {
-		Results []struct {
-			Name      string  `json:"name"`
-			Latitude  float64 `json:"latitude"`
-			Longitude float64 `json:"longitude"`
-			Country   string  `json:"country"`
-		} `json:"results"`
+		return nil, err
 	}
 	
-	if err := json.Unmarshal(geoOutput, &geoResult); err != nil || len(geoResult.Results) == 0 {
-		return nil, fmt.Errorf("location not found: %s", location)
-	}
-	
-	loc := geoResult.Results[0]
-	
 	// Get forecast
 	weatherURL := fmt.Sprintf("https://api.open-meteo.com/v1/forecast?latitude=%.4f&longitude=%.4f&daily=temperature_2m_max,temperature_2m_min&timezone=auto&forecast_days=%d",
 		loc.Latitude, loc.Longitude, days)
@@ -224,13 +211,82 @@ func (s *WeatherSkill) getOpenMeteoForecast(ctx context.Context, location string
 	}
 
 	return map[string]interface{}{
-		"location": fmt.Sprintf("%s, %s", loc.Name, loc.Country),
+		"location": loc.label(),
 		"forecast": forecast,
 		"days":     days,
 		"source":   "Open-Meteo",
 	}, nil
 }
 
+// resolveLocation returns the location asked for, or where the user last
+// checked in when none was given
+func resolveLocation(ctx context.Context, args map[string]interface{}) (string, error) {
+	if place, _ := args["location"].(string); strings.TrimSpace(place) != "" {
+		return place, nil
+	}
+	if fix := location.FromContext(ctx); fix != nil {
+		return fix.Coordinates(), nil
+	}
+	return "", fmt.Errorf("location is required (or turn on location sharing to use where you are)")
+}
+
+// geoPlace is a geocoded location
+type geoPlace struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Country   string  `json:"country"`
+}
+
+func (p geoPlace) label() string {
+	if p.Country == "" {
+		return p.Name
+	}
+	return fmt.Sprintf("%s, %s", p.Name, p.Country)
+}
+
+// geocode looks up a location with the Open-Meteo geocoding API. Coordinates
+// given as "lat,lon" are used as they are.
+func geocode(ctx context.Context, place string) (geoPlace, error) {
+	if lat, lon, ok := parseCoordinates(place); ok {
+		return geoPlace{Name: place, Latitude: lat, Longitude: lon}, nil
+	}
+
+	geoURL := fmt.Sprintf("https://geocoding-api.open-meteo.com/v1/search?name=%s&count=1", url.QueryEscape(place))
+	geoOutput, err := exec.CommandContext(ctx, "curl", "-s", "--max-time", "10", geoURL).Output()
+	if err != nil {
+		return geoPlace{}, fmt.Errorf("failed to geocode location: %w", err)
+	}
+
+	var geoResult struct {
+		Results []geoPlace `json:"results"`
+	}
+	if err := json.Unmarshal(geoOutput, &geoResult); err != nil {
+		return geoPlace{}, fmt.Errorf("failed to parse geocoding response")
+	}
+	if len(geoResult.Results) == 0 {
+		return geoPlace{}, fmt.Errorf("location not found: %s", place)
+	}
+	return geoResult.Results[0], nil
+}
+
+// parseCoordinates reads "lat,lon"
+func parseCoordinates(s string) (lat, lon float64, ok bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, false
+	}
+	lon, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
 func sanitizeLocation(loc string) string {
 	return strings.ReplaceAll(loc, " ", "+")
 }
@@ -0,0 +1,293 @@
+package store
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/klauspost/compress/zstd"
+)
+
+// An archive is a backup directory (manifest, database and workspace) plus
+// the notes vault, packed into a single .tar.zst file that can be copied
+// to another machine
+const (
+	archivePrefix = "myrai-"
+	archiveExt    = ".tar.zst"
+	archiveNotes  = "notes"
+)
+
+// ArchiveDir returns where scheduled archives, and archives created without
+// a path, are written
+func ArchiveDir(cfg *config.StorageConfig) string {
+	if cfg.Backup.Dir != "" {
+		return cfg.Backup.Dir
+	}
+	return backupsDir(cfg)
+}
+
+// ArchivePath returns the path of a new archive in ArchiveDir
+func ArchivePath(cfg *config.StorageConfig, now time.Time) string {
+	return filepath.Join(ArchiveDir(cfg), archivePrefix+now.Format(backupTimeFormat)+archiveExt)
+}
+
+// IsArchive reports whether path names an archive file
+func IsArchive(path string) bool {
+	return strings.HasSuffix(path, archiveExt)
+}
+
+// CreateArchive writes the database, workspace, uploaded documents and the
+// notes in notesDir to a .tar.zst archive at path. It is safe to call while
+// the store is open. The returned backup's Path is the archive.
+func CreateArchive(cfg *config.StorageConfig, notesDir, path string) (*Backup, error) {
+	if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
+		return nil, err
+	}
+	// Staged in the data directory, which has room for the database
+	staging, err := os.MkdirTemp(cfg.DataDir, ".archive-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	backup := &Backup{
+		ID:        strings.TrimSuffix(filepath.Base(path), archiveExt),
+		CreatedAt: time.Now(),
+		Path:      staging,
+	}
+	if err := writeBackup(cfg, backup); err != nil {
+		return nil, err
+	}
+	if notesDir != "" {
+		if _, err := os.Stat(notesDir); err == nil {
+			if err := copyPath(notesDir, filepath.Join(staging, archiveNotes)); err != nil {
+				return nil, fmt.Errorf("failed to back up notes: %w", err)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	tmp := path + ".tmp"
+	if err := writeTarZst(staging, tmp); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	backup.Path = path
+	return backup, nil
+}
+
+// RestoreArchive replaces the database and workspace with the contents of an
+// archive, and copies its notes into notesDir over the existing ones. The
+// store must not be open. The archive is unpacked into the backups
+// directory, and the current state is backed up first, as in RestoreBackup.
+func RestoreArchive(cfg *config.StorageConfig, notesDir, path string) (*Backup, error) {
+	dir := newBackupDir(cfg, time.Now(), BackupLabelImported)
+	if err := extractTarZst(path, dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, backupManifest))
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("%s is not a Myrai backup", path)
+	}
+	var backup Backup
+	if err := json.Unmarshal(data, &backup); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("corrupt backup manifest: %w", err)
+	}
+	backup.ID, backup.Label, backup.Path = filepath.Base(dir), BackupLabelImported, dir
+	if err := writeManifest(&backup); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	restored, err := RestoreBackup(cfg, backup.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := filepath.Join(dir, archiveNotes)
+	if _, err := os.Stat(notes); err == nil && notesDir != "" {
+		if err := copyPath(notes, notesDir); err != nil {
+			return nil, fmt.Errorf("failed to restore notes: %w", err)
+		}
+	}
+	return restored, nil
+}
+
+// ListArchives returns the archives in dir, newest first
+func ListArchives(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var archives []string
+	for _, e := range entries {
+		if !e.IsDir() && IsArchive(e.Name()) {
+			archives = append(archives, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Slice(archives, func(i, j int) bool { return archives[i] > archives[j] })
+	return archives, nil
+}
+
+// PruneArchives deletes all but the newest keep archives named by
+// ArchivePath in dir, and returns the deleted paths. Archives with other
+// names are left alone.
+func PruneArchives(dir string, keep int) ([]string, error) {
+	archives, err := ListArchives(dir)
+	if err != nil {
+		return nil, err
+	}
+	var scheduled []string
+	for _, a := range archives {
+		if strings.HasPrefix(filepath.Base(a), archivePrefix) {
+			scheduled = append(scheduled, a)
+		}
+	}
+	if keep < 1 || len(scheduled) <= keep {
+		return nil, nil
+	}
+
+	var removed []string
+	for _, a := range scheduled[keep:] {
+		if err := os.Remove(a); err != nil {
+			return removed, err
+		}
+		removed = append(removed, a)
+	}
+	return removed, nil
+}
+
+// writeTarZst packs the files under dir into a zstd-compressed tarball
+func writeTarZst(dir, dst string) error {
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	zw, err := zstd.NewWriter(out)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(zw)
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		zw.Close()
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		zw.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// extractTarZst unpacks an archive written by writeTarZst into dir
+func extractTarZst(src, dir string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	zr, err := zstd.NewReader(in)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.FromSlash(header.Name)
+		if filepath.IsAbs(name) || !filepath.IsLocal(name) {
+			return fmt.Errorf("unsafe path in archive: %s", header.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package store_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+func TestArchive_CreateAndRestore(t *testing.T) {
+	cfg := newTestConfig(t)
+	notesDir := filepath.Join(t.TempDir(), "vault")
+	if err := os.MkdirAll(notesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	note := filepath.Join(notesDir, "groceries.md")
+	doc := filepath.Join(cfg.Storage.DataDir, "documents", "lease.pdf")
+	if err := os.WriteFile(note, []byte("- milk"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(doc), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(doc, []byte("lease v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := store.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	if err := st.CreateConversation(&store.Conversation{Title: "Archived"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Taken with the store open, as the scheduled backup is
+	path := filepath.Join(t.TempDir(), "myrai.tar.zst")
+	backup, err := store.CreateArchive(&cfg.Storage, notesDir, path)
+	if err != nil {
+		t.Fatalf("CreateArchive failed: %v", err)
+	}
+	if backup.Path != path || backup.SchemaVersion != store.LatestSchemaVersion() {
+		t.Errorf("Unexpected backup %+v", backup)
+	}
+
+	if err := st.CreateConversation(&store.Conversation{Title: "After archive"}); err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+	os.WriteFile(doc, []byte("lease v2"), 0644)
+	os.WriteFile(note, []byte("- eggs"), 0644)
+
+	if _, err := store.RestoreArchive(&cfg.Storage, notesDir, path); err != nil {
+		t.Fatalf("RestoreArchive failed: %v", err)
+	}
+	if data, _ := os.ReadFile(doc); string(data) != "lease v1" {
+		t.Errorf("Expected the document restored, got %q", data)
+	}
+	if data, _ := os.ReadFile(note); string(data) != "- milk" {
+		t.Errorf("Expected the note restored, got %q", data)
+	}
+
+	st, err = store.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to open restored store: %v", err)
+	}
+	defer st.Close()
+	convs, err := st.ListConversations(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(convs) != 1 || convs[0].Title != "Archived" {
+		t.Errorf("Expected only the archived conversation, got %+v", convs)
+	}
+
+	bad := filepath.Join(t.TempDir(), "not-a-backup.tar.zst")
+	os.WriteFile(bad, []byte("garbage"), 0644)
+	if _, err := store.RestoreArchive(&cfg.Storage, notesDir, bad); err == nil {
+		t.Error("Expected a file that isn't an archive to be rejected")
+	}
+}
+
+func TestPruneArchives(t *testing.T) {
+	cfg := newTestConfig(t)
+	dir := store.ArchiveDir(&cfg.Storage)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		os.WriteFile(store.ArchivePath(&cfg.Storage, start.AddDate(0, 0, i)), nil, 0600)
+	}
+	// Named by hand, so not part of the schedule's rotation
+	os.WriteFile(filepath.Join(dir, "before-move.tar.zst"), nil, 0600)
+
+	removed, err := store.PruneArchives(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 2 || removed[0] != store.ArchivePath(&cfg.Storage, start.AddDate(0, 0, 1)) {
+		t.Errorf("Expected the two oldest scheduled archives removed, got %v", removed)
+	}
+	archives, _ := store.ListArchives(dir)
+	if len(archives) != 3 {
+		t.Errorf("Expected 3 archives left, got %v", archives)
+	}
+}
@@ -0,0 +1,313 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+)
+
+const (
+	backupsDirName   = "backups"
+	backupManifest   = "manifest.json"
+	backupDBFile     = "myrai.db"
+	backupWorkspace  = "workspace"
+	maxKeptBackups   = 5
+	backupTimeFormat = "20060102-150405"
+)
+
+// Backup labels
+const (
+	BackupLabelManual      = "manual"
+	BackupLabelPreMigrate  = "pre-migrate"
+	BackupLabelPreRollback = "pre-rollback"
+	BackupLabelImported    = "imported"
+)
+
+// workspaceEntries are the persona workspace files and directories, and the
+// uploaded documents, included in backups, relative to the data directory
+var workspaceEntries = []string{
+	"IDENTITY.md",
+	"USER.md",
+	"TOOLS.md",
+	"AGENTS.md",
+	"projects",
+	"diary",
+	"memory",
+	"documents",
+}
+
+// Backup is a snapshot of the SQLite database and persona workspace
+type Backup struct {
+	ID            string    `json:"id"`
+	Label         string    `json:"label"`
+	SchemaVersion int       `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Path          string    `json:"-"`
+}
+
+// SQLitePath returns the SQLite database path for a storage configuration
+func SQLitePath(cfg *config.StorageConfig) string {
+	if cfg.SQLitePath != "" {
+		return cfg.SQLitePath
+	}
+	return filepath.Join(cfg.DataDir, "myrai.db")
+}
+
+func backupsDir(cfg *config.StorageConfig) string {
+	return filepath.Join(cfg.DataDir, backupsDirName)
+}
+
+// CreateBackup snapshots the database and workspace into a new directory
+// under <data_dir>/backups. It is safe to call while the store is open.
+// Only the most recent backups are kept.
+func CreateBackup(cfg *config.StorageConfig, label string) (*Backup, error) {
+	now := time.Now()
+	dir := newBackupDir(cfg, now, label)
+	backup := &Backup{ID: filepath.Base(dir), Label: label, CreatedAt: now, Path: dir}
+	if err := writeBackup(cfg, backup); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	// The backup being rolled back to may be the oldest one kept
+	if label != BackupLabelPreRollback {
+		pruneBackups(cfg, maxKeptBackups)
+	}
+	return backup, nil
+}
+
+// newBackupDir returns an unused backup directory named after the time and
+// label
+func newBackupDir(cfg *config.StorageConfig, now time.Time, label string) string {
+	id := now.Format(backupTimeFormat)
+	if label != "" {
+		id += "-" + label
+	}
+	dir := filepath.Join(backupsDir(cfg), id)
+	for n := 2; ; n++ {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return dir
+		}
+		dir = filepath.Join(backupsDir(cfg), fmt.Sprintf("%s-%d", id, n))
+	}
+}
+
+// writeBackup snapshots the database and workspace into backup.Path and
+// writes its manifest
+func writeBackup(cfg *config.StorageConfig, backup *Backup) error {
+	if err := os.MkdirAll(backup.Path, 0700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	dbPath := SQLitePath(cfg)
+	if _, err := os.Stat(dbPath); err == nil {
+		version, err := snapshotSQLite(dbPath, filepath.Join(backup.Path, backupDBFile))
+		if err != nil {
+			return fmt.Errorf("failed to back up database: %w", err)
+		}
+		backup.SchemaVersion = version
+	}
+
+	for _, entry := range workspaceEntries {
+		src := filepath.Join(cfg.DataDir, entry)
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := copyPath(src, filepath.Join(backup.Path, backupWorkspace, entry)); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", entry, err)
+		}
+	}
+
+	return writeManifest(backup)
+}
+
+func writeManifest(backup *Backup) error {
+	data, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(backup.Path, backupManifest), data, 0600); err != nil {
+		return fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	return nil
+}
+
+// ListBackups returns the available backups, newest first
+func ListBackups(cfg *config.StorageConfig) ([]Backup, error) {
+	entries, err := os.ReadDir(backupsDir(cfg))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backups: %w", err)
+	}
+
+	var backups []Backup
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(backupsDir(cfg), e.Name())
+		data, err := os.ReadFile(filepath.Join(dir, backupManifest))
+		if err != nil {
+			continue
+		}
+		var b Backup
+		if err := json.Unmarshal(data, &b); err != nil {
+			continue
+		}
+		b.Path = dir
+		backups = append(backups, b)
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups, nil
+}
+
+// RestoreBackup replaces the database and workspace with a backup. An empty
+// id restores the most recent backup. The store must not be open. The
+// current state is backed up first so a rollback can itself be undone.
+func RestoreBackup(cfg *config.StorageConfig, id string) (*Backup, error) {
+	backups, err := ListBackups(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *Backup
+	for i := range backups {
+		if backups[i].Label == BackupLabelPreRollback && id == "" {
+			continue
+		}
+		if id == "" || backups[i].ID == id {
+			target = &backups[i]
+			break
+		}
+	}
+	if target == nil {
+		if id == "" {
+			return nil, fmt.Errorf("no backups found")
+		}
+		return nil, fmt.Errorf("backup not found: %s", id)
+	}
+
+	if _, err := CreateBackup(cfg, BackupLabelPreRollback); err != nil {
+		return nil, fmt.Errorf("failed to back up current state: %w", err)
+	}
+
+	dbBackup := filepath.Join(target.Path, backupDBFile)
+	if _, err := os.Stat(dbBackup); err == nil {
+		dbPath := SQLitePath(cfg)
+		tmp := dbPath + ".restore"
+		if err := copyFile(dbBackup, tmp); err != nil {
+			return nil, fmt.Errorf("failed to restore database: %w", err)
+		}
+		// Stale WAL files would be replayed on top of the restored database
+		os.Remove(dbPath + "-wal")
+		os.Remove(dbPath + "-shm")
+		if err := os.Rename(tmp, dbPath); err != nil {
+			os.Remove(tmp)
+			return nil, fmt.Errorf("failed to restore database: %w", err)
+		}
+	}
+
+	for _, entry := range workspaceEntries {
+		dst := filepath.Join(cfg.DataDir, entry)
+		src := filepath.Join(target.Path, backupWorkspace, entry)
+		if err := os.RemoveAll(dst); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", entry, err)
+		}
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := copyPath(src, dst); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", entry, err)
+		}
+	}
+
+	return target, nil
+}
+
+// snapshotSQLite writes a consistent copy of the database to dst using
+// VACUUM INTO and returns its schema version
+func snapshotSQLite(src, dst string) (int, error) {
+	conn, err := sql.Open("sqlite", src+"?_busy_timeout=5000")
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Exec("VACUUM INTO ?", dst); err != nil {
+		return 0, err
+	}
+
+	var version int
+	err = conn.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations WHERE component = ?", CoreComponent).Scan(&version)
+	if err != nil {
+		// A database from before migrations had components
+		conn.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	}
+	return version, nil
+}
+
+// pruneBackups removes all but the newest keep backups
+func pruneBackups(cfg *config.StorageConfig, keep int) {
+	backups, err := ListBackups(cfg)
+	if err != nil || len(backups) <= keep {
+		return
+	}
+	for _, b := range backups[keep:] {
+		if strings.HasPrefix(b.Path, backupsDir(cfg)) {
+			os.RemoveAll(b.Path)
+		}
+	}
+}
+
+// copyPath copies a file or directory tree
+func copyPath(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
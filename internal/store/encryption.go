@@ -0,0 +1,165 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/fieldcrypt"
+	"github.com/gmsas95/myrai-cli/internal/secrets"
+	"gorm.io/gorm"
+)
+
+// Settings kept in the config table for encryption. The salt and a sealed
+// check value travel with the database, so backups open with the same key.
+const (
+	encryptionSaltKey  = "storage.encryption.salt"
+	encryptionCheckKey = "storage.encryption.check"
+	encryptionCheck    = "myrai"
+)
+
+// Where the encryption key comes from
+const (
+	KeySourcePassphrase = "passphrase"
+	KeySourceKeychain   = "keychain"
+)
+
+// PassphraseEnv holds the passphrase the encryption key is derived from
+const PassphraseEnv = "MYRAI_STORAGE_PASSPHRASE"
+
+// keychainKeyName is the secret holding the key for the keychain source
+const keychainKeyName = "MYRAI_STORAGE_KEY"
+
+func init() {
+	fieldcrypt.Register(&Message{}, &File{}, &Pin{})
+}
+
+// setupEncryption sets the key for encrypted fields from cfg, or clears it
+// when encryption is off. A key that doesn't open data sealed before is
+// rejected.
+func setupEncryption(db *gorm.DB, cfg *config.EncryptionConfig) error {
+	if !cfg.Enabled {
+		return fieldcrypt.SetKey(nil)
+	}
+
+	var key []byte
+	var err error
+	switch cfg.KeySource {
+	case "", KeySourcePassphrase:
+		key, err = passphraseKey(db)
+	case KeySourceKeychain:
+		key, err = keychainKey()
+	default:
+		err = fmt.Errorf("unknown key source %q", cfg.KeySource)
+	}
+	if err != nil {
+		return fmt.Errorf("storage encryption: %w", err)
+	}
+	if err := fieldcrypt.SetKey(key); err != nil {
+		return fmt.Errorf("storage encryption: %w", err)
+	}
+
+	// Check the key against the one the database was encrypted with
+	check, ok, err := getSetting(db, encryptionCheckKey)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if plain, err := fieldcrypt.Decrypt(check); err != nil || string(plain) != encryptionCheck {
+			fieldcrypt.SetKey(nil)
+			return fmt.Errorf("storage encryption: %w", fieldcrypt.ErrWrongKey)
+		}
+		return nil
+	}
+	sealed, err := fieldcrypt.Encrypt([]byte(encryptionCheck))
+	if err != nil {
+		return err
+	}
+	return setSetting(db, encryptionCheckKey, sealed)
+}
+
+// passphraseKey derives the key from MYRAI_STORAGE_PASSPHRASE and the
+// database's salt, creating the salt the first time
+func passphraseKey(db *gorm.DB) ([]byte, error) {
+	passphrase := os.Getenv(PassphraseEnv)
+	if passphrase == "" {
+		return nil, fmt.Errorf("set %s, in the environment or with 'myrai secret set'", PassphraseEnv)
+	}
+
+	encoded, ok, err := getSetting(db, encryptionSaltKey)
+	if err != nil {
+		return nil, err
+	}
+	var salt []byte
+	if ok {
+		salt, err = base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("corrupt salt: %w", err)
+		}
+	} else {
+		salt, err = fieldcrypt.NewKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := setSetting(db, encryptionSaltKey, base64.StdEncoding.EncodeToString(salt)); err != nil {
+			return nil, err
+		}
+	}
+	return fieldcrypt.DeriveKey(passphrase, salt)
+}
+
+// keychainKey returns the key kept in the OS keychain, generating it the
+// first time
+func keychainKey() ([]byte, error) {
+	kc, err := secrets.Open(secrets.Options{Backend: secrets.BackendKeychain})
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := kc.Get(keychainKeyName)
+	if errors.Is(err, secrets.ErrNotFound) {
+		key, err := fieldcrypt.NewKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := kc.Set(keychainKeyName, hex.EncodeToString(key)); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(encoded)
+}
+
+// EncryptExisting encrypts the values written before encryption was turned
+// on, or decrypts everything when encrypt is false so encryption can be
+// turned off. It needs the key, so encryption must be enabled while it
+// runs. It returns the number of values changed.
+func (s *Store) EncryptExisting(encrypt bool) (int, error) {
+	n, err := fieldcrypt.Rewrite(s.db, encrypt)
+	if err != nil || encrypt {
+		return n, err
+	}
+	// Nothing is sealed any more, so a new passphrase may be used next time
+	return n, s.db.Where("key IN ?", []string{encryptionSaltKey, encryptionCheckKey}).Delete(&Config{}).Error
+}
+
+func getSetting(db *gorm.DB, key string) (string, bool, error) {
+	var rows []Config
+	if err := db.Where("key = ?", key).Limit(1).Find(&rows).Error; err != nil {
+		return "", false, err
+	}
+	if len(rows) == 0 {
+		return "", false, nil
+	}
+	return rows[0].Value, true, nil
+}
+
+func setSetting(db *gorm.DB, key, value string) error {
+	return db.Save(&Config{Key: key, Value: value, UpdatedAt: time.Now()}).Error
+}
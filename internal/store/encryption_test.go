@@ -0,0 +1,98 @@
+package store_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/fieldcrypt"
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+func TestEncryption_MigrateExisting(t *testing.T) {
+	t.Cleanup(func() { fieldcrypt.SetKey(nil) })
+	cfg := newTestConfig(t)
+
+	st, err := store.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	conv := &store.Conversation{Title: "Checkup"}
+	if err := st.CreateConversation(conv); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.CreateMessage(&store.Message{ConversationID: conv.ID, Role: "user", Content: "my blood pressure was 150/95"}); err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+
+	rawContent := func(st *store.Store) string {
+		var content string
+		st.DB().Raw("SELECT content FROM messages WHERE conversation_id = ?", conv.ID).Scan(&content)
+		return content
+	}
+
+	// Turning encryption on keeps old rows readable until they're migrated
+	cfg.Storage.Encryption = config.EncryptionConfig{Enabled: true, KeySource: store.KeySourcePassphrase}
+	t.Setenv(store.PassphraseEnv, "correct horse")
+	st, err = store.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to open encrypted store: %v", err)
+	}
+	if raw := rawContent(st); raw != "my blood pressure was 150/95" {
+		t.Fatalf("Expected the old row untouched, got %q", raw)
+	}
+	n, err := st.EncryptExisting(true)
+	if err != nil || n != 1 {
+		t.Fatalf("Expected 1 value encrypted, got %d (%v)", n, err)
+	}
+	if raw := rawContent(st); !fieldcrypt.IsEncrypted(raw) || strings.Contains(raw, "150/95") {
+		t.Errorf("Expected the content encrypted at rest, got %q", raw)
+	}
+	msgs, err := st.GetMessages(conv.ID, 10, 0)
+	if err != nil || len(msgs) != 1 || msgs[0].Content != "my blood pressure was 150/95" {
+		t.Fatalf("Expected the message decrypted, got %+v (%v)", msgs, err)
+	}
+
+	file := &store.File{ID: "file_1", Filename: "lab.pdf"}
+	if err := st.CreateFile(file); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.UpdateFileProcessedText(file.ID, "cholesterol 5.2"); err != nil {
+		t.Fatal(err)
+	}
+	var rawText string
+	st.DB().Raw("SELECT processed_text FROM files WHERE id = ?", file.ID).Scan(&rawText)
+	if !fieldcrypt.IsEncrypted(rawText) {
+		t.Errorf("Expected processed text encrypted, got %q", rawText)
+	}
+	st.Close()
+
+	// The wrong passphrase is refused rather than writing with another key
+	t.Setenv(store.PassphraseEnv, "battery staple")
+	if _, err := store.New(cfg); !errors.Is(err, fieldcrypt.ErrWrongKey) {
+		t.Fatalf("Expected ErrWrongKey, got %v", err)
+	}
+
+	// Decrypting everything lets encryption be turned off again
+	t.Setenv(store.PassphraseEnv, "correct horse")
+	st, err = store.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := st.EncryptExisting(false); err != nil || n != 2 {
+		t.Fatalf("Expected 2 values decrypted, got %d (%v)", n, err)
+	}
+	st.Close()
+
+	cfg.Storage.Encryption.Enabled = false
+	st, err = store.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	if raw := rawContent(st); raw != "my blood pressure was 150/95" {
+		t.Errorf("Expected plaintext after decrypting, got %q", raw)
+	}
+}
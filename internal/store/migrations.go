@@ -0,0 +1,290 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a versioned, one-off change to the database or workspace
+// format. Pending migrations run in order after a snapshot of the data
+// directory has been taken, each in its own database transaction.
+type Migration struct {
+	Version int
+	Name    string
+	// Up applies the migration. Database changes made through tx are rolled
+	// back if Up returns an error; workspace changes are not and rely on the
+	// pre-migration backup.
+	Up func(tx *gorm.DB, workspace string) error
+	// Down undoes Up for 'myrai db rollback'. Leave it nil for migrations
+	// that can't be undone; restore a backup instead.
+	Down func(tx *gorm.DB, workspace string) error
+}
+
+// SchemaMigration records an applied migration of a component
+type SchemaMigration struct {
+	Component string    `gorm:"primaryKey" json:"component"`
+	Version   int       `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// CoreComponent owns the migrations of the tables Store itself uses
+const CoreComponent = "core"
+
+// migrations lists every core migration in version order. Append new
+// migrations here; never renumber or remove released ones.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "baseline",
+		// Core tables are created by AutoMigrate; this marks the data
+		// directory as managed by versioned migrations.
+		Up: func(tx *gorm.DB, workspace string) error { return nil },
+	},
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string][]Migration{CoreComponent: migrations}
+)
+
+// RegisterMigrations adds the migrations of a component, usually a skill's
+// tables, from its package's init. Versions count from 1 per component.
+// Skill stores then call Migrate instead of creating tables themselves.
+func RegisterMigrations(component string, list ...Migration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	all := append(registry[component], list...)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	registry[component] = all
+}
+
+// Components returns the components with registered migrations, core first
+func Components() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		if name != CoreComponent {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{CoreComponent}, names...)
+}
+
+// ComponentMigrations returns the migrations registered for component
+func ComponentMigrations(component string) []Migration {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return append([]Migration(nil), registry[component]...)
+}
+
+// Migrate applies the pending migrations registered for component. Skill
+// stores call it when they are constructed.
+func Migrate(db *gorm.DB, component string) error {
+	_, err := ApplyComponentMigrations(db, component, "")
+	return err
+}
+
+// ApplyComponentMigrations applies the pending migrations registered for
+// component, returning those applied
+func ApplyComponentMigrations(db *gorm.DB, component, workspace string) ([]Migration, error) {
+	return applyMigrations(db, component, workspace, ComponentMigrations(component))
+}
+
+// MigrationReport describes the migrations applied when the store was opened
+type MigrationReport struct {
+	Backup  *Backup     // Snapshot taken before migrating, nil if none was needed
+	Applied []Migration // Migrations applied, in order
+}
+
+// Migrations returns the core migrations known to this build
+func Migrations() []Migration {
+	return migrations
+}
+
+// LatestSchemaVersion returns the core schema version this build migrates to
+func LatestSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion returns the highest core migration version applied to db
+func SchemaVersion(db *gorm.DB) (int, error) {
+	return ComponentVersion(db, CoreComponent)
+}
+
+// ComponentVersion returns the highest migration version of component
+// applied to db
+func ComponentVersion(db *gorm.DB, component string) (int, error) {
+	m := db.Migrator()
+	if !m.HasTable(&SchemaMigration{}) {
+		return 0, nil
+	}
+	query := db.Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)")
+	if m.HasColumn(&SchemaMigration{}, "component") {
+		query = query.Where("component = ?", component)
+	} else if component != CoreComponent {
+		return 0, nil
+	}
+	var version int
+	err := query.Scan(&version).Error
+	return version, err
+}
+
+// PendingMigrations returns the core migrations from list not yet applied
+// to db
+func PendingMigrations(db *gorm.DB, list []Migration) ([]Migration, error) {
+	return pendingMigrations(db, CoreComponent, list)
+}
+
+// PendingComponentMigrations returns the registered migrations of
+// component not yet applied to db
+func PendingComponentMigrations(db *gorm.DB, component string) ([]Migration, error) {
+	return pendingMigrations(db, component, ComponentMigrations(component))
+}
+
+func pendingMigrations(db *gorm.DB, component string, list []Migration) ([]Migration, error) {
+	applied := make(map[int]bool)
+	m := db.Migrator()
+	if m.HasTable(&SchemaMigration{}) {
+		query := db.Model(&SchemaMigration{}).Select("version")
+		if m.HasColumn(&SchemaMigration{}, "component") {
+			query = query.Where("component = ?", component)
+		} else if component != CoreComponent {
+			// Written by a release from before components: all core
+			query = nil
+		}
+		var rows []SchemaMigration
+		if query != nil {
+			if err := query.Find(&rows).Error; err != nil {
+				return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+			}
+		}
+		for _, r := range rows {
+			applied[r.Version] = true
+		}
+	}
+
+	var pending []Migration
+	for _, m := range list {
+		if !applied[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Version < pending[j].Version })
+	return pending, nil
+}
+
+// ApplyMigrations applies the pending core migrations from list. Each
+// migration and its bookkeeping row commit together; the first failure
+// stops the run and leaves later migrations pending.
+func ApplyMigrations(db *gorm.DB, workspace string, list []Migration) ([]Migration, error) {
+	return applyMigrations(db, CoreComponent, workspace, list)
+}
+
+func applyMigrations(db *gorm.DB, component, workspace string, list []Migration) ([]Migration, error) {
+	if err := ensureMigrationsTable(db); err != nil {
+		return nil, err
+	}
+
+	pending, err := pendingMigrations(db, component, list)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, m := range pending {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if m.Up != nil {
+				if err := m.Up(tx, workspace); err != nil {
+					return err
+				}
+			}
+			return tx.Create(&SchemaMigration{
+				Component: component,
+				Version:   m.Version,
+				Name:      m.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("%s migration %d (%s) failed: %w", component, m.Version, m.Name, err)
+		}
+		applied = append(applied, m)
+	}
+
+	return applied, nil
+}
+
+// RollbackMigration undoes the latest applied migration of component with
+// its Down step, returning it. It fails for migrations without one.
+func RollbackMigration(db *gorm.DB, component, workspace string) (*Migration, error) {
+	version, err := ComponentVersion(db, component)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("no %s migrations to roll back", component)
+	}
+
+	var m *Migration
+	for _, candidate := range ComponentMigrations(component) {
+		if candidate.Version == version {
+			m = &candidate
+			break
+		}
+	}
+	if m == nil {
+		return nil, fmt.Errorf("%s migration %d is not known to this build", component, version)
+	}
+	if m.Down == nil {
+		return nil, fmt.Errorf("%s migration %d (%s) can't be rolled back; restore a backup with 'myrai upgrade --rollback'", component, m.Version, m.Name)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := m.Down(tx, workspace); err != nil {
+			return err
+		}
+		return tx.Where("component = ? AND version = ?", component, m.Version).Delete(&SchemaMigration{}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rolling back %s migration %d (%s) failed: %w", component, m.Version, m.Name, err)
+	}
+	return m, nil
+}
+
+// ensureMigrationsTable creates the migrations table, and rebuilds one
+// from before components with the component in its key
+func ensureMigrationsTable(db *gorm.DB) error {
+	m := db.Migrator()
+	if m.HasTable(&SchemaMigration{}) && !m.HasColumn(&SchemaMigration{}, "component") {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Migrator().RenameTable("schema_migrations", "schema_migrations_old"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().CreateTable(&SchemaMigration{}); err != nil {
+				return err
+			}
+			if err := tx.Exec("INSERT INTO schema_migrations (component, version, name, applied_at) "+
+				"SELECT ?, version, name, applied_at FROM schema_migrations_old", CoreComponent).Error; err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable("schema_migrations_old")
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upgrade migrations table: %w", err)
+		}
+	}
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	return nil
+}
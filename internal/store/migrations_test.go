@@ -0,0 +1,264 @@
+package store_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"gorm.io/gorm"
+)
+
+func newTestConfig(t *testing.T) *config.Config {
+	dir := t.TempDir()
+	return &config.Config{
+		Storage: config.StorageConfig{
+			DataDir:    dir,
+			SQLitePath: filepath.Join(dir, "test.db"),
+			BadgerPath: filepath.Join(dir, "badger"),
+		},
+	}
+}
+
+func TestApplyMigrations_Transactional(t *testing.T) {
+	cfg := newTestConfig(t)
+	st, err := store.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer st.Close()
+	db := st.DB()
+
+	if v, _ := store.SchemaVersion(db); v != store.LatestSchemaVersion() {
+		t.Errorf("Expected new store at schema version %d, got %d", store.LatestSchemaVersion(), v)
+	}
+
+	list := append(store.Migrations(),
+		store.Migration{
+			Version: 1000,
+			Name:    "add notes table",
+			Up: func(tx *gorm.DB, workspace string) error {
+				return tx.Exec("CREATE TABLE notes (id INTEGER PRIMARY KEY)").Error
+			},
+		},
+		store.Migration{
+			Version: 1001,
+			Name:    "broken",
+			Up: func(tx *gorm.DB, workspace string) error {
+				if err := tx.Exec("CREATE TABLE half_done (id INTEGER PRIMARY KEY)").Error; err != nil {
+					return err
+				}
+				return errors.New("boom")
+			},
+		},
+	)
+
+	applied, err := store.ApplyMigrations(db, cfg.Storage.DataDir, list)
+	if err == nil {
+		t.Fatal("Expected broken migration to fail")
+	}
+	if len(applied) != 1 || applied[0].Version != 1000 {
+		t.Errorf("Expected only migration 1000 to apply, got %+v", applied)
+	}
+	if !db.Migrator().HasTable("notes") {
+		t.Error("Expected notes table from the successful migration")
+	}
+	if db.Migrator().HasTable("half_done") {
+		t.Error("Expected failed migration's changes to be rolled back")
+	}
+
+	pending, err := store.PendingMigrations(db, list)
+	if err != nil {
+		t.Fatalf("PendingMigrations failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Version != 1001 {
+		t.Errorf("Expected migration 1001 to remain pending, got %+v", pending)
+	}
+}
+
+func TestBackup_PreMigrateAndRollback(t *testing.T) {
+	cfg := newTestConfig(t)
+	identity := filepath.Join(cfg.Storage.DataDir, "IDENTITY.md")
+
+	st, err := store.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	if st.MigrationReport().Backup != nil {
+		t.Error("Expected no backup for a fresh data directory")
+	}
+
+	conv := &store.Conversation{Title: "Before upgrade"}
+	if err := st.CreateConversation(conv); err != nil {
+		t.Fatalf("Failed to create conversation: %v", err)
+	}
+	if err := os.WriteFile(identity, []byte("# Identity v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a data directory from an older release with a pending migration
+	if err := st.DB().Exec("DELETE FROM schema_migrations").Error; err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+
+	st, err = store.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	report := st.MigrationReport()
+	if report.Backup == nil {
+		t.Fatal("Expected a pre-migrate backup")
+	}
+	if len(report.Applied) != len(store.Migrations()) {
+		t.Errorf("Expected %d applied migrations, got %d", len(store.Migrations()), len(report.Applied))
+	}
+
+	// Changes made after the upgrade
+	if err := st.CreateConversation(&store.Conversation{Title: "After upgrade"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(identity, []byte("# Identity v2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(cfg.Storage.DataDir, "diary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+
+	restored, err := store.RestoreBackup(&cfg.Storage, "")
+	if err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	if restored.ID != report.Backup.ID {
+		t.Errorf("Expected latest backup %s to be restored, got %s", report.Backup.ID, restored.ID)
+	}
+
+	data, err := os.ReadFile(identity)
+	if err != nil || string(data) != "# Identity v1" {
+		t.Errorf("Expected workspace to be restored, got %q (%v)", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Storage.DataDir, "diary")); !os.IsNotExist(err) {
+		t.Error("Expected workspace entries created after the backup to be removed")
+	}
+
+	st, err = store.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to open restored store: %v", err)
+	}
+	defer st.Close()
+
+	convs, err := st.ListConversations(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(convs) != 1 || convs[0].Title != "Before upgrade" {
+		t.Errorf("Expected only the pre-upgrade conversation, got %+v", convs)
+	}
+
+	backups, err := store.ListBackups(&cfg.Storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foundUndo := false
+	for _, b := range backups {
+		if b.Label == store.BackupLabelPreRollback {
+			foundUndo = true
+		}
+	}
+	if !foundUndo {
+		t.Error("Expected the rollback to back up the replaced data")
+	}
+}
+
+func TestComponentMigrations_RegisterAndRollback(t *testing.T) {
+	cfg := newTestConfig(t)
+	st, err := store.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer st.Close()
+	db := st.DB()
+
+	type Widget struct {
+		ID   string `gorm:"primaryKey"`
+		Name string
+	}
+	store.RegisterMigrations("widgets", store.Migration{
+		Version: 1,
+		Name:    "create widgets",
+		Up:      func(tx *gorm.DB, workspace string) error { return tx.AutoMigrate(&Widget{}) },
+		Down:    func(tx *gorm.DB, workspace string) error { return tx.Migrator().DropTable(&Widget{}) },
+	}, store.Migration{
+		Version: 2,
+		Name:    "add widget colour",
+		Up: func(tx *gorm.DB, workspace string) error {
+			return tx.Exec("ALTER TABLE widgets ADD COLUMN colour TEXT").Error
+		},
+	})
+
+	if err := store.Migrate(db, "widgets"); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if v, _ := store.ComponentVersion(db, "widgets"); v != 2 {
+		t.Errorf("Expected widgets at version 2, got %d", v)
+	}
+	// Versions count per component, so core's version 1 is separate
+	if v, _ := store.SchemaVersion(db); v != store.LatestSchemaVersion() {
+		t.Errorf("Expected core unaffected at %d, got %d", store.LatestSchemaVersion(), v)
+	}
+
+	// Migration 2 has no Down step
+	if _, err := store.RollbackMigration(db, "widgets", ""); err == nil {
+		t.Fatal("Expected rolling back a migration without Down to fail")
+	}
+	if err := db.Exec("DELETE FROM schema_migrations WHERE component = 'widgets' AND version = 2").Error; err != nil {
+		t.Fatal(err)
+	}
+	m, err := store.RollbackMigration(db, "widgets", "")
+	if err != nil || m.Version != 1 {
+		t.Fatalf("Expected migration 1 rolled back, got %+v (%v)", m, err)
+	}
+	if db.Migrator().HasTable("widgets") {
+		t.Error("Expected the Down step to drop the table")
+	}
+	if pending, _ := store.PendingComponentMigrations(db, "widgets"); len(pending) != 2 {
+		t.Errorf("Expected both migrations pending again, got %+v", pending)
+	}
+}
+
+func TestApplyMigrations_UpgradesLegacyTable(t *testing.T) {
+	cfg := newTestConfig(t)
+	st, err := store.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer st.Close()
+	db := st.DB()
+
+	// The migrations table as releases before components wrote it
+	for _, stmt := range []string{
+		"DROP TABLE schema_migrations",
+		"CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, name TEXT, applied_at DATETIME)",
+		"INSERT INTO schema_migrations VALUES (1, 'baseline', CURRENT_TIMESTAMP)",
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pending, _ := store.PendingMigrations(db, store.Migrations()); len(pending) != 0 {
+		t.Errorf("Expected the legacy rows to count as core, got %+v pending", pending)
+	}
+
+	if _, err := store.ApplyMigrations(db, cfg.Storage.DataDir, store.Migrations()); err != nil {
+		t.Fatalf("ApplyMigrations failed: %v", err)
+	}
+	if !db.Migrator().HasColumn(&store.SchemaMigration{}, "component") {
+		t.Fatal("Expected the table rebuilt with a component column")
+	}
+	if v, _ := store.SchemaVersion(db); v != 1 {
+		t.Errorf("Expected core version 1 kept, got %d", v)
+	}
+}
@@ -26,6 +26,10 @@ type Conversation struct {
 	TokensUsed   int64     `json:"tokens_used"`
 	MessageCount int       `json:"message_count"`
 	IsArchived   bool      `json:"is_archived"`
+	TitleManual  bool      `json:"title_manual"` // set by the user, so never re-titled
+	TitledAt     int       `json:"titled_at"`    // MessageCount when last titled automatically
+	Route        string    `json:"route"`        // fast or smart when pinned with /model; empty routes each turn
+	SmartTurns   int       `json:"smart_turns"`  // turns left on the smart model
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 
@@ -38,12 +42,12 @@ type Message struct {
 	ID               string          `gorm:"primaryKey" json:"id"`
 	ConversationID   string          `gorm:"index:idx_conv_created" json:"conversation_id"`
 	Role             string          `json:"role"` // user, assistant, system, tool
-	Content          string          `json:"content"`
+	Content          string          `json:"content" gorm:"serializer:encrypted"`
 	Tokens           int             `json:"tokens"`
-	ToolCalls        json.RawMessage `json:"tool_calls,omitempty" gorm:"type:text"`
-	ToolResults      json.RawMessage `json:"tool_results,omitempty" gorm:"type:text"`
-	ToolCallID       string          `json:"tool_call_id,omitempty"`                       // For tool role messages
-	ReasoningContent string          `json:"reasoning_content,omitempty" gorm:"type:text"` // For thinking/reasoning models like Kimi
+	ToolCalls        json.RawMessage `json:"tool_calls,omitempty" gorm:"type:text;serializer:encrypted"`
+	ToolResults      json.RawMessage `json:"tool_results,omitempty" gorm:"type:text;serializer:encrypted"`
+	ToolCallID       string          `json:"tool_call_id,omitempty"`                                            // For tool role messages
+	ReasoningContent string          `json:"reasoning_content,omitempty" gorm:"type:text;serializer:encrypted"` // For thinking/reasoning models like Kimi
 	LatencyMs        int             `json:"latency_ms"`
 	CreatedAt        time.Time       `gorm:"index:idx_conv_created" json:"created_at"`
 }
@@ -57,7 +61,8 @@ type Memory struct {
 	Importance   int        `json:"importance"`         // 1-10
 	AccessCount  int        `json:"access_count"`
 	LastAccessed *time.Time `json:"last_accessed"`
-	Source       string     `json:"source"` // conversation_id or import
+	Source       string     `json:"source"`                         // conversation_id or import
+	Project      string     `gorm:"index" json:"project,omitempty"` // made in this project; empty applies everywhere
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
 }
@@ -71,7 +76,7 @@ type File struct {
 	StoragePath    string    `json:"storage_path"`
 	ConversationID *string   `json:"conversation_id,omitempty"`
 	SourceChatID   *int64    `json:"source_chat_id,omitempty"` // Which chat uploaded this file
-	ProcessedText  string    `json:"processed_text,omitempty" gorm:"type:text"`
+	ProcessedText  string    `json:"processed_text,omitempty" gorm:"type:text;serializer:encrypted"`
 	CreatedAt      time.Time `json:"created_at"`
 }
 
@@ -106,7 +111,8 @@ type ScheduledJob struct {
 type ChatMapping struct {
 	ID             string    `gorm:"primaryKey" json:"id"`
 	ChatID         int64     `json:"chat_id"`
-	ChatType       string    `json:"chat_type"` // telegram, discord, etc.
+	ThreadID       int64     `json:"thread_id,omitempty"` // forum topic within the chat; 0 for none
+	ChatType       string    `json:"chat_type"`           // telegram, discord, etc.
 	ConversationID string    `gorm:"index" json:"conversation_id"`
 	IsActive       bool      `json:"is_active"` // Whether this is the currently active conversation
 	CreatedAt      time.Time `json:"created_at"`
@@ -124,6 +130,175 @@ func (cm *ChatMapping) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// Pin is a context item pinned to a conversation. Pins are always included in
+// the model context regardless of summarization or truncation.
+type Pin struct {
+	ID             string    `gorm:"primaryKey" json:"id"`
+	ConversationID string    `gorm:"index" json:"conversation_id"`
+	Kind           string    `json:"kind"` // fact, file, tool_output
+	Label          string    `json:"label,omitempty"`
+	Content        string    `json:"content" gorm:"type:text;serializer:encrypted"`
+	Tokens         int       `json:"tokens"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook for Pin
+func (p *Pin) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = generateID("pin")
+	}
+	if p.Kind == "" {
+		p.Kind = "fact"
+	}
+	return nil
+}
+
+// WidgetToken is a long-lived, read-only credential for home-screen widgets.
+// Only a hash of the token is stored; the token itself is shown once on
+// creation.
+type WidgetToken struct {
+	ID         string     `gorm:"primaryKey" json:"id"`
+	Name       string     `json:"name"`
+	UserID     string     `json:"user_id"` // Whose tasks and events the widget shows
+	TokenHash  string     `gorm:"uniqueIndex" json:"-"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// BeforeCreate hook for WidgetToken
+func (w *WidgetToken) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = generateID("wgt")
+	}
+	return nil
+}
+
+// Plan statuses
+const (
+	PlanStatusActive    = "active"
+	PlanStatusPaused    = "paused"
+	PlanStatusCompleted = "completed"
+	PlanStatusFailed    = "failed"
+)
+
+// Plan step statuses
+const (
+	StepStatusPending   = "pending"
+	StepStatusRunning   = "running"
+	StepStatusCompleted = "completed"
+	StepStatusFailed    = "failed"
+	StepStatusSkipped   = "skipped"
+)
+
+// Plan is a multi-step task plan executed by the agent one step at a time.
+// A paused plan can be resumed later, in any conversation.
+type Plan struct {
+	ID             string     `gorm:"primaryKey" json:"id"`
+	ConversationID string     `gorm:"index" json:"conversation_id"` // Conversation the plan last ran in
+	Goal           string     `json:"goal" gorm:"type:text"`
+	Status         string     `gorm:"index" json:"status"`
+	Steps          []PlanStep `gorm:"foreignKey:PlanID" json:"steps"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// PlanStep is a single step of a plan
+type PlanStep struct {
+	ID          string     `gorm:"primaryKey" json:"id"`
+	PlanID      string     `gorm:"index" json:"plan_id"`
+	Position    int        `json:"position"` // 1-based order within the plan
+	Description string     `json:"description" gorm:"type:text"`
+	Status      string     `json:"status"`
+	Result      string     `json:"result,omitempty" gorm:"type:text"`
+	Artifacts   []string   `json:"artifacts,omitempty" gorm:"serializer:json"` // Files, URLs or IDs produced by the step
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Async job statuses
+const (
+	AsyncJobPending = "pending"
+	AsyncJobRunning = "running"
+	AsyncJobDone    = "done"
+	AsyncJobFailed  = "failed"
+)
+
+// AsyncJob is long agent work queued from a conversation. It runs in the
+// background, survives restarts, and its result is sent to the user when
+// it finishes.
+type AsyncJob struct {
+	ID             string     `gorm:"primaryKey" json:"id"`
+	UserID         string     `gorm:"index" json:"user_id"`
+	ConversationID string     `json:"conversation_id,omitempty"` // Conversation the job was asked for in
+	Title          string     `json:"title"`
+	Payload        string     `json:"payload" gorm:"type:text"` // The task, as a prompt for the agent
+	Status         string     `gorm:"index" json:"status"`
+	Result         string     `json:"result,omitempty" gorm:"type:text"`
+	Error          string     `json:"error,omitempty"`
+	Attempts       int        `json:"attempts"`
+	TokensUsed     int        `json:"tokens_used"`
+	RunID          string     `json:"run_id,omitempty"` // Conversation the job ran in
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
+
+// BeforeCreate hook for AsyncJob
+func (j *AsyncJob) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == "" {
+		j.ID = generateID("ajob")
+	}
+	if j.Status == "" {
+		j.Status = AsyncJobPending
+	}
+	return nil
+}
+
+// BeforeCreate hook for Plan
+func (p *Plan) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = generateID("plan")
+	}
+	if p.Status == "" {
+		p.Status = PlanStatusActive
+	}
+	return nil
+}
+
+// BeforeCreate hook for PlanStep
+func (s *PlanStep) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = generateID("step")
+	}
+	if s.Status == "" {
+		s.Status = StepStatusPending
+	}
+	return nil
+}
+
+// NextStep returns the first step that has not finished, or nil when every
+// step is done. A step left running by an interrupted run is returned again.
+func (p *Plan) NextStep() *PlanStep {
+	for i := range p.Steps {
+		switch p.Steps[i].Status {
+		case StepStatusPending, StepStatusRunning:
+			return &p.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Progress returns the number of finished steps and the total
+func (p *Plan) Progress() (int, int) {
+	done := 0
+	for _, step := range p.Steps {
+		if step.Status == StepStatusCompleted || step.Status == StepStatusSkipped {
+			done++
+		}
+	}
+	return done, len(p.Steps)
+}
+
 // Config stores key-value configuration
 type Config struct {
 	Key       string    `gorm:"primaryKey" json:"key"`
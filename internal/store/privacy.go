@@ -0,0 +1,232 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RedactedContent replaces the body of a redacted message
+const RedactedContent = "[redacted]"
+
+// ConversationsComponent is the purge component for conversations and
+// their messages
+const ConversationsComponent = "conversations"
+
+// privacyBatch is how many rows are redacted or deleted per transaction
+const privacyBatch = 500
+
+// A PurgeFunc permanently deletes a component's records from before cutoff
+// and returns how many were deleted
+type PurgeFunc func(db *gorm.DB, before time.Time) (int64, error)
+
+var (
+	purgeMu sync.Mutex
+	purges  = map[string]PurgeFunc{}
+)
+
+func init() {
+	RegisterPurge(ConversationsComponent, purgeConversations)
+}
+
+// RegisterPurge adds the purge for a component, usually a skill, so that
+// 'myrai privacy purge' can delete its old records. Skills call it from
+// init, next to RegisterMigrations.
+func RegisterPurge(component string, fn PurgeFunc) {
+	purgeMu.Lock()
+	defer purgeMu.Unlock()
+	purges[component] = fn
+}
+
+// PurgeComponents returns the components that can be purged, conversations
+// first and the rest sorted
+func PurgeComponents() []string {
+	purgeMu.Lock()
+	defer purgeMu.Unlock()
+	var names []string
+	for name := range purges {
+		if name != ConversationsComponent {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{ConversationsComponent}, names...)
+}
+
+// Purge permanently deletes a component's records from before cutoff
+func Purge(db *gorm.DB, component string, before time.Time) (int64, error) {
+	purgeMu.Lock()
+	fn, ok := purges[component]
+	purgeMu.Unlock()
+	if !ok {
+		return 0, fmt.Errorf("nothing to purge for %s", component)
+	}
+	// The skill may never have run, leaving its tables to be created
+	if component != ConversationsComponent && len(ComponentMigrations(component)) > 0 {
+		if err := Migrate(db, component); err != nil {
+			return 0, err
+		}
+	}
+	n, err := fn(db, before)
+	if err != nil {
+		return n, fmt.Errorf("failed to purge %s: %w", component, err)
+	}
+	return n, nil
+}
+
+// ForgetConversation permanently deletes a conversation with its messages,
+// pins, uploaded files, chat mappings and the memories learned from it
+func (s *Store) ForgetConversation(id string) error {
+	var paths []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if paths, err = deleteConversations(tx, []string{id}); err != nil {
+			return err
+		}
+		return tx.Where("source = ?", id).Delete(&Memory{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	removeFiles(paths)
+	return nil
+}
+
+// PurgeConversations permanently deletes the conversations last active
+// before cutoff and returns how many were deleted. Memories learned from
+// them are kept.
+func (s *Store) PurgeConversations(before time.Time) (int64, error) {
+	return purgeConversations(s.db, before)
+}
+
+func purgeConversations(db *gorm.DB, before time.Time) (int64, error) {
+	var ids []string
+	if err := db.Model(&Conversation{}).Where("updated_at < ?", before).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	var paths []string
+	for start := 0; start < len(ids); start += privacyBatch {
+		end := start + privacyBatch
+		if end > len(ids) {
+			end = len(ids)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			removed, err := deleteConversations(tx, ids[start:end])
+			paths = append(paths, removed...)
+			return err
+		})
+		if err != nil {
+			return int64(start), err
+		}
+	}
+	removeFiles(paths)
+	return int64(len(ids)), nil
+}
+
+// deleteConversations deletes conversations and everything attached to
+// them, returning the paths of their uploaded files to remove once the
+// transaction commits
+func deleteConversations(tx *gorm.DB, ids []string) ([]string, error) {
+	var files []File
+	if err := tx.Where("conversation_id IN ?", ids).Find(&files).Error; err != nil {
+		return nil, err
+	}
+	for _, model := range []interface{}{&Message{}, &Pin{}, &File{}, &ChatMapping{}} {
+		if err := tx.Where("conversation_id IN ?", ids).Delete(model).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Where("id IN ?", ids).Delete(&Conversation{}).Error; err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, f := range files {
+		if f.StoragePath != "" {
+			paths = append(paths, f.StoragePath)
+		}
+	}
+	return paths, nil
+}
+
+func removeFiles(paths []string) {
+	for _, path := range paths {
+		os.Remove(path)
+	}
+}
+
+// RedactMessages replaces the bodies, tool results and tool arguments of
+// messages created before cutoff, returning how many were redacted. Token
+// counts and latencies are kept, so usage stats still add up.
+func (s *Store) RedactMessages(before time.Time) (int64, error) {
+	var total int64
+	for {
+		var msgs []Message
+		err := s.db.Where("created_at < ? AND content <> ?", before, RedactedContent).
+			Order("created_at").Limit(privacyBatch).Find(&msgs).Error
+		if err != nil {
+			return total, err
+		}
+		if len(msgs) == 0 {
+			return total, nil
+		}
+
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			for i := range msgs {
+				m := &msgs[i]
+				m.Content = RedactedContent
+				m.ReasoningContent = ""
+				m.ToolResults = nil
+				m.ToolCalls = redactToolCalls(m.ToolCalls)
+				err := tx.Model(m).Select("content", "reasoning_content", "tool_results", "tool_calls").Updates(m).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+		total += int64(len(msgs))
+	}
+}
+
+// redactToolCalls blanks the arguments of stored tool calls. Their IDs and
+// names are kept, so tool results still pair with the calls that made them.
+func redactToolCalls(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return raw
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil
+	}
+
+	redact := func(call interface{}) {
+		if c, ok := call.(map[string]interface{}); ok {
+			if fn, ok := c["function"].(map[string]interface{}); ok {
+				fn["arguments"] = "{}"
+			}
+		}
+	}
+	if calls, ok := v.([]interface{}); ok {
+		for _, c := range calls {
+			redact(c)
+		}
+	} else {
+		redact(v)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
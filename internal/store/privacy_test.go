@@ -0,0 +1,123 @@
+package store_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+func TestPrivacy_ForgetAndPurge(t *testing.T) {
+	st, err := store.New(newTestConfig(t))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	old := &store.Conversation{Title: "Old", UpdatedAt: time.Now().AddDate(0, 0, -100)}
+	recent := &store.Conversation{Title: "Recent"}
+	forgotten := &store.Conversation{Title: "Forget me"}
+	for _, c := range []*store.Conversation{old, recent, forgotten} {
+		if err := st.CreateConversation(c); err != nil {
+			t.Fatal(err)
+		}
+		st.CreateMessage(&store.Message{ConversationID: c.ID, Role: "user", Content: "hello"})
+	}
+	st.DB().Model(old).UpdateColumn("updated_at", time.Now().AddDate(0, 0, -100))
+	st.CreatePin(&store.Pin{ConversationID: forgotten.ID, Kind: "fact", Content: "my PIN is 1234"})
+	st.SetChatMapping(42, "telegram", forgotten.ID)
+	st.CreateMemory(&store.Memory{Type: "fact", Content: "PIN 1234", Source: forgotten.ID})
+
+	if err := st.ForgetConversation(forgotten.ID); err != nil {
+		t.Fatalf("ForgetConversation failed: %v", err)
+	}
+	if _, err := st.GetConversation(forgotten.ID); err == nil {
+		t.Error("Expected the conversation deleted")
+	}
+	if n, _ := st.GetMessageCount(forgotten.ID); n != 0 {
+		t.Errorf("Expected its messages deleted, got %d", n)
+	}
+	if pins, _ := st.ListPins(forgotten.ID); len(pins) != 0 {
+		t.Errorf("Expected its pins deleted, got %d", len(pins))
+	}
+	if _, err := st.GetChatMapping(42, "telegram"); err == nil {
+		t.Error("Expected the chat mapping deleted, so the chat starts afresh")
+	}
+	if mems, _ := st.SearchMemories("PIN", 10); len(mems) != 0 {
+		t.Errorf("Expected memories from it deleted, got %d", len(mems))
+	}
+
+	n, err := store.Purge(st.DB(), store.ConversationsComponent, time.Now().AddDate(0, 0, -30))
+	if err != nil || n != 1 {
+		t.Fatalf("Expected 1 conversation purged, got %d (%v)", n, err)
+	}
+	convs, _ := st.ListConversations(10, 0)
+	if len(convs) != 1 || convs[0].ID != recent.ID {
+		t.Errorf("Expected only the recent conversation kept, got %+v", convs)
+	}
+
+	if _, err := store.Purge(st.DB(), "nonexistent", time.Now()); err == nil {
+		t.Error("Expected an unknown component to be rejected")
+	}
+}
+
+func TestPrivacy_RedactMessages(t *testing.T) {
+	st, err := store.New(newTestConfig(t))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	conv := &store.Conversation{Title: "Doctor"}
+	st.CreateConversation(conv)
+	oldCall := &store.Message{
+		ConversationID: conv.ID,
+		Role:           "assistant",
+		Content:        "Logging that",
+		Tokens:         12,
+		ToolCalls:      []byte(`[{"id":"call_1","type":"function","function":{"name":"log_metric","arguments":"{\"value\":\"150/95\"}"}}]`),
+		CreatedAt:      time.Now().AddDate(0, 0, -60),
+	}
+	oldResult := &store.Message{
+		ConversationID: conv.ID,
+		Role:           "tool",
+		Content:        "Logged 150/95",
+		ToolCallID:     "call_1",
+		ToolResults:    []byte(`{"value":"150/95"}`),
+		CreatedAt:      time.Now().AddDate(0, 0, -60),
+	}
+	fresh := &store.Message{ConversationID: conv.ID, Role: "user", Content: "and today?"}
+	for _, m := range []*store.Message{oldCall, oldResult, fresh} {
+		if err := st.CreateMessage(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := st.RedactMessages(time.Now().AddDate(0, 0, -30))
+	if err != nil || n != 2 {
+		t.Fatalf("Expected 2 messages redacted, got %d (%v)", n, err)
+	}
+	if n, _ := st.RedactMessages(time.Now().AddDate(0, 0, -30)); n != 0 {
+		t.Errorf("Expected redacting again to change nothing, got %d", n)
+	}
+
+	msgs, err := st.GetMessages(conv.ID, 10, 0)
+	if err != nil || len(msgs) != 3 {
+		t.Fatalf("Expected 3 messages, got %d (%v)", len(msgs), err)
+	}
+	for _, m := range msgs[:2] {
+		if m.Content != store.RedactedContent || len(m.ToolResults) != 0 {
+			t.Errorf("Expected %s redacted, got %q %s", m.Role, m.Content, m.ToolResults)
+		}
+		if strings.Contains(string(m.ToolCalls), "150/95") {
+			t.Errorf("Expected tool arguments blanked, got %s", m.ToolCalls)
+		}
+	}
+	if msgs[0].Tokens != 12 || !strings.Contains(string(msgs[0].ToolCalls), "log_metric") {
+		t.Errorf("Expected token counts and tool names kept, got %d %s", msgs[0].Tokens, msgs[0].ToolCalls)
+	}
+	if msgs[2].Content != "and today?" {
+		t.Errorf("Expected recent messages untouched, got %q", msgs[2].Content)
+	}
+}
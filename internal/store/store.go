@@ -2,10 +2,15 @@ package store
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/dgraph-io/badger/v4"
@@ -19,18 +24,16 @@ import (
 
 // Store provides unified access to SQLite and BadgerDB
 type Store struct {
-	db     *gorm.DB
-	badger *badger.DB
-	config *config.StorageConfig
+	db              *gorm.DB
+	badger          *badger.DB
+	config          *config.StorageConfig
+	migrationReport MigrationReport
 }
 
 // New creates a new Store instance
 func New(cfg *config.Config) (*Store, error) {
 	// Initialize SQLite
-	sqlitePath := cfg.Storage.SQLitePath
-	if sqlitePath == "" {
-		sqlitePath = filepath.Join(cfg.Storage.DataDir, "myrai.db")
-	}
+	sqlitePath := SQLitePath(&cfg.Storage)
 
 	// Open SQLite with optimizations
 	sqliteDB, err := sql.Open("sqlite", sqlitePath+"?_journal=WAL&_synchronous=NORMAL&_busy_timeout=5000&_cache_size=-64000")
@@ -52,6 +55,25 @@ func New(cfg *config.Config) (*Store, error) {
 		return nil, fmt.Errorf("failed to open sqlite: %w", err)
 	}
 
+	// Snapshot existing data before any schema or workspace migration runs,
+	// including the ones skill stores apply as they start
+	var report MigrationReport
+	pending := 0
+	for _, component := range Components() {
+		list, err := PendingComponentMigrations(db, component)
+		if err != nil {
+			return nil, err
+		}
+		pending += len(list)
+	}
+	if pending > 0 && db.Migrator().HasTable(&Conversation{}) {
+		backup, err := CreateBackup(&cfg.Storage, BackupLabelPreMigrate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up before migrating: %w", err)
+		}
+		report.Backup = backup
+	}
+
 	// Auto-migrate schemas
 	if err := db.AutoMigrate(
 		&Conversation{},
@@ -63,10 +85,27 @@ func New(cfg *config.Config) (*Store, error) {
 		&User{},
 		&Config{},
 		&ChatMapping{},
+		&Pin{},
+		&WidgetToken{},
+		&Plan{},
+		&PlanStep{},
+		&AsyncJob{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to migrate: %w", err)
 	}
 
+	report.Applied, err = ApplyMigrations(db, cfg.Storage.DataDir, migrations)
+	if err != nil {
+		if report.Backup != nil {
+			return nil, fmt.Errorf("%w (data was backed up to %s; restore it with 'myrai upgrade --rollback')", err, report.Backup.Path)
+		}
+		return nil, err
+	}
+
+	if err := setupEncryption(db, &cfg.Storage.Encryption); err != nil {
+		return nil, err
+	}
+
 	// Initialize BadgerDB
 	badgerPath := cfg.Storage.BadgerPath
 	if badgerPath == "" {
@@ -87,9 +126,10 @@ func New(cfg *config.Config) (*Store, error) {
 	}
 
 	store := &Store{
-		db:     db,
-		badger: badgerDB,
-		config: &cfg.Storage,
+		db:              db,
+		badger:          badgerDB,
+		config:          &cfg.Storage,
+		migrationReport: report,
 	}
 
 	// Create default user if none exists
@@ -115,6 +155,12 @@ func (s *Store) Badger() *badger.DB {
 	return s.badger
 }
 
+// MigrationReport returns the backup taken and migrations applied when the
+// store was opened
+func (s *Store) MigrationReport() MigrationReport {
+	return s.migrationReport
+}
+
 // createDefaultUser creates a default user if the database is empty
 func (s *Store) createDefaultUser() error {
 	var count int64
@@ -157,11 +203,91 @@ func (s *Store) ListConversations(limit, offset int) ([]Conversation, error) {
 	return convs, err
 }
 
+// ListActiveConversations lists the conversations that haven't been
+// deleted, most recently updated first
+func (s *Store) ListActiveConversations(limit, offset int) ([]Conversation, error) {
+	var convs []Conversation
+	err := s.db.Where("is_archived = ?", false).
+		Order("updated_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&convs).Error
+	return convs, err
+}
+
+// FindConversation finds a conversation by its ID or a start of it that
+// matches no other conversation
+func (s *Store) FindConversation(idPrefix string) (*Conversation, error) {
+	if conv, err := s.GetConversation(idPrefix); err == nil {
+		return conv, nil
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(idPrefix)
+	var convs []Conversation
+	if err := s.db.Where(`id LIKE ? ESCAPE '\'`, escaped+"%").Limit(2).Find(&convs).Error; err != nil {
+		return nil, err
+	}
+	switch len(convs) {
+	case 0:
+		return nil, fmt.Errorf("conversation %q not found", idPrefix)
+	case 1:
+		return &convs[0], nil
+	}
+	return nil, fmt.Errorf("%q matches more than one conversation", idPrefix)
+}
+
 // UpdateConversation updates a conversation
 func (s *Store) UpdateConversation(conv *Conversation) error {
 	return s.db.Save(conv).Error
 }
 
+// UpdateConversationStats saves a conversation's token and message counts,
+// leaving its title to RenameConversation and SetAutoTitle
+func (s *Store) UpdateConversationStats(conv *Conversation) error {
+	return s.db.Model(conv).Select("tokens_used", "message_count", "updated_at").Updates(conv).Error
+}
+
+// RenameConversation gives a conversation the user's title, which is never
+// replaced automatically
+func (s *Store) RenameConversation(id, title string) error {
+	result := s.db.Model(&Conversation{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"title": title, "title_manual": true})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetAutoTitle records a generated title, checked at messageCount messages.
+// It does nothing once the user has named the conversation.
+func (s *Store) SetAutoTitle(id, title string, messageCount int) error {
+	return s.db.Model(&Conversation{}).Where("id = ? AND title_manual = ?", id, false).
+		Updates(map[string]interface{}{"title": title, "titled_at": messageCount}).Error
+}
+
+// SetConversationRoute pins a conversation to the fast or smart model, or
+// with "" lets each turn be routed again
+func (s *Store) SetConversationRoute(id, route string) error {
+	result := s.db.Model(&Conversation{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"route": route, "smart_turns": 0})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetSmartTurns records how many more turns a conversation stays on the
+// smart model
+func (s *Store) SetSmartTurns(id string, turns int) error {
+	return s.db.Model(&Conversation{}).Where("id = ?", id).Update("smart_turns", turns).Error
+}
+
 // DeleteConversation soft-deletes a conversation
 func (s *Store) DeleteConversation(id string) error {
 	return s.db.Model(&Conversation{}).Where("id = ?", id).Update("is_archived", true).Error
@@ -185,6 +311,22 @@ func (s *Store) GetMessages(conversationID string, limit, offset int) ([]Message
 	return msgs, err
 }
 
+// GetLatestMessage returns the newest message of a conversation with role,
+// or nil when there is none
+func (s *Store) GetLatestMessage(conversationID, role string) (*Message, error) {
+	var msg Message
+	err := s.db.Where("conversation_id = ? AND role = ?", conversationID, role).
+		Order("created_at DESC").
+		First(&msg).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
 // GetMessageCount returns the number of messages in a conversation
 func (s *Store) GetMessageCount(conversationID string) (int64, error) {
 	var count int64
@@ -220,8 +362,14 @@ func (s *Store) GetRecentMemories(limit int) ([]Memory, error) {
 
 // GetChatMapping retrieves the active conversation ID for a chat
 func (s *Store) GetChatMapping(chatID int64, chatType string) (*ChatMapping, error) {
+	return s.GetThreadMapping(chatID, 0, chatType)
+}
+
+// GetThreadMapping retrieves the active conversation ID for a forum topic
+// or thread within a chat
+func (s *Store) GetThreadMapping(chatID, threadID int64, chatType string) (*ChatMapping, error) {
 	var mapping ChatMapping
-	err := s.db.Where("chat_id = ? AND chat_type = ? AND is_active = ?", chatID, chatType, true).
+	err := s.db.Where("chat_id = ? AND thread_id = ? AND chat_type = ? AND is_active = ?", chatID, threadID, chatType, true).
 		Order("updated_at DESC").
 		First(&mapping).Error
 	if err != nil {
@@ -232,9 +380,15 @@ func (s *Store) GetChatMapping(chatID int64, chatType string) (*ChatMapping, err
 
 // SetChatMapping creates or updates the mapping between a chat and conversation
 func (s *Store) SetChatMapping(chatID int64, chatType, conversationID string) error {
+	return s.SetThreadMapping(chatID, 0, chatType, conversationID)
+}
+
+// SetThreadMapping creates or updates the mapping between a forum topic or
+// thread and conversation
+func (s *Store) SetThreadMapping(chatID, threadID int64, chatType, conversationID string) error {
 	// Try to update existing mapping first (in case unique constraint exists)
 	result := s.db.Model(&ChatMapping{}).
-		Where("chat_id = ? AND chat_type = ? AND is_active = ?", chatID, chatType, true).
+		Where("chat_id = ? AND thread_id = ? AND chat_type = ? AND is_active = ?", chatID, threadID, chatType, true).
 		Updates(map[string]interface{}{
 			"conversation_id": conversationID,
 			"updated_at":      time.Now(),
@@ -246,12 +400,13 @@ func (s *Store) SetChatMapping(chatID int64, chatType, conversationID string) er
 
 	// Deactivate any existing active mappings for this chat
 	s.db.Model(&ChatMapping{}).
-		Where("chat_id = ? AND chat_type = ? AND is_active = ?", chatID, chatType, true).
+		Where("chat_id = ? AND thread_id = ? AND chat_type = ? AND is_active = ?", chatID, threadID, chatType, true).
 		Update("is_active", false)
 
 	// Create new mapping
 	mapping := &ChatMapping{
 		ChatID:         chatID,
+		ThreadID:       threadID,
 		ChatType:       chatType,
 		ConversationID: conversationID,
 		IsActive:       true,
@@ -261,8 +416,14 @@ func (s *Store) SetChatMapping(chatID int64, chatType, conversationID string) er
 
 // GetChatConversationHistory gets all conversation mappings for a chat
 func (s *Store) GetChatConversationHistory(chatID int64, chatType string, limit int) ([]ChatMapping, error) {
+	return s.GetThreadConversationHistory(chatID, 0, chatType, limit)
+}
+
+// GetThreadConversationHistory gets all conversation mappings for a forum
+// topic or thread within a chat
+func (s *Store) GetThreadConversationHistory(chatID, threadID int64, chatType string, limit int) ([]ChatMapping, error) {
 	var mappings []ChatMapping
-	err := s.db.Where("chat_id = ? AND chat_type = ?", chatID, chatType).
+	err := s.db.Where("chat_id = ? AND thread_id = ? AND chat_type = ?", chatID, threadID, chatType).
 		Order("updated_at DESC").
 		Limit(limit).
 		Find(&mappings).Error
@@ -271,11 +432,181 @@ func (s *Store) GetChatConversationHistory(chatID int64, chatType string, limit
 
 // DeactivateChatMapping marks a chat mapping as inactive
 func (s *Store) DeactivateChatMapping(chatID int64, chatType string) error {
+	return s.DeactivateThreadMapping(chatID, 0, chatType)
+}
+
+// DeactivateThreadMapping marks a forum topic or thread mapping as inactive
+func (s *Store) DeactivateThreadMapping(chatID, threadID int64, chatType string) error {
 	return s.db.Model(&ChatMapping{}).
-		Where("chat_id = ? AND chat_type = ? AND is_active = ?", chatID, chatType, true).
+		Where("chat_id = ? AND thread_id = ? AND chat_type = ? AND is_active = ?", chatID, threadID, chatType, true).
 		Update("is_active", false).Error
 }
 
+// ==================== Pin Methods ====================
+
+// CreatePin pins a context item to a conversation
+func (s *Store) CreatePin(pin *Pin) error {
+	return s.db.Create(pin).Error
+}
+
+// ListPins returns the pins for a conversation, oldest first
+func (s *Store) ListPins(conversationID string) ([]Pin, error) {
+	var pins []Pin
+	err := s.db.Where("conversation_id = ?", conversationID).
+		Order("created_at ASC").
+		Find(&pins).Error
+	return pins, err
+}
+
+// DeletePin removes a pin from a conversation
+func (s *Store) DeletePin(conversationID, pinID string) error {
+	result := s.db.Where("conversation_id = ? AND id = ?", conversationID, pinID).Delete(&Pin{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ClearPins removes all pins from a conversation
+func (s *Store) ClearPins(conversationID string) error {
+	return s.db.Where("conversation_id = ?", conversationID).Delete(&Pin{}).Error
+}
+
+// ==================== Plan Methods ====================
+
+// CreatePlan saves a plan together with its steps
+func (s *Store) CreatePlan(plan *Plan) error {
+	for i := range plan.Steps {
+		plan.Steps[i].Position = i + 1
+	}
+	return s.db.Create(plan).Error
+}
+
+// GetPlan returns a plan with its steps in order
+func (s *Store) GetPlan(id string) (*Plan, error) {
+	var plan Plan
+	err := s.db.Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).First(&plan, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// ListPlans returns plans, most recently updated first. An empty status
+// returns plans in any status.
+func (s *Store) ListPlans(status string, limit int) ([]Plan, error) {
+	var plans []Plan
+	query := s.db.Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Order("updated_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&plans).Error
+	return plans, err
+}
+
+// UpdatePlan saves a plan's own fields; steps are saved with UpdatePlanStep
+func (s *Store) UpdatePlan(plan *Plan) error {
+	return s.db.Omit("Steps").Save(plan).Error
+}
+
+// UpdatePlanStep saves a step and touches its plan's update time
+func (s *Store) UpdatePlanStep(step *PlanStep) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(step).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Plan{}).Where("id = ?", step.PlanID).Update("updated_at", time.Now()).Error
+	})
+}
+
+// DeletePlan removes a plan and its steps
+func (s *Store) DeletePlan(id string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("plan_id = ?", id).Delete(&PlanStep{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&Plan{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// ==================== Widget Token Methods ====================
+
+// CreateWidgetToken issues a new widget token showing userID's data and
+// returns it in plain text. The plain token cannot be recovered later.
+func (s *Store) CreateWidgetToken(name, userID string) (*WidgetToken, string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := "wgt_" + hex.EncodeToString(secret)
+
+	wt := &WidgetToken{
+		Name:      name,
+		UserID:    userID,
+		TokenHash: hashWidgetToken(token),
+	}
+	if err := s.db.Create(wt).Error; err != nil {
+		return nil, "", err
+	}
+	return wt, token, nil
+}
+
+// ValidateWidgetToken looks up a widget token and records its use
+func (s *Store) ValidateWidgetToken(token string) (*WidgetToken, error) {
+	var wt WidgetToken
+	if err := s.db.Where("token_hash = ?", hashWidgetToken(token)).First(&wt).Error; err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	wt.LastUsedAt = &now
+	if err := s.db.Model(&wt).Update("last_used_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to record widget token use: %w", err)
+	}
+	return &wt, nil
+}
+
+// ListWidgetTokens returns the widget tokens owned by one of userIDs,
+// newest first
+func (s *Store) ListWidgetTokens(userIDs []string) ([]WidgetToken, error) {
+	var tokens []WidgetToken
+	err := s.db.Where("user_id IN ?", userIDs).Order("created_at DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokeWidgetToken deletes a widget token owned by one of userIDs
+func (s *Store) RevokeWidgetToken(id string, userIDs []string) error {
+	result := s.db.Where("id = ? AND user_id IN ?", id, userIDs).Delete(&WidgetToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func hashWidgetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // ==================== File/Document Methods ====================
 
 // CreateFile creates a new file entry
@@ -313,7 +644,9 @@ func (s *Store) ListAllFiles(limit, offset int) ([]File, error) {
 
 // UpdateFileProcessedText updates the processed text for a file
 func (s *Store) UpdateFileProcessedText(fileID string, processedText string) error {
-	return s.db.Model(&File{}).Where("id = ?", fileID).Update("processed_text", processedText).Error
+	// Updating from the struct, not a column value, lets it be encrypted
+	return s.db.Model(&File{}).Where("id = ?", fileID).Select("processed_text").
+		Updates(&File{ProcessedText: processedText}).Error
 }
 
 // ==================== Task Methods ====================
@@ -338,6 +671,78 @@ func (s *Store) UpdateTask(task *Task) error {
 	return s.db.Save(task).Error
 }
 
+// ==================== Async Job Methods ====================
+
+// EnqueueAsyncJob saves a job as pending
+func (s *Store) EnqueueAsyncJob(job *AsyncJob) error {
+	job.Status = AsyncJobPending
+	return s.db.Create(job).Error
+}
+
+// GetAsyncJob returns a job by ID
+func (s *Store) GetAsyncJob(id string) (*AsyncJob, error) {
+	var job AsyncJob
+	if err := s.db.First(&job, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListAsyncJobs returns a user's jobs, newest first. An empty userID lists
+// everyone's.
+func (s *Store) ListAsyncJobs(userID string, limit int) ([]AsyncJob, error) {
+	var jobs []AsyncJob
+	query := s.db.Order("created_at DESC")
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&jobs).Error
+	return jobs, err
+}
+
+// ClaimAsyncJob marks the oldest pending job running and returns it, or nil
+// when none is waiting. Two workers never claim the same job.
+func (s *Store) ClaimAsyncJob() (*AsyncJob, error) {
+	for {
+		var job AsyncJob
+		err := s.db.Where("status = ?", AsyncJobPending).Order("created_at ASC").First(&job).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		result := s.db.Model(&AsyncJob{}).Where("id = ? AND status = ?", job.ID, AsyncJobPending).
+			Updates(map[string]interface{}{"status": AsyncJobRunning, "started_at": now, "attempts": job.Attempts + 1})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			job.Status, job.StartedAt = AsyncJobRunning, &now
+			job.Attempts++
+			return &job, nil
+		}
+		// Another worker got it first
+	}
+}
+
+// UpdateAsyncJob saves a job
+func (s *Store) UpdateAsyncJob(job *AsyncJob) error {
+	return s.db.Save(job).Error
+}
+
+// RequeueAsyncJobs puts jobs left running by a stopped process back in the
+// queue, returning how many there were
+func (s *Store) RequeueAsyncJobs() (int64, error) {
+	result := s.db.Model(&AsyncJob{}).Where("status = ?", AsyncJobRunning).Update("status", AsyncJobPending)
+	return result.RowsAffected, result.Error
+}
+
 // ==================== Session Methods (BadgerDB) ====================
 
 // SetSession stores session data in BadgerDB
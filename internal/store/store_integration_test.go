@@ -305,6 +305,142 @@ func TestStore_ChatMapping(t *testing.T) {
 	})
 }
 
+func TestStore_ThreadMapping(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+
+	chatID := int64(-100123)
+	if err := st.SetChatMapping(chatID, "telegram", "conv_general"); err != nil {
+		t.Fatalf("Failed to set chat mapping: %v", err)
+	}
+	if err := st.SetThreadMapping(chatID, 7, "telegram", "conv_topic"); err != nil {
+		t.Fatalf("Failed to set thread mapping: %v", err)
+	}
+
+	mapping, err := st.GetChatMapping(chatID, "telegram")
+	if err != nil || mapping.ConversationID != "conv_general" {
+		t.Errorf("Expected the chat's own conversation, got %+v, %v", mapping, err)
+	}
+	mapping, err = st.GetThreadMapping(chatID, 7, "telegram")
+	if err != nil || mapping.ConversationID != "conv_topic" || mapping.ThreadID != 7 {
+		t.Errorf("Expected the topic's conversation, got %+v, %v", mapping, err)
+	}
+
+	if err := st.DeactivateThreadMapping(chatID, 7, "telegram"); err != nil {
+		t.Fatalf("Failed to deactivate thread mapping: %v", err)
+	}
+	if _, err := st.GetThreadMapping(chatID, 7, "telegram"); err == nil {
+		t.Error("Expected the topic's mapping to be inactive")
+	}
+	if _, err := st.GetChatMapping(chatID, "telegram"); err != nil {
+		t.Errorf("Expected the chat's mapping to stay active: %v", err)
+	}
+}
+
+func TestStore_WidgetTokens(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+
+	wt, token, err := st.CreateWidgetToken("iphone", "profile_1")
+	if err != nil {
+		t.Fatalf("Failed to create widget token: %v", err)
+	}
+	if token == "" || wt.TokenHash == token {
+		t.Fatal("Widget token should be returned in plain text and stored hashed")
+	}
+
+	found, err := st.ValidateWidgetToken(token)
+	if err != nil {
+		t.Fatalf("Failed to validate widget token: %v", err)
+	}
+	if found.ID != wt.ID || found.UserID != "profile_1" || found.LastUsedAt == nil {
+		t.Errorf("Expected token %s with last use recorded, got %+v", wt.ID, found)
+	}
+
+	if _, err := st.ValidateWidgetToken("wgt_bogus"); err == nil {
+		t.Error("Expected error for unknown widget token")
+	}
+
+	if tokens, err := st.ListWidgetTokens([]string{"profile_2"}); err != nil || len(tokens) != 0 {
+		t.Errorf("Expected no tokens for another user, got %+v, %v", tokens, err)
+	}
+	if err := st.RevokeWidgetToken(wt.ID, []string{"profile_2"}); err == nil {
+		t.Error("Expected another user not to revoke the token")
+	}
+	if tokens, err := st.ListWidgetTokens([]string{"profile_1"}); err != nil || len(tokens) != 1 {
+		t.Errorf("Expected the owner's token, got %+v, %v", tokens, err)
+	}
+
+	if err := st.RevokeWidgetToken(wt.ID, []string{"profile_1"}); err != nil {
+		t.Fatalf("Failed to revoke widget token: %v", err)
+	}
+	if _, err := st.ValidateWidgetToken(token); err == nil {
+		t.Error("Expected revoked widget token to be rejected")
+	}
+}
+
+func TestStore_PlanOperations(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+
+	plan := &store.Plan{
+		Goal: "Publish the blog post",
+		Steps: []store.PlanStep{
+			{Description: "Draft the post"},
+			{Description: "Proofread"},
+			{Description: "Publish"},
+		},
+	}
+	if err := st.CreatePlan(plan); err != nil {
+		t.Fatalf("Failed to create plan: %v", err)
+	}
+	if plan.Status != store.PlanStatusActive {
+		t.Errorf("Expected new plan to be active, got %s", plan.Status)
+	}
+
+	retrieved, err := st.GetPlan(plan.ID)
+	if err != nil {
+		t.Fatalf("Failed to get plan: %v", err)
+	}
+	if len(retrieved.Steps) != 3 || retrieved.Steps[2].Position != 3 || retrieved.Steps[2].Description != "Publish" {
+		t.Fatalf("Expected 3 ordered steps, got %+v", retrieved.Steps)
+	}
+
+	step := &retrieved.Steps[0]
+	step.Status = store.StepStatusCompleted
+	step.Artifacts = []string{"drafts/post.md"}
+	if err := st.UpdatePlanStep(step); err != nil {
+		t.Fatalf("Failed to update step: %v", err)
+	}
+
+	retrieved, _ = st.GetPlan(plan.ID)
+	if next := retrieved.NextStep(); next == nil || next.Position != 2 {
+		t.Errorf("Expected step 2 to be next, got %+v", next)
+	}
+	if done, total := retrieved.Progress(); done != 1 || total != 3 {
+		t.Errorf("Expected progress 1/3, got %d/%d", done, total)
+	}
+	if len(retrieved.Steps[0].Artifacts) != 1 || retrieved.Steps[0].Artifacts[0] != "drafts/post.md" {
+		t.Errorf("Expected artifacts to round-trip, got %v", retrieved.Steps[0].Artifacts)
+	}
+
+	retrieved.Status = store.PlanStatusPaused
+	if err := st.UpdatePlan(retrieved); err != nil {
+		t.Fatalf("Failed to update plan: %v", err)
+	}
+	paused, err := st.ListPlans(store.PlanStatusPaused, 10)
+	if err != nil || len(paused) != 1 || len(paused[0].Steps) != 3 {
+		t.Errorf("Expected one paused plan with steps, got %+v (%v)", paused, err)
+	}
+
+	if err := st.DeletePlan(plan.ID); err != nil {
+		t.Fatalf("Failed to delete plan: %v", err)
+	}
+	if _, err := st.GetPlan(plan.ID); err == nil {
+		t.Error("Expected deleted plan to be gone")
+	}
+}
+
 func TestStore_FileOperations(t *testing.T) {
 	st := testutil.NewTestStore(t)
 	defer st.Close()
@@ -732,3 +868,65 @@ func TestStore_ConcurrentAccess(t *testing.T) {
 		}
 	})
 }
+
+func TestStore_FindConversation(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+
+	for _, id := range []string{"conv_abc1", "conv_abc2", "conv_xyz", "conv%1"} {
+		if err := st.CreateConversation(&store.Conversation{ID: id, Title: id}); err != nil {
+			t.Fatalf("Failed to create conversation: %v", err)
+		}
+	}
+
+	conv, err := st.FindConversation("conv_x")
+	if err != nil {
+		t.Fatalf("Failed to find conversation by prefix: %v", err)
+	}
+	if conv.ID != "conv_xyz" {
+		t.Errorf("Expected conv_xyz, got %s", conv.ID)
+	}
+
+	if conv, err := st.FindConversation("conv_abc1"); err != nil || conv.ID != "conv_abc1" {
+		t.Errorf("Expected exact match conv_abc1, got %v, %v", conv, err)
+	}
+	if _, err := st.FindConversation("conv_abc"); err == nil {
+		t.Error("Expected an error for an ambiguous prefix")
+	}
+	if _, err := st.FindConversation("nope"); err == nil {
+		t.Error("Expected an error for an unknown conversation")
+	}
+	if conv, err := st.FindConversation("conv%"); err != nil || conv.ID != "conv%1" {
+		t.Errorf("Expected %% to match literally, got %v, %v", conv, err)
+	}
+}
+
+func TestStore_ListActiveConversations(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+
+	old := &store.Conversation{ID: "conv_old"}
+	deleted := &store.Conversation{ID: "conv_deleted"}
+	recent := &store.Conversation{ID: "conv_recent"}
+	for _, conv := range []*store.Conversation{old, deleted, recent} {
+		if err := st.CreateConversation(conv); err != nil {
+			t.Fatalf("Failed to create conversation: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := st.DeleteConversation(deleted.ID); err != nil {
+		t.Fatalf("Failed to delete conversation: %v", err)
+	}
+
+	convs, err := st.ListActiveConversations(10, 0)
+	if err != nil {
+		t.Fatalf("Failed to list conversations: %v", err)
+	}
+	var ids []string
+	for _, conv := range convs {
+		ids = append(ids, conv.ID)
+	}
+	if len(ids) != 2 || ids[0] != "conv_recent" || ids[1] != "conv_old" {
+		t.Errorf("Expected [conv_recent conv_old], got %v", ids)
+	}
+}
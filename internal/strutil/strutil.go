@@ -0,0 +1,19 @@
+// Package strutil provides string helpers shared across packages.
+package strutil
+
+// Truncate shortens s to at most max characters, ending it with "..." when
+// anything was cut. It counts and cuts runes, so multi-byte text stays valid
+// UTF-8.
+func Truncate(s string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	if max <= 3 {
+		return string(runes[:max])
+	}
+	return string(runes[:max-3]) + "..."
+}
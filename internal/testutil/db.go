@@ -41,6 +41,8 @@ func NewTestDB(t *testing.T) *TestDB {
 		&store.Task{},
 		&store.ChatMapping{},
 		&store.Config{},
+		&store.Pin{},
+		&store.WidgetToken{},
 		// Add other models as needed
 	); err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
@@ -0,0 +1,140 @@
+// Package triggers runs prompts in reaction to events, such as an MQTT
+// message or a GitHub webhook, and sends the answers to the user. Each
+// event source maps its events to a Run; Runner does the rest.
+package triggers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/journal"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+	"go.uber.org/zap"
+)
+
+// Timeout bounds how long a triggered prompt may run
+const Timeout = 5 * time.Minute
+
+// Run is one triggered prompt
+type Run struct {
+	// Key groups runs of one trigger: a run is skipped while another with
+	// its key is running or within Cooldown of the last one starting
+	Key          string
+	Cooldown     time.Duration
+	UserID       string
+	Prompt       string
+	SystemPrompt string
+	Event        string // The event's type, for logs
+	Summary      string // Recorded in the activity journal
+	Category     string // Notification category of the answer
+	Title        string // Notification title of the answer
+}
+
+// Runner runs triggered prompts in the background
+type Runner struct {
+	source   string // e.g. "MQTT", for logs
+	agent    agent.Chatter
+	notifier notify.Sender
+	journal  *journal.Journal
+	logger   *zap.Logger
+
+	mu      sync.Mutex
+	running map[string]bool
+	lastRun map[string]time.Time
+	wg      sync.WaitGroup
+}
+
+// NewRunner creates a runner for the triggers of source
+func NewRunner(source string, a agent.Chatter, logger *zap.Logger) *Runner {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Runner{
+		source:  source,
+		agent:   a,
+		logger:  logger,
+		running: make(map[string]bool),
+		lastRun: make(map[string]time.Time),
+	}
+}
+
+// SetNotifier sets where answers are sent
+func (r *Runner) SetNotifier(n notify.Sender) {
+	r.notifier = n
+}
+
+// SetJournal records each triggered run in j
+func (r *Runner) SetJournal(j *journal.Journal) {
+	r.journal = j
+}
+
+// Start runs run in the background, so the event source isn't held up. It
+// reports false when the run was skipped, see Run.Key.
+func (r *Runner) Start(run Run) bool {
+	if !r.claim(run.Key, run.Cooldown) {
+		return false
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer r.release(run.Key)
+		r.run(run)
+	}()
+	return true
+}
+
+// Wait blocks until running triggers finish
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Runner) claim(key string, cooldown time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running[key] {
+		return false
+	}
+	if cooldown > 0 && time.Since(r.lastRun[key]) < cooldown {
+		return false
+	}
+	r.running[key] = true
+	r.lastRun[key] = time.Now()
+	return true
+}
+
+func (r *Runner) release(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.running, key)
+}
+
+func (r *Runner) run(run Run) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, "user_id", run.UserID)
+
+	resp, err := r.agent.Chat(ctx, agent.ChatRequest{
+		Message:      run.Prompt,
+		SystemPrompt: run.SystemPrompt,
+	})
+	if err != nil {
+		r.logger.Error(r.source+" trigger failed", zap.String("event", run.Event), zap.Error(err))
+		r.journal.Record(run.UserID, journal.KindTrigger, run.Summary, run.Prompt, err)
+		return
+	}
+	r.journal.Record(run.UserID, journal.KindTrigger, run.Summary, resp.Content, nil)
+
+	if r.notifier == nil || resp.Content == "" {
+		return
+	}
+	if _, err := r.notifier.Send(ctx, notify.Notification{
+		UserID:   run.UserID,
+		Category: run.Category,
+		Title:    run.Title,
+		Body:     resp.Content,
+	}); err != nil {
+		r.logger.Warn("Failed to send "+r.source+" trigger result", zap.String("event", run.Event), zap.Error(err))
+	}
+}
@@ -0,0 +1,93 @@
+package triggers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/notify"
+)
+
+type fakeAgent struct {
+	mu      sync.Mutex
+	prompts []string
+	release chan struct{}
+	err     error
+}
+
+func (a *fakeAgent) Chat(ctx context.Context, req agent.ChatRequest) (*agent.ChatResponse, error) {
+	a.mu.Lock()
+	a.prompts = append(a.prompts, req.Message)
+	a.mu.Unlock()
+	if a.release != nil {
+		<-a.release
+	}
+	if a.err != nil {
+		return nil, a.err
+	}
+	return &agent.ChatResponse{Content: "answer to " + req.Message}, nil
+}
+
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []notify.Notification
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, notification notify.Notification) (string, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, notification)
+	return notify.StatusSent, nil
+}
+
+func TestRunner_SkipsRunningAndCoolingDownTriggers(t *testing.T) {
+	a := &fakeAgent{release: make(chan struct{})}
+	notifier := &recordingNotifier{}
+	r := NewRunner("Test", a, nil)
+	r.SetNotifier(notifier)
+
+	run := Run{Key: "door", Cooldown: time.Hour, UserID: "u1", Prompt: "door opened", Category: "devices", Title: "door"}
+	if !r.Start(run) {
+		t.Fatal("Expected the first run to start")
+	}
+	if r.Start(run) {
+		t.Error("Expected a run to be skipped while its trigger is running")
+	}
+	if !r.Start(Run{Key: "window", Prompt: "window opened"}) {
+		t.Error("Expected another trigger to start")
+	}
+	close(a.release)
+	r.Wait()
+
+	if r.Start(run) {
+		t.Error("Expected a run to be skipped while its trigger cools down")
+	}
+	if len(a.prompts) != 2 {
+		t.Errorf("Expected 2 prompts, got %v", a.prompts)
+	}
+
+	var sent *notify.Notification
+	for i := range notifier.sent {
+		if notifier.sent[i].Title == "door" {
+			sent = &notifier.sent[i]
+		}
+	}
+	if sent == nil || sent.UserID != "u1" || sent.Category != "devices" || sent.Body != "answer to door opened" {
+		t.Errorf("Expected the answer to be sent to u1, got %+v", notifier.sent)
+	}
+}
+
+func TestRunner_FailureIsNotSent(t *testing.T) {
+	notifier := &recordingNotifier{}
+	r := NewRunner("Test", &fakeAgent{err: errors.New("provider down")}, nil)
+	r.SetNotifier(notifier)
+
+	r.Start(Run{Key: "ci", Prompt: "summarize"})
+	r.Wait()
+	if len(notifier.sent) != 0 {
+		t.Errorf("Expected nothing sent after a failure, got %+v", notifier.sent)
+	}
+}
@@ -0,0 +1,45 @@
+package tui
+
+import "strings"
+
+// command is a slash command offered by the palette
+type command struct {
+	Name        string
+	Args        string
+	Description string
+}
+
+// commands are the slash commands the TUI understands
+var commands = []command{
+	{Name: "/help", Description: "Show help"},
+	{Name: "/new", Description: "Start a new conversation"},
+	{Name: "/conversations", Description: "Show or hide past conversations"},
+	{Name: "/resume", Args: "<number>", Description: "Continue a conversation from the sidebar"},
+	{Name: "/title", Args: "<title>", Description: "Rename this conversation"},
+	{Name: "/model", Args: "fast|smart|auto", Description: "Pick the model for this conversation"},
+	{Name: "/skills", Description: "List available skills"},
+	{Name: "/clear", Description: "Clear the screen"},
+	{Name: "/quit", Description: "Exit"},
+}
+
+// maxPaletteItems is the most commands the palette shows at once
+const maxPaletteItems = 6
+
+// matchCommands returns the commands starting with what's been typed. The
+// palette closes once arguments follow the command.
+func matchCommands(input string) []command {
+	if !strings.HasPrefix(input, "/") || strings.Contains(input, " ") {
+		return nil
+	}
+	input = strings.ToLower(input)
+	var matches []command
+	for _, c := range commands {
+		if strings.HasPrefix(c.Name, input) {
+			matches = append(matches, c)
+		}
+	}
+	if len(matches) > maxPaletteItems {
+		matches = matches[:maxPaletteItems]
+	}
+	return matches
+}
@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/store"
+)
+
+const (
+	sidebarWidth = 32
+	sidebarLimit = 30  // conversations listed
+	historyLimit = 500 // messages loaded when a conversation is resumed
+)
+
+// conversationItem is a past conversation listed in the sidebar
+type conversationItem struct {
+	ID        string
+	Title     string
+	UpdatedAt time.Time
+}
+
+// listConversations returns recent conversations, newest first. Untitled
+// ones are named after their first message.
+func listConversations(st *store.Store) ([]conversationItem, error) {
+	if st == nil {
+		return nil, nil
+	}
+	convs, err := st.ListConversations(sidebarLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]conversationItem, 0, len(convs))
+	for _, c := range convs {
+		if c.IsArchived {
+			continue
+		}
+		title := c.Title
+		if title == "" || title == "New Conversation" {
+			if msgs, err := st.GetMessages(c.ID, 1, 0); err == nil && len(msgs) > 0 {
+				title = msgs[0].Content
+			}
+		}
+		items = append(items, conversationItem{ID: c.ID, Title: firstLine(title), UpdatedAt: c.UpdatedAt})
+	}
+	return items, nil
+}
+
+// loadHistory returns a conversation's messages for display, leaving out
+// tool calls and their results
+func loadHistory(st *store.Store, conversationID string) ([]Message, error) {
+	msgs, err := st.GetMessages(conversationID, historyLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+	var history []Message
+	for _, msg := range msgs {
+		if (msg.Role != "user" && msg.Role != "assistant") || strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+		history = append(history, Message{Role: msg.Role, Content: msg.Content, Timestamp: msg.CreatedAt})
+	}
+	return history, nil
+}
+
+// renderSidebar draws the conversation list, marking the selected and
+// current conversations
+func renderSidebar(items []conversationItem, selected int, current string, focused bool, height int) string {
+	var sb strings.Builder
+	title := "Conversations"
+	if focused {
+		title += " ↑↓ ⏎"
+	}
+	sb.WriteString(styles.SidebarTitle.Render(title))
+	sb.WriteString("\n")
+
+	if len(items) == 0 {
+		sb.WriteString(styles.HelpStyle.Render("No conversations yet"))
+	}
+
+	width := sidebarWidth - 4
+	for i, item := range items {
+		if i >= height-2 {
+			break
+		}
+		marker := " "
+		if item.ID == current {
+			marker = "•"
+		}
+		line := fmt.Sprintf("%s%2d %s", marker, i+1, truncate(item.Title, width-4))
+		if focused && i == selected {
+			line = styles.SidebarSelected.Render(line)
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	return styles.Sidebar.Height(height).Render(sb.String())
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	if s == "" {
+		return "(empty)"
+	}
+	return s
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
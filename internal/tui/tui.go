@@ -4,6 +4,7 @@ package tui
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,40 +15,79 @@ import (
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/gmsas95/myrai-cli/internal/agent"
+	"github.com/gmsas95/myrai-cli/internal/store"
 )
 
 // Message represents a chat message
 type Message struct {
 	Role      string
 	Content   string
+	Tools     []string // Tools the assistant used for this answer
 	Timestamp time.Time
+	rendered  string // Cached rendering; empty when it needs drawing again
 }
 
+// toolRun is a tool call made during the running turn
+type toolRun struct {
+	name string
+	done bool
+}
+
+// focus is the part of the screen keys go to
+type focus int
+
+const (
+	focusInput focus = iota
+	focusSidebar
+)
+
 // Model represents the TUI state
 type Model struct {
 	agent          *agent.Agent
+	store          *store.Store
 	viewport       viewport.Model
 	textInput      textinput.Model
 	spinner        spinner.Model
+	renderer       *glamour.TermRenderer
+	glamourStyle   string
+	wrapWidth      int
 	messages       []Message
-	isLoading      bool
-	executingTool  string // Name of tool currently being executed
 	width          int
 	height         int
-	renderer       *glamour.TermRenderer
-	showHelp       bool
 	conversationID string
+
+	// The running turn
+	isLoading bool
+	turn      int
+	events    <-chan tea.Msg
+	cancel    context.CancelFunc
+	tools     []toolRun
+	status    string // Timing of the last answer
+
+	paletteIndex int // Selected command in the slash command palette
+
+	// Conversation sidebar
+	showSidebar   bool
+	focus         focus
+	conversations []conversationItem
+	selected      int
 }
 
 // Styles
 type Styles struct {
-	Header       lipgloss.Style
-	UserStyle    lipgloss.Style
-	AIStyle      lipgloss.Style
-	SystemStyle  lipgloss.Style
-	HelpStyle    lipgloss.Style
-	InputStyle   lipgloss.Style
-	SpinnerStyle lipgloss.Style
+	Header          lipgloss.Style
+	UserStyle       lipgloss.Style
+	AIStyle         lipgloss.Style
+	SystemStyle     lipgloss.Style
+	HelpStyle       lipgloss.Style
+	InputStyle      lipgloss.Style
+	SpinnerStyle    lipgloss.Style
+	ToolStyle       lipgloss.Style
+	Sidebar         lipgloss.Style
+	SidebarTitle    lipgloss.Style
+	SidebarSelected lipgloss.Style
+	Palette         lipgloss.Style
+	PaletteSelected lipgloss.Style
 }
 
 func NewStyles() Styles {
@@ -82,27 +122,55 @@ func NewStyles() Styles {
 
 		SpinnerStyle: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#00BFFF")),
+
+		ToolStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#DAA520")),
+
+		Sidebar: lipgloss.NewStyle().
+			Width(sidebarWidth).
+			Border(lipgloss.NormalBorder(), false, true, false, false).
+			BorderForeground(lipgloss.Color("#444444")).
+			PaddingRight(1),
+
+		SidebarTitle: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#00BFFF")),
+
+		SidebarSelected: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#1a1a2e")).
+			Background(lipgloss.Color("#00BFFF")),
+
+		Palette: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#888888")).
+			PaddingLeft(2),
+
+		PaletteSelected: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#00BFFF")).
+			Bold(true).
+			PaddingLeft(2),
 	}
 }
 
 var styles = NewStyles()
 
-// NewModel creates a new TUI model
-func NewModel(agentInstance *agent.Agent) (*Model, error) {
-	// Initialize glamour renderer
-	renderer, err := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
-		glamour.WithWordWrap(80),
-	)
+// NewModel creates a new TUI model. Past conversations are listed from st,
+// which may be nil.
+func NewModel(agentInstance *agent.Agent, st *store.Store) (*Model, error) {
+	// The terminal's background is checked once, before Bubble Tea owns it
+	glamourStyle := "light"
+	if lipgloss.HasDarkBackground() {
+		glamourStyle = "dark"
+	}
+	renderer, err := newRenderer(glamourStyle, 80)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create renderer: %w", err)
 	}
 
 	// Initialize text input
 	ti := textinput.New()
-	ti.Placeholder = "Type your message..."
+	ti.Placeholder = "Type your message, or / for commands..."
 	ti.Focus()
-	ti.CharLimit = 1000
+	ti.CharLimit = 4000
 	ti.Width = 80
 
 	// Initialize spinner
@@ -115,117 +183,317 @@ func NewModel(agentInstance *agent.Agent) (*Model, error) {
 	vp.SetContent("")
 
 	return &Model{
-		agent:     agentInstance,
-		viewport:  vp,
-		textInput: ti,
-		spinner:   sp,
-		messages:  []Message{},
-		renderer:  renderer,
-		showHelp:  true,
+		agent:        agentInstance,
+		store:        st,
+		viewport:     vp,
+		textInput:    ti,
+		spinner:      sp,
+		renderer:     renderer,
+		glamourStyle: glamourStyle,
+		wrapWidth:    80,
+		messages: []Message{{
+			Role:      "system",
+			Content:   "Type a message to chat, / for commands, Ctrl+B for past conversations.",
+			Timestamp: time.Now(),
+		}},
 	}, nil
 }
 
+// newRenderer renders Markdown with code blocks highlighted for the
+// terminal's background
+func newRenderer(style string, width int) (*glamour.TermRenderer, error) {
+	return glamour.NewTermRenderer(
+		glamour.WithStandardStyle(style),
+		glamour.WithWordWrap(width),
+	)
+}
+
 // Init initializes the TUI
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
-		textinput.Blink,
-		m.spinner.Tick,
-	)
+	return textinput.Blink
 }
 
 // Update handles messages and updates the model
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
-
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.viewport.Width = msg.Width - 4
-		m.viewport.Height = msg.Height - 8
-		m.textInput.Width = msg.Width - 10
+		m.resize()
 		return m, nil
 
+	case tea.MouseMsg:
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+
+	case spinner.TickMsg:
+		// The spinner only turns while a reply is on its way
+		if !m.isLoading {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case turnMsg:
+		if msg.turn != m.turn || !m.isLoading {
+			return m, nil // from a turn that was stopped
+		}
+		return m.handleTurnEvent(msg.msg)
+
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyCtrlC, tea.KeyEsc:
-			return m, tea.Quit
-
-		case tea.KeyEnter:
-			input := m.textInput.Value()
-			if input == "" {
-				return m, nil
-			}
+		return m.handleKey(msg)
+	}
 
-			// Handle slash commands
-			if strings.HasPrefix(input, "/") {
-				return m.handleSlashCommand(input)
-			}
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
 
-			// Add user message
-			m.messages = append(m.messages, Message{
-				Role:      "user",
-				Content:   input,
-				Timestamp: time.Now(),
-			})
+// handleKey routes a key press to the palette, sidebar or input
+func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		if m.cancel != nil {
+			m.cancel()
+		}
+		return m, tea.Quit
+
+	case "esc":
+		if m.isLoading {
+			m.stopTurn()
+			return m, nil
+		}
+		if m.focus == focusSidebar {
+			m.focusInput()
+			return m, nil
+		}
+		return m, tea.Quit
 
-			// Clear input
-			m.textInput.SetValue("")
+	case "ctrl+b":
+		m.toggleSidebar()
+		return m, nil
 
-			// Start loading
-			m.isLoading = true
+	case "pgup":
+		m.viewport.HalfViewUp()
+		return m, nil
 
-			// Update viewport
-			m.updateViewport()
+	case "pgdown":
+		m.viewport.HalfViewDown()
+		return m, nil
+	}
 
-			// Send to agent
-			return m, m.sendToAgent(input)
+	if m.focus == focusSidebar {
+		return m.handleSidebarKey(msg)
+	}
 
-		case tea.KeyPgUp:
-			m.viewport.LineUp(5)
+	if matches := matchCommands(m.textInput.Value()); len(matches) > 0 {
+		selected := min(m.paletteIndex, len(matches)-1)
+		switch msg.String() {
+		case "up":
+			m.paletteIndex = (selected + len(matches) - 1) % len(matches)
 			return m, nil
-
-		case tea.KeyPgDown:
-			m.viewport.LineDown(5)
+		case "down":
+			m.paletteIndex = (selected + 1) % len(matches)
+			return m, nil
+		case "tab":
+			m.completeCommand(matches[selected])
+			return m, nil
+		case "enter":
+			if !isCommand(m.textInput.Value()) {
+				if matches[selected].Args != "" {
+					m.completeCommand(matches[selected])
+					return m, nil
+				}
+				m.textInput.SetValue(matches[selected].Name)
+			}
+		}
+	} else {
+		switch msg.String() {
+		case "tab":
+			if m.showSidebar {
+				m.focus = focusSidebar
+				m.textInput.Blur()
+			}
+			return m, nil
+		case "up":
+			m.viewport.LineUp(1)
+			return m, nil
+		case "down":
+			m.viewport.LineDown(1)
 			return m, nil
 		}
+	}
+
+	if msg.Type == tea.KeyEnter {
+		return m.submit()
+	}
+
+	before := m.textInput.Value()
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	if m.textInput.Value() != before {
+		m.paletteIndex = 0
+		m.layout()
+	}
+	return m, cmd
+}
+
+// handleSidebarKey moves through past conversations and opens one
+func (m Model) handleSidebarKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.selected > 0 {
+			m.selected--
+		}
+	case "down", "j":
+		if m.selected < len(m.conversations)-1 {
+			m.selected++
+		}
+	case "enter":
+		if m.selected < len(m.conversations) && !m.isLoading {
+			m.resume(m.conversations[m.selected].ID)
+			m.focusInput()
+		}
+	case "n":
+		if !m.isLoading {
+			m.newConversation()
+			m.focusInput()
+		}
+	case "tab":
+		m.focusInput()
+	}
+	return m, nil
+}
+
+// submit sends what's been typed, or runs it as a slash command
+func (m Model) submit() (tea.Model, tea.Cmd) {
+	input := strings.TrimSpace(m.textInput.Value())
+	if input == "" || m.isLoading {
+		return m, nil
+	}
+	m.textInput.SetValue("")
+	m.paletteIndex = 0
+	m.layout()
+
+	if strings.HasPrefix(input, "/") {
+		return m.handleSlashCommand(input)
+	}
+
+	if m.agent == nil {
+		m.addMessage("system", "❌ Agent not initialized")
+		return m, nil
+	}
+
+	// The answer streams into the empty assistant message
+	m.messages = append(m.messages,
+		Message{Role: "user", Content: input, Timestamp: time.Now()},
+		Message{Role: "assistant", Timestamp: time.Now()},
+	)
+	m.isLoading = true
+	m.status = ""
+	m.tools = nil
+	m.turn++
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.events = startTurn(ctx, m.agent, m.conversationID, input)
+
+	m.updateViewport()
+	m.viewport.GotoBottom()
+	return m, tea.Batch(waitForEvent(m.turn, m.events), m.spinner.Tick)
+}
+
+// handleTurnEvent shows what the running turn sent
+func (m Model) handleTurnEvent(event tea.Msg) (tea.Model, tea.Cmd) {
+	pending := &m.messages[len(m.messages)-1]
+
+	switch event := event.(type) {
+	case chunkMsg:
+		// Answer text means the tools before it have finished
+		m.finishTools()
+		pending.Content += string(event)
+		pending.rendered = ""
+
+	case toolMsg:
+		m.finishTools()
+		m.tools = append(m.tools, toolRun{name: string(event)})
+		// What the model said before calling the tool isn't part of the answer
+		pending.Content = ""
+		pending.rendered = ""
 
 	case responseMsg:
-		m.isLoading = false
-		m.messages = append(m.messages, Message{
-			Role:      "assistant",
-			Content:   msg.content,
-			Timestamp: time.Now(),
-		})
-		m.conversationID = msg.conversationID
+		pending.Content = event.content
+		if strings.TrimSpace(pending.Content) == "" {
+			pending.Content = "*(no answer)*"
+		}
+		pending.Tools = toolNames(m.tools)
+		pending.rendered = ""
+		m.endTurn()
+		m.status = fmt.Sprintf("⏱ %s · %d tokens", event.elapsed.Round(100*time.Millisecond), event.tokens)
+		if event.conversationID != m.conversationID {
+			m.conversationID = event.conversationID
+			m.refreshConversations()
+		}
 		m.updateViewport()
 		return m, nil
 
 	case errMsg:
-		m.isLoading = false
-		m.messages = append(m.messages, Message{
-			Role:      "system",
-			Content:   fmt.Sprintf("Error: %v", msg.err),
-			Timestamp: time.Now(),
-		})
-		m.updateViewport()
+		m.dropPendingIfEmpty()
+		m.endTurn()
+		m.addMessage("system", fmt.Sprintf("Error: %v", event.err))
 		return m, nil
 	}
 
-	// Update components
-	var cmd tea.Cmd
-	m.textInput, cmd = m.textInput.Update(msg)
-	cmds = append(cmds, cmd)
+	m.updateViewport()
+	return m, waitForEvent(m.turn, m.events)
+}
 
-	if m.isLoading {
-		m.spinner, cmd = m.spinner.Update(msg)
-		cmds = append(cmds, cmd)
+// stopTurn cancels the running turn, keeping what was streamed so far
+func (m *Model) stopTurn() {
+	pending := &m.messages[len(m.messages)-1]
+	if strings.TrimSpace(pending.Content) != "" {
+		pending.Content += "\n\n*(stopped)*"
+		pending.Tools = toolNames(m.tools)
+		pending.rendered = ""
+	} else {
+		m.dropPendingIfEmpty()
+	}
+	m.endTurn()
+	m.addMessage("system", "⏹ Stopped")
+}
+
+func (m *Model) endTurn() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.isLoading = false
+	m.cancel = nil
+	m.events = nil
+	m.tools = nil
+}
+
+// dropPendingIfEmpty removes the assistant message a failed turn left
+// without an answer
+func (m *Model) dropPendingIfEmpty() {
+	last := len(m.messages) - 1
+	if last >= 0 && m.messages[last].Role == "assistant" && strings.TrimSpace(m.messages[last].Content) == "" {
+		m.messages = m.messages[:last]
 	}
+}
 
-	m.viewport, cmd = m.viewport.Update(msg)
-	cmds = append(cmds, cmd)
+func (m *Model) finishTools() {
+	for i := range m.tools {
+		m.tools[i].done = true
+	}
+}
 
-	return m, tea.Batch(cmds...)
+func toolNames(runs []toolRun) []string {
+	var names []string
+	for _, run := range runs {
+		names = append(names, run.name)
+	}
+	return names
 }
 
 // View renders the UI
@@ -237,72 +505,228 @@ func (m Model) View() string {
 	var sb strings.Builder
 
 	// Header
-	header := styles.Header.Render("  🤖 Myrai (未来) - Your Personal AI Assistant  ")
-	sb.WriteString(header)
+	sb.WriteString(styles.Header.Width(m.width).Render("🤖 Myrai (未来) - Your Personal AI Assistant"))
 	sb.WriteString("\n\n")
 
-	// Messages viewport
-	sb.WriteString(m.viewport.View())
+	// Messages, with past conversations beside them
+	body := m.viewport.View()
+	if m.showSidebar {
+		sidebar := renderSidebar(m.conversations, m.selected, m.conversationID, m.focus == focusSidebar, m.viewport.Height)
+		body = lipgloss.JoinHorizontal(lipgloss.Top, sidebar, " ", body)
+	}
+	sb.WriteString(body)
+	sb.WriteString("\n")
+
+	// Progress of the running turn, or how the last one went
+	sb.WriteString(m.statusLine())
 	sb.WriteString("\n")
 
-	// Loading indicator
-	if m.isLoading {
-		if m.executingTool != "" {
-			sb.WriteString(fmt.Sprintf("\n  %s Using tool: %s...\n", m.spinner.View(), m.executingTool))
+	// Slash command palette
+	matches := matchCommands(m.textInput.Value())
+	for i, c := range matches {
+		line := fmt.Sprintf("%-18s %s", strings.TrimSpace(c.Name+" "+c.Args), c.Description)
+		if i == min(m.paletteIndex, len(matches)-1) {
+			sb.WriteString(styles.PaletteSelected.Render("▸ " + line))
 		} else {
-			sb.WriteString(fmt.Sprintf("\n  %s Thinking...\n", m.spinner.View()))
+			sb.WriteString(styles.Palette.Render("  " + line))
 		}
+		sb.WriteString("\n")
 	}
 
 	// Input area
+	sb.WriteString(styles.InputStyle.Width(m.width - 2).Render(m.textInput.View()))
 	sb.WriteString("\n")
-	inputBox := styles.InputStyle.Render(m.textInput.View())
-	sb.WriteString(inputBox)
+	sb.WriteString(styles.HelpStyle.Render("  Enter: Send | /: Commands | Ctrl+B: Conversations | PgUp/PgDn: Scroll | Esc: Stop/Exit"))
 
-	// Help text
-	if m.showHelp {
-		sb.WriteString("\n")
-		helpText := styles.HelpStyle.Render("  Enter: Send | /skills: List skills | Ctrl+C: Exit | PgUp/PgDn: Scroll")
-		sb.WriteString(helpText)
+	return sb.String()
+}
+
+// statusLine shows the tools the running turn has used, or the timing of
+// the last answer
+func (m Model) statusLine() string {
+	if !m.isLoading {
+		return styles.HelpStyle.Render("  " + m.status)
 	}
 
-	return sb.String()
+	var parts []string
+	running := ""
+	for _, run := range m.tools {
+		if run.done {
+			parts = append(parts, styles.ToolStyle.Render("✓ "+run.name))
+		} else {
+			running = run.name
+		}
+	}
+	switch {
+	case running != "":
+		parts = append(parts, fmt.Sprintf("%s Using %s...", m.spinner.View(), styles.ToolStyle.Render(running)))
+	case m.messages[len(m.messages)-1].Content != "":
+		parts = append(parts, m.spinner.View()+" Writing...")
+	default:
+		parts = append(parts, m.spinner.View()+" Thinking...")
+	}
+	return "  " + strings.Join(parts, "  ")
+}
+
+// resize fits the viewport, sidebar and Markdown wrapping to the window
+func (m *Model) resize() {
+	width := m.width - 2
+	if m.showSidebar {
+		width -= sidebarWidth + 2
+	}
+	width = max(width, 20)
+	m.viewport.Width = width
+	m.textInput.Width = m.width - 8
+
+	if wrap := width - 4; wrap != m.wrapWidth {
+		if renderer, err := newRenderer(m.glamourStyle, wrap); err == nil {
+			m.renderer = renderer
+			m.wrapWidth = wrap
+		}
+	}
+	for i := range m.messages {
+		m.messages[i].rendered = ""
+	}
+	m.layout()
+	m.updateViewport()
+}
+
+// layout gives the viewport the height the other parts leave
+func (m *Model) layout() {
+	// Header and gap, status line, input box and help line
+	used := 2 + 1 + 3 + 1 + len(matchCommands(m.textInput.Value()))
+	m.viewport.Height = max(m.height-used, 3)
 }
 
-// updateViewport updates the viewport content
+// updateViewport updates the viewport content, following the conversation
+// unless the user has scrolled up
 func (m *Model) updateViewport() {
-	var sb strings.Builder
+	atBottom := m.viewport.AtBottom()
 
-	for _, msg := range m.messages {
-		switch msg.Role {
-		case "user":
-			sb.WriteString(styles.UserStyle.Render("👤 You:"))
-			sb.WriteString(" ")
-			sb.WriteString(msg.Content)
-			sb.WriteString("\n\n")
-
-		case "assistant":
-			sb.WriteString(styles.AIStyle.Render("🤖 Myrai:"))
-			sb.WriteString(" ")
-			// Render markdown for AI responses
-			rendered, err := m.renderer.Render(msg.Content)
-			if err != nil {
-				sb.WriteString(msg.Content)
-			} else {
-				sb.WriteString(rendered)
-			}
-			sb.WriteString("\n\n")
+	parts := make([]string, 0, len(m.messages))
+	for i := range m.messages {
+		if m.messages[i].rendered == "" {
+			m.messages[i].rendered = m.renderMessage(m.messages[i])
+		}
+		parts = append(parts, m.messages[i].rendered)
+	}
+
+	m.viewport.SetContent(strings.Join(parts, "\n\n"))
+	if atBottom {
+		m.viewport.GotoBottom()
+	}
+}
 
-		case "system":
-			sb.WriteString(styles.SystemStyle.Render(msg.Content))
-			sb.WriteString("\n\n")
+// renderMessage draws one message; assistant answers are rendered as
+// Markdown, with code blocks highlighted
+func (m *Model) renderMessage(msg Message) string {
+	wrap := lipgloss.NewStyle().Width(m.viewport.Width)
+
+	switch msg.Role {
+	case "user":
+		return styles.UserStyle.Render("👤 You:") + "\n" + wrap.Render(msg.Content)
+
+	case "assistant":
+		header := styles.AIStyle.Render("🤖 Myrai:")
+		if len(msg.Tools) > 0 {
+			header += "  " + styles.ToolStyle.Render("🔧 "+strings.Join(msg.Tools, " · "))
+		}
+		if msg.Content == "" {
+			return header
 		}
+		rendered, err := m.renderer.Render(closeFences(msg.Content))
+		if err != nil {
+			return header + "\n" + wrap.Render(msg.Content)
+		}
+		return header + "\n" + strings.Trim(rendered, "\n")
+
+	default:
+		return styles.SystemStyle.Render(wrap.Render(msg.Content))
 	}
+}
+
+// closeFences closes a code block still being streamed, so it renders as
+// code rather than as the rest of the answer
+func closeFences(content string) string {
+	if strings.Count(content, "```")%2 == 1 {
+		return content + "\n```"
+	}
+	return content
+}
 
-	m.viewport.SetContent(sb.String())
+func (m *Model) addMessage(role, content string) {
+	m.messages = append(m.messages, Message{Role: role, Content: content, Timestamp: time.Now()})
+	m.updateViewport()
 	m.viewport.GotoBottom()
 }
 
+func (m *Model) focusInput() {
+	m.focus = focusInput
+	m.textInput.Focus()
+}
+
+// toggleSidebar shows or hides past conversations
+func (m *Model) toggleSidebar() {
+	m.showSidebar = !m.showSidebar
+	if m.showSidebar {
+		m.refreshConversations()
+	} else {
+		m.focusInput()
+	}
+	m.resize()
+}
+
+func (m *Model) refreshConversations() {
+	items, err := listConversations(m.store)
+	if err != nil {
+		m.addMessage("system", fmt.Sprintf("Failed to list conversations: %v", err))
+		return
+	}
+	m.conversations = items
+	m.selected = min(m.selected, max(len(items)-1, 0))
+}
+
+// resume continues a past conversation, showing its messages
+func (m *Model) resume(conversationID string) {
+	history, err := loadHistory(m.store, conversationID)
+	if err != nil {
+		m.addMessage("system", fmt.Sprintf("Failed to load conversation: %v", err))
+		return
+	}
+	m.messages = history
+	m.conversationID = conversationID
+	m.status = ""
+	m.addMessage("system", "↩ Resumed conversation")
+}
+
+func (m *Model) newConversation() {
+	m.conversationID = ""
+	m.messages = nil
+	m.status = ""
+	m.addMessage("system", "🆕 New conversation started!")
+}
+
+// completeCommand fills in the selected palette command
+func (m *Model) completeCommand(c command) {
+	value := c.Name
+	if c.Args != "" {
+		value += " "
+	}
+	m.textInput.SetValue(value)
+	m.textInput.CursorEnd()
+	m.paletteIndex = 0
+	m.layout()
+}
+
+func isCommand(input string) bool {
+	for _, c := range commands {
+		if strings.EqualFold(c.Name, input) {
+			return true
+		}
+	}
+	return false
+}
+
 // handleSlashCommand handles slash commands
 func (m Model) handleSlashCommand(input string) (tea.Model, tea.Cmd) {
 	parts := strings.Fields(input)
@@ -314,70 +738,105 @@ func (m Model) handleSlashCommand(input string) (tea.Model, tea.Cmd) {
 
 	switch command {
 	case "/skills":
-		return m.handleSkillsCommand()
+		m.handleSkillsCommand()
 	case "/help":
-		return m.handleHelpCommand()
+		m.addMessage("assistant", helpText)
 	case "/new":
-		m.conversationID = ""
-		m.messages = []Message{}
-		m.updateViewport()
-		m.messages = append(m.messages, Message{
-			Role:      "system",
-			Content:   "🆕 New conversation started!",
-			Timestamp: time.Now(),
-		})
-		m.updateViewport()
+		m.newConversation()
+		m.refreshConversations()
 	case "/clear":
-		m.messages = []Message{}
+		m.messages = nil
 		m.updateViewport()
+	case "/conversations":
+		m.toggleSidebar()
+	case "/resume":
+		m.handleResumeCommand(parts[1:])
+	case "/title":
+		m.handleTitleCommand(strings.TrimSpace(strings.TrimPrefix(input, parts[0])))
+	case "/model":
+		m.handleModelCommand(parts[1:])
+	case "/quit", "/exit":
+		return m, tea.Quit
 	default:
-		m.messages = append(m.messages, Message{
-			Role:      "system",
-			Content:   fmt.Sprintf("❓ Unknown command: %s. Type /help for available commands.", command),
-			Timestamp: time.Now(),
-		})
-		m.updateViewport()
+		m.addMessage("system", fmt.Sprintf("❓ Unknown command: %s. Type /help for available commands.", command))
 	}
 
-	m.textInput.SetValue("")
 	return m, nil
 }
 
+// handleResumeCommand continues the conversation numbered in the sidebar
+func (m *Model) handleResumeCommand(args []string) {
+	m.refreshConversations()
+	if len(args) == 0 {
+		m.addMessage("system", "Usage: /resume <number>, numbered as in the sidebar (Ctrl+B)")
+		return
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(m.conversations) {
+		m.addMessage("system", fmt.Sprintf("❌ No conversation %s. Press Ctrl+B to list them.", args[0]))
+		return
+	}
+	m.selected = n - 1
+	m.resume(m.conversations[n-1].ID)
+}
+
+// handleTitleCommand renames the current conversation
+func (m *Model) handleTitleCommand(title string) {
+	if m.conversationID == "" {
+		m.addMessage("system", "❌ Nothing to title yet; send a message first")
+		return
+	}
+	if title == "" {
+		m.addMessage("system", "Usage: /title <new title>")
+		return
+	}
+	if err := m.store.RenameConversation(m.conversationID, title); err != nil {
+		m.addMessage("system", fmt.Sprintf("❌ Failed to rename conversation: %v", err))
+		return
+	}
+	m.refreshConversations()
+	m.addMessage("system", fmt.Sprintf("✏️  Conversation renamed to %q", title))
+}
+
+// handleModelCommand pins the conversation to the fast or smart model, or
+// with auto lets each turn be routed
+func (m *Model) handleModelCommand(args []string) {
+	if m.agent == nil {
+		m.addMessage("system", "❌ No agent available")
+		return
+	}
+	if len(args) == 0 {
+		m.addMessage("system", m.agent.DescribeRoute(m.conversationID)+"\n\nUsage: /model fast|smart|auto")
+		return
+	}
+	if m.conversationID == "" {
+		m.addMessage("system", "❌ No conversation yet; send a message first")
+		return
+	}
+	if err := m.agent.SetConversationRoute(m.conversationID, args[0]); err != nil {
+		m.addMessage("system", fmt.Sprintf("❌ %v", err))
+		return
+	}
+	m.addMessage("system", "🔀 "+m.agent.DescribeRoute(m.conversationID))
+}
+
 // handleSkillsCommand shows all skills
-func (m Model) handleSkillsCommand() (tea.Model, tea.Cmd) {
+func (m *Model) handleSkillsCommand() {
 	if m.agent == nil {
-		m.messages = append(m.messages, Message{
-			Role:      "system",
-			Content:   "❌ Agent not initialized",
-			Timestamp: time.Now(),
-		})
-		m.updateViewport()
-		m.textInput.SetValue("")
-		return m, nil
+		m.addMessage("system", "❌ Agent not initialized")
+		return
 	}
 
 	registry := m.agent.GetSkillsRegistry()
 	if registry == nil {
-		m.messages = append(m.messages, Message{
-			Role:      "system",
-			Content:   "❌ Skills registry not available",
-			Timestamp: time.Now(),
-		})
-		m.updateViewport()
-		m.textInput.SetValue("")
-		return m, nil
+		m.addMessage("system", "❌ Skills registry not available")
+		return
 	}
 
 	skills := registry.ListSkills()
 	if len(skills) == 0 {
-		m.messages = append(m.messages, Message{
-			Role:      "system",
-			Content:   "📭 No skills registered",
-			Timestamp: time.Now(),
-		})
-		m.updateViewport()
-		m.textInput.SetValue("")
-		return m, nil
+		m.addMessage("system", "📭 No skills registered")
+		return
 	}
 
 	var sb strings.Builder
@@ -399,83 +858,39 @@ func (m Model) handleSkillsCommand() (tea.Model, tea.Cmd) {
 	}
 
 	sb.WriteString(fmt.Sprintf("**Total: %d skills**", len(skills)))
-
-	m.messages = append(m.messages, Message{
-		Role:      "assistant",
-		Content:   sb.String(),
-		Timestamp: time.Now(),
-	})
-	m.updateViewport()
-	m.textInput.SetValue("")
-	return m, nil
+	m.addMessage("assistant", sb.String())
 }
 
-// handleHelpCommand shows help
-func (m Model) handleHelpCommand() (tea.Model, tea.Cmd) {
-	help := `## 🆘 Help
+const helpText = `## 🆘 Help
 
 ### Slash Commands:
 - **/skills** - List all available skills
 - **/new** - Start a new conversation
-- **/clear** - Clear the chat history
+- **/conversations** - Show or hide past conversations
+- **/resume <number>** - Continue a conversation from the sidebar
+- **/title <title>** - Rename this conversation
+- **/model fast|smart|auto** - Pick the model for this conversation
+- **/clear** - Clear the screen
 - **/help** - Show this help
+- **/quit** - Exit
+
+Type **/** to open the command palette: ↑/↓ to choose, Tab to complete.
 
 ### Keyboard Shortcuts:
 - **Enter** - Send message
-- **PgUp/PgDn** - Scroll through chat
-- **Ctrl+C** or **Esc** - Exit
+- **PgUp/PgDn**, **↑/↓** or the mouse wheel - Scroll through chat
+- **Ctrl+B** - Show past conversations; **Tab** moves between them and the input
+- **Esc** - Stop the reply being written, or exit
+- **Ctrl+C** - Exit
 
 ### Tips:
 - Just type naturally to chat with Myrai
 - Use skills by asking naturally (e.g., "Search GitHub for...")
-- The AI can execute commands, read files, and more!`
-
-	m.messages = append(m.messages, Message{
-		Role:      "assistant",
-		Content:   help,
-		Timestamp: time.Now(),
-	})
-	m.updateViewport()
-	m.textInput.SetValue("")
-	return m, nil
-}
-
-// sendToAgent sends message to agent and returns response
-func (m Model) sendToAgent(message string) tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-		resp, err := m.agent.Chat(ctx, agent.ChatRequest{
-			Message:        message,
-			ConversationID: m.conversationID,
-			Stream:         false,
-			OnToolExecuting: func(toolName string) {
-				// This runs in a different goroutine, so we can't directly update the model
-				// The tool execution will be shown in the response
-			},
-		})
-		if err != nil {
-			return errMsg{err: err}
-		}
-		return responseMsg{
-			content:        resp.Content,
-			conversationID: resp.ConversationID,
-		}
-	}
-}
-
-// Messages for tea.Cmd
-type responseMsg struct {
-	content        string
-	conversationID string
-}
-
-type errMsg struct {
-	err error
-}
+- Tools Myrai uses are shown while it works`
 
 // Run starts the TUI
-func Run(agentInstance *agent.Agent) error {
-	model, err := NewModel(agentInstance)
+func Run(agentInstance *agent.Agent, st *store.Store) error {
+	model, err := NewModel(agentInstance, st)
 	if err != nil {
 		return fmt.Errorf("failed to create TUI model: %w", err)
 	}
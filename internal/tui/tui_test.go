@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchCommands(t *testing.T) {
+	assert.Len(t, matchCommands("/"), maxPaletteItems)
+
+	matches := matchCommands("/RE")
+	require.Len(t, matches, 1)
+	assert.Equal(t, "/resume", matches[0].Name)
+
+	assert.Empty(t, matchCommands("/resume 2"), "the palette closes once arguments are typed")
+	assert.Empty(t, matchCommands("hello"))
+}
+
+func TestCloseFences(t *testing.T) {
+	assert.Equal(t, "```go\nx := 1\n```", closeFences("```go\nx := 1"))
+	assert.Equal(t, "```\nx\n```", closeFences("```\nx\n```"))
+}
+
+func newTestModel(t *testing.T, st *store.Store) Model {
+	m, err := NewModel(nil, st)
+	require.NoError(t, err)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	return updated.(Model)
+}
+
+func TestTurnEvents(t *testing.T) {
+	m := newTestModel(t, nil)
+	m.messages = append(m.messages, Message{Role: "user", Content: "weather?"}, Message{Role: "assistant"})
+	m.isLoading = true
+	m.turn = 1
+
+	send := func(msg tea.Msg) {
+		updated, _ := m.Update(turnMsg{turn: 1, msg: msg})
+		m = updated.(Model)
+	}
+
+	send(chunkMsg("Let me check."))
+	send(toolMsg("get_weather"))
+	assert.Equal(t, []toolRun{{name: "get_weather"}}, m.tools)
+	assert.Contains(t, m.statusLine(), "Using")
+	assert.Empty(t, m.messages[len(m.messages)-1].Content, "text before a tool call isn't part of the answer")
+
+	send(chunkMsg("Sunny, "))
+	assert.True(t, m.tools[0].done)
+	send(chunkMsg("22°C."))
+	assert.Equal(t, "Sunny, 22°C.", m.messages[len(m.messages)-1].Content)
+
+	// Events from a stopped turn are dropped
+	updated, _ := m.Update(turnMsg{turn: 0, msg: chunkMsg("stale")})
+	m = updated.(Model)
+
+	send(responseMsg{content: "Sunny, 22°C.", conversationID: "conv_1", tokens: 42, elapsed: time.Second})
+	last := m.messages[len(m.messages)-1]
+	assert.Equal(t, "Sunny, 22°C.", last.Content)
+	assert.Equal(t, []string{"get_weather"}, last.Tools)
+	assert.False(t, m.isLoading)
+	assert.Equal(t, "conv_1", m.conversationID)
+	assert.Contains(t, m.status, "42 tokens")
+}
+
+func TestTurnError(t *testing.T) {
+	m := newTestModel(t, nil)
+	m.messages = append(m.messages, Message{Role: "user", Content: "hi"}, Message{Role: "assistant"})
+	m.isLoading = true
+	m.turn = 1
+
+	updated, _ := m.Update(turnMsg{turn: 1, msg: errMsg{err: assert.AnError}})
+	m = updated.(Model)
+	last := m.messages[len(m.messages)-1]
+	assert.Equal(t, "system", last.Role)
+	assert.Contains(t, last.Content, "Error:")
+	assert.Equal(t, "user", m.messages[len(m.messages)-2].Role, "the empty answer is dropped")
+}
+
+func TestSidebar(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+
+	require.NoError(t, st.CreateConversation(&store.Conversation{ID: "conv_1", Title: "New Conversation"}))
+	for _, msg := range []store.Message{
+		{ID: "m1", ConversationID: "conv_1", Role: "user", Content: "Plan a trip to Lisbon\nfor May"},
+		{ID: "m2", ConversationID: "conv_1", Role: "assistant", Content: ""},
+		{ID: "m3", ConversationID: "conv_1", Role: "tool", Content: "flights: ..."},
+		{ID: "m4", ConversationID: "conv_1", Role: "assistant", Content: "Here's a plan."},
+	} {
+		msg := msg
+		require.NoError(t, st.CreateMessage(&msg))
+	}
+	require.NoError(t, st.CreateConversation(&store.Conversation{ID: "conv_2", Title: "Old", IsArchived: true}))
+
+	m := newTestModel(t, st)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlB})
+	m = updated.(Model)
+	require.Len(t, m.conversations, 1, "archived conversations aren't listed")
+	assert.Equal(t, "Plan a trip to Lisbon", m.conversations[0].Title)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(Model)
+	assert.Equal(t, focusSidebar, m.focus)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+
+	assert.Equal(t, "conv_1", m.conversationID)
+	assert.Equal(t, focusInput, m.focus)
+	var roles []string
+	for _, msg := range m.messages {
+		roles = append(roles, msg.Role)
+	}
+	assert.Equal(t, []string{"user", "assistant", "system"}, roles, "tool messages are left out")
+}
+
+func TestPaletteCompletion(t *testing.T) {
+	m := newTestModel(t, nil)
+	m.textInput.SetValue("/res")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(Model)
+	assert.Equal(t, "/resume ", m.textInput.Value())
+
+	m.textInput.SetValue("/")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = updated.(Model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	assert.Equal(t, "", m.textInput.Value())
+	assert.Contains(t, m.messages[len(m.messages)-1].Content, "New conversation", "the selected /new ran")
+}
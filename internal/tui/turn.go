@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gmsas95/myrai-cli/internal/agent"
+)
+
+// Events a running turn sends the UI
+type (
+	chunkMsg string // more of the answer streamed in
+	toolMsg  string // a tool started running
+
+	responseMsg struct {
+		content        string
+		conversationID string
+		tokens         int
+		elapsed        time.Duration
+	}
+
+	errMsg struct {
+		err error
+	}
+)
+
+// turnMsg carries an event from turn number turn, so events from a turn
+// that was stopped can be told apart and dropped
+type turnMsg struct {
+	turn int
+	msg  tea.Msg
+}
+
+// startTurn sends a message to the agent in the background. What it streams
+// arrives on events, ending with a responseMsg or errMsg; the channel is
+// closed once the turn is over or cancelled.
+func startTurn(ctx context.Context, agentInstance *agent.Agent, conversationID, message string) <-chan tea.Msg {
+	events := make(chan tea.Msg, 64)
+	send := func(msg tea.Msg) {
+		select {
+		case events <- msg:
+		case <-ctx.Done():
+		}
+	}
+
+	go func() {
+		defer close(events)
+		start := time.Now()
+		resp, err := agentInstance.Chat(ctx, agent.ChatRequest{
+			Message:         message,
+			ConversationID:  conversationID,
+			Channel:         "cli",
+			Stream:          true,
+			OnStream:        func(chunk string) { send(chunkMsg(chunk)) },
+			OnToolExecuting: func(toolName string) { send(toolMsg(toolName)) },
+		})
+		if err != nil {
+			send(errMsg{err: err})
+			return
+		}
+		send(responseMsg{
+			content:        resp.Content,
+			conversationID: resp.ConversationID,
+			tokens:         resp.TokensUsed,
+			elapsed:        time.Since(start),
+		})
+	}()
+
+	return events
+}
+
+// waitForEvent delivers the next event of a turn
+func waitForEvent(turn int, events <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-events
+		if !ok {
+			return nil
+		}
+		return turnMsg{turn: turn, msg: msg}
+	}
+}
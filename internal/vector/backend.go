@@ -0,0 +1,287 @@
+package vector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"gorm.io/gorm"
+)
+
+// Point is an embedding with the fields it can be filtered on
+type Point struct {
+	ID      string            `json:"id"`
+	Vector  []float32         `json:"vector"`
+	Payload map[string]string `json:"payload,omitempty"`
+}
+
+// Hit is a point a search found, scored by cosine similarity to the query
+type Hit struct {
+	ID      string
+	Score   float64
+	Payload map[string]string
+}
+
+// VectorStore keeps embeddings in named collections and finds those nearest a
+// query. The database tables that own the embedded items stay the source of
+// truth; a store only indexes their embeddings.
+type VectorStore interface {
+	Name() string
+	Upsert(ctx context.Context, collection string, points []Point) error
+	Delete(ctx context.Context, collection string, ids []string) error
+	// Search returns up to limit points most similar to vector whose payload
+	// has every field of filter, most similar first
+	Search(ctx context.Context, collection string, vector []float32, limit int, filter map[string]string) ([]Hit, error)
+	// Scroll calls fn with every point in the collection
+	Scroll(ctx context.Context, collection string, fn func(Point) error) error
+	Count(ctx context.Context, collection string) (int, error)
+	Close() error
+}
+
+// NewVectorStore opens the backend cfg selects. Remote backends connect on
+// first use.
+func NewVectorStore(cfg *config.VectorConfig, db *gorm.DB) (VectorStore, error) {
+	switch strings.ToLower(cfg.Backend) {
+	case "", "local":
+		return NewLocalStore(db), nil
+	case "qdrant":
+		return NewQdrantStore(cfg.Qdrant), nil
+	case "pgvector", "postgres":
+		return NewPgvectorStore(cfg.Pgvector)
+	default:
+		return nil, fmt.Errorf("unknown vector backend: %s", cfg.Backend)
+	}
+}
+
+// External reports whether vs keeps embeddings apart from the tables that
+// own them, so writers must copy embeddings to it
+func External(vs VectorStore) bool {
+	_, local := vs.(*LocalStore)
+	return vs != nil && !local
+}
+
+// Migrate copies every point of the collections from one store to another,
+// returning how many it copied
+func Migrate(ctx context.Context, from, to VectorStore, collections []string) (int, error) {
+	const batchSize = 128
+
+	copied := 0
+	for _, collection := range collections {
+		var batch []Point
+		flush := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			if err := to.Upsert(ctx, collection, batch); err != nil {
+				return fmt.Errorf("failed to copy %s: %w", collection, err)
+			}
+			copied += len(batch)
+			batch = batch[:0]
+			return nil
+		}
+		err := from.Scroll(ctx, collection, func(p Point) error {
+			batch = append(batch, p)
+			if len(batch) == batchSize {
+				return flush()
+			}
+			return nil
+		})
+		if err != nil {
+			return copied, err
+		}
+		if err := flush(); err != nil {
+			return copied, err
+		}
+	}
+	return copied, nil
+}
+
+// ==================== Local ====================
+
+// localCollection is a table that keeps its rows' embeddings in an
+// "embedding" column
+type localCollection struct {
+	table   string
+	payload []string // columns points can be filtered on
+}
+
+var (
+	localMu          sync.RWMutex
+	localCollections = map[string]localCollection{
+		MemoriesIndex: {table: "memories", payload: []string{"project"}},
+	}
+)
+
+// RegisterLocalCollection makes the embeddings kept in table's "embedding"
+// column available to the local store as a collection, filterable on the
+// payload columns
+func RegisterLocalCollection(name, table string, payload ...string) {
+	localMu.Lock()
+	defer localMu.Unlock()
+	localCollections[name] = localCollection{table: table, payload: payload}
+}
+
+// LocalCollections returns the names of the collections the local store has
+func LocalCollections() []string {
+	localMu.RLock()
+	defer localMu.RUnlock()
+	names := make([]string, 0, len(localCollections))
+	for name := range localCollections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LocalStore searches the embeddings kept in the database by comparing the
+// query with each of them. It needs no other service and suits a personal
+// corpus of up to tens of thousands of items.
+type LocalStore struct {
+	db *gorm.DB
+}
+
+// NewLocalStore creates a store over db's embedding columns
+func NewLocalStore(db *gorm.DB) *LocalStore {
+	return &LocalStore{db: db}
+}
+
+func (s *LocalStore) Name() string { return "local" }
+
+func (s *LocalStore) Close() error { return nil }
+
+func (s *LocalStore) collection(name string) (localCollection, error) {
+	localMu.RLock()
+	defer localMu.RUnlock()
+	c, ok := localCollections[name]
+	if !ok {
+		return c, fmt.Errorf("unknown collection: %s", name)
+	}
+	return c, nil
+}
+
+// Upsert stores embeddings on the rows they belong to, which must exist
+func (s *LocalStore) Upsert(ctx context.Context, collection string, points []Point) error {
+	c, err := s.collection(collection)
+	if err != nil {
+		return err
+	}
+	for _, p := range points {
+		if err := s.db.WithContext(ctx).Table(c.table).Where("id = ?", p.ID).
+			Update("embedding", EncodeEmbedding(p.Vector)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete clears the embeddings of the rows
+func (s *LocalStore) Delete(ctx context.Context, collection string, ids []string) error {
+	c, err := s.collection(collection)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.db.WithContext(ctx).Table(c.table).Where("id IN ?", ids).Update("embedding", nil).Error
+}
+
+func (s *LocalStore) Search(ctx context.Context, collection string, vector []float32, limit int, filter map[string]string) ([]Hit, error) {
+	var hits []Hit
+	err := s.scan(ctx, collection, filter, func(p Point) error {
+		if len(p.Vector) != len(vector) {
+			return nil // Embedded by another model
+		}
+		hits = append(hits, Hit{ID: p.ID, Score: CosineSimilarity(vector, p.Vector), Payload: p.Payload})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+func (s *LocalStore) Scroll(ctx context.Context, collection string, fn func(Point) error) error {
+	return s.scan(ctx, collection, nil, fn)
+}
+
+func (s *LocalStore) Count(ctx context.Context, collection string) (int, error) {
+	c, err := s.collection(collection)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = s.db.WithContext(ctx).Table(c.table).
+		Where("embedding IS NOT NULL AND length(embedding) > 0").Count(&count).Error
+	return int(count), err
+}
+
+// scan calls fn with each embedded row of the collection matching filter.
+// Rows are read first, so fn may use the database.
+func (s *LocalStore) scan(ctx context.Context, collection string, filter map[string]string, fn func(Point) error) error {
+	c, err := s.collection(collection)
+	if err != nil {
+		return err
+	}
+
+	query := s.db.WithContext(ctx).Table(c.table).
+		Select(append([]string{"id", "embedding"}, c.payload...)).
+		Where("embedding IS NOT NULL AND length(embedding) > 0")
+	for key, value := range filter {
+		if !slices.Contains(c.payload, key) {
+			return fmt.Errorf("%s can't be filtered on %s", collection, key)
+		}
+		query = query.Where(key+" = ?", value)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var points []Point
+	for rows.Next() {
+		var id string
+		var embedding []byte
+		values := make([]interface{}, len(c.payload))
+		dest := []interface{}{&id, &embedding}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+
+		p := Point{ID: id, Vector: DecodeEmbedding(embedding), Payload: make(map[string]string, len(c.payload))}
+		for i, column := range c.payload {
+			switch v := values[i].(type) {
+			case nil:
+			case []byte:
+				p.Payload[column] = string(v)
+			default:
+				p.Payload[column] = fmt.Sprint(v)
+			}
+		}
+		points = append(points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, p := range points {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
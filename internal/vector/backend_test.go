@@ -0,0 +1,206 @@
+package vector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeQdrant serves the parts of Qdrant's REST API the store uses
+func fakeQdrant(t *testing.T) *httptest.Server {
+	type point struct {
+		ID      string                 `json:"id"`
+		Vector  []float32              `json:"vector"`
+		Payload map[string]interface{} `json:"payload"`
+	}
+	var mu sync.Mutex
+	collections := make(map[string]map[string]point)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/collections/"), "/")
+		points, exists := collections[parts[0]]
+		reply := func(result interface{}) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"result": result})
+		}
+
+		var body struct {
+			Points []json.RawMessage `json:"points"`
+			Vector []float32         `json:"vector"`
+			Limit  int               `json:"limit"`
+			Filter struct {
+				Must []struct {
+					Key   string `json:"key"`
+					Match struct {
+						Value string `json:"value"`
+					} `json:"match"`
+				} `json:"must"`
+			} `json:"filter"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		if len(parts) == 1 {
+			if r.Method == http.MethodPut {
+				collections[parts[0]] = make(map[string]point)
+				reply(true)
+			} else if exists {
+				reply(map[string]interface{}{})
+			} else {
+				http.NotFound(w, r)
+			}
+			return
+		}
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+
+		switch strings.Join(parts[1:], "/") {
+		case "points":
+			for _, raw := range body.Points {
+				var p point
+				require.NoError(t, json.Unmarshal(raw, &p))
+				points[p.ID] = p
+			}
+			reply(true)
+		case "points/delete":
+			for _, raw := range body.Points {
+				var id string
+				json.Unmarshal(raw, &id)
+				delete(points, id)
+			}
+			reply(true)
+		case "points/count":
+			reply(map[string]int{"count": len(points)})
+		case "points/scroll":
+			var all []point
+			for _, p := range points {
+				all = append(all, p)
+			}
+			reply(map[string]interface{}{"points": all, "next_page_offset": nil})
+		case "points/search":
+			var hits []map[string]interface{}
+			for _, p := range points {
+				matches := true
+				for _, m := range body.Filter.Must {
+					matches = matches && p.Payload[m.Key] == m.Match.Value
+				}
+				if matches {
+					hits = append(hits, map[string]interface{}{
+						"id": p.ID, "payload": p.Payload, "score": CosineSimilarity(body.Vector, p.Vector),
+					})
+				}
+			}
+			sort.Slice(hits, func(i, j int) bool { return hits[i]["score"].(float64) > hits[j]["score"].(float64) })
+			if len(hits) > body.Limit {
+				hits = hits[:body.Limit]
+			}
+			reply(hits)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestQdrantStore(t *testing.T) {
+	srv := fakeQdrant(t)
+	defer srv.Close()
+	ctx := context.Background()
+	qdrant := NewQdrantStore(config.QdrantConfig{URL: srv.URL, CollectionPrefix: "test_"})
+
+	hits, err := qdrant.Search(ctx, "kb", []float32{1, 0}, 5, nil)
+	require.NoError(t, err, "a collection that doesn't exist yet is empty")
+	assert.Empty(t, hits)
+
+	require.NoError(t, qdrant.Upsert(ctx, "kb", []Point{
+		{ID: "1", Vector: []float32{1, 0}, Payload: map[string]string{"user_id": "alice"}},
+		{ID: "2", Vector: []float32{0.9, 0.1}, Payload: map[string]string{"user_id": "bob"}},
+		{ID: "3", Vector: []float32{0, 1}, Payload: map[string]string{"user_id": "alice"}},
+	}))
+
+	hits, err = qdrant.Search(ctx, "kb", []float32{1, 0}, 5, map[string]string{"user_id": "alice"})
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.Equal(t, "1", hits[0].ID, "points keep their own IDs")
+	assert.Equal(t, "alice", hits[0].Payload["user_id"])
+	assert.NotContains(t, hits[0].Payload, qdrantIDField)
+
+	require.NoError(t, qdrant.Delete(ctx, "kb", []string{"1"}))
+	count, err := qdrant.Count(ctx, "kb")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestMigrate_LocalToQdrant(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+	ctx := context.Background()
+	for _, m := range []store.Memory{
+		{ID: "mem_1", Content: "Prefers oat milk", Embedding: EncodeEmbedding([]float32{1, 0})},
+		{ID: "mem_2", Content: "Allergic to peanuts", Embedding: EncodeEmbedding([]float32{0, 1})},
+		{ID: "mem_3", Content: "Not embedded"},
+	} {
+		require.NoError(t, st.DB().Create(&m).Error)
+	}
+
+	local := NewLocalStore(st.DB())
+	count, err := local.Count(ctx, MemoriesIndex)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	srv := fakeQdrant(t)
+	defer srv.Close()
+	qdrant := NewQdrantStore(config.QdrantConfig{URL: srv.URL})
+
+	copied, err := Migrate(ctx, local, qdrant, []string{MemoriesIndex})
+	require.NoError(t, err)
+	assert.Equal(t, 2, copied)
+
+	// Memories are then found through the backend
+	searcher, err := NewSearcher(&config.VectorConfig{Enabled: true, Provider: "local", Dimension: 2, Backend: "qdrant",
+		Qdrant: config.QdrantConfig{URL: srv.URL}}, st, zap.NewNop())
+	require.NoError(t, err)
+	assert.True(t, External(searcher.Vectors()))
+	hits, err := searcher.Vectors().Search(ctx, MemoriesIndex, []float32{0, 1}, 1, nil)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "mem_2", hits[0].ID)
+
+	// And copied back
+	require.NoError(t, local.Delete(ctx, MemoriesIndex, []string{"mem_1", "mem_2"}))
+	copied, err = Migrate(ctx, qdrant, local, []string{MemoriesIndex})
+	require.NoError(t, err)
+	assert.Equal(t, 2, copied)
+	hits, err = local.Search(ctx, MemoriesIndex, []float32{1, 0}, 1, nil)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "mem_1", hits[0].ID)
+
+	_, err = NewVectorStore(&config.VectorConfig{Backend: "pinecone"}, st.DB())
+	assert.Error(t, err)
+	_, err = NewVectorStore(&config.VectorConfig{Backend: "pgvector"}, st.DB())
+	assert.Error(t, err, "pgvector needs a DSN")
+}
+
+func TestPgVectorText(t *testing.T) {
+	text := formatPgVector([]float32{1, 0.5, -2.25})
+	assert.Equal(t, "[1,0.5,-2.25]", text)
+	v, err := parsePgVector(text)
+	require.NoError(t, err)
+	assert.Equal(t, []float32{1, 0.5, -2.25}, v)
+	_, err = parsePgVector("1,2")
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package vector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// googleBaseURL is the Gemini API
+const googleBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+// GoogleProvider uses Google's Gemini embedding API
+type GoogleProvider struct {
+	apiKey  string
+	model   string
+	baseURL string
+	client  *http.Client
+}
+
+// NewGoogleProvider creates a Google embedding provider
+func NewGoogleProvider(apiKey, model string) *GoogleProvider {
+	if model == "" {
+		model = "text-embedding-004"
+	}
+	return &GoogleProvider{
+		apiKey:  apiKey,
+		model:   strings.TrimPrefix(model, "models/"),
+		baseURL: googleBaseURL,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *GoogleProvider) Name() string { return "google" }
+
+func (p *GoogleProvider) Model() string { return p.model }
+
+func (p *GoogleProvider) Dimension() int {
+	// gemini-embedding-001 = 3072, text-embedding-004 = 768
+	if strings.HasPrefix(p.model, "gemini-embedding") {
+		return 3072
+	}
+	return 768
+}
+
+func (p *GoogleProvider) GenerateEmbedding(text string) ([]float32, error) {
+	reqBody := map[string]interface{}{
+		"model": "models/" + p.model,
+		"content": map[string]interface{}{
+			"parts": []map[string]string{{"text": text}},
+		},
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/models/%s:embedContent", p.baseURL, p.model), bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-goog-api-key", p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Google API error: %s", resp.Status)
+	}
+
+	var result struct {
+		Embedding struct {
+			Values []float32 `json:"values"`
+		} `json:"embedding"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if len(result.Embedding.Values) == 0 {
+		return nil, fmt.Errorf("no embedding returned")
+	}
+
+	return result.Embedding.Values, nil
+}
@@ -0,0 +1,202 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// MemoriesIndex is the index of memory embeddings every searcher keeps
+const MemoriesIndex = "memories"
+
+// IndexInfo records which embedding model built an index. Embeddings from
+// different models can't be compared, so an index has to be rebuilt when the
+// model changes.
+type IndexInfo struct {
+	Name      string    `gorm:"primaryKey" json:"name"`
+	Provider  string    `json:"provider"`
+	Model     string    `json:"model"`
+	Dimension int       `json:"dimension"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName sets the table name
+func (IndexInfo) TableName() string { return "vector_indexes" }
+
+// IndexStatus is an index as recorded, compared with the active provider
+type IndexStatus struct {
+	IndexInfo
+	Recorded bool `json:"recorded"`
+	Stale    bool `json:"stale"` // built by another model; needs a reindex
+}
+
+// IndexCheck is what Verify found when reading an index back
+type IndexCheck struct {
+	IndexStatus
+	Embeddings int `json:"embeddings"`
+	// Mismatched counts embeddings whose dimension isn't the recorded one;
+	// they can't be compared with queries until the index is rebuilt
+	Mismatched int    `json:"mismatched"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Reindexer embeds everything in an index again with the active provider and
+// returns how many items it embedded
+type Reindexer func() (int, error)
+
+// RegisterIndex adds an index that can be checked and rebuilt by name
+func (s *Searcher) RegisterIndex(name string, reindex Reindexer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.indexes[name] = reindex
+}
+
+// IndexNames returns the registered indexes in name order
+func (s *Searcher) IndexNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.indexes))
+	for name := range s.indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Indexes reports each registered index and whether it needs rebuilding
+func (s *Searcher) Indexes() ([]IndexStatus, error) {
+	var statuses []IndexStatus
+	for _, name := range s.IndexNames() {
+		status := IndexStatus{IndexInfo: IndexInfo{Name: name}}
+		var info IndexInfo
+		err := s.store.DB().Where("name = ?", name).First(&info).Error
+		switch {
+		case err == nil:
+			status.IndexInfo = info
+			status.Recorded = true
+			status.Stale = info.Provider != s.ProviderName() || info.Model != s.Model()
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, fmt.Errorf("failed to read index %s: %w", name, err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// CheckIndexes records the active model for indexes built before models were
+// recorded, and warns about indexes built by a different model
+func (s *Searcher) CheckIndexes() {
+	statuses, err := s.Indexes()
+	if err != nil {
+		s.logger.Warn("Failed to check vector indexes", zap.Error(err))
+		return
+	}
+	for _, status := range statuses {
+		switch {
+		case !status.Recorded:
+			if err := s.recordIndex(status.Name); err != nil {
+				s.logger.Warn("Failed to record vector index", zap.String("index", status.Name), zap.Error(err))
+			}
+		case status.Stale:
+			s.logger.Warn("Vector index was built by another embedding model; run 'myrai vector reindex' to rebuild it",
+				zap.String("index", status.Name),
+				zap.String("built_with", status.Provider+"/"+status.Model),
+				zap.String("active", s.ProviderName()+"/"+s.Model()),
+			)
+		}
+	}
+
+	// A new backend starts empty until embeddings are copied into it
+	if External(s.vectors) {
+		ctx := context.Background()
+		local := NewLocalStore(s.store.DB())
+		for _, name := range LocalCollections() {
+			if !slices.Contains(s.IndexNames(), name) {
+				continue
+			}
+			count, err := s.vectors.Count(ctx, name)
+			if err != nil {
+				s.logger.Warn("Vector backend unavailable", zap.String("backend", s.vectors.Name()), zap.Error(err))
+				return
+			}
+			if kept, _ := local.Count(ctx, name); count == 0 && kept > 0 {
+				s.logger.Warn("Vector backend has none of the embeddings; run 'myrai vector migrate local "+s.vectors.Name()+"' to copy them",
+					zap.String("index", name),
+					zap.Int("embeddings", kept),
+				)
+			}
+		}
+	}
+}
+
+// Verify reads back every embedding of the indexes kept in the vector
+// backend and counts those of the wrong dimension. Indexes kept elsewhere,
+// such as the notes index, are only checked against the active model.
+func (s *Searcher) Verify(ctx context.Context) ([]IndexCheck, error) {
+	statuses, err := s.Indexes()
+	if err != nil {
+		return nil, err
+	}
+	collections := LocalCollections()
+	checks := make([]IndexCheck, 0, len(statuses))
+	for _, status := range statuses {
+		check := IndexCheck{IndexStatus: status}
+		if slices.Contains(collections, status.Name) {
+			err := s.vectors.Scroll(ctx, status.Name, func(p Point) error {
+				check.Embeddings++
+				if status.Dimension > 0 && len(p.Vector) != status.Dimension {
+					check.Mismatched++
+				}
+				return nil
+			})
+			if err != nil {
+				check.Error = err.Error()
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// Reindex rebuilds the named index with the active provider and records the
+// model that built it
+func (s *Searcher) Reindex(name string) (int, error) {
+	if !s.config.Enabled {
+		return 0, fmt.Errorf("vector search is disabled")
+	}
+	s.mu.RLock()
+	reindex, ok := s.indexes[name]
+	s.mu.RUnlock()
+	if !ok {
+		return 0, fmt.Errorf("unknown index: %s", name)
+	}
+
+	count, err := reindex()
+	if err != nil {
+		return count, err
+	}
+	if err := s.recordIndex(name); err != nil {
+		return count, fmt.Errorf("failed to record index: %w", err)
+	}
+	return count, nil
+}
+
+// recordIndex notes that the named index was built by the active provider
+func (s *Searcher) recordIndex(name string) error {
+	dimension := int(s.dimension.Load())
+	if dimension == 0 {
+		dimension = s.getProvider().Dimension()
+	}
+	return s.store.DB().Save(&IndexInfo{
+		Name:      name,
+		Provider:  s.ProviderName(),
+		Model:     s.Model(),
+		Dimension: dimension,
+	}).Error
+}
@@ -0,0 +1,144 @@
+package vector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/gmsas95/myrai-cli/internal/store"
+	"github.com/gmsas95/myrai-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSearcher_IndexesTrackModel(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+	require.NoError(t, st.DB().Create(&store.Memory{ID: "mem_1", Content: "Prefers oat milk", Type: "preference"}).Error)
+
+	local, err := NewSearcher(&config.VectorConfig{Enabled: true, Provider: "local", Dimension: 64}, st, zap.NewNop())
+	require.NoError(t, err)
+	notes := 0
+	local.RegisterIndex("notes", func() (int, error) { notes++; return 3, nil })
+
+	statuses, err := local.Indexes()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.False(t, statuses[0].Recorded)
+
+	// Indexes from before models were recorded are taken to be current
+	local.CheckIndexes()
+	statuses, err = local.Indexes()
+	require.NoError(t, err)
+	for _, s := range statuses {
+		assert.True(t, s.Recorded)
+		assert.False(t, s.Stale)
+		assert.Equal(t, "local", s.Provider)
+	}
+
+	// Switching models marks every index stale until it is rebuilt
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"embedding": []float32{0.1, 0.2, 0.3}})
+	}))
+	defer ollama.Close()
+	switched, err := NewSearcher(&config.VectorConfig{Enabled: true, Provider: "ollama", OllamaHost: ollama.URL}, st, zap.NewNop())
+	require.NoError(t, err)
+	switched.RegisterIndex("notes", func() (int, error) { notes++; return 3, nil })
+
+	statuses, err = switched.Indexes()
+	require.NoError(t, err)
+	for _, s := range statuses {
+		assert.True(t, s.Stale, s.Name)
+	}
+
+	count, err := switched.Reindex(MemoriesIndex)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	require.NoError(t, switched.ReindexAll())
+	assert.Equal(t, 1, notes)
+
+	statuses, err = switched.Indexes()
+	require.NoError(t, err)
+	for _, s := range statuses {
+		assert.False(t, s.Stale, s.Name)
+		assert.Equal(t, "nomic-embed-text", s.Model)
+		assert.Equal(t, 3, s.Dimension, "the dimension is what the model returned")
+	}
+
+	checks, err := switched.Verify(context.Background())
+	require.NoError(t, err)
+	require.Len(t, checks, 2)
+	assert.Equal(t, MemoriesIndex, checks[0].Name)
+	assert.Equal(t, 1, checks[0].Embeddings)
+	assert.Zero(t, checks[0].Mismatched)
+	assert.Zero(t, checks[1].Embeddings, "the notes index isn't kept in the backend")
+
+	require.NoError(t, st.DB().Model(&IndexInfo{}).Where("name = ?", MemoriesIndex).Update("dimension", 768).Error)
+	checks, err = switched.Verify(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, checks[0].Mismatched)
+
+	_, err = switched.Reindex("photos")
+	assert.Error(t, err)
+}
+
+func TestGoogleProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/text-embedding-004:embedContent", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("x-goog-api-key"))
+		var body struct {
+			Content struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"content"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "hello", body.Content.Parts[0].Text)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"embedding": map[string]interface{}{"values": []float32{1, 0}},
+		})
+	}))
+	defer srv.Close()
+
+	p := NewGoogleProvider("secret", "models/text-embedding-004")
+	p.baseURL = srv.URL
+	assert.Equal(t, "text-embedding-004", p.Model())
+
+	embedding, err := p.GenerateEmbedding("hello")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{1, 0}, embedding)
+}
+
+func TestSearcher_SearchProject(t *testing.T) {
+	st := testutil.NewTestStore(t)
+	defer st.Close()
+	for _, mem := range []store.Memory{
+		{ID: "mem_api", Content: "Prefers tabs for indentation", Type: "preference", Project: "api"},
+		{ID: "mem_blog", Content: "Prefers tabs for indentation", Type: "preference", Project: "blog"},
+		{ID: "mem_all", Content: "Prefers tabs for indentation", Type: "preference"},
+	} {
+		require.NoError(t, st.DB().Create(&mem).Error)
+	}
+
+	s, err := NewSearcher(&config.VectorConfig{Enabled: true, Provider: "local", Dimension: 64}, st, zap.NewNop())
+	require.NoError(t, err)
+	_, err = s.Reindex(MemoriesIndex)
+	require.NoError(t, err)
+
+	results, err := s.SearchProject("Prefers tabs for indentation", "api", 5)
+	require.NoError(t, err)
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.MemoryID
+	}
+	assert.ElementsMatch(t, []string{"mem_api", "mem_all"}, ids, "another project's memories are left out")
+
+	results, err = s.Search("Prefers tabs for indentation", 5)
+	require.NoError(t, err)
+	assert.Len(t, results, 3)
+}
@@ -0,0 +1,243 @@
+package vector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the "pgx" driver
+)
+
+// pgUndefinedTable is Postgres' error code for a missing table
+const pgUndefinedTable = "42P01"
+
+// pgIdentifier matches characters not allowed in the table names used
+var pgIdentifier = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// PgvectorStore keeps embeddings in Postgres with the pgvector extension, a
+// table per collection with an HNSW index for cosine distance
+type PgvectorStore struct {
+	db     *sql.DB
+	prefix string
+
+	mu      sync.Mutex
+	created map[string]bool
+}
+
+// NewPgvectorStore creates a store on the database cfg locates
+func NewPgvectorStore(cfg config.PgvectorConfig) (*PgvectorStore, error) {
+	if cfg.DSN == "" {
+		return nil, fmt.Errorf("vector.pgvector.dsn is required")
+	}
+	db, err := sql.Open("pgx", cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres: %w", err)
+	}
+	return &PgvectorStore{
+		db:      db,
+		prefix:  cfg.TablePrefix,
+		created: make(map[string]bool),
+	}, nil
+}
+
+func (s *PgvectorStore) Name() string { return "pgvector" }
+
+func (s *PgvectorStore) Close() error { return s.db.Close() }
+
+func (s *PgvectorStore) table(collection string) string {
+	return pgIdentifier.ReplaceAllString(strings.ToLower(s.prefix+collection), "_")
+}
+
+// ensureTable creates the collection's table for vectors of the given size
+func (s *PgvectorStore) ensureTable(ctx context.Context, collection string, size int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.created[collection] {
+		return nil
+	}
+
+	table := s.table(collection)
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id TEXT PRIMARY KEY,
+			embedding vector(%d) NOT NULL,
+			payload JSONB NOT NULL DEFAULT '{}'
+		)`, table, size),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_embedding_idx ON %s USING hnsw (embedding vector_cosine_ops)`, table, table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_payload_idx ON %s USING gin (payload)`, table, table),
+	}
+	for _, stmt := range statements {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to create table for %s: %w", collection, err)
+		}
+	}
+	s.created[collection] = true
+	return nil
+}
+
+func (s *PgvectorStore) Upsert(ctx context.Context, collection string, points []Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+	if err := s.ensureTable(ctx, collection, len(points[0].Vector)); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := fmt.Sprintf(`INSERT INTO %s (id, embedding, payload) VALUES ($1, $2::vector, $3::jsonb)
+		ON CONFLICT (id) DO UPDATE SET embedding = EXCLUDED.embedding, payload = EXCLUDED.payload`, s.table(collection))
+	for _, p := range points {
+		payload, err := json.Marshal(nonNilPayload(p.Payload))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, stmt, p.ID, formatPgVector(p.Vector), string(payload)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *PgvectorStore) Delete(ctx context.Context, collection string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ANY($1)`, s.table(collection)), ids)
+	if isUndefinedTable(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *PgvectorStore) Search(ctx context.Context, collection string, vector []float32, limit int, filter map[string]string) ([]Hit, error) {
+	filterJSON, err := json.Marshal(nonNilPayload(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`SELECT id, payload, 1 - (embedding <=> $1::vector) AS score
+		FROM %s WHERE payload @> $2::jsonb ORDER BY embedding <=> $1::vector LIMIT $3`, s.table(collection)),
+		formatPgVector(vector), string(filterJSON), limit)
+	if isUndefinedTable(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []Hit
+	for rows.Next() {
+		var hit Hit
+		var payload []byte
+		if err := rows.Scan(&hit.ID, &payload, &hit.Score); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &hit.Payload); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+func (s *PgvectorStore) Scroll(ctx context.Context, collection string, fn func(Point) error) error {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`SELECT id, embedding::text, payload FROM %s ORDER BY id`, s.table(collection)))
+	if isUndefinedTable(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p Point
+		var embedding string
+		var payload []byte
+		if err := rows.Scan(&p.ID, &embedding, &payload); err != nil {
+			return err
+		}
+		if p.Vector, err = parsePgVector(embedding); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(payload, &p.Payload); err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *PgvectorStore) Count(ctx context.Context, collection string) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT count(*) FROM %s`, s.table(collection))).Scan(&count)
+	if isUndefinedTable(err) {
+		return 0, nil
+	}
+	return count, err
+}
+
+func isUndefinedTable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUndefinedTable
+}
+
+func nonNilPayload(payload map[string]string) map[string]string {
+	if payload == nil {
+		return map[string]string{}
+	}
+	return payload
+}
+
+// formatPgVector writes a vector in pgvector's text form, e.g. [1,0.5,-2]
+func formatPgVector(v []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// parsePgVector reads a vector in pgvector's text form
+func parsePgVector(s string) ([]float32, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("invalid vector: %.40s", s)
+	}
+	s = s[1 : len(s)-1]
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	v := make([]float32, len(parts))
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector: %w", err)
+		}
+		v[i] = float32(f)
+	}
+	return v, nil
+}
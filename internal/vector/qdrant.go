@@ -0,0 +1,279 @@
+package vector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gmsas95/myrai-cli/internal/config"
+	"github.com/google/uuid"
+)
+
+// qdrantIDField keeps a point's own ID in its payload, since Qdrant only
+// accepts UUIDs and integers as point IDs
+const qdrantIDField = "_id"
+
+// QdrantStore keeps embeddings in a Qdrant server, one collection each
+type QdrantStore struct {
+	baseURL string
+	apiKey  string
+	prefix  string
+	client  *http.Client
+
+	mu      sync.Mutex
+	created map[string]bool
+}
+
+// NewQdrantStore creates a store on the Qdrant server cfg locates
+func NewQdrantStore(cfg config.QdrantConfig) *QdrantStore {
+	baseURL := cfg.URL
+	if baseURL == "" {
+		baseURL = "http://localhost:6333"
+	}
+	return &QdrantStore{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  cfg.APIKey,
+		prefix:  cfg.CollectionPrefix,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		created: make(map[string]bool),
+	}
+}
+
+func (s *QdrantStore) Name() string { return "qdrant" }
+
+func (s *QdrantStore) Close() error { return nil }
+
+// qdrantError is an error response, kept so a missing collection can be
+// told apart from a failure
+type qdrantError struct {
+	status int
+	body   string
+}
+
+func (e *qdrantError) Error() string {
+	return fmt.Sprintf("qdrant error: %d %s", e.status, e.body)
+}
+
+func isNotFound(err error) bool {
+	qe, ok := err.(*qdrantError)
+	return ok && qe.status == http.StatusNotFound
+}
+
+// do sends a request and decodes the response's result into out
+func (s *QdrantStore) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("api-key", s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &qdrantError{status: resp.StatusCode, body: strings.TrimSpace(string(data))}
+	}
+	if out == nil {
+		return nil
+	}
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return err
+	}
+	return json.Unmarshal(envelope.Result, out)
+}
+
+func (s *QdrantStore) collectionPath(collection string) string {
+	return "/collections/" + s.prefix + collection
+}
+
+// pointID maps an ID to the UUID Qdrant stores it under
+func pointID(collection, id string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("myrai:"+collection+":"+id)).String()
+}
+
+// ensureCollection creates the collection for vectors of the given size
+func (s *QdrantStore) ensureCollection(ctx context.Context, collection string, size int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.created[collection] {
+		return nil
+	}
+
+	err := s.do(ctx, http.MethodGet, s.collectionPath(collection), nil, nil)
+	if isNotFound(err) {
+		err = s.do(ctx, http.MethodPut, s.collectionPath(collection), map[string]interface{}{
+			"vectors": map[string]interface{}{"size": size, "distance": "Cosine"},
+		}, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create collection %s: %w", collection, err)
+	}
+	s.created[collection] = true
+	return nil
+}
+
+func (s *QdrantStore) Upsert(ctx context.Context, collection string, points []Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+	if err := s.ensureCollection(ctx, collection, len(points[0].Vector)); err != nil {
+		return err
+	}
+
+	body := make([]map[string]interface{}, 0, len(points))
+	for _, p := range points {
+		payload := map[string]string{qdrantIDField: p.ID}
+		for k, v := range p.Payload {
+			payload[k] = v
+		}
+		body = append(body, map[string]interface{}{
+			"id":      pointID(collection, p.ID),
+			"vector":  p.Vector,
+			"payload": payload,
+		})
+	}
+	return s.do(ctx, http.MethodPut, s.collectionPath(collection)+"/points?wait=true",
+		map[string]interface{}{"points": body}, nil)
+}
+
+func (s *QdrantStore) Delete(ctx context.Context, collection string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	pointIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		pointIDs = append(pointIDs, pointID(collection, id))
+	}
+	err := s.do(ctx, http.MethodPost, s.collectionPath(collection)+"/points/delete?wait=true",
+		map[string]interface{}{"points": pointIDs}, nil)
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// qdrantPoint is a point as Qdrant returns it
+type qdrantPoint struct {
+	Score   float64                `json:"score"`
+	Payload map[string]interface{} `json:"payload"`
+	Vector  []float32              `json:"vector"`
+}
+
+// point converts it back, restoring its own ID
+func (p qdrantPoint) point() Point {
+	point := Point{Vector: p.Vector, Payload: make(map[string]string, len(p.Payload))}
+	for k, v := range p.Payload {
+		if k == qdrantIDField {
+			point.ID = fmt.Sprint(v)
+			continue
+		}
+		point.Payload[k] = fmt.Sprint(v)
+	}
+	return point
+}
+
+func (s *QdrantStore) Search(ctx context.Context, collection string, vector []float32, limit int, filter map[string]string) ([]Hit, error) {
+	body := map[string]interface{}{
+		"vector":       vector,
+		"limit":        limit,
+		"with_payload": true,
+	}
+	if len(filter) > 0 {
+		var must []map[string]interface{}
+		for k, v := range filter {
+			must = append(must, map[string]interface{}{
+				"key":   k,
+				"match": map[string]interface{}{"value": v},
+			})
+		}
+		body["filter"] = map[string]interface{}{"must": must}
+	}
+
+	var result []qdrantPoint
+	err := s.do(ctx, http.MethodPost, s.collectionPath(collection)+"/points/search", body, &result)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]Hit, 0, len(result))
+	for _, r := range result {
+		p := r.point()
+		hits = append(hits, Hit{ID: p.ID, Score: r.Score, Payload: p.Payload})
+	}
+	return hits, nil
+}
+
+func (s *QdrantStore) Scroll(ctx context.Context, collection string, fn func(Point) error) error {
+	var offset interface{}
+	for {
+		body := map[string]interface{}{
+			"limit":        256,
+			"with_payload": true,
+			"with_vector":  true,
+		}
+		if offset != nil {
+			body["offset"] = offset
+		}
+
+		var result struct {
+			Points []qdrantPoint `json:"points"`
+			Next   interface{}   `json:"next_page_offset"`
+		}
+		err := s.do(ctx, http.MethodPost, s.collectionPath(collection)+"/points/scroll", body, &result)
+		if isNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, r := range result.Points {
+			if err := fn(r.point()); err != nil {
+				return err
+			}
+		}
+		if result.Next == nil {
+			return nil
+		}
+		offset = result.Next
+	}
+}
+
+func (s *QdrantStore) Count(ctx context.Context, collection string) (int, error) {
+	var result struct {
+		Count int `json:"count"`
+	}
+	err := s.do(ctx, http.MethodPost, s.collectionPath(collection)+"/points/count",
+		map[string]interface{}{"exact": true}, &result)
+	if isNotFound(err) {
+		return 0, nil
+	}
+	return result.Count, err
+}
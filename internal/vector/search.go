@@ -3,12 +3,15 @@ package vector
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gmsas95/myrai-cli/internal/config"
@@ -24,11 +27,15 @@ type Searcher struct {
 	mu        sync.RWMutex
 	cache     map[string][]float32 // In-memory cache for embeddings
 	providers map[string]Provider
+	indexes   map[string]Reindexer
+	dimension atomic.Int64 // of the last embedding generated
+	vectors   VectorStore
 }
 
 // Provider interface for embedding generation
 type Provider interface {
 	Name() string
+	Model() string
 	GenerateEmbedding(text string) ([]float32, error)
 	Dimension() int
 }
@@ -40,6 +47,9 @@ type Result struct {
 	Similarity float64
 	Type       string
 	Importance int
+	Source     string    // conversation the memory came from, or import
+	Project    string    // project the memory belongs to, if any
+	CreatedAt  time.Time // when it was remembered
 }
 
 // NewSearcher creates a new vector searcher
@@ -58,10 +68,27 @@ func NewSearcher(cfg *config.VectorConfig, st *store.Store, logger *zap.Logger)
 		logger:    logger,
 		cache:     make(map[string][]float32),
 		providers: make(map[string]Provider),
+		indexes:   make(map[string]Reindexer),
 	}
 
 	// Register providers
 	s.registerProviders()
+	if _, ok := s.providers[cfg.Provider]; cfg.Enabled && !ok {
+		logger.Warn("Embedding provider not available, using local embeddings",
+			zap.String("provider", cfg.Provider))
+	}
+
+	if st != nil {
+		if err := st.DB().AutoMigrate(&IndexInfo{}); err != nil {
+			return nil, fmt.Errorf("failed to migrate vector indexes: %w", err)
+		}
+		vectors, err := NewVectorStore(cfg, st.DB())
+		if err != nil {
+			return nil, err
+		}
+		s.vectors = vectors
+		s.RegisterIndex(MemoriesIndex, s.reindexMemories)
+	}
 
 	return s, nil
 }
@@ -71,6 +98,11 @@ func (s *Searcher) IsEnabled() bool {
 	return s.config.Enabled
 }
 
+// Vectors returns the backend embeddings are searched in
+func (s *Searcher) Vectors() VectorStore {
+	return s.vectors
+}
+
 // registerProviders initializes embedding providers
 func (s *Searcher) registerProviders() {
 	// Local provider (simplified word-based embeddings)
@@ -81,6 +113,11 @@ func (s *Searcher) registerProviders() {
 		s.providers["openai"] = NewOpenAIProvider(s.config.OpenAIAPIKey, s.config.EmbeddingModel)
 	}
 
+	// Google provider
+	if s.config.GoogleAPIKey != "" {
+		s.providers["google"] = NewGoogleProvider(s.config.GoogleAPIKey, s.config.EmbeddingModel)
+	}
+
 	// Ollama provider
 	s.providers["ollama"] = NewOllamaProvider(s.config.OllamaHost, s.config.EmbeddingModel)
 }
@@ -94,6 +131,16 @@ func (s *Searcher) getProvider() Provider {
 	return s.providers["local"]
 }
 
+// ProviderName returns the name of the provider embeddings are generated with
+func (s *Searcher) ProviderName() string {
+	return s.getProvider().Name()
+}
+
+// Model returns the embedding model of the active provider
+func (s *Searcher) Model() string {
+	return s.getProvider().Model()
+}
+
 // GenerateEmbedding creates an embedding for the given text
 func (s *Searcher) GenerateEmbedding(text string) ([]float32, error) {
 	if !s.config.Enabled {
@@ -101,7 +148,15 @@ func (s *Searcher) GenerateEmbedding(text string) ([]float32, error) {
 	}
 
 	provider := s.getProvider()
-	return provider.GenerateEmbedding(text)
+	embedding, err := provider.GenerateEmbedding(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(embedding) == 0 {
+		return nil, fmt.Errorf("%s returned an empty embedding", provider.Name())
+	}
+	s.dimension.Store(int64(len(embedding)))
+	return embedding, nil
 }
 
 // IndexMemory indexes a memory for vector search
@@ -116,7 +171,7 @@ func (s *Searcher) IndexMemory(memoryID string, content string) error {
 	}
 
 	// Convert to bytes for storage
-	embeddingBytes := float32SliceToBytes(embedding)
+	embeddingBytes := EncodeEmbedding(embedding)
 
 	// Update memory in database
 	if err := s.store.DB().Model(&store.Memory{}).
@@ -124,6 +179,14 @@ func (s *Searcher) IndexMemory(memoryID string, content string) error {
 		Update("embedding", embeddingBytes).Error; err != nil {
 		return fmt.Errorf("failed to store embedding: %w", err)
 	}
+	if External(s.vectors) {
+		var mem store.Memory
+		s.store.DB().Select("project").First(&mem, "id = ?", memoryID)
+		point := Point{ID: memoryID, Vector: embedding, Payload: map[string]string{"project": mem.Project}}
+		if err := s.vectors.Upsert(context.Background(), MemoriesIndex, []Point{point}); err != nil {
+			return fmt.Errorf("failed to store embedding in %s: %w", s.vectors.Name(), err)
+		}
+	}
 
 	// Cache in memory
 	s.mu.Lock()
@@ -140,6 +203,13 @@ func (s *Searcher) IndexMemory(memoryID string, content string) error {
 
 // Search performs semantic search on memories
 func (s *Searcher) Search(query string, limit int) ([]Result, error) {
+	return s.SearchProject(query, "", limit)
+}
+
+// SearchProject searches the memories of a project together with those that
+// apply everywhere, leaving out other projects' memories. An empty project
+// searches them all.
+func (s *Searcher) SearchProject(query, project string, limit int) ([]Result, error) {
 	if !s.config.Enabled {
 		return nil, fmt.Errorf("vector search is disabled")
 	}
@@ -150,38 +220,74 @@ func (s *Searcher) Search(query string, limit int) ([]Result, error) {
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
-	// Get all memories with embeddings
-	memories, err := s.getMemoriesWithEmbeddings()
+	if project == "" {
+		return s.searchMemories(queryEmbedding, limit, nil)
+	}
+
+	results, err := s.searchMemories(queryEmbedding, limit, map[string]string{"project": project})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get memories: %w", err)
+		return nil, err
+	}
+	// Memories embedded before projects had no project in external
+	// backends, so those that apply everywhere are picked out here
+	everywhere, err := s.searchMemories(queryEmbedding, limit*2, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range everywhere {
+		if r.Project == "" {
+			results = append(results, r)
+		}
 	}
 
-	if len(memories) == 0 {
-		return []Result{}, nil
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Similarity > results[j].Similarity
+	})
+	if len(results) > limit {
+		results = results[:limit]
 	}
+	return results, nil
+}
 
-	// Calculate similarities
-	results := make([]Result, 0, len(memories))
-	for _, mem := range memories {
-		if len(mem.Embedding) == 0 {
-			continue
-		}
+// searchMemories finds the memories nearest an embedding among those whose
+// payload matches filter
+func (s *Searcher) searchMemories(queryEmbedding []float32, limit int, filter map[string]string) ([]Result, error) {
+	// Find the nearest memories in the backend
+	hits, err := s.vectors.Search(context.Background(), MemoriesIndex, queryEmbedding, limit, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search memories: %w", err)
+	}
 
-		embedding := bytesToFloat32Slice(mem.Embedding)
-		if len(embedding) != len(queryEmbedding) {
-			continue // Skip if dimensions don't match
+	similarities := make(map[string]float64, len(hits))
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		if hit.Score > 0.5 { // Threshold for relevance
+			similarities[hit.ID] = hit.Score
+			ids = append(ids, hit.ID)
 		}
+	}
+	if len(ids) == 0 {
+		return []Result{}, nil
+	}
 
-		similarity := cosineSimilarity(queryEmbedding, embedding)
-		if similarity > 0.5 { // Threshold for relevance
-			results = append(results, Result{
-				MemoryID:   mem.ID,
-				Content:    mem.Content,
-				Similarity: similarity,
-				Type:       mem.Type,
-				Importance: mem.Importance,
-			})
-		}
+	var memories []store.Memory
+	if err := s.store.DB().Where("id IN ?", ids).Find(&memories).Error; err != nil {
+		return nil, fmt.Errorf("failed to get memories: %w", err)
+	}
+
+	// Memories deleted since they were indexed are left out
+	results := make([]Result, 0, len(memories))
+	for _, mem := range memories {
+		results = append(results, Result{
+			MemoryID:   mem.ID,
+			Content:    mem.Content,
+			Similarity: similarities[mem.ID],
+			Type:       mem.Type,
+			Importance: mem.Importance,
+			Source:     mem.Source,
+			Project:    mem.Project,
+			CreatedAt:  mem.CreatedAt,
+		})
 	}
 
 	// Sort by similarity (highest first)
@@ -189,11 +295,6 @@ func (s *Searcher) Search(query string, limit int) ([]Result, error) {
 		return results[i].Similarity > results[j].Similarity
 	})
 
-	// Return top results
-	if len(results) > limit {
-		results = results[:limit]
-	}
-
 	return results, nil
 }
 
@@ -219,45 +320,49 @@ func (s *Searcher) SearchWithThreshold(query string, limit int, threshold float6
 	return filtered, nil
 }
 
-// getMemoriesWithEmbeddings retrieves all memories with embeddings
-func (s *Searcher) getMemoriesWithEmbeddings() ([]store.Memory, error) {
-	var memories []store.Memory
-	err := s.store.DB().
-		Where("embedding IS NOT NULL AND length(embedding) > 0").
-		Order("created_at DESC").
-		Limit(1000). // Reasonable limit for in-memory search
-		Find(&memories).Error
-	return memories, err
-}
-
-// ReindexAll reindexes all memories (useful when changing embedding models)
+// ReindexAll rebuilds every registered index (useful when changing embedding models)
 func (s *Searcher) ReindexAll() error {
-	if !s.config.Enabled {
-		return fmt.Errorf("vector search is disabled")
+	for _, name := range s.IndexNames() {
+		if _, err := s.Reindex(name); err != nil {
+			return fmt.Errorf("failed to reindex %s: %w", name, err)
+		}
 	}
+	return nil
+}
 
+// reindexMemories embeds every memory again
+func (s *Searcher) reindexMemories() (int, error) {
 	s.logger.Info("Starting full memory reindex")
 
 	var memories []store.Memory
 	if err := s.store.DB().Find(&memories).Error; err != nil {
-		return err
+		return 0, err
 	}
 
+	// Embeddings from the previous model can't be compared with new ones
+	s.mu.Lock()
+	s.cache = make(map[string][]float32)
+	s.mu.Unlock()
+
+	indexed := 0
+
 	for _, mem := range memories {
 		if err := s.IndexMemory(mem.ID, mem.Content); err != nil {
 			s.logger.Error("Failed to index memory",
 				zap.String("memory_id", mem.ID),
 				zap.Error(err),
 			)
+			continue
 		}
+		indexed++
 	}
 
-	s.logger.Info("Memory reindex complete", zap.Int("count", len(memories)))
-	return nil
+	s.logger.Info("Memory reindex complete", zap.Int("count", indexed))
+	return indexed, nil
 }
 
-// cosineSimilarity calculates cosine similarity between two vectors
-func cosineSimilarity(a, b []float32) float64 {
+// CosineSimilarity calculates cosine similarity between two vectors
+func CosineSimilarity(a, b []float32) float64 {
 	if len(a) != len(b) {
 		return 0
 	}
@@ -279,8 +384,8 @@ func cosineSimilarity(a, b []float32) float64 {
 	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
 }
 
-// float32SliceToBytes converts float32 slice to bytes
-func float32SliceToBytes(f []float32) []byte {
+// EncodeEmbedding converts an embedding to bytes for storage
+func EncodeEmbedding(f []float32) []byte {
 	buf := make([]byte, len(f)*4)
 	for i, v := range f {
 		bits := math.Float32bits(v)
@@ -292,8 +397,8 @@ func float32SliceToBytes(f []float32) []byte {
 	return buf
 }
 
-// bytesToFloat32Slice converts bytes to float32 slice
-func bytesToFloat32Slice(b []byte) []float32 {
+// DecodeEmbedding converts stored bytes back to an embedding
+func DecodeEmbedding(b []byte) []float32 {
 	if len(b)%4 != 0 {
 		return nil
 	}
@@ -328,6 +433,9 @@ func NewLocalProvider(dimension int) *LocalProvider {
 
 func (p *LocalProvider) Name() string { return "local" }
 
+// Model names the built-in hashed word embeddings, which need no service
+func (p *LocalProvider) Model() string { return "word-hash" }
+
 func (p *LocalProvider) Dimension() int { return p.dimension }
 
 func (p *LocalProvider) GenerateEmbedding(text string) ([]float32, error) {
@@ -394,6 +502,8 @@ func NewOpenAIProvider(apiKey, model string) *OpenAIProvider {
 
 func (p *OpenAIProvider) Name() string { return "openai" }
 
+func (p *OpenAIProvider) Model() string { return p.model }
+
 func (p *OpenAIProvider) Dimension() int {
 	// text-embedding-3-small = 1536, text-embedding-3-large = 3072
 	if p.model == "text-embedding-3-large" {
@@ -472,10 +582,20 @@ func NewOllamaProvider(host, model string) *OllamaProvider {
 
 func (p *OllamaProvider) Name() string { return "ollama" }
 
+func (p *OllamaProvider) Model() string { return p.model }
+
+// ollamaDimensions are the sizes of popular Ollama embedding models
+var ollamaDimensions = map[string]int{
+	"nomic-embed-text":       768,
+	"mxbai-embed-large":      1024,
+	"all-minilm":             384,
+	"snowflake-arctic-embed": 1024,
+	"bge-m3":                 1024,
+}
+
 func (p *OllamaProvider) Dimension() int {
-	// nomic-embed-text = 768
-	if p.model == "nomic-embed-text" {
-		return 768
+	if dim, ok := ollamaDimensions[strings.Split(p.model, ":")[0]]; ok {
+		return dim
 	}
 	return 4096 // Default for most models
 }